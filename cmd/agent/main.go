@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/jrzesz33/rez_agent/internal/agent"
+	"github.com/jrzesz33/rez_agent/internal/cors"
+	"github.com/jrzesz33/rez_agent/internal/health"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/prompts"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	internalscheduler "github.com/jrzesz33/rez_agent/internal/scheduler"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad()
+
+	logger.Info("agent lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+		slog.String("region", cfg.AWSRegion),
+	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+
+	// Create AWS clients
+	dynamoClient := dynamodb.NewFromConfig(awsCfg)
+	bedrockClient := bedrockruntime.NewFromConfig(awsCfg)
+
+	// Create repositories; usage against the daily spending cap is kept in the messages table
+	sessionRepo := repository.NewDynamoDBAgentSessionRepository(dynamoClient, cfg.AgentSessionTableName)
+	sessionRepo.SetWindow(cfg.AgentSessionWindow)
+	usageRepo := repository.NewDynamoDBAgentUsageRepository(dynamoClient, cfg.DynamoDBTableName, cfg.Stage.String())
+
+	// Chat turns run the scheduler's Bedrock conversation loop and MCP tools
+	httpClient := httpclient.NewClient(logger)
+	httpClient.SetCircuitBreaker(httpclient.NewCircuitBreaker(httpclient.CircuitBreakerConfig{
+		FailureThreshold: cfg.CircuitBreakerFailureThreshold,
+		OpenTimeout:      cfg.CircuitBreakerOpenTimeout,
+	}, logger))
+	chat := internalscheduler.NewAWSAgentEventHandler(
+		bedrockClient,
+		httpClient,
+		localrun.NewSecretsManager(cfg, awsCfg, logger),
+		nil,
+		logger,
+	)
+	chat.SetGuardrails(cfg.AgentGuardrails)
+	if cfg.PromptParameterPrefix != "" {
+		chat.SetPromptStore(prompts.NewStore(
+			prompts.NewSSMOverrideSource(ssm.NewFromConfig(awsCfg), cfg.PromptParameterPrefix),
+			logger,
+		))
+		logger.Info("prompt template overrides enabled", slog.String("prefix", cfg.PromptParameterPrefix))
+	}
+	if cfg.BedrockGuardrailID != "" {
+		chat.SetBedrockGuardrail(cfg.BedrockGuardrailID, cfg.BedrockGuardrailVersion)
+	}
+	chat.SetThrottleBudget(cfg.BedrockThrottleBudget)
+
+	// Initialize SQS processor for web action results sent back to the agent
+	sqsProcessor := messaging.NewSQSBatchProcessor(logger)
+	sqsProcessor.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentAgent)
+	sqsProcessor.SetRecordTimeout(cfg.SQSRecordTimeout)
+
+	// Create handler; GET /agent/health checks the session and messages tables
+	handler := agent.NewHandler(cfg, chat, sessionRepo, usageRepo, logger)
+	handler.SetHealthChecks([]health.Check{
+		health.DynamoDBTable("sessions", dynamoClient, cfg.AgentSessionTableName),
+		health.DynamoDBTable("messages", dynamoClient, cfg.DynamoDBTableName),
+	})
+	handler.SetResponseProcessor(sqsProcessor)
+	// Other agents cancel their tasks through the session table, which every instance polls
+	handler.SetTaskRepository(repository.NewDynamoDBAgentTaskRepository(dynamoClient, cfg.AgentSessionTableName))
+
+	// Start Lambda handler: API Gateway requests, function URL requests with streamed responses and
+	// the agent-responses queue (or, in local mode, serve the API and poll the queue). Browsers may
+	// call the API from the stage's allowed origins.
+	policy := cors.NewPolicy(cfg, http.MethodGet, http.MethodPost)
+	localrun.StartAPIAndSQS(cfg, localrun.AgentAddr, localrun.Handlers{
+		API:    policy.Handler(handler.HandleRequest),
+		Stream: policy.StreamHandler(handler.HandleStream),
+		SQS:    handler.HandleAgentResponses,
+	}, sqs.NewFromConfig(awsCfg), cfg.AgentResponseQueueURL, logger)
+}
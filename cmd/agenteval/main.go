@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/jrzesz33/rez_agent/internal/agenteval"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/prompts"
+	internalscheduler "github.com/jrzesz33/rez_agent/internal/scheduler"
+)
+
+// agenteval replays recorded scheduled agent runs against the prompts in this build (or a
+// directory of template overrides) and an optional different model. Tools are answered from
+// each recording, so nothing is booked or sent. Example:
+//
+//	go run ./cmd/agenteval -stage prod -since 2026/10/01 -prompts ./prompts-draft
+func main() {
+	bucket := flag.String("bucket", os.Getenv("AGENT_LOGS_BUCKET"), "agent logs bucket holding the recordings")
+	stage := flag.String("stage", "dev", "stage whose recordings are replayed")
+	region := flag.String("region", "us-east-1", "AWS region")
+	scheduleID := flag.String("schedule", "", "only replay runs of this schedule")
+	since := flag.String("since", "", "only replay runs recorded on or after this date (YYYY/MM/DD)")
+	limit := flag.Int("limit", 10, "maximum number of runs to replay, most recent first")
+	modelID := flag.String("model", "", "Bedrock model to replay with (default: each run's recorded model)")
+	promptDir := flag.String("prompts", "", "directory of {template}.tmpl files overriding the built-in prompts")
+	failOnChange := flag.Bool("fail-on-change", false, "exit 1 when any replay diverges from its recording")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	}))
+
+	ctx := context.Background()
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(*region))
+	if err != nil {
+		fatal("failed to load AWS config: %v", err)
+	}
+
+	recordings, err := loadRecordings(ctx, s3.NewFromConfig(awsCfg), *bucket, *stage, *scheduleID, *since, *limit, flag.Args())
+	if err != nil {
+		fatal("%v", err)
+	}
+	if len(recordings) == 0 {
+		fatal("no recordings found")
+	}
+
+	store := prompts.NewStore(nil, logger)
+	if *promptDir != "" {
+		store = prompts.NewStore(prompts.NewDirOverrideSource(*promptDir), logger)
+	}
+	bedrockClient := bedrockruntime.NewFromConfig(awsCfg)
+
+	changed := 0
+	for _, recording := range recordings {
+		// A fresh handler per run, so no state carries over between replays
+		handler := internalscheduler.NewAWSAgentEventHandler(bedrockClient, nil, nil, nil, logger)
+		handler.SetPromptStore(store)
+		handler.SetModelID(recording.ModelID)
+		if *modelID != "" {
+			handler.SetModelID(*modelID)
+		}
+		mock := agenteval.NewMockTools(recording)
+		handler.SetToolCaller(mock)
+
+		replay, err := handler.Replay(ctx, recording)
+		if err != nil {
+			fatal("failed to replay %s: %v", recording.ExecutionID, err)
+		}
+
+		comparison := agenteval.Compare(recording, replay, mock.Unmatched())
+		if comparison.Changed() {
+			changed++
+		}
+		fmt.Println(comparison.String())
+	}
+
+	fmt.Printf("%d of %d replayed runs changed\n", changed, len(recordings))
+	if changed > 0 && *failOnChange {
+		os.Exit(1)
+	}
+}
+
+// loadRecordings reads the recordings named on the command line, or else the most recent
+// recordings in the bucket matching the filters
+func loadRecordings(ctx context.Context, client *s3.Client, bucket, stage, scheduleID, since string, limit int, files []string) ([]*internalscheduler.RunRecording, error) {
+	recordings := make([]*internalscheduler.RunRecording, 0)
+	if len(files) > 0 {
+		for _, file := range files {
+			body, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read recording: %w", err)
+			}
+			recording, err := internalscheduler.ParseRunRecording(body)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			recordings = append(recordings, recording)
+		}
+		return recordings, nil
+	}
+
+	if bucket == "" {
+		return nil, fmt.Errorf("-bucket or AGENT_LOGS_BUCKET is required to load recordings from S3")
+	}
+	publisher := internalscheduler.NewRunSummaryPublisher(client, bucket, stage)
+	keys, err := publisher.ListRecordings(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys end in {yyyy/mm/dd}/{execution_id}.json with nanosecond execution IDs, so they sort by time
+	// within a schedule; sort on the dated suffix to order across schedules
+	keys = filterSince(keys, since)
+	sort.Slice(keys, func(i, j int) bool { return datedSuffix(keys[i]) > datedSuffix(keys[j]) })
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	for _, key := range keys {
+		recording, err := publisher.GetRecording(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		recordings = append(recordings, recording)
+	}
+	return recordings, nil
+}
+
+// filterSince keeps the keys recorded on or after since (YYYY/MM/DD); an empty since keeps all
+func filterSince(keys []string, since string) []string {
+	if since == "" {
+		return keys
+	}
+	kept := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if datedSuffix(key) >= since {
+			kept = append(kept, key)
+		}
+	}
+	return kept
+}
+
+// datedSuffix returns the {yyyy/mm/dd}/{execution_id}.json part of a recording key
+func datedSuffix(key string) string {
+	parts := strings.Split(key, "/")
+	if len(parts) < 4 {
+		return key
+	}
+	return strings.Join(parts[len(parts)-4:], "/")
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "agenteval: "+format+"\n", args...)
+	os.Exit(1)
+}
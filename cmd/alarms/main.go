@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+
+	"github.com/jrzesz33/rez_agent/internal/alarms"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/outbox"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+// AlarmHandler turns CloudWatch alarm notifications into notify messages, so a breached alarm
+// reaches the phone through the same pipeline as every other notification
+type AlarmHandler struct {
+	config *appconfig.Config
+	outbox *outbox.Writer
+	logger *slog.Logger
+}
+
+// HandleEvent handles the alarm notifications in an SNS event. An error makes Lambda retry the
+// whole event, so a notification may be sent twice but is not lost.
+func (h *AlarmHandler) HandleEvent(ctx context.Context, event events.SNSEvent) error {
+	for _, record := range event.Records {
+		alarm, err := alarms.Parse(record.SNS.Message)
+		if err != nil {
+			h.logger.WarnContext(ctx, "ignoring SNS message that is not an alarm",
+				slog.String("sns_message_id", record.SNS.MessageID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		// Only breaches are worth a push notification; recoveries go to the alerts topic alone
+		if !alarm.Breached() {
+			h.logger.InfoContext(ctx, "ignoring alarm state change",
+				slog.String("alarm_name", alarm.AlarmName),
+				slog.String("state", alarm.NewStateValue),
+			)
+			continue
+		}
+
+		if err := h.notify(ctx, alarm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notify saves and publishes a notify message for a breached alarm
+func (h *AlarmHandler) notify(ctx context.Context, alarm *alarms.Alarm) error {
+	msg := alarm.ToMessage(h.config.Stage)
+
+	// A saved notification is published by the outbox; retrying the alarm would send it twice
+	if err := h.outbox.Send(ctx, msg); err != nil {
+		if !errors.Is(err, outbox.ErrNotPublished) {
+			return fmt.Errorf("failed to send alarm notification: %w", err)
+		}
+		h.logger.WarnContext(ctx, "alarm notification saved for the outbox to publish",
+			slog.String("alarm_name", alarm.AlarmName),
+			slog.String("message_id", msg.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+
+	h.logger.InfoContext(ctx, "published alarm notification",
+		slog.String("alarm_name", alarm.AlarmName),
+		slog.String("message_id", msg.ID),
+	)
+	return nil
+}
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad(appconfig.GroupTopicRouting)
+
+	logger.Info("alarms lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+
+	// Route message types to topics from the configured routing table
+	routes, err := messaging.NewTopicRoutingTable(cfg.TopicRoutes, cfg.NotificationsSNSTopicArn)
+	if err != nil {
+		logger.Error("invalid topic routing configuration", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("invalid topic routing configuration: %v", err))
+	}
+	publisher := messaging.NewTopicRoutingSNSClient(sns.NewFromConfig(awsCfg), routes, logger)
+	publisher.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentAlarms)
+	publisher.SetEventPublisher(localrun.NewEventPublisher(cfg, awsCfg, logger))
+
+	// Notifications are saved and published through the outbox; with the relay deployed, it publishes them
+	writer := outbox.NewWriter(repository.NewDynamoDBRepository(dynamodb.NewFromConfig(awsCfg), cfg.DynamoDBTableName), publisher, logger)
+	if cfg.OutboxRelay {
+		writer.SetRelayed(models.DefaultMessageTypeRegistry(), models.ComponentAlarms)
+	}
+
+	handler := &AlarmHandler{
+		config: cfg,
+		outbox: writer,
+		logger: logger,
+	}
+
+	// Start Lambda handler
+	localrun.Start(cfg, localrun.AlarmsAddr, handler.HandleEvent, logger)
+}
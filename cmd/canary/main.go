@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/oauth"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/internal/webaction"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// defaultDaysAhead is how far ahead the canary searches: well inside the booking window, so there
+// are tee times to lock, and clear of the day the morning booking run opens
+const defaultDaysAhead = 7
+
+// CanaryRequest is the invocation payload. The EventBridge schedule sends its own event, which
+// has none of these fields, so a scheduled run checks every course.
+type CanaryRequest struct {
+	// CourseIDs limits the run to the given courses
+	CourseIDs []int `json:"course_ids,omitempty"`
+	// DaysAhead is how many days from today to search, 1 to 13
+	DaysAhead int `json:"days_ahead,omitempty"`
+}
+
+// CanaryResponse counts the courses that passed and failed and describes each run
+type CanaryResponse struct {
+	Passed  int      `json:"passed"`
+	Failed  int      `json:"failed"`
+	Results []string `json:"results"`
+}
+
+// CanaryHandler exercises the booking pipeline up to the reservation against every course, so a
+// change in the provider's API is caught before the morning booking run depends on it
+type CanaryHandler struct {
+	golf     *webaction.GolfHandler
+	recorder *metrics.CanaryRecorder
+	stage    string
+	logger   *slog.Logger
+}
+
+// HandleRequest runs the canary. Failures are reported through the CanaryFailures metric, which
+// the canary alarm watches, rather than as an error: a failed invocation would be retried and
+// lock more tee times.
+func (h *CanaryHandler) HandleRequest(ctx context.Context, request CanaryRequest) (CanaryResponse, error) {
+	daysAhead := request.DaysAhead
+	if daysAhead == 0 {
+		daysAhead = defaultDaysAhead
+	}
+	if daysAhead < 1 || daysAhead > 13 {
+		return CanaryResponse{}, fmt.Errorf("days_ahead must be between 1 and 13, got %d", daysAhead)
+	}
+
+	config, err := courses.LoadCourses()
+	if err != nil {
+		return CanaryResponse{}, err
+	}
+	var response CanaryResponse
+	for _, course := range config.Courses {
+		if len(request.CourseIDs) > 0 && !slices.Contains(request.CourseIDs, course.CourseID) {
+			continue
+		}
+
+		date := time.Now().In(course.Location()).AddDate(0, 0, daysAhead)
+		result := h.golf.RunCanary(ctx, &course, course.GetSecretName(h.stage), date)
+		for _, step := range result.Steps {
+			h.recorder.RecordStep(ctx, course.CourseID, step.Name, step.Duration, step.Err != nil)
+		}
+		failed := result.Failed() != nil
+		h.recorder.RecordRun(ctx, course.CourseID, failed)
+
+		if failed {
+			h.logger.ErrorContext(ctx, "booking canary failed", slog.Int("course_id", course.CourseID), slog.String("result", result.Summary()))
+			response.Failed++
+		} else {
+			h.logger.InfoContext(ctx, "booking canary passed", slog.Int("course_id", course.CourseID), slog.String("result", result.Summary()))
+			response.Passed++
+		}
+		response.Results = append(response.Results, result.Summary())
+	}
+	return response, nil
+}
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad()
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	logger.Info("booking canary lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+	)
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+	dynamoClient := dynamodb.NewFromConfig(awsCfg)
+
+	httpClient := httpclient.NewClient(logger)
+	secretsManager := localrun.NewSecretsManager(cfg, awsCfg, logger)
+	secretsManager.SetAccessRecorder(audit.NewRecorder(repository.NewDynamoDBAuditRepository(dynamoClient, cfg.AuditTableName), "canary", logger).SecretAccessed)
+	oauthClient := oauth.NewClient(httpClient, secretsManager, logger)
+	tokenCache := oauth.NewTokenCache(cfg.OAuthTokenRefreshBefore, logger)
+	if cfg.OAuthTokenTableName != "" {
+		tokenCache.SetStore(repository.NewDynamoDBOAuthTokenRepository(dynamoClient, cfg.OAuthTokenTableName))
+	}
+	oauthClient.SetTokenCache(tokenCache)
+
+	handler := &CanaryHandler{
+		golf:     webaction.NewGolfHandler(httpClient, oauthClient, secretsManager, logger),
+		recorder: metrics.NewCanaryRecorder(os.Stdout, cfg.Stage.String(), logger),
+		stage:    cfg.Stage.String(),
+		logger:   logger,
+	}
+
+	// Start Lambda handler
+	localrun.Start(cfg, localrun.CanaryAddr, handler.HandleRequest, logger)
+}
@@ -15,13 +15,13 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/scheduler"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
 
-	"github.com/jrzesz33/rez_agent/internal/httpclient"
 	"github.com/jrzesz33/rez_agent/internal/logging"
 	"github.com/jrzesz33/rez_agent/internal/messaging"
 	"github.com/jrzesz33/rez_agent/internal/models"
 	"github.com/jrzesz33/rez_agent/internal/repository"
 	internalscheduler "github.com/jrzesz33/rez_agent/internal/scheduler"
 	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
 	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
 )
 
@@ -84,12 +84,13 @@ func NewDebugger() *Debugger {
 	slog.SetDefault(logger)
 
 	// Load configuration
-	cfg := appconfig.MustLoad()
+	cfg := appconfig.MustLoad(appconfig.GroupTopicRouting)
 
 	logger.Info("scheduler lambda starting",
 		slog.String("stage", cfg.Stage.String()),
 		slog.String("region", cfg.AWSRegion),
 	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
 
 	// Initialize AWS SDK
 	awsCfg, err := config.LoadDefaultConfig(context.Background(),
@@ -112,13 +113,23 @@ func NewDebugger() *Debugger {
 	scheduleRepo := repository.NewDynamoDBScheduleRepository(dynamoClient, cfg.SchedulesTableName)
 
 	// Create publisher
-	publisher := messaging.NewTopicRoutingSNSClient(snsClient, cfg.WebActionsSNSTopicArn, cfg.NotificationsSNSTopicArn, cfg.AgentResponseTopicArn, cfg.ScheduleCreationTopicArn, logger)
+	routes, err := messaging.NewTopicRoutingTable(cfg.TopicRoutes, cfg.NotificationsSNSTopicArn)
+	if err != nil {
+		logger.Error("invalid topic routing configuration", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("invalid topic routing configuration: %v", err))
+	}
+	publisher := messaging.NewTopicRoutingSNSClient(snsClient, routes, logger)
+	publisher.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentScheduleCreation)
 
 	// Create EventBridge Scheduler service
 	ebScheduler := internalscheduler.NewAWSEventBridgeScheduler(schedulerClient, cfg.EventBridgeExecutionRoleArn)
 
 	// Create HTTP client and secrets manager for agent event handler
 	httpClient := httpclient.NewClient(logger)
+	httpClient.SetCircuitBreaker(httpclient.NewCircuitBreaker(httpclient.CircuitBreakerConfig{
+		FailureThreshold: cfg.CircuitBreakerFailureThreshold,
+		OpenTimeout:      cfg.CircuitBreakerOpenTimeout,
+	}, logger))
 	secretsManager := secrets.NewManager(awsCfg, logger)
 
 	// Create agent logger for S3 logging (optional for debug)
@@ -139,6 +150,7 @@ func NewDebugger() *Debugger {
 
 	// Initialize SQS processor
 	sqsProcessor := messaging.NewSQSBatchProcessor(logger)
+	sqsProcessor.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentScheduleCreation)
 
 	// Create handler
 	handler := internalscheduler.NewSchedulerHandler(
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/jrzesz33/rez_agent/internal/digest"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/notification"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/internal/scheduler"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// DigestHandler emails the weekly digest when a weekly_digest schedule fires
+type DigestHandler struct {
+	digest         *digest.Service
+	scheduleRepo   repository.ScheduleRepository
+	batchProcessor *messaging.SQSBatchProcessor
+	logger         *slog.Logger
+}
+
+// NewDigestHandler creates a new digest handler instance
+func NewDigestHandler(cfg *appconfig.Config, service *digest.Service, scheduleRepo repository.ScheduleRepository, logger *slog.Logger) *DigestHandler {
+	batchProcessor := messaging.NewSQSBatchProcessor(logger)
+	batchProcessor.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentDigest)
+	batchProcessor.SetLatencyRecorder(metrics.NewLatencyRecorder(os.Stdout, cfg.Stage.String(), metrics.DefaultSLOs))
+	batchProcessor.SetRecordTimeout(cfg.SQSRecordTimeout)
+	// Triggers that arrive together would send the same digest twice; one at a time keeps it simple
+	batchProcessor.SetConcurrency(1)
+
+	return &DigestHandler{
+		digest:         service,
+		scheduleRepo:   scheduleRepo,
+		batchProcessor: batchProcessor,
+		logger:         logger,
+	}
+}
+
+// HandleEvent processes SQS events
+func (h *DigestHandler) HandleEvent(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	response, err := h.batchProcessor.ProcessBatch(ctx, event, h.processMessage)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "batch processing encountered errors",
+			slog.String("error", err.Error()),
+			slog.Int("failure_count", len(response.BatchItemFailures)),
+		)
+	}
+	return response, nil
+}
+
+// processMessage sends the digest for one weekly_digest trigger
+func (h *DigestHandler) processMessage(ctx context.Context, message *models.Message) error {
+	if err := h.digest.Send(ctx, time.Now()); err != nil {
+		return fmt.Errorf("failed to send weekly digest: %w", err)
+	}
+
+	scheduleID := scheduler.ScheduleIDFromArguments(message.Arguments)
+	if err := scheduler.RecordScheduleExecution(ctx, h.scheduleRepo, scheduleID, h.logger); err != nil {
+		// The digest went out; a retry would send it again
+		h.logger.ErrorContext(ctx, "failed to record schedule execution",
+			slog.String("schedule_id", scheduleID),
+			slog.String("error", err.Error()),
+		)
+	}
+	return nil
+}
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad(appconfig.GroupDigest)
+
+	logger.Info("digest lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+		slog.String("region", cfg.AWSRegion),
+	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+
+	// Create AWS clients
+	dynamoClient := dynamodb.NewFromConfig(awsCfg)
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		// LocalStack serves buckets by path rather than by virtual host
+		o.UsePathStyle = cfg.Local
+	})
+
+	// Build the report from messages, and from run recordings and synced reservations when available
+	builder := digest.NewBuilder(repository.NewDynamoDBRepository(dynamoClient, cfg.DynamoDBTableName), cfg.Stage, logger)
+	if agentLogsBucket := os.Getenv("AGENT_LOGS_BUCKET"); agentLogsBucket != "" {
+		builder.SetRunRecordings(scheduler.NewRunSummaryPublisher(s3Client, agentLogsBucket, cfg.Stage.String()))
+	}
+	if cfg.ReservationsTableName != "" {
+		config, err := courses.LoadCourses()
+		if err != nil {
+			logger.Error("failed to load courses", slog.String("error", err.Error()))
+			panic(fmt.Sprintf("failed to load courses: %v", err))
+		}
+		// The digest covers the shared credentials; members' reservations stay private to them
+		secretNames := make([]string, 0, len(config.Courses))
+		seen := make(map[string]bool)
+		for _, course := range config.Courses {
+			if secretName := course.GetSecretName(cfg.Stage.String()); !seen[secretName] {
+				seen[secretName] = true
+				secretNames = append(secretNames, secretName)
+			}
+		}
+		builder.SetReservations(repository.NewDynamoDBReservationRepository(dynamoClient, cfg.ReservationsTableName), secretNames)
+	}
+
+	email := notification.NewSESEmailClient(sesv2.NewFromConfig(awsCfg), cfg.EmailFromAddress)
+	service := digest.NewService(builder, email, cfg.DigestRecipients, logger)
+	if modelID := os.Getenv("BEDROCK_MODEL_ID"); modelID != "" {
+		service.SetNarrator(digest.NewBedrockNarrator(bedrockruntime.NewFromConfig(awsCfg), modelID))
+	} else {
+		logger.Warn("BEDROCK_MODEL_ID not configured, digest narrative disabled")
+	}
+
+	// Create handler
+	handler := NewDigestHandler(cfg, service, repository.NewDynamoDBScheduleRepository(dynamoClient, cfg.SchedulesTableName), logger)
+	handler.batchProcessor.SetEventPublisher(localrun.NewEventPublisher(cfg, awsCfg, logger))
+
+	// Start Lambda handler (or, in local mode, poll the digest queue)
+	localrun.StartSQS(cfg, localrun.DigestAddr, handler.HandleEvent, sqs.NewFromConfig(awsCfg), cfg.DigestSQSQueueURL, logger)
+}
@@ -0,0 +1,46 @@
+// Command mcp-stdio runs the rez_agent MCP server locally over stdio, with the same tools as the
+// MCP Lambda, so desktop MCP clients can use them without deploying or exposing the API. Tools
+// reach AWS with the local credentials and the settings in the environment.
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/mcp/toolset"
+	"github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+func main() {
+	// Stdout carries the protocol, so logs go to stderr
+	logger := slog.New(logging.NewContextHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+
+	cfg, err := config.LoadFor(config.GroupPushNotifications)
+	if err != nil {
+		logger.Error("failed to load configuration", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	awsCfg, err := cfg.LoadAWSConfig(ctx)
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	mcpServer, err := toolset.NewServer(cfg, awsCfg, "mcp-stdio", logger)
+	if err != nil {
+		logger.Error("failed to create MCP server", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	logger.Info("MCP stdio server ready", slog.String("stage", cfg.Stage.String()))
+	if err := mcpServer.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil {
+		logger.Error("MCP stdio server stopped", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
@@ -4,44 +4,50 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"net/http"
 	"os"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-lambda-go/lambda"
-	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 
-	"github.com/jrzesz33/rez_agent/internal/httpclient"
+	"github.com/jrzesz33/rez_agent/internal/compress"
+	"github.com/jrzesz33/rez_agent/internal/cors"
+	"github.com/jrzesz33/rez_agent/internal/health"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
 	"github.com/jrzesz33/rez_agent/internal/logging"
-	"github.com/jrzesz33/rez_agent/internal/mcp/server"
-	"github.com/jrzesz33/rez_agent/internal/mcp/tools"
-	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/internal/mcp/toolset"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/kit/mcp/server"
 	"github.com/jrzesz33/rez_agent/pkg/config"
 )
 
 type Handler struct {
-	mcpServer *server.MCPServer
-	logger    *slog.Logger
-	apiKey    string
+	mcpServer    *server.MCPServer
+	logger       *slog.Logger
+	apiKey       string
+	stage        string
+	healthChecks []health.Check
 }
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: logging.GetLogLevel(),
-	}))
+	})))
 
 	logger.Info("MCP Lambda Function Starting...")
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.LoadFor(config.GroupPushNotifications)
 	if err != nil {
 		logger.Error("failed to load configuration", slog.String("error", err.Error()))
 		panic(err)
 	}
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
 
 	// Initialize AWS SDK
-	awsCfg, err := awsConfig.LoadDefaultConfig(context.Background(),
-		awsConfig.WithRegion(cfg.AWSRegion),
-	)
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
 	if err != nil {
 		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
 		panic(err)
@@ -49,65 +55,14 @@ func main() {
 
 	logger.Info("MCP Lambda initialized configuration")
 
-	// Create MCP server
-	serverName := os.Getenv("MCP_SERVER_NAME")
-	if serverName == "" {
-		serverName = "rez-agent-mcp"
-	}
-
-	serverVersion := os.Getenv("MCP_SERVER_VERSION")
-	if serverVersion == "" {
-		serverVersion = "1.0.0"
-	}
-
-	mcpServer := server.NewMCPServer(serverName, serverVersion, logger)
-
-	// Initialize dependencies
-	httpClient := httpclient.NewClient(logger)
-	secretsManager := secrets.NewManager(awsCfg, logger)
-	oauthClient := httpclient.NewOAuthClient(httpClient, secretsManager, logger)
-
-	// Register MCP tools
-	logger.Info("registering MCP tools...")
-
-	// 1. Notification tool
-	notificationTool := tools.NewNotificationTool(cfg.NtfyURL, logger)
-	if err := mcpServer.RegisterTool(notificationTool); err != nil {
-		logger.Error("failed to register notification tool", slog.String("error", err.Error()))
-		panic(err)
-	}
-
-	// 2. Weather tool
-	weatherTool := tools.NewWeatherTool(httpClient, logger)
-	if err := mcpServer.RegisterTool(weatherTool); err != nil {
-		logger.Error("failed to register weather tool", slog.String("error", err.Error()))
-		panic(err)
-	}
-
-	// 3. Golf reservations tool
-	golfReservationsTool := tools.NewGolfReservationsTool(httpClient, oauthClient, secretsManager, logger)
-	if err := mcpServer.RegisterTool(golfReservationsTool); err != nil {
-		logger.Error("failed to register golf reservations tool", slog.String("error", err.Error()))
-		panic(err)
-	}
-
-	// 4. Golf search tee times tool
-	golfSearchTool := tools.NewGolfSearchTeeTimesTool(httpClient, oauthClient, secretsManager, logger)
-	if err := mcpServer.RegisterTool(golfSearchTool); err != nil {
-		logger.Error("failed to register golf search tool", slog.String("error", err.Error()))
-		panic(err)
-	}
-
-	// 5. Golf book tee time tool
-	golfBookTool := tools.NewGolfBookTeeTimeTool(httpClient, oauthClient, secretsManager, logger)
-	if err := mcpServer.RegisterTool(golfBookTool); err != nil {
-		logger.Error("failed to register golf book tool", slog.String("error", err.Error()))
+	mcpServer, err := toolset.NewServer(cfg, awsCfg, "mcp", logger)
+	if err != nil {
+		logger.Error("failed to create MCP server", slog.String("error", err.Error()))
 		panic(err)
 	}
-
-	logger.Info("MCP server initialized successfully",
-		slog.Int("tool_count", 5),
-	)
+	// Tool call metrics reach CloudWatch through the Lambda's stdout. The API key is checked per
+	// HTTP request below, before any method runs.
+	mcpServer.Use(server.Metrics(metrics.NewToolCallRecorder(os.Stdout, cfg.Stage.String(), logger)))
 
 	// Get API key from environment (for authentication)
 	apiKey := os.Getenv("MCP_API_KEY")
@@ -115,28 +70,50 @@ func main() {
 		logger.Warn("MCP_API_KEY not set, authentication disabled")
 	}
 
+	// GET /mcp/health checks the messages table and the golf credentials the tools depend on
+	healthChecks := []health.Check{
+		health.DynamoDBTable("dynamodb", dynamodb.NewFromConfig(awsCfg), cfg.DynamoDBTableName),
+	}
+	if !cfg.Local {
+		healthChecks = append(healthChecks, health.Secret("secrets_manager", secretsmanager.NewFromConfig(awsCfg), cfg.GolfSecretName))
+	}
+
 	handler := &Handler{
-		mcpServer: mcpServer,
-		logger:    logger,
-		apiKey:    apiKey,
+		mcpServer:    mcpServer,
+		logger:       logger,
+		apiKey:       apiKey,
+		stage:        cfg.Stage.String(),
+		healthChecks: healthChecks,
 	}
 
-	lambda.Start(handler.HandleAPIGatewayRequest)
+	// Browser MCP clients may connect from the stage's allowed origins; large results, such as tee
+	// time searches, are gzipped for clients that accept it
+	policy := cors.NewPolicy(cfg, http.MethodGet, http.MethodPost)
+	localrun.StartAPI(cfg, localrun.MCPAddr, policy.Handler(compress.Handler(cfg.CompressionThreshold, handler.HandleAPIGatewayRequest)), logger)
 }
 
 // HandleAPIGatewayRequest processes API Gateway HTTP API requests
 func (h *Handler) HandleAPIGatewayRequest(ctx context.Context, event events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
-	h.logger.Info("received MCP request",
+	// Agent runs send their correlation ID so their tool calls can be found in these logs;
+	// API Gateway lowercases header names
+	ctx = logging.WithCorrelationID(ctx, logging.IncomingCorrelationID(event.Headers["x-correlation-id"]))
+
+	h.logger.InfoContext(ctx, "received MCP request",
 		slog.String("path", event.RawPath),
 		slog.String("method", event.RequestContext.HTTP.Method),
 		slog.String("request_id", event.RequestContext.RequestID),
 	)
 
+	// Uptime monitors call the health check without an API key
+	if event.RequestContext.HTTP.Method == "GET" && event.RawPath == "/mcp/health" {
+		return h.handleHealth(ctx)
+	}
+
 	// Validate API key if configured
 	if h.apiKey != "" {
 		providedKey := event.Headers["x-api-key"]
 		if providedKey != h.apiKey {
-			h.logger.Warn("invalid API key provided",
+			h.logger.WarnContext(ctx, "invalid API key provided",
 				slog.String("remote_addr", event.RequestContext.HTTP.SourceIP),
 			)
 			return events.APIGatewayV2HTTPResponse{
@@ -149,10 +126,16 @@ func (h *Handler) HandleAPIGatewayRequest(ctx context.Context, event events.APIG
 		}
 	}
 
+	// Only a caller holding the API key may act for a user; without a key every call uses the
+	// shared golf credentials
+	if h.apiKey != "" {
+		ctx = models.WithActingUser(ctx, event.Headers["x-acting-user"])
+	}
+
 	// Handle JSON-RPC request
 	responseBody, err := h.mcpServer.HandleRequest(ctx, []byte(event.Body))
 	if err != nil {
-		h.logger.Error("failed to handle MCP request",
+		h.logger.ErrorContext(ctx, "failed to handle MCP request",
 			slog.String("error", err.Error()),
 			slog.String("request_id", event.RequestContext.RequestID),
 		)
@@ -178,10 +161,15 @@ func (h *Handler) HandleAPIGatewayRequest(ctx context.Context, event events.APIG
 		}, nil
 	}
 
-	h.logger.Info("MCP request completed successfully",
+	h.logger.InfoContext(ctx, "MCP request completed successfully",
 		slog.String("request_id", event.RequestContext.RequestID),
 	)
 
+	// Notifications, such as cancellations, get no JSON-RPC response
+	if len(responseBody) == 0 {
+		return events.APIGatewayV2HTTPResponse{StatusCode: 202}, nil
+	}
+
 	return events.APIGatewayV2HTTPResponse{
 		StatusCode: 200,
 		Headers: map[string]string{
@@ -190,3 +178,25 @@ func (h *Handler) HandleAPIGatewayRequest(ctx context.Context, event events.APIG
 		Body: string(responseBody),
 	}, nil
 }
+
+// handleHealth reports whether the MCP server's dependencies are reachable: 200 when they all
+// are, 503 with the failing components otherwise
+func (h *Handler) handleHealth(ctx context.Context) (events.APIGatewayV2HTTPResponse, error) {
+	report := health.Run(ctx, "mcp", h.stage, health.DefaultTimeout, h.healthChecks)
+	if report.Status != health.StatusHealthy {
+		h.logger.WarnContext(ctx, "MCP health check failed", slog.Any("components", report.Components))
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return events.APIGatewayV2HTTPResponse{StatusCode: 500}, err
+	}
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: report.HTTPStatus(),
+		Headers: map[string]string{
+			"Content-Type":  "application/json",
+			"Cache-Control": "no-store",
+		},
+		Body: string(body),
+	}, nil
+}
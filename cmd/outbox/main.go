@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/outbox"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad(appconfig.GroupTopicRouting)
+
+	logger.Info("outbox relay lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+
+	// Route message types to topics from the configured routing table. Writers check messages
+	// against the registry before saving them, so the relay publishes them as they are.
+	routes, err := messaging.NewTopicRoutingTable(cfg.TopicRoutes, cfg.NotificationsSNSTopicArn)
+	if err != nil {
+		logger.Error("invalid topic routing configuration", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("invalid topic routing configuration: %v", err))
+	}
+	publisher := messaging.NewTopicRoutingSNSClient(sns.NewFromConfig(awsCfg), routes, logger)
+	publisher.SetEventPublisher(localrun.NewEventPublisher(cfg, awsCfg, logger))
+
+	relay := outbox.NewRelay(repository.NewDynamoDBRepository(dynamodb.NewFromConfig(awsCfg), cfg.DynamoDBTableName), publisher, logger)
+
+	// Start Lambda handler for the messages table's stream
+	localrun.Start(cfg, localrun.OutboxAddr, relay.HandleEvent, logger)
+}
@@ -8,11 +8,12 @@ import (
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
 	"github.com/jrzesz33/rez_agent/internal/logging"
 	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
 	"github.com/jrzesz33/rez_agent/internal/models"
 	"github.com/jrzesz33/rez_agent/internal/notification"
 	"github.com/jrzesz33/rez_agent/internal/repository"
@@ -35,11 +36,17 @@ func NewProcessorHandler(
 	notifClient notification.Client,
 	logger *slog.Logger,
 ) *ProcessorHandler {
+	batchProcessor := messaging.NewSQSBatchProcessor(logger)
+	batchProcessor.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentProcessor)
+	batchProcessor.SetLatencyRecorder(metrics.NewLatencyRecorder(os.Stdout, cfg.Stage.String(), metrics.DefaultSLOs))
+	batchProcessor.SetRecordTimeout(cfg.SQSRecordTimeout)
+	batchProcessor.SetConcurrency(cfg.SQSBatchConcurrency)
+
 	return &ProcessorHandler{
 		config:             cfg,
 		repository:         repo,
 		notificationClient: notifClient,
-		batchProcessor:     messaging.NewSQSBatchProcessor(logger),
+		batchProcessor:     batchProcessor,
 		logger:             logger,
 	}
 }
@@ -76,9 +83,14 @@ func (h *ProcessorHandler) processMessage(ctx context.Context, message *models.M
 		slog.String("current_status", message.Status.String()),
 	)
 
+	notificationPayload, err := models.DecodePayload[*models.NotificationPayload](message)
+	if err != nil {
+		return fmt.Errorf("failed to decode notification payload: %w", err)
+	}
+
 	// Mark message as processing
 	message.MarkProcessing()
-	err := h.repository.UpdateStatus(ctx, message.ID, message.Status, "")
+	err = h.repository.UpdateStatus(ctx, message.ID, message.Status, "")
 	if err != nil {
 		h.logger.ErrorContext(ctx, "failed to update status to processing",
 			slog.String("message_id", message.ID),
@@ -89,7 +101,7 @@ func (h *ProcessorHandler) processMessage(ctx context.Context, message *models.M
 
 	// Send notification to ntfy.sh
 	notificationTitle := fmt.Sprintf("Rez Agent - %s", h.config.Stage.String())
-	err = h.notificationClient.(*notification.NtfyClient).SendWithTitle(ctx, notificationTitle, message.Payload["message"].(string))
+	err = h.notificationClient.(*notification.NtfyClient).SendWithTitle(ctx, notificationTitle, notificationPayload.Message)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "failed to send notification",
 			slog.String("message_id", message.ID),
@@ -136,23 +148,22 @@ func (h *ProcessorHandler) processMessage(ctx context.Context, message *models.M
 
 func main() {
 	// Setup structured logging
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: logging.GetLogLevel(),
-	}))
+	})))
 	slog.SetDefault(logger)
 
 	// Load configuration
-	cfg := appconfig.MustLoad()
+	cfg := appconfig.MustLoad(appconfig.GroupPushNotifications)
 
 	logger.Info("processor lambda starting",
 		slog.String("stage", cfg.Stage.String()),
 		slog.String("region", cfg.AWSRegion),
 	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
 
 	// Initialize AWS SDK
-	awsCfg, err := config.LoadDefaultConfig(context.Background(),
-		config.WithRegion(cfg.AWSRegion),
-	)
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
 	if err != nil {
 		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
 		panic(fmt.Sprintf("failed to load AWS config: %v", err))
@@ -174,7 +185,8 @@ func main() {
 
 	// Create handler
 	handler := NewProcessorHandler(cfg, repo, notifClient, logger)
+	handler.batchProcessor.SetEventPublisher(localrun.NewEventPublisher(cfg, awsCfg, logger))
 
-	// Start Lambda handler
-	lambda.Start(handler.HandleEvent)
+	// Start Lambda handler (or, in local mode, poll the notification queue)
+	localrun.StartSQS(cfg, localrun.ProcessorAddr, handler.HandleEvent, sqs.NewFromConfig(awsCfg), cfg.NotificationSQSQueueURL, logger)
 }
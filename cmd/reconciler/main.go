@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awsscheduler "github.com/aws/aws-sdk-go-v2/service/scheduler"
+
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	internalscheduler "github.com/jrzesz33/rez_agent/internal/scheduler"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad()
+
+	logger.Info("schedule reconciler lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+		slog.Bool("repair", cfg.ScheduleReconcileRepair),
+	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+	dynamoClient := dynamodb.NewFromConfig(awsCfg)
+
+	reconciler := internalscheduler.NewReconciler(
+		repository.NewDynamoDBScheduleRepository(dynamoClient, cfg.SchedulesTableName),
+		awsscheduler.NewFromConfig(awsCfg),
+		cfg.Stage,
+		logger,
+	)
+	reconciler.SetRecorder(metrics.NewReconcileRecorder(os.Stdout, cfg.Stage.String(), logger))
+	reconciler.SetAuditRecorder(audit.NewRecorder(repository.NewDynamoDBAuditRepository(dynamoClient, cfg.AuditTableName), "reconciler", logger))
+	if cfg.SchedulerFunctionArn != "" {
+		reconciler.SetExecutionTarget(cfg.SchedulerFunctionArn)
+	}
+
+	// Start Lambda handler; the EventBridge rule's event carries nothing the reconciliation needs
+	localrun.Start(cfg, localrun.ReconcilerAddr, func(ctx context.Context) (internalscheduler.ReconcileReport, error) {
+		return reconciler.Reconcile(ctx, cfg.ScheduleReconcileRepair)
+	}, logger)
+}
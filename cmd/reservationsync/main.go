@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/oauth"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/internal/webaction"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// SyncRequest is the invocation payload. The EventBridge schedule sends its own event, which
+// has none of these fields, so a scheduled run syncs every course.
+type SyncRequest struct {
+	// CourseIDs limits the sync to the given courses
+	CourseIDs []int `json:"course_ids,omitempty"`
+}
+
+// SyncResponse counts the golfer and course pairs synced and failed
+type SyncResponse struct {
+	Synced int `json:"synced"`
+	Failed int `json:"failed"`
+}
+
+// SyncHandler copies each golfer's upcoming reservations at each course into the reservations
+// table, so reservations can be answered without calling the golf provider
+type SyncHandler struct {
+	golf   *webaction.GolfHandler
+	users  repository.UserRepository
+	stage  string
+	logger *slog.Logger
+}
+
+// HandleRequest runs one sync. One golfer or course failing does not stop the others; the run
+// fails only if nothing synced, so a provider outage shows up as a failed invocation.
+func (h *SyncHandler) HandleRequest(ctx context.Context, request SyncRequest) (SyncResponse, error) {
+	config, err := courses.LoadCourses()
+	if err != nil {
+		return SyncResponse{}, err
+	}
+	secretNames, err := h.secretNames(ctx, config.Courses)
+	if err != nil {
+		return SyncResponse{}, err
+	}
+
+	var response SyncResponse
+	var errs []error
+	for _, course := range config.Courses {
+		if len(request.CourseIDs) > 0 && !slices.Contains(request.CourseIDs, course.CourseID) {
+			continue
+		}
+		for _, secretName := range secretNames {
+			payload := &models.WebActionPayload{
+				Version:  "1.0",
+				Action:   models.WebActionTypeGolf,
+				CourseID: course.CourseID,
+				AuthConfig: &models.AuthConfig{
+					Type:       models.AuthTypeOAuthPassword,
+					SecretName: secretName,
+				},
+			}
+			if _, err := h.golf.Execute(ctx, map[string]interface{}{"operation": "sync_reservations"}, payload); err != nil {
+				h.logger.ErrorContext(ctx, "reservation sync failed",
+					slog.Int("course_id", course.CourseID),
+					slog.String("error", err.Error()),
+				)
+				errs = append(errs, fmt.Errorf("course %d: %w", course.CourseID, err))
+				response.Failed++
+				continue
+			}
+			response.Synced++
+		}
+	}
+
+	h.logger.InfoContext(ctx, "reservation sync completed",
+		slog.Int("synced", response.Synced),
+		slog.Int("failed", response.Failed),
+	)
+	if response.Synced == 0 && len(errs) > 0 {
+		return response, errors.Join(errs...)
+	}
+	return response, nil
+}
+
+// secretNames returns the golf credentials to sync: the courses' shared credentials and, in
+// multi-user mode, each enabled user's own
+func (h *SyncHandler) secretNames(ctx context.Context, all []courses.Course) ([]string, error) {
+	var names []string
+	for _, course := range all {
+		if name := course.GetSecretName(h.stage); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if h.users == nil {
+		return names, nil
+	}
+
+	users, err := h.users.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if user.Disabled || user.GolfSecretName == "" || slices.Contains(names, user.GolfSecretName) {
+			continue
+		}
+		names = append(names, user.GolfSecretName)
+	}
+	return names, nil
+}
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad(appconfig.GroupReservationSync)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	logger.Info("reservation sync lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+	)
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+	dynamoClient := dynamodb.NewFromConfig(awsCfg)
+
+	httpClient := httpclient.NewClient(logger)
+	httpClient.SetCircuitBreaker(httpclient.NewCircuitBreaker(httpclient.CircuitBreakerConfig{
+		FailureThreshold: cfg.CircuitBreakerFailureThreshold,
+		OpenTimeout:      cfg.CircuitBreakerOpenTimeout,
+	}, logger))
+	secretsManager := localrun.NewSecretsManager(cfg, awsCfg, logger)
+	secretsManager.SetAccessRecorder(audit.NewRecorder(repository.NewDynamoDBAuditRepository(dynamoClient, cfg.AuditTableName), "reservationsync", logger).SecretAccessed)
+	oauthClient := oauth.NewClient(httpClient, secretsManager, logger)
+	tokenCache := oauth.NewTokenCache(cfg.OAuthTokenRefreshBefore, logger)
+	if cfg.OAuthTokenTableName != "" {
+		tokenCache.SetStore(repository.NewDynamoDBOAuthTokenRepository(dynamoClient, cfg.OAuthTokenTableName))
+	}
+	oauthClient.SetTokenCache(tokenCache)
+
+	golfHandler := webaction.NewGolfHandler(httpClient, oauthClient, secretsManager, logger)
+	golfHandler.SetReservationCache(repository.NewDynamoDBReservationRepository(dynamoClient, cfg.ReservationsTableName))
+
+	handler := &SyncHandler{
+		golf:   golfHandler,
+		stage:  cfg.Stage.String(),
+		logger: logger,
+	}
+	if cfg.UsersTableName != "" {
+		handler.users = repository.NewDynamoDBUserRepository(dynamoClient, cfg.UsersTableName)
+	}
+
+	// Start Lambda handler
+	localrun.Start(cfg, localrun.ReservationSyncAddr, handler.HandleRequest, logger)
+}
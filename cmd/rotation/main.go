@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/oauth"
+	"github.com/jrzesz33/rez_agent/internal/rotation"
+	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// loginVerifier checks credentials with a password grant against the first course. All courses
+// share one set of credentials, so one login proves them.
+func loginVerifier(client *oauth.Client) (rotation.Verifier, error) {
+	config, err := courses.LoadCourses()
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Courses) == 0 {
+		return nil, fmt.Errorf("no courses configured")
+	}
+	course := config.Courses[0]
+
+	tokenURL, err := course.GetActionURL("token-url")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token URL from course config: %w", err)
+	}
+	headers := map[string]string{
+		"accept":     "application/json, text/plain, */*",
+		"client-id":  course.ClientID,
+		"origin":     course.Origin,
+		"user-agent": "Mozilla/5.0 (compatible; rez-agent/1.0)",
+	}
+
+	return func(ctx context.Context, creds *secrets.OAuthCredentials) error {
+		_, err := client.RequestToken(ctx, tokenURL, creds, course.Scope, headers)
+		return err
+	}, nil
+}
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad()
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	logger.Info("rotation lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+	)
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+
+	// The verifier never reads through the manager; pending credentials are passed in directly
+	oauthClient := oauth.NewClient(httpclient.NewClient(logger), secrets.NewManager(awsCfg, logger), logger)
+	verify, err := loginVerifier(oauthClient)
+	if err != nil {
+		logger.Error("failed to configure login check", slog.String("error", err.Error()))
+		panic(err)
+	}
+
+	rotator := rotation.NewRotator(secretsmanager.NewFromConfig(awsCfg), verify, logger)
+
+	// Start Lambda handler
+	localrun.Start(cfg, localrun.RotationAddr, rotator.Handle, logger)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	internalscheduler "github.com/jrzesz33/rez_agent/internal/scheduler"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad(appconfig.GroupTopicRouting, appconfig.GroupWebActions, appconfig.GroupScheduling)
+
+	logger.Info("schedule creation lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+		slog.String("region", cfg.AWSRegion),
+	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+
+	// Create AWS clients
+	dynamoClient := dynamodb.NewFromConfig(awsCfg)
+	snsClient := sns.NewFromConfig(awsCfg)
+	schedulerClient := scheduler.NewFromConfig(awsCfg)
+
+	// Create repositories
+	messageRepo := repository.NewDynamoDBRepository(dynamoClient, cfg.DynamoDBTableName)
+	scheduleRepo := repository.NewDynamoDBScheduleRepository(dynamoClient, cfg.SchedulesTableName)
+
+	// Create publisher
+	routes, err := messaging.NewTopicRoutingTable(cfg.TopicRoutes, cfg.NotificationsSNSTopicArn)
+	if err != nil {
+		logger.Error("invalid topic routing configuration", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("invalid topic routing configuration: %v", err))
+	}
+	publisher := messaging.NewTopicRoutingSNSClient(snsClient, routes, logger)
+	publisher.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentScheduleCreation)
+
+	// Domain events go to the event bus, when one is configured
+	eventPublisher := localrun.NewEventPublisher(cfg, awsCfg, logger)
+	publisher.SetEventPublisher(eventPublisher)
+
+	// Initialize SQS processor
+	sqsProcessor := messaging.NewSQSBatchProcessor(logger)
+	sqsProcessor.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentScheduleCreation)
+	sqsProcessor.SetLatencyRecorder(metrics.NewLatencyRecorder(os.Stdout, cfg.Stage.String(), metrics.DefaultSLOs))
+	sqsProcessor.SetRecordTimeout(cfg.SQSRecordTimeout)
+	sqsProcessor.SetConcurrency(cfg.SQSBatchConcurrency)
+	sqsProcessor.SetEventPublisher(eventPublisher)
+
+	// Create EventBridge Scheduler service; schedule changes are recorded in the audit log
+	auditRecorder := audit.NewRecorder(repository.NewDynamoDBAuditRepository(dynamoClient, cfg.AuditTableName), "schedulecreation", logger)
+	var ebScheduler internalscheduler.EventBridgeScheduler = internalscheduler.NewAWSEventBridgeScheduler(schedulerClient, cfg.EventBridgeExecutionRoleArn)
+	if cfg.SchedulerFunctionArn != "" {
+		// Agent runs are executed by the scheduler Lambda, not this one
+		ebScheduler = internalscheduler.NewExecutionTargetScheduler(ebScheduler, cfg.SchedulerFunctionArn)
+	} else {
+		logger.Warn("SCHEDULER_FUNCTION_ARN not configured, agent run schedules keep their built target")
+	}
+	ebScheduler = internalscheduler.NewAuditingScheduler(ebScheduler, auditRecorder)
+
+	// Create handler; scheduled agent runs invoke the scheduler Lambda, so this one never runs them
+	handler := internalscheduler.NewSchedulerHandler(cfg, messageRepo, scheduleRepo, publisher, ebScheduler, sqsProcessor, logger, nil)
+
+	// Start Lambda handler (or, in local mode, poll the schedule creation queue)
+	localrun.StartSQS(cfg, localrun.ScheduleCreationAddr, handler.HandleEvent, sqs.NewFromConfig(awsCfg), cfg.ScheduleCreationQueueURL, logger)
+}
@@ -6,41 +6,39 @@ import (
 	"log/slog"
 	"os"
 
-	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/scheduler"
-	"github.com/aws/aws-sdk-go-v2/service/sns"
-	"github.com/jrzesz33/rez_agent/internal/httpclient"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/jrzesz33/rez_agent/internal/a2a"
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
 	"github.com/jrzesz33/rez_agent/internal/logging"
-	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/prompts"
 	"github.com/jrzesz33/rez_agent/internal/repository"
 	internalscheduler "github.com/jrzesz33/rez_agent/internal/scheduler"
-	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
 	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
 )
 
 func main() {
 	// Setup structured logging
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: logging.GetLogLevel(),
-	}))
+	})))
 	slog.SetDefault(logger)
 
-	// Load configuration
+	// Load configuration; schedules are created by the schedule creation Lambda
 	cfg := appconfig.MustLoad()
 
 	logger.Info("scheduler lambda starting",
 		slog.String("stage", cfg.Stage.String()),
 		slog.String("region", cfg.AWSRegion),
 	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
 
 	// Initialize AWS SDK
-	awsCfg, err := config.LoadDefaultConfig(context.Background(),
-		config.WithRegion(cfg.AWSRegion),
-	)
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
 	if err != nil {
 		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
 		panic(fmt.Sprintf("failed to load AWS config: %v", err))
@@ -48,27 +46,25 @@ func main() {
 
 	// Create AWS clients
 	dynamoClient := dynamodb.NewFromConfig(awsCfg)
-	snsClient := sns.NewFromConfig(awsCfg)
-	schedulerClient := scheduler.NewFromConfig(awsCfg)
 	bedrockClient := bedrockruntime.NewFromConfig(awsCfg)
-	s3Client := s3.NewFromConfig(awsCfg)
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		// LocalStack serves buckets by path rather than by virtual host
+		o.UsePathStyle = cfg.Local
+	})
 
 	// Create repositories
-	messageRepo := repository.NewDynamoDBRepository(dynamoClient, cfg.DynamoDBTableName)
 	scheduleRepo := repository.NewDynamoDBScheduleRepository(dynamoClient, cfg.SchedulesTableName)
 
-	// Create publisher
-	publisher := messaging.NewTopicRoutingSNSClient(snsClient, cfg.WebActionsSNSTopicArn, cfg.NotificationsSNSTopicArn, cfg.AgentResponseTopicArn, cfg.ScheduleCreationTopicArn, logger)
-
-	// Initialize SQS processor
-	sqsProcessor := messaging.NewSQSBatchProcessor(logger)
-
-	// Create EventBridge Scheduler service
-	ebScheduler := internalscheduler.NewAWSEventBridgeScheduler(schedulerClient, cfg.EventBridgeExecutionRoleArn)
-
-	// Create HTTP client and secrets manager for agent event handler
+	// Create HTTP client and secrets manager for agent event handler; secret reads are recorded
+	// in the audit log
+	auditRecorder := audit.NewRecorder(repository.NewDynamoDBAuditRepository(dynamoClient, cfg.AuditTableName), "scheduler", logger)
 	httpClient := httpclient.NewClient(logger)
-	secretsManager := secrets.NewManager(awsCfg, logger)
+	httpClient.SetCircuitBreaker(httpclient.NewCircuitBreaker(httpclient.CircuitBreakerConfig{
+		FailureThreshold: cfg.CircuitBreakerFailureThreshold,
+		OpenTimeout:      cfg.CircuitBreakerOpenTimeout,
+	}, logger))
+	secretsManager := localrun.NewSecretsManager(cfg, awsCfg, logger)
+	secretsManager.SetAccessRecorder(auditRecorder.SecretAccessed)
 
 	// Create agent logger for S3 logging
 	agentLogsBucket := os.Getenv("AGENT_LOGS_BUCKET")
@@ -91,10 +87,40 @@ func main() {
 		agentLogger,
 		logger,
 	)
+	agentHandler.SetWeatherDecisionRepository(
+		repository.NewDynamoDBWeatherDecisionRepository(dynamoClient, cfg.WeatherDecisionsTableName),
+	)
+	agentHandler.SetPreferenceRepository(
+		repository.NewDynamoDBPreferenceRepository(dynamoClient, cfg.PreferencesTableName),
+	)
+	if len(cfg.A2AAgents) > 0 {
+		agentHandler.SetRemoteAgents(a2a.NewClient(httpClient, secretsManager, "rez-agent-scheduler", logger), cfg.A2AAgents)
+		logger.Info("remote agents configured", slog.Int("count", len(cfg.A2AAgents)))
+	}
+	agentHandler.SetGuardrails(cfg.AgentGuardrails)
+	if cfg.PromptParameterPrefix != "" {
+		agentHandler.SetPromptStore(prompts.NewStore(
+			prompts.NewSSMOverrideSource(ssm.NewFromConfig(awsCfg), cfg.PromptParameterPrefix),
+			logger,
+		))
+		logger.Info("prompt template overrides enabled", slog.String("prefix", cfg.PromptParameterPrefix))
+	}
+	if cfg.BedrockGuardrailID != "" {
+		agentHandler.SetBedrockGuardrail(cfg.BedrockGuardrailID, cfg.BedrockGuardrailVersion)
+		logger.Info("bedrock guardrail configured",
+			slog.String("guardrail_id", cfg.BedrockGuardrailID),
+			slog.String("guardrail_version", cfg.BedrockGuardrailVersion),
+		)
+	}
+	agentHandler.SetThrottleBudget(cfg.BedrockThrottleBudget)
+	if agentLogsBucket != "" {
+		agentHandler.SetRunSummaryPublisher(internalscheduler.NewRunSummaryPublisher(s3Client, agentLogsBucket, cfg.Stage.String()))
+	}
 
-	// Create handler
-	handler := internalscheduler.NewSchedulerHandler(cfg, messageRepo, scheduleRepo, publisher, ebScheduler, sqsProcessor, logger, agentHandler)
+	// Create handler for the runs EventBridge Scheduler invokes this Lambda with
+	handler := internalscheduler.NewExecutionHandler(scheduleRepo, agentHandler, logger)
+	handler.SetEventPublisher(localrun.NewEventPublisher(cfg, awsCfg, logger))
 
-	// Start Lambda handler
-	lambda.Start(handler.HandleEvent)
+	// Start Lambda handler (or, in local mode, take scheduled messages POSTed over HTTP)
+	localrun.Start(cfg, localrun.SchedulerAddr, handler.HandleEvent, logger)
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/outbox"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad(appconfig.GroupTopicRouting)
+
+	logger.Info("stuck message sweeper lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+		slog.Duration("stuck_message_age", cfg.StuckMessageAge),
+		slog.Int("max_retries", cfg.StuckMessageMaxRetries),
+	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+
+	// Stuck messages were checked against the registry when they were first sent, so they are
+	// published again as they are, like the outbox relay does
+	routes, err := messaging.NewTopicRoutingTable(cfg.TopicRoutes, cfg.NotificationsSNSTopicArn)
+	if err != nil {
+		logger.Error("invalid topic routing configuration", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("invalid topic routing configuration: %v", err))
+	}
+	publisher := messaging.NewTopicRoutingSNSClient(sns.NewFromConfig(awsCfg), routes, logger)
+
+	sweeper := outbox.NewSweeper(
+		repository.NewDynamoDBRepository(dynamodb.NewFromConfig(awsCfg), cfg.DynamoDBTableName),
+		publisher,
+		metrics.NewSweepRecorder(os.Stdout, cfg.Stage.String(), logger),
+		cfg.StuckMessageAge,
+		cfg.StuckMessageMaxRetries,
+		logger,
+	)
+
+	// Start Lambda handler; the EventBridge schedule's event carries nothing the sweep needs
+	localrun.Start(cfg, localrun.SweeperAddr, sweeper.Sweep, logger)
+}
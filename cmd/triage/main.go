@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/notification"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/internal/triage"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+// TriageRequest is the invocation payload; every field is optional
+type TriageRequest struct {
+	// Queues limits the report to the named dead-letter queues
+	Queues []string `json:"queues,omitempty"`
+
+	// MaxSamples is how many messages to sample per queue
+	MaxSamples int `json:"max_samples,omitempty"`
+
+	// LookbackHours is how far before each message was sent to search the logs
+	LookbackHours int `json:"lookback_hours,omitempty"`
+
+	// Notify sends the report link to the ops notification topic even when the queues are empty
+	Notify bool `json:"notify,omitempty"`
+}
+
+// TriageResponse is returned to the invoker, so a one-line CLI call prints the report link
+type TriageResponse struct {
+	ReportURL string `json:"report_url"`
+	Summary   string `json:"summary"`
+}
+
+// TriageHandler builds a triage report, uploads it, and links it in an ops notification
+type TriageHandler struct {
+	collector     *triage.Collector
+	publisher     *triage.ReportPublisher
+	notifier      *notification.NtfyClient
+	queues        []triage.Queue
+	logGroups     []string
+	deployVersion string
+	logger        *slog.Logger
+}
+
+// HandleRequest runs one triage
+func (h *TriageHandler) HandleRequest(ctx context.Context, request TriageRequest) (TriageResponse, error) {
+	queues, err := triage.SelectQueues(h.queues, request.Queues)
+	if err != nil {
+		return TriageResponse{}, err
+	}
+
+	report := h.collector.Collect(ctx, queues, triage.Options{
+		MaxSamples:    request.MaxSamples,
+		Lookback:      time.Duration(request.LookbackHours) * time.Hour,
+		LogGroups:     h.logGroups,
+		DeployVersion: h.deployVersion,
+	})
+
+	link, err := h.publisher.Publish(ctx, report)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to publish triage report", slog.String("error", err.Error()))
+		return TriageResponse{}, err
+	}
+
+	summary := report.Summary()
+	h.logger.InfoContext(ctx, "triage report published",
+		slog.String("report_id", report.ID),
+		slog.Int("messages", report.TotalDepth()),
+	)
+
+	if report.TotalDepth() > 0 || request.Notify {
+		message := fmt.Sprintf("%s\n\nTriage report: %s", summary, link)
+		if err := h.notifier.SendWithTitle(ctx, "🩺 DLQ triage report", message); err != nil {
+			// The invoker still gets the link, so a failed notification does not fail the run
+			h.logger.WarnContext(ctx, "failed to send triage notification", slog.String("error", err.Error()))
+		}
+	}
+
+	return TriageResponse{ReportURL: link, Summary: summary}, nil
+}
+
+func main() {
+	// Setup structured logging
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logging.GetLogLevel(),
+	})))
+	slog.SetDefault(logger)
+
+	// Load configuration
+	cfg := appconfig.MustLoad(appconfig.GroupPushNotifications)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
+
+	queues, err := triage.ParseQueues(os.Getenv("TRIAGE_DLQ_URLS"))
+	if err != nil {
+		logger.Error("invalid TRIAGE_DLQ_URLS", slog.String("error", err.Error()))
+		panic(err)
+	}
+
+	reportsBucket := os.Getenv("TRIAGE_REPORTS_BUCKET")
+	if reportsBucket == "" {
+		panic("TRIAGE_REPORTS_BUCKET environment variable is required")
+	}
+
+	var logGroups []string
+	for _, group := range strings.Split(os.Getenv("TRIAGE_LOG_GROUPS"), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			logGroups = append(logGroups, group)
+		}
+	}
+
+	logger.Info("triage lambda starting",
+		slog.String("stage", cfg.Stage.String()),
+		slog.Int("queues", len(queues)),
+		slog.Int("log_groups", len(logGroups)),
+	)
+
+	// Initialize AWS SDK
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(awsCfg)
+
+	handler := &TriageHandler{
+		collector: triage.NewCollector(
+			triage.NewSQSQueueReader(sqs.NewFromConfig(awsCfg)),
+			triage.NewCloudWatchLogSearcher(cloudwatchlogs.NewFromConfig(awsCfg)),
+			repository.NewDynamoDBRepository(dynamoClient, cfg.DynamoDBTableName),
+			repository.NewDynamoDBWebActionRepository(dynamoClient, cfg.WebActionResultsTableName),
+			cfg.Stage.String(),
+			logger,
+		),
+		publisher: triage.NewReportPublisher(s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+			// LocalStack serves buckets by path rather than by virtual host
+			o.UsePathStyle = cfg.Local
+		}), reportsBucket),
+		notifier: notification.NewNtfyClient(notification.NtfyClientConfig{
+			BaseURL: cfg.NtfyURL,
+			Logger:  logger,
+		}),
+		queues:        queues,
+		logGroups:     logGroups,
+		deployVersion: os.Getenv("DEPLOY_VERSION"),
+		logger:        logger,
+	}
+
+	// Start Lambda handler
+	localrun.Start(cfg, localrun.TriageAddr, handler.HandleRequest, logger)
+}
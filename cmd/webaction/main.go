@@ -4,40 +4,48 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
 
-	"github.com/aws/aws-lambda-go/lambda"
-	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 
-	"github.com/jrzesz33/rez_agent/internal/httpclient"
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/bodystore"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
 	"github.com/jrzesz33/rez_agent/internal/logging"
 	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/notification"
+	"github.com/jrzesz33/rez_agent/internal/oauth"
 	"github.com/jrzesz33/rez_agent/internal/repository"
-	"github.com/jrzesz33/rez_agent/internal/secrets"
 	"github.com/jrzesz33/rez_agent/internal/webaction"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
 	"github.com/jrzesz33/rez_agent/pkg/config"
 )
 
 func main() {
 	// Initialize structured logger
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: logging.GetLogLevel(),
-	}))
+	})))
 
 	logger.Info("Web Action Function Starting...")
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.LoadFor(config.GroupTopicRouting, config.GroupAgentResponses, config.GroupPushNotifications)
 	if err != nil {
 		logger.Error("failed to load configuration", slog.String("error", err.Error()))
 		panic(err)
 	}
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
 
 	// Initialize AWS SDK config
-	awsCfg, err := awsConfig.LoadDefaultConfig(context.Background(),
-		awsConfig.WithRegion(cfg.AWSRegion),
-	)
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
 	if err != nil {
 		logger.Error("failed to load AWS SDK config", slog.String("error", err.Error()))
 		panic(err)
@@ -52,38 +60,113 @@ func main() {
 
 	// Initialize repositories
 	messageRepo := repository.NewDynamoDBRepository(dynamoClient, cfg.DynamoDBTableName)
-	resultRepo := repository.NewDynamoDBWebActionRepository(dynamoClient, cfg.WebActionResultsTableName)
+	var resultRepo repository.WebActionResultRepository = repository.NewDynamoDBWebActionRepository(dynamoClient, cfg.WebActionResultsTableName)
+
+	// Bodies too large for the results table are kept whole in the agent logs bucket
+	if cfg.WebActionBodyOffload {
+		if agentLogsBucket := os.Getenv("AGENT_LOGS_BUCKET"); agentLogsBucket != "" {
+			s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+				// LocalStack serves buckets by path rather than by virtual host
+				o.UsePathStyle = cfg.Local
+			})
+			resultRepo = bodystore.NewRepository(resultRepo, bodystore.New(s3Client, agentLogsBucket, cfg.Stage.String()), logger)
+		} else {
+			logger.Warn("WEB_ACTION_BODY_OFFLOAD needs AGENT_LOGS_BUCKET; large response bodies are only kept truncated")
+		}
+	}
 
 	logger.Info("Initialized Repositories")
 
 	// Initialize SNS publisher
-	snsPublisher := messaging.NewTopicRoutingSNSClient(snsClient, cfg.WebActionsSNSTopicArn, cfg.NotificationsSNSTopicArn, cfg.AgentResponseTopicArn, cfg.ScheduleCreationTopicArn, logger)
+	routes, err := messaging.NewTopicRoutingTable(cfg.TopicRoutes, cfg.NotificationsSNSTopicArn)
+	if err != nil {
+		logger.Error("invalid topic routing configuration", slog.String("error", err.Error()))
+		panic(err)
+	}
+	snsPublisher := messaging.NewTopicRoutingSNSClient(snsClient, routes, logger)
+	snsPublisher.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentWebAction)
+
+	// Domain events go to the event bus, when one is configured
+	eventPublisher := localrun.NewEventPublisher(cfg, awsCfg, logger)
+	snsPublisher.SetEventPublisher(eventPublisher)
 
 	// Initialize SQS processor
 	sqsProcessor := messaging.NewSQSBatchProcessor(logger)
+	sqsProcessor.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentWebAction)
+	sqsProcessor.SetLatencyRecorder(metrics.NewLatencyRecorder(os.Stdout, cfg.Stage.String(), metrics.DefaultSLOs))
+	sqsProcessor.SetPermanentFailureHandler(messaging.DefaultMaxReceiveCount, webaction.NewFailureReporter(snsPublisher, cfg.Stage, logger).Report)
+	sqsProcessor.SetRetryPolicy(apperrors.IsRetryable)
+	sqsProcessor.SetRecordTimeout(cfg.SQSRecordTimeout)
+	sqsProcessor.SetConcurrency(cfg.SQSBatchConcurrency)
+	sqsProcessor.SetEventPublisher(eventPublisher)
 
 	logger.Info("Initialized SNS & SQS")
 
 	// Initialize HTTP client and secrets manager
 	httpClient := httpclient.NewClient(logger)
-	secretsManager := secrets.NewManager(awsCfg, logger)
-	oauthClient := httpclient.NewOAuthClient(httpClient, secretsManager, logger)
+	httpClient.SetCircuitBreaker(httpclient.NewCircuitBreaker(httpclient.CircuitBreakerConfig{
+		FailureThreshold: cfg.CircuitBreakerFailureThreshold,
+		OpenTimeout:      cfg.CircuitBreakerOpenTimeout,
+	}, logger))
+	// Secret reads and bookings are recorded in the audit log
+	auditRecorder := audit.NewRecorder(repository.NewDynamoDBAuditRepository(dynamoClient, cfg.AuditTableName), "webaction", logger)
+	secretsManager := localrun.NewSecretsManager(cfg, awsCfg, logger)
+	secretsManager.SetAccessRecorder(auditRecorder.SecretAccessed)
+	oauthClient := oauth.NewClient(httpClient, secretsManager, logger)
+	tokenCache := oauth.NewTokenCache(cfg.OAuthTokenRefreshBefore, logger)
+	if cfg.OAuthTokenTableName != "" {
+		tokenCache.SetStore(repository.NewDynamoDBOAuthTokenRepository(dynamoClient, cfg.OAuthTokenTableName))
+	}
+	oauthClient.SetTokenCache(tokenCache)
 
 	logger.Info("Initialized HTTP Clients and Secrets Manager")
 
-	// Initialize action handler registry
-	handlerRegistry := webaction.NewHandlerRegistry(logger)
+	// Handlers available in this build; the stage's handler config decides which are enabled
+	factories := map[models.WebActionType]webaction.HandlerFactory{
+		models.WebActionTypeWeather: func(options map[string]string) (webaction.ActionHandler, error) {
+			return webaction.NewWeatherHandler(httpClient, logger), nil
+		},
+		models.WebActionTypeGolf: func(options map[string]string) (webaction.ActionHandler, error) {
+			golfHandler := webaction.NewGolfHandler(httpClient, oauthClient, secretsManager, logger)
+			golfHandler.SetApprovalWorkflow(
+				repository.NewDynamoDBApprovalRepository(dynamoClient, cfg.ApprovalsTableName),
+				notification.NewNtfyClient(notification.NtfyClientConfig{BaseURL: cfg.NtfyURL, Logger: logger}),
+				cfg.ApprovalBaseURL,
+			)
+			golfHandler.SetAuditRecorder(auditRecorder)
+			golfHandler.SetEventPublisher(eventPublisher)
+			if cfg.BookingLedgerTableName != "" {
+				golfHandler.SetBookingLedger(repository.NewDynamoDBBookingLedgerRepository(dynamoClient, cfg.BookingLedgerTableName))
+			}
+			// Group members get their RSVP requests on their own topic of the same ntfy server
+			golfHandler.SetGroupNotifier(func(topic string) (webaction.ApprovalNotifier, error) {
+				topicURL, err := notification.TopicURL(cfg.NtfyURL, topic)
+				if err != nil {
+					return nil, err
+				}
+				return notification.NewNtfyClient(notification.NtfyClientConfig{BaseURL: topicURL, Logger: logger}), nil
+			})
+			return golfHandler, nil
+		},
+		models.WebActionTypeHTTPRequest: func(options map[string]string) (webaction.ActionHandler, error) {
+			allowedHosts := slices.Concat(cfg.HTTPRequestAllowedHosts, strings.Split(options["allowed_hosts"], ","))
+			return webaction.NewHTTPRequestHandler(httpClient, oauthClient, secretsManager, allowedHosts, logger), nil
+		},
+	}
 
-	// Register handlers
-	weatherHandler := webaction.NewWeatherHandler(httpClient, logger)
-	if err := handlerRegistry.Register(weatherHandler); err != nil {
-		logger.Error("failed to register weather handler", slog.String("error", err.Error()))
-		panic(err)
+	var handlerConfigs []*models.WebActionHandlerConfig
+	if cfg.WebActionHandlerTableName != "" {
+		handlerConfigs, err = repository.NewDynamoDBHandlerConfigRepository(dynamoClient, cfg.WebActionHandlerTableName).ListHandlerConfigs(context.Background())
+		if err != nil {
+			logger.Error("failed to load web action handler configs", slog.String("error", err.Error()))
+			panic(err)
+		}
 	}
 
-	golfHandler := webaction.NewGolfHandler(httpClient, oauthClient, secretsManager, logger)
-	if err := handlerRegistry.Register(golfHandler); err != nil {
-		logger.Error("failed to register golf handler", slog.String("error", err.Error()))
+	// Initialize action handler registry
+	handlerRegistry := webaction.NewHandlerRegistry(logger)
+	if err := handlerRegistry.RegisterFromConfig(factories, handlerConfigs); err != nil {
+		logger.Error("failed to register web action handlers", slog.String("error", err.Error()))
 		panic(err)
 	}
 
@@ -100,6 +183,6 @@ func main() {
 		logger,
 		sqsProcessor)
 
-	// Start Lambda
-	lambda.Start(handler.HandleSQSEvent)
+	// Start Lambda (or, in local mode, poll the web action queue)
+	localrun.StartSQS(cfg, localrun.WebActionAddr, handler.HandleSQSEvent, sqs.NewFromConfig(awsCfg), cfg.WebActionSQSQueueURL, logger)
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/pagination"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// auditListOptions is the list convention supported by GET /api/audit
+var auditListOptions = pagination.Options{
+	DefaultLimit: 100,
+	MaxLimit:     1000,
+	SortFields:   []string{"created_at", "action", "actor"},
+	DefaultSort:  "-created_at",
+	FilterFields: []string{"action", "actor", "user_id", "target", "correlation_id"},
+}
+
+// defaultAuditWindow is how far back GET /api/audit looks when no since is given
+const defaultAuditWindow = 30 * 24 * time.Hour
+
+// SetAuditLog enables GET /api/audit over repo, and records booking cancellations made through
+// the API with recorder
+func (h *WebAPIHandler) SetAuditLog(repo repository.AuditRepository, recorder *audit.Recorder) {
+	h.auditRepo = repo
+	h.auditRecorder = recorder
+}
+
+// authorizeOperator admits the data request API key (X-API-Key) or an admin's API key. Single-user
+// mode has no admins, so there only the data request key reaches the operator endpoints.
+func (h *WebAPIHandler) authorizeOperator(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, bool) {
+	// API Gateway HTTP APIs lowercase header names
+	if provided := request.Headers["x-api-key"]; provided != "" && h.config.DataRequestAPIKey != "" {
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(h.config.DataRequestAPIKey)) != 1 {
+			return h.createErrorResponse(http.StatusUnauthorized, "invalid API key"), false
+		}
+		return events.APIGatewayV2HTTPResponse{}, true
+	}
+
+	user, response, ok := h.authenticate(ctx, request)
+	if !ok {
+		return response, false
+	}
+	if user == nil || !user.IsAdmin() {
+		return h.createErrorResponse(http.StatusForbidden, "only operators can use this endpoint"), false
+	}
+	return events.APIGatewayV2HTTPResponse{}, true
+}
+
+// handleListAuditEntries returns a page of the audit log, newest first, covering the entries
+// recorded since the since parameter (RFC 3339, default the last 30 days)
+func (h *WebAPIHandler) handleListAuditEntries(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.auditRepo == nil {
+		return h.createErrorResponse(http.StatusNotFound, "audit log is not enabled"), nil
+	}
+	if response, ok := h.authorizeOperator(ctx, request); !ok {
+		return response, nil
+	}
+
+	query, err := pagination.Parse(legacyFilters(request.QueryStringParameters, "action", "user_id"), auditListOptions)
+	if err != nil {
+		return h.createErrorResponse(http.StatusBadRequest, err.Error()), nil
+	}
+	since := time.Now().Add(-defaultAuditWindow)
+	if raw := request.QueryStringParameters["since"]; raw != "" {
+		if since, err = time.Parse(time.RFC3339, raw); err != nil {
+			return h.createErrorResponse(http.StatusBadRequest, "since must be an RFC 3339 timestamp"), nil
+		}
+	}
+
+	entries, err := h.auditRepo.ListEntries(ctx, since)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list audit entries", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve audit entries"), err
+	}
+
+	page := pagination.Apply(entries, query, func(e *models.AuditEntry, field string) string {
+		switch field {
+		case "created_at":
+			return pagination.Time(e.CreatedAt)
+		case "action":
+			return string(e.Action)
+		case "actor":
+			return e.Actor
+		case "user_id":
+			return e.UserID
+		case "target":
+			return e.Target
+		case "correlation_id":
+			return e.CorrelationID
+		}
+		return ""
+	})
+
+	body, err := json.Marshal(page.Response("entries"))
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
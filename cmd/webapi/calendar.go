@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/calendar"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// calendarHorizon is how far ahead the calendar feed lists scheduled runs
+const calendarHorizon = 14 * 24 * time.Hour
+
+// calendarRunsPerSchedule caps the runs of one schedule in the feed, so an hourly schedule does
+// not bury the tee times
+const calendarRunsPerSchedule = 14
+
+// roundDuration is how long a tee time blocks the calendar; the synced reservations do not say
+// how many holes were booked, so every round is shown as eighteen
+const roundDuration = 4*time.Hour + 30*time.Minute
+
+// scheduledRunDuration is how long a scheduled run is shown for
+const scheduledRunDuration = 15 * time.Minute
+
+// handleGetCalendarURL returns the caller's calendar feed URL. The URL carries a signed token
+// instead of an API key, since calendar apps subscribe with a plain GET.
+func (h *WebAPIHandler) handleGetCalendarURL(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.config.CalendarSigningKey == "" {
+		return h.createErrorResponse(http.StatusNotFound, "calendar feed is not enabled"), nil
+	}
+	user, response, ok := h.authenticate(ctx, request)
+	if !ok {
+		return response, nil
+	}
+
+	var subject string
+	if user != nil {
+		subject = user.ID
+	}
+	query := url.Values{"token": {calendar.FeedToken(h.config.CalendarSigningKey, subject)}}
+	if subject != "" {
+		query.Set("user", subject)
+	}
+
+	baseURL := strings.TrimRight(h.config.ApprovalBaseURL, "/")
+	if baseURL == "" && request.RequestContext.DomainName != "" {
+		baseURL = "https://" + request.RequestContext.DomainName
+	}
+
+	body, err := json.Marshal(calendarURLResponse{
+		URL: baseURL + "/api/calendar.ics?" + query.Encode(),
+	})
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
+
+// handleCalendarFeed renders the upcoming synced reservations and scheduled runs of the user
+// named in the URL as an iCalendar feed
+func (h *WebAPIHandler) handleCalendarFeed(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.config.CalendarSigningKey == "" {
+		return h.createErrorResponse(http.StatusNotFound, "calendar feed is not enabled"), nil
+	}
+
+	subject := request.QueryStringParameters["user"]
+	if !calendar.VerifyFeedToken(h.config.CalendarSigningKey, subject, request.QueryStringParameters["token"]) {
+		return h.createErrorResponse(http.StatusUnauthorized, "invalid calendar token"), nil
+	}
+
+	// In single-user mode the feed belongs to the shared credentials and every schedule
+	var user *models.User
+	if h.userRepo != nil {
+		var err error
+		user, err = h.userRepo.GetUser(ctx, subject)
+		if err != nil {
+			if !errors.Is(err, apperrors.ErrNotFound) {
+				h.logger.ErrorContext(ctx, "failed to look up user", slog.String("error", err.Error()))
+				return h.createErrorResponse(http.StatusInternalServerError, "failed to authenticate"), err
+			}
+			return h.createErrorResponse(http.StatusUnauthorized, "invalid calendar token"), nil
+		}
+		if user.Disabled {
+			return h.createErrorResponse(http.StatusForbidden, "user is disabled"), nil
+		}
+	}
+
+	now := time.Now()
+	reservationEvents, err := h.reservationEvents(ctx, user, now)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list reservations", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve reservations"), err
+	}
+	scheduleEvents, err := h.scheduleEvents(ctx, user, now)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list schedules", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve schedules"), err
+	}
+
+	feed := calendar.Feed{
+		Name:   "rez_agent",
+		Events: append(reservationEvents, scheduleEvents...),
+	}
+	var body strings.Builder
+	if err := feed.Write(&body, now); err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to render calendar"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": calendar.ContentType},
+		Body:       body.String(),
+	}, nil
+}
+
+// reservationEvents returns the user's upcoming synced tee times; there are none when reservation
+// sync is off or the user has no golf credentials
+func (h *WebAPIHandler) reservationEvents(ctx context.Context, user *models.User, now time.Time) ([]calendar.Event, error) {
+	if h.reservationRepo == nil {
+		return nil, nil
+	}
+	secretName, err := h.reservationSecretName(user)
+	if errors.Is(err, errNoGolfCredentials) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := h.reservationRepo.ListSnapshots(ctx, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	var feedEvents []calendar.Event
+	for _, snapshot := range snapshots {
+		for _, reservation := range snapshot.Upcoming(now) {
+			description := fmt.Sprintf("%d player(s)", reservation.Players)
+			if reservation.ConfirmationKey != "" {
+				description += "\nConfirmation: " + reservation.ConfirmationKey
+			}
+			feedEvents = append(feedEvents, calendar.Event{
+				UID:         fmt.Sprintf("reservation-%d-%d@rez-agent", snapshot.CourseID, reservation.ReservationID),
+				Summary:     "⛳ Golf at " + snapshot.CourseName,
+				Description: description,
+				Location:    snapshot.CourseName,
+				Start:       reservation.TeeTime,
+				End:         reservation.TeeTime.Add(roundDuration),
+			})
+		}
+	}
+	return feedEvents, nil
+}
+
+// scheduleEvents returns the runs of the user's active schedules over the calendar horizon;
+// admins and single-user mode see every schedule
+func (h *WebAPIHandler) scheduleEvents(ctx context.Context, user *models.User, now time.Time) ([]calendar.Event, error) {
+	var schedules []*models.Schedule
+	var err error
+	if user != nil && !user.IsAdmin() {
+		schedules, err = h.scheduleRepository.ListSchedulesByCreator(ctx, user.ID)
+	} else {
+		schedules, err = h.scheduleRepository.ListSchedulesByStatus(ctx, models.ScheduleStatusActive)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var feedEvents []calendar.Event
+	for _, schedule := range schedules {
+		for _, runAt := range schedule.RunsBetween(now, now.Add(calendarHorizon), calendarRunsPerSchedule) {
+			feedEvents = append(feedEvents, calendar.Event{
+				UID:         fmt.Sprintf("%s-%d@rez-agent", schedule.ID, runAt.Unix()),
+				Summary:     "🤖 " + schedule.Name,
+				Description: schedule.Description,
+				Start:       runAt,
+				End:         runAt.Add(scheduledRunDuration),
+			})
+		}
+	}
+	return feedEvents, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsscheduler "github.com/aws/aws-sdk-go-v2/service/scheduler"
+	schedulertypes "github.com/aws/aws-sdk-go-v2/service/scheduler/types"
+	"github.com/jrzesz33/rez_agent/internal/privacy"
+)
+
+// SetPrivacyService enables the data export and deletion endpoints
+func (h *WebAPIHandler) SetPrivacyService(service *privacy.Service) {
+	h.privacy = service
+}
+
+// authorizeDataRequest checks the X-API-Key header against DATA_REQUEST_API_KEY; the endpoints are
+// disabled when no key is configured so user data is never exposed by default
+func (h *WebAPIHandler) authorizeDataRequest(request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, bool) {
+	if h.privacy == nil || h.config.DataRequestAPIKey == "" {
+		return h.createErrorResponse(http.StatusForbidden, "data requests are not enabled"), false
+	}
+	// API Gateway HTTP APIs lowercase header names
+	provided := request.Headers["x-api-key"]
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(h.config.DataRequestAPIKey)) != 1 {
+		return h.createErrorResponse(http.StatusUnauthorized, "invalid API key"), false
+	}
+	return events.APIGatewayV2HTTPResponse{}, true
+}
+
+// dataRequestSubject reads the user and chat sessions a data request covers
+func dataRequestSubject(request events.APIGatewayV2HTTPRequest) privacy.Subject {
+	return privacy.Subject{
+		UserID:     request.QueryStringParameters["user_id"],
+		SessionIDs: splitParam(request.QueryStringParameters["session_ids"]),
+	}
+}
+
+// handleExportData returns everything stored about a user as a JSON archive
+func (h *WebAPIHandler) handleExportData(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if response, ok := h.authorizeDataRequest(request); !ok {
+		return response, nil
+	}
+
+	subject := dataRequestSubject(request)
+	if err := subject.Validate(); err != nil {
+		return h.createErrorResponse(http.StatusBadRequest, err.Error()), nil
+	}
+
+	archive, err := h.privacy.Export(ctx, subject)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to export user data", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to export data"), err
+	}
+
+	body, err := json.Marshal(archive)
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Content-Disposition": fmt.Sprintf(`attachment; filename="rez-agent-export-%s.json"`, archive.ExportedAt.Format("20060102T150405Z")),
+		},
+		Body: string(body),
+	}, nil
+}
+
+// handleDeleteData permanently deletes everything stored about a user. The confirm parameter must
+// repeat the user_id so a mistyped request cannot delete another user's data.
+func (h *WebAPIHandler) handleDeleteData(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if response, ok := h.authorizeDataRequest(request); !ok {
+		return response, nil
+	}
+
+	subject := dataRequestSubject(request)
+	if err := subject.Validate(); err != nil {
+		return h.createErrorResponse(http.StatusBadRequest, err.Error()), nil
+	}
+	if request.QueryStringParameters["confirm"] != subject.UserID {
+		return h.createErrorResponse(http.StatusBadRequest, "confirm must repeat the user_id"), nil
+	}
+
+	entry, err := h.privacy.Delete(ctx, subject)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to delete user data", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to delete data; the request can be retried"), err
+	}
+
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
+
+// eventBridgeTriggerDeleter deletes EventBridge schedules for the privacy service
+type eventBridgeTriggerDeleter struct {
+	client *awsscheduler.Client
+}
+
+// DeleteTrigger deletes an EventBridge schedule; one that is already gone is not an error
+func (d *eventBridgeTriggerDeleter) DeleteTrigger(ctx context.Context, name string) error {
+	_, err := d.client.DeleteSchedule(ctx, &awsscheduler.DeleteScheduleInput{Name: aws.String(name)})
+	var notFound *schedulertypes.ResourceNotFoundException
+	if err != nil && !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to delete EventBridge schedule: %w", err)
+	}
+	return nil
+}
+
+// splitParam parses a comma-separated query parameter, dropping empty entries
+func splitParam(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
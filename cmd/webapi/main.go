@@ -3,32 +3,59 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	awsscheduler "github.com/aws/aws-sdk-go-v2/service/scheduler"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/bodystore"
+	"github.com/jrzesz33/rez_agent/internal/compress"
+	"github.com/jrzesz33/rez_agent/internal/cors"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
 	"github.com/jrzesz33/rez_agent/internal/logging"
 	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
 	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/outbox"
+	"github.com/jrzesz33/rez_agent/internal/pagination"
+	"github.com/jrzesz33/rez_agent/internal/privacy"
 	"github.com/jrzesz33/rez_agent/internal/repository"
+	internalscheduler "github.com/jrzesz33/rez_agent/internal/scheduler"
 	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
 )
 
 // WebAPIHandler handles API Gateway requests
 type WebAPIHandler struct {
-	config             *appconfig.Config
-	repository         repository.MessageRepository
-	scheduleRepository repository.ScheduleRepository
-	publisher          messaging.SNSPublisher
-	logger             *slog.Logger
+	config              *appconfig.Config
+	repository          repository.MessageRepository
+	scheduleRepository  repository.ScheduleRepository
+	preferenceRepo      repository.PreferenceRepository
+	approvalRepo        repository.ApprovalRepository
+	userRepo            repository.UserRepository
+	reservationRepo     repository.ReservationRepository
+	webActionResultRepo repository.WebActionResultRepository
+	responseBodies      responseBodyLinker
+	queueStats          queueStatsReader
+	reconciler          scheduleReconciler
+	publisher           messaging.SNSPublisher
+	outbox              *outbox.Writer
+	privacy             *privacy.Service
+	auditRepo           repository.AuditRepository
+	auditRecorder       *audit.Recorder
+	routes              []route
+	logger              *slog.Logger
 }
 
 // NewWebAPIHandler creates a new web API handler instance
@@ -36,39 +63,45 @@ func NewWebAPIHandler(
 	cfg *appconfig.Config,
 	repo repository.MessageRepository,
 	scheduleRepo repository.ScheduleRepository,
+	preferenceRepo repository.PreferenceRepository,
+	approvalRepo repository.ApprovalRepository,
 	pub messaging.SNSPublisher,
 	logger *slog.Logger,
 ) *WebAPIHandler {
+	// New messages are saved and published through the outbox; with the relay deployed, it publishes them
+	writer := outbox.NewWriter(repo, pub, logger)
+	if cfg.OutboxRelay {
+		writer.SetRelayed(models.DefaultMessageTypeRegistry(), models.ComponentWebAPI)
+	}
+
 	return &WebAPIHandler{
 		config:             cfg,
 		repository:         repo,
 		scheduleRepository: scheduleRepo,
+		preferenceRepo:     preferenceRepo,
+		approvalRepo:       approvalRepo,
 		publisher:          pub,
+		outbox:             writer,
+		routes:             webAPIRoutes,
 		logger:             logger,
 	}
 }
 
 // HandleRequest routes API Gateway V2 requests to appropriate handlers
 func (h *WebAPIHandler) HandleRequest(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	// Every request starts a journey, or continues the caller's; API Gateway lowercases header names
+	correlationID := logging.IncomingCorrelationID(request.Headers["x-correlation-id"])
+	ctx = logging.WithCorrelationID(ctx, correlationID)
+
 	h.logger.DebugContext(ctx, "received API request",
 		slog.String("method", request.RequestContext.HTTP.Method),
 		slog.String("path", request.RawPath),
 	)
 
-	// Add CORS headers
+	// CORS headers are added by the cors middleware the handler is started with
 	headers := map[string]string{
-		"Content-Type":                 "application/json",
-		"Access-Control-Allow-Origin":  "*",
-		"Access-Control-Allow-Methods": "GET, POST, OPTIONS",
-		"Access-Control-Allow-Headers": "Content-Type",
-	}
-
-	// Handle OPTIONS for CORS preflight
-	if request.RequestContext.HTTP.Method == "OPTIONS" {
-		return events.APIGatewayV2HTTPResponse{
-			StatusCode: http.StatusOK,
-			Headers:    headers,
-		}, nil
+		"Content-Type":              "application/json",
+		logging.CorrelationIDHeader: correlationID,
 	}
 
 	// Route requests
@@ -81,16 +114,9 @@ func (h *WebAPIHandler) HandleRequest(ctx context.Context, request events.APIGat
 	}
 	method := request.RequestContext.HTTP.Method
 
-	switch {
-	case path == "/api/health" && method == "GET":
-		response, err = h.handleHealth(ctx)
-	case path == "/api/messages" && method == "GET":
-		response, err = h.handleListMessages(ctx, request)
-	case path == "/api/messages" && method == "POST":
-		response, err = h.handleCreateMessage(ctx, request)
-	case path == "/api/metrics" && method == "GET":
-		response, err = h.handleMetrics(ctx)
-	default:
+	if r, params, ok := matchRoute(h.routes, method, path); ok {
+		response, err = r.handle(h, ctx, request, params)
+	} else {
 		response = h.createErrorResponse(http.StatusNotFound, "endpoint not found")
 	}
 
@@ -100,11 +126,14 @@ func (h *WebAPIHandler) HandleRequest(ctx context.Context, request events.APIGat
 		)
 	}
 
-	// Add CORS headers to response
+	// Add the common headers to the response, keeping a Content-Type set by the handler
 	if response.Headers == nil {
 		response.Headers = headers
 	} else {
 		for k, v := range headers {
+			if _, set := response.Headers[k]; k == "Content-Type" && set {
+				continue
+			}
 			response.Headers[k] = v
 		}
 	}
@@ -114,10 +143,10 @@ func (h *WebAPIHandler) HandleRequest(ctx context.Context, request events.APIGat
 
 // handleHealth returns the health status of the API
 func (h *WebAPIHandler) handleHealth(ctx context.Context) (events.APIGatewayV2HTTPResponse, error) {
-	health := map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"stage":     h.config.Stage.String(),
+	health := healthResponse{
+		Status:    "healthy",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Stage:     h.config.Stage.String(),
 	}
 	fmt.Println(ctx)
 	body, err := json.Marshal(health)
@@ -131,52 +160,71 @@ func (h *WebAPIHandler) handleHealth(ctx context.Context) (events.APIGatewayV2HT
 	}, nil
 }
 
-// handleListMessages returns a list of messages with optional filtering
+// messageListOptions is the list convention supported by GET /api/messages. Messages are read
+// from created date indexes, so they sort by created date only.
+var messageListOptions = pagination.Options{
+	DefaultLimit: 100,
+	MaxLimit:     1000,
+	SortFields:   []string{"created_date"},
+	DefaultSort:  "-created_date",
+	FilterFields: []string{"stage", "status", "message_type", "correlation_id"},
+}
+
+// handleListMessages returns a page of messages using the shared list convention; members only
+// see the messages they created. The stage filter defaults to the API's stage.
 func (h *WebAPIHandler) handleListMessages(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
-	// Parse query parameters
-	var stage *models.Stage
-	var status *models.Status
-	limit := 100
-
-	if stageParam, ok := request.QueryStringParameters["stage"]; ok && stageParam != "" {
-		s := models.Stage(stageParam)
-		if s.IsValid() {
-			stage = &s
-		}
+	user, response, ok := h.authenticate(ctx, request)
+	if !ok {
+		return response, nil
 	}
 
-	if statusParam, ok := request.QueryStringParameters["status"]; ok && statusParam != "" {
-		st := models.Status(statusParam)
-		if st.IsValid() {
-			status = &st
-		}
+	opts := messageListOptions
+	opts.DefaultFilters = map[string]string{"stage": h.config.Stage.String()}
+	query, err := pagination.Parse(legacyFilters(request.QueryStringParameters, "stage", "status"), opts)
+	if err != nil {
+		return h.createValidationResponse(listQueryViolations(err)), nil
 	}
 
-	if limitParam, ok := request.QueryStringParameters["limit"]; ok && limitParam != "" {
-		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 && l <= 1000 {
-			limit = l
+	var violations []fieldViolation
+	value, _ := query.Filter("stage")
+	messageQuery := repository.MessageQuery{Stage: models.Stage(value)}
+	if !messageQuery.Stage.IsValid() {
+		violations = append(violations, fieldViolation{Field: "filter[stage]", Message: fmt.Sprintf("%q is not a valid stage", value)})
+	}
+	if value, ok := query.Filter("status"); ok {
+		messageQuery.Status = models.Status(value)
+		if !messageQuery.Status.IsValid() {
+			violations = append(violations, fieldViolation{Field: "filter[status]", Message: fmt.Sprintf("%q is not a valid message status", value)})
 		}
 	}
+	if len(violations) > 0 {
+		return h.createValidationResponse(violations), nil
+	}
+	value, _ = query.Filter("message_type")
+	messageQuery.MessageType = models.MessageType(value)
+	messageQuery.CorrelationID, _ = query.Filter("correlation_id")
+	if user != nil && !user.IsAdmin() {
+		messageQuery.CreatedBy = user.ID
+	}
 
 	h.logger.DebugContext(ctx, "listing messages",
-		slog.Any("stage", stage),
-		slog.Any("status", status),
-		slog.Int("limit", limit),
+		slog.Any("filters", query.Filters),
+		slog.Int("limit", query.Limit),
 	)
 
-	// Query messages from repository
-	messages, err := h.repository.ListMessages(ctx, stage, status, limit)
+	// The repository reads one page from the creator's, the status's or the stage's index and
+	// returns the key to resume from
+	messages, next, err := h.repository.QueryMessages(ctx, messageQuery, repository.IndexPage{
+		Limit:      query.Limit,
+		Descending: query.Descending(),
+		Cursor:     query.Cursor,
+	})
 	if err != nil {
 		h.logger.ErrorContext(ctx, "failed to list messages", slog.String("error", err.Error()))
 		return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve messages"), err
 	}
 
-	response := map[string]interface{}{
-		"messages": messages,
-		"count":    len(messages),
-	}
-
-	body, err := json.Marshal(response)
+	body, err := json.Marshal(pagination.CursorPage(messages, query, next).Response("messages"))
 	if err != nil {
 		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
 	}
@@ -187,55 +235,56 @@ func (h *WebAPIHandler) handleListMessages(ctx context.Context, request events.A
 	}, nil
 }
 
-// handleCreateMessage creates a new message manually
+// handleCreateMessage creates a new message manually, on behalf of the authenticated user
 func (h *WebAPIHandler) handleCreateMessage(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
-	var req models.Message
-	err := json.Unmarshal([]byte(request.Body), &req)
-	if err != nil {
-		h.logger.ErrorContext(ctx, "failed to parse request body", slog.String("error", request.Body))
-		return h.createErrorResponse(http.StatusBadRequest, "invalid request body"), err
+	user, response, ok := h.authenticate(ctx, request)
+	if !ok {
+		return response, nil
+	}
+
+	req, violations := decodeMessageRequest(request.Body)
+	if violations != nil {
+		return h.createValidationResponse(violations), nil
 	}
 	// Use config stage if not provided
 	if req.Stage == "" {
 		req.Stage = h.config.Stage
 	}
 
-	if !req.Stage.IsValid() {
-		return h.createErrorResponse(http.StatusBadRequest, "invalid stage value"), nil
+	// Default to a notification when no message type is given
+	if req.MessageType == "" {
+		req.MessageType = models.MessageTypeNotification
 	}
 
-	// Determine message type
-	messageType := req.MessageType
-	if messageType == "" {
-		messageType = models.MessageTypeNotification
+	if violations := validateMessageRequest(req, models.ComponentWebAPI); len(violations) > 0 {
+		h.logger.WarnContext(ctx, "rejected invalid message", slog.Any("violations", violations))
+		return h.createValidationResponse(violations), nil
 	}
-
-	// Serialize web action to JSON
-	err = req.Validate()
-	if err != nil {
-		h.logger.ErrorContext(ctx, "invalid request", slog.String("error", err.Error()))
-		return h.createErrorResponse(http.StatusInternalServerError, "invalid request"), err
+	if err := req.Validate(); err != nil {
+		return h.createValidationResponse([]fieldViolation{violationFor(err, "payload")}), nil
 	}
-
-	// Save to repository
-	err = h.repository.SaveMessage(ctx, &req)
-	if err != nil {
-		h.logger.ErrorContext(ctx, "failed to save message", slog.String("error", err.Error()))
-		return h.createErrorResponse(http.StatusInternalServerError, "failed to save message"), err
+	if user != nil {
+		req.CreatedBy = user.ID
 	}
-
-	// Mark as queued
-	req.MarkQueued()
-	err = h.repository.UpdateStatus(ctx, req.ID, req.Status, "")
-	if err != nil {
-		h.logger.ErrorContext(ctx, "failed to update message status", slog.String("error", err.Error()))
+	req.CorrelationID = logging.CorrelationID(ctx)
+	if err := scopeCredentials(req, user); err != nil {
+		return h.createErrorResponse(http.StatusForbidden, err.Error()), nil
 	}
 
-	// Publish to SNS
-	err = h.publisher.PublishMessage(ctx, &req)
-	if err != nil {
-		h.logger.ErrorContext(ctx, "failed to publish message", slog.String("error", err.Error()))
-		return h.createErrorResponse(http.StatusInternalServerError, "failed to publish message"), err
+	// Save and publish through the outbox, so a saved message is always published eventually. Once
+	// it is saved the outbox delivers it, so a failed publish is accepted rather than failed:
+	// a client retrying an error would send it twice.
+	statusCode := http.StatusCreated
+	if err := h.outbox.Send(ctx, req); err != nil {
+		if !errors.Is(err, outbox.ErrNotPublished) {
+			h.logger.ErrorContext(ctx, "failed to send message", slog.String("error", err.Error()))
+			return h.createErrorResponse(http.StatusInternalServerError, "failed to send message"), err
+		}
+		h.logger.WarnContext(ctx, "message saved for the outbox to publish",
+			slog.String("message_id", req.ID),
+			slog.String("error", err.Error()),
+		)
+		statusCode = http.StatusAccepted
 	}
 
 	body, err := json.Marshal(req)
@@ -244,7 +293,7 @@ func (h *WebAPIHandler) handleCreateMessage(ctx context.Context, request events.
 	}
 
 	return events.APIGatewayV2HTTPResponse{
-		StatusCode: http.StatusCreated,
+		StatusCode: statusCode,
 		Body:       string(body),
 	}, nil
 }
@@ -261,27 +310,18 @@ func (h *WebAPIHandler) handleMetrics(ctx context.Context) (events.APIGatewayV2H
 	}
 
 	// Calculate metrics
-	metrics := map[string]interface{}{
-		"total":     len(allMessages),
-		"by_status": make(map[string]int),
-		"by_stage":  make(map[string]int),
-		"by_type":   make(map[string]int),
+	metrics := metricsResponse{
+		Total:    len(allMessages),
+		ByStatus: make(map[string]int),
+		ByStage:  make(map[string]int),
+		ByType:   make(map[string]int),
 	}
-
-	byStatus := make(map[string]int)
-	byStage := make(map[string]int)
-	byType := make(map[string]int)
-
 	for _, msg := range allMessages {
-		byStatus[msg.Status.String()]++
-		byStage[msg.Stage.String()]++
-		byType[msg.MessageType.String()]++
+		metrics.ByStatus[msg.Status.String()]++
+		metrics.ByStage[msg.Stage.String()]++
+		metrics.ByType[msg.MessageType.String()]++
 	}
 
-	metrics["by_status"] = byStatus
-	metrics["by_stage"] = byStage
-	metrics["by_type"] = byType
-
 	body, err := json.Marshal(metrics)
 	if err != nil {
 		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal metrics"), err
@@ -293,13 +333,275 @@ func (h *WebAPIHandler) handleMetrics(ctx context.Context) (events.APIGatewayV2H
 	}, nil
 }
 
+// scheduleResponse is a schedule as returned by the API, with derived timing fields
+type scheduleResponse struct {
+	*models.Schedule
+	OneTime          bool       `json:"one_time"`
+	RunAt            *time.Time `json:"run_at,omitempty"`
+	RemainingSeconds *int64     `json:"remaining_seconds,omitempty"`
+	NextRunAt        *time.Time `json:"next_run_at,omitempty"`
+}
+
+// scheduleListOptions is the list convention supported by GET /api/schedules
+var scheduleListOptions = pagination.Options{
+	DefaultLimit:   100,
+	MaxLimit:       1000,
+	SortFields:     []string{"created_date", "updated_date", "name", "status"},
+	DefaultSort:    "-created_date",
+	FilterFields:   []string{"status", "created_by"},
+	DefaultFilters: map[string]string{"status": string(models.ScheduleStatusActive)},
+}
+
+// handleListSchedules returns a page of schedules filtered by status (default: active); members
+// only see the schedules they created
+func (h *WebAPIHandler) handleListSchedules(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	user, response, ok := h.authenticate(ctx, request)
+	if !ok {
+		return response, nil
+	}
+
+	query, err := pagination.Parse(legacyFilters(request.QueryStringParameters, "status"), scheduleListOptions)
+	if err != nil {
+		return h.createValidationResponse(listQueryViolations(err)), nil
+	}
+
+	value, _ := query.Filter("status")
+	status := models.ScheduleStatus(value)
+	if !status.IsValid() {
+		return h.createValidationResponse([]fieldViolation{{Field: "filter[status]", Message: fmt.Sprintf("%q is not a valid schedule status", value)}}), nil
+	}
+
+	var schedules []*models.Schedule
+	if user != nil && !user.IsAdmin() {
+		schedules, err = h.scheduleRepository.ListSchedulesByCreator(ctx, user.ID)
+	} else {
+		schedules, err = h.scheduleRepository.ListSchedulesByStatus(ctx, status)
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list schedules", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve schedules"), err
+	}
+
+	page := pagination.Apply(schedules, query, func(s *models.Schedule, field string) string {
+		switch field {
+		case "created_date":
+			return pagination.Time(s.CreatedDate)
+		case "updated_date":
+			return pagination.Time(s.UpdatedDate)
+		case "name":
+			return s.Name
+		case "status":
+			return string(s.Status)
+		case "created_by":
+			return s.CreatedBy
+		}
+		return ""
+	})
+
+	now := time.Now().UTC()
+	items := make([]scheduleResponse, 0, len(page.Items))
+	for _, schedule := range page.Items {
+		item := scheduleResponse{Schedule: schedule, OneTime: schedule.IsOneTime()}
+		if item.OneTime {
+			if runAt, err := schedule.RunAt(); err == nil {
+				item.RunAt = &runAt
+			}
+		}
+		if remaining, ok := schedule.TimeRemaining(now); ok {
+			seconds := int64(remaining.Seconds())
+			item.RemainingSeconds = &seconds
+		}
+		if nextRunAt, ok := schedule.NextRunAt(now); ok {
+			item.NextRunAt = &nextRunAt
+		}
+		items = append(items, item)
+	}
+
+	body, err := json.Marshal(pagination.Page[scheduleResponse]{Items: items, NextToken: page.NextToken}.Response("schedules"))
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
+
+// handleCreateSurvey records a post-round survey response
+func (h *WebAPIHandler) handleCreateSurvey(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	var req models.RoundSurvey
+	if err := json.Unmarshal([]byte(request.Body), &req); err != nil {
+		return h.createErrorResponse(http.StatusBadRequest, "invalid request body"), nil
+	}
+
+	survey, err := models.NewRoundSurvey(req.CourseName, req.TeeTime, req.Enjoyment, req.PaceOfPlay, req.PriceFair, req.Comment)
+	if err != nil {
+		return h.createErrorResponse(http.StatusBadRequest, err.Error()), nil
+	}
+
+	if err := h.preferenceRepo.SaveSurvey(ctx, survey); err != nil {
+		h.logger.ErrorContext(ctx, "failed to save survey", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to save survey"), err
+	}
+
+	body, err := json.Marshal(survey)
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusCreated,
+		Body:       string(body),
+	}, nil
+}
+
+// handleGetPreferences returns course and time-of-day preferences from the last year of surveys
+func (h *WebAPIHandler) handleGetPreferences(ctx context.Context) (events.APIGatewayV2HTTPResponse, error) {
+	surveys, err := h.preferenceRepo.ListSurveys(ctx, time.Now().AddDate(-1, 0, 0))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list surveys", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve preferences"), err
+	}
+
+	body, err := json.Marshal(models.ComputePreferences(surveys))
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
+
+// handleApprovalDecision records the golfer's approve/decline decision for a held tee time and
+// queues the web action that completes the reservation or releases the lock
+func (h *WebAPIHandler) handleApprovalDecision(ctx context.Context, request events.APIGatewayV2HTTPRequest, token string) (events.APIGatewayV2HTTPResponse, error) {
+	if token == "" || strings.Contains(token, "/") {
+		return h.createErrorResponse(http.StatusNotFound, "approval not found"), nil
+	}
+
+	approval, err := h.approvalRepo.GetApproval(ctx, token)
+	if err != nil {
+		return h.createErrorResponse(http.StatusNotFound, "approval not found"), nil
+	}
+
+	next, err := approval.Decide(request.QueryStringParameters["decision"], time.Now())
+	if err != nil {
+		if approval.Status != models.ApprovalStatusPending {
+			return h.createErrorResponse(http.StatusConflict, err.Error()), nil
+		}
+		return h.createErrorResponse(http.StatusBadRequest, err.Error()), nil
+	}
+
+	if err := h.approvalRepo.TransitionApproval(ctx, approval.ID, models.ApprovalStatusPending, next); err != nil {
+		return h.createErrorResponse(http.StatusConflict, err.Error()), nil
+	}
+	approval.Status = next
+
+	h.logger.InfoContext(ctx, "booking approval decided",
+		slog.String("approval_id", approval.ID),
+		slog.String("status", next.String()),
+	)
+
+	if next == models.ApprovalStatusExpired {
+		return h.createErrorResponse(http.StatusGone, "approval window has expired"), nil
+	}
+	if response, err := h.queueApprovalDecision(ctx, approval); err != nil {
+		return response, err
+	}
+
+	if next == models.ApprovalStatusDeclined {
+		h.auditRecorder.Record(ctx, audit.Event{
+			Action:   models.AuditActionBookingCancellation,
+			Target:   approval.ID,
+			SourceIP: request.RequestContext.HTTP.SourceIP,
+			Details: map[string]string{
+				"course_name":  approval.CourseName,
+				"tee_sheet_id": strconv.Itoa(approval.TeeSheetID),
+			},
+		})
+	}
+
+	body, err := json.Marshal(approval)
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
+
+// queueApprovalDecision publishes the web action that carries out a decided approval: completing
+// the reservation of an approved tee time, or releasing the lock on a declined one. If it cannot
+// be published the approval goes back to pending, so the decision can be made again rather than
+// stranding a tee time nobody will reserve or release.
+func (h *WebAPIHandler) queueApprovalDecision(ctx context.Context, approval *models.BookingApproval) (events.APIGatewayV2HTTPResponse, error) {
+	operation := "complete_booking"
+	if approval.Status == models.ApprovalStatusDeclined {
+		operation = "release_lock"
+	}
+
+	response, err := h.queueApprovalAction(ctx, approval, operation)
+	if err != nil {
+		if rerr := h.approvalRepo.TransitionApproval(ctx, approval.ID, approval.Status, models.ApprovalStatusPending); rerr != nil {
+			h.logger.ErrorContext(ctx, "failed to reopen booking approval",
+				slog.String("approval_id", approval.ID),
+				slog.String("error", rerr.Error()),
+			)
+		}
+	}
+	return response, err
+}
+
+// queueApprovalAction publishes the golf web action operation for an approval's held tee time
+func (h *WebAPIHandler) queueApprovalAction(ctx context.Context, approval *models.BookingApproval, operation string) (events.APIGatewayV2HTTPResponse, error) {
+	course, err := courses.GetCourseByID(approval.CourseID)
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "course not found"), err
+	}
+	// Act as the golfer who locked the tee time
+	secretName := approval.SecretName
+	if secretName == "" {
+		secretName = course.GetSecretName(h.config.Stage.String())
+	}
+
+	msg := models.NewMessage("webapi", map[string]interface{}{
+		"operation": operation,
+	}, "1.0", h.config.Stage, models.MessageTypeWebAction, map[string]interface{}{
+		"action":        string(models.WebActionTypeGolf),
+		"courseID":      approval.CourseID,
+		"approvalToken": approval.ID,
+		"auth_config": map[string]interface{}{
+			"type":        string(models.AuthTypeOAuthPassword),
+			"secret_name": secretName,
+		},
+	})
+
+	// A saved action is carried out once the outbox publishes it, so the approval stays decided
+	if err := h.outbox.Send(ctx, msg); err != nil {
+		if !errors.Is(err, outbox.ErrNotPublished) {
+			h.logger.ErrorContext(ctx, "failed to send message", slog.String("error", err.Error()))
+			return h.createErrorResponse(http.StatusInternalServerError, "failed to send message"), err
+		}
+		h.logger.WarnContext(ctx, "approval action saved for the outbox to publish",
+			slog.String("approval_id", approval.ID),
+			slog.String("message_id", msg.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+	return events.APIGatewayV2HTTPResponse{}, nil
+}
+
 // createErrorResponse creates a standardized error response
 func (h *WebAPIHandler) createErrorResponse(statusCode int, message string) events.APIGatewayV2HTTPResponse {
-	errorBody := map[string]string{
-		"error":  message,
-		"status": strconv.Itoa(statusCode),
-	}
-	body, _ := json.Marshal(errorBody)
+	body, _ := json.Marshal(errorBody{
+		Error:  message,
+		Status: strconv.Itoa(statusCode),
+	})
 
 	return events.APIGatewayV2HTTPResponse{
 		StatusCode: statusCode,
@@ -309,23 +611,22 @@ func (h *WebAPIHandler) createErrorResponse(statusCode int, message string) even
 
 func main() {
 	// Setup structured logging
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: logging.GetLogLevel(),
-	}))
+	})))
 	slog.SetDefault(logger)
 
 	// Load configuration
-	cfg := appconfig.MustLoad()
+	cfg := appconfig.MustLoad(appconfig.GroupTopicRouting, appconfig.GroupWebActions, appconfig.GroupScheduleRequests)
 
 	logger.Info("web api lambda starting",
 		slog.String("stage", cfg.Stage.String()),
 		slog.String("region", cfg.AWSRegion),
 	)
+	logger.Debug("resolved configuration", slog.Any("config", cfg))
 
 	// Initialize AWS SDK
-	awsCfg, err := config.LoadDefaultConfig(context.Background(),
-		config.WithRegion(cfg.AWSRegion),
-	)
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
 	if err != nil {
 		logger.Error("failed to load AWS config", slog.String("error", err.Error()))
 		panic(fmt.Sprintf("failed to load AWS config: %v", err))
@@ -334,29 +635,99 @@ func main() {
 	// Create AWS clients
 	dynamoClient := dynamodb.NewFromConfig(awsCfg)
 	snsClient := sns.NewFromConfig(awsCfg)
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		// LocalStack serves buckets by path rather than by virtual host
+		o.UsePathStyle = cfg.Local
+	})
+	schedulerClient := awsscheduler.NewFromConfig(awsCfg)
 
 	// Create repositories
 	repo := repository.NewDynamoDBRepository(dynamoClient, cfg.DynamoDBTableName)
 	scheduleRepo := repository.NewDynamoDBScheduleRepository(dynamoClient, cfg.SchedulesTableName)
+	preferenceRepo := repository.NewDynamoDBPreferenceRepository(dynamoClient, cfg.PreferencesTableName)
+	approvalRepo := repository.NewDynamoDBApprovalRepository(dynamoClient, cfg.ApprovalsTableName)
 
-	// Use topic routing if both topics are configured, otherwise fall back to legacy single topic
-	publisher := messaging.NewTopicRoutingSNSClient(
-		snsClient,
-		cfg.WebActionsSNSTopicArn,
-		cfg.NotificationsSNSTopicArn,
-		cfg.AgentResponseTopicArn,
-		cfg.ScheduleCreationTopicArn,
-		logger,
-	)
+	// Route message types to topics from the configured routing table
+	routes, err := messaging.NewTopicRoutingTable(cfg.TopicRoutes, cfg.NotificationsSNSTopicArn)
+	if err != nil {
+		logger.Error("invalid topic routing configuration", slog.String("error", err.Error()))
+		panic(fmt.Sprintf("invalid topic routing configuration: %v", err))
+	}
+	publisher := messaging.NewTopicRoutingSNSClient(snsClient, routes, logger)
+	publisher.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentWebAPI)
+	publisher.SetEventPublisher(localrun.NewEventPublisher(cfg, awsCfg, logger))
 	logger.Info("using topic-routing SNS client",
-		slog.String("web_actions_topic", cfg.WebActionsSNSTopicArn),
-		slog.String("notifications_topic", cfg.NotificationsSNSTopicArn),
-		slog.String("schedule_creation_topic", cfg.ScheduleCreationTopicArn),
+		slog.Any("routed_message_types", routes.MessageTypes()),
+		slog.String("default_topic", cfg.NotificationsSNSTopicArn),
 	)
 
 	// Create handler
-	handler := NewWebAPIHandler(cfg, repo, scheduleRepo, publisher, logger)
+	handler := NewWebAPIHandler(cfg, repo, scheduleRepo, preferenceRepo, approvalRepo, publisher, logger)
+	if cfg.UsersTableName != "" {
+		handler.SetUserRepository(repository.NewDynamoDBUserRepository(dynamoClient, cfg.UsersTableName))
+		logger.Info("multi-user mode enabled", slog.String("users_table", cfg.UsersTableName))
+	} else {
+		// Without a users table no request carries an identity, so nothing is scoped to a caller
+		logger.Warn("USERS_TABLE_NAME not set, single-user mode: every endpoint is open to any caller",
+			slog.String("stage", cfg.Stage.String()),
+		)
+	}
+	if cfg.ReservationsTableName != "" {
+		handler.SetReservationRepository(repository.NewDynamoDBReservationRepository(dynamoClient, cfg.ReservationsTableName))
+	}
+
+	webActionResultRepo := repository.NewDynamoDBWebActionRepository(dynamoClient, cfg.WebActionResultsTableName)
+	handler.SetWebActionResultRepository(webActionResultRepo)
+
+	auditRepo := repository.NewDynamoDBAuditRepository(dynamoClient, cfg.AuditTableName)
+	handler.SetAuditLog(auditRepo, audit.NewRecorder(auditRepo, "webapi", logger))
+
+	// Data export and deletion reach every store that holds user data
+	privacyService := privacy.NewService(
+		repo,
+		webActionResultRepo,
+		scheduleRepo,
+		repository.NewDynamoDBAgentSessionRepository(dynamoClient, cfg.AgentSessionTableName),
+		auditRepo,
+		"webapi",
+		logger,
+	)
+	privacyService.SetTriggerDeleter(&eventBridgeTriggerDeleter{client: schedulerClient})
+	if agentLogsBucket := os.Getenv("AGENT_LOGS_BUCKET"); agentLogsBucket != "" {
+		privacyService.SetLogStore(internalscheduler.NewRunSummaryPublisher(s3Client, agentLogsBucket, cfg.Stage.String()))
+		if cfg.WebActionBodyOffload {
+			handler.SetResponseBodyStore(bodystore.New(s3Client, agentLogsBucket, cfg.Stage.String()))
+		}
+	}
+	handler.SetPrivacyService(privacyService)
+	handler.SetQueueStats(metrics.NewSQSQueueStats(sqs.NewFromConfig(awsCfg)))
+
+	// Operators can reconcile the schedules table with EventBridge on demand; repairs are audited
+	reconciler := internalscheduler.NewReconciler(scheduleRepo, schedulerClient, cfg.Stage, logger)
+	reconciler.SetAuditRecorder(handler.auditRecorder)
+	if cfg.SchedulerFunctionArn != "" {
+		reconciler.SetExecutionTarget(cfg.SchedulerFunctionArn)
+	}
+	handler.SetScheduleReconciler(reconciler)
 
-	// Start Lambda handler
-	lambda.Start(handler.HandleRequest)
+	// Start Lambda handler; browsers may call it from the stage's allowed origins and read the
+	// correlation ID, and large responses are gzipped for clients that accept it
+	policy := cors.NewPolicy(cfg, http.MethodGet, http.MethodPost, http.MethodDelete).Expose(logging.CorrelationIDHeader)
+	localrun.StartAPI(cfg, localrun.WebAPIAddr, policy.Handler(compress.Handler(cfg.CompressionThreshold, handler.HandleRequest)), logger)
+}
+
+// legacyFilters maps the older ?stage=&status= parameters onto filter[...] so existing
+// clients keep working; an explicit filter[...] wins
+func legacyFilters(params map[string]string, fields ...string) map[string]string {
+	merged := make(map[string]string, len(params))
+	for key, value := range params {
+		merged[key] = value
+	}
+	for _, field := range fields {
+		key := "filter[" + field + "]"
+		if value := params[field]; value != "" && merged[key] == "" {
+			merged[key] = value
+		}
+	}
+	return merged
 }
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+// recentAgentRunWindow is how far back scheduled agent runs count as recent
+const recentAgentRunWindow = 24 * time.Hour
+
+// prometheusMessageSample caps the messages scanned per scrape, matching GET /api/metrics
+const prometheusMessageSample = 1000
+
+// scheduleStatuses are the schedule states reported by the Prometheus endpoint
+var scheduleStatuses = []models.ScheduleStatus{
+	models.ScheduleStatusActive,
+	models.ScheduleStatusPaused,
+	models.ScheduleStatusError,
+	models.ScheduleStatusCompleted,
+}
+
+// queueStatsReader reads a work queue's message counts; satisfied by *metrics.SQSQueueStats
+type queueStatsReader interface {
+	Stats(ctx context.Context, queue metrics.Queue) (metrics.QueueStats, error)
+}
+
+// SetQueueStats adds the message counts of the work queues in the configuration to the
+// Prometheus endpoint
+func (h *WebAPIHandler) SetQueueStats(reader queueStatsReader) {
+	h.queueStats = reader
+}
+
+// configuredQueues are the work queues the configuration names, labelled like their CloudWatch alarms
+func configuredQueues(cfg *appconfig.Config) []metrics.Queue {
+	var queues []metrics.Queue
+	for _, q := range []metrics.Queue{
+		{Name: "web-actions", URL: cfg.WebActionSQSQueueURL},
+		{Name: "notifications", URL: cfg.NotificationSQSQueueURL},
+		{Name: "agent-responses", URL: cfg.AgentResponseQueueURL},
+		{Name: "schedule-creation", URL: cfg.ScheduleCreationQueueURL},
+		{Name: "digest", URL: cfg.DigestSQSQueueURL},
+	} {
+		if q.URL != "" {
+			queues = append(queues, q)
+		}
+	}
+	return queues
+}
+
+// queueFamilies reads the message counts of the configured queues. A queue that cannot be read is
+// left out of the scrape rather than failing it.
+func (h *WebAPIHandler) queueFamilies(ctx context.Context) []metrics.PrometheusFamily {
+	queues := configuredQueues(h.config)
+	if h.queueStats == nil || len(queues) == 0 {
+		return nil
+	}
+	stats := make([]metrics.QueueStats, 0, len(queues))
+	for _, queue := range queues {
+		s, err := h.queueStats.Stats(ctx, queue)
+		if err != nil {
+			h.logger.WarnContext(ctx, "failed to read queue metrics", slog.String("queue", queue.Name), slog.String("error", err.Error()))
+			continue
+		}
+		stats = append(stats, s)
+	}
+	return metrics.QueueFamilies(stats)
+}
+
+// handlePrometheusMetrics renders message, schedule and recent agent run counts, and work queue
+// depths when enabled, in the Prometheus text exposition format. Scrapers authenticate with
+// METRICS_API_KEY as an X-API-Key header or a bearer token; the endpoint is disabled when no key
+// is configured.
+func (h *WebAPIHandler) handlePrometheusMetrics(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.config.MetricsAPIKey == "" {
+		return h.createErrorResponse(http.StatusForbidden, "metrics export is not enabled"), nil
+	}
+	provided := request.Headers["x-api-key"]
+	if provided == "" {
+		provided = strings.TrimPrefix(request.Headers["authorization"], "Bearer ")
+	}
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(h.config.MetricsAPIKey)) != 1 {
+		return h.createErrorResponse(http.StatusUnauthorized, "invalid API key"), nil
+	}
+
+	messages, err := h.repository.ListMessages(ctx, nil, nil, prometheusMessageSample)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to retrieve messages for metrics", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve metrics"), err
+	}
+
+	families := messageFamilies(messages, time.Now())
+
+	schedules := metrics.PrometheusFamily{
+		Name: "rez_agent_schedules",
+		Help: "Schedules by status",
+		Type: metrics.PrometheusGauge,
+	}
+	for _, status := range scheduleStatuses {
+		list, err := h.scheduleRepository.ListSchedulesByStatus(ctx, status)
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to retrieve schedules for metrics", slog.String("error", err.Error()))
+			return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve metrics"), err
+		}
+		schedules.Samples = append(schedules.Samples, metrics.PrometheusSample{
+			Labels: map[string]string{"status": status.String()},
+			Value:  float64(len(list)),
+		})
+	}
+	families = append(families, schedules)
+	families = append(families, h.queueFamilies(ctx)...)
+
+	var body strings.Builder
+	if err := metrics.WritePrometheus(&body, families); err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to render metrics"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": metrics.PrometheusContentType},
+		Body:       body.String(),
+	}, nil
+}
+
+// messageFamilies counts messages by stage, type and status, and the scheduled agent runs created
+// within recentAgentRunWindow of now by status
+func messageFamilies(messages []*models.Message, now time.Time) []metrics.PrometheusFamily {
+	type messageKey struct{ stage, messageType, status string }
+	byKey := make(map[messageKey]int)
+	var keys []messageKey
+
+	runsByStatus := make(map[models.Status]int)
+	var lastRun time.Time
+
+	for _, msg := range messages {
+		key := messageKey{msg.Stage.String(), msg.MessageType.String(), msg.Status.String()}
+		if _, seen := byKey[key]; !seen {
+			keys = append(keys, key)
+		}
+		byKey[key]++
+
+		if msg.MessageType != models.MessageTypeScheduled {
+			continue
+		}
+		if msg.CreatedDate.After(lastRun) {
+			lastRun = msg.CreatedDate
+		}
+		if now.Sub(msg.CreatedDate) <= recentAgentRunWindow {
+			runsByStatus[msg.Status]++
+		}
+	}
+
+	// Sort for a stable exposition between scrapes
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.stage != b.stage {
+			return a.stage < b.stage
+		}
+		if a.messageType != b.messageType {
+			return a.messageType < b.messageType
+		}
+		return a.status < b.status
+	})
+
+	messageFamily := metrics.PrometheusFamily{
+		Name: "rez_agent_messages",
+		Help: "Messages by stage, type and status among the most recent messages",
+		Type: metrics.PrometheusGauge,
+	}
+	for _, key := range keys {
+		messageFamily.Samples = append(messageFamily.Samples, metrics.PrometheusSample{
+			Labels: map[string]string{"stage": key.stage, "type": key.messageType, "status": key.status},
+			Value:  float64(byKey[key]),
+		})
+	}
+
+	runFamily := metrics.PrometheusFamily{
+		Name: "rez_agent_agent_runs_recent",
+		Help: "Scheduled agent runs created in the last 24 hours by status",
+		Type: metrics.PrometheusGauge,
+	}
+	for _, status := range []models.Status{models.StatusCreated, models.StatusQueued, models.StatusProcessing, models.StatusCompleted, models.StatusFailed} {
+		runFamily.Samples = append(runFamily.Samples, metrics.PrometheusSample{
+			Labels: map[string]string{"status": status.String()},
+			Value:  float64(runsByStatus[status]),
+		})
+	}
+
+	families := []metrics.PrometheusFamily{messageFamily, runFamily}
+	if !lastRun.IsZero() {
+		families = append(families, metrics.PrometheusFamily{
+			Name:    "rez_agent_agent_run_last_created_timestamp_seconds",
+			Help:    "Creation time of the most recent scheduled agent run in Unix seconds",
+			Type:    metrics.PrometheusGauge,
+			Samples: []metrics.PrometheusSample{{Value: float64(lastRun.Unix())}},
+		})
+	}
+	return families
+}
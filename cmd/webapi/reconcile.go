@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	internalscheduler "github.com/jrzesz33/rez_agent/internal/scheduler"
+)
+
+// scheduleReconciler compares the schedules table with EventBridge; satisfied by
+// *internalscheduler.Reconciler
+type scheduleReconciler interface {
+	Reconcile(ctx context.Context, repair bool) (internalscheduler.ReconcileReport, error)
+}
+
+// SetScheduleReconciler enables POST /api/admin/reconcile-schedules
+func (h *WebAPIHandler) SetScheduleReconciler(reconciler scheduleReconciler) {
+	h.reconciler = reconciler
+}
+
+// handleReconcileSchedules reports where the schedules table and EventBridge disagree and, with
+// repair=true, repairs them, like the reconciler Lambda does on its cadence
+func (h *WebAPIHandler) handleReconcileSchedules(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.reconciler == nil {
+		return h.createErrorResponse(http.StatusNotFound, "schedule reconciliation is not enabled"), nil
+	}
+	if response, ok := h.authorizeOperator(ctx, request); !ok {
+		return response, nil
+	}
+
+	repair := false
+	if raw := request.QueryStringParameters["repair"]; raw != "" {
+		var err error
+		if repair, err = strconv.ParseBool(raw); err != nil {
+			return h.createErrorResponse(http.StatusBadRequest, "repair must be true or false"), nil
+		}
+	}
+
+	report, err := h.reconciler.Reconcile(ctx, repair)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to reconcile schedules", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to reconcile schedules"), err
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
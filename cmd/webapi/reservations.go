@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// SetReservationRepository enables GET /api/reservations, which answers from the reservations
+// the sync Lambda copied from the golf courses
+func (h *WebAPIHandler) SetReservationRepository(repo repository.ReservationRepository) {
+	h.reservationRepo = repo
+}
+
+// errNoGolfCredentials is returned by reservationSecretName for a member without golf credentials
+var errNoGolfCredentials = errors.New("user has no golf credentials")
+
+// reservationSecretName returns the credentials whose synced reservations the user may see: a
+// user's own, or for admins without their own and in single-user mode, the shared credentials
+func (h *WebAPIHandler) reservationSecretName(user *models.User) (string, error) {
+	switch {
+	case user != nil && user.GolfSecretName != "":
+		return user.GolfSecretName, nil
+	case user != nil && !user.IsAdmin():
+		return "", errNoGolfCredentials
+	}
+
+	config, err := courses.LoadCourses()
+	if err != nil {
+		return "", fmt.Errorf("no courses configured: %w", err)
+	}
+	if len(config.Courses) == 0 {
+		return "", fmt.Errorf("no courses configured")
+	}
+	return config.Courses[0].GetSecretName(h.config.Stage.String()), nil
+}
+
+// handleListReservations returns the caller's upcoming synced reservations at every course.
+// Members see their own; admins and single-user mode see those of the shared credentials.
+func (h *WebAPIHandler) handleListReservations(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.reservationRepo == nil {
+		return h.createErrorResponse(http.StatusNotFound, "reservation sync is not enabled"), nil
+	}
+	user, response, ok := h.authenticate(ctx, request)
+	if !ok {
+		return response, nil
+	}
+
+	secretName, err := h.reservationSecretName(user)
+	if errors.Is(err, errNoGolfCredentials) {
+		return h.createErrorResponse(http.StatusForbidden, err.Error()), nil
+	}
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, err.Error()), err
+	}
+
+	snapshots, err := h.reservationRepo.ListSnapshots(ctx, secretName)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list reservations", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve reservations"), err
+	}
+
+	// Tee times that have passed since the last sync are not upcoming anymore
+	now := time.Now()
+	for _, snapshot := range snapshots {
+		snapshot.Reservations = snapshot.Upcoming(now)
+	}
+
+	body, err := json.Marshal(reservationListResponse{
+		Reservations: snapshots,
+		Count:        len(snapshots),
+	})
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
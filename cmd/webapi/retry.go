@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// handleRetryMessage requeues a failed message: it counts the retry, marks the message queued,
+// and publishes it to its topic again. Only operators may retry, and a booking that already
+// reserved a tee time is refused so a retry cannot book twice. The requeue is conditional on the
+// message still being failed, so of two concurrent retries only one publishes.
+func (h *WebAPIHandler) handleRetryMessage(ctx context.Context, request events.APIGatewayV2HTTPRequest, id string) (events.APIGatewayV2HTTPResponse, error) {
+	if response, ok := h.authorizeOperator(ctx, request); !ok {
+		return response, nil
+	}
+	if id == "" || strings.Contains(id, "/") {
+		return h.createErrorResponse(http.StatusNotFound, "endpoint not found"), nil
+	}
+
+	message, err := h.repository.GetMessage(ctx, id)
+	if err != nil {
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			h.logger.ErrorContext(ctx, "failed to get message", slog.String("error", err.Error()))
+			return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve message"), err
+		}
+		return h.createErrorResponse(http.StatusNotFound, "message not found"), nil
+	}
+	if message.Status != models.StatusFailed {
+		return h.createErrorResponse(http.StatusConflict, fmt.Sprintf("only failed messages can be retried; this one is %s", message.Status)), nil
+	}
+	if message.IsBooking() {
+		booked, err := h.bookingCompleted(ctx, message)
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to check booking outcome", slog.String("error", err.Error()))
+			return h.createErrorResponse(http.StatusInternalServerError, "failed to check whether the booking went through"), err
+		}
+		if booked {
+			return h.createErrorResponse(http.StatusConflict, "the booking already reserved a tee time; retrying could book a second one"), nil
+		}
+	}
+
+	if err := h.repository.RequeueMessage(ctx, message.ID, models.StatusFailed); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return h.createErrorResponse(http.StatusConflict, "the message is no longer failed; it may already have been retried"), nil
+		}
+		h.logger.ErrorContext(ctx, "failed to requeue message", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to save message"), err
+	}
+	message.Requeue()
+	if err := h.publisher.PublishMessage(ctx, message); err != nil {
+		h.logger.ErrorContext(ctx, "failed to publish message", slog.String("error", err.Error()))
+		// Leave the message failed, not queued, so it can be retried again
+		message.MarkFailed("retry could not be published: " + err.Error())
+		if updateErr := h.repository.FailMessage(ctx, message.ID, models.StatusQueued, message.ErrorMessage); updateErr != nil {
+			h.logger.ErrorContext(ctx, "failed to update message status", slog.String("error", updateErr.Error()))
+		}
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to publish message"), err
+	}
+
+	h.logger.InfoContext(ctx, "message requeued",
+		slog.String("message_id", message.ID),
+		slog.String("message_type", message.MessageType.String()),
+		slog.Int("retry_count", message.RetryCount),
+	)
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusAccepted,
+		Body:       string(body),
+	}, nil
+}
+
+// bookingCompleted reports whether a failed booking message reserved a tee time anyway: the
+// reservation can succeed before a later step fails, so both its web action results and the
+// audit log's booking entries are checked
+func (h *WebAPIHandler) bookingCompleted(ctx context.Context, message *models.Message) (bool, error) {
+	if h.webActionResultRepo != nil {
+		results, err := h.webActionResultRepo.ListResultsByMessageID(ctx, message.ID)
+		if err != nil {
+			return false, err
+		}
+		for _, result := range results {
+			if result.Status == models.StatusCompleted {
+				return true, nil
+			}
+		}
+	}
+
+	if h.auditRepo != nil && message.CorrelationID != "" {
+		entries, err := h.auditRepo.ListEntries(ctx, message.CreatedDate)
+		if err != nil {
+			return false, err
+		}
+		for _, entry := range entries {
+			if entry.Action == models.AuditActionBooking && entry.CorrelationID == message.CorrelationID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/agent"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/openapi"
+	"github.com/jrzesz33/rez_agent/internal/pagination"
+	"github.com/jrzesz33/rez_agent/internal/privacy"
+	internalscheduler "github.com/jrzesz33/rez_agent/internal/scheduler"
+)
+
+// openAPIPath serves the API's OpenAPI document, and docsPath browses it with Swagger UI
+const (
+	openAPIPath = "/api/openapi.json"
+	docsPath    = "/api/docs"
+)
+
+// route is an endpoint of the web API: its documentation and the handler it dispatches to
+type route struct {
+	openapi.Route
+	handle func(h *WebAPIHandler, ctx context.Context, request events.APIGatewayV2HTTPRequest, params map[string]string) (events.APIGatewayV2HTTPResponse, error)
+}
+
+// healthResponse is the body of GET /api/health
+type healthResponse struct {
+	Status    string `json:"status"`
+	Timestamp string `json:"timestamp"`
+	Stage     string `json:"stage"`
+}
+
+// metricsResponse is the body of GET /api/metrics: message counts over the latest 1000 messages
+type metricsResponse struct {
+	Total    int            `json:"total"`
+	ByStatus map[string]int `json:"by_status"`
+	ByStage  map[string]int `json:"by_stage"`
+	ByType   map[string]int `json:"by_type"`
+}
+
+// calendarURLResponse is the body of GET /api/calendar
+type calendarURLResponse struct {
+	URL string `json:"url"`
+}
+
+// webActionBodyResponse is the body of GET /api/web-actions/{id}/body
+type webActionBodyResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// reservationListResponse is the body of GET /api/reservations
+type reservationListResponse struct {
+	Reservations []*models.ReservationSnapshot `json:"reservations"`
+	Count        int                           `json:"count"`
+}
+
+// errorBody is the body of every error response other than validation failures
+type errorBody struct {
+	Error  string `json:"error"`
+	Status string `json:"status"`
+}
+
+// Error statuses shared by most routes
+var (
+	authErrors  = []int{http.StatusUnauthorized, http.StatusInternalServerError}
+	listErrors  = []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError}
+	tokenErrors = []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusGone, http.StatusInternalServerError}
+	dataErrors  = []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError}
+)
+
+// webAPIRoutes are the web API's endpoints in dispatch order
+var webAPIRoutes = []route{
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/health", Tag: "health", Summary: "Check that the API is up", Response: healthResponse{}, Public: true},
+		handle: func(h *WebAPIHandler, ctx context.Context, _ events.APIGatewayV2HTTPRequest, _ map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+			return h.handleHealth(ctx)
+		},
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: openAPIPath, Tag: "docs", Summary: "Get this OpenAPI document", Public: true},
+		handle: func(h *WebAPIHandler, ctx context.Context, _ events.APIGatewayV2HTTPRequest, _ map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+			return h.handleOpenAPI(ctx)
+		},
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: docsPath, Tag: "docs", Summary: "Browse the API with Swagger UI", Response: "", ResponseContentType: "text/html", Public: true},
+		handle: func(h *WebAPIHandler, ctx context.Context, _ events.APIGatewayV2HTTPRequest, _ map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+			return h.handleDocs(ctx)
+		},
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/messages", Tag: "messages", Summary: "List messages",
+			Description: "Members only see the messages they created.",
+			Query:       listParams(messageListOptions, "stage", "status"), Response: openapi.List("messages", models.Message{}), Errors: listErrors},
+		handle: withRequest((*WebAPIHandler).handleListMessages),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodPost, Path: "/api/messages", Tag: "messages", Summary: "Create a message",
+			Description: "The payload is checked against the message type's schema; see docs/MESSAGE_SCHEMAS.md.",
+			Request:     models.Message{}, Response: models.Message{}, Status: http.StatusCreated,
+			Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError}},
+		handle: withRequest((*WebAPIHandler).handleCreateMessage),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodPost, Path: "/api/messages/{id}/retry", Tag: "messages", Summary: "Retry a failed message",
+			Description: "Only admins, or the operator's X-API-Key, may retry. A booking that already reserved a tee time is refused, as is a message another retry requeued first.",
+			Response:    models.Message{}, Status: http.StatusAccepted,
+			Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError}},
+		handle: withID((*WebAPIHandler).handleRetryMessage),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/metrics", Tag: "metrics", Summary: "Count messages by status, stage and type", Response: metricsResponse{}, Errors: []int{http.StatusInternalServerError}},
+		handle: func(h *WebAPIHandler, ctx context.Context, _ events.APIGatewayV2HTTPRequest, _ map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+			return h.handleMetrics(ctx)
+		},
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/metrics/prometheus", Tag: "metrics", Summary: "Export metrics in the Prometheus text format",
+			Description: "Authenticates with METRICS_API_KEY as an X-API-Key header or a bearer token.",
+			Response:    "", ResponseContentType: "text/plain", Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError}},
+		handle: withRequest((*WebAPIHandler).handlePrometheusMetrics),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/schedules", Tag: "schedules", Summary: "List schedules",
+			Description: "Filters on active schedules unless filter[status] is given. Members only see the schedules they created.",
+			Query:       listParams(scheduleListOptions, "status"), Response: openapi.List("schedules", scheduleResponse{}), Errors: listErrors},
+		handle: withRequest((*WebAPIHandler).handleListSchedules),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/web-actions", Tag: "web-actions", Summary: "List web action results",
+			Description: "Response bodies are cut to a preview; get a single result for its stored body.",
+			Query:       listParams(webActionResultListOptions, "message_id"), Response: openapi.List("web_actions", webActionResultResponse{}), Errors: append(listErrors, http.StatusNotFound)},
+		handle: withRequest((*WebAPIHandler).handleListWebActionResults),
+	},
+	{
+		Route:  openapi.Route{Method: http.MethodGet, Path: "/api/web-actions/{id}", Tag: "web-actions", Summary: "Get a web action result", Response: webActionResultResponse{}, Errors: []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError}},
+		handle: withID((*WebAPIHandler).handleGetWebActionResult),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/web-actions/{id}/body", Tag: "web-actions", Summary: "Link to a web action result's full response body",
+			Description: "Answers with a presigned S3 link, valid for 15 minutes, to the gzipped body of a result whose body was too large for the results table. Needs WEB_ACTION_BODY_OFFLOAD.",
+			Response:    webActionBodyResponse{}, Errors: []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError}},
+		handle: withID((*WebAPIHandler).handleGetWebActionBody),
+	},
+	{
+		Route:  openapi.Route{Method: http.MethodGet, Path: "/api/reservations", Tag: "reservations", Summary: "List upcoming synced reservations by course", Response: reservationListResponse{}, Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError}},
+		handle: withRequest((*WebAPIHandler).handleListReservations),
+	},
+	{
+		Route:  openapi.Route{Method: http.MethodGet, Path: "/api/calendar", Tag: "calendar", Summary: "Get the caller's calendar feed URL", Response: calendarURLResponse{}, Errors: []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError}},
+		handle: withRequest((*WebAPIHandler).handleGetCalendarURL),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/calendar.ics", Tag: "calendar", Summary: "Subscribe to the iCalendar feed of reservations and scheduled runs",
+			Query:    []openapi.Param{{Name: "token", Description: "Signed feed token from GET /api/calendar", Required: true}, {Name: "user", Description: "User the feed belongs to"}},
+			Response: "", ResponseContentType: "text/calendar", Errors: []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError}, Public: true},
+		handle: withRequest((*WebAPIHandler).handleCalendarFeed),
+	},
+	{
+		Route:  openapi.Route{Method: http.MethodPost, Path: "/api/surveys", Tag: "surveys", Summary: "Record a post-round survey", Request: models.RoundSurvey{}, Response: models.RoundSurvey{}, Status: http.StatusCreated, Errors: []int{http.StatusBadRequest, http.StatusInternalServerError}},
+		handle: withRequest((*WebAPIHandler).handleCreateSurvey),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/preferences", Tag: "surveys", Summary: "Get preferences learned from surveys", Response: models.Preferences{}, Errors: []int{http.StatusInternalServerError}},
+		handle: func(h *WebAPIHandler, ctx context.Context, _ events.APIGatewayV2HTTPRequest, _ map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+			return h.handleGetPreferences(ctx)
+		},
+	},
+	{
+		Route: openapi.Route{Method: http.MethodPost, Path: "/api/users", Tag: "users", Summary: "Create a user",
+			Description: "Only admins, or the operator's X-API-Key, may create users. The response carries the only copy of the user's API key.",
+			Request:     createUserRequest{}, Response: createUserResponse{}, Status: http.StatusCreated,
+			Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError}},
+		handle: withRequest((*WebAPIHandler).handleCreateUser),
+	},
+	{
+		Route:  openapi.Route{Method: http.MethodGet, Path: "/api/users/me", Tag: "users", Summary: "Get the caller's user record", Response: models.User{}, Errors: append(authErrors, http.StatusNotFound)},
+		handle: withRequest((*WebAPIHandler).handleGetCurrentUser),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/data/export", Tag: "data", Summary: "Export everything stored about a user",
+			Query:    []openapi.Param{{Name: "user_id", Required: true}, {Name: "session_ids", Description: "Comma-separated agent session IDs"}},
+			Response: privacy.Archive{}, Errors: dataErrors},
+		handle: withRequest((*WebAPIHandler).handleExportData),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodDelete, Path: "/api/data", Tag: "data", Summary: "Delete everything stored about a user",
+			Query:    []openapi.Param{{Name: "user_id", Required: true}, {Name: "confirm", Description: "Repeats user_id", Required: true}, {Name: "session_ids", Description: "Comma-separated agent session IDs"}},
+			Response: models.AuditEntry{}, Errors: dataErrors},
+		handle: withRequest((*WebAPIHandler).handleDeleteData),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/api/audit", Tag: "audit", Summary: "List audit log entries",
+			Query:    append(listParams(auditListOptions), openapi.Param{Name: "since", Description: "RFC 3339 timestamp (default 30 days ago)"}),
+			Response: openapi.List("entries", models.AuditEntry{}), Errors: append(listErrors, http.StatusForbidden, http.StatusNotFound)},
+		handle: withRequest((*WebAPIHandler).handleListAuditEntries),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodPost, Path: "/api/admin/reconcile-schedules", Tag: "admin", Summary: "Reconcile schedules with EventBridge",
+			Description: "Reports schedules whose EventBridge schedule is missing, EventBridge schedules no schedule uses, and EventBridge schedules that drifted from their schedule. Only admins, or the operator's X-API-Key, may reconcile.",
+			Query:       []openapi.Param{{Name: "repair", Description: "true also repairs what was found (default false)"}},
+			Response:    internalscheduler.ReconcileReport{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError}},
+		handle: withRequest((*WebAPIHandler).handleReconcileSchedules),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodPost, Path: "/api/approvals/{token}/rsvp", Tag: "approvals", Summary: "Answer a group booking RSVP",
+			Query:    []openapi.Param{{Name: "member", Required: true}, {Name: "decision", Description: "accept or decline", Required: true}},
+			Response: models.BookingApproval{}, Errors: tokenErrors, Public: true},
+		handle: withToken((*WebAPIHandler).handleGroupRSVP),
+	},
+	{
+		Route: openapi.Route{Method: http.MethodPost, Path: "/api/approvals/{token}", Tag: "approvals", Summary: "Approve or decline a held tee time",
+			Query:    []openapi.Param{{Name: "decision", Description: "approve or decline", Required: true}},
+			Response: models.BookingApproval{}, Errors: tokenErrors, Public: true},
+		handle: withToken((*WebAPIHandler).handleApprovalDecision),
+	},
+}
+
+// withRequest adapts a handler that reads the request
+func withRequest(handle func(*WebAPIHandler, context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error)) func(*WebAPIHandler, context.Context, events.APIGatewayV2HTTPRequest, map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+	return func(h *WebAPIHandler, ctx context.Context, request events.APIGatewayV2HTTPRequest, _ map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+		return handle(h, ctx, request)
+	}
+}
+
+// withID adapts a handler of the resource named by the {id} path segment
+func withID(handle func(*WebAPIHandler, context.Context, events.APIGatewayV2HTTPRequest, string) (events.APIGatewayV2HTTPResponse, error)) func(*WebAPIHandler, context.Context, events.APIGatewayV2HTTPRequest, map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+	return func(h *WebAPIHandler, ctx context.Context, request events.APIGatewayV2HTTPRequest, params map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+		return handle(h, ctx, request, params["id"])
+	}
+}
+
+// withToken adapts a handler of the approval named by the {token} path segment
+func withToken(handle func(*WebAPIHandler, context.Context, events.APIGatewayV2HTTPRequest, string) (events.APIGatewayV2HTTPResponse, error)) func(*WebAPIHandler, context.Context, events.APIGatewayV2HTTPRequest, map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+	return func(h *WebAPIHandler, ctx context.Context, request events.APIGatewayV2HTTPRequest, params map[string]string) (events.APIGatewayV2HTTPResponse, error) {
+		return handle(h, ctx, request, params["token"])
+	}
+}
+
+// listParams documents the list convention of a list endpoint, plus the legacy filter aliases it accepts
+func listParams(opts pagination.Options, legacy ...string) []openapi.Param {
+	params := []openapi.Param{
+		{Name: "limit", Description: "Page size"},
+		{Name: "next_token", Description: "Token from the previous page's next_token"},
+		{Name: "sort", Description: "Comma-separated fields of " + strings.Join(opts.SortFields, ", ") + "; prefix - for descending (default " + opts.DefaultSort + ")"},
+	}
+	for _, field := range opts.FilterFields {
+		description := "Exact-match filter on " + field
+		if value, ok := opts.DefaultFilters[field]; ok {
+			description += " (default " + value + ")"
+		}
+		params = append(params, openapi.Param{Name: "filter[" + field + "]", Description: description})
+	}
+	for _, field := range legacy {
+		params = append(params, openapi.Param{Name: field, Description: "Alias of filter[" + field + "]"})
+	}
+	return params
+}
+
+// matchRoute finds the route serving method and path
+func matchRoute(routes []route, method, path string) (route, map[string]string, bool) {
+	for _, r := range routes {
+		if params, ok := r.Match(method, path); ok {
+			return r, params, true
+		}
+	}
+	return route{}, nil, false
+}
+
+// openAPIDocument documents the web API's routes together with the agent's, which share its API Gateway
+func openAPIDocument(routes []route) *openapi.Document {
+	documented := make([]openapi.Route, 0, len(routes))
+	for _, r := range routes {
+		documented = append(documented, r.Route)
+	}
+	documented = append(documented, agent.APIRoutes()...)
+
+	return openapi.Build(openapi.Options{
+		Title:             "rez_agent API",
+		Version:           "1.0",
+		Description:       "Messages, schedules, metrics and the golf assistant agent. In multi-user mode, routes that are not public need a user's API key as a bearer token.",
+		ErrorBody:         errorBody{},
+		ValidationProblem: problemDetails{},
+		SecurityScheme:    "apiKey",
+	}, documented...)
+}
+
+// handleOpenAPI returns the OpenAPI document of the web API and agent routes
+func (h *WebAPIHandler) handleOpenAPI(ctx context.Context) (events.APIGatewayV2HTTPResponse, error) {
+	body, err := json.Marshal(openAPIDocument(h.routes))
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal OpenAPI document"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
+
+// handleDocs serves Swagger UI over the OpenAPI document
+func (h *WebAPIHandler) handleDocs(ctx context.Context) (events.APIGatewayV2HTTPResponse, error) {
+	page, err := openapi.SwaggerUI("rez_agent API", openAPIPath)
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to render API docs"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "text/html; charset=utf-8"},
+		Body:       page,
+	}, nil
+}
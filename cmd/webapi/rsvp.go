@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// handleGroupRSVP records a group member's accept/decline answer for a held tee time. Once every
+// member has answered, the booking is completed for those who accepted, or declined if nobody did.
+func (h *WebAPIHandler) handleGroupRSVP(ctx context.Context, request events.APIGatewayV2HTTPRequest, token string) (events.APIGatewayV2HTTPResponse, error) {
+	if token == "" || strings.Contains(token, "/") {
+		return h.createErrorResponse(http.StatusNotFound, "approval not found"), nil
+	}
+
+	approval, err := h.approvalRepo.GetApproval(ctx, token)
+	if err != nil {
+		return h.createErrorResponse(http.StatusNotFound, "approval not found"), nil
+	}
+	index := approval.RSVPIndex(request.QueryStringParameters["member"])
+	if index < 0 {
+		return h.createErrorResponse(http.StatusNotFound, "rsvp not found"), nil
+	}
+
+	now := time.Now()
+	if approval.Status == models.ApprovalStatusPending && approval.IsExpired(now) {
+		if err := h.approvalRepo.TransitionApproval(ctx, approval.ID, models.ApprovalStatusPending, models.ApprovalStatusExpired); err != nil {
+			h.logger.WarnContext(ctx, "failed to expire booking approval", slog.String("error", err.Error()))
+		}
+		return h.createErrorResponse(http.StatusGone, "approval window has expired"), nil
+	}
+
+	rsvp, err := approval.RespondRSVP(index, request.QueryStringParameters["decision"], now)
+	if err != nil {
+		if approval.Status != models.ApprovalStatusPending || approval.RSVPs[index].Status != models.RSVPStatusPending {
+			return h.createErrorResponse(http.StatusConflict, err.Error()), nil
+		}
+		return h.createErrorResponse(http.StatusBadRequest, err.Error()), nil
+	}
+	if err := h.approvalRepo.RecordRSVP(ctx, approval.ID, index, rsvp); err != nil {
+		return h.createErrorResponse(http.StatusConflict, err.Error()), nil
+	}
+
+	h.logger.InfoContext(ctx, "group rsvp recorded",
+		slog.String("approval_id", approval.ID),
+		slog.String("member", approval.RSVPs[index].Name),
+		slog.String("rsvp", rsvp.String()),
+	)
+
+	// Re-read so answers recorded concurrently by other members are counted
+	approval, err = h.approvalRepo.GetApproval(ctx, approval.ID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to reload booking approval", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to reload approval"), err
+	}
+
+	if approval.Status == models.ApprovalStatusPending && approval.AllResponded() {
+		next := models.ApprovalStatusApproved
+		if approval.ConfirmedPlayers() == 0 {
+			next = models.ApprovalStatusDeclined
+		}
+		// The last two answers can race here; only one of them moves the approval on
+		if err := h.approvalRepo.TransitionApproval(ctx, approval.ID, models.ApprovalStatusPending, next); err != nil {
+			h.logger.InfoContext(ctx, "group booking already decided", slog.String("approval_id", approval.ID))
+		} else {
+			approval.Status = next
+			h.logger.InfoContext(ctx, "group booking decided by rsvps",
+				slog.String("approval_id", approval.ID),
+				slog.String("status", next.String()),
+				slog.Int("players", approval.ConfirmedPlayers()),
+			)
+			if response, err := h.queueApprovalDecision(ctx, approval); err != nil {
+				return response, err
+			}
+		}
+	}
+
+	body, err := json.Marshal(approval)
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// SetUserRepository enables multi-user mode: the messages and schedules endpoints require a
+// user's API key and only show members what they created
+func (h *WebAPIHandler) SetUserRepository(repo repository.UserRepository) {
+	h.userRepo = repo
+}
+
+// authenticate resolves the user of an "Authorization: Bearer <api key>" header. In single-user
+// mode there are no users, and every request is allowed with a nil user.
+func (h *WebAPIHandler) authenticate(ctx context.Context, request events.APIGatewayV2HTTPRequest) (*models.User, events.APIGatewayV2HTTPResponse, bool) {
+	if h.userRepo == nil {
+		return nil, events.APIGatewayV2HTTPResponse{}, true
+	}
+
+	// API Gateway HTTP APIs lowercase header names
+	apiKey, ok := strings.CutPrefix(request.Headers["authorization"], "Bearer ")
+	if !ok || apiKey == "" {
+		return nil, h.createErrorResponse(http.StatusUnauthorized, "missing API key"), false
+	}
+
+	user, err := h.userRepo.GetUserByAPIKeyHash(ctx, models.HashAPIKey(apiKey))
+	if err != nil {
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			h.logger.ErrorContext(ctx, "failed to look up user", slog.String("error", err.Error()))
+			return nil, h.createErrorResponse(http.StatusInternalServerError, "failed to authenticate"), false
+		}
+		return nil, h.createErrorResponse(http.StatusUnauthorized, "invalid API key"), false
+	}
+	if user.Disabled {
+		return nil, h.createErrorResponse(http.StatusForbidden, "user is disabled"), false
+	}
+	return user, events.APIGatewayV2HTTPResponse{}, true
+}
+
+// scopeCredentials makes a member's message authenticate as that member: golf actions use the
+// member's own credentials, and no other secret may be named. Admins keep whatever they send.
+func scopeCredentials(msg *models.Message, user *models.User) error {
+	if user == nil || user.IsAdmin() {
+		return nil
+	}
+	if msg.AuthConfig != nil && msg.AuthConfig.SecretName != "" && msg.AuthConfig.SecretName != user.GolfSecretName {
+		return fmt.Errorf("members may only use their own credentials")
+	}
+
+	authConfig, _ := msg.Payload["auth_config"].(map[string]interface{})
+	golf := msg.Payload["action"] == string(models.WebActionTypeGolf) ||
+		(authConfig != nil && authConfig["type"] == string(models.AuthTypeOAuthPassword))
+	if !golf {
+		if secretName, _ := authConfig["secret_name"].(string); secretName != "" && secretName != user.GolfSecretName {
+			return fmt.Errorf("members may only use their own credentials")
+		}
+		return nil
+	}
+
+	secretName, err := user.GolfSecret()
+	if err != nil {
+		return err
+	}
+	msg.Payload["auth_config"] = map[string]interface{}{
+		"type":        string(models.AuthTypeOAuthPassword),
+		"secret_name": secretName,
+	}
+	return nil
+}
+
+// handleGetCurrentUser returns the authenticated user
+func (h *WebAPIHandler) handleGetCurrentUser(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.userRepo == nil {
+		return h.createErrorResponse(http.StatusNotFound, "multi-user mode is not enabled"), nil
+	}
+	user, response, ok := h.authenticate(ctx, request)
+	if !ok {
+		return response, nil
+	}
+
+	body, err := json.Marshal(user)
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
+
+// createUserRequest is the body of POST /api/users
+type createUserRequest struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Email          string          `json:"email"`
+	Role           models.UserRole `json:"role"`
+	GolfSecretName string          `json:"golf_secret_name"`
+}
+
+// createUserResponse returns the new user's API key, the only time it is available
+type createUserResponse struct {
+	*models.User
+	APIKey string `json:"api_key"`
+}
+
+// handleCreateUser creates a user. Admins may create users; the first admin is created with the
+// operator's data request key (X-API-Key), since no user exists yet to authenticate with.
+func (h *WebAPIHandler) handleCreateUser(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.userRepo == nil {
+		return h.createErrorResponse(http.StatusNotFound, "multi-user mode is not enabled"), nil
+	}
+	if !h.isOperator(request) {
+		user, response, ok := h.authenticate(ctx, request)
+		if !ok {
+			return response, nil
+		}
+		if !user.IsAdmin() {
+			return h.createErrorResponse(http.StatusForbidden, "only admins can create users"), nil
+		}
+	}
+
+	var req createUserRequest
+	if err := json.Unmarshal([]byte(request.Body), &req); err != nil {
+		return h.createErrorResponse(http.StatusBadRequest, "invalid request body"), nil
+	}
+
+	user, apiKey, err := models.NewUser(req.ID, req.Name, req.Email, req.Role, req.GolfSecretName)
+	if err != nil {
+		return h.createErrorResponse(http.StatusBadRequest, err.Error()), nil
+	}
+	if err := h.userRepo.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrUserExists) {
+			return h.createErrorResponse(http.StatusConflict, err.Error()), nil
+		}
+		h.logger.ErrorContext(ctx, "failed to create user", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to create user"), err
+	}
+
+	h.logger.InfoContext(ctx, "user created",
+		slog.String("user_id", user.ID),
+		slog.String("role", user.Role.String()),
+	)
+
+	body, err := json.Marshal(createUserResponse{User: user, APIKey: apiKey})
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusCreated,
+		Body:       string(body),
+	}, nil
+}
+
+// isOperator reports whether the request carries the operator's data request key
+func (h *WebAPIHandler) isOperator(request events.APIGatewayV2HTTPRequest) bool {
+	provided := request.Headers["x-api-key"]
+	return h.config.DataRequestAPIKey != "" &&
+		subtle.ConstantTimeCompare([]byte(provided), []byte(h.config.DataRequestAPIKey)) == 1
+}
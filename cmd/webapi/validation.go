@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/pagination"
+)
+
+// problemContentType is the media type of RFC 9457 problem details
+const problemContentType = "application/problem+json"
+
+// fieldViolation is one rejected field of a request
+type fieldViolation struct {
+	// Field is the JSON path of a body field, such as "arguments.timezone", or a query parameter
+	Field string `json:"field"`
+
+	// Message says what is wrong with the field
+	Message string `json:"message"`
+}
+
+// problemDetails is an RFC 9457 problem details body, extended with the rejected fields
+type problemDetails struct {
+	Type   string           `json:"type"`
+	Title  string           `json:"title"`
+	Status int              `json:"status"`
+	Detail string           `json:"detail"`
+	Errors []fieldViolation `json:"errors"`
+}
+
+// createValidationResponse answers 400 Bad Request with problem details listing every rejected field
+func (h *WebAPIHandler) createValidationResponse(violations []fieldViolation) events.APIGatewayV2HTTPResponse {
+	detail := fmt.Sprintf("%d field(s) failed validation", len(violations))
+	if len(violations) == 1 {
+		detail = violations[0].Field + ": " + violations[0].Message
+	}
+	body, _ := json.Marshal(problemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusBadRequest),
+		Status: http.StatusBadRequest,
+		Detail: detail,
+		Errors: violations,
+	})
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusBadRequest,
+		Headers:    map[string]string{"Content-Type": problemContentType},
+		Body:       string(body),
+	}
+}
+
+// decodeMessageRequest parses a POST /api/messages body, naming the field a mistyped value is in
+func decodeMessageRequest(body string) (*models.Message, []fieldViolation) {
+	var req models.Message
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return nil, []fieldViolation{{Field: typeErr.Field, Message: fmt.Sprintf("must be a %s, not a %s", typeErr.Type, typeErr.Value)}}
+		}
+		return nil, []fieldViolation{{Field: "body", Message: "must be a JSON message object"}}
+	}
+	return &req, nil
+}
+
+// validateMessageRequest checks a new message's envelope and, when its type is known, its
+// payload, reporting every rejected field at once rather than the first
+func validateMessageRequest(req *models.Message, producer string) []fieldViolation {
+	var violations []fieldViolation
+	if !req.Stage.IsValid() {
+		violations = append(violations, fieldViolation{Field: "stage", Message: fmt.Sprintf("%q is not a valid stage (must be dev, stage, or prod)", req.Stage)})
+	}
+	if req.Priority != "" && !req.Priority.IsValid() {
+		violations = append(violations, fieldViolation{Field: "priority", Message: fmt.Sprintf("%q is not a valid priority (must be low, normal or high)", req.Priority)})
+	}
+
+	registry := models.DefaultMessageTypeRegistry()
+	spec, err := registry.Lookup(req.MessageType)
+	if err != nil {
+		return append(violations, fieldViolation{Field: "message_type", Message: err.Error()})
+	}
+	if !slices.Contains(spec.Producers, producer) {
+		return append(violations, fieldViolation{Field: "message_type", Message: fmt.Sprintf("%s messages cannot be created through the API", req.MessageType)})
+	}
+	if err := registry.ValidatePayload(req); err != nil {
+		violations = append(violations, violationFor(err, "payload"))
+	}
+	return violations
+}
+
+// listQueryViolations reports the query parameter a list endpoint rejected
+func listQueryViolations(err error) []fieldViolation {
+	var paramErr *pagination.ParamError
+	if errors.As(err, &paramErr) {
+		return []fieldViolation{{Field: paramErr.Param, Message: paramErr.Message}}
+	}
+	return []fieldViolation{{Field: "query", Message: err.Error()}}
+}
+
+// violationFor names the field a validation error is about, or field when the error does not say
+func violationFor(err error, field string) fieldViolation {
+	var fieldErr *models.FieldError
+	if errors.As(err, &fieldErr) {
+		return fieldViolation{Field: fieldErr.Field, Message: fieldErr.Message}
+	}
+	return fieldViolation{Field: field, Message: err.Error()}
+}
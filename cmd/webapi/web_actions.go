@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/bodystore"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/pagination"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// webActionBodyPreview is how much of each response body GET /api/web-actions returns; stored
+// bodies reach 50KB, so a full page of them would pass the Lambda response size limit
+const webActionBodyPreview = 1024
+
+// webActionResultListOptions is the list convention supported by GET /api/web-actions. Results
+// are read from the table's created date index, so they sort by created date only.
+var webActionResultListOptions = pagination.Options{
+	DefaultLimit: 100,
+	MaxLimit:     1000,
+	SortFields:   []string{"created_date"},
+	DefaultSort:  "-created_date",
+	FilterFields: []string{"stage", "message_id", "status", "action", "correlation_id"},
+}
+
+// webActionResultResponse is a web action result with a note of whether its body was cut short,
+// when stored or for the list preview
+type webActionResultResponse struct {
+	*models.WebActionResult
+	ResponseBodyTruncated bool `json:"response_body_truncated"`
+}
+
+// newWebActionResultResponse renders a result, cutting its body to limit bytes when limit is positive
+func newWebActionResultResponse(result *models.WebActionResult, limit int) webActionResultResponse {
+	item := *result
+	truncated := models.IsTruncatedResponseBody(item.ResponseBody)
+	if limit > 0 && len(item.ResponseBody) > limit {
+		body := item.ResponseBody[:limit]
+		// Don't split a multi-byte character
+		for !utf8.ValidString(body) {
+			body = body[:len(body)-1]
+		}
+		item.ResponseBody = body
+		truncated = true
+	}
+	return webActionResultResponse{WebActionResult: &item, ResponseBodyTruncated: truncated}
+}
+
+// SetWebActionResultRepository enables GET /api/web-actions, which reads the results the web
+// action Lambda records for each request it executes
+func (h *WebAPIHandler) SetWebActionResultRepository(repo repository.WebActionResultRepository) {
+	h.webActionResultRepo = repo
+}
+
+// canSeeMessage reports whether the user may read the message's results: members only see
+// results of the messages they created
+func (h *WebAPIHandler) canSeeMessage(ctx context.Context, user *models.User, messageID string) bool {
+	if user == nil || user.IsAdmin() {
+		return true
+	}
+	message, err := h.repository.GetMessage(ctx, messageID)
+	return err == nil && message.CreatedBy == user.ID
+}
+
+// handleListWebActionResults returns a page of web action results with their bodies cut to a
+// preview. filter[message_id] looks up one message's results through the table's message index,
+// and members' results are gathered from their messages; both are paged in memory. Other lists
+// are read a page at a time from the stage's created date index. The stage filter defaults to
+// the API's stage.
+func (h *WebAPIHandler) handleListWebActionResults(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.webActionResultRepo == nil {
+		return h.createErrorResponse(http.StatusNotFound, "web action results are not enabled"), nil
+	}
+	user, response, ok := h.authenticate(ctx, request)
+	if !ok {
+		return response, nil
+	}
+
+	opts := webActionResultListOptions
+	opts.DefaultFilters = map[string]string{"stage": h.config.Stage.String()}
+	query, err := pagination.Parse(legacyFilters(request.QueryStringParameters, "message_id"), opts)
+	if err != nil {
+		return h.createErrorResponse(http.StatusBadRequest, err.Error()), nil
+	}
+
+	var page pagination.Page[*models.WebActionResult]
+	messageID, byMessage := query.Filter("message_id")
+	if byMessage || (user != nil && !user.IsAdmin()) {
+		var results []*models.WebActionResult
+		if !byMessage {
+			results, err = h.listMemberWebActionResults(ctx, user)
+		} else if h.canSeeMessage(ctx, user, messageID) {
+			results, err = h.webActionResultRepo.ListResultsByMessageID(ctx, messageID)
+		}
+		page = pagination.Apply(results, query, func(r *models.WebActionResult, field string) string {
+			switch field {
+			case "created_date":
+				return pagination.Time(r.CreatedDate)
+			case "stage":
+				return string(r.Stage)
+			case "message_id":
+				return r.MessageID
+			case "status":
+				return string(r.Status)
+			case "action":
+				return string(r.Action)
+			case "correlation_id":
+				return r.CorrelationID
+			}
+			return ""
+		})
+	} else {
+		resultQuery := repository.ResultQuery{CorrelationID: query.Filters["correlation_id"]}
+		resultQuery.Stage = models.Stage(query.Filters["stage"])
+		resultQuery.Status = models.Status(query.Filters["status"])
+		resultQuery.Action = models.WebActionType(query.Filters["action"])
+		var results []*models.WebActionResult
+		var next map[string]string
+		results, next, err = h.webActionResultRepo.QueryResults(ctx, resultQuery, repository.IndexPage{
+			Limit:      query.Limit,
+			Descending: query.Descending(),
+			Cursor:     query.Cursor,
+		})
+		page = pagination.CursorPage(results, query, next)
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list web action results", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve web action results"), err
+	}
+
+	items := make([]webActionResultResponse, 0, len(page.Items))
+	for _, result := range page.Items {
+		items = append(items, newWebActionResultResponse(result, webActionBodyPreview))
+	}
+
+	body, err := json.Marshal(pagination.Page[webActionResultResponse]{Items: items, NextToken: page.NextToken}.Response("web_actions"))
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
+
+// listMemberWebActionResults gathers the results of the member's own web action messages
+func (h *WebAPIHandler) listMemberWebActionResults(ctx context.Context, user *models.User) ([]*models.WebActionResult, error) {
+	messages, err := h.repository.ListMessagesByCreator(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*models.WebActionResult
+	for _, message := range messages {
+		if message.MessageType != models.MessageTypeWebAction {
+			continue
+		}
+		messageResults, err := h.webActionResultRepo.ListResultsByMessageID(ctx, message.ID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, messageResults...)
+	}
+	return results, nil
+}
+
+// responseBodyLinker hands out links to the full response bodies offloaded to S3; satisfied by
+// *bodystore.Store
+type responseBodyLinker interface {
+	Link(ctx context.Context, key string) (string, error)
+}
+
+// SetResponseBodyStore enables GET /api/web-actions/{id}/body, which links to the full response
+// bodies the web action Lambda offloads to S3
+func (h *WebAPIHandler) SetResponseBodyStore(store responseBodyLinker) {
+	h.responseBodies = store
+}
+
+// getVisibleWebActionResult looks up the result the user may read, answering with the response to
+// send instead when there is none
+func (h *WebAPIHandler) getVisibleWebActionResult(ctx context.Context, request events.APIGatewayV2HTTPRequest, id string) (*models.WebActionResult, events.APIGatewayV2HTTPResponse, error) {
+	if h.webActionResultRepo == nil {
+		return nil, h.createErrorResponse(http.StatusNotFound, "web action results are not enabled"), nil
+	}
+	user, response, ok := h.authenticate(ctx, request)
+	if !ok {
+		return nil, response, nil
+	}
+	if id == "" || strings.Contains(id, "/") {
+		return nil, h.createErrorResponse(http.StatusNotFound, "endpoint not found"), nil
+	}
+
+	result, err := h.webActionResultRepo.GetResult(ctx, id)
+	if err != nil {
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			h.logger.ErrorContext(ctx, "failed to get web action result", slog.String("error", err.Error()))
+			return nil, h.createErrorResponse(http.StatusInternalServerError, "failed to retrieve web action result"), err
+		}
+		return nil, h.createErrorResponse(http.StatusNotFound, "web action result not found"), nil
+	}
+	// A member asking for someone else's result learns no more than for a missing one
+	if !h.canSeeMessage(ctx, user, result.MessageID) {
+		return nil, h.createErrorResponse(http.StatusNotFound, "web action result not found"), nil
+	}
+	return result, events.APIGatewayV2HTTPResponse{}, nil
+}
+
+// handleGetWebActionResult returns one web action result with its whole stored body
+func (h *WebAPIHandler) handleGetWebActionResult(ctx context.Context, request events.APIGatewayV2HTTPRequest, id string) (events.APIGatewayV2HTTPResponse, error) {
+	result, response, err := h.getVisibleWebActionResult(ctx, request, id)
+	if result == nil {
+		return response, err
+	}
+
+	body, err := json.Marshal(newWebActionResultResponse(result, 0))
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
+
+// handleGetWebActionBody returns a short-lived link to a result's full response body, when it was
+// too large for the results table and offloaded to S3
+func (h *WebAPIHandler) handleGetWebActionBody(ctx context.Context, request events.APIGatewayV2HTTPRequest, id string) (events.APIGatewayV2HTTPResponse, error) {
+	if h.responseBodies == nil {
+		return h.createErrorResponse(http.StatusNotFound, "response body storage is not enabled"), nil
+	}
+	result, response, err := h.getVisibleWebActionResult(ctx, request, id)
+	if result == nil {
+		return response, err
+	}
+	if result.ResponseBodyKey == "" {
+		return h.createErrorResponse(http.StatusNotFound, "web action result has no offloaded response body"), nil
+	}
+
+	expiresAt := time.Now().UTC().Add(bodystore.LinkTTL)
+	url, err := h.responseBodies.Link(ctx, result.ResponseBodyKey)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to link response body", slog.String("error", err.Error()))
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to link response body"), err
+	}
+
+	body, err := json.Marshal(webActionBodyResponse{URL: url, ExpiresAt: expiresAt})
+	if err != nil {
+		return h.createErrorResponse(http.StatusInternalServerError, "failed to marshal response"), err
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+	}, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/apigatewayv2"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/dynamodb"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/lambda"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// AgentArgs are the inputs to NewAgentComponent
+type AgentArgs struct {
+	Stage        string
+	Architecture string
+
+	MessagesTable  *dynamodb.Table
+	WebActions     *Channel
+	Notifications  *Channel
+	AgentResponses *Channel
+
+	HttpApi      *apigatewayv2.Api
+	McpServerUrl pulumi.StringInput
+
+	// SessionSigningKey signs the chat UI's session cookie; the chat UI is disabled without it
+	SessionSigningKey pulumi.StringInput
+
+	// ModelID is the Bedrock model or inference profile the agent calls; its policy allows only that
+	ModelID string
+	Scope   awsScope
+
+	// CORSOrigins and CORSCredentials are the agent's CORS_ALLOWED_ORIGINS and CORS_ALLOW_CREDENTIALS
+	CORSOrigins     string
+	CORSCredentials bool
+
+	TracingMode      string
+	Tuning           LambdaTuning
+	QueueTuning      QueueTuning // Of the agent responses queue's trigger
+	LogRetentionDays int
+	Tags             pulumi.StringMap
+}
+
+// AgentComponent is the interactive chat agent: its session table, Lambda and /agent API routes
+type AgentComponent struct {
+	pulumi.ResourceState
+
+	SessionTable *dynamodb.Table
+	Service      *LambdaServiceComponent
+	// StreamURL is the function URL that streams chat responses as server-sent events
+	StreamURL *lambda.FunctionUrl
+}
+
+// NewAgentComponent creates the agent. It consumes the agent-responses channel for tool results and
+// serves chat, the A2A agent card, the chat UI and a health check from the HTTP API. The same
+// routes are served from a function URL, which streams chat responses; API Gateway buffers them.
+func NewAgentComponent(ctx *pulumi.Context, name string, args *AgentArgs, opts ...pulumi.ResourceOption) (*AgentComponent, error) {
+	component := &AgentComponent{}
+	if err := ctx.RegisterComponentResource("rez-agent:index:Agent", name, component, opts...); err != nil {
+		return nil, err
+	}
+	stage := args.Stage
+
+	sessionTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-sessions-%s", stage), &dynamodb.TableArgs{
+		Name:        pulumi.String(fmt.Sprintf("rez-agent-sessions-%s", stage)),
+		BillingMode: pulumi.String("PAY_PER_REQUEST"),
+		HashKey:     pulumi.String("session_id"),
+		Attributes: dynamodb.TableAttributeArray{
+			&dynamodb.TableAttributeArgs{
+				Name: pulumi.String("session_id"),
+				Type: pulumi.String("S"),
+			},
+		},
+		Ttl: &dynamodb.TableTtlArgs{
+			AttributeName: pulumi.String("ttl"),
+			Enabled:       pulumi.Bool(true),
+		},
+		Tags: args.Tags,
+	}, childOf(component)...)
+	if err != nil {
+		return nil, err
+	}
+	component.SessionTable = sessionTable
+
+	policy := newIAMPolicy().
+		allow([]string{"dynamodb:GetItem", "dynamodb:PutItem", "dynamodb:UpdateItem", "dynamodb:Query"},
+			sessionTable.Arn, args.MessagesTable.Arn, tableIndexes(args.MessagesTable)).
+		// The chat UI deletes sessions
+		allow([]string{"dynamodb:DeleteItem"}, sessionTable.Arn).
+		allow([]string{"sns:Publish"}, args.WebActions.Topic.Arn, args.Notifications.Topic.Arn, args.AgentResponses.Topic.Arn).
+		allow(sqsConsumerActions, args.AgentResponses.Queue.Arn).
+		allow([]string{"bedrock:InvokeModel", "bedrock:InvokeModelWithResponseStream"},
+			args.Scope.bedrockModelArns(args.Scope.region, args.ModelID)...).
+		// GET /agent/health describes the tables
+		allow([]string{"dynamodb:DescribeTable"}, sessionTable.Arn, args.MessagesTable.Arn)
+
+	service, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-agent-service-%s", stage), &LambdaServiceArgs{
+		Stage:        stage,
+		Name:         "agent",
+		Code:         pulumi.NewFileArchive("../build/agent.zip"),
+		Architecture: args.Architecture,
+		Policy:       policy,
+		Environment: pulumi.StringMap{
+			"DYNAMODB_TABLE_NAME":       args.MessagesTable.Name,
+			"AGENT_SESSION_TABLE_NAME":  sessionTable.Name,
+			"WEB_ACTIONS_TOPIC_ARN":     args.WebActions.Topic.Arn,
+			"NOTIFICATIONS_TOPIC_ARN":   args.Notifications.Topic.Arn,
+			"AGENT_RESPONSE_TOPIC_ARN":  args.AgentResponses.Topic.Arn,
+			"AGENT_RESPONSE_QUEUE_URL":  args.AgentResponses.Queue.Url,
+			"STAGE":                     pulumi.String(stage),
+			"MCP_SERVER_URL":            args.McpServerUrl,
+			"BEDROCK_MODEL_ID":          pulumi.String(args.ModelID),
+			"CORS_ALLOWED_ORIGINS":      pulumi.String(args.CORSOrigins),
+			"CORS_ALLOW_CREDENTIALS":    pulumi.String(strconv.FormatBool(args.CORSCredentials)),
+			"AGENT_SESSION_SIGNING_KEY": args.SessionSigningKey,
+		},
+		MemorySize:       256,
+		Timeout:          300,
+		TracingMode:      args.TracingMode,
+		Tuning:           args.Tuning,
+		LogRetentionDays: args.LogRetentionDays,
+		Trigger: &SQSTriggerArgs{
+			Queue:                   args.AgentResponses.Queue,
+			BatchSize:               10,
+			ReportBatchItemFailures: true,
+			DependsOn:               []pulumi.Resource{args.AgentResponses.QueuePolicy},
+			Tuning:                  args.QueueTuning,
+		},
+		Tags: args.Tags,
+	}, pulumi.Parent(component))
+	if err != nil {
+		return nil, err
+	}
+	component.Service = service
+
+	apiInvokeArn := args.HttpApi.ExecutionArn.ApplyT(func(arn string) string {
+		return fmt.Sprintf("%s/*/*", arn)
+	}).(pulumi.StringOutput)
+	if err := service.AllowInvoke(ctx, "apigw-permission", "apigateway.amazonaws.com", apiInvokeArn, childOf(component)...); err != nil {
+		return nil, err
+	}
+
+	integration, err := apigatewayv2.NewIntegration(ctx, fmt.Sprintf("rez-agent-agent-api-integration-%s", stage), &apigatewayv2.IntegrationArgs{
+		ApiId:                args.HttpApi.ID(),
+		IntegrationType:      pulumi.String("AWS_PROXY"),
+		IntegrationUri:       service.InvokeArn,
+		IntegrationMethod:    pulumi.String("POST"),
+		PayloadFormatVersion: pulumi.String("2.0"),
+	}, childOf(component)...)
+	if err != nil {
+		return nil, err
+	}
+	target := integration.ID().ApplyT(func(id string) string {
+		return fmt.Sprintf("integrations/%s", id)
+	}).(pulumi.StringOutput)
+
+	// Chat, A2A task cancellation, the agent card for A2A discovery (also at its well-known path)
+	// and the chat UI, which deletes sessions with a form post
+	routes := []struct{ resource, key string }{
+		{"api-route", "POST /agent"},
+		{"cancel-route", "POST /agent/tasks/{id}/cancel"},
+		{"card-route", "GET /agent/card"},
+		{"wellknown-route", "GET /agent/.well-known/agent-card"},
+		{"ui-route", "GET /agent/ui"},
+		{"ui-delete-route", "POST /agent/ui/delete"},
+		{"health-route", "GET /agent/health"},
+	}
+	for _, route := range routes {
+		_, err = apigatewayv2.NewRoute(ctx, fmt.Sprintf("rez-agent-agent-%s-%s", route.resource, stage), &apigatewayv2.RouteArgs{
+			ApiId:    args.HttpApi.ID(),
+			RouteKey: pulumi.String(route.key),
+			Target:   target,
+		}, childOf(component)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The handler answers CORS itself, as it does through the HTTP API
+	streamURL, err := service.StreamingURL(ctx, childOf(component)...)
+	if err != nil {
+		return nil, err
+	}
+	component.StreamURL = streamURL
+
+	if err := ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"sessionTableName": sessionTable.Name,
+		"functionArn":      service.Function.Arn,
+		"streamUrl":        streamURL.FunctionUrl,
+	}); err != nil {
+		return nil, err
+	}
+	return component, nil
+}
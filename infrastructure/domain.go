@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/acm"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/apigatewayv2"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/route53"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// customDomain is the API's custom domain and the Route53 zone that serves it
+type customDomain struct {
+	name   string
+	domain *apigatewayv2.DomainName
+}
+
+// newCustomDomain issues a DNS-validated ACM certificate for domainName, creates the API Gateway
+// domain with TLS 1.2, and points an alias record at it. The hosted zone defaults to the parent of
+// domainName (api.example.com is served from example.com).
+func newCustomDomain(ctx *pulumi.Context, stage, domainName, hostedZoneName string, tags pulumi.StringMap) (*customDomain, error) {
+	if hostedZoneName == "" {
+		_, parent, ok := strings.Cut(domainName, ".")
+		if !ok || !strings.Contains(parent, ".") {
+			return nil, fmt.Errorf("cannot derive a hosted zone from domainName %q; set hostedZoneName", domainName)
+		}
+		hostedZoneName = parent
+	}
+
+	zone, err := route53.LookupZone(ctx, &route53.LookupZoneArgs{
+		Name:        pulumi.StringRef(hostedZoneName),
+		PrivateZone: pulumi.BoolRef(false),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find hosted zone %s: %w", hostedZoneName, err)
+	}
+
+	cert, err := acm.NewCertificate(ctx, fmt.Sprintf("rez-agent-api-cert-%s", stage), &acm.CertificateArgs{
+		DomainName:       pulumi.String(domainName),
+		ValidationMethod: pulumi.String("DNS"),
+		Tags:             tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	validation := cert.DomainValidationOptions.Index(pulumi.Int(0))
+	validationRecord, err := route53.NewRecord(ctx, fmt.Sprintf("rez-agent-api-cert-validation-%s", stage), &route53.RecordArgs{
+		ZoneId:         pulumi.String(zone.ZoneId),
+		Name:           validation.ResourceRecordName().Elem(),
+		Type:           validation.ResourceRecordType().Elem(),
+		Records:        pulumi.StringArray{validation.ResourceRecordValue().Elem()},
+		Ttl:            pulumi.Int(300),
+		AllowOverwrite: pulumi.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Waits until ACM has issued the certificate, so the domain is never created with a pending one
+	validated, err := acm.NewCertificateValidation(ctx, fmt.Sprintf("rez-agent-api-cert-validated-%s", stage), &acm.CertificateValidationArgs{
+		CertificateArn:        cert.Arn,
+		ValidationRecordFqdns: pulumi.StringArray{validationRecord.Fqdn},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	domain, err := apigatewayv2.NewDomainName(ctx, fmt.Sprintf("rez-agent-api-domain-%s", stage), &apigatewayv2.DomainNameArgs{
+		DomainName: pulumi.String(domainName),
+		DomainNameConfiguration: &apigatewayv2.DomainNameDomainNameConfigurationArgs{
+			CertificateArn: validated.CertificateArn,
+			EndpointType:   pulumi.String("REGIONAL"),
+			SecurityPolicy: pulumi.String("TLS_1_2"),
+		},
+		Tags: tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = route53.NewRecord(ctx, fmt.Sprintf("rez-agent-api-alias-%s", stage), &route53.RecordArgs{
+		ZoneId: pulumi.String(zone.ZoneId),
+		Name:   pulumi.String(domainName),
+		Type:   pulumi.String("A"),
+		Aliases: route53.RecordAliasArray{
+			&route53.RecordAliasArgs{
+				Name:                 domain.DomainNameConfiguration.TargetDomainName().Elem(),
+				ZoneId:               domain.DomainNameConfiguration.HostedZoneId().Elem(),
+				EvaluateTargetHealth: pulumi.Bool(false),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &customDomain{name: domainName, domain: domain}, nil
+}
+
+// URL is the HTTPS base URL of the custom domain
+func (d *customDomain) URL() string {
+	return fmt.Sprintf("https://%s", d.name)
+}
+
+// mapStage serves the API stage at the root of the domain. /api, /mcp and /agent all go through this
+// one mapping: the HTTP API's routes already carry those prefixes, and a base-path mapping per prefix
+// would strip it before the route is matched.
+func (d *customDomain) mapStage(ctx *pulumi.Context, stage string, api *apigatewayv2.Api, apiStage *apigatewayv2.Stage) error {
+	_, err := apigatewayv2.NewApiMapping(ctx, fmt.Sprintf("rez-agent-api-mapping-%s", stage), &apigatewayv2.ApiMappingArgs{
+		ApiId:      api.ID(),
+		DomainName: d.domain.ID(),
+		Stage:      apiStage.Name,
+	})
+	return err
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/dynamodb"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/s3"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// iamStatement is one Allow statement. Resources may be outputs that are only known at deploy time.
+type iamStatement struct {
+	actions   []string
+	resources []pulumi.StringInput
+}
+
+// iamPolicy builds an inline policy document from the ARNs of the resources it grants access to,
+// so a statement never needs a wildcard to name something this program created
+type iamPolicy struct {
+	statements []iamStatement
+}
+
+func newIAMPolicy() *iamPolicy {
+	return &iamPolicy{}
+}
+
+// allow grants actions on resources
+func (p *iamPolicy) allow(actions []string, resources ...pulumi.StringInput) *iamPolicy {
+	p.statements = append(p.statements, iamStatement{actions: actions, resources: resources})
+	return p
+}
+
+// with returns a copy of p with extra statements, leaving p unchanged
+func (p *iamPolicy) with(statements ...iamStatement) *iamPolicy {
+	return &iamPolicy{statements: append(append([]iamStatement{}, p.statements...), statements...)}
+}
+
+// document renders the policy once every resource ARN has resolved
+func (p *iamPolicy) document() pulumi.StringOutput {
+	var resources []interface{}
+	for _, statement := range p.statements {
+		for _, resource := range statement.resources {
+			resources = append(resources, resource)
+		}
+	}
+	statements := p.statements
+
+	return pulumi.All(resources...).ApplyT(func(args []interface{}) (string, error) {
+		type statementJSON struct {
+			Effect   string   `json:"Effect"`
+			Action   []string `json:"Action"`
+			Resource []string `json:"Resource"`
+		}
+		document := struct {
+			Version   string          `json:"Version"`
+			Statement []statementJSON `json:"Statement"`
+		}{Version: "2012-10-17"}
+
+		next := 0
+		for _, statement := range statements {
+			arns := make([]string, 0, len(statement.resources))
+			for range statement.resources {
+				arns = append(arns, args[next].(string))
+				next++
+			}
+			document.Statement = append(document.Statement, statementJSON{
+				Effect:   "Allow",
+				Action:   statement.actions,
+				Resource: arns,
+			})
+		}
+
+		body, err := json.Marshal(document)
+		return string(body), err
+	}).(pulumi.StringOutput)
+}
+
+// xrayStatement lets a traced function send segments. X-Ray has no resource-level permissions,
+// so it keeps Resource "*".
+var xrayStatement = iamStatement{
+	actions:   []string{"xray:PutTraceSegments", "xray:PutTelemetryRecords"},
+	resources: []pulumi.StringInput{pulumi.String("*")},
+}
+
+// listSchedulesStatement lets the schedule reconciler list EventBridge schedules. ListSchedules
+// has no resource-level permissions either, so it also keeps Resource "*".
+var listSchedulesStatement = iamStatement{
+	actions:   []string{"scheduler:ListSchedules"},
+	resources: []pulumi.StringInput{pulumi.String("*")},
+}
+
+// awsScope is the account and region the stack deploys into, used to build ARNs for resources
+// this program does not create (secrets, parameters, schedules, Bedrock models)
+type awsScope struct {
+	region    string
+	accountID string
+}
+
+func lookupAWSScope(ctx *pulumi.Context) (awsScope, error) {
+	region, err := aws.GetRegion(ctx, nil)
+	if err != nil {
+		return awsScope{}, fmt.Errorf("failed to look up region: %w", err)
+	}
+	identity, err := aws.GetCallerIdentity(ctx, nil)
+	if err != nil {
+		return awsScope{}, fmt.Errorf("failed to look up account: %w", err)
+	}
+	return awsScope{region: region.Name, accountID: identity.AccountId}, nil
+}
+
+// arn is arn:aws:<service>:<region>:<account>:<resource>
+func (s awsScope) arn(service, resource string) pulumi.String {
+	return pulumi.String(fmt.Sprintf("arn:aws:%s:%s:%s:%s", service, s.region, s.accountID, resource))
+}
+
+// inferenceProfilePrefixes mark a Bedrock model ID as a cross-region inference profile
+var inferenceProfilePrefixes = []string{"us.", "eu.", "apac.", "global."}
+
+// bedrockModelArns are the resources InvokeModel needs for modelID in region. A cross-region
+// inference profile routes to the foundation model in any region of its geography, so the model
+// itself is granted in every region.
+func (s awsScope) bedrockModelArns(region, modelID string) []pulumi.StringInput {
+	for _, prefix := range inferenceProfilePrefixes {
+		if strings.HasPrefix(modelID, prefix) {
+			return []pulumi.StringInput{
+				pulumi.String(fmt.Sprintf("arn:aws:bedrock:%s:%s:inference-profile/%s", region, s.accountID, modelID)),
+				pulumi.String(fmt.Sprintf("arn:aws:bedrock:*::foundation-model/%s", strings.TrimPrefix(modelID, prefix))),
+			}
+		}
+	}
+	return []pulumi.StringInput{pulumi.String(fmt.Sprintf("arn:aws:bedrock:%s::foundation-model/%s", region, modelID))}
+}
+
+// bedrockGuardrailArn accepts either form of the bedrockGuardrailArn config: a full ARN or a bare ID
+func (s awsScope) bedrockGuardrailArn(guardrail string) pulumi.String {
+	if strings.HasPrefix(guardrail, "arn:") {
+		return pulumi.String(guardrail)
+	}
+	return s.arn("bedrock", "guardrail/"+guardrail)
+}
+
+// sqsConsumerActions are what an SQS event source mapping needs from its queue
+var sqsConsumerActions = []string{"sqs:ReceiveMessage", "sqs:DeleteMessage", "sqs:GetQueueAttributes"}
+
+// tableIndexes is the ARN pattern covering every secondary index of table
+func tableIndexes(table *dynamodb.Table) pulumi.StringOutput {
+	return pulumi.Sprintf("%s/index/*", table.Arn)
+}
+
+// bucketObjects is the ARN pattern covering the objects of bucket under prefix
+func bucketObjects(bucket *s3.Bucket, prefix string) pulumi.StringOutput {
+	return pulumi.Sprintf("%s/%s*", bucket.Arn, prefix)
+}
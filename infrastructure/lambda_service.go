@@ -0,0 +1,414 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudwatch"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/lambda"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/sqs"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const lambdaAssumeRolePolicy = `{
+	"Version": "2012-10-17",
+	"Statement": [{
+		"Effect": "Allow",
+		"Principal": {"Service": "lambda.amazonaws.com"},
+		"Action": "sts:AssumeRole"
+	}]
+}`
+
+// warmerSchedule is how often a warmed function is pinged; Lambda keeps idle instances for a few minutes
+const warmerSchedule = "rate(5 minutes)"
+
+// LambdaTuning is a function's entry in the lambdaOverrides stack config, e.g.
+//
+//	rez-agent:lambdaOverrides:
+//	  agent: {memorySize: 2048, provisionedConcurrency: 1}
+//	  mcp: {warm: true}
+type LambdaTuning struct {
+	MemorySize int `json:"memorySize"`
+	Timeout    int `json:"timeout"`
+	// ProvisionedConcurrency keeps this many instances initialized behind a "live" alias
+	ProvisionedConcurrency int `json:"provisionedConcurrency"`
+	// Warm pings the function on a schedule, a cheaper alternative to provisioned concurrency
+	Warm bool `json:"warm"`
+}
+
+// QueueTuning is a queue's entry in the queueOverrides stack config, tuning the event source
+// mapping of the function that consumes it and the queue's backlog alarm, e.g.
+//
+//	rez-agent:queueOverrides:
+//	  web-actions: {maximumConcurrency: 5, ageAlarmSeconds: 300}
+//	  notifications: {batchSize: 50, maximumBatchingWindowSeconds: 5}
+type QueueTuning struct {
+	// BatchSize is the most records per invocation: 1 to 10, or up to 10000 with a batching window
+	BatchSize int `json:"batchSize"`
+	// MaximumBatchingWindowSeconds waits up to this long (at most 300) for a batch to fill
+	MaximumBatchingWindowSeconds int `json:"maximumBatchingWindowSeconds"`
+	// MaximumConcurrency caps the invocations the queue drives at once (2 to 1000); unset, Lambda
+	// scales up to the account's concurrency
+	MaximumConcurrency int `json:"maximumConcurrency"`
+	// AgeAlarmSeconds is how old the oldest message may get before the backlog alarm fires
+	AgeAlarmSeconds int `json:"ageAlarmSeconds"`
+}
+
+// validate checks the tuning against the limits of SQS event source mappings
+func (t QueueTuning) validate() error {
+	switch {
+	case t.BatchSize < 0 || t.BatchSize > 10000:
+		return fmt.Errorf("batchSize must be between 1 and 10000, got %d", t.BatchSize)
+	case t.BatchSize > 10 && t.MaximumBatchingWindowSeconds == 0:
+		return fmt.Errorf("batchSize over 10 needs maximumBatchingWindowSeconds")
+	case t.MaximumBatchingWindowSeconds < 0 || t.MaximumBatchingWindowSeconds > 300:
+		return fmt.Errorf("maximumBatchingWindowSeconds must be between 0 and 300, got %d", t.MaximumBatchingWindowSeconds)
+	case t.MaximumConcurrency != 0 && (t.MaximumConcurrency < 2 || t.MaximumConcurrency > 1000):
+		return fmt.Errorf("maximumConcurrency must be between 2 and 1000, got %d", t.MaximumConcurrency)
+	case t.AgeAlarmSeconds < 0:
+		return fmt.Errorf("ageAlarmSeconds must be positive, got %d", t.AgeAlarmSeconds)
+	}
+	return nil
+}
+
+// SQSTriggerArgs connects a queue to the function
+type SQSTriggerArgs struct {
+	Queue     *sqs.Queue
+	BatchSize int
+	// ReportBatchItemFailures redelivers only the records the handler lists as failed
+	ReportBatchItemFailures bool
+	// DependsOn is usually the queue's policy, so the mapping is not created before SNS can deliver
+	DependsOn []pulumi.Resource
+	// Tuning overrides BatchSize and sets the batching window and maximum concurrency (optional)
+	Tuning QueueTuning
+}
+
+// StreamTriggerArgs connects a DynamoDB table's stream to the function
+type StreamTriggerArgs struct {
+	StreamArn pulumi.StringInput
+	BatchSize int
+	// Filter is an event filter pattern; records it does not match never invoke the function
+	Filter string
+	// MaximumRetryAttempts bounds the retries of a failing record, which hold up its shard meanwhile
+	MaximumRetryAttempts int
+}
+
+// LambdaServiceArgs are the inputs to NewLambdaServiceComponent
+type LambdaServiceArgs struct {
+	Stage string
+	// Name is the service's short name: the function is rez-agent-<Name>-<stage>
+	Name string
+
+	// Runtime and Handler default to the Go custom runtime (provided.al2023, bootstrap)
+	Runtime string
+	Handler string
+	// Architecture is arm64 or x86_64; empty leaves Lambda's default, x86_64
+	Architecture string
+	// Code is a local archive; for packages too large to upload directly set S3Bucket and S3Key instead
+	Code           pulumi.Archive
+	S3Bucket       pulumi.StringInput
+	S3Key          pulumi.StringInput
+	SourceCodeHash pulumi.StringInput
+
+	// Policy is attached inline to the function's role. The component adds write access to the
+	// function's own log group and, when tracing is configured, X-Ray.
+	Policy *iamPolicy
+
+	Environment pulumi.StringMap
+	MemorySize  int
+	Timeout     int
+	// TracingMode is Active or PassThrough; empty leaves X-Ray tracing unconfigured
+	TracingMode string
+
+	// Tuning overrides MemorySize and Timeout and turns on provisioned concurrency or warming
+	Tuning LambdaTuning
+
+	LogRetentionDays int
+	Trigger          *SQSTriggerArgs
+	// DependsOn lists extra resources the function must wait for, such as its S3 package
+	DependsOn []pulumi.Resource
+	Tags      pulumi.StringMap
+}
+
+// LambdaServiceComponent is one Lambda with its role, log group and optional SQS trigger
+type LambdaServiceComponent struct {
+	pulumi.ResourceState
+
+	Role     *iam.Role
+	LogGroup *cloudwatch.LogGroup
+	Function *lambda.Function
+	// InvokeArn is what integrations and triggers call: the live alias with provisioned
+	// concurrency, otherwise the function itself
+	InvokeArn pulumi.StringOutput
+
+	alias *lambda.Alias
+	name  string
+	stage string
+}
+
+// NewLambdaServiceComponent creates the Lambda described by args. Child resources keep the names
+// the hand-written program used: rez-agent-<name>-role, -policy, -logs and -sqs-trigger.
+func NewLambdaServiceComponent(ctx *pulumi.Context, name string, args *LambdaServiceArgs, opts ...pulumi.ResourceOption) (*LambdaServiceComponent, error) {
+	component := &LambdaServiceComponent{name: args.Name, stage: args.Stage}
+	if err := ctx.RegisterComponentResource("rez-agent:index:LambdaService", name, component, opts...); err != nil {
+		return nil, err
+	}
+
+	resourceName := func(suffix string) string {
+		if suffix == "" {
+			return fmt.Sprintf("rez-agent-%s-%s", args.Name, args.Stage)
+		}
+		return fmt.Sprintf("rez-agent-%s-%s-%s", args.Name, suffix, args.Stage)
+	}
+
+	role, err := iam.NewRole(ctx, resourceName("role"), &iam.RoleArgs{
+		Name:             pulumi.String(resourceName("role")),
+		AssumeRolePolicy: pulumi.String(lambdaAssumeRolePolicy),
+		Tags:             args.Tags,
+	}, childOf(component)...)
+	if err != nil {
+		return nil, err
+	}
+	component.Role = role
+
+	logGroup, err := cloudwatch.NewLogGroup(ctx, resourceName("logs"), &cloudwatch.LogGroupArgs{
+		Name:            pulumi.String(fmt.Sprintf("/aws/lambda/%s", resourceName(""))),
+		RetentionInDays: pulumi.Int(args.LogRetentionDays),
+		Tags:            args.Tags,
+	}, childOf(component)...)
+	if err != nil {
+		return nil, err
+	}
+	component.LogGroup = logGroup
+
+	// The log group already exists, so the function only needs to write streams into it
+	policy := args.Policy.with(iamStatement{
+		actions:   []string{"logs:CreateLogStream", "logs:PutLogEvents"},
+		resources: []pulumi.StringInput{pulumi.Sprintf("%s:*", logGroup.Arn)},
+	})
+	if args.TracingMode != "" {
+		policy = policy.with(xrayStatement)
+	}
+	_, err = iam.NewRolePolicy(ctx, resourceName("policy"), &iam.RolePolicyArgs{
+		Role:   role.Name,
+		Policy: policy.document(),
+	}, childOf(component)...)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime, handler := args.Runtime, args.Handler
+	if runtime == "" {
+		runtime = "provided.al2023"
+	}
+	if handler == "" {
+		handler = "bootstrap"
+	}
+
+	memorySize, timeout := args.MemorySize, args.Timeout
+	if args.Tuning.MemorySize > 0 {
+		memorySize = args.Tuning.MemorySize
+	}
+	if args.Tuning.Timeout > 0 {
+		timeout = args.Tuning.Timeout
+	}
+	provisioned := args.Tuning.ProvisionedConcurrency > 0
+
+	functionArgs := &lambda.FunctionArgs{
+		Name:           pulumi.String(resourceName("")),
+		Runtime:        pulumi.String(runtime),
+		Role:           role.Arn,
+		Handler:        pulumi.String(handler),
+		Code:           args.Code,
+		S3Bucket:       args.S3Bucket,
+		S3Key:          args.S3Key,
+		SourceCodeHash: args.SourceCodeHash,
+		Environment: &lambda.FunctionEnvironmentArgs{
+			Variables: args.Environment,
+		},
+		MemorySize: pulumi.Int(memorySize),
+		Timeout:    pulumi.Int(timeout),
+		// Provisioned concurrency needs a published version for the alias to point at
+		Publish: pulumi.Bool(provisioned),
+		Tags:    args.Tags,
+	}
+	if args.Architecture != "" {
+		functionArgs.Architectures = pulumi.StringArray{pulumi.String(args.Architecture)}
+	}
+	if args.TracingMode != "" {
+		functionArgs.TracingConfig = &lambda.FunctionTracingConfigArgs{
+			Mode: pulumi.String(args.TracingMode),
+		}
+	}
+
+	function, err := lambda.NewFunction(ctx, resourceName(""), functionArgs,
+		childOf(component, pulumi.DependsOn(append([]pulumi.Resource{logGroup}, args.DependsOn...)))...)
+	if err != nil {
+		return nil, err
+	}
+	component.Function = function
+	component.InvokeArn = function.Arn
+
+	if provisioned {
+		alias, err := lambda.NewAlias(ctx, resourceName("live"), &lambda.AliasArgs{
+			Name:            pulumi.String("live"),
+			FunctionName:    function.Name,
+			FunctionVersion: function.Version,
+		}, pulumi.Parent(component))
+		if err != nil {
+			return nil, err
+		}
+		component.alias = alias
+		component.InvokeArn = alias.Arn
+
+		_, err = lambda.NewProvisionedConcurrencyConfig(ctx, resourceName("provisioned-concurrency"), &lambda.ProvisionedConcurrencyConfigArgs{
+			FunctionName:                    function.Name,
+			Qualifier:                       alias.Name,
+			ProvisionedConcurrentExecutions: pulumi.Int(args.Tuning.ProvisionedConcurrency),
+		}, pulumi.Parent(component))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if args.Tuning.Warm {
+		if err := component.warm(ctx, args.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if trigger := args.Trigger; trigger != nil {
+		batchSize := trigger.BatchSize
+		if trigger.Tuning.BatchSize > 0 {
+			batchSize = trigger.Tuning.BatchSize
+		}
+		mappingArgs := &lambda.EventSourceMappingArgs{
+			EventSourceArn: trigger.Queue.Arn,
+			FunctionName:   component.InvokeArn,
+			BatchSize:      pulumi.Int(batchSize),
+			Enabled:        pulumi.Bool(true),
+		}
+		if trigger.ReportBatchItemFailures {
+			mappingArgs.FunctionResponseTypes = pulumi.StringArray{pulumi.String("ReportBatchItemFailures")}
+		}
+		if window := trigger.Tuning.MaximumBatchingWindowSeconds; window > 0 {
+			mappingArgs.MaximumBatchingWindowInSeconds = pulumi.Int(window)
+		}
+		if concurrency := trigger.Tuning.MaximumConcurrency; concurrency > 0 {
+			mappingArgs.ScalingConfig = &lambda.EventSourceMappingScalingConfigArgs{
+				MaximumConcurrency: pulumi.Int(concurrency),
+			}
+		}
+		_, err = lambda.NewEventSourceMapping(ctx, resourceName("sqs-trigger"), mappingArgs,
+			childOf(component, pulumi.DependsOn(trigger.DependsOn))...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"functionArn":  function.Arn,
+		"invokeArn":    component.InvokeArn,
+		"functionName": function.Name,
+		"roleArn":      role.Arn,
+	}); err != nil {
+		return nil, err
+	}
+	return component, nil
+}
+
+// AllowInvoke grants principal permission to invoke InvokeArn from sourceArn. The permission is
+// named rez-agent-<name>-<suffix>-<stage> and stays at the top level, where it was first declared.
+func (s *LambdaServiceComponent) AllowInvoke(ctx *pulumi.Context, suffix, principal string, sourceArn pulumi.StringInput, opts ...pulumi.ResourceOption) error {
+	permissionArgs := &lambda.PermissionArgs{
+		Action:    pulumi.String("lambda:InvokeFunction"),
+		Function:  s.Function.Name,
+		Principal: pulumi.String(principal),
+		SourceArn: sourceArn,
+	}
+	if s.alias != nil {
+		permissionArgs.Qualifier = s.alias.Name
+	}
+	_, err := lambda.NewPermission(ctx, fmt.Sprintf("rez-agent-%s-%s-%s", s.name, suffix, s.stage), permissionArgs, opts...)
+	return err
+}
+
+// StreamingURL gives the function a public function URL whose responses Lambda streams as the
+// handler writes them (see localrun.StreamHandler). Callers authenticate and CORS is answered in
+// the handler, as they are through the HTTP API, so the URL has no CORS configuration of its own.
+func (s *LambdaServiceComponent) StreamingURL(ctx *pulumi.Context, opts ...pulumi.ResourceOption) (*lambda.FunctionUrl, error) {
+	urlArgs := &lambda.FunctionUrlArgs{
+		FunctionName:      s.Function.Name,
+		AuthorizationType: pulumi.String("NONE"),
+		InvokeMode:        pulumi.String("RESPONSE_STREAM"),
+	}
+	permissionArgs := &lambda.PermissionArgs{
+		Action:              pulumi.String("lambda:InvokeFunctionUrl"),
+		Function:            s.Function.Name,
+		Principal:           pulumi.String("*"),
+		FunctionUrlAuthType: pulumi.String("NONE"),
+	}
+	if s.alias != nil {
+		urlArgs.Qualifier = s.alias.Name
+		permissionArgs.Qualifier = s.alias.Name
+	}
+
+	url, err := lambda.NewFunctionUrl(ctx, fmt.Sprintf("rez-agent-%s-url-%s", s.name, s.stage), urlArgs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := lambda.NewPermission(ctx, fmt.Sprintf("rez-agent-%s-url-permission-%s", s.name, s.stage), permissionArgs, opts...); err != nil {
+		return nil, err
+	}
+	return url, nil
+}
+
+// StreamTrigger invokes the function with the new records of a table's stream, starting from the
+// latest. The handler reports the first record it failed, and Lambda retries from there.
+func (s *LambdaServiceComponent) StreamTrigger(ctx *pulumi.Context, args *StreamTriggerArgs) error {
+	mappingArgs := &lambda.EventSourceMappingArgs{
+		EventSourceArn:             args.StreamArn,
+		FunctionName:               s.InvokeArn,
+		StartingPosition:           pulumi.String("LATEST"),
+		BatchSize:                  pulumi.Int(args.BatchSize),
+		MaximumRetryAttempts:       pulumi.Int(args.MaximumRetryAttempts),
+		BisectBatchOnFunctionError: pulumi.Bool(true),
+		FunctionResponseTypes:      pulumi.StringArray{pulumi.String("ReportBatchItemFailures")},
+		Enabled:                    pulumi.Bool(true),
+	}
+	if args.Filter != "" {
+		mappingArgs.FilterCriteria = &lambda.EventSourceMappingFilterCriteriaArgs{
+			Filters: lambda.EventSourceMappingFilterCriteriaFilterArray{
+				&lambda.EventSourceMappingFilterCriteriaFilterArgs{Pattern: pulumi.String(args.Filter)},
+			},
+		}
+	}
+	_, err := lambda.NewEventSourceMapping(ctx, fmt.Sprintf("rez-agent-%s-stream-trigger-%s", s.name, s.stage), mappingArgs, pulumi.Parent(s))
+	return err
+}
+
+// warm pings the function with the warmer payload every few minutes. The handlers (see
+// localrun.WarmerPayload) answer the ping without doing any work.
+func (s *LambdaServiceComponent) warm(ctx *pulumi.Context, tags pulumi.StringMap) error {
+	ruleName := fmt.Sprintf("rez-agent-%s-warmer-%s", s.name, s.stage)
+	rule, err := cloudwatch.NewEventRule(ctx, ruleName, &cloudwatch.EventRuleArgs{
+		Name:               pulumi.String(ruleName),
+		Description:        pulumi.String(fmt.Sprintf("Keeps rez-agent-%s-%s warm", s.name, s.stage)),
+		ScheduleExpression: pulumi.String(warmerSchedule),
+		Tags:               tags,
+	}, pulumi.Parent(s))
+	if err != nil {
+		return err
+	}
+
+	_, err = cloudwatch.NewEventTarget(ctx, ruleName, &cloudwatch.EventTargetArgs{
+		Rule:  rule.Name,
+		Arn:   s.InvokeArn,
+		Input: pulumi.String(`{"warmer":true}`),
+	}, pulumi.Parent(s))
+	if err != nil {
+		return err
+	}
+
+	return s.AllowInvoke(ctx, "warmer-permission", "events.amazonaws.com", rule.Arn, pulumi.Parent(s))
+}
@@ -1,24 +1,34 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/apigatewayv2"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudwatch"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/dynamodb"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
-	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/lambda"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/s3"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/scheduler"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/secretsmanager"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/sesv2"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/sns"
-	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/sqs"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ssm"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 )
 
+// lambdaNames are the functions the lambdaOverrides config may tune
+var lambdaNames = []string{"scheduler", "schedulecreation", "processor", "webapi", "webaction", "mcp", "agent", "triage", "alarms", "rotation", "reservationsync", "digest", "canary", "outbox", "sweeper", "reconciler"}
+
+// queueNames are the work queues the queueOverrides config may tune, named like their alarms
+var queueNames = []string{"web-actions", "notifications", "agent-responses", "schedule-creation", "digest"}
+
 func main() {
 	pulumi.Run(func(ctx *pulumi.Context) (err error) {
 		// Add panic recovery with detailed logging
@@ -54,6 +64,53 @@ func main() {
 
 		enableXRay := cfg.GetBool("enableXRay")
 		log.Printf("X-Ray tracing enabled: %v", enableXRay)
+		tracingMode := map[bool]string{true: "Active", false: "PassThrough"}[enableXRay]
+
+		// CPU architecture of the Go Lambdas; must match the LAMBDA_ARCH the packages were built with
+		lambdaArchitecture := cfg.Get("lambdaArchitecture")
+		if lambdaArchitecture == "" {
+			lambdaArchitecture = "arm64" // Graviton: cheaper per GB-second than x86_64
+		}
+		if lambdaArchitecture != "arm64" && lambdaArchitecture != "x86_64" {
+			return fmt.Errorf("config 'lambdaArchitecture' must be arm64 or x86_64, got %q", lambdaArchitecture)
+		}
+
+		// Extra consumers of the messaging topics, each taking the messages its filter policy
+		// selects (list, optional); see SubscriptionArgs
+		var topicSubscriptions []SubscriptionArgs
+		if err := cfg.GetObject("topicSubscriptions", &topicSubscriptions); err != nil {
+			return fmt.Errorf("invalid config 'topicSubscriptions': %w", err)
+		}
+
+		// Per-function memory/timeout overrides, provisioned concurrency and warming, keyed by
+		// function name (object, optional); see LambdaTuning
+		var lambdaOverrides map[string]LambdaTuning
+		if err := cfg.GetObject("lambdaOverrides", &lambdaOverrides); err != nil {
+			return fmt.Errorf("invalid config 'lambdaOverrides': %w", err)
+		}
+		for name, tuning := range lambdaOverrides {
+			if !slices.Contains(lambdaNames, name) {
+				return fmt.Errorf("config 'lambdaOverrides' names unknown function %q (want one of %s)", name, strings.Join(lambdaNames, ", "))
+			}
+			if tuning.ProvisionedConcurrency > 0 && tuning.Warm {
+				return fmt.Errorf("config 'lambdaOverrides': %s sets both provisionedConcurrency and warm", name)
+			}
+		}
+
+		// Per-queue batch size, batching window, maximum concurrency and backlog alarm threshold,
+		// keyed by queue name (object, optional); see QueueTuning
+		var queueOverrides map[string]QueueTuning
+		if err := cfg.GetObject("queueOverrides", &queueOverrides); err != nil {
+			return fmt.Errorf("invalid config 'queueOverrides': %w", err)
+		}
+		for name, tuning := range queueOverrides {
+			if !slices.Contains(queueNames, name) {
+				return fmt.Errorf("config 'queueOverrides' names unknown queue %q (want one of %s)", name, strings.Join(queueNames, ", "))
+			}
+			if err := tuning.validate(); err != nil {
+				return fmt.Errorf("config 'queueOverrides': %s: %w", name, err)
+			}
+		}
 
 		schedulerCron := cfg.Get("schedulerCron")
 		if schedulerCron == "" {
@@ -61,6 +118,140 @@ func main() {
 			log.Printf("Using default schedulerCron: %s", schedulerCron)
 		}
 
+		// Remote A2A agents the scheduler may delegate to (JSON array, optional)
+		a2aAgents := cfg.Get("a2aAgents")
+
+		// Limits applied to every scheduled agent run (JSON object); default allows one booking per run
+		agentGuardrails := cfg.Get("agentGuardrails")
+		if agentGuardrails == "" {
+			agentGuardrails = `{"max_bookings_per_run":1}`
+		}
+
+		// Bedrock guardrail (ID or ARN) that filters scheduled agent prompts and responses (optional)
+		bedrockGuardrailArn := cfg.Get("bedrockGuardrailArn")
+		bedrockGuardrailVersion := cfg.Get("bedrockGuardrailVersion")
+		if bedrockGuardrailArn != "" && bedrockGuardrailVersion == "" {
+			bedrockGuardrailVersion = "DRAFT"
+		}
+
+		// Bedrock models the scheduled agent and the chat agent call; their IAM policies name only these
+		schedulerModelID := cfg.Get("schedulerModelId")
+		if schedulerModelID == "" {
+			schedulerModelID = "amazon.nova-lite-v1:0"
+		}
+		agentModelID := cfg.Get("agentModelId")
+		if agentModelID == "" {
+			agentModelID = "us.anthropic.claude-sonnet-4-20250514-v1:0"
+		}
+
+		// API key for the data export and deletion endpoints (secret, optional; endpoints are disabled without it)
+		dataRequestAPIKey := cfg.GetSecret("dataRequestApiKey")
+
+		// API key for the Prometheus metrics endpoint (secret, optional; the endpoint is disabled without it)
+		metricsAPIKey := cfg.GetSecret("metricsApiKey")
+
+		// Key that signs calendar feed URLs (secret, optional; the feed is disabled without it)
+		calendarSigningKey := cfg.GetSecret("calendarSigningKey")
+
+		// Key that signs the chat UI's session cookie (secret, optional; the chat UI is disabled without it)
+		agentSessionSigningKey := cfg.GetSecret("agentSessionSigningKey")
+
+		// Custom domain for the HTTP API, e.g. api.example.com (optional; the default execute-api URL is used without it)
+		domainName := cfg.Get("domainName")
+		hostedZoneName := cfg.Get("hostedZoneName")
+
+		// WAF in front of the HTTP API (optional): per-IP rate limit, AWS managed common rules, and an IP allowlist
+		wafEnabled := cfg.GetBool("wafEnabled")
+		wafRateLimit := cfg.GetInt("wafRateLimit")
+		if wafRateLimit == 0 {
+			wafRateLimit = 1000 // Requests per 5 minutes from one IP
+		}
+		var wafAllowedIps []string
+		for _, cidr := range strings.Split(cfg.Get("wafAllowedIps"), ",") {
+			if cidr = strings.TrimSpace(cidr); cidr != "" {
+				wafAllowedIps = append(wafAllowedIps, cidr)
+			}
+		}
+		if wafEnabled && domainName != "" {
+			// The custom domain points at the API directly and would bypass the web ACL
+			return fmt.Errorf("config 'wafEnabled' cannot be combined with 'domainName'")
+		}
+
+		// Where CloudWatch alarms are sent: an email address and/or an ntfy topic URL (optional)
+		alertEmail := cfg.Get("alertEmail")
+		alertNtfyUrl := cfg.Get("alertNtfyUrl")
+
+		// Commit being deployed, set by the deploy workflows and shown in triage reports (optional)
+		deployVersion := cfg.Get("deployVersion")
+
+		// Days between rotations of the golf credentials secret; 0 (the default) leaves rotation off.
+		// The secret itself is created by hand, so it is looked up rather than managed here.
+		golfSecretRotationDays := cfg.GetInt("golfSecretRotationDays")
+		if golfSecretRotationDays < 0 || golfSecretRotationDays > 1000 {
+			return fmt.Errorf("config 'golfSecretRotationDays' must be between 0 and 1000, got %d", golfSecretRotationDays)
+		}
+		// Every stage books with the prod credentials (courses.GetSecretName)
+		golfSecretName := cfg.Get("golfSecretName")
+		if golfSecretName == "" {
+			golfSecretName = "rez-agent/golf/credentials-prod"
+		}
+
+		// Multi-user mode: the web API requires a user's API key on the messages and schedules
+		// endpoints. Off by default so existing single-golfer clients keep working.
+		multiUser := cfg.GetBool("multiUser")
+
+		// Origins browsers may call the web API, MCP server and agent from (comma-separated, or *);
+		// unset, the Lambdas allow any origin in dev and none elsewhere
+		corsAllowedOrigins := cfg.Get("corsAllowedOrigins")
+		corsAllowCredentials := cfg.GetBool("corsAllowCredentials")
+		if corsAllowCredentials && (corsAllowedOrigins == "" || corsAllowedOrigins == "*") {
+			return fmt.Errorf("config 'corsAllowCredentials' requires 'corsAllowedOrigins' to list origins")
+		}
+
+		// Keep web action response bodies over 50KB whole in the agent logs bucket, linked from
+		// GET /api/web-actions/{id}/body
+		webActionBodyOffload := cfg.GetBool("webActionBodyOffload")
+
+		// How often the stuck message sweeper runs (EventBridge rate or cron)
+		sweeperSchedule := cfg.Get("sweeperSchedule")
+		if sweeperSchedule == "" {
+			sweeperSchedule = "rate(15 minutes)"
+		}
+
+		// How often the schedules table is reconciled with EventBridge (EventBridge rate or cron), and
+		// whether the reconciler repairs what it finds or only reports it
+		scheduleReconcileSchedule := cfg.Get("scheduleReconcileSchedule")
+		if scheduleReconcileSchedule == "" {
+			scheduleReconcileSchedule = "rate(1 hour)"
+		}
+		scheduleReconcileRepair := cfg.GetBool("scheduleReconcileRepair")
+
+		// How often golf reservations are copied into the reservations table (EventBridge rate or cron)
+		reservationSyncSchedule := cfg.Get("reservationSyncSchedule")
+		if reservationSyncSchedule == "" {
+			reservationSyncSchedule = "rate(1 hour)"
+		}
+
+		// When the booking canary runs (EventBridge rate or cron, UTC); by default half an hour
+		// before the 7am Eastern booking run in summer time, an hour and a half in winter
+		canarySchedule := cfg.Get("canarySchedule")
+		if canarySchedule == "" {
+			canarySchedule = "cron(30 10 * * ? *)"
+		}
+
+		// Weekly digest email (optional): sent through SES from digestEmailFrom to the comma-separated
+		// digestRecipients whenever a weekly_digest schedule fires. Off unless a sender is set.
+		digestEmailFrom := cfg.Get("digestEmailFrom")
+		digestRecipients := cfg.Get("digestRecipients")
+		digestEnabled := digestEmailFrom != ""
+		if digestEnabled && digestRecipients == "" {
+			return fmt.Errorf("config 'digestEmailFrom' requires 'digestRecipients'")
+		}
+
+		// Domain event bus (optional): MessageCreated, BookingCompleted and ScheduleTriggered events
+		// are published to rez-agent-events-<stage> for other systems to subscribe to with rules
+		eventBusEnabled := cfg.GetBool("eventBus")
+
 		log.Printf("Configuration loaded successfully: stage=%s, logRetentionDays=%d, enableXRay=%v", stage, logRetentionDays, enableXRay)
 
 		// Common tags
@@ -71,41 +262,31 @@ func main() {
 			"Environment": pulumi.String(stage),
 		}
 
+		// ========================================
+		// S3 Bucket for Access Logs
+		// ========================================
+		log.Printf("Creating S3 bucket for access logs...")
+		accessLogsBucket, err := newAccessLogsBucket(ctx, stage, commonTags)
+		if err != nil {
+			return err
+		}
+
 		// ========================================
 		// S3 Bucket for Lambda Deployment Artifacts
 		// ========================================
 		log.Printf("Creating S3 bucket for Lambda deployment artifacts...")
 		lambdaDeploymentBucket, err := s3.NewBucket(ctx, fmt.Sprintf("rez-agent-lambda-deployments-%s", stage), &s3.BucketArgs{
-			Bucket: pulumi.String(fmt.Sprintf("rez-agent-lambda-deployments-%s", stage)),
+			Bucket:       pulumi.String(fmt.Sprintf("rez-agent-lambda-deployments-%s", stage)),
 			ForceDestroy: pulumi.Bool(true),
-			Tags:   commonTags,
+			Tags:         commonTags,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create Lambda deployment S3 bucket: %w", err)
 		}
 
-		// Block public access to the deployment bucket
-		_, err = s3.NewBucketPublicAccessBlock(ctx, fmt.Sprintf("rez-agent-lambda-deployments-pab-%s", stage), &s3.BucketPublicAccessBlockArgs{
-			Bucket:                lambdaDeploymentBucket.ID(),
-			BlockPublicAcls:       pulumi.Bool(true),
-			BlockPublicPolicy:     pulumi.Bool(true),
-			IgnorePublicAcls:      pulumi.Bool(true),
-			RestrictPublicBuckets: pulumi.Bool(true),
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create bucket public access block: %w", err)
-		}
-
-		// Upload agent.zip to S3 (for large Lambda packages > 50MB)
-		log.Printf("Uploading agent.zip to S3...")
-		agentZipObject, err := s3.NewBucketObject(ctx, fmt.Sprintf("rez-agent-agent-code-%s", stage), &s3.BucketObjectArgs{
-			Bucket: lambdaDeploymentBucket.ID(),
-			Key:    pulumi.String(fmt.Sprintf("agent-%s.zip", stage)),
-			Source: pulumi.NewFileArchive("../build/agent.zip"),
-			Tags:   commonTags,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to upload agent.zip to S3: %w", err)
+		// Encrypt, block public access, require TLS and log access
+		if err := secureBucket(ctx, "rez-agent-lambda-deployments", stage, lambdaDeploymentBucket, accessLogsBucket); err != nil {
+			return err
 		}
 
 		// ========================================
@@ -113,27 +294,21 @@ func main() {
 		// ========================================
 		log.Printf("Creating S3 bucket for agent logs...")
 		agentLogsBucket, err := s3.NewBucket(ctx, fmt.Sprintf("rez-agent-logs-%s", stage), &s3.BucketArgs{
-			Bucket: pulumi.String(fmt.Sprintf("rez-agent-logs-%s", stage)),
+			Bucket:       pulumi.String(fmt.Sprintf("rez-agent-logs-%s", stage)),
 			ForceDestroy: pulumi.Bool(true),
-			Tags:   commonTags,
+			Tags:         commonTags,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create agent logs S3 bucket: %w", err)
 		}
 
-		// Block public access to the logs bucket
-		_, err = s3.NewBucketPublicAccessBlock(ctx, fmt.Sprintf("rez-agent-logs-pab-%s", stage), &s3.BucketPublicAccessBlockArgs{
-			Bucket:                agentLogsBucket.ID(),
-			BlockPublicAcls:       pulumi.Bool(true),
-			BlockPublicPolicy:     pulumi.Bool(true),
-			IgnorePublicAcls:      pulumi.Bool(true),
-			RestrictPublicBuckets: pulumi.Bool(true),
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create logs bucket public access block: %w", err)
+		// Encrypt, block public access, require TLS and log access
+		if err := secureBucket(ctx, "rez-agent-logs", stage, agentLogsBucket, accessLogsBucket); err != nil {
+			return err
 		}
 
-		// Configure lifecycle policy for agent logs (auto-delete after 90 days)
+		// Configure lifecycle policy for agent logs (auto-delete after 90 days); offloaded web action
+		// response bodies go after 3 days, with the results that point to them
 		_, err = s3.NewBucketLifecycleConfigurationV2(ctx, fmt.Sprintf("rez-agent-logs-lifecycle-%s", stage), &s3.BucketLifecycleConfigurationV2Args{
 			Bucket: agentLogsBucket.ID(),
 			Rules: s3.BucketLifecycleConfigurationV2RuleArray{
@@ -144,6 +319,16 @@ func main() {
 						Days: pulumi.Int(90),
 					},
 				},
+				&s3.BucketLifecycleConfigurationV2RuleArgs{
+					Id:     pulumi.String("delete-response-bodies"),
+					Status: pulumi.String("Enabled"),
+					Filter: &s3.BucketLifecycleConfigurationV2RuleFilterArgs{
+						Prefix: pulumi.String("response-bodies/"),
+					},
+					Expiration: &s3.BucketLifecycleConfigurationV2RuleExpirationArgs{
+						Days: pulumi.Int(3),
+					},
+				},
 			},
 		})
 		if err != nil {
@@ -176,6 +361,10 @@ func main() {
 					Name: pulumi.String("stage"),
 					Type: pulumi.String("S"),
 				},
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("created_by"),
+					Type: pulumi.String("S"),
+				},
 			},
 			GlobalSecondaryIndexes: dynamodb.TableGlobalSecondaryIndexArray{
 				&dynamodb.TableGlobalSecondaryIndexArgs{
@@ -190,12 +379,22 @@ func main() {
 					RangeKey:       pulumi.String("created_date"),
 					ProjectionType: pulumi.String("ALL"),
 				},
+				// A user's messages, for member listings and data export and deletion
+				&dynamodb.TableGlobalSecondaryIndexArgs{
+					Name:           pulumi.String("created_by-created_date-index"),
+					HashKey:        pulumi.String("created_by"),
+					RangeKey:       pulumi.String("created_date"),
+					ProjectionType: pulumi.String("ALL"),
+				},
 			},
 			Ttl: &dynamodb.TableTtlArgs{
 				AttributeName: pulumi.String("ttl"),
 				Enabled:       pulumi.Bool(true),
 			},
-			Tags: commonTags,
+			// The outbox relay publishes new messages from the stream
+			StreamEnabled:  pulumi.Bool(true),
+			StreamViewType: pulumi.String("NEW_IMAGE"),
+			Tags:           commonTags,
 		})
 		if err != nil {
 			return err
@@ -217,6 +416,14 @@ func main() {
 					Name: pulumi.String("message_id"),
 					Type: pulumi.String("S"),
 				},
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("stage"),
+					Type: pulumi.String("S"),
+				},
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("created_date"),
+					Type: pulumi.String("S"),
+				},
 			},
 			GlobalSecondaryIndexes: dynamodb.TableGlobalSecondaryIndexArray{
 				&dynamodb.TableGlobalSecondaryIndexArgs{
@@ -224,6 +431,13 @@ func main() {
 					HashKey:        pulumi.String("message_id"),
 					ProjectionType: pulumi.String("ALL"),
 				},
+				// GET /api/web-actions pages through a stage's results by created date
+				&dynamodb.TableGlobalSecondaryIndexArgs{
+					Name:           pulumi.String("stage-created_date-index"),
+					HashKey:        pulumi.String("stage"),
+					RangeKey:       pulumi.String("created_date"),
+					ProjectionType: pulumi.String("ALL"),
+				},
 			},
 			Ttl: &dynamodb.TableTtlArgs{
 				AttributeName: pulumi.String("ttl"),
@@ -284,39 +498,91 @@ func main() {
 		}
 
 		// ========================================
-		// SNS Topics (Topic-based routing)
+		// DynamoDB Table for Weather Decisions
 		// ========================================
-
-		// Web Actions Topic
-		webActionsTopic, err := sns.NewTopic(ctx, fmt.Sprintf("rez-agent-web-actions-%s", stage), &sns.TopicArgs{
-			Name: pulumi.String(fmt.Sprintf("rez-agent-web-actions-%s", stage)),
+		weatherDecisionsTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-weather-decisions-%s", stage), &dynamodb.TableArgs{
+			Name:        pulumi.String(fmt.Sprintf("rez-agent-weather-decisions-%s", stage)),
+			BillingMode: pulumi.String("PAY_PER_REQUEST"),
+			HashKey:     pulumi.String("id"),
+			Attributes: dynamodb.TableAttributeArray{
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("id"),
+					Type: pulumi.String("S"),
+				},
+			},
+			Ttl: &dynamodb.TableTtlArgs{
+				AttributeName: pulumi.String("ttl"),
+				Enabled:       pulumi.Bool(true),
+			},
 			Tags: commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Notifications Topic (for scheduler, manual messages, etc.)
-		notificationsTopic, err := sns.NewTopic(ctx, fmt.Sprintf("rez-agent-notifications-%s", stage), &sns.TopicArgs{
-			Name: pulumi.String(fmt.Sprintf("rez-agent-notifications-%s", stage)),
+		// ========================================
+		// DynamoDB Table for Preferences (post-round surveys)
+		// ========================================
+		preferencesTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-preferences-%s", stage), &dynamodb.TableArgs{
+			Name:        pulumi.String(fmt.Sprintf("rez-agent-preferences-%s", stage)),
+			BillingMode: pulumi.String("PAY_PER_REQUEST"),
+			HashKey:     pulumi.String("id"),
+			Attributes: dynamodb.TableAttributeArray{
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("id"),
+					Type: pulumi.String("S"),
+				},
+			},
+			Ttl: &dynamodb.TableTtlArgs{
+				AttributeName: pulumi.String("ttl"),
+				Enabled:       pulumi.Bool(true),
+			},
 			Tags: commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Agent Response Topic (for receiving tool results)
-		agentResponseTopic, err := sns.NewTopic(ctx, fmt.Sprintf("rez-agent-agent-responses-%s", stage), &sns.TopicArgs{
-			Name: pulumi.String(fmt.Sprintf("rez-agent-agent-responses-%s", stage)),
+		// ========================================
+		// DynamoDB Table for Booking Approvals (held tee times awaiting approve/decline)
+		// ========================================
+		approvalsTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-approvals-%s", stage), &dynamodb.TableArgs{
+			Name:        pulumi.String(fmt.Sprintf("rez-agent-approvals-%s", stage)),
+			BillingMode: pulumi.String("PAY_PER_REQUEST"),
+			HashKey:     pulumi.String("id"),
+			Attributes: dynamodb.TableAttributeArray{
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("id"),
+					Type: pulumi.String("S"),
+				},
+			},
+			Ttl: &dynamodb.TableTtlArgs{
+				AttributeName: pulumi.String("ttl"),
+				Enabled:       pulumi.Bool(true),
+			},
 			Tags: commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Schedule Creation Topic (for dynamically creating EventBridge Schedules)
-		scheduleCreationTopic, err := sns.NewTopic(ctx, fmt.Sprintf("rez-agent-schedule-creation-%s", stage), &sns.TopicArgs{
-			Name: pulumi.String(fmt.Sprintf("rez-agent-schedule-creation-%s", stage)),
+		// ========================================
+		// DynamoDB Table for the Booking Ledger (one reservation per golfer, course and date)
+		// ========================================
+		bookingLedgerTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-booking-ledger-%s", stage), &dynamodb.TableArgs{
+			Name:        pulumi.String(fmt.Sprintf("rez-agent-booking-ledger-%s", stage)),
+			BillingMode: pulumi.String("PAY_PER_REQUEST"),
+			HashKey:     pulumi.String("id"),
+			Attributes: dynamodb.TableAttributeArray{
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("id"),
+					Type: pulumi.String("S"),
+				},
+			},
+			Ttl: &dynamodb.TableTtlArgs{
+				AttributeName: pulumi.String("ttl"),
+				Enabled:       pulumi.Bool(true),
+			},
 			Tags: commonTags,
 		})
 		if err != nil {
@@ -324,97 +590,100 @@ func main() {
 		}
 
 		// ========================================
-		// SQS Queues (Separate queues per message type)
+		// DynamoDB Table for Synced Reservations
 		// ========================================
 
-		// Dead Letter Queues
-		webActionsDlq, err := sqs.NewQueue(ctx, fmt.Sprintf("rez-agent-web-actions-dlq-%s", stage), &sqs.QueueArgs{
-			Name:                    pulumi.String(fmt.Sprintf("rez-agent-web-actions-dlq-%s", stage)),
-			MessageRetentionSeconds: pulumi.Int(1209600), // 14 days
-			Tags:                    commonTags,
-		})
-		if err != nil {
-			return err
-		}
-
-		notificationsDlq, err := sqs.NewQueue(ctx, fmt.Sprintf("rez-agent-notifications-dlq-%s", stage), &sqs.QueueArgs{
-			Name:                    pulumi.String(fmt.Sprintf("rez-agent-notifications-dlq-%s", stage)),
-			MessageRetentionSeconds: pulumi.Int(1209600), // 14 days
-			Tags:                    commonTags,
-		})
-		if err != nil {
-			return err
-		}
-
-		// Web Actions Queue
-		webActionsQueue, err := sqs.NewQueue(ctx, fmt.Sprintf("rez-agent-web-actions-%s", stage), &sqs.QueueArgs{
-			Name:                     pulumi.String(fmt.Sprintf("rez-agent-web-actions-%s", stage)),
-			VisibilityTimeoutSeconds: pulumi.Int(300),     // 5 minutes
-			MessageRetentionSeconds:  pulumi.Int(1209600), // 14 days
-			RedrivePolicy: webActionsDlq.Arn.ApplyT(func(arn string) string {
-				return fmt.Sprintf(`{"deadLetterTargetArn":"%s","maxReceiveCount":3}`, arn)
-			}).(pulumi.StringOutput),
+		// One item per golfer (by credentials secret) and course, replaced by each sync
+		reservationsTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-reservations-%s", stage), &dynamodb.TableArgs{
+			Name:        pulumi.String(fmt.Sprintf("rez-agent-reservations-%s", stage)),
+			BillingMode: pulumi.String("PAY_PER_REQUEST"),
+			HashKey:     pulumi.String("secret_name"),
+			RangeKey:    pulumi.String("course_id"),
+			Attributes: dynamodb.TableAttributeArray{
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("secret_name"),
+					Type: pulumi.String("S"),
+				},
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("course_id"),
+					Type: pulumi.String("N"),
+				},
+			},
 			Tags: commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Notifications Queue
-		notificationsQueue, err := sqs.NewQueue(ctx, fmt.Sprintf("rez-agent-notifications-%s", stage), &sqs.QueueArgs{
-			Name:                     pulumi.String(fmt.Sprintf("rez-agent-notifications-%s", stage)),
-			VisibilityTimeoutSeconds: pulumi.Int(300),     // 5 minutes
-			MessageRetentionSeconds:  pulumi.Int(1209600), // 14 days
-			RedrivePolicy: notificationsDlq.Arn.ApplyT(func(arn string) string {
-				return fmt.Sprintf(`{"deadLetterTargetArn":"%s","maxReceiveCount":3}`, arn)
-			}).(pulumi.StringOutput),
+		// ========================================
+		// DynamoDB Table for Users (API keys and per-user golf credentials)
+		// ========================================
+		usersTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-users-%s", stage), &dynamodb.TableArgs{
+			Name:        pulumi.String(fmt.Sprintf("rez-agent-users-%s", stage)),
+			BillingMode: pulumi.String("PAY_PER_REQUEST"),
+			HashKey:     pulumi.String("id"),
+			Attributes: dynamodb.TableAttributeArray{
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("id"),
+					Type: pulumi.String("S"),
+				},
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("api_key_hash"),
+					Type: pulumi.String("S"),
+				},
+			},
+			GlobalSecondaryIndexes: dynamodb.TableGlobalSecondaryIndexArray{
+				&dynamodb.TableGlobalSecondaryIndexArgs{
+					Name:           pulumi.String("api_key_hash-index"),
+					HashKey:        pulumi.String("api_key_hash"),
+					ProjectionType: pulumi.String("ALL"),
+				},
+			},
+			PointInTimeRecovery: &dynamodb.TablePointInTimeRecoveryArgs{
+				Enabled: pulumi.Bool(true),
+			},
 			Tags: commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Agent Response Queue
-		agentResponseDlq, err := sqs.NewQueue(ctx, fmt.Sprintf("rez-agent-agent-responses-dlq-%s", stage), &sqs.QueueArgs{
-			Name:                    pulumi.String(fmt.Sprintf("rez-agent-agent-responses-dlq-%s", stage)),
-			MessageRetentionSeconds: pulumi.Int(1209600), // 14 days
-			Tags:                    commonTags,
-		})
-		if err != nil {
-			return err
-		}
-
-		agentResponseQueue, err := sqs.NewQueue(ctx, fmt.Sprintf("rez-agent-agent-responses-%s", stage), &sqs.QueueArgs{
-			Name:                     pulumi.String(fmt.Sprintf("rez-agent-agent-responses-%s", stage)),
-			VisibilityTimeoutSeconds: pulumi.Int(300),     // 5 minutes
-			MessageRetentionSeconds:  pulumi.Int(1209600), // 14 days
-			RedrivePolicy: agentResponseDlq.Arn.ApplyT(func(arn string) string {
-				return fmt.Sprintf(`{"deadLetterTargetArn":"%s","maxReceiveCount":3}`, arn)
-			}).(pulumi.StringOutput),
+		// ========================================
+		// DynamoDB Table for OAuth Tokens (shared across Lambda invocations)
+		// ========================================
+		oauthTokensTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-oauth-tokens-%s", stage), &dynamodb.TableArgs{
+			Name:        pulumi.String(fmt.Sprintf("rez-agent-oauth-tokens-%s", stage)),
+			BillingMode: pulumi.String("PAY_PER_REQUEST"),
+			HashKey:     pulumi.String("key"),
+			Attributes: dynamodb.TableAttributeArray{
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("key"),
+					Type: pulumi.String("S"),
+				},
+			},
+			Ttl: &dynamodb.TableTtlArgs{
+				AttributeName: pulumi.String("ttl"),
+				Enabled:       pulumi.Bool(true),
+			},
 			Tags: commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Schedule Creation DLQ
-		scheduleCreationDlq, err := sqs.NewQueue(ctx, fmt.Sprintf("rez-agent-schedule-creation-dlq-%s", stage), &sqs.QueueArgs{
-			Name:                    pulumi.String(fmt.Sprintf("rez-agent-schedule-creation-dlq-%s", stage)),
-			MessageRetentionSeconds: pulumi.Int(1209600), // 14 days
-			Tags:                    commonTags,
-		})
-		if err != nil {
-			return err
-		}
-
-		// Schedule Creation Queue
-		scheduleCreationQueue, err := sqs.NewQueue(ctx, fmt.Sprintf("rez-agent-schedule-creation-%s", stage), &sqs.QueueArgs{
-			Name:                     pulumi.String(fmt.Sprintf("rez-agent-schedule-creation-%s", stage)),
-			VisibilityTimeoutSeconds: pulumi.Int(60),      // 1 minute (schedule creation should be quick)
-			MessageRetentionSeconds:  pulumi.Int(1209600), // 14 days
-			RedrivePolicy: scheduleCreationDlq.Arn.ApplyT(func(arn string) string {
-				return fmt.Sprintf(`{"deadLetterTargetArn":"%s","maxReceiveCount":3}`, arn)
-			}).(pulumi.StringOutput),
+		// ========================================
+		// DynamoDB Table for per-stage web action handler configs
+		// ========================================
+		webActionHandlersTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-web-action-handlers-%s", stage), &dynamodb.TableArgs{
+			Name:        pulumi.String(fmt.Sprintf("rez-agent-web-action-handlers-%s", stage)),
+			BillingMode: pulumi.String("PAY_PER_REQUEST"),
+			HashKey:     pulumi.String("action"),
+			Attributes: dynamodb.TableAttributeArray{
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("action"),
+					Type: pulumi.String("S"),
+				},
+			},
 			Tags: commonTags,
 		})
 		if err != nil {
@@ -422,150 +691,93 @@ func main() {
 		}
 
 		// ========================================
-		// SNS to SQS Subscriptions
+		// DynamoDB Table for the audit log of privileged operations (append-only)
 		// ========================================
 
-		// Web Actions Topic -> Web Actions Queue
-		_, err = sns.NewTopicSubscription(ctx, fmt.Sprintf("rez-agent-web-actions-subscription-%s", stage), &sns.TopicSubscriptionArgs{
-			Topic:              webActionsTopic.Arn,
-			Protocol:           pulumi.String("sqs"),
-			Endpoint:           webActionsQueue.Arn,
-			RawMessageDelivery: pulumi.Bool(true),
-		})
-		if err != nil {
-			return err
-		}
-
-		// Notifications Topic -> Notifications Queue
-		_, err = sns.NewTopicSubscription(ctx, fmt.Sprintf("rez-agent-notifications-subscription-%s", stage), &sns.TopicSubscriptionArgs{
-			Topic:              notificationsTopic.Arn,
-			Protocol:           pulumi.String("sqs"),
-			Endpoint:           notificationsQueue.Arn,
-			RawMessageDelivery: pulumi.Bool(true),
-		})
-		if err != nil {
-			return err
-		}
-		// Agent Response Topic -> Queue Subscription
-		_, err = sns.NewTopicSubscription(ctx, fmt.Sprintf("rez-agent-agent-responses-subscription-%s", stage), &sns.TopicSubscriptionArgs{
-			Topic:              agentResponseTopic.Arn,
-			Protocol:           pulumi.String("sqs"),
-			Endpoint:           agentResponseQueue.Arn,
-			RawMessageDelivery: pulumi.Bool(true),
-		})
-		if err != nil {
-			return err
-		}
-
-		// Schedule Creation Topic -> Schedule Creation Queue
-		_, err = sns.NewTopicSubscription(ctx, fmt.Sprintf("rez-agent-schedule-creation-subscription-%s", stage), &sns.TopicSubscriptionArgs{
-			Topic:              scheduleCreationTopic.Arn,
-			Protocol:           pulumi.String("sqs"),
-			Endpoint:           scheduleCreationQueue.Arn,
-			RawMessageDelivery: pulumi.Bool(true),
+		// No Lambda is granted UpdateItem or DeleteItem on this table, and entries are written with
+		// attribute_not_exists(id), so an entry can never be changed once recorded
+		auditTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-audit-%s", stage), &dynamodb.TableArgs{
+			Name:        pulumi.String(fmt.Sprintf("rez-agent-audit-%s", stage)),
+			BillingMode: pulumi.String("PAY_PER_REQUEST"),
+			HashKey:     pulumi.String("id"),
+			Attributes: dynamodb.TableAttributeArray{
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("id"),
+					Type: pulumi.String("S"),
+				},
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("user_id"),
+					Type: pulumi.String("S"),
+				},
+				&dynamodb.TableAttributeArgs{
+					Name: pulumi.String("created_at"),
+					Type: pulumi.String("S"),
+				},
+			},
+			GlobalSecondaryIndexes: dynamodb.TableGlobalSecondaryIndexArray{
+				&dynamodb.TableGlobalSecondaryIndexArgs{
+					Name:           pulumi.String("user_id-created_at-index"),
+					HashKey:        pulumi.String("user_id"),
+					RangeKey:       pulumi.String("created_at"),
+					ProjectionType: pulumi.String("ALL"),
+				},
+			},
+			PointInTimeRecovery: &dynamodb.TablePointInTimeRecoveryArgs{
+				Enabled: pulumi.Bool(true),
+			},
+			DeletionProtectionEnabled: pulumi.Bool(true),
+			Tags:                      commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
 		// ========================================
-		// SQS Queue Policies
+		// Messaging (SNS topic -> SQS queue per route)
 		// ========================================
 
-		// Web Actions Queue Policy
-		qPolicy, err := sqs.NewQueuePolicy(ctx, fmt.Sprintf("rez-agent-web-actions-queue-policy-%s", stage), &sqs.QueuePolicyArgs{
-			QueueUrl: webActionsQueue.Url,
-			Policy: pulumi.All(webActionsQueue.Arn, webActionsTopic.Arn).ApplyT(func(args []interface{}) string {
-				queueArn := args[0].(string)
-				topicArn := args[1].(string)
-				return fmt.Sprintf(`{
-					"Version": "2012-10-17",
-					"Statement": [{
-						"Effect": "Allow",
-						"Principal": {"Service": "sns.amazonaws.com"},
-						"Action": "sqs:SendMessage",
-						"Resource": "%s",
-						"Condition": {
-							"ArnEquals": {"aws:SourceArn": "%s"}
-						}
-					}]
-				}`, queueArn, topicArn)
-			}).(pulumi.StringOutput),
-		})
-		if err != nil {
-			return err
+		channels := []ChannelArgs{
+			{Name: "web-actions"},
+			{Name: "notifications"},                                   // scheduler, manual messages, etc.
+			{Name: "agent-responses"},                                 // tool results for the agent
+			{Name: "schedule-creation", VisibilityTimeoutSeconds: 60}, // schedule creation should be quick
 		}
-
-		// Notifications Queue Policy
-		nPolicy, err := sqs.NewQueuePolicy(ctx, fmt.Sprintf("rez-agent-notifications-queue-policy-%s", stage), &sqs.QueuePolicyArgs{
-			QueueUrl: notificationsQueue.Url,
-			Policy: pulumi.All(notificationsQueue.Arn, notificationsTopic.Arn).ApplyT(func(args []interface{}) string {
-				queueArn := args[0].(string)
-				topicArn := args[1].(string)
-				return fmt.Sprintf(`{
-					"Version": "2012-10-17",
-					"Statement": [{
-						"Effect": "Allow",
-						"Principal": {"Service": "sns.amazonaws.com"},
-						"Action": "sqs:SendMessage",
-						"Resource": "%s",
-						"Condition": {
-							"ArnEquals": {"aws:SourceArn": "%s"}
-						}
-					}]
-				}`, queueArn, topicArn)
-			}).(pulumi.StringOutput),
+		if digestEnabled {
+			channels = append(channels, ChannelArgs{Name: "digest", VisibilityTimeoutSeconds: 120}) // weekly_digest schedules
+		}
+		messaging, err := NewMessagingComponent(ctx, fmt.Sprintf("rez-agent-messaging-%s", stage), &MessagingArgs{
+			Stage:         stage,
+			Channels:      channels,
+			Subscriptions: topicSubscriptions,
+			EventBus:      eventBusEnabled,
+			Tags:          commonTags,
 		})
 		if err != nil {
 			return err
 		}
+		webActions := messaging.Channel("web-actions")
+		notifications := messaging.Channel("notifications")
+		agentResponses := messaging.Channel("agent-responses")
+		scheduleCreation := messaging.Channel("schedule-creation")
 
-		// Agent Response Queue Policy
-		agentResponseQueuePolicy, err := sqs.NewQueuePolicy(ctx, fmt.Sprintf("rez-agent-agent-responses-queue-policy-%s", stage), &sqs.QueuePolicyArgs{
-			QueueUrl: agentResponseQueue.Url,
-			Policy: pulumi.All(agentResponseQueue.Arn, agentResponseTopic.Arn).ApplyT(func(args []interface{}) string {
-				queueArn := args[0].(string)
-				topicArn := args[1].(string)
-				return fmt.Sprintf(`{
-					"Version": "2012-10-17",
-					"Statement": [{
-						"Effect": "Allow",
-						"Principal": {"Service": "sns.amazonaws.com"},
-						"Action": "sqs:SendMessage",
-						"Resource": "%s",
-						"Condition": {
-							"ArnEquals": {"aws:SourceArn": "%s"}
-						}
-					}]
-				}`, queueArn, topicArn)
-			}).(pulumi.StringOutput),
-		})
-		if err != nil {
-			return err
+		// Schedules publish weekly_digest messages to the digest topic; the scheduler learns the
+		// route from TOPIC_ROUTES, and the execution role may publish to every scheduled topic
+		scheduledTopics := []pulumi.StringInput{notifications.Topic.Arn, webActions.Topic.Arn}
+		schedulerTopicRoutes := pulumi.String("").ToStringOutput()
+		var digestChannel *Channel
+		if digestEnabled {
+			digestChannel = messaging.Channel("digest")
+			scheduledTopics = append(scheduledTopics, digestChannel.Topic.Arn)
+			schedulerTopicRoutes = digestChannel.Topic.Arn.ApplyT(func(arn string) (string, error) {
+				routes, err := json.Marshal(map[string]string{"weekly_digest": arn})
+				return string(routes), err
+			}).(pulumi.StringOutput)
 		}
 
-		// Schedule Creation Queue Policy
-		scheduleCreationQueuePolicy, err := sqs.NewQueuePolicy(ctx, fmt.Sprintf("rez-agent-schedule-creation-queue-policy-%s", stage), &sqs.QueuePolicyArgs{
-			QueueUrl: scheduleCreationQueue.Url,
-			Policy: pulumi.All(scheduleCreationQueue.Arn, scheduleCreationTopic.Arn).ApplyT(func(args []interface{}) string {
-				queueArn := args[0].(string)
-				topicArn := args[1].(string)
-				return fmt.Sprintf(`{
-					"Version": "2012-10-17",
-					"Statement": [{
-						"Effect": "Allow",
-						"Principal": {"Service": "sns.amazonaws.com"},
-						"Action": "sqs:SendMessage",
-						"Resource": "%s",
-						"Condition": {
-							"ArnEquals": {"aws:SourceArn": "%s"}
-						}
-					}]
-				}`, queueArn, topicArn)
-			}).(pulumi.StringOutput),
-		})
-		if err != nil {
-			return err
+		// Lambdas that publish domain events learn the bus from EVENT_BUS_NAME; empty turns them off
+		eventBusName := pulumi.StringInput(pulumi.String(""))
+		if messaging.EventBus != nil {
+			eventBusName = messaging.EventBus.Name
 		}
 
 		// ========================================
@@ -585,120 +797,8 @@ func main() {
 		// IAM Roles and Policies
 		// ========================================
 
-		// Scheduler Lambda Role
-		schedulerRole, err := iam.NewRole(ctx, fmt.Sprintf("rez-agent-scheduler-role-%s", stage), &iam.RoleArgs{
-			Name: pulumi.String(fmt.Sprintf("rez-agent-scheduler-role-%s", stage)),
-			AssumeRolePolicy: pulumi.String(`{
-				"Version": "2012-10-17",
-				"Statement": [{
-					"Effect": "Allow",
-					"Principal": {"Service": "lambda.amazonaws.com"},
-					"Action": "sts:AssumeRole"
-				}]
-			}`),
-			Tags: commonTags,
-		})
-		if err != nil {
-			return err
-		}
-
-		// Scheduler Lambda Policy
-		_, err = iam.NewRolePolicy(ctx, fmt.Sprintf("rez-agent-scheduler-policy-%s", stage), &iam.RolePolicyArgs{
-			Role: schedulerRole.Name,
-			Policy: pulumi.All(
-				messagesTable.Arn,
-				schedulesTable.Arn,
-				notificationsTopic.Arn,
-				webActionsTopic.Arn,
-				scheduleCreationQueue.Arn,
-				agentLogsBucket.Arn,
-			).ApplyT(func(args []interface{}) string {
-				messagesTableArn := args[0].(string)
-				schedulesTableArn := args[1].(string)
-				notificationsTopicArn := args[2].(string)
-				webActionsTopicArn := args[3].(string)
-				scheduleCreationQueueArn := args[4].(string)
-				agentLogsBucketArn := args[5].(string)
-				return fmt.Sprintf(`{
-					"Version": "2012-10-17",
-					"Statement": [
-						{
-							"Effect": "Allow",
-							"Action": [
-								"dynamodb:PutItem",
-								"dynamodb:UpdateItem",
-								"dynamodb:GetItem",
-								"dynamodb:Query"
-							],
-							"Resource": ["%s", "%s/*", "%s", "%s/*"]
-						},
-						{
-							"Effect": "Allow",
-							"Action": ["sns:Publish"],
-							"Resource": ["%s", "%s"]
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"sqs:ReceiveMessage",
-								"sqs:DeleteMessage",
-								"sqs:GetQueueAttributes"
-							],
-							"Resource": "%s"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"s3:PutObject",
-								"s3:PutObjectAcl"
-							],
-							"Resource": "%s/*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"scheduler:CreateSchedule",
-								"scheduler:GetSchedule",
-								"scheduler:UpdateSchedule",
-								"scheduler:DeleteSchedule"
-							],
-							"Resource": "arn:aws:scheduler:*:*:schedule/default/*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": ["iam:PassRole"],
-							"Resource": "arn:aws:iam::*:role/rez-agent-eventbridge-scheduler-execution-role-%s"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"bedrock:InvokeModel",
-								"bedrock:InvokeModelWithResponseStream"
-							],
-							"Resource": "*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"logs:CreateLogGroup",
-								"logs:CreateLogStream",
-								"logs:PutLogEvents"
-							],
-							"Resource": "arn:aws:logs:*:*:*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"xray:PutTraceSegments",
-								"xray:PutTelemetryRecords"
-							],
-							"Resource": "*"
-						}
-					]
-				}`, messagesTableArn, messagesTableArn, schedulesTableArn, schedulesTableArn,
-					notificationsTopicArn, webActionsTopicArn, scheduleCreationQueueArn, agentLogsBucketArn, stage)
-			}).(pulumi.StringOutput),
-		})
+		// Account and region for the ARNs of resources this program does not create
+		scope, err := lookupAWSScope(ctx)
 		if err != nil {
 			return err
 		}
@@ -721,492 +821,547 @@ func main() {
 			return err
 		}
 
-		// EventBridge Scheduler Execution Role Policy
+		// EventBridge Scheduler Execution Role Policy: dynamic schedules invoke the scheduler Lambda or
+		// publish to a routed topic
 		_, err = iam.NewRolePolicy(ctx, fmt.Sprintf("rez-agent-eventbridge-scheduler-execution-policy-%s", stage), &iam.RolePolicyArgs{
 			Role: eventBridgeSchedulerExecutionRole.Name,
-			Policy: pulumi.String(`{
-				"Version": "2012-10-17",
-				"Statement": [
-					{
-						"Effect": "Allow",
-						"Action": ["lambda:InvokeFunction"],
-						"Resource": "arn:aws:lambda:*:*:function:rez-agent-scheduler-*"
-					},
-					{
-						"Effect": "Allow",
-						"Action": ["sns:Publish"],
-						"Resource": "*"
-					},
-					{
-						"Effect": "Allow",
-						"Action": ["sqs:SendMessage"],
-						"Resource": "arn:aws:sqs:*:*:rez-agent-schedule-creation-queue-*"
-					}
-				]
-			}`),
+			Policy: newIAMPolicy().
+				allow([]string{"lambda:InvokeFunction"}, scope.arn("lambda", fmt.Sprintf("function:rez-agent-scheduler-%s", stage))).
+				allow([]string{"sns:Publish"}, scheduledTopics...).
+				document(),
 		})
 		if err != nil {
 			return err
 		}
 
-		// Processor Lambda Role
-		processorRole, err := iam.NewRole(ctx, fmt.Sprintf("rez-agent-processor-role-%s", stage), &iam.RoleArgs{
-			Name: pulumi.String(fmt.Sprintf("rez-agent-processor-role-%s", stage)),
-			AssumeRolePolicy: pulumi.String(`{
-				"Version": "2012-10-17",
-				"Statement": [{
-					"Effect": "Allow",
-					"Principal": {"Service": "lambda.amazonaws.com"},
-					"Action": "sts:AssumeRole"
-				}]
-			}`),
-			Tags: commonTags,
-		})
-		if err != nil {
-			return err
+		// Scheduler Lambda Policy: runs the agent for triggered schedules and records the execution
+		schedulerPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:GetItem", "dynamodb:UpdateItem"}, schedulesTable.Arn).
+			allow([]string{"dynamodb:Scan"}, weatherDecisionsTable.Arn, preferencesTable.Arn).
+			allow([]string{"dynamodb:PutItem"}, auditTable.Arn).
+			allow([]string{"s3:PutObject", "s3:PutObjectAcl", "s3:GetObject"}, bucketObjects(agentLogsBucket, "")).
+			allow([]string{"bedrock:InvokeModel", "bedrock:InvokeModelWithResponseStream"},
+				scope.bedrockModelArns(scope.region, schedulerModelID)...).
+			allow([]string{"secretsmanager:GetSecretValue"}, scope.arn("secretsmanager", "secret:rez-agent/a2a/*")).
+			allow([]string{"ssm:GetParameter"}, scope.arn("ssm", fmt.Sprintf("parameter/rez-agent/%s/prompts/*", stage)))
+		if bedrockGuardrailArn != "" {
+			schedulerPolicy.allow([]string{"bedrock:ApplyGuardrail"}, scope.bedrockGuardrailArn(bedrockGuardrailArn))
 		}
 
+		// Schedule Creation Lambda Policy: creates and deletes EventBridge schedules, but never
+		// calls a model or reads secrets
+		scheduleCreationPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:PutItem", "dynamodb:UpdateItem", "dynamodb:GetItem", "dynamodb:Query"},
+				messagesTable.Arn, tableIndexes(messagesTable), schedulesTable.Arn, tableIndexes(schedulesTable)).
+			allow([]string{"dynamodb:PutItem"}, auditTable.Arn).
+			allow([]string{"sns:Publish"}, notifications.Topic.Arn, webActions.Topic.Arn).
+			allow(sqsConsumerActions, scheduleCreation.Queue.Arn).
+			allow([]string{"scheduler:CreateSchedule", "scheduler:GetSchedule", "scheduler:UpdateSchedule", "scheduler:DeleteSchedule"},
+				scope.arn("scheduler", "schedule/default/*")).
+			allow([]string{"iam:PassRole"}, eventBridgeSchedulerExecutionRole.Arn)
+
 		// Processor Lambda Policy
-		_, err = iam.NewRolePolicy(ctx, fmt.Sprintf("rez-agent-processor-policy-%s", stage), &iam.RolePolicyArgs{
-			Role: processorRole.Name,
-			Policy: pulumi.All(messagesTable.Arn, notificationsQueue.Arn).ApplyT(func(args []interface{}) string {
-				tableArn := args[0].(string)
-				queueArn := args[1].(string)
-				return fmt.Sprintf(`{
-					"Version": "2012-10-17",
-					"Statement": [
-						{
-							"Effect": "Allow",
-							"Action": [
-								"dynamodb:GetItem",
-								"dynamodb:UpdateItem",
-								"dynamodb:Query"
-							],
-							"Resource": ["%s", "%s/*"]
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"sqs:ReceiveMessage",
-								"sqs:DeleteMessage",
-								"sqs:GetQueueAttributes"
-							],
-							"Resource": "%s"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"ssm:GetParameter",
-								"ssm:GetParameters"
-							],
-							"Resource": "arn:aws:ssm:*:*:parameter/rez-agent/%s/*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"logs:CreateLogGroup",
-								"logs:CreateLogStream",
-								"logs:PutLogEvents"
-							],
-							"Resource": "arn:aws:logs:*:*:*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"xray:PutTraceSegments",
-								"xray:PutTelemetryRecords"
-							],
-							"Resource": "*"
-						}
-					]
-				}`, tableArn, tableArn, queueArn, stage)
-			}).(pulumi.StringOutput),
-		})
-		if err != nil {
-			return err
+		processorPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:GetItem", "dynamodb:UpdateItem", "dynamodb:Query"}, messagesTable.Arn, tableIndexes(messagesTable)).
+			allow(sqsConsumerActions, notifications.Queue.Arn).
+			allow([]string{"ssm:GetParameter", "ssm:GetParameters"}, scope.arn("ssm", fmt.Sprintf("parameter/rez-agent/%s/*", stage)))
+
+		// WebAPI Lambda Policy
+		webapiPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:Query", "dynamodb:GetItem", "dynamodb:PutItem", "dynamodb:UpdateItem"},
+				messagesTable.Arn, tableIndexes(messagesTable), schedulesTable.Arn, tableIndexes(schedulesTable)).
+			allow([]string{"dynamodb:PutItem", "dynamodb:Scan"}, preferencesTable.Arn).
+			allow([]string{"dynamodb:GetItem", "dynamodb:UpdateItem"}, approvalsTable.Arn).
+			allow([]string{"dynamodb:GetItem", "dynamodb:PutItem", "dynamodb:Query"}, usersTable.Arn, tableIndexes(usersTable)).
+			allow([]string{"dynamodb:Query"}, reservationsTable.Arn).
+			allow([]string{"sns:Publish"}, webActions.Topic.Arn, notifications.Topic.Arn, scheduleCreation.Topic.Arn).
+			allow([]string{"dynamodb:Scan", "dynamodb:DeleteItem"}, messagesTable.Arn, schedulesTable.Arn).
+			allow([]string{"dynamodb:PutItem", "dynamodb:Query", "dynamodb:Scan"}, auditTable.Arn, tableIndexes(auditTable)).
+			allow([]string{"dynamodb:GetItem", "dynamodb:Query", "dynamodb:DeleteItem"}, webActionResultsTable.Arn, tableIndexes(webActionResultsTable)).
+			// The session table belongs to the agent component, created after this Lambda
+			allow([]string{"dynamodb:GetItem", "dynamodb:DeleteItem"}, scope.arn("dynamodb", fmt.Sprintf("table/rez-agent-sessions-%s", stage))).
+			allow([]string{"s3:ListBucket"}, agentLogsBucket.Arn).
+			allow([]string{"s3:DeleteObject"}, bucketObjects(agentLogsBucket, "summaries/")).
+			// POST /api/admin/reconcile-schedules repairs schedules as well as deleting them
+			allow([]string{"scheduler:GetSchedule", "scheduler:UpdateSchedule", "scheduler:DeleteSchedule"}, scope.arn("scheduler", "schedule/default/*")).
+			allow([]string{"iam:PassRole"}, eventBridgeSchedulerExecutionRole.Arn).
+			// Queue depths for GET /api/metrics/prometheus
+			allow([]string{"sqs:GetQueueAttributes"}, webActions.Queue.Arn, notifications.Queue.Arn, agentResponses.Queue.Arn, scheduleCreation.Queue.Arn)
+		if webActionBodyOffload {
+			// Presigned body links are signed with the web API's role
+			webapiPolicy.allow([]string{"s3:GetObject"}, bucketObjects(agentLogsBucket, "response-bodies/"))
+		}
+		webapiPolicy = webapiPolicy.with(listSchedulesStatement)
+
+		// Lambdas that publish domain events may put them on the bus
+		if messaging.EventBus != nil {
+			for _, policy := range []*iamPolicy{schedulerPolicy, scheduleCreationPolicy, processorPolicy, webapiPolicy} {
+				policy.allow([]string{"events:PutEvents"}, messaging.EventBus.Arn)
+			}
 		}
 
-		// WebAPI Lambda Role
-		webapiRole, err := iam.NewRole(ctx, fmt.Sprintf("rez-agent-webapi-role-%s", stage), &iam.RoleArgs{
-			Name: pulumi.String(fmt.Sprintf("rez-agent-webapi-role-%s", stage)),
-			AssumeRolePolicy: pulumi.String(`{
-				"Version": "2012-10-17",
-				"Statement": [{
-					"Effect": "Allow",
-					"Principal": {"Service": "lambda.amazonaws.com"},
-					"Action": "sts:AssumeRole"
-				}]
-			}`),
-			Tags: commonTags,
+		// ========================================
+		// API Gateway HTTP API (created early for MCP URL)
+		// ========================================
+
+		httpApi, err := apigatewayv2.NewApi(ctx, fmt.Sprintf("rez-agent-api-%s", stage), &apigatewayv2.ApiArgs{
+			Name:         pulumi.String(fmt.Sprintf("rez-agent-api-%s", stage)),
+			ProtocolType: pulumi.String("HTTP"),
+			Description:  pulumi.String("HTTP API for rez-agent web interface"),
+			Tags:         commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// WebAPI Lambda Policy
-		_, err = iam.NewRolePolicy(ctx, fmt.Sprintf("rez-agent-webapi-policy-%s", stage), &iam.RolePolicyArgs{
-			Role: webapiRole.Name,
-			Policy: pulumi.All(
-				messagesTable.Arn,
-				schedulesTable.Arn,
-				webActionsTopic.Arn,
-				notificationsTopic.Arn,
-				scheduleCreationTopic.Arn,
-			).ApplyT(func(args []interface{}) string {
-				messagesTableArn := args[0].(string)
-				schedulesTableArn := args[1].(string)
-				webActionsTopicArn := args[2].(string)
-				notificationsTopicArn := args[3].(string)
-				scheduleCreationTopicArn := args[4].(string)
-				return fmt.Sprintf(`{
-					"Version": "2012-10-17",
-					"Statement": [
-						{
-							"Effect": "Allow",
-							"Action": [
-								"dynamodb:Query",
-								"dynamodb:GetItem",
-								"dynamodb:PutItem",
-								"dynamodb:UpdateItem"
-							],
-							"Resource": ["%s", "%s/*", "%s", "%s/*"]
-						},
-						{
-							"Effect": "Allow",
-							"Action": ["sns:Publish"],
-							"Resource": ["%s", "%s", "%s"]
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"logs:CreateLogGroup",
-								"logs:CreateLogStream",
-								"logs:PutLogEvents"
-							],
-							"Resource": "arn:aws:logs:*:*:*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"xray:PutTraceSegments",
-								"xray:PutTelemetryRecords"
-							],
-							"Resource": "*"
-						}
-					]
-				}`, messagesTableArn, messagesTableArn, schedulesTableArn, schedulesTableArn,
-					webActionsTopicArn, notificationsTopicArn, scheduleCreationTopicArn)
-			}).(pulumi.StringOutput),
+		// Lambdas behind the API may be invoked by any of its routes
+		apiInvokeArn := httpApi.ExecutionArn.ApplyT(func(arn string) string {
+			return fmt.Sprintf("%s/*/*", arn)
+		}).(pulumi.StringOutput)
+
+		// Base URL the Lambdas hand out for the API: the custom domain when configured
+		apiBaseUrl := httpApi.ApiEndpoint
+		var apiDomain *customDomain
+		if domainName != "" {
+			apiDomain, err = newCustomDomain(ctx, stage, domainName, hostedZoneName, commonTags)
+			if err != nil {
+				return err
+			}
+			apiBaseUrl = pulumi.String(apiDomain.URL()).ToStringOutput()
+		}
+		mcpServerUrl := apiBaseUrl.ApplyT(func(endpoint string) string {
+			return fmt.Sprintf("%s/mcp", endpoint)
+		}).(pulumi.StringOutput)
+
+		// Behind WAF, people use the CloudFront URL; Lambdas keep calling MCP directly so an IP
+		// allowlist cannot block them
+		if wafEnabled {
+			apiBaseUrl, err = newProtectedEndpoint(ctx, stage, httpApi.ApiEndpoint, wafOptions{
+				rateLimit:      wafRateLimit,
+				managedRules:   cfg.Get("wafManagedRules") != "false",
+				allowedIPCIDRs: wafAllowedIps,
+			}, commonTags)
+			if err != nil {
+				return err
+			}
+		}
+
+		// ========================================
+		// Lambda Functions
+		// ========================================
+
+		// Scheduler Lambda: invoked by EventBridge Scheduler to run scheduled agent events
+		schedulerService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-scheduler-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "scheduler",
+			Code:         pulumi.NewFileArchive("../build/scheduler.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       schedulerPolicy,
+			Environment: pulumi.StringMap{
+				"DYNAMODB_TABLE_NAME":          messagesTable.Name,
+				"SCHEDULES_TABLE_NAME":         schedulesTable.Name,
+				"AUDIT_TABLE_NAME":             auditTable.Name,
+				"BEDROCK_MODEL_ID":             pulumi.String(schedulerModelID),
+				"AGENT_LOGS_BUCKET":            agentLogsBucket.ID(),
+				"WEATHER_DECISIONS_TABLE_NAME": weatherDecisionsTable.Name,
+				"PREFERENCES_TABLE_NAME":       preferencesTable.Name,
+				"A2A_AGENTS":                   pulumi.String(a2aAgents),
+				"AGENT_GUARDRAILS":             pulumi.String(agentGuardrails),
+				"BEDROCK_GUARDRAIL_ID":         pulumi.String(bedrockGuardrailArn),
+				"BEDROCK_GUARDRAIL_VERSION":    pulumi.String(bedrockGuardrailVersion),
+				"PROMPT_PARAMETER_PREFIX":      pulumi.String(fmt.Sprintf("/rez-agent/%s/prompts/", stage)),
+				"MCP_SERVER_URL":               mcpServerUrl,
+				"EVENT_BUS_NAME":               eventBusName,
+				"STAGE":                        pulumi.String(stage),
+			},
+			MemorySize:       256,
+			Timeout:          60,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["scheduler"],
+			LogRetentionDays: logRetentionDays,
+			Tags:             commonTags,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Schedule Creation Lambda: consumes schedule creation requests and points agent run
+		// schedules at the scheduler Lambda
+		scheduleCreationService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-schedulecreation-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "schedulecreation",
+			Code:         pulumi.NewFileArchive("../build/schedulecreation.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       scheduleCreationPolicy,
+			Environment: pulumi.StringMap{
+				"DYNAMODB_TABLE_NAME":            messagesTable.Name,
+				"SCHEDULES_TABLE_NAME":           schedulesTable.Name,
+				"AUDIT_TABLE_NAME":               auditTable.Name,
+				"WEB_ACTIONS_TOPIC_ARN":          webActions.Topic.Arn,       // Topic-based routing
+				"NOTIFICATIONS_TOPIC_ARN":        notifications.Topic.Arn,    // Topic-based routing
+				"SCHEDULE_CREATION_TOPIC_ARN":    scheduleCreation.Topic.Arn, // For publishing new schedule requests
+				"SCHEDULE_CREATION_QUEUE_URL":    scheduleCreation.Queue.Url, // For receiving schedule creation requests
+				"TOPIC_ROUTES":                   schedulerTopicRoutes,       // Routed schedule targets such as weekly_digest
+				"WEB_ACTION_SQS_QUEUE_URL":       webActions.Queue.Url,
+				"NOTIFICATION_SQS_QUEUE_URL":     notifications.Queue.Url,
+				"EVENTBRIDGE_EXECUTION_ROLE_ARN": eventBridgeSchedulerExecutionRole.Arn,
+				"SCHEDULER_FUNCTION_ARN":         schedulerService.Function.Arn, // Target of agent run schedules
+				"EVENT_BUS_NAME":                 eventBusName,
+				"STAGE":                          pulumi.String(stage),
+			},
+			MemorySize:       128,
+			Timeout:          60,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["schedulecreation"],
+			LogRetentionDays: logRetentionDays,
+			Trigger: &SQSTriggerArgs{
+				Queue:                   scheduleCreation.Queue,
+				BatchSize:               10,
+				ReportBatchItemFailures: true,
+				DependsOn:               []pulumi.Resource{scheduleCreation.QueuePolicy},
+				Tuning:                  queueOverrides["schedule-creation"],
+			},
+			Tags: commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// ========================================
-		// CloudWatch Log Groups
-		// ========================================
-		schedulerLogGroup, err := cloudwatch.NewLogGroup(ctx, fmt.Sprintf("rez-agent-scheduler-logs-%s", stage), &cloudwatch.LogGroupArgs{
-			Name:            pulumi.String(fmt.Sprintf("/aws/lambda/rez-agent-scheduler-%s", stage)),
-			RetentionInDays: pulumi.Int(logRetentionDays),
-			Tags:            commonTags,
+		// Processor Lambda
+		processorService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-processor-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "processor",
+			Code:         pulumi.NewFileArchive("../build/processor.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       processorPolicy,
+			Environment: pulumi.StringMap{
+				"DYNAMODB_TABLE_NAME":        messagesTable.Name,
+				"WEB_ACTIONS_TOPIC_ARN":      webActions.Topic.Arn,    // Topic-based routing
+				"NOTIFICATIONS_TOPIC_ARN":    notifications.Topic.Arn, // Topic-based routing
+				"WEB_ACTION_SQS_QUEUE_URL":   webActions.Queue.Url,
+				"NOTIFICATION_SQS_QUEUE_URL": notifications.Queue.Url,
+				"NTFY_URL":                   pulumi.String(ntfyUrl),
+				"SQS_BATCH_CONCURRENCY":      pulumi.String("10"), // Notifications are independent; send a batch at once
+				"EVENT_BUS_NAME":             eventBusName,
+				"STAGE":                      pulumi.String(stage),
+			},
+			MemorySize:       512,
+			Timeout:          300,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["processor"],
+			LogRetentionDays: logRetentionDays,
+			Trigger: &SQSTriggerArgs{
+				Queue:                   notifications.Queue,
+				BatchSize:               10,
+				ReportBatchItemFailures: true,
+				DependsOn:               []pulumi.Resource{notifications.QueuePolicy},
+				Tuning:                  queueOverrides["notifications"],
+			},
+			Tags: commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		processorLogGroup, err := cloudwatch.NewLogGroup(ctx, fmt.Sprintf("rez-agent-processor-logs-%s", stage), &cloudwatch.LogGroupArgs{
-			Name:            pulumi.String(fmt.Sprintf("/aws/lambda/rez-agent-processor-%s", stage)),
-			RetentionInDays: pulumi.Int(logRetentionDays),
-			Tags:            commonTags,
-		})
-		if err != nil {
-			return err
+		// An empty users table name keeps the web API in single-user mode
+		webapiUsersTable := pulumi.StringInput(pulumi.String(""))
+		if multiUser {
+			webapiUsersTable = usersTable.Name
 		}
 
-		webapiLogGroup, err := cloudwatch.NewLogGroup(ctx, fmt.Sprintf("rez-agent-webapi-logs-%s", stage), &cloudwatch.LogGroupArgs{
-			Name:            pulumi.String(fmt.Sprintf("/aws/lambda/rez-agent-webapi-%s", stage)),
-			RetentionInDays: pulumi.Int(logRetentionDays),
-			Tags:            commonTags,
+		// WebAPI Lambda
+		webapiService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-webapi-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "webapi",
+			Code:         pulumi.NewFileArchive("../build/webapi.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       webapiPolicy,
+			Environment: pulumi.StringMap{
+				"DYNAMODB_TABLE_NAME":           messagesTable.Name,
+				"SCHEDULES_TABLE_NAME":          schedulesTable.Name,
+				"WEB_ACTIONS_TOPIC_ARN":         webActions.Topic.Arn,       // Topic-based routing
+				"NOTIFICATIONS_TOPIC_ARN":       notifications.Topic.Arn,    // Topic-based routing
+				"AGENT_RESPONSE_TOPIC_ARN":      agentResponses.Topic.Arn,   // Topic-based routing
+				"SCHEDULE_CREATION_TOPIC_ARN":   scheduleCreation.Topic.Arn, // Schedule management
+				"SCHEDULER_FUNCTION_ARN":        schedulerService.Function.Arn,
+				"PREFERENCES_TABLE_NAME":        preferencesTable.Name,
+				"APPROVALS_TABLE_NAME":          approvalsTable.Name,
+				"AUDIT_TABLE_NAME":              auditTable.Name,
+				"USERS_TABLE_NAME":              webapiUsersTable,
+				"RESERVATIONS_TABLE_NAME":       reservationsTable.Name,
+				"WEB_ACTION_RESULTS_TABLE_NAME": webActionResultsTable.Name,
+				"AGENT_SESSION_TABLE_NAME":      pulumi.String(fmt.Sprintf("rez-agent-sessions-%s", stage)),
+				"AGENT_LOGS_BUCKET":             agentLogsBucket.ID(),
+				"DATA_REQUEST_API_KEY":          dataRequestAPIKey,
+				"METRICS_API_KEY":               metricsAPIKey,
+				"CALENDAR_SIGNING_KEY":          calendarSigningKey,
+				"WEB_ACTION_SQS_QUEUE_URL":      webActions.Queue.Url,
+				"NOTIFICATION_SQS_QUEUE_URL":    notifications.Queue.Url,
+				"AGENT_RESPONSE_QUEUE_URL":      agentResponses.Queue.Url,   // Depth for Prometheus
+				"SCHEDULE_CREATION_QUEUE_URL":   scheduleCreation.Queue.Url, // Depth for Prometheus
+				"EVENT_BUS_NAME":                eventBusName,
+				"OUTBOX_RELAY":                  pulumi.String("true"),
+				"CORS_ALLOWED_ORIGINS":          pulumi.String(corsAllowedOrigins),
+				"CORS_ALLOW_CREDENTIALS":        pulumi.String(strconv.FormatBool(corsAllowCredentials)),
+				"WEB_ACTION_BODY_OFFLOAD":       pulumi.String(strconv.FormatBool(webActionBodyOffload)),
+				"STAGE":                         pulumi.String(stage),
+			},
+			MemorySize:       256,
+			Timeout:          30,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["webapi"],
+			LogRetentionDays: logRetentionDays,
+			Tags:             commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
 		// ========================================
-		// API Gateway HTTP API (created early for MCP URL)
+		// Outbox Relay Lambda
 		// ========================================
 
-		httpApi, err := apigatewayv2.NewApi(ctx, fmt.Sprintf("rez-agent-api-%s", stage), &apigatewayv2.ApiArgs{
-			Name:         pulumi.String(fmt.Sprintf("rez-agent-api-%s", stage)),
-			ProtocolType: pulumi.String("HTTP"),
-			Description:  pulumi.String("HTTP API for rez-agent web interface"),
-			Tags:         commonTags,
+		// The web API and alarms Lambdas save new messages as created and leave publishing them to
+		// the relay, so a message cannot be saved without being published
+		outboxPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:GetItem", "dynamodb:UpdateItem"}, messagesTable.Arn).
+			allow([]string{"dynamodb:DescribeStream", "dynamodb:GetRecords", "dynamodb:GetShardIterator", "dynamodb:ListStreams"}, messagesTable.StreamArn).
+			allow([]string{"sns:Publish"}, webActions.Topic.Arn, notifications.Topic.Arn, scheduleCreation.Topic.Arn)
+		if messaging.EventBus != nil {
+			outboxPolicy.allow([]string{"events:PutEvents"}, messaging.EventBus.Arn)
+		}
+
+		outboxService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-outbox-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "outbox",
+			Code:         pulumi.NewFileArchive("../build/outbox.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       outboxPolicy,
+			Environment: pulumi.StringMap{
+				"DYNAMODB_TABLE_NAME":         messagesTable.Name,
+				"WEB_ACTIONS_TOPIC_ARN":       webActions.Topic.Arn,
+				"NOTIFICATIONS_TOPIC_ARN":     notifications.Topic.Arn,
+				"SCHEDULE_CREATION_TOPIC_ARN": scheduleCreation.Topic.Arn,
+				"EVENT_BUS_NAME":              eventBusName,
+				"STAGE":                       pulumi.String(stage),
+			},
+			MemorySize:       128,
+			Timeout:          30,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["outbox"],
+			LogRetentionDays: logRetentionDays,
+			Tags:             commonTags,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Only inserted messages that are still created invoke the relay; a message whose retries
+		// run out stays created for the stuck message sweeper
+		err = outboxService.StreamTrigger(ctx, &StreamTriggerArgs{
+			StreamArn:            messagesTable.StreamArn,
+			BatchSize:            10,
+			Filter:               `{"eventName": ["INSERT"], "dynamodb": {"NewImage": {"status": {"S": ["created"]}}}}`,
+			MaximumRetryAttempts: 5,
 		})
 		if err != nil {
 			return err
 		}
 
 		// ========================================
-		// Lambda Functions
+		// Stuck Message Sweeper
 		// ========================================
 
-		// Scheduler Lambda
-		schedulerLambda, err := lambda.NewFunction(ctx, fmt.Sprintf("rez-agent-scheduler-%s", stage), &lambda.FunctionArgs{
-			Name:    pulumi.String(fmt.Sprintf("rez-agent-scheduler-%s", stage)),
-			Runtime: pulumi.String("provided.al2"),
-			Role:    schedulerRole.Arn,
-			Handler: pulumi.String("bootstrap"),
-			Code:    pulumi.NewFileArchive("../build/scheduler.zip"),
-			Environment: &lambda.FunctionEnvironmentArgs{
-				Variables: pulumi.StringMap{
-					"DYNAMODB_TABLE_NAME":            messagesTable.Name,
-					"SCHEDULES_TABLE_NAME":           schedulesTable.Name,
-					"WEB_ACTIONS_TOPIC_ARN":          webActionsTopic.Arn,       // Topic-based routing
-					"NOTIFICATIONS_TOPIC_ARN":        notificationsTopic.Arn,    // Topic-based routing
-					"SCHEDULE_CREATION_TOPIC_ARN":    scheduleCreationTopic.Arn, // For publishing new schedule requests
-					"SCHEDULE_CREATION_QUEUE_URL":    scheduleCreationQueue.Url, // For receiving schedule creation requests
-					"WEB_ACTION_SQS_QUEUE_URL":       webActionsQueue.Url,
-					"NOTIFICATION_SQS_QUEUE_URL":     notificationsQueue.Url,
-					"EVENTBRIDGE_EXECUTION_ROLE_ARN": eventBridgeSchedulerExecutionRole.Arn,
-					"BEDROCK_MODEL_ID":               pulumi.String("amazon.nova-lite-v1:0"),
-					"AGENT_LOGS_BUCKET":              agentLogsBucket.ID(),
-					"MCP_SERVER_URL": httpApi.ApiEndpoint.ApplyT(func(endpoint string) string {
-						return fmt.Sprintf("%s/mcp", endpoint)
-					}).(pulumi.StringOutput),
-					"STAGE": pulumi.String(stage),
-				},
-			},
-			MemorySize: pulumi.Int(256),
-			Timeout:    pulumi.Int(60),
-			TracingConfig: &lambda.FunctionTracingConfigArgs{
-				Mode: pulumi.String(map[bool]string{true: "Active", false: "PassThrough"}[enableXRay]),
+		// Re-drives or fails messages left in created, queued or processing by a lost publish,
+		// delivery or status update, found through the status-created_date-index
+		sweeperPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:Query"}, tableIndexes(messagesTable)).
+			allow([]string{"dynamodb:UpdateItem"}, messagesTable.Arn).
+			allow([]string{"sns:Publish"}, webActions.Topic.Arn, notifications.Topic.Arn, scheduleCreation.Topic.Arn)
+
+		sweeperService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-sweeper-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "sweeper",
+			Code:         pulumi.NewFileArchive("../build/sweeper.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       sweeperPolicy,
+			Environment: pulumi.StringMap{
+				"DYNAMODB_TABLE_NAME":         messagesTable.Name,
+				"WEB_ACTIONS_TOPIC_ARN":       webActions.Topic.Arn,
+				"NOTIFICATIONS_TOPIC_ARN":     notifications.Topic.Arn,
+				"SCHEDULE_CREATION_TOPIC_ARN": scheduleCreation.Topic.Arn,
+				"STAGE":                       pulumi.String(stage),
 			},
-			Tags: commonTags,
-		}, pulumi.DependsOn([]pulumi.Resource{schedulerLogGroup}))
+			MemorySize:       128,
+			Timeout:          60,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["sweeper"],
+			LogRetentionDays: logRetentionDays,
+			Tags:             commonTags,
+		})
 		if err != nil {
 			return err
 		}
 
-		// Processor Lambda
-		processorLambda, err := lambda.NewFunction(ctx, fmt.Sprintf("rez-agent-processor-%s", stage), &lambda.FunctionArgs{
-			Name:    pulumi.String(fmt.Sprintf("rez-agent-processor-%s", stage)),
-			Runtime: pulumi.String("provided.al2"),
-			Role:    processorRole.Arn,
-			Handler: pulumi.String("bootstrap"),
-			Code:    pulumi.NewFileArchive("../build/processor.zip"),
-			Environment: &lambda.FunctionEnvironmentArgs{
-				Variables: pulumi.StringMap{
-					"DYNAMODB_TABLE_NAME":        messagesTable.Name,
-					"WEB_ACTIONS_TOPIC_ARN":      webActionsTopic.Arn,    // Topic-based routing
-					"NOTIFICATIONS_TOPIC_ARN":    notificationsTopic.Arn, // Topic-based routing
-					"WEB_ACTION_SQS_QUEUE_URL":   webActionsQueue.Url,
-					"NOTIFICATION_SQS_QUEUE_URL": notificationsQueue.Url,
-					"NTFY_URL":                   pulumi.String(ntfyUrl),
-					"STAGE":                      pulumi.String(stage),
-				},
-			},
-			MemorySize: pulumi.Int(512),
-			Timeout:    pulumi.Int(300),
-			TracingConfig: &lambda.FunctionTracingConfigArgs{
-				Mode: pulumi.String(map[bool]string{true: "Active", false: "PassThrough"}[enableXRay]),
-			},
-			Tags: commonTags,
-		}, pulumi.DependsOn([]pulumi.Resource{processorLogGroup}))
+		sweeperRule, err := cloudwatch.NewEventRule(ctx, fmt.Sprintf("rez-agent-sweeper-%s", stage), &cloudwatch.EventRuleArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-sweeper-%s", stage)),
+			Description:        pulumi.String("Re-drives or fails messages stuck in flight"),
+			ScheduleExpression: pulumi.String(sweeperSchedule),
+			Tags:               commonTags,
+		})
 		if err != nil {
 			return err
 		}
 
-		// SQS Event Source Mapping for Processor Lambda (Notifications Queue)
-		_, err = lambda.NewEventSourceMapping(ctx, fmt.Sprintf("rez-agent-processor-sqs-trigger-%s", stage), &lambda.EventSourceMappingArgs{
-			EventSourceArn: notificationsQueue.Arn,
-			FunctionName:   processorLambda.Arn,
-			BatchSize:      pulumi.Int(10),
-			Enabled:        pulumi.Bool(true),
-			// No filter criteria needed - dedicated queue for notifications
-		}, pulumi.DependsOn([]pulumi.Resource{nPolicy}))
+		// No retries: the next scheduled sweep picks up whatever this one missed
+		_, err = cloudwatch.NewEventTarget(ctx, fmt.Sprintf("rez-agent-sweeper-%s", stage), &cloudwatch.EventTargetArgs{
+			Rule: sweeperRule.Name,
+			Arn:  sweeperService.InvokeArn,
+			RetryPolicy: &cloudwatch.EventTargetRetryPolicyArgs{
+				MaximumRetryAttempts: pulumi.Int(0),
+			},
+		})
 		if err != nil {
 			return err
 		}
 
-		// WebAPI Lambda
-		webapiLambda, err := lambda.NewFunction(ctx, fmt.Sprintf("rez-agent-webapi-%s", stage), &lambda.FunctionArgs{
-			Name:    pulumi.String(fmt.Sprintf("rez-agent-webapi-%s", stage)),
-			Runtime: pulumi.String("provided.al2"),
-			Role:    webapiRole.Arn,
-			Handler: pulumi.String("bootstrap"),
-			Code:    pulumi.NewFileArchive("../build/webapi.zip"),
-			Environment: &lambda.FunctionEnvironmentArgs{
-				Variables: pulumi.StringMap{
-					"DYNAMODB_TABLE_NAME":         messagesTable.Name,
-					"SCHEDULES_TABLE_NAME":        schedulesTable.Name,
-					"WEB_ACTIONS_TOPIC_ARN":       webActionsTopic.Arn,       // Topic-based routing
-					"NOTIFICATIONS_TOPIC_ARN":     notificationsTopic.Arn,    // Topic-based routing
-					"AGENT_RESPONSE_TOPIC_ARN":    agentResponseTopic.Arn,    // Topic-based routing
-					"SCHEDULE_CREATION_TOPIC_ARN": scheduleCreationTopic.Arn, // Schedule management
-					"WEB_ACTION_SQS_QUEUE_URL":    webActionsQueue.Url,
-					"NOTIFICATION_SQS_QUEUE_URL":  notificationsQueue.Url,
-					"STAGE":                       pulumi.String(stage),
-				},
-			},
-			MemorySize: pulumi.Int(256),
-			Timeout:    pulumi.Int(30),
-			TracingConfig: &lambda.FunctionTracingConfigArgs{
-				Mode: pulumi.String(map[bool]string{true: "Active", false: "PassThrough"}[enableXRay]),
-			},
-			Tags: commonTags,
-		}, pulumi.DependsOn([]pulumi.Resource{webapiLogGroup}))
+		err = sweeperService.AllowInvoke(ctx, "events-permission", "events.amazonaws.com", sweeperRule.Arn)
 		if err != nil {
 			return err
 		}
 
 		// ========================================
-		// WebAction Lambda
+		// Schedule Reconciler
 		// ========================================
 
-		// WebAction Lambda Role
-		webactionRole, err := iam.NewRole(ctx, fmt.Sprintf("rez-agent-webaction-role-%s", stage), &iam.RoleArgs{
-			Name: pulumi.String(fmt.Sprintf("rez-agent-webaction-role-%s", stage)),
-			AssumeRolePolicy: pulumi.String(`{
-				"Version": "2012-10-17",
-				"Statement": [{
-					"Effect": "Allow",
-					"Principal": {"Service": "lambda.amazonaws.com"},
-					"Action": "sts:AssumeRole"
-				}]
-			}`),
-			Tags: commonTags,
+		// Compares the schedules table with the stage's EventBridge schedules and reports, or with
+		// scheduleReconcileRepair repairs, those missing, orphaned or drifted
+		reconcilerPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:Query"}, tableIndexes(schedulesTable)).
+			allow([]string{"dynamodb:UpdateItem"}, schedulesTable.Arn).
+			allow([]string{"dynamodb:PutItem"}, auditTable.Arn).
+			allow([]string{"scheduler:GetSchedule", "scheduler:UpdateSchedule", "scheduler:DeleteSchedule"}, scope.arn("scheduler", "schedule/default/*")).
+			allow([]string{"iam:PassRole"}, eventBridgeSchedulerExecutionRole.Arn).
+			with(listSchedulesStatement)
+
+		reconcilerService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-reconciler-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "reconciler",
+			Code:         pulumi.NewFileArchive("../build/reconciler.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       reconcilerPolicy,
+			Environment: pulumi.StringMap{
+				"SCHEDULES_TABLE_NAME":      schedulesTable.Name,
+				"AUDIT_TABLE_NAME":          auditTable.Name,
+				"SCHEDULER_FUNCTION_ARN":    schedulerService.Function.Arn,
+				"SCHEDULE_RECONCILE_REPAIR": pulumi.String(strconv.FormatBool(scheduleReconcileRepair)),
+				"STAGE":                     pulumi.String(stage),
+			},
+			MemorySize:       128,
+			Timeout:          120,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["reconciler"],
+			LogRetentionDays: logRetentionDays,
+			Tags:             commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Attach basic Lambda execution policy
-		_, err = iam.NewRolePolicyAttachment(ctx, fmt.Sprintf("rez-agent-webaction-basic-execution-%s", stage), &iam.RolePolicyAttachmentArgs{
-			Role:      webactionRole.Name,
-			PolicyArn: pulumi.String("arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"),
+		reconcilerRule, err := cloudwatch.NewEventRule(ctx, fmt.Sprintf("rez-agent-reconciler-%s", stage), &cloudwatch.EventRuleArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-reconciler-%s", stage)),
+			Description:        pulumi.String("Reconciles the schedules table with EventBridge schedules"),
+			ScheduleExpression: pulumi.String(scheduleReconcileSchedule),
+			Tags:               commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// WebAction Lambda Policy
-		_, err = iam.NewRolePolicy(ctx, fmt.Sprintf("rez-agent-webaction-policy-%s", stage), &iam.RolePolicyArgs{
-			Role: webactionRole.Name,
-			Policy: pulumi.All(messagesTable.Arn, webActionResultsTable.Arn, webActionsQueue.Arn, webActionsTopic.Arn, notificationsQueue.Arn, notificationsTopic.Arn, agentResponseTopic.Arn).ApplyT(func(args []interface{}) string {
-				tableArn := args[0].(string)
-				webActionResultsArn := args[1].(string)
-				waQueueArn := args[2].(string)
-				waTtopicArn := args[3].(string)
-				noQueueArn := args[4].(string)
-				noTtopicArn := args[5].(string)
-				agentResponseTopicArn := args[6].(string)
-				return fmt.Sprintf(`{
-					"Version": "2012-10-17",
-					"Statement": [
-						{
-							"Effect": "Allow",
-							"Action": [
-								"dynamodb:GetItem",
-								"dynamodb:PutItem",
-								"dynamodb:UpdateItem"
-							],
-							"Resource": [
-								"%s",
-								"%s/*"
-							]
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"dynamodb:PutItem",
-								"dynamodb:GetItem",
-								"dynamodb:Query"
-							],
-							"Resource": [
-								"%s",
-								"%s/*"
-							]
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"sqs:ReceiveMessage",
-								"sqs:DeleteMessage",
-								"sqs:GetQueueAttributes"
-							],
-							"Resource": ["%s","%s"]
-						},
-						{
-							"Effect": "Allow",
-							"Action": ["sns:Publish"],
-							"Resource": ["%s","%s","%s"]
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"secretsmanager:GetSecretValue"
-							],
-							"Resource": "arn:aws:secretsmanager:*:*:secret:rez-agent/*"
-						}
-					]
-				}`, tableArn, tableArn, webActionResultsArn, webActionResultsArn, waQueueArn, noQueueArn, waTtopicArn, noTtopicArn, agentResponseTopicArn)
-			}).(pulumi.StringOutput),
+		// No retries: the next scheduled reconciliation finds whatever this one missed
+		_, err = cloudwatch.NewEventTarget(ctx, fmt.Sprintf("rez-agent-reconciler-%s", stage), &cloudwatch.EventTargetArgs{
+			Rule: reconcilerRule.Name,
+			Arn:  reconcilerService.InvokeArn,
+			RetryPolicy: &cloudwatch.EventTargetRetryPolicyArgs{
+				MaximumRetryAttempts: pulumi.Int(0),
+			},
 		})
 		if err != nil {
 			return err
 		}
 
-		// WebAction Lambda Log Group
-		webactionLogGroup, err := cloudwatch.NewLogGroup(ctx, fmt.Sprintf("rez-agent-webaction-logs-%s", stage), &cloudwatch.LogGroupArgs{
-			Name:            pulumi.String(fmt.Sprintf("/aws/lambda/rez-agent-webaction-%s", stage)),
-			RetentionInDays: pulumi.Int(logRetentionDays),
-			Tags:            commonTags,
-		})
+		err = reconcilerService.AllowInvoke(ctx, "events-permission", "events.amazonaws.com", reconcilerRule.Arn)
 		if err != nil {
 			return err
 		}
 
+		// ========================================
+		// WebAction Lambda
+		// ========================================
+
+		// WebAction Lambda Policy
+		webactionPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:GetItem", "dynamodb:PutItem", "dynamodb:UpdateItem"}, messagesTable.Arn, tableIndexes(messagesTable)).
+			allow([]string{"dynamodb:PutItem", "dynamodb:GetItem", "dynamodb:Query"}, webActionResultsTable.Arn, tableIndexes(webActionResultsTable)).
+			allow([]string{"dynamodb:PutItem", "dynamodb:GetItem", "dynamodb:UpdateItem"}, approvalsTable.Arn).
+			allow([]string{"dynamodb:PutItem", "dynamodb:UpdateItem", "dynamodb:DeleteItem"}, bookingLedgerTable.Arn).
+			allow([]string{"dynamodb:GetItem", "dynamodb:PutItem"}, oauthTokensTable.Arn).
+			allow([]string{"dynamodb:Scan"}, webActionHandlersTable.Arn).
+			allow([]string{"dynamodb:PutItem"}, auditTable.Arn).
+			allow(sqsConsumerActions, webActions.Queue.Arn, notifications.Queue.Arn).
+			allow([]string{"sns:Publish"}, webActions.Topic.Arn, notifications.Topic.Arn, agentResponses.Topic.Arn).
+			allow([]string{"secretsmanager:GetSecretValue"}, scope.arn("secretsmanager", "secret:rez-agent/*"))
+		if messaging.EventBus != nil {
+			webactionPolicy.allow([]string{"events:PutEvents"}, messaging.EventBus.Arn)
+		}
+		if webActionBodyOffload {
+			webactionPolicy.allow([]string{"s3:PutObject"}, bucketObjects(agentLogsBucket, "response-bodies/"))
+		}
+
 		// Note: AGENT_RESPONSE_TOPIC_ARN will be added after agent infrastructure is created
 
 		// WebAction Lambda Function
-		webactionLambda, err := lambda.NewFunction(ctx, fmt.Sprintf("rez-agent-webaction-%s", stage), &lambda.FunctionArgs{
-			Name:    pulumi.String(fmt.Sprintf("rez-agent-webaction-%s", stage)),
-			Runtime: pulumi.String("provided.al2"),
-			Role:    webactionRole.Arn,
-			Handler: pulumi.String("bootstrap"),
-			Code:    pulumi.NewFileArchive("../build/webaction.zip"),
-			Environment: &lambda.FunctionEnvironmentArgs{
-				Variables: pulumi.StringMap{
-					"DYNAMODB_TABLE_NAME":         messagesTable.Name,
-					"WEB_ACTIONS_TOPIC_ARN":       webActionsTopic.Arn,    // Topic-based routing
-					"NOTIFICATIONS_TOPIC_ARN":     notificationsTopic.Arn, // Topic-based routing
-					"WEB_ACTION_SQS_QUEUE_URL":    webActionsQueue.Url,
-					"NOTIFICATION_SQS_QUEUE_URL":  notificationsQueue.Url,
-					"AGENT_RESPONSE_TOPIC_ARN":    agentResponseTopic.Arn,    // Now available
-					"SCHEDULE_CREATION_TOPIC_ARN": scheduleCreationTopic.Arn, // Schedule management
-					"STAGE":                       pulumi.String(stage),
-					"GOLF_SECRET_NAME":            pulumi.String(fmt.Sprintf("rez-agent/golf/credentials-%s", stage)),
-				},
+		webactionService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-webaction-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "webaction",
+			Code:         pulumi.NewFileArchive("../build/webaction.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       webactionPolicy,
+			Environment: pulumi.StringMap{
+				"DYNAMODB_TABLE_NAME":           messagesTable.Name,
+				"WEB_ACTIONS_TOPIC_ARN":         webActions.Topic.Arn,    // Topic-based routing
+				"NOTIFICATIONS_TOPIC_ARN":       notifications.Topic.Arn, // Topic-based routing
+				"WEB_ACTION_SQS_QUEUE_URL":      webActions.Queue.Url,
+				"NOTIFICATION_SQS_QUEUE_URL":    notifications.Queue.Url,
+				"AGENT_RESPONSE_TOPIC_ARN":      agentResponses.Topic.Arn,   // Now available
+				"SCHEDULE_CREATION_TOPIC_ARN":   scheduleCreation.Topic.Arn, // Schedule management
+				"STAGE":                         pulumi.String(stage),
+				"GOLF_SECRET_NAME":              pulumi.String(fmt.Sprintf("rez-agent/golf/credentials-%s", stage)),
+				"NTFY_URL":                      pulumi.String(ntfyUrl),
+				"APPROVALS_TABLE_NAME":          approvalsTable.Name,
+				"APPROVAL_BASE_URL":             apiBaseUrl,
+				"OAUTH_TOKEN_TABLE_NAME":        oauthTokensTable.Name,
+				"AUDIT_TABLE_NAME":              auditTable.Name,
+				"WEB_ACTION_HANDLER_TABLE_NAME": webActionHandlersTable.Name,
+				"BOOKING_LEDGER_TABLE_NAME":     bookingLedgerTable.Name,
+				"EVENT_BUS_NAME":                eventBusName,
+				"AGENT_LOGS_BUCKET":             agentLogsBucket.ID(),
+				"WEB_ACTION_BODY_OFFLOAD":       pulumi.String(strconv.FormatBool(webActionBodyOffload)),
 			},
-			MemorySize: pulumi.Int(512),
-			Timeout:    pulumi.Int(300),
-			TracingConfig: &lambda.FunctionTracingConfigArgs{
-				Mode: pulumi.String(map[bool]string{true: "Active", false: "PassThrough"}[enableXRay]),
+			MemorySize:       512,
+			Timeout:          300,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["webaction"],
+			LogRetentionDays: logRetentionDays,
+			Trigger: &SQSTriggerArgs{
+				Queue:                   webActions.Queue,
+				BatchSize:               1,
+				ReportBatchItemFailures: true,
+				DependsOn:               []pulumi.Resource{webActions.QueuePolicy},
+				Tuning:                  queueOverrides["web-actions"],
 			},
 			Tags: commonTags,
-		}, pulumi.DependsOn([]pulumi.Resource{webactionLogGroup}))
-		if err != nil {
-			return err
-		}
-
-		// WebAction Lambda SQS Event Source Mapping (Web Actions Queue)
-		_, err = lambda.NewEventSourceMapping(ctx, fmt.Sprintf("rez-agent-webaction-sqs-trigger-%s", stage), &lambda.EventSourceMappingArgs{
-			EventSourceArn: webActionsQueue.Arn,
-			FunctionName:   webactionLambda.Arn,
-			BatchSize:      pulumi.Int(1),
-			Enabled:        pulumi.Bool(true),
-			// No filter criteria needed - dedicated queue for web actions
-		}, pulumi.DependsOn([]pulumi.Resource{qPolicy}))
+		})
 		if err != nil {
 			return err
 		}
@@ -1217,14 +1372,7 @@ func main() {
 
 		// API Gateway HTTP API
 		// Lambda permission for API Gateway to invoke WebAPI
-		_, err = lambda.NewPermission(ctx, fmt.Sprintf("rez-agent-webapi-apigw-permission-%s", stage), &lambda.PermissionArgs{
-			Action:    pulumi.String("lambda:InvokeFunction"),
-			Function:  webapiLambda.Name,
-			Principal: pulumi.String("apigateway.amazonaws.com"),
-			SourceArn: httpApi.ExecutionArn.ApplyT(func(arn string) string {
-				return fmt.Sprintf("%s/*/*", arn)
-			}).(pulumi.StringOutput),
-		})
+		err = webapiService.AllowInvoke(ctx, "apigw-permission", "apigateway.amazonaws.com", apiInvokeArn)
 		if err != nil {
 			return err
 		}
@@ -1233,7 +1381,7 @@ func main() {
 		httpApiIntegration, err := apigatewayv2.NewIntegration(ctx, fmt.Sprintf("rez-agent-api-integration-%s", stage), &apigatewayv2.IntegrationArgs{
 			ApiId:                httpApi.ID(),
 			IntegrationType:      pulumi.String("AWS_PROXY"),
-			IntegrationUri:       webapiLambda.Arn,
+			IntegrationUri:       webapiService.InvokeArn,
 			IntegrationMethod:    pulumi.String("POST"),
 			PayloadFormatVersion: pulumi.String("2.0"),
 		})
@@ -1254,12 +1402,12 @@ func main() {
 		}
 
 		// API Gateway Stage (auto-deploy)
-		_, err = apigatewayv2.NewStage(ctx, fmt.Sprintf("rez-agent-api-stage-%s", stage), &apigatewayv2.StageArgs{
+		apiStage, err := apigatewayv2.NewStage(ctx, fmt.Sprintf("rez-agent-api-stage-%s", stage), &apigatewayv2.StageArgs{
 			ApiId:      httpApi.ID(),
 			Name:       pulumi.String("$default"),
 			AutoDeploy: pulumi.Bool(true),
 			AccessLogSettings: &apigatewayv2.StageAccessLogSettingsArgs{
-				DestinationArn: webapiLogGroup.Arn,
+				DestinationArn: webapiService.LogGroup.Arn,
 				Format:         pulumi.String(`{"requestId":"$context.requestId","ip":"$context.identity.sourceIp","requestTime":"$context.requestTime","httpMethod":"$context.httpMethod","routeKey":"$context.routeKey","status":"$context.status","protocol":"$context.protocol","responseLength":"$context.responseLength"}`),
 			},
 			Tags: commonTags,
@@ -1268,22 +1416,16 @@ func main() {
 			return err
 		}
 
+		if apiDomain != nil {
+			if err := apiDomain.mapStage(ctx, stage, httpApi, apiStage); err != nil {
+				return err
+			}
+		}
+
 		// ========================================
 		// EventBridge Scheduler
 		// ========================================
 
-		// SQS Event Source Mapping for Scheduler Lambda (Schedule Creation Queue)
-		_, err = lambda.NewEventSourceMapping(ctx, fmt.Sprintf("rez-agent-scheduler-sqs-trigger-%s", stage), &lambda.EventSourceMappingArgs{
-			EventSourceArn: scheduleCreationQueue.Arn,
-			FunctionName:   schedulerLambda.Arn,
-			BatchSize:      pulumi.Int(10),
-			Enabled:        pulumi.Bool(true),
-			// No filter criteria needed - dedicated queue for schedule creation
-		}, pulumi.DependsOn([]pulumi.Resource{scheduleCreationQueuePolicy}))
-		if err != nil {
-			return err
-		}
-
 		// EventBridge Scheduler Role
 		schedulerExecutionRole, err := iam.NewRole(ctx, fmt.Sprintf("rez-agent-eventbridge-scheduler-role-%s", stage), &iam.RoleArgs{
 			Name: pulumi.String(fmt.Sprintf("rez-agent-eventbridge-scheduler-role-%s", stage)),
@@ -1304,7 +1446,7 @@ func main() {
 		// EventBridge Scheduler Policy
 		_, err = iam.NewRolePolicy(ctx, fmt.Sprintf("rez-agent-eventbridge-scheduler-policy-%s", stage), &iam.RolePolicyArgs{
 			Role: schedulerExecutionRole.Name,
-			Policy: schedulerLambda.Arn.ApplyT(func(arn string) string {
+			Policy: schedulerService.Function.Arn.ApplyT(func(arn string) string {
 				return fmt.Sprintf(`{
 					"Version": "2012-10-17",
 					"Statement": [{
@@ -1327,7 +1469,7 @@ func main() {
 				Mode: pulumi.String("OFF"),
 			},
 			Target: &scheduler.ScheduleTargetArgs{
-				Arn:     schedulerLambda.Arn,
+				Arn:     schedulerService.Function.Arn,
 				RoleArn: schedulerExecutionRole.Arn,
 				RetryPolicy: &scheduler.ScheduleTargetRetryPolicyArgs{
 					MaximumRetryAttempts:     pulumi.Int(3),
@@ -1345,126 +1487,62 @@ func main() {
 
 		log.Printf("Creating MCP Lambda function...")
 
-		// MCP Lambda Role
-		mcpRole, err := iam.NewRole(ctx, fmt.Sprintf("rez-agent-mcp-role-%s", stage), &iam.RoleArgs{
-			Name: pulumi.String(fmt.Sprintf("rez-agent-mcp-role-%s", stage)),
-			AssumeRolePolicy: pulumi.String(`{
-				"Version": "2012-10-17",
-				"Statement": [{
-					"Effect": "Allow",
-					"Principal": {"Service": "lambda.amazonaws.com"},
-					"Action": "sts:AssumeRole"
-				}]
-			}`),
-			Tags: commonTags,
-		})
-		if err != nil {
-			return err
-		}
-
 		// MCP Lambda Policy
-		_, err = iam.NewRolePolicy(ctx, fmt.Sprintf("rez-agent-mcp-policy-%s", stage), &iam.RolePolicyArgs{
-			Role: mcpRole.Name,
-			Policy: pulumi.All(messagesTable.Arn, notificationsTopic.Arn).ApplyT(func(args []interface{}) string {
-				tableArn := args[0].(string)
-				topicArn := args[1].(string)
-				return fmt.Sprintf(`{
-					"Version": "2012-10-17",
-					"Statement": [
-						{
-							"Effect": "Allow",
-							"Action": [
-								"dynamodb:GetItem",
-								"dynamodb:PutItem",
-								"dynamodb:UpdateItem"
-							],
-							"Resource": ["%s", "%s/*"]
-						},
-						{
-							"Effect": "Allow",
-							"Action": ["sns:Publish"],
-							"Resource": "%s"
-						},
-						{
-							"Effect": "Allow",
-							"Action": ["secretsmanager:GetSecretValue"],
-							"Resource": "arn:aws:secretsmanager:*:*:secret:rez-agent/*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"logs:CreateLogGroup",
-								"logs:CreateLogStream",
-								"logs:PutLogEvents"
-							],
-							"Resource": "arn:aws:logs:*:*:*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"xray:PutTraceSegments",
-								"xray:PutTelemetryRecords"
-							],
-							"Resource": "*"
-						}
-					]
-				}`, tableArn, tableArn, topicArn)
-			}).(pulumi.StringOutput),
-		})
-		if err != nil {
-			return err
-		}
-
-		// MCP Lambda Log Group
-		mcpLogGroup, err := cloudwatch.NewLogGroup(ctx, fmt.Sprintf("rez-agent-mcp-logs-%s", stage), &cloudwatch.LogGroupArgs{
-			Name:            pulumi.String(fmt.Sprintf("/aws/lambda/rez-agent-mcp-%s", stage)),
-			RetentionInDays: pulumi.Int(logRetentionDays),
-			Tags:            commonTags,
-		})
-		if err != nil {
-			return err
-		}
+		mcpPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:GetItem", "dynamodb:PutItem", "dynamodb:UpdateItem"}, messagesTable.Arn, tableIndexes(messagesTable)).
+			allow([]string{"dynamodb:DescribeTable"}, messagesTable.Arn).
+			allow([]string{"dynamodb:PutItem", "dynamodb:Scan"}, weatherDecisionsTable.Arn, preferencesTable.Arn).
+			allow([]string{"dynamodb:PutItem", "dynamodb:GetItem", "dynamodb:UpdateItem"}, approvalsTable.Arn).
+			allow([]string{"dynamodb:GetItem", "dynamodb:PutItem"}, oauthTokensTable.Arn).
+			allow([]string{"dynamodb:GetItem"}, reservationsTable.Arn).
+			allow([]string{"dynamodb:PutItem", "dynamodb:UpdateItem", "dynamodb:DeleteItem"}, bookingLedgerTable.Arn).
+			allow([]string{"dynamodb:PutItem"}, auditTable.Arn).
+			allow([]string{"dynamodb:GetItem"}, usersTable.Arn).
+			allow([]string{"sns:Publish"}, notifications.Topic.Arn).
+			allow([]string{"secretsmanager:GetSecretValue", "secretsmanager:DescribeSecret"}, scope.arn("secretsmanager", "secret:rez-agent/*"))
 
 		// MCP Lambda Function
-		mcpLambda, err := lambda.NewFunction(ctx, fmt.Sprintf("rez-agent-mcp-%s", stage), &lambda.FunctionArgs{
-			Name:    pulumi.String(fmt.Sprintf("rez-agent-mcp-%s", stage)),
-			Runtime: pulumi.String("provided.al2"),
-			Role:    mcpRole.Arn,
-			Handler: pulumi.String("bootstrap"),
-			Code:    pulumi.NewFileArchive("../build/mcp.zip"),
-			Environment: &lambda.FunctionEnvironmentArgs{
-				Variables: pulumi.StringMap{
-					"MCP_SERVER_NAME":            pulumi.String("rez-agent-mcp"),
-					"MCP_SERVER_VERSION":         pulumi.String("1.0.0"),
-					"DYNAMODB_TABLE_NAME":        messagesTable.Name,
-					"NOTIFICATIONS_TOPIC_ARN":    notificationsTopic.Arn,
-					"NOTIFICATION_SQS_QUEUE_URL": notificationsQueue.Url,
-					"NTFY_URL":                   pulumi.String(ntfyUrl),
-					"STAGE":                      pulumi.String(stage),
-					"GOLF_SECRET_NAME":           pulumi.String(fmt.Sprintf("rez-agent/golf/credentials-%s", stage)),
-					"WEATHER_API_KEY_SECRET":     pulumi.String(fmt.Sprintf("rez-agent/weather/api-key-%s", stage)),
-				},
-			},
-			MemorySize: pulumi.Int(512),
-			Timeout:    pulumi.Int(30),
-			TracingConfig: &lambda.FunctionTracingConfigArgs{
-				Mode: pulumi.String(map[bool]string{true: "Active", false: "PassThrough"}[enableXRay]),
+		mcpService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-mcp-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "mcp",
+			Code:         pulumi.NewFileArchive("../build/mcp.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       mcpPolicy,
+			Environment: pulumi.StringMap{
+				"MCP_SERVER_NAME":              pulumi.String("rez-agent-mcp"),
+				"MCP_SERVER_VERSION":           pulumi.String("1.0.0"),
+				"DYNAMODB_TABLE_NAME":          messagesTable.Name,
+				"NOTIFICATIONS_TOPIC_ARN":      notifications.Topic.Arn,
+				"NOTIFICATION_SQS_QUEUE_URL":   notifications.Queue.Url,
+				"NTFY_URL":                     pulumi.String(ntfyUrl),
+				"STAGE":                        pulumi.String(stage),
+				"GOLF_SECRET_NAME":             pulumi.String(fmt.Sprintf("rez-agent/golf/credentials-%s", stage)),
+				"WEATHER_API_KEY_SECRET":       pulumi.String(fmt.Sprintf("rez-agent/weather/api-key-%s", stage)),
+				"WEATHER_DECISIONS_TABLE_NAME": weatherDecisionsTable.Name,
+				"PREFERENCES_TABLE_NAME":       preferencesTable.Name,
+				"APPROVALS_TABLE_NAME":         approvalsTable.Name,
+				"APPROVAL_BASE_URL":            apiBaseUrl,
+				"OAUTH_TOKEN_TABLE_NAME":       oauthTokensTable.Name,
+				"RESERVATIONS_TABLE_NAME":      reservationsTable.Name,
+				"AUDIT_TABLE_NAME":             auditTable.Name,
+				"BOOKING_LEDGER_TABLE_NAME":    bookingLedgerTable.Name,
+				"USERS_TABLE_NAME":             webapiUsersTable,
+				"CORS_ALLOWED_ORIGINS":         pulumi.String(corsAllowedOrigins),
+				"CORS_ALLOW_CREDENTIALS":       pulumi.String(strconv.FormatBool(corsAllowCredentials)),
 			},
-			Tags: commonTags,
-		}, pulumi.DependsOn([]pulumi.Resource{mcpLogGroup}))
+			MemorySize:       512,
+			Timeout:          30,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["mcp"],
+			LogRetentionDays: logRetentionDays,
+			Tags:             commonTags,
+		})
 		if err != nil {
 			return err
 		}
 
 		// Lambda permission for API Gateway to invoke MCP
-		_, err = lambda.NewPermission(ctx, fmt.Sprintf("rez-agent-mcp-apigw-permission-%s", stage), &lambda.PermissionArgs{
-			Action:    pulumi.String("lambda:InvokeFunction"),
-			Function:  mcpLambda.Name,
-			Principal: pulumi.String("apigateway.amazonaws.com"),
-			SourceArn: httpApi.ExecutionArn.ApplyT(func(arn string) string {
-				return fmt.Sprintf("%s/*/*", arn)
-			}).(pulumi.StringOutput),
-		})
+		err = mcpService.AllowInvoke(ctx, "apigw-permission", "apigateway.amazonaws.com", apiInvokeArn)
 		if err != nil {
 			return err
 		}
@@ -1473,7 +1551,7 @@ func main() {
 		mcpApiIntegration, err := apigatewayv2.NewIntegration(ctx, fmt.Sprintf("rez-agent-mcp-api-integration-%s", stage), &apigatewayv2.IntegrationArgs{
 			ApiId:                httpApi.ID(),
 			IntegrationType:      pulumi.String("AWS_PROXY"),
-			IntegrationUri:       mcpLambda.Arn,
+			IntegrationUri:       mcpService.InvokeArn,
 			IntegrationMethod:    pulumi.String("POST"),
 			PayloadFormatVersion: pulumi.String("2.0"),
 		})
@@ -1493,263 +1571,426 @@ func main() {
 			return err
 		}
 
+		// Unauthenticated dependency health check for uptime monitors
+		_, err = apigatewayv2.NewRoute(ctx, fmt.Sprintf("rez-agent-mcp-health-route-%s", stage), &apigatewayv2.RouteArgs{
+			ApiId:    httpApi.ID(),
+			RouteKey: pulumi.String("GET /mcp/health"),
+			Target: mcpApiIntegration.ID().ApplyT(func(id string) string {
+				return fmt.Sprintf("integrations/%s", id)
+			}).(pulumi.StringOutput),
+		})
+		if err != nil {
+			return err
+		}
+
 		log.Printf("MCP Lambda function created successfully")
 
 		// ========================================
 		// AI Agent Infrastructure
 		// ========================================
 
-		// Agent Session DynamoDB Table
-		agentSessionTable, err := dynamodb.NewTable(ctx, fmt.Sprintf("rez-agent-sessions-%s", stage), &dynamodb.TableArgs{
-			Name:        pulumi.String(fmt.Sprintf("rez-agent-sessions-%s", stage)),
-			BillingMode: pulumi.String("PAY_PER_REQUEST"),
-			HashKey:     pulumi.String("session_id"),
-			Attributes: dynamodb.TableAttributeArray{
-				&dynamodb.TableAttributeArgs{
-					Name: pulumi.String("session_id"),
-					Type: pulumi.String("S"),
-				},
-			},
-			Ttl: &dynamodb.TableTtlArgs{
-				AttributeName: pulumi.String("ttl"),
-				Enabled:       pulumi.Bool(true),
+		// Agent Lambda, its session table and /agent routes
+		log.Printf("Creating agent Lambda function...")
+		agent, err := NewAgentComponent(ctx, fmt.Sprintf("rez-agent-agent-%s", stage), &AgentArgs{
+			Stage:             stage,
+			Architecture:      lambdaArchitecture,
+			MessagesTable:     messagesTable,
+			WebActions:        webActions,
+			Notifications:     notifications,
+			AgentResponses:    agentResponses,
+			HttpApi:           httpApi,
+			McpServerUrl:      mcpServerUrl,
+			SessionSigningKey: agentSessionSigningKey,
+			ModelID:           agentModelID,
+			CORSOrigins:       corsAllowedOrigins,
+			CORSCredentials:   corsAllowCredentials,
+			Scope:             scope,
+			TracingMode:       tracingMode,
+			Tuning:            lambdaOverrides["agent"],
+			QueueTuning:       queueOverrides["agent-responses"],
+			LogRetentionDays:  logRetentionDays,
+			Tags:              commonTags,
+		})
+		if err != nil {
+			return err
+		}
+		agentService := agent.Service
+
+		// ========================================
+		// Triage Lambda Function
+		// ========================================
+
+		// Dead-letter queues sampled by the triage Lambda, by name
+		triageQueues := pulumi.All(webActions.Dlq.Url, notifications.Dlq.Url, agentResponses.Dlq.Url, scheduleCreation.Dlq.Url).ApplyT(func(args []interface{}) (string, error) {
+			queues, err := json.Marshal(map[string]string{
+				"web-actions":       args[0].(string),
+				"notifications":     args[1].(string),
+				"agent-responses":   args[2].(string),
+				"schedule-creation": args[3].(string),
+			})
+			return string(queues), err
+		}).(pulumi.StringOutput)
+
+		// Lambda log groups searched for the sampled message IDs
+		triageLogGroups := pulumi.All(scheduleCreationService.LogGroup.Name, processorService.LogGroup.Name, webapiService.LogGroup.Name, webactionService.LogGroup.Name, mcpService.LogGroup.Name, agentService.LogGroup.Name).ApplyT(func(args []interface{}) string {
+			groups := make([]string, 0, len(args))
+			for _, arg := range args {
+				groups = append(groups, arg.(string))
+			}
+			return strings.Join(groups, ",")
+		}).(pulumi.StringOutput)
+
+		// Triage Lambda Policy: sample (but never delete) DLQ messages, read logs and records, write reports
+		triagePolicy := newIAMPolicy().
+			allow([]string{"sqs:GetQueueAttributes", "sqs:ReceiveMessage", "sqs:ChangeMessageVisibility"},
+				webActions.Dlq.Arn, notifications.Dlq.Arn, agentResponses.Dlq.Arn, scheduleCreation.Dlq.Arn).
+			allow([]string{"logs:FilterLogEvents"},
+				pulumi.Sprintf("%s:*", scheduleCreationService.LogGroup.Arn), pulumi.Sprintf("%s:*", processorService.LogGroup.Arn),
+				pulumi.Sprintf("%s:*", webapiService.LogGroup.Arn), pulumi.Sprintf("%s:*", webactionService.LogGroup.Arn),
+				pulumi.Sprintf("%s:*", mcpService.LogGroup.Arn), pulumi.Sprintf("%s:*", agentService.LogGroup.Arn)).
+			allow([]string{"dynamodb:GetItem"}, messagesTable.Arn).
+			allow([]string{"dynamodb:Query"}, webActionResultsTable.Arn, tableIndexes(webActionResultsTable)).
+			allow([]string{"s3:PutObject", "s3:GetObject"}, bucketObjects(agentLogsBucket, "triage/"))
+
+		// Invoked on demand: make triage STAGE=<stage>
+		triageService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-triage-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "triage",
+			Code:         pulumi.NewFileArchive("../build/triage.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       triagePolicy,
+			Environment: pulumi.StringMap{
+				"DYNAMODB_TABLE_NAME":           messagesTable.Name,
+				"WEB_ACTION_RESULTS_TABLE_NAME": webActionResultsTable.Name,
+				"NOTIFICATION_SQS_QUEUE_URL":    notifications.Queue.Url,
+				"NTFY_URL":                      pulumi.String(ntfyUrl),
+				"STAGE":                         pulumi.String(stage),
+				"TRIAGE_DLQ_URLS":               triageQueues,
+				"TRIAGE_LOG_GROUPS":             triageLogGroups,
+				"TRIAGE_REPORTS_BUCKET":         agentLogsBucket.ID(),
+				"DEPLOY_VERSION":                pulumi.String(deployVersion),
 			},
-			Tags: commonTags,
+			MemorySize:       256,
+			Timeout:          120,
+			Tuning:           lambdaOverrides["triage"],
+			LogRetentionDays: logRetentionDays,
+			Tags:             commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Agent Lambda Role
-		agentRole, err := iam.NewRole(ctx, fmt.Sprintf("rez-agent-agent-role-%s", stage), &iam.RoleArgs{
-			Name: pulumi.String(fmt.Sprintf("rez-agent-agent-role-%s", stage)),
-			AssumeRolePolicy: pulumi.String(`{
-				"Version": "2012-10-17",
-				"Statement": [{
-					"Effect": "Allow",
-					"Principal": {"Service": "lambda.amazonaws.com"},
-					"Action": "sts:AssumeRole"
-				}]
-			}`),
-			Tags: commonTags,
+		// ========================================
+		// Golf Credentials Rotation
+		// ========================================
+
+		// Secrets Manager invokes the rotation Lambda on schedule; it promotes the newest credentials
+		// only after they log in, so a bad password fails the rotation instead of a nightly booking
+		var rotationService *LambdaServiceComponent
+		if golfSecretRotationDays > 0 {
+			golfSecret, err := secretsmanager.LookupSecret(ctx, &secretsmanager.LookupSecretArgs{Name: pulumi.StringRef(golfSecretName)})
+			if err != nil {
+				return fmt.Errorf("failed to look up golf secret %s: %w", golfSecretName, err)
+			}
+
+			rotationPolicy := newIAMPolicy().
+				allow([]string{
+					"secretsmanager:DescribeSecret",
+					"secretsmanager:GetSecretValue",
+					"secretsmanager:PutSecretValue",
+					"secretsmanager:UpdateSecretVersionStage",
+				}, pulumi.String(golfSecret.Arn))
+
+			rotationService, err = NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-rotation-service-%s", stage), &LambdaServiceArgs{
+				Stage:        stage,
+				Name:         "rotation",
+				Code:         pulumi.NewFileArchive("../build/rotation.zip"),
+				Architecture: lambdaArchitecture,
+				Policy:       rotationPolicy,
+				Environment: pulumi.StringMap{
+					"DYNAMODB_TABLE_NAME":        messagesTable.Name,
+					"NOTIFICATION_SQS_QUEUE_URL": notifications.Queue.Url,
+					"GOLF_SECRET_NAME":           pulumi.String(golfSecretName),
+					"STAGE":                      pulumi.String(stage),
+				},
+				MemorySize:       128,
+				Timeout:          60,
+				Tuning:           lambdaOverrides["rotation"],
+				LogRetentionDays: logRetentionDays,
+				Tags:             commonTags,
+			})
+			if err != nil {
+				return err
+			}
+
+			// Parented to the service so depending on the service waits for the permission too
+			err = rotationService.AllowInvoke(ctx, "secretsmanager-permission", "secretsmanager.amazonaws.com", pulumi.String(golfSecret.Arn),
+				pulumi.Parent(rotationService))
+			if err != nil {
+				return err
+			}
+
+			_, err = secretsmanager.NewSecretRotation(ctx, fmt.Sprintf("rez-agent-golf-secret-rotation-%s", stage), &secretsmanager.SecretRotationArgs{
+				SecretId:          pulumi.String(golfSecret.Arn),
+				RotationLambdaArn: rotationService.Function.Arn,
+				RotationRules: &secretsmanager.SecretRotationRotationRulesArgs{
+					AutomaticallyAfterDays: pulumi.Int(golfSecretRotationDays),
+				},
+				// Rotating as soon as this is created would test a secret nobody has just changed
+				RotateImmediately: pulumi.Bool(false),
+			}, pulumi.DependsOn([]pulumi.Resource{rotationService}))
+			if err != nil {
+				return err
+			}
+		}
+
+		// ========================================
+		// Golf Reservation Sync
+		// ========================================
+
+		// Copies every golfer's upcoming reservations into the reservations table on a schedule, so
+		// the agent and the web API answer "what do I have booked" without calling the courses
+		reservationSyncPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:PutItem"}, reservationsTable.Arn, auditTable.Arn).
+			allow([]string{"dynamodb:Scan"}, usersTable.Arn).
+			allow([]string{"dynamodb:GetItem", "dynamodb:PutItem"}, oauthTokensTable.Arn).
+			allow([]string{"secretsmanager:GetSecretValue"}, scope.arn("secretsmanager", "secret:rez-agent/*"))
+
+		// The users table is only read in multi-user mode, like the web API
+		reservationSyncEnv := pulumi.StringMap{
+			"DYNAMODB_TABLE_NAME":     messagesTable.Name,
+			"RESERVATIONS_TABLE_NAME": reservationsTable.Name,
+			"OAUTH_TOKEN_TABLE_NAME":  oauthTokensTable.Name,
+			"AUDIT_TABLE_NAME":        auditTable.Name,
+			"STAGE":                   pulumi.String(stage),
+		}
+		if multiUser {
+			reservationSyncEnv["USERS_TABLE_NAME"] = usersTable.Name
+		}
+
+		reservationSyncService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-reservationsync-service-%s", stage), &LambdaServiceArgs{
+			Stage:            stage,
+			Name:             "reservationsync",
+			Code:             pulumi.NewFileArchive("../build/reservationsync.zip"),
+			Architecture:     lambdaArchitecture,
+			Policy:           reservationSyncPolicy,
+			Environment:      reservationSyncEnv,
+			MemorySize:       256,
+			Timeout:          120,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["reservationsync"],
+			LogRetentionDays: logRetentionDays,
+			Tags:             commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Agent Lambda Policy
-		_, err = iam.NewRolePolicy(ctx, fmt.Sprintf("rez-agent-agent-policy-%s", stage), &iam.RolePolicyArgs{
-			Role: agentRole.Name,
-			Policy: pulumi.All(
-				agentSessionTable.Arn,
-				messagesTable.Arn,
-				webActionsTopic.Arn,
-				notificationsTopic.Arn,
-				agentResponseTopic.Arn,
-				agentResponseQueue.Arn,
-			).ApplyT(func(args []interface{}) string {
-				sessionTableArn := args[0].(string)
-				messagesTableArn := args[1].(string)
-				webActionsTopicArn := args[2].(string)
-				notificationsTopicArn := args[3].(string)
-				agentResponseTopicArn := args[4].(string)
-				agentResponseQueueArn := args[5].(string)
-				return fmt.Sprintf(`{
-					"Version": "2012-10-17",
-					"Statement": [
-						{
-							"Effect": "Allow",
-							"Action": [
-								"dynamodb:GetItem",
-								"dynamodb:PutItem",
-								"dynamodb:UpdateItem",
-								"dynamodb:Query"
-							],
-							"Resource": ["%s", "%s", "%s/*"]
-						},
-						{
-							"Effect": "Allow",
-							"Action": ["sns:Publish"],
-							"Resource": ["%s", "%s", "%s"]
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"sqs:ReceiveMessage",
-								"sqs:DeleteMessage",
-								"sqs:GetQueueAttributes"
-							],
-							"Resource": "%s"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"bedrock:InvokeModel",
-								"bedrock:InvokeModelWithResponseStream"
-							],
-							"Resource": "*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"logs:CreateLogGroup",
-								"logs:CreateLogStream",
-								"logs:PutLogEvents"
-							],
-							"Resource": "arn:aws:logs:*:*:*"
-						},
-						{
-							"Effect": "Allow",
-							"Action": [
-								"xray:PutTraceSegments",
-								"xray:PutTelemetryRecords"
-							],
-							"Resource": "*"
-						}
-					]
-				}`, sessionTableArn, messagesTableArn, messagesTableArn,
-					webActionsTopicArn, notificationsTopicArn, agentResponseTopicArn,
-					agentResponseQueueArn)
-			}).(pulumi.StringOutput),
+		reservationSyncRule, err := cloudwatch.NewEventRule(ctx, fmt.Sprintf("rez-agent-reservationsync-%s", stage), &cloudwatch.EventRuleArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-reservationsync-%s", stage)),
+			Description:        pulumi.String("Syncs golf reservations into the reservations table"),
+			ScheduleExpression: pulumi.String(reservationSyncSchedule),
+			Tags:               commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Agent Lambda Log Group
-		agentLogGroup, err := cloudwatch.NewLogGroup(ctx, fmt.Sprintf("rez-agent-agent-logs-%s", stage), &cloudwatch.LogGroupArgs{
-			Name:            pulumi.String(fmt.Sprintf("/aws/lambda/rez-agent-agent-%s", stage)),
-			RetentionInDays: pulumi.Int(logRetentionDays),
-			Tags:            commonTags,
+		_, err = cloudwatch.NewEventTarget(ctx, fmt.Sprintf("rez-agent-reservationsync-%s", stage), &cloudwatch.EventTargetArgs{
+			Rule: reservationSyncRule.Name,
+			Arn:  reservationSyncService.InvokeArn,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Agent Lambda Function (using S3 for large package)
-		log.Printf("Creating agent Lambda function from S3...")
-		agentLambda, err := lambda.NewFunction(ctx, fmt.Sprintf("rez-agent-agent-%s", stage), &lambda.FunctionArgs{
-			Name:           pulumi.String(fmt.Sprintf("rez-agent-agent-%s", stage)),
-			Runtime:        pulumi.String("python3.12"),
-			Role:           agentRole.Arn,
-			Handler:        pulumi.String("main.lambda_handler"),
-			S3Bucket:       lambdaDeploymentBucket.ID(),
-			S3Key:          agentZipObject.Key,
-			SourceCodeHash: agentZipObject.Etag, // Use ETag to detect file changes (works without versioning)
-			Environment: &lambda.FunctionEnvironmentArgs{
-				Variables: pulumi.StringMap{
-					"DYNAMODB_TABLE_NAME":      messagesTable.Name,
-					"AGENT_SESSION_TABLE_NAME": agentSessionTable.Name,
-					"WEB_ACTIONS_TOPIC_ARN":    webActionsTopic.Arn,
-					"NOTIFICATIONS_TOPIC_ARN":  notificationsTopic.Arn,
-					"AGENT_RESPONSE_TOPIC_ARN": agentResponseTopic.Arn,
-					"AGENT_RESPONSE_QUEUE_URL": agentResponseQueue.Url,
-					"STAGE":                    pulumi.String(stage),
-					// MCP Server Configuration
-					"MCP_SERVER_URL": httpApi.ApiEndpoint.ApplyT(func(endpoint string) string {
-						return fmt.Sprintf("%s/mcp", endpoint)
-					}).(pulumi.StringOutput),
-					// Note: MCP_API_KEY should be set via AWS Parameter Store or Secrets Manager
-					// For now, omitting it (MCP Lambda will allow unauthenticated requests for internal use)
-					// Bedrock LLM Configuration
-					"BEDROCK_MODEL_ID":    pulumi.String("us.anthropic.claude-sonnet-4-20250514-v1:0"),
-					"BEDROCK_PROVIDER":    pulumi.String("anthropic"),
-					"BEDROCK_REGION":      pulumi.String("us-east-1"),
-					"BEDROCK_TEMPERATURE": pulumi.String("0.5"),
-					"BEDROCK_MAX_TOKENS":  pulumi.String("4096"),
-				},
-			},
-			MemorySize: pulumi.Int(1024),
-			Timeout:    pulumi.Int(300),
-			TracingConfig: &lambda.FunctionTracingConfigArgs{
-				Mode: pulumi.String(map[bool]string{true: "Active", false: "PassThrough"}[enableXRay]),
-			},
-			Tags: commonTags,
-		}, pulumi.DependsOn([]pulumi.Resource{agentLogGroup, agentZipObject}))
+		err = reservationSyncService.AllowInvoke(ctx, "events-permission", "events.amazonaws.com", reservationSyncRule.Arn)
 		if err != nil {
 			return err
 		}
 
-		// SQS Event Source Mapping for Agent Lambda (Agent Response Queue)
-		_, err = lambda.NewEventSourceMapping(ctx, fmt.Sprintf("rez-agent-agent-sqs-trigger-%s", stage), &lambda.EventSourceMappingArgs{
-			EventSourceArn: agentResponseQueue.Arn,
-			FunctionName:   agentLambda.Arn,
-			BatchSize:      pulumi.Int(10),
-			Enabled:        pulumi.Bool(true),
-			// No filter criteria needed - dedicated queue for notifications
-		}, pulumi.DependsOn([]pulumi.Resource{agentResponseQueuePolicy}))
+		// ========================================
+		// Booking Canary
+		// ========================================
+
+		// Signs in, searches, locks and prices a tee time at every course without reserving it, so
+		// a broken provider API alarms before the morning booking run
+		canaryPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:PutItem"}, auditTable.Arn).
+			allow([]string{"dynamodb:GetItem", "dynamodb:PutItem"}, oauthTokensTable.Arn).
+			allow([]string{"secretsmanager:GetSecretValue"}, scope.arn("secretsmanager", "secret:rez-agent/*"))
+
+		canaryService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-canary-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "canary",
+			Code:         pulumi.NewFileArchive("../build/canary.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       canaryPolicy,
+			Environment: pulumi.StringMap{
+				"DYNAMODB_TABLE_NAME":    messagesTable.Name,
+				"OAUTH_TOKEN_TABLE_NAME": oauthTokensTable.Name,
+				"AUDIT_TABLE_NAME":       auditTable.Name,
+				"STAGE":                  pulumi.String(stage),
+			},
+			MemorySize:       128,
+			Timeout:          120,
+			TracingMode:      tracingMode,
+			Tuning:           lambdaOverrides["canary"],
+			LogRetentionDays: logRetentionDays,
+			Tags:             commonTags,
+		})
 		if err != nil {
 			return err
 		}
 
-		// Lambda permission for API Gateway to invoke Agent
-		_, err = lambda.NewPermission(ctx, fmt.Sprintf("rez-agent-agent-apigw-permission-%s", stage), &lambda.PermissionArgs{
-			Action:    pulumi.String("lambda:InvokeFunction"),
-			Function:  agentLambda.Name,
-			Principal: pulumi.String("apigateway.amazonaws.com"),
-			SourceArn: httpApi.ExecutionArn.ApplyT(func(arn string) string {
-				return fmt.Sprintf("%s/*/*", arn)
-			}).(pulumi.StringOutput),
+		canaryRule, err := cloudwatch.NewEventRule(ctx, fmt.Sprintf("rez-agent-canary-%s", stage), &cloudwatch.EventRuleArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-canary-%s", stage)),
+			Description:        pulumi.String("Exercises the golf booking pipeline without reserving"),
+			ScheduleExpression: pulumi.String(canarySchedule),
+			Tags:               commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// API Gateway Integration for Agent
-		agentApiIntegration, err := apigatewayv2.NewIntegration(ctx, fmt.Sprintf("rez-agent-agent-api-integration-%s", stage), &apigatewayv2.IntegrationArgs{
-			ApiId:                httpApi.ID(),
-			IntegrationType:      pulumi.String("AWS_PROXY"),
-			IntegrationUri:       agentLambda.Arn,
-			IntegrationMethod:    pulumi.String("POST"),
-			PayloadFormatVersion: pulumi.String("2.0"),
+		// No retries: a retried run would lock more tee times, and failures alarm through metrics
+		_, err = cloudwatch.NewEventTarget(ctx, fmt.Sprintf("rez-agent-canary-%s", stage), &cloudwatch.EventTargetArgs{
+			Rule: canaryRule.Name,
+			Arn:  canaryService.InvokeArn,
+			RetryPolicy: &cloudwatch.EventTargetRetryPolicyArgs{
+				MaximumRetryAttempts: pulumi.Int(0),
+			},
 		})
 		if err != nil {
 			return err
 		}
 
-		// API Gateway Route for Agent (POST for chat)
-		_, err = apigatewayv2.NewRoute(ctx, fmt.Sprintf("rez-agent-agent-api-route-%s", stage), &apigatewayv2.RouteArgs{
-			ApiId:    httpApi.ID(),
-			RouteKey: pulumi.String("POST /agent"),
-			Target: agentApiIntegration.ID().ApplyT(func(id string) string {
-				return fmt.Sprintf("integrations/%s", id)
-			}).(pulumi.StringOutput),
-		})
+		err = canaryService.AllowInvoke(ctx, "events-permission", "events.amazonaws.com", canaryRule.Arn)
 		if err != nil {
 			return err
 		}
 
-		// API Gateway Route for Agent Card (GET for A2A discovery)
-		_, err = apigatewayv2.NewRoute(ctx, fmt.Sprintf("rez-agent-agent-card-route-%s", stage), &apigatewayv2.RouteArgs{
-			ApiId:    httpApi.ID(),
-			RouteKey: pulumi.String("GET /agent/card"),
-			Target: agentApiIntegration.ID().ApplyT(func(id string) string {
-				return fmt.Sprintf("integrations/%s", id)
-			}).(pulumi.StringOutput),
+		// ========================================
+		// Weekly Digest
+		// ========================================
+
+		// Emails a summary of the week's messages, bookings, agent costs, and upcoming tee times
+		// when a weekly_digest schedule fires. SES sends only from a verified identity; creating it
+		// emails a verification link to the sender address.
+		var digestService *LambdaServiceComponent
+		if digestEnabled {
+			digestIdentity, err := sesv2.NewEmailIdentity(ctx, fmt.Sprintf("rez-agent-digest-sender-%s", stage), &sesv2.EmailIdentityArgs{
+				EmailIdentity: pulumi.String(digestEmailFrom),
+				Tags:          commonTags,
+			})
+			if err != nil {
+				return err
+			}
+
+			digestPolicy := newIAMPolicy().
+				allow([]string{"dynamodb:Query", "dynamodb:Scan"}, messagesTable.Arn, tableIndexes(messagesTable)).
+				allow([]string{"dynamodb:GetItem", "dynamodb:PutItem"}, schedulesTable.Arn).
+				allow([]string{"dynamodb:Query"}, reservationsTable.Arn).
+				allow([]string{"s3:ListBucket"}, agentLogsBucket.Arn).
+				allow([]string{"s3:GetObject"}, bucketObjects(agentLogsBucket, "recordings/")).
+				allow(sqsConsumerActions, digestChannel.Queue.Arn).
+				allow([]string{"ses:SendEmail"}, digestIdentity.Arn).
+				allow([]string{"bedrock:InvokeModel"}, scope.bedrockModelArns(scope.region, schedulerModelID)...)
+			if messaging.EventBus != nil {
+				digestPolicy.allow([]string{"events:PutEvents"}, messaging.EventBus.Arn)
+			}
+
+			digestService, err = NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-digest-service-%s", stage), &LambdaServiceArgs{
+				Stage:        stage,
+				Name:         "digest",
+				Code:         pulumi.NewFileArchive("../build/digest.zip"),
+				Architecture: lambdaArchitecture,
+				Policy:       digestPolicy,
+				Environment: pulumi.StringMap{
+					"DYNAMODB_TABLE_NAME":     messagesTable.Name,
+					"SCHEDULES_TABLE_NAME":    schedulesTable.Name,
+					"RESERVATIONS_TABLE_NAME": reservationsTable.Name,
+					"AGENT_LOGS_BUCKET":       agentLogsBucket.ID(),
+					"DIGEST_SQS_QUEUE_URL":    digestChannel.Queue.Url,
+					"EMAIL_FROM_ADDRESS":      pulumi.String(digestEmailFrom),
+					"DIGEST_RECIPIENTS":       pulumi.String(digestRecipients),
+					"BEDROCK_MODEL_ID":        pulumi.String(schedulerModelID),
+					"EVENT_BUS_NAME":          eventBusName,
+					"STAGE":                   pulumi.String(stage),
+				},
+				MemorySize:       256,
+				Timeout:          120,
+				TracingMode:      tracingMode,
+				Tuning:           lambdaOverrides["digest"],
+				LogRetentionDays: logRetentionDays,
+				Trigger: &SQSTriggerArgs{
+					Queue:                   digestChannel.Queue,
+					BatchSize:               1,
+					ReportBatchItemFailures: true,
+					DependsOn:               []pulumi.Resource{digestChannel.QueuePolicy},
+					Tuning:                  queueOverrides["digest"],
+				},
+				Tags: commonTags,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// ========================================
+		// Alarm Notifications
+		// ========================================
+
+		// DLQ depth alarms also notify this topic; its Lambda sends a push notification through the pipeline
+		dlqAlarmsTopic, err := sns.NewTopic(ctx, fmt.Sprintf("rez-agent-dlq-alarms-%s", stage), &sns.TopicArgs{
+			Name: pulumi.String(fmt.Sprintf("rez-agent-dlq-alarms-%s", stage)),
+			Tags: commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// API Gateway Route for Agent Card (well-known path for A2A discovery)
-		_, err = apigatewayv2.NewRoute(ctx, fmt.Sprintf("rez-agent-agent-wellknown-route-%s", stage), &apigatewayv2.RouteArgs{
-			ApiId:    httpApi.ID(),
-			RouteKey: pulumi.String("GET /agent/.well-known/agent-card"),
-			Target: agentApiIntegration.ID().ApplyT(func(id string) string {
-				return fmt.Sprintf("integrations/%s", id)
-			}).(pulumi.StringOutput),
+		// Alarms Lambda Policy: save notification messages and publish them to the notifications topic
+		alarmsPolicy := newIAMPolicy().
+			allow([]string{"dynamodb:PutItem", "dynamodb:UpdateItem"}, messagesTable.Arn).
+			allow([]string{"sns:Publish"}, notifications.Topic.Arn)
+		if messaging.EventBus != nil {
+			alarmsPolicy.allow([]string{"events:PutEvents"}, messaging.EventBus.Arn)
+		}
+
+		alarmsService, err := NewLambdaServiceComponent(ctx, fmt.Sprintf("rez-agent-alarms-service-%s", stage), &LambdaServiceArgs{
+			Stage:        stage,
+			Name:         "alarms",
+			Code:         pulumi.NewFileArchive("../build/alarms.zip"),
+			Architecture: lambdaArchitecture,
+			Policy:       alarmsPolicy,
+			Environment: pulumi.StringMap{
+				"DYNAMODB_TABLE_NAME":     messagesTable.Name,
+				"NOTIFICATIONS_TOPIC_ARN": notifications.Topic.Arn,
+				"NTFY_URL":                pulumi.String(ntfyUrl),
+				"EVENT_BUS_NAME":          eventBusName,
+				"OUTBOX_RELAY":            pulumi.String("true"),
+				"STAGE":                   pulumi.String(stage),
+			},
+			MemorySize:       128,
+			Timeout:          30,
+			Tuning:           lambdaOverrides["alarms"],
+			LogRetentionDays: logRetentionDays,
+			Tags:             commonTags,
 		})
 		if err != nil {
 			return err
 		}
 
-		// API Gateway Route for Agent UI Interface
-		_, err = apigatewayv2.NewRoute(ctx, fmt.Sprintf("rez-agent-agent-ui-route-%s", stage), &apigatewayv2.RouteArgs{
-			ApiId:    httpApi.ID(),
-			RouteKey: pulumi.String("GET /agent/ui"),
-			Target: agentApiIntegration.ID().ApplyT(func(id string) string {
-				return fmt.Sprintf("integrations/%s", id)
-			}).(pulumi.StringOutput),
+		err = alarmsService.AllowInvoke(ctx, "sns-permission", "sns.amazonaws.com", dlqAlarmsTopic.Arn)
+		if err != nil {
+			return err
+		}
+
+		_, err = sns.NewTopicSubscription(ctx, fmt.Sprintf("rez-agent-dlq-alarms-subscription-%s", stage), &sns.TopicSubscriptionArgs{
+			Topic:    dlqAlarmsTopic.Arn,
+			Protocol: pulumi.String("lambda"),
+			Endpoint: alarmsService.Function.Arn,
 		})
 		if err != nil {
 			return err
@@ -1759,21 +2000,183 @@ func main() {
 		// CloudWatch Alarms
 		// ========================================
 
-		// Processor Lambda Error Alarm
-		_, err = cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("rez-agent-processor-errors-%s", stage), &cloudwatch.MetricAlarmArgs{
-			Name:               pulumi.String(fmt.Sprintf("rez-agent-processor-errors-%s", stage)),
+		alertsTopic, err := newAlertsTopic(ctx, stage, alertEmail, alertNtfyUrl, commonTags)
+		if err != nil {
+			return err
+		}
+
+		monitoredFunctions := []monitoredFunction{
+			{"scheduler", schedulerService.Function.Name},
+			{"schedulecreation", scheduleCreationService.Function.Name},
+			{"processor", processorService.Function.Name},
+			{"webapi", webapiService.Function.Name},
+			{"webaction", webactionService.Function.Name},
+			{"mcp", mcpService.Function.Name},
+			{"agent", agentService.Function.Name},
+			{"triage", triageService.Function.Name},
+			{"alarms", alarmsService.Function.Name},
+			{"reservationsync", reservationSyncService.Function.Name},
+			{"canary", canaryService.Function.Name},
+			{"outbox", outboxService.Function.Name},
+			{"sweeper", sweeperService.Function.Name},
+			{"reconciler", reconcilerService.Function.Name},
+		}
+		if rotationService != nil {
+			monitoredFunctions = append(monitoredFunctions, monitoredFunction{"rotation", rotationService.Function.Name})
+		}
+		if digestService != nil {
+			monitoredFunctions = append(monitoredFunctions, monitoredFunction{"digest", digestService.Function.Name})
+		}
+		monitoredQueues := []monitoredQueue{
+			{"web-actions", webActions.Queue.Name, webActions.Dlq.Name, queueOverrides["web-actions"].AgeAlarmSeconds},
+			{"notifications", notifications.Queue.Name, notifications.Dlq.Name, queueOverrides["notifications"].AgeAlarmSeconds},
+			{"agent-responses", agentResponses.Queue.Name, agentResponses.Dlq.Name, queueOverrides["agent-responses"].AgeAlarmSeconds},
+			{"schedule-creation", scheduleCreation.Queue.Name, scheduleCreation.Dlq.Name, queueOverrides["schedule-creation"].AgeAlarmSeconds},
+		}
+		if digestChannel != nil {
+			monitoredQueues = append(monitoredQueues, monitoredQueue{"digest", digestChannel.Queue.Name, digestChannel.Dlq.Name, queueOverrides["digest"].AgeAlarmSeconds})
+		}
+
+		// Per-Lambda error and throttle alarms, queue backlog and DLQ depth alarms, and API 5xx alarm
+		if err := newAlarmSuite(ctx, stage, monitoredFunctions, monitoredQueues, httpApi.ID().ToStringOutput(), alertsTopic, dlqAlarmsTopic, commonTags); err != nil {
+			return err
+		}
+
+		if err := newDashboard(ctx, stage, monitoredFunctions, monitoredQueues, httpApi.ID().ToStringOutput()); err != nil {
+			return err
+		}
+
+		// End-to-end latency SLO burn-rate alarms. Lambdas emit SLOEvents/SLOGoodEvents per message
+		// (internal/metrics); objectives mirror metrics.DefaultSLOs. Each SLO gets a fast window
+		// (1h, 14.4x burn) and a slow window (6h, 6x burn) over a 30-day error budget.
+		latencySLOs := []struct {
+			messageType string
+			objective   float64
+		}{
+			{"notify", 0.95},
+			{"hello_world", 0.95},
+			{"web_action", 0.95},
+			{"schedule_creation", 0.95},
+		}
+		burnWindows := []struct {
+			name      string
+			period    int
+			threshold float64
+		}{
+			{"fast", 3600, 14.4},
+			{"slow", 21600, 6},
+		}
+		for _, slo := range latencySLOs {
+			for _, window := range burnWindows {
+				alarmName := fmt.Sprintf("rez-agent-slo-%s-%s-burn-%s", strings.ReplaceAll(slo.messageType, "_", "-"), window.name, stage)
+				sloMetric := func(id, metricName string) *cloudwatch.MetricAlarmMetricQueryArgs {
+					return &cloudwatch.MetricAlarmMetricQueryArgs{
+						Id: pulumi.String(id),
+						Metric: &cloudwatch.MetricAlarmMetricQueryMetricArgs{
+							MetricName: pulumi.String(metricName),
+							Namespace:  pulumi.String("RezAgent/Pipeline"),
+							Period:     pulumi.Int(window.period),
+							Stat:       pulumi.String("Sum"),
+							Dimensions: pulumi.StringMap{
+								"Stage":       pulumi.String(stage),
+								"MessageType": pulumi.String(slo.messageType),
+							},
+						},
+						ReturnData: pulumi.Bool(false),
+					}
+				}
+				_, err = cloudwatch.NewMetricAlarm(ctx, alarmName, &cloudwatch.MetricAlarmArgs{
+					Name:               pulumi.String(alarmName),
+					ComparisonOperator: pulumi.String("GreaterThanThreshold"),
+					EvaluationPeriods:  pulumi.Int(1),
+					Threshold:          pulumi.Float64(window.threshold),
+					TreatMissingData:   pulumi.String("notBreaching"),
+					AlarmDescription: pulumi.String(fmt.Sprintf("%s messages are burning their latency error budget %.1fx too fast (%s window)",
+						slo.messageType, window.threshold, window.name)),
+					MetricQueries: cloudwatch.MetricAlarmMetricQueryArray{
+						sloMetric("events", "SLOEvents"),
+						sloMetric("good", "SLOGoodEvents"),
+						&cloudwatch.MetricAlarmMetricQueryArgs{
+							Id:         pulumi.String("burn"),
+							Expression: pulumi.String(fmt.Sprintf("IF(events > 0, (1 - good / events) / %g, 0)", 1-slo.objective)),
+							Label:      pulumi.String("Burn rate"),
+							ReturnData: pulumi.Bool(true),
+						},
+					},
+					AlarmActions: pulumi.Array{alertsTopic.Arn},
+					Tags:         commonTags,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Booking canary: any course failing a step (internal/metrics CanaryFailures) means the
+		// provider's API contract may have broken before the morning booking run
+		_, err = cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("rez-agent-canary-failed-%s", stage), &cloudwatch.MetricAlarmArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-canary-failed-%s", stage)),
 			ComparisonOperator: pulumi.String("GreaterThanThreshold"),
-			EvaluationPeriods:  pulumi.Int(2),
-			MetricName:         pulumi.String("Errors"),
-			Namespace:          pulumi.String("AWS/Lambda"),
-			Period:             pulumi.Int(300),
+			EvaluationPeriods:  pulumi.Int(1),
+			MetricName:         pulumi.String("CanaryFailures"),
+			Namespace:          pulumi.String("RezAgent/Canary"),
+			Period:             pulumi.Int(3600),
 			Statistic:          pulumi.String("Sum"),
-			Threshold:          pulumi.Float64(5),
-			AlarmDescription:   pulumi.String("Alert when processor Lambda has errors"),
+			Threshold:          pulumi.Float64(0),
+			TreatMissingData:   pulumi.String("notBreaching"),
 			Dimensions: pulumi.StringMap{
-				"FunctionName": processorLambda.Name,
+				"Stage": pulumi.String(stage),
 			},
-			Tags: commonTags,
+			AlarmDescription: pulumi.String("The booking canary failed to sign in, search, lock or price a tee time; check the canary logs before the booking run"),
+			AlarmActions:     pulumi.Array{alertsTopic.Arn},
+			Tags:             commonTags,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Stuck messages: the sweeper failed a message it could not re-drive (internal/metrics
+		// StuckMessagesFailed), so a request was lost or a booking needs checking
+		_, err = cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("rez-agent-stuck-messages-failed-%s", stage), &cloudwatch.MetricAlarmArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-stuck-messages-failed-%s", stage)),
+			ComparisonOperator: pulumi.String("GreaterThanThreshold"),
+			EvaluationPeriods:  pulumi.Int(1),
+			MetricName:         pulumi.String("StuckMessagesFailed"),
+			Namespace:          pulumi.String("RezAgent/Pipeline"),
+			Period:             pulumi.Int(3600),
+			Statistic:          pulumi.String("Sum"),
+			Threshold:          pulumi.Float64(0),
+			TreatMissingData:   pulumi.String("notBreaching"),
+			Dimensions: pulumi.StringMap{
+				"Stage": pulumi.String(stage),
+			},
+			AlarmDescription: pulumi.String("The stuck message sweeper failed messages it could not re-drive; check their errors and retry them"),
+			AlarmActions:     pulumi.Array{alertsTopic.Arn},
+			Tags:             commonTags,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Schedule discrepancies: EventBridge and the schedules table disagree and the reconciler did
+		// not repair it (internal/metrics ScheduleDiscrepanciesOpen), so a schedule may not fire, or
+		// fire without one
+		_, err = cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("rez-agent-schedule-discrepancies-%s", stage), &cloudwatch.MetricAlarmArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-schedule-discrepancies-%s", stage)),
+			ComparisonOperator: pulumi.String("GreaterThanThreshold"),
+			EvaluationPeriods:  pulumi.Int(1),
+			MetricName:         pulumi.String("ScheduleDiscrepanciesOpen"),
+			Namespace:          pulumi.String("RezAgent/Pipeline"),
+			Period:             pulumi.Int(3600),
+			Statistic:          pulumi.String("Maximum"),
+			Threshold:          pulumi.Float64(0),
+			TreatMissingData:   pulumi.String("notBreaching"),
+			Dimensions: pulumi.StringMap{
+				"Stage": pulumi.String(stage),
+			},
+			AlarmDescription: pulumi.String("The schedules table and EventBridge disagree; check the reconciler logs or POST /api/admin/reconcile-schedules?repair=true"),
+			AlarmActions:     pulumi.Array{alertsTopic.Arn},
+			Tags:             commonTags,
 		})
 		if err != nil {
 			return err
@@ -1790,50 +2193,74 @@ func main() {
 		ctx.Export("dynamodbTableArn", messagesTable.Arn)
 
 		// SNS Topics
-		ctx.Export("webActionsTopicArn", webActionsTopic.Arn)
-		ctx.Export("notificationsTopicArn", notificationsTopic.Arn)
+		ctx.Export("webActionsTopicArn", webActions.Topic.Arn)
+		ctx.Export("notificationsTopicArn", notifications.Topic.Arn)
 
 		// SQS Queues
-		ctx.Export("webActionsQueueUrl", webActionsQueue.Url)
-		ctx.Export("webActionsQueueArn", webActionsQueue.Arn)
-		ctx.Export("notificationsQueueUrl", notificationsQueue.Url)
-		ctx.Export("notificationsQueueArn", notificationsQueue.Arn)
+		ctx.Export("webActionsQueueUrl", webActions.Queue.Url)
+		ctx.Export("webActionsQueueArn", webActions.Queue.Arn)
+		ctx.Export("notificationsQueueUrl", notifications.Queue.Url)
+		ctx.Export("notificationsQueueArn", notifications.Queue.Arn)
 
 		// Dead Letter Queues
-		ctx.Export("webActionsDlqUrl", webActionsDlq.Url)
-		ctx.Export("webActionsDlqArn", webActionsDlq.Arn)
-		ctx.Export("notificationsDlqUrl", notificationsDlq.Url)
-		ctx.Export("notificationsDlqArn", notificationsDlq.Arn)
+		ctx.Export("webActionsDlqUrl", webActions.Dlq.Url)
+		ctx.Export("webActionsDlqArn", webActions.Dlq.Arn)
+		ctx.Export("notificationsDlqUrl", notifications.Dlq.Url)
+		ctx.Export("notificationsDlqArn", notifications.Dlq.Arn)
 
 		// Lambda Functions
-		ctx.Export("schedulerLambdaArn", schedulerLambda.Arn)
-		ctx.Export("processorLambdaArn", processorLambda.Arn)
-		ctx.Export("webactionLambdaArn", webactionLambda.Arn)
-		ctx.Export("webapiLambdaArn", webapiLambda.Arn)
-		ctx.Export("agentLambdaArn", agentLambda.Arn)
-		ctx.Export("mcpLambdaArn", mcpLambda.Arn)
+		ctx.Export("schedulerLambdaArn", schedulerService.Function.Arn)
+		ctx.Export("scheduleCreationLambdaArn", scheduleCreationService.Function.Arn)
+		ctx.Export("processorLambdaArn", processorService.Function.Arn)
+		ctx.Export("webactionLambdaArn", webactionService.Function.Arn)
+		ctx.Export("webapiLambdaArn", webapiService.Function.Arn)
+		ctx.Export("outboxLambdaArn", outboxService.Function.Arn)
+		ctx.Export("sweeperLambdaArn", sweeperService.Function.Arn)
+		ctx.Export("reconcilerLambdaArn", reconcilerService.Function.Arn)
+		ctx.Export("agentLambdaArn", agentService.Function.Arn)
+		ctx.Export("mcpLambdaArn", mcpService.Function.Arn)
 
 		// Agent Infrastructure
-		ctx.Export("agentResponseTopicArn", agentResponseTopic.Arn)
-		ctx.Export("agentResponseQueueUrl", agentResponseQueue.Url)
-		ctx.Export("agentResponseQueueArn", agentResponseQueue.Arn)
-		ctx.Export("agentSessionTableName", agentSessionTable.Name)
-		ctx.Export("agentSessionTableArn", agentSessionTable.Arn)
+		ctx.Export("agentResponseTopicArn", agentResponses.Topic.Arn)
+		ctx.Export("agentResponseQueueUrl", agentResponses.Queue.Url)
+		ctx.Export("agentResponseQueueArn", agentResponses.Queue.Arn)
+		ctx.Export("agentSessionTableName", agent.SessionTable.Name)
+		ctx.Export("agentSessionTableArn", agent.SessionTable.Arn)
+		ctx.Export("agentStreamUrl", agent.StreamURL.FunctionUrl)
 
 		// S3 Buckets
 		ctx.Export("lambdaDeploymentBucket", lambdaDeploymentBucket.ID())
 		ctx.Export("agentLogsBucket", agentLogsBucket.ID())
+		ctx.Export("accessLogsBucket", accessLogsBucket.ID())
+		ctx.Export("triageLambdaName", triageService.Function.Name)
+
+		// Monitoring
+		ctx.Export("alertsTopicArn", alertsTopic.Arn)
+		ctx.Export("dlqAlarmsTopicArn", dlqAlarmsTopic.Arn)
+		ctx.Export("dashboardName", pulumi.String(fmt.Sprintf("rez-agent-%s", stage)))
 
 		// API Gateway
 		ctx.Export("apiGatewayId", httpApi.ID())
 		ctx.Export("apiGatewayEndpoint", httpApi.ApiEndpoint)
 		ctx.Export("webapiUrl", httpApi.ApiEndpoint)
+		ctx.Export("apiUrl", apiBaseUrl)
+		ctx.Export("mcpServerUrl", mcpServerUrl)
 
 		// Schedule-related exports
 		ctx.Export("schedulesTableName", schedulesTable.Name)
 		ctx.Export("schedulesTableArn", schedulesTable.Arn)
-		ctx.Export("scheduleCreationTopicArn", scheduleCreationTopic.Arn)
+		ctx.Export("weatherDecisionsTableName", weatherDecisionsTable.Name)
+		ctx.Export("preferencesTableName", preferencesTable.Name)
+		ctx.Export("approvalsTableName", approvalsTable.Name)
+		ctx.Export("oauthTokensTableName", oauthTokensTable.Name)
+		ctx.Export("webActionHandlersTableName", webActionHandlersTable.Name)
+		ctx.Export("auditTableName", auditTable.Name)
+		ctx.Export("usersTableName", usersTable.Name)
+		ctx.Export("reservationsTableName", reservationsTable.Name)
+		ctx.Export("bookingLedgerTableName", bookingLedgerTable.Name)
+		ctx.Export("scheduleCreationTopicArn", scheduleCreation.Topic.Arn)
 		ctx.Export("eventBridgeSchedulerExecutionRoleArn", eventBridgeSchedulerExecutionRole.Arn)
+		ctx.Export("eventBusName", eventBusName)
 
 		return nil
 	})
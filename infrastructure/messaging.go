@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudwatch"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/sns"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/sqs"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// childOf parents a resource under a component while keeping the URN it had when it was declared
+// at the top level, so moving a resource into a component does not replace it in existing stacks
+func childOf(parent pulumi.Resource, opts ...pulumi.ResourceOption) []pulumi.ResourceOption {
+	return append([]pulumi.ResourceOption{
+		pulumi.Parent(parent),
+		pulumi.Aliases([]pulumi.Alias{{NoParent: pulumi.Bool(true)}}),
+	}, opts...)
+}
+
+// ChannelArgs describes one SNS topic -> SQS queue channel
+type ChannelArgs struct {
+	// Name is the channel's short name: the topic and queue are rez-agent-<Name>-<stage>
+	Name string
+	// VisibilityTimeoutSeconds defaults to 300; keep it at or above the consumer's timeout
+	VisibilityTimeoutSeconds int
+}
+
+// Channel is a topic, its queue and the queue's dead-letter queue
+type Channel struct {
+	Topic       *sns.Topic
+	Queue       *sqs.Queue
+	Dlq         *sqs.Queue
+	QueuePolicy *sqs.QueuePolicy
+}
+
+// SubscriptionArgs subscribes another consumer to a channel's topic. Its filter policy selects
+// the messages it receives by the attributes messaging.TopicRoutingSNSClient publishes, so a
+// consumer can take a slice of a topic without a topic of its own.
+type SubscriptionArgs struct {
+	// Name is the subscription's short name: rez-agent-<Name>-subscription-<stage>
+	Name string `json:"name"`
+	// Channel is the name of the channel whose topic is subscribed to
+	Channel string `json:"channel"`
+	// Protocol and Endpoint are the SNS subscription's, e.g. sqs and a queue ARN. The endpoint's
+	// owner grants the topic access (a queue or function policy).
+	Protocol string `json:"protocol"`
+	Endpoint string `json:"endpoint"`
+	// Filter maps message attributes to the values they may take, e.g. {"priority": ["high"]}
+	Filter map[string][]interface{} `json:"filter"`
+}
+
+// filterAttributes are the message attributes a subscription filter policy can select on
+var filterAttributes = []string{"message_type", "stage", "status", "priority", "course_id"}
+
+// MessagingArgs are the inputs to NewMessagingComponent
+type MessagingArgs struct {
+	Stage         string
+	Channels      []ChannelArgs
+	Subscriptions []SubscriptionArgs
+	// EventBus creates the rez-agent-events-<stage> bus that domain events are published to
+	EventBus bool
+	Tags     pulumi.StringMap
+}
+
+// MessagingComponent owns the topic-based routing fabric: one channel per message route
+type MessagingComponent struct {
+	pulumi.ResourceState
+
+	Channels map[string]*Channel
+	// EventBus is nil unless MessagingArgs.EventBus is set
+	EventBus *cloudwatch.EventBus
+}
+
+// NewMessagingComponent creates every channel in args. Each queue keeps failed messages for 14 days
+// and moves a message to its DLQ after three receives.
+func NewMessagingComponent(ctx *pulumi.Context, name string, args *MessagingArgs, opts ...pulumi.ResourceOption) (*MessagingComponent, error) {
+	component := &MessagingComponent{Channels: make(map[string]*Channel, len(args.Channels))}
+	if err := ctx.RegisterComponentResource("rez-agent:index:Messaging", name, component, opts...); err != nil {
+		return nil, err
+	}
+
+	outputs := pulumi.Map{}
+	for _, channelArgs := range args.Channels {
+		channel, err := newChannel(ctx, component, args.Stage, channelArgs, args.Tags)
+		if err != nil {
+			return nil, err
+		}
+		component.Channels[channelArgs.Name] = channel
+		outputs[channelArgs.Name+"TopicArn"] = channel.Topic.Arn
+		outputs[channelArgs.Name+"QueueUrl"] = channel.Queue.Url
+	}
+
+	for _, subscriptionArgs := range args.Subscriptions {
+		if err := component.subscribe(ctx, args.Stage, subscriptionArgs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Domain events (messaging.EventBridgePublisher) for other systems to subscribe to with rules
+	if args.EventBus {
+		bus, err := cloudwatch.NewEventBus(ctx, fmt.Sprintf("rez-agent-events-%s", args.Stage), &cloudwatch.EventBusArgs{
+			Name: pulumi.String(fmt.Sprintf("rez-agent-events-%s", args.Stage)),
+			Tags: args.Tags,
+		}, pulumi.Parent(component))
+		if err != nil {
+			return nil, err
+		}
+		component.EventBus = bus
+		outputs["eventBusName"] = bus.Name
+	}
+
+	if err := ctx.RegisterResourceOutputs(component, outputs); err != nil {
+		return nil, err
+	}
+	return component, nil
+}
+
+// Channel returns the named channel; it panics on a name that was not declared, which is a
+// programming error in main.go rather than a deploy-time condition
+func (m *MessagingComponent) Channel(name string) *Channel {
+	channel, ok := m.Channels[name]
+	if !ok {
+		panic(fmt.Sprintf("messaging channel %q is not declared", name))
+	}
+	return channel
+}
+
+// subscribe creates a filtered subscription to a channel's topic
+func (m *MessagingComponent) subscribe(ctx *pulumi.Context, stage string, args SubscriptionArgs) error {
+	channel, ok := m.Channels[args.Channel]
+	if !ok {
+		return fmt.Errorf("subscription %q names unknown channel %q", args.Name, args.Channel)
+	}
+	if args.Name == "" || args.Protocol == "" || args.Endpoint == "" {
+		return fmt.Errorf("subscription to %s needs a name, protocol and endpoint", args.Channel)
+	}
+	if len(args.Filter) == 0 {
+		return fmt.Errorf("subscription %q has no filter; subscribe to the whole topic with its channel's queue", args.Name)
+	}
+	for name := range args.Filter {
+		if !slices.Contains(filterAttributes, name) {
+			return fmt.Errorf("subscription %q filters on unknown attribute %q (want one of %v)", args.Name, name, filterAttributes)
+		}
+	}
+	policy, err := json.Marshal(args.Filter)
+	if err != nil {
+		return fmt.Errorf("subscription %q has an invalid filter: %w", args.Name, err)
+	}
+
+	_, err = sns.NewTopicSubscription(ctx, fmt.Sprintf("rez-agent-%s-subscription-%s", args.Name, stage), &sns.TopicSubscriptionArgs{
+		Topic:              channel.Topic.Arn,
+		Protocol:           pulumi.String(args.Protocol),
+		Endpoint:           pulumi.String(args.Endpoint),
+		RawMessageDelivery: pulumi.Bool(args.Protocol == "sqs"),
+		FilterPolicy:       pulumi.String(string(policy)),
+		FilterPolicyScope:  pulumi.String("MessageAttributes"),
+	}, pulumi.Parent(m))
+	return err
+}
+
+func newChannel(ctx *pulumi.Context, parent pulumi.Resource, stage string, args ChannelArgs, tags pulumi.StringMap) (*Channel, error) {
+	resourceName := func(suffix string) string {
+		if suffix == "" {
+			return fmt.Sprintf("rez-agent-%s-%s", args.Name, stage)
+		}
+		return fmt.Sprintf("rez-agent-%s-%s-%s", args.Name, suffix, stage)
+	}
+
+	visibilityTimeout := args.VisibilityTimeoutSeconds
+	if visibilityTimeout == 0 {
+		visibilityTimeout = 300
+	}
+
+	topic, err := sns.NewTopic(ctx, resourceName(""), &sns.TopicArgs{
+		Name: pulumi.String(resourceName("")),
+		Tags: tags,
+	}, childOf(parent)...)
+	if err != nil {
+		return nil, err
+	}
+
+	dlq, err := sqs.NewQueue(ctx, resourceName("dlq"), &sqs.QueueArgs{
+		Name:                    pulumi.String(resourceName("dlq")),
+		MessageRetentionSeconds: pulumi.Int(1209600), // 14 days
+		Tags:                    tags,
+	}, childOf(parent)...)
+	if err != nil {
+		return nil, err
+	}
+
+	queue, err := sqs.NewQueue(ctx, resourceName(""), &sqs.QueueArgs{
+		Name:                     pulumi.String(resourceName("")),
+		VisibilityTimeoutSeconds: pulumi.Int(visibilityTimeout),
+		MessageRetentionSeconds:  pulumi.Int(1209600), // 14 days
+		RedrivePolicy: dlq.Arn.ApplyT(func(arn string) string {
+			return fmt.Sprintf(`{"deadLetterTargetArn":"%s","maxReceiveCount":3}`, arn)
+		}).(pulumi.StringOutput),
+		Tags: tags,
+	}, childOf(parent)...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = sns.NewTopicSubscription(ctx, resourceName("subscription"), &sns.TopicSubscriptionArgs{
+		Topic:              topic.Arn,
+		Protocol:           pulumi.String("sqs"),
+		Endpoint:           queue.Arn,
+		RawMessageDelivery: pulumi.Bool(true),
+	}, childOf(parent)...)
+	if err != nil {
+		return nil, err
+	}
+
+	queuePolicy, err := sqs.NewQueuePolicy(ctx, resourceName("queue-policy"), &sqs.QueuePolicyArgs{
+		QueueUrl: queue.Url,
+		Policy: pulumi.All(queue.Arn, topic.Arn).ApplyT(func(args []interface{}) string {
+			queueArn := args[0].(string)
+			topicArn := args[1].(string)
+			return fmt.Sprintf(`{
+				"Version": "2012-10-17",
+				"Statement": [{
+					"Effect": "Allow",
+					"Principal": {"Service": "sns.amazonaws.com"},
+					"Action": "sqs:SendMessage",
+					"Resource": "%s",
+					"Condition": {
+						"ArnEquals": {"aws:SourceArn": "%s"}
+					}
+				}]
+			}`, queueArn, topicArn)
+		}).(pulumi.StringOutput),
+	}, childOf(parent)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Channel{Topic: topic, Queue: queue, Dlq: dlq, QueuePolicy: queuePolicy}, nil
+}
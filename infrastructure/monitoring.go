@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudwatch"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/sns"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// monitoredFunction is a Lambda function covered by the dashboard and alarm suite
+type monitoredFunction struct {
+	label string
+	name  pulumi.StringOutput
+}
+
+// monitoredQueue is a work queue and the DLQ its failed messages are moved to
+type monitoredQueue struct {
+	label   string
+	name    pulumi.StringOutput
+	dlqName pulumi.StringOutput
+	// ageThreshold overrides queueAgeThresholdSeconds for the backlog alarm (optional)
+	ageThreshold int
+}
+
+// backlogThreshold is the age in seconds of the oldest message that raises the queue's backlog alarm
+func (q monitoredQueue) backlogThreshold() int {
+	if q.ageThreshold > 0 {
+		return q.ageThreshold
+	}
+	return queueAgeThresholdSeconds
+}
+
+// Alarm thresholds
+const (
+	lambdaErrorThreshold     = 5   // Errors per 5 minutes, for two periods in a row
+	queueAgeThresholdSeconds = 900 // Age of the oldest message before a queue counts as backed up, unless tuned
+	api5xxThreshold          = 5   // 5xx responses per 5 minutes
+)
+
+// newAlertsTopic creates the SNS topic every alarm notifies, subscribing the configured email address
+// and ntfy topic. SNS asks both endpoints to confirm the subscription before delivering alerts.
+func newAlertsTopic(ctx *pulumi.Context, stage, email, ntfyURL string, tags pulumi.StringMap) (*sns.Topic, error) {
+	topic, err := sns.NewTopic(ctx, fmt.Sprintf("rez-agent-alerts-%s", stage), &sns.TopicArgs{
+		Name: pulumi.String(fmt.Sprintf("rez-agent-alerts-%s", stage)),
+		Tags: tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if email != "" {
+		_, err = sns.NewTopicSubscription(ctx, fmt.Sprintf("rez-agent-alerts-email-%s", stage), &sns.TopicSubscriptionArgs{
+			Topic:    topic.Arn,
+			Protocol: pulumi.String("email"),
+			Endpoint: pulumi.String(email),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ntfyURL != "" {
+		_, err = sns.NewTopicSubscription(ctx, fmt.Sprintf("rez-agent-alerts-ntfy-%s", stage), &sns.TopicSubscriptionArgs{
+			Topic:    topic.Arn,
+			Protocol: pulumi.String("https"),
+			Endpoint: pulumi.String(ntfyURL),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return topic, nil
+}
+
+// newAlarmSuite creates error and throttle alarms for each function, backlog and DLQ depth alarms for
+// each queue, and a 5xx alarm for the HTTP API, all notifying alertsTopic. DLQ depth alarms also
+// notify dlqAlarmsTopic when they are breached.
+func newAlarmSuite(
+	ctx *pulumi.Context,
+	stage string,
+	functions []monitoredFunction,
+	queues []monitoredQueue,
+	apiID pulumi.StringOutput,
+	alertsTopic *sns.Topic,
+	dlqAlarmsTopic *sns.Topic,
+	tags pulumi.StringMap,
+) error {
+	actions := pulumi.Array{alertsTopic.Arn}
+
+	for _, fn := range functions {
+		_, err := cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("rez-agent-%s-errors-%s", fn.label, stage), &cloudwatch.MetricAlarmArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-%s-errors-%s", fn.label, stage)),
+			ComparisonOperator: pulumi.String("GreaterThanThreshold"),
+			EvaluationPeriods:  pulumi.Int(2),
+			MetricName:         pulumi.String("Errors"),
+			Namespace:          pulumi.String("AWS/Lambda"),
+			Period:             pulumi.Int(300),
+			Statistic:          pulumi.String("Sum"),
+			Threshold:          pulumi.Float64(lambdaErrorThreshold),
+			TreatMissingData:   pulumi.String("notBreaching"),
+			AlarmDescription:   pulumi.String(fmt.Sprintf("Alert when %s Lambda has errors", fn.label)),
+			Dimensions:         pulumi.StringMap{"FunctionName": fn.name},
+			AlarmActions:       actions,
+			OkActions:          actions,
+			Tags:               tags,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("rez-agent-%s-throttles-%s", fn.label, stage), &cloudwatch.MetricAlarmArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-%s-throttles-%s", fn.label, stage)),
+			ComparisonOperator: pulumi.String("GreaterThanThreshold"),
+			EvaluationPeriods:  pulumi.Int(1),
+			MetricName:         pulumi.String("Throttles"),
+			Namespace:          pulumi.String("AWS/Lambda"),
+			Period:             pulumi.Int(300),
+			Statistic:          pulumi.String("Sum"),
+			Threshold:          pulumi.Float64(0),
+			TreatMissingData:   pulumi.String("notBreaching"),
+			AlarmDescription:   pulumi.String(fmt.Sprintf("Alert when %s Lambda invocations are throttled", fn.label)),
+			Dimensions:         pulumi.StringMap{"FunctionName": fn.name},
+			AlarmActions:       actions,
+			OkActions:          actions,
+			Tags:               tags,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, q := range queues {
+		threshold := q.backlogThreshold()
+		_, err := cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("rez-agent-%s-backlog-%s", q.label, stage), &cloudwatch.MetricAlarmArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-%s-backlog-%s", q.label, stage)),
+			ComparisonOperator: pulumi.String("GreaterThanThreshold"),
+			EvaluationPeriods:  pulumi.Int(1),
+			MetricName:         pulumi.String("ApproximateAgeOfOldestMessage"),
+			Namespace:          pulumi.String("AWS/SQS"),
+			Period:             pulumi.Int(300),
+			Statistic:          pulumi.String("Maximum"),
+			Threshold:          pulumi.Float64(threshold),
+			TreatMissingData:   pulumi.String("notBreaching"),
+			AlarmDescription:   pulumi.String(fmt.Sprintf("Alert when %s messages wait more than %d seconds to be processed", q.label, threshold)),
+			Dimensions:         pulumi.StringMap{"QueueName": q.name},
+			AlarmActions:       actions,
+			OkActions:          actions,
+			Tags:               tags,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("rez-agent-%s-dlq-depth-%s", q.label, stage), &cloudwatch.MetricAlarmArgs{
+			Name:               pulumi.String(fmt.Sprintf("rez-agent-%s-dlq-depth-%s", q.label, stage)),
+			ComparisonOperator: pulumi.String("GreaterThanThreshold"),
+			EvaluationPeriods:  pulumi.Int(1),
+			MetricName:         pulumi.String("ApproximateNumberOfMessagesVisible"),
+			Namespace:          pulumi.String("AWS/SQS"),
+			Period:             pulumi.Int(300),
+			Statistic:          pulumi.String("Maximum"),
+			Threshold:          pulumi.Float64(0),
+			TreatMissingData:   pulumi.String("notBreaching"),
+			AlarmDescription:   pulumi.String(fmt.Sprintf("Alert when %s messages are dead-lettered", q.label)),
+			Dimensions:         pulumi.StringMap{"QueueName": q.dlqName},
+			AlarmActions:       pulumi.Array{alertsTopic.Arn, dlqAlarmsTopic.Arn},
+			OkActions:          actions,
+			Tags:               tags,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("rez-agent-api-5xx-%s", stage), &cloudwatch.MetricAlarmArgs{
+		Name:               pulumi.String(fmt.Sprintf("rez-agent-api-5xx-%s", stage)),
+		ComparisonOperator: pulumi.String("GreaterThanThreshold"),
+		EvaluationPeriods:  pulumi.Int(1),
+		MetricName:         pulumi.String("5xx"),
+		Namespace:          pulumi.String("AWS/ApiGateway"),
+		Period:             pulumi.Int(300),
+		Statistic:          pulumi.String("Sum"),
+		Threshold:          pulumi.Float64(api5xxThreshold),
+		TreatMissingData:   pulumi.String("notBreaching"),
+		AlarmDescription:   pulumi.String("Alert when the HTTP API returns server errors"),
+		Dimensions: pulumi.StringMap{
+			"ApiId": apiID,
+			"Stage": pulumi.String("$default"),
+		},
+		AlarmActions: actions,
+		OkActions:    actions,
+		Tags:         tags,
+	})
+	return err
+}
+
+// newDashboard creates a CloudWatch dashboard with Lambda errors, duration and throttles, queue and
+// DLQ depth, messages in flight and oldest message age, and HTTP API responses
+func newDashboard(ctx *pulumi.Context, stage string, functions []monitoredFunction, queues []monitoredQueue, apiID pulumi.StringOutput) error {
+	region, err := aws.GetRegion(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Resolve every name in one pass; the order matches dashboardBody's reads
+	var names []interface{}
+	for _, fn := range functions {
+		names = append(names, fn.name)
+	}
+	for _, q := range queues {
+		names = append(names, q.name, q.dlqName)
+	}
+	names = append(names, apiID)
+
+	body := pulumi.All(names...).ApplyT(func(args []interface{}) (string, error) {
+		return dashboardBody(region.Name, functions, queues, args)
+	}).(pulumi.StringOutput)
+
+	_, err = cloudwatch.NewDashboard(ctx, fmt.Sprintf("rez-agent-%s", stage), &cloudwatch.DashboardArgs{
+		DashboardName: pulumi.String(fmt.Sprintf("rez-agent-%s", stage)),
+		DashboardBody: body,
+	})
+	return err
+}
+
+// dashboardWidget is a metric graph on a CloudWatch dashboard
+type dashboardWidget struct {
+	Type       string                 `json:"type"`
+	X          int                    `json:"x"`
+	Y          int                    `json:"y"`
+	Width      int                    `json:"width"`
+	Height     int                    `json:"height"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// dashboardBody renders the dashboard JSON from resolved names: one per function, a queue and DLQ
+// name per queue, then the API ID
+func dashboardBody(region string, functions []monitoredFunction, queues []monitoredQueue, names []interface{}) (string, error) {
+	next := 0
+	take := func() string {
+		name := names[next].(string)
+		next++
+		return name
+	}
+
+	var errorsMetrics, durationMetrics, throttleMetrics [][]interface{}
+	for range functions {
+		name := take()
+		errorsMetrics = append(errorsMetrics, []interface{}{"AWS/Lambda", "Errors", "FunctionName", name})
+		durationMetrics = append(durationMetrics, []interface{}{"AWS/Lambda", "Duration", "FunctionName", name, map[string]string{"stat": "p95"}})
+		throttleMetrics = append(throttleMetrics, []interface{}{"AWS/Lambda", "Throttles", "FunctionName", name})
+	}
+
+	var depthMetrics, dlqMetrics, inFlightMetrics, ageMetrics [][]interface{}
+	for range queues {
+		queue, dlq := take(), take()
+		depthMetrics = append(depthMetrics, []interface{}{"AWS/SQS", "ApproximateNumberOfMessagesVisible", "QueueName", queue, map[string]string{"stat": "Maximum"}})
+		dlqMetrics = append(dlqMetrics, []interface{}{"AWS/SQS", "ApproximateNumberOfMessagesVisible", "QueueName", dlq, map[string]string{"stat": "Maximum"}})
+		inFlightMetrics = append(inFlightMetrics, []interface{}{"AWS/SQS", "ApproximateNumberOfMessagesNotVisible", "QueueName", queue, map[string]string{"stat": "Maximum"}})
+		ageMetrics = append(ageMetrics, []interface{}{"AWS/SQS", "ApproximateAgeOfOldestMessage", "QueueName", queue, map[string]string{"stat": "Maximum"}})
+	}
+
+	apiID := take()
+	apiMetrics := [][]interface{}{
+		{"AWS/ApiGateway", "Count", "ApiId", apiID, "Stage", "$default"},
+		{"AWS/ApiGateway", "4xx", "ApiId", apiID, "Stage", "$default"},
+		{"AWS/ApiGateway", "5xx", "ApiId", apiID, "Stage", "$default"},
+	}
+
+	graph := func(x, y int, title, stat string, metrics [][]interface{}) dashboardWidget {
+		return dashboardWidget{
+			Type: "metric", X: x, Y: y, Width: 12, Height: 6,
+			Properties: map[string]interface{}{
+				"title":   title,
+				"region":  region,
+				"stat":    stat,
+				"period":  300,
+				"view":    "timeSeries",
+				"metrics": metrics,
+			},
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"widgets": []dashboardWidget{
+			graph(0, 0, "Lambda errors", "Sum", errorsMetrics),
+			graph(12, 0, "Lambda duration (p95)", "p95", durationMetrics),
+			graph(0, 6, "Lambda throttles", "Sum", throttleMetrics),
+			graph(12, 6, "HTTP API responses", "Sum", apiMetrics),
+			graph(0, 12, "Queue depth", "Maximum", depthMetrics),
+			graph(12, 12, "DLQ depth", "Maximum", dlqMetrics),
+			graph(0, 18, "Messages in flight", "Maximum", inFlightMetrics),
+			graph(12, 18, "Oldest message age (seconds)", "Maximum", ageMetrics),
+		},
+	})
+	return string(body), err
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/s3"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// accessLogRetentionDays is how long S3 server access logs are kept
+const accessLogRetentionDays = 90
+
+// newAccessLogsBucket creates the bucket that receives server access logs for the other buckets.
+// S3 log delivery cannot write to SSE-KMS buckets, so this one uses SSE-S3.
+func newAccessLogsBucket(ctx *pulumi.Context, stage string, tags pulumi.StringMap) (*s3.Bucket, error) {
+	name := fmt.Sprintf("rez-agent-access-logs-%s", stage)
+	bucket, err := s3.NewBucket(ctx, name, &s3.BucketArgs{
+		Bucket:       pulumi.String(name),
+		ForceDestroy: pulumi.Bool(true),
+		Tags:         tags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access logs S3 bucket: %w", err)
+	}
+
+	publicAccessBlock, err := blockPublicAccess(ctx, "rez-agent-access-logs", stage, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s3.NewBucketServerSideEncryptionConfigurationV2(ctx, fmt.Sprintf("rez-agent-access-logs-sse-%s", stage), &s3.BucketServerSideEncryptionConfigurationV2Args{
+		Bucket: bucket.ID(),
+		Rules: s3.BucketServerSideEncryptionConfigurationV2RuleArray{
+			&s3.BucketServerSideEncryptionConfigurationV2RuleArgs{
+				ApplyServerSideEncryptionByDefault: &s3.BucketServerSideEncryptionConfigurationV2RuleApplyServerSideEncryptionByDefaultArgs{
+					SseAlgorithm: pulumi.String("AES256"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure access logs bucket encryption: %w", err)
+	}
+
+	_, err = s3.NewBucketLifecycleConfigurationV2(ctx, fmt.Sprintf("rez-agent-access-logs-lifecycle-%s", stage), &s3.BucketLifecycleConfigurationV2Args{
+		Bucket: bucket.ID(),
+		Rules: s3.BucketLifecycleConfigurationV2RuleArray{
+			&s3.BucketLifecycleConfigurationV2RuleArgs{
+				Id:     pulumi.String("delete-old-access-logs"),
+				Status: pulumi.String("Enabled"),
+				Expiration: &s3.BucketLifecycleConfigurationV2RuleExpirationArgs{
+					Days: pulumi.Int(accessLogRetentionDays),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access logs lifecycle policy: %w", err)
+	}
+
+	// Log delivery may write here only for buckets of this stack; everyone else must use TLS
+	_, err = s3.NewBucketPolicy(ctx, fmt.Sprintf("rez-agent-access-logs-policy-%s", stage), &s3.BucketPolicyArgs{
+		Bucket: bucket.ID(),
+		Policy: bucket.Arn.ApplyT(func(arn string) string {
+			return fmt.Sprintf(`{
+				"Version": "2012-10-17",
+				"Statement": [
+					{
+						"Sid": "AllowLogDelivery",
+						"Effect": "Allow",
+						"Principal": {"Service": "logging.s3.amazonaws.com"},
+						"Action": "s3:PutObject",
+						"Resource": "%s/*",
+						"Condition": {
+							"ArnLike": {"aws:SourceArn": "arn:aws:s3:::rez-agent-*-%s"}
+						}
+					},
+					%s
+				]
+			}`, arn, stage, denyInsecureTransport(arn))
+		}).(pulumi.StringOutput),
+	}, pulumi.DependsOn([]pulumi.Resource{publicAccessBlock}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access logs bucket policy: %w", err)
+	}
+
+	return bucket, nil
+}
+
+// secureBucket blocks public access to bucket, encrypts it with the AWS managed S3 KMS key, denies
+// requests without TLS and sends its access logs to logsBucket under <resourceName>/. Resources are
+// named <resourceName>-<purpose>-<stage>.
+func secureBucket(ctx *pulumi.Context, resourceName, stage string, bucket, logsBucket *s3.Bucket) error {
+	publicAccessBlock, err := blockPublicAccess(ctx, resourceName, stage, bucket)
+	if err != nil {
+		return err
+	}
+
+	// Without a key ID, aws:kms uses the aws/s3 key, which every role in the account may use
+	// through S3, so the Lambda policies need no KMS statements. The bucket key cuts KMS calls.
+	_, err = s3.NewBucketServerSideEncryptionConfigurationV2(ctx, fmt.Sprintf("%s-sse-%s", resourceName, stage), &s3.BucketServerSideEncryptionConfigurationV2Args{
+		Bucket: bucket.ID(),
+		Rules: s3.BucketServerSideEncryptionConfigurationV2RuleArray{
+			&s3.BucketServerSideEncryptionConfigurationV2RuleArgs{
+				ApplyServerSideEncryptionByDefault: &s3.BucketServerSideEncryptionConfigurationV2RuleApplyServerSideEncryptionByDefaultArgs{
+					SseAlgorithm: pulumi.String("aws:kms"),
+				},
+				BucketKeyEnabled: pulumi.Bool(true),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to configure %s encryption: %w", resourceName, err)
+	}
+
+	_, err = s3.NewBucketPolicy(ctx, fmt.Sprintf("%s-policy-%s", resourceName, stage), &s3.BucketPolicyArgs{
+		Bucket: bucket.ID(),
+		Policy: bucket.Arn.ApplyT(func(arn string) string {
+			return fmt.Sprintf(`{
+				"Version": "2012-10-17",
+				"Statement": [%s]
+			}`, denyInsecureTransport(arn))
+		}).(pulumi.StringOutput),
+	}, pulumi.DependsOn([]pulumi.Resource{publicAccessBlock}))
+	if err != nil {
+		return fmt.Errorf("failed to create %s bucket policy: %w", resourceName, err)
+	}
+
+	_, err = s3.NewBucketLoggingV2(ctx, fmt.Sprintf("%s-access-logging-%s", resourceName, stage), &s3.BucketLoggingV2Args{
+		Bucket:       bucket.ID(),
+		TargetBucket: logsBucket.ID(),
+		TargetPrefix: pulumi.String(resourceName + "/"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to configure %s access logging: %w", resourceName, err)
+	}
+
+	return nil
+}
+
+// blockPublicAccess turns on all four public access blocks for bucket
+func blockPublicAccess(ctx *pulumi.Context, resourceName, stage string, bucket *s3.Bucket) (*s3.BucketPublicAccessBlock, error) {
+	publicAccessBlock, err := s3.NewBucketPublicAccessBlock(ctx, fmt.Sprintf("%s-pab-%s", resourceName, stage), &s3.BucketPublicAccessBlockArgs{
+		Bucket:                bucket.ID(),
+		BlockPublicAcls:       pulumi.Bool(true),
+		BlockPublicPolicy:     pulumi.Bool(true),
+		IgnorePublicAcls:      pulumi.Bool(true),
+		RestrictPublicBuckets: pulumi.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s public access block: %w", resourceName, err)
+	}
+	return publicAccessBlock, nil
+}
+
+// denyInsecureTransport is a bucket policy statement refusing every request made without TLS
+func denyInsecureTransport(bucketArn string) string {
+	return fmt.Sprintf(`{
+		"Sid": "DenyInsecureTransport",
+		"Effect": "Deny",
+		"Principal": "*",
+		"Action": "s3:*",
+		"Resource": ["%s", "%s/*"],
+		"Condition": {
+			"Bool": {"aws:SecureTransport": "false"}
+		}
+	}`, bucketArn, bucketArn)
+}
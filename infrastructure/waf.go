@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudfront"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/wafv2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// AWS managed CloudFront policies: forward every request uncached, with all viewer headers except Host
+const (
+	cachingDisabledPolicyID           = "4135ea2d-6df8-44a3-9df3-4b5a84be39ad"
+	allViewerExceptHostHeaderPolicyID = "b689b0a8-53d0-40ab-baf2-68738e2966ac"
+)
+
+// wafOptions are the Pulumi config toggles for the API's web ACL
+type wafOptions struct {
+	rateLimit      int      // Requests per 5 minutes from one IP before it is blocked
+	managedRules   bool     // Apply the AWS managed common rule set
+	allowedIPCIDRs []string // When set, only these source ranges are allowed
+}
+
+// newProtectedEndpoint puts a CloudFront distribution with a WAFv2 web ACL in front of the HTTP API
+// and returns its HTTPS base URL. WAF cannot be attached to an HTTP API stage directly, so the ACL
+// filters requests at CloudFront instead; CLOUDFRONT-scoped ACLs must live in us-east-1.
+func newProtectedEndpoint(ctx *pulumi.Context, stage string, apiEndpoint pulumi.StringOutput, opts wafOptions, tags pulumi.StringMap) (pulumi.StringOutput, error) {
+	usEast1, err := aws.NewProvider(ctx, fmt.Sprintf("rez-agent-us-east-1-%s", stage), &aws.ProviderArgs{
+		Region: pulumi.String("us-east-1"),
+	})
+	if err != nil {
+		return pulumi.StringOutput{}, err
+	}
+
+	visibility := func(metricName string) *wafv2.WebAclRuleVisibilityConfigArgs {
+		return &wafv2.WebAclRuleVisibilityConfigArgs{
+			CloudwatchMetricsEnabled: pulumi.Bool(true),
+			MetricName:               pulumi.String(metricName),
+			SampledRequestsEnabled:   pulumi.Bool(true),
+		}
+	}
+
+	rules := wafv2.WebAclRuleArray{
+		&wafv2.WebAclRuleArgs{
+			Name:     pulumi.String("rate-limit"),
+			Priority: pulumi.Int(1),
+			Action: &wafv2.WebAclRuleActionArgs{
+				Block: &wafv2.WebAclRuleActionBlockArgs{},
+			},
+			Statement: &wafv2.WebAclRuleStatementArgs{
+				RateBasedStatement: &wafv2.WebAclRuleStatementRateBasedStatementArgs{
+					Limit:            pulumi.Int(opts.rateLimit),
+					AggregateKeyType: pulumi.String("IP"),
+				},
+			},
+			VisibilityConfig: visibility("rez-agent-rate-limit"),
+		},
+	}
+
+	if opts.managedRules {
+		rules = append(rules, &wafv2.WebAclRuleArgs{
+			Name:     pulumi.String("aws-common-rules"),
+			Priority: pulumi.Int(2),
+			OverrideAction: &wafv2.WebAclRuleOverrideActionArgs{
+				None: &wafv2.WebAclRuleOverrideActionNoneArgs{},
+			},
+			Statement: &wafv2.WebAclRuleStatementArgs{
+				ManagedRuleGroupStatement: &wafv2.WebAclRuleStatementManagedRuleGroupStatementArgs{
+					Name:       pulumi.String("AWSManagedRulesCommonRuleSet"),
+					VendorName: pulumi.String("AWS"),
+				},
+			},
+			VisibilityConfig: visibility("rez-agent-aws-common-rules"),
+		})
+	}
+
+	// With an allowlist, requests that pass the rules above are allowed only from listed ranges
+	defaultAction := &wafv2.WebAclDefaultActionArgs{Allow: &wafv2.WebAclDefaultActionAllowArgs{}}
+	if len(opts.allowedIPCIDRs) > 0 {
+		ipSet, err := wafv2.NewIpSet(ctx, fmt.Sprintf("rez-agent-api-allowlist-%s", stage), &wafv2.IpSetArgs{
+			Name:             pulumi.String(fmt.Sprintf("rez-agent-api-allowlist-%s", stage)),
+			Scope:            pulumi.String("CLOUDFRONT"),
+			IpAddressVersion: pulumi.String("IPV4"),
+			Addresses:        pulumi.ToStringArray(opts.allowedIPCIDRs),
+			Tags:             tags,
+		}, pulumi.Provider(usEast1))
+		if err != nil {
+			return pulumi.StringOutput{}, err
+		}
+
+		rules = append(rules, &wafv2.WebAclRuleArgs{
+			Name:     pulumi.String("ip-allowlist"),
+			Priority: pulumi.Int(3),
+			Action: &wafv2.WebAclRuleActionArgs{
+				Allow: &wafv2.WebAclRuleActionAllowArgs{},
+			},
+			Statement: &wafv2.WebAclRuleStatementArgs{
+				IpSetReferenceStatement: &wafv2.WebAclRuleStatementIpSetReferenceStatementArgs{
+					Arn: ipSet.Arn,
+				},
+			},
+			VisibilityConfig: visibility("rez-agent-ip-allowlist"),
+		})
+		defaultAction = &wafv2.WebAclDefaultActionArgs{Block: &wafv2.WebAclDefaultActionBlockArgs{}}
+	}
+
+	webAcl, err := wafv2.NewWebAcl(ctx, fmt.Sprintf("rez-agent-api-waf-%s", stage), &wafv2.WebAclArgs{
+		Name:          pulumi.String(fmt.Sprintf("rez-agent-api-waf-%s", stage)),
+		Scope:         pulumi.String("CLOUDFRONT"),
+		DefaultAction: defaultAction,
+		Rules:         rules,
+		VisibilityConfig: &wafv2.WebAclVisibilityConfigArgs{
+			CloudwatchMetricsEnabled: pulumi.Bool(true),
+			MetricName:               pulumi.String(fmt.Sprintf("rez-agent-api-waf-%s", stage)),
+			SampledRequestsEnabled:   pulumi.Bool(true),
+		},
+		Tags: tags,
+	}, pulumi.Provider(usEast1))
+	if err != nil {
+		return pulumi.StringOutput{}, err
+	}
+
+	originID := fmt.Sprintf("rez-agent-api-%s", stage)
+	distribution, err := cloudfront.NewDistribution(ctx, fmt.Sprintf("rez-agent-api-cdn-%s", stage), &cloudfront.DistributionArgs{
+		Enabled:  pulumi.Bool(true),
+		Comment:  pulumi.String(fmt.Sprintf("WAF-protected entry point for rez-agent-api-%s", stage)),
+		WebAclId: webAcl.Arn,
+		Origins: cloudfront.DistributionOriginArray{
+			&cloudfront.DistributionOriginArgs{
+				OriginId: pulumi.String(originID),
+				DomainName: apiEndpoint.ApplyT(func(endpoint string) string {
+					return strings.TrimPrefix(endpoint, "https://")
+				}).(pulumi.StringOutput),
+				CustomOriginConfig: &cloudfront.DistributionOriginCustomOriginConfigArgs{
+					HttpPort:             pulumi.Int(80),
+					HttpsPort:            pulumi.Int(443),
+					OriginProtocolPolicy: pulumi.String("https-only"),
+					OriginSslProtocols:   pulumi.StringArray{pulumi.String("TLSv1.2")},
+				},
+			},
+		},
+		DefaultCacheBehavior: &cloudfront.DistributionDefaultCacheBehaviorArgs{
+			TargetOriginId:        pulumi.String(originID),
+			ViewerProtocolPolicy:  pulumi.String("https-only"),
+			AllowedMethods:        pulumi.ToStringArray([]string{"GET", "HEAD", "OPTIONS", "PUT", "POST", "PATCH", "DELETE"}),
+			CachedMethods:         pulumi.ToStringArray([]string{"GET", "HEAD"}),
+			CachePolicyId:         pulumi.String(cachingDisabledPolicyID),
+			OriginRequestPolicyId: pulumi.String(allViewerExceptHostHeaderPolicyID),
+		},
+		Restrictions: &cloudfront.DistributionRestrictionsArgs{
+			GeoRestriction: &cloudfront.DistributionRestrictionsGeoRestrictionArgs{
+				RestrictionType: pulumi.String("none"),
+			},
+		},
+		ViewerCertificate: &cloudfront.DistributionViewerCertificateArgs{
+			CloudfrontDefaultCertificate: pulumi.Bool(true),
+		},
+		PriceClass: pulumi.String("PriceClass_100"),
+		Tags:       tags,
+	})
+	if err != nil {
+		return pulumi.StringOutput{}, err
+	}
+
+	return distribution.DomainName.ApplyT(func(domain string) string {
+		return fmt.Sprintf("https://%s", domain)
+	}).(pulumi.StringOutput), nil
+}
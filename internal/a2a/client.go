@@ -0,0 +1,200 @@
+// Package a2a discovers external agents through their agent cards and delegates subtasks to them
+// using the agent-to-agent (A2A) request format served by the rez_agent agent Lambda.
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+const (
+	// AgentCardPath is where an agent serves its card, relative to its base URL
+	AgentCardPath = "/.well-known/agent-card"
+
+	// ToolPrefix starts the name of every delegation tool offered to the model
+	ToolPrefix = "delegate_to_"
+
+	// DefaultTimeout bounds a delegated request; remote agents run their own model conversations
+	DefaultTimeout = 60 * time.Second
+
+	// cardCacheTTL is how long a discovered card is reused within a warm Lambda
+	cardCacheTTL = 10 * time.Minute
+)
+
+// Skill is a capability advertised on an agent card
+type Skill struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+}
+
+// AgentCard describes an agent and where to send it requests
+type AgentCard struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	URL         string  `json:"url"`
+	Version     string  `json:"version,omitempty"`
+	Skills      []Skill `json:"skills,omitempty"`
+}
+
+// delegateRequest is the body sent to a remote agent
+type delegateRequest struct {
+	Message      string       `json:"message"`
+	AgentContext agentContext `json:"agent_context"`
+}
+
+// agentContext identifies the calling agent to the remote agent
+type agentContext struct {
+	AgentID   string `json:"agent_id"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// delegateResponse is the reply from a remote agent
+type delegateResponse struct {
+	Message string `json:"message"`
+	Status  string `json:"status,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Client discovers remote agents and delegates tasks to them
+type Client struct {
+	httpClient     *httpclient.Client
+	secretsManager *secrets.Manager
+	agentID        string
+	logger         *slog.Logger
+}
+
+// NewClient creates an A2A client that identifies itself to remote agents as agentID
+func NewClient(httpClient *httpclient.Client, secretsManager *secrets.Manager, agentID string, logger *slog.Logger) *Client {
+	return &Client{
+		httpClient:     httpClient,
+		secretsManager: secretsManager,
+		agentID:        agentID,
+		logger:         logger,
+	}
+}
+
+// Discover fetches a remote agent's card. Cards without a URL fall back to the configured base URL.
+func (c *Client) Discover(ctx context.Context, agent models.RemoteAgent) (*AgentCard, error) {
+	resp, err := c.httpClient.Do(ctx, httpclient.RequestConfig{
+		Method:   http.MethodGet,
+		URL:      strings.TrimRight(agent.URL, "/") + AgentCardPath,
+		Headers:  map[string]string{"Accept": "application/json"},
+		Timeout:  10 * time.Second,
+		CacheTTL: cardCacheTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch agent card for %s: %w", agent.Name, err)
+	}
+
+	var card AgentCard
+	if err := json.Unmarshal([]byte(resp.Body), &card); err != nil {
+		return nil, fmt.Errorf("failed to parse agent card for %s: %w", agent.Name, err)
+	}
+	if card.URL == "" {
+		card.URL = strings.TrimRight(agent.URL, "/")
+	}
+	if card.Name == "" {
+		card.Name = agent.Name
+	}
+	if !strings.HasPrefix(card.URL, "https://") {
+		return nil, fmt.Errorf("agent card for %s has a non-https url", agent.Name)
+	}
+	return &card, nil
+}
+
+// Delegate sends a task to a remote agent and returns its reply
+func (c *Client) Delegate(ctx context.Context, agent models.RemoteAgent, card *AgentCard, task, requestID string) (string, error) {
+	headers := map[string]string{"Content-Type": "application/json"}
+	if agent.APIKeySecret != "" {
+		secret, err := c.secretsManager.GetSecret(ctx, agent.APIKeySecret)
+		if err != nil {
+			return "", fmt.Errorf("failed to load API key for %s: %w", agent.Name, err)
+		}
+		headers = httpclient.AddAPIKey(headers, secret["api_key"], "")
+	}
+
+	resp, err := c.httpClient.Do(ctx, httpclient.RequestConfig{
+		Method:  http.MethodPost,
+		URL:     card.URL,
+		Headers: headers,
+		Body: delegateRequest{
+			Message: task,
+			AgentContext: agentContext{
+				AgentID:   c.agentID,
+				RequestID: requestID,
+			},
+		},
+		Timeout: DefaultTimeout,
+	})
+	if err != nil {
+		return "", fmt.Errorf("delegation to %s failed: %w", agent.Name, err)
+	}
+
+	var reply delegateResponse
+	if err := json.Unmarshal([]byte(resp.Body), &reply); err != nil {
+		return "", fmt.Errorf("failed to parse reply from %s: %w", agent.Name, err)
+	}
+	if reply.Error != "" {
+		return "", fmt.Errorf("%s returned an error: %s", agent.Name, reply.Error)
+	}
+
+	c.logger.InfoContext(ctx, "delegated task to remote agent",
+		slog.String("agent", agent.Name),
+		slog.String("status", reply.Status),
+		slog.String("request_id", requestID),
+	)
+	return reply.Message, nil
+}
+
+// ToolName returns the delegation tool name for a remote agent
+func ToolName(agent models.RemoteAgent) string {
+	return ToolPrefix + agent.Name
+}
+
+// Tool describes a remote agent as a tool the model can call with a single task argument
+func Tool(agent models.RemoteAgent, card *AgentCard) protocol.Tool {
+	var description strings.Builder
+	fmt.Fprintf(&description, "Delegate a task to %s", card.Name)
+	if card.Description != "" {
+		fmt.Fprintf(&description, ", %s", strings.TrimSuffix(card.Description, "."))
+	}
+	description.WriteString(". Describe the task in plain language; the agent replies in text.")
+	if len(card.Skills) > 0 {
+		description.WriteString(" Skills:")
+		for _, skill := range card.Skills {
+			fmt.Fprintf(&description, " %s", skill.Name)
+			if skill.Description != "" {
+				fmt.Fprintf(&description, " (%s)", skill.Description)
+			}
+			description.WriteString(";")
+		}
+	}
+
+	return protocol.Tool{
+		Name:        ToolName(agent),
+		Description: strings.TrimSuffix(description.String(), ";"),
+		InputSchema: protocol.InputSchema{
+			Type: "object",
+			Properties: map[string]protocol.Property{
+				"task": {
+					Type:        "string",
+					Description: "The subtask for the remote agent, with any dates, times, and names it needs",
+				},
+			},
+			Required: []string{"task"},
+		},
+	}
+}
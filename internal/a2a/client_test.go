@@ -0,0 +1,59 @@
+package a2a
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+func TestTool(t *testing.T) {
+	agent := models.RemoteAgent{Name: "calendar", URL: "https://calendar.example.com/agent"}
+
+	tests := []struct {
+		name            string
+		card            *AgentCard
+		wantDescription []string
+	}{
+		{
+			name:            "card without skills",
+			card:            &AgentCard{Name: "Household Calendar"},
+			wantDescription: []string{"Delegate a task to Household Calendar."},
+		},
+		{
+			name: "card with skills",
+			card: &AgentCard{
+				Name:        "Household Calendar",
+				Description: "Manages the family calendar.",
+				Skills: []Skill{
+					{ID: "check_availability", Name: "Check availability", Description: "Find conflicts on a date"},
+					{ID: "add_event", Name: "Add event"},
+				},
+			},
+			wantDescription: []string{
+				"Household Calendar, Manages the family calendar.",
+				"Check availability (Find conflicts on a date);",
+				"Add event",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := Tool(agent, tt.card)
+			if tool.Name != "delegate_to_calendar" {
+				t.Errorf("Name = %q, want delegate_to_calendar", tool.Name)
+			}
+			for _, want := range tt.wantDescription {
+				if !strings.Contains(tool.Description, want) {
+					t.Errorf("Description = %q, want it to contain %q", tool.Description, want)
+				}
+			}
+			if strings.HasSuffix(tool.Description, ";") {
+				t.Errorf("Description = %q has a trailing separator", tool.Description)
+			}
+			if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "task" {
+				t.Errorf("Required = %v, want [task]", tool.InputSchema.Required)
+			}
+		})
+	}
+}
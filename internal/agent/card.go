@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+//go:embed agent_card.json
+var agentCardJSON []byte
+
+// Skill is a capability advertised to other agents, mapped onto the MCP tools that provide it
+type Skill struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Examples    []string `json:"examples"`
+	Tools       []string `json:"tools"`
+}
+
+// Skills are the skills other agents can delegate to the golf assistant
+var Skills = []Skill{
+	{
+		ID:          "search_tee_times",
+		Name:        "Search tee times",
+		Description: "Find available tee times at a supported course for a date, time window, and group size.",
+		Tags:        []string{"golf", "tee-times", "search"},
+		Examples:    []string{"Find a tee time for 2 at Totteridge on Saturday morning"},
+		Tools:       []string{"golf_list_courses", "golf_search_tee_times", "golf_search_tee_times_range"},
+	},
+	{
+		ID:          "book_tee_time",
+		Name:        "Book a tee time",
+		Description: "Book a tee time found by a search, within the golfer's booking policy.",
+		Tags:        []string{"golf", "tee-times", "booking"},
+		Examples:    []string{"Book the 8:10 tee time at Birdsfoot for 4 players"},
+		Tools:       []string{"golf_book_tee_time", "check_constraints"},
+	},
+	{
+		ID:          "cancel_tee_time",
+		Name:        "Cancel a tee time",
+		Description: "Find a reservation and hand the golfer the course's cancellation page; the course confirms cancellations by email.",
+		Tags:        []string{"golf", "tee-times", "cancellation"},
+		Examples:    []string{"Cancel my Saturday tee time at Totteridge"},
+		Tools:       []string{"golf_get_reservations", "golf_cancel_tee_time"},
+	},
+	{
+		ID:          "get_reservations",
+		Name:        "List reservations",
+		Description: "List the golfer's upcoming reservations at a course.",
+		Tags:        []string{"golf", "reservations"},
+		Examples:    []string{"What tee times do I have this week?"},
+		Tools:       []string{"golf_get_reservations"},
+	},
+	{
+		ID:          "weather_brief",
+		Name:        "Weather brief",
+		Description: "Summarize the forecast at a course and whether it is good golf weather.",
+		Tags:        []string{"weather", "golf"},
+		Examples:    []string{"Is it going to rain at Totteridge tomorrow afternoon?"},
+		Tools:       []string{"get_weather", "get_weather_by_place"},
+	},
+}
+
+// baseURL returns the public agent URL: the configured one, or one derived from the API Gateway
+// domain the request came in on
+func baseURL(configured string, request events.APIGatewayV2HTTPRequest) string {
+	if configured = strings.TrimSpace(configured); configured != "" {
+		return strings.TrimRight(configured, "/")
+	}
+	if request.RequestContext.DomainName == "" {
+		return "/agent"
+	}
+	return fmt.Sprintf("https://%s/agent", request.RequestContext.DomainName)
+}
+
+// buildCard fills the static agent card in with the agent's URL and skills
+func buildCard(url string) (map[string]interface{}, error) {
+	var card map[string]interface{}
+	if err := json.Unmarshal(agentCardJSON, &card); err != nil {
+		return nil, fmt.Errorf("failed to parse agent card: %w", err)
+	}
+
+	card["url"] = url
+	card["skills"] = Skills
+	endpoints, _ := card["endpoints"].(map[string]interface{})
+	if endpoints == nil {
+		endpoints = make(map[string]interface{})
+	}
+	endpoints["primary"] = url
+	endpoints["card"] = url + "/.well-known/agent-card"
+	endpoints["cancel"] = url + "/tasks/{request_id}/cancel"
+	card["endpoints"] = endpoints
+	return card, nil
+}
+
+// authorizeAgent checks the API key of a request made by another agent. It returns "" when the
+// request may proceed, or why it was refused. Agent requests are refused when no key is
+// configured, so the agent is never open by default.
+func authorizeAgent(expected, provided string) string {
+	if expected == "" {
+		return "Agent-to-agent requests are not enabled"
+	}
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) != 1 {
+		return "Invalid API key"
+	}
+	return ""
+}
@@ -0,0 +1,538 @@
+// Package agent serves the interactive golf assistant: chat at POST /agent, the A2A agent card
+// and task cancellation, the chat UI with the browser's previous sessions and a health check. Chat turns run the scheduler's Bedrock conversation loop and
+// MCP tools, with the history kept in windowed agent sessions.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/health"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/internal/scheduler"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+// estimatedTurnCost is what a chat turn is assumed to cost when checking the daily spending cap
+// before running it: about 4,000 input and 2,000 output tokens at Claude 3.5 Sonnet rates
+const estimatedTurnCost = 0.042
+
+// defaultTaskPollInterval is how often a running A2A task checks whether it was cancelled
+const defaultTaskPollInterval = 2 * time.Second
+
+// errTaskCancelled ends a chat turn whose A2A task was cancelled
+var errTaskCancelled = errors.New("agent task cancelled")
+
+// usageQueries are the messages answered with the day's Bedrock usage instead of a chat turn
+var usageQueries = []string{"cost", "usage", "spending", "budget"}
+
+// Chatter answers chat turns; *scheduler.AWSAgentEventHandler implements it
+type Chatter interface {
+	Chat(ctx context.Context, req *scheduler.ChatRequest) (*scheduler.ChatResponse, error)
+}
+
+// Handler serves the agent's API Gateway routes and consumes its agent-responses queue
+type Handler struct {
+	config       *appconfig.Config
+	chat         Chatter
+	sessions     repository.AgentSessionRepository
+	usage        repository.AgentUsageRepository
+	tasks        repository.AgentTaskRepository
+	healthChecks []health.Check
+	responses    *messaging.SQSBatchProcessor
+	logger       *slog.Logger
+
+	// taskPollInterval is how often a running A2A task checks whether it was cancelled
+	taskPollInterval time.Duration
+}
+
+// NewHandler creates the agent handler
+func NewHandler(cfg *appconfig.Config, chat Chatter, sessions repository.AgentSessionRepository, usage repository.AgentUsageRepository, logger *slog.Logger) *Handler {
+	return &Handler{
+		config:   cfg,
+		chat:     chat,
+		sessions: sessions,
+		usage:    usage,
+		logger:   logger,
+
+		taskPollInterval: defaultTaskPollInterval,
+	}
+}
+
+// SetTaskRepository enables cancelling A2A tasks through POST /agent/tasks/{id}/cancel
+func (h *Handler) SetTaskRepository(tasks repository.AgentTaskRepository) {
+	h.tasks = tasks
+}
+
+// SetHealthChecks sets the dependencies GET /agent/health checks
+func (h *Handler) SetHealthChecks(checks []health.Check) {
+	h.healthChecks = checks
+}
+
+// SetResponseProcessor enables consuming the agent-responses queue with processor
+func (h *Handler) SetResponseProcessor(processor *messaging.SQSBatchProcessor) {
+	h.responses = processor
+}
+
+// agentContext identifies another agent calling the chat endpoint
+type agentContext struct {
+	AgentID   string `json:"agent_id"`
+	RequestID string `json:"request_id"`
+	Priority  string `json:"priority"`
+}
+
+// chatRequest is the body of POST /agent
+type chatRequest struct {
+	Message      string        `json:"message"`
+	SessionID    string        `json:"session_id"`
+	ApproveTools []string      `json:"approve_tools"`
+	AgentContext *agentContext `json:"agent_context"`
+}
+
+// consentRequired lists the tools waiting for the user's approval
+type consentRequired struct {
+	Tools []string `json:"tools"`
+}
+
+// responseMetadata echoes the calling agent's request ID
+type responseMetadata struct {
+	RequestID *string `json:"request_id"`
+}
+
+// chatResponse is the body of a successful POST /agent
+type chatResponse struct {
+	SessionID       string           `json:"session_id"`
+	Message         string           `json:"message"`
+	Status          string           `json:"status"`
+	ConsentRequired *consentRequired `json:"consent_required"`
+	Metadata        responseMetadata `json:"metadata"`
+}
+
+// taskCancellation is the body of a successful POST /agent/tasks/{id}/cancel
+type taskCancellation struct {
+	TaskID string `json:"task_id"`
+	Status string `json:"status"`
+}
+
+// usageReport is the day's Bedrock usage against the spending cap
+type usageReport struct {
+	Date            string  `json:"date"`
+	TotalCost       float64 `json:"total_cost"`
+	DailyCap        float64 `json:"daily_cap"`
+	RemainingBudget float64 `json:"remaining_budget"`
+	PercentageUsed  float64 `json:"percentage_used"`
+	RequestCount    int64   `json:"request_count"`
+	InputTokens     int64   `json:"input_tokens"`
+	OutputTokens    int64   `json:"output_tokens"`
+	ResetTime       string  `json:"reset_time"`
+}
+
+// HandleRequest routes API Gateway V2 requests to the agent's endpoints
+func (h *Handler) HandleRequest(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	ctx = logging.WithCorrelationID(ctx, logging.IncomingCorrelationID(request.Headers["x-correlation-id"]))
+
+	path := request.RawPath
+	if path == "" {
+		path = request.RequestContext.HTTP.Path
+	}
+	method := request.RequestContext.HTTP.Method
+
+	h.logger.DebugContext(ctx, "received agent request",
+		slog.String("method", method),
+		slog.String("path", path),
+	)
+
+	for _, r := range routes {
+		if params, ok := r.Match(method, path); ok {
+			if params != nil {
+				request.PathParameters = params
+			}
+			return r.handle(h, ctx, request)
+		}
+	}
+	return errorResponse(http.StatusNotFound, "endpoint not found"), nil
+}
+
+// handleCard returns the agent card for A2A discovery
+func (h *Handler) handleCard(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	card, err := buildCard(baseURL(h.config.AgentCardURL, request))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load agent card", slog.String("error", err.Error()))
+		return errorResponse(http.StatusInternalServerError, "Failed to load agent card"), nil
+	}
+	return jsonResponse(http.StatusOK, card), nil
+}
+
+// handleHealth checks the agent's dependencies for uptime monitors
+func (h *Handler) handleHealth(ctx context.Context) (events.APIGatewayV2HTTPResponse, error) {
+	report := health.Run(ctx, "agent", h.config.Stage.String(), health.DefaultTimeout, h.healthChecks)
+	if report.Status != health.StatusHealthy {
+		h.logger.WarnContext(ctx, "agent health check failed", slog.Any("components", report.Components))
+	}
+	return jsonResponse(report.HTTPStatus(), report), nil
+}
+
+// chatTurn is a chat request that passed validation, authentication and the spending cap
+type chatTurn struct {
+	body          chatRequest
+	date          string
+	approvedTools []string
+	chat          *scheduler.ChatRequest
+	// taskID is the calling agent's request ID, which POST /agent/tasks/{id}/cancel cancels
+	taskID string
+}
+
+// handleChat answers one chat turn and appends it to the session. A request that accepts
+// text/event-stream is answered with server-sent events, all at once since API Gateway buffers
+// the response; the function URL streams them (see HandleStream).
+func (h *Handler) handleChat(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	turn, rejected := h.prepareChat(ctx, request)
+	if rejected != nil {
+		return *rejected, nil
+	}
+
+	if acceptsEvents(request) {
+		var body strings.Builder
+		h.streamChat(ctx, turn, &body)
+		return events.APIGatewayV2HTTPResponse{
+			StatusCode: http.StatusOK,
+			Headers:    eventStreamHeaders(),
+			Body:       body.String(),
+		}, nil
+	}
+
+	response, err := h.runChat(ctx, turn)
+	if err != nil {
+		status, message := h.chatFailure(ctx, err)
+		return errorResponseWithRetry(status, message), nil
+	}
+	return jsonResponse(http.StatusOK, response), nil
+}
+
+// prepareChat parses and checks a chat request and loads its session. It returns the turn to
+// run, or the response that rejects or answers the request without running one.
+func (h *Handler) prepareChat(ctx context.Context, request events.APIGatewayV2HTTPRequest) (*chatTurn, *events.APIGatewayV2HTTPResponse) {
+	reject := func(response events.APIGatewayV2HTTPResponse) (*chatTurn, *events.APIGatewayV2HTTPResponse) {
+		return nil, &response
+	}
+
+	var body chatRequest
+	if request.Body != "" {
+		if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+			return reject(errorResponse(http.StatusBadRequest, "invalid request body"))
+		}
+	}
+	// A caller that presents an API key is another agent and must present the A2A key. Without
+	// one, only the chat UI may chat: it continues a session listed in the browser's signed
+	// session cookie, which GET /agent/ui sets. agent_context only describes the call.
+	apiKey := request.Headers["x-api-key"]
+	fromAgent := apiKey != "" || !slices.Contains(h.browserSessions(request), body.SessionID)
+	if fromAgent {
+		var caller agentContext
+		if body.AgentContext != nil {
+			caller = *body.AgentContext
+		}
+		if reason := authorizeAgent(h.config.A2AAPIKey, apiKey); reason != "" {
+			h.logger.WarnContext(ctx, "rejected A2A request", slog.String("agent_id", caller.AgentID))
+			return reject(errorResponse(http.StatusUnauthorized, reason))
+		}
+		h.logger.InfoContext(ctx, "A2A request",
+			slog.String("agent_id", caller.AgentID),
+			slog.String("request_id", caller.RequestID),
+			slog.String("priority", caller.Priority),
+		)
+	}
+
+	if body.SessionID == "" {
+		var err error
+		if body.SessionID, err = newSessionID(); err != nil {
+			h.logger.ErrorContext(ctx, "failed to start agent session", slog.String("error", err.Error()))
+			return reject(errorResponse(http.StatusInternalServerError, "failed to start a session"))
+		}
+	}
+	if body.Message == "" && len(body.ApproveTools) > 0 {
+		body.Message = approvalMessage(body.ApproveTools)
+	}
+
+	date := time.Now().UTC().Format("2006-01-02")
+	usage, err := h.usage.GetUsage(ctx, date)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to read agent usage", slog.String("error", err.Error()))
+		return reject(errorResponse(http.StatusInternalServerError, "failed to check the daily spending cap"))
+	}
+	report := h.usageReport(usage)
+
+	if slices.Contains(usageQueries, strings.ToLower(strings.TrimSpace(body.Message))) {
+		return reject(jsonResponse(http.StatusOK, map[string]interface{}{
+			"session_id": body.SessionID,
+			"message":    formatUsage(report),
+			"usage":      report,
+		}))
+	}
+	if body.Message == "" {
+		return reject(errorResponse(http.StatusBadRequest, "Message is required"))
+	}
+
+	if usage.TotalCost+estimatedTurnCost > h.config.AgentDailySpendingCap {
+		message := fmt.Sprintf("Daily spending cap of $%.2f would be exceeded. Current usage: $%.2f. Resets at midnight UTC (%s).",
+			h.config.AgentDailySpendingCap, usage.TotalCost, report.ResetTime)
+		h.logger.WarnContext(ctx, "chat blocked by the daily spending cap", slog.Float64("total_cost", usage.TotalCost))
+		response := jsonResponse(http.StatusTooManyRequests, map[string]interface{}{
+			"error":   "Daily spending limit reached",
+			"message": message,
+			"usage":   report,
+		})
+		response.Headers["Retry-After"] = "86400"
+		return reject(response)
+	}
+
+	// A missing or unreadable session starts a new conversation; it is created when this turn is saved
+	session, err := h.sessions.GetSession(ctx, body.SessionID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load agent session", slog.String("error", err.Error()))
+	}
+	if session == nil {
+		session = models.NewAgentSession(body.SessionID, time.Now())
+	}
+	approvedTools := mergeApprovals(session.ApprovedTools, body.ApproveTools, h.config.ConsentRequiredTools)
+
+	chat := &scheduler.ChatRequest{
+		History:       session.Messages,
+		Message:       body.Message,
+		ApprovedTools: approvedTools,
+	}
+	// Agents cannot answer consent prompts; the A2A key stands in for the user's approval
+	if !fromAgent {
+		chat.ConsentTools = h.config.ConsentRequiredTools
+	}
+	turn := &chatTurn{body: body, date: date, approvedTools: approvedTools, chat: chat}
+	if fromAgent && body.AgentContext != nil {
+		turn.taskID = body.AgentContext.RequestID
+	}
+	return turn, nil
+}
+
+// runChat runs a turn, records its usage and appends it to the session. A cancelled A2A task
+// abandons the Bedrock or tool call in flight and is answered with status "cancelled"; nothing
+// of it is saved.
+func (h *Handler) runChat(ctx context.Context, turn *chatTurn) (*chatResponse, error) {
+	chatCtx := ctx
+	if h.tasks != nil && turn.taskID != "" {
+		var stop context.CancelFunc
+		chatCtx, stop = h.watchTask(ctx, turn.taskID)
+		defer stop()
+	}
+
+	result, err := h.chat.Chat(chatCtx, turn.chat)
+	if err != nil && errors.Is(context.Cause(chatCtx), errTaskCancelled) {
+		h.logger.InfoContext(ctx, "A2A task cancelled", slog.String("request_id", turn.taskID))
+		return &chatResponse{
+			SessionID: turn.body.SessionID,
+			Message:   "The task was cancelled.",
+			Status:    "cancelled",
+			Metadata:  responseMetadata{RequestID: &turn.taskID},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := h.usage.AddUsage(ctx, turn.date, result.InputTokens, result.OutputTokens, result.Cost); err != nil {
+		h.logger.WarnContext(ctx, "failed to record agent usage", slog.String("error", err.Error()))
+	}
+	if _, err := h.sessions.AppendMessages(ctx, turn.body.SessionID, result.Messages, turn.approvedTools); err != nil {
+		h.logger.ErrorContext(ctx, "failed to save agent session", slog.String("error", err.Error()))
+	}
+
+	response := &chatResponse{
+		SessionID: turn.body.SessionID,
+		Message:   result.Message,
+		Status:    "success",
+	}
+	if len(result.PendingConsent) > 0 {
+		response.Status = "pending"
+		response.ConsentRequired = &consentRequired{Tools: result.PendingConsent}
+	}
+	if agent := turn.body.AgentContext; agent != nil && agent.RequestID != "" {
+		response.Metadata.RequestID = &agent.RequestID
+	}
+	return response, nil
+}
+
+// watchTask returns a context that is cancelled with errTaskCancelled once the task is cancelled.
+// The cancellation is polled, since another Lambda instance usually serves the cancel request.
+func (h *Handler) watchTask(ctx context.Context, taskID string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		ticker := time.NewTicker(h.taskPollInterval)
+		defer ticker.Stop()
+		for {
+			cancelled, err := h.tasks.IsTaskCancelled(ctx, taskID)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				h.logger.WarnContext(ctx, "failed to check for task cancellation", slog.String("error", err.Error()))
+			case cancelled:
+				cancel(errTaskCancelled)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return ctx, func() { cancel(nil) }
+}
+
+// handleCancelTask cancels another agent's chat task, identified by the agent_context.request_id
+// it was sent with. The running turn notices within the poll interval; a cancellation that
+// arrives first stops the task as soon as it starts.
+func (h *Handler) handleCancelTask(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if reason := authorizeAgent(h.config.A2AAPIKey, request.Headers["x-api-key"]); reason != "" {
+		h.logger.WarnContext(ctx, "rejected A2A task cancellation")
+		return errorResponse(http.StatusUnauthorized, reason), nil
+	}
+	if h.tasks == nil {
+		return errorResponse(http.StatusNotFound, "task cancellation is not enabled"), nil
+	}
+
+	taskID := request.PathParameters["id"]
+	if err := h.tasks.CancelTask(ctx, taskID); err != nil {
+		h.logger.ErrorContext(ctx, "failed to cancel A2A task", slog.String("request_id", taskID), slog.String("error", err.Error()))
+		return errorResponse(http.StatusInternalServerError, "failed to cancel the task"), nil
+	}
+	h.logger.InfoContext(ctx, "A2A task cancellation requested", slog.String("request_id", taskID))
+	return jsonResponse(http.StatusAccepted, taskCancellation{TaskID: taskID, Status: "cancelling"}), nil
+}
+
+// chatFailure maps a failed chat turn to a status and message: Bedrock throttling to 429, a
+// timeout to 504
+func (h *Handler) chatFailure(ctx context.Context, err error) (int, string) {
+	h.logger.ErrorContext(ctx, "chat turn failed", slog.String("error", err.Error()))
+
+	switch {
+	case apperrors.Is(err, apperrors.ErrThrottled):
+		return http.StatusTooManyRequests, "The service is experiencing high traffic. Please wait a moment and try again."
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "Request timed out. Please try again."
+	default:
+		return http.StatusInternalServerError, "I'm experiencing an issue right now. Please try again later."
+	}
+}
+
+// usageReport reports usage against the daily spending cap
+func (h *Handler) usageReport(usage *models.AgentUsage) usageReport {
+	limit := h.config.AgentDailySpendingCap
+	return usageReport{
+		Date:            usage.Date,
+		TotalCost:       usage.TotalCost,
+		DailyCap:        limit,
+		RemainingBudget: max(limit-usage.TotalCost, 0),
+		PercentageUsed:  usage.TotalCost / limit * 100,
+		RequestCount:    usage.RequestCount,
+		InputTokens:     usage.InputTokens,
+		OutputTokens:    usage.OutputTokens,
+		ResetTime:       usage.Date + " 23:59:59 UTC",
+	}
+}
+
+// formatUsage describes the day's usage for the chat
+func formatUsage(report usageReport) string {
+	return fmt.Sprintf("Current Bedrock usage today:\n"+
+		"- Cost: $%.2f / $%.2f\n"+
+		"- Remaining budget: $%.2f\n"+
+		"- Requests: %d\n"+
+		"- Tokens: %d input, %d output\n"+
+		"- Resets at: %s",
+		report.TotalCost, report.DailyCap, report.RemainingBudget, report.RequestCount,
+		report.InputTokens, report.OutputTokens, report.ResetTime)
+}
+
+// approvalMessage is the user message recorded when tools are approved without any other text
+func approvalMessage(tools []string) string {
+	return fmt.Sprintf("I approve using %s for this session. Please continue.", strings.Join(tools, ", "))
+}
+
+// mergeApprovals adds newly approved tools to the session's approvals, ignoring tools that do not
+// need consent
+func mergeApprovals(approved, newApprovals, consentTools []string) []string {
+	merged := slices.Clone(approved)
+	for _, name := range newApprovals {
+		if slices.Contains(consentTools, name) && !slices.Contains(merged, name) {
+			merged = append(merged, name)
+		}
+	}
+	return merged
+}
+
+// HandleAgentResponses consumes web action results handed back to the agent. Chat turns call
+// their tools synchronously through MCP, so the results are only logged.
+func (h *Handler) HandleAgentResponses(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	if h.responses == nil {
+		return events.SQSEventResponse{}, fmt.Errorf("agent response processing is not configured")
+	}
+	return h.responses.ProcessBatch(ctx, event, func(ctx context.Context, message *models.Message) error {
+		response, err := models.DecodePayload[*models.AgentResponsePayload](message)
+		if err != nil {
+			return err
+		}
+		h.logger.InfoContext(ctx, "agent response received",
+			slog.String("message_id", message.ID),
+			slog.String("created_by", message.CreatedBy),
+			slog.String("status", response.Status),
+			slog.String("message", response.Message),
+		)
+		return nil
+	})
+}
+
+// responseHeaders are the headers of every agent response; CORS headers are added by the cors
+// middleware the handler is started with
+func responseHeaders(contentType string) map[string]string {
+	return map[string]string{
+		"Content-Type": contentType,
+	}
+}
+
+// jsonResponse marshals body as the response
+func jsonResponse(status int, body interface{}) events.APIGatewayV2HTTPResponse {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		status = http.StatusInternalServerError
+		encoded = []byte(`{"error":"failed to marshal response"}`)
+	}
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: status,
+		Headers:    responseHeaders("application/json"),
+		Body:       string(encoded),
+	}
+}
+
+// errorResponseWithRetry is a JSON error response that tells a throttled client when to retry
+func errorResponseWithRetry(status int, message string) events.APIGatewayV2HTTPResponse {
+	response := errorResponse(status, message)
+	if status == http.StatusTooManyRequests {
+		response.Headers["Retry-After"] = "60"
+	}
+	return response
+}
+
+// errorResponse is a JSON error response
+func errorResponse(status int, message string) events.APIGatewayV2HTTPResponse {
+	return jsonResponse(status, errorBody{Error: message})
+}
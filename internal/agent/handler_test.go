@@ -0,0 +1,395 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/internal/scheduler"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+// fakeChatter asks for consent to book until the booking tool is approved
+type fakeChatter struct {
+	requests []*scheduler.ChatRequest
+	err      error
+}
+
+func (f *fakeChatter) Chat(ctx context.Context, req *scheduler.ChatRequest) (*scheduler.ChatResponse, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	response := &scheduler.ChatResponse{Message: "Booked 8:10 AM", InputTokens: 1000, OutputTokens: 100, Cost: 0.01}
+	if slices.Contains(req.ConsentTools, "golf_book_tee_time") && !slices.Contains(req.ApprovedTools, "golf_book_tee_time") {
+		response.Message = scheduler.ConsentPrompt([]string{"golf_book_tee_time"})
+		response.PendingConsent = []string{"golf_book_tee_time"}
+	}
+	if req.OnText != nil {
+		for _, word := range strings.SplitAfter(response.Message, " ") {
+			req.OnText(word)
+		}
+	}
+	response.Messages = []map[string]interface{}{
+		{"role": "user", "content": req.Message},
+		{"role": "assistant", "content": response.Message},
+	}
+	return response, nil
+}
+
+func newTestHandler(chat Chatter) (*Handler, *repository.MemoryAgentSessionRepository, *repository.MemoryAgentUsageRepository) {
+	cfg := &appconfig.Config{
+		Stage:                  models.StageDev,
+		ConsentRequiredTools:   []string{"golf_book_tee_time"},
+		A2AAPIKey:              "secret",
+		AgentSessionSigningKey: "signing-key",
+		AgentDailySpendingCap:  5,
+	}
+	sessions := repository.NewMemoryAgentSessionRepository(models.DefaultAgentSessionWindow)
+	usage := repository.NewMemoryAgentUsageRepository("dev")
+	return NewHandler(cfg, chat, sessions, usage, slog.New(slog.NewTextHandler(io.Discard, nil))), sessions, usage
+}
+
+func request(method, path, body string, headers map[string]string) events.APIGatewayV2HTTPRequest {
+	request := events.APIGatewayV2HTTPRequest{RawPath: path, Body: body, Headers: headers}
+	request.RequestContext.HTTP.Method = method
+	request.RequestContext.DomainName = "api.example.com"
+	return request
+}
+
+// uiRequest is a chat request from the chat UI, whose session is listed in the browser's cookie
+func uiRequest(body, sessionID string, headers map[string]string) events.APIGatewayV2HTTPRequest {
+	request := request(http.MethodPost, "/agent", body, headers)
+	request.Cookies = []string{signedCookie(sessionID)}
+	return request
+}
+
+// signedCookie is the session cookie the test handler issues for ids
+func signedCookie(ids ...string) string {
+	list := strings.Join(ids, ".")
+	return sessionCookie + "=" + list + "~" + signSessions("signing-key", list)
+}
+
+func decode(t *testing.T, response events.APIGatewayV2HTTPResponse) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+		t.Fatalf("response body %q is not JSON: %v", response.Body, err)
+	}
+	return body
+}
+
+func TestHandler_Card(t *testing.T) {
+	h, _, _ := newTestHandler(&fakeChatter{})
+
+	for _, path := range []string{"/agent/card", "/agent/.well-known/agent-card"} {
+		response, _ := h.HandleRequest(context.Background(), request(http.MethodGet, path, "", nil))
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s StatusCode = %d, want 200", path, response.StatusCode)
+		}
+		card := decode(t, response)
+		if card["url"] != "https://api.example.com/agent" {
+			t.Errorf("url = %v, want the API domain", card["url"])
+		}
+		if skills, _ := card["skills"].([]interface{}); len(skills) != len(Skills) {
+			t.Errorf("skills = %d, want %d", len(skills), len(Skills))
+		}
+		if endpoints, _ := card["endpoints"].(map[string]interface{}); endpoints["cancel"] != "https://api.example.com/agent/tasks/{request_id}/cancel" {
+			t.Errorf("cancel endpoint = %v, want the task cancellation URL", endpoints["cancel"])
+		}
+	}
+
+	h.config.AgentCardURL = "https://golf.example.com/agent/"
+	response, _ := h.HandleRequest(context.Background(), request(http.MethodGet, "/agent/card", "", nil))
+	if card := decode(t, response); card["url"] != "https://golf.example.com/agent" {
+		t.Errorf("url = %v, want the configured URL", card["url"])
+	}
+}
+
+func TestHandler_UI(t *testing.T) {
+	h, sessions, _ := newTestHandler(&fakeChatter{})
+	ctx := context.Background()
+	for _, id := range []string{"s1", "s2", "other"} {
+		if _, err := sessions.AppendMessages(ctx, id, []map[string]interface{}{
+			{"role": "user", "content": "Tee times at " + id + "?"},
+			{"role": "assistant", "content": "<b>8:10 AM</b>"},
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cookies := []string{signedCookie("s1", "s2", "gone")}
+
+	// Without a session a new conversation starts, listing only this browser's sessions
+	ui := request(http.MethodGet, "/agent/ui", "", nil)
+	ui.Cookies = cookies
+	response, _ := h.HandleRequest(ctx, ui)
+	if response.StatusCode != http.StatusOK || !strings.HasPrefix(response.Headers["Content-Type"], "text/html") {
+		t.Fatalf("GET /agent/ui = %d %s, want 200 text/html", response.StatusCode, response.Headers["Content-Type"])
+	}
+	if !strings.Contains(response.Body, "Tee times at s1?") || !strings.Contains(response.Body, "Tee times at s2?") || strings.Contains(response.Body, "Tee times at other?") {
+		t.Error("session list is not the browser's sessions")
+	}
+	if !strings.Contains(response.Body, "What would you like to do today?") {
+		t.Error("new conversation does not greet the user")
+	}
+	if len(response.Cookies) != 1 || !strings.Contains(response.Cookies[0], ".s1.s2~") || !strings.Contains(response.Cookies[0], "SameSite=Lax") {
+		t.Errorf("Cookies = %v, want the new session ahead of s1 and s2, without the missing one", response.Cookies)
+	}
+	issued, _, _ := strings.Cut(strings.TrimPrefix(response.Cookies[0], sessionCookie+"="), ".")
+	if !strings.HasPrefix(issued, "session_") || len(issued) != len("session_")+32 {
+		t.Errorf("new session ID = %q, want 128 random bits", issued)
+	}
+
+	// Resuming renders the session's messages, escaped, and moves it to the front
+	ui.QueryStringParameters = map[string]string{"session": "s2"}
+	response, _ = h.HandleRequest(ctx, ui)
+	if !strings.Contains(response.Body, "&lt;b&gt;8:10 AM&lt;/b&gt;") || strings.Contains(response.Body, "What would you like to do today?") {
+		t.Error("resumed session's messages are not rendered")
+	}
+	if !strings.HasPrefix(response.Cookies[0], signedCookie("s2", "s1")+";") {
+		t.Errorf("Cookies = %v, want s2 first", response.Cookies)
+	}
+
+	// Another session, or one listed in a cookie this handler did not sign, cannot be opened
+	ui.QueryStringParameters = map[string]string{"session": "other"}
+	if response, _ = h.HandleRequest(ctx, ui); response.StatusCode != http.StatusNotFound || strings.Contains(response.Body, "Tee times at other?") {
+		t.Errorf("GET /agent/ui?session=other = %d, want 404", response.StatusCode)
+	}
+	ui.Cookies = []string{"agent_sessions=other~forged", "agent_sessions=other"}
+	if response, _ = h.HandleRequest(ctx, ui); response.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /agent/ui?session=other with a forged cookie = %d, want 404", response.StatusCode)
+	}
+}
+
+func TestHandler_DeleteSession(t *testing.T) {
+	h, sessions, _ := newTestHandler(&fakeChatter{})
+	ctx := context.Background()
+	for _, id := range []string{"s1", "other"} {
+		if _, err := sessions.AppendMessages(ctx, id, []map[string]interface{}{{"role": "user", "content": "hi"}}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only sessions listed in the browser's cookie can be deleted
+	remove := request(http.MethodPost, "/agent/ui/delete", "session_id=other", map[string]string{"content-type": "application/x-www-form-urlencoded"})
+	remove.Cookies = []string{signedCookie("s1")}
+	if response, _ := h.HandleRequest(ctx, remove); response.StatusCode != http.StatusNotFound {
+		t.Errorf("deleting an unlisted session = %d, want 404", response.StatusCode)
+	}
+	forged := request(http.MethodPost, "/agent/ui/delete", "session_id=other", map[string]string{"content-type": "application/x-www-form-urlencoded"})
+	forged.Cookies = []string{"agent_sessions=other"}
+	if response, _ := h.HandleRequest(ctx, forged); response.StatusCode != http.StatusNotFound {
+		t.Errorf("deleting with an unsigned cookie = %d, want 404", response.StatusCode)
+	}
+
+	remove.Body = base64.StdEncoding.EncodeToString([]byte("session_id=s1"))
+	remove.IsBase64Encoded = true
+	response, _ := h.HandleRequest(ctx, remove)
+	if response.StatusCode != http.StatusSeeOther || response.Headers["Location"] != "/agent/ui" {
+		t.Fatalf("delete = %d %s, want a redirect to the UI", response.StatusCode, response.Headers["Location"])
+	}
+	if !strings.HasPrefix(response.Cookies[0], signedCookie()+";") {
+		t.Errorf("Cookies = %v, want s1 removed", response.Cookies)
+	}
+	if session, _ := sessions.GetSession(ctx, "s1"); session != nil {
+		t.Error("session s1 was not deleted")
+	}
+	if session, _ := sessions.GetSession(ctx, "other"); session == nil {
+		t.Error("unlisted session was deleted")
+	}
+}
+
+func TestHandler_ChatConsent(t *testing.T) {
+	chat := &fakeChatter{}
+	h, sessions, usage := newTestHandler(chat)
+	ctx := context.Background()
+
+	response, _ := h.HandleRequest(ctx, uiRequest(`{"message":"Book 8:10 at Birdsfoot","session_id":"s1"}`, "s1", nil))
+	body := decode(t, response)
+	if body["status"] != "pending" {
+		t.Fatalf("status = %v, want pending", body["status"])
+	}
+	if consent, _ := body["consent_required"].(map[string]interface{}); consent == nil || len(consent["tools"].([]interface{})) != 1 {
+		t.Errorf("consent_required = %v, want golf_book_tee_time", body["consent_required"])
+	}
+
+	// Approving without a message continues the conversation, and the approval sticks to the session
+	response, _ = h.HandleRequest(ctx, uiRequest(`{"session_id":"s1","approve_tools":["golf_book_tee_time","get_weather"]}`, "s1", nil))
+	body = decode(t, response)
+	if body["status"] != "success" || body["message"] != "Booked 8:10 AM" {
+		t.Fatalf("response = %v, want the booking confirmed", body)
+	}
+	last := chat.requests[1]
+	if last.Message != approvalMessage([]string{"golf_book_tee_time", "get_weather"}) || len(last.History) != 2 {
+		t.Errorf("second turn = %q with %d history messages, want the approval after the first turn", last.Message, len(last.History))
+	}
+
+	session, err := sessions.GetSession(ctx, "s1")
+	if err != nil || session == nil {
+		t.Fatalf("GetSession() = %v, %v", session, err)
+	}
+	if !slices.Equal(session.ApprovedTools, []string{"golf_book_tee_time"}) {
+		t.Errorf("ApprovedTools = %v, want only the consent-gated tool", session.ApprovedTools)
+	}
+	if len(session.Messages) != 4 {
+		t.Errorf("session messages = %d, want both turns", len(session.Messages))
+	}
+
+	recorded, _ := usage.GetUsage(ctx, time.Now().UTC().Format("2006-01-02"))
+	if recorded.RequestCount != 2 || recorded.InputTokens != 2000 {
+		t.Errorf("usage = %d requests %d input tokens, want both turns", recorded.RequestCount, recorded.InputTokens)
+	}
+}
+
+func TestHandler_AgentRequests(t *testing.T) {
+	chat := &fakeChatter{}
+	h, _, _ := newTestHandler(chat)
+	withContext := `{"message":"Book 8:10 at Birdsfoot","agent_context":{"agent_id":"planner","request_id":"r1"}}`
+	withoutContext := `{"message":"Book 8:10 at Birdsfoot","session_id":"s1"}`
+	forged := request(http.MethodPost, "/agent", withoutContext, nil)
+	forged.Cookies = []string{sessionCookie + "=s1"}
+
+	// Only the chat UI's own sessions may chat without the A2A key, whatever the body says
+	tests := []struct {
+		name       string
+		request    events.APIGatewayV2HTTPRequest
+		wantStatus int
+	}{
+		{"missing key", request(http.MethodPost, "/agent", withContext, nil), http.StatusUnauthorized},
+		{"missing key without agent_context", request(http.MethodPost, "/agent", withoutContext, nil), http.StatusUnauthorized},
+		{"session not in the browser's cookie", uiRequest(withoutContext, "s2", nil), http.StatusUnauthorized},
+		{"unsigned session cookie", forged, http.StatusUnauthorized},
+		{"wrong key", request(http.MethodPost, "/agent", withContext, map[string]string{"x-api-key": "guess"}), http.StatusUnauthorized},
+		{"wrong key from the UI", uiRequest(withoutContext, "s1", map[string]string{"x-api-key": "guess"}), http.StatusUnauthorized},
+		{"valid key", request(http.MethodPost, "/agent", withContext, map[string]string{"x-api-key": "secret"}), http.StatusOK},
+		{"valid key without agent_context", request(http.MethodPost, "/agent", withoutContext, map[string]string{"x-api-key": "secret"}), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, _ := h.HandleRequest(context.Background(), tt.request)
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	// Agents cannot answer consent prompts, so their requests skip them
+	if len(chat.requests) != 2 || len(chat.requests[0].ConsentTools) != 0 || len(chat.requests[1].ConsentTools) != 0 {
+		t.Fatalf("chat requests = %d, want two with no consent tools", len(chat.requests))
+	}
+
+	// The UI's user is asked, even if the body claims to come from an agent
+	response, _ := h.HandleRequest(context.Background(), uiRequest(`{"message":"Book 8:10","session_id":"s1","agent_context":{"agent_id":"planner"}}`, "s1", nil))
+	if body := decode(t, response); body["status"] != "pending" {
+		t.Errorf("status = %v, want pending consent", body["status"])
+	}
+}
+
+// blockingChatter runs until its context ends, like a turn waiting on Bedrock
+type blockingChatter struct {
+	started chan struct{}
+}
+
+func (b *blockingChatter) Chat(ctx context.Context, req *scheduler.ChatRequest) (*scheduler.ChatResponse, error) {
+	close(b.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestHandler_CancelTask(t *testing.T) {
+	chat := &blockingChatter{started: make(chan struct{})}
+	h, sessions, _ := newTestHandler(chat)
+	h.SetTaskRepository(repository.NewMemoryAgentTaskRepository())
+	h.taskPollInterval = 10 * time.Millisecond
+	ctx := context.Background()
+	key := map[string]string{"x-api-key": "secret"}
+
+	responses := make(chan events.APIGatewayV2HTTPResponse)
+	go func() {
+		body := `{"message":"Book 8:10 at Birdsfoot","session_id":"s1","agent_context":{"agent_id":"planner","request_id":"r1"}}`
+		response, _ := h.HandleRequest(ctx, request(http.MethodPost, "/agent", body, key))
+		responses <- response
+	}()
+	<-chat.started
+
+	response, _ := h.HandleRequest(ctx, request(http.MethodPost, "/agent/tasks/r1/cancel", "", nil))
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("cancel without the A2A key StatusCode = %d, want 401", response.StatusCode)
+	}
+	response, _ = h.HandleRequest(ctx, request(http.MethodPost, "/agent/tasks/r1/cancel", "", key))
+	if body := decode(t, response); response.StatusCode != http.StatusAccepted || body["task_id"] != "r1" {
+		t.Fatalf("cancel = %d %v, want 202 for r1", response.StatusCode, body)
+	}
+
+	select {
+	case response = <-responses:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the task kept running after it was cancelled")
+	}
+	body := decode(t, response)
+	if response.StatusCode != http.StatusOK || body["status"] != "cancelled" {
+		t.Errorf("chat = %d %v, want status cancelled", response.StatusCode, body["status"])
+	}
+	if metadata, _ := body["metadata"].(map[string]interface{}); metadata["request_id"] != "r1" {
+		t.Errorf("metadata = %v, want request_id r1", body["metadata"])
+	}
+	if session, _ := sessions.GetSession(ctx, "s1"); session != nil {
+		t.Error("cancelled turn was saved to the session")
+	}
+}
+
+func TestHandler_SpendingCap(t *testing.T) {
+	chat := &fakeChatter{}
+	h, _, usage := newTestHandler(chat)
+	ctx := context.Background()
+	if _, err := usage.AddUsage(ctx, time.Now().UTC().Format("2006-01-02"), 100000, 50000, 4.99); err != nil {
+		t.Fatal(err)
+	}
+
+	response, _ := h.HandleRequest(ctx, uiRequest(`{"message":"Find a tee time","session_id":"s1"}`, "s1", nil))
+	if response.StatusCode != http.StatusTooManyRequests || response.Headers["Retry-After"] != "86400" {
+		t.Errorf("response = %d Retry-After %q, want 429 until tomorrow", response.StatusCode, response.Headers["Retry-After"])
+	}
+	if len(chat.requests) != 0 {
+		t.Error("chat ran past the spending cap")
+	}
+
+	// Usage questions are answered without calling the model
+	response, _ = h.HandleRequest(ctx, uiRequest(`{"message":"Usage","session_id":"s1"}`, "s1", nil))
+	body := decode(t, response)
+	if response.StatusCode != http.StatusOK || !strings.Contains(body["message"].(string), "$4.99 / $5.00") {
+		t.Errorf("usage response = %d %v, want today's usage", response.StatusCode, body["message"])
+	}
+}
+
+func TestHandler_ChatErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"throttled", apperrors.Wrap(apperrors.ErrThrottled, io.EOF), http.StatusTooManyRequests},
+		{"timeout", context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{"other", io.ErrUnexpectedEOF, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, _ := newTestHandler(&fakeChatter{err: tt.err})
+			response, _ := h.HandleRequest(context.Background(), uiRequest(`{"message":"hi","session_id":"s1"}`, "s1", nil))
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
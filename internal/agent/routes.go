@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/health"
+	"github.com/jrzesz33/rez_agent/internal/openapi"
+)
+
+// route is an endpoint of the agent: its documentation and the handler it dispatches to
+type route struct {
+	openapi.Route
+	handle func(h *Handler, ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error)
+}
+
+// errorBody is the body of the agent's error responses
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// deleteSessionForm is the form POST /agent/ui/delete submits
+type deleteSessionForm struct {
+	SessionID string `json:"session_id"`
+}
+
+// routes are the agent's endpoints in dispatch order
+var routes = []route{
+	{
+		Route:  openapi.Route{Method: http.MethodGet, Path: "/agent/card", Tag: "agent", Summary: "Get the A2A agent card", Response: map[string]any{}, Errors: []int{http.StatusInternalServerError}, ErrorBody: errorBody{}, Public: true},
+		handle: (*Handler).handleCard,
+	},
+	{
+		Route:  openapi.Route{Method: http.MethodGet, Path: "/agent/.well-known/agent-card", Tag: "agent", Summary: "Discover the A2A agent card", Response: map[string]any{}, Errors: []int{http.StatusInternalServerError}, ErrorBody: errorBody{}, Public: true},
+		handle: (*Handler).handleCard,
+	},
+	{
+		Route: openapi.Route{Method: http.MethodGet, Path: "/agent/health", Tag: "agent", Summary: "Check the agent's dependencies",
+			Description: "Answers 503 with the same report when a dependency is unhealthy.",
+			Response:    health.Report{}, Errors: []int{http.StatusServiceUnavailable}, ErrorBody: health.Report{}, Public: true},
+		handle: func(h *Handler, ctx context.Context, _ events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+			return h.handleHealth(ctx)
+		},
+	},
+	{
+		Route:  openapi.Route{Method: http.MethodGet, Path: "/agent/ui", Tag: "agent", Summary: "Open the chat UI", Response: "", ResponseContentType: "text/html", Errors: []int{http.StatusInternalServerError}, ErrorBody: errorBody{}, Public: true},
+		handle: (*Handler).handleUI,
+	},
+	{
+		Route: openapi.Route{Method: http.MethodPost, Path: "/agent/ui/delete", Tag: "agent", Summary: "Delete one of the browser's chat sessions",
+			Description: "Only sessions listed in the browser's session cookie can be deleted. Redirects to the chat UI.",
+			Request:     deleteSessionForm{}, RequestContentType: "application/x-www-form-urlencoded", Status: http.StatusSeeOther,
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError}, ErrorBody: errorBody{}, Public: true},
+		handle: (*Handler).handleDeleteSession,
+	},
+	{
+		Route: openapi.Route{Method: http.MethodPost, Path: "/agent", Tag: "agent", Summary: "Send the golf assistant a chat message",
+			Description: "Callers other than the chat UI must send the A2A key as X-API-Key. Asking about cost, usage, spending or budget answers with the day's Bedrock usage. A request that accepts text/event-stream is answered with server-sent events.",
+			Request:     chatRequest{}, Response: chatResponse{},
+			Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusGatewayTimeout}, ErrorBody: errorBody{}},
+		handle: (*Handler).handleChat,
+	},
+	{
+		Route: openapi.Route{Method: http.MethodPost, Path: "/agent/tasks/{id}/cancel", Tag: "agent", Summary: "Cancel another agent's chat task",
+			Description: "The task is the chat request sent with agent_context.request_id {id}; it is answered with status \"cancelled\". Requires the A2A key as X-API-Key.",
+			Response:    taskCancellation{}, Status: http.StatusAccepted,
+			Errors: []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError}, ErrorBody: errorBody{}},
+		handle: (*Handler).handleCancelTask,
+	},
+}
+
+// APIRoutes documents the agent's endpoints, which share the web API's API Gateway
+func APIRoutes() []openapi.Route {
+	documented := make([]openapi.Route, 0, len(routes))
+	for _, r := range routes {
+		documented = append(documented, r.Route)
+	}
+	return documented
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+)
+
+// Server-sent events of a streamed chat turn
+const (
+	// eventToken carries text as the model generates it
+	eventToken = "token"
+	// eventDone carries the turn's response, the body POST /agent returns without streaming
+	eventDone = "done"
+	// eventError carries a failed turn's status and message
+	eventError = "error"
+)
+
+// HandleStream serves requests made through the agent's function URL, whose responses Lambda
+// streams. A chat request that accepts text/event-stream gets the model's text as it is
+// generated; every other request is answered by HandleRequest in one piece.
+func (h *Handler) HandleStream(ctx context.Context, request events.APIGatewayV2HTTPRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+	if request.RequestContext.HTTP.Method != http.MethodPost || request.RawPath != "/agent" || !acceptsEvents(request) {
+		response, err := h.HandleRequest(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		return &events.LambdaFunctionURLStreamingResponse{
+			StatusCode: response.StatusCode,
+			Headers:    response.Headers,
+			Body:       strings.NewReader(response.Body),
+			Cookies:    response.Cookies,
+		}, nil
+	}
+
+	ctx = logging.WithCorrelationID(ctx, logging.IncomingCorrelationID(request.Headers["x-correlation-id"]))
+	turn, rejected := h.prepareChat(ctx, request)
+	if rejected != nil {
+		return &events.LambdaFunctionURLStreamingResponse{
+			StatusCode: rejected.StatusCode,
+			Headers:    rejected.Headers,
+			Body:       strings.NewReader(rejected.Body),
+		}, nil
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		h.streamChat(ctx, turn, writer)
+		_ = writer.Close()
+	}()
+	return &events.LambdaFunctionURLStreamingResponse{
+		StatusCode: http.StatusOK,
+		Headers:    eventStreamHeaders(),
+		Body:       reader,
+	}, nil
+}
+
+// streamChat runs a turn, writing its text to w as token events and finishing with a done or
+// error event. The turn runs to the end even if the client goes away, so it is still recorded
+// and saved to the session.
+func (h *Handler) streamChat(ctx context.Context, turn *chatTurn, w io.Writer) {
+	connected := true
+	send := func(event string, data interface{}) {
+		if !connected {
+			return
+		}
+		if err := writeEvent(w, event, data); err != nil {
+			h.logger.WarnContext(ctx, "chat stream closed by the client", slog.String("error", err.Error()))
+			connected = false
+		}
+	}
+
+	turn.chat.OnText = func(text string) {
+		send(eventToken, map[string]string{"text": text})
+	}
+	response, err := h.runChat(ctx, turn)
+	if err != nil {
+		status, message := h.chatFailure(ctx, err)
+		send(eventError, map[string]interface{}{"status": status, "error": message})
+		return
+	}
+	send(eventDone, response)
+}
+
+// writeEvent writes one server-sent event with a JSON payload
+func writeEvent(w io.Writer, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", event, err)
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
+
+// acceptsEvents reports whether the client asked for server-sent events
+func acceptsEvents(request events.APIGatewayV2HTTPRequest) bool {
+	return strings.Contains(request.Headers["accept"], "text/event-stream")
+}
+
+// eventStreamHeaders are the headers of a server-sent event response
+func eventStreamHeaders() map[string]string {
+	headers := responseHeaders("text/event-stream")
+	headers["Cache-Control"] = "no-cache"
+	return headers
+}
@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// serverEvent is one parsed server-sent event
+type serverEvent struct {
+	name string
+	data map[string]interface{}
+}
+
+func readEvents(t *testing.T, r io.Reader) []serverEvent {
+	t.Helper()
+	var parsed []serverEvent
+	var current serverEvent
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &current.data); err != nil {
+				t.Fatalf("event data %q is not JSON: %v", line, err)
+			}
+		case line == "":
+			parsed = append(parsed, current)
+			current = serverEvent{}
+		}
+	}
+	return parsed
+}
+
+func TestHandler_HandleStream(t *testing.T) {
+	chat := &fakeChatter{}
+	h, sessions, _ := newTestHandler(chat)
+	h.config.ConsentRequiredTools = nil
+	ctx := context.Background()
+	headers := map[string]string{"accept": "text/event-stream"}
+
+	response, err := h.HandleStream(ctx, uiRequest(`{"message":"Book 8:10","session_id":"s1"}`, "s1", headers))
+	if err != nil {
+		t.Fatalf("HandleStream() error = %v", err)
+	}
+	if response.Headers["Content-Type"] != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", response.Headers["Content-Type"])
+	}
+
+	parsed := readEvents(t, response.Body)
+	if len(parsed) != 4 {
+		t.Fatalf("events = %v, want three tokens and done", parsed)
+	}
+	var text strings.Builder
+	for _, event := range parsed[:3] {
+		if event.name != eventToken {
+			t.Fatalf("event = %s, want %s", event.name, eventToken)
+		}
+		text.WriteString(event.data["text"].(string))
+	}
+	if text.String() != "Booked 8:10 AM" {
+		t.Errorf("streamed text = %q", text.String())
+	}
+	done := parsed[3]
+	if done.name != eventDone || done.data["message"] != "Booked 8:10 AM" || done.data["session_id"] != "s1" {
+		t.Errorf("last event = %s %v, want done with the response", done.name, done.data)
+	}
+
+	// The streamed turn is saved like any other
+	if session, _ := sessions.GetSession(ctx, "s1"); session == nil || len(session.Messages) != 2 {
+		t.Errorf("session = %v, want the streamed turn saved", session)
+	}
+}
+
+func TestHandler_HandleStreamFallsBack(t *testing.T) {
+	h, _, _ := newTestHandler(&fakeChatter{})
+	ctx := context.Background()
+
+	// Other routes are answered in one piece
+	response, err := h.HandleStream(ctx, request(http.MethodGet, "/agent/card", "", nil))
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("GET /agent/card = %v, %v", response, err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	if !strings.Contains(string(body), `"skills"`) {
+		t.Errorf("body = %s, want the agent card", body)
+	}
+
+	// Rejected chat requests get their JSON error rather than a stream
+	response, _ = h.HandleStream(ctx, uiRequest(`{"session_id":"s1"}`, "s1", map[string]string{"accept": "text/event-stream"}))
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("StatusCode = %d, want 400 for a missing message", response.StatusCode)
+	}
+}
+
+func TestHandler_ChatEventsThroughAPIGateway(t *testing.T) {
+	h, _, _ := newTestHandler(&fakeChatter{err: io.ErrUnexpectedEOF})
+
+	response, _ := h.HandleRequest(context.Background(), uiRequest(`{"message":"hi","session_id":"s1"}`, "s1", map[string]string{"accept": "text/event-stream"}))
+	if response.StatusCode != http.StatusOK || response.Headers["Content-Type"] != "text/event-stream" {
+		t.Fatalf("response = %d %s, want a buffered event stream", response.StatusCode, response.Headers["Content-Type"])
+	}
+	parsed := readEvents(t, strings.NewReader(response.Body))
+	if len(parsed) != 1 || parsed[0].name != eventError || parsed[0].data["status"] != float64(http.StatusInternalServerError) {
+		t.Errorf("events = %v, want one error event", parsed)
+	}
+}
@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+//go:embed ui/*.tmpl
+var uiFiles embed.FS
+
+// chatUI renders GET /agent/ui: the chat, with the browser's previous sessions alongside
+var chatUI = template.Must(template.ParseFS(uiFiles, "ui/*.tmpl"))
+
+const (
+	// sessionCookie lists the sessions this browser was issued, most recently opened first, signed
+	// with the session signing key. Sessions carry no owner, so the cookie is what entitles a
+	// browser to resume, delete and chat in them, and the chat UI lists only these rather than
+	// every session in the table.
+	sessionCookie = "agent_sessions"
+	// maxListedSessions is the most sessions the cookie keeps
+	maxListedSessions = 20
+	// sessionTitleLength is the most characters of a session's first message used as its title
+	sessionTitleLength = 60
+)
+
+// listableSessionID matches the session IDs the cookie can carry
+var listableSessionID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// sessionSummary is a previous session in the chat UI's list
+type sessionSummary struct {
+	ID           string
+	Title        string
+	Updated      string
+	updatedAt    string
+	MessageCount int
+	Current      bool
+}
+
+// uiMessage is a message of the open session
+type uiMessage struct {
+	Role    string
+	Content string
+}
+
+// uiPage is the data of the chat UI template
+type uiPage struct {
+	SessionID string
+	// Listed reports whether the open session is already in Sessions
+	Listed   bool
+	Sessions []sessionSummary
+	Messages []uiMessage
+}
+
+// handleUI renders the chat UI. ?session= resumes one of the sessions in the browser's session
+// cookie; without it a new one is started. The open session moves to the front of the cookie.
+func (h *Handler) handleUI(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.config.AgentSessionSigningKey == "" {
+		return errorResponse(http.StatusNotFound, "chat UI is not enabled"), nil
+	}
+
+	browser := h.browserSessions(request)
+	current, resume := request.QueryStringParameters["session"]
+	if resume && !slices.Contains(browser, current) {
+		return errorResponse(http.StatusNotFound, "session not found"), nil
+	}
+	if !resume {
+		var err error
+		if current, err = newSessionID(); err != nil {
+			h.logger.ErrorContext(ctx, "failed to start agent session", slog.String("error", err.Error()))
+			return errorResponse(http.StatusInternalServerError, "failed to start a session"), nil
+		}
+	}
+	ids := append([]string{current}, slices.DeleteFunc(browser, func(id string) bool { return id == current })...)
+
+	page := uiPage{SessionID: current}
+	kept := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if len(kept) == maxListedSessions {
+			break
+		}
+		session, err := h.sessions.GetSession(ctx, id)
+		if err != nil {
+			// Keep a session that could not be read; it is listed again once it can be
+			h.logger.WarnContext(ctx, "failed to load agent session", slog.String("session_id", id), slog.String("error", err.Error()))
+			kept = append(kept, id)
+			continue
+		}
+		if session == nil {
+			// Expired or deleted sessions drop out of the cookie, except a new one about to be saved
+			if id == current {
+				kept = append(kept, id)
+			}
+			continue
+		}
+		kept = append(kept, id)
+		page.Sessions = append(page.Sessions, summarizeSession(session, id == current))
+		if id == current {
+			page.Listed = true
+			page.Messages = uiMessages(session)
+		}
+	}
+	slices.SortStableFunc(page.Sessions, func(a, b sessionSummary) int {
+		return strings.Compare(b.updatedAt, a.updatedAt)
+	})
+
+	var body bytes.Buffer
+	if err := chatUI.ExecuteTemplate(&body, "chat.tmpl", page); err != nil {
+		h.logger.ErrorContext(ctx, "failed to render chat UI", slog.String("error", err.Error()))
+		return errorResponse(http.StatusInternalServerError, "failed to render chat UI"), nil
+	}
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "text/html; charset=utf-8", "Cache-Control": "no-store"},
+		Body:       body.String(),
+		Cookies:    []string{h.sessionCookie(kept)},
+	}, nil
+}
+
+// handleDeleteSession deletes a session listed in the browser's session cookie and returns to a
+// new conversation. The cookie is SameSite, so other sites cannot delete sessions through it.
+func (h *Handler) handleDeleteSession(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	if h.config.AgentSessionSigningKey == "" {
+		return errorResponse(http.StatusNotFound, "chat UI is not enabled"), nil
+	}
+	body := request.Body
+	if request.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, "invalid request body"), nil
+		}
+		body = string(decoded)
+	}
+	form, err := url.ParseQuery(body)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, "invalid request body"), nil
+	}
+	sessionID := form.Get("session_id")
+
+	ids := h.browserSessions(request)
+	if !slices.Contains(ids, sessionID) {
+		return errorResponse(http.StatusNotFound, "session not found"), nil
+	}
+	if err := h.sessions.DeleteSession(ctx, sessionID); err != nil {
+		h.logger.ErrorContext(ctx, "failed to delete agent session", slog.String("session_id", sessionID), slog.String("error", err.Error()))
+		return errorResponse(http.StatusInternalServerError, "failed to delete session"), nil
+	}
+	h.logger.InfoContext(ctx, "deleted agent session", slog.String("session_id", sessionID))
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusSeeOther,
+		Headers:    map[string]string{"Location": "/agent/ui"},
+		Cookies:    []string{h.sessionCookie(slices.DeleteFunc(ids, func(id string) bool { return id == sessionID }))},
+	}, nil
+}
+
+// browserSessions returns the session IDs in the request's session cookie. A cookie whose
+// signature does not match lists none, so a client cannot claim a session it was not issued.
+func (h *Handler) browserSessions(request events.APIGatewayV2HTTPRequest) []string {
+	key := h.config.AgentSessionSigningKey
+	if key == "" {
+		return nil
+	}
+
+	var ids []string
+	for _, line := range request.Cookies {
+		cookies, err := http.ParseCookie(line)
+		if err != nil {
+			continue
+		}
+		for _, cookie := range cookies {
+			if cookie.Name != sessionCookie {
+				continue
+			}
+			list, signature, ok := strings.Cut(cookie.Value, "~")
+			if !ok || !hmac.Equal([]byte(signature), []byte(signSessions(key, list))) {
+				continue
+			}
+			for _, id := range strings.Split(list, ".") {
+				if listableSessionID.MatchString(id) && !slices.Contains(ids, id) {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// sessionCookie is the Set-Cookie value listing ids; it lasts as long as an idle session
+func (h *Handler) sessionCookie(ids []string) string {
+	ttl := h.config.AgentSessionWindow.TTL
+	if ttl <= 0 {
+		ttl = models.DefaultAgentSessionWindow.TTL
+	}
+	list := strings.Join(ids, ".")
+	cookie := &http.Cookie{
+		Name:     sessionCookie,
+		Value:    list + "~" + signSessions(h.config.AgentSessionSigningKey, list),
+		Path:     "/agent",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	return cookie.String()
+}
+
+// signSessions signs the session list of a session cookie
+func signSessions(key, list string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte("agent-sessions:" + list))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newSessionID generates the unguessable ID of a new session
+func newSessionID() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return "session_" + hex.EncodeToString(random), nil
+}
+
+// summarizeSession titles a session with its first user message
+func summarizeSession(session *models.AgentSession, current bool) sessionSummary {
+	summary := sessionSummary{
+		ID:           session.SessionID,
+		Title:        "New conversation",
+		updatedAt:    session.UpdatedAt,
+		MessageCount: len(session.Messages),
+		Current:      current,
+	}
+	if updated, err := time.Parse(time.RFC3339, session.UpdatedAt); err == nil {
+		summary.Updated = updated.Format("Jan 2 15:04 UTC")
+	}
+	for _, message := range session.Messages {
+		content, _ := message["content"].(string)
+		if message["role"] != "user" || content == "" {
+			continue
+		}
+		if title := []rune(content); len(title) > sessionTitleLength {
+			content = string(title[:sessionTitleLength]) + "…"
+		}
+		summary.Title = content
+		break
+	}
+	return summary
+}
+
+// uiMessages returns a session's user and assistant text messages
+func uiMessages(session *models.AgentSession) []uiMessage {
+	messages := make([]uiMessage, 0, len(session.Messages))
+	for _, message := range session.Messages {
+		role, _ := message["role"].(string)
+		content, _ := message["content"].(string)
+		if (role == "user" || role == "assistant") && content != "" {
+			messages = append(messages, uiMessage{Role: role, Content: content})
+		}
+	}
+	return messages
+}
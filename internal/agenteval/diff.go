@@ -0,0 +1,206 @@
+package agenteval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jrzesz33/rez_agent/internal/scheduler"
+)
+
+// bookingTool is the tool whose successful call means the run booked (or held) a tee time
+const bookingTool = "golf_book_tee_time"
+
+// Outcome is what a run did, reduced to what a prompt change should be judged on
+type Outcome struct {
+	ToolCalls     []string
+	Booked        bool
+	Notified      bool
+	FinalResponse string
+	Error         string
+	InputTokens   int64
+	OutputTokens  int64
+}
+
+// RecordedOutcome returns the outcome of the recorded run
+func RecordedOutcome(recording *scheduler.RunRecording) Outcome {
+	outcome := Outcome{
+		FinalResponse: recording.FinalResponse,
+		Error:         recording.Error,
+		InputTokens:   recording.InputTokens,
+		OutputTokens:  recording.OutputTokens,
+	}
+	terminal := terminalTools(recording)
+	for _, call := range recording.ToolCalls {
+		outcome.add(call.Name, call.Failed, terminal)
+	}
+	return outcome
+}
+
+// ReplayOutcome returns the outcome of a replay of recording
+func ReplayOutcome(recording *scheduler.RunRecording, replay *scheduler.RunSummary) Outcome {
+	outcome := Outcome{
+		FinalResponse: replay.FinalResponse,
+		Error:         replay.Error,
+		InputTokens:   replay.InputTokens,
+		OutputTokens:  replay.OutputTokens,
+	}
+	terminal := terminalTools(recording)
+	for _, call := range replay.ToolCalls {
+		outcome.add(call.Name, call.Failed, terminal)
+	}
+	return outcome
+}
+
+// add records a tool call
+func (o *Outcome) add(name string, failed bool, terminal map[string]bool) {
+	o.ToolCalls = append(o.ToolCalls, name)
+	if failed {
+		return
+	}
+	if name == bookingTool {
+		o.Booked = true
+	}
+	if terminal[name] {
+		o.Notified = true
+	}
+}
+
+// terminalTools returns the names and aliases of the recording's terminal tools
+func terminalTools(recording *scheduler.RunRecording) map[string]bool {
+	terminal := make(map[string]bool)
+	for _, tool := range recording.Tools {
+		if tool.IsTerminal() {
+			for _, name := range tool.Names() {
+				terminal[name] = true
+			}
+		}
+	}
+	return terminal
+}
+
+// Comparison is the difference between a recorded run and its replay
+type Comparison struct {
+	ExecutionID string
+	ScheduleID  string
+	Recorded    Outcome
+	Replayed    Outcome
+
+	// SequenceDiff lists both tool call sequences merged, prefixed "  " for calls in both,
+	// "- " for calls only in the recording and "+ " for calls only in the replay
+	SequenceDiff []string
+
+	// Unmatched are replayed calls the recording had no result for
+	Unmatched []string
+}
+
+// Compare compares a recorded run with its replay
+func Compare(recording *scheduler.RunRecording, replay *scheduler.RunSummary, unmatched []string) Comparison {
+	recorded := RecordedOutcome(recording)
+	replayed := ReplayOutcome(recording, replay)
+	return Comparison{
+		ExecutionID:  recording.ExecutionID,
+		ScheduleID:   recording.ScheduleID,
+		Recorded:     recorded,
+		Replayed:     replayed,
+		SequenceDiff: diffSequences(recorded.ToolCalls, replayed.ToolCalls),
+		Unmatched:    unmatched,
+	}
+}
+
+// SequenceChanged reports whether the replay called different tools, or in a different order
+func (c Comparison) SequenceChanged() bool {
+	for _, line := range c.SequenceDiff {
+		if !strings.HasPrefix(line, "  ") {
+			return true
+		}
+	}
+	return false
+}
+
+// OutcomeChanged reports whether the replay booked, notified, or failed differently
+func (c Comparison) OutcomeChanged() bool {
+	return c.Recorded.Booked != c.Replayed.Booked ||
+		c.Recorded.Notified != c.Replayed.Notified ||
+		(c.Recorded.Error == "") != (c.Replayed.Error == "")
+}
+
+// Changed reports whether the replay diverged from the recording
+func (c Comparison) Changed() bool {
+	return c.SequenceChanged() || c.OutcomeChanged() || len(c.Unmatched) > 0
+}
+
+// String renders the comparison for a terminal
+func (c Comparison) String() string {
+	var b strings.Builder
+	status := "SAME"
+	if c.Changed() {
+		status = "CHANGED"
+	}
+	fmt.Fprintf(&b, "=== %s %s (schedule %s)\n", status, c.ExecutionID, c.ScheduleID)
+	fmt.Fprintf(&b, "booked:   %t -> %t\n", c.Recorded.Booked, c.Replayed.Booked)
+	fmt.Fprintf(&b, "notified: %t -> %t\n", c.Recorded.Notified, c.Replayed.Notified)
+	fmt.Fprintf(&b, "tokens:   %d/%d -> %d/%d (in/out)\n",
+		c.Recorded.InputTokens, c.Recorded.OutputTokens, c.Replayed.InputTokens, c.Replayed.OutputTokens)
+	if c.Recorded.Error != "" || c.Replayed.Error != "" {
+		fmt.Fprintf(&b, "error:    %q -> %q\n", c.Recorded.Error, c.Replayed.Error)
+	}
+	b.WriteString("tool calls:\n")
+	for _, line := range c.SequenceDiff {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	if len(c.Unmatched) > 0 {
+		fmt.Fprintf(&b, "no recorded result for: %s\n", strings.Join(c.Unmatched, ", "))
+	}
+	if c.Recorded.FinalResponse != c.Replayed.FinalResponse {
+		fmt.Fprintf(&b, "final response:\n- %s\n+ %s\n",
+			indent(c.Recorded.FinalResponse), indent(c.Replayed.FinalResponse))
+	}
+	return b.String()
+}
+
+// indent continues a multi-line value under its diff marker
+func indent(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n  ")
+}
+
+// diffSequences merges two call sequences along their longest common subsequence
+func diffSequences(before, after []string) []string {
+	// lcs[i][j] is the LCS length of before[i:] and after[j:]
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(before)+len(after))
+	i, j := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			lines = append(lines, "  "+before[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+before[i])
+			i++
+		default:
+			lines = append(lines, "+ "+after[j])
+			j++
+		}
+	}
+	for ; i < len(before); i++ {
+		lines = append(lines, "- "+before[i])
+	}
+	for ; j < len(after); j++ {
+		lines = append(lines, "+ "+after[j])
+	}
+	return lines
+}
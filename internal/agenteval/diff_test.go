@@ -0,0 +1,74 @@
+package agenteval
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/internal/scheduler"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+func TestDiffSequences(t *testing.T) {
+	got := diffSequences(
+		[]string{"get_weather", "golf_search_tee_times", "golf_book_tee_time", "send_notification"},
+		[]string{"get_weather", "golf_search_tee_times", "golf_search_tee_times", "send_notification"},
+	)
+	want := []string{
+		"  get_weather",
+		"  golf_search_tee_times",
+		"- golf_book_tee_time",
+		"+ golf_search_tee_times",
+		"  send_notification",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffSequences() = %q, want %q", got, want)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	recording := &scheduler.RunRecording{
+		ExecutionID: "exec-1",
+		ScheduleID:  "sched-1",
+		Tools: []protocol.Tool{
+			{Name: "golf_book_tee_time"},
+			{Name: "send_notification", Annotations: &protocol.ToolAnnotations{Terminal: true, Aliases: []string{"send_push_notification"}}},
+		},
+		ToolCalls: []scheduler.RecordedToolCall{
+			{Name: "golf_book_tee_time", Result: "booked"},
+			{Name: "send_notification", Result: "sent"},
+		},
+		FinalResponse: "Booked 9:30",
+	}
+
+	same := Compare(recording, &scheduler.RunSummary{
+		ToolCalls: []scheduler.ToolCallSummary{
+			{Name: "golf_book_tee_time", Result: "booked"},
+			{Name: "send_notification", Result: "sent"},
+		},
+		FinalResponse: "Booked 9:30",
+	}, nil)
+	if same.Changed() {
+		t.Errorf("Compare() of an identical replay changed:\n%s", same)
+	}
+	if !same.Replayed.Booked || !same.Replayed.Notified {
+		t.Errorf("Replayed = %+v, want booked and notified", same.Replayed)
+	}
+
+	changed := Compare(recording, &scheduler.RunSummary{
+		ToolCalls: []scheduler.ToolCallSummary{
+			{Name: "golf_book_tee_time", Result: "slot taken", Failed: true},
+			{Name: "send_push_notification", Result: "sent"},
+		},
+		FinalResponse: "Could not book",
+	}, nil)
+	if !changed.OutcomeChanged() || changed.Replayed.Booked || !changed.Replayed.Notified {
+		t.Errorf("Compare() = %+v, want a replay that notified without booking", changed.Replayed)
+	}
+	report := changed.String()
+	for _, want := range []string{"CHANGED exec-1", "booked:   true -> false", "- send_notification", "+ send_push_notification", "+ Could not book"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("String() is missing %q:\n%s", want, report)
+		}
+	}
+}
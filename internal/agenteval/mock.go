@@ -0,0 +1,97 @@
+// Package agenteval replays recorded agent runs against a new prompt or model, with tools
+// answered from the recording, and reports how the tool calls and outcome changed.
+package agenteval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/jrzesz33/rez_agent/internal/scheduler"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// injectedArguments are added to tool calls by the scheduler rather than chosen by the model,
+// so they are ignored when matching calls
+var injectedArguments = map[string]bool{
+	"policy":           true,
+	"require_approval": true,
+}
+
+// MockTools answers tool calls from a recorded run. A call gets the recorded result of an unused
+// call to the same tool with the same arguments, or else of the next unused call to that tool.
+// Calls the recording has no result for fail, and are reported as unmatched.
+type MockTools struct {
+	mu        sync.Mutex
+	recorded  []scheduler.RecordedToolCall
+	used      []bool
+	unmatched []string
+}
+
+// NewMockTools creates mock tools answering from the recording's tool calls
+func NewMockTools(recording *scheduler.RunRecording) *MockTools {
+	return &MockTools{
+		recorded: recording.ToolCalls,
+		used:     make([]bool, len(recording.ToolCalls)),
+	}
+}
+
+// CallTool returns the recorded result for the call; it is safe for concurrent use
+func (m *MockTools) CallTool(_ context.Context, req protocol.ToolCallRequest) (*protocol.ToolCallResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	index := m.match(req)
+	if index < 0 {
+		m.unmatched = append(m.unmatched, req.Name)
+		return nil, fmt.Errorf("no recorded result for %s", req.Name)
+	}
+	m.used[index] = true
+
+	call := m.recorded[index]
+	if call.Failed {
+		return nil, fmt.Errorf("%s", call.Result)
+	}
+	return &protocol.ToolCallResult{
+		Content: []protocol.Content{protocol.NewTextContent(call.Result)},
+	}, nil
+}
+
+// Unmatched returns the names of the calls the recording had no result for, in call order
+func (m *MockTools) Unmatched() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.unmatched...)
+}
+
+// match returns the index of the recorded call that answers req, or -1
+func (m *MockTools) match(req protocol.ToolCallRequest) int {
+	key := argumentsKey(req.Arguments)
+	next := -1
+	for i, call := range m.recorded {
+		if m.used[i] || call.Name != req.Name {
+			continue
+		}
+		if argumentsKey(call.Arguments) == key {
+			return i
+		}
+		if next < 0 {
+			next = i
+		}
+	}
+	return next
+}
+
+// argumentsKey returns a comparable form of the model-chosen arguments
+func argumentsKey(args map[string]interface{}) string {
+	chosen := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		if !injectedArguments[k] {
+			chosen[k] = v
+		}
+	}
+	// Maps marshal with sorted keys, and numbers compare equal once both sides went through JSON
+	key, _ := json.Marshal(chosen)
+	return string(key)
+}
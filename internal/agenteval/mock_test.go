@@ -0,0 +1,53 @@
+package agenteval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/internal/scheduler"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+func TestMockTools_CallTool(t *testing.T) {
+	mock := NewMockTools(&scheduler.RunRecording{ToolCalls: []scheduler.RecordedToolCall{
+		{Name: "golf_search_tee_times", Arguments: map[string]interface{}{"date": "2026-06-06"}, Result: "saturday times"},
+		{Name: "golf_search_tee_times", Arguments: map[string]interface{}{"date": "2026-06-07"}, Result: "sunday times"},
+		{Name: "golf_book_tee_time", Arguments: map[string]interface{}{"tee_time_id": "1"}, Result: "slot taken", Failed: true},
+	}})
+	ctx := context.Background()
+
+	// Exact argument match wins over call order, and injected arguments are ignored
+	result, err := mock.CallTool(ctx, protocol.ToolCallRequest{
+		Name:      "golf_search_tee_times",
+		Arguments: map[string]interface{}{"date": "2026-06-07", "policy": map[string]interface{}{"max_price": 40}},
+	})
+	if err != nil || result.Content[0].Text != "sunday times" {
+		t.Fatalf("CallTool(sunday) = %v, %v; want sunday times", result, err)
+	}
+
+	// Otherwise the next unused call to the same tool answers
+	result, err = mock.CallTool(ctx, protocol.ToolCallRequest{
+		Name:      "golf_search_tee_times",
+		Arguments: map[string]interface{}{"date": "2026-06-08"},
+	})
+	if err != nil || result.Content[0].Text != "saturday times" {
+		t.Fatalf("CallTool(monday) = %v, %v; want saturday times", result, err)
+	}
+
+	// Recorded failures fail again
+	if _, err := mock.CallTool(ctx, protocol.ToolCallRequest{Name: "golf_book_tee_time"}); err == nil || err.Error() != "slot taken" {
+		t.Errorf("CallTool(book) error = %v, want slot taken", err)
+	}
+
+	// Calls beyond the recording fail and are reported
+	if _, err := mock.CallTool(ctx, protocol.ToolCallRequest{Name: "golf_search_tee_times"}); err == nil {
+		t.Error("CallTool(third search) error = nil, want error")
+	}
+	if _, err := mock.CallTool(ctx, protocol.ToolCallRequest{Name: "get_weather"}); err == nil {
+		t.Error("CallTool(get_weather) error = nil, want error")
+	}
+	unmatched := mock.Unmatched()
+	if len(unmatched) != 2 || unmatched[0] != "golf_search_tee_times" || unmatched[1] != "get_weather" {
+		t.Errorf("Unmatched() = %v, want [golf_search_tee_times get_weather]", unmatched)
+	}
+}
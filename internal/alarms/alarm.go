@@ -0,0 +1,91 @@
+// Package alarms turns CloudWatch alarm notifications into pipeline notification messages
+package alarms
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// StateAlarm is the NewStateValue of an alarm that has been breached
+const StateAlarm = "ALARM"
+
+// Dimension is a metric dimension in an alarm's trigger
+type Dimension struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Trigger is the metric an alarm watches
+type Trigger struct {
+	MetricName string      `json:"MetricName"`
+	Namespace  string      `json:"Namespace"`
+	Dimensions []Dimension `json:"Dimensions"`
+	Threshold  float64     `json:"Threshold"`
+}
+
+// Alarm is the state change CloudWatch publishes to an SNS alarm action
+type Alarm struct {
+	AlarmName        string  `json:"AlarmName"`
+	AlarmDescription string  `json:"AlarmDescription"`
+	NewStateValue    string  `json:"NewStateValue"`
+	OldStateValue    string  `json:"OldStateValue"`
+	NewStateReason   string  `json:"NewStateReason"`
+	StateChangeTime  string  `json:"StateChangeTime"`
+	Trigger          Trigger `json:"Trigger"`
+}
+
+// Parse reads an alarm from an SNS message body
+func Parse(message string) (*Alarm, error) {
+	var alarm Alarm
+	if err := json.Unmarshal([]byte(message), &alarm); err != nil {
+		return nil, fmt.Errorf("failed to parse alarm notification: %w", err)
+	}
+	if alarm.AlarmName == "" {
+		return nil, fmt.Errorf("alarm notification has no AlarmName")
+	}
+	return &alarm, nil
+}
+
+// Breached reports whether the alarm has just entered the ALARM state
+func (a *Alarm) Breached() bool {
+	return a.NewStateValue == StateAlarm
+}
+
+// QueueName returns the SQS queue the alarm watches, or "" for other metrics
+func (a *Alarm) QueueName() string {
+	for _, dimension := range a.Trigger.Dimensions {
+		if dimension.Name == "QueueName" {
+			return dimension.Value
+		}
+	}
+	return ""
+}
+
+// Summary returns the push notification text for the alarm
+func (a *Alarm) Summary() string {
+	var b strings.Builder
+	if queue := a.QueueName(); queue != "" && strings.Contains(queue, "-dlq-") {
+		fmt.Fprintf(&b, "🚨 Messages are dead-lettering in %s", queue)
+	} else {
+		fmt.Fprintf(&b, "🚨 Alarm %s", a.AlarmName)
+	}
+	if a.AlarmDescription != "" {
+		fmt.Fprintf(&b, "\n%s", a.AlarmDescription)
+	}
+	if a.NewStateReason != "" {
+		fmt.Fprintf(&b, "\n%s", a.NewStateReason)
+	}
+	return b.String()
+}
+
+// ToMessage wraps the alarm in a notify message for the processor to deliver
+func (a *Alarm) ToMessage(stage models.Stage) *models.Message {
+	return models.NewMessage(models.ComponentAlarms, map[string]interface{}{
+		"alarm_name": a.AlarmName,
+	}, "1.0", stage, models.MessageTypeNotification, map[string]interface{}{
+		"message": a.Summary(),
+	})
+}
@@ -0,0 +1,62 @@
+package alarms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+const dlqAlarm = `{
+	"AlarmName": "rez-agent-notifications-dlq-depth-dev",
+	"AlarmDescription": "Alert when notifications messages are dead-lettered",
+	"NewStateValue": "ALARM",
+	"OldStateValue": "OK",
+	"NewStateReason": "Threshold Crossed: 1 out of the last 1 datapoints [3.0] was greater than the threshold (0.0).",
+	"StateChangeTime": "2026-10-16T12:05:00.000+0000",
+	"Trigger": {
+		"MetricName": "ApproximateNumberOfMessagesVisible",
+		"Namespace": "AWS/SQS",
+		"Dimensions": [{"value": "rez-agent-notifications-dlq-dev", "name": "QueueName"}],
+		"Threshold": 0.0
+	}
+}`
+
+func TestParse(t *testing.T) {
+	alarm, err := Parse(dlqAlarm)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !alarm.Breached() {
+		t.Error("Breached() = false, want true")
+	}
+	if got := alarm.QueueName(); got != "rez-agent-notifications-dlq-dev" {
+		t.Errorf("QueueName() = %q, want rez-agent-notifications-dlq-dev", got)
+	}
+
+	if _, err := Parse(`{"Type": "SubscriptionConfirmation"}`); err == nil {
+		t.Error("Parse() of a non-alarm message error = nil, want an error")
+	}
+}
+
+func TestAlarm_ToMessage(t *testing.T) {
+	alarm, err := Parse(dlqAlarm)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	msg := alarm.ToMessage(models.StageDev)
+	if msg.MessageType != models.MessageTypeNotification {
+		t.Errorf("MessageType = %v, want %v", msg.MessageType, models.MessageTypeNotification)
+	}
+	if err := models.DefaultMessageTypeRegistry().ValidatePublish(msg, models.ComponentAlarms); err != nil {
+		t.Errorf("ValidatePublish() error = %v", err)
+	}
+
+	text, _ := msg.Payload["message"].(string)
+	for _, want := range []string{"dead-lettering in rez-agent-notifications-dlq-dev", "Threshold Crossed"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("message %q does not contain %q", text, want)
+		}
+	}
+}
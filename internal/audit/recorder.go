@@ -0,0 +1,83 @@
+// Package audit records privileged operations (schedule changes, bookings, cancellations and
+// secret reads) in the append-only audit log
+package audit
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// Event describes a privileged operation that just completed
+type Event struct {
+	// Action is the operation performed
+	Action models.AuditAction
+
+	// UserID is the user who asked for the operation; empty for the system or single-user mode
+	UserID string
+
+	// Target identifies what the operation acted on
+	Target string
+
+	// SourceIP is the caller's address, for operations requested over HTTP
+	SourceIP string
+
+	// Details holds operation-specific context
+	Details map[string]string
+}
+
+// Recorder appends entries to the audit log on behalf of one component. A nil Recorder records
+// nothing, so handlers can call it whether or not auditing is configured.
+type Recorder struct {
+	repo   repository.AuditRepository
+	actor  string
+	logger *slog.Logger
+}
+
+// NewRecorder creates a recorder whose entries name actor as the component that performed them
+func NewRecorder(repo repository.AuditRepository, actor string, logger *slog.Logger) *Recorder {
+	return &Recorder{
+		repo:   repo,
+		actor:  actor,
+		logger: logger,
+	}
+}
+
+// Record appends the event to the audit log, tagged with the context's correlation ID. The
+// operation has already happened, so a failure to record it is logged rather than returned:
+// failing a booking that went through would only lead to it being retried.
+func (r *Recorder) Record(ctx context.Context, event Event) {
+	if r == nil {
+		return
+	}
+
+	entry, err := models.NewAuditEntry(event.UserID, event.Action, r.actor, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to record audit entry",
+			slog.String("action", string(event.Action)),
+			slog.String("target", event.Target),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	entry.Target = event.Target
+	entry.SourceIP = event.SourceIP
+	entry.CorrelationID = logging.CorrelationID(ctx)
+	entry.Details = event.Details
+
+	if err := r.repo.SaveEntry(ctx, entry); err != nil {
+		r.logger.ErrorContext(ctx, "failed to record audit entry",
+			slog.String("action", string(event.Action)),
+			slog.String("target", event.Target),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// SecretAccessed records a read of the named secret; pass it to secrets.Manager.SetAccessRecorder
+func (r *Recorder) SecretAccessed(ctx context.Context, secretName string) {
+	r.Record(ctx, Event{Action: models.AuditActionSecretAccess, Target: secretName})
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+type failingAudit struct {
+	repository.AuditRepository
+}
+
+func (failingAudit) SaveEntry(ctx context.Context, entry *models.AuditEntry) error {
+	return errors.New("throttled")
+}
+
+func TestRecorder_Record(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := repository.NewMemoryAuditRepository()
+	recorder := NewRecorder(repo, "webapi", logger)
+
+	ctx := logging.WithCorrelationID(context.Background(), "corr_test")
+	recorder.Record(ctx, Event{
+		Action:   models.AuditActionBookingCancellation,
+		UserID:   "usr_1",
+		Target:   "apr_1",
+		SourceIP: "203.0.113.9",
+	})
+	recorder.SecretAccessed(ctx, "rez-agent/golf/credentials-dev")
+
+	entries, err := repo.ListEntries(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("ListEntries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("recorded %d entries, want 2", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Actor != "webapi" || entry.CorrelationID != "corr_test" {
+			t.Errorf("entry = %+v, want actor webapi and the context's correlation ID", entry)
+		}
+	}
+
+	// Neither a nil recorder nor a failing store disturbs the operation being audited
+	var disabled *Recorder
+	disabled.Record(ctx, Event{Action: models.AuditActionBooking})
+	NewRecorder(failingAudit{}, "webapi", logger).Record(ctx, Event{Action: models.AuditActionBooking})
+}
@@ -0,0 +1,140 @@
+// Package bodystore keeps the full response bodies of web actions in S3 when they are too large
+// for the web action results table, which stores only their first 50KB. Bodies are gzipped and
+// stored under a key derived from their content, so repeated responses share one object.
+package bodystore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// keyPrefix is where bodies are stored in the bucket; a lifecycle rule expires them with the results
+const keyPrefix = "response-bodies/"
+
+// LinkTTL is how long a presigned body link stays valid, or until the signing session expires
+const LinkTTL = 15 * time.Minute
+
+// objectPutter uploads objects; satisfied by *s3.Client
+type objectPutter interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// objectPresigner signs object downloads; satisfied by *s3.PresignClient
+type objectPresigner interface {
+	PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
+// Store keeps response bodies in a bucket and hands out presigned links to them
+type Store struct {
+	client  objectPutter
+	presign objectPresigner
+	bucket  string
+	stage   string
+}
+
+// New creates a store that writes bodies to the given bucket
+func New(client *s3.Client, bucket, stage string) *Store {
+	return &Store{
+		client:  client,
+		presign: s3.NewPresignClient(client),
+		bucket:  bucket,
+		stage:   stage,
+	}
+}
+
+// Key returns the object key of a body: the SHA-256 of its content, under the stage's prefix
+func (s *Store) Key(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return fmt.Sprintf("%s%s/%s.gz", keyPrefix, s.stage, hex.EncodeToString(sum[:]))
+}
+
+// Put gzips body and uploads it, returning its key. An identical body is uploaded again rather
+// than skipped, which restarts its expiry for the newer result that points to it.
+func (s *Store) Put(ctx context.Context, body string) (string, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write([]byte(body)); err != nil {
+		return "", fmt.Errorf("failed to compress response body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress response body: %w", err)
+	}
+
+	contentType := "text/plain; charset=utf-8"
+	if json.Valid([]byte(body)) {
+		contentType = "application/json"
+	}
+
+	key := s.Key(body)
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(compressed.Bytes()),
+		// Browsers and curl --compressed decompress the download themselves
+		ContentEncoding: aws.String("gzip"),
+		ContentType:     aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload response body: %w", err)
+	}
+	return key, nil
+}
+
+// Link returns a presigned link to the body stored under key, which must be one of the store's keys
+func (s *Store) Link(ctx context.Context, key string) (string, error) {
+	if !strings.HasPrefix(key, fmt.Sprintf("%s%s/", keyPrefix, s.stage)) {
+		return "", fmt.Errorf("not a response body key: %s", key)
+	}
+	req, err := s.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(LinkTTL))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign response body link: %w", err)
+	}
+	return req.URL, nil
+}
+
+// Repository saves web action results, first uploading the full bodies of results whose stored
+// body was truncated and recording their keys on the results
+type Repository struct {
+	repository.WebActionResultRepository
+	store  *Store
+	logger *slog.Logger
+}
+
+// NewRepository wraps repo so the full bodies of the results it saves are kept in store
+func NewRepository(repo repository.WebActionResultRepository, store *Store, logger *slog.Logger) *Repository {
+	return &Repository{WebActionResultRepository: repo, store: store, logger: logger}
+}
+
+// SaveResult uploads the result's full body when it was truncated, then saves the result. A failed
+// upload is logged and the result saved with its truncated body alone.
+func (r *Repository) SaveResult(ctx context.Context, result *models.WebActionResult) error {
+	if body := result.FullResponseBody(); body != "" && result.ResponseBodyKey == "" {
+		key, err := r.store.Put(ctx, body)
+		if err != nil {
+			r.logger.WarnContext(ctx, "failed to store full response body",
+				slog.String("result_id", result.ID),
+				slog.String("error", err.Error()),
+			)
+		} else {
+			result.ResponseBodyKey = key
+		}
+	}
+	return r.WebActionResultRepository.SaveResult(ctx, result)
+}
@@ -0,0 +1,155 @@
+package bodystore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// fakeS3 keeps uploaded objects in memory and signs links without credentials
+type fakeS3 struct {
+	objects map[string]*s3.PutObjectInput
+	bodies  map[string][]byte
+	err     error
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string]*s3.PutObjectInput{}, bodies: map[string][]byte{}}
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	key := aws.ToString(params.Key)
+	f.objects[key] = params
+	f.bodies[key] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	return &v4.PresignedHTTPRequest{URL: "https://" + aws.ToString(params.Bucket) + ".s3.amazonaws.com/" + aws.ToString(params.Key) + "?X-Amz-Signature=sig"}, nil
+}
+
+func newTestStore(client *fakeS3) *Store {
+	return &Store{client: client, presign: client, bucket: "rez-agent-logs-dev", stage: "dev"}
+}
+
+func TestStore_Put(t *testing.T) {
+	client := newFakeS3()
+	store := newTestStore(client)
+	body := `{"tee_times":[` + strings.Repeat(`{"time":"07:00","players":4},`, 100) + `{}]}`
+
+	key, err := store.Put(context.Background(), body)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if !strings.HasPrefix(key, "response-bodies/dev/") || !strings.HasSuffix(key, ".gz") || key != store.Key(body) {
+		t.Errorf("Put() key = %s", key)
+	}
+
+	object := client.objects[key]
+	if aws.ToString(object.ContentEncoding) != "gzip" || aws.ToString(object.ContentType) != "application/json" {
+		t.Errorf("object ContentEncoding = %q, ContentType = %q", aws.ToString(object.ContentEncoding), aws.ToString(object.ContentType))
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(client.bodies[key]))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	stored, _ := io.ReadAll(reader)
+	if string(stored) != body {
+		t.Errorf("stored body differs from the original")
+	}
+
+	// Identical bodies share a key; different ones do not
+	again, _ := store.Put(context.Background(), body)
+	other, _ := store.Put(context.Background(), "<html>maintenance</html>")
+	if again != key || other == key {
+		t.Errorf("keys: first %s, identical %s, different %s", key, again, other)
+	}
+	if aws.ToString(client.objects[other].ContentType) != "text/plain; charset=utf-8" {
+		t.Errorf("non-JSON ContentType = %q", aws.ToString(client.objects[other].ContentType))
+	}
+}
+
+func TestStore_Link(t *testing.T) {
+	store := newTestStore(newFakeS3())
+	key := store.Key("body")
+
+	link, err := store.Link(context.Background(), key)
+	if err != nil || !strings.Contains(link, key) {
+		t.Errorf("Link() = %s, %v", link, err)
+	}
+	for _, foreign := range []string{"summaries/dev/sched_1/2026/10/17/exec.html", "response-bodies/prod/abc.gz"} {
+		if _, err := store.Link(context.Background(), foreign); err == nil {
+			t.Errorf("Link(%s) error = nil, want it refused", foreign)
+		}
+	}
+}
+
+func TestRepository_SaveResult(t *testing.T) {
+	ctx := context.Background()
+	large := `{"data":"` + strings.Repeat("x", 60*1024) + `","email":"golfer@example.com"}`
+
+	tests := []struct {
+		name    string
+		body    string
+		putErr  error
+		wantKey bool
+	}{
+		{"small body stays in the table", `{"status":"ok"}`, nil, false},
+		{"large body is offloaded", large, nil, true},
+		{"failed upload still saves the result", large, errors.New("access denied"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeS3()
+			client.err = tt.putErr
+			results := repository.NewMemoryWebActionRepository()
+			repo := NewRepository(results, newTestStore(client), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			result := models.NewWebActionResult("msg_1", models.WebActionTypeWeather, "https://api.example.com", models.StageDev)
+			result.MarkSuccess(200, tt.body, 10)
+			if err := repo.SaveResult(ctx, result); err != nil {
+				t.Fatalf("SaveResult() error = %v", err)
+			}
+
+			saved, err := results.GetResult(ctx, result.ID)
+			if err != nil {
+				t.Fatalf("GetResult() error = %v", err)
+			}
+			if (saved.ResponseBodyKey != "") != tt.wantKey {
+				t.Fatalf("ResponseBodyKey = %q, want set %v", saved.ResponseBodyKey, tt.wantKey)
+			}
+			if !tt.wantKey {
+				return
+			}
+
+			// The offloaded body is whole and masked like the stored one
+			reader, err := gzip.NewReader(bytes.NewReader(client.bodies[saved.ResponseBodyKey]))
+			if err != nil {
+				t.Fatalf("gzip.NewReader() error = %v", err)
+			}
+			stored, _ := io.ReadAll(reader)
+			if len(stored) < 60*1024 || strings.Contains(string(stored), "golfer@example.com") {
+				t.Errorf("offloaded body is %d bytes, masked %v", len(stored), !strings.Contains(string(stored), "golfer@example.com"))
+			}
+		})
+	}
+}
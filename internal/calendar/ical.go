@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// ContentType is the Content-Type of an iCalendar feed
+const ContentType = "text/calendar; charset=utf-8"
+
+// productID identifies rez_agent as the producer of the feed (RFC 5545 PRODID)
+const productID = "-//rez_agent//Calendar Feed//EN"
+
+// maxLineOctets is the longest content line RFC 5545 allows before it must be folded
+const maxLineOctets = 75
+
+// utcFormat is the iCalendar UTC date-time form, e.g. 20260606T123000Z
+const utcFormat = "20060102T150405Z"
+
+// Event is one VEVENT of a feed
+type Event struct {
+	// UID must stay the same across renders so calendar apps update the event instead of duplicating it
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+}
+
+// Feed is a VCALENDAR with a display name and its events
+type Feed struct {
+	Name   string
+	Events []Event
+}
+
+// Write renders the feed as iCalendar text with CRLF line endings, folded long lines, and times
+// in UTC. stamp is the DTSTAMP of every event, normally the time the feed was generated.
+func (f Feed) Write(w io.Writer, stamp time.Time) error {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+productID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if f.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escapeText(f.Name))
+	}
+
+	for _, event := range f.Events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+escapeText(event.UID))
+		writeLine(&b, "DTSTAMP:"+stamp.UTC().Format(utcFormat))
+		writeLine(&b, "DTSTART:"+event.Start.UTC().Format(utcFormat))
+		if !event.End.IsZero() {
+			writeLine(&b, "DTEND:"+event.End.UTC().Format(utcFormat))
+		}
+		writeLine(&b, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if event.Location != "" {
+			writeLine(&b, "LOCATION:"+escapeText(event.Location))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeText escapes a TEXT property value (RFC 5545 section 3.3.11)
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// writeLine writes a content line, folding it into 75-octet lines continued by a leading space.
+// Folds never split a UTF-8 sequence, so emoji in summaries survive.
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8Start(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts toward its length
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// utf8Start reports whether c begins a UTF-8 sequence rather than continuing one
+func utf8Start(c byte) bool {
+	return c&0xC0 != 0x80
+}
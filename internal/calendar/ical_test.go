@@ -0,0 +1,84 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeed_Write(t *testing.T) {
+	start := time.Date(2026, 6, 6, 8, 30, 0, 0, time.FixedZone("EDT", -4*60*60))
+	feed := Feed{
+		Name: "rez_agent",
+		Events: []Event{{
+			UID:         "reservation-1-42@rez-agent",
+			Summary:     "Golf at Totteridge",
+			Description: "4 player(s)\nConfirmation: ABC;123",
+			Start:       start,
+			End:         start.Add(4 * time.Hour),
+		}},
+	}
+
+	var b strings.Builder
+	if err := feed.Write(&b, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	got := b.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"X-WR-CALNAME:rez_agent\r\n",
+		"UID:reservation-1-42@rez-agent\r\n",
+		"DTSTAMP:20260601T000000Z\r\n",
+		"DTSTART:20260606T123000Z\r\n",
+		"DTEND:20260606T163000Z\r\n",
+		`DESCRIPTION:4 player(s)\nConfirmation: ABC\;123` + "\r\n",
+		"END:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write() is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteLine_Folds(t *testing.T) {
+	var b strings.Builder
+	writeLine(&b, "SUMMARY:"+strings.Repeat("⛳", 40))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected a folded line, got %q", b.String())
+	}
+	var unfolded strings.Builder
+	for i, line := range lines {
+		if len(line) > maxLineOctets {
+			t.Errorf("line %d is %d octets, want at most %d", i, len(line), maxLineOctets)
+		}
+		if i > 0 {
+			if !strings.HasPrefix(line, " ") {
+				t.Errorf("continuation line %d does not start with a space", i)
+			}
+			line = line[1:]
+		}
+		unfolded.WriteString(line)
+	}
+	if want := "SUMMARY:" + strings.Repeat("⛳", 40); unfolded.String() != want {
+		t.Errorf("unfolded = %q, want %q", unfolded.String(), want)
+	}
+}
+
+func TestVerifyFeedToken(t *testing.T) {
+	token := FeedToken("key", "alice")
+	if !VerifyFeedToken("key", "alice", token) {
+		t.Error("expected the token to verify for its subject")
+	}
+	if VerifyFeedToken("key", "bob", token) {
+		t.Error("expected the token not to verify for another subject")
+	}
+	if VerifyFeedToken("other", "alice", token) {
+		t.Error("expected the token not to verify with another key")
+	}
+	if VerifyFeedToken("", "alice", FeedToken("", "alice")) {
+		t.Error("expected no token to verify without a key")
+	}
+}
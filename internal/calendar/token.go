@@ -0,0 +1,24 @@
+package calendar
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// FeedToken signs the subject of a calendar feed, usually a user ID, so the feed URL can be
+// given to a calendar app that cannot send an API key. Anyone holding the URL can read the
+// feed; rotating the signing key revokes every URL.
+func FeedToken(key, subject string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte("calendar-feed:" + subject))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyFeedToken reports whether token was issued by FeedToken for the subject
+func VerifyFeedToken(key, subject, token string) bool {
+	if key == "" || token == "" {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(FeedToken(key, subject)))
+}
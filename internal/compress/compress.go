@@ -0,0 +1,143 @@
+// Package compress gzips API Gateway responses for clients that accept it. Only bodies above a
+// threshold are compressed; smaller ones would gain little and cost CPU on every request.
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// gzipEncoding is the only content coding offered; the standard library has no brotli encoder
+const gzipEncoding = "gzip"
+
+// AcceptsGzip reports whether an Accept-Encoding header value allows a gzip response
+func AcceptsGzip(acceptEncoding string) bool {
+	wildcard := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != gzipEncoding && coding != "x-gzip" && coding != "*" {
+			continue
+		}
+		accepted := quality(params) > 0
+		if coding == "*" {
+			wildcard = accepted
+			continue
+		}
+		return accepted
+	}
+	return wildcard
+}
+
+// quality reads the q parameter of an Accept-Encoding entry, which defaults to 1
+func quality(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.ToLower(strings.TrimSpace(name)) != "q" {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
+
+// compressible reports whether a body of contentType is worth compressing; images, archives and
+// other binary types are usually compressed already
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch {
+	case mediaType == "":
+		// Handlers here default to JSON
+		return true
+	case strings.HasPrefix(mediaType, "text/"):
+		// Event streams are flushed as they are written; buffering them to compress defeats that
+		return mediaType != "text/event-stream"
+	case mediaType == "application/json", strings.HasSuffix(mediaType, "+json"),
+		mediaType == "application/xml", strings.HasSuffix(mediaType, "+xml"),
+		mediaType == "application/javascript":
+		return true
+	}
+	return false
+}
+
+// header returns a response header regardless of the case handlers wrote its name in
+func header(headers map[string]string, name string) (string, string) {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return k, v
+		}
+	}
+	return name, ""
+}
+
+// Response gzips response when the request accepts gzip and its body is at least threshold bytes.
+// A threshold of zero or less turns compression off.
+func Response(request events.APIGatewayV2HTTPRequest, response events.APIGatewayV2HTTPResponse, threshold int) events.APIGatewayV2HTTPResponse {
+	if threshold <= 0 || len(response.Body) < threshold || response.IsBase64Encoded {
+		return response
+	}
+	if response.StatusCode == http.StatusNoContent || response.StatusCode == http.StatusNotModified {
+		return response
+	}
+	if _, encoding := header(response.Headers, "Content-Encoding"); encoding != "" {
+		return response
+	}
+	if _, contentType := header(response.Headers, "Content-Type"); !compressible(contentType) {
+		return response
+	}
+	// API Gateway lowercases header names
+	if !AcceptsGzip(request.Headers["accept-encoding"]) {
+		return response
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write([]byte(response.Body)); err != nil {
+		return response
+	}
+	if err := writer.Close(); err != nil {
+		return response
+	}
+
+	headers := make(map[string]string, len(response.Headers)+2)
+	for k, v := range response.Headers {
+		headers[k] = v
+	}
+	headers["Content-Encoding"] = gzipEncoding
+	varyKey, vary := header(headers, "Vary")
+	if vary == "" {
+		headers[varyKey] = "Accept-Encoding"
+	} else {
+		headers[varyKey] = vary + ", Accept-Encoding"
+	}
+
+	response.Headers = headers
+	response.Body = base64.StdEncoding.EncodeToString(compressed.Bytes())
+	response.IsBase64Encoded = true
+	return response
+}
+
+// Handler wraps an API Gateway handler, gzipping its responses of at least threshold bytes for
+// clients that accept gzip
+func Handler(threshold int, next func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error)) func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		response, err := next(ctx, request)
+		if err != nil {
+			// A failed invocation is answered by API Gateway, not with this response
+			return response, err
+		}
+		return Response(request, response, threshold), nil
+	}
+}
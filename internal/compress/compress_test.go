@@ -0,0 +1,119 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.8, br", true},
+		{"GZIP", true},
+		{"gzip;q=0", false},
+		{"br", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"gzip;q=0, *", false},
+		{"x-gzip", true},
+	}
+	for _, tt := range tests {
+		if got := AcceptsGzip(tt.header); got != tt.want {
+			t.Errorf("AcceptsGzip(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestResponse(t *testing.T) {
+	large := `{"messages":[` + strings.Repeat(`{"id":"msg_1","status":"completed"},`, 100) + `{}]}`
+	gzipRequest := events.APIGatewayV2HTTPRequest{Headers: map[string]string{"accept-encoding": "gzip, deflate, br"}}
+
+	tests := []struct {
+		name         string
+		request      events.APIGatewayV2HTTPRequest
+		response     events.APIGatewayV2HTTPResponse
+		threshold    int
+		wantCompress bool
+	}{
+		{"large JSON", gzipRequest, events.APIGatewayV2HTTPResponse{StatusCode: 200, Headers: map[string]string{"Content-Type": "application/json"}, Body: large}, 1024, true},
+		{"below threshold", gzipRequest, events.APIGatewayV2HTTPResponse{StatusCode: 200, Body: `{"status":"healthy"}`}, 1024, false},
+		{"compression off", gzipRequest, events.APIGatewayV2HTTPResponse{StatusCode: 200, Body: large}, 0, false},
+		{"client without gzip", events.APIGatewayV2HTTPRequest{}, events.APIGatewayV2HTTPResponse{StatusCode: 200, Body: large}, 1024, false},
+		{"already encoded", gzipRequest, events.APIGatewayV2HTTPResponse{StatusCode: 200, Headers: map[string]string{"content-encoding": "br"}, Body: large}, 1024, false},
+		{"binary body", gzipRequest, events.APIGatewayV2HTTPResponse{StatusCode: 200, Body: large, IsBase64Encoded: true}, 1024, false},
+		{"image", gzipRequest, events.APIGatewayV2HTTPResponse{StatusCode: 200, Headers: map[string]string{"Content-Type": "image/png"}, Body: large}, 1024, false},
+		{"event stream", gzipRequest, events.APIGatewayV2HTTPResponse{StatusCode: 200, Headers: map[string]string{"Content-Type": "text/event-stream"}, Body: large}, 1024, false},
+		{"calendar", gzipRequest, events.APIGatewayV2HTTPResponse{StatusCode: 200, Headers: map[string]string{"Content-Type": "text/calendar; charset=utf-8"}, Body: large}, 1024, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Response(tt.request, tt.response, tt.threshold)
+			if !tt.wantCompress {
+				if got.Body != tt.response.Body || got.Headers["Content-Encoding"] != "" {
+					t.Errorf("Response() compressed a response it should have left alone: %v", got.Headers)
+				}
+				return
+			}
+
+			if !got.IsBase64Encoded || got.Headers["Content-Encoding"] != "gzip" || got.Headers["Vary"] != "Accept-Encoding" {
+				t.Fatalf("Response() = base64 %v, headers %v", got.IsBase64Encoded, got.Headers)
+			}
+			if got.Headers["Content-Type"] != tt.response.Headers["Content-Type"] {
+				t.Errorf("Content-Type = %q, want it kept", got.Headers["Content-Type"])
+			}
+			compressed, err := base64.StdEncoding.DecodeString(got.Body)
+			if err != nil {
+				t.Fatalf("body is not base64: %v", err)
+			}
+			if len(compressed) >= len(tt.response.Body) {
+				t.Errorf("compressed body is %d bytes, not smaller than %d", len(compressed), len(tt.response.Body))
+			}
+			reader, err := gzip.NewReader(bytes.NewReader(compressed))
+			if err != nil {
+				t.Fatalf("gzip.NewReader() error = %v", err)
+			}
+			body, _ := io.ReadAll(reader)
+			if string(body) != tt.response.Body {
+				t.Errorf("decompressed body differs from the original")
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	body := strings.Repeat("a", 2048)
+	request := events.APIGatewayV2HTTPRequest{Headers: map[string]string{"accept-encoding": "gzip"}}
+
+	handler := Handler(1024, func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK, Headers: map[string]string{"Vary": "Origin"}, Body: body}, nil
+	})
+	response, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler() error = %v", err)
+	}
+	if response.Headers["Content-Encoding"] != "gzip" || response.Headers["Vary"] != "Origin, Accept-Encoding" {
+		t.Errorf("Headers = %v", response.Headers)
+	}
+
+	failing := Handler(1024, func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusInternalServerError, Body: body}, errors.New("boom")
+	})
+	response, err = failing(context.Background(), request)
+	if err == nil || response.IsBase64Encoded {
+		t.Errorf("failed invocation = %v, base64 %v; want the error and the response untouched", err, response.IsBase64Encoded)
+	}
+}
@@ -0,0 +1,170 @@
+// Package cors answers CORS preflight requests and adds CORS headers to the responses of the
+// web API, MCP server and agent, from the allowed origins configured for the stage.
+package cors
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	appconfig "github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+// defaultHeaders are the request headers browsers may send to every API
+var defaultHeaders = []string{"Content-Type", "Authorization", "X-API-Key", "X-Correlation-ID"}
+
+// defaultMaxAge is how long browsers may cache a preflight response
+const defaultMaxAge = time.Hour
+
+// Policy is the CORS policy of one API
+type Policy struct {
+	// AllowedOrigins are the origins browsers may call the API from; "*" allows any origin and
+	// empty allows none
+	AllowedOrigins []string
+
+	// AllowCredentials lets browsers send cookies and credentials; it needs listed origins
+	AllowCredentials bool
+
+	// AllowedMethods are the methods the API serves
+	AllowedMethods []string
+
+	// AllowedHeaders are the request headers browsers may send
+	AllowedHeaders []string
+
+	// ExposedHeaders are the response headers scripts may read (optional)
+	ExposedHeaders []string
+
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// NewPolicy creates the policy of an API serving methods, with the origins configured for the stage
+func NewPolicy(cfg *appconfig.Config, methods ...string) *Policy {
+	return &Policy{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		AllowedMethods:   methods,
+		AllowedHeaders:   defaultHeaders,
+		MaxAge:           defaultMaxAge,
+	}
+}
+
+// Expose lets scripts read the response headers named
+func (p *Policy) Expose(headers ...string) *Policy {
+	p.ExposedHeaders = append(p.ExposedHeaders, headers...)
+	return p
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for a request from origin, or "" when
+// the origin may not call the API
+func (p *Policy) allowOrigin(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	if slices.Contains(p.AllowedOrigins, "*") && !p.AllowCredentials {
+		return "*"
+	}
+	if slices.Contains(p.AllowedOrigins, origin) {
+		return origin
+	}
+	return ""
+}
+
+// Headers returns the CORS headers of a response to a request from origin; preflight responses
+// also carry the allowed methods and headers
+func (p *Policy) Headers(origin string, preflight bool) map[string]string {
+	headers := make(map[string]string)
+	allowed := p.allowOrigin(origin)
+	if allowed != "*" && len(p.AllowedOrigins) > 0 {
+		// The response depends on the origin, so caches must not share it across origins
+		headers["Vary"] = "Origin"
+	}
+	if allowed == "" {
+		return headers
+	}
+
+	headers["Access-Control-Allow-Origin"] = allowed
+	if p.AllowCredentials {
+		headers["Access-Control-Allow-Credentials"] = "true"
+	}
+	if preflight {
+		headers["Access-Control-Allow-Methods"] = strings.Join(p.AllowedMethods, ", ")
+		headers["Access-Control-Allow-Headers"] = strings.Join(p.AllowedHeaders, ", ")
+		if p.MaxAge > 0 {
+			headers["Access-Control-Max-Age"] = strconv.Itoa(int(p.MaxAge.Seconds()))
+		}
+	} else if len(p.ExposedHeaders) > 0 {
+		headers["Access-Control-Expose-Headers"] = strings.Join(p.ExposedHeaders, ", ")
+	}
+	return headers
+}
+
+// isPreflight reports whether request is a CORS preflight; API Gateway lowercases header names
+func isPreflight(request events.APIGatewayV2HTTPRequest) bool {
+	return request.RequestContext.HTTP.Method == http.MethodOptions && request.Headers["access-control-request-method"] != ""
+}
+
+// preflightResponse answers a preflight request without calling the API
+func (p *Policy) preflightResponse(request events.APIGatewayV2HTTPRequest) events.APIGatewayV2HTTPResponse {
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusNoContent,
+		Headers:    p.Headers(request.Headers["origin"], true),
+	}
+}
+
+// setHeaders replaces any CORS headers the handler set with the policy's, adding to the Vary
+// header the handler set rather than replacing it
+func setHeaders(response map[string]string, headers map[string]string) map[string]string {
+	if response == nil {
+		response = make(map[string]string, len(headers))
+	}
+	for k, v := range response {
+		switch key := http.CanonicalHeaderKey(k); {
+		case strings.HasPrefix(key, "Access-Control-"):
+			delete(response, k)
+		case key == "Vary" && headers["Vary"] != "":
+			delete(response, k)
+			headers["Vary"] = v + ", " + headers["Vary"]
+		}
+	}
+	for k, v := range headers {
+		response[k] = v
+	}
+	return response
+}
+
+// Handler wraps an API Gateway handler with the policy: preflight requests are answered before
+// they reach it, and its responses carry the CORS headers of the request's origin
+func (p *Policy) Handler(next func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error)) func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		if isPreflight(request) {
+			return p.preflightResponse(request), nil
+		}
+		response, err := next(ctx, request)
+		response.Headers = setHeaders(response.Headers, p.Headers(request.Headers["origin"], false))
+		return response, err
+	}
+}
+
+// StreamHandler wraps a function URL handler with the policy, like Handler
+func (p *Policy) StreamHandler(next func(context.Context, events.APIGatewayV2HTTPRequest) (*events.LambdaFunctionURLStreamingResponse, error)) func(context.Context, events.APIGatewayV2HTTPRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+		if isPreflight(request) {
+			preflight := p.preflightResponse(request)
+			return &events.LambdaFunctionURLStreamingResponse{
+				StatusCode: preflight.StatusCode,
+				Headers:    preflight.Headers,
+				Body:       strings.NewReader(""),
+			}, nil
+		}
+		response, err := next(ctx, request)
+		if response != nil {
+			response.Headers = setHeaders(response.Headers, p.Headers(request.Headers["origin"], false))
+		}
+		return response, err
+	}
+}
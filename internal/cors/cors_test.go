@@ -0,0 +1,142 @@
+package cors
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func request(method, origin string, headers map[string]string) events.APIGatewayV2HTTPRequest {
+	request := events.APIGatewayV2HTTPRequest{Headers: map[string]string{}}
+	request.RequestContext.HTTP.Method = method
+	if origin != "" {
+		request.Headers["origin"] = origin
+	}
+	for k, v := range headers {
+		request.Headers[k] = v
+	}
+	return request
+}
+
+func TestPolicy_Handler(t *testing.T) {
+	ok := func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "application/json", "Access-Control-Allow-Origin": "*", "vary": "Accept-Encoding"},
+		}, nil
+	}
+	preflight := map[string]string{"access-control-request-method": "POST"}
+
+	tests := []struct {
+		name        string
+		policy      Policy
+		request     events.APIGatewayV2HTTPRequest
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "any origin",
+			policy:      Policy{AllowedOrigins: []string{"*"}, ExposedHeaders: []string{"X-Correlation-ID"}},
+			request:     request(http.MethodGet, "https://app.example.com", nil),
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "*", "Access-Control-Expose-Headers": "X-Correlation-ID", "vary": "Accept-Encoding"},
+		},
+		{
+			name:        "listed origin with credentials",
+			policy:      Policy{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+			request:     request(http.MethodGet, "https://app.example.com", nil),
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "https://app.example.com", "Access-Control-Allow-Credentials": "true", "Vary": "Accept-Encoding, Origin"},
+		},
+		{
+			name:        "unlisted origin",
+			policy:      Policy{AllowedOrigins: []string{"https://app.example.com"}},
+			request:     request(http.MethodGet, "https://evil.example.com", nil),
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Accept-Encoding, Origin", "Content-Type": "application/json"},
+		},
+		{
+			name:        "no origins allowed",
+			policy:      Policy{},
+			request:     request(http.MethodGet, "https://app.example.com", nil),
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Vary": "", "vary": "Accept-Encoding"},
+		},
+		{
+			name:       "preflight",
+			policy:     Policy{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{"GET", "POST"}, AllowedHeaders: []string{"Content-Type", "X-API-Key"}, MaxAge: defaultMaxAge},
+			request:    request(http.MethodOptions, "https://app.example.com", preflight),
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, X-API-Key",
+				"Access-Control-Max-Age":       "3600",
+			},
+		},
+		{
+			name:        "preflight from an unlisted origin",
+			policy:      Policy{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{"GET"}},
+			request:     request(http.MethodOptions, "https://evil.example.com", preflight),
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+		{
+			name:        "options without a requested method reaches the handler",
+			policy:      Policy{AllowedOrigins: []string{"*"}},
+			request:     request(http.MethodOptions, "https://app.example.com", nil),
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "*"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := tt.policy.Handler(ok)(context.Background(), tt.request)
+			if err != nil {
+				t.Fatalf("Handler() error = %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			for name, want := range tt.wantHeaders {
+				if got := response.Headers[name]; got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicy_StreamHandler(t *testing.T) {
+	policy := Policy{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{"POST"}}
+	stream := func(context.Context, events.APIGatewayV2HTTPRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+		return &events.LambdaFunctionURLStreamingResponse{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "text/event-stream"},
+			Body:       strings.NewReader("event: done\n\n"),
+		}, nil
+	}
+	handler := policy.StreamHandler(stream)
+
+	response, err := handler(context.Background(), request(http.MethodPost, "https://app.example.com", nil))
+	if err != nil {
+		t.Fatalf("StreamHandler() error = %v", err)
+	}
+	if response.Headers["Access-Control-Allow-Origin"] != "https://app.example.com" || response.Headers["Content-Type"] != "text/event-stream" {
+		t.Errorf("Headers = %v", response.Headers)
+	}
+
+	response, err = handler(context.Background(), request(http.MethodOptions, "https://app.example.com", map[string]string{"access-control-request-method": "POST"}))
+	if err != nil {
+		t.Fatalf("StreamHandler() preflight error = %v", err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusNoContent || response.Headers["Access-Control-Allow-Methods"] != "POST" || len(body) != 0 {
+		t.Errorf("preflight = %d %v %q", response.StatusCode, response.Headers, body)
+	}
+}
@@ -0,0 +1,132 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/jrzesz33/rez_agent/internal/notification"
+)
+
+// narrativePrompt asks the model for the opening of the digest email
+const narrativePrompt = `You write the opening of a weekly email for a golfer's tee time booking assistant.
+In two or three short, friendly paragraphs, summarize the week's activity and the tee times coming up.
+Use only the facts you are given, do not invent numbers, and do not add a greeting, sign-off, or headings.`
+
+// narrativeMaxTokens bounds the narrative, which only needs a few paragraphs
+const narrativeMaxTokens = 400
+
+// Narrator writes the prose opening of a digest from its report
+type Narrator interface {
+	Narrate(ctx context.Context, report *Report) (string, error)
+}
+
+// BedrockNarrator writes digest narratives with a Bedrock model
+type BedrockNarrator struct {
+	client  *bedrockruntime.Client
+	modelID string
+}
+
+// NewBedrockNarrator creates a narrator using the given model
+func NewBedrockNarrator(client *bedrockruntime.Client, modelID string) *BedrockNarrator {
+	return &BedrockNarrator{
+		client:  client,
+		modelID: modelID,
+	}
+}
+
+// Narrate asks the model to summarize the report
+func (n *BedrockNarrator) Narrate(ctx context.Context, report *Report) (string, error) {
+	output, err := n.client.Converse(ctx, &bedrockruntime.ConverseInput{
+		ModelId: aws.String(n.modelID),
+		System: []types.SystemContentBlock{
+			&types.SystemContentBlockMemberText{Value: narrativePrompt},
+		},
+		Messages: []types.Message{{
+			Role:    types.ConversationRoleUser,
+			Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: report.Text()}},
+		}},
+		InferenceConfig: &types.InferenceConfiguration{
+			MaxTokens: aws.Int32(narrativeMaxTokens),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("bedrock converse failed: %w", err)
+	}
+
+	message, ok := output.Output.(*types.ConverseOutputMemberMessage)
+	if !ok {
+		return "", fmt.Errorf("bedrock returned no message")
+	}
+	var text strings.Builder
+	for _, block := range message.Value.Content {
+		if t, ok := block.(*types.ContentBlockMemberText); ok {
+			text.WriteString(t.Value)
+		}
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", fmt.Errorf("bedrock returned an empty narrative")
+	}
+	return strings.TrimSpace(text.String()), nil
+}
+
+// Service builds the weekly digest and emails it
+type Service struct {
+	builder    *Builder
+	narrator   Narrator
+	email      notification.EmailSender
+	recipients []string
+	logger     *slog.Logger
+}
+
+// NewService creates a digest service emailing the recipients
+func NewService(builder *Builder, email notification.EmailSender, recipients []string, logger *slog.Logger) *Service {
+	return &Service{
+		builder:    builder,
+		email:      email,
+		recipients: recipients,
+		logger:     logger,
+	}
+}
+
+// SetNarrator opens the digest with a written summary; without one the email is the facts alone
+func (s *Service) SetNarrator(narrator Narrator) {
+	s.narrator = narrator
+}
+
+// Send emails the digest of the week ending at now
+func (s *Service) Send(ctx context.Context, now time.Time) error {
+	report, err := s.builder.Build(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	body := report.Text()
+	if s.narrator != nil {
+		// The facts are the point of the digest, so a failed narrative does not hold it back
+		narrative, err := s.narrator.Narrate(ctx, report)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to write digest narrative", slog.String("error", err.Error()))
+		} else {
+			body = narrative + "\n\n" + body
+		}
+	}
+
+	subject := fmt.Sprintf("rez_agent weekly digest: %s - %s", report.Start.Format("Jan 2"), report.End.Format("Jan 2"))
+	if err := s.email.SendEmail(ctx, s.recipients, subject, body); err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "weekly digest sent",
+		slog.Int("recipients", len(s.recipients)),
+		slog.Int("messages", report.Messages),
+		slog.Int("bookings", report.Bookings),
+		slog.Int("agent_runs", report.AgentRuns),
+	)
+	return nil
+}
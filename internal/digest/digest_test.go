@@ -0,0 +1,163 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/internal/scheduler"
+)
+
+type fakeRecordings struct {
+	recordings map[string]*scheduler.RunRecording
+}
+
+func (f *fakeRecordings) ListRecordingsSince(ctx context.Context, since time.Time) ([]string, error) {
+	keys := make([]string, 0, len(f.recordings))
+	for key := range f.recordings {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (f *fakeRecordings) GetRecording(ctx context.Context, key string) (*scheduler.RunRecording, error) {
+	return f.recordings[key], nil
+}
+
+type fakeEmail struct {
+	to      []string
+	subject string
+	body    string
+}
+
+func (f *fakeEmail) SendEmail(ctx context.Context, to []string, subject, body string) error {
+	f.to, f.subject, f.body = to, subject, body
+	return nil
+}
+
+type fakeNarrator struct {
+	narrative string
+	err       error
+}
+
+func (f *fakeNarrator) Narrate(ctx context.Context, report *Report) (string, error) {
+	return f.narrative, f.err
+}
+
+func newTestBuilder(t *testing.T, now time.Time) *Builder {
+	t.Helper()
+	ctx := context.Background()
+
+	messages := repository.NewMemoryRepository()
+	save := func(messageType models.MessageType, status models.Status, operation string, created time.Time) {
+		msg := models.NewMessage("test", map[string]interface{}{"operation": operation}, "1.0", models.StageDev, messageType, nil)
+		msg.Status = status
+		msg.CreatedDate = created
+		if err := messages.SaveMessage(ctx, msg); err != nil {
+			t.Fatalf("SaveMessage() error = %v", err)
+		}
+	}
+	save(models.MessageTypeWebAction, models.StatusCompleted, "book_tee_time", now.Add(-24*time.Hour))
+	save(models.MessageTypeWebAction, models.StatusFailed, "book_tee_time", now.Add(-24*time.Hour))
+	save(models.MessageTypeWebAction, models.StatusCompleted, "search_tee_times", now.Add(-48*time.Hour))
+	save(models.MessageTypeNotification, models.StatusCompleted, "", now.Add(-2*time.Hour))
+	save(models.MessageTypeNotification, models.StatusCompleted, "", now.Add(-10*24*time.Hour))
+
+	reservations := repository.NewMemoryReservationRepository()
+	if err := reservations.SaveSnapshot(ctx, &models.ReservationSnapshot{
+		SecretName: "shared",
+		CourseID:   1,
+		CourseName: "Totteridge",
+		Reservations: []models.CachedReservation{
+			{ReservationID: 2, TeeTime: now.Add(72 * time.Hour), Players: 2},
+			{ReservationID: 1, TeeTime: now.Add(24 * time.Hour), Players: 4},
+			{ReservationID: 3, TeeTime: now.Add(-24 * time.Hour), Players: 1},
+		},
+		SyncedAt: now,
+	}); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	builder := NewBuilder(messages, models.StageDev, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	builder.SetReservations(reservations, []string{"shared"})
+	builder.SetRunRecordings(&fakeRecordings{recordings: map[string]*scheduler.RunRecording{
+		"a": {ModelID: "amazon.nova-lite-v1:0", StartedAt: now.Add(-time.Hour), InputTokens: 1000, OutputTokens: 1000},
+		"b": {ModelID: "unknown-model", StartedAt: now.Add(-time.Hour)},
+		"c": {ModelID: "amazon.nova-lite-v1:0", StartedAt: now.Add(-8 * 24 * time.Hour), InputTokens: 1000},
+	}})
+	return builder
+}
+
+func TestBuilder_Build(t *testing.T) {
+	now := time.Date(2030, 6, 8, 18, 0, 0, 0, time.UTC)
+	report, err := newTestBuilder(t, now).Build(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if report.Messages != 4 {
+		t.Errorf("Messages = %d, want the 4 from the last week", report.Messages)
+	}
+	if report.MessagesByStatus[models.StatusFailed] != 1 || report.MessagesByType[models.MessageTypeWebAction] != 3 {
+		t.Errorf("MessagesByStatus = %v, MessagesByType = %v", report.MessagesByStatus, report.MessagesByType)
+	}
+	if report.Bookings != 1 {
+		t.Errorf("Bookings = %d, want the one completed booking", report.Bookings)
+	}
+	if report.AgentRuns != 2 || report.UnpricedRuns != 1 {
+		t.Errorf("AgentRuns = %d, UnpricedRuns = %d; want 2 and 1", report.AgentRuns, report.UnpricedRuns)
+	}
+	if want := 0.0003; report.AgentCost < want-1e-9 || report.AgentCost > want+1e-9 {
+		t.Errorf("AgentCost = %v, want %v", report.AgentCost, want)
+	}
+	if len(report.UpcomingTeeTimes) != 2 || report.UpcomingTeeTimes[0].Players != 4 {
+		t.Errorf("UpcomingTeeTimes = %+v, want the 2 upcoming, earliest first", report.UpcomingTeeTimes)
+	}
+
+	text := report.Text()
+	for _, want := range []string{"Messages processed: 4", "Bookings made: 1", "not included", "Totteridge, 4 player(s)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() is missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestService_Send(t *testing.T) {
+	now := time.Date(2030, 6, 8, 18, 0, 0, 0, time.UTC)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name     string
+		narrator Narrator
+		wantOpen string
+	}{
+		{"narrative", &fakeNarrator{narrative: "A busy week on the course."}, "A busy week on the course.\n\nActivity from"},
+		{"narrative fails", &fakeNarrator{err: errors.New("throttled")}, "Activity from"},
+		{"no narrator", nil, "Activity from"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := &fakeEmail{}
+			service := NewService(newTestBuilder(t, now), email, []string{"golfer@example.com"}, logger)
+			if tt.narrator != nil {
+				service.SetNarrator(tt.narrator)
+			}
+
+			if err := service.Send(context.Background(), now); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if len(email.to) != 1 || email.subject != "rez_agent weekly digest: Jun 1 - Jun 8" {
+				t.Errorf("sent to %v with subject %q", email.to, email.subject)
+			}
+			if !strings.HasPrefix(email.body, tt.wantOpen) {
+				t.Errorf("body = %q, want it to start with %q", email.body, tt.wantOpen)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/internal/scheduler"
+)
+
+// Period is how far back a digest reports
+const Period = 7 * 24 * time.Hour
+
+// messageSample caps the messages scanned for a digest, matching GET /api/metrics
+const messageSample = 1000
+
+// bookingOperations are the golf operations that reserve a tee time
+var bookingOperations = map[string]bool{
+	"book_tee_time":    true,
+	"complete_booking": true,
+}
+
+// TeeTime is an upcoming reservation listed in the digest
+type TeeTime struct {
+	CourseName string
+	TeeTime    time.Time
+	Players    int
+}
+
+// Report is the activity of one digest period
+type Report struct {
+	Start time.Time
+	End   time.Time
+
+	Messages         int
+	MessagesByStatus map[models.Status]int
+	MessagesByType   map[models.MessageType]int
+
+	// Bookings counts completed booking web actions; a booking held for approval counts once
+	// when the hold is made and once when it is completed
+	Bookings int
+
+	AgentRuns int
+	// AgentCost is the estimated Bedrock cost of the runs whose model has known pricing
+	AgentCost    float64
+	UnpricedRuns int
+
+	UpcomingTeeTimes []TeeTime
+}
+
+// RecordingSource lists and reads scheduled agent run recordings
+type RecordingSource interface {
+	ListRecordingsSince(ctx context.Context, since time.Time) ([]string, error)
+	GetRecording(ctx context.Context, key string) (*scheduler.RunRecording, error)
+}
+
+// Builder gathers a digest report from the message table, run recordings, and synced reservations
+type Builder struct {
+	messages     repository.MessageRepository
+	stage        models.Stage
+	recordings   RecordingSource
+	reservations repository.ReservationRepository
+	secretNames  []string
+	logger       *slog.Logger
+}
+
+// NewBuilder creates a report builder for the stage's messages
+func NewBuilder(messages repository.MessageRepository, stage models.Stage, logger *slog.Logger) *Builder {
+	return &Builder{
+		messages: messages,
+		stage:    stage,
+		logger:   logger,
+	}
+}
+
+// SetRunRecordings adds scheduled agent runs and their cost to the report
+func (b *Builder) SetRunRecordings(source RecordingSource) {
+	b.recordings = source
+}
+
+// SetReservations adds the upcoming synced reservations of the given credentials to the report
+func (b *Builder) SetReservations(repo repository.ReservationRepository, secretNames []string) {
+	b.reservations = repo
+	b.secretNames = secretNames
+}
+
+// Build reports the period ending at now
+func (b *Builder) Build(ctx context.Context, now time.Time) (*Report, error) {
+	report := &Report{
+		Start:            now.Add(-Period),
+		End:              now,
+		MessagesByStatus: make(map[models.Status]int),
+		MessagesByType:   make(map[models.MessageType]int),
+	}
+
+	stage := b.stage
+	messages, err := b.messages.ListMessages(ctx, &stage, nil, messageSample)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	for _, msg := range messages {
+		if msg.CreatedDate.Before(report.Start) || msg.CreatedDate.After(now) {
+			continue
+		}
+		report.Messages++
+		report.MessagesByStatus[msg.Status]++
+		report.MessagesByType[msg.MessageType]++
+
+		operation, _ := msg.Arguments["operation"].(string)
+		if msg.MessageType == models.MessageTypeWebAction && msg.Status == models.StatusCompleted && bookingOperations[operation] {
+			report.Bookings++
+		}
+	}
+
+	// Runs and tee times are extras: a digest without them is still worth sending
+	if b.recordings != nil {
+		if err := b.addAgentRuns(ctx, report); err != nil {
+			b.logger.WarnContext(ctx, "failed to add agent runs to digest", slog.String("error", err.Error()))
+		}
+	}
+	if b.reservations != nil {
+		if err := b.addTeeTimes(ctx, report, now); err != nil {
+			b.logger.WarnContext(ctx, "failed to add tee times to digest", slog.String("error", err.Error()))
+		}
+	}
+
+	return report, nil
+}
+
+// addAgentRuns counts the period's scheduled agent runs and their Bedrock cost
+func (b *Builder) addAgentRuns(ctx context.Context, report *Report) error {
+	keys, err := b.recordings.ListRecordingsSince(ctx, report.Start)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		recording, err := b.recordings.GetRecording(ctx, key)
+		if err != nil {
+			return err
+		}
+		if recording.StartedAt.Before(report.Start) || recording.StartedAt.After(report.End) {
+			continue
+		}
+		report.AgentRuns++
+		if cost, ok := recording.EstimatedCost(); ok {
+			report.AgentCost += cost
+		} else {
+			report.UnpricedRuns++
+		}
+	}
+	return nil
+}
+
+// addTeeTimes lists the upcoming synced reservations, earliest first
+func (b *Builder) addTeeTimes(ctx context.Context, report *Report, now time.Time) error {
+	for _, secretName := range b.secretNames {
+		snapshots, err := b.reservations.ListSnapshots(ctx, secretName)
+		if err != nil {
+			return err
+		}
+		for _, snapshot := range snapshots {
+			for _, reservation := range snapshot.Upcoming(now) {
+				report.UpcomingTeeTimes = append(report.UpcomingTeeTimes, TeeTime{
+					CourseName: snapshot.CourseName,
+					TeeTime:    reservation.TeeTime,
+					Players:    reservation.Players,
+				})
+			}
+		}
+	}
+	sort.Slice(report.UpcomingTeeTimes, func(i, j int) bool {
+		return report.UpcomingTeeTimes[i].TeeTime.Before(report.UpcomingTeeTimes[j].TeeTime)
+	})
+	return nil
+}
+
+// Text renders the report's facts as plain text, used as the body of the email and as the input
+// the narrative is written from
+func (r *Report) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Activity from %s to %s\n\n", r.Start.Format("Mon, Jan 2"), r.End.Format("Mon, Jan 2"))
+
+	fmt.Fprintf(&b, "Messages processed: %d\n", r.Messages)
+	for _, status := range sortedKeys(r.MessagesByStatus) {
+		fmt.Fprintf(&b, "  %s: %d\n", status, r.MessagesByStatus[status])
+	}
+	for _, messageType := range sortedKeys(r.MessagesByType) {
+		fmt.Fprintf(&b, "  %s messages: %d\n", messageType, r.MessagesByType[messageType])
+	}
+
+	fmt.Fprintf(&b, "\nBookings made: %d\n", r.Bookings)
+	fmt.Fprintf(&b, "Scheduled agent runs: %d\n", r.AgentRuns)
+	fmt.Fprintf(&b, "Estimated agent cost: $%.4f", r.AgentCost)
+	if r.UnpricedRuns > 0 {
+		fmt.Fprintf(&b, " (%d run(s) on models without known pricing not included)", r.UnpricedRuns)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("\nUpcoming tee times:\n")
+	if len(r.UpcomingTeeTimes) == 0 {
+		b.WriteString("  None\n")
+	}
+	for _, teeTime := range r.UpcomingTeeTimes {
+		fmt.Fprintf(&b, "  %s at %s, %d player(s)\n", teeTime.TeeTime.Format("Mon, Jan 2 at 3:04 PM"), teeTime.CourseName, teeTime.Players)
+	}
+	return b.String()
+}
+
+// sortedKeys returns a count map's keys in sorted order, so the report text is stable
+func sortedKeys[K ~string](counts map[K]int) []K {
+	keys := make([]K, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
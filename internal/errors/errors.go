@@ -0,0 +1,172 @@
+// Package errors classifies failures so callers decide on retries and HTTP status codes by
+// kind, not by matching error strings. Errors are tagged with Wrap or Newf and keep their
+// message; Kind also recognizes HTTP status errors, open circuits and AWS API errors.
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// Error kinds
+var (
+	// ErrValidation is a request that can never succeed as sent
+	ErrValidation = stderrors.New("validation failed")
+	// ErrNotFound is a request for something that does not exist
+	ErrNotFound = stderrors.New("not found")
+	// ErrAuth is a missing, invalid or expired credential, or a denied permission
+	ErrAuth = stderrors.New("authentication failed")
+	// ErrThrottled is a request rejected by a rate limit
+	ErrThrottled = stderrors.New("throttled")
+	// ErrProviderUnavailable is an upstream service that is down, failing or timing out
+	ErrProviderUnavailable = stderrors.New("provider unavailable")
+)
+
+// kinds in the order Kind checks them
+var kinds = []error{ErrValidation, ErrNotFound, ErrAuth, ErrThrottled, ErrProviderUnavailable}
+
+// kindError tags an error with a kind without changing its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// Wrap tags err with kind; a nil err or kind returns err unchanged
+func Wrap(kind, err error) error {
+	if err == nil || kind == nil {
+		return err
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// Newf formats an error tagged with kind
+func Newf(kind error, format string, args ...any) error {
+	return Wrap(kind, fmt.Errorf(format, args...))
+}
+
+// Kind returns the kind of err, or nil if it is unclassified
+func Kind(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, kind := range kinds {
+		if stderrors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	var statusErr *httpclient.StatusError
+	if stderrors.As(err, &statusErr) {
+		return StatusKind(statusErr.StatusCode)
+	}
+	if stderrors.Is(err, httpclient.ErrCircuitOpen) {
+		return ErrProviderUnavailable
+	}
+	var rpcErr *protocol.JSONRPCError
+	if stderrors.As(err, &rpcErr) {
+		return RPCKind(rpcErr.Code)
+	}
+	var apiErr smithy.APIError
+	if stderrors.As(err, &apiErr) {
+		return awsKind(apiErr.ErrorCode())
+	}
+	return nil
+}
+
+// Is reports whether err is of kind
+func Is(err, kind error) bool {
+	return kind != nil && Kind(err) == kind
+}
+
+// IsRetryable reports whether retrying the operation that returned err could succeed.
+// Unclassified errors are retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch Kind(err) {
+	case ErrValidation, ErrNotFound, ErrAuth:
+		return false
+	}
+	return true
+}
+
+// HTTPStatus returns the HTTP status code to answer err with
+func HTTPStatus(err error) int {
+	switch Kind(err) {
+	case ErrValidation:
+		return http.StatusBadRequest
+	case ErrNotFound:
+		return http.StatusNotFound
+	case ErrAuth:
+		return http.StatusUnauthorized
+	case ErrThrottled:
+		return http.StatusTooManyRequests
+	case ErrProviderUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// StatusKind returns the kind of an upstream HTTP error status, or nil if it has none
+func StatusKind(statusCode int) error {
+	switch {
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return ErrAuth
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusTooManyRequests:
+		return ErrThrottled
+	case statusCode == http.StatusRequestTimeout, statusCode >= 500:
+		return ErrProviderUnavailable
+	case statusCode >= 400:
+		return ErrValidation
+	}
+	return nil
+}
+
+// RPCKind returns the kind of a JSON-RPC error code, or nil if it has none
+func RPCKind(code int) error {
+	switch code {
+	case protocol.ErrCodeParseError, protocol.ErrCodeInvalidRequest, protocol.ErrCodeInvalidParams:
+		return ErrValidation
+	case protocol.ErrCodeMethodNotFound, protocol.ErrCodeToolNotFound:
+		return ErrNotFound
+	case protocol.ErrCodeAuthFailure:
+		return ErrAuth
+	case protocol.ErrCodeAsyncTimeout:
+		return ErrProviderUnavailable
+	}
+	return nil
+}
+
+// awsKind returns the kind of an AWS API error code, or nil if it has none
+func awsKind(code string) error {
+	switch code {
+	case "ValidationException", "InvalidParameterException", "InvalidParameterValueException":
+		return ErrValidation
+	case "ResourceNotFoundException", "NotFoundException", "ParameterNotFound":
+		return ErrNotFound
+	case "AccessDeniedException", "UnrecognizedClientException", "ExpiredTokenException", "InvalidSignatureException":
+		return ErrAuth
+	case "ThrottlingException", "TooManyRequestsException", "ProvisionedThroughputExceededException", "ServiceQuotaExceededException":
+		return ErrThrottled
+	case "ServiceUnavailableException", "InternalServerException", "InternalFailure", "ModelNotReadyException", "ModelTimeoutException":
+		return ErrProviderUnavailable
+	}
+	return nil
+}
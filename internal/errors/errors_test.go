@@ -0,0 +1,119 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+func TestWrap(t *testing.T) {
+	cause := stderrors.New("teeSheetId is required")
+	err := Wrap(ErrValidation, cause)
+
+	if err.Error() != cause.Error() {
+		t.Errorf("Error() = %q, want %q", err.Error(), cause.Error())
+	}
+	if !stderrors.Is(err, ErrValidation) || !stderrors.Is(err, cause) {
+		t.Error("Wrap() error does not match both its kind and its cause")
+	}
+	if Wrap(ErrValidation, nil) != nil {
+		t.Error("Wrap(kind, nil) != nil")
+	}
+	if Wrap(nil, cause) != cause {
+		t.Error("Wrap(nil, err) did not return err")
+	}
+}
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"nil", nil, nil},
+		{"unclassified", stderrors.New("boom"), nil},
+		{"tagged", Newf(ErrAuth, "token expired"), ErrAuth},
+		{"wrapped tag", fmt.Errorf("lock failed: %w", Newf(ErrThrottled, "slow down")), ErrThrottled},
+		{"http 400", &httpclient.StatusError{StatusCode: http.StatusBadRequest}, ErrValidation},
+		{"http 401", fmt.Errorf("request failed after 3 attempts: %w", &httpclient.StatusError{StatusCode: http.StatusUnauthorized}), ErrAuth},
+		{"http 404", &httpclient.StatusError{StatusCode: http.StatusNotFound}, ErrNotFound},
+		{"http 429", &httpclient.StatusError{StatusCode: http.StatusTooManyRequests}, ErrThrottled},
+		{"http 502", &httpclient.StatusError{StatusCode: http.StatusBadGateway}, ErrProviderUnavailable},
+		{"circuit open", fmt.Errorf("search failed: %w", httpclient.ErrCircuitOpen), ErrProviderUnavailable},
+		{"json-rpc invalid params", protocol.NewJSONRPCError(protocol.ErrCodeInvalidParams, "bad", nil), ErrValidation},
+		{"json-rpc internal", protocol.NewJSONRPCError(protocol.ErrCodeInternalError, "oops", nil), nil},
+		{"aws throttling", &smithy.GenericAPIError{Code: "ThrottlingException"}, ErrThrottled},
+		{"aws access denied", fmt.Errorf("bedrock converse failed: %w", &smithy.GenericAPIError{Code: "AccessDeniedException"}), ErrAuth},
+		{"aws unknown", &smithy.GenericAPIError{Code: "SomethingElse"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Kind(tt.err); got != tt.want {
+				t.Errorf("Kind() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"unclassified", stderrors.New("connection reset"), true},
+		{"context deadline", context.DeadlineExceeded, true},
+		{"validation", Newf(ErrValidation, "invalid"), false},
+		{"not found", Newf(ErrNotFound, "course"), false},
+		{"auth", &httpclient.StatusError{StatusCode: http.StatusForbidden}, false},
+		{"throttled", Newf(ErrThrottled, "429"), true},
+		{"unavailable", &httpclient.StatusError{StatusCode: http.StatusServiceUnavailable}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{Newf(ErrValidation, "bad"), http.StatusBadRequest},
+		{Newf(ErrNotFound, "missing"), http.StatusNotFound},
+		{Newf(ErrAuth, "denied"), http.StatusUnauthorized},
+		{Newf(ErrThrottled, "slow"), http.StatusTooManyRequests},
+		{Newf(ErrProviderUnavailable, "down"), http.StatusServiceUnavailable},
+		{stderrors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := HTTPStatus(tt.err); got != tt.want {
+			t.Errorf("HTTPStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestIs(t *testing.T) {
+	err := fmt.Errorf("reservation failed: %w", &httpclient.StatusError{StatusCode: http.StatusTooManyRequests})
+	if !Is(err, ErrThrottled) {
+		t.Error("Is(429, ErrThrottled) = false, want true")
+	}
+	if Is(err, ErrAuth) {
+		t.Error("Is(429, ErrAuth) = true, want false")
+	}
+}
@@ -0,0 +1,173 @@
+// Package health checks that a service's downstream dependencies are reachable and reports the
+// result per component, for the health endpoints uptime monitors poll.
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/smithy-go"
+)
+
+// DefaultTimeout bounds each check, so a hung dependency still gets a report well inside API
+// Gateway's 30 second limit
+const DefaultTimeout = 5 * time.Second
+
+// Component statuses, and the overall status when every component is healthy or any is not
+const (
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+)
+
+// Check verifies one dependency. Run returns nil when the dependency is reachable.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// ComponentStatus is the result of one check. Error is an AWS error code or a short reason, never
+// the full error, because health endpoints are unauthenticated.
+type ComponentStatus struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the body of a health response
+type Report struct {
+	Status     string            `json:"status"`
+	Service    string            `json:"service"`
+	Stage      string            `json:"stage"`
+	Timestamp  string            `json:"timestamp"`
+	Components []ComponentStatus `json:"components"`
+}
+
+// HTTPStatus is 200 when every component is healthy and 503 otherwise, so monitors that only look
+// at the status code still alert
+func (r Report) HTTPStatus() int {
+	if r.Status == StatusHealthy {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+// Run runs checks concurrently, each bounded by timeout, and reports their results in the order
+// given
+func Run(ctx context.Context, service, stage string, timeout time.Duration, checks []Check) Report {
+	report := Report{
+		Status:     StatusHealthy,
+		Service:    service,
+		Stage:      stage,
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Components: make([]ComponentStatus, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Components[i] = runCheck(ctx, check, timeout)
+		}()
+	}
+	wg.Wait()
+
+	for _, component := range report.Components {
+		if component.Status != StatusHealthy {
+			report.Status = StatusUnhealthy
+		}
+	}
+	return report
+}
+
+// runCheck runs one check and times it
+func runCheck(ctx context.Context, check Check, timeout time.Duration) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	status := ComponentStatus{
+		Name:      check.Name,
+		Status:    StatusHealthy,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = StatusUnhealthy
+		status.Error = reason(ctx, err)
+	}
+	return status
+}
+
+// reason summarizes err without the resource names and account details its message may contain
+func reason(ctx context.Context, err error) string {
+	var apiErr smithy.APIError
+	var state stateError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "timed out"
+	case errors.As(err, &apiErr):
+		return apiErr.ErrorCode()
+	case errors.As(err, &state):
+		return string(state)
+	default:
+		return "unreachable"
+	}
+}
+
+// stateError is a dependency that answered but is not usable; its message is safe to report
+type stateError string
+
+func (e stateError) Error() string {
+	return string(e)
+}
+
+// DescribeTableAPI is the part of the DynamoDB client DynamoDBTable uses
+type DescribeTableAPI interface {
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+}
+
+// DynamoDBTable checks that table exists and is active
+func DynamoDBTable(name string, client DescribeTableAPI, table string) Check {
+	return Check{Name: name, Run: func(ctx context.Context) error {
+		out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+		if err != nil {
+			return err
+		}
+		if out.Table == nil {
+			return stateError("table not found")
+		}
+		switch out.Table.TableStatus {
+		case "ACTIVE", "UPDATING":
+			return nil
+		default:
+			return stateError("table " + string(out.Table.TableStatus))
+		}
+	}}
+}
+
+// DescribeSecretAPI is the part of the Secrets Manager client Secret uses
+type DescribeSecretAPI interface {
+	DescribeSecret(ctx context.Context, params *secretsmanager.DescribeSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.DescribeSecretOutput, error)
+}
+
+// Secret checks that secretID exists and is not scheduled for deletion, without reading its value
+func Secret(name string, client DescribeSecretAPI, secretID string) Check {
+	return Check{Name: name, Run: func(ctx context.Context) error {
+		out, err := client.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: aws.String(secretID)})
+		if err != nil {
+			return err
+		}
+		if out.DeletedDate != nil {
+			return stateError("secret scheduled for deletion")
+		}
+		return nil
+	}}
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/smithy-go"
+)
+
+type fakeDynamo struct {
+	status types.TableStatus
+	err    error
+}
+
+func (f *fakeDynamo) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &dynamodb.DescribeTableOutput{Table: &types.TableDescription{TableName: params.TableName, TableStatus: f.status}}, nil
+}
+
+type fakeSecrets struct {
+	deleted bool
+	err     error
+}
+
+func (f *fakeSecrets) DescribeSecret(ctx context.Context, params *secretsmanager.DescribeSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.DescribeSecretOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := &secretsmanager.DescribeSecretOutput{Name: params.SecretId}
+	if f.deleted {
+		out.DeletedDate = aws.Time(time.Now())
+	}
+	return out, nil
+}
+
+func TestRun(t *testing.T) {
+	accessDenied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "User: arn:aws:sts::123456789012:assumed-role/mcp is not authorized"}
+	hung := Check{Name: "hung", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	tests := []struct {
+		name       string
+		checks     []Check
+		wantStatus int
+		wantErrors []string
+	}{
+		{
+			name: "all healthy",
+			checks: []Check{
+				DynamoDBTable("dynamodb", &fakeDynamo{status: types.TableStatusActive}, "messages"),
+				Secret("secrets", &fakeSecrets{}, "rez-agent/golf/credentials-dev"),
+			},
+			wantStatus: http.StatusOK,
+			wantErrors: []string{"", ""},
+		},
+		{
+			name: "AWS error reported by code only",
+			checks: []Check{
+				DynamoDBTable("dynamodb", &fakeDynamo{status: types.TableStatusActive}, "messages"),
+				Secret("secrets", &fakeSecrets{err: accessDenied}, "rez-agent/golf/credentials-dev"),
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantErrors: []string{"", "AccessDeniedException"},
+		},
+		{
+			name: "unusable dependencies",
+			checks: []Check{
+				DynamoDBTable("dynamodb", &fakeDynamo{status: types.TableStatusDeleting}, "messages"),
+				Secret("secrets", &fakeSecrets{deleted: true}, "rez-agent/golf/credentials-dev"),
+				{Name: "network", Run: func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.1:443: connection refused") }},
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantErrors: []string{"table DELETING", "secret scheduled for deletion", "unreachable"},
+		},
+		{
+			name:       "hung dependency times out",
+			checks:     []Check{hung},
+			wantStatus: http.StatusServiceUnavailable,
+			wantErrors: []string{"timed out"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Run(context.Background(), "mcp", "dev", 10*time.Millisecond, tt.checks)
+			if got := report.HTTPStatus(); got != tt.wantStatus {
+				t.Errorf("HTTPStatus() = %d, want %d", got, tt.wantStatus)
+			}
+			if len(report.Components) != len(tt.wantErrors) {
+				t.Fatalf("Components = %+v, want %d", report.Components, len(tt.wantErrors))
+			}
+			for i, component := range report.Components {
+				if component.Name != tt.checks[i].Name {
+					t.Errorf("Components[%d].Name = %q, want %q", i, component.Name, tt.checks[i].Name)
+				}
+				if component.Error != tt.wantErrors[i] {
+					t.Errorf("Components[%d].Error = %q, want %q", i, component.Error, tt.wantErrors[i])
+				}
+				if wantHealthy := tt.wantErrors[i] == ""; (component.Status == StatusHealthy) != wantHealthy {
+					t.Errorf("Components[%d].Status = %q, want healthy = %v", i, component.Status, wantHealthy)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,507 @@
+// Package localrun starts a Lambda handler either in the Lambda runtime or, in local mode, in an
+// in-process HTTP server, so the whole pipeline can run on a laptop against LocalStack.
+package localrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/google/uuid"
+
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+// Default local server addresses, one port per Lambda so they can all run at once
+const (
+	WebAPIAddr           = ":8080"
+	MCPAddr              = ":8081"
+	ProcessorAddr        = ":8082"
+	WebActionAddr        = ":8083"
+	SchedulerAddr        = ":8084"
+	TriageAddr           = ":8085"
+	AlarmsAddr           = ":8086"
+	RotationAddr         = ":8087"
+	ReservationSyncAddr  = ":8088"
+	DigestAddr           = ":8089"
+	CanaryAddr           = ":8090"
+	AgentAddr            = ":8091"
+	OutboxAddr           = ":8092"
+	SweeperAddr          = ":8093"
+	ScheduleCreationAddr = ":8094"
+	ReconcilerAddr       = ":8095"
+)
+
+// sqsPollWait is the long-poll wait of the local queue poller
+const sqsPollWait = 10 * time.Second
+
+// WarmerPayload is the scheduled ping that keeps a function's instances warm. Handlers started
+// through this package answer it without running, so a ping never touches the API or a queue.
+const WarmerPayload = `{"warmer":true}`
+
+// APIHandler handles API Gateway HTTP API (payload v2) requests
+type APIHandler func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error)
+
+// StreamHandler handles Lambda function URL requests in RESPONSE_STREAM mode, whose response body
+// is sent as the handler writes it
+type StreamHandler func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (*events.LambdaFunctionURLStreamingResponse, error)
+
+// SQSHandler handles SQS event batches with partial batch failures
+type SQSHandler func(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error)
+
+// NewSecretsManager reads secrets from Secrets Manager, or from the local secrets file in local mode
+func NewSecretsManager(cfg *config.Config, awsCfg aws.Config, logger *slog.Logger) *secrets.Manager {
+	if cfg.Local {
+		logger.Info("reading secrets from local file", slog.String("path", cfg.LocalSecretsFile))
+		return secrets.NewFileManager(cfg.LocalSecretsFile, logger)
+	}
+	return secrets.NewManager(awsCfg, logger)
+}
+
+// NewEventPublisher publishes domain events to the configured event bus. It returns nil, which
+// publishes nothing, when no bus is configured.
+func NewEventPublisher(cfg *config.Config, awsCfg aws.Config, logger *slog.Logger) *messaging.EventBridgePublisher {
+	if cfg.EventBusName == "" {
+		return nil
+	}
+	logger.Info("publishing domain events", slog.String("event_bus", cfg.EventBusName))
+	return messaging.NewEventBridgePublisher(eventbridge.NewFromConfig(awsCfg), cfg.EventBusName, cfg.Stage.String(), logger)
+}
+
+// Start runs handler with lambda.Start, or in local mode serves it over HTTP: each POST body is
+// the invocation payload and the response body is the handler's result.
+func Start(cfg *config.Config, defaultAddr string, handler interface{}, logger *slog.Logger) {
+	if !cfg.Local {
+		lambda.Start(Warmable(lambda.NewHandler(handler)))
+		return
+	}
+	serve(address(cfg, defaultAddr), InvokeHandler(lambda.NewHandler(handler), logger), logger)
+}
+
+// StartAPI runs an API Gateway handler with lambda.Start, or in local mode serves real HTTP
+// requests by translating them to and from API Gateway events
+func StartAPI(cfg *config.Config, defaultAddr string, handler APIHandler, logger *slog.Logger) {
+	if !cfg.Local {
+		lambda.Start(Warmable(lambda.NewHandler(handler)))
+		return
+	}
+	serve(address(cfg, defaultAddr), APIGatewayHandler(handler, logger), logger)
+}
+
+// StartSQS runs an SQS handler with lambda.Start. In local mode it polls queueURL, standing in
+// for the event source mapping, and also serves SQS events POSTed over HTTP.
+func StartSQS(cfg *config.Config, defaultAddr string, handler SQSHandler, client *sqs.Client, queueURL string, logger *slog.Logger) {
+	if !cfg.Local {
+		lambda.Start(Warmable(lambda.NewHandler(handler)))
+		return
+	}
+	if queueURL != "" {
+		go NewQueuePoller(client, queueURL, handler, logger).Run(context.Background())
+	} else {
+		logger.Warn("no queue configured, only serving events posted over HTTP")
+	}
+	serve(address(cfg, defaultAddr), InvokeHandler(lambda.NewHandler(handler), logger), logger)
+}
+
+// Handlers are the entry points of a Lambda that serves an API and also consumes a queue
+type Handlers struct {
+	API APIHandler
+	// Stream serves requests made through the function URL, which streams responses; optional
+	Stream StreamHandler
+	SQS    SQSHandler
+}
+
+// StartAPIAndSQS runs a Lambda that serves API Gateway and function URL requests and also
+// consumes a queue. In local mode it serves the API over HTTP, streaming responses when the
+// Lambda has a Stream handler, and polls queueURL.
+func StartAPIAndSQS(cfg *config.Config, defaultAddr string, handlers Handlers, client *sqs.Client, queueURL string, logger *slog.Logger) {
+	if !cfg.Local {
+		lambda.Start(APIAndSQSHandler(handlers))
+		return
+	}
+	if queueURL != "" {
+		go NewQueuePoller(client, queueURL, handlers.SQS, logger).Run(context.Background())
+	}
+	if handlers.Stream != nil {
+		serve(address(cfg, defaultAddr), StreamingHandler(handlers.Stream, logger), logger)
+		return
+	}
+	serve(address(cfg, defaultAddr), APIGatewayHandler(handlers.API, logger), logger)
+}
+
+// APIAndSQSHandler routes a Lambda's invocations: SQS event batches to the SQS handler, function
+// URL requests to the Stream handler when there is one, and everything else to the API handler.
+// Warmer pings return without reaching any of them. It returns a plain function rather than a
+// lambda.Handler so the runtime can stream a function URL response instead of buffering it.
+func APIAndSQSHandler(handlers Handlers) func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		if IsWarmerPing(payload) {
+			return json.RawMessage(`{}`), nil
+		}
+
+		var records struct {
+			Records []struct {
+				EventSource string `json:"eventSource"`
+			} `json:"Records"`
+		}
+		if json.Unmarshal(payload, &records) == nil && len(records.Records) > 0 && records.Records[0].EventSource == "aws:sqs" {
+			var event events.SQSEvent
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return nil, fmt.Errorf("failed to parse SQS event: %w", err)
+			}
+			return handlers.SQS(ctx, event)
+		}
+
+		// Function URLs send the same payload as API Gateway HTTP APIs (v2)
+		var request events.APIGatewayV2HTTPRequest
+		if err := json.Unmarshal(payload, &request); err != nil {
+			return nil, fmt.Errorf("failed to parse API request: %w", err)
+		}
+		if handlers.Stream != nil && IsFunctionURLRequest(request) {
+			return handlers.Stream(ctx, request)
+		}
+		return handlers.API(ctx, request)
+	}
+}
+
+// IsFunctionURLRequest reports whether request came through the Lambda's function URL rather
+// than API Gateway
+func IsFunctionURLRequest(request events.APIGatewayV2HTTPRequest) bool {
+	return strings.Contains(request.RequestContext.DomainName, ".lambda-url.")
+}
+
+// StreamingHandler serves a function URL handler over plain HTTP, flushing the response body as
+// the handler writes it
+func StreamingHandler(handler StreamHandler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, err := NewAPIGatewayRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response, err := handler(r.Context(), request)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "local streaming invocation failed", slog.String("error", err.Error()))
+			http.Error(w, `{"message":"Internal Server Error"}`, http.StatusInternalServerError)
+			return
+		}
+		defer response.Close()
+
+		for name, value := range response.Headers {
+			w.Header().Set(name, value)
+		}
+		for _, cookie := range response.Cookies {
+			w.Header().Add("Set-Cookie", cookie)
+		}
+		status := response.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+		if response.Body == nil {
+			return
+		}
+
+		flusher, _ := w.(http.Flusher)
+		buf := make([]byte, 4096)
+		for {
+			n, err := response.Body.Read(buf)
+			if n > 0 {
+				if _, werr := w.Write(buf[:n]); werr != nil {
+					return
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+}
+
+// Warmable wraps handler so warmer pings return immediately instead of reaching it
+func Warmable(handler lambda.Handler) lambda.Handler {
+	return warmable{handler: handler}
+}
+
+type warmable struct {
+	handler lambda.Handler
+}
+
+func (w warmable) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	if IsWarmerPing(payload) {
+		return []byte(`{}`), nil
+	}
+	return w.handler.Invoke(ctx, payload)
+}
+
+// IsWarmerPing reports whether payload is WarmerPayload rather than a real event
+func IsWarmerPing(payload []byte) bool {
+	// Cheap check first: real events are often large SQS batches
+	if len(payload) > 64 || !bytes.Contains(payload, []byte("warmer")) {
+		return false
+	}
+	var ping struct {
+		Warmer bool `json:"warmer"`
+	}
+	return json.Unmarshal(payload, &ping) == nil && ping.Warmer
+}
+
+// address returns the configured local address, or the Lambda's default
+func address(cfg *config.Config, defaultAddr string) string {
+	if cfg.LocalHTTPAddr != "" {
+		return cfg.LocalHTTPAddr
+	}
+	return defaultAddr
+}
+
+// serve listens on addr until the process exits
+func serve(addr string, handler http.Handler, logger *slog.Logger) {
+	logger.Info("local mode: serving handler over HTTP", slog.String("addr", addr))
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		logger.Error("local server stopped", slog.String("error", err.Error()))
+		panic(err)
+	}
+}
+
+// InvokeHandler serves a Lambda handler over HTTP; the request body is the invocation payload
+func InvokeHandler(handler lambda.Handler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST the invocation payload", http.StatusMethodNotAllowed)
+			return
+		}
+		payload, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := handler.Invoke(r.Context(), payload)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "local invocation failed", slog.String("error", err.Error()))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(map[string]string{"errorMessage": err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(result)
+	})
+}
+
+// APIGatewayHandler serves an API Gateway handler over plain HTTP
+func APIGatewayHandler(handler APIHandler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, err := NewAPIGatewayRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response, err := handler(r.Context(), request)
+		if err != nil {
+			// API Gateway answers a failed invocation with a 500 and no handler response
+			logger.ErrorContext(r.Context(), "local API invocation failed", slog.String("error", err.Error()))
+			http.Error(w, `{"message":"Internal Server Error"}`, http.StatusInternalServerError)
+			return
+		}
+
+		for name, value := range response.Headers {
+			w.Header().Set(name, value)
+		}
+		for name, values := range response.MultiValueHeaders {
+			for _, value := range values {
+				w.Header().Add(name, value)
+			}
+		}
+		for _, cookie := range response.Cookies {
+			w.Header().Add("Set-Cookie", cookie)
+		}
+		status := response.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		body := []byte(response.Body)
+		if response.IsBase64Encoded {
+			// Binary and compressed bodies are base64-encoded for API Gateway, which decodes them
+			if body, err = base64.StdEncoding.DecodeString(response.Body); err != nil {
+				logger.ErrorContext(r.Context(), "local API response body is not base64", slog.String("error", err.Error()))
+				http.Error(w, `{"message":"Internal Server Error"}`, http.StatusInternalServerError)
+				return
+			}
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	})
+}
+
+// NewAPIGatewayRequest converts an HTTP request to the event API Gateway sends for it
+func NewAPIGatewayRequest(r *http.Request) (events.APIGatewayV2HTTPRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return events.APIGatewayV2HTTPRequest{}, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	// API Gateway lowercases header names, joins repeated values with commas and passes cookies
+	// separately
+	headers := make(map[string]string, len(r.Header))
+	var cookies []string
+	for name, values := range r.Header {
+		if strings.EqualFold(name, "Cookie") {
+			for _, value := range values {
+				cookies = append(cookies, strings.Split(value, "; ")...)
+			}
+			continue
+		}
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	var query map[string]string
+	if values := r.URL.Query(); len(values) > 0 {
+		query = make(map[string]string, len(values))
+		for name, value := range values {
+			query[name] = strings.Join(value, ",")
+		}
+	}
+	sourceIP := r.RemoteAddr
+	if i := strings.LastIndex(sourceIP, ":"); i >= 0 {
+		sourceIP = sourceIP[:i]
+	}
+
+	return events.APIGatewayV2HTTPRequest{
+		Version:               "2.0",
+		RouteKey:              "$default",
+		RawPath:               r.URL.Path,
+		RawQueryString:        r.URL.RawQuery,
+		Cookies:               cookies,
+		Headers:               headers,
+		QueryStringParameters: query,
+		Body:                  string(body),
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RequestID: uuid.New().String(),
+			Stage:     "$default",
+			TimeEpoch: time.Now().UnixMilli(),
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Protocol:  r.Proto,
+				SourceIP:  sourceIP,
+				UserAgent: r.UserAgent(),
+			},
+		},
+	}, nil
+}
+
+// QueuePoller delivers messages from a queue to an SQS handler, deleting the ones it processed
+type QueuePoller struct {
+	client   *sqs.Client
+	queueURL string
+	handler  SQSHandler
+	logger   *slog.Logger
+}
+
+// NewQueuePoller creates a poller for queueURL
+func NewQueuePoller(client *sqs.Client, queueURL string, handler SQSHandler, logger *slog.Logger) *QueuePoller {
+	return &QueuePoller{client: client, queueURL: queueURL, handler: handler, logger: logger}
+}
+
+// Run polls until ctx is done
+func (p *QueuePoller) Run(ctx context.Context) {
+	p.logger.Info("local mode: polling queue", slog.String("queue_url", p.queueURL))
+	for ctx.Err() == nil {
+		if err := p.poll(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			p.logger.Warn("local queue poll failed", slog.String("error", err.Error()))
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+// poll receives one batch and hands it to the handler
+func (p *QueuePoller) poll(ctx context.Context) error {
+	out, err := p.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:                    aws.String(p.queueURL),
+		MaxNumberOfMessages:         10,
+		WaitTimeSeconds:             int32(sqsPollWait / time.Second),
+		MessageSystemAttributeNames: []sqstypes.MessageSystemAttributeName{sqstypes.MessageSystemAttributeNameAll},
+		MessageAttributeNames:       []string{"All"},
+	})
+	if err != nil {
+		return err
+	}
+	if len(out.Messages) == 0 {
+		return nil
+	}
+
+	event := NewSQSEvent(p.queueURL, out.Messages)
+	response, err := p.handler(ctx, event)
+	if err != nil {
+		// Like the event source mapping, a failed invocation leaves the whole batch for redelivery
+		return fmt.Errorf("handler failed: %w", err)
+	}
+
+	failed := make(map[string]bool, len(response.BatchItemFailures))
+	for _, failure := range response.BatchItemFailures {
+		failed[failure.ItemIdentifier] = true
+	}
+	for _, message := range out.Messages {
+		if failed[aws.ToString(message.MessageId)] {
+			continue
+		}
+		if _, err := p.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+			QueueUrl:      aws.String(p.queueURL),
+			ReceiptHandle: message.ReceiptHandle,
+		}); err != nil {
+			p.logger.Warn("failed to delete processed message",
+				slog.String("message_id", aws.ToString(message.MessageId)),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return nil
+}
+
+// NewSQSEvent converts received messages to the event Lambda's event source mapping delivers
+func NewSQSEvent(queueURL string, messages []sqstypes.Message) events.SQSEvent {
+	event := events.SQSEvent{Records: make([]events.SQSMessage, 0, len(messages))}
+	for _, message := range messages {
+		attributes := make(map[string]events.SQSMessageAttribute, len(message.MessageAttributes))
+		for name, value := range message.MessageAttributes {
+			attributes[name] = events.SQSMessageAttribute{
+				StringValue: value.StringValue,
+				BinaryValue: value.BinaryValue,
+				DataType:    aws.ToString(value.DataType),
+			}
+		}
+		event.Records = append(event.Records, events.SQSMessage{
+			MessageId:         aws.ToString(message.MessageId),
+			ReceiptHandle:     aws.ToString(message.ReceiptHandle),
+			Body:              aws.ToString(message.Body),
+			Md5OfBody:         aws.ToString(message.MD5OfBody),
+			Attributes:        message.Attributes,
+			MessageAttributes: attributes,
+			EventSource:       "aws:sqs",
+			EventSourceARN:    queueURL,
+		})
+	}
+	return event
+}
@@ -0,0 +1,268 @@
+package localrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	"github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+func TestNewAPIGatewayRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/messages?stage=dev&status=queued", strings.NewReader(`{"message":"hi"}`))
+	r.Header.Set("X-Api-Key", "secret")
+	r.Header.Add("Accept", "text/html")
+	r.Header.Add("Accept", "application/json")
+	r.Header.Set("Cookie", "theme=dark; agent_sessions=s1.s2")
+	r.RemoteAddr = "10.0.0.7:51234"
+
+	request, err := NewAPIGatewayRequest(r)
+	if err != nil {
+		t.Fatalf("NewAPIGatewayRequest() error = %v", err)
+	}
+
+	if request.RawPath != "/api/messages" {
+		t.Errorf("RawPath = %q, want %q", request.RawPath, "/api/messages")
+	}
+	if request.RequestContext.HTTP.Method != http.MethodPost {
+		t.Errorf("Method = %q, want %q", request.RequestContext.HTTP.Method, http.MethodPost)
+	}
+	if request.RequestContext.HTTP.SourceIP != "10.0.0.7" {
+		t.Errorf("SourceIP = %q, want %q", request.RequestContext.HTTP.SourceIP, "10.0.0.7")
+	}
+	if request.RequestContext.RequestID == "" {
+		t.Error("RequestID is empty")
+	}
+	if request.Headers["x-api-key"] != "secret" {
+		t.Errorf("Headers[x-api-key] = %q, want %q", request.Headers["x-api-key"], "secret")
+	}
+	if request.Headers["accept"] != "text/html,application/json" {
+		t.Errorf("Headers[accept] = %q, want joined values", request.Headers["accept"])
+	}
+	if !slices.Equal(request.Cookies, []string{"theme=dark", "agent_sessions=s1.s2"}) || request.Headers["cookie"] != "" {
+		t.Errorf("Cookies = %v, want them apart from the headers", request.Cookies)
+	}
+	if request.QueryStringParameters["status"] != "queued" {
+		t.Errorf("QueryStringParameters[status] = %q, want %q", request.QueryStringParameters["status"], "queued")
+	}
+	if request.Body != `{"message":"hi"}` {
+		t.Errorf("Body = %q, want the request body", request.Body)
+	}
+}
+
+func TestAPIGatewayHandler(t *testing.T) {
+	handler := func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{
+			StatusCode: http.StatusCreated,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       `{"path":"` + request.RawPath + `"}`,
+		}, nil
+	}
+	server := httptest.NewServer(APIGatewayHandler(handler, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/schedules", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want %q", got, "application/json")
+	}
+	if string(body) != `{"path":"/api/schedules"}` {
+		t.Errorf("body = %q, want %q", body, `{"path":"/api/schedules"}`)
+	}
+}
+
+func TestAPIGatewayHandler_Base64Body(t *testing.T) {
+	handler := func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{
+			StatusCode:      http.StatusOK,
+			Body:            base64.StdEncoding.EncodeToString([]byte{0x1f, 0x8b, 0x00}),
+			IsBase64Encoded: true,
+		}, nil
+	}
+	server := httptest.NewServer(APIGatewayHandler(handler, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/messages")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if !bytes.Equal(body, []byte{0x1f, 0x8b, 0x00}) {
+		t.Errorf("body = %v, want the decoded bytes", body)
+	}
+}
+
+func TestInvokeHandler(t *testing.T) {
+	type request struct {
+		Name string `json:"name"`
+	}
+	type response struct {
+		Greeting string `json:"greeting"`
+	}
+	handler := func(ctx context.Context, req request) (response, error) {
+		return response{Greeting: "hello " + req.Name}, nil
+	}
+	server := httptest.NewServer(InvokeHandler(lambda.NewHandler(handler), slog.New(slog.NewTextHandler(io.Discard, nil))))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"name":"rez"}`))
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if strings.TrimSpace(string(body)) != `{"greeting":"hello rez"}` {
+		t.Errorf("body = %q, want %q", body, `{"greeting":"hello rez"}`)
+	}
+
+	get, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	get.Body.Close()
+	if get.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET StatusCode = %d, want %d", get.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestWarmable(t *testing.T) {
+	calls := 0
+	handler := Warmable(lambda.NewHandler(func(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+		calls++
+		return events.SQSEventResponse{}, nil
+	}))
+
+	if _, err := handler.Invoke(context.Background(), []byte(WarmerPayload)); err != nil {
+		t.Fatalf("Invoke(warmer) error = %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("handler called %d times for a warmer ping, want 0", calls)
+	}
+
+	if _, err := handler.Invoke(context.Background(), []byte(`{"Records":[{"messageId":"warmer"}]}`)); err != nil {
+		t.Fatalf("Invoke(event) error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times for a real event, want 1", calls)
+	}
+}
+
+func TestAPIAndSQSHandler(t *testing.T) {
+	var got []string
+	handler := APIAndSQSHandler(Handlers{
+		API: func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+			got = append(got, "api "+request.RawPath)
+			return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK}, nil
+		},
+		Stream: func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+			got = append(got, "stream "+request.RawPath)
+			return &events.LambdaFunctionURLStreamingResponse{Body: strings.NewReader("data: hi\n\n")}, nil
+		},
+		SQS: func(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+			got = append(got, "sqs "+event.Records[0].MessageId)
+			return events.SQSEventResponse{}, nil
+		},
+	})
+
+	payloads := []string{
+		WarmerPayload,
+		`{"version":"2.0","rawPath":"/agent","requestContext":{"domainName":"abc.execute-api.us-east-1.amazonaws.com","http":{"method":"POST"}}}`,
+		`{"version":"2.0","rawPath":"/agent","requestContext":{"domainName":"abc.lambda-url.us-east-1.on.aws","http":{"method":"POST"}}}`,
+		`{"Records":[{"messageId":"m1","eventSource":"aws:sqs","body":"{}"}]}`,
+	}
+	for _, payload := range payloads {
+		if _, err := handler(context.Background(), json.RawMessage(payload)); err != nil {
+			t.Fatalf("handler(%s) error = %v", payload, err)
+		}
+	}
+	want := []string{"api /agent", "stream /agent", "sqs m1"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("invocations = %v, want %v", got, want)
+	}
+}
+
+func TestStreamingHandler(t *testing.T) {
+	reader, writer := io.Pipe()
+	handler := StreamingHandler(func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+		go func() {
+			_, _ = io.WriteString(writer, "event: token\ndata: {}\n\n")
+			_ = writer.Close()
+		}()
+		return &events.LambdaFunctionURLStreamingResponse{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "text/event-stream"},
+			Body:       reader,
+		}, nil
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/agent", strings.NewReader(`{}`)))
+	if recorder.Header().Get("Content-Type") != "text/event-stream" || !recorder.Flushed {
+		t.Errorf("response = %s flushed %v, want a flushed event stream", recorder.Header().Get("Content-Type"), recorder.Flushed)
+	}
+	if recorder.Body.String() != "event: token\ndata: {}\n\n" {
+		t.Errorf("body = %q", recorder.Body.String())
+	}
+}
+
+func TestNewSQSEvent(t *testing.T) {
+	event := NewSQSEvent("http://localhost:4566/000000000000/notifications", []sqstypes.Message{{
+		MessageId:     aws.String("m-1"),
+		ReceiptHandle: aws.String("r-1"),
+		Body:          aws.String(`{"id":"1"}`),
+		Attributes:    map[string]string{"ApproximateReceiveCount": "2"},
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			"message_type": {DataType: aws.String("String"), StringValue: aws.String("web_action")},
+		},
+	}})
+
+	if len(event.Records) != 1 {
+		t.Fatalf("len(Records) = %d, want 1", len(event.Records))
+	}
+	record := event.Records[0]
+	if record.MessageId != "m-1" || record.ReceiptHandle != "r-1" || record.Body != `{"id":"1"}` {
+		t.Errorf("record = %+v, want the message's id, receipt handle and body", record)
+	}
+	if record.Attributes["ApproximateReceiveCount"] != "2" {
+		t.Errorf("ApproximateReceiveCount = %q, want %q", record.Attributes["ApproximateReceiveCount"], "2")
+	}
+	if got := aws.ToString(record.MessageAttributes["message_type"].StringValue); got != "web_action" {
+		t.Errorf("message_type = %q, want %q", got, "web_action")
+	}
+}
+
+func TestAddress(t *testing.T) {
+	if got := address(&config.Config{}, WebAPIAddr); got != WebAPIAddr {
+		t.Errorf("address() = %q, want %q", got, WebAPIAddr)
+	}
+	if got := address(&config.Config{LocalHTTPAddr: ":9000"}, WebAPIAddr); got != ":9000" {
+		t.Errorf("address() = %q, want %q", got, ":9000")
+	}
+}
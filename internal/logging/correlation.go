@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// CorrelationIDHeader carries a correlation ID between services over HTTP
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// CorrelationIDAttribute is the log field, and the SNS message attribute, holding a correlation ID
+const CorrelationIDAttribute = "correlation_id"
+
+type correlationIDKey struct{}
+
+// NewCorrelationID generates the ID that ties together everything done for one request, from
+// the ingress that received it to the last Lambda it reached
+func NewCorrelationID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand failed: " + err.Error())
+	}
+	return "corr_" + hex.EncodeToString(b)
+}
+
+// maxIncomingCorrelationIDLength bounds a correlation ID accepted from a caller
+const maxIncomingCorrelationIDLength = 128
+
+// IncomingCorrelationID continues a caller's correlation ID, from the X-Correlation-ID header,
+// or starts a new one when the caller sent none or one that is too long or not printable ASCII
+func IncomingCorrelationID(header string) string {
+	if header == "" || len(header) > maxIncomingCorrelationIDLength {
+		return NewCorrelationID()
+	}
+	for _, c := range header {
+		if c < '!' || c > '~' {
+			return NewCorrelationID()
+		}
+	}
+	return header
+}
+
+// WithCorrelationID returns a context carrying the correlation ID; an empty ID leaves ctx as it is
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the context's correlation ID, or "" when it has none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// ContextHandler adds the context's correlation ID to every record logged with a context
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps handler so that records logged through the *Context methods carry
+// the correlation ID of their context
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: handler}
+}
+
+// Handle adds the correlation ID to the record before passing it on
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := CorrelationID(ctx); id != "" {
+		record.AddAttrs(slog.String(CorrelationIDAttribute, id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs keeps the wrapper on handlers derived with attributes
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the wrapper on handlers derived with a group
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil))).With(slog.String("component", "test"))
+
+	logger.InfoContext(WithCorrelationID(context.Background(), "corr_abc"), "with correlation")
+	logger.InfoContext(context.Background(), "without correlation")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %d lines, want 2", len(lines))
+	}
+	for i, want := range []string{"corr_abc", ""} {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i]), &record); err != nil {
+			t.Fatalf("log line %d is not JSON: %v", i, err)
+		}
+		got, _ := record[CorrelationIDAttribute].(string)
+		if got != want {
+			t.Errorf("line %d correlation_id = %q, want %q", i, got, want)
+		}
+		if record["component"] != "test" {
+			t.Errorf("line %d lost the logger's attributes: %v", i, record)
+		}
+	}
+}
+
+func TestIncomingCorrelationID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantKept bool
+	}{
+		{"caller's ID", "corr_1234", true},
+		{"none", "", false},
+		{"too long", strings.Repeat("a", 129), false},
+		{"control characters", "corr\n1234", false},
+		{"spaces", "corr 1234", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IncomingCorrelationID(tt.header)
+			if kept := got == tt.header; kept != tt.wantKept {
+				t.Errorf("IncomingCorrelationID(%q) = %q, want kept = %v", tt.header, got, tt.wantKept)
+			}
+			if !tt.wantKept && !strings.HasPrefix(got, "corr_") {
+				t.Errorf("IncomingCorrelationID(%q) = %q, want a new ID", tt.header, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// GolfCancelTeeTimeTool implements the golf_cancel_tee_time MCP tool. The courses' booking API
+// has no cancellation call: a golfer cancels on the course's site after verifying their email,
+// so the tool hands them that page for the reservation.
+type GolfCancelTeeTimeTool struct {
+	logger *slog.Logger
+}
+
+// NewGolfCancelTeeTimeTool creates a new tee time cancellation tool
+func NewGolfCancelTeeTimeTool(logger *slog.Logger) *GolfCancelTeeTimeTool {
+	return &GolfCancelTeeTimeTool{
+		logger: logger,
+	}
+}
+
+// GetDefinition returns the tool's MCP definition
+func (t *GolfCancelTeeTimeTool) GetDefinition() protocol.Tool {
+	return protocol.Tool{
+		Name:        "golf_cancel_tee_time",
+		Description: "Get the link where the golfer cancels a reservation at a course. The course confirms cancellations by email, so they cannot be made on the golfer's behalf; pass the confirmation number from golf_get_reservations so the golfer knows which one to cancel.",
+		InputSchema: protocol.InputSchema{
+			Type: "object",
+			Properties: map[string]protocol.Property{
+				"course_name": {
+					Type:        "string",
+					Description: "Name of the golf course (e.g., 'Birdsfoot Golf Course' or 'Totteridge')",
+				},
+				"confirmation": {
+					Type:        "string",
+					Description: "Confirmation number of the reservation to cancel",
+				},
+			},
+			Required: []string{"course_name"},
+		},
+	}
+}
+
+// ValidateInput validates the tool's input arguments
+func (t *GolfCancelTeeTimeTool) ValidateInput(args map[string]interface{}) error {
+	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+}
+
+// Execute runs the tool with the given arguments
+func (t *GolfCancelTeeTimeTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+	courseName := GetStringArg(args, "course_name", "")
+	confirmation := GetStringArg(args, "confirmation", "")
+
+	course, err := courses.GetCourseByName(courseName)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("failed to find course: %w", err))
+	}
+	link := course.GetCancelReservationURL()
+	if link == "" {
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("%s does not offer online cancellation", course.Name))
+	}
+
+	t.logger.InfoContext(ctx, "providing tee time cancellation link",
+		slog.String("course_name", course.Name),
+		slog.String("confirmation", confirmation),
+	)
+
+	message := fmt.Sprintf("Reservations at %s are cancelled on the course's site after verifying your email: %s", course.Name, link)
+	if confirmation != "" {
+		message += fmt.Sprintf("\nCancel the reservation with confirmation number %s.", confirmation)
+	}
+	return []protocol.Content{protocol.NewTextContent(message)}, nil
+}
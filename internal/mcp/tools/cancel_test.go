@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+)
+
+func TestGolfCancelTeeTimeTool_Execute(t *testing.T) {
+	tool := NewGolfCancelTeeTimeTool(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	content, err := tool.Execute(context.Background(), map[string]interface{}{"course_name": "Totteridge", "confirmation": "ABC123"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(content) != 1 || !strings.Contains(content[0].Text, "returnUrl=cancel-booking") || !strings.Contains(content[0].Text, "ABC123") {
+		t.Errorf("content = %v, want the course's cancellation link for ABC123", content)
+	}
+
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"course_name": "Augusta"}); !apperrors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("Execute() unknown course error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// GolfListCoursesTool implements the golf_list_courses MCP tool
+type GolfListCoursesTool struct {
+	logger *slog.Logger
+}
+
+// NewGolfListCoursesTool creates a new course listing tool
+func NewGolfListCoursesTool(logger *slog.Logger) *GolfListCoursesTool {
+	return &GolfListCoursesTool{
+		logger: logger,
+	}
+}
+
+// GetDefinition returns the tool's MCP definition
+func (t *GolfListCoursesTool) GetDefinition() protocol.Tool {
+	return protocol.Tool{
+		Name:        "golf_list_courses",
+		Description: "List the golf courses that can be searched and booked, with their exact names, IDs, booking windows and locations. Use the names it returns as course_name for the other golf tools.",
+		InputSchema: protocol.InputSchema{
+			Type:       "object",
+			Properties: map[string]protocol.Property{},
+		},
+	}
+}
+
+// ValidateInput validates the tool's input arguments
+func (t *GolfListCoursesTool) ValidateInput(args map[string]interface{}) error {
+	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+}
+
+// Execute runs the tool with the given arguments
+func (t *GolfListCoursesTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+	config, err := courses.LoadCourses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load courses: %w", err)
+	}
+
+	t.logger.InfoContext(ctx, "listing golf courses", slog.Int("count", len(config.Courses)))
+
+	return []protocol.Content{protocol.NewTextContent(formatCourses(config.Courses))}, nil
+}
+
+// formatCourses renders one block per course
+func formatCourses(list []courses.Course) string {
+	if len(list) == 0 {
+		return "No golf courses are configured."
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d golf course(s) available:\n", len(list)))
+	for _, course := range list {
+		sb.WriteString(fmt.Sprintf("\n- %s (course ID %d)\n", course.Name, course.CourseID))
+		sb.WriteString(fmt.Sprintf("  Booking window: tee times can be booked up to %d days in advance\n", course.GetBookingWindowDays()))
+		if course.Address != "" {
+			sb.WriteString(fmt.Sprintf("  Address: %s\n", course.Address))
+		}
+		if course.Latitude != 0 || course.Longitude != 0 {
+			sb.WriteString(fmt.Sprintf("  Location: %.4f, %.4f\n", course.Latitude, course.Longitude))
+		}
+		if description := strings.TrimSpace(course.Description); description != "" {
+			sb.WriteString(fmt.Sprintf("  About: %s\n", description))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+func TestGolfListCoursesTool_Execute(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	tool := NewGolfListCoursesTool(logger)
+
+	if err := tool.ValidateInput(map[string]interface{}{}); err != nil {
+		t.Fatalf("ValidateInput() error = %v", err)
+	}
+
+	content, err := tool.Execute(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(content) != 1 {
+		t.Fatalf("content count = %d, want 1", len(content))
+	}
+
+	config, err := courses.LoadCourses()
+	if err != nil {
+		t.Fatalf("LoadCourses() error = %v", err)
+	}
+	// Every registered course is listed by its exact name, so the agent can pass it back as course_name
+	for _, course := range config.Courses {
+		if !strings.Contains(content[0].Text, course.Name) {
+			t.Errorf("listing is missing course %q:\n%s", course.Name, content[0].Text)
+		}
+	}
+	if !strings.Contains(content[0].Text, "up to 14 days in advance") {
+		t.Errorf("listing is missing the booking window:\n%s", content[0].Text)
+	}
+}
@@ -0,0 +1,202 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// Default lookup services: OpenStreetMap Nominatim for place names, weather.gov for gridpoints
+const (
+	defaultGeocodeURL = "https://nominatim.openstreetmap.org/search"
+	defaultPointsURL  = "https://api.weather.gov/points"
+)
+
+// geocodeCacheTTL is long: places and their forecast gridpoints do not move, and Nominatim's
+// usage policy asks clients to cache results
+const geocodeCacheTTL = 24 * time.Hour
+
+// geoPoint is a latitude and longitude in decimal degrees
+type geoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// geocodedPlace is a place name resolved to a point and its weather.gov forecasts
+type geocodedPlace struct {
+	Name        string
+	Point       geoPoint
+	ForecastURL string
+}
+
+// Geocoder turns an address or place name into a weather.gov forecast URL
+type Geocoder struct {
+	httpClient *httpclient.Client
+	geocodeURL string
+	pointsURL  string
+}
+
+// NewGeocoder creates a geocoder backed by Nominatim and the weather.gov points API
+func NewGeocoder(httpClient *httpclient.Client) *Geocoder {
+	return &Geocoder{
+		httpClient: httpClient,
+		geocodeURL: defaultGeocodeURL,
+		pointsURL:  defaultPointsURL,
+	}
+}
+
+// Resolve geocodes place and looks up the forecast of its gridpoint
+func (g *Geocoder) Resolve(ctx context.Context, place string) (*geocodedPlace, error) {
+	point, name, err := g.geocode(ctx, place)
+	if err != nil {
+		return nil, err
+	}
+	forecastURL, err := g.forecastURL(ctx, point)
+	if err != nil {
+		return nil, err
+	}
+	return &geocodedPlace{Name: name, Point: point, ForecastURL: forecastURL}, nil
+}
+
+// geocode returns the best match for place within the US, the only area weather.gov covers
+func (g *Geocoder) geocode(ctx context.Context, place string) (geoPoint, string, error) {
+	query := url.Values{
+		"q":            {place},
+		"format":       {"jsonv2"},
+		"limit":        {"1"},
+		"countrycodes": {"us"},
+	}
+	resp, err := g.httpClient.Do(ctx, httpclient.RequestConfig{
+		Method: "GET",
+		URL:    g.geocodeURL + "?" + query.Encode(),
+		Headers: map[string]string{
+			"Accept":     "application/json",
+			"User-Agent": "rez-agent MCP weather tool (contact@example.com)",
+		},
+		Timeout:  10 * time.Second,
+		CacheTTL: geocodeCacheTTL,
+	})
+	if err != nil {
+		return geoPoint{}, "", fmt.Errorf("failed to geocode place: %w", err)
+	}
+
+	var matches []struct {
+		Lat         string `json:"lat"`
+		Lon         string `json:"lon"`
+		DisplayName string `json:"display_name"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &matches); err != nil {
+		return geoPoint{}, "", fmt.Errorf("failed to parse geocoding response: %w", err)
+	}
+	if len(matches) == 0 {
+		return geoPoint{}, "", apperrors.Newf(apperrors.ErrNotFound, "no US location found for %q", place)
+	}
+
+	latitude, latErr := strconv.ParseFloat(matches[0].Lat, 64)
+	longitude, lonErr := strconv.ParseFloat(matches[0].Lon, 64)
+	if latErr != nil || lonErr != nil {
+		return geoPoint{}, "", fmt.Errorf("geocoding returned an invalid point for %q", place)
+	}
+	return geoPoint{Latitude: latitude, Longitude: longitude}, matches[0].DisplayName, nil
+}
+
+// forecastURL looks up the gridpoint forecast covering point
+func (g *Geocoder) forecastURL(ctx context.Context, point geoPoint) (string, error) {
+	// weather.gov redirects points with more than four decimals, and redirects are not followed
+	resp, err := g.httpClient.Do(ctx, httpclient.RequestConfig{
+		Method: "GET",
+		URL:    fmt.Sprintf("%s/%.4f,%.4f", g.pointsURL, point.Latitude, point.Longitude),
+		Headers: map[string]string{
+			"Accept":     "application/geo+json",
+			"User-Agent": "rez-agent MCP weather tool (contact@example.com)",
+		},
+		Timeout:  10 * time.Second,
+		CacheTTL: geocodeCacheTTL,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up weather.gov gridpoint: %w", err)
+	}
+
+	var points struct {
+		Properties struct {
+			Forecast string `json:"forecast"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &points); err != nil {
+		return "", fmt.Errorf("failed to parse weather.gov gridpoint: %w", err)
+	}
+	if points.Properties.Forecast == "" {
+		return "", apperrors.Newf(apperrors.ErrNotFound, "weather.gov has no forecast for %.4f,%.4f", point.Latitude, point.Longitude)
+	}
+	return points.Properties.Forecast, nil
+}
+
+// WeatherByPlaceTool implements the get_weather_by_place MCP tool
+type WeatherByPlaceTool struct {
+	weather  *WeatherTool
+	geocoder *Geocoder
+	logger   *slog.Logger
+}
+
+// NewWeatherByPlaceTool creates a weather tool for arbitrary US addresses and place names
+func NewWeatherByPlaceTool(httpClient *httpclient.Client, logger *slog.Logger) *WeatherByPlaceTool {
+	return &WeatherByPlaceTool{
+		weather:  NewWeatherTool(httpClient, logger),
+		geocoder: NewGeocoder(httpClient),
+		logger:   logger,
+	}
+}
+
+// GetDefinition returns the tool's MCP definition
+func (t *WeatherByPlaceTool) GetDefinition() protocol.Tool {
+	properties := weatherOptionProperties()
+	properties["place"] = protocol.Property{
+		Type:        "string",
+		Description: "US address or place name (e.g., 'Pittsburgh, PA' or '225 Furnace Run Rd, Freeport, PA')",
+	}
+
+	return protocol.Tool{
+		Name:        "get_weather_by_place",
+		Description: "Get the weather forecast for any US address or place name, in the same form as get_weather. Use get_weather for configured golf courses.",
+		InputSchema: protocol.InputSchema{
+			Type:       "object",
+			Properties: properties,
+			Required:   []string{"place"},
+		},
+	}
+}
+
+// ValidateInput validates the tool's input arguments
+func (t *WeatherByPlaceTool) ValidateInput(args map[string]interface{}) error {
+	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+}
+
+// Execute runs the tool with the given arguments
+func (t *WeatherByPlaceTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+	place := strings.TrimSpace(GetStringArg(args, "place", ""))
+	if place == "" {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "place cannot be empty")
+	}
+
+	t.logger.InfoContext(ctx, "geocoding place for weather", slog.String("place", place))
+
+	resolved, err := t.geocoder.Resolve(ctx, place)
+	if err != nil {
+		return nil, err
+	}
+
+	query := newWeatherQuery(args)
+	query.place = resolved.Name
+	query.location = resolved.ForecastURL
+	query.point = &resolved.Point
+	return t.weather.forecast(ctx, query)
+}
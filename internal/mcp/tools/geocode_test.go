@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+)
+
+func newGeocodeTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/search":
+			if r.URL.Query().Get("q") == "Nowhere" {
+				_, _ = io.WriteString(w, `[]`)
+				return
+			}
+			_, _ = io.WriteString(w, `[{"lat":"40.4406248","lon":"-79.9958864","display_name":"Pittsburgh, Allegheny County, Pennsylvania, United States"}]`)
+		case r.URL.Path == "/points/40.4406,-79.9959":
+			_, _ = io.WriteString(w, `{"properties":{"forecast":"`+server.URL+`/forecast"}}`)
+		case strings.HasPrefix(r.URL.Path, "/points/"):
+			t.Errorf("points requested with unrounded coordinates: %s", r.URL.Path)
+			http.NotFound(w, r)
+		case r.URL.Path == "/forecast":
+			_, _ = io.WriteString(w, testDailyForecast)
+		case r.URL.Path == "/alerts/active":
+			_, _ = io.WriteString(w, `{"features":[]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestWeatherByPlaceTool(server *httptest.Server) *WeatherByPlaceTool {
+	weather := newTestWeatherTool(server)
+	tool := NewWeatherByPlaceTool(weather.httpClient, weather.logger)
+	tool.weather = weather
+	tool.geocoder.geocodeURL = server.URL + "/search"
+	tool.geocoder.pointsURL = server.URL + "/points"
+	return tool
+}
+
+func TestWeatherByPlaceTool_Execute(t *testing.T) {
+	server := newGeocodeTestServer(t)
+	tool := newTestWeatherByPlaceTool(server)
+
+	content, err := tool.Execute(context.Background(), map[string]interface{}{"place": "Pittsburgh, PA"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	report := decodeReport(t, content)
+	if !strings.HasPrefix(report.Place, "Pittsburgh") {
+		t.Errorf("place = %q, want the geocoded name", report.Place)
+	}
+	if len(report.Days) != 2 {
+		t.Errorf("days = %d, want the default 2", len(report.Days))
+	}
+	// Alerts were checked at the geocoded point and none are in effect
+	if report.Alerts == nil || len(report.Alerts) != 0 {
+		t.Errorf("alerts = %v, want checked and empty", report.Alerts)
+	}
+}
+
+func TestWeatherByPlaceTool_UnknownPlace(t *testing.T) {
+	server := newGeocodeTestServer(t)
+	tool := newTestWeatherByPlaceTool(server)
+
+	_, err := tool.Execute(context.Background(), map[string]interface{}{"place": "Nowhere"})
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
+	}
+}
@@ -2,27 +2,69 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
-	"github.com/jrzesz33/rez_agent/internal/httpclient"
-	"github.com/jrzesz33/rez_agent/internal/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
 	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/notification"
+	"github.com/jrzesz33/rez_agent/internal/oauth"
+	"github.com/jrzesz33/rez_agent/internal/repository"
 	"github.com/jrzesz33/rez_agent/internal/secrets"
 	"github.com/jrzesz33/rez_agent/internal/webaction"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/kit/mcp/server"
 	"github.com/jrzesz33/rez_agent/pkg/courses"
 )
 
+// golfSecretName returns the secret holding the golf credentials a call authenticates with: the
+// acting user's own when the call acts for one and users are configured, else the course's
+func golfSecretName(ctx context.Context, users repository.UserRepository, course *courses.Course, stage string) (string, error) {
+	userID := models.ActingUser(ctx)
+	if userID == "" || users == nil {
+		return course.GetSecretName(stage), nil
+	}
+	user, err := users.GetUser(ctx, userID)
+	if err != nil {
+		return "", apperrors.Wrap(apperrors.ErrAuth, fmt.Errorf("failed to look up acting user %s: %w", userID, err))
+	}
+	if user.Disabled {
+		return "", apperrors.Newf(apperrors.ErrAuth, "user %s is disabled", userID)
+	}
+	secretName, err := user.GolfSecret()
+	if err != nil {
+		return "", apperrors.Wrap(apperrors.ErrAuth, err)
+	}
+	return secretName, nil
+}
+
+// validateRanking rejects preferred_time and rank_by values the golf handler cannot rank by, so
+// the model gets a validation error before anything is searched
+func validateRanking(preferredTime, rankBy string) error {
+	params := models.SearchTeeTimesParams{PreferredTime: preferredTime, RankBy: rankBy}
+	if _, _, err := params.RankOptions(); err != nil {
+		return apperrors.Wrap(apperrors.ErrValidation, err)
+	}
+	return nil
+}
+
 // GolfReservationsTool implements the golf_get_reservations MCP tool
 type GolfReservationsTool struct {
 	golfHandler *webaction.GolfHandler
 	logger      *slog.Logger
 	stage       string
+	cache       repository.ReservationRepository
+	cacheMaxAge time.Duration
+	users       repository.UserRepository
 }
 
 // NewGolfReservationsTool creates a new golf reservations tool
-func NewGolfReservationsTool(httpClient *httpclient.Client, oauthClient *httpclient.OAuthClient,
+func NewGolfReservationsTool(httpClient *httpclient.Client, oauthClient *oauth.Client,
 	secretsManager *secrets.Manager, logger *slog.Logger) *GolfReservationsTool {
 	stage := os.Getenv("STAGE")
 	if stage == "" {
@@ -35,6 +77,19 @@ func NewGolfReservationsTool(httpClient *httpclient.Client, oauthClient *httpcli
 	}
 }
 
+// SetUserRepository makes calls acting for a user, such as a scheduled run, use that user's golf
+// credentials instead of the course's shared ones
+func (t *GolfReservationsTool) SetUserRepository(users repository.UserRepository) {
+	t.users = users
+}
+
+// SetReservationCache answers from the reservations synced into repo while they are no older
+// than maxAge, instead of calling the golf provider
+func (t *GolfReservationsTool) SetReservationCache(repo repository.ReservationRepository, maxAge time.Duration) {
+	t.cache = repo
+	t.cacheMaxAge = maxAge
+}
+
 // GetDefinition returns the tool's MCP definition
 func (t *GolfReservationsTool) GetDefinition() protocol.Tool {
 	return protocol.Tool{
@@ -47,6 +102,10 @@ func (t *GolfReservationsTool) GetDefinition() protocol.Tool {
 					Type:        "string",
 					Description: "Name of the golf course (e.g., 'Birdsfoot Golf Course' or 'Totteridge')",
 				},
+				"refresh": {
+					Type:        "boolean",
+					Description: "Fetch live from the course instead of the periodically synced copy (default false)",
+				},
 			},
 			Required: []string{"course_name"},
 		},
@@ -62,15 +121,24 @@ func (t *GolfReservationsTool) ValidateInput(args map[string]interface{}) error
 func (t *GolfReservationsTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
 	courseName := GetStringArg(args, "course_name", "")
 
-	t.logger.Info("fetching golf reservations", slog.String("course_name", courseName))
+	t.logger.InfoContext(ctx, "fetching golf reservations", slog.String("course_name", courseName))
 
 	// Load course configuration
 	course, err := courses.GetCourseByName(courseName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find course: %w", err)
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("failed to find course: %w", err))
+	}
+
+	secretName, err := golfSecretName(ctx, t.users, course, t.stage)
+	if err != nil {
+		return nil, err
 	}
 
-	secretName := course.GetSecretName(t.stage)
+	if t.cache != nil && !GetBoolArg(args, "refresh", false) {
+		if content, ok := t.fromCache(ctx, course, secretName); ok {
+			return content, nil
+		}
+	}
 
 	// Create web action payload
 	payload := &models.WebActionPayload{
@@ -100,15 +168,52 @@ func (t *GolfReservationsTool) Execute(ctx context.Context, args map[string]inte
 	return content, nil
 }
 
+// fromCache renders the synced reservations for the course, if a fresh enough sync exists
+func (t *GolfReservationsTool) fromCache(ctx context.Context, course *courses.Course, secretName string) ([]protocol.Content, bool) {
+	snapshot, err := t.cache.GetSnapshot(ctx, secretName, course.CourseID)
+	if err != nil {
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			t.logger.WarnContext(ctx, "failed to read synced reservations", slog.String("error", err.Error()))
+		}
+		return nil, false
+	}
+	now := time.Now()
+	if snapshot.IsStale(now, t.cacheMaxAge) {
+		return nil, false
+	}
+
+	loc := course.Location()
+	data := notification.ReservationSummaryData{CourseName: course.Name, Now: now.In(loc)}
+	for _, res := range snapshot.Upcoming(now) {
+		data.Reservations = append(data.Reservations, notification.ReservationSummaryItem{
+			TeeTime:      res.TeeTime.In(loc),
+			Players:      res.Players,
+			Confirmation: res.ConfirmationKey,
+		})
+	}
+	message, err := notification.Render(notification.TemplateReservationSummary, data)
+	if err != nil {
+		t.logger.WarnContext(ctx, "failed to render synced reservations", slog.String("error", err.Error()))
+		return nil, false
+	}
+
+	t.logger.InfoContext(ctx, "answered golf reservations from sync", slog.Time("synced_at", snapshot.SyncedAt))
+	return []protocol.Content{
+		protocol.NewTextContent(message),
+		protocol.NewTextContent(fmt.Sprintf("As of %s. Pass refresh=true for a live check.", snapshot.SyncedAt.Format(time.RFC3339))),
+	}, true
+}
+
 // GolfSearchTeeTimesTool implements the golf_search_tee_times MCP tool
 type GolfSearchTeeTimesTool struct {
 	golfHandler *webaction.GolfHandler
 	logger      *slog.Logger
 	stage       string
+	users       repository.UserRepository
 }
 
 // NewGolfSearchTeeTimesTool creates a new golf tee time search tool
-func NewGolfSearchTeeTimesTool(httpClient *httpclient.Client, oauthClient *httpclient.OAuthClient,
+func NewGolfSearchTeeTimesTool(httpClient *httpclient.Client, oauthClient *oauth.Client,
 	secretsManager *secrets.Manager, logger *slog.Logger) *GolfSearchTeeTimesTool {
 	stage := os.Getenv("STAGE")
 	if stage == "" {
@@ -121,11 +226,22 @@ func NewGolfSearchTeeTimesTool(httpClient *httpclient.Client, oauthClient *httpc
 	}
 }
 
+// SetUserRepository makes calls acting for a user, such as a scheduled run, use that user's golf
+// credentials instead of the course's shared ones
+func (t *GolfSearchTeeTimesTool) SetUserRepository(users repository.UserRepository) {
+	t.users = users
+}
+
+// SetBookingLedger claims auto-booked tee times in the booking ledger before reserving them
+func (t *GolfSearchTeeTimesTool) SetBookingLedger(ledger repository.BookingLedgerRepository) {
+	t.golfHandler.SetBookingLedger(ledger)
+}
+
 // GetDefinition returns the tool's MCP definition
 func (t *GolfSearchTeeTimesTool) GetDefinition() protocol.Tool {
 	return protocol.Tool{
 		Name:        "golf_search_tee_times",
-		Description: "Search for available tee times and optionally book the earliest one",
+		Description: "Search for available tee times and optionally book the best match (closest to preferred_time, or the earliest)",
 		InputSchema: protocol.InputSchema{
 			Type: "object",
 			Properties: map[string]protocol.Property{
@@ -150,7 +266,45 @@ func (t *GolfSearchTeeTimesTool) GetDefinition() protocol.Tool {
 				"auto_book": {
 					Type:        "boolean",
 					Default:     false,
-					Description: "Automatically book the earliest available time",
+					Description: "Automatically book the best-ranked available time",
+				},
+				"lock_attempts": {
+					Type:        "integer",
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(10),
+					Default:     3,
+					Description: "With auto_book, how many of the best-ranked times to try in order when one is taken before it can be held",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "With auto_book, price the best-ranked time and report what would be booked without locking or reserving it (optional)",
+				},
+				"max_results": {
+					Type:        "integer",
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(20),
+					Default:     5,
+					Description: "Number of tee times to return per page",
+				},
+				"cursor": {
+					Type:        "string",
+					Description: "Cursor from a previous search's result to fetch its next page of tee times (optional)",
+				},
+				"max_price": {
+					Type:        "number",
+					Description: "Maximum 18-hole green fee per player in dollars; more expensive times are skipped (optional)",
+				},
+				"preferred_time": {
+					Type:        "string",
+					Description: "Preferred time of day in HH:MM (24-hour) format; times closest to it are ranked first (optional)",
+				},
+				"rank_by": {
+					Type:        "string",
+					Description: "Comma-separated ranking criteria in priority order: time, price, holes (optional, default 'time,price' when preferred_time is set)",
+				},
+				"policy": {
+					Type:        "object",
+					Description: "Hard booking rules (max_price, blackout_dates, max_precip_chance, min_daylight_hours); times that break them are skipped (optional)",
 				},
 			},
 			Required: []string{"course_name", "start_time", "end_time", "num_players"},
@@ -170,23 +324,42 @@ func (t *GolfSearchTeeTimesTool) Execute(ctx context.Context, args map[string]in
 	endTime := GetStringArg(args, "end_time", "")
 	numPlayers := GetIntArg(args, "num_players", 1)
 	autoBook := GetBoolArg(args, "auto_book", false)
+	maxResults := GetIntArg(args, "max_results", 5)
+	maxPrice := GetFloatArg(args, "max_price", 0)
+	bookingPolicy, err := GetPolicyArg(args, "policy")
+	if err != nil {
+		return nil, err
+	}
+	preferredTime := GetStringArg(args, "preferred_time", "")
+	rankBy := GetStringArg(args, "rank_by", "")
+	if err := validateRanking(preferredTime, rankBy); err != nil {
+		return nil, err
+	}
+	offset, err := GetCursorArg(args, "cursor")
+	if err != nil {
+		return nil, err
+	}
 
-	t.logger.Info("searching for tee times",
+	t.logger.InfoContext(ctx, "searching for tee times",
 		slog.String("course_name", courseName),
 		slog.String("start_time", startTime),
 		slog.Int("num_players", numPlayers),
 		slog.Bool("auto_book", autoBook),
+		slog.Float64("max_price", maxPrice),
 	)
 
 	// Load course configuration
 	course, err := courses.GetCourseByName(courseName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find course: %w", err)
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("failed to find course: %w", err))
 	}
 
-	secretName := course.GetSecretName(t.stage)
+	secretName, err := golfSecretName(ctx, t.users, course, t.stage)
+	if err != nil {
+		return nil, err
+	}
 
-	t.logger.Info("using course configuration",
+	t.logger.InfoContext(ctx, "using course configuration",
 		slog.String("name", course.Name),
 	)
 
@@ -203,35 +376,226 @@ func (t *GolfSearchTeeTimesTool) Execute(ctx context.Context, args map[string]in
 		EndSearchTime:   endTime,
 		NumberOfPlayers: numPlayers,
 		AutoBook:        autoBook,
+		LockAttempts:    GetIntArg(args, "lock_attempts", 0),
+		DryRun:          GetBoolArg(args, "dry_run", false),
+		MaxResults:      maxResults,
+		ResultOffset:    offset,
+		MaxPrice:        maxPrice,
+		PreferredTime:   preferredTime,
+		RankBy:          rankBy,
+		Policy:          bookingPolicy,
 	}
 
 	_args := make(map[string]interface{})
 	_args["operation"] = "search_tee_times"
 
 	// Execute golf handler
+	receipt := &webaction.BookingReceipt{}
+	results, err := t.golfHandler.Execute(webaction.WithBookingReceipt(ctx, receipt), _args, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tee times: %w", err)
+	}
+
+	content, err := bookingContent(results, receipt)
+	if err != nil {
+		return nil, err
+	}
+	setNextPage(ctx, payload)
+
+	return content, nil
+}
+
+// GolfSearchTeeTimesRangeTool implements the golf_search_tee_times_range MCP tool
+type GolfSearchTeeTimesRangeTool struct {
+	golfHandler *webaction.GolfHandler
+	logger      *slog.Logger
+	stage       string
+	users       repository.UserRepository
+}
+
+// NewGolfSearchTeeTimesRangeTool creates a new multi-day golf tee time search tool
+func NewGolfSearchTeeTimesRangeTool(httpClient *httpclient.Client, oauthClient *oauth.Client,
+	secretsManager *secrets.Manager, logger *slog.Logger) *GolfSearchTeeTimesRangeTool {
+	stage := os.Getenv("STAGE")
+	if stage == "" {
+		stage = "dev"
+	}
+	return &GolfSearchTeeTimesRangeTool{
+		golfHandler: webaction.NewGolfHandler(httpClient, oauthClient, secretsManager, logger),
+		logger:      logger,
+		stage:       stage,
+	}
+}
+
+// SetUserRepository makes calls acting for a user, such as a scheduled run, use that user's golf
+// credentials instead of the course's shared ones
+func (t *GolfSearchTeeTimesRangeTool) SetUserRepository(users repository.UserRepository) {
+	t.users = users
+}
+
+// GetDefinition returns the tool's MCP definition
+func (t *GolfSearchTeeTimesRangeTool) GetDefinition() protocol.Tool {
+	return protocol.Tool{
+		Name:        "golf_search_tee_times_range",
+		Description: "Search every date in a range at once and return the best available tee times with their dates (cheapest first unless preferred_time or rank_by is given)",
+		InputSchema: protocol.InputSchema{
+			Type: "object",
+			Properties: map[string]protocol.Property{
+				"course_name": {
+					Type:        "string",
+					Description: "Name of the golf course (e.g., 'Birdsfoot Golf Course' or 'Totteridge')",
+				},
+				"start_time": {
+					Type:        "string",
+					Description: "First date and earliest time of day in ISO format (YYYY-MM-DDTHH:MM:SS)",
+				},
+				"end_time": {
+					Type:        "string",
+					Description: "Latest time of day on the first date in ISO format (YYYY-MM-DDTHH:MM:SS); the same window is used for every date (optional)",
+				},
+				"end_date": {
+					Type:        "string",
+					Description: "Last date to search in YYYY-MM-DD format (at most 14 days)",
+				},
+				"num_players": {
+					Type:        "integer",
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(4),
+					Description: "Number of players",
+				},
+				"max_results": {
+					Type:        "integer",
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(20),
+					Default:     5,
+					Description: "Number of tee times to return per page",
+				},
+				"cursor": {
+					Type:        "string",
+					Description: "Cursor from a previous search's result to fetch its next page of tee times (optional)",
+				},
+				"max_price": {
+					Type:        "number",
+					Description: "Maximum 18-hole green fee per player in dollars; more expensive times are skipped (optional)",
+				},
+				"preferred_time": {
+					Type:        "string",
+					Description: "Preferred time of day in HH:MM (24-hour) format; times closest to it are ranked first (optional)",
+				},
+				"rank_by": {
+					Type:        "string",
+					Description: "Comma-separated ranking criteria in priority order: time, price, holes (optional, default 'time,price' when preferred_time is set)",
+				},
+				"policy": {
+					Type:        "object",
+					Description: "Hard booking rules (max_price, blackout_dates, max_precip_chance, min_daylight_hours); times that break them are skipped (optional)",
+				},
+			},
+			Required: []string{"course_name", "start_time", "end_date", "num_players"},
+		},
+	}
+}
+
+// ValidateInput validates the tool's input arguments
+func (t *GolfSearchTeeTimesRangeTool) ValidateInput(args map[string]interface{}) error {
+	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+}
+
+// Execute runs the tool with the given arguments
+func (t *GolfSearchTeeTimesRangeTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+	courseName := GetStringArg(args, "course_name", "")
+	startTime := GetStringArg(args, "start_time", "")
+	endTime := GetStringArg(args, "end_time", "")
+	endDate := GetStringArg(args, "end_date", "")
+	numPlayers := GetIntArg(args, "num_players", 1)
+	maxResults := GetIntArg(args, "max_results", 5)
+	maxPrice := GetFloatArg(args, "max_price", 0)
+	bookingPolicy, err := GetPolicyArg(args, "policy")
+	if err != nil {
+		return nil, err
+	}
+	preferredTime := GetStringArg(args, "preferred_time", "")
+	rankBy := GetStringArg(args, "rank_by", "")
+	if err := validateRanking(preferredTime, rankBy); err != nil {
+		return nil, err
+	}
+	offset, err := GetCursorArg(args, "cursor")
+	if err != nil {
+		return nil, err
+	}
+
+	t.logger.InfoContext(ctx, "searching for tee times across dates",
+		slog.String("course_name", courseName),
+		slog.String("start_time", startTime),
+		slog.String("end_date", endDate),
+		slog.Int("num_players", numPlayers),
+		slog.Float64("max_price", maxPrice),
+	)
+
+	course, err := courses.GetCourseByName(courseName)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("failed to find course: %w", err))
+	}
+
+	secretName, err := golfSecretName(ctx, t.users, course, t.stage)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &models.WebActionPayload{
+		Action:   models.WebActionTypeGolf,
+		CourseID: course.CourseID,
+		AuthConfig: &models.AuthConfig{
+			Type:       models.AuthTypeOAuthPassword,
+			SecretName: secretName,
+		},
+
+		StartSearchTime: startTime,
+		EndSearchTime:   endTime,
+		EndSearchDate:   endDate,
+		NumberOfPlayers: numPlayers,
+		MaxResults:      maxResults,
+		ResultOffset:    offset,
+		MaxPrice:        maxPrice,
+		PreferredTime:   preferredTime,
+		RankBy:          rankBy,
+		Policy:          bookingPolicy,
+	}
+
+	_args := make(map[string]interface{})
+	_args["operation"] = "search_tee_times_range"
+
 	results, err := t.golfHandler.Execute(ctx, _args, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search tee times: %w", err)
 	}
 
-	// Convert results to content
 	var content []protocol.Content
 	for _, result := range results {
 		content = append(content, protocol.NewTextContent(result))
 	}
+	setNextPage(ctx, payload)
 
 	return content, nil
 }
 
+// setNextPage returns a cursor to the client when the search left tee times for a later page
+func setNextPage(ctx context.Context, payload *models.WebActionPayload) {
+	if payload.NextResultOffset > 0 {
+		server.SetNextCursor(ctx, protocol.EncodeOffsetCursor(payload.NextResultOffset))
+	}
+}
+
 // GolfBookTeeTimeTool implements the golf_book_tee_time MCP tool
 type GolfBookTeeTimeTool struct {
 	golfHandler *webaction.GolfHandler
 	logger      *slog.Logger
 	stage       string
+	users       repository.UserRepository
 }
 
 // NewGolfBookTeeTimeTool creates a new golf tee time booking tool
-func NewGolfBookTeeTimeTool(httpClient *httpclient.Client, oauthClient *httpclient.OAuthClient,
+func NewGolfBookTeeTimeTool(httpClient *httpclient.Client, oauthClient *oauth.Client,
 	secretsManager *secrets.Manager, logger *slog.Logger) *GolfBookTeeTimeTool {
 	stage := os.Getenv("STAGE")
 	if stage == "" {
@@ -244,6 +608,27 @@ func NewGolfBookTeeTimeTool(httpClient *httpclient.Client, oauthClient *httpclie
 	}
 }
 
+// SetUserRepository makes calls acting for a user, such as a scheduled run, use that user's golf
+// credentials instead of the course's shared ones
+func (t *GolfBookTeeTimeTool) SetUserRepository(users repository.UserRepository) {
+	t.users = users
+}
+
+// SetApprovalWorkflow enables require_approval bookings
+func (t *GolfBookTeeTimeTool) SetApprovalWorkflow(repo repository.ApprovalRepository, notifier webaction.ApprovalNotifier, apiBaseURL string) {
+	t.golfHandler.SetApprovalWorkflow(repo, notifier, apiBaseURL)
+}
+
+// SetAuditRecorder records the tee times the tool books in the audit log
+func (t *GolfBookTeeTimeTool) SetAuditRecorder(recorder *audit.Recorder) {
+	t.golfHandler.SetAuditRecorder(recorder)
+}
+
+// SetBookingLedger claims the tee times the tool books in the booking ledger before reserving them
+func (t *GolfBookTeeTimeTool) SetBookingLedger(ledger repository.BookingLedgerRepository) {
+	t.golfHandler.SetBookingLedger(ledger)
+}
+
 // GetDefinition returns the tool's MCP definition
 func (t *GolfBookTeeTimeTool) GetDefinition() protocol.Tool {
 	return protocol.Tool{
@@ -260,6 +645,22 @@ func (t *GolfBookTeeTimeTool) GetDefinition() protocol.Tool {
 					Type:        "integer",
 					Description: "The tee sheet ID from search results",
 				},
+				"max_price": {
+					Type:        "number",
+					Description: "Maximum 18-hole green fee per player in dollars; the booking is aborted if the price is higher (optional)",
+				},
+				"policy": {
+					Type:        "object",
+					Description: "Hard booking rules (max_price, blackout_dates, max_precip_chance, min_daylight_hours); the booking is aborted if they are broken (optional)",
+				},
+				"require_approval": {
+					Type:        "boolean",
+					Description: "Hold the tee time and send the golfer approve/decline buttons instead of booking immediately (optional)",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Price the tee time and report what would be booked without locking or reserving it (optional)",
+				},
 			},
 			Required: []string{"course_name", "tee_sheet_id"},
 		},
@@ -275,8 +676,13 @@ func (t *GolfBookTeeTimeTool) ValidateInput(args map[string]interface{}) error {
 func (t *GolfBookTeeTimeTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
 	courseName := GetStringArg(args, "course_name", "")
 	teeSheetID := GetIntArg(args, "tee_sheet_id", 0)
+	maxPrice := GetFloatArg(args, "max_price", 0)
+	bookingPolicy, err := GetPolicyArg(args, "policy")
+	if err != nil {
+		return nil, err
+	}
 
-	t.logger.Info("booking tee time",
+	t.logger.InfoContext(ctx, "booking tee time",
 		slog.String("course_name", courseName),
 		slog.Int("tee_sheet_id", teeSheetID),
 	)
@@ -284,10 +690,13 @@ func (t *GolfBookTeeTimeTool) Execute(ctx context.Context, args map[string]inter
 	// Load course configuration
 	course, err := courses.GetCourseByName(courseName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find course: %w", err)
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("failed to find course: %w", err))
 	}
 
-	secretName := course.GetSecretName(t.stage)
+	secretName, err := golfSecretName(ctx, t.users, course, t.stage)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create web action payload
 	payload := &models.WebActionPayload{
@@ -297,22 +706,38 @@ func (t *GolfBookTeeTimeTool) Execute(ctx context.Context, args map[string]inter
 			Type:       models.AuthTypeOAuthPassword,
 			SecretName: secretName,
 		},
-		TeeSheetID: teeSheetID,
+		TeeSheetID:      teeSheetID,
+		MaxPrice:        maxPrice,
+		Policy:          bookingPolicy,
+		RequireApproval: GetBoolArg(args, "require_approval", false),
+		DryRun:          GetBoolArg(args, "dry_run", false),
 	}
 	_args := make(map[string]interface{})
 	_args["operation"] = "book_tee_time"
 
 	// Execute golf handler
-	results, err := t.golfHandler.Execute(ctx, _args, payload)
+	receipt := &webaction.BookingReceipt{}
+	results, err := t.golfHandler.Execute(webaction.WithBookingReceipt(ctx, receipt), _args, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to book tee time: %w", err)
 	}
 
-	// Convert results to content
+	return bookingContent(results, receipt)
+}
+
+// bookingContent converts golf handler results to content, followed by a JSON item for each tee
+// time the call reserved so an agent run can check its bookings against the booking policy
+func bookingContent(results []string, receipt *webaction.BookingReceipt) ([]protocol.Content, error) {
 	var content []protocol.Content
 	for _, result := range results {
 		content = append(content, protocol.NewTextContent(result))
 	}
-
+	for _, booked := range receipt.Bookings() {
+		item, err := protocol.NewJSONContent(booked)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, item)
+	}
 	return content, nil
 }
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+func TestGolfSecretName(t *testing.T) {
+	course, err := courses.GetCourseByName("Totteridge")
+	if err != nil {
+		t.Fatalf("GetCourseByName() error = %v", err)
+	}
+	shared := course.GetSecretName("dev")
+
+	users := repository.NewMemoryUserRepository()
+	for _, user := range []*models.User{
+		{ID: "alice", Name: "Alice", Role: models.UserRoleMember, GolfSecretName: "rez-agent/golf/users/alice"},
+		{ID: "bob", Name: "Bob", Role: models.UserRoleMember},
+		{ID: "carol", Name: "Carol", Role: models.UserRoleMember, GolfSecretName: "rez-agent/golf/users/carol", Disabled: true},
+	} {
+		if err := users.CreateUser(context.Background(), user); err != nil {
+			t.Fatalf("CreateUser(%s) error = %v", user.ID, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		users   repository.UserRepository
+		acting  string
+		want    string
+		wantErr error
+	}{
+		{"no acting user", users, "", shared, nil},
+		{"single-user mode", nil, "alice", shared, nil},
+		{"acting user", users, "alice", "rez-agent/golf/users/alice", nil},
+		{"user without credentials", users, "bob", "", apperrors.ErrAuth},
+		{"disabled user", users, "carol", "", apperrors.ErrAuth},
+		{"unknown user", users, "dave", "", apperrors.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := models.WithActingUser(context.Background(), tt.acting)
+			got, err := golfSecretName(ctx, tt.users, course, "dev")
+			if tt.wantErr != nil {
+				if !apperrors.Is(err, tt.wantErr) {
+					t.Errorf("golfSecretName() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("golfSecretName() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("golfSecretName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGolfSearchTools_RejectInvalidArguments(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	searchTools := []interface {
+		Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error)
+	}{
+		NewGolfSearchTeeTimesTool(nil, nil, nil, logger),
+		NewGolfSearchTeeTimesRangeTool(nil, nil, nil, logger),
+	}
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"invalid policy", map[string]interface{}{"policy": map[string]interface{}{"max_precip_chance": 150}}},
+		{"invalid preferred time", map[string]interface{}{"preferred_time": "noonish"}},
+		{"invalid rank_by", map[string]interface{}{"rank_by": "time,vibes"}},
+	}
+	for _, tool := range searchTools {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				args := map[string]interface{}{
+					"course_name": "Totteridge",
+					"start_time":  "2030-06-01T07:00:00",
+					"end_time":    "2030-06-01T10:00:00",
+					"end_date":    "2030-06-02",
+					"num_players": 2,
+				}
+				for k, v := range tt.args {
+					args[k] = v
+				}
+				if _, err := tool.Execute(context.Background(), args); !apperrors.Is(err, apperrors.ErrValidation) {
+					t.Errorf("%T.Execute() error = %v, want a validation error", tool, err)
+				}
+			})
+		}
+	}
+}
@@ -5,8 +5,9 @@ import (
 	"fmt"
 	"log/slog"
 
-	"github.com/jrzesz33/rez_agent/internal/mcp/protocol"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
 	"github.com/jrzesz33/rez_agent/internal/notification"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
 )
 
 // NotificationTool implements the send_push_notification MCP tool
@@ -30,7 +31,7 @@ func NewNotificationTool(ntfyURL string, logger *slog.Logger) *NotificationTool
 func (t *NotificationTool) GetDefinition() protocol.Tool {
 	return protocol.Tool{
 		Name:        "send_push_notification",
-		Description: "Send a push notification via ntfy.sh to the configured alert topic",
+		Description: "Send a push notification via ntfy.sh to the configured alert topic, optionally with tags, a click URL, action buttons and an attachment",
 		InputSchema: protocol.InputSchema{
 			Type: "object",
 			Properties: map[string]protocol.Property{
@@ -44,41 +45,141 @@ func (t *NotificationTool) GetDefinition() protocol.Tool {
 				},
 				"priority": {
 					Type:        "string",
-					Description: "Notification priority level",
-					Enum:        []string{"low", "default", "high"},
+					Description: "Notification priority level; urgent overrides do-not-disturb on the phone",
+					Enum:        notification.Priorities,
 					Default:     "default",
 				},
+				"tags": {
+					Type:        "array",
+					Description: "Tags shown beside the title; emoji shortcodes such as golf, warning or white_check_mark render as emoji (optional)",
+					Items:       &protocol.Property{Type: "string"},
+				},
+				"click_url": {
+					Type:        "string",
+					Description: "URL opened when the notification is tapped (optional)",
+					Format:      "uri",
+				},
+				"actions": {
+					Type:        "array",
+					Description: fmt.Sprintf("Up to %d action buttons, e.g. 'View reservation' opening the course site (optional)", notification.MaxActions),
+					Items: &protocol.Property{
+						Type: "object",
+						Properties: map[string]protocol.Property{
+							"label": {
+								Type:        "string",
+								Description: "Button label",
+							},
+							"url": {
+								Type:        "string",
+								Description: "URL the button opens or requests",
+								Format:      "uri",
+							},
+							"type": {
+								Type:        "string",
+								Description: "view opens the URL; http sends a request to it without opening anything",
+								Enum:        []string{notification.ActionView, notification.ActionHTTP},
+								Default:     notification.ActionView,
+							},
+							"method": {
+								Type:        "string",
+								Description: "HTTP method of an http action (default: POST)",
+								Enum:        []string{"GET", "POST", "PUT", "DELETE"},
+							},
+						},
+						Required: []string{"label", "url"},
+					},
+				},
+				"attachment_url": {
+					Type:        "string",
+					Description: "URL of a file or image to attach (optional)",
+					Format:      "uri",
+				},
+				"attachment_name": {
+					Type:        "string",
+					Description: "File name shown for the attachment (optional)",
+				},
 			},
 			Required: []string{"message"},
 		},
+		Annotations: &protocol.ToolAnnotations{
+			// The notification reports the outcome, so it ends a scheduled agent run
+			Terminal: true,
+			Aliases:  []string{"send_notification"},
+		},
 	}
 }
 
 // ValidateInput validates the tool's input arguments
 func (t *NotificationTool) ValidateInput(args map[string]interface{}) error {
-	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+	if err := ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema); err != nil {
+		return err
+	}
+	if err := notificationOptions(args).Validate(); err != nil {
+		return apperrors.Wrap(apperrors.ErrValidation, err)
+	}
+	return nil
 }
 
 // Execute runs the tool with the given arguments
 func (t *NotificationTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
-	title := GetStringArg(args, "title", "rez_agent Notification")
 	message := GetStringArg(args, "message", "")
-	priority := GetStringArg(args, "priority", "default")
+	opts := notificationOptions(args)
 
 	if message == "" {
-		return nil, fmt.Errorf("message cannot be empty")
+		return nil, apperrors.Newf(apperrors.ErrValidation, "message cannot be empty")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, err)
 	}
 
-	t.logger.Info("sending push notification",
-		slog.String("title", title),
-		slog.String("priority", priority),
+	t.logger.InfoContext(ctx, "sending push notification",
+		slog.String("title", opts.Title),
+		slog.String("priority", opts.Priority),
+		slog.Int("actions", len(opts.Actions)),
+		slog.Bool("attachment", opts.Attach != ""),
 	)
 
-	if err := t.ntfyClient.SendWithTitle(ctx, title, message); err != nil {
+	if err := t.ntfyClient.SendWithOptions(ctx, message, opts); err != nil {
 		return nil, fmt.Errorf("failed to send notification: %w", err)
 	}
 
 	return []protocol.Content{
-		protocol.NewTextContent(fmt.Sprintf("✅ Notification sent successfully: %s", title)),
+		protocol.NewTextContent(fmt.Sprintf("✅ Notification sent successfully: %s", opts.Title)),
 	}, nil
 }
+
+// notificationOptions reads the ntfy options of a tool call
+func notificationOptions(args map[string]interface{}) notification.Options {
+	opts := notification.Options{
+		Title:    GetStringArg(args, "title", "rez_agent Notification"),
+		Priority: GetStringArg(args, "priority", "default"),
+		Click:    GetStringArg(args, "click_url", ""),
+		Attach:   GetStringArg(args, "attachment_url", ""),
+		Filename: GetStringArg(args, "attachment_name", ""),
+	}
+
+	if tags, ok := args["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok && tag != "" {
+				opts.Tags = append(opts.Tags, tag)
+			}
+		}
+	}
+
+	if actions, ok := args["actions"].([]interface{}); ok {
+		for _, item := range actions {
+			action, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			opts.Actions = append(opts.Actions, notification.Action{
+				Type:   GetStringArg(action, "type", notification.ActionView),
+				Label:  GetStringArg(action, "label", ""),
+				URL:    GetStringArg(action, "url", ""),
+				Method: GetStringArg(action, "method", ""),
+			})
+		}
+	}
+
+	return opts
+}
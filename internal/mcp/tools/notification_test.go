@@ -17,6 +17,11 @@ func TestNotificationTool_GetDefinition(t *testing.T) {
 		t.Errorf("Name = %v, want send_push_notification", def.Name)
 	}
 
+	// The scheduler relies on this flag, not the tool name, to end a run
+	if !def.IsTerminal() {
+		t.Error("notification tool should be terminal")
+	}
+
 	if def.InputSchema.Type != "object" {
 		t.Errorf("InputSchema.Type = %v, want object", def.InputSchema.Type)
 	}
@@ -45,8 +50,8 @@ func TestNotificationTool_GetDefinition(t *testing.T) {
 
 	// Check priority enum
 	priorityProp := def.InputSchema.Properties["priority"]
-	if len(priorityProp.Enum) != 3 {
-		t.Errorf("Priority enum count = %d, want 3", len(priorityProp.Enum))
+	if len(priorityProp.Enum) != 5 {
+		t.Errorf("Priority enum count = %d, want 5 (min through urgent)", len(priorityProp.Enum))
 	}
 }
 
@@ -88,6 +93,48 @@ func TestNotificationTool_ValidateInput(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid tags, click URL, actions and attachment",
+			args: map[string]interface{}{
+				"message":   "Booked!",
+				"tags":      []interface{}{"golf", "white_check_mark"},
+				"click_url": "https://birdsfoot.cps.golf/onlineresweb",
+				"actions": []interface{}{
+					map[string]interface{}{"label": "View reservation", "url": "https://birdsfoot.cps.golf/onlineresweb"},
+				},
+				"attachment_url": "https://example.com/scorecard.png",
+			},
+			wantErr: false,
+		},
+		{
+			name: "action missing url",
+			args: map[string]interface{}{
+				"message": "Booked!",
+				"actions": []interface{}{map[string]interface{}{"label": "View reservation"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many actions",
+			args: map[string]interface{}{
+				"message": "Booked!",
+				"actions": []interface{}{
+					map[string]interface{}{"label": "1", "url": "https://example.com/1"},
+					map[string]interface{}{"label": "2", "url": "https://example.com/2"},
+					map[string]interface{}{"label": "3", "url": "https://example.com/3"},
+					map[string]interface{}{"label": "4", "url": "https://example.com/4"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-string tag",
+			args: map[string]interface{}{
+				"message": "Booked!",
+				"tags":    []interface{}{42},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid message type",
 			args: map[string]interface{}{
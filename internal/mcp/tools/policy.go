@@ -0,0 +1,251 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/policy"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// policyCandidateProperties are the input properties shared by the policy tools
+func policyCandidateProperties() map[string]protocol.Property {
+	return map[string]protocol.Property{
+		"course_name": {
+			Type:        "string",
+			Description: "Name of the golf course (e.g., 'Birdsfoot Golf Course' or 'Totteridge')",
+		},
+		"tee_time": {
+			Type:        "string",
+			Description: "Local tee time in YYYY-MM-DDTHH:MM:SS format, or a date in YYYY-MM-DD format",
+		},
+		"green_fee": {
+			Type:        "number",
+			Description: "18-hole green fee per player in dollars (optional)",
+		},
+		"precip_chance": {
+			Type:        "integer",
+			Description: "Forecast chance of precipitation in percent (optional)",
+			Minimum:     intPtr(0),
+			Maximum:     intPtr(100),
+		},
+		"policy": {
+			Type:        "object",
+			Description: "Hard booking rules (max_price, blackout_dates, max_precip_chance, min_daylight_hours)",
+		},
+	}
+}
+
+// parsePolicyCandidate builds the policy and candidate from the tool arguments
+func parsePolicyCandidate(args map[string]interface{}) (*policy.Config, policy.Candidate, error) {
+	var candidate policy.Candidate
+
+	cfg, err := GetPolicyArg(args, "policy")
+	if err != nil {
+		return nil, candidate, err
+	}
+
+	course, err := courses.GetCourseByName(GetStringArg(args, "course_name", ""))
+	if err != nil {
+		return nil, candidate, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("course not found: %w", err))
+	}
+
+	loc := course.Location()
+
+	teeTime := GetStringArg(args, "tee_time", "")
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", teeTime, loc); err == nil {
+		candidate.TeeTime = t
+		candidate.HasTime = true
+	} else if t, err := time.ParseInLocation("2006-01-02", teeTime, loc); err == nil {
+		candidate.TeeTime = t
+	} else {
+		return nil, candidate, apperrors.Newf(apperrors.ErrValidation, "tee_time must be in YYYY-MM-DDTHH:MM:SS or YYYY-MM-DD format")
+	}
+
+	candidate.GreenFee = GetFloatArg(args, "green_fee", 0)
+	if _, ok := args["precip_chance"]; ok {
+		precip := GetIntArg(args, "precip_chance", 0)
+		candidate.PrecipChance = &precip
+	}
+	if course.Latitude != 0 || course.Longitude != 0 {
+		candidate.Location = &policy.Coordinates{Latitude: course.Latitude, Longitude: course.Longitude}
+	}
+
+	return cfg, candidate, nil
+}
+
+// formatRuleResults lists each rule's outcome, noting when a rule could not be applied
+func formatRuleResults(sb *strings.Builder, results []policy.RuleResult) {
+	if len(results) == 0 {
+		sb.WriteString("- No hard booking rules are configured\n")
+		return
+	}
+	for _, r := range results {
+		if r.Passed {
+			sb.WriteString(fmt.Sprintf("- %s: passed\n", r.Rule))
+		} else {
+			sb.WriteString(fmt.Sprintf("- %s: FAILED - %s\n", r.Rule, r.Reason))
+		}
+	}
+}
+
+// CheckConstraintsTool implements the check_constraints MCP tool
+type CheckConstraintsTool struct {
+	logger *slog.Logger
+}
+
+// NewCheckConstraintsTool creates a new policy constraint checking tool
+func NewCheckConstraintsTool(logger *slog.Logger) *CheckConstraintsTool {
+	return &CheckConstraintsTool{
+		logger: logger,
+	}
+}
+
+// GetDefinition returns the tool's MCP definition
+func (t *CheckConstraintsTool) GetDefinition() protocol.Tool {
+	return protocol.Tool{
+		Name:        "check_constraints",
+		Description: "Check a tee time against the hard booking rules (budget, blackout dates, weather, daylight) before booking it",
+		InputSchema: protocol.InputSchema{
+			Type:       "object",
+			Properties: policyCandidateProperties(),
+			Required:   []string{"course_name", "tee_time"},
+		},
+	}
+}
+
+// ValidateInput validates the tool's input arguments
+func (t *CheckConstraintsTool) ValidateInput(args map[string]interface{}) error {
+	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+}
+
+// Execute runs the tool with the given arguments
+func (t *CheckConstraintsTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+	cfg, candidate, err := parsePolicyCandidate(args)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid constraint check: %w", err))
+	}
+
+	engine := policy.NewEngine(cfg)
+	decision := engine.Evaluate(candidate)
+
+	t.logger.InfoContext(ctx, "constraints checked",
+		slog.String("course_name", GetStringArg(args, "course_name", "")),
+		slog.String("tee_time", GetStringArg(args, "tee_time", "")),
+		slog.Bool("allowed", decision.Allowed),
+	)
+
+	var sb strings.Builder
+	if decision.Allowed {
+		sb.WriteString("ALLOWED: this tee time satisfies every hard booking rule\n\n")
+	} else {
+		sb.WriteString("NOT ALLOWED: do not book this tee time\n\n")
+	}
+	formatRuleResults(&sb, engine.Check(candidate))
+
+	return []protocol.Content{
+		protocol.NewTextContent(sb.String()),
+	}, nil
+}
+
+// ExplainDecisionTool implements the explain_decision MCP tool
+type ExplainDecisionTool struct {
+	logger *slog.Logger
+}
+
+// NewExplainDecisionTool creates a new booking decision explanation tool
+func NewExplainDecisionTool(logger *slog.Logger) *ExplainDecisionTool {
+	return &ExplainDecisionTool{
+		logger: logger,
+	}
+}
+
+// GetDefinition returns the tool's MCP definition
+func (t *ExplainDecisionTool) GetDefinition() protocol.Tool {
+	properties := policyCandidateProperties()
+	properties["decision"] = protocol.Property{
+		Type:        "string",
+		Description: "Whether the tee time was booked or skipped",
+		Enum:        []string{"booked", "skipped"},
+	}
+	properties["rationale"] = protocol.Property{
+		Type:        "string",
+		Description: "Short explanation of the preference trade-offs behind the decision (optional)",
+	}
+
+	return protocol.Tool{
+		Name:        "explain_decision",
+		Description: "Produce a machine-verified justification for a booking decision by re-checking it against the hard booking rules; include the result in the notification",
+		InputSchema: protocol.InputSchema{
+			Type:       "object",
+			Properties: properties,
+			Required:   []string{"course_name", "tee_time", "decision"},
+		},
+	}
+}
+
+// ValidateInput validates the tool's input arguments
+func (t *ExplainDecisionTool) ValidateInput(args map[string]interface{}) error {
+	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+}
+
+// Execute runs the tool with the given arguments
+func (t *ExplainDecisionTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+	cfg, candidate, err := parsePolicyCandidate(args)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid decision: %w", err))
+	}
+
+	courseName := GetStringArg(args, "course_name", "")
+	decision := GetStringArg(args, "decision", "")
+	rationale := GetStringArg(args, "rationale", "")
+
+	engine := policy.NewEngine(cfg)
+	results := engine.Check(candidate)
+	policyDecision := engine.Evaluate(candidate)
+	consistent := policyDecision.Allowed || decision == "skipped"
+
+	// Structured log entry serves as the audit record of the decision
+	t.logger.InfoContext(ctx, "booking decision explained",
+		slog.String("course_name", courseName),
+		slog.String("tee_time", GetStringArg(args, "tee_time", "")),
+		slog.String("decision", decision),
+		slog.Bool("policy_allowed", policyDecision.Allowed),
+		slog.Bool("consistent", consistent),
+		slog.String("rationale", rationale),
+	)
+
+	when := candidate.TeeTime.Format("Mon Jan 2, 2006")
+	if candidate.HasTime {
+		when = candidate.TeeTime.Format("Mon Jan 2, 2006 3:04 PM")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Decision: %s %s on %s\n\n", decision, courseName, when))
+	sb.WriteString("Policy checks:\n")
+	formatRuleResults(&sb, results)
+	sb.WriteString("\n")
+
+	switch {
+	case !consistent:
+		sb.WriteString(fmt.Sprintf("WARNING: booked despite policy violations (%s)\n", policyDecision.Err()))
+	case decision == "skipped" && !policyDecision.Allowed:
+		sb.WriteString("Verified: skipped because the tee time breaks the hard booking rules\n")
+	case decision == "skipped":
+		sb.WriteString("Verified: the tee time met every hard rule and was skipped for preference reasons\n")
+	default:
+		sb.WriteString("Verified: the booking satisfies every hard booking rule\n")
+	}
+	if rationale != "" {
+		sb.WriteString(fmt.Sprintf("Rationale: %s\n", rationale))
+	}
+
+	return []protocol.Content{
+		protocol.NewTextContent(sb.String()),
+	}, nil
+}
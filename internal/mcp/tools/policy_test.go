@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckConstraintsTool_Execute(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	tool := NewCheckConstraintsTool(logger)
+
+	rules := map[string]interface{}{
+		"max_price":      40.0,
+		"blackout_dates": []interface{}{"2030-07-04"},
+	}
+
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		wantText string
+		wantErr  bool
+	}{
+		{
+			name: "allowed",
+			args: map[string]interface{}{
+				"course_name": "Totteridge",
+				"tee_time":    "2030-06-01T09:00:00",
+				"green_fee":   35.0,
+				"policy":      rules,
+			},
+			wantText: "ALLOWED",
+		},
+		{
+			name: "over budget",
+			args: map[string]interface{}{
+				"course_name": "Totteridge",
+				"tee_time":    "2030-06-01T09:00:00",
+				"green_fee":   55.0,
+				"policy":      rules,
+			},
+			wantText: "budget: FAILED",
+		},
+		{
+			name: "blackout date without time",
+			args: map[string]interface{}{
+				"course_name": "Birdsfoot Golf Course",
+				"tee_time":    "2030-07-04",
+				"policy":      rules,
+			},
+			wantText: "blackout: FAILED",
+		},
+		{
+			name: "no policy",
+			args: map[string]interface{}{
+				"course_name": "Totteridge",
+				"tee_time":    "2030-06-01T09:00:00",
+			},
+			wantText: "No hard booking rules",
+		},
+		{
+			name: "unknown course",
+			args: map[string]interface{}{
+				"course_name": "Augusta",
+				"tee_time":    "2030-06-01T09:00:00",
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad tee time",
+			args: map[string]interface{}{
+				"course_name": "Totteridge",
+				"tee_time":    "June 1",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := tool.Execute(context.Background(), tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(content) != 1 || !strings.Contains(content[0].Text, tt.wantText) {
+				t.Errorf("Execute() = %+v, want text containing %q", content, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestExplainDecisionTool_Execute(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	tool := NewExplainDecisionTool(logger)
+
+	rules := map[string]interface{}{"max_precip_chance": 60}
+
+	tests := []struct {
+		name     string
+		decision string
+		precip   int
+		wantText string
+	}{
+		{"booked within rules", "booked", 10, "Verified: the booking satisfies"},
+		{"booked despite rain", "booked", 80, "WARNING"},
+		{"skipped for rain", "skipped", 80, "breaks the hard booking rules"},
+		{"skipped by preference", "skipped", 10, "preference reasons"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := tool.Execute(context.Background(), map[string]interface{}{
+				"course_name":   "Totteridge",
+				"tee_time":      "2030-06-01T09:00:00",
+				"precip_chance": tt.precip,
+				"decision":      tt.decision,
+				"rationale":     "morning slot at the favorite course",
+				"policy":        rules,
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			text := content[0].Text
+			if !strings.Contains(text, tt.wantText) || !strings.Contains(text, "Rationale: morning slot") {
+				t.Errorf("Execute() = %q, want text containing %q and the rationale", text, tt.wantText)
+			}
+		})
+	}
+}
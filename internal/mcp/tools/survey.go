@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// RecordRoundSurveyTool implements the record_round_survey MCP tool
+type RecordRoundSurveyTool struct {
+	repo   repository.PreferenceRepository
+	logger *slog.Logger
+}
+
+// NewRecordRoundSurveyTool creates a new round survey recording tool
+func NewRecordRoundSurveyTool(repo repository.PreferenceRepository, logger *slog.Logger) *RecordRoundSurveyTool {
+	return &RecordRoundSurveyTool{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// GetDefinition returns the tool's MCP definition
+func (t *RecordRoundSurveyTool) GetDefinition() protocol.Tool {
+	return protocol.Tool{
+		Name:        "record_round_survey",
+		Description: "Record the golfer's post-round survey answers (enjoyment, pace of play, price) so future bookings favor courses and times they liked",
+		InputSchema: protocol.InputSchema{
+			Type: "object",
+			Properties: map[string]protocol.Property{
+				"course_name": {
+					Type:        "string",
+					Description: "Name of the golf course the round was played at",
+				},
+				"tee_time": {
+					Type:        "string",
+					Description: "Local tee time of the round in YYYY-MM-DDTHH:MM:SS format",
+				},
+				"enjoyment": {
+					Type:        "integer",
+					Description: "How much the golfer enjoyed the round (1-5)",
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(5),
+				},
+				"pace_of_play": {
+					Type:        "integer",
+					Description: "Pace of play rating (1-5)",
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(5),
+				},
+				"price_fair": {
+					Type:        "boolean",
+					Description: "Whether the price felt fair",
+				},
+				"comment": {
+					Type:        "string",
+					Description: "Optional free-form feedback",
+				},
+			},
+			Required: []string{"course_name", "tee_time", "enjoyment", "pace_of_play", "price_fair"},
+		},
+	}
+}
+
+// ValidateInput validates the tool's input arguments
+func (t *RecordRoundSurveyTool) ValidateInput(args map[string]interface{}) error {
+	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+}
+
+// Execute runs the tool with the given arguments
+func (t *RecordRoundSurveyTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+	survey, err := models.NewRoundSurvey(
+		GetStringArg(args, "course_name", ""),
+		GetStringArg(args, "tee_time", ""),
+		GetIntArg(args, "enjoyment", 0),
+		GetIntArg(args, "pace_of_play", 0),
+		GetBoolArg(args, "price_fair", false),
+		GetStringArg(args, "comment", ""),
+	)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid round survey: %w", err))
+	}
+
+	if err := t.repo.SaveSurvey(ctx, survey); err != nil {
+		return nil, fmt.Errorf("failed to record round survey: %w", err)
+	}
+
+	t.logger.InfoContext(ctx, "round survey recorded",
+		slog.String("course_name", survey.CourseName),
+		slog.String("tee_time", survey.TeeTime),
+		slog.Int("enjoyment", survey.Enjoyment),
+	)
+
+	return []protocol.Content{
+		protocol.NewTextContent(fmt.Sprintf("Thanks! Recorded your feedback for %s on %s", survey.CourseName, survey.TeeTime)),
+	}, nil
+}
@@ -1,10 +1,13 @@
 package tools
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 
-	"github.com/jrzesz33/rez_agent/internal/mcp/protocol"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/policy"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
 )
 
 // ValidateInputAgainstSchema validates input arguments against a JSON schema
@@ -12,7 +15,7 @@ func ValidateInputAgainstSchema(args map[string]interface{}, schema protocol.Inp
 	// Check required fields
 	for _, required := range schema.Required {
 		if _, exists := args[required]; !exists {
-			return fmt.Errorf("required field missing: %s", required)
+			return apperrors.Newf(apperrors.ErrValidation, "required field missing: %s", required)
 		}
 	}
 
@@ -25,7 +28,7 @@ func ValidateInputAgainstSchema(args map[string]interface{}, schema protocol.Inp
 		}
 
 		if err := validateValue(key, value, prop); err != nil {
-			return err
+			return apperrors.Wrap(apperrors.ErrValidation, err)
 		}
 	}
 
@@ -98,14 +101,38 @@ func validateValue(fieldName string, value interface{}, prop protocol.Property)
 		}
 
 	case "object":
-		if _, ok := value.(map[string]interface{}); !ok {
+		object, ok := value.(map[string]interface{})
+		if !ok {
 			return fmt.Errorf("field %s: expected object", fieldName)
 		}
 
+		// Nested fields, when the schema describes them
+		for _, required := range prop.Required {
+			if _, exists := object[required]; !exists {
+				return fmt.Errorf("field %s: required field missing: %s", fieldName, required)
+			}
+		}
+		for key, fieldValue := range object {
+			if fieldProp, exists := prop.Properties[key]; exists {
+				if err := validateValue(fieldName+"."+key, fieldValue, fieldProp); err != nil {
+					return err
+				}
+			}
+		}
+
 	case "array":
-		if _, ok := value.([]interface{}); !ok {
+		items, ok := value.([]interface{})
+		if !ok {
 			return fmt.Errorf("field %s: expected array", fieldName)
 		}
+
+		if prop.Items != nil {
+			for i, item := range items {
+				if err := validateValue(fmt.Sprintf("%s[%d]", fieldName, i), item, *prop.Items); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
 	return nil
@@ -193,6 +220,49 @@ func GetIntArg(args map[string]interface{}, key string, defaultValue int) int {
 	return defaultValue
 }
 
+// GetFloatArg safely extracts a numeric argument
+func GetFloatArg(args map[string]interface{}, key string, defaultValue float64) float64 {
+	if val, exists := args[key]; exists {
+		switch v := val.(type) {
+		case float64:
+			return v
+		case int:
+			return float64(v)
+		}
+	}
+	return defaultValue
+}
+
+// GetPolicyArg extracts and validates an optional booking policy object argument
+func GetPolicyArg(args map[string]interface{}, key string) (*policy.Config, error) {
+	val, exists := args[key]
+	if !exists || val == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("field %s: %w", key, err))
+	}
+	var cfg policy.Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("field %s: invalid policy: %w", key, err))
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("field %s: %w", key, err))
+	}
+	return &cfg, nil
+}
+
+// GetCursorArg extracts an optional pagination cursor argument as the result offset it points to
+func GetCursorArg(args map[string]interface{}, key string) (int, error) {
+	offset, err := protocol.DecodeOffsetCursor(GetStringArg(args, key, ""))
+	if err != nil {
+		return 0, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("field %s: %w", key, err))
+	}
+	return offset, nil
+}
+
 // GetBoolArg safely extracts a boolean argument
 func GetBoolArg(args map[string]interface{}, key string, defaultValue bool) bool {
 	if val, exists := args[key]; exists {
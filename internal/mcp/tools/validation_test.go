@@ -3,7 +3,7 @@ package tools
 import (
 	"testing"
 
-	"github.com/jrzesz33/rez_agent/internal/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
 )
 
 func TestValidateInputAgainstSchema_RequiredFields(t *testing.T) {
@@ -588,6 +588,145 @@ func TestGetIntArg(t *testing.T) {
 	}
 }
 
+func TestGetFloatArg(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         map[string]interface{}
+		key          string
+		defaultValue float64
+		want         float64
+	}{
+		{
+			name: "existing float64 value",
+			args: map[string]interface{}{
+				"max_price": 42.5,
+			},
+			key:          "max_price",
+			defaultValue: 0,
+			want:         42.5,
+		},
+		{
+			name: "existing int value",
+			args: map[string]interface{}{
+				"max_price": 40,
+			},
+			key:          "max_price",
+			defaultValue: 0,
+			want:         40,
+		},
+		{
+			name:         "missing key",
+			args:         map[string]interface{}{},
+			key:          "max_price",
+			defaultValue: 10,
+			want:         10,
+		},
+		{
+			name: "wrong type",
+			args: map[string]interface{}{
+				"max_price": "42",
+			},
+			key:          "max_price",
+			defaultValue: 10,
+			want:         10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetFloatArg(tt.args, tt.key, tt.defaultValue)
+			if got != tt.want {
+				t.Errorf("GetFloatArg() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPolicyArg(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         map[string]interface{}
+		wantNil      bool
+		wantErr      bool
+		wantMaxPrice float64
+	}{
+		{
+			name:    "missing key",
+			args:    map[string]interface{}{},
+			wantNil: true,
+		},
+		{
+			name: "valid policy",
+			args: map[string]interface{}{
+				"policy": map[string]interface{}{
+					"max_price":      45.0,
+					"blackout_dates": []interface{}{"2030-07-04"},
+				},
+			},
+			wantMaxPrice: 45,
+		},
+		{
+			name: "invalid blackout date",
+			args: map[string]interface{}{
+				"policy": map[string]interface{}{
+					"blackout_dates": []interface{}{"July 4"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong type",
+			args: map[string]interface{}{
+				"policy": "cheap",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetPolicyArg(tt.args, "policy")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetPolicyArg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("GetPolicyArg() = %+v, wantNil %v", got, tt.wantNil)
+			}
+			if got != nil && got.MaxPrice != tt.wantMaxPrice {
+				t.Errorf("MaxPrice = %v, want %v", got.MaxPrice, tt.wantMaxPrice)
+			}
+		})
+	}
+}
+
+func TestGetCursorArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		want    int
+		wantErr bool
+	}{
+		{name: "missing key", args: map[string]interface{}{}, want: 0},
+		{name: "next page", args: map[string]interface{}{"cursor": protocol.EncodeOffsetCursor(10)}, want: 10},
+		{name: "made-up cursor", args: map[string]interface{}{"cursor": "page-2"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetCursorArg(tt.args, "cursor")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetCursorArg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetCursorArg() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetBoolArg(t *testing.T) {
 	tests := []struct {
 		name         string
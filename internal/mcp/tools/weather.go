@@ -8,14 +8,30 @@ import (
 	"strings"
 	"time"
 
-	"github.com/jrzesz33/rez_agent/internal/httpclient"
-	"github.com/jrzesz33/rez_agent/internal/mcp/protocol"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
 )
 
+// Forecast modes of the get_weather tool
+const (
+	weatherModeDaily  = "daily"
+	weatherModeHourly = "hourly"
+)
+
+// defaultWeatherAlertsURL lists the active NWS alerts for a point
+const defaultWeatherAlertsURL = "https://api.weather.gov/alerts/active"
+
+// weatherAlertsCacheTTL is short: an alert issued on the morning of a round must not be missed
+const weatherAlertsCacheTTL = 5 * time.Minute
+
 // WeatherTool implements the get_weather MCP tool
 type WeatherTool struct {
 	httpClient *httpclient.Client
 	logger     *slog.Logger
+	alertsURL  string
 }
 
 // NewWeatherTool creates a new weather tool
@@ -23,54 +39,190 @@ func NewWeatherTool(httpClient *httpclient.Client, logger *slog.Logger) *Weather
 	return &WeatherTool{
 		httpClient: httpClient,
 		logger:     logger,
+		alertsURL:  defaultWeatherAlertsURL,
+	}
+}
+
+// weatherOptionProperties are the forecast options shared by the weather tools
+func weatherOptionProperties() map[string]protocol.Property {
+	return map[string]protocol.Property{
+		"mode": {
+			Type:        "string",
+			Description: "daily for per-day summaries, hourly for hour-by-hour forecasts (default: daily)",
+			Enum:        []string{weatherModeDaily, weatherModeHourly},
+			Default:     weatherModeDaily,
+		},
+		"days": {
+			Type:        "integer",
+			Description: "Number of days to forecast in daily mode (default: 2)",
+			Minimum:     intPtr(1),
+			Maximum:     intPtr(7),
+			Default:     2,
+		},
+		"hours": {
+			Type:        "integer",
+			Description: "Number of hours to forecast in hourly mode, starting now (default: 12)",
+			Minimum:     intPtr(1),
+			Maximum:     intPtr(156),
+			Default:     12,
+		},
+		"include_alerts": {
+			Type:        "boolean",
+			Description: "Include active NWS alerts for the location (default: true)",
+			Default:     true,
+		},
+	}
+}
+
+// weatherQuery is a forecast request whose location has been resolved
+type weatherQuery struct {
+	// course or place names the location in the report
+	course string
+	place  string
+	// location is the weather.gov daily forecast URL
+	location string
+	// point is where alerts are checked; nil skips them
+	point *geoPoint
+
+	mode          string
+	days          int
+	hours         int
+	includeAlerts bool
+}
+
+// newWeatherQuery reads the forecast options of a tool call
+func newWeatherQuery(args map[string]interface{}) weatherQuery {
+	return weatherQuery{
+		mode:          GetStringArg(args, "mode", weatherModeDaily),
+		days:          GetIntArg(args, "days", 2),
+		hours:         GetIntArg(args, "hours", 12),
+		includeAlerts: GetBoolArg(args, "include_alerts", true),
 	}
 }
 
 // GetDefinition returns the tool's MCP definition
 func (t *WeatherTool) GetDefinition() protocol.Tool {
+	properties := weatherOptionProperties()
+	properties["course_name"] = protocol.Property{
+		Type:        "string",
+		Description: "Name of the golf course (e.g., 'Birdsfoot Golf Course' or 'Totteridge'); required for alerts",
+	}
+	properties["location"] = protocol.Property{
+		Type:        "string",
+		Description: "URL of the the Course from weather.gov (e.g. https://api.weather.gov/gridpoints/TOP/31,80/forecast); defaults to the course's forecast",
+	}
+
 	return protocol.Tool{
 		Name:        "get_weather",
-		Description: "Get current weather forecast for a location using weather.gov API (US locations only)",
+		Description: "Get the weather forecast for a golf course or weather.gov forecast URL (US locations only): per-day summaries, or hourly forecasts for tee-off time, plus active NWS alerts for the course. Returns a readable forecast followed by the same data as JSON.",
 		InputSchema: protocol.InputSchema{
-			Type: "object",
-			Properties: map[string]protocol.Property{
-				"location": {
-					Type:        "string",
-					Description: "URL of the the Course from weather.gov (e.g. https://api.weather.gov/gridpoints/TOP/31,80/forecast)",
-				},
-				"days": {
-					Type:        "integer",
-					Description: "Number of days to forecast (default: 2)",
-					Minimum:     intPtr(1),
-					Maximum:     intPtr(7),
-					Default:     2,
-				},
-			},
-			Required: []string{"location"},
+			Type:       "object",
+			Properties: properties,
 		},
 	}
 }
 
 // ValidateInput validates the tool's input arguments
 func (t *WeatherTool) ValidateInput(args map[string]interface{}) error {
-	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+	if err := ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema); err != nil {
+		return err
+	}
+	if GetStringArg(args, "course_name", "") == "" && GetStringArg(args, "location", "") == "" {
+		return apperrors.Newf(apperrors.ErrValidation, "course_name or location is required")
+	}
+	return nil
 }
 
 // Execute runs the tool with the given arguments
 func (t *WeatherTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
-	location := GetStringArg(args, "location", "")
-	numDays := GetIntArg(args, "days", 2)
+	query := newWeatherQuery(args)
+	query.location = GetStringArg(args, "location", "")
+
+	if courseName := GetStringArg(args, "course_name", ""); courseName != "" {
+		course, err := courses.GetCourseByName(courseName)
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("failed to find course: %w", err))
+		}
+		if query.location == "" {
+			query.location, err = course.GetActionURL("get-weather")
+			if err != nil {
+				return nil, fmt.Errorf("failed to get weather URL for course %s: %w", course.Name, err)
+			}
+		}
+		query.course = course.Name
+		query.point = &geoPoint{Latitude: course.Latitude, Longitude: course.Longitude}
+	}
+	if query.location == "" {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "location cannot be empty")
+	}
+
+	return t.forecast(ctx, query)
+}
 
-	if location == "" {
-		return nil, fmt.Errorf("location cannot be empty")
+// forecast fetches and renders the forecast for a resolved query
+func (t *WeatherTool) forecast(ctx context.Context, query weatherQuery) ([]protocol.Content, error) {
+	location := query.location
+	if query.mode == weatherModeHourly && !strings.HasSuffix(location, "/hourly") {
+		location = strings.TrimSuffix(location, "/") + "/hourly"
 	}
 
-	t.logger.Info("fetching weather forecast",
+	t.logger.InfoContext(ctx, "fetching weather forecast",
 		slog.String("location", location),
-		slog.Int("days", numDays),
+		slog.String("mode", query.mode),
+		slog.Int("days", query.days),
+		slog.Int("hours", query.hours),
 	)
 
-	// Fetch weather data
+	weatherData, err := t.fetchForecast(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	report := weatherReport{Course: query.course, Place: query.place, Mode: query.mode, Updated: weatherData.Properties.Updated}
+	if query.mode == weatherModeHourly {
+		report.Hours = hourlyForecasts(weatherData.Properties.Periods, query.hours)
+	} else {
+		report.Days = dailySummaries(weatherData.Properties.Periods, query.days)
+	}
+
+	// Alerts need a point; a failed lookup is reported rather than failing the forecast
+	alertsNote := ""
+	if query.includeAlerts && query.point != nil {
+		report.Alerts, err = t.fetchAlerts(ctx, query.point.Latitude, query.point.Longitude)
+		if err != nil {
+			t.logger.WarnContext(ctx, "failed to fetch weather alerts", slog.String("error", err.Error()))
+			alertsNote = "⚠️ Active weather alerts are unavailable right now.\n\n"
+		}
+	}
+
+	var forecast string
+	if query.mode == weatherModeHourly {
+		forecast = formatHourlyForecast(report.Hours)
+	} else {
+		forecast = formatDailySummaries(report.Days) + "\n" + t.formatWeatherForecast(*weatherData, query.days)
+	}
+	forecast = alertsNote + formatWeatherAlerts(report.Alerts) + forecast
+	if query.place != "" {
+		forecast = fmt.Sprintf("📍 %s\n\n%s", query.place, forecast)
+	}
+
+	t.logger.InfoContext(ctx, "weather forecast retrieved successfully",
+		slog.Int("periods", len(weatherData.Properties.Periods)),
+		slog.Int("alerts", len(report.Alerts)),
+	)
+
+	structured, err := protocol.NewJSONContent(report)
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.Content{
+		protocol.NewTextContent(forecast),
+		structured,
+	}, nil
+}
+
+// fetchForecast reads a weather.gov forecast, daily or hourly
+func (t *WeatherTool) fetchForecast(ctx context.Context, location string) (*WeatherAPIResponse, error) {
 	resp, err := t.httpClient.Do(ctx, httpclient.RequestConfig{
 		Method: "GET",
 		URL:    location,
@@ -78,28 +230,50 @@ func (t *WeatherTool) Execute(ctx context.Context, args map[string]interface{})
 			"Accept":     "application/json",
 			"User-Agent": "rez-agent MCP weather tool (contact@example.com)",
 		},
-		Timeout: 30 * time.Second,
+		Timeout:  30 * time.Second,
+		CacheTTL: models.WeatherCacheTTL,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch weather data: %w", err)
 	}
 
-	// Parse weather response
 	var weatherData WeatherAPIResponse
 	if err := json.Unmarshal([]byte(resp.Body), &weatherData); err != nil {
 		return nil, fmt.Errorf("failed to parse weather response: %w", err)
 	}
+	return &weatherData, nil
+}
 
-	// Format weather forecast
-	forecast := t.formatWeatherForecast(weatherData, numDays)
+// fetchAlerts lists the NWS alerts in effect at a point
+func (t *WeatherTool) fetchAlerts(ctx context.Context, latitude, longitude float64) ([]WeatherAlert, error) {
+	resp, err := t.httpClient.Do(ctx, httpclient.RequestConfig{
+		Method: "GET",
+		URL:    fmt.Sprintf("%s?point=%.4f,%.4f", t.alertsURL, latitude, longitude),
+		Headers: map[string]string{
+			"Accept":     "application/geo+json",
+			"User-Agent": "rez-agent MCP weather tool (contact@example.com)",
+		},
+		Timeout:  30 * time.Second,
+		CacheTTL: weatherAlertsCacheTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch weather alerts: %w", err)
+	}
 
-	t.logger.Info("weather forecast retrieved successfully",
-		slog.Int("periods", len(weatherData.Properties.Periods)),
-	)
+	var alertsData struct {
+		Features []struct {
+			Properties WeatherAlert `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &alertsData); err != nil {
+		return nil, fmt.Errorf("failed to parse weather alerts: %w", err)
+	}
 
-	return []protocol.Content{
-		protocol.NewTextContent(forecast),
-	}, nil
+	alerts := make([]WeatherAlert, 0, len(alertsData.Features))
+	for _, feature := range alertsData.Features {
+		alerts = append(alerts, feature.Properties)
+	}
+	return alerts, nil
 }
 
 // WeatherAPIResponse represents the weather.gov API response structure
@@ -124,6 +298,166 @@ type WeatherPeriod struct {
 	WindDirection    string `json:"windDirection"`
 	ShortForecast    string `json:"shortForecast"`
 	DetailedForecast string `json:"detailedForecast"`
+
+	ProbabilityOfPrecipitation struct {
+		Value *int `json:"value"`
+	} `json:"probabilityOfPrecipitation"`
+}
+
+// WeatherAlert is an active NWS alert
+type WeatherAlert struct {
+	Event       string `json:"event"`
+	Severity    string `json:"severity"`
+	Urgency     string `json:"urgency"`
+	Headline    string `json:"headline"`
+	Onset       string `json:"onset,omitempty"`
+	Ends        string `json:"ends,omitempty"`
+	Instruction string `json:"instruction,omitempty"`
+}
+
+// weatherReport is the JSON content block of a get_weather result
+type weatherReport struct {
+	Course  string          `json:"course,omitempty"`
+	Place   string          `json:"place,omitempty"`
+	Mode    string          `json:"mode"`
+	Updated string          `json:"updated,omitempty"`
+	Days    []dailySummary  `json:"days,omitempty"`
+	Hours   []hourlyWeather `json:"hours,omitempty"`
+	// Alerts is null when they were not checked, and empty when none are in effect
+	Alerts []WeatherAlert `json:"alerts"`
+}
+
+// dailySummary folds a day's daytime and overnight periods into one entry
+type dailySummary struct {
+	Date            string `json:"date"`
+	High            *int   `json:"high,omitempty"`
+	Low             *int   `json:"low,omitempty"`
+	TemperatureUnit string `json:"temperature_unit"`
+	PrecipChance    int    `json:"precip_chance"`
+	Forecast        string `json:"forecast"`
+	Wind            string `json:"wind,omitempty"`
+}
+
+// hourlyWeather is one hour of an hourly forecast
+type hourlyWeather struct {
+	StartTime       string `json:"start_time"`
+	Temperature     int    `json:"temperature"`
+	TemperatureUnit string `json:"temperature_unit"`
+	PrecipChance    int    `json:"precip_chance"`
+	Wind            string `json:"wind"`
+	Forecast        string `json:"forecast"`
+}
+
+// dailySummaries groups forecast periods by the local date they start on, keeping numDays days.
+// An overnight period belongs to the date it starts on, so it supplies that day's low.
+func dailySummaries(periods []WeatherPeriod, numDays int) []dailySummary {
+	var days []dailySummary
+	for _, period := range periods {
+		start, err := time.Parse(time.RFC3339, period.StartTime)
+		if err != nil {
+			continue
+		}
+		date := start.Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			if len(days) == numDays {
+				break
+			}
+			days = append(days, dailySummary{Date: date, TemperatureUnit: period.TemperatureUnit})
+		}
+
+		day := &days[len(days)-1]
+		temperature := period.Temperature
+		if period.IsDaytime {
+			day.High = &temperature
+			day.Forecast = period.ShortForecast
+			day.Wind = strings.TrimSpace(period.WindSpeed + " " + period.WindDirection)
+		} else {
+			day.Low = &temperature
+			if day.Forecast == "" {
+				day.Forecast = period.ShortForecast
+			}
+		}
+		day.PrecipChance = max(day.PrecipChance, precipChance(period))
+	}
+	return days
+}
+
+// hourlyForecasts keeps the first numHours periods of an hourly forecast
+func hourlyForecasts(periods []WeatherPeriod, numHours int) []hourlyWeather {
+	hours := make([]hourlyWeather, 0, min(numHours, len(periods)))
+	for _, period := range periods[:min(numHours, len(periods))] {
+		hours = append(hours, hourlyWeather{
+			StartTime:       period.StartTime,
+			Temperature:     period.Temperature,
+			TemperatureUnit: period.TemperatureUnit,
+			PrecipChance:    precipChance(period),
+			Wind:            strings.TrimSpace(period.WindSpeed + " " + period.WindDirection),
+			Forecast:        period.ShortForecast,
+		})
+	}
+	return hours
+}
+
+// precipChance treats a missing probability as zero, as weather.gov does for dry periods
+func precipChance(period WeatherPeriod) int {
+	if period.ProbabilityOfPrecipitation.Value == nil {
+		return 0
+	}
+	return *period.ProbabilityOfPrecipitation.Value
+}
+
+// formatWeatherAlerts lists active alerts ahead of the forecast
+func formatWeatherAlerts(alerts []WeatherAlert) string {
+	if len(alerts) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🚨 Active Weather Alerts\n\n")
+	for _, alert := range alerts {
+		sb.WriteString(fmt.Sprintf("⚠️ **%s** (%s)\n", alert.Event, alert.Severity))
+		if alert.Headline != "" {
+			sb.WriteString(alert.Headline + "\n")
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// formatDailySummaries renders one line per day
+func formatDailySummaries(days []dailySummary) string {
+	var sb strings.Builder
+	sb.WriteString("📆 Daily Summary\n\n")
+	for _, day := range days {
+		label := day.Date
+		if date, err := time.Parse("2006-01-02", day.Date); err == nil {
+			label = date.Format("Mon Jan 2")
+		}
+
+		var temps []string
+		if day.High != nil {
+			temps = append(temps, fmt.Sprintf("high %d°%s", *day.High, day.TemperatureUnit))
+		}
+		if day.Low != nil {
+			temps = append(temps, fmt.Sprintf("low %d°%s", *day.Low, day.TemperatureUnit))
+		}
+		sb.WriteString(fmt.Sprintf("- %s: %s, %s, precipitation up to %d%%\n", label, day.Forecast, strings.Join(temps, " / "), day.PrecipChance))
+	}
+	return sb.String()
+}
+
+// formatHourlyForecast renders one line per hour, in the forecast's local time
+func formatHourlyForecast(hours []hourlyWeather) string {
+	var sb strings.Builder
+	sb.WriteString("🕐 Hourly Forecast\n\n")
+	for _, hour := range hours {
+		label := hour.StartTime
+		if start, err := time.Parse(time.RFC3339, hour.StartTime); err == nil {
+			label = start.Format("Mon 3 PM")
+		}
+		sb.WriteString(fmt.Sprintf("- %s: %d°%s, %s, 🌧️ %d%%, 💨 %s\n", label, hour.Temperature, hour.TemperatureUnit, hour.Forecast, hour.PrecipChance, hour.Wind))
+	}
+	return sb.String()
 }
 
 // formatWeatherForecast formats weather data into a readable forecast
@@ -167,6 +501,11 @@ func (t *WeatherTool) formatWeatherForecast(data WeatherAPIResponse, numDays int
 		// Wind
 		sb.WriteString(fmt.Sprintf("💨 Wind: %s %s\n", period.WindSpeed, period.WindDirection))
 
+		// Precipitation chance
+		if period.ProbabilityOfPrecipitation.Value != nil {
+			sb.WriteString(fmt.Sprintf("🌧️ Chance of precipitation: %d%%\n", *period.ProbabilityOfPrecipitation.Value))
+		}
+
 		// Detailed forecast
 		sb.WriteString(fmt.Sprintf("☁️ %s\n", period.DetailedForecast))
 
@@ -0,0 +1,347 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// courseTimezone is the time zone the report's dates are in, that of the configured courses
+const courseTimezone = courses.DefaultTimezone
+
+// RecordWeatherDecisionTool implements the record_weather_decision MCP tool
+type RecordWeatherDecisionTool struct {
+	repo   repository.WeatherDecisionRepository
+	logger *slog.Logger
+}
+
+// NewRecordWeatherDecisionTool creates a new weather decision recording tool
+func NewRecordWeatherDecisionTool(repo repository.WeatherDecisionRepository, logger *slog.Logger) *RecordWeatherDecisionTool {
+	return &RecordWeatherDecisionTool{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// GetDefinition returns the tool's MCP definition
+func (t *RecordWeatherDecisionTool) GetDefinition() protocol.Tool {
+	return protocol.Tool{
+		Name:        "record_weather_decision",
+		Description: "Record whether a date was booked or skipped and the weather forecast that decision was based on",
+		InputSchema: protocol.InputSchema{
+			Type: "object",
+			Properties: map[string]protocol.Property{
+				"course_name": {
+					Type:        "string",
+					Description: "Name of the golf course (e.g., 'Birdsfoot Golf Course' or 'Totteridge')",
+				},
+				"date": {
+					Type:        "string",
+					Description: "Tee time date in YYYY-MM-DD format",
+				},
+				"decision": {
+					Type:        "string",
+					Description: "Whether the date was booked or skipped",
+					Enum:        []string{"booked", "skipped"},
+				},
+				"forecast_summary": {
+					Type:        "string",
+					Description: "Short forecast used for the decision (e.g., 'Chance Showers And Thunderstorms')",
+				},
+				"precip_chance": {
+					Type:        "integer",
+					Description: "Forecast probability of precipitation in percent",
+					Minimum:     intPtr(0),
+					Maximum:     intPtr(100),
+					Default:     0,
+				},
+				"schedule_id": {
+					Type:        "string",
+					Description: "Schedule that triggered the decision (optional)",
+				},
+			},
+			Required: []string{"course_name", "date", "decision", "forecast_summary"},
+		},
+	}
+}
+
+// ValidateInput validates the tool's input arguments
+func (t *RecordWeatherDecisionTool) ValidateInput(args map[string]interface{}) error {
+	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+}
+
+// Execute runs the tool with the given arguments
+func (t *RecordWeatherDecisionTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+	decision, err := models.NewWeatherDecision(
+		GetStringArg(args, "course_name", ""),
+		GetStringArg(args, "date", ""),
+		models.WeatherDecisionType(GetStringArg(args, "decision", "")),
+		GetStringArg(args, "forecast_summary", ""),
+		GetIntArg(args, "precip_chance", 0),
+		GetStringArg(args, "schedule_id", ""),
+	)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid weather decision: %w", err))
+	}
+
+	if err := t.repo.SaveDecision(ctx, decision); err != nil {
+		return nil, fmt.Errorf("failed to record weather decision: %w", err)
+	}
+
+	t.logger.InfoContext(ctx, "weather decision recorded",
+		slog.String("course_name", decision.CourseName),
+		slog.String("date", decision.Date),
+		slog.String("decision", decision.Decision.String()),
+	)
+
+	return []protocol.Content{
+		protocol.NewTextContent(fmt.Sprintf("Recorded %s decision for %s on %s", decision.Decision, decision.CourseName, decision.Date)),
+	}, nil
+}
+
+// WeatherFeedbackReportTool implements the weather_feedback_report MCP tool
+type WeatherFeedbackReportTool struct {
+	repo       repository.WeatherDecisionRepository
+	httpClient *httpclient.Client
+	logger     *slog.Logger
+}
+
+// NewWeatherFeedbackReportTool creates a new weather feedback report tool
+func NewWeatherFeedbackReportTool(repo repository.WeatherDecisionRepository, httpClient *httpclient.Client, logger *slog.Logger) *WeatherFeedbackReportTool {
+	return &WeatherFeedbackReportTool{
+		repo:       repo,
+		httpClient: httpClient,
+		logger:     logger,
+	}
+}
+
+// GetDefinition returns the tool's MCP definition
+func (t *WeatherFeedbackReportTool) GetDefinition() protocol.Tool {
+	return protocol.Tool{
+		Name:        "weather_feedback_report",
+		Description: "Compare recorded forecasts with observed weather, report forecast accuracy, skipped days that turned out dry, and a suggested precipitation threshold",
+		InputSchema: protocol.InputSchema{
+			Type: "object",
+			Properties: map[string]protocol.Property{
+				"days": {
+					Type:        "integer",
+					Description: "Number of days of history to include (default: 30)",
+					Minimum:     intPtr(1),
+					Maximum:     intPtr(180),
+					Default:     30,
+				},
+			},
+		},
+	}
+}
+
+// ValidateInput validates the tool's input arguments
+func (t *WeatherFeedbackReportTool) ValidateInput(args map[string]interface{}) error {
+	return ValidateInputAgainstSchema(args, t.GetDefinition().InputSchema)
+}
+
+// Execute runs the tool with the given arguments
+func (t *WeatherFeedbackReportTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+	days := GetIntArg(args, "days", 30)
+
+	loc, err := time.LoadLocation(courseTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load course timezone: %w", err)
+	}
+	today := time.Now().In(loc).Format("2006-01-02")
+	since := time.Now().In(loc).AddDate(0, 0, -days).Format("2006-01-02")
+
+	decisions, err := t.repo.ListDecisions(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list weather decisions: %w", err)
+	}
+
+	// Backfill observations for past dates
+	for _, d := range decisions {
+		if d.IsObserved() || d.Date >= today {
+			continue
+		}
+		summary, precip, err := t.fetchObservedWeather(ctx, d.CourseName, d.Date, loc)
+		if err != nil {
+			t.logger.WarnContext(ctx, "failed to fetch observed weather",
+				slog.String("course_name", d.CourseName),
+				slog.String("date", d.Date),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		d.RecordObservation(summary, precip)
+		if err := t.repo.SaveDecision(ctx, d); err != nil {
+			t.logger.WarnContext(ctx, "failed to save observed weather",
+				slog.String("id", d.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	report := models.ComputeWeatherAccuracy(decisions, models.DefaultMaxPrecipChance)
+
+	return []protocol.Content{
+		protocol.NewTextContent(formatWeatherFeedbackReport(report, days)),
+	}, nil
+}
+
+// observationsResponse is the weather.gov station observations response
+type observationsResponse struct {
+	Features []struct {
+		Properties observation `json:"properties"`
+	} `json:"features"`
+}
+
+// observation is one station observation: a routine hourly report or a special one between them
+type observation struct {
+	Timestamp             string `json:"timestamp"`
+	TextDescription       string `json:"textDescription"`
+	PrecipitationLastHour struct {
+		Value *float64 `json:"value"`
+	} `json:"precipitationLastHour"`
+}
+
+// fetchObservedWeather returns the most common observed condition and total precipitation
+// (inches) between 6 AM and 8 PM local time on the given date
+func (t *WeatherFeedbackReportTool) fetchObservedWeather(ctx context.Context, courseName, date string, loc *time.Location) (string, float64, error) {
+	course, err := courses.GetCourseByName(courseName)
+	if err != nil {
+		return "", 0, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("course not found: %w", err))
+	}
+	observationsURL, err := course.GetActionURL("get-observations")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get observations URL for course %s: %w", courseName, err)
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return "", 0, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid date %s: %w", date, err))
+	}
+	start := day.Add(6 * time.Hour)
+	end := day.Add(20 * time.Hour)
+
+	query := url.Values{}
+	query.Set("start", start.Format(time.RFC3339))
+	query.Set("end", end.Format(time.RFC3339))
+
+	resp, err := t.httpClient.Do(ctx, httpclient.RequestConfig{
+		Method: "GET",
+		URL:    observationsURL + "?" + query.Encode(),
+		Headers: map[string]string{
+			"Accept":     "application/geo+json",
+			"User-Agent": "rez-agent MCP weather tool (contact@example.com)",
+		},
+		Timeout: 30 * time.Second,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to fetch observations: %w", err)
+	}
+
+	var data observationsResponse
+	if err := json.Unmarshal([]byte(resp.Body), &data); err != nil {
+		return "", 0, fmt.Errorf("failed to parse observations response: %w", err)
+	}
+	if len(data.Features) == 0 {
+		return "", 0, fmt.Errorf("no observations for %s", date)
+	}
+
+	observations := make([]observation, 0, len(data.Features))
+	counts := make(map[string]int)
+	for _, f := range data.Features {
+		observations = append(observations, f.Properties)
+		if desc := strings.TrimSpace(f.Properties.TextDescription); desc != "" {
+			counts[desc]++
+		}
+	}
+	precipMM := observedPrecipitationMM(observations)
+
+	summary, best := "", 0
+	for desc, n := range counts {
+		if n > best || (n == best && desc < summary) {
+			summary, best = desc, n
+		}
+	}
+
+	return summary, precipMM / 25.4, nil
+}
+
+// observedPrecipitationMM totals the precipitation (mm) the observations measured. Each one
+// reports the hour before it, and a special observation's hour overlaps the routine ones around
+// it, so walking back from the latest, an observation only counts when its hour ends by the
+// start of the last hour counted.
+func observedPrecipitationMM(observations []observation) float64 {
+	type measured struct {
+		at time.Time
+		mm float64
+	}
+	var readings []measured
+	for _, o := range observations {
+		if o.PrecipitationLastHour.Value == nil {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, o.Timestamp)
+		if err != nil {
+			continue
+		}
+		readings = append(readings, measured{at: at, mm: *o.PrecipitationLastHour.Value})
+	}
+	sort.Slice(readings, func(i, j int) bool { return readings[i].at.After(readings[j].at) })
+
+	var total float64
+	var windowStart time.Time
+	for _, r := range readings {
+		if !windowStart.IsZero() && r.at.After(windowStart) {
+			continue
+		}
+		total += r.mm
+		windowStart = r.at.Add(-time.Hour)
+	}
+	return total
+}
+
+// formatWeatherFeedbackReport formats the accuracy report as text
+func formatWeatherFeedbackReport(report *models.WeatherAccuracyReport, days int) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("🌦️ Weather Decision Feedback (last %d days)\n\n", days))
+	sb.WriteString(fmt.Sprintf("Decisions: %d (observed: %d)\n", report.Total, report.Observed))
+
+	if report.Observed == 0 {
+		sb.WriteString("No observed weather yet - check back after the recorded dates have passed.\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("Forecast accuracy: %.0f%% (%d/%d)\n", report.Accuracy*100, report.ForecastHits, report.Observed))
+	sb.WriteString(fmt.Sprintf("Precipitation threshold: %d%% (suggested: %d%%)\n", report.MaxPrecipChance, report.SuggestedMaxPrecipChance))
+
+	if len(report.MissedPlayDays) > 0 {
+		sb.WriteString("\n☀️ Skipped days that turned out dry:\n")
+		for _, d := range report.MissedPlayDays {
+			sb.WriteString(fmt.Sprintf("- %s at %s: forecast %q (%d%%), observed %q\n",
+				d.Date, d.CourseName, d.ForecastSummary, d.ForecastPrecipChance, d.ObservedSummary))
+		}
+	}
+
+	if len(report.WetBookings) > 0 {
+		sb.WriteString("\n🌧️ Booked days that turned out wet:\n")
+		for _, d := range report.WetBookings {
+			sb.WriteString(fmt.Sprintf("- %s at %s: forecast %q (%d%%), observed %q (%.2f in)\n",
+				d.Date, d.CourseName, d.ForecastSummary, d.ForecastPrecipChance, d.ObservedSummary, *d.ObservedPrecipInches))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestObservedPrecipitationMM(t *testing.T) {
+	// Newest first, as weather.gov lists them; the 08:20 and 09:10 specials overlap the
+	// routine hourly reports around them
+	const observations = `[
+{"timestamp":"2030-06-01T10:53:00+00:00","precipitationLastHour":{"value":1.0}},
+{"timestamp":"2030-06-01T10:15:00+00:00","precipitationLastHour":{"value":0.8}},
+{"timestamp":"2030-06-01T09:53:00+00:00","precipitationLastHour":{"value":2.0}},
+{"timestamp":"2030-06-01T09:10:00+00:00","precipitationLastHour":{"value":1.5}},
+{"timestamp":"2030-06-01T08:53:00+00:00","precipitationLastHour":{"value":3.0}},
+{"timestamp":"2030-06-01T08:20:00+00:00","precipitationLastHour":{"value":2.5}},
+{"timestamp":"2030-06-01T07:53:00+00:00","precipitationLastHour":{"value":null}},
+{"timestamp":"2030-06-01T06:53:00+00:00","precipitationLastHour":{"value":0.5}}]`
+
+	var parsed []observation
+	if err := json.Unmarshal([]byte(observations), &parsed); err != nil {
+		t.Fatalf("failed to parse observations: %v", err)
+	}
+
+	// Only the routine hours count: 1.0 + 2.0 + 3.0 + 0.5
+	if got := observedPrecipitationMM(parsed); math.Abs(got-6.5) > 1e-9 {
+		t.Errorf("observedPrecipitationMM() = %v, want 6.5", got)
+	}
+	if got := observedPrecipitationMM(nil); got != 0 {
+		t.Errorf("observedPrecipitationMM(nil) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+const testDailyForecast = `{"properties":{"updated":"2026-06-05T10:00:00-04:00","periods":[
+{"number":1,"name":"Today","startTime":"2026-06-05T10:00:00-04:00","isDaytime":true,"temperature":78,"temperatureUnit":"F","windSpeed":"5 mph","windDirection":"W","shortForecast":"Sunny","probabilityOfPrecipitation":{"value":null}},
+{"number":2,"name":"Tonight","startTime":"2026-06-05T18:00:00-04:00","isDaytime":false,"temperature":58,"temperatureUnit":"F","shortForecast":"Chance Showers","probabilityOfPrecipitation":{"value":30}},
+{"number":3,"name":"Saturday","startTime":"2026-06-06T06:00:00-04:00","isDaytime":true,"temperature":71,"temperatureUnit":"F","windSpeed":"10 mph","windDirection":"NW","shortForecast":"Showers","probabilityOfPrecipitation":{"value":70}},
+{"number":4,"name":"Saturday Night","startTime":"2026-06-06T18:00:00-04:00","isDaytime":false,"temperature":55,"temperatureUnit":"F","shortForecast":"Cloudy","probabilityOfPrecipitation":{"value":20}},
+{"number":5,"name":"Sunday","startTime":"2026-06-07T06:00:00-04:00","isDaytime":true,"temperature":75,"temperatureUnit":"F","shortForecast":"Sunny","probabilityOfPrecipitation":{"value":0}}]}}`
+
+const testHourlyForecast = `{"properties":{"periods":[
+{"number":1,"startTime":"2026-06-05T10:00:00-04:00","isDaytime":true,"temperature":70,"temperatureUnit":"F","windSpeed":"5 mph","windDirection":"W","shortForecast":"Sunny","probabilityOfPrecipitation":{"value":5}},
+{"number":2,"startTime":"2026-06-05T11:00:00-04:00","isDaytime":true,"temperature":72,"temperatureUnit":"F","windSpeed":"5 mph","windDirection":"W","shortForecast":"Sunny","probabilityOfPrecipitation":{"value":10}},
+{"number":3,"startTime":"2026-06-05T12:00:00-04:00","isDaytime":true,"temperature":74,"temperatureUnit":"F","windSpeed":"10 mph","windDirection":"W","shortForecast":"Showers","probabilityOfPrecipitation":{"value":60}}]}}`
+
+const testAlerts = `{"features":[{"properties":{"event":"Heat Advisory","severity":"Moderate","urgency":"Expected","headline":"Heat Advisory until 8 PM"}}]}`
+
+func newWeatherTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/forecast":
+			_, _ = io.WriteString(w, testDailyForecast)
+		case "/forecast/hourly":
+			_, _ = io.WriteString(w, testHourlyForecast)
+		case "/alerts/active":
+			if r.URL.Query().Get("point") == "" {
+				t.Error("alerts request has no point")
+			}
+			_, _ = io.WriteString(w, testAlerts)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestWeatherTool(server *httptest.Server) *WeatherTool {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	httpClient := httpclient.NewClient(logger)
+	httpClient.SetCircuitBreaker(nil)
+	tool := NewWeatherTool(httpClient, logger)
+	tool.alertsURL = server.URL + "/alerts/active"
+	return tool
+}
+
+// decodeReport returns the JSON block that follows the text forecast
+func decodeReport(t *testing.T, content []protocol.Content) weatherReport {
+	t.Helper()
+	if len(content) != 2 {
+		t.Fatalf("content count = %d, want text and JSON", len(content))
+	}
+	if content[1].MimeType != protocol.JSONMimeType {
+		t.Fatalf("second block MimeType = %q, want %q", content[1].MimeType, protocol.JSONMimeType)
+	}
+	var report weatherReport
+	if err := json.Unmarshal([]byte(content[1].Text), &report); err != nil {
+		t.Fatalf("failed to decode JSON block: %v", err)
+	}
+	return report
+}
+
+func TestWeatherTool_DailySummaries(t *testing.T) {
+	server := newWeatherTestServer(t)
+	tool := newTestWeatherTool(server)
+
+	content, err := tool.Execute(context.Background(), map[string]interface{}{
+		"location": server.URL + "/forecast",
+		"days":     float64(2),
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	report := decodeReport(t, content)
+	if len(report.Days) != 2 {
+		t.Fatalf("days = %d, want 2", len(report.Days))
+	}
+	saturday := report.Days[1]
+	if saturday.Date != "2026-06-06" || saturday.High == nil || *saturday.High != 71 || saturday.Low == nil || *saturday.Low != 55 {
+		t.Errorf("Saturday summary = %+v", saturday)
+	}
+	if saturday.PrecipChance != 70 {
+		t.Errorf("Saturday precip chance = %d, want the day's highest, 70", saturday.PrecipChance)
+	}
+	// Without a course there is no point to check alerts for
+	if report.Alerts != nil {
+		t.Errorf("alerts = %v, want unchecked", report.Alerts)
+	}
+	if !strings.Contains(content[0].Text, "Daily Summary") {
+		t.Errorf("text forecast is missing the daily summary:\n%s", content[0].Text)
+	}
+}
+
+func TestWeatherTool_HourlyWithAlerts(t *testing.T) {
+	server := newWeatherTestServer(t)
+	tool := newTestWeatherTool(server)
+
+	content, err := tool.Execute(context.Background(), map[string]interface{}{
+		"course_name": "Totteridge",
+		"location":    server.URL + "/forecast",
+		"mode":        "hourly",
+		"hours":       float64(2),
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	report := decodeReport(t, content)
+	if report.Course != "Totteridge" || report.Mode != "hourly" {
+		t.Errorf("report course/mode = %q/%q", report.Course, report.Mode)
+	}
+	if len(report.Hours) != 2 || report.Hours[1].PrecipChance != 10 {
+		t.Errorf("hours = %+v, want the first 2 hours", report.Hours)
+	}
+	if len(report.Alerts) != 1 || report.Alerts[0].Event != "Heat Advisory" {
+		t.Errorf("alerts = %+v, want the heat advisory", report.Alerts)
+	}
+	if !strings.Contains(content[0].Text, "Heat Advisory") {
+		t.Errorf("text forecast is missing the alert:\n%s", content[0].Text)
+	}
+}
+
+func TestWeatherTool_ValidateInput(t *testing.T) {
+	tool := NewWeatherTool(nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := tool.ValidateInput(map[string]interface{}{}); err == nil {
+		t.Error("expected an error without course_name or location")
+	}
+	if err := tool.ValidateInput(map[string]interface{}{"course_name": "Totteridge", "mode": "weekly"}); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+	if err := tool.ValidateInput(map[string]interface{}{"course_name": "Totteridge", "mode": "hourly"}); err != nil {
+		t.Errorf("ValidateInput() error = %v", err)
+	}
+}
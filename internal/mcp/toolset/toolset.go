@@ -0,0 +1,138 @@
+// Package toolset builds the rez_agent MCP server with every tool registered, so the Lambda and
+// the local stdio server offer the same tools
+package toolset
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/localrun"
+	"github.com/jrzesz33/rez_agent/internal/mcp/tools"
+	"github.com/jrzesz33/rez_agent/internal/notification"
+	"github.com/jrzesz33/rez_agent/internal/oauth"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/server"
+	"github.com/jrzesz33/rez_agent/pkg/config"
+)
+
+const (
+	// maxToolInputBytes bounds a tools/call's parameters; real tool inputs are a few hundred bytes
+	maxToolInputBytes = 32 * 1024
+	// toolCallsPerMinute caps each tool's calls per server instance, stopping a runaway agent loop
+	toolCallsPerMinute = 30
+)
+
+// NewServer creates the MCP server with all tools registered, their timeouts, and the input,
+// rate, and result size limits. Secret reads and bookings are recorded in the audit log under
+// auditSource.
+func NewServer(cfg *config.Config, awsCfg aws.Config, auditSource string, logger *slog.Logger) (*server.MCPServer, error) {
+	serverName := os.Getenv("MCP_SERVER_NAME")
+	if serverName == "" {
+		serverName = "rez-agent-mcp"
+	}
+
+	serverVersion := os.Getenv("MCP_SERVER_VERSION")
+	if serverVersion == "" {
+		serverVersion = "1.0.0"
+	}
+
+	mcpServer := server.NewMCPServer(serverName, serverVersion, logger)
+	mcpServer.SetInstructions("This is the rez_agent MCP server. It provides tools for push notifications, weather information, and golf course operations.")
+
+	// Initialize dependencies
+	httpClient := httpclient.NewClient(logger)
+	httpClient.SetCircuitBreaker(httpclient.NewCircuitBreaker(httpclient.CircuitBreakerConfig{
+		FailureThreshold: cfg.CircuitBreakerFailureThreshold,
+		OpenTimeout:      cfg.CircuitBreakerOpenTimeout,
+	}, logger))
+	secretsManager := localrun.NewSecretsManager(cfg, awsCfg, logger)
+	oauthClient := oauth.NewClient(httpClient, secretsManager, logger)
+	dynamoClient := dynamodb.NewFromConfig(awsCfg)
+	auditRecorder := audit.NewRecorder(repository.NewDynamoDBAuditRepository(dynamoClient, cfg.AuditTableName), auditSource, logger)
+	secretsManager.SetAccessRecorder(auditRecorder.SecretAccessed)
+	tokenCache := oauth.NewTokenCache(cfg.OAuthTokenRefreshBefore, logger)
+	if cfg.OAuthTokenTableName != "" {
+		tokenCache.SetStore(repository.NewDynamoDBOAuthTokenRepository(dynamoClient, cfg.OAuthTokenTableName))
+	}
+	oauthClient.SetTokenCache(tokenCache)
+	weatherDecisionRepo := repository.NewDynamoDBWeatherDecisionRepository(dynamoClient, cfg.WeatherDecisionsTableName)
+	preferenceRepo := repository.NewDynamoDBPreferenceRepository(dynamoClient, cfg.PreferencesTableName)
+	approvalRepo := repository.NewDynamoDBApprovalRepository(dynamoClient, cfg.ApprovalsTableName)
+
+	golfReservationsTool := tools.NewGolfReservationsTool(httpClient, oauthClient, secretsManager, logger)
+	if cfg.ReservationsTableName != "" {
+		golfReservationsTool.SetReservationCache(repository.NewDynamoDBReservationRepository(dynamoClient, cfg.ReservationsTableName), cfg.ReservationCacheMaxAge)
+	}
+
+	golfBookTool := tools.NewGolfBookTeeTimeTool(httpClient, oauthClient, secretsManager, logger)
+	approvalNotifier := notification.NewNtfyClient(notification.NtfyClientConfig{
+		BaseURL: cfg.NtfyURL,
+		Logger:  logger,
+	})
+	golfBookTool.SetApprovalWorkflow(approvalRepo, approvalNotifier, cfg.ApprovalBaseURL)
+	golfBookTool.SetAuditRecorder(auditRecorder)
+	golfSearchTool := tools.NewGolfSearchTeeTimesTool(httpClient, oauthClient, secretsManager, logger)
+	golfSearchRangeTool := tools.NewGolfSearchTeeTimesRangeTool(httpClient, oauthClient, secretsManager, logger)
+	if cfg.BookingLedgerTableName != "" {
+		bookingLedger := repository.NewDynamoDBBookingLedgerRepository(dynamoClient, cfg.BookingLedgerTableName)
+		golfBookTool.SetBookingLedger(bookingLedger)
+		golfSearchTool.SetBookingLedger(bookingLedger)
+	}
+	// Scheduled runs act for the schedule's owner, who books with their own golf account
+	if cfg.UsersTableName != "" {
+		userRepo := repository.NewDynamoDBUserRepository(dynamoClient, cfg.UsersTableName)
+		golfReservationsTool.SetUserRepository(userRepo)
+		golfSearchTool.SetUserRepository(userRepo)
+		golfSearchRangeTool.SetUserRepository(userRepo)
+		golfBookTool.SetUserRepository(userRepo)
+	}
+
+	toolList := []server.Tool{
+		tools.NewNotificationTool(cfg.NtfyURL, logger),
+		tools.NewWeatherTool(httpClient, logger),
+		golfReservationsTool,
+		golfSearchTool,
+		golfBookTool,
+		tools.NewRecordWeatherDecisionTool(weatherDecisionRepo, logger),
+		tools.NewWeatherFeedbackReportTool(weatherDecisionRepo, httpClient, logger),
+		tools.NewRecordRoundSurveyTool(preferenceRepo, logger),
+		tools.NewCheckConstraintsTool(logger),
+		tools.NewExplainDecisionTool(logger),
+		golfSearchRangeTool,
+		tools.NewGolfListCoursesTool(logger),
+		tools.NewGolfCancelTeeTimeTool(logger),
+		tools.NewWeatherByPlaceTool(httpClient, logger),
+	}
+	for _, tool := range toolList {
+		if err := mcpServer.RegisterTool(tool); err != nil {
+			return nil, fmt.Errorf("failed to register tool %s: %w", tool.GetDefinition().Name, err)
+		}
+	}
+
+	logger.Info("MCP server initialized successfully",
+		slog.Int("tool_count", len(toolList)),
+	)
+
+	// Bound each tool call well inside the 30 second Lambda and API Gateway timeouts so a hung
+	// golf API call returns an error result instead of timing out the whole request. Booking
+	// gets the most headroom since it makes several sequential golf API calls.
+	mcpServer.SetDefaultToolTimeout(20 * time.Second)
+	mcpServer.SetToolTimeout("golf_book_tee_time", 25*time.Second)
+	mcpServer.SetToolTimeout("golf_search_tee_times_range", 25*time.Second)
+
+	// Cross-cutting limits for every tools/call; the server logs each call itself
+	mcpServer.Use(
+		server.MaxInputSize(maxToolInputBytes),
+		server.RateLimit(toolCallsPerMinute, time.Minute),
+		server.MaxResultSize(cfg.MCPMaxResultBytes),
+	)
+
+	return mcpServer, nil
+}
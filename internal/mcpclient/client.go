@@ -0,0 +1,245 @@
+// Package mcpclient calls an MCP server over its HTTP transport: it initializes the session,
+// lists the server's tools and calls them. Failures are typed so the errors package can classify
+// them, and requests that cannot have run a tool are retried.
+package mcpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+const (
+	// DefaultTimeout bounds any HTTP request to the MCP server; API Gateway gives up at 30 seconds
+	DefaultTimeout = 32 * time.Second
+
+	// APIKeyHeader carries the API key the MCP server checks on every request
+	APIKeyHeader = "X-API-Key"
+
+	// Retries of a failed request, doubling the delay each time
+	defaultRetries    = 2
+	defaultRetryDelay = 500 * time.Millisecond
+
+	// maxErrorBody is how much of a failed response's body is kept in its StatusError
+	maxErrorBody = 512
+)
+
+// Client is an MCP client for one server. It reuses connections and is safe for concurrent use.
+type Client struct {
+	url        string
+	apiKey     string
+	clientInfo protocol.MCPClientInfo
+	httpClient *http.Client
+	logger     *slog.Logger
+	retries    int
+	retryDelay time.Duration
+	nextID     atomic.Int64
+}
+
+// New creates a client for the MCP server at url that introduces itself as clientInfo
+func New(url string, clientInfo protocol.MCPClientInfo, logger *slog.Logger) *Client {
+	return &Client{
+		url:        url,
+		clientInfo: clientInfo,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		logger:     logger,
+		retries:    defaultRetries,
+		retryDelay: defaultRetryDelay,
+	}
+}
+
+// SetAPIKey sends key in the X-API-Key header of every request
+func (c *Client) SetAPIKey(key string) {
+	c.apiKey = key
+}
+
+// SetHTTPClient replaces the HTTP client requests are sent with
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// SetRetries sets how many times a failed request is retried and the delay before the first
+// retry, which doubles for each one after it
+func (c *Client) SetRetries(retries int, delay time.Duration) {
+	c.retries = retries
+	c.retryDelay = delay
+}
+
+// Initialize starts a session with the server and returns what it supports
+func (c *Client) Initialize(ctx context.Context) (*protocol.InitializeResult, error) {
+	var result protocol.InitializeResult
+	err := c.call(ctx, "initialize", protocol.InitializeRequest{
+		ProtocolVersion: protocol.MCPVersion,
+		ClientInfo:      c.clientInfo,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTools returns the tools the server offers
+func (c *Client) ListTools(ctx context.Context) ([]protocol.Tool, error) {
+	var result protocol.ToolsListResult
+	if err := c.call(ctx, "tools/list", map[string]interface{}{}, &result); err != nil {
+		return nil, err
+	}
+	return result.Tools, nil
+}
+
+// CallTool calls a tool. A tool that fails returns a result with IsError set, not an error. When
+// the server has lost the session, e.g. a new Lambda instance answered, the client initializes
+// again and repeats the call once.
+func (c *Client) CallTool(ctx context.Context, req protocol.ToolCallRequest) (*protocol.ToolCallResult, error) {
+	var result protocol.ToolCallResult
+	err := c.call(ctx, "tools/call", req, &result)
+	if isNotInitialized(err) {
+		c.logger.InfoContext(ctx, "MCP server lost the session, initializing again",
+			slog.String("tool_name", req.Name),
+		)
+		if _, err = c.Initialize(ctx); err == nil {
+			err = c.call(ctx, "tools/call", req, &result)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// call sends a request, retrying failures that retryable allows for the method, and decodes its
+// result into out
+func (c *Client) call(ctx context.Context, method string, params, out interface{}) error {
+	delay := c.retryDelay
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, params, out)
+		if err == nil || attempt >= c.retries || ctx.Err() != nil || !retryable(method, err) {
+			return err
+		}
+
+		c.logger.WarnContext(ctx, "MCP request failed, retrying",
+			slog.String("method", method),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("delay", delay),
+			slog.String("error", err.Error()),
+		)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// send makes one JSON-RPC request over HTTP
+func (c *Client) send(ctx context.Context, method string, params, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": protocol.JSONRPCVersion,
+		"id":      c.nextID.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal MCP %s request: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create MCP %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set(APIKeyHeader, c.apiKey)
+	}
+	if correlationID := logging.CorrelationID(ctx); correlationID != "" {
+		req.Header.Set(logging.CorrelationIDHeader, correlationID)
+	}
+	if userID := models.ActingUser(ctx); userID != "" {
+		req.Header.Set(models.ActingUserHeader, userID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("MCP %s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	// Read the whole body so the connection can be reused
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read MCP %s response: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("MCP %s request failed: %w", method, &httpclient.StatusError{
+			StatusCode: resp.StatusCode,
+			Body:       string(respBody[:min(len(respBody), maxErrorBody)]),
+		})
+	}
+
+	var rpcResp protocol.JSONRPCResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return fmt.Errorf("failed to unmarshal MCP %s response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return &RPCError{Method: method, Err: rpcResp.Error}
+	}
+	if out != nil {
+		if err := json.Unmarshal(rpcResp.Result, out); err != nil {
+			return fmt.Errorf("failed to unmarshal MCP %s result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// RPCError is a JSON-RPC error the server returned for a request
+type RPCError struct {
+	Method string
+	Err    *protocol.JSONRPCError
+}
+
+func (e *RPCError) Error() string {
+	msg := fmt.Sprintf("MCP %s error %d: %s", e.Method, e.Err.Code, e.Err.Message)
+	if e.Err.Data != nil {
+		msg += fmt.Sprintf(" (%v)", e.Err.Data)
+	}
+	return msg
+}
+
+func (e *RPCError) Unwrap() error {
+	return e.Err
+}
+
+// isNotInitialized reports whether the server refused a request because it has no session
+func isNotInitialized(err error) bool {
+	var rpcErr *RPCError
+	return errors.As(err, &rpcErr) && rpcErr.Err.Code == protocol.ErrCodeInvalidRequest && rpcErr.Err.Message == "Server not initialized"
+}
+
+// retryable reports whether a failed request may be sent again. A tool call may have booked a tee
+// time or sent a notification before failing, so it is only retried when the server cannot have
+// run it: the connection was never made, or API Gateway throttled the request.
+func retryable(method string, err error) bool {
+	if method != "tools/call" {
+		return apperrors.IsRetryable(err)
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return apperrors.Is(err, apperrors.ErrThrottled)
+}
@@ -0,0 +1,159 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// reply is one canned response from the test server
+type reply struct {
+	status int
+	body   string
+}
+
+// scriptedServer answers requests with replies in order, repeating the last one, and records
+// the methods and headers it received
+type scriptedServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	replies []reply
+	methods []string
+	apiKeys []string
+}
+
+func newScriptedServer(t *testing.T, replies ...reply) *scriptedServer {
+	t.Helper()
+	s := &scriptedServer{replies: replies}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req protocol.JSONRPCRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		s.mu.Lock()
+		s.methods = append(s.methods, req.Method)
+		s.apiKeys = append(s.apiKeys, r.Header.Get(APIKeyHeader))
+		next := s.replies[0]
+		if len(s.replies) > 1 {
+			s.replies = s.replies[1:]
+		}
+		s.mu.Unlock()
+
+		w.WriteHeader(next.status)
+		_, _ = io.WriteString(w, next.body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestClient(url string) *Client {
+	client := New(url, protocol.MCPClientInfo{Name: "test", Version: "1.0.0"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	client.SetRetries(2, time.Millisecond)
+	return client
+}
+
+const toolResultBody = `{"jsonrpc":"2.0","id":1,"result":{"content":[{"type":"text","text":"booked"}]}}`
+
+func TestClient_ListTools(t *testing.T) {
+	server := newScriptedServer(t, reply{http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"get_weather","inputSchema":{"type":"object"}}]}}`})
+	client := newTestClient(server.URL)
+	client.SetAPIKey("secret")
+
+	tools, err := client.ListTools(context.Background())
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "get_weather" {
+		t.Errorf("ListTools() = %+v, want get_weather", tools)
+	}
+	if server.apiKeys[0] != "secret" {
+		t.Errorf("%s = %q, want the API key", APIKeyHeader, server.apiKeys[0])
+	}
+}
+
+func TestClient_ErrorKinds(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		want      error
+		retryable bool
+	}{
+		{"invalid API key", http.StatusUnauthorized, `{"jsonrpc":"2.0","error":{"code":-32004,"message":"Invalid API key"},"id":null}`, apperrors.ErrAuth, false},
+		{"gateway timeout", http.StatusGatewayTimeout, `{"message":"Endpoint request timed out"}`, apperrors.ErrProviderUnavailable, true},
+		{"invalid params", http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid tool input"},"id":1}`, apperrors.ErrValidation, false},
+		{"internal error", http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":1}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newScriptedServer(t, reply{tt.status, tt.body})
+			client := newTestClient(server.URL)
+			client.SetRetries(0, 0)
+
+			_, err := client.ListTools(context.Background())
+			if err == nil {
+				t.Fatal("ListTools() error = nil")
+			}
+			if got := apperrors.Kind(err); got != tt.want {
+				t.Errorf("Kind() = %v, want %v", got, tt.want)
+			}
+			if got := apperrors.IsRetryable(err); got != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	unavailable := reply{http.StatusServiceUnavailable, `{"message":"Service Unavailable"}`}
+	throttled := reply{http.StatusTooManyRequests, `{"message":"Too Many Requests"}`}
+
+	t.Run("tools/list retried", func(t *testing.T) {
+		server := newScriptedServer(t, unavailable, reply{http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}`})
+		if _, err := newTestClient(server.URL).ListTools(context.Background()); err != nil || len(server.methods) != 2 {
+			t.Errorf("ListTools() error = %v after %d requests, want success on the retry", err, len(server.methods))
+		}
+	})
+
+	t.Run("throttled tool call retried", func(t *testing.T) {
+		server := newScriptedServer(t, throttled, reply{http.StatusOK, toolResultBody})
+		result, err := newTestClient(server.URL).CallTool(context.Background(), protocol.ToolCallRequest{Name: "golf_book_tee_time"})
+		if err != nil || result.Content[0].Text != "booked" {
+			t.Errorf("CallTool() = %+v, %v; want the result of the retry", result, err)
+		}
+	})
+
+	t.Run("failed tool call not retried", func(t *testing.T) {
+		server := newScriptedServer(t, unavailable, reply{http.StatusOK, toolResultBody})
+		_, err := newTestClient(server.URL).CallTool(context.Background(), protocol.ToolCallRequest{Name: "golf_book_tee_time"})
+		if !apperrors.Is(err, apperrors.ErrProviderUnavailable) || len(server.methods) != 1 {
+			t.Errorf("CallTool() error = %v after %d requests, want the failure without a retry that could book twice", err, len(server.methods))
+		}
+	})
+}
+
+func TestClient_CallToolReinitializes(t *testing.T) {
+	server := newScriptedServer(t,
+		reply{http.StatusOK, `{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"Server not initialized","data":"Call initialize first"}}`},
+		reply{http.StatusOK, `{"jsonrpc":"2.0","id":2,"result":{"protocolVersion":"2025-03-26","serverInfo":{"name":"rez-agent-mcp","version":"1.0.0"},"capabilities":{}}}`},
+		reply{http.StatusOK, toolResultBody},
+	)
+
+	result, err := newTestClient(server.URL).CallTool(context.Background(), protocol.ToolCallRequest{Name: "get_weather"})
+	if err != nil || result.Content[0].Text != "booked" {
+		t.Fatalf("CallTool() = %+v, %v; want the result after initializing again", result, err)
+	}
+	want := []string{"tools/call", "initialize", "tools/call"}
+	if len(server.methods) != len(want) || server.methods[1] != want[1] || server.methods[2] != want[2] {
+		t.Errorf("methods = %v, want %v", server.methods, want)
+	}
+}
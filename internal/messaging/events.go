@@ -0,0 +1,195 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/google/uuid"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// DomainEventType names something that happened, independent of the queue that carried it
+type DomainEventType string
+
+const (
+	// DomainEventMessageCreated is published when a new message is published to its topic
+	DomainEventMessageCreated DomainEventType = "MessageCreated"
+	// DomainEventBookingCompleted is published when a tee time is reserved
+	DomainEventBookingCompleted DomainEventType = "BookingCompleted"
+	// DomainEventScheduleTriggered is published when a schedule's message reaches its consumer
+	DomainEventScheduleTriggered DomainEventType = "ScheduleTriggered"
+)
+
+const (
+	// DomainEventSource is the source of every event on the bus; rules match on it
+	DomainEventSource = "rez-agent"
+	// DomainEventVersion is the version of the envelope and its data. It changes only when a
+	// field is removed or changes meaning; new fields are added without a new version.
+	DomainEventVersion = "1"
+)
+
+// DomainEvent is the envelope published as an EventBridge event's detail. Its detail-type is the
+// event's Type, so rules can match either.
+type DomainEvent struct {
+	ID            string          `json:"id"`
+	Type          DomainEventType `json:"type"`
+	Version       string          `json:"version"`
+	Stage         string          `json:"stage"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Data          interface{}     `json:"data"`
+}
+
+// MessageCreatedData is the data of a MessageCreated event
+type MessageCreatedData struct {
+	MessageID   string `json:"message_id"`
+	MessageType string `json:"message_type"`
+	CreatedBy   string `json:"created_by"`
+	Priority    string `json:"priority"`
+	CourseID    int    `json:"course_id,omitempty"`
+}
+
+// BookingCompletedData is the data of a BookingCompleted event
+type BookingCompletedData struct {
+	ReservationID int    `json:"reservation_id"`
+	CourseID      int    `json:"course_id"`
+	CourseName    string `json:"course_name"`
+	TeeSheetID    int    `json:"tee_sheet_id"`
+	Players       int    `json:"players"`
+	ApprovalID    string `json:"approval_id,omitempty"`
+}
+
+// ScheduleTriggeredData is the data of a ScheduleTriggered event
+type ScheduleTriggeredData struct {
+	ScheduleID  string `json:"schedule_id"`
+	MessageID   string `json:"message_id"`
+	MessageType string `json:"message_type"`
+}
+
+// MessageCreated is the event of a newly published message
+func MessageCreated(message *models.Message) DomainEvent {
+	data := MessageCreatedData{
+		MessageID:   message.ID,
+		MessageType: message.MessageType.String(),
+		CreatedBy:   message.CreatedBy,
+		Priority:    message.EffectivePriority().String(),
+	}
+	if courseID, ok := message.CourseID(); ok {
+		data.CourseID = courseID
+	}
+	return DomainEvent{Type: DomainEventMessageCreated, CorrelationID: message.CorrelationID, Data: data}
+}
+
+// BookingCompleted is the event of a reserved tee time
+func BookingCompleted(data BookingCompletedData) DomainEvent {
+	return DomainEvent{Type: DomainEventBookingCompleted, Data: data}
+}
+
+// ScheduleTriggered is the event of a schedule's message arriving
+func ScheduleTriggered(scheduleID string, message *models.Message) DomainEvent {
+	return DomainEvent{
+		Type:          DomainEventScheduleTriggered,
+		CorrelationID: message.CorrelationID,
+		Data: ScheduleTriggeredData{
+			ScheduleID:  scheduleID,
+			MessageID:   message.ID,
+			MessageType: message.MessageType.String(),
+		},
+	}
+}
+
+// EventBridgeClient is the part of the EventBridge API the publisher uses
+type EventBridgeClient interface {
+	PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error)
+}
+
+// EventBridgePublisher publishes domain events to a custom EventBridge bus, so other systems can
+// subscribe to them with rules instead of reading our queues. A nil publisher publishes nothing,
+// so components can call it whether or not a bus is configured.
+type EventBridgePublisher struct {
+	client  EventBridgeClient
+	busName string
+	stage   string
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewEventBridgePublisher creates a publisher for the named bus, stamping events with stage
+func NewEventBridgePublisher(client EventBridgeClient, busName, stage string, logger *slog.Logger) *EventBridgePublisher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &EventBridgePublisher{
+		client:  client,
+		busName: busName,
+		stage:   stage,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Publish puts the event on the bus, filling in its ID, time, stage, version and (when unset)
+// the context's correlation ID. Events describe things that already happened, so a failure to
+// publish is logged rather than returned.
+func (p *EventBridgePublisher) Publish(ctx context.Context, event DomainEvent) {
+	if p == nil {
+		return
+	}
+
+	event.ID = uuid.New().String()
+	event.Version = DomainEventVersion
+	event.Stage = p.stage
+	event.OccurredAt = p.now().UTC()
+	if event.CorrelationID == "" {
+		event.CorrelationID = logging.CorrelationID(ctx)
+	}
+
+	if err := p.put(ctx, event); err != nil {
+		p.logger.ErrorContext(ctx, "failed to publish domain event",
+			slog.String("event_type", string(event.Type)),
+			slog.String("event_id", event.ID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	p.logger.DebugContext(ctx, "domain event published",
+		slog.String("event_type", string(event.Type)),
+		slog.String("event_id", event.ID),
+		slog.String("event_bus", p.busName),
+	)
+}
+
+// put sends one event, reporting an entry EventBridge rejected as an error
+func (p *EventBridgePublisher) put(ctx context.Context, event DomainEvent) error {
+	detail, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal domain event: %w", err)
+	}
+
+	output, err := p.client.PutEvents(ctx, &eventbridge.PutEventsInput{
+		Entries: []types.PutEventsRequestEntry{{
+			EventBusName: aws.String(p.busName),
+			Source:       aws.String(DomainEventSource),
+			DetailType:   aws.String(string(event.Type)),
+			Detail:       aws.String(string(detail)),
+			Time:         aws.Time(event.OccurredAt),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put event on bus %s: %w", p.busName, err)
+	}
+	if output.FailedEntryCount > 0 && len(output.Entries) > 0 {
+		entry := output.Entries[0]
+		return fmt.Errorf("event rejected by bus %s: %s: %s", p.busName, aws.ToString(entry.ErrorCode), aws.ToString(entry.ErrorMessage))
+	}
+	return nil
+}
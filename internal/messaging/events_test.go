@@ -0,0 +1,94 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+type fakeEventBridge struct {
+	inputs []*eventbridge.PutEventsInput
+	output *eventbridge.PutEventsOutput
+	err    error
+}
+
+func (f *fakeEventBridge) PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error) {
+	f.inputs = append(f.inputs, params)
+	if f.output == nil {
+		return &eventbridge.PutEventsOutput{}, f.err
+	}
+	return f.output, f.err
+}
+
+func TestEventBridgePublisher_Publish(t *testing.T) {
+	client := &fakeEventBridge{}
+	publisher := NewEventBridgePublisher(client, "rez-agent-events-dev", "dev", nil)
+
+	message := models.NewMessage("scheduler", map[string]interface{}{"operation": "book_tee_time"}, "1.0", models.StageDev, models.MessageTypeWebAction,
+		map[string]interface{}{"action": string(models.WebActionTypeGolf), "courseID": 2})
+	ctx := logging.WithCorrelationID(context.Background(), "corr-1")
+	publisher.Publish(ctx, MessageCreated(message))
+
+	if len(client.inputs) != 1 || len(client.inputs[0].Entries) != 1 {
+		t.Fatalf("PutEvents inputs = %+v, want one entry", client.inputs)
+	}
+	entry := client.inputs[0].Entries[0]
+	if got := aws.ToString(entry.EventBusName); got != "rez-agent-events-dev" {
+		t.Errorf("EventBusName = %q", got)
+	}
+	if got := aws.ToString(entry.Source); got != DomainEventSource {
+		t.Errorf("Source = %q, want %q", got, DomainEventSource)
+	}
+	if got := aws.ToString(entry.DetailType); got != string(DomainEventMessageCreated) {
+		t.Errorf("DetailType = %q, want %q", got, DomainEventMessageCreated)
+	}
+
+	var detail struct {
+		ID            string             `json:"id"`
+		Type          DomainEventType    `json:"type"`
+		Version       string             `json:"version"`
+		Stage         string             `json:"stage"`
+		CorrelationID string             `json:"correlation_id"`
+		Data          MessageCreatedData `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(aws.ToString(entry.Detail)), &detail); err != nil {
+		t.Fatalf("detail is not JSON: %v", err)
+	}
+	if detail.ID == "" || detail.Type != DomainEventMessageCreated || detail.Version != DomainEventVersion || detail.Stage != "dev" {
+		t.Errorf("envelope = %+v", detail)
+	}
+	// The message has no correlation ID yet, so the context's is used
+	if detail.CorrelationID != "corr-1" {
+		t.Errorf("correlation_id = %q, want corr-1", detail.CorrelationID)
+	}
+	want := MessageCreatedData{MessageID: message.ID, MessageType: "web_action", CreatedBy: "scheduler", Priority: "high", CourseID: 2}
+	if detail.Data != want {
+		t.Errorf("data = %+v, want %+v", detail.Data, want)
+	}
+}
+
+func TestEventBridgePublisher_PublishFailures(t *testing.T) {
+	// Failures are logged, never returned or panicked on
+	rejected := &fakeEventBridge{output: &eventbridge.PutEventsOutput{
+		FailedEntryCount: 1,
+		Entries:          []types.PutEventsResultEntry{{ErrorCode: aws.String("InternalFailure")}},
+	}}
+	NewEventBridgePublisher(rejected, "bus", "dev", nil).Publish(context.Background(), BookingCompleted(BookingCompletedData{ReservationID: 1}))
+
+	failing := &fakeEventBridge{err: errors.New("throttled")}
+	NewEventBridgePublisher(failing, "bus", "dev", nil).Publish(context.Background(), BookingCompleted(BookingCompletedData{ReservationID: 1}))
+	if len(failing.inputs) != 1 {
+		t.Errorf("PutEvents calls = %d, want 1", len(failing.inputs))
+	}
+
+	// Without a bus nothing is published
+	var disabled *EventBridgePublisher
+	disabled.Publish(context.Background(), BookingCompleted(BookingCompletedData{ReservationID: 1}))
+}
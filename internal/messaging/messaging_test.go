@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/logging"
 	"github.com/jrzesz33/rez_agent/internal/models"
 )
 
@@ -184,3 +186,114 @@ func TestSQSBatchProcessor_ProcessBatch(t *testing.T) {
 		})
 	}
 }
+
+func TestSQSBatchProcessor_ProcessBatch_MessageTypeRegistry(t *testing.T) {
+	body := func(mt models.MessageType, payload map[string]interface{}) string {
+		b, _ := json.Marshal(models.NewMessage("test-system", nil, "1.0", models.StageDev, mt, payload))
+		return string(b)
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		wantHandled bool
+	}{
+		{"bound notification", body(models.MessageTypeNotification, map[string]interface{}{"message": "hi"}), true},
+		{"unknown type", body(models.MessageType("notfy"), map[string]interface{}{"message": "hi"}), false},
+		{"misrouted type", body(models.MessageTypeWebAction, map[string]interface{}{"action": "golf"}), false},
+		{"invalid payload", body(models.MessageTypeNotification, map[string]interface{}{}), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewSQSBatchProcessor(slog.Default())
+			processor.SetMessageTypeRegistry(models.DefaultMessageTypeRegistry(), models.ComponentProcessor)
+
+			handled := false
+			event := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "msg-1", Body: tt.body}}}
+			response, _ := processor.ProcessBatch(context.Background(), event, func(ctx context.Context, msg *models.Message) error {
+				handled = true
+				return nil
+			})
+
+			if handled != tt.wantHandled {
+				t.Errorf("handler called = %v, want %v", handled, tt.wantHandled)
+			}
+			if failed := len(response.BatchItemFailures) == 1; failed == tt.wantHandled {
+				t.Errorf("batch failures = %d, want failure %v", len(response.BatchItemFailures), !tt.wantHandled)
+			}
+		})
+	}
+}
+
+func TestSQSBatchProcessor_ProcessBatch_PermanentFailure(t *testing.T) {
+	b, _ := json.Marshal(models.NewMessage("test-system", nil, "1.0", models.StageDev, models.MessageTypeWebAction, map[string]interface{}{"action": "golf"}))
+
+	tests := []struct {
+		name         string
+		receiveCount string
+		wantReported bool
+	}{
+		{"first attempt", "1", false},
+		{"final attempt", "3", true},
+		{"missing attribute", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewSQSBatchProcessor(slog.Default())
+
+			var reported int
+			processor.SetPermanentFailureHandler(DefaultMaxReceiveCount, func(ctx context.Context, msg *models.Message, cause error, attempts int) {
+				reported = attempts
+			})
+
+			record := events.SQSMessage{MessageId: "msg-1", Body: string(b), Attributes: map[string]string{}}
+			if tt.receiveCount != "" {
+				record.Attributes["ApproximateReceiveCount"] = tt.receiveCount
+			}
+			response, _ := processor.ProcessBatch(context.Background(), events.SQSEvent{Records: []events.SQSMessage{record}}, func(ctx context.Context, msg *models.Message) error {
+				return errors.New("HTTP error 503: unavailable")
+			})
+
+			if len(response.BatchItemFailures) != 1 {
+				t.Errorf("batch failures = %d, want 1", len(response.BatchItemFailures))
+			}
+			if (reported > 0) != tt.wantReported {
+				t.Errorf("failure reported = %v, want %v", reported > 0, tt.wantReported)
+			}
+		})
+	}
+}
+
+func TestSQSBatchProcessor_ProcessBatch_CorrelationID(t *testing.T) {
+	body := func(correlationID string) string {
+		message := models.NewMessage("test-system", nil, "1.0", models.StageDev, models.MessageTypeHelloWorld, nil)
+		message.CorrelationID = correlationID
+		b, _ := json.Marshal(message)
+		return string(b)
+	}
+
+	var got []string
+	processor := NewSQSBatchProcessor(nil)
+	_, err := processor.ProcessBatch(context.Background(), events.SQSEvent{Records: []events.SQSMessage{
+		{MessageId: "sqs-1", Body: body("corr_from_webapi")},
+		{MessageId: "sqs-2", Body: body("")},
+	}}, func(ctx context.Context, message *models.Message) error {
+		if logging.CorrelationID(ctx) != message.CorrelationID {
+			t.Errorf("context correlation ID = %q, message has %q", logging.CorrelationID(ctx), message.CorrelationID)
+		}
+		got = append(got, message.CorrelationID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ProcessBatch() error = %v", err)
+	}
+
+	if len(got) != 2 || got[0] != "corr_from_webapi" {
+		t.Fatalf("correlation IDs = %v, want the message's own first", got)
+	}
+	if !strings.HasPrefix(got[1], "corr_") {
+		t.Errorf("correlation ID = %q, want one generated for a message without", got[1])
+	}
+}
@@ -0,0 +1,76 @@
+package messaging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// TopicRoutingTable maps message types to SNS topic ARNs.
+// Message types without a route are published to the default topic.
+type TopicRoutingTable struct {
+	routes          map[models.MessageType]string
+	defaultTopicArn string
+}
+
+// NewTopicRoutingTable creates and validates a routing table.
+// Routes with an empty ARN are treated as unconfigured and dropped.
+func NewTopicRoutingTable(routes map[models.MessageType]string, defaultTopicArn string) (*TopicRoutingTable, error) {
+	table := &TopicRoutingTable{
+		routes:          make(map[models.MessageType]string, len(routes)),
+		defaultTopicArn: defaultTopicArn,
+	}
+
+	for messageType, topicArn := range routes {
+		if !messageType.IsValid() {
+			return nil, fmt.Errorf("invalid topic route: unknown message type %q", messageType)
+		}
+		if topicArn == "" {
+			continue
+		}
+		if err := validateTopicArn(topicArn); err != nil {
+			return nil, fmt.Errorf("invalid topic route for %s: %w", messageType, err)
+		}
+		table.routes[messageType] = topicArn
+	}
+
+	if defaultTopicArn != "" {
+		if err := validateTopicArn(defaultTopicArn); err != nil {
+			return nil, fmt.Errorf("invalid default topic: %w", err)
+		}
+	}
+
+	return table, nil
+}
+
+// validateTopicArn checks that the value looks like an SNS topic ARN
+func validateTopicArn(topicArn string) error {
+	parts := strings.Split(topicArn, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "sns" || parts[5] == "" {
+		return fmt.Errorf("not an SNS topic ARN: %s", topicArn)
+	}
+	return nil
+}
+
+// TopicFor returns the topic ARN for the message type, falling back to the default topic
+func (t *TopicRoutingTable) TopicFor(messageType models.MessageType) (string, error) {
+	if topicArn, ok := t.routes[messageType]; ok {
+		return topicArn, nil
+	}
+	if t.defaultTopicArn != "" {
+		return t.defaultTopicArn, nil
+	}
+	return "", fmt.Errorf("no SNS topic configured for message type %s", messageType)
+}
+
+// MessageTypes returns the message types with an explicit route, sorted
+func (t *TopicRoutingTable) MessageTypes() []models.MessageType {
+	types := make([]models.MessageType, 0, len(t.routes))
+	for messageType := range t.routes {
+		types = append(types, messageType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
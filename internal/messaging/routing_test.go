@@ -0,0 +1,77 @@
+package messaging
+
+import (
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+func TestNewTopicRoutingTable(t *testing.T) {
+	const (
+		webActionsArn    = "arn:aws:sns:us-east-1:123456789012:rez-agent-web-actions-dev"
+		notificationsArn = "arn:aws:sns:us-east-1:123456789012:rez-agent-notifications-dev"
+	)
+
+	tests := []struct {
+		name       string
+		routes     map[models.MessageType]string
+		defaultArn string
+		wantErr    bool
+	}{
+		{"valid", map[models.MessageType]string{models.MessageTypeWebAction: webActionsArn}, notificationsArn, false},
+		{"empty routes are dropped", map[models.MessageType]string{models.MessageTypeAgentResponse: ""}, notificationsArn, false},
+		{"no default", map[models.MessageType]string{models.MessageTypeWebAction: webActionsArn}, "", false},
+		{"unknown message type", map[models.MessageType]string{"carrier_pigeon": webActionsArn}, notificationsArn, true},
+		{"malformed route ARN", map[models.MessageType]string{models.MessageTypeWebAction: "web-actions"}, notificationsArn, true},
+		{"non-SNS route ARN", map[models.MessageType]string{models.MessageTypeWebAction: "arn:aws:sqs:us-east-1:123456789012:queue"}, notificationsArn, true},
+		{"malformed default ARN", nil, "notifications", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTopicRoutingTable(tt.routes, tt.defaultArn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTopicRoutingTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTopicRoutingTable_TopicFor(t *testing.T) {
+	const (
+		webActionsArn    = "arn:aws:sns:us-east-1:123456789012:rez-agent-web-actions-dev"
+		notificationsArn = "arn:aws:sns:us-east-1:123456789012:rez-agent-notifications-dev"
+	)
+
+	table, err := NewTopicRoutingTable(map[models.MessageType]string{
+		models.MessageTypeWebAction:     webActionsArn,
+		models.MessageTypeAgentResponse: "",
+	}, notificationsArn)
+	if err != nil {
+		t.Fatalf("NewTopicRoutingTable() error = %v", err)
+	}
+
+	tests := []struct {
+		messageType models.MessageType
+		want        string
+	}{
+		{models.MessageTypeWebAction, webActionsArn},
+		{models.MessageTypeAgentResponse, notificationsArn},
+		{models.MessageTypeScheduled, notificationsArn},
+	}
+	for _, tt := range tests {
+		got, err := table.TopicFor(tt.messageType)
+		if err != nil || got != tt.want {
+			t.Errorf("TopicFor(%s) = %q, %v, want %q", tt.messageType, got, err, tt.want)
+		}
+	}
+
+	if got := table.MessageTypes(); len(got) != 1 || got[0] != models.MessageTypeWebAction {
+		t.Errorf("MessageTypes() = %v, want [web_action]", got)
+	}
+
+	noDefault, _ := NewTopicRoutingTable(nil, "")
+	if _, err := noDefault.TopicFor(models.MessageTypeScheduled); err == nil {
+		t.Error("TopicFor() without a route or default should return an error")
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/jrzesz33/rez_agent/internal/logging"
 	"github.com/jrzesz33/rez_agent/internal/models"
 )
 
@@ -40,49 +42,64 @@ func NewSNSClient(client *sns.Client, topicArn string, logger *slog.Logger) *SNS
 
 // TopicRoutingSNSClient implements SNSPublisher with message-type-based topic routing
 type TopicRoutingSNSClient struct {
-	client                   *sns.Client
-	webActionsTopicArn       string
-	notificationsTopicArn    string
-	agentResponseTopicArn    string
-	scheduleCreationTopicArn string
-	logger                   *slog.Logger
+	client   *sns.Client
+	routes   *TopicRoutingTable
+	logger   *slog.Logger
+	registry *models.MessageTypeRegistry
+	producer string
+	events   *EventBridgePublisher
 }
 
 // NewTopicRoutingSNSClient creates a new topic-routing SNS client
-func NewTopicRoutingSNSClient(client *sns.Client, webActionsTopicArn, notificationsTopicArn, agentResponseTopicArn, scheduleCreationTopicArn string, logger *slog.Logger) *TopicRoutingSNSClient {
+func NewTopicRoutingSNSClient(client *sns.Client, routes *TopicRoutingTable, logger *slog.Logger) *TopicRoutingSNSClient {
 	if logger == nil {
 		logger = slog.Default()
 	}
 
 	return &TopicRoutingSNSClient{
-		client:                   client,
-		webActionsTopicArn:       webActionsTopicArn,
-		notificationsTopicArn:    notificationsTopicArn,
-		agentResponseTopicArn:    agentResponseTopicArn,
-		scheduleCreationTopicArn: scheduleCreationTopicArn,
-		logger:                   logger,
+		client: client,
+		routes: routes,
+		logger: logger,
 	}
 }
 
-// getTopicForMessageType returns the appropriate topic ARN based on message type
-func (s *TopicRoutingSNSClient) GetTopicForMessageType(messageType models.MessageType) string {
-	switch messageType {
-	case models.MessageTypeWebAction:
-		return s.webActionsTopicArn
-	case models.MessageTypeAgentResponse:
-		return s.agentResponseTopicArn
-	case models.MessageTypeScheduleCreation:
-		return s.scheduleCreationTopicArn
-	default:
-		// All other message types (scheduled, manual, hello_world) go to notifications topic
-		return s.notificationsTopicArn
-	}
+// SetMessageTypeRegistry enforces the registry's producer and payload rules for every published message
+func (s *TopicRoutingSNSClient) SetMessageTypeRegistry(registry *models.MessageTypeRegistry, producer string) {
+	s.registry = registry
+	s.producer = producer
+}
+
+// SetEventPublisher publishes a MessageCreated domain event for every new message published
+func (s *TopicRoutingSNSClient) SetEventPublisher(publisher *EventBridgePublisher) {
+	s.events = publisher
+}
+
+// GetTopicForMessageType returns the appropriate topic ARN based on message type
+func (s *TopicRoutingSNSClient) GetTopicForMessageType(messageType models.MessageType) (string, error) {
+	return s.routes.TopicFor(messageType)
 }
 
 // PublishMessage publishes a message to the appropriate topic based on message type
 func (s *TopicRoutingSNSClient) PublishMessage(ctx context.Context, message *models.Message) error {
+	if s.registry != nil {
+		if err := s.registry.ValidatePublish(message, s.producer); err != nil {
+			return fmt.Errorf("refusing to publish message %s: %w", message.ID, err)
+		}
+	}
+
+	// A message published while handling another continues its journey; anything else starts one
+	if message.CorrelationID == "" {
+		message.CorrelationID = logging.CorrelationID(ctx)
+	}
+	if message.CorrelationID == "" {
+		message.CorrelationID = logging.NewCorrelationID()
+	}
+
 	// Determine which topic to use based on message type
-	topicArn := s.GetTopicForMessageType(message.MessageType)
+	topicArn, err := s.GetTopicForMessageType(message.MessageType)
+	if err != nil {
+		return err
+	}
 
 	// Serialize message to JSON
 	messageBytes, err := json.Marshal(message)
@@ -92,22 +109,9 @@ func (s *TopicRoutingSNSClient) PublishMessage(ctx context.Context, message *mod
 
 	// Publish to SNS
 	input := &sns.PublishInput{
-		TopicArn: aws.String(topicArn),
-		Message:  aws.String(string(messageBytes)),
-		MessageAttributes: map[string]types.MessageAttributeValue{
-			"stage": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(message.Stage.String()),
-			},
-			"message_type": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(message.MessageType.String()),
-			},
-			"status": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(message.Status.String()),
-			},
-		},
+		TopicArn:          aws.String(topicArn),
+		Message:           aws.String(string(messageBytes)),
+		MessageAttributes: messageAttributes(message),
 	}
 
 	result, err := s.client.Publish(ctx, input)
@@ -118,9 +122,38 @@ func (s *TopicRoutingSNSClient) PublishMessage(ctx context.Context, message *mod
 	s.logger.DebugContext(ctx, "message published to topic-routed SNS",
 		slog.String("message_id", message.ID),
 		slog.String("message_type", message.MessageType.String()),
+		slog.String(logging.CorrelationIDAttribute, message.CorrelationID),
 		slog.String("sns_message_id", aws.ToString(result.MessageId)),
 		slog.String("topic_arn", topicArn),
 	)
 
+	// Retries republish a message that was already announced
+	if message.RetryCount == 0 {
+		s.events.Publish(ctx, MessageCreated(message))
+	}
+
 	return nil
 }
+
+// messageAttributes are the SNS message attributes of a published message. Besides carrying the
+// correlation ID, they let a subscription filter policy select messages on a topic by
+// message_type, stage, status, priority or course_id (a Number, present on golf web actions).
+func messageAttributes(message *models.Message) map[string]types.MessageAttributeValue {
+	attribute := func(value string) types.MessageAttributeValue {
+		return types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+	attributes := map[string]types.MessageAttributeValue{
+		"stage":                        attribute(message.Stage.String()),
+		"message_type":                 attribute(message.MessageType.String()),
+		"status":                       attribute(message.Status.String()),
+		"priority":                     attribute(message.EffectivePriority().String()),
+		logging.CorrelationIDAttribute: attribute(message.CorrelationID),
+	}
+	if courseID, ok := message.CourseID(); ok {
+		attributes["course_id"] = types.MessageAttributeValue{
+			DataType:    aws.String("Number"),
+			StringValue: aws.String(strconv.Itoa(courseID)),
+		}
+	}
+	return attributes
+}
@@ -0,0 +1,43 @@
+package messaging
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+func TestMessageAttributes(t *testing.T) {
+	booking := models.NewMessage("test", map[string]interface{}{"operation": "book_tee_time"}, "1.0", models.StageProd, models.MessageTypeWebAction,
+		map[string]interface{}{"action": string(models.WebActionTypeGolf), "courseID": 1})
+	booking.CorrelationID = "corr-1"
+
+	attributes := messageAttributes(booking)
+	want := map[string]string{
+		"stage":          "prod",
+		"message_type":   "web_action",
+		"status":         "created",
+		"priority":       "high",
+		"correlation_id": "corr-1",
+		"course_id":      "1",
+	}
+	for name, value := range want {
+		if got := aws.ToString(attributes[name].StringValue); got != value {
+			t.Errorf("attribute %s = %q, want %q", name, got, value)
+		}
+	}
+	if dataType := aws.ToString(attributes["course_id"].DataType); dataType != "Number" {
+		t.Errorf("course_id DataType = %q, want Number so filter policies can match it numerically", dataType)
+	}
+
+	// Messages without a course leave the attribute out, and an explicit priority wins
+	notification := models.NewMessage("test", nil, "1.0", models.StageDev, models.MessageTypeNotification, map[string]interface{}{"message": "hi"})
+	notification.Priority = models.PriorityLow
+	attributes = messageAttributes(notification)
+	if _, ok := attributes["course_id"]; ok {
+		t.Error("notification has a course_id attribute")
+	}
+	if got := aws.ToString(attributes["priority"].StringValue); got != "low" {
+		t.Errorf("priority = %q, want low", got)
+	}
+}
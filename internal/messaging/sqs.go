@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
 	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/kit/sqsbatch"
 )
 
 // SQSMessageWrapper represents the structure of messages received from SQS
@@ -66,9 +70,21 @@ func ParseSQSEvent(event events.SQSEvent, logger *slog.Logger) ([]*models.Messag
 	return messages, nil
 }
 
-// SQSBatchProcessor processes SQS messages in batch
+// DefaultMaxReceiveCount matches the maxReceiveCount of the queues' redrive policies
+const DefaultMaxReceiveCount = 3
+
+// PermanentFailureHandler is called for a message whose final delivery attempt failed
+type PermanentFailureHandler = sqsbatch.PermanentFailureHandler[*models.Message]
+
+// SQSBatchProcessor processes SQS messages in batch, adding message type checks and latency
+// metrics to the generic sqsbatch processor
 type SQSBatchProcessor struct {
-	logger *slog.Logger
+	processor *sqsbatch.Processor[*models.Message]
+	logger    *slog.Logger
+	registry  *models.MessageTypeRegistry
+	consumer  string
+	latency   *metrics.LatencyRecorder
+	events    *EventBridgePublisher
 }
 
 // NewSQSBatchProcessor creates a new SQS batch processor
@@ -77,51 +93,106 @@ func NewSQSBatchProcessor(logger *slog.Logger) *SQSBatchProcessor {
 		logger = slog.Default()
 	}
 
-	return &SQSBatchProcessor{
-		logger: logger,
+	p := &SQSBatchProcessor{
+		processor: sqsbatch.NewProcessor[*models.Message](logger),
+		logger:    logger,
 	}
+	p.processor.SetValidator(p.checkMessageType)
+	p.processor.SetSuccessHook(p.recordLatency)
+	p.processor.SetLogAttrs(func(message *models.Message) []any {
+		return []any{
+			slog.String("message_id", message.ID),
+			slog.String("message_type", message.MessageType.String()),
+			slog.String(logging.CorrelationIDAttribute, message.CorrelationID),
+		}
+	})
+	return p
 }
 
-// ProcessBatch processes a batch of SQS messages
-func (p *SQSBatchProcessor) ProcessBatch(ctx context.Context, event events.SQSEvent, handler func(context.Context, *models.Message) error) (events.SQSEventResponse, error) {
-	response := events.SQSEventResponse{
-		BatchItemFailures: []events.SQSBatchItemFailure{},
+// SetMessageTypeRegistry rejects messages whose type is unknown or not bound to consumer
+func (p *SQSBatchProcessor) SetMessageTypeRegistry(registry *models.MessageTypeRegistry, consumer string) {
+	p.registry = registry
+	p.consumer = consumer
+}
+
+// SetLatencyRecorder records enqueue-to-completion latency for every successfully handled message
+func (p *SQSBatchProcessor) SetLatencyRecorder(recorder *metrics.LatencyRecorder) {
+	p.latency = recorder
+}
+
+// SetPermanentFailureHandler reports messages that fail on their last delivery before SQS moves them to the DLQ
+func (p *SQSBatchProcessor) SetPermanentFailureHandler(maxReceiveCount int, handler PermanentFailureHandler) {
+	p.processor.SetPermanentFailureHandler(maxReceiveCount, handler)
+}
+
+// SetRecordTimeout fails a message, for redelivery, when its handler runs longer than timeout
+func (p *SQSBatchProcessor) SetRecordTimeout(timeout time.Duration) {
+	p.processor.SetRecordTimeout(timeout)
+}
+
+// SetConcurrency handles up to n messages of a batch at once; 1, the default, keeps queue order
+func (p *SQSBatchProcessor) SetConcurrency(n int) {
+	p.processor.SetConcurrency(n)
+}
+
+// SetRetryPolicy fails messages for good, without a redelivery, when retryable rejects the handler's error
+func (p *SQSBatchProcessor) SetRetryPolicy(retryable func(err error) bool) {
+	p.processor.SetRetryPolicy(retryable)
+}
+
+// SetEventPublisher publishes a ScheduleTriggered domain event for every message a schedule sends
+func (p *SQSBatchProcessor) SetEventPublisher(publisher *EventBridgePublisher) {
+	p.events = publisher
+}
+
+// checkMessageType verifies the message belongs to this consumer and carries a valid payload
+func (p *SQSBatchProcessor) checkMessageType(message *models.Message) error {
+	if p.registry == nil {
+		return nil
 	}
+	if err := p.registry.ValidateConsume(message, p.consumer); err != nil {
+		return err
+	}
+	return p.registry.ValidatePayload(message)
+}
 
-	messages, err := ParseSQSEvent(event, p.logger)
+// ProcessBatch processes a batch of SQS messages, handling each with its correlation ID in the
+// context so that everything the handler logs, saves, or publishes carries it
+func (p *SQSBatchProcessor) ProcessBatch(ctx context.Context, event events.SQSEvent, handler func(context.Context, *models.Message) error) (events.SQSEventResponse, error) {
+	response, err := p.processor.ProcessBatch(ctx, event, func(ctx context.Context, message *models.Message) error {
+		// Schedules publish straight to SNS, so a scheduled message starts its journey here
+		scheduled := message.CorrelationID == ""
+		if scheduled {
+			message.CorrelationID = logging.NewCorrelationID()
+		}
+		ctx = logging.WithCorrelationID(ctx, message.CorrelationID)
+		if scheduleID, ok := message.Arguments["schedule_id"].(string); ok && scheduled && scheduleID != "" {
+			p.events.Publish(ctx, ScheduleTriggered(scheduleID, message))
+		}
+		return handler(ctx, message)
+	})
 	if err != nil {
 		p.logger.ErrorContext(ctx, "failed to parse SQS event", slog.String("error", err.Error()))
-		// Mark all messages as failed
-		for _, record := range event.Records {
-			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{
-				ItemIdentifier: record.MessageId,
-			})
-		}
-		return response, err
 	}
+	return response, err
+}
 
-	// Process each message
-	for i, message := range messages {
-		record := event.Records[i]
-
-		err := handler(ctx, message)
-		if err != nil {
-			p.logger.ErrorContext(ctx, "failed to process message",
-				slog.String("message_id", message.ID),
-				slog.String("sqs_message_id", record.MessageId),
-				slog.String("error", err.Error()),
-			)
-			// Add to batch item failures for retry
-			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{
-				ItemIdentifier: record.MessageId,
-			})
-		} else {
-			p.logger.DebugContext(ctx, "successfully processed message",
-				slog.String("message_id", message.ID),
-				slog.String("sqs_message_id", record.MessageId),
-			)
-		}
+// recordLatency emits the message's end-to-end latency; failures never fail the message
+func (p *SQSBatchProcessor) recordLatency(ctx context.Context, message *models.Message) {
+	if p.latency == nil {
+		return
 	}
-
-	return response, nil
+	latency, err := p.latency.Record(message)
+	if err != nil {
+		p.logger.WarnContext(ctx, "failed to record message latency",
+			slog.String("message_id", message.ID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	p.logger.DebugContext(ctx, "message latency recorded",
+		slog.String("message_id", message.ID),
+		slog.String("message_type", message.MessageType.String()),
+		slog.Duration("latency", latency),
+	)
 }
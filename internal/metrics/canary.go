@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CanaryNamespace is the CloudWatch namespace for booking canary metrics
+const CanaryNamespace = "RezAgent/Canary"
+
+// Metric names emitted by CanaryRecorder
+const (
+	MetricCanaryRuns         = "CanaryRuns"
+	MetricCanaryFailures     = "CanaryFailures"
+	MetricCanaryStepDuration = "CanaryStepDuration"
+	MetricCanaryStepFailures = "CanaryStepFailures"
+)
+
+// CanaryRecorder emits the outcome and per-step latency of booking canary runs as CloudWatch
+// Embedded Metric Format log lines
+type CanaryRecorder struct {
+	mu     sync.Mutex
+	out    io.Writer
+	stage  string
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewCanaryRecorder creates a recorder that writes EMF records to out
+func NewCanaryRecorder(out io.Writer, stage string, logger *slog.Logger) *CanaryRecorder {
+	return &CanaryRecorder{
+		out:    out,
+		stage:  stage,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// RecordStep emits one step's latency and whether it failed, by Stage and Step
+func (r *CanaryRecorder) RecordStep(ctx context.Context, courseID int, step string, duration time.Duration, failed bool) {
+	r.write(ctx, map[string]interface{}{
+		"Step":                   step,
+		"course_id":              strconv.Itoa(courseID),
+		MetricCanaryStepDuration: duration.Milliseconds(),
+		MetricCanaryStepFailures: count(failed),
+	}, []string{"Stage", "Step"}, []emfMetric{
+		{Name: MetricCanaryStepDuration, Unit: "Milliseconds"},
+		{Name: MetricCanaryStepFailures, Unit: "Count"},
+	})
+}
+
+// RecordRun emits one course's run and whether it failed, by Stage; the canary alarm watches
+// CanaryFailures
+func (r *CanaryRecorder) RecordRun(ctx context.Context, courseID int, failed bool) {
+	r.write(ctx, map[string]interface{}{
+		"course_id":          strconv.Itoa(courseID),
+		MetricCanaryRuns:     1,
+		MetricCanaryFailures: count(failed),
+	}, []string{"Stage"}, []emfMetric{
+		{Name: MetricCanaryRuns, Unit: "Count"},
+		{Name: MetricCanaryFailures, Unit: "Count"},
+	})
+}
+
+// write adds the stage and EMF directive to record and writes it; a failure is logged, never
+// returned, so metrics cannot fail a canary run
+func (r *CanaryRecorder) write(ctx context.Context, record map[string]interface{}, dimensions []string, metrics []emfMetric) {
+	record["Stage"] = r.stage
+	record["_aws"] = map[string]interface{}{
+		"Timestamp": r.now().UnixMilli(),
+		"CloudWatchMetrics": []emfDirective{{
+			Namespace:  CanaryNamespace,
+			Dimensions: [][]string{dimensions},
+			Metrics:    metrics,
+		}},
+	}
+
+	line, err := json.Marshal(record)
+	if err == nil {
+		r.mu.Lock()
+		_, err = fmt.Fprintln(r.out, string(line))
+		r.mu.Unlock()
+	}
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to record canary metric", slog.String("error", err.Error()))
+	}
+}
+
+// count is 1 when b is true and 0 otherwise
+func count(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanaryRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewCanaryRecorder(&buf, "prod", slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	recorder.RecordStep(context.Background(), 1, "lock", 850*time.Millisecond, true)
+	recorder.RecordRun(context.Background(), 1, true)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrote %d records, want 2", len(lines))
+	}
+	var step, run map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &step); err != nil {
+		t.Fatalf("step record is not JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &run); err != nil {
+		t.Fatalf("run record is not JSON: %v", err)
+	}
+
+	if step["Step"] != "lock" || step["Stage"] != "prod" || step[MetricCanaryStepDuration] != float64(850) || step[MetricCanaryStepFailures] != float64(1) {
+		t.Errorf("step record = %v, want the failed 850ms lock step in prod", step)
+	}
+	if run[MetricCanaryRuns] != float64(1) || run[MetricCanaryFailures] != float64(1) || run["course_id"] != "1" {
+		t.Errorf("run record = %v, want one failed run of course 1", run)
+	}
+	directive := run["_aws"].(map[string]interface{})["CloudWatchMetrics"].([]interface{})[0].(map[string]interface{})
+	if directive["Namespace"] != CanaryNamespace {
+		t.Errorf("Namespace = %v, want %s", directive["Namespace"], CanaryNamespace)
+	}
+}
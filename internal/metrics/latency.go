@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// Namespace is the CloudWatch namespace for pipeline metrics
+const Namespace = "RezAgent/Pipeline"
+
+// Metric names emitted by LatencyRecorder
+const (
+	MetricEndToEndLatency = "EndToEndLatency"
+	MetricSLOEvents       = "SLOEvents"
+	MetricSLOGoodEvents   = "SLOGoodEvents"
+)
+
+// LatencyRecorder emits enqueue-to-completion latency for each message as CloudWatch
+// Embedded Metric Format log lines, so Lambda publishes the metrics without API calls
+type LatencyRecorder struct {
+	mu    sync.Mutex
+	out   io.Writer
+	stage string
+	slos  map[models.MessageType]SLO
+	now   func() time.Time
+}
+
+// NewLatencyRecorder creates a recorder that writes EMF records to out
+func NewLatencyRecorder(out io.Writer, stage string, slos map[models.MessageType]SLO) *LatencyRecorder {
+	return &LatencyRecorder{
+		out:   out,
+		stage: stage,
+		slos:  slos,
+		now:   time.Now,
+	}
+}
+
+// emfMetric is a metric definition in an EMF directive
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+// emfDirective tells CloudWatch which fields of the record are metrics
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+// Record emits the latency of a completed message and whether it met its SLO
+func (r *LatencyRecorder) Record(message *models.Message) (time.Duration, error) {
+	now := r.now()
+	latency := now.Sub(message.CreatedDate)
+	if latency < 0 {
+		latency = 0
+	}
+
+	directive := emfDirective{
+		Namespace:  Namespace,
+		Dimensions: [][]string{{"Stage", "MessageType"}},
+		Metrics:    []emfMetric{{Name: MetricEndToEndLatency, Unit: "Milliseconds"}},
+	}
+	record := map[string]interface{}{
+		"Stage":               r.stage,
+		"MessageType":         message.MessageType.String(),
+		"message_id":          message.ID,
+		MetricEndToEndLatency: latency.Milliseconds(),
+	}
+
+	if slo, ok := r.slos[message.MessageType]; ok {
+		good := 0
+		if slo.Met(latency) {
+			good = 1
+		}
+		directive.Metrics = append(directive.Metrics,
+			emfMetric{Name: MetricSLOEvents, Unit: "Count"},
+			emfMetric{Name: MetricSLOGoodEvents, Unit: "Count"},
+		)
+		record[MetricSLOEvents] = 1
+		record[MetricSLOGoodEvents] = good
+		record["slo_target_ms"] = slo.Target.Milliseconds()
+	}
+
+	record["_aws"] = map[string]interface{}{
+		"Timestamp":         now.UnixMilli(),
+		"CloudWatchMetrics": []emfDirective{directive},
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return latency, fmt.Errorf("failed to marshal latency metric: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := fmt.Fprintln(r.out, string(line)); err != nil {
+		return latency, fmt.Errorf("failed to write latency metric: %w", err)
+	}
+	return latency, nil
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+func TestLatencyRecorder_Record(t *testing.T) {
+	created := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		messageType models.MessageType
+		elapsed     time.Duration
+		wantGood    interface{}
+	}{
+		{"notification within SLO", models.MessageTypeNotification, 10 * time.Second, float64(1)},
+		{"notification over SLO", models.MessageTypeNotification, 45 * time.Second, float64(0)},
+		{"booking within SLO", models.MessageTypeWebAction, 2 * time.Minute, float64(1)},
+		{"type without SLO", models.MessageTypeAgentResponse, time.Minute, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			recorder := NewLatencyRecorder(&buf, "dev", DefaultSLOs)
+			recorder.now = func() time.Time { return created.Add(tt.elapsed) }
+
+			msg := &models.Message{ID: "msg_1", MessageType: tt.messageType, CreatedDate: created}
+			latency, err := recorder.Record(msg)
+			if err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+			if latency != tt.elapsed {
+				t.Errorf("latency = %v, want %v", latency, tt.elapsed)
+			}
+
+			var record map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("record is not JSON: %v", err)
+			}
+			if _, ok := record["_aws"]; !ok {
+				t.Error("record is missing the EMF _aws directive")
+			}
+			if got := record[MetricEndToEndLatency]; got != float64(tt.elapsed.Milliseconds()) {
+				t.Errorf("%s = %v, want %d", MetricEndToEndLatency, got, tt.elapsed.Milliseconds())
+			}
+			if got := record[MetricSLOGoodEvents]; got != tt.wantGood {
+				t.Errorf("%s = %v, want %v", MetricSLOGoodEvents, got, tt.wantGood)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the Content-Type of the Prometheus text exposition format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PrometheusGauge is the metric type of a value that can go up and down
+const PrometheusGauge = "gauge"
+
+// PrometheusSample is one labelled value of a metric family
+type PrometheusSample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// PrometheusFamily is a metric with its help text, type and samples
+type PrometheusFamily struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []PrometheusSample
+}
+
+// WritePrometheus renders families in the Prometheus text exposition format. Labels are written in
+// sorted order and samples keep the order given, so the output is stable for identical input.
+func WritePrometheus(w io.Writer, families []PrometheusFamily) error {
+	var b strings.Builder
+	for _, family := range families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			b.WriteString(family.Name)
+			writeLabels(&b, sample.Labels)
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatFloat(sample.Value, 'g', -1, 64))
+			b.WriteByte('\n')
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeLabels writes {name="value",...} with names in sorted order; nothing when there are no labels
+func writeLabels(b *strings.Builder, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(b, "%s=\"%s\"", name, escapeLabelValue(labels[name]))
+	}
+	b.WriteByte('}')
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// escapeHelp escapes a HELP line as the exposition format requires
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+// escapeLabelValue escapes a label value as the exposition format requires
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
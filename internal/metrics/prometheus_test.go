@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	families := []PrometheusFamily{
+		{
+			Name: "rez_agent_messages",
+			Help: "Messages by status and type",
+			Type: PrometheusGauge,
+			Samples: []PrometheusSample{
+				{Labels: map[string]string{"type": "notify", "status": "completed"}, Value: 12},
+				{Labels: map[string]string{"type": "web_action", "status": "failed"}, Value: 1},
+			},
+		},
+		{
+			Name:    "rez_agent_last_run_timestamp_seconds",
+			Help:    "Start of the last run\nin Unix seconds",
+			Type:    PrometheusGauge,
+			Samples: []PrometheusSample{{Value: 1.7e9}},
+		},
+		{
+			Name:    "rez_agent_schedules",
+			Help:    "Schedules",
+			Type:    PrometheusGauge,
+			Samples: []PrometheusSample{{Labels: map[string]string{"name": "say \"hi\"\\now"}, Value: 0.5}},
+		},
+	}
+
+	var b strings.Builder
+	if err := WritePrometheus(&b, families); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+
+	want := `# HELP rez_agent_messages Messages by status and type
+# TYPE rez_agent_messages gauge
+rez_agent_messages{status="completed",type="notify"} 12
+rez_agent_messages{status="failed",type="web_action"} 1
+# HELP rez_agent_last_run_timestamp_seconds Start of the last run\nin Unix seconds
+# TYPE rez_agent_last_run_timestamp_seconds gauge
+rez_agent_last_run_timestamp_seconds 1.7e+09
+# HELP rez_agent_schedules Schedules
+# TYPE rez_agent_schedules gauge
+rez_agent_schedules{name="say \"hi\"\\now"} 0.5
+`
+	if got := b.String(); got != want {
+		t.Errorf("WritePrometheus() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Queue is a work queue reported by the Prometheus endpoint, named like its CloudWatch alarms
+type Queue struct {
+	Name string
+	URL  string
+}
+
+// QueueStats are a queue's approximate message counts. SQS reports them within about a minute; the
+// age of the oldest message is only published to CloudWatch, where the backlog alarms watch it.
+type QueueStats struct {
+	Queue    string
+	Visible  int // Waiting to be received
+	InFlight int // Received and not yet deleted or returned to the queue
+	Delayed  int // Not yet visible because of a delivery delay
+}
+
+// queueAttributesGetter reads queue attributes; satisfied by *sqs.Client
+type queueAttributesGetter interface {
+	GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
+}
+
+// SQSQueueStats reads queue message counts through the SQS API
+type SQSQueueStats struct {
+	client queueAttributesGetter
+}
+
+// NewSQSQueueStats creates a queue stats reader
+func NewSQSQueueStats(client *sqs.Client) *SQSQueueStats {
+	return &SQSQueueStats{client: client}
+}
+
+// Stats returns the queue's approximate message counts
+func (s *SQSQueueStats) Stats(ctx context.Context, queue Queue) (QueueStats, error) {
+	visible := sqstypes.QueueAttributeNameApproximateNumberOfMessages
+	inFlight := sqstypes.QueueAttributeNameApproximateNumberOfMessagesNotVisible
+	delayed := sqstypes.QueueAttributeNameApproximateNumberOfMessagesDelayed
+
+	out, err := s.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queue.URL),
+		AttributeNames: []sqstypes.QueueAttributeName{visible, inFlight, delayed},
+	})
+	if err != nil {
+		return QueueStats{}, fmt.Errorf("failed to get attributes of queue %s: %w", queue.Name, err)
+	}
+
+	count := func(name sqstypes.QueueAttributeName) int {
+		n, _ := strconv.Atoi(out.Attributes[string(name)])
+		return n
+	}
+	return QueueStats{
+		Queue:    queue.Name,
+		Visible:  count(visible),
+		InFlight: count(inFlight),
+		Delayed:  count(delayed),
+	}, nil
+}
+
+// QueueFamilies renders queue message counts as Prometheus gauges, one sample per queue
+func QueueFamilies(stats []QueueStats) []PrometheusFamily {
+	visible := PrometheusFamily{
+		Name: "rez_agent_queue_messages_visible",
+		Help: "Approximate messages waiting in a work queue",
+		Type: PrometheusGauge,
+	}
+	inFlight := PrometheusFamily{
+		Name: "rez_agent_queue_messages_in_flight",
+		Help: "Approximate messages of a work queue being processed",
+		Type: PrometheusGauge,
+	}
+	delayed := PrometheusFamily{
+		Name: "rez_agent_queue_messages_delayed",
+		Help: "Approximate messages of a work queue not yet delivered",
+		Type: PrometheusGauge,
+	}
+	for _, s := range stats {
+		labels := map[string]string{"queue": s.Queue}
+		visible.Samples = append(visible.Samples, PrometheusSample{Labels: labels, Value: float64(s.Visible)})
+		inFlight.Samples = append(inFlight.Samples, PrometheusSample{Labels: labels, Value: float64(s.InFlight)})
+		delayed.Samples = append(delayed.Samples, PrometheusSample{Labels: labels, Value: float64(s.Delayed)})
+	}
+	return []PrometheusFamily{visible, inFlight, delayed}
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// fakeQueueAttributes answers GetQueueAttributes from fixed attributes per queue URL
+type fakeQueueAttributes map[string]map[string]string
+
+func (f fakeQueueAttributes) GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error) {
+	attributes, ok := f[aws.ToString(params.QueueUrl)]
+	if !ok {
+		return nil, errors.New("queue does not exist")
+	}
+	return &sqs.GetQueueAttributesOutput{Attributes: attributes}, nil
+}
+
+func TestSQSQueueStats_Stats(t *testing.T) {
+	reader := &SQSQueueStats{client: fakeQueueAttributes{
+		"https://sqs/web-actions": {
+			"ApproximateNumberOfMessages":           "42",
+			"ApproximateNumberOfMessagesNotVisible": "3",
+			"ApproximateNumberOfMessagesDelayed":    "1",
+		},
+	}}
+
+	stats, err := reader.Stats(context.Background(), Queue{Name: "web-actions", URL: "https://sqs/web-actions"})
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats != (QueueStats{Queue: "web-actions", Visible: 42, InFlight: 3, Delayed: 1}) {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	if _, err := reader.Stats(context.Background(), Queue{Name: "missing", URL: "https://sqs/missing"}); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Stats() error = %v, want one naming the queue", err)
+	}
+}
+
+func TestQueueFamilies(t *testing.T) {
+	var b strings.Builder
+	err := WritePrometheus(&b, QueueFamilies([]QueueStats{
+		{Queue: "web-actions", Visible: 42, InFlight: 3},
+		{Queue: "notifications"},
+	}))
+	if err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	for _, line := range []string{
+		`rez_agent_queue_messages_visible{queue="web-actions"} 42`,
+		`rez_agent_queue_messages_in_flight{queue="web-actions"} 3`,
+		`rez_agent_queue_messages_delayed{queue="notifications"} 0`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("output missing %q:\n%s", line, b.String())
+		}
+	}
+}
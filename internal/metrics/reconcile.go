@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Metric names emitted by ReconcileRecorder
+const (
+	MetricScheduleDiscrepancies     = "ScheduleDiscrepancies"
+	MetricScheduleRepairs           = "ScheduleRepairs"
+	MetricScheduleDiscrepanciesOpen = "ScheduleDiscrepanciesOpen"
+)
+
+// ReconcileRecorder emits what each schedule reconciliation found as CloudWatch Embedded Metric
+// Format log lines, in the pipeline namespace
+type ReconcileRecorder struct {
+	mu     sync.Mutex
+	out    io.Writer
+	stage  string
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewReconcileRecorder creates a recorder that writes EMF records to out
+func NewReconcileRecorder(out io.Writer, stage string, logger *slog.Logger) *ReconcileRecorder {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &ReconcileRecorder{
+		out:    out,
+		stage:  stage,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Record emits how many discrepancies of kind a reconciliation found, how many it repaired and
+// how many are left open, by Stage and Kind and by Stage alone for alarms. A failure is logged,
+// never returned, so metrics cannot fail a reconciliation.
+func (r *ReconcileRecorder) Record(ctx context.Context, kind string, found, repaired int) {
+	record := map[string]interface{}{
+		"Stage":                         r.stage,
+		"Kind":                          kind,
+		MetricScheduleDiscrepancies:     found,
+		MetricScheduleRepairs:           repaired,
+		MetricScheduleDiscrepanciesOpen: found - repaired,
+		"_aws": map[string]interface{}{
+			"Timestamp": r.now().UnixMilli(),
+			"CloudWatchMetrics": []emfDirective{{
+				Namespace:  Namespace,
+				Dimensions: [][]string{{"Stage", "Kind"}, {"Stage"}},
+				Metrics: []emfMetric{
+					{Name: MetricScheduleDiscrepancies, Unit: "Count"},
+					{Name: MetricScheduleRepairs, Unit: "Count"},
+					{Name: MetricScheduleDiscrepanciesOpen, Unit: "Count"},
+				},
+			}},
+		},
+	}
+
+	line, err := json.Marshal(record)
+	if err == nil {
+		r.mu.Lock()
+		_, err = fmt.Fprintln(r.out, string(line))
+		r.mu.Unlock()
+	}
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to record reconciliation metric", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestReconcileRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	NewReconcileRecorder(&buf, "prod", nil).Record(context.Background(), "drifted", 3, 2)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if record["Stage"] != "prod" || record["Kind"] != "drifted" {
+		t.Errorf("dimensions = %v/%v, want prod/drifted", record["Stage"], record["Kind"])
+	}
+	if record[MetricScheduleDiscrepancies] != float64(3) || record[MetricScheduleRepairs] != float64(2) || record[MetricScheduleDiscrepanciesOpen] != float64(1) {
+		t.Errorf("record = %v, want 3 found, 2 repaired and 1 open", record)
+	}
+}
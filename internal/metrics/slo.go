@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// SLO is a latency objective: Objective of messages should complete within Target of being enqueued
+type SLO struct {
+	Target    time.Duration
+	Objective float64
+}
+
+// ErrorBudget is the fraction of messages allowed to miss the target
+func (s SLO) ErrorBudget() float64 {
+	return 1 - s.Objective
+}
+
+// Met reports whether a single message's latency met the target
+func (s SLO) Met(latency time.Duration) bool {
+	return latency <= s.Target
+}
+
+// BurnRate is how fast the error budget is being spent given good and total event counts;
+// 1.0 spends the budget exactly over the SLO window
+func (s SLO) BurnRate(good, total int) float64 {
+	if total == 0 || s.ErrorBudget() <= 0 {
+		return 0
+	}
+	badRatio := float64(total-good) / float64(total)
+	return badRatio / s.ErrorBudget()
+}
+
+// DefaultSLOs are the end-to-end latency objectives per message type; keep the burn-rate
+// alarms in infrastructure/main.go in sync
+var DefaultSLOs = map[models.MessageType]SLO{
+	models.MessageTypeNotification:     {Target: 30 * time.Second, Objective: 0.95},
+	models.MessageTypeHelloWorld:       {Target: 30 * time.Second, Objective: 0.95},
+	models.MessageTypeWebAction:        {Target: 3 * time.Minute, Objective: 0.95},
+	models.MessageTypeScheduleCreation: {Target: time.Minute, Objective: 0.95},
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSLO_BurnRate(t *testing.T) {
+	slo := SLO{Target: 30 * time.Second, Objective: 0.95}
+
+	tests := []struct {
+		name  string
+		good  int
+		total int
+		want  float64
+	}{
+		{"no events", 0, 0, 0},
+		{"all good", 100, 100, 0},
+		{"exactly on budget", 95, 100, 1},
+		{"twice the budget", 90, 100, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slo.BurnRate(tt.good, tt.total); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("BurnRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
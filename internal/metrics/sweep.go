@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// Metric names emitted by SweepRecorder
+const (
+	MetricStuckMessages       = "StuckMessages"
+	MetricMessagesRedriven    = "MessagesRedriven"
+	MetricStuckMessagesFailed = "StuckMessagesFailed"
+)
+
+// SweepRecorder emits what each stuck message sweep found as CloudWatch Embedded Metric Format
+// log lines, in the pipeline namespace
+type SweepRecorder struct {
+	mu     sync.Mutex
+	out    io.Writer
+	stage  string
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewSweepRecorder creates a recorder that writes EMF records to out
+func NewSweepRecorder(out io.Writer, stage string, logger *slog.Logger) *SweepRecorder {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SweepRecorder{
+		out:    out,
+		stage:  stage,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Record emits how many messages a sweep found stuck in status and how many of them it re-drove
+// and failed, by Stage and Status and by Stage alone for alarms. A failure is logged, never returned, so metrics cannot fail a
+// sweep.
+func (r *SweepRecorder) Record(ctx context.Context, status models.Status, stuck, redriven, failed int) {
+	record := map[string]interface{}{
+		"Stage":                   r.stage,
+		"Status":                  status.String(),
+		MetricStuckMessages:       stuck,
+		MetricMessagesRedriven:    redriven,
+		MetricStuckMessagesFailed: failed,
+		"_aws": map[string]interface{}{
+			"Timestamp": r.now().UnixMilli(),
+			"CloudWatchMetrics": []emfDirective{{
+				Namespace:  Namespace,
+				Dimensions: [][]string{{"Stage", "Status"}, {"Stage"}},
+				Metrics: []emfMetric{
+					{Name: MetricStuckMessages, Unit: "Count"},
+					{Name: MetricMessagesRedriven, Unit: "Count"},
+					{Name: MetricStuckMessagesFailed, Unit: "Count"},
+				},
+			}},
+		},
+	}
+
+	line, err := json.Marshal(record)
+	if err == nil {
+		r.mu.Lock()
+		_, err = fmt.Fprintln(r.out, string(line))
+		r.mu.Unlock()
+	}
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to record sweep metric", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+func TestSweepRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	NewSweepRecorder(&buf, "prod", nil).Record(context.Background(), models.StatusQueued, 3, 2, 1)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if record["Stage"] != "prod" || record["Status"] != "queued" {
+		t.Errorf("dimensions = %v/%v, want prod/queued", record["Stage"], record["Status"])
+	}
+	if record[MetricStuckMessages] != float64(3) || record[MetricMessagesRedriven] != float64(2) || record[MetricStuckMessagesFailed] != float64(1) {
+		t.Errorf("record = %v, want 3 stuck, 2 re-driven and 1 failed", record)
+	}
+	directive := record["_aws"].(map[string]interface{})["CloudWatchMetrics"].([]interface{})[0].(map[string]interface{})
+	if directive["Namespace"] != Namespace {
+		t.Errorf("Namespace = %v, want %s", directive["Namespace"], Namespace)
+	}
+}
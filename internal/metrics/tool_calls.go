@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ToolNamespace is the CloudWatch namespace for MCP tool call metrics
+const ToolNamespace = "RezAgent/MCP"
+
+// Metric names emitted by ToolCallRecorder
+const (
+	MetricToolCalls        = "ToolCalls"
+	MetricToolCallErrors   = "ToolCallErrors"
+	MetricToolCallDuration = "ToolCallDuration"
+)
+
+// ToolCallRecorder emits the count, failures, and duration of MCP tool calls as CloudWatch
+// Embedded Metric Format log lines
+type ToolCallRecorder struct {
+	mu     sync.Mutex
+	out    io.Writer
+	stage  string
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewToolCallRecorder creates a recorder that writes EMF records to out
+func NewToolCallRecorder(out io.Writer, stage string, logger *slog.Logger) *ToolCallRecorder {
+	return &ToolCallRecorder{
+		out:    out,
+		stage:  stage,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// RecordToolCall emits one tool call's metrics; a failure to write them is logged, never
+// returned, so metrics cannot fail a tool call
+func (r *ToolCallRecorder) RecordToolCall(ctx context.Context, name string, duration time.Duration, failed bool) {
+	errorCount := 0
+	if failed {
+		errorCount = 1
+	}
+
+	record := map[string]interface{}{
+		"Stage":                r.stage,
+		"ToolName":             name,
+		MetricToolCalls:        1,
+		MetricToolCallErrors:   errorCount,
+		MetricToolCallDuration: duration.Milliseconds(),
+		"_aws": map[string]interface{}{
+			"Timestamp": r.now().UnixMilli(),
+			"CloudWatchMetrics": []emfDirective{{
+				Namespace:  ToolNamespace,
+				Dimensions: [][]string{{"Stage", "ToolName"}},
+				Metrics: []emfMetric{
+					{Name: MetricToolCalls, Unit: "Count"},
+					{Name: MetricToolCallErrors, Unit: "Count"},
+					{Name: MetricToolCallDuration, Unit: "Milliseconds"},
+				},
+			}},
+		},
+	}
+
+	line, err := json.Marshal(record)
+	if err == nil {
+		r.mu.Lock()
+		_, err = fmt.Fprintln(r.out, string(line))
+		r.mu.Unlock()
+	}
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to record tool call metric",
+			slog.String("tool_name", name),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestToolCallRecorder_RecordToolCall(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewToolCallRecorder(&buf, "dev", slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	recorder.RecordToolCall(context.Background(), "golf_book_tee_time", 1500*time.Millisecond, true)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if _, ok := record["_aws"]; !ok {
+		t.Error("record is missing the EMF _aws directive")
+	}
+	if record["ToolName"] != "golf_book_tee_time" || record["Stage"] != "dev" {
+		t.Errorf("dimensions = %v / %v, want golf_book_tee_time / dev", record["ToolName"], record["Stage"])
+	}
+	if record[MetricToolCallDuration] != float64(1500) || record[MetricToolCallErrors] != float64(1) {
+		t.Errorf("%s = %v, %s = %v; want 1500 and 1", MetricToolCallDuration, record[MetricToolCallDuration], MetricToolCallErrors, record[MetricToolCallErrors])
+	}
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AgentSession is an interactive chat session stored by the agent Lambda
+type AgentSession struct {
+	// SessionID is the client-generated session identifier
+	SessionID string `json:"session_id" dynamodbav:"session_id"`
+
+	// CreatedAt and UpdatedAt are ISO 8601 timestamps written by the agent
+	CreatedAt string `json:"created_at,omitempty" dynamodbav:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty" dynamodbav:"updated_at,omitempty"`
+
+	// Messages is the conversation history as role/content pairs
+	Messages []map[string]interface{} `json:"messages" dynamodbav:"messages"`
+
+	// ApprovedTools are the consent-gated tools the user approved for the session
+	ApprovedTools []string `json:"approved_tools,omitempty" dynamodbav:"approved_tools,omitempty"`
+
+	// TTL removes the session once it has been idle for the window's TTL (Unix seconds)
+	TTL int64 `json:"-" dynamodbav:"ttl,omitempty"`
+}
+
+// AgentSessionWindow bounds how much conversation history a session keeps. The oldest messages
+// are dropped first, so the model always sees the most recent turns.
+type AgentSessionWindow struct {
+	// MaxMessages is the most messages kept
+	MaxMessages int
+	// MaxBytes is the most JSON-encoded message bytes kept, well under DynamoDB's 400 KB item limit
+	MaxBytes int
+	// TTL is how long an idle session is kept; every append refreshes it
+	TTL time.Duration
+}
+
+// DefaultAgentSessionWindow is the window of a session store that does not set one
+var DefaultAgentSessionWindow = AgentSessionWindow{
+	MaxMessages: 50,
+	MaxBytes:    200 * 1024,
+	TTL:         7 * 24 * time.Hour,
+}
+
+// NewAgentSession creates an empty session
+func NewAgentSession(sessionID string, now time.Time) *AgentSession {
+	return &AgentSession{
+		SessionID: sessionID,
+		CreatedAt: now.UTC().Format(time.RFC3339),
+		Messages:  []map[string]interface{}{},
+	}
+}
+
+// IsExpired reports whether the session's TTL has passed. DynamoDB removes expired items lazily,
+// so readers treat an expired session as gone.
+func (s *AgentSession) IsExpired(now time.Time) bool {
+	return s.TTL > 0 && now.Unix() >= s.TTL
+}
+
+// Append adds messages to the session, replaces its approved tools when approvedTools is not nil,
+// trims it to the window and refreshes its TTL
+func (s *AgentSession) Append(messages []map[string]interface{}, approvedTools []string, window AgentSessionWindow, now time.Time) {
+	s.Messages = append(s.Messages, messages...)
+	if approvedTools != nil {
+		s.ApprovedTools = approvedTools
+	}
+	s.Trim(window)
+	s.Touch(window, now)
+}
+
+// Touch marks the session updated at now and pushes its expiry out to the window's TTL
+func (s *AgentSession) Touch(window AgentSessionWindow, now time.Time) {
+	s.UpdatedAt = now.UTC().Format(time.RFC3339)
+	if window.TTL > 0 {
+		s.TTL = now.Add(window.TTL).Unix()
+	}
+}
+
+// Trim drops the oldest messages until the session fits the window, then any leading
+// non-user messages, since a conversation must open with the user. It returns how many
+// messages were dropped.
+func (s *AgentSession) Trim(window AgentSessionWindow) int {
+	sizes := make([]int, len(s.Messages))
+	total := 0
+	for i, message := range s.Messages {
+		encoded, _ := json.Marshal(message)
+		sizes[i] = len(encoded)
+		total += sizes[i]
+	}
+
+	drop := 0
+	for drop < len(s.Messages) {
+		kept := len(s.Messages) - drop
+		overCount := window.MaxMessages > 0 && kept > window.MaxMessages
+		overBytes := window.MaxBytes > 0 && total > window.MaxBytes
+		if !overCount && !overBytes {
+			break
+		}
+		total -= sizes[drop]
+		drop++
+	}
+	if drop > 0 {
+		for drop < len(s.Messages) && s.Messages[drop]["role"] != "user" {
+			drop++
+		}
+	}
+
+	s.Messages = append([]map[string]interface{}{}, s.Messages[drop:]...)
+	return drop
+}
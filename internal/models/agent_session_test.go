@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAgentSession_Trim(t *testing.T) {
+	message := func(role, content string) map[string]interface{} {
+		return map[string]interface{}{"role": role, "content": content}
+	}
+	conversation := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			message("user", "find me a tee time"),
+			message("assistant", "which day?"),
+			message("user", "saturday"),
+			message("assistant", strings.Repeat("x", 500)),
+			message("user", "book it"),
+			message("assistant", "booked"),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		window      AgentSessionWindow
+		wantDropped int
+		wantFirst   string
+	}{
+		{"fits", AgentSessionWindow{MaxMessages: 10, MaxBytes: 10000}, 0, "find me a tee time"},
+		{"message count", AgentSessionWindow{MaxMessages: 4}, 2, "saturday"},
+		// Dropping to three messages would open with the assistant, so its reply goes too
+		{"opens with the user", AgentSessionWindow{MaxMessages: 3}, 4, "book it"},
+		{"byte size", AgentSessionWindow{MaxBytes: 200}, 4, "book it"},
+		{"unbounded", AgentSessionWindow{}, 0, "find me a tee time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &AgentSession{Messages: conversation()}
+			if dropped := session.Trim(tt.window); dropped != tt.wantDropped {
+				t.Errorf("Trim() = %d, want %d", dropped, tt.wantDropped)
+			}
+			if got := session.Messages[0]["content"]; got != tt.wantFirst {
+				t.Errorf("first message = %q, want %q", got, tt.wantFirst)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+// AgentTaskCancellation records that another agent cancelled one of its chat tasks, kept in the
+// session table until no turn of the task could still be running
+type AgentTaskCancellation struct {
+	// ID is the item's session table key (see AgentTaskCancellationID)
+	ID string `json:"session_id" dynamodbav:"session_id"`
+
+	// TaskID is the agent_context.request_id of the cancelled chat request
+	TaskID string `json:"task_id" dynamodbav:"task_id"`
+
+	// CancelledAt is an ISO 8601 timestamp
+	CancelledAt string `json:"cancelled_at" dynamodbav:"cancelled_at"`
+
+	// TTL removes the record once the task can no longer be running (Unix seconds)
+	TTL int64 `json:"-" dynamodbav:"ttl,omitempty"`
+}
+
+// AgentTaskCancellationID is the session table key of a task's cancellation; the prefix keeps it
+// apart from the chat UI's session_ IDs
+func AgentTaskCancellationID(taskID string) string {
+	return "agent_task_" + taskID
+}
@@ -0,0 +1,20 @@
+package models
+
+import "fmt"
+
+// AgentUsage is the chat agent's Bedrock usage on one UTC day, kept in the messages table to
+// enforce its daily spending cap
+type AgentUsage struct {
+	ID           string  `json:"id" dynamodbav:"id"`
+	Date         string  `json:"date" dynamodbav:"date"`
+	TotalCost    float64 `json:"total_cost" dynamodbav:"total_cost"`
+	RequestCount int64   `json:"request_count" dynamodbav:"request_count"`
+	InputTokens  int64   `json:"input_tokens" dynamodbav:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens" dynamodbav:"output_tokens"`
+	LastUpdated  string  `json:"last_updated,omitempty" dynamodbav:"last_updated,omitempty"`
+}
+
+// AgentUsageID is the key of a stage's usage record for date (YYYY-MM-DD)
+func AgentUsageID(stage, date string) string {
+	return fmt.Sprintf("agent_usage_%s_%s", stage, date)
+}
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// ApprovalTimeout is how long a locked tee time waits for approval. The course releases
+// its lock on its own shortly after, so an unanswered approval simply lapses.
+const ApprovalTimeout = 5 * time.Minute
+
+// ApprovalStatus represents the state of a booking approval
+type ApprovalStatus string
+
+const (
+	// ApprovalStatusPending is waiting for the golfer's decision
+	ApprovalStatusPending ApprovalStatus = "pending"
+	// ApprovalStatusApproved was approved and the reservation is being completed
+	ApprovalStatusApproved ApprovalStatus = "approved"
+	// ApprovalStatusDeclined was declined and its lock released
+	ApprovalStatusDeclined ApprovalStatus = "declined"
+	// ApprovalStatusExpired timed out before a decision
+	ApprovalStatusExpired ApprovalStatus = "expired"
+	// ApprovalStatusCompleted was approved and reserved
+	ApprovalStatusCompleted ApprovalStatus = "completed"
+)
+
+// String returns the string representation of the approval status
+func (s ApprovalStatus) String() string {
+	return string(s)
+}
+
+// BookingApproval is a locked tee time waiting for the golfer to approve or decline the reservation
+type BookingApproval struct {
+	// ID is the unguessable approval token used in the approve/decline links
+	ID string `json:"id" dynamodbav:"id"`
+
+	CourseID        int     `json:"course_id" dynamodbav:"course_id"`
+	CourseName      string  `json:"course_name" dynamodbav:"course_name"`
+	TeeSheetID      int     `json:"tee_sheet_id" dynamodbav:"tee_sheet_id"`
+	TeeTime         string  `json:"tee_time" dynamodbav:"tee_time"`
+	NumberOfPlayers int     `json:"number_of_players" dynamodbav:"number_of_players"`
+	Total           float64 `json:"total" dynamodbav:"total"`
+
+	// SessionID and TransactionID identify the lock and pricing needed to complete the reservation
+	SessionID     string `json:"-" dynamodbav:"session_id"`
+	TransactionID string `json:"-" dynamodbav:"transaction_id"`
+
+	// SecretName is the golf credentials secret the tee time was locked with (empty for the shared credentials)
+	SecretName string `json:"-" dynamodbav:"secret_name,omitempty"`
+
+	// RSVPs tracks each member's answer in a group booking; empty for a single golfer's booking
+	RSVPs []GroupRSVP `json:"rsvps,omitempty" dynamodbav:"rsvps,omitempty"`
+
+	Status      ApprovalStatus `json:"status" dynamodbav:"status"`
+	CreatedDate time.Time      `json:"created_date" dynamodbav:"created_date"`
+	ExpiresAt   time.Time      `json:"expires_at" dynamodbav:"expires_at"`
+	DecidedAt   *time.Time     `json:"decided_at,omitempty" dynamodbav:"decided_at,omitempty"`
+
+	// TTL removes the record a week after it expires
+	TTL int64 `json:"-" dynamodbav:"ttl"`
+}
+
+// NewBookingApproval creates a pending approval for a locked and priced tee time
+func NewBookingApproval(courseID int, courseName string, teeSheetID int, teeTime string, numberOfPlayers int, total float64, sessionID, transactionID string) (*BookingApproval, error) {
+	if sessionID == "" || transactionID == "" {
+		return nil, fmt.Errorf("session_id and transaction_id are required")
+	}
+
+	token, err := newApprovalToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(ApprovalTimeout)
+	return &BookingApproval{
+		ID:              token,
+		CourseID:        courseID,
+		CourseName:      courseName,
+		TeeSheetID:      teeSheetID,
+		TeeTime:         teeTime,
+		NumberOfPlayers: numberOfPlayers,
+		Total:           total,
+		SessionID:       sessionID,
+		TransactionID:   transactionID,
+		Status:          ApprovalStatusPending,
+		CreatedDate:     now,
+		ExpiresAt:       expiresAt,
+		TTL:             expiresAt.AddDate(0, 0, 7).Unix(),
+	}, nil
+}
+
+// IsExpired reports whether the approval window has passed
+func (a *BookingApproval) IsExpired(now time.Time) bool {
+	return !now.Before(a.ExpiresAt)
+}
+
+// Decide returns the status a pending approval moves to for the given decision ("approve" or "decline")
+func (a *BookingApproval) Decide(decision string, now time.Time) (ApprovalStatus, error) {
+	if a.Status != ApprovalStatusPending {
+		return "", fmt.Errorf("approval is already %s", a.Status)
+	}
+	if a.IsExpired(now) {
+		return ApprovalStatusExpired, nil
+	}
+
+	switch decision {
+	case "approve":
+		return ApprovalStatusApproved, nil
+	case "decline":
+		return ApprovalStatusDeclined, nil
+	default:
+		return "", fmt.Errorf("decision must be approve or decline")
+	}
+}
+
+// InviteGroup adds a pending RSVP, with its own token, for each group member
+func (a *BookingApproval) InviteGroup(members []GroupMember) error {
+	if err := ValidateGroup(members); err != nil {
+		return err
+	}
+
+	rsvps := make([]GroupRSVP, 0, len(members))
+	for _, m := range members {
+		token, err := newApprovalToken()
+		if err != nil {
+			return err
+		}
+		rsvps = append(rsvps, GroupRSVP{Name: m.Name, Token: token, Status: RSVPStatusPending})
+	}
+	a.RSVPs = rsvps
+	return nil
+}
+
+// IsGroup reports whether the approval is a group booking waiting on RSVPs
+func (a *BookingApproval) IsGroup() bool {
+	return len(a.RSVPs) > 0
+}
+
+// RSVPIndex returns the index of the RSVP with the given token, or -1 if there is none
+func (a *BookingApproval) RSVPIndex(token string) int {
+	for i, r := range a.RSVPs {
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Token), []byte(token)) == 1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// RespondRSVP returns the status a pending member's RSVP moves to for the given decision ("accept" or "decline")
+func (a *BookingApproval) RespondRSVP(index int, decision string, now time.Time) (RSVPStatus, error) {
+	if a.Status != ApprovalStatusPending {
+		return "", fmt.Errorf("approval is already %s", a.Status)
+	}
+	if index < 0 || index >= len(a.RSVPs) {
+		return "", fmt.Errorf("rsvp not found")
+	}
+	if a.RSVPs[index].Status != RSVPStatusPending {
+		return "", fmt.Errorf("%s already %s", a.RSVPs[index].Name, a.RSVPs[index].Status)
+	}
+	if a.IsExpired(now) {
+		return "", fmt.Errorf("approval window has expired")
+	}
+
+	switch decision {
+	case "accept":
+		return RSVPStatusAccepted, nil
+	case "decline":
+		return RSVPStatusDeclined, nil
+	default:
+		return "", fmt.Errorf("decision must be accept or decline")
+	}
+}
+
+// AllResponded reports whether every group member has answered
+func (a *BookingApproval) AllResponded() bool {
+	for _, r := range a.RSVPs {
+		if r.Status == RSVPStatusPending {
+			return false
+		}
+	}
+	return true
+}
+
+// ConfirmedPlayers is the number of players to reserve for: everyone in the group who has not
+// declined, or the held player count for a single golfer's booking
+func (a *BookingApproval) ConfirmedPlayers() int {
+	if !a.IsGroup() {
+		return a.NumberOfPlayers
+	}
+
+	players := 0
+	for _, r := range a.RSVPs {
+		if r.Status != RSVPStatusDeclined {
+			players++
+		}
+	}
+	return players
+}
+
+// newApprovalToken returns a random hex token for approve/decline and RSVP links
+func newApprovalToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate approval token: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
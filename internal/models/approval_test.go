@@ -0,0 +1,120 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewBookingApproval(t *testing.T) {
+	a, err := NewBookingApproval(1, "Totteridge", 42, "2030-06-01T08:30:00", 2, 88.5, "session", "txn")
+	if err != nil {
+		t.Fatalf("NewBookingApproval() error = %v", err)
+	}
+	if len(a.ID) != 32 {
+		t.Errorf("ID length = %d, want 32", len(a.ID))
+	}
+	if a.Status != ApprovalStatusPending {
+		t.Errorf("Status = %s, want pending", a.Status)
+	}
+	if got := a.ExpiresAt.Sub(a.CreatedDate); got != ApprovalTimeout {
+		t.Errorf("expiry window = %v, want %v", got, ApprovalTimeout)
+	}
+
+	b, _ := NewBookingApproval(1, "Totteridge", 42, "2030-06-01T08:30:00", 2, 88.5, "session", "txn")
+	if a.ID == b.ID {
+		t.Error("approval tokens should be unique")
+	}
+
+	if _, err := NewBookingApproval(1, "Totteridge", 42, "2030-06-01T08:30:00", 2, 88.5, "", "txn"); err == nil {
+		t.Error("expected error without a session ID")
+	}
+}
+
+func TestBookingApproval_Decide(t *testing.T) {
+	created := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		status   ApprovalStatus
+		decision string
+		now      time.Time
+		want     ApprovalStatus
+		wantErr  bool
+	}{
+		{"approve", ApprovalStatusPending, "approve", created.Add(time.Minute), ApprovalStatusApproved, false},
+		{"decline", ApprovalStatusPending, "decline", created.Add(time.Minute), ApprovalStatusDeclined, false},
+		{"expired", ApprovalStatusPending, "approve", created.Add(ApprovalTimeout), ApprovalStatusExpired, false},
+		{"unknown decision", ApprovalStatusPending, "maybe", created.Add(time.Minute), "", true},
+		{"already decided", ApprovalStatusApproved, "approve", created.Add(time.Minute), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &BookingApproval{Status: tt.status, CreatedDate: created, ExpiresAt: created.Add(ApprovalTimeout)}
+			got, err := a.Decide(tt.decision, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decide() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Decide() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBookingApproval_GroupRSVPs(t *testing.T) {
+	a, err := NewBookingApproval(1, "Totteridge", 42, "2030-06-01T08:30:00", 3, 132, "session", "txn")
+	if err != nil {
+		t.Fatalf("NewBookingApproval() error = %v", err)
+	}
+	if a.IsGroup() || a.ConfirmedPlayers() != 3 {
+		t.Fatalf("a booking without a group should keep its %d players", a.NumberOfPlayers)
+	}
+
+	if err := a.InviteGroup([]GroupMember{{Name: "Alice", NtfyTopic: "alice"}, {Name: "Bob", NtfyTopic: "bob"}, {Name: "Carol", NtfyTopic: "carol"}}); err != nil {
+		t.Fatalf("InviteGroup() error = %v", err)
+	}
+	if a.RSVPs[0].Token == "" || a.RSVPs[0].Token == a.RSVPs[1].Token || a.RSVPs[0].Token == a.ID {
+		t.Error("each member should get a distinct RSVP token")
+	}
+	if got := a.RSVPIndex(a.RSVPs[1].Token); got != 1 {
+		t.Errorf("RSVPIndex() = %d, want 1", got)
+	}
+	if got := a.RSVPIndex("unknown"); got != -1 {
+		t.Errorf("RSVPIndex(unknown) = %d, want -1", got)
+	}
+	if got := a.RSVPIndex(""); got != -1 {
+		t.Errorf("RSVPIndex(\"\") = %d, want -1", got)
+	}
+
+	now := a.CreatedDate.Add(time.Minute)
+	if got, err := a.RespondRSVP(1, "decline", now); err != nil || got != RSVPStatusDeclined {
+		t.Fatalf("RespondRSVP(decline) = %q, %v", got, err)
+	}
+	if _, err := a.RespondRSVP(0, "maybe", now); err == nil {
+		t.Error("expected an error for an unknown decision")
+	}
+	if _, err := a.RespondRSVP(0, "accept", a.ExpiresAt); err == nil {
+		t.Error("expected an error after the approval window")
+	}
+
+	a.RSVPs[0].Status = RSVPStatusAccepted
+	a.RSVPs[1].Status = RSVPStatusDeclined
+	if _, err := a.RespondRSVP(1, "accept", now); err == nil {
+		t.Error("expected an error for a member who already answered")
+	}
+	if a.AllResponded() {
+		t.Error("AllResponded() = true with Carol still pending")
+	}
+	if got := a.ConfirmedPlayers(); got != 2 {
+		t.Errorf("ConfirmedPlayers() = %d, want 2 (pending members still count)", got)
+	}
+
+	a.RSVPs[2].Status = RSVPStatusDeclined
+	if !a.AllResponded() {
+		t.Error("AllResponded() = false after every member answered")
+	}
+	if got := a.ConfirmedPlayers(); got != 1 {
+		t.Errorf("ConfirmedPlayers() = %d, want 1", got)
+	}
+}
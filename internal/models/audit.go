@@ -0,0 +1,91 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// AuditAction is the kind of operation recorded in the audit log
+type AuditAction string
+
+const (
+	// AuditActionDataExport records an export of a user's data
+	AuditActionDataExport AuditAction = "data_export"
+
+	// AuditActionDataDeletion records a hard delete of a user's data
+	AuditActionDataDeletion AuditAction = "data_deletion"
+
+	// AuditActionScheduleCreation records an EventBridge schedule being created
+	AuditActionScheduleCreation AuditAction = "schedule_creation"
+
+	// AuditActionScheduleDeletion records an EventBridge schedule being deleted
+	AuditActionScheduleDeletion AuditAction = "schedule_deletion"
+
+	// AuditActionScheduleReconciliation records a schedule repaired to match the schedules table
+	AuditActionScheduleReconciliation AuditAction = "schedule_reconciliation"
+
+	// AuditActionBooking records a tee time being reserved
+	AuditActionBooking AuditAction = "booking"
+
+	// AuditActionBookingCancellation records a held tee time being declined
+	AuditActionBookingCancellation AuditAction = "booking_cancellation"
+
+	// AuditActionSecretAccess records a secret being read from Secrets Manager
+	AuditActionSecretAccess AuditAction = "secret_access"
+)
+
+// AuditEntry records a privileged operation: who asked for it, what it touched, when, and from
+// where. Entries about a user's data hold only the user ID and item counts, so they are kept
+// after a deletion as the record that it happened.
+type AuditEntry struct {
+	// ID is the unique identifier for the entry (audit_<timestamp>_<random_hex>)
+	ID string `json:"id" dynamodbav:"id"`
+
+	// UserID is the user who asked for the operation, or whose data it covered; empty for
+	// operations made by the system or in single-user mode
+	UserID string `json:"user_id,omitempty" dynamodbav:"user_id,omitempty"`
+
+	// Action is the operation performed
+	Action AuditAction `json:"action" dynamodbav:"action"`
+
+	// Actor is the component that performed the operation
+	Actor string `json:"actor" dynamodbav:"actor"`
+
+	// Target identifies what the operation acted on (a schedule name, approval ID, secret name)
+	Target string `json:"target,omitempty" dynamodbav:"target,omitempty"`
+
+	// SourceIP is the address of the caller, for operations requested over HTTP
+	SourceIP string `json:"source_ip,omitempty" dynamodbav:"source_ip,omitempty"`
+
+	// CorrelationID ties the entry to the request that led to it, including one received by
+	// another component
+	CorrelationID string `json:"correlation_id,omitempty" dynamodbav:"correlation_id,omitempty"`
+
+	// Details holds operation-specific context (course, tee sheet, schedule expression)
+	Details map[string]string `json:"details,omitempty" dynamodbav:"details,omitempty"`
+
+	// Counts holds the number of items covered, by kind (e.g. messages, schedules)
+	Counts map[string]int `json:"counts,omitempty" dynamodbav:"counts,omitempty"`
+
+	// CreatedAt is when the operation completed
+	CreatedAt time.Time `json:"created_at" dynamodbav:"created_at"`
+}
+
+// NewAuditEntry creates an audit entry for an operation that just completed
+func NewAuditEntry(userID string, action AuditAction, actor string, counts map[string]int) (*AuditEntry, error) {
+	now := time.Now().UTC()
+	randomBytes := make([]byte, 4)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate audit entry ID: %w", err)
+	}
+	return &AuditEntry{
+		ID:        fmt.Sprintf("audit_%s_%s", now.Format("20060102150405"), hex.EncodeToString(randomBytes)),
+		UserID:    userID,
+		Action:    action,
+		Actor:     actor,
+		Counts:    counts,
+		CreatedAt: now,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// BookingClaimTimeout is how long a claim on the booking ledger waits for its reservation. It
+// matches the course's hold on a locked tee time: once the lock has lapsed the reservation can no
+// longer go through, so an abandoned claim stops blocking new bookings.
+const BookingClaimTimeout = ApprovalTimeout
+
+// BookingLedgerStatus represents the state of a booking ledger entry
+type BookingLedgerStatus string
+
+const (
+	// BookingLedgerStatusClaimed is a reservation in progress
+	BookingLedgerStatusClaimed BookingLedgerStatus = "claimed"
+	// BookingLedgerStatusBooked is a completed reservation
+	BookingLedgerStatusBooked BookingLedgerStatus = "booked"
+)
+
+// BookingLedgerEntry records that a golfer is reserving, or has reserved, a tee time at a course
+// on a date. There is at most one entry per golfer, course and date, so concurrent schedules and
+// retries cannot reserve the same morning twice.
+type BookingLedgerEntry struct {
+	// ID joins the golfer, course and date; see BookingLedgerID
+	ID string `json:"id" dynamodbav:"id"`
+
+	GolferID      string              `json:"golfer_id" dynamodbav:"golfer_id"`
+	CourseID      int                 `json:"course_id" dynamodbav:"course_id"`
+	Date          string              `json:"date" dynamodbav:"date"`
+	TeeSheetID    int                 `json:"tee_sheet_id" dynamodbav:"tee_sheet_id"`
+	Status        BookingLedgerStatus `json:"status" dynamodbav:"status"`
+	ReservationID int                 `json:"reservation_id,omitempty" dynamodbav:"reservation_id,omitempty"`
+	ClaimedAt     time.Time           `json:"claimed_at" dynamodbav:"claimed_at"`
+
+	// ExpiresAt is when an unfinished claim is abandoned and may be taken over; it is stored as
+	// epoch seconds so the claim condition can compare it
+	ExpiresAt time.Time `json:"expires_at" dynamodbav:"expires_at,unixtime"`
+
+	// TTL removes the entry a week after the tee date
+	TTL int64 `json:"-" dynamodbav:"ttl"`
+}
+
+// BookingLedgerID is the ledger key for a golfer's booking at a course on a date (YYYY-MM-DD)
+func BookingLedgerID(golferID string, courseID int, date string) string {
+	return fmt.Sprintf("%s#%d#%s", golferID, courseID, date)
+}
+
+// NewBookingLedgerClaim creates a claim for a golfer's reservation of teeSheetID, which tees off
+// at teeTime (the course's local time)
+func NewBookingLedgerClaim(golferID string, courseID, teeSheetID int, teeTime time.Time) *BookingLedgerEntry {
+	now := time.Now().UTC()
+	date := teeTime.Format("2006-01-02")
+	return &BookingLedgerEntry{
+		ID:         BookingLedgerID(golferID, courseID, date),
+		GolferID:   golferID,
+		CourseID:   courseID,
+		Date:       date,
+		TeeSheetID: teeSheetID,
+		Status:     BookingLedgerStatusClaimed,
+		ClaimedAt:  now,
+		ExpiresAt:  now.Add(BookingClaimTimeout),
+		TTL:        teeTime.AddDate(0, 0, 7).Unix(),
+	}
+}
+
+// IsAbandoned reports whether the entry is an unfinished claim whose hold has lapsed
+func (e *BookingLedgerEntry) IsAbandoned(now time.Time) bool {
+	return e.Status == BookingLedgerStatusClaimed && !now.Before(e.ExpiresAt)
+}
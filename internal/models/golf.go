@@ -1,6 +1,9 @@
 package models
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -13,6 +16,26 @@ type SearchTeeTimesParams struct {
 	StartSearchTime *string `json:"startSearchTime"` // "2025-10-29T07:30:00" (optional)
 	EndSearchTime   *string `json:"endSearchTime"`   // "2025-10-29T09:00:00" (optional)
 	AutoBook        bool    `json:"autoBook"`        // Auto-book first available
+	MaxPrice        float64 `json:"maxPrice"`        // Skip slots whose 18-hole green fee exceeds this (0 = no cap)
+	PreferredTime   string  `json:"preferredTime"`   // Preferred time of day "09:30" (optional)
+	RankBy          string  `json:"rankBy"`          // Ranking criteria in priority order "time,price,holes" (optional)
+}
+
+// BookedTeeTime is a tee time a booking reserved, reported by the booking tools alongside their
+// text so an agent run can check what was booked
+type BookedTeeTime struct {
+	CourseName    string `json:"course_name"`
+	TeeSheetID    int    `json:"tee_sheet_id"`
+	ReservationID int    `json:"reservation_id"`
+
+	// TeeTime is the course-local start time (YYYY-MM-DDTHH:MM:SS)
+	TeeTime string `json:"tee_time"`
+
+	// GreenFee is the 18-hole green fee per player (0 = unknown)
+	GreenFee float64 `json:"green_fee,omitempty"`
+
+	// PrecipChance is the forecast precipitation chance for the tee time's hour when it was booked
+	PrecipChance *int `json:"precip_chance,omitempty"`
 }
 
 // TeeTimeSlot represents an available tee time from the API
@@ -65,9 +88,10 @@ type TeeTimePrice struct {
 
 // BookTeeTimeParams contains parameters for booking
 type BookTeeTimeParams struct {
-	TeeSheetID     int    `json:"teeSheetId"`
-	NumberOfPlayer int    `json:"numberOfPlayer"`
-	SearchDate     string `json:"searchDate"` // For context/logging
+	TeeSheetID     int     `json:"teeSheetId"`
+	NumberOfPlayer int     `json:"numberOfPlayer"`
+	SearchDate     string  `json:"searchDate"` // For context/logging
+	MaxPrice       float64 `json:"maxPrice"`   // Abort if the 18-hole green fee exceeds this (0 = no cap)
 }
 
 // JWTClaims contains parsed JWT token claims (MUST verify signature!)
@@ -99,6 +123,12 @@ type LockTeeTimeResponse struct {
 	Warning     string `json:"warning"`
 }
 
+// ReleaseTeeTimeRequest is the request body for releasing a locked tee time
+type ReleaseTeeTimeRequest struct {
+	TeeSheetIDs []int  `json:"teeSheetIds"`
+	SessionID   string `json:"sessionId"`
+}
+
 // PricingCalculationRequest is the request body for pricing calculation
 type PricingCalculationRequest struct {
 	SelectedTeeSheetID   int                  `json:"selectedTeeSheetId"`
@@ -294,3 +324,172 @@ func (t *TeeTimeSlot) IsWithinTimeRange(startTime, endTime *string) (bool, error
 
 	return true, nil
 }
+
+// GreenFee18 returns the 18-hole green fee for the slot, if listed
+func (t *TeeTimeSlot) GreenFee18() (float64, bool) {
+	for _, price := range t.ShItemPrices {
+		if price.ShItemCode == "GreenFee18" {
+			return price.Price, true
+		}
+	}
+	return 0, false
+}
+
+// ExceedsPrice reports whether the slot's 18-hole green fee is above maxPrice.
+// A maxPrice of zero or less means no cap; slots without a listed fee never exceed it.
+func (t *TeeTimeSlot) ExceedsPrice(maxPrice float64) bool {
+	if maxPrice <= 0 {
+		return false
+	}
+	fee, ok := t.GreenFee18()
+	return ok && fee > maxPrice
+}
+
+// GreenFee18 returns the 18-hole green fee per player from the pricing calculation, if listed
+func (r *PricingCalculationResponse) GreenFee18() (float64, bool) {
+	for _, item := range r.ShItemPrices {
+		if item.ShItemCode == "GreenFee18" {
+			return item.Price, true
+		}
+	}
+	return 0, false
+}
+
+// RankCriterion is a tee time ranking criterion
+type RankCriterion string
+
+const (
+	// RankByTime ranks slots closest to the preferred time of day first
+	RankByTime RankCriterion = "time"
+	// RankByPrice ranks the cheapest 18-hole green fee first; slots without a listed fee rank last
+	RankByPrice RankCriterion = "price"
+	// RankByHoles ranks slots with more holes first
+	RankByHoles RankCriterion = "holes"
+)
+
+// ParseRankBy parses a comma-separated list of ranking criteria in priority order (e.g. "time,price")
+func ParseRankBy(rankBy string) ([]RankCriterion, error) {
+	if strings.TrimSpace(rankBy) == "" {
+		return nil, nil
+	}
+
+	var criteria []RankCriterion
+	for _, part := range strings.Split(rankBy, ",") {
+		criterion := RankCriterion(strings.ToLower(strings.TrimSpace(part)))
+		switch criterion {
+		case RankByTime, RankByPrice, RankByHoles:
+			criteria = append(criteria, criterion)
+		default:
+			return nil, fmt.Errorf("invalid rankBy criterion %q (must be time, price, or holes)", part)
+		}
+	}
+	return criteria, nil
+}
+
+// ParsePreferredTime parses a preferred time of day in HH:MM (24-hour) format and returns minutes after midnight
+func ParsePreferredTime(preferredTime string) (int, error) {
+	t, err := time.Parse("15:04", preferredTime)
+	if err != nil {
+		return 0, fmt.Errorf("preferredTime must be in HH:MM format: %s", preferredTime)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// RankOptions controls how tee time slots are ranked
+type RankOptions struct {
+	// PreferredMinute is the preferred time of day in minutes after midnight (nil = none)
+	PreferredMinute *int
+
+	// RankBy lists the criteria in priority order; earliest start time breaks remaining ties
+	RankBy []RankCriterion
+}
+
+// RankTeeTimeSlots sorts slots best first: cheapest 18-hole green fee, then earliest start.
+// Slots without a listed fee rank after priced ones.
+func RankTeeTimeSlots(slots []TeeTimeSlot) {
+	RankTeeTimeSlotsBy(slots, RankOptions{RankBy: []RankCriterion{RankByPrice}})
+}
+
+// RankTeeTimeSlotsBy sorts slots best first using the given criteria.
+// The time criterion is ignored when no preferred time is set.
+func RankTeeTimeSlotsBy(slots []TeeTimeSlot, opts RankOptions) {
+	sort.SliceStable(slots, func(i, j int) bool {
+		for _, criterion := range opts.RankBy {
+			if less, decided := compareSlots(&slots[i], &slots[j], criterion, opts.PreferredMinute); decided {
+				return less
+			}
+		}
+		return slots[i].StartTime < slots[j].StartTime
+	})
+}
+
+// compareSlots compares two slots on one criterion; decided is false when they tie
+func compareSlots(a, b *TeeTimeSlot, criterion RankCriterion, preferredMinute *int) (less, decided bool) {
+	switch criterion {
+	case RankByTime:
+		if preferredMinute == nil {
+			return false, false
+		}
+		distA, okA := a.minutesFrom(*preferredMinute)
+		distB, okB := b.minutesFrom(*preferredMinute)
+		if okA != okB {
+			return okA, true
+		}
+		if distA != distB {
+			return distA < distB, true
+		}
+	case RankByPrice:
+		feeA, okA := a.GreenFee18()
+		feeB, okB := b.GreenFee18()
+		if okA != okB {
+			return okA, true
+		}
+		if okA && feeA != feeB {
+			return feeA < feeB, true
+		}
+	case RankByHoles:
+		if a.Holes != b.Holes {
+			return a.Holes > b.Holes, true
+		}
+	}
+	return false, false
+}
+
+// minutesFrom returns how many minutes the slot's time of day is from the given minute of the day
+func (t *TeeTimeSlot) minutesFrom(minuteOfDay int) (int, bool) {
+	start, err := t.ParseStartTime()
+	if err != nil {
+		return 0, false
+	}
+	diff := start.Hour()*60 + start.Minute() - minuteOfDay
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff, true
+}
+
+// RankOptions returns the ranking options for the search. When a preferred time is set
+// without explicit criteria, slots closest to it rank first, then the cheapest.
+// ok is false when neither option is set and the API order should be kept.
+func (p *SearchTeeTimesParams) RankOptions() (opts RankOptions, ok bool, err error) {
+	if p.PreferredTime == "" && p.RankBy == "" {
+		return RankOptions{}, false, nil
+	}
+
+	if p.PreferredTime != "" {
+		minute, err := ParsePreferredTime(p.PreferredTime)
+		if err != nil {
+			return RankOptions{}, false, err
+		}
+		opts.PreferredMinute = &minute
+	}
+
+	opts.RankBy, err = ParseRankBy(p.RankBy)
+	if err != nil {
+		return RankOptions{}, false, err
+	}
+	if len(opts.RankBy) == 0 {
+		opts.RankBy = []RankCriterion{RankByTime, RankByPrice}
+	}
+	return opts, true, nil
+}
@@ -631,3 +631,175 @@ func TestPricingCalculationResponse_JSONMarshaling(t *testing.T) {
 		t.Errorf("Total = %v, want %v", unmarshaled.SummaryDetail.Total, resp.SummaryDetail.Total)
 	}
 }
+
+func TestTeeTimeSlot_ExceedsPrice(t *testing.T) {
+	slot := &TeeTimeSlot{
+		ShItemPrices: []TeeTimePrice{
+			{ShItemCode: "GreenFee9", Price: 25},
+			{ShItemCode: "GreenFee18", Price: 45},
+		},
+	}
+	noPrice := &TeeTimeSlot{}
+
+	tests := []struct {
+		name     string
+		slot     *TeeTimeSlot
+		maxPrice float64
+		want     bool
+	}{
+		{"no cap", slot, 0, false},
+		{"under cap", slot, 50, false},
+		{"at cap", slot, 45, false},
+		{"over cap", slot, 40, true},
+		{"no listed fee", noPrice, 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.slot.ExceedsPrice(tt.maxPrice); got != tt.want {
+				t.Errorf("ExceedsPrice(%v) = %v, want %v", tt.maxPrice, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPricingCalculationResponse_GreenFee18(t *testing.T) {
+	resp := &PricingCalculationResponse{
+		ShItemPrices: []PricingItemDetails{
+			{ShItemCode: "Cart", Price: 15},
+			{ShItemCode: "GreenFee18", Price: 52.5},
+		},
+	}
+	fee, ok := resp.GreenFee18()
+	if !ok || fee != 52.5 {
+		t.Errorf("GreenFee18() = %v, %v; want 52.5, true", fee, ok)
+	}
+
+	if _, ok := (&PricingCalculationResponse{}).GreenFee18(); ok {
+		t.Error("GreenFee18() ok = true for response without green fee")
+	}
+}
+
+func TestRankTeeTimeSlots(t *testing.T) {
+	slot := func(id int, start string, fee float64) TeeTimeSlot {
+		s := TeeTimeSlot{TeeSheetID: id, StartTime: start}
+		if fee > 0 {
+			s.ShItemPrices = []TeeTimePrice{{ShItemCode: "GreenFee18", Price: fee}}
+		}
+		return s
+	}
+
+	slots := []TeeTimeSlot{
+		slot(1, "2030-06-02T08:00:00", 0),
+		slot(2, "2030-06-02T09:00:00", 40),
+		slot(3, "2030-06-01T10:00:00", 30),
+		slot(4, "2030-06-01T07:00:00", 40),
+	}
+
+	RankTeeTimeSlots(slots)
+
+	want := []int{3, 4, 2, 1}
+	for i, id := range want {
+		if slots[i].TeeSheetID != id {
+			t.Errorf("slots[%d].TeeSheetID = %d, want %d", i, slots[i].TeeSheetID, id)
+		}
+	}
+}
+
+func TestRankTeeTimeSlotsBy(t *testing.T) {
+	slot := func(id int, start string, fee float64, holes int) TeeTimeSlot {
+		s := TeeTimeSlot{TeeSheetID: id, StartTime: start, Holes: holes}
+		if fee > 0 {
+			s.ShItemPrices = []TeeTimePrice{{ShItemCode: "GreenFee18", Price: fee}}
+		}
+		return s
+	}
+	minute := func(m int) *int { return &m }
+
+	tests := []struct {
+		name string
+		opts RankOptions
+		want []int
+	}{
+		{
+			name: "closest to preferred time",
+			opts: RankOptions{PreferredMinute: minute(9*60 + 30), RankBy: []RankCriterion{RankByTime}},
+			want: []int{3, 2, 4, 1},
+		},
+		{
+			name: "price then time",
+			opts: RankOptions{PreferredMinute: minute(9*60 + 30), RankBy: []RankCriterion{RankByPrice, RankByTime}},
+			want: []int{4, 3, 2, 1},
+		},
+		{
+			name: "holes then price",
+			opts: RankOptions{RankBy: []RankCriterion{RankByHoles, RankByPrice}},
+			want: []int{2, 3, 1, 4},
+		},
+		{
+			name: "time without preferred time falls back to earliest",
+			opts: RankOptions{RankBy: []RankCriterion{RankByTime}},
+			want: []int{1, 2, 3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slots := []TeeTimeSlot{
+				slot(1, "2030-06-01T07:00:00", 0, 18),
+				slot(2, "2030-06-01T09:00:00", 40, 18),
+				slot(3, "2030-06-01T09:40:00", 40, 18),
+				slot(4, "2030-06-01T11:00:00", 25, 9),
+			}
+
+			RankTeeTimeSlotsBy(slots, tt.opts)
+
+			for i, id := range tt.want {
+				if slots[i].TeeSheetID != id {
+					t.Errorf("slots[%d].TeeSheetID = %d, want %d", i, slots[i].TeeSheetID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchTeeTimesParams_RankOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		preferredTime string
+		rankBy        string
+		wantOK        bool
+		wantRankBy    []RankCriterion
+		wantErr       bool
+	}{
+		{"none", "", "", false, nil, false},
+		{"preferred time defaults to time then price", "09:30", "", true, []RankCriterion{RankByTime, RankByPrice}, false},
+		{"explicit criteria", "", "Price, holes", true, []RankCriterion{RankByPrice, RankByHoles}, false},
+		{"invalid preferred time", "9:30 AM", "", false, nil, true},
+		{"invalid criterion", "", "distance", false, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &SearchTeeTimesParams{PreferredTime: tt.preferredTime, RankBy: tt.rankBy}
+			opts, ok, err := params.RankOptions()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RankOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("RankOptions() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if len(opts.RankBy) != len(tt.wantRankBy) {
+				t.Fatalf("RankBy = %v, want %v", opts.RankBy, tt.wantRankBy)
+			}
+			for i := range tt.wantRankBy {
+				if opts.RankBy[i] != tt.wantRankBy[i] {
+					t.Errorf("RankBy[%d] = %s, want %s", i, opts.RankBy[i], tt.wantRankBy[i])
+				}
+			}
+			if tt.preferredTime == "09:30" && (opts.PreferredMinute == nil || *opts.PreferredMinute != 570) {
+				t.Errorf("PreferredMinute = %v, want 570", opts.PreferredMinute)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// MaxGroupSize is the most players one tee time can be booked for
+const MaxGroupSize = 4
+
+// ntfyTopicPattern matches the topic names ntfy accepts
+var ntfyTopicPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// GroupMember is a golfer in a group booking and the ntfy topic their RSVP request is sent to
+type GroupMember struct {
+	Name      string `json:"name" dynamodbav:"name"`
+	NtfyTopic string `json:"ntfyTopic" dynamodbav:"ntfyTopic"`
+}
+
+// ValidateGroup checks a group booking's members; an empty group is a regular booking
+func ValidateGroup(members []GroupMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+	if len(members) > MaxGroupSize {
+		return fmt.Errorf("group has %d members, at most %d can play one tee time", len(members), MaxGroupSize)
+	}
+
+	seen := make(map[string]bool, len(members))
+	for _, m := range members {
+		if m.Name == "" {
+			return fmt.Errorf("group member name is required")
+		}
+		if seen[m.Name] {
+			return fmt.Errorf("group member %s is listed twice", m.Name)
+		}
+		seen[m.Name] = true
+		if !ntfyTopicPattern.MatchString(m.NtfyTopic) {
+			return fmt.Errorf("group member %s has an invalid ntfy topic %q", m.Name, m.NtfyTopic)
+		}
+	}
+	return nil
+}
+
+// RSVPStatus is a group member's answer to a group booking
+type RSVPStatus string
+
+const (
+	// RSVPStatusPending has not answered yet
+	RSVPStatusPending RSVPStatus = "pending"
+	// RSVPStatusAccepted is playing
+	RSVPStatusAccepted RSVPStatus = "accepted"
+	// RSVPStatusDeclined is not playing and is dropped from the reservation
+	RSVPStatusDeclined RSVPStatus = "declined"
+)
+
+// String returns the string representation of the RSVP status
+func (s RSVPStatus) String() string {
+	return string(s)
+}
+
+// GroupRSVP tracks one group member's answer to a held tee time
+type GroupRSVP struct {
+	Name string `json:"name" dynamodbav:"name"`
+
+	// Token is the member's unguessable RSVP token, so members can only answer for themselves
+	Token string `json:"-" dynamodbav:"token"`
+
+	Status      RSVPStatus `json:"status" dynamodbav:"status"`
+	RespondedAt *time.Time `json:"responded_at,omitempty" dynamodbav:"responded_at,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestValidateGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []GroupMember
+		wantErr bool
+	}{
+		{name: "no group"},
+		{name: "valid", members: []GroupMember{{Name: "Alice", NtfyTopic: "alice-golf"}, {Name: "Bob", NtfyTopic: "bob_golf"}}},
+		{name: "missing name", members: []GroupMember{{NtfyTopic: "alice"}}, wantErr: true},
+		{name: "duplicate name", members: []GroupMember{{Name: "Alice", NtfyTopic: "a"}, {Name: "Alice", NtfyTopic: "b"}}, wantErr: true},
+		{name: "topic with a path", members: []GroupMember{{Name: "Alice", NtfyTopic: "../alerts"}}, wantErr: true},
+		{name: "missing topic", members: []GroupMember{{Name: "Alice"}}, wantErr: true},
+		{name: "too many", members: []GroupMember{
+			{Name: "A", NtfyTopic: "a"}, {Name: "B", NtfyTopic: "b"}, {Name: "C", NtfyTopic: "c"},
+			{Name: "D", NtfyTopic: "d"}, {Name: "E", NtfyTopic: "e"},
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateGroup(tt.members); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateGroup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -63,6 +63,33 @@ func (s Status) String() string {
 	return string(s)
 }
 
+// Priority ranks a message for consumers that subscribe to a subset of a topic
+type Priority string
+
+const (
+	// PriorityLow marks messages that can wait, such as digests
+	PriorityLow Priority = "low"
+	// PriorityNormal is the priority of a message that does not set one
+	PriorityNormal Priority = "normal"
+	// PriorityHigh marks messages that should be handled first, such as bookings
+	PriorityHigh Priority = "high"
+)
+
+// IsValid checks if the priority value is valid
+func (p Priority) IsValid() bool {
+	switch p {
+	case PriorityLow, PriorityNormal, PriorityHigh:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the string representation of the priority
+func (p Priority) String() string {
+	return string(p)
+}
+
 // MessageType represents the type of message
 type MessageType string
 
@@ -79,16 +106,16 @@ const (
 	MessageTypeWebAction MessageType = "web_action"
 	// MessageTypeScheduleCreation is a schedule creation/management request
 	MessageTypeScheduleCreation MessageType = "schedule_creation"
+	// MessageTypeStandingTeeTime is a standing tee time reminder/renewal trigger
+	MessageTypeStandingTeeTime MessageType = "standing_tee_time"
+	// MessageTypeWeeklyDigest triggers the weekly activity digest email
+	MessageTypeWeeklyDigest MessageType = "weekly_digest"
 )
 
-// IsValid checks if the message type value is valid
+// IsValid checks if the message type is registered in the message type registry
 func (mt MessageType) IsValid() bool {
-	switch mt {
-	case MessageTypeHelloWorld, MessageTypeNotification, MessageTypeScheduled, MessageTypeWebAction, MessageTypeAgentResponse, MessageTypeScheduleCreation:
-		return true
-	default:
-		return false
-	}
+	_, err := defaultMessageTypeRegistry.Lookup(mt)
+	return err == nil
 }
 
 // String returns the string representation of the message type
@@ -119,6 +146,9 @@ type Message struct {
 	// Status is the current state of the message
 	Status Status `json:"status" dynamodbav:"status"`
 
+	// Priority ranks the message for selective subscribers; see EffectivePriority when unset
+	Priority Priority `json:"priority,omitempty" dynamodbav:"priority,omitempty"`
+
 	// Payload is the message content
 	Payload map[string]interface{} `json:"payload" dynamodbav:"payload"`
 
@@ -136,6 +166,11 @@ type Message struct {
 
 	// RetryCount tracks the number of retry attempts
 	RetryCount int `json:"retry_count" dynamodbav:"retry_count"`
+
+	// CorrelationID ties the message to the request that caused it: it is set at ingress (the web
+	// API, a schedule firing, or the MCP server) and copied to every message, result, and agent
+	// run that follows
+	CorrelationID string `json:"correlation_id,omitempty" dynamodbav:"correlation_id,omitempty"`
 }
 
 // NewMessage creates a new message with default values
@@ -162,29 +197,10 @@ func (m *Message) Validate() error {
 	m.RetryCount = 0
 	m.CreatedBy = "webapi"
 	m.Status = StatusCreated
-	switch m.MessageType {
-	case MessageTypeWebAction:
-		_, err := ParseWebActionPayload(m.Payload)
-		if err != nil {
-			return err
-		}
-	case MessageTypeScheduleCreation:
-		//Schedules Creation requires Arguments to be present
-		if m.Arguments != nil && m.Arguments["action"] != nil {
-			action, ok := m.Arguments["action"].(string)
-			if !ok || action == "" {
-				return fmt.Errorf("invalid action argument for schedule creation message")
-			}
-			if action == "create" {
-				if m.Arguments["name"] == nil || m.Arguments["schedule_expression"] == nil || m.Arguments["target_type"] == nil || m.Arguments["timezone"] == nil {
-					return fmt.Errorf("missing required arguments for schedule creation...name, schedule_expression, target_type, and timezone are required")
-				}
-			}
-		} else {
-			return fmt.Errorf("arguments are required for schedule creation messages")
-		}
+	if m.Priority != "" && !m.Priority.IsValid() {
+		return fmt.Errorf("invalid priority %q: must be low, normal or high", m.Priority)
 	}
-	return nil
+	return defaultMessageTypeRegistry.ValidatePayload(m)
 }
 
 // generateMessageID generates a unique message ID based on timestamp and random component
@@ -223,3 +239,48 @@ func (m *Message) IncrementRetry() {
 	m.RetryCount++
 	m.UpdatedDate = time.Now().UTC()
 }
+
+// Requeue prepares a failed message to be published again: it counts the retry, clears the
+// error, and marks the message queued
+func (m *Message) Requeue() {
+	m.IncrementRetry()
+	m.ErrorMessage = ""
+	m.MarkQueued()
+}
+
+// EffectivePriority returns the message's priority. A message that does not set one is high
+// priority when it books a tee time and normal otherwise.
+func (m *Message) EffectivePriority() Priority {
+	if m.Priority != "" {
+		return m.Priority
+	}
+	if m.IsBooking() {
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
+
+// CourseID returns the golf course a web action message targets, if it names one
+func (m *Message) CourseID() (int, bool) {
+	if m.MessageType != MessageTypeWebAction {
+		return 0, false
+	}
+	payload, err := DecodePayload[*WebActionPayload](m)
+	if err != nil || payload.CourseID == 0 {
+		return 0, false
+	}
+	return payload.CourseID, true
+}
+
+// IsBooking reports whether the message is a golf web action that reserves a tee time, which
+// must never run twice once the reservation went through
+func (m *Message) IsBooking() bool {
+	if m.MessageType != MessageTypeWebAction || m.Payload["action"] != string(WebActionTypeGolf) {
+		return false
+	}
+	switch m.Arguments["operation"] {
+	case "book_tee_time", "complete_booking":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Components that produce and consume messages, named after their Lambda functions
+const (
+	ComponentWebAPI           = "webapi"
+	ComponentScheduler        = "scheduler"
+	ComponentScheduleCreation = "schedulecreation"
+	ComponentWebAction        = "webaction"
+	ComponentProcessor        = "processor"
+	ComponentAgent            = "agent"
+	ComponentAlarms           = "alarms"
+	ComponentDigest           = "digest"
+)
+
+// components are the names components record as the creator of what they make
+var components = []string{
+	ComponentWebAPI, ComponentScheduler, ComponentScheduleCreation, ComponentWebAction,
+	ComponentProcessor, ComponentAgent, ComponentAlarms, ComponentDigest,
+}
+
+// IsComponent reports whether name is a component rather than a user. Messages and schedules
+// made without a signed-in user are created by the component that made them.
+func IsComponent(name string) bool {
+	return slices.Contains(components, name)
+}
+
+// MessageTypeSpec describes a message type: who may publish it, who consumes it, and how its payload is decoded
+type MessageTypeSpec struct {
+	// Type is the message type being described
+	Type MessageType
+
+	// Description is a short human-readable summary
+	Description string
+
+	// Producers are the components allowed to publish this type
+	Producers []string
+
+	// Consumers are the components bound to receive this type
+	Consumers []string
+
+	// Decode parses the message payload or arguments into its typed, validated form (optional)
+	Decode func(m *Message) (MessagePayload, error)
+}
+
+// MessageTypeRegistry is the central catalog of known message types
+type MessageTypeRegistry struct {
+	specs map[MessageType]MessageTypeSpec
+}
+
+// NewMessageTypeRegistry creates a registry from the given specs
+func NewMessageTypeRegistry(specs ...MessageTypeSpec) (*MessageTypeRegistry, error) {
+	r := &MessageTypeRegistry{specs: make(map[MessageType]MessageTypeSpec, len(specs))}
+	for _, spec := range specs {
+		if spec.Type == "" {
+			return nil, fmt.Errorf("message type spec is missing a type")
+		}
+		if _, exists := r.specs[spec.Type]; exists {
+			return nil, fmt.Errorf("message type %s is registered twice", spec.Type)
+		}
+		if len(spec.Consumers) == 0 {
+			return nil, fmt.Errorf("message type %s has no consumers", spec.Type)
+		}
+		r.specs[spec.Type] = spec
+	}
+	return r, nil
+}
+
+// defaultMessageTypeRegistry holds the message types this system exchanges
+var defaultMessageTypeRegistry = mustMessageTypeRegistry(
+	MessageTypeSpec{
+		Type:        MessageTypeHelloWorld,
+		Description: "Test message delivered as a push notification",
+		Producers:   []string{ComponentWebAPI, ComponentScheduleCreation},
+		Consumers:   []string{ComponentProcessor},
+		Decode:      decodeNotification,
+	},
+	MessageTypeSpec{
+		Type:        MessageTypeNotification,
+		Description: "Push notification sent through ntfy",
+		Producers:   []string{ComponentWebAPI, ComponentScheduleCreation, ComponentWebAction, ComponentAgent, ComponentAlarms},
+		Consumers:   []string{ComponentProcessor},
+		Decode:      decodeNotification,
+	},
+	MessageTypeSpec{
+		Type:        MessageTypeAgentResponse,
+		Description: "Web action result handed back to the AI agent",
+		Producers:   []string{ComponentWebAction},
+		Consumers:   []string{ComponentAgent},
+		Decode:      decodeAgentResponse,
+	},
+	MessageTypeSpec{
+		Type:        MessageTypeScheduled,
+		Description: "Scheduled autonomous agent task",
+		Producers:   []string{ComponentScheduleCreation},
+		Consumers:   []string{ComponentScheduler},
+	},
+	MessageTypeSpec{
+		Type:        MessageTypeWebAction,
+		Description: "HTTP REST API call such as a golf search or booking",
+		Producers:   []string{ComponentWebAPI, ComponentScheduleCreation, ComponentAgent},
+		Consumers:   []string{ComponentWebAction},
+		Decode:      decodeWebAction,
+	},
+	MessageTypeSpec{
+		Type:        MessageTypeScheduleCreation,
+		Description: "Schedule creation or management request",
+		Producers:   []string{ComponentWebAPI, ComponentAgent},
+		Consumers:   []string{ComponentScheduleCreation},
+		Decode:      decodeScheduleCreation,
+	},
+	MessageTypeSpec{
+		Type:        MessageTypeStandingTeeTime,
+		Description: "Standing tee time reminder or renewal trigger",
+		Producers:   []string{ComponentScheduleCreation},
+		Consumers:   []string{ComponentScheduler},
+		Decode:      decodeStandingTeeTime,
+	},
+	MessageTypeSpec{
+		Type:        MessageTypeWeeklyDigest,
+		Description: "Weekly activity digest email trigger",
+		Producers:   []string{ComponentScheduleCreation},
+		Consumers:   []string{ComponentDigest},
+	},
+)
+
+// mustMessageTypeRegistry builds a registry from static specs, panicking on a programming error
+func mustMessageTypeRegistry(specs ...MessageTypeSpec) *MessageTypeRegistry {
+	r, err := NewMessageTypeRegistry(specs...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// DefaultMessageTypeRegistry returns the registry of message types known to this system
+func DefaultMessageTypeRegistry() *MessageTypeRegistry {
+	return defaultMessageTypeRegistry
+}
+
+// Lookup returns the spec for a message type, failing for unknown types
+func (r *MessageTypeRegistry) Lookup(mt MessageType) (MessageTypeSpec, error) {
+	spec, ok := r.specs[mt]
+	if !ok {
+		return MessageTypeSpec{}, fmt.Errorf("unknown message type %q (known types: %v)", mt, r.Types())
+	}
+	return spec, nil
+}
+
+// Types returns the registered message types in sorted order
+func (r *MessageTypeRegistry) Types() []MessageType {
+	types := make([]MessageType, 0, len(r.specs))
+	for mt := range r.specs {
+		types = append(types, mt)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// ValidatePayload checks that the message type is known and its payload matches the type's schema
+func (r *MessageTypeRegistry) ValidatePayload(m *Message) error {
+	spec, err := r.Lookup(m.MessageType)
+	if err != nil {
+		return err
+	}
+	if spec.Decode == nil {
+		return nil
+	}
+	if _, err := spec.Decode(m); err != nil {
+		return fmt.Errorf("invalid %s message: %w", m.MessageType, err)
+	}
+	return nil
+}
+
+// DecodePayload parses the message into the typed payload of its type, failing for unknown
+// types, types without a typed payload, and payloads that do not match the type's schema
+func (r *MessageTypeRegistry) DecodePayload(m *Message) (MessagePayload, error) {
+	spec, err := r.Lookup(m.MessageType)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Decode == nil {
+		return nil, fmt.Errorf("%s messages carry no typed payload", m.MessageType)
+	}
+	payload, err := spec.Decode(m)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s message: %w", m.MessageType, err)
+	}
+	return payload, nil
+}
+
+// ValidatePublish checks that producer may publish the message and that its payload is valid
+func (r *MessageTypeRegistry) ValidatePublish(m *Message, producer string) error {
+	spec, err := r.Lookup(m.MessageType)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(spec.Producers, producer) {
+		return fmt.Errorf("%s is not allowed to publish %s messages (producers: %v)", producer, m.MessageType, spec.Producers)
+	}
+	return r.ValidatePayload(m)
+}
+
+// ValidateConsume checks that the message was delivered to a consumer bound to its type
+func (r *MessageTypeRegistry) ValidateConsume(m *Message, consumer string) error {
+	spec, err := r.Lookup(m.MessageType)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(spec.Consumers, consumer) {
+		return fmt.Errorf("%s messages are consumed by %v, not %s", m.MessageType, spec.Consumers, consumer)
+	}
+	return nil
+}
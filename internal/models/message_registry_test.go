@@ -0,0 +1,67 @@
+package models
+
+import "testing"
+
+func TestNewMessageTypeRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []MessageTypeSpec
+		wantErr bool
+	}{
+		{"valid", []MessageTypeSpec{{Type: "a", Consumers: []string{ComponentProcessor}}}, false},
+		{"missing type", []MessageTypeSpec{{Consumers: []string{ComponentProcessor}}}, true},
+		{"no consumers", []MessageTypeSpec{{Type: "a"}}, true},
+		{"duplicate", []MessageTypeSpec{
+			{Type: "a", Consumers: []string{ComponentProcessor}},
+			{Type: "a", Consumers: []string{ComponentAgent}},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMessageTypeRegistry(tt.specs...); (err != nil) != tt.wantErr {
+				t.Errorf("NewMessageTypeRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessageTypeRegistry_ValidatePublish(t *testing.T) {
+	registry := DefaultMessageTypeRegistry()
+
+	tests := []struct {
+		name     string
+		msgType  MessageType
+		producer string
+		payload  map[string]interface{}
+		args     map[string]interface{}
+		wantErr  bool
+	}{
+		{"notification from webapi", MessageTypeNotification, ComponentWebAPI, map[string]interface{}{"message": "hi"}, nil, false},
+		{"notification without text", MessageTypeNotification, ComponentWebAPI, map[string]interface{}{}, nil, true},
+		{"typo'd type", MessageType("web-action"), ComponentWebAPI, nil, nil, true},
+		{"disallowed producer", MessageTypeAgentResponse, ComponentWebAPI, nil, nil, true},
+		{"schedule creation without arguments", MessageTypeScheduleCreation, ComponentWebAPI, nil, nil, true},
+		{"schedule delete", MessageTypeScheduleCreation, ComponentWebAPI, nil, map[string]interface{}{"action": "delete"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewMessage("test", tt.args, "1.0", StageDev, tt.msgType, tt.payload)
+			if err := registry.ValidatePublish(msg, tt.producer); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePublish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessageTypeRegistry_ValidateConsume(t *testing.T) {
+	registry := DefaultMessageTypeRegistry()
+
+	if err := registry.ValidateConsume(&Message{MessageType: MessageTypeWebAction}, ComponentWebAction); err != nil {
+		t.Errorf("web_action should be consumed by webaction: %v", err)
+	}
+	if err := registry.ValidateConsume(&Message{MessageType: MessageTypeWebAction}, ComponentProcessor); err == nil {
+		t.Error("web_action should not be consumed by processor")
+	}
+}
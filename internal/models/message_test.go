@@ -224,3 +224,63 @@ func TestMessage_IncrementRetry(t *testing.T) {
 		t.Error("IncrementRetry() did not update UpdatedDate")
 	}
 }
+
+func TestMessage_Requeue(t *testing.T) {
+	msg := NewMessage("test", nil, "1.0", StageDev, MessageTypeNotification, map[string]interface{}{"message": "hi"})
+	msg.MarkFailed("ntfy unavailable")
+	msg.IncrementRetry()
+
+	msg.Requeue()
+
+	if msg.Status != StatusQueued || msg.ErrorMessage != "" || msg.RetryCount != 2 {
+		t.Errorf("Requeue() = status %v, error %q, retries %d; want queued, no error, 2 retries", msg.Status, msg.ErrorMessage, msg.RetryCount)
+	}
+}
+
+func TestMessage_IsBooking(t *testing.T) {
+	golf := map[string]interface{}{"action": string(WebActionTypeGolf)}
+	tests := []struct {
+		name        string
+		messageType MessageType
+		operation   string
+		payload     map[string]interface{}
+		want        bool
+	}{
+		{"book tee time", MessageTypeWebAction, "book_tee_time", golf, true},
+		{"complete booking", MessageTypeWebAction, "complete_booking", golf, true},
+		{"search", MessageTypeWebAction, "search_tee_times", golf, false},
+		{"weather", MessageTypeWebAction, "book_tee_time", map[string]interface{}{"action": string(WebActionTypeWeather)}, false},
+		{"notification", MessageTypeNotification, "book_tee_time", golf, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewMessage("test", map[string]interface{}{"operation": tt.operation}, "1.0", StageDev, tt.messageType, tt.payload)
+			if got := msg.IsBooking(); got != tt.want {
+				t.Errorf("IsBooking() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessage_EffectivePriority(t *testing.T) {
+	golf := map[string]interface{}{"action": string(WebActionTypeGolf)}
+	tests := []struct {
+		name      string
+		operation string
+		priority  Priority
+		want      Priority
+	}{
+		{"booking", "book_tee_time", "", PriorityHigh},
+		{"search", "search_tee_times", "", PriorityNormal},
+		{"explicit", "search_tee_times", PriorityLow, PriorityLow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewMessage("test", map[string]interface{}{"operation": tt.operation}, "1.0", StageDev, MessageTypeWebAction, golf)
+			msg.Priority = tt.priority
+			if got := msg.EffectivePriority(); got != tt.want {
+				t.Errorf("EffectivePriority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MessagePayload is the typed content of a message. The message type decides which struct a
+// message's payload (or, for types driven by arguments, its arguments) decodes into; see
+// MessageTypeSpec.Decode.
+type MessagePayload interface {
+	Validate() error
+}
+
+// FieldError is a validation failure of one field of a message, such as "payload.message" or
+// "arguments.timezone", so API callers can point at what to fix
+type FieldError struct {
+	// Field is the dotted path of the field
+	Field string
+
+	// Message says what is wrong with it
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// NotificationPayload is the payload of hello_world and notify messages
+type NotificationPayload struct {
+	// Message is the text of the push notification
+	Message string `json:"message"`
+}
+
+// Validate requires the text of the push notification
+func (p *NotificationPayload) Validate() error {
+	if p.Message == "" {
+		return &FieldError{Field: "payload.message", Message: "is required"}
+	}
+	return nil
+}
+
+// AgentResponsePayload is the payload of agent_response messages, a web action's outcome handed
+// back to the agent
+type AgentResponsePayload struct {
+	// Status is the outcome of the web action, such as failed
+	Status string `json:"status"`
+
+	// Message summarizes the outcome for the agent
+	Message string `json:"message"`
+
+	// Failure describes a web action that failed permanently
+	Failure *WebActionFailure `json:"failure,omitempty"`
+}
+
+// Validate accepts any response; the agent only logs them
+func (p *AgentResponsePayload) Validate() error {
+	return nil
+}
+
+// ScheduleCreationArgs are the arguments of a schedule_creation message
+type ScheduleCreationArgs struct {
+	// Action is what to do with the schedule: create, delete, pause or resume
+	Action string `json:"action"`
+
+	// Name is the human-readable name of a new schedule
+	Name string `json:"name,omitempty"`
+
+	// Description says what a new schedule does (optional)
+	Description string `json:"description,omitempty"`
+
+	// ScheduleExpression is the rate(), cron() or at() expression of a new schedule
+	ScheduleExpression string `json:"schedule_expression,omitempty"`
+
+	// Timezone is the IANA timezone the expression is read in
+	Timezone string `json:"timezone,omitempty"`
+
+	// TargetType is the kind of message the schedule sends when it fires
+	TargetType TargetType `json:"target_type,omitempty"`
+
+	// Operation is the agent operation a scheduled target runs
+	Operation string `json:"operation,omitempty"`
+}
+
+// Validate requires the action and, to create a schedule, a complete schedule definition
+func (a *ScheduleCreationArgs) Validate() error {
+	if a.Action == "" {
+		return &FieldError{Field: "arguments.action", Message: "is required"}
+	}
+	if a.Action == "create" {
+		return a.validateSchedule()
+	}
+	return nil
+}
+
+// validateSchedule requires the name, expression, timezone and target type of a new schedule and
+// checks that each one is usable, naming the offending argument
+func (a *ScheduleCreationArgs) validateSchedule() error {
+	switch {
+	case a.Name == "":
+		return &FieldError{Field: "arguments.name", Message: "is required"}
+	case a.ScheduleExpression == "":
+		return &FieldError{Field: "arguments.schedule_expression", Message: "is required"}
+	case a.Timezone == "":
+		return &FieldError{Field: "arguments.timezone", Message: "is required"}
+	case a.TargetType == "":
+		return &FieldError{Field: "arguments.target_type", Message: "is required"}
+	}
+	if !a.TargetType.IsValid() {
+		return &FieldError{Field: "arguments.target_type", Message: fmt.Sprintf("%q is not a valid target type", a.TargetType)}
+	}
+	if _, err := time.LoadLocation(a.Timezone); err != nil {
+		return &FieldError{Field: "arguments.timezone", Message: fmt.Sprintf("%q is not a valid IANA timezone", a.Timezone)}
+	}
+	if err := ValidateScheduleExpression(a.ScheduleExpression); err != nil {
+		return &FieldError{Field: "arguments.schedule_expression", Message: "is invalid: " + err.Error()}
+	}
+	return nil
+}
+
+// parseScheduleArguments decodes the arguments of a schedule to create, whatever the action
+func parseScheduleArguments(arguments map[string]interface{}) (*ScheduleCreationArgs, error) {
+	if arguments == nil {
+		return nil, fmt.Errorf("arguments are required for schedule creation messages")
+	}
+	var args ScheduleCreationArgs
+	if err := decodeInto("arguments", arguments, &args); err != nil {
+		return nil, err
+	}
+	if err := args.validateSchedule(); err != nil {
+		return nil, err
+	}
+	return &args, nil
+}
+
+// DecodePayload decodes a message's typed payload with the default registry, failing when its
+// type carries no typed payload or one that is not a T
+func DecodePayload[T MessagePayload](m *Message) (T, error) {
+	var typed T
+	payload, err := defaultMessageTypeRegistry.DecodePayload(m)
+	if err != nil {
+		return typed, err
+	}
+	typed, ok := payload.(T)
+	if !ok {
+		return typed, fmt.Errorf("%s messages carry a %T payload, not %T", m.MessageType, payload, typed)
+	}
+	return typed, nil
+}
+
+// EncodePayload validates a typed payload and converts it to a message's payload map
+func EncodePayload(payload MessagePayload) (map[string]interface{}, error) {
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+	return fields, nil
+}
+
+// NewPayloadMessage creates a new message carrying a typed payload
+func NewPayloadMessage(createdBy string, arguments map[string]interface{}, stage Stage, messageType MessageType, payload MessagePayload) (*Message, error) {
+	fields, err := EncodePayload(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", messageType, err)
+	}
+	return NewMessage(createdBy, arguments, "1.0", stage, messageType, fields), nil
+}
+
+// decodeFields decodes a message's payload or arguments map into v and validates it. source
+// names the map in errors, so a mistyped field reads as "arguments.name must be a string".
+func decodeFields(source string, fields map[string]interface{}, v MessagePayload) error {
+	if err := decodeInto(source, fields, v); err != nil {
+		return err
+	}
+	return v.Validate()
+}
+
+// decodeInto decodes a message's payload or arguments map into v without validating it
+func decodeInto(source string, fields map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", source, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return &FieldError{Field: source + "." + typeErr.Field, Message: fmt.Sprintf("must be a %s, not a %s", typeErr.Type, typeErr.Value)}
+		}
+		return fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+	return nil
+}
+
+// decodeNotification decodes the payload of hello_world and notify messages
+func decodeNotification(m *Message) (MessagePayload, error) {
+	var payload NotificationPayload
+	if err := decodeFields("payload", m.Payload, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// decodeAgentResponse decodes the payload of agent_response messages
+func decodeAgentResponse(m *Message) (MessagePayload, error) {
+	var payload AgentResponsePayload
+	if err := decodeFields("payload", m.Payload, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// decodeWebAction decodes the payload of web_action messages
+func decodeWebAction(m *Message) (MessagePayload, error) {
+	return ParseWebActionPayload(m.Payload)
+}
+
+// decodeScheduleCreation decodes the arguments of schedule_creation messages
+func decodeScheduleCreation(m *Message) (MessagePayload, error) {
+	if m.Arguments == nil {
+		return nil, fmt.Errorf("arguments are required for schedule creation messages")
+	}
+	var args ScheduleCreationArgs
+	if err := decodeFields("arguments", m.Arguments, &args); err != nil {
+		return nil, err
+	}
+	return &args, nil
+}
+
+// decodeStandingTeeTime decodes the arguments of standing_tee_time messages
+func decodeStandingTeeTime(m *Message) (MessagePayload, error) {
+	return ParseStandingTeeTimeArgs(m.Arguments)
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodePayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		msgType MessageType
+		payload map[string]interface{}
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"notification", MessageTypeNotification, map[string]interface{}{"message": "hi"}, nil, ""},
+		{"notification without text", MessageTypeNotification, map[string]interface{}{}, nil, "payload.message is required"},
+		{"notification with mistyped text", MessageTypeHelloWorld, map[string]interface{}{"message": 42}, nil, "payload.message must be a string, not a number"},
+		{"schedule creation", MessageTypeScheduleCreation, nil, map[string]interface{}{"action": "delete"}, ""},
+		{"schedule creation without arguments", MessageTypeScheduleCreation, nil, nil, "arguments are required"},
+		{"schedule creation with mistyped action", MessageTypeScheduleCreation, nil, map[string]interface{}{"action": true}, "arguments.action must be a string, not a bool"},
+		{"schedule create missing fields", MessageTypeScheduleCreation, nil, map[string]interface{}{"action": "create", "name": "x"}, "arguments.schedule_expression is required"},
+		{"type without typed payload", MessageTypeScheduled, nil, nil, "carry no typed payload"},
+		{"unknown type", MessageType("web-action"), nil, nil, "unknown message type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewMessage("test", tt.args, "1.0", StageDev, tt.msgType, tt.payload)
+			_, err := DefaultMessageTypeRegistry().DecodePayload(msg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("DecodePayload() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("DecodePayload() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodePayload_Typed(t *testing.T) {
+	msg := NewMessage("test", nil, "1.0", StageDev, MessageTypeNotification, map[string]interface{}{"message": "hi"})
+
+	notification, err := DecodePayload[*NotificationPayload](msg)
+	if err != nil {
+		t.Fatalf("DecodePayload() error = %v", err)
+	}
+	if notification.Message != "hi" {
+		t.Errorf("Message = %q, want %q", notification.Message, "hi")
+	}
+
+	if _, err := DecodePayload[*WebActionPayload](msg); err == nil {
+		t.Error("DecodePayload() into the wrong payload type should fail")
+	}
+}
+
+func TestNewPayloadMessage(t *testing.T) {
+	msg, err := NewPayloadMessage(ComponentWebAction, nil, StageDev, MessageTypeAgentResponse, &AgentResponsePayload{
+		Status:  "failed",
+		Message: "booking failed",
+		Failure: &WebActionFailure{OriginalMessageID: "msg_1", Attempts: 2},
+	})
+	if err != nil {
+		t.Fatalf("NewPayloadMessage() error = %v", err)
+	}
+
+	response, err := DecodePayload[*AgentResponsePayload](msg)
+	if err != nil {
+		t.Fatalf("DecodePayload() error = %v", err)
+	}
+	if response.Status != "failed" || response.Failure == nil || response.Failure.OriginalMessageID != "msg_1" {
+		t.Errorf("round-tripped payload = %+v", response)
+	}
+
+	if _, err := NewPayloadMessage("test", nil, StageDev, MessageTypeNotification, &NotificationPayload{}); err == nil {
+		t.Error("NewPayloadMessage() with an invalid payload should fail")
+	}
+}
+
+func TestDecodePayload_FieldError(t *testing.T) {
+	msg := NewMessage("test", map[string]interface{}{"action": "create", "name": "x", "schedule_expression": "rate(1 hour)", "target_type": "notification", "timezone": 5}, "1.0", StageDev, MessageTypeScheduleCreation, nil)
+
+	_, err := DefaultMessageTypeRegistry().DecodePayload(msg)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("DecodePayload() error = %v, want a FieldError", err)
+	}
+	if fieldErr.Field != "arguments.timezone" || fieldErr.Message != "must be a string, not a number" {
+		t.Errorf("FieldError = %+v", fieldErr)
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// remoteAgentNamePattern keeps remote agent names usable inside Bedrock tool names
+var remoteAgentNamePattern = regexp.MustCompile(`^[a-z0-9_]{1,40}$`)
+
+// RemoteAgent is an external A2A agent that scheduled agent runs may delegate subtasks to
+type RemoteAgent struct {
+	// Name identifies the agent and becomes the delegate_to_<name> tool name
+	Name string `json:"name"`
+
+	// URL is the agent's base URL; its card is served at <URL>/.well-known/agent-card
+	URL string `json:"url"`
+
+	// APIKeySecret is the Secrets Manager secret whose "api_key" value is sent as X-API-Key (optional)
+	APIKeySecret string `json:"api_key_secret,omitempty"`
+}
+
+// Validate checks that the agent has a tool-safe name and an HTTPS URL
+func (a *RemoteAgent) Validate() error {
+	if !remoteAgentNamePattern.MatchString(a.Name) {
+		return fmt.Errorf("remote agent name %q must be 1-40 lowercase letters, digits, or underscores", a.Name)
+	}
+	if !strings.HasPrefix(a.URL, "https://") {
+		return fmt.Errorf("remote agent %s URL must use https", a.Name)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// CachedReservation is one upcoming tee time copied from the golf provider by the reservation sync
+type CachedReservation struct {
+	ReservationID   int       `json:"reservation_id" dynamodbav:"reservation_id"`
+	TeeTime         time.Time `json:"tee_time" dynamodbav:"tee_time"`
+	Players         int       `json:"players" dynamodbav:"players"`
+	ConfirmationKey string    `json:"confirmation_key,omitempty" dynamodbav:"confirmation_key,omitempty"`
+}
+
+// ReservationSnapshot is one golfer's reservations at one course as of the last sync. Each sync
+// replaces the whole snapshot, so cancelled reservations drop out without a separate delete.
+type ReservationSnapshot struct {
+	// SecretName identifies the golfer by the credentials the reservations were fetched with
+	SecretName string `json:"-" dynamodbav:"secret_name"`
+
+	CourseID     int                 `json:"course_id" dynamodbav:"course_id"`
+	CourseName   string              `json:"course_name" dynamodbav:"course_name"`
+	Reservations []CachedReservation `json:"reservations" dynamodbav:"reservations"`
+	SyncedAt     time.Time           `json:"synced_at" dynamodbav:"synced_at"`
+}
+
+// Upcoming returns the reservations that have not teed off by now, earliest first
+func (s *ReservationSnapshot) Upcoming(now time.Time) []CachedReservation {
+	upcoming := make([]CachedReservation, 0, len(s.Reservations))
+	for _, r := range s.Reservations {
+		if r.TeeTime.After(now) {
+			upcoming = append(upcoming, r)
+		}
+	}
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].TeeTime.Before(upcoming[j].TeeTime)
+	})
+	return upcoming
+}
+
+// IsStale reports whether the snapshot is older than maxAge and should be refreshed from the provider
+func (s *ReservationSnapshot) IsStale(now time.Time, maxAge time.Duration) bool {
+	return now.Sub(s.SyncedAt) > maxAge
+}
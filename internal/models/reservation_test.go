@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReservationSnapshot_Upcoming(t *testing.T) {
+	now := time.Date(2026, 6, 6, 12, 0, 0, 0, time.UTC)
+	s := &ReservationSnapshot{Reservations: []CachedReservation{
+		{ReservationID: 3, TeeTime: now.Add(48 * time.Hour)},
+		{ReservationID: 1, TeeTime: now.Add(-time.Hour)},
+		{ReservationID: 2, TeeTime: now.Add(24 * time.Hour)},
+	}}
+
+	got := s.Upcoming(now)
+	if len(got) != 2 || got[0].ReservationID != 2 || got[1].ReservationID != 3 {
+		t.Errorf("Upcoming() = %+v, want reservations 2 and 3 in tee time order", got)
+	}
+}
+
+func TestReservationSnapshot_IsStale(t *testing.T) {
+	synced := time.Date(2026, 6, 6, 12, 0, 0, 0, time.UTC)
+	s := &ReservationSnapshot{SyncedAt: synced}
+
+	if s.IsStale(synced.Add(time.Hour), 2*time.Hour) {
+		t.Error("IsStale() = true within maxAge")
+	}
+	if !s.IsStale(synced.Add(3*time.Hour), 2*time.Hour) {
+		t.Error("IsStale() = false past maxAge")
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import "fmt"
+
+// MaxRunIterations is the most model calls a schedule may allow one agent run
+const MaxRunIterations = 50
+
+// RunLimits bound one scheduled agent run. A run that would go over a limit stops early and
+// notifies the golfer instead of failing. Zero values leave a limit at the scheduler's default:
+// 10 model calls and no token or cost limit.
+type RunLimits struct {
+	// MaxIterations caps the Bedrock calls made in one run
+	MaxIterations int `json:"max_iterations,omitempty" dynamodbav:"max_iterations,omitempty"`
+
+	// MaxTokens caps the input and output tokens used in one run
+	MaxTokens int64 `json:"max_tokens,omitempty" dynamodbav:"max_tokens,omitempty"`
+
+	// MaxCost caps the estimated Bedrock cost of one run, in dollars
+	MaxCost float64 `json:"max_cost,omitempty" dynamodbav:"max_cost,omitempty"`
+}
+
+// IsZero reports whether no limit is set
+func (l RunLimits) IsZero() bool {
+	return l == RunLimits{}
+}
+
+// Validate checks if the run limits are valid
+func (l RunLimits) Validate() error {
+	if l.MaxIterations < 0 || l.MaxIterations > MaxRunIterations {
+		return fmt.Errorf("max_iterations must be between 0 and %d", MaxRunIterations)
+	}
+	if l.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must not be negative")
+	}
+	if l.MaxCost < 0 {
+		return fmt.Errorf("max_cost must not be negative")
+	}
+	return nil
+}
+
+// ToArguments returns the limits that are set as message arguments
+func (l RunLimits) ToArguments() map[string]interface{} {
+	args := make(map[string]interface{})
+	if l.MaxIterations > 0 {
+		args["max_iterations"] = l.MaxIterations
+	}
+	if l.MaxTokens > 0 {
+		args["max_tokens"] = l.MaxTokens
+	}
+	if l.MaxCost > 0 {
+		args["max_cost"] = l.MaxCost
+	}
+	return args
+}
+
+// ParseRunLimitsArgs reads run limits from message arguments; missing limits are left at zero
+func ParseRunLimitsArgs(args map[string]interface{}) (RunLimits, error) {
+	var limits RunLimits
+	values := make(map[string]float64)
+	for _, name := range []string{"max_iterations", "max_tokens", "max_cost"} {
+		raw, ok := args[name]
+		if !ok || raw == nil {
+			continue
+		}
+		value, ok := numberArgument(raw)
+		if !ok {
+			return RunLimits{}, fmt.Errorf("%s must be a number", name)
+		}
+		values[name] = value
+	}
+	limits.MaxIterations = int(values["max_iterations"])
+	limits.MaxTokens = int64(values["max_tokens"])
+	limits.MaxCost = values["max_cost"]
+
+	if err := limits.Validate(); err != nil {
+		return RunLimits{}, err
+	}
+	return limits, nil
+}
+
+// numberArgument converts a numeric argument, decoded from JSON or set in Go, to a float64
+func numberArgument(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -25,12 +25,14 @@ const (
 	ScheduleStatusDeleted ScheduleStatus = "deleted"
 	// ScheduleStatusError indicates the schedule creation/update failed
 	ScheduleStatusError ScheduleStatus = "error"
+	// ScheduleStatusCompleted indicates a one-time schedule has fired
+	ScheduleStatusCompleted ScheduleStatus = "completed"
 )
 
 // IsValid checks if the schedule status value is valid
 func (s ScheduleStatus) IsValid() bool {
 	switch s {
-	case ScheduleStatusActive, ScheduleStatusPaused, ScheduleStatusDeleted, ScheduleStatusError:
+	case ScheduleStatusActive, ScheduleStatusPaused, ScheduleStatusDeleted, ScheduleStatusError, ScheduleStatusCompleted:
 		return true
 	default:
 		return false
@@ -54,12 +56,16 @@ const (
 	TargetTypeScheduler TargetType = "scheduled"
 	// TargetTypeCustom triggers a custom action
 	TargetTypeCustom TargetType = "custom"
+	// TargetTypeStandingTeeTime reminds or re-books a standing weekly tee time
+	TargetTypeStandingTeeTime TargetType = "standing_tee_time"
+	// TargetTypeWeeklyDigest emails a summary of the past week's activity
+	TargetTypeWeeklyDigest TargetType = "weekly_digest"
 )
 
 // IsValid checks if the target type value is valid
 func (t TargetType) IsValid() bool {
 	switch t {
-	case TargetTypeWebAction, TargetTypeNotification, TargetTypeCustom, TargetTypeScheduler:
+	case TargetTypeWebAction, TargetTypeNotification, TargetTypeCustom, TargetTypeScheduler, TargetTypeStandingTeeTime, TargetTypeWeeklyDigest:
 		return true
 	default:
 		return false
@@ -126,8 +132,11 @@ type Schedule struct {
 
 	// Stage is the environment (dev, stage, prod)
 	Stage Stage `json:"stage" dynamodbav:"stage"`
+
+	// RunLimits bound each agent run the schedule triggers
+	RunLimits
 	// CreateScheduleReq is the AWS SDK input used to create the EventBridge Schedule
-	CreateRequest *scheduler.CreateScheduleInput
+	CreateRequest *scheduler.CreateScheduleInput `json:"-"`
 }
 
 // NewSchedule creates a new schedule with default values
@@ -139,14 +148,27 @@ func NewSchedule(
 	execRoleArn string,
 ) (*Schedule, error) {
 
+	if msg == nil {
+		return nil, fmt.Errorf("schedule creation message is required")
+	}
+	args, err := parseScheduleArguments(msg.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
 	var scheduleOut Schedule
 
 	now := time.Now().UTC()
 	scheduleOut.ID = generateScheduleID(now)
-	scheduleOut.Name = msg.Arguments["name"].(string)
-	scheduleOut.ScheduleExpression = msg.Arguments["schedule_expression"].(string)
-	scheduleOut.Timezone = msg.Arguments["timezone"].(string)
-	scheduleOut.TargetType = TargetType(msg.Arguments["target_type"].(string))
+	scheduleOut.Name = args.Name
+	scheduleOut.ScheduleExpression = args.ScheduleExpression
+	scheduleOut.Timezone = args.Timezone
+	loc, err := time.LoadLocation(scheduleOut.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", scheduleOut.Timezone, err)
+	}
+	scheduleOut.TargetType = args.TargetType
+	scheduleOut.Description = args.Description
 	scheduleOut.Status = ScheduleStatusActive
 	scheduleOut.CreatedBy = createdBy
 	scheduleOut.CreatedDate = now
@@ -154,29 +176,62 @@ func NewSchedule(
 	scheduleOut.ExecutionCount = 0
 	scheduleOut.TargetTopicArn = targetTopicArn
 	scheduleOut.Stage = stage
-	// Validate timezone
-	if scheduleOut.Timezone == "" {
-		scheduleOut.Timezone = "UTC"
-	}
-	if _, err := time.LoadLocation(scheduleOut.Timezone); err != nil {
-		return nil, fmt.Errorf("invalid timezone %q: %w", scheduleOut.Timezone, err)
-	}
 
-	if msg.Arguments["description"] != nil {
-		scheduleOut.Description = msg.Arguments["description"].(string)
+	limits, err := ParseRunLimitsArgs(msg.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid run limits: %w", err)
 	}
+	scheduleOut.RunLimits = limits
 	// Generate EventBridge name (must be unique and conform to naming rules)
 	eventBridgeName := generateEventBridgeName(scheduleOut.Name, stage)
 
 	// Build the new Message for the Payload
 
+	// One-time schedules must fire in the future
+	if scheduleOut.IsOneTime() {
+		runAt, err := scheduleOut.RunAt()
+		if err != nil {
+			return nil, err
+		}
+		if !runAt.After(now) {
+			return nil, fmt.Errorf("at() schedule time %s is in the past", runAt.Format(time.RFC3339))
+		}
+		// EventBridge does not accept offsets in at(), so normalize to the schedule timezone
+		scheduleOut.ScheduleExpression = fmt.Sprintf("at(%s)", runAt.In(loc).Format("2006-01-02T15:04:05"))
+	}
+
 	// Only include relevant arguments for the schedule target
 	_newArgs := make(map[string]interface{})
+	// schedule_id lets the triggered handler record the execution against this schedule
+	_newArgs["schedule_id"] = scheduleOut.ID
 	if scheduleOut.TargetType == TargetTypeScheduler {
-		if msg.Arguments["operation"] != nil {
-			_newArgs["operation"] = msg.Arguments["operation"]
-		} else {
-			return nil, fmt.Errorf("missing required argument 'operation' for web_action target")
+		if args.Operation == "" {
+			return nil, fmt.Errorf("arguments.operation is required for %s schedules", TargetTypeScheduler)
+		}
+		_newArgs["operation"] = args.Operation
+	}
+	if scheduleOut.TargetType == TargetTypeStandingTeeTime {
+		standing, err := ParseStandingTeeTimeArgs(msg.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("invalid standing tee time arguments: %w", err)
+		}
+		for k, v := range standing.ToArguments() {
+			_newArgs[k] = v
+		}
+		// Standing tee time runs are built from the arguments
+		for k, v := range limits.ToArguments() {
+			_newArgs[k] = v
+		}
+	}
+	payload := msg.Payload
+	if scheduleOut.TargetType == TargetTypeScheduler && !limits.IsZero() {
+		// Scheduled agent runs are decoded from the payload, so the limits go there
+		payload = make(map[string]interface{}, len(msg.Payload)+3)
+		for k, v := range msg.Payload {
+			payload[k] = v
+		}
+		for k, v := range limits.ToArguments() {
+			payload[k] = v
 		}
 	}
 	payloadMsg := NewMessage(
@@ -185,7 +240,7 @@ func NewSchedule(
 		"1.0",
 		stage,
 		MessageType(scheduleOut.TargetType),
-		msg.Payload)
+		payload)
 
 	payloadBytes, err := json.Marshal(payloadMsg)
 	if err != nil {
@@ -207,6 +262,10 @@ func NewSchedule(
 			Input:   aws.String(string(payloadBytes)),
 		},
 	}
+	// EventBridge removes one-time schedules itself once they have fired
+	if scheduleOut.IsOneTime() {
+		scheduleOut.CreateRequest.ActionAfterCompletion = types.ActionAfterCompletionDelete
+	}
 	err = scheduleOut.Validate()
 	if err != nil {
 		return nil, fmt.Errorf("invalid schedule data: %w", err)
@@ -278,18 +337,78 @@ func (s *Schedule) MarkError(errorMessage string) {
 	s.UpdatedDate = time.Now().UTC()
 }
 
+// MarkCompleted updates the schedule status to completed
+func (s *Schedule) MarkCompleted() {
+	s.Status = ScheduleStatusCompleted
+	s.UpdatedDate = time.Now().UTC()
+}
+
 // UpdateEventBridgeArn sets the EventBridge Schedule ARN after creation
 func (s *Schedule) UpdateEventBridgeArn(arn string) {
 	s.EventBridgeArn = arn
 	s.UpdatedDate = time.Now().UTC()
 }
 
-// RecordExecution updates the last triggered time and increments execution count
+// RecordExecution updates the last triggered time and increments execution count.
+// One-time schedules are marked completed since they will not fire again.
 func (s *Schedule) RecordExecution() {
 	now := time.Now().UTC()
 	s.LastTriggered = &now
 	s.ExecutionCount++
 	s.UpdatedDate = now
+	if s.IsOneTime() {
+		s.Status = ScheduleStatusCompleted
+	}
+}
+
+// IsOneTime reports whether the schedule uses an at() expression
+func (s *Schedule) IsOneTime() bool {
+	return IsOneTimeExpression(s.ScheduleExpression)
+}
+
+// RunAt returns the time a one-time schedule fires
+func (s *Schedule) RunAt() (time.Time, error) {
+	return ParseAtExpression(s.ScheduleExpression, s.Timezone)
+}
+
+// TimeRemaining returns how long until a one-time schedule fires.
+// The boolean is false for recurring schedules and for schedules that are no longer active.
+func (s *Schedule) TimeRemaining(now time.Time) (time.Duration, bool) {
+	if !s.IsOneTime() || s.Status != ScheduleStatusActive {
+		return 0, false
+	}
+	runAt, err := s.RunAt()
+	if err != nil {
+		return 0, false
+	}
+	remaining := runAt.Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// NextRunAt returns the next time the schedule fires after now.
+// The boolean is false when the schedule is not active or will not fire again.
+func (s *Schedule) NextRunAt(now time.Time) (time.Time, bool) {
+	if s.Status != ScheduleStatusActive {
+		return time.Time{}, false
+	}
+	next, err := NextRunTime(s.ScheduleExpression, s.Timezone, s.CreatedDate, now)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// RunsBetween returns the times the schedule fires after from and up to to, at most limit of them.
+// Schedules that are not active never fire.
+func (s *Schedule) RunsBetween(from, to time.Time, limit int) []time.Time {
+	var runs []time.Time
+	for next, ok := s.NextRunAt(from); ok && !next.After(to) && len(runs) < limit; next, ok = s.NextRunAt(next) {
+		runs = append(runs, next)
+	}
+	return runs
 }
 
 // Validate checks if the schedule has valid required fields
@@ -318,6 +437,13 @@ func (s *Schedule) Validate() error {
 		return fmt.Errorf("invalid schedule expression: %w", err)
 	}
 
+	if err := s.RunLimits.Validate(); err != nil {
+		return fmt.Errorf("invalid run limits: %w", err)
+	}
+	if !s.RunLimits.IsZero() && s.TargetType != TargetTypeScheduler && s.TargetType != TargetTypeStandingTeeTime {
+		return fmt.Errorf("run limits only apply to agent schedules, not %s", s.TargetType)
+	}
+
 	return nil
 }
 
@@ -368,6 +494,39 @@ func ValidateScheduleExpression(expr string) error {
 	return fmt.Errorf("schedule expression must start with rate(), cron(), or at()")
 }
 
+// IsOneTimeExpression reports whether expr is an at() expression
+func IsOneTimeExpression(expr string) bool {
+	expr = strings.TrimSpace(expr)
+	return strings.HasPrefix(expr, "at(") && strings.HasSuffix(expr, ")")
+}
+
+// ParseAtExpression returns the instant an at() expression fires.
+// Timestamps without an offset are interpreted in the given IANA timezone (default UTC).
+func ParseAtExpression(expr, timezone string) (time.Time, error) {
+	if !IsOneTimeExpression(expr) {
+		return time.Time{}, fmt.Errorf("not an at() expression: %s", expr)
+	}
+	expr = strings.TrimSpace(expr)
+	atContent := expr[3 : len(expr)-1]
+
+	if t, err := time.Parse(time.RFC3339, atContent); err == nil {
+		return t.UTC(), nil
+	}
+
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04:05", atContent, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("at() expression must be in ISO 8601 format: yyyy-mm-ddThh:mm:ss")
+	}
+	return t.UTC(), nil
+}
+
 /*/ ScheduleCreationRequest represents the request to create a schedule
 type ScheduleCreationRequest struct {
 	Action   string             `json:"action"` // "create", "update", "delete", "pause", "resume"
@@ -400,7 +559,7 @@ func (sd *ScheduleDefinition) Validate() error {
 	// Validate target type
 	targetType := TargetType(sd.TargetType)
 	if !targetType.IsValid() {
-		return fmt.Errorf("invalid target type: %s (must be one of: web_action, notification, custom)", sd.TargetType)
+		return fmt.Errorf("invalid target type: %s (must be one of: web_action, notification, scheduled, custom, standing_tee_time)", sd.TargetType)
 	}
 
 	// Validate schedule expression
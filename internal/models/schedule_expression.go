@@ -0,0 +1,298 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears bounds how far ahead NextRunTime looks for a matching cron time
+const cronSearchYears = 5
+
+// NextRunTime returns the first time after `after` that the schedule expression fires.
+// Cron and at() expressions are evaluated in the given IANA timezone (default UTC);
+// rate() expressions repeat from anchor, which is normally the schedule creation time.
+// The result is returned in UTC.
+func NextRunTime(expr, timezone string, anchor, after time.Time) (time.Time, error) {
+	expr = strings.TrimSpace(expr)
+
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+
+	switch {
+	case IsOneTimeExpression(expr):
+		runAt, err := ParseAtExpression(expr, timezone)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !runAt.After(after) {
+			return time.Time{}, fmt.Errorf("one-time schedule already ran at %s", runAt.Format(time.RFC3339))
+		}
+		return runAt, nil
+
+	case strings.HasPrefix(expr, "rate(") && strings.HasSuffix(expr, ")"):
+		interval, err := ParseRateExpression(expr)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if anchor.After(after) {
+			return anchor.UTC(), nil
+		}
+		periods := after.Sub(anchor)/interval + 1
+		return anchor.Add(periods * interval).UTC(), nil
+
+	case strings.HasPrefix(expr, "cron(") && strings.HasSuffix(expr, ")"):
+		cron, err := parseCronExpression(expr)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return cron.next(after.In(loc))
+
+	default:
+		return time.Time{}, fmt.Errorf("schedule expression must start with rate(), cron(), or at()")
+	}
+}
+
+// ParseRateExpression returns the interval of a rate(value unit) expression
+func ParseRateExpression(expr string) (time.Duration, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "rate(") || !strings.HasSuffix(expr, ")") {
+		return 0, fmt.Errorf("not a rate() expression: %s", expr)
+	}
+
+	parts := strings.Fields(expr[5 : len(expr)-1])
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("rate expression must have format: rate(value unit)")
+	}
+
+	value, err := strconv.Atoi(parts[0])
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("rate value must be a positive integer: %s", parts[0])
+	}
+
+	var unit time.Duration
+	switch strings.ToLower(parts[1]) {
+	case "minute", "minutes":
+		unit = time.Minute
+	case "hour", "hours":
+		unit = time.Hour
+	case "day", "days":
+		unit = 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("rate unit must be minute(s), hour(s), or day(s): %s", parts[1])
+	}
+
+	return time.Duration(value) * unit, nil
+}
+
+// cronExpression is a parsed EventBridge cron(Minutes Hours Day-of-month Month Day-of-week Year)
+type cronExpression struct {
+	minutes map[int]bool
+	hours   map[int]bool
+	months  map[int]bool
+	years   map[int]bool // nil matches any year
+
+	// Exactly one of the day fields is set; the other is '?'
+	daysOfMonth    map[int]bool // nil when day-of-month is '?'
+	lastDayOfMonth bool         // day-of-month is L
+	daysOfWeek     map[int]bool // 1=SUN..7=SAT, nil when day-of-week is '?' or uses #
+	nthWeekday     int          // day-of-week N#M: weekday N
+	nthOccurrence  int          // day-of-week N#M: occurrence M (1-5)
+}
+
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronWeekdayNames = map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}
+
+// parseCronExpression parses an EventBridge cron() expression. Supports *, ?, lists,
+// ranges, steps, month/weekday names, L in day-of-month and N#M in day-of-week.
+func parseCronExpression(expr string) (*cronExpression, error) {
+	fields := strings.Fields(expr[5 : len(expr)-1])
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("cron expression must have 6 fields: Minutes Hours Day-of-month Month Day-of-week Year")
+	}
+
+	cron := &cronExpression{}
+	var err error
+
+	if cron.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minutes field: %w", err)
+	}
+	if cron.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hours field: %w", err)
+	}
+	if cron.months, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+	if fields[5] != "*" {
+		if cron.years, err = parseCronField(fields[5], 1970, 2199, nil); err != nil {
+			return nil, fmt.Errorf("invalid year field: %w", err)
+		}
+	}
+
+	dom, dow := fields[2], fields[4]
+	if (dom == "?") == (dow == "?") {
+		return nil, fmt.Errorf("exactly one of day-of-month or day-of-week must be '?'")
+	}
+
+	if dom != "?" {
+		if strings.ToUpper(dom) == "L" {
+			cron.lastDayOfMonth = true
+		} else if cron.daysOfMonth, err = parseCronField(dom, 1, 31, nil); err != nil {
+			return nil, fmt.Errorf("invalid day-of-month field: %w", err)
+		}
+		return cron, nil
+	}
+
+	if weekday, occurrence, ok := strings.Cut(dow, "#"); ok {
+		days, err := parseCronField(weekday, 1, 7, cronWeekdayNames)
+		if err != nil || len(days) != 1 {
+			return nil, fmt.Errorf("invalid day-of-week field: %s", dow)
+		}
+		n, err := strconv.Atoi(occurrence)
+		if err != nil || n < 1 || n > 5 {
+			return nil, fmt.Errorf("invalid day-of-week occurrence: %s", dow)
+		}
+		for d := range days {
+			cron.nthWeekday = d
+		}
+		cron.nthOccurrence = n
+		return cron, nil
+	}
+
+	if cron.daysOfWeek, err = parseCronField(dow, 1, 7, cronWeekdayNames); err != nil {
+		return nil, fmt.Errorf("invalid day-of-week field: %w", err)
+	}
+	return cron, nil
+}
+
+// parseCronField expands a single cron field into the set of values it matches
+func parseCronField(field string, minValue, maxValue int, names map[string]int) (map[int]bool, error) {
+	values := make(map[int]bool)
+
+	parseValue := func(s string) (int, error) {
+		if v, ok := names[strings.ToUpper(s)]; ok {
+			return v, nil
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %q", s)
+		}
+		if v < minValue || v > maxValue {
+			return 0, fmt.Errorf("value %d out of range %d-%d", v, minValue, maxValue)
+		}
+		return v, nil
+	}
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*":
+			start, end = minValue, maxValue
+		case strings.Contains(rangePart, "-"):
+			lo, hi, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseValue(lo); err != nil {
+				return nil, err
+			}
+			if end, err = parseValue(hi); err != nil {
+				return nil, err
+			}
+			if start > end {
+				return nil, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			v, err := parseValue(rangePart)
+			if err != nil {
+				return nil, err
+			}
+			start, end = v, v
+			if hasStep {
+				end = maxValue
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+// matchesDay reports whether the cron expression fires on the given calendar day
+func (c *cronExpression) matchesDay(day time.Time) bool {
+	if c.years != nil && !c.years[day.Year()] {
+		return false
+	}
+	if !c.months[int(day.Month())] {
+		return false
+	}
+
+	switch {
+	case c.lastDayOfMonth:
+		return day.AddDate(0, 0, 1).Day() == 1
+	case c.daysOfMonth != nil:
+		return c.daysOfMonth[day.Day()]
+	case c.nthOccurrence > 0:
+		return int(day.Weekday())+1 == c.nthWeekday && (day.Day()-1)/7+1 == c.nthOccurrence
+	default:
+		return c.daysOfWeek[int(day.Weekday())+1]
+	}
+}
+
+// next returns the first matching minute strictly after `after`, evaluated in after's location
+func (c *cronExpression) next(after time.Time) (time.Time, error) {
+	loc := after.Location()
+	start := after.Truncate(time.Minute).Add(time.Minute)
+	limit := start.AddDate(cronSearchYears, 0, 0)
+
+	y, m, d := start.Date()
+	for day := time.Date(y, m, d, 0, 0, 0, 0, loc); day.Before(limit); day = day.AddDate(0, 0, 1) {
+		if !c.matchesDay(day) {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if !c.hours[hour] {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if !c.minutes[minute] {
+					continue
+				}
+				candidate := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+				// Skip times that don't exist locally (DST gaps normalize to a different hour)
+				if candidate.Hour() != hour {
+					continue
+				}
+				if !candidate.Before(start) {
+					return candidate.UTC(), nil
+				}
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cron expression has no run time in the next %d years", cronSearchYears)
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextRunTime(t *testing.T) {
+	// 2030-06-05 is a Wednesday
+	after := time.Date(2030, 6, 5, 14, 30, 0, 0, time.UTC)
+	anchor := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		timezone string
+		want     time.Time
+		wantErr  bool
+	}{
+		{"daily at noon UTC is tomorrow", "cron(0 12 * * ? *)", "", time.Date(2030, 6, 6, 12, 0, 0, 0, time.UTC), false},
+		{"daily later today", "cron(45 14 * * ? *)", "UTC", time.Date(2030, 6, 5, 14, 45, 0, 0, time.UTC), false},
+		{"honors timezone", "cron(0 12 * * ? *)", "America/New_York", time.Date(2030, 6, 5, 16, 0, 0, 0, time.UTC), false},
+		{"weekday name", "cron(0 8 ? * SAT *)", "UTC", time.Date(2030, 6, 8, 8, 0, 0, 0, time.UTC), false},
+		{"weekday range", "cron(0 6 ? * MON-FRI *)", "UTC", time.Date(2030, 6, 6, 6, 0, 0, 0, time.UTC), false},
+		{"minute step", "cron(0/20 * * * ? *)", "UTC", time.Date(2030, 6, 5, 14, 40, 0, 0, time.UTC), false},
+		{"last day of month", "cron(0 9 L * ? *)", "UTC", time.Date(2030, 6, 30, 9, 0, 0, 0, time.UTC), false},
+		{"second tuesday", "cron(0 9 ? * 3#2 *)", "UTC", time.Date(2030, 6, 11, 9, 0, 0, 0, time.UTC), false},
+		{"month list", "cron(0 0 1 JAN,JUL ? *)", "UTC", time.Date(2030, 7, 1, 0, 0, 0, 0, time.UTC), false},
+		{"past year", "cron(0 0 1 1 ? 2020)", "UTC", time.Time{}, true},
+		{"both day fields set", "cron(0 12 * * * *)", "UTC", time.Time{}, true},
+		{"out of range hour", "cron(0 25 * * ? *)", "UTC", time.Time{}, true},
+		{"rate from anchor", "rate(1 day)", "UTC", time.Date(2030, 6, 6, 0, 0, 0, 0, time.UTC), false},
+		{"rate minutes", "rate(45 minutes)", "UTC", time.Date(2030, 6, 5, 15, 0, 0, 0, time.UTC), false},
+		{"bad rate unit", "rate(1 week)", "UTC", time.Time{}, true},
+		{"future at", "at(2030-06-10T08:00:00)", "America/New_York", time.Date(2030, 6, 10, 12, 0, 0, 0, time.UTC), false},
+		{"past at", "at(2030-06-01T08:00:00)", "UTC", time.Time{}, true},
+		{"bad timezone", "cron(0 12 * * ? *)", "Mars/Olympus", time.Time{}, true},
+		{"unknown expression", "every day", "UTC", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextRunTime(tt.expr, tt.timezone, anchor, after)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NextRunTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextRunTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_NextRunAt(t *testing.T) {
+	now := time.Date(2030, 6, 5, 14, 30, 0, 0, time.UTC)
+	schedule := &Schedule{
+		ScheduleExpression: "cron(0 12 * * ? *)",
+		Timezone:           "UTC",
+		Status:             ScheduleStatusActive,
+		CreatedDate:        now.Add(-time.Hour),
+	}
+
+	next, ok := schedule.NextRunAt(now)
+	if !ok {
+		t.Fatal("NextRunAt() ok = false for active schedule")
+	}
+	if want := time.Date(2030, 6, 6, 12, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("NextRunAt() = %v, want %v", next, want)
+	}
+
+	schedule.MarkPaused()
+	if _, ok := schedule.NextRunAt(now); ok {
+		t.Error("NextRunAt() ok = true for paused schedule")
+	}
+}
@@ -0,0 +1,373 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/scheduler/types"
+)
+
+func TestIsOneTimeExpression(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		{"at expression", "at(2030-01-02T15:04:05)", true},
+		{"at expression with spaces", "  at(2030-01-02T15:04:05) ", true},
+		{"cron expression", "cron(0 12 * * ? *)", false},
+		{"rate expression", "rate(1 hour)", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsOneTimeExpression(tt.expr); got != tt.want {
+				t.Errorf("IsOneTimeExpression(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAtExpression(t *testing.T) {
+	ny, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name     string
+		expr     string
+		timezone string
+		want     time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "local time in UTC",
+			expr:     "at(2030-06-01T08:30:00)",
+			timezone: "UTC",
+			want:     time.Date(2030, 6, 1, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "local time defaults to UTC",
+			expr:     "at(2030-06-01T08:30:00)",
+			timezone: "",
+			want:     time.Date(2030, 6, 1, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "local time in named timezone",
+			expr:     "at(2030-06-01T08:30:00)",
+			timezone: "America/New_York",
+			want:     time.Date(2030, 6, 1, 8, 30, 0, 0, ny).UTC(),
+		},
+		{
+			name:     "RFC3339 offset wins over timezone",
+			expr:     "at(2030-06-01T08:30:00Z)",
+			timezone: "America/New_York",
+			want:     time.Date(2030, 6, 1, 8, 30, 0, 0, time.UTC),
+		},
+		{"cron is rejected", "cron(0 12 * * ? *)", "UTC", time.Time{}, true},
+		{"bad timestamp", "at(tomorrow)", "UTC", time.Time{}, true},
+		{"bad timezone", "at(2030-06-01T08:30:00)", "Mars/Olympus", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAtExpression(tt.expr, tt.timezone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAtExpression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseAtExpression() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_TimeRemaining(t *testing.T) {
+	now := time.Date(2030, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     time.Duration
+		wantOK   bool
+	}{
+		{
+			name:     "active one-time schedule",
+			schedule: Schedule{ScheduleExpression: "at(2030-06-01T08:30:00)", Timezone: "UTC", Status: ScheduleStatusActive},
+			want:     30 * time.Minute,
+			wantOK:   true,
+		},
+		{
+			name:     "overdue one-time schedule clamps to zero",
+			schedule: Schedule{ScheduleExpression: "at(2030-06-01T07:00:00)", Timezone: "UTC", Status: ScheduleStatusActive},
+			want:     0,
+			wantOK:   true,
+		},
+		{
+			name:     "completed one-time schedule",
+			schedule: Schedule{ScheduleExpression: "at(2030-06-01T08:30:00)", Timezone: "UTC", Status: ScheduleStatusCompleted},
+			wantOK:   false,
+		},
+		{
+			name:     "recurring schedule",
+			schedule: Schedule{ScheduleExpression: "rate(1 hour)", Timezone: "UTC", Status: ScheduleStatusActive},
+			wantOK:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.schedule.TimeRemaining(now)
+			if ok != tt.wantOK {
+				t.Fatalf("TimeRemaining() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("TimeRemaining() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_RunsBetween(t *testing.T) {
+	from := time.Date(2030, 6, 1, 8, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+
+	daily := Schedule{ScheduleExpression: "cron(0 7 * * ? *)", Timezone: "UTC", Status: ScheduleStatusActive}
+	if runs := daily.RunsBetween(from, to, 100); len(runs) != 7 {
+		t.Errorf("RunsBetween() = %d runs of a daily schedule in a week, want 7", len(runs))
+	}
+	if runs := daily.RunsBetween(from, to, 3); len(runs) != 3 {
+		t.Errorf("RunsBetween() = %d runs, want the limit of 3", len(runs))
+	}
+
+	oneTime := Schedule{ScheduleExpression: "at(2030-06-02T09:00:00)", Timezone: "UTC", Status: ScheduleStatusActive}
+	if runs := oneTime.RunsBetween(from, to, 100); len(runs) != 1 || !runs[0].Equal(time.Date(2030, 6, 2, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("RunsBetween() = %v, want the one run", runs)
+	}
+
+	paused := Schedule{ScheduleExpression: "rate(1 hour)", Timezone: "UTC", Status: ScheduleStatusPaused}
+	if runs := paused.RunsBetween(from, to, 100); len(runs) != 0 {
+		t.Errorf("RunsBetween() = %v, want no runs of a paused schedule", runs)
+	}
+}
+
+func TestSchedule_RecordExecution(t *testing.T) {
+	tests := []struct {
+		name       string
+		expr       string
+		wantStatus ScheduleStatus
+	}{
+		{"one-time schedule completes", "at(2030-06-01T08:30:00)", ScheduleStatusCompleted},
+		{"recurring schedule stays active", "cron(0 12 * * ? *)", ScheduleStatusActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Schedule{ScheduleExpression: tt.expr, Timezone: "UTC", Status: ScheduleStatusActive}
+			s.RecordExecution()
+			if s.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", s.Status, tt.wantStatus)
+			}
+			if s.ExecutionCount != 1 {
+				t.Errorf("ExecutionCount = %d, want 1", s.ExecutionCount)
+			}
+			if s.LastTriggered == nil {
+				t.Error("LastTriggered should be set")
+			}
+		})
+	}
+}
+
+func TestNewSchedule_InvalidArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   interface{}
+		wantErr string
+	}{
+		{"missing name", "name", nil, "arguments.name is required"},
+		{"mistyped name", "name", 42, "arguments.name must be a string, not a number"},
+		{"missing schedule expression", "schedule_expression", nil, "arguments.schedule_expression is required"},
+		{"mistyped schedule expression", "schedule_expression", []interface{}{"rate(1 hour)"}, "arguments.schedule_expression must be a string, not a array"},
+		{"invalid schedule expression", "schedule_expression", "every hour", "arguments.schedule_expression is invalid"},
+		{"missing timezone", "timezone", nil, "arguments.timezone is required"},
+		{"mistyped timezone", "timezone", true, "arguments.timezone must be a string, not a bool"},
+		{"unknown timezone", "timezone", "Mars/Olympus_Mons", "arguments.timezone \"Mars/Olympus_Mons\" is not a valid IANA timezone"},
+		{"missing target type", "target_type", nil, "arguments.target_type is required"},
+		{"mistyped target type", "target_type", map[string]interface{}{"type": "notification"}, "arguments.target_type must be a models.TargetType, not a object"},
+		{"unknown target type", "target_type", "fax", "arguments.target_type \"fax\" is not a valid target type"},
+		{"mistyped description", "description", 1.5, "arguments.description must be a string, not a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{
+				"name":                "reminder",
+				"schedule_expression": "rate(1 hour)",
+				"timezone":            "UTC",
+				"target_type":         "notification",
+			}
+			if tt.value == nil {
+				delete(args, tt.key)
+			} else {
+				args[tt.key] = tt.value
+			}
+
+			_, err := NewSchedule(&Message{Arguments: args}, "test", "arn:aws:sns:us-east-1:123456789012:topic", StageDev, "arn:aws:iam::123456789012:role/exec")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewSchedule() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSchedule_MissingArguments(t *testing.T) {
+	if _, err := NewSchedule(nil, "test", "topic", StageDev, "role"); err == nil {
+		t.Error("NewSchedule(nil) should fail")
+	}
+	if _, err := NewSchedule(&Message{}, "test", "topic", StageDev, "role"); err == nil || !strings.Contains(err.Error(), "arguments are required") {
+		t.Errorf("NewSchedule() without arguments error = %v", err)
+	}
+	msg := &Message{Arguments: map[string]interface{}{
+		"name":                "agent run",
+		"schedule_expression": "rate(1 day)",
+		"timezone":            "UTC",
+		"target_type":         "scheduled",
+	}}
+	if _, err := NewSchedule(msg, "test", "topic", StageDev, "role"); err == nil || !strings.Contains(err.Error(), "arguments.operation is required") {
+		t.Errorf("NewSchedule() without an operation error = %v", err)
+	}
+}
+
+func TestNewSchedule_OneTime(t *testing.T) {
+	future := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		expr           string
+		wantErr        bool
+		wantExpr       string
+		wantAutoDelete bool
+	}{
+		{
+			name:           "future at() auto-deletes",
+			expr:           "at(" + future.Format("2006-01-02T15:04:05") + ")",
+			wantExpr:       "at(" + future.Format("2006-01-02T15:04:05") + ")",
+			wantAutoDelete: true,
+		},
+		{
+			name:           "RFC3339 at() is normalized",
+			expr:           "at(" + future.Format(time.RFC3339) + ")",
+			wantExpr:       "at(" + future.Format("2006-01-02T15:04:05") + ")",
+			wantAutoDelete: true,
+		},
+		{
+			name:    "past at() is rejected",
+			expr:    "at(2001-01-01T00:00:00)",
+			wantErr: true,
+		},
+		{
+			name:     "cron keeps default completion action",
+			expr:     "cron(0 12 * * ? *)",
+			wantExpr: "cron(0 12 * * ? *)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Message{
+				Arguments: map[string]interface{}{
+					"name":                "reminder",
+					"schedule_expression": tt.expr,
+					"timezone":            "UTC",
+					"target_type":         "notification",
+				},
+			}
+			s, err := NewSchedule(msg, "test", "arn:aws:sns:us-east-1:123456789012:topic", StageDev, "arn:aws:iam::123456789012:role/exec")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if s.ScheduleExpression != tt.wantExpr {
+				t.Errorf("ScheduleExpression = %q, want %q", s.ScheduleExpression, tt.wantExpr)
+			}
+			autoDelete := s.CreateRequest.ActionAfterCompletion == types.ActionAfterCompletionDelete
+			if autoDelete != tt.wantAutoDelete {
+				t.Errorf("ActionAfterCompletion = %q, want auto-delete %v", s.CreateRequest.ActionAfterCompletion, tt.wantAutoDelete)
+			}
+			if *s.CreateRequest.ScheduleExpression != s.ScheduleExpression {
+				t.Errorf("CreateRequest expression = %q, want %q", *s.CreateRequest.ScheduleExpression, s.ScheduleExpression)
+			}
+		})
+	}
+}
+
+func TestNewSchedule_RunLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		targetType string
+		limits     map[string]interface{}
+		wantErr    bool
+		want       RunLimits
+	}{
+		{
+			name:       "agent schedule carries its limits",
+			targetType: "scheduled",
+			limits:     map[string]interface{}{"max_iterations": 5.0, "max_tokens": 20000.0, "max_cost": 0.25},
+			want:       RunLimits{MaxIterations: 5, MaxTokens: 20000, MaxCost: 0.25},
+		},
+		{
+			name:       "too many iterations",
+			targetType: "scheduled",
+			limits:     map[string]interface{}{"max_iterations": MaxRunIterations + 1},
+			wantErr:    true,
+		},
+		{
+			name:       "not a number",
+			targetType: "scheduled",
+			limits:     map[string]interface{}{"max_cost": "a dollar"},
+			wantErr:    true,
+		},
+		{
+			name:       "limits on a notification schedule",
+			targetType: "notification",
+			limits:     map[string]interface{}{"max_tokens": 1000},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{
+				"name":                "booking",
+				"schedule_expression": "cron(0 12 * * ? *)",
+				"timezone":            "UTC",
+				"target_type":         tt.targetType,
+				"operation":           "book",
+			}
+			for k, v := range tt.limits {
+				args[k] = v
+			}
+			msg := &Message{Arguments: args, Payload: map[string]interface{}{"user_prompt": "Book a tee time"}}
+
+			s, err := NewSchedule(msg, "test", "arn:aws:sns:us-east-1:123456789012:topic", StageDev, "arn:aws:iam::123456789012:role/exec")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if s.RunLimits != tt.want {
+				t.Errorf("RunLimits = %+v, want %+v", s.RunLimits, tt.want)
+			}
+			if input := *s.CreateRequest.Target.Input; !strings.Contains(input, `"max_tokens":20000`) || !strings.Contains(input, `"user_prompt":"Book a tee time"`) {
+				t.Errorf("target input = %s, want the limits added to the payload", input)
+			}
+			if _, ok := msg.Payload["max_tokens"]; ok {
+				t.Error("NewSchedule() should not modify the request's payload")
+			}
+		})
+	}
+}
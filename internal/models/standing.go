@@ -0,0 +1,267 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StandingMode controls what happens when a standing tee time's booking window opens
+type StandingMode string
+
+const (
+	// StandingModeRemind sends a reminder that the booking window is open
+	StandingModeRemind StandingMode = "remind"
+	// StandingModeRenew books the same slot again automatically
+	StandingModeRenew StandingMode = "renew"
+)
+
+// IsValid checks if the standing mode value is valid
+func (m StandingMode) IsValid() bool {
+	switch m {
+	case StandingModeRemind, StandingModeRenew:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the string representation of the standing mode
+func (m StandingMode) String() string {
+	return string(m)
+}
+
+// ReservationRecord is a minimal view of a reservation used for pattern detection
+type ReservationRecord struct {
+	TeeTime         time.Time `json:"tee_time"`
+	CourseName      string    `json:"course_name,omitempty"`
+	NumberOfPlayers int       `json:"num_players"`
+}
+
+// StandingTeeTime is a reservation that recurs on the same weekday and time every week
+type StandingTeeTime struct {
+	// CourseName is the golf course the standing tee time is at
+	CourseName string `json:"course_name,omitempty"`
+
+	// Weekday is the day of week the tee time falls on
+	Weekday time.Weekday `json:"weekday"`
+
+	// TimeOfDay is the local tee time in HH:MM (24h) format
+	TimeOfDay string `json:"time_of_day"`
+
+	// NumberOfPlayers is the party size used for the reservation
+	NumberOfPlayers int `json:"num_players"`
+
+	// Occurrences is the number of consecutive weeks the pattern was seen
+	Occurrences int `json:"occurrences,omitempty"`
+
+	// LastDate is the most recent reservation matching the pattern
+	LastDate time.Time `json:"last_date,omitempty"`
+
+	// Mode is remind or renew
+	Mode StandingMode `json:"mode"`
+}
+
+// DetectStandingTeeTimes finds reservations that repeat on the same weekday and time
+// for at least minWeeks consecutive weeks. Results are ordered by weekday then time.
+func DetectStandingTeeTimes(records []ReservationRecord, minWeeks int) []StandingTeeTime {
+	if minWeeks < 2 {
+		minWeeks = 2
+	}
+
+	type slotKey struct {
+		course    string
+		weekday   time.Weekday
+		timeOfDay string
+	}
+
+	grouped := make(map[slotKey][]ReservationRecord)
+	for _, r := range records {
+		if r.TeeTime.IsZero() {
+			continue
+		}
+		key := slotKey{
+			course:    r.CourseName,
+			weekday:   r.TeeTime.Weekday(),
+			timeOfDay: r.TeeTime.Format("15:04"),
+		}
+		grouped[key] = append(grouped[key], r)
+	}
+
+	var standing []StandingTeeTime
+	for key, group := range grouped {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].TeeTime.Before(group[j].TeeTime)
+		})
+
+		// Find the longest run of reservations exactly one week apart
+		best, run := 1, 1
+		bestEnd := 0
+		for i := 1; i < len(group); i++ {
+			days := daysBetween(group[i-1].TeeTime, group[i].TeeTime)
+			switch days {
+			case 0:
+				// Duplicate booking for the same slot, doesn't break the run
+				continue
+			case 7:
+				run++
+			default:
+				run = 1
+			}
+			if run > best {
+				best = run
+				bestEnd = i
+			}
+		}
+
+		if best < minWeeks {
+			continue
+		}
+
+		last := group[len(group)-1]
+		standing = append(standing, StandingTeeTime{
+			CourseName:      key.course,
+			Weekday:         key.weekday,
+			TimeOfDay:       key.timeOfDay,
+			NumberOfPlayers: group[bestEnd].NumberOfPlayers,
+			Occurrences:     best,
+			LastDate:        last.TeeTime,
+			Mode:            StandingModeRemind,
+		})
+	}
+
+	sort.Slice(standing, func(i, j int) bool {
+		if standing[i].Weekday != standing[j].Weekday {
+			return standing[i].Weekday < standing[j].Weekday
+		}
+		if standing[i].TimeOfDay != standing[j].TimeOfDay {
+			return standing[i].TimeOfDay < standing[j].TimeOfDay
+		}
+		return standing[i].CourseName < standing[j].CourseName
+	})
+
+	return standing
+}
+
+// daysBetween returns the number of calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	start := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	end := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
+
+// Validate checks if the standing tee time has valid fields
+func (s *StandingTeeTime) Validate() error {
+	if s.Weekday < time.Sunday || s.Weekday > time.Saturday {
+		return fmt.Errorf("invalid weekday: %d", s.Weekday)
+	}
+	if _, err := time.Parse("15:04", s.TimeOfDay); err != nil {
+		return fmt.Errorf("time_of_day must be in HH:MM format: %s", s.TimeOfDay)
+	}
+	if s.NumberOfPlayers < 1 || s.NumberOfPlayers > 4 {
+		return fmt.Errorf("num_players must be between 1 and 4")
+	}
+	if !s.Mode.IsValid() {
+		return fmt.Errorf("invalid standing mode: %s (must be remind or renew)", s.Mode)
+	}
+	return nil
+}
+
+// RenewalScheduleExpression returns the weekly cron expression that fires when the booking
+// window for this standing tee time opens. bookingWindowDays is how far in advance the course
+// allows bookings; opensAt is the HH:MM local time the window opens (defaults to the tee time).
+func (s *StandingTeeTime) RenewalScheduleExpression(bookingWindowDays int, opensAt string) (string, error) {
+	if opensAt == "" {
+		opensAt = s.TimeOfDay
+	}
+	t, err := time.Parse("15:04", opensAt)
+	if err != nil {
+		return "", fmt.Errorf("opens_at must be in HH:MM format: %s", opensAt)
+	}
+	if bookingWindowDays < 0 {
+		return "", fmt.Errorf("booking window days must not be negative")
+	}
+
+	openDay := (int(s.Weekday) - bookingWindowDays%7 + 7) % 7
+	dayName := strings.ToUpper(time.Weekday(openDay).String()[:3])
+
+	return fmt.Sprintf("cron(%d %d ? * %s *)", t.Minute(), t.Hour(), dayName), nil
+}
+
+// TargetDate returns the date of the tee time that becomes bookable when the window opens
+// at triggeredAt, i.e. the first matching weekday at least bookingWindowDays out.
+func (s *StandingTeeTime) TargetDate(triggeredAt time.Time, bookingWindowDays int) time.Time {
+	target := triggeredAt.AddDate(0, 0, bookingWindowDays)
+	for target.Weekday() != s.Weekday {
+		target = target.AddDate(0, 0, 1)
+	}
+	y, m, d := target.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, target.Location())
+}
+
+// ToArguments converts the standing tee time to message arguments for a schedule payload
+func (s *StandingTeeTime) ToArguments() map[string]interface{} {
+	return map[string]interface{}{
+		"course_name":      s.CourseName,
+		"standing_weekday": s.Weekday.String(),
+		"standing_time":    s.TimeOfDay,
+		"num_players":      s.NumberOfPlayers,
+		"standing_mode":    s.Mode.String(),
+	}
+}
+
+// ParseStandingTeeTimeArgs builds a StandingTeeTime from schedule or message arguments
+func ParseStandingTeeTimeArgs(args map[string]interface{}) (*StandingTeeTime, error) {
+	if args == nil {
+		return nil, fmt.Errorf("standing tee time arguments are required")
+	}
+
+	standing := &StandingTeeTime{
+		NumberOfPlayers: 1,
+		Mode:            StandingModeRemind,
+	}
+
+	if v, ok := args["course_name"].(string); ok {
+		standing.CourseName = v
+	}
+
+	weekdayName, _ := args["standing_weekday"].(string)
+	weekday, err := parseWeekday(weekdayName)
+	if err != nil {
+		return nil, err
+	}
+	standing.Weekday = weekday
+
+	standing.TimeOfDay, _ = args["standing_time"].(string)
+
+	switch v := args["num_players"].(type) {
+	case int:
+		standing.NumberOfPlayers = v
+	case float64:
+		standing.NumberOfPlayers = int(v)
+	}
+
+	if v, ok := args["standing_mode"].(string); ok && v != "" {
+		standing.Mode = StandingMode(v)
+	}
+
+	if err := standing.Validate(); err != nil {
+		return nil, err
+	}
+	return standing, nil
+}
+
+// parseWeekday parses a full or three-letter weekday name (case-insensitive)
+func parseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid standing_weekday: %q", name)
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectStandingTeeTimes(t *testing.T) {
+	sat := func(week int, hour, minute int) ReservationRecord {
+		// 2030-06-01 is a Saturday
+		return ReservationRecord{
+			TeeTime:         time.Date(2030, 6, 1+7*week, hour, minute, 0, 0, time.UTC),
+			CourseName:      "Birdsfoot Golf Course",
+			NumberOfPlayers: 2,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		records   []ReservationRecord
+		minWeeks  int
+		wantCount int
+		wantWeeks int
+	}{
+		{
+			name:      "three consecutive saturdays",
+			records:   []ReservationRecord{sat(0, 8, 0), sat(1, 8, 0), sat(2, 8, 0)},
+			minWeeks:  3,
+			wantCount: 1,
+			wantWeeks: 3,
+		},
+		{
+			name:      "unordered input is handled",
+			records:   []ReservationRecord{sat(2, 8, 0), sat(0, 8, 0), sat(1, 8, 0)},
+			minWeeks:  2,
+			wantCount: 1,
+			wantWeeks: 3,
+		},
+		{
+			name:      "gap breaks the streak",
+			records:   []ReservationRecord{sat(0, 8, 0), sat(2, 8, 0), sat(4, 8, 0)},
+			minWeeks:  2,
+			wantCount: 0,
+		},
+		{
+			name:      "different times are different slots",
+			records:   []ReservationRecord{sat(0, 8, 0), sat(1, 9, 30)},
+			minWeeks:  2,
+			wantCount: 0,
+		},
+		{
+			name:      "below minimum weeks",
+			records:   []ReservationRecord{sat(0, 8, 0), sat(1, 8, 0)},
+			minWeeks:  3,
+			wantCount: 0,
+		},
+		{
+			name:      "empty input",
+			records:   nil,
+			minWeeks:  2,
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectStandingTeeTimes(tt.records, tt.minWeeks)
+			if len(got) != tt.wantCount {
+				t.Fatalf("DetectStandingTeeTimes() returned %d results, want %d", len(got), tt.wantCount)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+			st := got[0]
+			if st.Weekday != time.Saturday {
+				t.Errorf("Weekday = %v, want Saturday", st.Weekday)
+			}
+			if st.TimeOfDay != "08:00" {
+				t.Errorf("TimeOfDay = %q, want 08:00", st.TimeOfDay)
+			}
+			if st.Occurrences != tt.wantWeeks {
+				t.Errorf("Occurrences = %d, want %d", st.Occurrences, tt.wantWeeks)
+			}
+			if st.NumberOfPlayers != 2 {
+				t.Errorf("NumberOfPlayers = %d, want 2", st.NumberOfPlayers)
+			}
+		})
+	}
+}
+
+func TestStandingTeeTime_RenewalScheduleExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		weekday    time.Weekday
+		windowDays int
+		opensAt    string
+		want       string
+		wantErr    bool
+	}{
+		{"14 day window opens same weekday", time.Saturday, 14, "", "cron(0 8 ? * SAT *)", false},
+		{"10 day window opens 3 days earlier", time.Saturday, 10, "19:00", "cron(0 19 ? * WED *)", false},
+		{"window wraps around the week", time.Monday, 3, "06:30", "cron(30 6 ? * FRI *)", false},
+		{"bad opens_at", time.Monday, 14, "6pm", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &StandingTeeTime{Weekday: tt.weekday, TimeOfDay: "08:00"}
+			got, err := st.RenewalScheduleExpression(tt.windowDays, tt.opensAt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenewalScheduleExpression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenewalScheduleExpression() = %q, want %q", got, tt.want)
+			}
+			if !tt.wantErr {
+				if err := ValidateScheduleExpression(got); err != nil {
+					t.Errorf("generated expression is invalid: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestStandingTeeTime_TargetDate(t *testing.T) {
+	st := &StandingTeeTime{Weekday: time.Saturday}
+	// Saturday 2030-06-01 + 14 days = Saturday 2030-06-15
+	got := st.TargetDate(time.Date(2030, 6, 1, 8, 0, 0, 0, time.UTC), 14)
+	want := time.Date(2030, 6, 15, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("TargetDate() = %v, want %v", got, want)
+	}
+}
+
+func TestParseStandingTeeTimeArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		want    StandingTeeTime
+		wantErr bool
+	}{
+		{
+			name: "full arguments from JSON",
+			args: map[string]interface{}{
+				"course_name":      "Totteridge",
+				"standing_weekday": "saturday",
+				"standing_time":    "07:30",
+				"num_players":      float64(3),
+				"standing_mode":    "renew",
+			},
+			want: StandingTeeTime{CourseName: "Totteridge", Weekday: time.Saturday, TimeOfDay: "07:30", NumberOfPlayers: 3, Mode: StandingModeRenew},
+		},
+		{
+			name: "defaults to remind and one player",
+			args: map[string]interface{}{
+				"standing_weekday": "Sun",
+				"standing_time":    "09:00",
+			},
+			want: StandingTeeTime{Weekday: time.Sunday, TimeOfDay: "09:00", NumberOfPlayers: 1, Mode: StandingModeRemind},
+		},
+		{"missing weekday", map[string]interface{}{"standing_time": "09:00"}, StandingTeeTime{}, true},
+		{"bad time", map[string]interface{}{"standing_weekday": "Sun", "standing_time": "9am"}, StandingTeeTime{}, true},
+		{"bad mode", map[string]interface{}{"standing_weekday": "Sun", "standing_time": "09:00", "standing_mode": "cancel"}, StandingTeeTime{}, true},
+		{"nil args", nil, StandingTeeTime{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStandingTeeTimeArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStandingTeeTimeArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if *got != tt.want {
+				t.Errorf("ParseStandingTeeTimeArgs() = %+v, want %+v", *got, tt.want)
+			}
+
+			// Round trip through ToArguments
+			again, err := ParseStandingTeeTimeArgs(got.ToArguments())
+			if err != nil {
+				t.Fatalf("round trip failed: %v", err)
+			}
+			if *again != *got {
+				t.Errorf("round trip = %+v, want %+v", *again, *got)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimeOfDay buckets tee times for preference tracking
+type TimeOfDay string
+
+const (
+	// TimeOfDayMorning is a tee time before 11 AM
+	TimeOfDayMorning TimeOfDay = "morning"
+	// TimeOfDayMidday is a tee time from 11 AM to 2 PM
+	TimeOfDayMidday TimeOfDay = "midday"
+	// TimeOfDayAfternoon is a tee time at or after 2 PM
+	TimeOfDayAfternoon TimeOfDay = "afternoon"
+)
+
+// TimeOfDayFor returns the bucket a local tee time falls in
+func TimeOfDayFor(teeTime time.Time) TimeOfDay {
+	switch hour := teeTime.Hour(); {
+	case hour < 11:
+		return TimeOfDayMorning
+	case hour < 14:
+		return TimeOfDayMidday
+	default:
+		return TimeOfDayAfternoon
+	}
+}
+
+// RoundSurvey is the golfer's feedback on a played tee time
+type RoundSurvey struct {
+	// ID is the unique identifier for the survey (<course>#<tee time>)
+	ID string `json:"id" dynamodbav:"id"`
+
+	// CourseName is the golf course the round was played at
+	CourseName string `json:"course_name" dynamodbav:"course_name"`
+
+	// TeeTime is the local tee time of the round (YYYY-MM-DDTHH:MM:SS)
+	TeeTime string `json:"tee_time" dynamodbav:"tee_time"`
+
+	// Enjoyment is how much the golfer enjoyed the round (1-5)
+	Enjoyment int `json:"enjoyment" dynamodbav:"enjoyment"`
+
+	// PaceOfPlay is how the golfer rated the pace of play (1-5)
+	PaceOfPlay int `json:"pace_of_play" dynamodbav:"pace_of_play"`
+
+	// PriceFair is whether the golfer felt the price was fair
+	PriceFair bool `json:"price_fair" dynamodbav:"price_fair"`
+
+	// Comment is optional free-form feedback
+	Comment string `json:"comment,omitempty" dynamodbav:"comment,omitempty"`
+
+	// CreatedDate is when the survey response was recorded
+	CreatedDate time.Time `json:"created_date" dynamodbav:"created_date"`
+
+	// TTL is the Unix timestamp when the record expires (1 year)
+	TTL int64 `json:"ttl" dynamodbav:"ttl"`
+}
+
+// NewRoundSurvey creates a new round survey response
+func NewRoundSurvey(courseName, teeTime string, enjoyment, paceOfPlay int, priceFair bool, comment string) (*RoundSurvey, error) {
+	now := time.Now().UTC()
+	survey := &RoundSurvey{
+		CourseName:  courseName,
+		TeeTime:     teeTime,
+		Enjoyment:   enjoyment,
+		PaceOfPlay:  paceOfPlay,
+		PriceFair:   priceFair,
+		Comment:     comment,
+		CreatedDate: now,
+		TTL:         now.Add(365 * 24 * time.Hour).Unix(),
+	}
+	if err := survey.Validate(); err != nil {
+		return nil, err
+	}
+	survey.ID = fmt.Sprintf("%s#%s", strings.ToLower(courseName), teeTime)
+	return survey, nil
+}
+
+// Validate checks if the survey has valid fields
+func (s *RoundSurvey) Validate() error {
+	if s.CourseName == "" {
+		return fmt.Errorf("course name is required")
+	}
+	if _, err := s.TeeTimeValue(); err != nil {
+		return err
+	}
+	if s.Enjoyment < 1 || s.Enjoyment > 5 {
+		return fmt.Errorf("enjoyment must be between 1 and 5")
+	}
+	if s.PaceOfPlay < 1 || s.PaceOfPlay > 5 {
+		return fmt.Errorf("pace_of_play must be between 1 and 5")
+	}
+	return nil
+}
+
+// TeeTimeValue parses the survey's local tee time
+func (s *RoundSurvey) TeeTimeValue() (time.Time, error) {
+	t, err := time.Parse("2006-01-02T15:04:05", s.TeeTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("tee_time must be in YYYY-MM-DDTHH:MM:SS format: %s", s.TeeTime)
+	}
+	return t, nil
+}
+
+// Satisfaction returns an overall score from 0 (worst) to 1 (best).
+// Enjoyment carries the most weight, then pace of play, then price.
+func (s *RoundSurvey) Satisfaction() float64 {
+	price := 0.0
+	if s.PriceFair {
+		price = 1.0
+	}
+	enjoyment := float64(s.Enjoyment-1) / 4
+	pace := float64(s.PaceOfPlay-1) / 4
+	return 0.6*enjoyment + 0.25*pace + 0.15*price
+}
+
+// PreferenceScore is the average satisfaction for a course or time of day
+type PreferenceScore struct {
+	// Key is the course name or time-of-day bucket
+	Key string `json:"key"`
+
+	// Rounds is the number of surveyed rounds
+	Rounds int `json:"rounds"`
+
+	// Satisfaction is the average satisfaction (0-1)
+	Satisfaction float64 `json:"satisfaction"`
+}
+
+// Preferences summarizes survey responses into course and time-of-day preferences
+type Preferences struct {
+	// Courses are ordered from most to least liked
+	Courses []PreferenceScore `json:"courses"`
+
+	// TimesOfDay are ordered from most to least liked
+	TimesOfDay []PreferenceScore `json:"times_of_day"`
+
+	// Surveys is the number of surveys the preferences were built from
+	Surveys int `json:"surveys"`
+}
+
+// ComputePreferences aggregates survey responses into ranked preferences
+func ComputePreferences(surveys []*RoundSurvey) *Preferences {
+	courses := make(map[string][]float64)
+	times := make(map[string][]float64)
+
+	for _, s := range surveys {
+		teeTime, err := s.TeeTimeValue()
+		if err != nil {
+			continue
+		}
+		score := s.Satisfaction()
+		courses[s.CourseName] = append(courses[s.CourseName], score)
+		bucket := string(TimeOfDayFor(teeTime))
+		times[bucket] = append(times[bucket], score)
+	}
+
+	return &Preferences{
+		Courses:    rankPreferenceScores(courses),
+		TimesOfDay: rankPreferenceScores(times),
+		Surveys:    len(surveys),
+	}
+}
+
+// rankPreferenceScores averages scores per key and sorts best first
+func rankPreferenceScores(grouped map[string][]float64) []PreferenceScore {
+	scores := make([]PreferenceScore, 0, len(grouped))
+	for key, values := range grouped {
+		total := 0.0
+		for _, v := range values {
+			total += v
+		}
+		scores = append(scores, PreferenceScore{
+			Key:          key,
+			Rounds:       len(values),
+			Satisfaction: total / float64(len(values)),
+		})
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Satisfaction != scores[j].Satisfaction {
+			return scores[i].Satisfaction > scores[j].Satisfaction
+		}
+		return scores[i].Key < scores[j].Key
+	})
+	return scores
+}
+
+// Summary returns a short text description of the preferences for the agent prompt
+func (p *Preferences) Summary() string {
+	if p == nil || p.Surveys == 0 {
+		return "No post-round survey feedback yet."
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Based on %d post-round survey(s):\n", p.Surveys))
+	for _, c := range p.Courses {
+		sb.WriteString(fmt.Sprintf("- Course %s: %.0f%% satisfied over %d round(s)\n", c.Key, c.Satisfaction*100, c.Rounds))
+	}
+	for _, t := range p.TimesOfDay {
+		sb.WriteString(fmt.Sprintf("- %s tee times: %.0f%% satisfied over %d round(s)\n", t.Key, t.Satisfaction*100, t.Rounds))
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewRoundSurvey(t *testing.T) {
+	tests := []struct {
+		name       string
+		courseName string
+		teeTime    string
+		enjoyment  int
+		pace       int
+		wantErr    bool
+	}{
+		{"valid", "Totteridge", "2030-06-01T08:30:00", 5, 4, false},
+		{"missing course", "", "2030-06-01T08:30:00", 5, 4, true},
+		{"bad tee time", "Totteridge", "2030-06-01", 5, 4, true},
+		{"enjoyment too low", "Totteridge", "2030-06-01T08:30:00", 0, 4, true},
+		{"pace too high", "Totteridge", "2030-06-01T08:30:00", 3, 6, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRoundSurvey(tt.courseName, tt.teeTime, tt.enjoyment, tt.pace, true, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRoundSurvey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.ID != "totteridge#2030-06-01T08:30:00" {
+				t.Errorf("ID = %q, want totteridge#2030-06-01T08:30:00", got.ID)
+			}
+		})
+	}
+}
+
+func TestRoundSurvey_Satisfaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		enjoyment int
+		pace      int
+		priceFair bool
+		want      float64
+	}{
+		{"best", 5, 5, true, 1.0},
+		{"worst", 1, 1, false, 0.0},
+		{"enjoyed but slow and pricey", 5, 1, false, 0.6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &RoundSurvey{Enjoyment: tt.enjoyment, PaceOfPlay: tt.pace, PriceFair: tt.priceFair}
+			if got := s.Satisfaction(); got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Errorf("Satisfaction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputePreferences(t *testing.T) {
+	survey := func(course, teeTime string, enjoyment int) *RoundSurvey {
+		s, err := NewRoundSurvey(course, teeTime, enjoyment, 3, true, "")
+		if err != nil {
+			t.Fatalf("NewRoundSurvey() error = %v", err)
+		}
+		return s
+	}
+
+	prefs := ComputePreferences([]*RoundSurvey{
+		survey("Birdsfoot Golf Course", "2030-06-01T08:00:00", 5),
+		survey("Birdsfoot Golf Course", "2030-06-08T08:30:00", 4),
+		survey("Totteridge", "2030-06-02T15:00:00", 1),
+	})
+
+	if prefs.Surveys != 3 {
+		t.Errorf("Surveys = %d, want 3", prefs.Surveys)
+	}
+	if len(prefs.Courses) != 2 || prefs.Courses[0].Key != "Birdsfoot Golf Course" || prefs.Courses[0].Rounds != 2 {
+		t.Errorf("Courses = %+v, want Birdsfoot ranked first with 2 rounds", prefs.Courses)
+	}
+	if len(prefs.TimesOfDay) != 2 || prefs.TimesOfDay[0].Key != string(TimeOfDayMorning) {
+		t.Errorf("TimesOfDay = %+v, want morning ranked first", prefs.TimesOfDay)
+	}
+
+	summary := prefs.Summary()
+	if !strings.Contains(summary, "Birdsfoot Golf Course") || !strings.Contains(summary, "morning") {
+		t.Errorf("Summary() = %q, missing course or time of day", summary)
+	}
+
+	if got := ComputePreferences(nil).Summary(); !strings.Contains(got, "No post-round survey") {
+		t.Errorf("empty Summary() = %q", got)
+	}
+}
+
+func TestTimeOfDayFor(t *testing.T) {
+	tests := []struct {
+		hour int
+		want TimeOfDay
+	}{
+		{7, TimeOfDayMorning},
+		{11, TimeOfDayMidday},
+		{13, TimeOfDayMidday},
+		{14, TimeOfDayAfternoon},
+	}
+
+	for _, tt := range tests {
+		if got := TimeOfDayFor(time.Date(2030, 6, 1, tt.hour, 0, 0, 0, time.UTC)); got != tt.want {
+			t.Errorf("TimeOfDayFor(%d:00) = %q, want %q", tt.hour, got, tt.want)
+		}
+	}
+}
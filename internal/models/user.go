@@ -0,0 +1,153 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// userIDPattern keeps user IDs usable in created_by fields and Secrets Manager names
+var userIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// UserRole controls what a user can see through the web API
+type UserRole string
+
+const (
+	// UserRoleMember sees only the messages and schedules they created
+	UserRoleMember UserRole = "member"
+	// UserRoleAdmin sees every user's messages and schedules and can create users
+	UserRoleAdmin UserRole = "admin"
+)
+
+// IsValid checks if the user role value is valid
+func (r UserRole) IsValid() bool {
+	switch r {
+	case UserRoleMember, UserRoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the string representation of the user role
+func (r UserRole) String() string {
+	return string(r)
+}
+
+// User is a golfer with their own API key, golf credentials, messages, and schedules
+type User struct {
+	// ID identifies the user and is recorded as created_by on their messages and schedules
+	ID string `json:"id" dynamodbav:"id"`
+
+	Name  string   `json:"name" dynamodbav:"name"`
+	Email string   `json:"email,omitempty" dynamodbav:"email,omitempty"`
+	Role  UserRole `json:"role" dynamodbav:"role"`
+
+	// GolfSecretName is the Secrets Manager secret holding the user's golf credentials; members
+	// need one to run golf actions, admins may use any secret
+	GolfSecretName string `json:"golf_secret_name,omitempty" dynamodbav:"golf_secret_name,omitempty"`
+
+	// APIKeyHash is the SHA-256 of the user's API key; the key itself is never stored
+	APIKeyHash string `json:"-" dynamodbav:"api_key_hash"`
+
+	// Disabled users are refused by the web API
+	Disabled bool `json:"disabled,omitempty" dynamodbav:"disabled,omitempty"`
+
+	CreatedDate time.Time `json:"created_date" dynamodbav:"created_date"`
+	UpdatedDate time.Time `json:"updated_date" dynamodbav:"updated_date"`
+}
+
+// NewUser creates a user and returns it with its API key, which is shown once and not stored
+func NewUser(id, name, email string, role UserRole, golfSecretName string) (*User, string, error) {
+	if role == "" {
+		role = UserRoleMember
+	}
+
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	user := &User{
+		ID:             id,
+		Name:           name,
+		Email:          email,
+		Role:           role,
+		GolfSecretName: golfSecretName,
+		APIKeyHash:     HashAPIKey(apiKey),
+		CreatedDate:    now,
+		UpdatedDate:    now,
+	}
+	if err := user.Validate(); err != nil {
+		return nil, "", err
+	}
+	return user, apiKey, nil
+}
+
+// Validate checks the user's ID, name, and role
+func (u *User) Validate() error {
+	if !userIDPattern.MatchString(u.ID) {
+		return fmt.Errorf("user id %q must be 1-64 lowercase letters, digits, underscores, or hyphens", u.ID)
+	}
+	if u.Name == "" {
+		return fmt.Errorf("user name is required")
+	}
+	if !u.Role.IsValid() {
+		return fmt.Errorf("invalid user role: %s", u.Role)
+	}
+	return nil
+}
+
+// IsAdmin reports whether the user can see and manage every user's data
+func (u *User) IsAdmin() bool {
+	return u.Role == UserRoleAdmin
+}
+
+// GolfSecret returns the secret the user's golf web actions authenticate with
+func (u *User) GolfSecret() (string, error) {
+	if u.GolfSecretName == "" {
+		return "", fmt.Errorf("user %s has no golf credentials", u.ID)
+	}
+	return u.GolfSecretName, nil
+}
+
+// ActingUserHeader names the user a trusted service calls the MCP server for, such as the owner
+// of a scheduled agent run, so the golf tools use that user's credentials
+const ActingUserHeader = "X-Acting-User"
+
+type actingUserKey struct{}
+
+// WithActingUser returns a context acting for userID; an empty ID leaves ctx as it is
+func WithActingUser(ctx context.Context, userID string) context.Context {
+	if userID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, actingUserKey{}, userID)
+}
+
+// ActingUser returns the user the context acts for, or "" when it acts for nobody in particular
+func ActingUser(ctx context.Context) string {
+	userID, _ := ctx.Value(actingUserKey{}).(string)
+	return userID
+}
+
+// HashAPIKey returns the hex SHA-256 of an API key, the form keys are stored and looked up in.
+// API keys are random, so an unsalted fast hash is enough to keep them out of the table.
+func HashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateAPIKey returns a random API key with a recognizable prefix
+func generateAPIKey() (string, error) {
+	key := make([]byte, 24)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return "rez_" + hex.EncodeToString(key), nil
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewUser(t *testing.T) {
+	user, apiKey, err := NewUser("alice", "Alice", "alice@example.com", "", "")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if user.Role != UserRoleMember {
+		t.Errorf("Role = %v, want the member default", user.Role)
+	}
+	if !strings.HasPrefix(apiKey, "rez_") {
+		t.Errorf("API key = %q, want the rez_ prefix", apiKey)
+	}
+	if user.APIKeyHash != HashAPIKey(apiKey) || strings.Contains(user.APIKeyHash, apiKey) {
+		t.Error("APIKeyHash should be the hash of the key, not the key")
+	}
+
+	if _, _, err := NewUser("Alice Smith", "Alice", "", UserRoleMember, ""); err == nil {
+		t.Error("expected an error for an ID with spaces and capitals")
+	}
+	if _, _, err := NewUser("alice", "", "", UserRoleMember, ""); err == nil {
+		t.Error("expected an error without a name")
+	}
+	if _, _, err := NewUser("alice", "Alice", "", "owner", ""); err == nil {
+		t.Error("expected an error for an unknown role")
+	}
+}
+
+func TestUser_GolfSecret(t *testing.T) {
+	own := &User{ID: "alice", Role: UserRoleMember, GolfSecretName: "rez-agent/golf/users/alice"}
+	if secret, err := own.GolfSecret(); err != nil || secret != own.GolfSecretName {
+		t.Errorf("GolfSecret() = %q, %v, want the user's secret", secret, err)
+	}
+
+	member := &User{ID: "bob", Role: UserRoleMember}
+	if _, err := member.GolfSecret(); err == nil {
+		t.Error("expected an error for a user without golf credentials")
+	}
+}
@@ -0,0 +1,241 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WeatherDecisionType is what the agent did with a date after looking at the forecast
+type WeatherDecisionType string
+
+const (
+	// WeatherDecisionBooked indicates the agent booked a tee time for the date
+	WeatherDecisionBooked WeatherDecisionType = "booked"
+	// WeatherDecisionSkipped indicates the agent skipped the date because of the forecast
+	WeatherDecisionSkipped WeatherDecisionType = "skipped"
+)
+
+// IsValid checks if the weather decision type value is valid
+func (d WeatherDecisionType) IsValid() bool {
+	switch d {
+	case WeatherDecisionBooked, WeatherDecisionSkipped:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the string representation of the weather decision type
+func (d WeatherDecisionType) String() string {
+	return string(d)
+}
+
+// DefaultMaxPrecipChance is the precipitation probability (percent) at or above which
+// the agent treats a forecast as too wet to play
+const DefaultMaxPrecipChance = 50
+
+// wetPrecipInches is the observed rainfall at or above which a day counts as wet
+const wetPrecipInches = 0.05
+
+// inclementKeywords mark a forecast or observation description as wet or unplayable
+var inclementKeywords = []string{"rain", "shower", "thunder", "storm", "snow", "sleet", "drizzle", "hail"}
+
+// WeatherDecision records the forecast the agent used for a date and what actually happened
+type WeatherDecision struct {
+	// ID is the unique identifier for the decision (<course>#<date>)
+	ID string `json:"id" dynamodbav:"id"`
+
+	// CourseName is the golf course the decision was made for
+	CourseName string `json:"course_name" dynamodbav:"course_name"`
+
+	// Date is the tee time date in YYYY-MM-DD format
+	Date string `json:"date" dynamodbav:"date"`
+
+	// Decision is whether the agent booked or skipped the date
+	Decision WeatherDecisionType `json:"decision" dynamodbav:"decision"`
+
+	// ScheduleID is the schedule that triggered the decision (optional)
+	ScheduleID string `json:"schedule_id,omitempty" dynamodbav:"schedule_id,omitempty"`
+
+	// ForecastSummary is the short forecast the agent saw
+	ForecastSummary string `json:"forecast_summary" dynamodbav:"forecast_summary"`
+
+	// ForecastPrecipChance is the forecast probability of precipitation (0-100)
+	ForecastPrecipChance int `json:"forecast_precip_chance" dynamodbav:"forecast_precip_chance"`
+
+	// ObservedSummary is the most common observed condition for the day
+	ObservedSummary string `json:"observed_summary,omitempty" dynamodbav:"observed_summary,omitempty"`
+
+	// ObservedPrecipInches is the total observed precipitation, nil until observed
+	ObservedPrecipInches *float64 `json:"observed_precip_inches,omitempty" dynamodbav:"observed_precip_inches,omitempty"`
+
+	// ObservedAt is when the observation was recorded
+	ObservedAt *time.Time `json:"observed_at,omitempty" dynamodbav:"observed_at,omitempty"`
+
+	// CreatedDate is when the decision was recorded
+	CreatedDate time.Time `json:"created_date" dynamodbav:"created_date"`
+
+	// TTL is the Unix timestamp when the record expires (180 days)
+	TTL int64 `json:"ttl" dynamodbav:"ttl"`
+}
+
+// NewWeatherDecision creates a new weather decision record
+func NewWeatherDecision(courseName, date string, decision WeatherDecisionType, forecastSummary string, precipChance int, scheduleID string) (*WeatherDecision, error) {
+	if courseName == "" {
+		return nil, fmt.Errorf("course name is required")
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, fmt.Errorf("date must be in YYYY-MM-DD format: %s", date)
+	}
+	if !decision.IsValid() {
+		return nil, fmt.Errorf("invalid decision: %s (must be booked or skipped)", decision)
+	}
+	if precipChance < 0 || precipChance > 100 {
+		return nil, fmt.Errorf("precipitation chance must be between 0 and 100")
+	}
+
+	now := time.Now().UTC()
+	return &WeatherDecision{
+		ID:                   fmt.Sprintf("%s#%s", strings.ToLower(courseName), date),
+		CourseName:           courseName,
+		Date:                 date,
+		Decision:             decision,
+		ScheduleID:           scheduleID,
+		ForecastSummary:      forecastSummary,
+		ForecastPrecipChance: precipChance,
+		CreatedDate:          now,
+		TTL:                  now.Add(180 * 24 * time.Hour).Unix(),
+	}, nil
+}
+
+// RecordObservation stores the observed weather for the decision date
+func (d *WeatherDecision) RecordObservation(summary string, precipInches float64) {
+	now := time.Now().UTC()
+	d.ObservedSummary = summary
+	d.ObservedPrecipInches = &precipInches
+	d.ObservedAt = &now
+}
+
+// IsObserved reports whether actual weather has been recorded
+func (d *WeatherDecision) IsObserved() bool {
+	return d.ObservedPrecipInches != nil
+}
+
+// ForecastWet reports whether the forecast should gate play at the given threshold
+func (d *WeatherDecision) ForecastWet(maxPrecipChance int) bool {
+	return d.ForecastPrecipChance >= maxPrecipChance || hasInclementKeyword(d.ForecastSummary)
+}
+
+// ObservedWet reports whether the observed weather was wet or unplayable
+func (d *WeatherDecision) ObservedWet() bool {
+	if !d.IsObserved() {
+		return false
+	}
+	return *d.ObservedPrecipInches >= wetPrecipInches || hasInclementKeyword(d.ObservedSummary)
+}
+
+// hasInclementKeyword checks a weather description for wet/unplayable conditions
+func hasInclementKeyword(text string) bool {
+	text = strings.ToLower(text)
+	for _, kw := range inclementKeywords {
+		if strings.Contains(text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// WeatherAccuracyReport summarizes how well forecasts predicted the weather on decision dates
+type WeatherAccuracyReport struct {
+	// Total is the number of decisions considered
+	Total int `json:"total"`
+
+	// Observed is the number of decisions with observed weather
+	Observed int `json:"observed"`
+
+	// ForecastHits is the number of observed decisions where forecast wet/dry matched reality
+	ForecastHits int `json:"forecast_hits"`
+
+	// Accuracy is ForecastHits / Observed (0 when nothing has been observed)
+	Accuracy float64 `json:"accuracy"`
+
+	// MaxPrecipChance is the gating threshold the report was computed with
+	MaxPrecipChance int `json:"max_precip_chance"`
+
+	// SuggestedMaxPrecipChance is the threshold that best fits the observed history
+	SuggestedMaxPrecipChance int `json:"suggested_max_precip_chance"`
+
+	// MissedPlayDays are dates the agent skipped that turned out dry
+	MissedPlayDays []*WeatherDecision `json:"missed_play_days,omitempty"`
+
+	// WetBookings are dates the agent booked that turned out wet
+	WetBookings []*WeatherDecision `json:"wet_bookings,omitempty"`
+}
+
+// ComputeWeatherAccuracy compares forecasts with observations at the given threshold
+func ComputeWeatherAccuracy(decisions []*WeatherDecision, maxPrecipChance int) *WeatherAccuracyReport {
+	report := &WeatherAccuracyReport{
+		Total:           len(decisions),
+		MaxPrecipChance: maxPrecipChance,
+	}
+
+	for _, d := range decisions {
+		if !d.IsObserved() {
+			continue
+		}
+		report.Observed++
+
+		if d.ForecastWet(maxPrecipChance) == d.ObservedWet() {
+			report.ForecastHits++
+		}
+
+		switch {
+		case d.Decision == WeatherDecisionSkipped && !d.ObservedWet():
+			report.MissedPlayDays = append(report.MissedPlayDays, d)
+		case d.Decision == WeatherDecisionBooked && d.ObservedWet():
+			report.WetBookings = append(report.WetBookings, d)
+		}
+	}
+
+	if report.Observed > 0 {
+		report.Accuracy = float64(report.ForecastHits) / float64(report.Observed)
+	}
+	report.SuggestedMaxPrecipChance = TuneMaxPrecipChance(decisions, maxPrecipChance, 5)
+
+	return report
+}
+
+// TuneMaxPrecipChance picks the precipitation threshold (in steps of 10) that would have
+// classified the most observed days correctly. The current threshold is kept when fewer than
+// minSamples days have been observed or when no candidate does strictly better.
+func TuneMaxPrecipChance(decisions []*WeatherDecision, current, minSamples int) int {
+	observed := make([]*WeatherDecision, 0, len(decisions))
+	for _, d := range decisions {
+		if d.IsObserved() {
+			observed = append(observed, d)
+		}
+	}
+	if len(observed) < minSamples {
+		return current
+	}
+
+	score := func(threshold int) int {
+		hits := 0
+		for _, d := range observed {
+			// Only the precipitation chance is tunable; keywords always gate
+			if d.ForecastWet(threshold) == d.ObservedWet() {
+				hits++
+			}
+		}
+		return hits
+	}
+
+	best, bestScore := current, score(current)
+	for threshold := 10; threshold <= 90; threshold += 10 {
+		if s := score(threshold); s > bestScore {
+			best, bestScore = threshold, s
+		}
+	}
+	return best
+}
@@ -0,0 +1,137 @@
+package models
+
+import "testing"
+
+func observedDecision(t *testing.T, decision WeatherDecisionType, summary string, precipChance int, observed string, inches float64) *WeatherDecision {
+	t.Helper()
+	d, err := NewWeatherDecision("Birdsfoot Golf Course", "2030-06-01", decision, summary, precipChance, "")
+	if err != nil {
+		t.Fatalf("NewWeatherDecision() error = %v", err)
+	}
+	d.RecordObservation(observed, inches)
+	return d
+}
+
+func TestNewWeatherDecision(t *testing.T) {
+	tests := []struct {
+		name         string
+		courseName   string
+		date         string
+		decision     WeatherDecisionType
+		precipChance int
+		wantErr      bool
+	}{
+		{"valid booked", "Totteridge", "2030-06-01", WeatherDecisionBooked, 20, false},
+		{"valid skipped", "Totteridge", "2030-06-01", WeatherDecisionSkipped, 80, false},
+		{"missing course", "", "2030-06-01", WeatherDecisionBooked, 0, true},
+		{"bad date", "Totteridge", "06/01/2030", WeatherDecisionBooked, 0, true},
+		{"bad decision", "Totteridge", "2030-06-01", WeatherDecisionType("maybe"), 0, true},
+		{"precip out of range", "Totteridge", "2030-06-01", WeatherDecisionBooked, 101, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewWeatherDecision(tt.courseName, tt.date, tt.decision, "Sunny", tt.precipChance, "sched-1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewWeatherDecision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.ID != "totteridge#2030-06-01" {
+				t.Errorf("ID = %q, want totteridge#2030-06-01", got.ID)
+			}
+			if got.IsObserved() {
+				t.Error("new decision should not be observed")
+			}
+		})
+	}
+}
+
+func TestWeatherDecision_Wet(t *testing.T) {
+	tests := []struct {
+		name         string
+		summary      string
+		precipChance int
+		observed     string
+		inches       float64
+		forecastWet  bool
+		observedWet  bool
+	}{
+		{"dry forecast and day", "Mostly Sunny", 10, "Clear", 0, false, false},
+		{"high precip chance", "Partly Cloudy", 60, "Cloudy", 0, true, false},
+		{"forecast keyword", "Chance Showers", 20, "Light Rain", 0.01, true, true},
+		{"measured rain", "Sunny", 0, "Cloudy", 0.3, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := observedDecision(t, WeatherDecisionBooked, tt.summary, tt.precipChance, tt.observed, tt.inches)
+			if got := d.ForecastWet(DefaultMaxPrecipChance); got != tt.forecastWet {
+				t.Errorf("ForecastWet() = %v, want %v", got, tt.forecastWet)
+			}
+			if got := d.ObservedWet(); got != tt.observedWet {
+				t.Errorf("ObservedWet() = %v, want %v", got, tt.observedWet)
+			}
+		})
+	}
+}
+
+func TestComputeWeatherAccuracy(t *testing.T) {
+	unobserved, err := NewWeatherDecision("Totteridge", "2030-06-08", WeatherDecisionBooked, "Sunny", 0, "")
+	if err != nil {
+		t.Fatalf("NewWeatherDecision() error = %v", err)
+	}
+
+	decisions := []*WeatherDecision{
+		observedDecision(t, WeatherDecisionSkipped, "Partly Cloudy", 60, "Sunny", 0),
+		observedDecision(t, WeatherDecisionBooked, "Sunny", 10, "Thunderstorm", 0.5),
+		observedDecision(t, WeatherDecisionSkipped, "Rain", 90, "Heavy Rain", 1.2),
+		unobserved,
+	}
+
+	report := ComputeWeatherAccuracy(decisions, DefaultMaxPrecipChance)
+
+	if report.Total != 4 || report.Observed != 3 {
+		t.Errorf("Total/Observed = %d/%d, want 4/3", report.Total, report.Observed)
+	}
+	if report.ForecastHits != 1 {
+		t.Errorf("ForecastHits = %d, want 1", report.ForecastHits)
+	}
+	if len(report.MissedPlayDays) != 1 || report.MissedPlayDays[0].ObservedSummary != "Sunny" {
+		t.Errorf("MissedPlayDays = %+v, want the sunny skipped day", report.MissedPlayDays)
+	}
+	if len(report.WetBookings) != 1 || report.WetBookings[0].ObservedSummary != "Thunderstorm" {
+		t.Errorf("WetBookings = %+v, want the stormy booked day", report.WetBookings)
+	}
+}
+
+func TestTuneMaxPrecipChance(t *testing.T) {
+	// Days forecast at 40-60% all stayed dry; 80%+ were wet
+	history := []*WeatherDecision{
+		observedDecision(t, WeatherDecisionSkipped, "Partly Cloudy", 50, "Clear", 0),
+		observedDecision(t, WeatherDecisionSkipped, "Partly Cloudy", 60, "Clear", 0),
+		observedDecision(t, WeatherDecisionBooked, "Partly Cloudy", 40, "Clear", 0),
+		observedDecision(t, WeatherDecisionSkipped, "Cloudy", 80, "Cloudy", 0.4),
+		observedDecision(t, WeatherDecisionSkipped, "Cloudy", 90, "Cloudy", 0.8),
+	}
+
+	tests := []struct {
+		name       string
+		decisions  []*WeatherDecision
+		minSamples int
+		want       int
+	}{
+		{"raises threshold to fit history", history, 5, 70},
+		{"keeps current below min samples", history, 10, DefaultMaxPrecipChance},
+		{"keeps current with no history", nil, 5, DefaultMaxPrecipChance},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TuneMaxPrecipChance(tt.decisions, DefaultMaxPrecipChance, tt.minSamples); got != tt.want {
+				t.Errorf("TuneMaxPrecipChance() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WebActionFailure describes a web action that failed on its final delivery attempt and is headed for the DLQ
+type WebActionFailure struct {
+	// OriginalMessageID is the ID of the web action message that failed
+	OriginalMessageID string `json:"original_message_id"`
+
+	// Action is the web action type (weather, golf)
+	Action WebActionType `json:"action,omitempty"`
+
+	// Operation is the action's operation, e.g. book_tee_time
+	Operation string `json:"operation,omitempty"`
+
+	// CourseID is the golf course the action targeted
+	CourseID int `json:"course_id,omitempty"`
+
+	// Error is the error from the final attempt
+	Error string `json:"error"`
+
+	// Attempts is how many times the action was delivered
+	Attempts int `json:"attempts"`
+
+	// FailedAt is when the final attempt failed
+	FailedAt time.Time `json:"failed_at"`
+
+	// Remediation lists suggested next steps for the agent or golfer
+	Remediation []string `json:"remediation,omitempty"`
+
+	// Arguments are the original message arguments, so a follow-up run can retry with changes
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// NewWebActionFailure builds a failure event for a web action message that will not be retried again
+func NewWebActionFailure(original *Message, cause error, attempts int, failedAt time.Time) *WebActionFailure {
+	failure := &WebActionFailure{
+		OriginalMessageID: original.ID,
+		Error:             cause.Error(),
+		Attempts:          attempts,
+		FailedAt:          failedAt.UTC(),
+		Remediation:       remediationFor(cause.Error()),
+		Arguments:         original.Arguments,
+	}
+
+	if payload, err := DecodePayload[*WebActionPayload](original); err == nil {
+		failure.Action = payload.Action
+		failure.CourseID = payload.CourseID
+	}
+	if operation, ok := original.Arguments["operation"].(string); ok {
+		failure.Operation = operation
+	}
+
+	return failure
+}
+
+// Summary returns a one-line description of the failure
+func (f *WebActionFailure) Summary() string {
+	action := string(f.Action)
+	if f.Operation != "" {
+		action = f.Operation
+	}
+	if action == "" {
+		action = "web action"
+	}
+	return fmt.Sprintf("%s failed permanently after %d attempt(s): %s", action, f.Attempts, f.Error)
+}
+
+// ToMessage wraps the failure in an agent_response message
+func (f *WebActionFailure) ToMessage(stage Stage) (*Message, error) {
+	return NewPayloadMessage(ComponentWebAction, nil, stage, MessageTypeAgentResponse, &AgentResponsePayload{
+		Status:  "failed",
+		Message: f.Summary(),
+		Failure: f,
+	})
+}
+
+// remediationFor suggests next steps based on the error text
+func remediationFor(errText string) []string {
+	lower := strings.ToLower(errText)
+	var steps []string
+
+	switch {
+	case strings.Contains(lower, "short-circuited"):
+		steps = append(steps, "The course API has been failing repeatedly; wait a few minutes before retrying")
+	case strings.Contains(lower, "http error 401"), strings.Contains(lower, "http error 403"), strings.Contains(lower, "oauth"):
+		steps = append(steps, "Check the golf credentials secret and that the account can still sign in")
+	case strings.Contains(lower, "http error 429"):
+		steps = append(steps, "The API is rate limiting requests; retry later with fewer searches")
+	case strings.Contains(lower, "http error 5"), strings.Contains(lower, "timeout"), strings.Contains(lower, "deadline exceeded"):
+		steps = append(steps, "The API was unavailable; retry the same request later")
+	}
+
+	if strings.Contains(lower, "price") {
+		steps = append(steps, "Raise max_price or search for a cheaper tee time")
+	}
+	if strings.Contains(lower, "not available") || strings.Contains(lower, "no longer") || strings.Contains(lower, "lock") {
+		steps = append(steps, "The tee time was taken; search again and book a different time")
+	}
+
+	if len(steps) == 0 {
+		steps = append(steps, "Review the error and retry with corrected parameters")
+	}
+	return steps
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewWebActionFailure(t *testing.T) {
+	original := NewMessage("scheduler", map[string]interface{}{"operation": "book_tee_time"}, "1.0", StageDev, MessageTypeWebAction,
+		map[string]interface{}{"version": "1.0", "url": "https://example.com", "action": "golf", "courseID": 1})
+	failedAt := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	failure := NewWebActionFailure(original, errors.New("request to example.com short-circuited: circuit breaker is open"), 3, failedAt)
+
+	if failure.OriginalMessageID != original.ID || failure.Action != WebActionTypeGolf || failure.Operation != "book_tee_time" || failure.CourseID != 1 {
+		t.Errorf("unexpected failure fields: %+v", failure)
+	}
+	if len(failure.Remediation) == 0 || !strings.Contains(failure.Remediation[0], "wait") {
+		t.Errorf("Remediation = %v, want a wait-and-retry suggestion", failure.Remediation)
+	}
+
+	msg, err := failure.ToMessage(StageDev)
+	if err != nil {
+		t.Fatalf("ToMessage() error = %v", err)
+	}
+	if err := DefaultMessageTypeRegistry().ValidatePublish(msg, ComponentWebAction); err != nil {
+		t.Errorf("failure message should be publishable by the webaction Lambda: %v", err)
+	}
+	if msg.Payload["status"] != "failed" || !strings.Contains(msg.Payload["message"].(string), "book_tee_time failed permanently after 3") {
+		t.Errorf("unexpected payload: %v", msg.Payload)
+	}
+}
+
+func TestRemediationFor(t *testing.T) {
+	tests := []struct {
+		err  string
+		want string
+	}{
+		{"HTTP error 401: unauthorized", "credentials"},
+		{"HTTP error 429: slow down", "rate limiting"},
+		{"HTTP error 503: unavailable", "unavailable"},
+		{"price $95.00 exceeds max_price $80.00", "max_price"},
+		{"something unexpected", "corrected parameters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			steps := remediationFor(tt.err)
+			if !strings.Contains(strings.Join(steps, " "), tt.want) {
+				t.Errorf("remediationFor(%q) = %v, want a step mentioning %q", tt.err, steps, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "slices"
+
+// WebActionHandlerConfig enables or disables a web action handler for a stage and carries its options.
+// Configs are loaded once at cold start, so changes apply to new Lambda execution environments.
+type WebActionHandlerConfig struct {
+	// Action is the web action type the config applies to
+	Action WebActionType `json:"action" dynamodbav:"action"`
+
+	// Enabled turns the handler on or off for the stage
+	Enabled bool `json:"enabled" dynamodbav:"enabled"`
+
+	// DisabledOperations are operations the handler refuses, e.g. book_tee_time in dev
+	DisabledOperations []string `json:"disabled_operations,omitempty" dynamodbav:"disabled_operations,omitempty"`
+
+	// Options are handler-specific settings passed to the handler's factory
+	Options map[string]string `json:"options,omitempty" dynamodbav:"options,omitempty"`
+}
+
+// OperationDisabled reports whether the operation is turned off by this config
+func (c *WebActionHandlerConfig) OperationDisabled(operation string) bool {
+	return slices.Contains(c.DisabledOperations, operation)
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jrzesz33/rez_agent/internal/policy"
+	"github.com/jrzesz33/rez_agent/kit/redact"
 	"github.com/jrzesz33/rez_agent/pkg/courses"
 )
 
@@ -18,12 +20,14 @@ const (
 	WebActionTypeWeather WebActionType = "weather"
 	// WebActionTypeGolf fetches golf reservation data
 	WebActionTypeGolf WebActionType = "golf"
+	// WebActionTypeHTTPRequest calls an allowlisted HTTP endpoint described entirely by the payload
+	WebActionTypeHTTPRequest WebActionType = "http_request"
 )
 
 // IsValid checks if the web action type value is valid
 func (wat WebActionType) IsValid() bool {
 	switch wat {
-	case WebActionTypeWeather, WebActionTypeGolf:
+	case WebActionTypeWeather, WebActionTypeGolf, WebActionTypeHTTPRequest:
 		return true
 	default:
 		return false
@@ -85,6 +89,21 @@ type AuthConfig struct {
 	Headers map[string]string `json:"headers,omitempty" dynamodbav:"headers,omitempty"`
 }
 
+// OAuthToken is a cached OAuth access token shared across Lambda invocations
+type OAuthToken struct {
+	// Key identifies the credentials and scope the token was issued for
+	Key string `json:"key" dynamodbav:"key"`
+
+	// AccessToken is the bearer token
+	AccessToken string `json:"access_token" dynamodbav:"access_token"`
+
+	// ExpiresAt is when the token stops being accepted
+	ExpiresAt time.Time `json:"expires_at" dynamodbav:"expires_at"`
+
+	// TTL removes the record once the token has expired
+	TTL int64 `json:"ttl" dynamodbav:"ttl"`
+}
+
 // WebActionPayload represents the configuration for a web action request
 type WebActionPayload struct {
 	// Version is the payload schema version
@@ -101,15 +120,30 @@ type WebActionPayload struct {
 
 	//End Search Time for golf tee time search
 	EndSearchTime string `json:"endSearchTime,omitempty" dynamodbav:"endSearchTime,omitempty"`
+
+	// EndSearchDate is the last date (YYYY-MM-DD) of a multi-day tee time search
+	EndSearchDate string `json:"endSearchDate,omitempty" dynamodbav:"endSearchDate,omitempty"`
+
 	// AutoBook indicates whether to auto-book available tee times
 	AutoBook bool `json:"autoBook,omitempty" dynamodbav:"autoBook,omitempty"`
 
+	// LockAttempts is how many ranked tee times an auto-book tries, in order, when the one before
+	// was taken before it could be locked (default 3)
+	LockAttempts int `json:"lockAttempts,omitempty" dynamodbav:"lockAttempts,omitempty"`
+
 	// CourseID is the identifier for the golf course
 	CourseID int `json:"courseID,omitempty" dynamodbav:"courseID,omitempty"`
 
 	//MaxResults limits the number of results returned
 	MaxResults int `json:"maxResults,omitempty" dynamodbav:"maxResults,omitempty"`
 
+	// ResultOffset skips that many ranked results, to fetch a later page of a search
+	ResultOffset int `json:"resultOffset,omitempty" dynamodbav:"resultOffset,omitempty"`
+
+	// NextResultOffset is set by a search when results remain past the page it returned; it is
+	// where the next page starts and is never stored
+	NextResultOffset int `json:"-" dynamodbav:"-"`
+
 	//Days number of days for search
 	Days int `json:"days,omitempty" dynamodbav:"days,omitempty"`
 
@@ -119,6 +153,41 @@ type WebActionPayload struct {
 	// teeSheetId is the identifier for the golf tee sheet
 	TeeSheetID int `json:"teeSheetID,omitempty" dynamodbav:"teeSheetID,omitempty"`
 
+	// MaxPrice caps the 18-hole green fee for search and booking (0 = no cap)
+	MaxPrice float64 `json:"maxPrice,omitempty" dynamodbav:"maxPrice,omitempty"`
+
+	// PreferredTime is the preferred tee time of day (HH:MM) used to rank search results
+	PreferredTime string `json:"preferredTime,omitempty" dynamodbav:"preferredTime,omitempty"`
+
+	// RankBy lists tee time ranking criteria in priority order (time, price, holes), comma-separated
+	RankBy string `json:"rankBy,omitempty" dynamodbav:"rankBy,omitempty"`
+
+	// Policy holds hard booking rules enforced regardless of what the agent requests
+	Policy *policy.Config `json:"policy,omitempty" dynamodbav:"policy,omitempty"`
+
+	// RequireApproval locks and prices the tee time but waits for the golfer to approve before reserving
+	RequireApproval bool `json:"requireApproval,omitempty" dynamodbav:"requireApproval,omitempty"`
+
+	// DryRun searches and prices a booking but never locks or reserves it, reporting what would
+	// have been booked
+	DryRun bool `json:"dryRun,omitempty" dynamodbav:"dryRun,omitempty"`
+
+	// Group books for several golfers: the tee time is held for len(Group) players and each member
+	// is asked to RSVP before the reservation is completed for everyone who did not decline
+	Group []GroupMember `json:"group,omitempty" dynamodbav:"group,omitempty"`
+
+	// ApprovalToken identifies the approved booking to complete
+	ApprovalToken string `json:"approvalToken,omitempty" dynamodbav:"approvalToken,omitempty"`
+
+	// Method is the HTTP method for http_request actions (default GET)
+	Method string `json:"method,omitempty" dynamodbav:"method,omitempty"`
+
+	// Headers are request headers for http_request actions; values may use {{.arg}} templates
+	Headers map[string]string `json:"headers,omitempty" dynamodbav:"headers,omitempty"`
+
+	// Body is the JSON request body template for http_request actions, rendered with the message arguments
+	Body string `json:"body,omitempty" dynamodbav:"body,omitempty"`
+
 	// AuthConfig contains authentication configuration
 	AuthConfig *AuthConfig `json:"auth_config,omitempty" dynamodbav:"auth_config,omitempty"`
 }
@@ -129,6 +198,9 @@ var AllowedHosts = map[string]bool{
 	"birdsfoot.cps.golf": true,
 }
 
+// WeatherCacheTTL is how long weather.gov forecasts are reused; they are regenerated about hourly
+const WeatherCacheTTL = 30 * time.Minute
+
 func (p *WebActionPayload) AddCourseConfig(oper string, course courses.Course) {
 
 	var err error
@@ -136,11 +208,13 @@ func (p *WebActionPayload) AddCourseConfig(oper string, course courses.Course) {
 	switch oper {
 	case "get_weather":
 		p.URL, err = course.GetActionURL("get-weather")
-	case "search_tee_times":
+	case "search_tee_times", "search_tee_times_range":
 		p.URL, err = course.GetActionURL("search-tee-times")
-	case "book_tee_time":
+	case "book_tee_time", "complete_booking":
 		p.URL, err = course.GetActionURL("book-tee-time")
-	case "fetch_reservations":
+	case "release_lock":
+		p.URL, err = course.GetActionURL("release-tee-time")
+	case "fetch_reservations", "sync_reservations", "detect_standing_tee_times", "round_survey":
 		p.URL, err = course.GetActionURL("fetch_reservations")
 	default:
 		err = fmt.Errorf("unknown operation: %s", oper)
@@ -191,6 +265,11 @@ func (p *WebActionPayload) Validate() error {
 		}
 	}
 
+	// Validate group booking members
+	if err := ValidateGroup(p.Group); err != nil {
+		return fmt.Errorf("group validation failed: %w", err)
+	}
+
 	return nil
 }
 
@@ -234,6 +313,13 @@ type WebActionResult struct {
 	// ResponseBody is the HTTP response body (truncated for storage)
 	ResponseBody string `json:"response_body,omitempty" dynamodbav:"response_body,omitempty"`
 
+	// ResponseBodyKey is the S3 key of the full response body, gzipped, when a truncated body
+	// was offloaded to the agent logs bucket
+	ResponseBodyKey string `json:"response_body_key,omitempty" dynamodbav:"response_body_key,omitempty"`
+
+	// fullResponseBody is the whole masked body when MarkSuccess truncated it; it is not stored
+	fullResponseBody string
+
 	// ErrorMessage contains error details if Status is Failed
 	ErrorMessage string `json:"error_message,omitempty" dynamodbav:"error_message,omitempty"`
 
@@ -248,6 +334,9 @@ type WebActionResult struct {
 
 	// Stage is the environment
 	Stage Stage `json:"stage" dynamodbav:"stage"`
+
+	// CorrelationID is the correlation ID of the message that triggered this action
+	CorrelationID string `json:"correlation_id,omitempty" dynamodbav:"correlation_id,omitempty"`
 }
 
 // NewWebActionResult creates a new web action result with TTL set to 3 days
@@ -272,47 +361,57 @@ func generateResultID(t time.Time) string {
 	return "result_" + t.Format("20060102150405") + "_" + fmt.Sprintf("%d", t.Nanosecond()%1000000)
 }
 
-// MarkSuccess marks the result as successful
+// MarkSuccess marks the result as successful, storing the response body with credentials and
+// personal data masked
 func (r *WebActionResult) MarkSuccess(responseCode int, responseBody string, executionMs int64) {
 	r.Status = StatusCompleted
 	r.ResponseCode = responseCode
-	r.ResponseBody = truncateResponseBody(responseBody)
+	body := redact.Text(responseBody)
+	r.ResponseBody = truncateResponseBody(body)
+	r.fullResponseBody = ""
+	if r.ResponseBody != body {
+		r.fullResponseBody = body
+	}
 	r.ExecutionTimeMs = executionMs
 }
 
-// MarkFailure marks the result as failed
+// FullResponseBody returns the whole masked response body when MarkSuccess truncated it for
+// storage, and "" otherwise
+func (r *WebActionResult) FullResponseBody() string {
+	return r.fullResponseBody
+}
+
+// MarkFailure marks the result as failed; error messages may quote response bodies, so they are
+// masked like them
 func (r *WebActionResult) MarkFailure(errorMessage string, executionMs int64) {
 	r.Status = StatusFailed
-	r.ErrorMessage = errorMessage
+	r.ErrorMessage = redact.Text(errorMessage)
 	r.ExecutionTimeMs = executionMs
 }
 
+// truncatedBodyMarker ends a response body that was cut short for storage
+const truncatedBodyMarker = "... [TRUNCATED]"
+
 // truncateResponseBody limits response body size for storage (max 50KB)
 func truncateResponseBody(body string) string {
 	const maxSize = 50 * 1024 // 50KB
 	if len(body) <= maxSize {
 		return body
 	}
-	return body[:maxSize] + "... [TRUNCATED]"
+	return body[:maxSize] + truncatedBodyMarker
+}
+
+// IsTruncatedResponseBody reports whether a stored response body was cut short for storage
+func IsTruncatedResponseBody(body string) bool {
+	return strings.HasSuffix(body, truncatedBodyMarker)
 }
 
-// ParseWebActionPayload parses a JSON string into a WebActionPayload
+// ParseWebActionPayload decodes and validates a web action payload map
 func ParseWebActionPayload(payloadJSON map[string]interface{}) (*WebActionPayload, error) {
-	jsonBytes, err := json.Marshal(payloadJSON)
-	if err != nil {
-		fmt.Println("Error marshaling map:", err)
-		return nil, fmt.Errorf("failed to parse web action payload: %w", err)
-	}
 	var payload WebActionPayload
-	if err := json.Unmarshal(jsonBytes, &payload); err != nil {
-		return nil, fmt.Errorf("failed to parse web action payload: %w", err)
-	}
-
-	// Validate the parsed payload
-	if err := payload.Validate(); err != nil {
+	if err := decodeFields("payload", payloadJSON, &payload); err != nil {
 		return nil, fmt.Errorf("payload validation failed: %w", err)
 	}
-
 	return &payload, nil
 }
 
@@ -333,22 +432,10 @@ func (p *WebActionPayload) RedactSensitiveData() *WebActionPayload {
 	if redacted.AuthConfig != nil {
 		redactedAuth := *redacted.AuthConfig
 		if redactedAuth.SecretName != "" {
-			redactedAuth.SecretName = "[REDACTED]"
+			redactedAuth.SecretName = redact.Mask
 		}
 		if redactedAuth.Headers != nil {
-			redactedHeaders := make(map[string]string)
-			for k, v := range redactedAuth.Headers {
-				lowerKey := strings.ToLower(k)
-				if strings.Contains(lowerKey, "auth") ||
-					strings.Contains(lowerKey, "token") ||
-					strings.Contains(lowerKey, "key") ||
-					strings.Contains(lowerKey, "secret") {
-					redactedHeaders[k] = "[REDACTED]"
-				} else {
-					redactedHeaders[k] = v
-				}
-			}
-			redactedAuth.Headers = redactedHeaders
+			redactedAuth.Headers = redact.Headers(redactedAuth.Headers)
 		}
 		redacted.AuthConfig = &redactedAuth
 	}
@@ -0,0 +1,53 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// EmailSender defines the interface for sending email
+type EmailSender interface {
+	SendEmail(ctx context.Context, to []string, subject, body string) error
+}
+
+// SESEmailClient sends plain-text email through Amazon SES
+type SESEmailClient struct {
+	client *sesv2.Client
+	from   string
+}
+
+// NewSESEmailClient creates an email client sending from an SES-verified address
+func NewSESEmailClient(client *sesv2.Client, from string) *SESEmailClient {
+	return &SESEmailClient{
+		client: client,
+		from:   from,
+	}
+}
+
+// SendEmail sends a plain-text email to the recipients
+func (c *SESEmailClient) SendEmail(ctx context.Context, to []string, subject, body string) error {
+	if len(to) == 0 {
+		return fmt.Errorf("no email recipients")
+	}
+
+	_, err := c.client.SendEmail(ctx, &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(c.from),
+		Destination:      &types.Destination{ToAddresses: to},
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{Data: aws.String(subject), Charset: aws.String("UTF-8")},
+				Body: &types.Body{
+					Text: &types.Content{Data: aws.String(body), Charset: aws.String("UTF-8")},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
@@ -7,6 +7,10 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -117,14 +121,132 @@ func (c *NtfyClient) sendOnce(ctx context.Context, message string) error {
 	return nil
 }
 
+// Action types of ntfy action buttons
+const (
+	// ActionHTTP sends an HTTP request when tapped
+	ActionHTTP = "http"
+	// ActionView opens a URL in the browser or app when tapped
+	ActionView = "view"
+)
+
+// MaxActions is the most action buttons ntfy shows on one notification
+const MaxActions = 3
+
+// Action is an ntfy action button
+type Action struct {
+	// Type is ActionHTTP (the default) or ActionView
+	Type   string
+	Label  string
+	URL    string
+	Method string // HTTP actions only; defaults to POST
+}
+
+// header formats the action for the ntfy Actions header
+func (a Action) header() string {
+	if a.Type == ActionView {
+		return fmt.Sprintf("view, %s, %s, clear=true", a.Label, a.URL)
+	}
+	method := a.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	return fmt.Sprintf("http, %s, %s, method=%s, clear=true", a.Label, a.URL, method)
+}
+
+// Options are the ntfy message features beyond the text itself; every field is optional
+type Options struct {
+	Title string
+	// Priority is min, low, default, high or urgent
+	Priority string
+	// Tags are shown beside the title; tags that name an emoji shortcode (e.g. "golf") render as the emoji
+	Tags []string
+	// Click is opened when the notification itself is tapped
+	Click   string
+	Actions []Action
+	// Attach is the URL of a file to attach; Filename overrides the name ntfy shows for it
+	Attach   string
+	Filename string
+}
+
+// Priorities ntfy accepts by name
+var Priorities = []string{"min", "low", "default", "high", "urgent"}
+
+// Validate rejects options ntfy would refuse or silently drop
+func (o Options) Validate() error {
+	if o.Priority != "" && !slices.Contains(Priorities, o.Priority) {
+		return fmt.Errorf("priority must be one of %s", strings.Join(Priorities, ", "))
+	}
+	if len(o.Actions) > MaxActions {
+		return fmt.Errorf("at most %d actions are allowed, got %d", MaxActions, len(o.Actions))
+	}
+	for _, action := range o.Actions {
+		if action.Label == "" {
+			return fmt.Errorf("action label is required")
+		}
+		if action.Type != "" && action.Type != ActionHTTP && action.Type != ActionView {
+			return fmt.Errorf("action type must be %s or %s, got %q", ActionHTTP, ActionView, action.Type)
+		}
+		if err := validateLink("action URL", action.URL); err != nil {
+			return err
+		}
+	}
+	if o.Click != "" {
+		if err := validateLink("click URL", o.Click); err != nil {
+			return err
+		}
+	}
+	if o.Attach != "" {
+		if err := validateLink("attachment URL", o.Attach); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateLink accepts absolute http and https URLs
+func validateLink(name, link string) error {
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s must be an absolute http(s) URL: %q", name, link)
+	}
+	return nil
+}
+
+// TopicURL returns the URL of another topic on the same ntfy server as topicURL, e.g.
+// TopicURL("https://ntfy.sh/alerts", "alice") is "https://ntfy.sh/alice"
+func TopicURL(topicURL, topic string) (string, error) {
+	parsed, err := url.Parse(topicURL)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid ntfy topic URL: %q", topicURL)
+	}
+	parsed.Path = path.Join(path.Dir(strings.TrimRight(parsed.Path, "/")), url.PathEscape(topic))
+	parsed.RawQuery = ""
+	return parsed.String(), nil
+}
+
 // SendWithTitle sends a notification with a custom title
 func (c *NtfyClient) SendWithTitle(ctx context.Context, title, message string) error {
+	return c.SendWithOptions(ctx, message, Options{Title: title})
+}
+
+// SendWithActions sends a notification with a custom title and action buttons
+func (c *NtfyClient) SendWithActions(ctx context.Context, title, message string, actions []Action) error {
+	return c.SendWithOptions(ctx, message, Options{Title: title, Actions: actions})
+}
+
+// SendWithOptions sends a notification with a title, priority, tags, click URL, action buttons
+// and attachment, retrying like Send
+func (c *NtfyClient) SendWithOptions(ctx context.Context, message string, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return fmt.Errorf("invalid notification options: %w", err)
+	}
+
 	var lastErr error
 
 	for attempt := 0; attempt < c.maxRetries; attempt++ {
 		if attempt > 0 {
 			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
-			c.logger.DebugContext(ctx, "retrying notification send with title",
+			c.logger.DebugContext(ctx, "retrying notification send with options",
 				slog.Int("attempt", attempt+1),
 				slog.Int("max_retries", c.maxRetries),
 				slog.Duration("backoff", backoff),
@@ -137,33 +259,45 @@ func (c *NtfyClient) SendWithTitle(ctx context.Context, title, message string) e
 			}
 		}
 
-		err := c.sendOnceWithTitle(ctx, title, message)
+		err := c.sendOnceWithOptions(ctx, message, opts)
 		if err == nil {
-			c.logger.DebugContext(ctx, "notification with title sent successfully",
+			c.logger.DebugContext(ctx, "notification with options sent successfully",
 				slog.Int("attempt", attempt+1),
 			)
 			return nil
 		}
 
 		lastErr = err
-		c.logger.WarnContext(ctx, "failed to send notification with title",
+		c.logger.WarnContext(ctx, "failed to send notification with options",
 			slog.Int("attempt", attempt+1),
 			slog.String("error", err.Error()),
 		)
 	}
 
-	return fmt.Errorf("failed to send notification with title after %d attempts: %w", c.maxRetries, lastErr)
+	return fmt.Errorf("failed to send notification with options after %d attempts: %w", c.maxRetries, lastErr)
 }
 
-// sendOnceWithTitle attempts to send a notification with title once without retries
-func (c *NtfyClient) sendOnceWithTitle(ctx context.Context, title, message string) error {
+// sendOnceWithOptions attempts to send a notification with options once without retries
+func (c *NtfyClient) sendOnceWithOptions(ctx context.Context, message string, opts Options) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL, bytes.NewBufferString(message))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "text/plain")
-	req.Header.Set("Title", title)
+	setHeader(req, "Title", opts.Title)
+	setHeader(req, "Priority", opts.Priority)
+	setHeader(req, "Tags", strings.Join(opts.Tags, ","))
+	setHeader(req, "Click", opts.Click)
+	setHeader(req, "Attach", opts.Attach)
+	setHeader(req, "Filename", opts.Filename)
+	if len(opts.Actions) > 0 {
+		headers := make([]string, 0, len(opts.Actions))
+		for _, action := range opts.Actions {
+			headers = append(headers, action.header())
+		}
+		req.Header.Set("Actions", strings.Join(headers, "; "))
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -179,3 +313,10 @@ func (c *NtfyClient) sendOnceWithTitle(ctx context.Context, title, message strin
 
 	return nil
 }
+
+// setHeader sets an ntfy header only when it has a value, so ntfy applies its own default
+func setHeader(req *http.Request, name, value string) {
+	if value != "" {
+		req.Header.Set(name, value)
+	}
+}
@@ -166,6 +166,131 @@ func TestNtfyClient_SendWithTitle_Success(t *testing.T) {
 	}
 }
 
+func TestNtfyClient_SendWithActions_Success(t *testing.T) {
+	var receivedActions string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedActions = r.Header.Get("Actions")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewNtfyClient(NtfyClientConfig{
+		BaseURL:    server.URL,
+		MaxRetries: 1,
+		Logger:     slog.Default(),
+	})
+
+	err := client.SendWithActions(context.Background(), "Approve booking?", "test message", []Action{
+		{Label: "Approve", URL: "https://api.example.com/api/approvals/abc?decision=approve"},
+		{Label: "Decline", URL: "https://api.example.com/api/approvals/abc?decision=decline"},
+	})
+	if err != nil {
+		t.Fatalf("SendWithActions() error = %v, want nil", err)
+	}
+
+	want := "http, Approve, https://api.example.com/api/approvals/abc?decision=approve, method=POST, clear=true; " +
+		"http, Decline, https://api.example.com/api/approvals/abc?decision=decline, method=POST, clear=true"
+	if receivedActions != want {
+		t.Errorf("Actions header = %q, want %q", receivedActions, want)
+	}
+}
+
+func TestNtfyClient_SendWithOptions_Success(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewNtfyClient(NtfyClientConfig{
+		BaseURL:    server.URL,
+		MaxRetries: 1,
+		Logger:     slog.Default(),
+	})
+
+	err := client.SendWithOptions(context.Background(), "Tee time booked", Options{
+		Title:    "Booked",
+		Priority: "high",
+		Tags:     []string{"golf", "white_check_mark"},
+		Click:    "https://birdsfoot.cps.golf/onlineresweb",
+		Actions: []Action{
+			{Type: ActionView, Label: "View reservation", URL: "https://birdsfoot.cps.golf/onlineresweb"},
+			{Label: "Cancel booking", URL: "https://api.example.com/cancel", Method: http.MethodDelete},
+		},
+		Attach:   "https://example.com/scorecard.png",
+		Filename: "scorecard.png",
+	})
+	if err != nil {
+		t.Fatalf("SendWithOptions() error = %v, want nil", err)
+	}
+
+	want := map[string]string{
+		"Title":    "Booked",
+		"Priority": "high",
+		"Tags":     "golf,white_check_mark",
+		"Click":    "https://birdsfoot.cps.golf/onlineresweb",
+		"Attach":   "https://example.com/scorecard.png",
+		"Filename": "scorecard.png",
+		"Actions": "view, View reservation, https://birdsfoot.cps.golf/onlineresweb, clear=true; " +
+			"http, Cancel booking, https://api.example.com/cancel, method=DELETE, clear=true",
+	}
+	for header, value := range want {
+		if got := received.Get(header); got != value {
+			t.Errorf("%s header = %q, want %q", header, got, value)
+		}
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	link := "https://example.com"
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "empty", opts: Options{}},
+		{name: "unknown priority", opts: Options{Priority: "critical"}, wantErr: true},
+		{name: "too many actions", opts: Options{Actions: []Action{{Label: "1", URL: link}, {Label: "2", URL: link}, {Label: "3", URL: link}, {Label: "4", URL: link}}}, wantErr: true},
+		{name: "action without label", opts: Options{Actions: []Action{{URL: link}}}, wantErr: true},
+		{name: "unknown action type", opts: Options{Actions: []Action{{Type: "broadcast", Label: "Go", URL: link}}}, wantErr: true},
+		{name: "relative click URL", opts: Options{Click: "/reservations"}, wantErr: true},
+		{name: "non-http attachment", opts: Options{Attach: "file:///etc/passwd"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTopicURL(t *testing.T) {
+	tests := []struct {
+		base string
+		want string
+	}{
+		{"https://ntfy.sh/rzesz-alerts", "https://ntfy.sh/alice"},
+		{"https://ntfy.example.com/ntfy/alerts/", "https://ntfy.example.com/ntfy/alice"},
+		{"https://ntfy.sh", "https://ntfy.sh/alice"},
+	}
+	for _, tt := range tests {
+		got, err := TopicURL(tt.base, "alice")
+		if err != nil {
+			t.Fatalf("TopicURL(%q) error = %v", tt.base, err)
+		}
+		if got != tt.want {
+			t.Errorf("TopicURL(%q) = %q, want %q", tt.base, got, tt.want)
+		}
+	}
+
+	if _, err := TopicURL("not a url", "alice"); err == nil {
+		t.Error("expected an error for a URL without a host")
+	}
+}
+
 func TestNtfyClient_Interface(t *testing.T) {
 	// Verify that NtfyClient implements Client interface
 	var _ Client = (*NtfyClient)(nil)
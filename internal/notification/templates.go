@@ -0,0 +1,186 @@
+package notification
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Names of the notification templates
+const (
+	TemplateBookingSuccess     = "booking_success"
+	TemplateReservationSummary = "reservation_summary"
+	TemplateWeatherAlert       = "weather_alert"
+)
+
+// DefaultLocale is used when a locale has no translation of a template
+const DefaultLocale = "en"
+
+// templateFiles holds one directory per locale, with a <name>.tmpl file per template. A locale
+// only needs the templates it translates; the rest fall back to DefaultLocale.
+//
+//go:embed templates
+var templateFiles embed.FS
+
+// BookingSuccessData is rendered by TemplateBookingSuccess
+type BookingSuccessData struct {
+	CourseName      string
+	ConfirmationKey string
+	ReservationID   int
+	// TeeTime is omitted when zero
+	TeeTime time.Time
+	// TeeSheetCourse is the course name on the tee sheet, which may name a specific layout
+	TeeSheetCourse string
+	Holes          int
+	Total          float64
+	DueAtCourse    float64
+}
+
+// ReservationSummaryData is rendered by TemplateReservationSummary
+type ReservationSummaryData struct {
+	// CourseName is omitted when empty
+	CourseName   string
+	Reservations []ReservationSummaryItem
+	// Now is the course-local time of the summary. When set, tee times today and tomorrow are
+	// shown as such; tee times should be in the same location.
+	Now time.Time
+}
+
+// ReservationSummaryItem is one upcoming reservation
+type ReservationSummaryItem struct {
+	TeeTime      time.Time
+	Players      int
+	Confirmation string
+}
+
+// WeatherAlertData is rendered by TemplateWeatherAlert, one day of forecast periods per message
+type WeatherAlertData struct {
+	Periods []WeatherAlertPeriod
+}
+
+// WeatherAlertPeriod is one forecast period, e.g. "Saturday" or "Saturday Night"
+type WeatherAlertPeriod struct {
+	Name             string
+	Temperature      int
+	TemperatureUnit  string
+	TemperatureTrend string
+	Wind             string
+	Forecast         string
+}
+
+// relativeDatetime formats t like datetime, but as "Today" or "Tomorrow" when it falls on now's
+// date or the next in now's location. A zero now always gives the full date.
+func relativeDatetime(t, now time.Time) string {
+	if !now.IsZero() {
+		t = t.In(now.Location())
+		day := func(d time.Time) string { return d.Format("2006-01-02") }
+		switch day(t) {
+		case day(now):
+			return t.Format("Today at 3:04 PM")
+		case day(now.AddDate(0, 0, 1)):
+			return t.Format("Tomorrow at 3:04 PM")
+		}
+	}
+	return t.Format("Mon, Jan 2 at 3:04 PM")
+}
+
+// templateFuncs are available to every template
+var templateFuncs = template.FuncMap{
+	"money": func(amount float64) string {
+		return fmt.Sprintf("%.2f", amount)
+	},
+	"datetime": func(t time.Time) string {
+		return t.Format("Mon, Jan 2 at 3:04 PM")
+	},
+	"relativeDatetime": relativeDatetime,
+	"temperatureEmoji": func(temperature int) string {
+		switch {
+		case temperature < 32:
+			return "❄️"
+		case temperature > 80:
+			return "🔥"
+		default:
+			return "🌡️"
+		}
+	},
+	"trendEmoji": func(trend string) string {
+		if trend == "falling" {
+			return "↘️"
+		}
+		return "↗️"
+	},
+}
+
+// Templates renders notification text from named templates, per locale
+type Templates struct {
+	locales map[string]*template.Template
+}
+
+// LoadTemplates parses the embedded templates of every locale
+func LoadTemplates() (*Templates, error) {
+	entries, err := fs.ReadDir(templateFiles, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification templates: %w", err)
+	}
+
+	t := &Templates{locales: make(map[string]*template.Template, len(entries))}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		locale := entry.Name()
+		parsed, err := template.New(locale).Funcs(templateFuncs).ParseFS(templateFiles, path.Join("templates", locale, "*.tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s notification templates: %w", locale, err)
+		}
+		t.locales[locale] = parsed
+	}
+	if t.locales[DefaultLocale] == nil {
+		return nil, fmt.Errorf("no %s notification templates", DefaultLocale)
+	}
+	return t, nil
+}
+
+// Render executes the named template for locale, falling back to DefaultLocale when the locale
+// has no translation of it. Trailing newlines are trimmed.
+func (t *Templates) Render(locale, name string, data any) (string, error) {
+	tmpl := t.lookup(locale, name)
+	if tmpl == nil {
+		return "", fmt.Errorf("unknown notification template: %s", name)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render notification template %s: %w", name, err)
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+func (t *Templates) lookup(locale, name string) *template.Template {
+	file := name + ".tmpl"
+	if set, ok := t.locales[locale]; ok {
+		if tmpl := set.Lookup(file); tmpl != nil {
+			return tmpl
+		}
+	}
+	return t.locales[DefaultLocale].Lookup(file)
+}
+
+// defaultTemplates are the embedded templates; they are checked by the package tests, so a
+// failure to parse them is a programming error
+var defaultTemplates = func() *Templates {
+	t, err := LoadTemplates()
+	if err != nil {
+		panic(err)
+	}
+	return t
+}()
+
+// Render executes the named template in DefaultLocale
+func Render(name string, data any) (string, error) {
+	return defaultTemplates.Render(DefaultLocale, name, data)
+}
@@ -0,0 +1,170 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender_BookingSuccess(t *testing.T) {
+	got, err := Render(TemplateBookingSuccess, BookingSuccessData{
+		CourseName:      "Totteridge",
+		ConfirmationKey: "ABC123",
+		ReservationID:   42,
+		TeeTime:         time.Date(2026, 6, 6, 8, 30, 0, 0, time.UTC),
+		TeeSheetCourse:  "Totteridge Back 9",
+		Holes:           18,
+		Total:           120,
+		DueAtCourse:     60.5,
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := "⛳ Tee Time Booked Successfully at Totteridge!\n\n" +
+		"Confirmation: ABC123\n" +
+		"Reservation ID: 42\n\n" +
+		"Date/Time: Sat, Jun 6 at 8:30 AM\n" +
+		"Course: Totteridge Back 9\n" +
+		"Holes: 18\n\n" +
+		"Total: $120.00\n" +
+		"Due at Course: $60.50\n\n" +
+		"See you on the course!"
+	if got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRender_BookingSuccessWithoutTeeTime(t *testing.T) {
+	got, err := Render(TemplateBookingSuccess, BookingSuccessData{CourseName: "Totteridge"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if strings.Contains(got, "Date/Time") {
+		t.Errorf("Render() included an unknown tee time:\n%s", got)
+	}
+}
+
+func TestRender_ReservationSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     ReservationSummaryData
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "no reservations",
+			data:     ReservationSummaryData{CourseName: "Totteridge"},
+			contains: []string{"No tee-times found."},
+			excludes: []string{"Total:"},
+		},
+		{
+			name: "reservations",
+			data: ReservationSummaryData{
+				CourseName: "Totteridge",
+				Reservations: []ReservationSummaryItem{
+					{TeeTime: time.Date(2026, 6, 6, 8, 30, 0, 0, time.UTC), Players: 4, Confirmation: "ABC123"},
+					{TeeTime: time.Date(2026, 6, 7, 9, 0, 0, 0, time.UTC), Players: 2},
+				},
+			},
+			contains: []string{
+				"⛳ Current Reservations at Totteridge:",
+				"- Sat, Jun 6 at 8:30 AM\n\t4 player(s)\n\tConfirmation: ABC123\n",
+				"- Sun, Jun 7 at 9:00 AM\n\t2 player(s)\n",
+				"🏌️ Total: 2 upcoming reservation(s)",
+			},
+		},
+		{
+			name: "today and tomorrow",
+			data: ReservationSummaryData{
+				Now: time.Date(2026, 6, 6, 22, 0, 0, 0, time.FixedZone("EDT", -4*60*60)),
+				Reservations: []ReservationSummaryItem{
+					{TeeTime: time.Date(2026, 6, 6, 23, 30, 0, 0, time.FixedZone("EDT", -4*60*60)), Players: 1},
+					// 03:00 UTC on the 7th is still the 6th at the course
+					{TeeTime: time.Date(2026, 6, 7, 3, 0, 0, 0, time.UTC), Players: 1},
+					{TeeTime: time.Date(2026, 6, 7, 8, 0, 0, 0, time.FixedZone("EDT", -4*60*60)), Players: 2},
+					{TeeTime: time.Date(2026, 6, 8, 8, 0, 0, 0, time.FixedZone("EDT", -4*60*60)), Players: 3},
+				},
+			},
+			contains: []string{
+				"- Today at 11:30 PM\n",
+				"- Today at 11:00 PM\n",
+				"- Tomorrow at 8:00 AM\n\t2 player(s)",
+				"- Mon, Jun 8 at 8:00 AM\n\t3 player(s)",
+			},
+		},
+		{
+			name: "no course name",
+			data: ReservationSummaryData{
+				Reservations: []ReservationSummaryItem{{TeeTime: time.Date(2026, 6, 6, 8, 30, 0, 0, time.UTC), Players: 1}},
+			},
+			contains: []string{"⛳ Current Reservations:"},
+			excludes: []string{" at :"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(TemplateReservationSummary, tt.data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Render() is missing %q:\n%s", want, got)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("Render() contains %q:\n%s", unwanted, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_WeatherAlert(t *testing.T) {
+	got, err := Render(TemplateWeatherAlert, WeatherAlertData{Periods: []WeatherAlertPeriod{
+		{Name: "Saturday", Temperature: 85, TemperatureUnit: "F", Wind: "10 mph NW", Forecast: "Sunny"},
+		{Name: "Saturday Night", Temperature: 28, TemperatureUnit: "F", TemperatureTrend: "falling", Wind: "5 mph N", Forecast: "Clear"},
+	}})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"🌤️ Weather Forecast\n📅 Saturday\n",
+		"🔥 85°F\n💨 Wind: 10 mph NW\n☁️ Sunny",
+		"❄️ 28°F ↘️ falling\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestTemplates_LocaleFallback(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	data := BookingSuccessData{CourseName: "Totteridge"}
+	want, err := templates.Render(DefaultLocale, TemplateBookingSuccess, data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	got, err := templates.Render("fr", TemplateBookingSuccess, data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("Render(fr) = %q, want the %s template", got, DefaultLocale)
+	}
+}
+
+func TestTemplates_UnknownTemplate(t *testing.T) {
+	if _, err := Render("does_not_exist", nil); err == nil {
+		t.Error("expected an error for an unknown template")
+	}
+}
@@ -0,0 +1,154 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/redact"
+)
+
+// Client handles OAuth 2.0 authentication flows
+type Client struct {
+	httpClient     *httpclient.Client
+	secretsManager *secrets.Manager
+	logger         *slog.Logger
+	tokens         *TokenCache
+}
+
+// NewClient creates a new OAuth client with an in-memory token cache
+func NewClient(httpClient *httpclient.Client, secretsManager *secrets.Manager, logger *slog.Logger) *Client {
+	return &Client{
+		httpClient:     httpClient,
+		secretsManager: secretsManager,
+		logger:         logger,
+		tokens:         NewTokenCache(DefaultTokenRefreshBefore, logger),
+	}
+}
+
+// SetTokenCache replaces the token cache, e.g. with one backed by a shared TokenStore
+func (oc *Client) SetTokenCache(cache *TokenCache) {
+	oc.tokens = cache
+}
+
+// OAuthTokenResponse represents the response from an OAuth token endpoint
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// OAuthPasswordGrant performs OAuth 2.0 password grant flow
+func (oc *Client) OAuthPasswordGrant(ctx context.Context, tokenURL, secretName, scope string, additionalHeaders map[string]string) (string, error) {
+	// Reuse an unexpired token for the same credentials and scope
+	cacheKey := TokenCacheKey(secretName, scope)
+	if cachedToken, found := oc.tokens.Get(ctx, cacheKey); found {
+		oc.logger.Debug("using cached OAuth token")
+		return cachedToken, nil
+	}
+
+	oc.logger.Debug("fetching new OAuth token via password grant",
+		slog.String("token_url", tokenURL),
+		slog.String("secret_name", "[REDACTED]"),
+	)
+
+	// Fetch credentials from Secrets Manager
+	creds, err := oc.secretsManager.GetOAuthCredentials(ctx, secretName)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve OAuth credentials: %w", err)
+	}
+
+	tokenResp, err := oc.RequestToken(ctx, tokenURL, creds, scope, additionalHeaders)
+	if isUnauthorized(err) {
+		// The password may have been rotated since the secret was cached; try once more with the
+		// secret's current value, unless it has not changed (a second rejection risks a lockout)
+		oc.secretsManager.Invalidate(secretName)
+		fresh, freshErr := oc.secretsManager.GetOAuthCredentials(ctx, secretName)
+		if freshErr != nil {
+			return "", fmt.Errorf("failed to retrieve OAuth credentials: %w", freshErr)
+		}
+		if *fresh != *creds {
+			oc.logger.Warn("OAuth credentials rejected, retrying with the rotated secret")
+			tokenResp, err = oc.RequestToken(ctx, tokenURL, fresh, scope, additionalHeaders)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Cache until the JWT exp claim, falling back to expires_in
+	expiresAt := tokenExpiry(tokenResp.AccessToken, tokenResp.ExpiresIn, time.Now())
+	oc.tokens.Put(ctx, cacheKey, tokenResp.AccessToken, expiresAt)
+
+	oc.logger.Debug("OAuth token acquired successfully",
+		slog.String("token_type", tokenResp.TokenType),
+		slog.Time("expires_at", expiresAt),
+		// SECURITY: Never log the actual token
+	)
+
+	return tokenResp.AccessToken, nil
+}
+
+// RequestToken performs the password grant with creds, without caching. It also lets secret
+// rotation check new credentials before they become current.
+func (oc *Client) RequestToken(ctx context.Context, tokenURL string, creds *secrets.OAuthCredentials, scope string, additionalHeaders map[string]string) (*OAuthTokenResponse, error) {
+	// Prepare form data
+	formData := url.Values{
+		"grant_type": {"password"},
+		"username":   {creds.Username},
+		"password":   {creds.Password},
+	}
+
+	// Add client credentials if present
+	if creds.ClientID != "" {
+		formData.Set("client_id", creds.ClientID)
+	}
+	if creds.ClientSecret != "" {
+		formData.Set("client_secret", creds.ClientSecret)
+	}
+
+	// Add scope if provided
+	if scope != "" {
+		formData.Set("scope", scope)
+	}
+
+	// Perform token request
+	resp, err := oc.httpClient.DoFormPost(ctx, tokenURL, formData, additionalHeaders)
+	if err != nil {
+		oc.logger.Error("OAuth token request failed",
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("OAuth token request failed: %w", err)
+	}
+
+	// Parse token response
+	var tokenResp OAuthTokenResponse
+	if err := json.Unmarshal([]byte(resp.Body), &tokenResp); err != nil {
+		oc.logger.Error("failed to parse OAuth token response",
+			slog.String("error", err.Error()),
+			slog.String("response_body", httpclient.TruncateBody(redact.Text(resp.Body), 200)),
+		)
+		return nil, fmt.Errorf("failed to parse OAuth token response: %w", err)
+	}
+
+	// Validate response
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("OAuth response missing access_token")
+	}
+	return &tokenResp, nil
+}
+
+// isUnauthorized reports whether the token endpoint rejected the credentials
+func isUnauthorized(err error) bool {
+	var statusErr *httpclient.StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized
+}
@@ -0,0 +1,96 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+)
+
+// rotatingSource serves whatever password it currently holds, like a secret an operator updates
+type rotatingSource struct {
+	password string
+	reads    int
+}
+
+func (s *rotatingSource) GetSecretString(ctx context.Context, secretName string) (string, error) {
+	s.reads++
+	body, _ := json.Marshal(map[string]string{"username": "golfer", "password": s.password})
+	return string(body), nil
+}
+
+// tokenServer accepts only password and counts the grants it receives
+func tokenServer(t *testing.T, password string, requests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		if r.PostForm.Get("password") != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"error":"invalid_grant"}`)
+			return
+		}
+		token := fakeJWT(time.Now().Add(time.Hour).Unix())
+		_, _ = io.WriteString(w, `{"access_token":"`+token+`","token_type":"Bearer","expires_in":3600}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(source secrets.Source) *Client {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	httpClient := httpclient.NewClient(logger)
+	httpClient.SetCircuitBreaker(nil)
+	return NewClient(httpClient, secrets.NewManagerWithSource(source, logger), logger)
+}
+
+func TestOAuthPasswordGrant_RetriesWithRotatedSecret(t *testing.T) {
+	var requests int
+	server := tokenServer(t, "new-password", &requests)
+	source := &rotatingSource{password: "old-password"}
+	client := newTestClient(source)
+
+	// Cache the old password, then rotate it behind the manager's back
+	if _, err := client.secretsManager.GetOAuthCredentials(context.Background(), "golf"); err != nil {
+		t.Fatalf("GetOAuthCredentials() error = %v", err)
+	}
+	source.password = "new-password"
+
+	token, err := client.OAuthPasswordGrant(context.Background(), server.URL, "golf", "", nil)
+	if err != nil {
+		t.Fatalf("OAuthPasswordGrant() error = %v", err)
+	}
+	if token == "" {
+		t.Error("expected a token")
+	}
+	if requests != 2 {
+		t.Errorf("token requests = %d, want 2 (rejected, then retried with the fresh secret)", requests)
+	}
+}
+
+func TestOAuthPasswordGrant_DoesNotRetrySameCredentials(t *testing.T) {
+	var requests int
+	server := tokenServer(t, "new-password", &requests)
+	source := &rotatingSource{password: "old-password"}
+	client := newTestClient(source)
+
+	_, err := client.OAuthPasswordGrant(context.Background(), server.URL, "golf", "", nil)
+	if !isUnauthorized(err) {
+		t.Fatalf("error = %v, want a 401", err)
+	}
+	if requests != 1 {
+		t.Errorf("token requests = %d, want 1; unchanged credentials must not be retried", requests)
+	}
+	if source.reads != 2 {
+		t.Errorf("secret reads = %d, want 2 (cached read, then a fresh one)", source.reads)
+	}
+}
@@ -0,0 +1,163 @@
+package oauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// DefaultTokenRefreshBefore is how long before expiry a cached token is refreshed
+const DefaultTokenRefreshBefore = 10 * time.Minute
+
+// defaultTokenLifetime is assumed when neither the token nor the response says when it expires
+const defaultTokenLifetime = time.Hour
+
+// TokenStore persists OAuth tokens so they survive Lambda cold starts
+type TokenStore interface {
+	// GetToken returns the stored token for key, or nil if there is none
+	GetToken(ctx context.Context, key string) (*models.OAuthToken, error)
+
+	// PutToken stores a token
+	PutToken(ctx context.Context, token *models.OAuthToken) error
+}
+
+// TokenCache caches OAuth access tokens in memory and, optionally, in a shared TokenStore
+type TokenCache struct {
+	mu            sync.RWMutex
+	memory        map[string]models.OAuthToken
+	store         TokenStore
+	refreshBefore time.Duration
+	logger        *slog.Logger
+	now           func() time.Time
+}
+
+// NewTokenCache creates an in-memory token cache that treats tokens as stale refreshBefore ahead of expiry
+func NewTokenCache(refreshBefore time.Duration, logger *slog.Logger) *TokenCache {
+	if refreshBefore <= 0 {
+		refreshBefore = DefaultTokenRefreshBefore
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &TokenCache{
+		memory:        make(map[string]models.OAuthToken),
+		refreshBefore: refreshBefore,
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+// SetStore shares cached tokens across invocations through store
+func (c *TokenCache) SetStore(store TokenStore) {
+	c.store = store
+}
+
+// TokenCacheKey builds the cache key for a credentials secret and scope
+func TokenCacheKey(secretName, scope string) string {
+	return fmt.Sprintf("%s|%s", secretName, scope)
+}
+
+// fresh reports whether a token is usable without refreshing
+func (c *TokenCache) fresh(token models.OAuthToken) bool {
+	return token.AccessToken != "" && c.now().Add(c.refreshBefore).Before(token.ExpiresAt)
+}
+
+// Get returns a cached token that is not close to expiry
+func (c *TokenCache) Get(ctx context.Context, key string) (string, bool) {
+	c.mu.RLock()
+	token, ok := c.memory[key]
+	c.mu.RUnlock()
+	if ok && c.fresh(token) {
+		// SECURITY: Never log the actual token
+		c.logger.Debug("OAuth token cache hit", slog.String("source", "memory"))
+		return token.AccessToken, true
+	}
+
+	if c.store == nil {
+		return "", false
+	}
+
+	stored, err := c.store.GetToken(ctx, key)
+	if err != nil {
+		c.logger.Warn("failed to read shared OAuth token cache", slog.String("error", err.Error()))
+		return "", false
+	}
+	if stored == nil || !c.fresh(*stored) {
+		return "", false
+	}
+
+	c.mu.Lock()
+	c.memory[key] = *stored
+	c.mu.Unlock()
+
+	c.logger.Debug("OAuth token cache hit", slog.String("source", "store"))
+	return stored.AccessToken, true
+}
+
+// Put caches a token until expiresAt
+func (c *TokenCache) Put(ctx context.Context, key, accessToken string, expiresAt time.Time) {
+	token := models.OAuthToken{
+		Key:         key,
+		AccessToken: accessToken,
+		ExpiresAt:   expiresAt,
+		TTL:         expiresAt.Unix(),
+	}
+
+	c.mu.Lock()
+	c.memory[key] = token
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.PutToken(ctx, &token); err != nil {
+			c.logger.Warn("failed to write shared OAuth token cache", slog.String("error", err.Error()))
+		}
+	}
+
+	c.logger.Debug("OAuth token cached", slog.Time("expires_at", expiresAt))
+}
+
+// Clear drops all tokens from memory
+func (c *TokenCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.memory = make(map[string]models.OAuthToken)
+}
+
+// tokenExpiry returns when an access token expires, preferring the JWT exp claim over expires_in
+func tokenExpiry(accessToken string, expiresIn int, now time.Time) time.Time {
+	if exp, ok := jwtExpiry(accessToken); ok {
+		return exp
+	}
+	if expiresIn > 0 {
+		return now.Add(time.Duration(expiresIn) * time.Second)
+	}
+	return now.Add(defaultTokenLifetime)
+}
+
+// jwtExpiry reads the exp claim of a JWT without verifying it; verification happens where the claims are used
+func jwtExpiry(accessToken string) (time.Time, bool) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
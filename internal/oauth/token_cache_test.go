@@ -0,0 +1,124 @@
+package oauth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// fakeJWT builds an unsigned token carrying only an exp claim
+func fakeJWT(exp int64) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"golfer","exp":%d}`, exp)))
+	return "eyJhbGciOiJub25lIn0." + payload + ".sig"
+}
+
+type fakeTokenStore struct {
+	tokens map[string]*models.OAuthToken
+	err    error
+	puts   int
+}
+
+func (s *fakeTokenStore) GetToken(ctx context.Context, key string) (*models.OAuthToken, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.tokens[key], nil
+}
+
+func (s *fakeTokenStore) PutToken(ctx context.Context, token *models.OAuthToken) error {
+	s.puts++
+	if s.err != nil {
+		return s.err
+	}
+	s.tokens[token.Key] = token
+	return nil
+}
+
+func TestTokenExpiry(t *testing.T) {
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	exp := now.Add(45 * time.Minute)
+
+	tests := []struct {
+		name      string
+		token     string
+		expiresIn int
+		want      time.Time
+	}{
+		{"jwt exp claim wins", fakeJWT(exp.Unix()), 7200, exp},
+		{"opaque token uses expires_in", "opaque-token", 1800, now.Add(30 * time.Minute)},
+		{"malformed jwt uses expires_in", "a.!!!.c", 60, now.Add(time.Minute)},
+		{"no expiry information", "opaque-token", 0, now.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenExpiry(tt.token, tt.expiresIn, now); !got.Equal(tt.want) {
+				t.Errorf("tokenExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenCache_RefreshWindow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewTokenCache(5*time.Minute, nil)
+	cache.now = func() time.Time { return now }
+
+	key := TokenCacheKey("golf-secret", "onlinereservation")
+	cache.Put(ctx, key, "token-a", now.Add(10*time.Minute))
+
+	if got, ok := cache.Get(ctx, key); !ok || got != "token-a" {
+		t.Fatalf("Get() = %q, %v; want token-a, true", got, ok)
+	}
+
+	// Within the refresh window the token is treated as expired
+	now = now.Add(6 * time.Minute)
+	if _, ok := cache.Get(ctx, key); ok {
+		t.Error("Get() should miss when the token is within the refresh window")
+	}
+
+	if _, ok := cache.Get(ctx, TokenCacheKey("golf-secret", "other-scope")); ok {
+		t.Error("Get() should miss for a different scope")
+	}
+}
+
+func TestTokenCache_Store(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	key := TokenCacheKey("golf-secret", "onlinereservation")
+
+	store := &fakeTokenStore{tokens: map[string]*models.OAuthToken{}}
+	writer := NewTokenCache(time.Minute, nil)
+	writer.now = func() time.Time { return now }
+	writer.SetStore(store)
+	writer.Put(ctx, key, "shared-token", now.Add(time.Hour))
+
+	if store.puts != 1 || store.tokens[key].TTL != now.Add(time.Hour).Unix() {
+		t.Fatalf("store not written as expected: puts=%d token=%+v", store.puts, store.tokens[key])
+	}
+
+	// A fresh cache, as in a cold-started Lambda, picks the token up from the store
+	reader := NewTokenCache(time.Minute, nil)
+	reader.now = func() time.Time { return now }
+	reader.SetStore(store)
+	if got, ok := reader.Get(ctx, key); !ok || got != "shared-token" {
+		t.Fatalf("Get() = %q, %v; want shared-token, true", got, ok)
+	}
+
+	// Store failures degrade to a cache miss
+	store.err = errors.New("throttled")
+	reader.Clear()
+	if _, ok := reader.Get(ctx, key); ok {
+		t.Error("Get() should miss when the store fails")
+	}
+	writer.Put(ctx, key, "next-token", now.Add(time.Hour))
+	if got, ok := writer.Get(ctx, key); !ok || got != "next-token" {
+		t.Errorf("memory cache should still hold the token after a store failure, got %q, %v", got, ok)
+	}
+}
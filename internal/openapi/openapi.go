@@ -0,0 +1,309 @@
+// Package openapi builds an OpenAPI 3 document from the route definitions handlers dispatch on,
+// so the served specification cannot drift from the routes. Request and response schemas are
+// reflected from the Go types the handlers decode and encode.
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version of the documents built here
+const Version = "3.0.3"
+
+// Route describes one API endpoint
+type Route struct {
+	// Method is the HTTP method, such as GET
+	Method string
+
+	// Path is the path template; a {name} segment matches any single segment
+	Path string
+
+	// Tag groups the route in the documentation, such as messages
+	Tag string
+
+	// Summary is a one-line description of the route
+	Summary string
+
+	// Description adds detail to the summary (optional)
+	Description string
+
+	// Query are the query parameters the route reads
+	Query []Param
+
+	// Request is a value of the request body's type, nil for no body
+	Request any
+
+	// RequestContentType is the media type of the body (default application/json)
+	RequestContentType string
+
+	// Response is a value of the success body's type, nil for no body. List documents the
+	// paginated envelope of list endpoints.
+	Response any
+
+	// ResponseContentType is the media type of the success body (default application/json)
+	ResponseContentType string
+
+	// Status is the success status code (default 200)
+	Status int
+
+	// Errors are the error status codes the route answers with
+	Errors []int
+
+	// ErrorBody is a value of the type of the route's error responses, when it differs from the
+	// document's (optional)
+	ErrorBody any
+
+	// Public routes need no credentials
+	Public bool
+}
+
+// Param is a query parameter
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Match reports whether the route serves method and path, returning the values of the path
+// template's {name} segments
+func (r Route) Match(method, path string) (map[string]string, bool) {
+	if r.Method != method {
+		return nil, false
+	}
+	want := strings.Split(strings.Trim(r.Path, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+	var params map[string]string
+	for i, segment := range want {
+		if name, ok := pathParam(segment); ok {
+			if got[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = got[i]
+			continue
+		}
+		if segment != got[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// pathParam returns the name of a {name} path template segment
+func pathParam(segment string) (string, bool) {
+	if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
+
+// Options describe the document and the error bodies shared by every route
+type Options struct {
+	// Title and Version identify the API
+	Title   string
+	Version string
+
+	// Description introduces the API (optional)
+	Description string
+
+	// ErrorBody is a value of the type of error responses
+	ErrorBody any
+
+	// ValidationProblem is a value of the type of 400 responses, sent as application/problem+json
+	// (optional; 400s use ErrorBody without it)
+	ValidationProblem any
+
+	// SecurityScheme names the credentials non-public routes need, documented as a bearer token
+	// (optional)
+	SecurityScheme string
+}
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Tags       []Tag                           `json:"tags,omitempty"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+// Info identifies the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// Components holds the schemas and security schemes operations refer to
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how callers authenticate
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// Operation is one method of a path
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is an operation's request body
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is one response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body in one media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Build documents routes
+func Build(opts Options, routes ...Route) *Document {
+	g := newGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: opts.Title, Version: opts.Version, Description: opts.Description},
+		Paths:   make(map[string]map[string]Operation),
+	}
+
+	tags := make(map[string]bool)
+	for _, route := range routes {
+		if route.Tag != "" && !tags[route.Tag] {
+			tags[route.Tag] = true
+			doc.Tags = append(doc.Tags, Tag{Name: route.Tag})
+		}
+		operations, ok := doc.Paths[route.Path]
+		if !ok {
+			operations = make(map[string]Operation)
+			doc.Paths[route.Path] = operations
+		}
+		operations[strings.ToLower(route.Method)] = g.operation(route, opts)
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	doc.Components.Schemas = g.schemas
+	if opts.SecurityScheme != "" {
+		doc.Components.SecuritySchemes = map[string]SecurityScheme{
+			opts.SecurityScheme: {Type: "http", Scheme: "bearer"},
+		}
+	}
+	return doc
+}
+
+// operation documents one route
+func (g *generator) operation(route Route, opts Options) Operation {
+	op := Operation{
+		Summary:     route.Summary,
+		Description: route.Description,
+		OperationID: operationID(route),
+		Responses:   make(map[string]Response),
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	if !route.Public && opts.SecurityScheme != "" {
+		op.Security = []map[string][]string{{opts.SecurityScheme: {}}}
+	}
+
+	for _, segment := range strings.Split(route.Path, "/") {
+		if name, ok := pathParam(segment); ok {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	for _, param := range route.Query {
+		op.Parameters = append(op.Parameters, Parameter{Name: param.Name, In: "query", Description: param.Description, Required: param.Required, Schema: &Schema{Type: "string"}})
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{orDefault(route.RequestContentType, "application/json"): {Schema: g.schemaFor(route.Request)}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		success.Content = map[string]MediaType{orDefault(route.ResponseContentType, "application/json"): {Schema: g.schemaFor(route.Response)}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errorBody := opts.ErrorBody
+	if route.ErrorBody != nil {
+		errorBody = route.ErrorBody
+	}
+	for _, code := range route.Errors {
+		response := Response{Description: http.StatusText(code)}
+		switch {
+		case code == http.StatusBadRequest && opts.ValidationProblem != nil && route.ErrorBody == nil:
+			response.Content = map[string]MediaType{"application/problem+json": {Schema: g.schemaFor(opts.ValidationProblem)}}
+		case errorBody != nil:
+			response.Content = map[string]MediaType{"application/json": {Schema: g.schemaFor(errorBody)}}
+		}
+		op.Responses[strconv.Itoa(code)] = response
+	}
+	return op
+}
+
+// operationID derives a stable operation ID such as getApiMessagesIdRetry from the route
+func operationID(route Route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(route.Method))
+	for _, part := range strings.FieldsFunc(route.Path, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// orDefault returns value, or fallback when it is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
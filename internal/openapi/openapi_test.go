@@ -0,0 +1,136 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testLimits struct {
+	MaxTokens int `json:"max_tokens,omitempty"`
+}
+
+type testItem struct {
+	ID      string         `json:"id"`
+	Created time.Time      `json:"created_date"`
+	Tags    []string       `json:"tags,omitempty"`
+	Labels  map[string]int `json:"labels,omitempty"`
+	Parent  *testItem      `json:"parent,omitempty"`
+	Extra   interface{}    `json:"extra,omitempty"`
+	Secret  string         `json:"-"`
+	hidden  string
+	Nested  map[string]string `json:"nested"`
+	testLimits
+}
+
+type testError struct {
+	Error string `json:"error"`
+}
+
+func TestRoute_Match(t *testing.T) {
+	route := Route{Method: http.MethodPost, Path: "/api/messages/{id}/retry"}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   map[string]string
+		wantOK bool
+	}{
+		{"match", http.MethodPost, "/api/messages/msg_1/retry", map[string]string{"id": "msg_1"}, true},
+		{"wrong method", http.MethodGet, "/api/messages/msg_1/retry", nil, false},
+		{"wrong literal", http.MethodPost, "/api/messages/msg_1/redo", nil, false},
+		{"extra segment", http.MethodPost, "/api/messages/a/b/retry", nil, false},
+		{"empty parameter", http.MethodPost, "/api/messages//retry", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := route.Match(tt.method, tt.path)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match(%s %s) = %v, %v, want %v, %v", tt.method, tt.path, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	doc := Build(Options{Title: "test", Version: "1", ErrorBody: testError{}, SecurityScheme: "apiKey"},
+		Route{Method: http.MethodGet, Path: "/items", Tag: "items", Summary: "List items", Response: List("items", testItem{}), Query: []Param{{Name: "limit"}}, Errors: []int{http.StatusBadRequest}},
+		Route{Method: http.MethodPost, Path: "/items/{id}", Tag: "items", Summary: "Update an item", Request: &testItem{}, Response: testItem{}, Status: http.StatusAccepted},
+		Route{Method: http.MethodGet, Path: "/health", Summary: "Health", Public: true},
+		Route{Method: http.MethodDelete, Path: "/items/{id}", Errors: []int{http.StatusNotFound}, ErrorBody: testItem{}},
+	)
+
+	list := doc.Paths["/items"]["get"]
+	if list.OperationID != "getItems" || len(list.Parameters) != 1 || list.Parameters[0].In != "query" {
+		t.Errorf("list operation = %+v", list)
+	}
+	if got := list.Responses["200"].Content["application/json"].Schema.Properties["items"].Items.Ref; got != "#/components/schemas/testItem" {
+		t.Errorf("list item schema = %q", got)
+	}
+	if list.Responses["400"].Content["application/json"].Schema.Ref != "#/components/schemas/testError" {
+		t.Errorf("400 response = %+v", list.Responses["400"])
+	}
+	if len(list.Security) != 1 {
+		t.Errorf("Security = %v, want the api key", list.Security)
+	}
+
+	update := doc.Paths["/items/{id}"]["post"]
+	if len(update.Parameters) != 1 || update.Parameters[0].Name != "id" || update.Parameters[0].In != "path" {
+		t.Errorf("path parameters = %+v", update.Parameters)
+	}
+	if _, ok := update.Responses["202"]; !ok || update.RequestBody == nil {
+		t.Errorf("update operation = %+v", update)
+	}
+
+	if got := doc.Paths["/items/{id}"]["delete"].Responses["404"].Content["application/json"].Schema.Ref; got != "#/components/schemas/testItem" {
+		t.Errorf("404 response of a route with its own error body = %q", got)
+	}
+
+	if health := doc.Paths["/health"]["get"]; health.Security != nil {
+		t.Errorf("public route Security = %v", health.Security)
+	}
+
+	item := doc.Components.Schemas["testItem"]
+	if item == nil {
+		t.Fatalf("components = %v, want testItem", doc.Components.Schemas)
+	}
+	if got := item.Properties["created_date"]; got.Type != "string" || got.Format != "date-time" {
+		t.Errorf("created_date = %+v", got)
+	}
+	if item.Properties["parent"].Ref != "#/components/schemas/testItem" {
+		t.Errorf("parent = %+v, want a reference to itself", item.Properties["parent"])
+	}
+	if item.Properties["labels"].AdditionalProperties.Type != "integer" {
+		t.Errorf("labels = %+v", item.Properties["labels"])
+	}
+	if _, ok := item.Properties["max_tokens"]; !ok {
+		t.Error("embedded struct fields should be flattened")
+	}
+	for _, name := range []string{"Secret", "-", "hidden", "testLimits"} {
+		if _, ok := item.Properties[name]; ok {
+			t.Errorf("property %q should not be documented", name)
+		}
+	}
+	if !reflect.DeepEqual(item.Required, []string{"id", "created_date", "nested"}) {
+		t.Errorf("Required = %v", item.Required)
+	}
+
+	if _, err := json.Marshal(doc); err != nil {
+		t.Errorf("Marshal() error = %v", err)
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	page, err := SwaggerUI("rez_agent API", "/api/openapi.json")
+	if err != nil {
+		t.Fatalf("SwaggerUI() error = %v", err)
+	}
+	if !strings.Contains(page, `"/api/openapi.json"`) || !strings.Contains(page, "<title>rez_agent API</title>") {
+		t.Errorf("SwaggerUI() = %s", page)
+	}
+}
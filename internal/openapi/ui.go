@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// swaggerUIVersion pins the Swagger UI release loaded from the CDN
+const swaggerUIVersion = "5.17.14"
+
+// swaggerUITemplate renders Swagger UI against a specification URL
+var swaggerUITemplate = template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: {{.SpecURL}}, dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`))
+
+// SwaggerUI renders a page that browses the specification at specURL
+func SwaggerUI(title, specURL string) (string, error) {
+	var page bytes.Buffer
+	err := swaggerUITemplate.Execute(&page, struct {
+		Title   string
+		Version string
+		SpecURL string
+	}{title, swaggerUIVersion, specURL})
+	if err != nil {
+		return "", fmt.Errorf("failed to render Swagger UI: %w", err)
+	}
+	return page.String(), nil
+}
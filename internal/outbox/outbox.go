@@ -0,0 +1,161 @@
+// Package outbox keeps a message's publication consistent with its persistence. The messages
+// table is the outbox: a saved message whose status is still created has not been published yet,
+// and the Relay publishes it from the table's stream, so a crash between saving and publishing
+// cannot strand it.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// ErrNotPublished means a message was saved but could not be published. It stays created and the
+// relay or the stuck message sweeper publishes it, so the caller must not send it again.
+var ErrNotPublished = errors.New("message saved but not yet published")
+
+// Writer saves new messages to the outbox and, unless a Relay publishes them, publishes them too
+type Writer struct {
+	repo      repository.MessageRepository
+	publisher messaging.SNSPublisher
+	logger    *slog.Logger
+	relayed   bool
+	registry  *models.MessageTypeRegistry
+	producer  string
+}
+
+// NewWriter creates a writer that publishes each message itself right after saving it
+func NewWriter(repo repository.MessageRepository, publisher messaging.SNSPublisher, logger *slog.Logger) *Writer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Writer{
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// SetRelayed leaves publishing to the Relay. Messages are checked against the registry's rules
+// for producer before they are saved, since the relay publishes whatever it finds.
+func (w *Writer) SetRelayed(registry *models.MessageTypeRegistry, producer string) {
+	w.relayed = true
+	w.registry = registry
+	w.producer = producer
+}
+
+// Send saves a new message as created and gets it published. When relayed, it returns once the
+// message is saved; otherwise it publishes the message and marks it queued. A message whose
+// publish fails stays created, where the relay or the stuck message sweeper finds it, and
+// ErrNotPublished is returned.
+func (w *Writer) Send(ctx context.Context, message *models.Message) error {
+	if w.registry != nil {
+		if err := w.registry.ValidatePublish(message, w.producer); err != nil {
+			return fmt.Errorf("refusing to send message %s: %w", message.ID, err)
+		}
+	}
+
+	message.Status = models.StatusCreated
+	if err := w.repo.SaveMessage(ctx, message); err != nil {
+		return fmt.Errorf("failed to save message: %w", err)
+	}
+	if w.relayed {
+		w.logger.DebugContext(ctx, "message saved for the outbox relay", slog.String("message_id", message.ID))
+		return nil
+	}
+	if err := publish(ctx, w.repo, w.publisher, message, w.logger); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotPublished, err)
+	}
+	return nil
+}
+
+// Relay publishes the messages saved to the outbox, from the messages table's stream
+type Relay struct {
+	repo      repository.MessageRepository
+	publisher messaging.SNSPublisher
+	logger    *slog.Logger
+}
+
+// NewRelay creates a relay that publishes saved messages with publisher
+func NewRelay(repo repository.MessageRepository, publisher messaging.SNSPublisher, logger *slog.Logger) *Relay {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// HandleEvent publishes every message inserted into the table that is still created. Records are
+// handled in stream order; on the first failure the rest of the batch is left for the retry,
+// which starts again from the failed record.
+func (r *Relay) HandleEvent(ctx context.Context, event events.DynamoDBEvent) (events.DynamoDBEventResponse, error) {
+	var response events.DynamoDBEventResponse
+	for _, record := range event.Records {
+		if err := r.relay(ctx, record); err != nil {
+			r.logger.ErrorContext(ctx, "failed to relay message",
+				slog.String("event_id", record.EventID),
+				slog.String("error", err.Error()),
+			)
+			response.BatchItemFailures = append(response.BatchItemFailures, events.DynamoDBBatchItemFailure{
+				ItemIdentifier: record.Change.SequenceNumber,
+			})
+			break
+		}
+	}
+	return response, nil
+}
+
+// relay publishes the message a stream record inserted, if it has not been published since
+func (r *Relay) relay(ctx context.Context, record events.DynamoDBEventRecord) error {
+	if record.EventName != string(events.DynamoDBOperationTypeInsert) {
+		return nil
+	}
+	key, ok := record.Change.Keys["id"]
+	if !ok || key.DataType() != events.DataTypeString {
+		return fmt.Errorf("stream record %s has no message id", record.EventID)
+	}
+
+	message, err := r.repo.GetMessage(ctx, key.String())
+	if err != nil {
+		return err
+	}
+	if message.Status != models.StatusCreated {
+		r.logger.DebugContext(ctx, "message already published", slog.String("message_id", message.ID))
+		return nil
+	}
+	if message.CorrelationID != "" {
+		ctx = logging.WithCorrelationID(ctx, message.CorrelationID)
+	}
+	return publish(ctx, r.repo, r.publisher, message, r.logger)
+}
+
+// publish publishes a created message and marks it queued. The consumer may have moved it on by
+// then, and only a message still created is marked.
+func publish(ctx context.Context, repo repository.MessageRepository, publisher messaging.SNSPublisher, message *models.Message, logger *slog.Logger) error {
+	if err := publisher.PublishMessage(ctx, message); err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	message.MarkQueued()
+	err := repo.TransitionStatus(ctx, message.ID, models.StatusCreated, models.StatusQueued)
+	if err != nil && !errors.Is(err, repository.ErrStatusChanged) {
+		// The message is published; the relay or sweeper may publish it again, which consumers tolerate
+		logger.ErrorContext(ctx, "failed to mark message queued",
+			slog.String("message_id", message.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+	return nil
+}
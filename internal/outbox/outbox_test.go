@@ -0,0 +1,167 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+type fakePublisher struct {
+	published []string
+	err       error
+}
+
+func (f *fakePublisher) PublishMessage(ctx context.Context, message *models.Message) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.published = append(f.published, message.ID)
+	return nil
+}
+
+func newNotification() *models.Message {
+	return models.NewMessage("webapi", nil, "1.0", models.StageDev, models.MessageTypeNotification, map[string]interface{}{"message": "hi"})
+}
+
+func insertRecord(id, sequence string) events.DynamoDBEventRecord {
+	return events.DynamoDBEventRecord{
+		EventID:   "event-" + sequence,
+		EventName: string(events.DynamoDBOperationTypeInsert),
+		Change: events.DynamoDBStreamRecord{
+			Keys:           map[string]events.DynamoDBAttributeValue{"id": events.NewStringAttribute(id)},
+			SequenceNumber: sequence,
+		},
+	}
+}
+
+func status(t *testing.T, repo repository.MessageRepository, id string) models.Status {
+	t.Helper()
+	message, err := repo.GetMessage(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetMessage(%s) error = %v", id, err)
+	}
+	return message.Status
+}
+
+func TestWriter_Send(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	publisher := &fakePublisher{}
+	writer := NewWriter(repo, publisher, nil)
+
+	message := newNotification()
+	if err := writer.Send(ctx, message); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(publisher.published) != 1 || status(t, repo, message.ID) != models.StatusQueued {
+		t.Errorf("published %v, status %v; want the message published and queued", publisher.published, status(t, repo, message.ID))
+	}
+
+	// A failed publish leaves the saved message created for the relay or sweeper
+	publisher.err = errors.New("sns unavailable")
+	failed := newNotification()
+	if err := writer.Send(ctx, failed); !errors.Is(err, ErrNotPublished) {
+		t.Errorf("Send() error = %v, want ErrNotPublished when the publish failed", err)
+	}
+	if got := status(t, repo, failed.ID); got != models.StatusCreated {
+		t.Errorf("status = %v, want %v", got, models.StatusCreated)
+	}
+}
+
+func TestWriter_SendRelayed(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	publisher := &fakePublisher{}
+	writer := NewWriter(repo, publisher, nil)
+	writer.SetRelayed(models.DefaultMessageTypeRegistry(), models.ComponentWebAPI)
+
+	message := newNotification()
+	message.Status = models.StatusCompleted
+	if err := writer.Send(ctx, message); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(publisher.published) != 0 {
+		t.Errorf("published %v, want publishing left to the relay", publisher.published)
+	}
+	if got := status(t, repo, message.ID); got != models.StatusCreated {
+		t.Errorf("status = %v, want %v", got, models.StatusCreated)
+	}
+
+	// The relay publishes whatever is saved, so messages the producer may not publish are refused
+	unknown := newNotification()
+	unknown.MessageType = "unregistered"
+	if err := writer.Send(ctx, unknown); err == nil {
+		t.Error("Send() error = nil for an unregistered message type")
+	}
+	if _, err := repo.GetMessage(ctx, unknown.ID); err == nil {
+		t.Error("refused message was saved")
+	}
+}
+
+func TestRelay_HandleEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	publisher := &fakePublisher{}
+	relay := NewRelay(repo, publisher, nil)
+
+	created := newNotification()
+	consumed := newNotification()
+	consumed.Status = models.StatusProcessing
+	for _, message := range []*models.Message{created, consumed} {
+		if err := repo.SaveMessage(ctx, message); err != nil {
+			t.Fatalf("SaveMessage() error = %v", err)
+		}
+	}
+	modify := insertRecord(created.ID, "3")
+	modify.EventName = string(events.DynamoDBOperationTypeModify)
+
+	response, err := relay.HandleEvent(ctx, events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{
+		insertRecord(created.ID, "1"),
+		insertRecord(consumed.ID, "2"),
+		modify,
+	}})
+	if err != nil || len(response.BatchItemFailures) != 0 {
+		t.Fatalf("HandleEvent() = %+v, %v; want no failures", response, err)
+	}
+	if len(publisher.published) != 1 || publisher.published[0] != created.ID {
+		t.Errorf("published %v, want only the created message", publisher.published)
+	}
+	if got := status(t, repo, created.ID); got != models.StatusQueued {
+		t.Errorf("created message status = %v, want %v", got, models.StatusQueued)
+	}
+	if got := status(t, repo, consumed.ID); got != models.StatusProcessing {
+		t.Errorf("consumed message status = %v, want %v", got, models.StatusProcessing)
+	}
+}
+
+func TestRelay_HandleEventFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	relay := NewRelay(repo, &fakePublisher{err: errors.New("sns unavailable")}, nil)
+
+	first, second := newNotification(), newNotification()
+	for _, message := range []*models.Message{first, second} {
+		if err := repo.SaveMessage(ctx, message); err != nil {
+			t.Fatalf("SaveMessage() error = %v", err)
+		}
+	}
+
+	// The batch stops at the first failure; the retry starts again from its sequence number
+	response, err := relay.HandleEvent(ctx, events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{
+		insertRecord(first.ID, "1"),
+		insertRecord(second.ID, "2"),
+	}})
+	if err != nil {
+		t.Fatalf("HandleEvent() error = %v", err)
+	}
+	if len(response.BatchItemFailures) != 1 || response.BatchItemFailures[0].ItemIdentifier != "1" {
+		t.Errorf("BatchItemFailures = %+v, want the first record", response.BatchItemFailures)
+	}
+	if got := status(t, repo, first.ID); got != models.StatusCreated {
+		t.Errorf("status = %v, want %v", got, models.StatusCreated)
+	}
+}
@@ -0,0 +1,179 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// sweepLimit bounds how many messages of each status one sweep looks at; the rest wait for the
+// next sweep
+const sweepLimit = 100
+
+// sweptStatuses are the statuses a message should only pass through
+var sweptStatuses = []models.Status{models.StatusCreated, models.StatusQueued, models.StatusProcessing}
+
+// SweepResult counts the stuck messages a sweep found, re-drove and failed
+type SweepResult struct {
+	Stuck    int `json:"stuck"`
+	Redriven int `json:"redriven"`
+	Failed   int `json:"failed"`
+}
+
+// Sweeper finds messages that have sat in created, queued or processing for longer than maxAge,
+// because a publish, a delivery or a consumer's status update was lost, and re-drives or fails
+// them so none stays in flight forever
+type Sweeper struct {
+	repo       repository.MessageRepository
+	publisher  messaging.SNSPublisher
+	recorder   *metrics.SweepRecorder
+	maxAge     time.Duration
+	maxRetries int
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+// NewSweeper creates a sweeper that re-drives a stuck message up to maxRetries times before
+// failing it
+func NewSweeper(repo repository.MessageRepository, publisher messaging.SNSPublisher, recorder *metrics.SweepRecorder, maxAge time.Duration, maxRetries int, logger *slog.Logger) *Sweeper {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Sweeper{
+		repo:       repo,
+		publisher:  publisher,
+		recorder:   recorder,
+		maxAge:     maxAge,
+		maxRetries: maxRetries,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Sweep handles the messages stuck in each status and records a metric for each. Messages that
+// were never published or never picked up are published again; messages stuck in processing, and
+// bookings that may have been delivered, are failed instead, so an admin can check them and retry.
+// One message failing does not stop the others.
+func (s *Sweeper) Sweep(ctx context.Context) (SweepResult, error) {
+	cutoff := s.now().Add(-s.maxAge)
+
+	var result SweepResult
+	var errs []error
+	for _, status := range sweptStatuses {
+		swept, err := s.sweepStatus(ctx, status, cutoff)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		s.recorder.Record(ctx, status, swept.Stuck, swept.Redriven, swept.Failed)
+
+		result.Stuck += swept.Stuck
+		result.Redriven += swept.Redriven
+		result.Failed += swept.Failed
+	}
+
+	s.logger.InfoContext(ctx, "stuck message sweep completed",
+		slog.Int("stuck", result.Stuck),
+		slog.Int("redriven", result.Redriven),
+		slog.Int("failed", result.Failed),
+	)
+	return result, errors.Join(errs...)
+}
+
+// sweepStatus handles the messages in status that have not changed since cutoff
+func (s *Sweeper) sweepStatus(ctx context.Context, status models.Status, cutoff time.Time) (SweepResult, error) {
+	var result SweepResult
+	messages, err := s.repo.ListMessagesByStatus(ctx, status, cutoff, sweepLimit)
+	if err != nil {
+		return result, fmt.Errorf("failed to list %s messages: %w", status, err)
+	}
+
+	var errs []error
+	for _, message := range messages {
+		// A message created long ago may have been re-driven recently
+		if message.UpdatedDate.After(cutoff) {
+			continue
+		}
+		result.Stuck++
+
+		msgCtx := ctx
+		if message.CorrelationID != "" {
+			msgCtx = logging.WithCorrelationID(ctx, message.CorrelationID)
+		}
+		redriven, err := s.sweep(msgCtx, message)
+		if errors.Is(err, repository.ErrStatusChanged) {
+			// The message moved on since it was listed
+			continue
+		}
+		if err != nil {
+			s.logger.ErrorContext(msgCtx, "failed to sweep stuck message",
+				slog.String("message_id", message.ID),
+				slog.String("status", status.String()),
+				slog.String("error", err.Error()),
+			)
+			errs = append(errs, fmt.Errorf("message %s: %w", message.ID, err))
+			continue
+		}
+		if redriven {
+			result.Redriven++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// sweep re-drives or fails one stuck message, reporting whether it was re-driven
+func (s *Sweeper) sweep(ctx context.Context, message *models.Message) (bool, error) {
+	from := message.Status
+	if reason := s.failReason(message); reason != "" {
+		s.logger.WarnContext(ctx, "failing stuck message",
+			slog.String("message_id", message.ID),
+			slog.String("status", from.String()),
+			slog.String("reason", reason),
+		)
+		return false, s.repo.FailMessage(ctx, message.ID, from, reason)
+	}
+
+	// Claim the message before publishing, so a consumer that picks up the original delivery in
+	// the meantime is not undone. A failed publish leaves it queued for the next sweep.
+	if err := s.repo.RequeueMessage(ctx, message.ID, from); err != nil {
+		return false, err
+	}
+	message.Requeue()
+	if err := s.publisher.PublishMessage(ctx, message); err != nil {
+		return false, fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "stuck message re-driven",
+		slog.String("message_id", message.ID),
+		slog.String("status", from.String()),
+		slog.Int("retry_count", message.RetryCount),
+	)
+	return true, nil
+}
+
+// failReason explains why a stuck message is failed rather than re-driven, or is empty when it
+// can safely be published again
+func (s *Sweeper) failReason(message *models.Message) string {
+	stuckFor := s.now().Sub(message.UpdatedDate).Round(time.Minute)
+	switch {
+	case message.Status == models.StatusProcessing:
+		return fmt.Sprintf("stuck in processing for %s; its consumer stopped or could not record the outcome", stuckFor)
+	case message.RetryCount >= s.maxRetries:
+		return fmt.Sprintf("stuck in %s for %s after %d retries", message.Status, stuckFor, message.RetryCount)
+	case message.Status == models.StatusQueued && message.IsBooking():
+		// A queued booking may have been delivered and reserved a tee time; the retry endpoint
+		// checks before booking again
+		return fmt.Sprintf("booking stuck in queued for %s; retry once it is known not to have reserved", stuckFor)
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+func TestSweeper_Sweep(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	publisher := &fakePublisher{}
+	sweeper := NewSweeper(repo, publisher, metrics.NewSweepRecorder(io.Discard, "dev", nil), 30*time.Minute, 3, nil)
+
+	stale := time.Now().Add(-time.Hour)
+	save := func(status models.Status, mutate func(*models.Message)) *models.Message {
+		t.Helper()
+		message := newNotification()
+		message.Status = status
+		message.CreatedDate, message.UpdatedDate = stale, stale
+		if mutate != nil {
+			mutate(message)
+		}
+		if err := repo.SaveMessage(ctx, message); err != nil {
+			t.Fatalf("SaveMessage() error = %v", err)
+		}
+		return message
+	}
+
+	unpublished := save(models.StatusCreated, nil)
+	undelivered := save(models.StatusQueued, nil)
+	processing := save(models.StatusProcessing, nil)
+	exhausted := save(models.StatusQueued, func(m *models.Message) { m.RetryCount = 3 })
+	booking := save(models.StatusQueued, func(m *models.Message) {
+		m.MessageType = models.MessageTypeWebAction
+		m.Payload = map[string]interface{}{"action": string(models.WebActionTypeGolf)}
+		m.Arguments = map[string]interface{}{"operation": "book_tee_time"}
+	})
+	recent := save(models.StatusQueued, func(m *models.Message) { m.UpdatedDate = time.Now() })
+	done := save(models.StatusCompleted, nil)
+
+	result, err := sweeper.Sweep(ctx)
+	if err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	if result != (SweepResult{Stuck: 5, Redriven: 2, Failed: 3}) {
+		t.Errorf("Sweep() = %+v, want 5 stuck, 2 re-driven and 3 failed", result)
+	}
+
+	for _, message := range []*models.Message{unpublished, undelivered} {
+		got, _ := repo.GetMessage(ctx, message.ID)
+		if got.Status != models.StatusQueued || got.RetryCount != 1 {
+			t.Errorf("re-driven message is %s with %d retries, want queued with 1", got.Status, got.RetryCount)
+		}
+	}
+	if len(publisher.published) != 2 {
+		t.Errorf("published %v, want the two re-driven messages", publisher.published)
+	}
+	for _, message := range []*models.Message{processing, exhausted, booking} {
+		got, _ := repo.GetMessage(ctx, message.ID)
+		if got.Status != models.StatusFailed || got.ErrorMessage == "" {
+			t.Errorf("message %s is %s (%q), want failed with a reason", message.ID, got.Status, got.ErrorMessage)
+		}
+	}
+	if got := status(t, repo, recent.ID); got != models.StatusQueued {
+		t.Errorf("recently updated message status = %v, want it left queued", got)
+	}
+	if got := status(t, repo, done.ID); got != models.StatusCompleted {
+		t.Errorf("completed message status = %v, want it left completed", got)
+	}
+}
+
+func TestSweeper_SweepPublishFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	sweeper := NewSweeper(repo, &fakePublisher{err: errors.New("sns unavailable")}, metrics.NewSweepRecorder(io.Discard, "dev", nil), 30*time.Minute, 3, nil)
+
+	message := newNotification()
+	message.CreatedDate = time.Now().Add(-time.Hour)
+	message.UpdatedDate = message.CreatedDate
+	if err := repo.SaveMessage(ctx, message); err != nil {
+		t.Fatalf("SaveMessage() error = %v", err)
+	}
+
+	// The claimed message stays queued with the retry counted, so later sweeps give up on it
+	result, err := sweeper.Sweep(ctx)
+	if err == nil {
+		t.Error("Sweep() error = nil when the publish failed")
+	}
+	if result.Stuck != 1 || result.Redriven != 0 {
+		t.Errorf("Sweep() = %+v, want one stuck message not re-driven", result)
+	}
+	got, _ := repo.GetMessage(ctx, message.ID)
+	if got.Status != models.StatusQueued || got.RetryCount != 1 {
+		t.Errorf("message is %s with %d retries, want queued with 1", got.Status, got.RetryCount)
+	}
+}
@@ -0,0 +1,274 @@
+// Package pagination implements the query-parameter convention shared by all list endpoints:
+//
+//	limit=N              page size (bounded by the endpoint's maximum)
+//	next_token=T         opaque token from the previous page's "next_token"
+//	sort=field,-other    sort fields in priority order; a leading "-" sorts descending
+//	filter[field]=value  exact-match filter; repeat for several fields
+//
+// Lists held in memory are paged by offset with Apply. Lists read from a table index are paged
+// by the index's cursor with CursorPage, so a token resumes the query where it stopped.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sortableTime is a fixed-width UTC layout, so timestamps compare correctly as strings
+const sortableTime = "2006-01-02T15:04:05.000000000Z"
+
+// Options describes what a list endpoint supports
+type Options struct {
+	// DefaultLimit is the page size when limit is not given
+	DefaultLimit int
+
+	// MaxLimit is the largest page size a client may request
+	MaxLimit int
+
+	// SortFields are the fields clients may sort by
+	SortFields []string
+
+	// DefaultSort applies when sort is not given, e.g. "-created_date"
+	DefaultSort string
+
+	// FilterFields are the fields clients may filter on
+	FilterFields []string
+
+	// DefaultFilters apply to the fields a client does not filter on, e.g. status=active
+	DefaultFilters map[string]string
+}
+
+// SortField is one sort key
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// Query is a parsed list request
+type Query struct {
+	Limit   int
+	Offset  int
+	Sort    []SortField
+	Filters map[string]string
+
+	// Cursor is where an index query resumes: the key of the last item the previous page read
+	Cursor map[string]string
+}
+
+// ParamError is a query parameter a list endpoint rejected
+type ParamError struct {
+	// Param is the query parameter, such as "limit" or "filter[status]"
+	Param string
+
+	// Message says what is wrong with it
+	Message string
+}
+
+func (e *ParamError) Error() string {
+	return e.Message
+}
+
+// Parse reads the list convention from query string parameters. Unknown filter or sort
+// fields, bad limits, and tampered tokens are errors so clients learn about typos.
+func Parse(params map[string]string, opts Options) (*Query, error) {
+	q := &Query{
+		Limit:   opts.DefaultLimit,
+		Filters: make(map[string]string),
+	}
+
+	if raw := params["limit"]; raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > opts.MaxLimit {
+			return nil, &ParamError{Param: "limit", Message: fmt.Sprintf("limit must be between 1 and %d", opts.MaxLimit)}
+		}
+		q.Limit = limit
+	}
+
+	rawSort := params["sort"]
+	if rawSort == "" {
+		rawSort = opts.DefaultSort
+	}
+	for _, part := range strings.Split(rawSort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Descending: strings.HasPrefix(part, "-")}
+		if !slices.Contains(opts.SortFields, field.Field) {
+			return nil, &ParamError{Param: "sort", Message: fmt.Sprintf("cannot sort by %q (sortable fields: %s)", field.Field, strings.Join(opts.SortFields, ", "))}
+		}
+		q.Sort = append(q.Sort, field)
+	}
+
+	for key, value := range params {
+		if !strings.HasPrefix(key, "filter[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		field := key[len("filter[") : len(key)-1]
+		if !slices.Contains(opts.FilterFields, field) {
+			return nil, &ParamError{Param: key, Message: fmt.Sprintf("cannot filter by %q (filterable fields: %s)", field, strings.Join(opts.FilterFields, ", "))}
+		}
+		q.Filters[field] = value
+	}
+	// Defaults go in before the token is checked, which was issued for the defaulted query
+	for field, value := range opts.DefaultFilters {
+		if _, ok := q.Filters[field]; !ok {
+			q.Filters[field] = value
+		}
+	}
+
+	if raw := params["next_token"]; raw != "" {
+		token, err := decodeToken(raw, q.fingerprint())
+		if err != nil {
+			return nil, &ParamError{Param: "next_token", Message: err.Error()}
+		}
+		q.Offset = token.Offset
+		q.Cursor = token.Cursor
+	}
+
+	return q, nil
+}
+
+// Filter returns the filter value for a field, if one was given
+func (q *Query) Filter(field string) (string, bool) {
+	value, ok := q.Filters[field]
+	return value, ok
+}
+
+// Descending reports whether the list is sorted in descending order of its first sort field
+func (q *Query) Descending() bool {
+	return len(q.Sort) > 0 && q.Sort[0].Descending
+}
+
+// Page is one page of list results
+type Page[T any] struct {
+	Items     []T
+	NextToken string
+}
+
+// Time formats a timestamp for use as a sort field value
+func Time(t time.Time) string {
+	return t.UTC().Format(sortableTime)
+}
+
+// Apply filters, sorts, and pages items in memory. field returns the string value of
+// a sortable or filterable field; timestamps should go through Time so they sort correctly.
+func Apply[T any](items []T, q *Query, field func(item T, name string) string) Page[T] {
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		if matches(q, item, field) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	if len(q.Sort) > 0 {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			for _, s := range q.Sort {
+				a, b := field(filtered[i], s.Field), field(filtered[j], s.Field)
+				if a == b {
+					continue
+				}
+				if s.Descending {
+					return a > b
+				}
+				return a < b
+			}
+			return false
+		})
+	}
+
+	start := min(q.Offset, len(filtered))
+	end := min(start+q.Limit, len(filtered))
+	page := Page[T]{Items: filtered[start:end]}
+	if end < len(filtered) {
+		page.NextToken = encodeToken(pageToken{Offset: end}, q.fingerprint())
+	}
+	return page
+}
+
+// CursorPage is a page read from a table index, which filtered and sorted it. next is the key
+// the index query stopped at, or nil when it reached the end.
+func CursorPage[T any](items []T, q *Query, next map[string]string) Page[T] {
+	page := Page[T]{Items: items}
+	if next != nil {
+		page.NextToken = encodeToken(pageToken{Cursor: next}, q.fingerprint())
+	}
+	return page
+}
+
+// Response builds the standard list response body, keeping the endpoint's collection key
+func (p Page[T]) Response(key string) map[string]interface{} {
+	response := map[string]interface{}{
+		key:     p.Items,
+		"count": len(p.Items),
+	}
+	if p.NextToken != "" {
+		response["next_token"] = p.NextToken
+	}
+	return response
+}
+
+// matches reports whether the item satisfies every filter
+func matches[T any](q *Query, item T, field func(item T, name string) string) bool {
+	for name, want := range q.Filters {
+		if field(item, name) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// fingerprint identifies the filters and sort a token was issued for, so a token
+// cannot be replayed against a differently shaped query
+func (q *Query) fingerprint() string {
+	parts := make([]string, 0, len(q.Filters)+len(q.Sort))
+	for name, value := range q.Filters {
+		parts = append(parts, "f:"+name+"="+value)
+	}
+	sort.Strings(parts)
+	for _, s := range q.Sort {
+		if s.Descending {
+			parts = append(parts, "s:-"+s.Field)
+		} else {
+			parts = append(parts, "s:"+s.Field)
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// pageToken is the decoded form of next_token: an offset into an in-memory list, or the cursor
+// of an index query
+type pageToken struct {
+	Offset int               `json:"o,omitempty"`
+	Cursor map[string]string `json:"k,omitempty"`
+	Query  string            `json:"q"`
+}
+
+// encodeToken builds an opaque next_token
+func encodeToken(token pageToken, fingerprint string) string {
+	token.Query = fingerprint
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeToken validates a next_token against the current query
+func decodeToken(raw, fingerprint string) (pageToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return pageToken{}, fmt.Errorf("invalid next_token")
+	}
+	var token pageToken
+	if err := json.Unmarshal(data, &token); err != nil || token.Offset < 0 {
+		return pageToken{}, fmt.Errorf("invalid next_token")
+	}
+	if token.Query != fingerprint {
+		return pageToken{}, fmt.Errorf("next_token does not match the query's sort and filters")
+	}
+	return token, nil
+}
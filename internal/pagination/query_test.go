@@ -0,0 +1,198 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+)
+
+type item struct {
+	name   string
+	status string
+}
+
+func itemField(i item, field string) string {
+	switch field {
+	case "name":
+		return i.name
+	case "status":
+		return i.status
+	}
+	return ""
+}
+
+var testOptions = Options{
+	DefaultLimit: 2,
+	MaxLimit:     10,
+	SortFields:   []string{"name", "status"},
+	DefaultSort:  "name",
+	FilterFields: []string{"status"},
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]string
+		wantParam string
+	}{
+		{"limit not a number", map[string]string{"limit": "abc"}, "limit"},
+		{"limit too large", map[string]string{"limit": "11"}, "limit"},
+		{"limit zero", map[string]string{"limit": "0"}, "limit"},
+		{"unknown sort field", map[string]string{"sort": "-size"}, "sort"},
+		{"unknown filter field", map[string]string{"filter[owner]": "me"}, "filter[owner]"},
+		{"garbage token", map[string]string{"next_token": "!!!"}, "next_token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.params, testOptions)
+			if err == nil {
+				t.Fatalf("Parse(%v) error = nil, want error", tt.params)
+			}
+			var paramErr *ParamError
+			if !errors.As(err, &paramErr) || paramErr.Param != tt.wantParam {
+				t.Errorf("Parse(%v) error = %v, want a ParamError for %q", tt.params, err, tt.wantParam)
+			}
+		})
+	}
+}
+
+func TestParse_Defaults(t *testing.T) {
+	q, err := Parse(map[string]string{}, testOptions)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if q.Limit != 2 {
+		t.Errorf("Limit = %d, want 2", q.Limit)
+	}
+	if len(q.Sort) != 1 || q.Sort[0] != (SortField{Field: "name"}) {
+		t.Errorf("Sort = %v, want [name]", q.Sort)
+	}
+}
+
+func TestApply_FiltersSortsAndPages(t *testing.T) {
+	items := []item{
+		{"delta", "active"},
+		{"alpha", "active"},
+		{"charlie", "paused"},
+		{"bravo", "active"},
+	}
+	params := map[string]string{"sort": "-name", "filter[status]": "active"}
+
+	var names []string
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not terminate")
+		}
+		q, err := Parse(params, testOptions)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		page := Apply(items, q, itemField)
+		for _, i := range page.Items {
+			names = append(names, i.name)
+		}
+		if page.NextToken == "" {
+			break
+		}
+		params["next_token"] = page.NextToken
+	}
+
+	want := []string{"delta", "bravo", "alpha"}
+	if len(names) != len(want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("names = %v, want %v", names, want)
+			break
+		}
+	}
+}
+
+func TestParse_TokenBoundToQuery(t *testing.T) {
+	q, _ := Parse(map[string]string{"filter[status]": "active"}, testOptions)
+	page := Apply([]item{{"a", "active"}, {"b", "active"}, {"c", "active"}}, q, itemField)
+	if page.NextToken == "" {
+		t.Fatal("NextToken is empty, want a token for the second page")
+	}
+
+	_, err := Parse(map[string]string{"filter[status]": "paused", "next_token": page.NextToken}, testOptions)
+	if err == nil {
+		t.Error("Parse() with a token from a different filter succeeded, want error")
+	}
+}
+
+func TestCursorPage_TokenCarriesCursor(t *testing.T) {
+	q, _ := Parse(map[string]string{"filter[status]": "active", "sort": "-name"}, testOptions)
+	if !q.Descending() {
+		t.Error("Descending() = false for sort=-name")
+	}
+	cursor := map[string]string{"id": "b", "name": "bravo"}
+	page := CursorPage([]item{{"a", "active"}}, q, cursor)
+	if page.NextToken == "" {
+		t.Fatal("NextToken is empty, want a token carrying the cursor")
+	}
+
+	next, err := Parse(map[string]string{"filter[status]": "active", "sort": "-name", "next_token": page.NextToken}, testOptions)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if next.Cursor["id"] != "b" || next.Cursor["name"] != "bravo" || next.Offset != 0 {
+		t.Errorf("Cursor = %v Offset = %d, want the cursor back", next.Cursor, next.Offset)
+	}
+	if _, err := Parse(map[string]string{"filter[status]": "paused", "next_token": page.NextToken}, testOptions); err == nil {
+		t.Error("Parse() with a cursor from a different filter succeeded, want error")
+	}
+	if page := CursorPage([]item{{"a", "active"}}, q, nil); page.NextToken != "" {
+		t.Error("CursorPage() without a cursor has a NextToken")
+	}
+}
+
+func TestParse_DefaultFiltersPage(t *testing.T) {
+	opts := testOptions
+	opts.DefaultFilters = map[string]string{"status": "active"}
+	items := []item{{"a", "active"}, {"b", "paused"}, {"c", "active"}, {"d", "active"}}
+
+	// The second page of a defaulted query is accepted and stays filtered
+	params := map[string]string{}
+	var names []string
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not terminate")
+		}
+		q, err := Parse(params, opts)
+		if err != nil {
+			t.Fatalf("Parse() page %d error = %v", pages+1, err)
+		}
+		page := Apply(items, q, itemField)
+		for _, i := range page.Items {
+			names = append(names, i.name)
+		}
+		if page.NextToken == "" {
+			break
+		}
+		params["next_token"] = page.NextToken
+	}
+	if len(names) != 3 || names[0] != "a" || names[1] != "c" || names[2] != "d" {
+		t.Errorf("names = %v, want [a c d]", names)
+	}
+
+	// A filter the client gives replaces the default
+	q, err := Parse(map[string]string{"filter[status]": "paused"}, opts)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if value, _ := q.Filter("status"); value != "paused" {
+		t.Errorf("status filter = %q, want paused", value)
+	}
+}
+
+func TestPage_Response(t *testing.T) {
+	resp := Page[int]{Items: []int{1, 2}, NextToken: "abc"}.Response("numbers")
+	if resp["count"] != 2 || resp["next_token"] != "abc" {
+		t.Errorf("Response() = %v", resp)
+	}
+	if _, ok := (Page[int]{}).Response("numbers")["next_token"]; ok {
+		t.Error("Response() includes next_token on the last page")
+	}
+}
@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Guardrails limit what an agent may do during a single run. They are checked before each tool
+// call, outside the LLM conversation. Zero values disable a limit.
+type Guardrails struct {
+	// MaxBookingsPerRun caps the bookings (including held tee times) made in one run
+	MaxBookingsPerRun int `json:"max_bookings_per_run,omitempty"`
+
+	// MaxSpendPerRun caps the total green fees, in dollars, committed in one run
+	MaxSpendPerRun float64 `json:"max_spend_per_run,omitempty"`
+
+	// MaxToolCallsPerRun caps the tool calls made in one run
+	MaxToolCallsPerRun int `json:"max_tool_calls_per_run,omitempty"`
+
+	// ConfirmationRequired lists tools that must not act without the golfer's confirmation
+	ConfirmationRequired []string `json:"confirmation_required,omitempty"`
+}
+
+// Validate checks if the guardrail configuration is valid
+func (g *Guardrails) Validate() error {
+	if g == nil {
+		return nil
+	}
+	if g.MaxBookingsPerRun < 0 {
+		return fmt.Errorf("max_bookings_per_run must not be negative")
+	}
+	if g.MaxSpendPerRun < 0 {
+		return fmt.Errorf("max_spend_per_run must not be negative")
+	}
+	if g.MaxToolCallsPerRun < 0 {
+		return fmt.Errorf("max_tool_calls_per_run must not be negative")
+	}
+	for _, name := range g.ConfirmationRequired {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("confirmation_required must not contain empty tool names")
+		}
+	}
+	return nil
+}
+
+// RequiresConfirmation reports whether the named tool needs the golfer's confirmation
+func (g *Guardrails) RequiresConfirmation(tool string) bool {
+	return g != nil && slices.Contains(g.ConfirmationRequired, tool)
+}
+
+// Merge returns guardrails at least as strict as both g and other: the lower of each enabled
+// limit and every tool either requires confirmation for
+func (g *Guardrails) Merge(other *Guardrails) *Guardrails {
+	out := Guardrails{}
+	for _, src := range []*Guardrails{g, other} {
+		if src == nil {
+			continue
+		}
+		out.MaxBookingsPerRun = lowerLimit(out.MaxBookingsPerRun, src.MaxBookingsPerRun)
+		out.MaxToolCallsPerRun = lowerLimit(out.MaxToolCallsPerRun, src.MaxToolCallsPerRun)
+		if src.MaxSpendPerRun > 0 && (out.MaxSpendPerRun <= 0 || src.MaxSpendPerRun < out.MaxSpendPerRun) {
+			out.MaxSpendPerRun = src.MaxSpendPerRun
+		}
+		for _, name := range src.ConfirmationRequired {
+			if !slices.Contains(out.ConfirmationRequired, name) {
+				out.ConfirmationRequired = append(out.ConfirmationRequired, name)
+			}
+		}
+	}
+	return &out
+}
+
+// lowerLimit returns the lower of two limits where zero means unlimited
+func lowerLimit(a, b int) int {
+	if a <= 0 {
+		return b
+	}
+	if b > 0 && b < a {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,47 @@
+package policy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGuardrails_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		guardrails *Guardrails
+		wantErr    bool
+	}{
+		{"nil", nil, false},
+		{"valid", &Guardrails{MaxBookingsPerRun: 1, MaxSpendPerRun: 200, ConfirmationRequired: []string{"golf_book_tee_time"}}, false},
+		{"negative bookings", &Guardrails{MaxBookingsPerRun: -1}, true},
+		{"negative spend", &Guardrails{MaxSpendPerRun: -5}, true},
+		{"negative tool calls", &Guardrails{MaxToolCallsPerRun: -1}, true},
+		{"empty tool name", &Guardrails{ConfirmationRequired: []string{" "}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.guardrails.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGuardrails_Merge(t *testing.T) {
+	defaults := &Guardrails{MaxBookingsPerRun: 2, MaxSpendPerRun: 300, ConfirmationRequired: []string{"golf_book_tee_time"}}
+	event := &Guardrails{MaxBookingsPerRun: 1, MaxToolCallsPerRun: 10, MaxSpendPerRun: 500, ConfirmationRequired: []string{"golf_cancel_reservation", "golf_book_tee_time"}}
+
+	got := defaults.Merge(event)
+	if got.MaxBookingsPerRun != 1 || got.MaxSpendPerRun != 300 || got.MaxToolCallsPerRun != 10 {
+		t.Errorf("Merge() = %+v, want the stricter of each limit", got)
+	}
+	if want := []string{"golf_book_tee_time", "golf_cancel_reservation"}; !slices.Equal(got.ConfirmationRequired, want) {
+		t.Errorf("ConfirmationRequired = %v, want %v", got.ConfirmationRequired, want)
+	}
+
+	var none *Guardrails
+	if merged := none.Merge(nil); merged.MaxBookingsPerRun != 0 || merged.RequiresConfirmation("golf_book_tee_time") {
+		t.Errorf("nil.Merge(nil) = %+v, want no limits", merged)
+	}
+}
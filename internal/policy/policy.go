@@ -0,0 +1,203 @@
+// Package policy implements deterministic booking rules (budget caps, blackout dates,
+// weather thresholds, daylight) that are enforced outside the LLM conversation.
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config holds the hard booking constraints. Zero values disable a rule.
+type Config struct {
+	// MaxPrice caps the 18-hole green fee per player in dollars
+	MaxPrice float64 `json:"max_price,omitempty"`
+
+	// BlackoutDates are local dates (YYYY-MM-DD) that must never be booked
+	BlackoutDates []string `json:"blackout_dates,omitempty"`
+
+	// MaxPrecipChance blocks dates whose forecast precipitation chance is at or above this percent
+	MaxPrecipChance int `json:"max_precip_chance,omitempty"`
+
+	// MinDaylightHours is the daylight required between the tee time and sunset
+	MinDaylightHours float64 `json:"min_daylight_hours,omitempty"`
+}
+
+// Validate checks if the policy configuration is valid
+func (c *Config) Validate() error {
+	if c.MaxPrice < 0 {
+		return fmt.Errorf("max_price must not be negative")
+	}
+	for _, d := range c.BlackoutDates {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return fmt.Errorf("blackout date must be in YYYY-MM-DD format: %s", d)
+		}
+	}
+	if c.MaxPrecipChance < 0 || c.MaxPrecipChance > 100 {
+		return fmt.Errorf("max_precip_chance must be between 0 and 100")
+	}
+	if c.MinDaylightHours < 0 || c.MinDaylightHours > 12 {
+		return fmt.Errorf("min_daylight_hours must be between 0 and 12")
+	}
+	return nil
+}
+
+// IsEmpty reports whether no rule is enabled
+func (c *Config) IsEmpty() bool {
+	return c == nil || (c.MaxPrice <= 0 && len(c.BlackoutDates) == 0 && c.MaxPrecipChance <= 0 && c.MinDaylightHours <= 0)
+}
+
+// WithMaxPrice returns a copy of the config whose price cap is the lower of its own and maxPrice
+func (c *Config) WithMaxPrice(maxPrice float64) *Config {
+	out := Config{}
+	if c != nil {
+		out = *c
+	}
+	if maxPrice > 0 && (out.MaxPrice <= 0 || maxPrice < out.MaxPrice) {
+		out.MaxPrice = maxPrice
+	}
+	return &out
+}
+
+// Coordinates is a course location used for sunrise/sunset
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Candidate is a tee time (or just a date) being considered for booking.
+// Unknown fields are left zero/nil and the rules that need them are skipped.
+type Candidate struct {
+	// TeeTime is the local tee time; only the date is used when HasTime is false
+	TeeTime time.Time
+
+	// HasTime is true when TeeTime includes a time of day
+	HasTime bool
+
+	// GreenFee is the 18-hole green fee per player (0 = unknown)
+	GreenFee float64
+
+	// PrecipChance is the forecast precipitation chance in percent (nil = unknown)
+	PrecipChance *int
+
+	// Location is the course location (nil = unknown)
+	Location *Coordinates
+}
+
+// Violation is a single broken rule
+type Violation struct {
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+// Decision is the outcome of evaluating a candidate against all rules
+type Decision struct {
+	Allowed    bool        `json:"allowed"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Err returns nil when the candidate is allowed, otherwise an error listing the violations
+func (d Decision) Err() error {
+	if d.Allowed {
+		return nil
+	}
+	reasons := make([]string, 0, len(d.Violations))
+	for _, v := range d.Violations {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", v.Rule, v.Reason))
+	}
+	return fmt.Errorf("booking policy violated (%s)", strings.Join(reasons, "; "))
+}
+
+// Rule is a single deterministic booking constraint
+type Rule interface {
+	// Name returns the rule identifier used in violations
+	Name() string
+
+	// Evaluate returns a violation, or nil if the candidate passes or the rule cannot be applied
+	Evaluate(c Candidate) *Violation
+}
+
+// Engine evaluates candidates against a set of rules
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine creates an engine with the rules enabled by the configuration
+func NewEngine(cfg *Config) *Engine {
+	var rules []Rule
+	if cfg != nil {
+		if cfg.MaxPrice > 0 {
+			rules = append(rules, BudgetRule{MaxPrice: cfg.MaxPrice})
+		}
+		if len(cfg.BlackoutDates) > 0 {
+			rules = append(rules, NewBlackoutRule(cfg.BlackoutDates))
+		}
+		if cfg.MaxPrecipChance > 0 {
+			rules = append(rules, WeatherRule{MaxPrecipChance: cfg.MaxPrecipChance})
+		}
+		if cfg.MinDaylightHours > 0 {
+			rules = append(rules, DaylightRule{MinHours: cfg.MinDaylightHours})
+		}
+	}
+	return &Engine{rules: rules}
+}
+
+// NewEngineWithRules creates an engine with explicit rules
+func NewEngineWithRules(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// Evaluate checks the candidate against every rule
+func (e *Engine) Evaluate(c Candidate) Decision {
+	decision := Decision{Allowed: true}
+	for _, result := range e.Check(c) {
+		if !result.Passed {
+			decision.Allowed = false
+			decision.Violations = append(decision.Violations, Violation{Rule: result.Rule, Reason: result.Reason})
+		}
+	}
+	return decision
+}
+
+// RuleResult is the outcome of a single rule for a candidate
+type RuleResult struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Check evaluates the candidate and returns one result per enabled rule, in rule order
+func (e *Engine) Check(c Candidate) []RuleResult {
+	results := make([]RuleResult, 0, len(e.rules))
+	for _, rule := range e.rules {
+		result := RuleResult{Rule: rule.Name(), Passed: true}
+		if v := rule.Evaluate(c); v != nil {
+			result.Passed = false
+			result.Reason = v.Reason
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Describe returns the enabled rules as prompt-friendly text
+func (c *Config) Describe() string {
+	if c.IsEmpty() {
+		return "No hard booking rules are configured."
+	}
+	var sb strings.Builder
+	sb.WriteString("The following rules are enforced automatically; bookings that break them will be rejected:\n")
+	if c.MaxPrice > 0 {
+		sb.WriteString(fmt.Sprintf("- 18-hole green fee must be $%.2f or less per player\n", c.MaxPrice))
+	}
+	if len(c.BlackoutDates) > 0 {
+		sb.WriteString(fmt.Sprintf("- Never book on: %s\n", strings.Join(c.BlackoutDates, ", ")))
+	}
+	if c.MaxPrecipChance > 0 {
+		sb.WriteString(fmt.Sprintf("- Skip dates with a %d%% or higher chance of precipitation\n", c.MaxPrecipChance))
+	}
+	if c.MinDaylightHours > 0 {
+		sb.WriteString(fmt.Sprintf("- Tee times must leave at least %.1f hours of daylight before sunset\n", c.MinDaylightHours))
+	}
+	return sb.String()
+}
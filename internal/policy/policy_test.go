@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"empty", Config{}, false},
+		{"valid", Config{MaxPrice: 40, BlackoutDates: []string{"2030-07-04"}, MaxPrecipChance: 50, MinDaylightHours: 4}, false},
+		{"negative price", Config{MaxPrice: -1}, true},
+		{"bad blackout date", Config{BlackoutDates: []string{"07/04/2030"}}, true},
+		{"precip over 100", Config{MaxPrecipChance: 101}, true},
+		{"daylight over 12", Config{MinDaylightHours: 13}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_WithMaxPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *Config
+		maxPrice float64
+		want     float64
+	}{
+		{"nil config", nil, 40, 40},
+		{"no override", &Config{MaxPrice: 50}, 0, 50},
+		{"lower override wins", &Config{MaxPrice: 50}, 40, 40},
+		{"higher override ignored", &Config{MaxPrice: 50}, 60, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.WithMaxPrice(tt.maxPrice).MaxPrice; got != tt.want {
+				t.Errorf("WithMaxPrice() MaxPrice = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	cfg := &Config{MaxPrice: 50}
+	cfg.WithMaxPrice(40)
+	if cfg.MaxPrice != 50 {
+		t.Errorf("WithMaxPrice() modified the receiver")
+	}
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	freeport := &Coordinates{Latitude: 40.6817, Longitude: -79.6592}
+	precip := func(p int) *int { return &p }
+
+	engine := NewEngine(&Config{
+		MaxPrice:         40,
+		BlackoutDates:    []string{"2030-07-04"},
+		MaxPrecipChance:  60,
+		MinDaylightHours: 4,
+	})
+
+	tests := []struct {
+		name      string
+		candidate Candidate
+		wantRules []string
+	}{
+		{
+			name:      "allowed",
+			candidate: Candidate{TeeTime: time.Date(2030, 6, 21, 9, 0, 0, 0, loc), HasTime: true, GreenFee: 35, PrecipChance: precip(20), Location: freeport},
+		},
+		{
+			name:      "over budget",
+			candidate: Candidate{TeeTime: time.Date(2030, 6, 21, 9, 0, 0, 0, loc), HasTime: true, GreenFee: 45},
+			wantRules: []string{"budget"},
+		},
+		{
+			name:      "blackout date only",
+			candidate: Candidate{TeeTime: time.Date(2030, 7, 4, 0, 0, 0, 0, loc)},
+			wantRules: []string{"blackout"},
+		},
+		{
+			name:      "rainy",
+			candidate: Candidate{TeeTime: time.Date(2030, 6, 21, 9, 0, 0, 0, loc), PrecipChance: precip(60)},
+			wantRules: []string{"weather"},
+		},
+		{
+			name:      "before sunrise",
+			candidate: Candidate{TeeTime: time.Date(2030, 6, 21, 5, 0, 0, 0, loc), HasTime: true, Location: freeport},
+			wantRules: []string{"daylight"},
+		},
+		{
+			name:      "too close to sunset",
+			candidate: Candidate{TeeTime: time.Date(2030, 12, 21, 14, 0, 0, 0, loc), HasTime: true, Location: freeport},
+			wantRules: []string{"daylight"},
+		},
+		{
+			name:      "unknown inputs are skipped",
+			candidate: Candidate{TeeTime: time.Date(2030, 12, 21, 14, 0, 0, 0, loc)},
+		},
+		{
+			name:      "multiple violations",
+			candidate: Candidate{TeeTime: time.Date(2030, 7, 4, 9, 0, 0, 0, loc), GreenFee: 80},
+			wantRules: []string{"budget", "blackout"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.Evaluate(tt.candidate)
+			if decision.Allowed != (len(tt.wantRules) == 0) {
+				t.Fatalf("Allowed = %v, violations = %+v", decision.Allowed, decision.Violations)
+			}
+			if len(decision.Violations) != len(tt.wantRules) {
+				t.Fatalf("violations = %+v, want rules %v", decision.Violations, tt.wantRules)
+			}
+			for i, v := range decision.Violations {
+				if v.Rule != tt.wantRules[i] {
+					t.Errorf("violation[%d].Rule = %q, want %q", i, v.Rule, tt.wantRules[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewEngine_NoRules(t *testing.T) {
+	for _, cfg := range []*Config{nil, {}} {
+		decision := NewEngine(cfg).Evaluate(Candidate{GreenFee: 500})
+		if !decision.Allowed || decision.Err() != nil {
+			t.Errorf("NewEngine(%+v).Evaluate() = %+v, want allowed", cfg, decision)
+		}
+	}
+}
+
+func TestDecision_Err(t *testing.T) {
+	decision := Decision{Violations: []Violation{
+		{Rule: "budget", Reason: "too expensive"},
+		{Rule: "blackout", Reason: "holiday"},
+	}}
+
+	err := decision.Err()
+	if err == nil {
+		t.Fatal("Err() = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "budget: too expensive") || !strings.Contains(err.Error(), "blackout: holiday") {
+		t.Errorf("Err() = %q, missing violations", err)
+	}
+}
+
+func TestConfig_Describe(t *testing.T) {
+	var empty *Config
+	if got := empty.Describe(); !strings.Contains(got, "No hard booking rules") {
+		t.Errorf("nil Describe() = %q", got)
+	}
+
+	got := (&Config{MaxPrice: 40, BlackoutDates: []string{"2030-07-04"}}).Describe()
+	if !strings.Contains(got, "$40.00") || !strings.Contains(got, "2030-07-04") {
+		t.Errorf("Describe() = %q, missing price or blackout date", got)
+	}
+}
@@ -0,0 +1,120 @@
+package policy
+
+import (
+	"fmt"
+	"time"
+)
+
+// BudgetRule rejects tee times whose green fee is above the cap
+type BudgetRule struct {
+	MaxPrice float64
+}
+
+// Name returns the rule identifier
+func (r BudgetRule) Name() string { return "budget" }
+
+// Evaluate checks the candidate's green fee
+func (r BudgetRule) Evaluate(c Candidate) *Violation {
+	if r.MaxPrice <= 0 || c.GreenFee <= 0 {
+		return nil
+	}
+	if c.GreenFee > r.MaxPrice {
+		return &Violation{
+			Rule:   r.Name(),
+			Reason: fmt.Sprintf("green fee $%.2f exceeds cap $%.2f", c.GreenFee, r.MaxPrice),
+		}
+	}
+	return nil
+}
+
+// BlackoutRule rejects tee times on blacked-out dates
+type BlackoutRule struct {
+	dates map[string]bool
+}
+
+// NewBlackoutRule creates a blackout rule for the given YYYY-MM-DD dates
+func NewBlackoutRule(dates []string) BlackoutRule {
+	set := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		set[d] = true
+	}
+	return BlackoutRule{dates: set}
+}
+
+// Name returns the rule identifier
+func (r BlackoutRule) Name() string { return "blackout" }
+
+// Evaluate checks the candidate's date
+func (r BlackoutRule) Evaluate(c Candidate) *Violation {
+	if c.TeeTime.IsZero() {
+		return nil
+	}
+	date := c.TeeTime.Format("2006-01-02")
+	if r.dates[date] {
+		return &Violation{
+			Rule:   r.Name(),
+			Reason: fmt.Sprintf("%s is a blackout date", date),
+		}
+	}
+	return nil
+}
+
+// WeatherRule rejects dates whose forecast precipitation chance is too high
+type WeatherRule struct {
+	MaxPrecipChance int
+}
+
+// Name returns the rule identifier
+func (r WeatherRule) Name() string { return "weather" }
+
+// Evaluate checks the candidate's forecast precipitation chance
+func (r WeatherRule) Evaluate(c Candidate) *Violation {
+	if r.MaxPrecipChance <= 0 || c.PrecipChance == nil {
+		return nil
+	}
+	if *c.PrecipChance >= r.MaxPrecipChance {
+		return &Violation{
+			Rule:   r.Name(),
+			Reason: fmt.Sprintf("%d%% chance of precipitation (limit %d%%)", *c.PrecipChance, r.MaxPrecipChance),
+		}
+	}
+	return nil
+}
+
+// DaylightRule rejects tee times that start before sunrise or too close to sunset
+type DaylightRule struct {
+	MinHours float64
+}
+
+// Name returns the rule identifier
+func (r DaylightRule) Name() string { return "daylight" }
+
+// Evaluate checks the candidate's tee time against sunrise and sunset at the course
+func (r DaylightRule) Evaluate(c Candidate) *Violation {
+	if r.MinHours <= 0 || !c.HasTime || c.Location == nil {
+		return nil
+	}
+
+	sunrise, sunset, ok := SunTimes(c.TeeTime, c.Location.Latitude, c.Location.Longitude)
+	if !ok {
+		return nil
+	}
+
+	if c.TeeTime.Before(sunrise) {
+		return &Violation{
+			Rule:   r.Name(),
+			Reason: fmt.Sprintf("tee time is before sunrise (%s)", sunrise.In(c.TeeTime.Location()).Format("3:04 PM")),
+		}
+	}
+
+	available := sunset.Sub(c.TeeTime)
+	required := time.Duration(r.MinHours * float64(time.Hour))
+	if available < required {
+		return &Violation{
+			Rule: r.Name(),
+			Reason: fmt.Sprintf("only %.1f hours of daylight before sunset (%s), need %.1f",
+				available.Hours(), sunset.In(c.TeeTime.Location()).Format("3:04 PM"), r.MinHours),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package policy
+
+import (
+	"math"
+	"time"
+)
+
+// SunTimes returns sunrise and sunset (UTC) on the local calendar date of day at the
+// given latitude and longitude (degrees, east positive), using the NOAA sunrise equation.
+// ok is false during polar day or night.
+func SunTimes(day time.Time, latitude, longitude float64) (sunrise, sunset time.Time, ok bool) {
+	const j2000 = 2451545.0
+	rad := math.Pi / 180
+
+	// Days since J2000 at local noon of the requested date
+	y, m, d := day.Date()
+	noon := time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+	n := math.Round(julianDate(noon) - j2000)
+
+	meanSolarNoon := n - longitude/360
+	anomaly := math.Mod(357.5291+0.98560028*meanSolarNoon, 360)
+	center := 1.9148*math.Sin(anomaly*rad) + 0.0200*math.Sin(2*anomaly*rad) + 0.0003*math.Sin(3*anomaly*rad)
+	eclipticLongitude := math.Mod(anomaly+center+180+102.9372, 360)
+	transit := j2000 + meanSolarNoon + 0.0053*math.Sin(anomaly*rad) - 0.0069*math.Sin(2*eclipticLongitude*rad)
+
+	declination := math.Asin(math.Sin(eclipticLongitude*rad) * math.Sin(23.4397*rad))
+	cosHourAngle := (math.Sin(-0.833*rad) - math.Sin(latitude*rad)*math.Sin(declination)) /
+		(math.Cos(latitude*rad) * math.Cos(declination))
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	hourAngle := math.Acos(cosHourAngle) / rad
+
+	return fromJulianDate(transit - hourAngle/360), fromJulianDate(transit + hourAngle/360), true
+}
+
+// julianDate converts a time to a Julian date
+func julianDate(t time.Time) float64 {
+	return float64(t.Unix())/86400 + 2440587.5
+}
+
+// fromJulianDate converts a Julian date to a UTC time (second precision)
+func fromJulianDate(jd float64) time.Time {
+	return time.Unix(int64(math.Round((jd-2440587.5)*86400)), 0).UTC()
+}
@@ -0,0 +1,54 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSunTimes(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		day         time.Time
+		wantSunrise string
+		wantSunset  string
+	}{
+		{"summer solstice", time.Date(2030, 6, 21, 0, 0, 0, 0, loc), "05:47", "20:53"},
+		{"winter solstice", time.Date(2030, 12, 21, 0, 0, 0, 0, loc), "07:39", "16:54"},
+	}
+
+	within := func(got time.Time, want string) bool {
+		w, err := time.ParseInLocation("15:04", want, loc)
+		if err != nil {
+			return false
+		}
+		local := got.In(loc)
+		diff := (local.Hour()*60 + local.Minute()) - (w.Hour()*60 + w.Minute())
+		return diff >= -5 && diff <= 5
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sunrise, sunset, ok := SunTimes(tt.day, 40.6817, -79.6592)
+			if !ok {
+				t.Fatal("SunTimes() ok = false")
+			}
+			if !within(sunrise, tt.wantSunrise) {
+				t.Errorf("sunrise = %s, want about %s", sunrise.In(loc).Format("15:04"), tt.wantSunrise)
+			}
+			if !within(sunset, tt.wantSunset) {
+				t.Errorf("sunset = %s, want about %s", sunset.In(loc).Format("15:04"), tt.wantSunset)
+			}
+		})
+	}
+}
+
+func TestSunTimes_PolarNight(t *testing.T) {
+	if _, _, ok := SunTimes(time.Date(2030, 12, 21, 0, 0, 0, 0, time.UTC), 80, 0); ok {
+		t.Error("SunTimes() ok = true during polar night")
+	}
+}
@@ -0,0 +1,256 @@
+// Package privacy exports and permanently deletes the data stored about a user.
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// Subject identifies whose data is exported or deleted. Messages and schedules are matched on
+// created_by, which holds the signed-in user's ID; chat sessions carry no owner, so the caller
+// names them explicitly.
+type Subject struct {
+	UserID     string
+	SessionIDs []string
+}
+
+// Validate checks that the subject names a user
+func (s Subject) Validate() error {
+	if s.UserID == "" {
+		return fmt.Errorf("user_id is required")
+	}
+	// Without users, created_by names the component, whose data belongs to everyone
+	if models.IsComponent(s.UserID) {
+		return fmt.Errorf("user_id %q is a service, not a user", s.UserID)
+	}
+	return nil
+}
+
+// Archive is everything stored about a user, returned as the export
+type Archive struct {
+	UserID           string                    `json:"user_id"`
+	ExportedAt       time.Time                 `json:"exported_at"`
+	Messages         []*models.Message         `json:"messages"`
+	WebActionResults []*models.WebActionResult `json:"web_action_results"`
+	Schedules        []*models.Schedule        `json:"schedules"`
+	Sessions         []*models.AgentSession    `json:"sessions"`
+	RunSummaries     []string                  `json:"run_summaries"`
+	AuditEntries     []*models.AuditEntry      `json:"audit_entries"`
+}
+
+// LogStore lists and removes the agent run logs stored for a schedule
+type LogStore interface {
+	ListScheduleSummaries(ctx context.Context, scheduleID string) ([]string, error)
+	DeleteScheduleSummaries(ctx context.Context, scheduleID string) (int, error)
+}
+
+// TriggerDeleter removes the EventBridge schedule that triggers a stored schedule
+type TriggerDeleter interface {
+	DeleteTrigger(ctx context.Context, name string) error
+}
+
+// Service exports and deletes a user's data across the repositories, run logs, and schedule triggers
+type Service struct {
+	messages  repository.MessageRepository
+	results   repository.WebActionResultRepository
+	schedules repository.ScheduleRepository
+	sessions  repository.AgentSessionRepository
+	audit     repository.AuditRepository
+	logs      LogStore
+	triggers  TriggerDeleter
+	actor     string
+	logger    *slog.Logger
+}
+
+// NewService creates a privacy service that records its operations in the audit log as actor
+func NewService(
+	messages repository.MessageRepository,
+	results repository.WebActionResultRepository,
+	schedules repository.ScheduleRepository,
+	sessions repository.AgentSessionRepository,
+	audit repository.AuditRepository,
+	actor string,
+	logger *slog.Logger,
+) *Service {
+	return &Service{
+		messages:  messages,
+		results:   results,
+		schedules: schedules,
+		sessions:  sessions,
+		audit:     audit,
+		actor:     actor,
+		logger:    logger,
+	}
+}
+
+// SetLogStore includes agent run summaries in exports and deletions
+func (s *Service) SetLogStore(logs LogStore) {
+	s.logs = logs
+}
+
+// SetTriggerDeleter removes EventBridge schedules when their stored schedules are deleted
+func (s *Service) SetTriggerDeleter(triggers TriggerDeleter) {
+	s.triggers = triggers
+}
+
+// Export collects everything stored about the subject and records the export in the audit log
+func (s *Service) Export(ctx context.Context, subject Subject) (*Archive, error) {
+	if err := subject.Validate(); err != nil {
+		return nil, err
+	}
+
+	archive, err := s.collect(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := models.NewAuditEntry(subject.UserID, models.AuditActionDataExport, s.actor, archive.counts())
+	if err != nil {
+		return nil, fmt.Errorf("failed to record export: %w", err)
+	}
+	if err := s.audit.SaveEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to record export: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "user data exported", slog.Any("counts", entry.Counts))
+	return archive, nil
+}
+
+// Delete permanently removes everything stored about the subject. Triggers go first so no new
+// run writes data mid-deletion. Each step is idempotent, so a failed deletion can be retried.
+// The returned audit entry, which holds only counts, is kept as the record of the deletion.
+func (s *Service) Delete(ctx context.Context, subject Subject) (*models.AuditEntry, error) {
+	if err := subject.Validate(); err != nil {
+		return nil, err
+	}
+
+	archive, err := s.collect(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	// The entry is made up front so nothing is deleted without one to record it
+	entry, err := models.NewAuditEntry(subject.UserID, models.AuditActionDataDeletion, s.actor, archive.counts())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, schedule := range archive.Schedules {
+		if s.triggers != nil && schedule.EventBridgeName != "" {
+			if err := s.triggers.DeleteTrigger(ctx, schedule.EventBridgeName); err != nil {
+				return nil, fmt.Errorf("failed to delete trigger for schedule %s: %w", schedule.ID, err)
+			}
+		}
+		if s.logs != nil {
+			if _, err := s.logs.DeleteScheduleSummaries(ctx, schedule.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete run summaries for schedule %s: %w", schedule.ID, err)
+			}
+		}
+	}
+
+	for _, session := range archive.Sessions {
+		if err := s.sessions.DeleteSession(ctx, session.SessionID); err != nil {
+			return nil, err
+		}
+	}
+	for _, result := range archive.WebActionResults {
+		if err := s.results.DeleteResult(ctx, result.ID); err != nil {
+			return nil, err
+		}
+	}
+	for _, message := range archive.Messages {
+		if err := s.messages.DeleteMessage(ctx, message.ID); err != nil {
+			return nil, err
+		}
+	}
+	for _, schedule := range archive.Schedules {
+		if err := s.schedules.PurgeSchedule(ctx, schedule.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	entry.CreatedAt = time.Now().UTC()
+	if err := s.audit.SaveEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("data deleted but failed to record deletion: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "user data deleted", slog.Any("counts", entry.Counts))
+	return entry, nil
+}
+
+// collect reads everything stored about the subject
+func (s *Service) collect(ctx context.Context, subject Subject) (*Archive, error) {
+	archive := &Archive{
+		UserID:           subject.UserID,
+		ExportedAt:       time.Now().UTC(),
+		WebActionResults: make([]*models.WebActionResult, 0),
+		Sessions:         make([]*models.AgentSession, 0),
+		RunSummaries:     make([]string, 0),
+	}
+
+	messages, err := s.messages.ListMessagesByCreator(ctx, subject.UserID)
+	if err != nil {
+		return nil, err
+	}
+	archive.Messages = messages
+
+	for _, message := range messages {
+		if message.MessageType != models.MessageTypeWebAction {
+			continue
+		}
+		results, err := s.results.ListResultsByMessageID(ctx, message.ID)
+		if err != nil {
+			return nil, err
+		}
+		archive.WebActionResults = append(archive.WebActionResults, results...)
+	}
+
+	schedules, err := s.schedules.ListSchedulesByCreator(ctx, subject.UserID)
+	if err != nil {
+		return nil, err
+	}
+	archive.Schedules = schedules
+
+	if s.logs != nil {
+		for _, schedule := range schedules {
+			keys, err := s.logs.ListScheduleSummaries(ctx, schedule.ID)
+			if err != nil {
+				return nil, err
+			}
+			archive.RunSummaries = append(archive.RunSummaries, keys...)
+		}
+	}
+
+	for _, sessionID := range subject.SessionIDs {
+		session, err := s.sessions.GetSession(ctx, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if session != nil {
+			archive.Sessions = append(archive.Sessions, session)
+		}
+	}
+
+	entries, err := s.audit.ListEntriesByUser(ctx, subject.UserID)
+	if err != nil {
+		return nil, err
+	}
+	archive.AuditEntries = entries
+
+	return archive, nil
+}
+
+// counts summarizes the archive for the audit log
+func (a *Archive) counts() map[string]int {
+	return map[string]int{
+		"messages":           len(a.Messages),
+		"web_action_results": len(a.WebActionResults),
+		"schedules":          len(a.Schedules),
+		"sessions":           len(a.Sessions),
+		"run_summaries":      len(a.RunSummaries),
+	}
+}
@@ -0,0 +1,307 @@
+package privacy
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+type fakeMessages struct {
+	items map[string]*models.Message
+}
+
+func (f *fakeMessages) SaveMessage(ctx context.Context, m *models.Message) error { return nil }
+func (f *fakeMessages) GetMessage(ctx context.Context, id string) (*models.Message, error) {
+	return f.items[id], nil
+}
+func (f *fakeMessages) ListMessages(ctx context.Context, stage *models.Stage, status *models.Status, limit int) ([]*models.Message, error) {
+	return nil, nil
+}
+func (f *fakeMessages) QueryMessages(ctx context.Context, query repository.MessageQuery, page repository.IndexPage) ([]*models.Message, map[string]string, error) {
+	return nil, nil, nil
+}
+func (f *fakeMessages) UpdateStatus(ctx context.Context, id string, status models.Status, errorMessage string) error {
+	return nil
+}
+func (f *fakeMessages) TransitionStatus(ctx context.Context, id string, from, to models.Status) error {
+	return nil
+}
+func (f *fakeMessages) ListMessagesByStatus(ctx context.Context, status models.Status, createdBefore time.Time, limit int) ([]*models.Message, error) {
+	return nil, nil
+}
+func (f *fakeMessages) RequeueMessage(ctx context.Context, id string, from models.Status) error {
+	return nil
+}
+func (f *fakeMessages) FailMessage(ctx context.Context, id string, from models.Status, errorMessage string) error {
+	return nil
+}
+func (f *fakeMessages) ListMessagesByCreator(ctx context.Context, createdBy string) ([]*models.Message, error) {
+	var out []*models.Message
+	for _, m := range f.items {
+		if m.CreatedBy == createdBy {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+func (f *fakeMessages) DeleteMessage(ctx context.Context, id string) error {
+	delete(f.items, id)
+	return nil
+}
+
+type fakeResults struct {
+	items map[string]*models.WebActionResult
+}
+
+func (f *fakeResults) SaveResult(ctx context.Context, r *models.WebActionResult) error { return nil }
+func (f *fakeResults) GetResult(ctx context.Context, id string) (*models.WebActionResult, error) {
+	return f.items[id], nil
+}
+func (f *fakeResults) GetResultByMessageID(ctx context.Context, messageID string) (*models.WebActionResult, error) {
+	return nil, nil
+}
+func (f *fakeResults) ListResultsByMessageID(ctx context.Context, messageID string) ([]*models.WebActionResult, error) {
+	var out []*models.WebActionResult
+	for _, r := range f.items {
+		if r.MessageID == messageID {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+func (f *fakeResults) QueryResults(ctx context.Context, query repository.ResultQuery, page repository.IndexPage) ([]*models.WebActionResult, map[string]string, error) {
+	return nil, nil, nil
+}
+func (f *fakeResults) DeleteResult(ctx context.Context, id string) error {
+	delete(f.items, id)
+	return nil
+}
+
+type fakeSchedules struct {
+	items map[string]*models.Schedule
+}
+
+func (f *fakeSchedules) SaveSchedule(ctx context.Context, s *models.Schedule) error { return nil }
+func (f *fakeSchedules) GetSchedule(ctx context.Context, id string) (*models.Schedule, error) {
+	return f.items[id], nil
+}
+func (f *fakeSchedules) UpdateSchedule(ctx context.Context, s *models.Schedule) error { return nil }
+func (f *fakeSchedules) UpdateScheduleStatus(ctx context.Context, id string, status models.ScheduleStatus, errorMessage string) error {
+	return nil
+}
+func (f *fakeSchedules) ListSchedulesByStatus(ctx context.Context, status models.ScheduleStatus) ([]*models.Schedule, error) {
+	return nil, nil
+}
+func (f *fakeSchedules) ListSchedulesByCreator(ctx context.Context, createdBy string) ([]*models.Schedule, error) {
+	var out []*models.Schedule
+	for _, s := range f.items {
+		if s.CreatedBy == createdBy {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+func (f *fakeSchedules) DeleteSchedule(ctx context.Context, id string) error { return nil }
+func (f *fakeSchedules) PurgeSchedule(ctx context.Context, id string) error {
+	delete(f.items, id)
+	return nil
+}
+
+type fakeSessions struct {
+	items map[string]*models.AgentSession
+}
+
+func (f *fakeSessions) GetSession(ctx context.Context, id string) (*models.AgentSession, error) {
+	return f.items[id], nil
+}
+func (f *fakeSessions) AppendMessages(ctx context.Context, id string, messages []map[string]interface{}, approvedTools []string) (*models.AgentSession, error) {
+	return nil, nil
+}
+func (f *fakeSessions) TrimSession(ctx context.Context, id string) (int, error) { return 0, nil }
+func (f *fakeSessions) ExpireSession(ctx context.Context, id string, at time.Time) error {
+	return nil
+}
+func (f *fakeSessions) DeleteSession(ctx context.Context, id string) error {
+	delete(f.items, id)
+	return nil
+}
+
+type fakeAudit struct {
+	entries []*models.AuditEntry
+}
+
+func (f *fakeAudit) SaveEntry(ctx context.Context, e *models.AuditEntry) error {
+	f.entries = append(f.entries, e)
+	return nil
+}
+func (f *fakeAudit) ListEntriesByUser(ctx context.Context, userID string) ([]*models.AuditEntry, error) {
+	var out []*models.AuditEntry
+	for _, e := range f.entries {
+		if e.UserID == userID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+func (f *fakeAudit) ListEntries(ctx context.Context, since time.Time) ([]*models.AuditEntry, error) {
+	return f.entries, nil
+}
+
+type fakeLogs struct {
+	keys map[string][]string
+}
+
+func (f *fakeLogs) ListScheduleSummaries(ctx context.Context, scheduleID string) ([]string, error) {
+	return f.keys[scheduleID], nil
+}
+func (f *fakeLogs) DeleteScheduleSummaries(ctx context.Context, scheduleID string) (int, error) {
+	n := len(f.keys[scheduleID])
+	delete(f.keys, scheduleID)
+	return n, nil
+}
+
+type fakeTriggers struct {
+	deleted []string
+}
+
+func (f *fakeTriggers) DeleteTrigger(ctx context.Context, name string) error {
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+type fixture struct {
+	service   *Service
+	messages  *fakeMessages
+	results   *fakeResults
+	schedules *fakeSchedules
+	sessions  *fakeSessions
+	audit     *fakeAudit
+	logs      *fakeLogs
+	triggers  *fakeTriggers
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		messages: &fakeMessages{items: map[string]*models.Message{
+			"msg_1": {ID: "msg_1", CreatedBy: "golfer", MessageType: models.MessageTypeWebAction},
+			"msg_2": {ID: "msg_2", CreatedBy: "golfer", MessageType: models.MessageTypeNotification},
+			"msg_3": {ID: "msg_3", CreatedBy: "someone-else", MessageType: models.MessageTypeWebAction},
+		}},
+		results: &fakeResults{items: map[string]*models.WebActionResult{
+			"res_1": {ID: "res_1", MessageID: "msg_1"},
+			"res_3": {ID: "res_3", MessageID: "msg_3"},
+		}},
+		schedules: &fakeSchedules{items: map[string]*models.Schedule{
+			"sched_1": {ID: "sched_1", CreatedBy: "golfer", EventBridgeName: "rez-agent-dev-weekly"},
+			"sched_2": {ID: "sched_2", CreatedBy: "someone-else"},
+		}},
+		sessions: &fakeSessions{items: map[string]*models.AgentSession{
+			"session-a": {SessionID: "session-a"},
+		}},
+		audit:    &fakeAudit{},
+		logs:     &fakeLogs{keys: map[string][]string{"sched_1": {"summaries/dev/sched_1/2026/10/01/1.html"}}},
+		triggers: &fakeTriggers{},
+	}
+	f.service = NewService(f.messages, f.results, f.schedules, f.sessions, f.audit, "webapi", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	f.service.SetLogStore(f.logs)
+	f.service.SetTriggerDeleter(f.triggers)
+	return f
+}
+
+func TestService_Export(t *testing.T) {
+	f := newFixture()
+	subject := Subject{UserID: "golfer", SessionIDs: []string{"session-a", "session-missing"}}
+
+	archive, err := f.service.Export(context.Background(), subject)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"messages", len(archive.Messages), 2},
+		{"web action results", len(archive.WebActionResults), 1},
+		{"schedules", len(archive.Schedules), 1},
+		{"sessions", len(archive.Sessions), 1},
+		{"run summaries", len(archive.RunSummaries), 1},
+		{"audit entries recorded", len(f.audit.entries), 1},
+		{"messages left in place", len(f.messages.items), 3},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+	if f.audit.entries[0].Action != models.AuditActionDataExport {
+		t.Errorf("audit action = %s, want %s", f.audit.entries[0].Action, models.AuditActionDataExport)
+	}
+}
+
+func TestService_Delete(t *testing.T) {
+	f := newFixture()
+	subject := Subject{UserID: "golfer", SessionIDs: []string{"session-a"}}
+
+	entry, err := f.service.Delete(context.Background(), subject)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if entry.Action != models.AuditActionDataDeletion || entry.Counts["messages"] != 2 || entry.Counts["schedules"] != 1 {
+		t.Errorf("audit entry = %+v, want a deletion of 2 messages and 1 schedule", entry)
+	}
+	if _, ok := f.messages.items["msg_3"]; !ok || len(f.messages.items) != 1 {
+		t.Errorf("remaining messages = %v, want only msg_3", f.messages.items)
+	}
+	if _, ok := f.results.items["res_3"]; !ok || len(f.results.items) != 1 {
+		t.Errorf("remaining results = %v, want only res_3", f.results.items)
+	}
+	if _, ok := f.schedules.items["sched_2"]; !ok || len(f.schedules.items) != 1 {
+		t.Errorf("remaining schedules = %v, want only sched_2", f.schedules.items)
+	}
+	if len(f.sessions.items) != 0 || len(f.logs.keys) != 0 {
+		t.Errorf("sessions = %v, logs = %v, want both empty", f.sessions.items, f.logs.keys)
+	}
+	if len(f.triggers.deleted) != 1 || f.triggers.deleted[0] != "rez-agent-dev-weekly" {
+		t.Errorf("deleted triggers = %v, want [rez-agent-dev-weekly]", f.triggers.deleted)
+	}
+
+	// A second deletion finds nothing and still records the request
+	entry, err = f.service.Delete(context.Background(), subject)
+	if err != nil {
+		t.Fatalf("second Delete() error = %v", err)
+	}
+	if entry.Counts["messages"] != 0 || len(f.audit.entries) != 2 {
+		t.Errorf("second deletion counts = %v with %d audit entries, want 0 messages and 2 entries", entry.Counts, len(f.audit.entries))
+	}
+}
+
+func TestService_RequiresUser(t *testing.T) {
+	f := newFixture()
+	if _, err := f.service.Export(context.Background(), Subject{}); err == nil {
+		t.Error("Export() should fail without a user_id")
+	}
+	if _, err := f.service.Delete(context.Background(), Subject{}); err == nil {
+		t.Error("Delete() should fail without a user_id")
+	}
+}
+
+func TestService_RefusesComponents(t *testing.T) {
+	f := newFixture()
+	f.messages.items["msg_webapi"] = &models.Message{ID: "msg_webapi", CreatedBy: models.ComponentWebAPI}
+
+	// A component created every message made without a signed-in user, so it is nobody's data
+	if _, err := f.service.Delete(context.Background(), Subject{UserID: models.ComponentWebAPI}); err == nil {
+		t.Error("Delete() should refuse a component as the user")
+	}
+	if _, ok := f.messages.items["msg_webapi"]; !ok {
+		t.Error("Delete() removed a message created by the web API")
+	}
+}
@@ -0,0 +1,34 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DirOverrideSource reads template overrides from {name}.tmpl files in a local directory,
+// for trying prompt edits before they are published to SSM
+type DirOverrideSource struct {
+	dir string
+}
+
+// NewDirOverrideSource creates an override source for the templates in dir
+func NewDirOverrideSource(dir string) *DirOverrideSource {
+	return &DirOverrideSource{dir: dir}
+}
+
+// GetTemplate returns the file's contents, or ErrNoOverride if there is no file for the template
+func (s *DirOverrideSource) GetTemplate(_ context.Context, name string) (string, string, error) {
+	path := filepath.Join(s.dir, name+".tmpl")
+	body, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", "", ErrNoOverride
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read prompt template %s: %w", path, err)
+	}
+	return string(body), "file:" + path, nil
+}
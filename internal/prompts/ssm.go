@@ -0,0 +1,44 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// SSMOverrideSource reads template overrides from SSM Parameter Store, one parameter per
+// template under a per-stage prefix (e.g. /rez-agent/prod/prompts/scheduled_booking).
+// Parameter Store keeps every version, so an edit can be rolled back by restoring an old one.
+type SSMOverrideSource struct {
+	client *ssm.Client
+	prefix string
+}
+
+// NewSSMOverrideSource creates an override source for parameters under prefix
+func NewSSMOverrideSource(client *ssm.Client, prefix string) *SSMOverrideSource {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &SSMOverrideSource{client: client, prefix: prefix}
+}
+
+// GetTemplate returns the parameter's value and version, or ErrNoOverride if it does not exist
+func (s *SSMOverrideSource) GetTemplate(ctx context.Context, name string) (string, string, error) {
+	out, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(s.prefix + name),
+	})
+	if err != nil {
+		var notFound *ssmtypes.ParameterNotFound
+		if errors.As(err, &notFound) {
+			return "", "", ErrNoOverride
+		}
+		return "", "", fmt.Errorf("failed to get prompt template parameter %s: %w", s.prefix+name, err)
+	}
+
+	return aws.ToString(out.Parameter.Value), fmt.Sprintf("ssm:%d", out.Parameter.Version), nil
+}
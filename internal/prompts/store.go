@@ -0,0 +1,221 @@
+package prompts
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Template names
+const (
+	// ScheduledBooking is the system prompt for scheduled tee time bookings
+	ScheduledBooking = "scheduled_booking"
+
+	// StandingTeeTime is the system prompt for standing tee time reminders and renewals
+	StandingTeeTime = "standing_tee_time"
+
+	// InteractiveChat is the system prompt for chat sessions with the golf assistant
+	InteractiveChat = "interactive_chat"
+)
+
+// EmbeddedVersion is the version reported for the templates compiled into the binary
+const EmbeddedVersion = "embedded"
+
+// defaultCacheTTL is how long a Lambda container reuses an override before checking for a new version
+const defaultCacheTTL = 5 * time.Minute
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var embeddedTemplates = template.Must(newTemplate("").ParseFS(templateFS, "templates/*.tmpl"))
+
+// ErrNoOverride is returned by an OverrideSource when a template is not overridden
+var ErrNoOverride = errors.New("no template override")
+
+// OverrideSource supplies templates that replace the embedded ones, such as per-stage prompts
+// edited without a deploy
+type OverrideSource interface {
+	GetTemplate(ctx context.Context, name string) (body, version string, err error)
+}
+
+// BookingData is the data available to the ScheduledBooking template
+type BookingData struct {
+	CurrentDate         string
+	Reservations        string
+	Weather             string
+	Preferences         string
+	BookingRules        string
+	MaxPrecipChance     int
+	NumPlayers          int
+	PriceInstruction    string
+	ApprovalInstruction string
+}
+
+// StandingData is the data available to the StandingTeeTime template
+type StandingData struct {
+	CurrentDate  string
+	CourseName   string
+	Weekday      string
+	TimeOfDay    string
+	NumPlayers   int
+	TargetDate   string
+	Reservations string
+	Weather      string
+
+	// Renew is set when the standing tee time should be booked rather than only reminded
+	Renew bool
+}
+
+// ChatData is the data available to the InteractiveChat template
+type ChatData struct {
+	CurrentTime string
+	// Courses describes the supported courses, one per line
+	Courses string
+}
+
+// Rendered is a rendered prompt and the template version it came from
+type Rendered struct {
+	Text    string
+	Name    string
+	Version string
+}
+
+// cachedOverride is an override lookup; a nil template means the name is not overridden
+type cachedOverride struct {
+	tmpl     *template.Template
+	version  string
+	loadedAt time.Time
+}
+
+// Store renders prompt templates, preferring overrides over the embedded templates.
+// A nil Store renders the embedded templates.
+type Store struct {
+	overrides OverrideSource
+	cacheTTL  time.Duration
+	logger    *slog.Logger
+
+	mu    sync.Mutex
+	cache map[string]cachedOverride
+}
+
+// NewStore creates a template store. overrides may be nil to use only the embedded templates.
+func NewStore(overrides OverrideSource, logger *slog.Logger) *Store {
+	return &Store{
+		overrides: overrides,
+		cacheTTL:  defaultCacheTTL,
+		logger:    logger,
+		cache:     make(map[string]cachedOverride),
+	}
+}
+
+// Render executes the named template with data. An override that cannot be loaded, parsed, or
+// executed is logged and the embedded template is used instead, so a bad edit cannot stop runs.
+func (s *Store) Render(ctx context.Context, name string, data interface{}) (Rendered, error) {
+	if s != nil && s.overrides != nil {
+		if tmpl, version := s.override(ctx, name); tmpl != nil {
+			text, err := execute(tmpl, data)
+			if err == nil {
+				return Rendered{Text: text, Name: name, Version: version}, nil
+			}
+			s.logger.WarnContext(ctx, "prompt template override failed to render, using embedded template",
+				slog.String("template", name),
+				slog.String("version", version),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	tmpl := embeddedTemplates.Lookup(name + ".tmpl")
+	if tmpl == nil {
+		return Rendered{}, fmt.Errorf("unknown prompt template: %s", name)
+	}
+	text, err := execute(tmpl, data)
+	if err != nil {
+		return Rendered{}, fmt.Errorf("failed to render prompt template %s: %w", name, err)
+	}
+	return Rendered{Text: text, Name: name, Version: EmbeddedVersion}, nil
+}
+
+// override returns the cached or freshly loaded override for name, or nil when there is none
+func (s *Store) override(ctx context.Context, name string) (*template.Template, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cached, ok := s.cache[name]; ok && time.Since(cached.loadedAt) < s.cacheTTL {
+		return cached.tmpl, cached.version
+	}
+
+	body, version, err := s.overrides.GetTemplate(ctx, name)
+	if errors.Is(err, ErrNoOverride) {
+		s.cache[name] = cachedOverride{loadedAt: time.Now()}
+		return nil, ""
+	}
+	if err != nil {
+		// Not cached, so the next run tries the source again
+		s.logger.WarnContext(ctx, "failed to load prompt template override, using embedded template",
+			slog.String("template", name),
+			slog.String("error", err.Error()),
+		)
+		return nil, ""
+	}
+
+	tmpl, err := newTemplate(name).Parse(body)
+	if err != nil {
+		s.logger.WarnContext(ctx, "invalid prompt template override, using embedded template",
+			slog.String("template", name),
+			slog.String("version", version),
+			slog.String("error", err.Error()),
+		)
+		tmpl = nil
+	}
+	s.cache[name] = cachedOverride{tmpl: tmpl, version: version, loadedAt: time.Now()}
+	return tmpl, version
+}
+
+// newTemplate creates a template that fails on missing map keys instead of printing "<no value>"
+func newTemplate(name string) *template.Template {
+	return template.New(name).Option("missingkey=error")
+}
+
+// execute renders tmpl into a string
+func execute(tmpl *template.Template, data interface{}) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// DecodeData decodes recorded template data for the named template, so a recorded run's
+// prompt can be rendered again with different templates
+func DecodeData(name string, raw []byte) (interface{}, error) {
+	switch name {
+	case ScheduledBooking:
+		var data BookingData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, fmt.Errorf("failed to decode %s data: %w", name, err)
+		}
+		return data, nil
+	case StandingTeeTime:
+		var data StandingData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, fmt.Errorf("failed to decode %s data: %w", name, err)
+		}
+		return data, nil
+	case InteractiveChat:
+		var data ChatData
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, fmt.Errorf("failed to decode %s data: %w", name, err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown prompt template: %s", name)
+	}
+}
@@ -0,0 +1,179 @@
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeOverrides struct {
+	body    string
+	version string
+	err     error
+	calls   int
+}
+
+func (f *fakeOverrides) GetTemplate(ctx context.Context, name string) (string, string, error) {
+	f.calls++
+	return f.body, f.version, f.err
+}
+
+var bookingData = BookingData{
+	CurrentDate:         "Saturday, June 6, 2026",
+	Reservations:        "No reservations",
+	Weather:             "Sunny",
+	Preferences:         "Prefers mornings",
+	BookingRules:        "No rules",
+	MaxPrecipChance:     40,
+	NumPlayers:          2,
+	PriceInstruction:    "There is no price cap for this booking",
+	ApprovalInstruction: "Bookings are completed immediately",
+}
+
+func TestStore_RenderEmbedded(t *testing.T) {
+	var store *Store // a nil store renders the embedded templates
+
+	booking, err := store.Render(context.Background(), ScheduledBooking, bookingData)
+	if err != nil {
+		t.Fatalf("Render(%s) error = %v", ScheduledBooking, err)
+	}
+	if booking.Version != EmbeddedVersion {
+		t.Errorf("Version = %q, want %q", booking.Version, EmbeddedVersion)
+	}
+	for _, want := range []string{"CURRENT DATE: Saturday, June 6, 2026", "Prefers mornings", "precipitation is 40% or higher", "use 2 player(s)"} {
+		if !strings.Contains(booking.Text, want) {
+			t.Errorf("booking prompt is missing %q", want)
+		}
+	}
+
+	for _, tt := range []struct {
+		renew bool
+		want  string
+	}{
+		{true, "MODE: RENEW"},
+		{false, "MODE: REMIND"},
+	} {
+		standing, err := store.Render(context.Background(), StandingTeeTime, StandingData{
+			CourseName: "Birdsfoot",
+			Weekday:    "Saturday",
+			TimeOfDay:  "08:00",
+			NumPlayers: 4,
+			TargetDate: "Saturday, June 20, 2026",
+			Renew:      tt.renew,
+		})
+		if err != nil {
+			t.Fatalf("Render(%s) error = %v", StandingTeeTime, err)
+		}
+		if !strings.Contains(standing.Text, tt.want) || !strings.Contains(standing.Text, "Every Saturday at 08:00") {
+			t.Errorf("standing prompt (renew=%v) = %q, want %s", tt.renew, standing.Text, tt.want)
+		}
+	}
+
+	if _, err := store.Render(context.Background(), "missing", nil); err == nil {
+		t.Error("Render() should fail for an unknown template")
+	}
+}
+
+func TestStore_RenderOverride(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name        string
+		overrides   *fakeOverrides
+		wantVersion string
+		wantText    string
+	}{
+		{"override", &fakeOverrides{body: "Book for {{.NumPlayers}} on {{.CurrentDate}}", version: "ssm:3"}, "ssm:3", "Book for 2 on Saturday, June 6, 2026"},
+		{"not overridden", &fakeOverrides{err: ErrNoOverride}, EmbeddedVersion, "CURRENT DATE"},
+		{"source unavailable", &fakeOverrides{err: errors.New("throttled")}, EmbeddedVersion, "CURRENT DATE"},
+		{"invalid template", &fakeOverrides{body: "{{.NumPlayers", version: "ssm:4"}, EmbeddedVersion, "CURRENT DATE"},
+		{"unknown field", &fakeOverrides{body: "{{.Handicap}}", version: "ssm:5"}, EmbeddedVersion, "CURRENT DATE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(tt.overrides, logger)
+			got, err := store.Render(context.Background(), ScheduledBooking, bookingData)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got.Version != tt.wantVersion || !strings.Contains(got.Text, tt.wantText) {
+				t.Errorf("Render() = %s %q, want %s containing %q", got.Version, got.Text, tt.wantVersion, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestStore_OverrideCache(t *testing.T) {
+	overrides := &fakeOverrides{body: "v1", version: "ssm:1"}
+	store := NewStore(overrides, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for i := 0; i < 3; i++ {
+		if _, err := store.Render(context.Background(), ScheduledBooking, bookingData); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+	}
+	if overrides.calls != 1 {
+		t.Errorf("override source called %d times, want 1 while cached", overrides.calls)
+	}
+
+	// Once the cache expires, a new version is picked up without a deploy
+	store.cacheTTL = time.Nanosecond
+	overrides.body, overrides.version = "v2", "ssm:2"
+	got, err := store.Render(context.Background(), ScheduledBooking, bookingData)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got.Text != "v2" || got.Version != "ssm:2" {
+		t.Errorf("Render() after expiry = %s %q, want ssm:2 %q", got.Version, got.Text, "v2")
+	}
+}
+
+func TestDecodeData_RerendersRecordedData(t *testing.T) {
+	raw, err := json.Marshal(bookingData)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	data, err := DecodeData(ScheduledBooking, raw)
+	if err != nil {
+		t.Fatalf("DecodeData() error = %v", err)
+	}
+	if data != bookingData {
+		t.Errorf("DecodeData() = %+v, want %+v", data, bookingData)
+	}
+
+	if _, err := DecodeData("unknown", raw); err == nil {
+		t.Error("DecodeData(unknown) error = nil, want error")
+	}
+}
+
+func TestDirOverrideSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ScheduledBooking+".tmpl"), []byte("Local prompt for {{.NumPlayers}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore(NewDirOverrideSource(dir), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	booking, err := store.Render(context.Background(), ScheduledBooking, bookingData)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if booking.Text != "Local prompt for 2" || !strings.HasPrefix(booking.Version, "file:") {
+		t.Errorf("Render() = %+v, want the local override", booking)
+	}
+
+	standing, err := store.Render(context.Background(), StandingTeeTime, StandingData{CourseName: "Birdsfoot"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if standing.Version != EmbeddedVersion {
+		t.Errorf("Version = %q, want %q for a template without a file", standing.Version, EmbeddedVersion)
+	}
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// AgentSessionRepository defines the interface for agent chat session storage. Sessions are kept
+// to a window of recent messages and expire once idle for the window's TTL.
+type AgentSessionRepository interface {
+	// GetSession retrieves a session, returning nil when it does not exist or has expired
+	GetSession(ctx context.Context, sessionID string) (*models.AgentSession, error)
+
+	// AppendMessages adds messages to a session, creating it if needed, and returns the stored
+	// session: trimmed to the window, with its TTL refreshed. approvedTools replaces the session's
+	// approved tools unless it is nil.
+	AppendMessages(ctx context.Context, sessionID string, messages []map[string]interface{}, approvedTools []string) (*models.AgentSession, error)
+
+	// TrimSession trims a stored session to the window, returning how many messages were dropped
+	TrimSession(ctx context.Context, sessionID string) (int, error)
+
+	// ExpireSession sets when a session expires; a time in the past ends it at the next TTL sweep
+	ExpireSession(ctx context.Context, sessionID string, at time.Time) error
+
+	// DeleteSession permanently removes a session
+	DeleteSession(ctx context.Context, sessionID string) error
+}
+
+// DynamoDBAgentSessionRepository implements AgentSessionRepository using the agent's session table
+type DynamoDBAgentSessionRepository struct {
+	client    *dynamodb.Client
+	tableName string
+	window    models.AgentSessionWindow
+}
+
+// NewDynamoDBAgentSessionRepository creates a new agent session repository with the default window
+func NewDynamoDBAgentSessionRepository(client *dynamodb.Client, tableName string) *DynamoDBAgentSessionRepository {
+	return &DynamoDBAgentSessionRepository{
+		client:    client,
+		tableName: tableName,
+		window:    models.DefaultAgentSessionWindow,
+	}
+}
+
+// SetWindow sets how much history sessions keep and how long idle sessions live
+func (r *DynamoDBAgentSessionRepository) SetWindow(window models.AgentSessionWindow) {
+	r.window = window
+}
+
+// GetSession retrieves a session, returning nil when it does not exist or has expired
+func (r *DynamoDBAgentSessionRepository) GetSession(ctx context.Context, sessionID string) (*models.AgentSession, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"session_id": &types.AttributeValueMemberS{Value: sessionID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent session: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var session models.AgentSession
+	if err := attributevalue.UnmarshalMap(result.Item, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal agent session: %w", err)
+	}
+	if session.IsExpired(time.Now()) {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// AppendMessages adds messages to a session, creating it if needed. The session is read, extended
+// and written back whole, since trimming rewrites the message list; a chat session has one
+// conversation in flight at a time.
+func (r *DynamoDBAgentSessionRepository) AppendMessages(ctx context.Context, sessionID string, messages []map[string]interface{}, approvedTools []string) (*models.AgentSession, error) {
+	session, err := r.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if session == nil {
+		session = models.NewAgentSession(sessionID, now)
+	}
+	session.Append(messages, approvedTools, r.window, now)
+
+	if err := r.putSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// TrimSession trims a stored session to the window, returning how many messages were dropped
+func (r *DynamoDBAgentSessionRepository) TrimSession(ctx context.Context, sessionID string) (int, error) {
+	session, err := r.GetSession(ctx, sessionID)
+	if err != nil || session == nil {
+		return 0, err
+	}
+	dropped := session.Trim(r.window)
+	if dropped == 0 {
+		return 0, nil
+	}
+	if err := r.putSession(ctx, session); err != nil {
+		return 0, err
+	}
+	return dropped, nil
+}
+
+// ExpireSession sets when a session expires
+func (r *DynamoDBAgentSessionRepository) ExpireSession(ctx context.Context, sessionID string, at time.Time) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"session_id": &types.AttributeValueMemberS{Value: sessionID},
+		},
+		ConditionExpression: aws.String("attribute_exists(session_id)"),
+		UpdateExpression:    aws.String("SET #ttl = :ttl"),
+		ExpressionAttributeNames: map[string]string{
+			"#ttl": "ttl",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ttl": &types.AttributeValueMemberN{Value: strconv.FormatInt(at.Unix(), 10)},
+		},
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return apperrors.Newf(apperrors.ErrNotFound, "agent session not found: %s", sessionID)
+		}
+		return fmt.Errorf("failed to expire agent session: %w", err)
+	}
+	return nil
+}
+
+// putSession writes a whole session
+func (r *DynamoDBAgentSessionRepository) putSession(ctx context.Context, session *models.AgentSession) error {
+	item, err := attributevalue.MarshalMap(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal agent session: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save agent session: %w", err)
+	}
+	return nil
+}
+
+// DeleteSession permanently removes a session
+func (r *DynamoDBAgentSessionRepository) DeleteSession(ctx context.Context, sessionID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"session_id": &types.AttributeValueMemberS{Value: sessionID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete agent session: %w", err)
+	}
+	return nil
+}
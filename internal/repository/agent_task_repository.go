@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// AgentTaskCancellationTTL is how long a task's cancellation is kept: longer than any chat turn
+// can run
+const AgentTaskCancellationTTL = time.Hour
+
+// AgentTaskRepository defines the interface for cancelling other agents' chat tasks. A task is
+// usually cancelled from another Lambda instance than the one running it, which polls for the
+// cancellation.
+type AgentTaskRepository interface {
+	// CancelTask records that a task was cancelled
+	CancelTask(ctx context.Context, taskID string) error
+
+	// IsTaskCancelled reports whether a task was cancelled
+	IsTaskCancelled(ctx context.Context, taskID string) (bool, error)
+}
+
+// DynamoDBAgentTaskRepository implements AgentTaskRepository with one item per cancelled task in
+// the agent's session table
+type DynamoDBAgentTaskRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBAgentTaskRepository creates a new agent task repository
+func NewDynamoDBAgentTaskRepository(client *dynamodb.Client, tableName string) *DynamoDBAgentTaskRepository {
+	return &DynamoDBAgentTaskRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// CancelTask records that a task was cancelled
+func (r *DynamoDBAgentTaskRepository) CancelTask(ctx context.Context, taskID string) error {
+	item, err := attributevalue.MarshalMap(newAgentTaskCancellation(taskID, time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to marshal agent task cancellation: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel agent task: %w", err)
+	}
+	return nil
+}
+
+// IsTaskCancelled reports whether a task was cancelled
+func (r *DynamoDBAgentTaskRepository) IsTaskCancelled(ctx context.Context, taskID string) (bool, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"session_id": &types.AttributeValueMemberS{Value: models.AgentTaskCancellationID(taskID)},
+		},
+		ProjectionExpression: aws.String("session_id"),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get agent task cancellation: %w", err)
+	}
+	return result.Item != nil, nil
+}
+
+// newAgentTaskCancellation is the record of a task cancelled at now
+func newAgentTaskCancellation(taskID string, now time.Time) *models.AgentTaskCancellation {
+	return &models.AgentTaskCancellation{
+		ID:          models.AgentTaskCancellationID(taskID),
+		TaskID:      taskID,
+		CancelledAt: now.UTC().Format(time.RFC3339),
+		TTL:         now.Add(AgentTaskCancellationTTL).Unix(),
+	}
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// AgentUsageRepository defines the interface for the chat agent's daily Bedrock usage
+type AgentUsageRepository interface {
+	// GetUsage returns the usage recorded on date (YYYY-MM-DD, UTC), or an empty record for a day
+	// with none
+	GetUsage(ctx context.Context, date string) (*models.AgentUsage, error)
+
+	// AddUsage adds one request's tokens and cost to date's usage and returns the new totals
+	AddUsage(ctx context.Context, date string, inputTokens, outputTokens int64, cost float64) (*models.AgentUsage, error)
+}
+
+// DynamoDBAgentUsageRepository implements AgentUsageRepository with one item per stage and day in
+// the messages table
+type DynamoDBAgentUsageRepository struct {
+	client    *dynamodb.Client
+	tableName string
+	stage     string
+}
+
+// NewDynamoDBAgentUsageRepository creates a new agent usage repository
+func NewDynamoDBAgentUsageRepository(client *dynamodb.Client, tableName, stage string) *DynamoDBAgentUsageRepository {
+	return &DynamoDBAgentUsageRepository{
+		client:    client,
+		tableName: tableName,
+		stage:     stage,
+	}
+}
+
+// GetUsage returns the usage recorded on date
+func (r *DynamoDBAgentUsageRepository) GetUsage(ctx context.Context, date string) (*models.AgentUsage, error) {
+	id := models.AgentUsageID(r.stage, date)
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent usage: %w", err)
+	}
+
+	usage := &models.AgentUsage{ID: id, Date: date}
+	if result.Item != nil {
+		if err := attributevalue.UnmarshalMap(result.Item, usage); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal agent usage: %w", err)
+		}
+	}
+	return usage, nil
+}
+
+// AddUsage adds to date's usage with an atomic counter update, so concurrent requests never
+// overwrite each other's usage
+func (r *DynamoDBAgentUsageRepository) AddUsage(ctx context.Context, date string, inputTokens, outputTokens int64, cost float64) (*models.AgentUsage, error) {
+	result, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: models.AgentUsageID(r.stage, date)},
+		},
+		UpdateExpression: aws.String("SET #date = :date, last_updated = :now ADD total_cost :cost, request_count :one, input_tokens :input, output_tokens :output"),
+		ExpressionAttributeNames: map[string]string{
+			"#date": "date",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":date":   &types.AttributeValueMemberS{Value: date},
+			":now":    &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+			":cost":   &types.AttributeValueMemberN{Value: strconv.FormatFloat(cost, 'f', -1, 64)},
+			":one":    &types.AttributeValueMemberN{Value: "1"},
+			":input":  &types.AttributeValueMemberN{Value: strconv.FormatInt(inputTokens, 10)},
+			":output": &types.AttributeValueMemberN{Value: strconv.FormatInt(outputTokens, 10)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add agent usage: %w", err)
+	}
+
+	var usage models.AgentUsage
+	if err := attributevalue.UnmarshalMap(result.Attributes, &usage); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal agent usage: %w", err)
+	}
+	return &usage, nil
+}
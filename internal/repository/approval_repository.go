@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// ApprovalRepository defines the interface for booking approval persistence
+type ApprovalRepository interface {
+	// SaveApproval saves a new booking approval
+	SaveApproval(ctx context.Context, approval *models.BookingApproval) error
+
+	// GetApproval retrieves a booking approval by token
+	GetApproval(ctx context.Context, id string) (*models.BookingApproval, error)
+
+	// TransitionApproval moves an approval from one status to another, failing if it is no longer in the from status
+	TransitionApproval(ctx context.Context, id string, from, to models.ApprovalStatus) error
+
+	// RecordRSVP saves a group member's answer, failing if the approval or the member's RSVP is no longer pending
+	RecordRSVP(ctx context.Context, id string, index int, status models.RSVPStatus) error
+}
+
+// DynamoDBApprovalRepository implements ApprovalRepository using DynamoDB
+type DynamoDBApprovalRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBApprovalRepository creates a new approval repository
+func NewDynamoDBApprovalRepository(client *dynamodb.Client, tableName string) *DynamoDBApprovalRepository {
+	return &DynamoDBApprovalRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// SaveApproval saves a new booking approval
+func (r *DynamoDBApprovalRepository) SaveApproval(ctx context.Context, approval *models.BookingApproval) error {
+	item, err := attributevalue.MarshalMap(approval)
+	if err != nil {
+		return fmt.Errorf("failed to marshal booking approval: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	}
+
+	_, err = r.client.PutItem(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to save booking approval: %w", err)
+	}
+
+	return nil
+}
+
+// GetApproval retrieves a booking approval by token
+func (r *DynamoDBApprovalRepository) GetApproval(ctx context.Context, id string) (*models.BookingApproval, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	}
+
+	result, err := r.client.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking approval: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, fmt.Errorf("booking approval not found: %s", id)
+	}
+
+	var approval models.BookingApproval
+	if err := attributevalue.UnmarshalMap(result.Item, &approval); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal booking approval: %w", err)
+	}
+
+	return &approval, nil
+}
+
+// TransitionApproval moves an approval from one status to another, failing if it is no longer in the from status
+func (r *DynamoDBApprovalRepository) TransitionApproval(ctx context.Context, id string, from, to models.ApprovalStatus) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String("SET #status = :to, decided_at = :decided_at"),
+		ConditionExpression: aws.String("#status = :from"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":from":       &types.AttributeValueMemberS{Value: string(from)},
+			":to":         &types.AttributeValueMemberS{Value: string(to)},
+			":decided_at": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+	}
+
+	_, err := r.client.UpdateItem(ctx, input)
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("booking approval %s is no longer %s", id, from)
+		}
+		return fmt.Errorf("failed to update booking approval: %w", err)
+	}
+
+	return nil
+}
+
+// RecordRSVP saves a group member's answer, failing if the approval or the member's RSVP is no longer pending
+func (r *DynamoDBApprovalRepository) RecordRSVP(ctx context.Context, id string, index int, status models.RSVPStatus) error {
+	rsvp := fmt.Sprintf("rsvps[%d]", index)
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String(fmt.Sprintf("SET %s.#status = :to, %s.responded_at = :responded_at", rsvp, rsvp)),
+		ConditionExpression: aws.String(fmt.Sprintf("#status = :approval_pending AND %s.#status = :rsvp_pending", rsvp)),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":approval_pending": &types.AttributeValueMemberS{Value: string(models.ApprovalStatusPending)},
+			":rsvp_pending":     &types.AttributeValueMemberS{Value: string(models.RSVPStatusPending)},
+			":to":               &types.AttributeValueMemberS{Value: string(status)},
+			":responded_at":     &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+	}
+
+	_, err := r.client.UpdateItem(ctx, input)
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("booking approval %s is no longer waiting for this rsvp", id)
+		}
+		return fmt.Errorf("failed to record rsvp: %w", err)
+	}
+
+	return nil
+}
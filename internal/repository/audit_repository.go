@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// AuditRepository defines the interface for audit log persistence
+type AuditRepository interface {
+	// SaveEntry appends an entry to the audit log
+	SaveEntry(ctx context.Context, entry *models.AuditEntry) error
+
+	// ListEntriesByUser lists a user's audit entries, oldest first
+	ListEntriesByUser(ctx context.Context, userID string) ([]*models.AuditEntry, error)
+
+	// ListEntries lists the audit entries recorded on or after since, oldest first
+	ListEntries(ctx context.Context, since time.Time) ([]*models.AuditEntry, error)
+}
+
+// DynamoDBAuditRepository implements AuditRepository using DynamoDB
+type DynamoDBAuditRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBAuditRepository creates a new audit repository
+func NewDynamoDBAuditRepository(client *dynamodb.Client, tableName string) *DynamoDBAuditRepository {
+	return &DynamoDBAuditRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// SaveEntry appends an entry to the audit log; entries are never overwritten
+func (r *DynamoDBAuditRepository) SaveEntry(ctx context.Context, entry *models.AuditEntry) error {
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListEntriesByUser lists a user's audit entries, oldest first
+func (r *DynamoDBAuditRepository) ListEntriesByUser(ctx context.Context, userID string) ([]*models.AuditEntry, error) {
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("user_id-created_at-index"),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+
+	entries := make([]*models.AuditEntry, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query audit entries: %w", err)
+		}
+		for _, item := range page.Items {
+			var entry models.AuditEntry
+			if err := attributevalue.UnmarshalMap(item, &entry); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal audit entry: %w", err)
+			}
+			entries = append(entries, &entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// ListEntries lists the audit entries recorded on or after since, oldest first
+func (r *DynamoDBAuditRepository) ListEntries(ctx context.Context, since time.Time) ([]*models.AuditEntry, error) {
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("created_at >= :since"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":since": &types.AttributeValueMemberS{Value: since.UTC().Format(time.RFC3339)},
+		},
+	})
+
+	entries := make([]*models.AuditEntry, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entries: %w", err)
+		}
+		for _, item := range page.Items {
+			var entry models.AuditEntry
+			if err := attributevalue.UnmarshalMap(item, &entry); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal audit entry: %w", err)
+			}
+			entries = append(entries, &entry)
+		}
+	}
+
+	sortByCreated(entries, func(e *models.AuditEntry) (time.Time, string) { return e.CreatedAt, e.ID })
+	return entries, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// BookingLedgerRepository defines the interface for the booking ledger, which allows one
+// reservation per golfer, course and date
+type BookingLedgerRepository interface {
+	// ClaimBooking records a reservation in progress, failing with ErrValidation if the golfer
+	// already has a booking or an unexpired claim at the course on that date
+	ClaimBooking(ctx context.Context, entry *models.BookingLedgerEntry) error
+
+	// ConfirmBooking marks a claim as booked with the reservation it produced
+	ConfirmBooking(ctx context.Context, id string, reservationID int) error
+
+	// ReleaseBooking removes a claim whose reservation was rejected, so it can be tried again
+	ReleaseBooking(ctx context.Context, id string) error
+}
+
+// claimConflict is the error for a booking the ledger already holds
+func claimConflict(entry *models.BookingLedgerEntry) error {
+	return apperrors.Newf(apperrors.ErrValidation, "golfer already has a booking at course %d on %s", entry.CourseID, entry.Date)
+}
+
+// DynamoDBBookingLedgerRepository implements BookingLedgerRepository using DynamoDB, keyed by id
+type DynamoDBBookingLedgerRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBBookingLedgerRepository creates a new booking ledger repository
+func NewDynamoDBBookingLedgerRepository(client *dynamodb.Client, tableName string) *DynamoDBBookingLedgerRepository {
+	return &DynamoDBBookingLedgerRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// ClaimBooking records a reservation in progress with a conditional write, so of two concurrent
+// claims for the same golfer, course and date only one succeeds. An abandoned claim is replaced.
+func (r *DynamoDBBookingLedgerRepository) ClaimBooking(ctx context.Context, entry *models.BookingLedgerEntry) error {
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal booking ledger entry: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id) OR (#status = :claimed AND expires_at <= :now)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":claimed": &types.AttributeValueMemberS{Value: string(models.BookingLedgerStatusClaimed)},
+			":now":     &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return claimConflict(entry)
+		}
+		return fmt.Errorf("failed to claim booking: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmBooking marks a claim as booked with the reservation it produced
+func (r *DynamoDBBookingLedgerRepository) ConfirmBooking(ctx context.Context, id string, reservationID int) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression: aws.String("SET #status = :booked, reservation_id = :reservation_id"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":booked":         &types.AttributeValueMemberS{Value: string(models.BookingLedgerStatusBooked)},
+			":reservation_id": &types.AttributeValueMemberN{Value: strconv.Itoa(reservationID)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to confirm booking: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseBooking removes a claim whose reservation was rejected; a booked entry is never removed
+func (r *DynamoDBBookingLedgerRepository) ReleaseBooking(ctx context.Context, id string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		ConditionExpression: aws.String("#status = :claimed"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":claimed": &types.AttributeValueMemberS{Value: string(models.BookingLedgerStatusClaimed)},
+		},
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("booking ledger entry %s is no longer claimed", id)
+		}
+		return fmt.Errorf("failed to release booking: %w", err)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,6 +10,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/logging"
 	"github.com/jrzesz33/rez_agent/internal/models"
 )
 
@@ -17,9 +20,33 @@ type MessageRepository interface {
 	SaveMessage(ctx context.Context, message *models.Message) error
 	GetMessage(ctx context.Context, id string) (*models.Message, error)
 	ListMessages(ctx context.Context, stage *models.Stage, status *models.Status, limit int) ([]*models.Message, error)
+	QueryMessages(ctx context.Context, query MessageQuery, page IndexPage) ([]*models.Message, map[string]string, error)
 	UpdateStatus(ctx context.Context, id string, status models.Status, errorMessage string) error
+	TransitionStatus(ctx context.Context, id string, from, to models.Status) error
+	ListMessagesByStatus(ctx context.Context, status models.Status, createdBefore time.Time, limit int) ([]*models.Message, error)
+	RequeueMessage(ctx context.Context, id string, from models.Status) error
+	FailMessage(ctx context.Context, id string, from models.Status, errorMessage string) error
+	ListMessagesByCreator(ctx context.Context, createdBy string) ([]*models.Message, error)
+	DeleteMessage(ctx context.Context, id string) error
 }
 
+// MessageQuery selects messages by creator or by stage, with optional exact-match filters.
+// QueryMessages reads them from the creator's, the status's or the stage's created date index.
+type MessageQuery struct {
+	// CreatedBy lists one creator's messages
+	CreatedBy string
+
+	// Stage lists one stage's messages; it is required without CreatedBy
+	Stage models.Stage
+
+	Status        models.Status
+	MessageType   models.MessageType
+	CorrelationID string
+}
+
+// ErrStatusChanged is returned by TransitionStatus when the message is no longer in the expected status
+var ErrStatusChanged = errors.New("message status changed")
+
 // DynamoDBRepository implements MessageRepository using DynamoDB
 type DynamoDBRepository struct {
 	client    *dynamodb.Client
@@ -34,8 +61,11 @@ func NewDynamoDBRepository(client *dynamodb.Client, tableName string) *DynamoDBR
 	}
 }
 
-// SaveMessage saves a message to DynamoDB
+// SaveMessage saves a message to DynamoDB, taking the context's correlation ID when it has none
 func (r *DynamoDBRepository) SaveMessage(ctx context.Context, message *models.Message) error {
+	if message.CorrelationID == "" {
+		message.CorrelationID = logging.CorrelationID(ctx)
+	}
 	av, err := attributevalue.MarshalMap(message)
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
@@ -69,7 +99,7 @@ func (r *DynamoDBRepository) GetMessage(ctx context.Context, id string) (*models
 	}
 
 	if result.Item == nil {
-		return nil, fmt.Errorf("message not found: %s", id)
+		return nil, apperrors.Newf(apperrors.ErrNotFound, "message not found: %s", id)
 	}
 
 	var message models.Message
@@ -137,6 +167,54 @@ func (r *DynamoDBRepository) ListMessages(ctx context.Context, stage *models.Sta
 	return messages, nil
 }
 
+// QueryMessages reads a page of messages in created date order from the index that narrows the
+// query most, filtering on the query's other fields
+func (r *DynamoDBRepository) QueryMessages(ctx context.Context, query MessageQuery, page IndexPage) ([]*models.Message, map[string]string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:                aws.String(r.tableName),
+		KeyConditionExpression:   aws.String("#key = :key"),
+		ExpressionAttributeNames: map[string]string{},
+	}
+	filters := map[string]string{
+		"message_type":   string(query.MessageType),
+		"correlation_id": query.CorrelationID,
+	}
+	var key, value string
+	switch {
+	case query.CreatedBy != "":
+		key, value = "created_by", query.CreatedBy
+		filters["stage"] = string(query.Stage)
+		filters["status"] = string(query.Status)
+	case query.Status != "":
+		key, value = "status", string(query.Status)
+		filters["stage"] = string(query.Stage)
+	case query.Stage != "":
+		key, value = "stage", string(query.Stage)
+	default:
+		return nil, nil, apperrors.Newf(apperrors.ErrValidation, "a message query needs a creator or a stage")
+	}
+	input.IndexName = aws.String(key + "-created_date-index")
+	input.ExpressionAttributeNames["#key"] = key
+	input.ExpressionAttributeValues = map[string]types.AttributeValue{
+		":key": &types.AttributeValueMemberS{Value: value},
+	}
+	addEqualityFilters(input, filters)
+
+	items, next, err := queryIndexPage(ctx, r.client, input, page)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	messages := make([]*models.Message, 0, len(items))
+	for _, item := range items {
+		var message models.Message
+		if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		messages = append(messages, &message)
+	}
+	return messages, next, nil
+}
+
 // UpdateStatus updates the status of a message in DynamoDB
 func (r *DynamoDBRepository) UpdateStatus(ctx context.Context, id string, status models.Status, errorMessage string) error {
 	updateExpression := "SET #status = :status, updated_date = :updated_date"
@@ -174,3 +252,151 @@ func (r *DynamoDBRepository) UpdateStatus(ctx context.Context, id string, status
 
 	return nil
 }
+
+// TransitionStatus moves a message from one status to another, failing with ErrStatusChanged if
+// it is no longer in from, so a late writer cannot undo progress a consumer has already made
+func (r *DynamoDBRepository) TransitionStatus(ctx context.Context, id string, from, to models.Status) error {
+	return r.conditionalUpdate(ctx, id, from,
+		"SET #status = :to, updated_date = :updated_date",
+		map[string]types.AttributeValue{
+			":to": &types.AttributeValueMemberS{Value: to.String()},
+		})
+}
+
+// ListMessagesByStatus retrieves up to limit messages (default 100) in status that were created
+// before createdBefore, oldest first, from the status-created_date-index
+func (r *DynamoDBRepository) ListMessagesByStatus(ctx context.Context, status models.Status, createdBefore time.Time, limit int) ([]*models.Message, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("status-created_date-index"),
+		KeyConditionExpression: aws.String("#status = :status AND created_date < :before"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: status.String()},
+			":before": &types.AttributeValueMemberS{Value: createdBefore.UTC().Format(time.RFC3339Nano)},
+		},
+		ScanIndexForward: aws.Bool(true),
+	})
+
+	messages := make([]*models.Message, 0)
+	for paginator.HasMorePages() && len(messages) < limit {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query messages by status: %w", err)
+		}
+		for _, item := range page.Items {
+			var message models.Message
+			if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+			}
+			messages = append(messages, &message)
+		}
+	}
+
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+// RequeueMessage marks a message queued, counting a retry and clearing its error, failing with
+// ErrStatusChanged if it is no longer in from
+func (r *DynamoDBRepository) RequeueMessage(ctx context.Context, id string, from models.Status) error {
+	return r.conditionalUpdate(ctx, id, from,
+		"SET #status = :to, updated_date = :updated_date ADD retry_count :one REMOVE error_message",
+		map[string]types.AttributeValue{
+			":to":  &types.AttributeValueMemberS{Value: models.StatusQueued.String()},
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		})
+}
+
+// FailMessage marks a message failed with errorMessage, failing with ErrStatusChanged if it is no
+// longer in from
+func (r *DynamoDBRepository) FailMessage(ctx context.Context, id string, from models.Status, errorMessage string) error {
+	return r.conditionalUpdate(ctx, id, from,
+		"SET #status = :to, updated_date = :updated_date, error_message = :error_message",
+		map[string]types.AttributeValue{
+			":to":            &types.AttributeValueMemberS{Value: models.StatusFailed.String()},
+			":error_message": &types.AttributeValueMemberS{Value: errorMessage},
+		})
+}
+
+// conditionalUpdate applies updateExpression to a message that is still in from. The expression
+// may use #status and :updated_date alongside its own values.
+func (r *DynamoDBRepository) conditionalUpdate(ctx context.Context, id string, from models.Status, updateExpression string, values map[string]types.AttributeValue) error {
+	values[":from"] = &types.AttributeValueMemberS{Value: from.String()}
+	values[":updated_date"] = &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)}
+
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String(updateExpression),
+		ConditionExpression: aws.String("#status = :from"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("%w: message %s is no longer %s", ErrStatusChanged, id, from)
+		}
+		return fmt.Errorf("failed to update message in DynamoDB: %w", err)
+	}
+
+	return nil
+}
+
+// ListMessagesByCreator retrieves every message created by a user or system, oldest first, from
+// the created_by-created_date-index
+func (r *DynamoDBRepository) ListMessagesByCreator(ctx context.Context, createdBy string) ([]*models.Message, error) {
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("created_by-created_date-index"),
+		KeyConditionExpression: aws.String("created_by = :created_by"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":created_by": &types.AttributeValueMemberS{Value: createdBy},
+		},
+		ScanIndexForward: aws.Bool(true),
+	})
+
+	messages := make([]*models.Message, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query messages by creator: %w", err)
+		}
+		for _, item := range page.Items {
+			var message models.Message
+			if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+			}
+			messages = append(messages, &message)
+		}
+	}
+
+	return messages, nil
+}
+
+// DeleteMessage permanently removes a message from DynamoDB
+func (r *DynamoDBRepository) DeleteMessage(ctx context.Context, id string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// DynamoDBHandlerConfigRepository reads per-stage web action handler configs
+type DynamoDBHandlerConfigRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBHandlerConfigRepository creates a new handler config repository
+func NewDynamoDBHandlerConfigRepository(client *dynamodb.Client, tableName string) *DynamoDBHandlerConfigRepository {
+	return &DynamoDBHandlerConfigRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// ListHandlerConfigs returns every handler config in the table
+func (r *DynamoDBHandlerConfigRepository) ListHandlerConfigs(ctx context.Context) ([]*models.WebActionHandlerConfig, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(r.tableName),
+	}
+
+	configs := make([]*models.WebActionHandlerConfig, 0)
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan handler configs: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var config models.WebActionHandlerConfig
+			if err := attributevalue.UnmarshalMap(item, &config); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal handler config: %w", err)
+			}
+			configs = append(configs, &config)
+		}
+	}
+
+	return configs, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// IndexPage asks an index query for one page of items ordered by their index's sort key
+type IndexPage struct {
+	// Limit is the most items returned (default 100)
+	Limit int
+
+	// Descending lists the newest items first
+	Descending bool
+
+	// Cursor resumes after the key the previous page stopped at; nil starts at the beginning
+	Cursor map[string]string
+}
+
+// limit is the page size, defaulted
+func (p IndexPage) limit() int {
+	if p.Limit <= 0 {
+		return 100
+	}
+	return p.Limit
+}
+
+// queryIndexPage runs a query until it has a page of items or reaches the end of the index.
+// DynamoDB applies a filter expression after its Limit, so a filtered page can take several
+// requests. It returns the key the query stopped at, which resumes the next page, or nil at the
+// end.
+func queryIndexPage(ctx context.Context, client *dynamodb.Client, input *dynamodb.QueryInput, page IndexPage) ([]map[string]types.AttributeValue, map[string]string, error) {
+	limit := page.limit()
+	input.ScanIndexForward = aws.Bool(!page.Descending)
+	input.ExclusiveStartKey = keyFromCursor(page.Cursor)
+
+	items := make([]map[string]types.AttributeValue, 0, limit)
+	for {
+		input.Limit = aws.Int32(int32(limit - len(items)))
+		result, err := client.Query(ctx, input)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, result.Items...)
+		if result.LastEvaluatedKey == nil {
+			return items, nil, nil
+		}
+		if len(items) >= limit {
+			cursor, err := cursorFromKey(result.LastEvaluatedKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			return items, cursor, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// addEqualityFilters narrows a query to items whose attributes equal the non-empty values
+func addEqualityFilters(input *dynamodb.QueryInput, filters map[string]string) {
+	attributes := make([]string, 0, len(filters))
+	for attribute, value := range filters {
+		if value != "" {
+			attributes = append(attributes, attribute)
+		}
+	}
+	sort.Strings(attributes)
+
+	expression := aws.ToString(input.FilterExpression)
+	for _, attribute := range attributes {
+		if input.ExpressionAttributeNames == nil {
+			input.ExpressionAttributeNames = make(map[string]string)
+		}
+		if input.ExpressionAttributeValues == nil {
+			input.ExpressionAttributeValues = make(map[string]types.AttributeValue)
+		}
+		input.ExpressionAttributeNames["#f_"+attribute] = attribute
+		input.ExpressionAttributeValues[":f_"+attribute] = &types.AttributeValueMemberS{Value: filters[attribute]}
+		if expression != "" {
+			expression += " AND "
+		}
+		expression += fmt.Sprintf("#f_%s = :f_%s", attribute, attribute)
+	}
+	if expression != "" {
+		input.FilterExpression = aws.String(expression)
+	}
+}
+
+// keyFromCursor is the ExclusiveStartKey of a page's cursor. The tables' keys are all strings.
+func keyFromCursor(cursor map[string]string) map[string]types.AttributeValue {
+	if len(cursor) == 0 {
+		return nil
+	}
+	key := make(map[string]types.AttributeValue, len(cursor))
+	for attribute, value := range cursor {
+		key[attribute] = &types.AttributeValueMemberS{Value: value}
+	}
+	return key
+}
+
+// cursorFromKey is the cursor of a query's LastEvaluatedKey
+func cursorFromKey(key map[string]types.AttributeValue) (map[string]string, error) {
+	cursor := make(map[string]string, len(key))
+	for attribute, value := range key {
+		s, ok := value.(*types.AttributeValueMemberS)
+		if !ok {
+			return nil, fmt.Errorf("key attribute %s is not a string", attribute)
+		}
+		cursor[attribute] = s.Value
+	}
+	return cursor, nil
+}
+
+// memoryIndexPage pages items the way an index on their created date (then ID) does, for the
+// in-memory repositories. Its cursors hold the created date and ID of the last item returned.
+func memoryIndexPage[T any](items []*T, key func(*T) (time.Time, string), page IndexPage) ([]*T, map[string]string) {
+	sortByCreated(items, key)
+	if page.Descending {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	start := 0
+	if page.Cursor != nil {
+		after, _ := time.Parse(time.RFC3339Nano, page.Cursor["created_date"])
+		afterID := page.Cursor["id"]
+		start = sort.Search(len(items), func(i int) bool {
+			created, id := key(items[i])
+			if page.Descending {
+				return created.Before(after) || (created.Equal(after) && id < afterID)
+			}
+			return created.After(after) || (created.Equal(after) && id > afterID)
+		})
+	}
+
+	end := min(start+page.limit(), len(items))
+	if end == len(items) {
+		return items[start:end], nil
+	}
+	created, id := key(items[end-1])
+	return items[start:end], map[string]string{"created_date": created.UTC().Format(time.RFC3339Nano), "id": id}
+}
@@ -0,0 +1,862 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// memoryTable holds items by ID the way DynamoDB does: items are stored in their marshaled form,
+// so callers never share memory with the table and only what DynamoDB would persist survives
+type memoryTable[T any] struct {
+	mu    sync.RWMutex
+	items map[string]map[string]types.AttributeValue
+}
+
+func newMemoryTable[T any]() *memoryTable[T] {
+	return &memoryTable[T]{items: make(map[string]map[string]types.AttributeValue)}
+}
+
+// put stores item under id, failing with a conditional check error when mustNotExist is set and
+// the id is taken
+func (t *memoryTable[T]) put(id string, item *T, mustNotExist bool) error {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, exists := t.items[id]; exists && mustNotExist {
+		return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	t.items[id] = av
+	return nil
+}
+
+// putIf stores item under id when allow accepts the item already stored there (nil when there is
+// none), failing with a conditional check error otherwise, like PutItem with a condition
+func (t *memoryTable[T]) putIf(id string, item *T, allow func(existing *T) bool) error {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	existing, err := t.unmarshal(id)
+	if err != nil {
+		return err
+	}
+	if !allow(existing) {
+		return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	t.items[id] = av
+	return nil
+}
+
+// get returns a copy of the item stored under id, or nil
+func (t *memoryTable[T]) get(id string) (*T, error) {
+	t.mu.RLock()
+	av, ok := t.items[id]
+	t.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+
+	var item T
+	if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// update applies fn to a copy of the item under id and stores the result. Like UpdateItem, a
+// missing item is created, starting from the zero value.
+func (t *memoryTable[T]) update(id string, fn func(item *T)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var item T
+	if av, ok := t.items[id]; ok {
+		if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+			return err
+		}
+	}
+	fn(&item)
+
+	av, err := attributevalue.MarshalMap(&item)
+	if err != nil {
+		return err
+	}
+	t.items[id] = av
+	return nil
+}
+
+// delete removes the item under id; deleting a missing item is not an error
+func (t *memoryTable[T]) delete(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.items, id)
+}
+
+// deleteIf removes the item under id when allow accepts it, failing with a conditional check
+// error otherwise, like DeleteItem with a condition
+func (t *memoryTable[T]) deleteIf(id string, allow func(existing *T) bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	existing, err := t.unmarshal(id)
+	if err != nil {
+		return err
+	}
+	if !allow(existing) {
+		return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	delete(t.items, id)
+	return nil
+}
+
+// unmarshal returns a copy of the item under id, or nil; the caller holds the lock
+func (t *memoryTable[T]) unmarshal(id string) (*T, error) {
+	av, ok := t.items[id]
+	if !ok {
+		return nil, nil
+	}
+	var item T
+	if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// scan returns copies of the items matching keep
+func (t *memoryTable[T]) scan(keep func(item *T) bool) ([]*T, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	items := make([]*T, 0)
+	for _, av := range t.items {
+		var item T
+		if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+			return nil, err
+		}
+		if keep(&item) {
+			items = append(items, &item)
+		}
+	}
+	return items, nil
+}
+
+// MemoryRepository implements MessageRepository in memory, for tests and local runs
+type MemoryRepository struct {
+	table *memoryTable[models.Message]
+}
+
+// NewMemoryRepository creates an empty in-memory message repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{table: newMemoryTable[models.Message]()}
+}
+
+// SaveMessage saves a message, replacing any message with the same ID and taking the context's
+// correlation ID when it has none
+func (r *MemoryRepository) SaveMessage(ctx context.Context, message *models.Message) error {
+	if message.CorrelationID == "" {
+		message.CorrelationID = logging.CorrelationID(ctx)
+	}
+	if err := r.table.put(message.ID, message, false); err != nil {
+		return fmt.Errorf("failed to save message: %w", err)
+	}
+	return nil
+}
+
+// GetMessage retrieves a message by ID
+func (r *MemoryRepository) GetMessage(ctx context.Context, id string) (*models.Message, error) {
+	message, err := r.table.get(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	if message == nil {
+		return nil, apperrors.Newf(apperrors.ErrNotFound, "message not found: %s", id)
+	}
+	return message, nil
+}
+
+// ListMessages retrieves up to limit messages (default 100) with optional filtering by stage and
+// status, oldest first
+func (r *MemoryRepository) ListMessages(ctx context.Context, stage *models.Stage, status *models.Status, limit int) ([]*models.Message, error) {
+	messages, err := r.table.scan(func(message *models.Message) bool {
+		return (stage == nil || message.Stage == *stage) && (status == nil || message.Status == *status)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	sortByCreated(messages, func(m *models.Message) (time.Time, string) { return m.CreatedDate, m.ID })
+	if limit <= 0 {
+		limit = 100
+	}
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+// QueryMessages reads a page of messages in created date order, as from an index
+func (r *MemoryRepository) QueryMessages(ctx context.Context, query MessageQuery, page IndexPage) ([]*models.Message, map[string]string, error) {
+	if query.CreatedBy == "" && query.Stage == "" {
+		return nil, nil, apperrors.Newf(apperrors.ErrValidation, "a message query needs a creator or a stage")
+	}
+	messages, err := r.table.scan(func(message *models.Message) bool {
+		return (query.CreatedBy == "" || message.CreatedBy == query.CreatedBy) &&
+			(query.Stage == "" || message.Stage == query.Stage) &&
+			(query.Status == "" || message.Status == query.Status) &&
+			(query.MessageType == "" || message.MessageType == query.MessageType) &&
+			(query.CorrelationID == "" || message.CorrelationID == query.CorrelationID)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	messages, next := memoryIndexPage(messages, func(m *models.Message) (time.Time, string) { return m.CreatedDate, m.ID }, page)
+	return messages, next, nil
+}
+
+// UpdateStatus updates the status of a message, creating a bare message when it does not exist
+// as DynamoDB's UpdateItem does
+func (r *MemoryRepository) UpdateStatus(ctx context.Context, id string, status models.Status, errorMessage string) error {
+	err := r.table.update(id, func(message *models.Message) {
+		message.ID = id
+		message.Status = status
+		message.UpdatedDate = time.Now()
+		if errorMessage != "" {
+			message.ErrorMessage = errorMessage
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update message status: %w", err)
+	}
+	return nil
+}
+
+// TransitionStatus moves a message from one status to another, failing with ErrStatusChanged if
+// it is no longer in from
+func (r *MemoryRepository) TransitionStatus(ctx context.Context, id string, from, to models.Status) error {
+	return r.conditionalUpdate(id, from, func(message *models.Message) {
+		message.Status = to
+		message.UpdatedDate = time.Now()
+	})
+}
+
+// ListMessagesByStatus retrieves up to limit messages (default 100) in status that were created
+// before createdBefore, oldest first like the status-created_date-index
+func (r *MemoryRepository) ListMessagesByStatus(ctx context.Context, status models.Status, createdBefore time.Time, limit int) ([]*models.Message, error) {
+	messages, err := r.table.scan(func(message *models.Message) bool {
+		return message.Status == status && message.CreatedDate.Before(createdBefore)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	sortByCreated(messages, func(m *models.Message) (time.Time, string) { return m.CreatedDate, m.ID })
+	if limit <= 0 {
+		limit = 100
+	}
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+// RequeueMessage marks a message queued, counting a retry and clearing its error, failing with
+// ErrStatusChanged if it is no longer in from
+func (r *MemoryRepository) RequeueMessage(ctx context.Context, id string, from models.Status) error {
+	return r.conditionalUpdate(id, from, func(message *models.Message) {
+		message.Requeue()
+	})
+}
+
+// FailMessage marks a message failed with errorMessage, failing with ErrStatusChanged if it is no
+// longer in from
+func (r *MemoryRepository) FailMessage(ctx context.Context, id string, from models.Status, errorMessage string) error {
+	return r.conditionalUpdate(id, from, func(message *models.Message) {
+		message.MarkFailed(errorMessage)
+	})
+}
+
+// conditionalUpdate applies fn to a message that is still in from
+func (r *MemoryRepository) conditionalUpdate(id string, from models.Status, fn func(message *models.Message)) error {
+	message, err := r.table.get(id)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	if message == nil {
+		return fmt.Errorf("%w: message %s is no longer %s", ErrStatusChanged, id, from)
+	}
+	fn(message)
+
+	err = r.table.putIf(id, message, func(existing *models.Message) bool {
+		return existing != nil && existing.Status == from
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("%w: message %s is no longer %s", ErrStatusChanged, id, from)
+		}
+		return fmt.Errorf("failed to update message: %w", err)
+	}
+	return nil
+}
+
+// ListMessagesByCreator retrieves every message created by a user or system
+func (r *MemoryRepository) ListMessagesByCreator(ctx context.Context, createdBy string) ([]*models.Message, error) {
+	messages, err := r.table.scan(func(message *models.Message) bool {
+		return message.CreatedBy == createdBy
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	sortByCreated(messages, func(m *models.Message) (time.Time, string) { return m.CreatedDate, m.ID })
+	return messages, nil
+}
+
+// DeleteMessage permanently removes a message
+func (r *MemoryRepository) DeleteMessage(ctx context.Context, id string) error {
+	r.table.delete(id)
+	return nil
+}
+
+// MemoryScheduleRepository implements ScheduleRepository in memory, for tests and local runs
+type MemoryScheduleRepository struct {
+	table *memoryTable[models.Schedule]
+}
+
+// NewMemoryScheduleRepository creates an empty in-memory schedule repository
+func NewMemoryScheduleRepository() *MemoryScheduleRepository {
+	return &MemoryScheduleRepository{table: newMemoryTable[models.Schedule]()}
+}
+
+// SaveSchedule saves a new schedule, failing with a conditional check error if the ID is taken
+func (r *MemoryScheduleRepository) SaveSchedule(ctx context.Context, schedule *models.Schedule) error {
+	if err := r.table.put(schedule.ID, schedule, true); err != nil {
+		return fmt.Errorf("failed to save schedule: %w", err)
+	}
+	return nil
+}
+
+// GetSchedule retrieves a schedule by ID
+func (r *MemoryScheduleRepository) GetSchedule(ctx context.Context, id string) (*models.Schedule, error) {
+	schedule, err := r.table.get(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schedule: %w", err)
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule not found: %s", id)
+	}
+	return schedule, nil
+}
+
+// UpdateSchedule updates an existing schedule
+func (r *MemoryScheduleRepository) UpdateSchedule(ctx context.Context, schedule *models.Schedule) error {
+	if err := r.table.put(schedule.ID, schedule, false); err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return nil
+}
+
+// UpdateScheduleStatus updates only the status of a schedule, creating a bare schedule when it
+// does not exist as DynamoDB's UpdateItem does
+func (r *MemoryScheduleRepository) UpdateScheduleStatus(ctx context.Context, id string, status models.ScheduleStatus, errorMessage string) error {
+	err := r.table.update(id, func(schedule *models.Schedule) {
+		schedule.ID = id
+		schedule.Status = status
+		schedule.UpdatedDate = time.Now().UTC().Truncate(time.Second)
+		if errorMessage != "" {
+			schedule.ErrorMessage = errorMessage
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update schedule status: %w", err)
+	}
+	return nil
+}
+
+// ListSchedulesByStatus lists schedules with a specific status, oldest first like the
+// status-created_date-index
+func (r *MemoryScheduleRepository) ListSchedulesByStatus(ctx context.Context, status models.ScheduleStatus) ([]*models.Schedule, error) {
+	schedules, err := r.table.scan(func(schedule *models.Schedule) bool {
+		return schedule.Status == status
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schedule: %w", err)
+	}
+	sortByCreated(schedules, func(s *models.Schedule) (time.Time, string) { return s.CreatedDate, s.ID })
+	return schedules, nil
+}
+
+// ListSchedulesByCreator lists schedules created by a specific user/system
+func (r *MemoryScheduleRepository) ListSchedulesByCreator(ctx context.Context, createdBy string) ([]*models.Schedule, error) {
+	schedules, err := r.table.scan(func(schedule *models.Schedule) bool {
+		return schedule.CreatedBy == createdBy
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schedule: %w", err)
+	}
+	sortByCreated(schedules, func(s *models.Schedule) (time.Time, string) { return s.CreatedDate, s.ID })
+	return schedules, nil
+}
+
+// DeleteSchedule marks a schedule as deleted
+func (r *MemoryScheduleRepository) DeleteSchedule(ctx context.Context, id string) error {
+	return r.UpdateScheduleStatus(ctx, id, models.ScheduleStatusDeleted, "")
+}
+
+// PurgeSchedule permanently removes a schedule, unlike DeleteSchedule which keeps it as deleted
+func (r *MemoryScheduleRepository) PurgeSchedule(ctx context.Context, id string) error {
+	r.table.delete(id)
+	return nil
+}
+
+// MemoryWebActionRepository implements WebActionResultRepository in memory, for tests and local runs
+type MemoryWebActionRepository struct {
+	table *memoryTable[models.WebActionResult]
+}
+
+// NewMemoryWebActionRepository creates an empty in-memory web action result repository
+func NewMemoryWebActionRepository() *MemoryWebActionRepository {
+	return &MemoryWebActionRepository{table: newMemoryTable[models.WebActionResult]()}
+}
+
+// SaveResult saves a web action result, replacing any result with the same ID and taking the
+// context's correlation ID when it has none
+func (r *MemoryWebActionRepository) SaveResult(ctx context.Context, result *models.WebActionResult) error {
+	if result.CorrelationID == "" {
+		result.CorrelationID = logging.CorrelationID(ctx)
+	}
+	if err := r.table.put(result.ID, result, false); err != nil {
+		return fmt.Errorf("failed to save web action result: %w", err)
+	}
+	return nil
+}
+
+// GetResult retrieves a web action result by ID
+func (r *MemoryWebActionRepository) GetResult(ctx context.Context, id string) (*models.WebActionResult, error) {
+	result, err := r.table.get(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal web action result: %w", err)
+	}
+	if result == nil {
+		return nil, apperrors.Newf(apperrors.ErrNotFound, "web action result not found: %s", id)
+	}
+	return result, nil
+}
+
+// GetResultByMessageID retrieves the oldest web action result recorded for a message
+func (r *MemoryWebActionRepository) GetResultByMessageID(ctx context.Context, messageID string) (*models.WebActionResult, error) {
+	results, err := r.ListResultsByMessageID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("web action result not found for message: %s", messageID)
+	}
+	return results[0], nil
+}
+
+// ListResultsByMessageID retrieves every result recorded for a message, oldest first
+func (r *MemoryWebActionRepository) ListResultsByMessageID(ctx context.Context, messageID string) ([]*models.WebActionResult, error) {
+	results, err := r.table.scan(func(result *models.WebActionResult) bool {
+		return result.MessageID == messageID
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal web action result: %w", err)
+	}
+	sortByCreated(results, func(w *models.WebActionResult) (time.Time, string) { return w.CreatedDate, w.ID })
+	return results, nil
+}
+
+// QueryResults reads a page of a stage's web action results in created date order
+func (r *MemoryWebActionRepository) QueryResults(ctx context.Context, query ResultQuery, page IndexPage) ([]*models.WebActionResult, map[string]string, error) {
+	results, err := r.table.scan(func(result *models.WebActionResult) bool {
+		return result.Stage == query.Stage &&
+			(query.Status == "" || result.Status == query.Status) &&
+			(query.Action == "" || result.Action == query.Action) &&
+			(query.CorrelationID == "" || result.CorrelationID == query.CorrelationID)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal web action result: %w", err)
+	}
+	results, next := memoryIndexPage(results, func(w *models.WebActionResult) (time.Time, string) { return w.CreatedDate, w.ID }, page)
+	return results, next, nil
+}
+
+// DeleteResult permanently removes a web action result
+func (r *MemoryWebActionRepository) DeleteResult(ctx context.Context, id string) error {
+	r.table.delete(id)
+	return nil
+}
+
+// MemoryUserRepository implements UserRepository in memory, for tests and local runs
+type MemoryUserRepository struct {
+	table *memoryTable[models.User]
+}
+
+// NewMemoryUserRepository creates an empty in-memory user repository
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{table: newMemoryTable[models.User]()}
+}
+
+// CreateUser saves a new user, failing with ErrUserExists if the ID is taken
+func (r *MemoryUserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	if err := r.table.put(user.ID, user, true); err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("%w: %s", ErrUserExists, user.ID)
+		}
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+	return nil
+}
+
+// GetUser retrieves a user by ID
+func (r *MemoryUserRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
+	user, err := r.table.get(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+	if user == nil {
+		return nil, apperrors.Newf(apperrors.ErrNotFound, "user not found: %s", id)
+	}
+	return user, nil
+}
+
+// GetUserByAPIKeyHash retrieves the user an API key belongs to
+func (r *MemoryUserRepository) GetUserByAPIKeyHash(ctx context.Context, apiKeyHash string) (*models.User, error) {
+	users, err := r.table.scan(func(user *models.User) bool {
+		return user.APIKeyHash == apiKeyHash
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, apperrors.Newf(apperrors.ErrNotFound, "no user has this API key")
+	}
+	return users[0], nil
+}
+
+// ListUsers returns every user, ordered by ID
+func (r *MemoryUserRepository) ListUsers(ctx context.Context) ([]*models.User, error) {
+	users, err := r.table.scan(func(user *models.User) bool { return true })
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// MemoryReservationRepository implements ReservationRepository in memory, for tests and local runs
+type MemoryReservationRepository struct {
+	table *memoryTable[models.ReservationSnapshot]
+}
+
+// NewMemoryReservationRepository creates an empty in-memory reservation repository
+func NewMemoryReservationRepository() *MemoryReservationRepository {
+	return &MemoryReservationRepository{table: newMemoryTable[models.ReservationSnapshot]()}
+}
+
+// reservationKey joins the table's two key attributes into one memory table ID
+func reservationKey(secretName string, courseID int) string {
+	return fmt.Sprintf("%s#%d", secretName, courseID)
+}
+
+// SaveSnapshot replaces a golfer's cached reservations at a course
+func (r *MemoryReservationRepository) SaveSnapshot(ctx context.Context, snapshot *models.ReservationSnapshot) error {
+	if err := r.table.put(reservationKey(snapshot.SecretName, snapshot.CourseID), snapshot, false); err != nil {
+		return fmt.Errorf("failed to save reservation snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetSnapshot retrieves a golfer's cached reservations at a course
+func (r *MemoryReservationRepository) GetSnapshot(ctx context.Context, secretName string, courseID int) (*models.ReservationSnapshot, error) {
+	snapshot, err := r.table.get(reservationKey(secretName, courseID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reservation snapshot: %w", err)
+	}
+	if snapshot == nil {
+		return nil, apperrors.Newf(apperrors.ErrNotFound, "no synced reservations for course %d", courseID)
+	}
+	return snapshot, nil
+}
+
+// ListSnapshots retrieves a golfer's cached reservations at every synced course, ordered by course ID
+func (r *MemoryReservationRepository) ListSnapshots(ctx context.Context, secretName string) ([]*models.ReservationSnapshot, error) {
+	snapshots, err := r.table.scan(func(snapshot *models.ReservationSnapshot) bool {
+		return snapshot.SecretName == secretName
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reservation snapshot: %w", err)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].CourseID < snapshots[j].CourseID })
+	return snapshots, nil
+}
+
+// MemoryBookingLedgerRepository implements BookingLedgerRepository in memory, for tests and local runs
+type MemoryBookingLedgerRepository struct {
+	table *memoryTable[models.BookingLedgerEntry]
+}
+
+// NewMemoryBookingLedgerRepository creates an empty in-memory booking ledger
+func NewMemoryBookingLedgerRepository() *MemoryBookingLedgerRepository {
+	return &MemoryBookingLedgerRepository{table: newMemoryTable[models.BookingLedgerEntry]()}
+}
+
+// ClaimBooking records a reservation in progress, failing with ErrValidation if the golfer
+// already has a booking or an unexpired claim at the course on that date
+func (r *MemoryBookingLedgerRepository) ClaimBooking(ctx context.Context, entry *models.BookingLedgerEntry) error {
+	err := r.table.putIf(entry.ID, entry, func(existing *models.BookingLedgerEntry) bool {
+		return existing == nil || existing.IsAbandoned(time.Now())
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return claimConflict(entry)
+		}
+		return fmt.Errorf("failed to claim booking: %w", err)
+	}
+	return nil
+}
+
+// ConfirmBooking marks a claim as booked with the reservation it produced
+func (r *MemoryBookingLedgerRepository) ConfirmBooking(ctx context.Context, id string, reservationID int) error {
+	if err := r.table.update(id, func(entry *models.BookingLedgerEntry) {
+		entry.ID = id
+		entry.Status = models.BookingLedgerStatusBooked
+		entry.ReservationID = reservationID
+	}); err != nil {
+		return fmt.Errorf("failed to confirm booking: %w", err)
+	}
+	return nil
+}
+
+// ReleaseBooking removes a claim whose reservation was rejected; a booked entry is never removed
+func (r *MemoryBookingLedgerRepository) ReleaseBooking(ctx context.Context, id string) error {
+	err := r.table.deleteIf(id, func(existing *models.BookingLedgerEntry) bool {
+		return existing != nil && existing.Status == models.BookingLedgerStatusClaimed
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("booking ledger entry %s is no longer claimed", id)
+		}
+		return fmt.Errorf("failed to release booking: %w", err)
+	}
+	return nil
+}
+
+// GetEntry retrieves a ledger entry by ID, or nil when there is none
+func (r *MemoryBookingLedgerRepository) GetEntry(id string) (*models.BookingLedgerEntry, error) {
+	return r.table.get(id)
+}
+
+// MemoryAgentSessionRepository implements AgentSessionRepository in memory, for tests and local runs
+type MemoryAgentSessionRepository struct {
+	table  *memoryTable[models.AgentSession]
+	window models.AgentSessionWindow
+}
+
+// NewMemoryAgentSessionRepository creates an empty in-memory session store with the given window
+func NewMemoryAgentSessionRepository(window models.AgentSessionWindow) *MemoryAgentSessionRepository {
+	return &MemoryAgentSessionRepository{table: newMemoryTable[models.AgentSession](), window: window}
+}
+
+// GetSession retrieves a session, returning nil when it does not exist or has expired
+func (r *MemoryAgentSessionRepository) GetSession(ctx context.Context, sessionID string) (*models.AgentSession, error) {
+	session, err := r.table.get(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal agent session: %w", err)
+	}
+	if session == nil || session.IsExpired(time.Now()) {
+		return nil, nil
+	}
+	return session, nil
+}
+
+// AppendMessages adds messages to a session, creating it if needed, and returns the stored session
+func (r *MemoryAgentSessionRepository) AppendMessages(ctx context.Context, sessionID string, messages []map[string]interface{}, approvedTools []string) (*models.AgentSession, error) {
+	var stored models.AgentSession
+	now := time.Now()
+	if err := r.table.update(sessionID, func(session *models.AgentSession) {
+		if session.SessionID == "" || session.IsExpired(now) {
+			*session = *models.NewAgentSession(sessionID, now)
+		}
+		session.Append(messages, approvedTools, r.window, now)
+		stored = *session
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save agent session: %w", err)
+	}
+	return &stored, nil
+}
+
+// TrimSession trims a stored session to the window, returning how many messages were dropped
+func (r *MemoryAgentSessionRepository) TrimSession(ctx context.Context, sessionID string) (int, error) {
+	session, err := r.GetSession(ctx, sessionID)
+	if err != nil || session == nil {
+		return 0, err
+	}
+	dropped := session.Trim(r.window)
+	if err := r.table.put(sessionID, session, false); err != nil {
+		return 0, fmt.Errorf("failed to save agent session: %w", err)
+	}
+	return dropped, nil
+}
+
+// ExpireSession sets when a session expires
+func (r *MemoryAgentSessionRepository) ExpireSession(ctx context.Context, sessionID string, at time.Time) error {
+	session, err := r.table.get(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal agent session: %w", err)
+	}
+	if session == nil {
+		return apperrors.Newf(apperrors.ErrNotFound, "agent session not found: %s", sessionID)
+	}
+	session.TTL = at.Unix()
+	if err := r.table.put(sessionID, session, false); err != nil {
+		return fmt.Errorf("failed to expire agent session: %w", err)
+	}
+	return nil
+}
+
+// DeleteSession permanently removes a session
+func (r *MemoryAgentSessionRepository) DeleteSession(ctx context.Context, sessionID string) error {
+	r.table.delete(sessionID)
+	return nil
+}
+
+// MemoryAgentTaskRepository implements AgentTaskRepository in memory, for tests and local runs
+type MemoryAgentTaskRepository struct {
+	table *memoryTable[models.AgentTaskCancellation]
+}
+
+// NewMemoryAgentTaskRepository creates an empty in-memory task repository
+func NewMemoryAgentTaskRepository() *MemoryAgentTaskRepository {
+	return &MemoryAgentTaskRepository{table: newMemoryTable[models.AgentTaskCancellation]()}
+}
+
+// CancelTask records that a task was cancelled
+func (r *MemoryAgentTaskRepository) CancelTask(ctx context.Context, taskID string) error {
+	cancellation := newAgentTaskCancellation(taskID, time.Now())
+	if err := r.table.put(cancellation.ID, cancellation, false); err != nil {
+		return fmt.Errorf("failed to cancel agent task: %w", err)
+	}
+	return nil
+}
+
+// IsTaskCancelled reports whether a task was cancelled
+func (r *MemoryAgentTaskRepository) IsTaskCancelled(ctx context.Context, taskID string) (bool, error) {
+	cancellation, err := r.table.get(models.AgentTaskCancellationID(taskID))
+	if err != nil {
+		return false, fmt.Errorf("failed to unmarshal agent task cancellation: %w", err)
+	}
+	return cancellation != nil, nil
+}
+
+// MemoryAgentUsageRepository implements AgentUsageRepository in memory, for tests and local runs
+type MemoryAgentUsageRepository struct {
+	table *memoryTable[models.AgentUsage]
+	stage string
+}
+
+// NewMemoryAgentUsageRepository creates an empty in-memory usage repository for stage
+func NewMemoryAgentUsageRepository(stage string) *MemoryAgentUsageRepository {
+	return &MemoryAgentUsageRepository{table: newMemoryTable[models.AgentUsage](), stage: stage}
+}
+
+// GetUsage returns the usage recorded on date
+func (r *MemoryAgentUsageRepository) GetUsage(ctx context.Context, date string) (*models.AgentUsage, error) {
+	id := models.AgentUsageID(r.stage, date)
+	usage, err := r.table.get(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal agent usage: %w", err)
+	}
+	if usage == nil {
+		usage = &models.AgentUsage{ID: id, Date: date}
+	}
+	return usage, nil
+}
+
+// AddUsage adds one request's tokens and cost to date's usage
+func (r *MemoryAgentUsageRepository) AddUsage(ctx context.Context, date string, inputTokens, outputTokens int64, cost float64) (*models.AgentUsage, error) {
+	var stored models.AgentUsage
+	id := models.AgentUsageID(r.stage, date)
+	if err := r.table.update(id, func(usage *models.AgentUsage) {
+		usage.ID = id
+		usage.Date = date
+		usage.TotalCost += cost
+		usage.RequestCount++
+		usage.InputTokens += inputTokens
+		usage.OutputTokens += outputTokens
+		usage.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+		stored = *usage
+	}); err != nil {
+		return nil, fmt.Errorf("failed to add agent usage: %w", err)
+	}
+	return &stored, nil
+}
+
+// MemoryAuditRepository implements AuditRepository in memory, for tests and local runs
+type MemoryAuditRepository struct {
+	table *memoryTable[models.AuditEntry]
+}
+
+// NewMemoryAuditRepository creates an empty in-memory audit repository
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{table: newMemoryTable[models.AuditEntry]()}
+}
+
+// SaveEntry appends an entry to the audit log, failing with a conditional check error if the ID is taken
+func (r *MemoryAuditRepository) SaveEntry(ctx context.Context, entry *models.AuditEntry) error {
+	if err := r.table.put(entry.ID, entry, true); err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListEntriesByUser lists a user's audit entries, oldest first
+func (r *MemoryAuditRepository) ListEntriesByUser(ctx context.Context, userID string) ([]*models.AuditEntry, error) {
+	return r.list(func(e *models.AuditEntry) bool { return e.UserID == userID })
+}
+
+// ListEntries lists the audit entries recorded on or after since, oldest first
+func (r *MemoryAuditRepository) ListEntries(ctx context.Context, since time.Time) ([]*models.AuditEntry, error) {
+	return r.list(func(e *models.AuditEntry) bool { return !e.CreatedAt.Before(since) })
+}
+
+func (r *MemoryAuditRepository) list(keep func(*models.AuditEntry) bool) ([]*models.AuditEntry, error) {
+	entries, err := r.table.scan(keep)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit entry: %w", err)
+	}
+	sortByCreated(entries, func(e *models.AuditEntry) (time.Time, string) { return e.CreatedAt, e.ID })
+	return entries, nil
+}
+
+// sortByCreated orders items oldest first, breaking ties by ID so listings are deterministic
+func sortByCreated[T any](items []*T, key func(*T) (time.Time, string)) {
+	sort.Slice(items, func(i, j int) bool {
+		ti, idi := key(items[i])
+		tj, idj := key(items[j])
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return idi < idj
+	})
+}
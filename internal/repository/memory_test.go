@@ -0,0 +1,561 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+func TestMemoryRepositories_Interface(t *testing.T) {
+	var _ MessageRepository = (*MemoryRepository)(nil)
+	var _ ScheduleRepository = (*MemoryScheduleRepository)(nil)
+	var _ WebActionResultRepository = (*MemoryWebActionRepository)(nil)
+	var _ UserRepository = (*MemoryUserRepository)(nil)
+	var _ ReservationRepository = (*MemoryReservationRepository)(nil)
+	var _ AuditRepository = (*MemoryAuditRepository)(nil)
+	var _ BookingLedgerRepository = (*MemoryBookingLedgerRepository)(nil)
+	var _ AgentSessionRepository = (*MemoryAgentSessionRepository)(nil)
+	var _ AgentUsageRepository = (*MemoryAgentUsageRepository)(nil)
+}
+
+func TestMemoryRepository_Messages(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	first := models.NewMessage("alice", nil, "1.0", models.StageDev, models.MessageTypeHelloWorld, map[string]interface{}{"message": "one"})
+	second := models.NewMessage("bob", nil, "1.0", models.StageProd, models.MessageTypeHelloWorld, map[string]interface{}{"message": "two"})
+	second.CreatedDate = first.CreatedDate.Add(time.Second)
+	for _, message := range []*models.Message{first, second} {
+		if err := repo.SaveMessage(ctx, message); err != nil {
+			t.Fatalf("SaveMessage() error = %v", err)
+		}
+	}
+
+	// Stored messages are copies, as they would be in DynamoDB
+	first.Status = models.StatusFailed
+	got, err := repo.GetMessage(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if got.Status != models.StatusCreated {
+		t.Errorf("GetMessage() Status = %v, want %v", got.Status, models.StatusCreated)
+	}
+	if got.Payload["message"] != "one" {
+		t.Errorf("GetMessage() Payload = %v, want message one", got.Payload)
+	}
+
+	if _, err := repo.GetMessage(ctx, "missing"); err == nil {
+		t.Error("GetMessage() error = nil for a missing message")
+	}
+
+	stage := models.StageProd
+	listed, err := repo.ListMessages(ctx, &stage, nil, 0)
+	if err != nil {
+		t.Fatalf("ListMessages() error = %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != second.ID {
+		t.Errorf("ListMessages(prod) = %v, want only the prod message", listed)
+	}
+	all, _ := repo.ListMessages(ctx, nil, nil, 1)
+	if len(all) != 1 || all[0].ID != first.ID {
+		t.Errorf("ListMessages(limit 1) = %v, want the oldest message", all)
+	}
+
+	if err := repo.UpdateStatus(ctx, first.ID, models.StatusFailed, "boom"); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	got, _ = repo.GetMessage(ctx, first.ID)
+	if got.Status != models.StatusFailed || got.ErrorMessage != "boom" || got.CreatedBy != "alice" {
+		t.Errorf("UpdateStatus() stored %+v, want failed with error and other fields kept", got)
+	}
+
+	byCreator, _ := repo.ListMessagesByCreator(ctx, "bob")
+	if len(byCreator) != 1 || byCreator[0].ID != second.ID {
+		t.Errorf("ListMessagesByCreator(bob) = %v, want bob's message", byCreator)
+	}
+
+	if err := repo.DeleteMessage(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	if _, err := repo.GetMessage(ctx, first.ID); err == nil {
+		t.Error("GetMessage() error = nil after DeleteMessage()")
+	}
+}
+
+func TestMemoryRepository_QueryMessages(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	created := time.Date(2030, 6, 1, 8, 0, 0, 0, time.UTC)
+	var ids []string
+	for i, creator := range []string{"alice", "bob", "alice", "alice"} {
+		message := models.NewMessage(creator, nil, "1.0", models.StageDev, models.MessageTypeHelloWorld, nil)
+		message.CreatedDate = created.Add(time.Duration(i) * time.Minute)
+		if err := repo.SaveMessage(ctx, message); err != nil {
+			t.Fatalf("SaveMessage() error = %v", err)
+		}
+		if creator == "alice" {
+			ids = append(ids, message.ID)
+		}
+	}
+
+	// Pages resume from the cursor, newest first
+	var got []string
+	page := IndexPage{Limit: 2, Descending: true}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("paging did not terminate")
+		}
+		messages, next, err := repo.QueryMessages(ctx, MessageQuery{CreatedBy: "alice", Stage: models.StageDev}, page)
+		if err != nil {
+			t.Fatalf("QueryMessages() error = %v", err)
+		}
+		for _, message := range messages {
+			got = append(got, message.ID)
+		}
+		if next == nil {
+			break
+		}
+		page.Cursor = next
+	}
+	if len(got) != 3 || got[0] != ids[2] || got[1] != ids[1] || got[2] != ids[0] {
+		t.Errorf("QueryMessages() = %v, want alice's messages newest first %v", got, ids)
+	}
+
+	if _, _, err := repo.QueryMessages(ctx, MessageQuery{}, IndexPage{}); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("QueryMessages() without a creator or stage error = %v, want ErrValidation", err)
+	}
+}
+
+func TestMemoryRepository_UpdateStatusCreatesMissing(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	if err := repo.UpdateStatus(ctx, "new", models.StatusProcessing, ""); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	got, err := repo.GetMessage(ctx, "new")
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if got.Status != models.StatusProcessing {
+		t.Errorf("Status = %v, want %v", got.Status, models.StatusProcessing)
+	}
+}
+
+func TestMemoryRepository_TransitionStatus(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	message := models.NewMessage("alice", nil, "1.0", models.StageDev, models.MessageTypeHelloWorld, map[string]interface{}{"message": "one"})
+	if err := repo.SaveMessage(ctx, message); err != nil {
+		t.Fatalf("SaveMessage() error = %v", err)
+	}
+	if err := repo.TransitionStatus(ctx, message.ID, models.StatusCreated, models.StatusQueued); err != nil {
+		t.Fatalf("TransitionStatus(created -> queued) error = %v", err)
+	}
+
+	// A consumer already moved the message on, so a late transition from created must not undo it
+	if err := repo.UpdateStatus(ctx, message.ID, models.StatusProcessing, ""); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	if err := repo.TransitionStatus(ctx, message.ID, models.StatusQueued, models.StatusCreated); !errors.Is(err, ErrStatusChanged) {
+		t.Errorf("TransitionStatus(queued -> created) error = %v, want ErrStatusChanged", err)
+	}
+	got, _ := repo.GetMessage(ctx, message.ID)
+	if got.Status != models.StatusProcessing {
+		t.Errorf("Status = %v, want %v", got.Status, models.StatusProcessing)
+	}
+
+	if err := repo.TransitionStatus(ctx, "missing", models.StatusCreated, models.StatusQueued); !errors.Is(err, ErrStatusChanged) {
+		t.Errorf("TransitionStatus(missing) error = %v, want ErrStatusChanged", err)
+	}
+	if _, err := repo.GetMessage(ctx, "missing"); err == nil {
+		t.Error("TransitionStatus() created a missing message")
+	}
+}
+
+func TestMemoryRepository_ListMessagesByStatus(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	now := time.Now().UTC()
+	for i, created := range []time.Time{now.Add(-time.Hour), now.Add(-2 * time.Hour), now} {
+		message := models.NewMessage("alice", nil, "1.0", models.StageDev, models.MessageTypeHelloWorld, map[string]interface{}{"message": "one"})
+		message.ID = fmt.Sprintf("msg-%d", i)
+		message.CreatedDate = created
+		message.Status = models.StatusQueued
+		if err := repo.SaveMessage(ctx, message); err != nil {
+			t.Fatalf("SaveMessage() error = %v", err)
+		}
+	}
+
+	messages, err := repo.ListMessagesByStatus(ctx, models.StatusQueued, now.Add(-time.Minute), 0)
+	if err != nil {
+		t.Fatalf("ListMessagesByStatus() error = %v", err)
+	}
+	if len(messages) != 2 || messages[0].ID != "msg-1" || messages[1].ID != "msg-0" {
+		t.Errorf("ListMessagesByStatus() = %v, want the two older messages, oldest first", messages)
+	}
+
+	if err := repo.RequeueMessage(ctx, "msg-0", models.StatusQueued); err != nil {
+		t.Fatalf("RequeueMessage() error = %v", err)
+	}
+	if err := repo.FailMessage(ctx, "msg-0", models.StatusProcessing, "stuck"); !errors.Is(err, ErrStatusChanged) {
+		t.Errorf("FailMessage(processing) error = %v, want ErrStatusChanged", err)
+	}
+	if err := repo.FailMessage(ctx, "msg-1", models.StatusQueued, "stuck"); err != nil {
+		t.Fatalf("FailMessage() error = %v", err)
+	}
+	requeued, _ := repo.GetMessage(ctx, "msg-0")
+	failed, _ := repo.GetMessage(ctx, "msg-1")
+	if requeued.Status != models.StatusQueued || requeued.RetryCount != 1 {
+		t.Errorf("requeued message is %s with %d retries, want queued with 1", requeued.Status, requeued.RetryCount)
+	}
+	if failed.Status != models.StatusFailed || failed.ErrorMessage != "stuck" {
+		t.Errorf("failed message is %s (%q), want failed with the reason", failed.Status, failed.ErrorMessage)
+	}
+}
+
+func TestMemoryScheduleRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryScheduleRepository()
+
+	schedule := &models.Schedule{
+		ID:          "sched-1",
+		Name:        "morning",
+		Status:      models.ScheduleStatusActive,
+		CreatedBy:   "alice",
+		CreatedDate: time.Now().UTC(),
+		Stage:       models.StageDev,
+	}
+	if err := repo.SaveSchedule(ctx, schedule); err != nil {
+		t.Fatalf("SaveSchedule() error = %v", err)
+	}
+
+	// SaveSchedule only creates, like the attribute_not_exists(id) condition
+	err := repo.SaveSchedule(ctx, schedule)
+	var condErr *types.ConditionalCheckFailedException
+	if !errors.As(err, &condErr) {
+		t.Errorf("SaveSchedule() twice error = %v, want ConditionalCheckFailedException", err)
+	}
+
+	schedule.Name = "renamed"
+	if err := repo.UpdateSchedule(ctx, schedule); err != nil {
+		t.Fatalf("UpdateSchedule() error = %v", err)
+	}
+	got, err := repo.GetSchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	if got.Name != "renamed" {
+		t.Errorf("GetSchedule() Name = %q, want %q", got.Name, "renamed")
+	}
+
+	active, _ := repo.ListSchedulesByStatus(ctx, models.ScheduleStatusActive)
+	if len(active) != 1 {
+		t.Errorf("ListSchedulesByStatus(active) returned %d schedules, want 1", len(active))
+	}
+
+	if err := repo.DeleteSchedule(ctx, schedule.ID); err != nil {
+		t.Fatalf("DeleteSchedule() error = %v", err)
+	}
+	got, _ = repo.GetSchedule(ctx, schedule.ID)
+	if got.Status != models.ScheduleStatusDeleted {
+		t.Errorf("Status after DeleteSchedule() = %v, want %v", got.Status, models.ScheduleStatusDeleted)
+	}
+	byCreator, _ := repo.ListSchedulesByCreator(ctx, "alice")
+	if len(byCreator) != 1 {
+		t.Errorf("ListSchedulesByCreator() returned %d schedules, want the deleted one too", len(byCreator))
+	}
+
+	if err := repo.PurgeSchedule(ctx, schedule.ID); err != nil {
+		t.Fatalf("PurgeSchedule() error = %v", err)
+	}
+	if _, err := repo.GetSchedule(ctx, schedule.ID); err == nil {
+		t.Error("GetSchedule() error = nil after PurgeSchedule()")
+	}
+}
+
+func TestMemoryWebActionRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryWebActionRepository()
+
+	older := models.NewWebActionResult("msg-1", models.WebActionTypeWeather, "https://api.weather.gov", models.StageDev)
+	newer := models.NewWebActionResult("msg-1", models.WebActionTypeWeather, "https://api.weather.gov", models.StageDev)
+	newer.CreatedDate = older.CreatedDate.Add(time.Minute)
+	other := models.NewWebActionResult("msg-2", models.WebActionTypeGolf, "https://golf.example.com", models.StageDev)
+	for _, result := range []*models.WebActionResult{newer, older, other} {
+		if err := repo.SaveResult(ctx, result); err != nil {
+			t.Fatalf("SaveResult() error = %v", err)
+		}
+	}
+
+	results, err := repo.ListResultsByMessageID(ctx, "msg-1")
+	if err != nil {
+		t.Fatalf("ListResultsByMessageID() error = %v", err)
+	}
+	if len(results) != 2 || results[0].ID != older.ID {
+		t.Errorf("ListResultsByMessageID() = %v, want both msg-1 results oldest first", results)
+	}
+
+	first, err := repo.GetResultByMessageID(ctx, "msg-1")
+	if err != nil {
+		t.Fatalf("GetResultByMessageID() error = %v", err)
+	}
+	if first.ID != older.ID {
+		t.Errorf("GetResultByMessageID() ID = %v, want %v", first.ID, older.ID)
+	}
+	if _, err := repo.GetResultByMessageID(ctx, "none"); err == nil {
+		t.Error("GetResultByMessageID() error = nil for a message without results")
+	}
+
+	page, next, err := repo.QueryResults(ctx, ResultQuery{Stage: models.StageDev, Action: models.WebActionTypeWeather}, IndexPage{Limit: 1, Descending: true})
+	if err != nil {
+		t.Fatalf("QueryResults() error = %v", err)
+	}
+	if len(page) != 1 || page[0].ID != newer.ID || next == nil {
+		t.Fatalf("QueryResults() = %v next %v, want the newer weather result and a cursor", page, next)
+	}
+	page, next, _ = repo.QueryResults(ctx, ResultQuery{Stage: models.StageDev, Action: models.WebActionTypeWeather}, IndexPage{Limit: 1, Descending: true, Cursor: next})
+	if len(page) != 1 || page[0].ID != older.ID || next != nil {
+		t.Errorf("QueryResults() second page = %v next %v, want the older result and no cursor", page, next)
+	}
+
+	if err := repo.DeleteResult(ctx, other.ID); err != nil {
+		t.Fatalf("DeleteResult() error = %v", err)
+	}
+	if _, err := repo.GetResult(ctx, other.ID); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("GetResult() error = %v after DeleteResult(), want ErrNotFound", err)
+	}
+}
+
+func TestMemoryUserRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+
+	user, apiKey, err := models.NewUser("alice", "Alice", "", models.UserRoleMember, "rez-agent/golf/users/alice")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if err := repo.CreateUser(ctx, user); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if err := repo.CreateUser(ctx, user); !errors.Is(err, ErrUserExists) {
+		t.Errorf("CreateUser() twice error = %v, want ErrUserExists", err)
+	}
+
+	got, err := repo.GetUserByAPIKeyHash(ctx, models.HashAPIKey(apiKey))
+	if err != nil {
+		t.Fatalf("GetUserByAPIKeyHash() error = %v", err)
+	}
+	if got.ID != "alice" || got.GolfSecretName != "rez-agent/golf/users/alice" {
+		t.Errorf("GetUserByAPIKeyHash() = %+v, want alice", got)
+	}
+
+	if _, err := repo.GetUserByAPIKeyHash(ctx, models.HashAPIKey("wrong")); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("GetUserByAPIKeyHash(wrong key) error = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.GetUser(ctx, "bob"); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("GetUser(bob) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryReservationRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryReservationRepository()
+
+	teeTime := time.Date(2026, 6, 6, 8, 30, 0, 0, time.UTC)
+	for _, s := range []*models.ReservationSnapshot{
+		{SecretName: "alice", CourseID: 2, CourseName: "Totteridge", Reservations: []models.CachedReservation{{ReservationID: 7, TeeTime: teeTime, Players: 2}}},
+		{SecretName: "alice", CourseID: 1, CourseName: "Birdsfoot"},
+		{SecretName: "bob", CourseID: 1, CourseName: "Birdsfoot"},
+	} {
+		if err := repo.SaveSnapshot(ctx, s); err != nil {
+			t.Fatalf("SaveSnapshot() error = %v", err)
+		}
+	}
+
+	got, err := repo.GetSnapshot(ctx, "alice", 2)
+	if err != nil {
+		t.Fatalf("GetSnapshot() error = %v", err)
+	}
+	if len(got.Reservations) != 1 || !got.Reservations[0].TeeTime.Equal(teeTime) {
+		t.Errorf("GetSnapshot() = %+v, want the saved reservation", got)
+	}
+
+	// A sync replaces the snapshot, so a cancelled reservation disappears
+	if err := repo.SaveSnapshot(ctx, &models.ReservationSnapshot{SecretName: "alice", CourseID: 2, CourseName: "Totteridge"}); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	if got, _ := repo.GetSnapshot(ctx, "alice", 2); len(got.Reservations) != 0 {
+		t.Errorf("GetSnapshot() after resync = %+v, want no reservations", got.Reservations)
+	}
+
+	list, err := repo.ListSnapshots(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListSnapshots() error = %v", err)
+	}
+	if len(list) != 2 || list[0].CourseID != 1 || list[1].CourseID != 2 {
+		t.Errorf("ListSnapshots() = %+v, want alice's two courses in order", list)
+	}
+
+	if _, err := repo.GetSnapshot(ctx, "bob", 2); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("GetSnapshot(unsynced) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryAuditRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryAuditRepository()
+
+	old, err := models.NewAuditEntry("alice", models.AuditActionBooking, "webaction", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old.CreatedAt = old.CreatedAt.Add(-48 * time.Hour)
+	recent, err := models.NewAuditEntry("", models.AuditActionScheduleCreation, "scheduler", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range []*models.AuditEntry{recent, old} {
+		if err := repo.SaveEntry(ctx, entry); err != nil {
+			t.Fatalf("SaveEntry() error = %v", err)
+		}
+	}
+
+	// The log is append-only: an entry is never replaced
+	var conflict *types.ConditionalCheckFailedException
+	if err := repo.SaveEntry(ctx, recent); !errors.As(err, &conflict) {
+		t.Errorf("SaveEntry(existing) error = %v, want a conditional check failure", err)
+	}
+
+	all, err := repo.ListEntries(ctx, time.Now().Add(-72*time.Hour))
+	if err != nil {
+		t.Fatalf("ListEntries() error = %v", err)
+	}
+	if len(all) != 2 || all[0].ID != old.ID {
+		t.Errorf("ListEntries() = %+v, want both entries oldest first", all)
+	}
+	if since, _ := repo.ListEntries(ctx, time.Now().Add(-time.Hour)); len(since) != 1 || since[0].ID != recent.ID {
+		t.Errorf("ListEntries(last hour) = %+v, want only the recent entry", since)
+	}
+	if mine, _ := repo.ListEntriesByUser(ctx, "alice"); len(mine) != 1 || mine[0].ID != old.ID {
+		t.Errorf("ListEntriesByUser() = %+v, want alice's entry", mine)
+	}
+}
+
+func TestMemoryBookingLedgerRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryBookingLedgerRepository()
+	teeTime := time.Date(2030, 6, 1, 7, 30, 0, 0, time.UTC)
+
+	claim := models.NewBookingLedgerClaim("30417", 1, 918274, teeTime)
+	if err := repo.ClaimBooking(ctx, claim); err != nil {
+		t.Fatalf("ClaimBooking() error = %v", err)
+	}
+
+	// A second claim for the same golfer, course and morning fails, even for another tee time
+	if err := repo.ClaimBooking(ctx, models.NewBookingLedgerClaim("30417", 1, 918301, teeTime.Add(2*time.Hour))); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("ClaimBooking(same morning) error = %v, want ErrValidation", err)
+	}
+	if err := repo.ClaimBooking(ctx, models.NewBookingLedgerClaim("30417", 2, 918274, teeTime)); err != nil {
+		t.Errorf("ClaimBooking(other course) error = %v", err)
+	}
+	if err := repo.ClaimBooking(ctx, models.NewBookingLedgerClaim("30417", 1, 918274, teeTime.AddDate(0, 0, 1))); err != nil {
+		t.Errorf("ClaimBooking(other date) error = %v", err)
+	}
+
+	// A released claim can be claimed again; a booked one cannot be released
+	if err := repo.ReleaseBooking(ctx, claim.ID); err != nil {
+		t.Fatalf("ReleaseBooking() error = %v", err)
+	}
+	if err := repo.ClaimBooking(ctx, claim); err != nil {
+		t.Fatalf("ClaimBooking(released) error = %v", err)
+	}
+	if err := repo.ConfirmBooking(ctx, claim.ID, 552190); err != nil {
+		t.Fatalf("ConfirmBooking() error = %v", err)
+	}
+	if err := repo.ReleaseBooking(ctx, claim.ID); err == nil {
+		t.Error("ReleaseBooking(booked) error = nil, want an error")
+	}
+	if got, _ := repo.GetEntry(claim.ID); got.Status != models.BookingLedgerStatusBooked || got.ReservationID != 552190 {
+		t.Errorf("GetEntry() = %+v, want booked with reservation 552190", got)
+	}
+
+	// An abandoned claim is taken over, but a booking blocks for good
+	abandoned := models.NewBookingLedgerClaim("40000", 1, 918274, teeTime)
+	abandoned.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := repo.ClaimBooking(ctx, abandoned); err != nil {
+		t.Fatalf("ClaimBooking() error = %v", err)
+	}
+	if err := repo.ClaimBooking(ctx, models.NewBookingLedgerClaim("40000", 1, 918275, teeTime)); err != nil {
+		t.Errorf("ClaimBooking(abandoned claim) error = %v", err)
+	}
+	if err := repo.ConfirmBooking(ctx, abandoned.ID, 552191); err != nil {
+		t.Fatalf("ConfirmBooking() error = %v", err)
+	}
+	late := models.NewBookingLedgerClaim("40000", 1, 918301, teeTime)
+	late.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := repo.ClaimBooking(ctx, late); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("ClaimBooking(booked) error = %v, want ErrValidation", err)
+	}
+}
+
+func TestMemoryAgentSessionRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryAgentSessionRepository(models.AgentSessionWindow{MaxMessages: 4, TTL: time.Hour})
+	turn := func(user, assistant string) []map[string]interface{} {
+		return []map[string]interface{}{
+			{"role": "user", "content": user},
+			{"role": "assistant", "content": assistant},
+		}
+	}
+
+	if got, err := repo.GetSession(ctx, "s1"); err != nil || got != nil {
+		t.Fatalf("GetSession(missing) = %v, %v, want nil, nil", got, err)
+	}
+
+	session, err := repo.AppendMessages(ctx, "s1", turn("hi", "hello"), []string{"golf_book_tee_time"})
+	if err != nil {
+		t.Fatalf("AppendMessages() error = %v", err)
+	}
+	if session.CreatedAt == "" || len(session.Messages) != 2 || session.TTL <= time.Now().Unix() {
+		t.Errorf("AppendMessages() = %+v, want a new session with 2 messages and a future TTL", session)
+	}
+
+	// The window keeps the newest four messages; nil approvals leave the stored ones alone
+	repo.AppendMessages(ctx, "s1", turn("search saturday", "found 3"), nil)
+	session, err = repo.AppendMessages(ctx, "s1", turn("book the first", "booked"), nil)
+	if err != nil {
+		t.Fatalf("AppendMessages() error = %v", err)
+	}
+	if len(session.Messages) != 4 || session.Messages[0]["content"] != "search saturday" {
+		t.Errorf("Messages = %v, want the last two turns", session.Messages)
+	}
+	if len(session.ApprovedTools) != 1 {
+		t.Errorf("ApprovedTools = %v, want the approval kept", session.ApprovedTools)
+	}
+
+	// An expired session reads as gone and starts over on the next append
+	if err := repo.ExpireSession(ctx, "s1", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("ExpireSession() error = %v", err)
+	}
+	if got, _ := repo.GetSession(ctx, "s1"); got != nil {
+		t.Errorf("GetSession(expired) = %+v, want nil", got)
+	}
+	session, _ = repo.AppendMessages(ctx, "s1", turn("hi again", "hello"), nil)
+	if len(session.Messages) != 2 || len(session.ApprovedTools) != 0 {
+		t.Errorf("AppendMessages(expired) = %+v, want a fresh session", session)
+	}
+
+	if err := repo.ExpireSession(ctx, "missing", time.Now()); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("ExpireSession(missing) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// DynamoDBOAuthTokenRepository stores cached OAuth tokens in DynamoDB so warm and cold Lambdas share them
+type DynamoDBOAuthTokenRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBOAuthTokenRepository creates a new OAuth token repository
+func NewDynamoDBOAuthTokenRepository(client *dynamodb.Client, tableName string) *DynamoDBOAuthTokenRepository {
+	return &DynamoDBOAuthTokenRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// GetToken retrieves a cached token by key, returning nil if there is none
+func (r *DynamoDBOAuthTokenRepository) GetToken(ctx context.Context, key string) (*models.OAuthToken, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"key": &types.AttributeValueMemberS{Value: key},
+		},
+	}
+
+	result, err := r.client.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OAuth token: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var token models.OAuthToken
+	if err := attributevalue.UnmarshalMap(result.Item, &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal OAuth token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// PutToken stores a cached token, replacing any previous token for the same key
+func (r *DynamoDBOAuthTokenRepository) PutToken(ctx context.Context, token *models.OAuthToken) error {
+	item, err := attributevalue.MarshalMap(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal OAuth token: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save OAuth token: %w", err)
+	}
+
+	return nil
+}
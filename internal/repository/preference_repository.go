@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// PreferenceRepository defines the interface for post-round survey persistence
+type PreferenceRepository interface {
+	// SaveSurvey saves or replaces the survey for a course and tee time
+	SaveSurvey(ctx context.Context, survey *models.RoundSurvey) error
+
+	// ListSurveys lists surveys recorded on or after since
+	ListSurveys(ctx context.Context, since time.Time) ([]*models.RoundSurvey, error)
+}
+
+// DynamoDBPreferenceRepository implements PreferenceRepository using DynamoDB
+type DynamoDBPreferenceRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBPreferenceRepository creates a new preference repository
+func NewDynamoDBPreferenceRepository(client *dynamodb.Client, tableName string) *DynamoDBPreferenceRepository {
+	return &DynamoDBPreferenceRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// SaveSurvey saves or replaces the survey for a course and tee time
+func (r *DynamoDBPreferenceRepository) SaveSurvey(ctx context.Context, survey *models.RoundSurvey) error {
+	item, err := attributevalue.MarshalMap(survey)
+	if err != nil {
+		return fmt.Errorf("failed to marshal round survey: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	}
+
+	_, err = r.client.PutItem(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to save round survey: %w", err)
+	}
+
+	return nil
+}
+
+// ListSurveys lists surveys recorded on or after since
+func (r *DynamoDBPreferenceRepository) ListSurveys(ctx context.Context, since time.Time) ([]*models.RoundSurvey, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("created_date >= :since"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":since": &types.AttributeValueMemberS{Value: since.UTC().Format(time.RFC3339)},
+		},
+	}
+
+	surveys := make([]*models.RoundSurvey, 0)
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan round surveys: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var survey models.RoundSurvey
+			if err := attributevalue.UnmarshalMap(item, &survey); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal round survey: %w", err)
+			}
+			surveys = append(surveys, &survey)
+		}
+	}
+
+	return surveys, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// ReservationRepository defines the interface for the synced reservations cache
+type ReservationRepository interface {
+	// SaveSnapshot replaces a golfer's cached reservations at a course
+	SaveSnapshot(ctx context.Context, snapshot *models.ReservationSnapshot) error
+
+	// GetSnapshot retrieves a golfer's cached reservations at a course
+	GetSnapshot(ctx context.Context, secretName string, courseID int) (*models.ReservationSnapshot, error)
+
+	// ListSnapshots retrieves a golfer's cached reservations at every synced course
+	ListSnapshots(ctx context.Context, secretName string) ([]*models.ReservationSnapshot, error)
+}
+
+// DynamoDBReservationRepository implements ReservationRepository using DynamoDB, keyed by
+// secret_name and course_id
+type DynamoDBReservationRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBReservationRepository creates a new reservation repository
+func NewDynamoDBReservationRepository(client *dynamodb.Client, tableName string) *DynamoDBReservationRepository {
+	return &DynamoDBReservationRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// SaveSnapshot replaces a golfer's cached reservations at a course
+func (r *DynamoDBReservationRepository) SaveSnapshot(ctx context.Context, snapshot *models.ReservationSnapshot) error {
+	item, err := attributevalue.MarshalMap(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reservation snapshot: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save reservation snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetSnapshot retrieves a golfer's cached reservations at a course
+func (r *DynamoDBReservationRepository) GetSnapshot(ctx context.Context, secretName string, courseID int) (*models.ReservationSnapshot, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"secret_name": &types.AttributeValueMemberS{Value: secretName},
+			"course_id":   &types.AttributeValueMemberN{Value: strconv.Itoa(courseID)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation snapshot: %w", err)
+	}
+	if result.Item == nil {
+		return nil, apperrors.Newf(apperrors.ErrNotFound, "no synced reservations for course %d", courseID)
+	}
+
+	var snapshot models.ReservationSnapshot
+	if err := attributevalue.UnmarshalMap(result.Item, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reservation snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// ListSnapshots retrieves a golfer's cached reservations at every synced course
+func (r *DynamoDBReservationRepository) ListSnapshots(ctx context.Context, secretName string) ([]*models.ReservationSnapshot, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("secret_name = :secret_name"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":secret_name": &types.AttributeValueMemberS{Value: secretName},
+		},
+	}
+
+	snapshots := make([]*models.ReservationSnapshot, 0)
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query reservation snapshots: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var snapshot models.ReservationSnapshot
+			if err := attributevalue.UnmarshalMap(item, &snapshot); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal reservation snapshot: %w", err)
+			}
+			snapshots = append(snapshots, &snapshot)
+		}
+	}
+
+	return snapshots, nil
+}
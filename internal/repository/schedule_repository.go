@@ -34,6 +34,9 @@ type ScheduleRepository interface {
 
 	// DeleteSchedule marks a schedule as deleted
 	DeleteSchedule(ctx context.Context, id string) error
+
+	// PurgeSchedule permanently removes a schedule
+	PurgeSchedule(ctx context.Context, id string) error
 }
 
 // DynamoDBScheduleRepository implements ScheduleRepository using DynamoDB
@@ -218,3 +221,17 @@ func (r *DynamoDBScheduleRepository) ListSchedulesByCreator(ctx context.Context,
 func (r *DynamoDBScheduleRepository) DeleteSchedule(ctx context.Context, id string) error {
 	return r.UpdateScheduleStatus(ctx, id, models.ScheduleStatusDeleted, "")
 }
+
+// PurgeSchedule permanently removes a schedule, unlike DeleteSchedule which keeps it as deleted
+func (r *DynamoDBScheduleRepository) PurgeSchedule(ctx context.Context, id string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to purge schedule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// ErrUserExists is returned by CreateUser when the user ID is taken
+var ErrUserExists = errors.New("user already exists")
+
+// UserRepository defines the interface for user persistence
+type UserRepository interface {
+	// CreateUser saves a new user, failing if the ID is taken
+	CreateUser(ctx context.Context, user *models.User) error
+
+	// GetUser retrieves a user by ID
+	GetUser(ctx context.Context, id string) (*models.User, error)
+
+	// GetUserByAPIKeyHash retrieves the user an API key belongs to
+	GetUserByAPIKeyHash(ctx context.Context, apiKeyHash string) (*models.User, error)
+
+	// ListUsers returns every user
+	ListUsers(ctx context.Context) ([]*models.User, error)
+}
+
+// DynamoDBUserRepository implements UserRepository using DynamoDB
+type DynamoDBUserRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBUserRepository creates a new user repository
+func NewDynamoDBUserRepository(client *dynamodb.Client, tableName string) *DynamoDBUserRepository {
+	return &DynamoDBUserRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// CreateUser saves a new user, failing if the ID is taken
+func (r *DynamoDBUserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	item, err := attributevalue.MarshalMap(user)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("%w: %s", ErrUserExists, user.ID)
+		}
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+
+	return nil
+}
+
+// GetUser retrieves a user by ID
+func (r *DynamoDBUserRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if result.Item == nil {
+		return nil, apperrors.Newf(apperrors.ErrNotFound, "user not found: %s", id)
+	}
+
+	var user models.User
+	if err := attributevalue.UnmarshalMap(result.Item, &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+	return &user, nil
+}
+
+// GetUserByAPIKeyHash retrieves the user an API key belongs to
+func (r *DynamoDBUserRepository) GetUserByAPIKeyHash(ctx context.Context, apiKeyHash string) (*models.User, error) {
+	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("api_key_hash-index"),
+		KeyConditionExpression: aws.String("api_key_hash = :api_key_hash"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":api_key_hash": &types.AttributeValueMemberS{Value: apiKeyHash},
+		},
+		Limit: aws.Int32(1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user by API key: %w", err)
+	}
+	if len(result.Items) == 0 {
+		return nil, apperrors.Newf(apperrors.ErrNotFound, "no user has this API key")
+	}
+
+	var user models.User
+	if err := attributevalue.UnmarshalMap(result.Items[0], &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+	return &user, nil
+}
+
+// ListUsers returns every user
+func (r *DynamoDBUserRepository) ListUsers(ctx context.Context) ([]*models.User, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(r.tableName),
+	}
+
+	users := make([]*models.User, 0)
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan users: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var user models.User
+			if err := attributevalue.UnmarshalMap(item, &user); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+			}
+			users = append(users, &user)
+		}
+	}
+
+	return users, nil
+}
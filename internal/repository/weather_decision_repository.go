@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// WeatherDecisionRepository defines the interface for weather decision persistence
+type WeatherDecisionRepository interface {
+	// SaveDecision saves or replaces the decision for a course and date
+	SaveDecision(ctx context.Context, decision *models.WeatherDecision) error
+
+	// ListDecisions lists decisions for dates on or after sinceDate (YYYY-MM-DD)
+	ListDecisions(ctx context.Context, sinceDate string) ([]*models.WeatherDecision, error)
+}
+
+// DynamoDBWeatherDecisionRepository implements WeatherDecisionRepository using DynamoDB
+type DynamoDBWeatherDecisionRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBWeatherDecisionRepository creates a new weather decision repository
+func NewDynamoDBWeatherDecisionRepository(client *dynamodb.Client, tableName string) *DynamoDBWeatherDecisionRepository {
+	return &DynamoDBWeatherDecisionRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// SaveDecision saves or replaces the decision for a course and date
+func (r *DynamoDBWeatherDecisionRepository) SaveDecision(ctx context.Context, decision *models.WeatherDecision) error {
+	item, err := attributevalue.MarshalMap(decision)
+	if err != nil {
+		return fmt.Errorf("failed to marshal weather decision: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	}
+
+	_, err = r.client.PutItem(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to save weather decision: %w", err)
+	}
+
+	return nil
+}
+
+// ListDecisions lists decisions for dates on or after sinceDate (YYYY-MM-DD)
+func (r *DynamoDBWeatherDecisionRepository) ListDecisions(ctx context.Context, sinceDate string) ([]*models.WeatherDecision, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("#date >= :since"),
+		ExpressionAttributeNames: map[string]string{
+			"#date": "date",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":since": &types.AttributeValueMemberS{Value: sinceDate},
+		},
+	}
+
+	decisions := make([]*models.WeatherDecision, 0)
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan weather decisions: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var decision models.WeatherDecision
+			if err := attributevalue.UnmarshalMap(item, &decision); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal weather decision: %w", err)
+			}
+			decisions = append(decisions, &decision)
+		}
+	}
+
+	return decisions, nil
+}
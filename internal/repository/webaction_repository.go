@@ -8,6 +8,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/logging"
 	"github.com/jrzesz33/rez_agent/internal/models"
 )
 
@@ -16,6 +18,18 @@ type WebActionResultRepository interface {
 	SaveResult(ctx context.Context, result *models.WebActionResult) error
 	GetResult(ctx context.Context, id string) (*models.WebActionResult, error)
 	GetResultByMessageID(ctx context.Context, messageID string) (*models.WebActionResult, error)
+	ListResultsByMessageID(ctx context.Context, messageID string) ([]*models.WebActionResult, error)
+	QueryResults(ctx context.Context, query ResultQuery, page IndexPage) ([]*models.WebActionResult, map[string]string, error)
+	DeleteResult(ctx context.Context, id string) error
+}
+
+// ResultQuery selects a stage's web action results, with optional exact-match filters.
+// QueryResults reads them from the stage-created_date-index.
+type ResultQuery struct {
+	Stage         models.Stage
+	Status        models.Status
+	Action        models.WebActionType
+	CorrelationID string
 }
 
 // DynamoDBWebActionRepository implements WebActionResultRepository using DynamoDB
@@ -32,8 +46,12 @@ func NewDynamoDBWebActionRepository(client *dynamodb.Client, tableName string) *
 	}
 }
 
-// SaveResult saves a web action result to DynamoDB with TTL
+// SaveResult saves a web action result to DynamoDB with TTL, taking the context's correlation ID
+// when it has none
 func (r *DynamoDBWebActionRepository) SaveResult(ctx context.Context, result *models.WebActionResult) error {
+	if result.CorrelationID == "" {
+		result.CorrelationID = logging.CorrelationID(ctx)
+	}
 	av, err := attributevalue.MarshalMap(result)
 	if err != nil {
 		return fmt.Errorf("failed to marshal web action result: %w", err)
@@ -67,7 +85,7 @@ func (r *DynamoDBWebActionRepository) GetResult(ctx context.Context, id string)
 	}
 
 	if resp.Item == nil {
-		return nil, fmt.Errorf("web action result not found: %s", id)
+		return nil, apperrors.Newf(apperrors.ErrNotFound, "web action result not found: %s", id)
 	}
 
 	var result models.WebActionResult
@@ -108,3 +126,76 @@ func (r *DynamoDBWebActionRepository) GetResultByMessageID(ctx context.Context,
 
 	return &result, nil
 }
+
+// ListResultsByMessageID retrieves every result recorded for a message, which is empty when the message has none
+func (r *DynamoDBWebActionRepository) ListResultsByMessageID(ctx context.Context, messageID string) ([]*models.WebActionResult, error) {
+	resp, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("message_id-index"),
+		KeyConditionExpression: aws.String("message_id = :messageID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":messageID": &types.AttributeValueMemberS{Value: messageID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query web action results by message ID: %w", err)
+	}
+
+	results := make([]*models.WebActionResult, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		var result models.WebActionResult
+		if err := attributevalue.UnmarshalMap(item, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal web action result: %w", err)
+		}
+		results = append(results, &result)
+	}
+	return results, nil
+}
+
+// QueryResults reads a page of a stage's web action results in created date order
+func (r *DynamoDBWebActionRepository) QueryResults(ctx context.Context, query ResultQuery, page IndexPage) ([]*models.WebActionResult, map[string]string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("stage-created_date-index"),
+		KeyConditionExpression: aws.String("#stage = :stage"),
+		ExpressionAttributeNames: map[string]string{
+			"#stage": "stage",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":stage": &types.AttributeValueMemberS{Value: string(query.Stage)},
+		},
+	}
+	addEqualityFilters(input, map[string]string{
+		"status":         string(query.Status),
+		"action":         string(query.Action),
+		"correlation_id": query.CorrelationID,
+	})
+
+	items, next, err := queryIndexPage(ctx, r.client, input, page)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query web action results: %w", err)
+	}
+	results := make([]*models.WebActionResult, 0, len(items))
+	for _, item := range items {
+		var result models.WebActionResult
+		if err := attributevalue.UnmarshalMap(item, &result); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal web action result: %w", err)
+		}
+		results = append(results, &result)
+	}
+	return results, next, nil
+}
+
+// DeleteResult permanently removes a web action result
+func (r *DynamoDBWebActionRepository) DeleteResult(ctx context.Context, id string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete web action result: %w", err)
+	}
+	return nil
+}
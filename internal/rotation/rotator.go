@@ -0,0 +1,193 @@
+// Package rotation implements the Secrets Manager rotation steps for the golf credentials secret
+package rotation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+
+	"github.com/jrzesz33/rez_agent/internal/secrets"
+)
+
+// Secrets Manager rotation steps, run in this order for each rotation
+const (
+	StepCreateSecret = "createSecret"
+	StepSetSecret    = "setSecret"
+	StepTestSecret   = "testSecret"
+	StepFinishSecret = "finishSecret"
+)
+
+// Version stages Secrets Manager moves between versions during rotation
+const (
+	stageCurrent = "AWSCURRENT"
+	stagePending = "AWSPENDING"
+)
+
+// Event is the payload Secrets Manager invokes the rotation Lambda with, once per step
+type Event struct {
+	SecretID           string `json:"SecretId"`
+	ClientRequestToken string `json:"ClientRequestToken"`
+	Step               string `json:"Step"`
+}
+
+// SecretsAPI is the part of the Secrets Manager client rotation uses
+type SecretsAPI interface {
+	DescribeSecret(ctx context.Context, params *secretsmanager.DescribeSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.DescribeSecretOutput, error)
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+	PutSecretValue(ctx context.Context, params *secretsmanager.PutSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.PutSecretValueOutput, error)
+	UpdateSecretVersionStage(ctx context.Context, params *secretsmanager.UpdateSecretVersionStageInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.UpdateSecretVersionStageOutput, error)
+}
+
+// Verifier logs in with creds and returns an error if the login is rejected
+type Verifier func(ctx context.Context, creds *secrets.OAuthCredentials) error
+
+// Rotator runs the rotation steps. The course sites have no API for changing a password, so a
+// rotation cannot invent one: the pending version carries the newest value an operator has put,
+// and is promoted only after it logs in. A scheduled rotation therefore doubles as a check that
+// the stored credentials still work, failing loudly before a nightly booking does.
+type Rotator struct {
+	client SecretsAPI
+	verify Verifier
+	logger *slog.Logger
+}
+
+// NewRotator creates a rotator that checks pending credentials with verify
+func NewRotator(client SecretsAPI, verify Verifier, logger *slog.Logger) *Rotator {
+	return &Rotator{client: client, verify: verify, logger: logger}
+}
+
+// Handle runs one rotation step
+func (r *Rotator) Handle(ctx context.Context, event Event) error {
+	logger := r.logger.With(slog.String("step", event.Step), slog.String("version", event.ClientRequestToken))
+
+	metadata, err := r.client.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: aws.String(event.SecretID)})
+	if err != nil {
+		return fmt.Errorf("failed to describe secret: %w", err)
+	}
+	if !aws.ToBool(metadata.RotationEnabled) {
+		return fmt.Errorf("rotation is not enabled for the secret")
+	}
+	stages, ok := metadata.VersionIdsToStages[event.ClientRequestToken]
+	if !ok {
+		return fmt.Errorf("secret has no version %s to rotate", event.ClientRequestToken)
+	}
+	if slices.Contains(stages, stageCurrent) {
+		logger.Info("version is already current")
+		return nil
+	}
+	if !slices.Contains(stages, stagePending) {
+		return fmt.Errorf("version %s is not pending rotation", event.ClientRequestToken)
+	}
+
+	switch event.Step {
+	case StepCreateSecret:
+		return r.createSecret(ctx, event, logger)
+	case StepSetSecret:
+		// Nothing to change at the course site; see Rotator
+		return nil
+	case StepTestSecret:
+		return r.testSecret(ctx, event, logger)
+	case StepFinishSecret:
+		return r.finishSecret(ctx, event, metadata.VersionIdsToStages, logger)
+	default:
+		return fmt.Errorf("unknown rotation step %q", event.Step)
+	}
+}
+
+// createSecret stores the current credentials as the pending version, unless it already exists
+func (r *Rotator) createSecret(ctx context.Context, event Event, logger *slog.Logger) error {
+	_, err := r.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(event.SecretID),
+		VersionId:    aws.String(event.ClientRequestToken),
+		VersionStage: aws.String(stagePending),
+	})
+	if err == nil {
+		logger.Info("pending version already exists")
+		return nil
+	}
+	var notFound *types.ResourceNotFoundException
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to read pending version: %w", err)
+	}
+
+	current, err := r.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(event.SecretID),
+		VersionStage: aws.String(stageCurrent),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read current version: %w", err)
+	}
+
+	_, err = r.client.PutSecretValue(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:           aws.String(event.SecretID),
+		ClientRequestToken: aws.String(event.ClientRequestToken),
+		SecretString:       current.SecretString,
+		VersionStages:      []string{stagePending},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store pending version: %w", err)
+	}
+	logger.Info("pending version created")
+	return nil
+}
+
+// testSecret logs in with the pending credentials
+func (r *Rotator) testSecret(ctx context.Context, event Event, logger *slog.Logger) error {
+	pending, err := r.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(event.SecretID),
+		VersionId:    aws.String(event.ClientRequestToken),
+		VersionStage: aws.String(stagePending),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read pending version: %w", err)
+	}
+
+	var value secrets.SecretValue
+	if err := json.Unmarshal([]byte(aws.ToString(pending.SecretString)), &value); err != nil {
+		return fmt.Errorf("failed to parse pending version: %w", err)
+	}
+	creds, err := secrets.ParseOAuthCredentials(value)
+	if err != nil {
+		return err
+	}
+
+	if err := r.verify(ctx, creds); err != nil {
+		logger.Error("pending credentials were rejected", slog.String("error", err.Error()))
+		return fmt.Errorf("pending credentials were rejected: %w", err)
+	}
+	logger.Info("pending credentials verified")
+	return nil
+}
+
+// finishSecret moves AWSCURRENT to the pending version
+func (r *Rotator) finishSecret(ctx context.Context, event Event, versions map[string][]string, logger *slog.Logger) error {
+	var currentVersion string
+	for version, stages := range versions {
+		if slices.Contains(stages, stageCurrent) {
+			currentVersion = version
+			break
+		}
+	}
+
+	input := &secretsmanager.UpdateSecretVersionStageInput{
+		SecretId:        aws.String(event.SecretID),
+		VersionStage:    aws.String(stageCurrent),
+		MoveToVersionId: aws.String(event.ClientRequestToken),
+	}
+	if currentVersion != "" {
+		input.RemoveFromVersionId = aws.String(currentVersion)
+	}
+	_, err := r.client.UpdateSecretVersionStage(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to promote pending version: %w", err)
+	}
+	logger.Info("pending version promoted", slog.String("previous_version", currentVersion))
+	return nil
+}
@@ -0,0 +1,138 @@
+package rotation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+
+	"github.com/jrzesz33/rez_agent/internal/secrets"
+)
+
+// fakeSecrets keeps one secret's versions in memory
+type fakeSecrets struct {
+	values   map[string]string
+	stages   map[string][]string
+	rotating bool
+}
+
+func newFakeSecrets(current string) *fakeSecrets {
+	return &fakeSecrets{
+		values:   map[string]string{"v1": current},
+		stages:   map[string][]string{"v1": {stageCurrent}},
+		rotating: true,
+	}
+}
+
+// startRotation adds the pending version Secrets Manager creates before invoking createSecret
+func (f *fakeSecrets) startRotation(token string) {
+	f.stages[token] = []string{stagePending}
+}
+
+func (f *fakeSecrets) DescribeSecret(ctx context.Context, params *secretsmanager.DescribeSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.DescribeSecretOutput, error) {
+	return &secretsmanager.DescribeSecretOutput{RotationEnabled: aws.Bool(f.rotating), VersionIdsToStages: f.stages}, nil
+}
+
+func (f *fakeSecrets) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	for version, stages := range f.stages {
+		if params.VersionId != nil && aws.ToString(params.VersionId) != version {
+			continue
+		}
+		if !slices.Contains(stages, aws.ToString(params.VersionStage)) {
+			continue
+		}
+		if value, ok := f.values[version]; ok {
+			return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(value)}, nil
+		}
+	}
+	return nil, &types.ResourceNotFoundException{Message: aws.String("no such version")}
+}
+
+func (f *fakeSecrets) PutSecretValue(ctx context.Context, params *secretsmanager.PutSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.PutSecretValueOutput, error) {
+	version := aws.ToString(params.ClientRequestToken)
+	f.values[version] = aws.ToString(params.SecretString)
+	f.stages[version] = params.VersionStages
+	return &secretsmanager.PutSecretValueOutput{}, nil
+}
+
+func (f *fakeSecrets) UpdateSecretVersionStage(ctx context.Context, params *secretsmanager.UpdateSecretVersionStageInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.UpdateSecretVersionStageOutput, error) {
+	stage := aws.ToString(params.VersionStage)
+	if from := aws.ToString(params.RemoveFromVersionId); from != "" {
+		f.stages[from] = slices.DeleteFunc(f.stages[from], func(s string) bool { return s == stage })
+	}
+	to := aws.ToString(params.MoveToVersionId)
+	f.stages[to] = append(f.stages[to], stage)
+	return &secretsmanager.UpdateSecretVersionStageOutput{}, nil
+}
+
+func rotate(t *testing.T, rotator *Rotator, token string) error {
+	t.Helper()
+	for _, step := range []string{StepCreateSecret, StepSetSecret, StepTestSecret, StepFinishSecret} {
+		if err := rotator.Handle(context.Background(), Event{SecretID: "golf", ClientRequestToken: token, Step: step}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRotator_PromotesVerifiedCredentials(t *testing.T) {
+	store := newFakeSecrets(`{"username":"golfer","password":"hunter2"}`)
+	store.startRotation("v2")
+
+	var verified string
+	rotator := NewRotator(store, func(ctx context.Context, creds *secrets.OAuthCredentials) error {
+		verified = creds.Password
+		return nil
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := rotate(t, rotator, "v2"); err != nil {
+		t.Fatalf("rotation error = %v", err)
+	}
+	if verified != "hunter2" {
+		t.Errorf("verified password = %q, want the current one copied to pending", verified)
+	}
+	if !slices.Contains(store.stages["v2"], stageCurrent) {
+		t.Errorf("v2 stages = %v, want AWSCURRENT", store.stages["v2"])
+	}
+	if slices.Contains(store.stages["v1"], stageCurrent) {
+		t.Errorf("v1 stages = %v, want AWSCURRENT removed", store.stages["v1"])
+	}
+}
+
+func TestRotator_RejectedCredentialsStayPending(t *testing.T) {
+	store := newFakeSecrets(`{"username":"golfer","password":"expired"}`)
+	store.startRotation("v2")
+
+	rotator := NewRotator(store, func(ctx context.Context, creds *secrets.OAuthCredentials) error {
+		return errors.New("HTTP error 401")
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := rotate(t, rotator, "v2"); err == nil {
+		t.Fatal("expected rejected credentials to fail the rotation")
+	}
+	if slices.Contains(store.stages["v2"], stageCurrent) {
+		t.Error("rejected credentials must not become current")
+	}
+	if !slices.Contains(store.stages["v1"], stageCurrent) {
+		t.Error("the previous version must stay current")
+	}
+}
+
+func TestRotator_RequiresRotationEnabled(t *testing.T) {
+	store := newFakeSecrets(`{"username":"golfer","password":"hunter2"}`)
+	store.startRotation("v2")
+	store.rotating = false
+
+	rotator := NewRotator(store, func(ctx context.Context, creds *secrets.OAuthCredentials) error { return nil },
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := rotator.Handle(context.Background(), Event{SecretID: "golf", ClientRequestToken: "v2", Step: StepCreateSecret}); err == nil {
+		t.Error("expected an error when rotation is disabled")
+	}
+}
@@ -1,13 +1,11 @@
 package scheduler
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -16,13 +14,33 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
-	"github.com/jrzesz33/rez_agent/internal/httpclient"
-	"github.com/jrzesz33/rez_agent/internal/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/internal/a2a"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/mcpclient"
 	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/policy"
+	"github.com/jrzesz33/rez_agent/internal/prompts"
+	"github.com/jrzesz33/rez_agent/internal/repository"
 	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
 	"github.com/jrzesz33/rez_agent/pkg/courses"
+	"golang.org/x/sync/errgroup"
 )
 
+const (
+	// defaultMCPToolTimeout is slightly longer than the MCP server's own tool timeout so the
+	// server's timeout error, which the model can act on, normally arrives first
+	defaultMCPToolTimeout = 23 * time.Second
+)
+
+// mcpToolTimeouts overrides defaultMCPToolTimeout for tools the MCP server gives more time
+var mcpToolTimeouts = map[string]time.Duration{
+	"golf_book_tee_time":          28 * time.Second,
+	"golf_search_tee_times_range": 28 * time.Second,
+}
+
 // AgentEventHandler handles scheduled agent operations using AWS Bedrock and MCP tools
 type AgentEventHandler interface {
 	// ExecuteScheduledEvent processes a scheduled agent event with multi-step workflow
@@ -43,26 +61,73 @@ type ScheduledAgentEvent struct {
 	// NumPlayers is the number of players (default: 1)
 	NumPlayers int `json:"num_players,omitempty"`
 
+	// MaxPrice caps the 18-hole green fee per player in dollars (0 = no cap)
+	MaxPrice float64 `json:"max_price,omitempty"`
+
+	// Policy holds hard booking rules enforced outside the LLM conversation
+	Policy *policy.Config `json:"policy,omitempty"`
+
+	// TargetDate is the local date (YYYY-MM-DD) being booked, checked against the policy before the conversation starts
+	TargetDate string `json:"target_date,omitempty"`
+
+	// RequireApproval holds the chosen tee time and waits for the golfer to approve it from the notification
+	RequireApproval bool `json:"require_approval,omitempty"`
+
+	// DryRun prices the chosen tee time and reports what would have been booked without locking
+	// or reserving it, so a new schedule or prompt can be tried safely
+	DryRun bool `json:"dry_run,omitempty"`
+
 	// TriggeredAt is when the event was triggered
 	TriggeredAt time.Time `json:"triggered_at"`
 
 	// AuthConfig contains authentication configuration
 	AuthConfig *models.AuthConfig `json:"auth_config,omitempty" dynamodbav:"auth_config,omitempty"`
+
+	// Owner is the user who created the schedule; the golf tools run with their credentials
+	Owner string `json:"owner,omitempty"`
+
+	// Standing is set for standing tee time schedules and selects the standing prompt template
+	Standing *models.StandingTeeTime `json:"standing,omitempty"`
+
+	// Guardrails limit what the agent may do during this run, on top of the handler's defaults
+	Guardrails *policy.Guardrails `json:"guardrails,omitempty"`
+
+	// RunLimits cap the model calls, tokens and cost of this run
+	models.RunLimits
 }
 
 // AWSAgentEventHandler implements AgentEventHandler using AWS Bedrock
 type AWSAgentEventHandler struct {
-	bedrockClient        *bedrockruntime.Client
+	bedrockClient        bedrockConverser
+	streamer             converseStreamer
 	httpClient           *httpclient.Client
 	secretsManager       *secrets.Manager
 	agentLogger          *AgentLogger
-	mcpServerURL         string
+	mcpClient            *mcpclient.Client
 	stage                string
 	logger               *slog.Logger
 	maxRetries           int
 	retryDelay           time.Duration
+	throttleBudget       time.Duration
 	modelID              string
 	defaultToolArguments map[string]interface{}
+	weatherDecisions     repository.WeatherDecisionRepository
+	preferences          repository.PreferenceRepository
+	runSummaries         *RunSummaryPublisher
+	runSummary           *RunSummary
+	runSummaryLink       string
+	terminalTools        map[string]bool
+	a2aClient            *a2a.Client
+	remoteAgents         []models.RemoteAgent
+	delegates            map[string]remoteDelegate
+	prompts              *prompts.Store
+	defaultGuardrails    *policy.Guardrails
+	bedrockGuardrail     *types.GuardrailConfiguration
+	guardrails           *runGuardrails
+	toolCaller           ToolCaller
+	promptTemplate       string
+	promptData           interface{}
+	chatTools            []protocol.Tool
 }
 
 // NewAWSAgentEventHandler creates a new AWS-based agent event handler
@@ -77,6 +142,8 @@ func NewAWSAgentEventHandler(
 	if mcpURL == "" {
 		mcpURL = "http://localhost:8080/mcp"
 	}
+	mcpClient := mcpclient.New(mcpURL, protocol.MCPClientInfo{Name: "rez-agent-scheduler", Version: "1.0.0"}, logger)
+	mcpClient.SetAPIKey(os.Getenv("MCP_API_KEY"))
 
 	stage := os.Getenv("STAGE")
 	if stage == "" {
@@ -91,20 +158,158 @@ func NewAWSAgentEventHandler(
 
 	return &AWSAgentEventHandler{
 		bedrockClient:  bedrockClient,
+		streamer:       bedrockStreamClient{client: bedrockClient},
 		httpClient:     httpClient,
 		secretsManager: secretsManager,
 		agentLogger:    agentLogger,
-		mcpServerURL:   mcpURL,
+		mcpClient:      mcpClient,
 		stage:          stage,
 		logger:         logger,
 		maxRetries:     3,
 		retryDelay:     5 * time.Second,
+		throttleBudget: defaultThrottleBudget,
 		modelID:        modelID,
+		prompts:        prompts.NewStore(nil, logger),
+	}
+}
+
+// SetWeatherDecisionRepository enables tuning the weather gate from recorded forecast outcomes
+func (h *AWSAgentEventHandler) SetWeatherDecisionRepository(repo repository.WeatherDecisionRepository) {
+	h.weatherDecisions = repo
+}
+
+// maxPrecipChance returns the precipitation threshold for the weather gate, tuned from the
+// last 90 days of recorded decisions when a repository is configured
+func (h *AWSAgentEventHandler) maxPrecipChance(ctx context.Context) int {
+	if h.weatherDecisions == nil {
+		return models.DefaultMaxPrecipChance
+	}
+
+	since := time.Now().AddDate(0, 0, -90).Format("2006-01-02")
+	decisions, err := h.weatherDecisions.ListDecisions(ctx, since)
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to load weather decisions, using default threshold",
+			slog.String("error", err.Error()),
+		)
+		return models.DefaultMaxPrecipChance
+	}
+
+	threshold := models.TuneMaxPrecipChance(decisions, models.DefaultMaxPrecipChance, 5)
+	h.logger.InfoContext(ctx, "weather gate threshold selected",
+		slog.Int("max_precip_chance", threshold),
+		slog.Int("decisions", len(decisions)),
+	)
+	return threshold
+}
+
+// SetPromptStore replaces the embedded-only prompt store, e.g. with one that reads per-stage overrides
+func (h *AWSAgentEventHandler) SetPromptStore(store *prompts.Store) {
+	h.prompts = store
+}
+
+// SetGuardrails sets the limits applied to every run; an event's own guardrails can only tighten them
+func (h *AWSAgentEventHandler) SetGuardrails(guardrails *policy.Guardrails) {
+	h.defaultGuardrails = guardrails
+}
+
+// SetBedrockGuardrail filters every Converse call through an AWS-managed Bedrock guardrail.
+// An empty identifier disables it; an empty version uses the guardrail's working draft.
+func (h *AWSAgentEventHandler) SetBedrockGuardrail(identifier, version string) {
+	if identifier == "" {
+		h.bedrockGuardrail = nil
+		return
+	}
+	if version == "" {
+		version = "DRAFT"
+	}
+	h.bedrockGuardrail = &types.GuardrailConfiguration{
+		GuardrailIdentifier: aws.String(identifier),
+		GuardrailVersion:    aws.String(version),
+	}
+}
+
+// SetThrottleBudget sets how long one run may wait out Bedrock throttling before it stops and
+// reports what it got done; zero or less keeps the default
+func (h *AWSAgentEventHandler) SetThrottleBudget(budget time.Duration) {
+	if budget > 0 {
+		h.throttleBudget = budget
+	}
+}
+
+// SetRunSummaryPublisher enables uploading a human-readable summary of each run and linking it from the notification
+func (h *AWSAgentEventHandler) SetRunSummaryPublisher(publisher *RunSummaryPublisher) {
+	h.runSummaries = publisher
+}
+
+// startRunSummary begins the run's summary and presigns its link so the agent's notification can include it
+func (h *AWSAgentEventHandler) startRunSummary(ctx context.Context, event *ScheduledAgentEvent, executionID string, startTime time.Time) {
+	h.runSummary = NewRunSummary(event, executionID, h.stage, h.modelID, startTime)
+	h.runSummary.CorrelationID = logging.CorrelationID(ctx)
+	h.runSummaryLink = ""
+	if h.runSummaries == nil {
+		return
+	}
+
+	link, err := h.runSummaries.Link(ctx, h.runSummary)
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to create run summary link", slog.String("error", err.Error()))
+		return
+	}
+	h.runSummaryLink = link
+}
+
+// finishRunSummary uploads the run's summary; failures are logged and never fail the run
+func (h *AWSAgentEventHandler) finishRunSummary(ctx context.Context, finalResponse string, runErr error) {
+	if h.runSummaries == nil || h.runSummary == nil {
+		return
+	}
+
+	h.runSummary.Duration = time.Since(h.runSummary.StartedAt)
+	h.runSummary.FinalResponse = finalResponse
+	if runErr != nil {
+		h.runSummary.Error = runErr.Error()
+	}
+
+	if err := h.runSummaries.Publish(ctx, h.runSummary); err != nil {
+		h.logger.WarnContext(ctx, "failed to publish run summary", slog.String("error", err.Error()))
+		return
+	}
+	h.logger.InfoContext(ctx, "run summary published",
+		slog.String("execution_id", h.runSummary.ExecutionID),
+		slog.String("key", h.runSummaries.Key(h.runSummary)),
+	)
+}
+
+// SetPreferenceRepository enables biasing bookings toward courses and times rated well in post-round surveys
+func (h *AWSAgentEventHandler) SetPreferenceRepository(repo repository.PreferenceRepository) {
+	h.preferences = repo
+}
+
+// preferenceSummary returns the golfer's survey-based preferences from the last year for the agent prompt
+func (h *AWSAgentEventHandler) preferenceSummary(ctx context.Context) string {
+	if h.preferences == nil {
+		return models.ComputePreferences(nil).Summary()
+	}
+
+	surveys, err := h.preferences.ListSurveys(ctx, time.Now().AddDate(-1, 0, 0))
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to load round surveys, continuing without preferences",
+			slog.String("error", err.Error()),
+		)
+		return models.ComputePreferences(nil).Summary()
 	}
+
+	return models.ComputePreferences(surveys).Summary()
 }
 
 // ExecuteScheduledEvent processes a scheduled agent event
 func (h *AWSAgentEventHandler) ExecuteScheduledEvent(ctx context.Context, event *ScheduledAgentEvent) error {
+	// The run's logs, MCP calls, and recording share the triggering message's correlation ID
+	if logging.CorrelationID(ctx) == "" {
+		ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	}
+	// Tool calls book with the schedule owner's golf account, not the shared one
+	ctx = models.WithActingUser(ctx, event.Owner)
 
 	// Set default tool arguments
 	defToolArgs := make(map[string]interface{})
@@ -118,7 +323,22 @@ func (h *AWSAgentEventHandler) ExecuteScheduledEvent(ctx context.Context, event
 
 	// Validate event
 	if err := h.validateEvent(event); err != nil {
-		return fmt.Errorf("invalid event: %w", err)
+		return apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid event: %w", err))
+	}
+	rules := newRunRules(event)
+	ctx = withRunRules(ctx, rules)
+
+	// Counted across retries so a retried conversation cannot exceed the run's limits
+	h.guardrails = newRunGuardrails(h.defaultGuardrails.Merge(event.Guardrails), event.NumPlayers, event.RequireApproval)
+
+	// Hard rules are checked before any LLM call so they never depend on prompt adherence
+	if decision := preConversationDecision(event, rules.policy); !decision.Allowed {
+		h.logger.WarnContext(ctx, "scheduled event skipped by booking policy",
+			slog.String("schedule_id", event.ScheduleID),
+			slog.String("target_date", event.TargetDate),
+			slog.String("reason", decision.Err().Error()),
+		)
+		return nil
 	}
 
 	// Execute with retry logic
@@ -143,8 +363,8 @@ func (h *AWSAgentEventHandler) ExecuteScheduledEvent(ctx context.Context, event
 			slog.String("error", err.Error()),
 		)
 
-		// Don't retry on validation or configuration errors
-		if isNonRetryableError(err) {
+		// Don't retry on validation, not found or authentication errors
+		if !apperrors.IsRetryable(err) {
 			h.logger.ErrorContext(ctx, "non-retryable error encountered",
 				slog.String("error", err.Error()),
 			)
@@ -177,6 +397,7 @@ func (h *AWSAgentEventHandler) executeWithContext(ctx context.Context, event *Sc
 	if err != nil {
 		return fmt.Errorf("failed to load MCP tools: %w", err)
 	}
+	tools = append(tools, h.remoteAgentTools(ctx)...)
 
 	// Step 1: Fetch existing reservations
 	h.logger.InfoContext(ctx, "fetching existing reservations")
@@ -197,7 +418,15 @@ func (h *AWSAgentEventHandler) executeWithContext(ctx context.Context, event *Sc
 	}
 
 	// Step 4: Construct system message with context
-	systemMessage := h.constructSystemMessage(event, reservations, weather)
+	var systemMessage string
+	if event.Standing != nil {
+		systemMessage, err = h.constructStandingTeeTimeMessage(ctx, event, reservations, weather)
+	} else {
+		systemMessage, err = h.constructSystemMessage(ctx, event, reservations, weather, h.preferenceSummary(ctx), h.maxPrecipChance(ctx))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to construct system message: %w", err)
+	}
 
 	h.logger.InfoContext(ctx, "system message constructed",
 		slog.Int("system_message_length", len(systemMessage)),
@@ -209,6 +438,26 @@ func (h *AWSAgentEventHandler) executeWithContext(ctx context.Context, event *Sc
 	if err != nil {
 		return fmt.Errorf("agent conversation failed: %w", err)
 	}
+
+	// Step 6: Check what the conversation booked against the hard rules once more. The booking
+	// is already made, so a violation fails the run without a retry and tells the golfer.
+	if err := rulesFromContext(ctx).checkBookings(); err != nil {
+		h.logger.ErrorContext(ctx, "run booked a tee time that breaks the booking policy",
+			slog.String("schedule_id", event.ScheduleID),
+			slog.String("error", err.Error()),
+		)
+		if _, notifyErr := h.callTool(ctx, protocol.ToolCallRequest{
+			Name: "send_notification",
+			Arguments: map[string]interface{}{
+				"title":    "Booking policy violated",
+				"message":  fmt.Sprintf("⚠️ A scheduled run %s. Cancel it if you do not want it.", err),
+				"priority": 4,
+			},
+		}); notifyErr != nil {
+			h.logger.WarnContext(ctx, "failed to send policy violation notification", slog.String("error", notifyErr.Error()))
+		}
+		return apperrors.Wrap(apperrors.ErrValidation, err)
+	}
 	fmt.Println(result)
 	/*/ Step 6: Send notification with results
 	h.logger.InfoContext(ctx, "sending notification with results")
@@ -233,6 +482,21 @@ func (h *AWSAgentEventHandler) executeWithContext(ctx context.Context, event *Sc
 // validateEvent validates the scheduled agent event
 func (h *AWSAgentEventHandler) validateEvent(event *ScheduledAgentEvent) error {
 
+	if event.Standing != nil {
+		if err := event.Standing.Validate(); err != nil {
+			return fmt.Errorf("invalid standing tee time: %w", err)
+		}
+		if event.CourseName == "" {
+			event.CourseName = event.Standing.CourseName
+		}
+		if event.NumPlayers <= 0 {
+			event.NumPlayers = event.Standing.NumberOfPlayers
+		}
+		if event.UserPrompt == "" {
+			event.UserPrompt = standingUserPrompt(event.Standing, event.TriggeredAt)
+		}
+	}
+
 	if event.UserPrompt == "" {
 		return fmt.Errorf("user_prompt is required")
 	}
@@ -252,9 +516,50 @@ func (h *AWSAgentEventHandler) validateEvent(event *ScheduledAgentEvent) error {
 	if event.NumPlayers > 4 {
 		return fmt.Errorf("num_players must be between 1 and 4")
 	}
+	if event.MaxPrice < 0 {
+		return fmt.Errorf("max_price must not be negative")
+	}
+	if event.Policy != nil {
+		if err := event.Policy.Validate(); err != nil {
+			return fmt.Errorf("invalid policy: %w", err)
+		}
+	}
+	if err := event.Guardrails.Validate(); err != nil {
+		return fmt.Errorf("invalid guardrails: %w", err)
+	}
+	if event.TargetDate != "" {
+		if _, err := time.Parse("2006-01-02", event.TargetDate); err != nil {
+			return fmt.Errorf("target_date must be in YYYY-MM-DD format")
+		}
+	}
 	return nil
 }
 
+// preConversationDecision evaluates the event's target date against the run's policy.
+// Events without a target date are allowed; per-tee-time rules are enforced by the golf tools.
+func preConversationDecision(event *ScheduledAgentEvent, cfg *policy.Config) policy.Decision {
+	if event.TargetDate == "" || cfg.IsEmpty() {
+		return policy.Decision{Allowed: true}
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", event.TargetDate, courseLocation(event.CourseName))
+	if err != nil {
+		return policy.Decision{Allowed: true}
+	}
+
+	return policy.NewEngine(cfg).Evaluate(policy.Candidate{TeeTime: date})
+}
+
+// courseLocation returns the named course's time zone, or the default course time zone when the
+// course is unknown. Dates the agent reasons about, such as today and the target date, are in it.
+func courseLocation(courseName string) *time.Location {
+	course, err := courses.GetCourseByName(courseName)
+	if err != nil {
+		course = &courses.Course{}
+	}
+	return course.Location()
+}
+
 // fetchReservations fetches existing golf reservations via MCP
 func (h *AWSAgentEventHandler) fetchReservations(ctx context.Context, courseName string) (string, error) {
 	// Call MCP tool golf_get_reservations
@@ -283,7 +588,7 @@ func (h *AWSAgentEventHandler) getWeather(ctx context.Context, courseName string
 	// Get course configuration to find weather URL
 	course, err := courses.GetCourseByName(courseName)
 	if err != nil {
-		return "", fmt.Errorf("course not found: %w", err)
+		return "", apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("course not found: %w", err))
 	}
 
 	// Get weather URL from course configuration
@@ -296,8 +601,9 @@ func (h *AWSAgentEventHandler) getWeather(ctx context.Context, courseName string
 	req := protocol.ToolCallRequest{
 		Name: "get_weather",
 		Arguments: map[string]interface{}{
-			"location": weatherURL,
-			"days":     7, // Get 7-day forecast for advance booking
+			"course_name": course.Name, // Includes active alerts for the course
+			"location":    weatherURL,
+			"days":        7, // Get 7-day forecast for advance booking
 		},
 	}
 
@@ -314,81 +620,73 @@ func (h *AWSAgentEventHandler) getWeather(ctx context.Context, courseName string
 	return "", fmt.Errorf("no weather data in response")
 }
 
-// getMCPTools loads available tools from the MCP server
+// getMCPTools initializes the MCP session and loads the server's tools
 func (h *AWSAgentEventHandler) getMCPTools(ctx context.Context) ([]protocol.Tool, error) {
-	// First initialize the MCP connection
-	initReq := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  "initialize",
-		"params": map[string]interface{}{
-			"protocolVersion": "2024-11-05",
-			"capabilities":    map[string]interface{}{},
-			"clientInfo": map[string]interface{}{
-				"name":    "rez-agent-scheduler",
-				"version": "1.0.0",
-			},
-		},
-	}
-
-	if err := h.callMCPMethod(ctx, initReq, nil); err != nil {
+	if _, err := h.mcpClient.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("MCP initialize failed: %w", err)
 	}
 
-	// List available tools
-	listReq := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  "tools/list",
-		"params":  map[string]interface{}{},
-	}
-
-	var listResp protocol.ToolsListResult
-	if err := h.callMCPMethod(ctx, listReq, &listResp); err != nil {
+	tools, err := h.mcpClient.ListTools(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("MCP tools/list failed: %w", err)
 	}
 
 	h.logger.InfoContext(ctx, "MCP tools loaded",
-		slog.Int("tool_count", len(listResp.Tools)),
+		slog.Int("tool_count", len(tools)),
 	)
 
-	return listResp.Tools, nil
+	return tools, nil
 }
 
 // constructSystemMessage builds the system prompt with context
-func (h *AWSAgentEventHandler) constructSystemMessage(event *ScheduledAgentEvent, reservations, weather string) string {
-	currentDate := time.Now().Format("Monday, January 2, 2006")
-
-	return fmt.Sprintf(`You are an AI assistant that helps with golf tee time bookings. You have been given a scheduled task to complete autonomously.
-
-CURRENT DATE: %s
-
-IMPORTANT INSTRUCTIONS:
-
-1. The existing reservations and weather are provided, DO NOT book or search for tee times if there is an existing reservation on the requested date
-%s
-
-%s
+func (h *AWSAgentEventHandler) constructSystemMessage(ctx context.Context, event *ScheduledAgentEvent, reservations, weather, preferences string, maxPrecipChance int) (string, error) {
+	return h.renderPrompt(ctx, prompts.ScheduledBooking, prompts.BookingData{
+		CurrentDate:         time.Now().In(courseLocation(event.CourseName)).Format("Monday, January 2, 2006"),
+		Reservations:        reservations,
+		Weather:             weather,
+		Preferences:         preferences,
+		BookingRules:        event.Policy.WithMaxPrice(event.MaxPrice).Describe(),
+		MaxPrecipChance:     maxPrecipChance,
+		NumPlayers:          event.NumPlayers,
+		PriceInstruction:    priceInstruction(event.MaxPrice),
+		ApprovalInstruction: approvalInstruction(event.RequireApproval, event.DryRun),
+	})
+}
 
-2. Consider the weather forecast - DO NOT book tee times if there is inclement weather (rain, storms, severe conditions)
-3. If the user hasn't specified the number of players, use %d player(s)
-4. You should AUTO-BOOK without asking for confirmation - this is a scheduled autonomous task
-5. After booking (or if booking fails), send a push notification with the result
-6. Be specific about what you booked (date, time, course, confirmation number)
-7. If weather is too far in advance and unavailable, you may proceed with booking but mention this in the notification
-8. The Course only allows booking 14 days in advance
+// renderPrompt renders a system prompt template and logs which version was used
+func (h *AWSAgentEventHandler) renderPrompt(ctx context.Context, name string, data interface{}) (string, error) {
+	rendered, err := h.prompts.Render(ctx, name, data)
+	if err != nil {
+		return "", err
+	}
+	h.promptTemplate = name
+	h.promptData = data
 
-AVAILABLE TOOLS:
-- golf_search_tee_times: Search for available tee times and can only search one day per request, (returns tee sheet IDs needed for booking)
-- golf_book_tee_time: Book a specific tee time using the tee_sheet_id from search results
-- golf_get_reservations: Get existing reservations (already called)
-- get_weather: Get weather forecast (already called)
-- send_notification: Send push notification to user
+	h.logger.InfoContext(ctx, "system prompt rendered",
+		slog.String("template", rendered.Name),
+		slog.String("template_version", rendered.Version),
+	)
+	return rendered.Text, nil
+}
 
-IMPORTANT BOOKING WORKFLOW:
-1. First call golf_search_tee_times to find available times
-2. The search results will include a "Tee Sheet ID" for each time slot
-3. Use that tee_sheet_id when calling golf_book_tee_time to complete the booking
+// approvalInstruction tells the agent whether bookings wait for the golfer's approval, or are
+// only dry runs
+func approvalInstruction(requireApproval, dryRun bool) string {
+	if dryRun {
+		return "This is a dry run - golf_book_tee_time only prices the tee time and nothing is booked, so report what would have been booked, not that it was booked"
+	}
+	if !requireApproval {
+		return "Bookings are completed immediately"
+	}
+	return "Bookings require the golfer's approval - golf_book_tee_time only holds the tee time and sends approve/decline buttons, so report it as awaiting approval, not booked"
+}
 
-Now complete this task:`, currentDate, reservations, weather, event.NumPlayers)
+// priceInstruction tells the agent how to apply the event's price cap
+func priceInstruction(maxPrice float64) string {
+	if maxPrice <= 0 {
+		return "There is no price cap for this booking"
+	}
+	return fmt.Sprintf("DO NOT book tee times with an 18-hole green fee above $%.2f per player - pass max_price=%.2f to golf_search_tee_times and golf_book_tee_time", maxPrice, maxPrice)
 }
 
 // executeAgentConversation runs the multi-step conversation loop with Bedrock
@@ -399,10 +697,19 @@ func (h *AWSAgentEventHandler) executeAgentConversation(
 	reservations string,
 	weather string,
 	tools []protocol.Tool,
-) (string, error) {
+) (finalResponse string, err error) {
 	startTime := time.Now()
 	executionID := fmt.Sprintf("%d", startTime.UnixNano())
 
+	h.startRunSummary(ctx, event, executionID, startTime)
+	h.runSummary.PromptTemplate = h.promptTemplate
+	h.runSummary.PromptData = h.promptData
+	h.runSummary.SystemMessage = systemMsg
+	h.runSummary.Tools = tools
+	defer func() {
+		h.finishRunSummary(ctx, finalResponse, err)
+	}()
+
 	h.logger.InfoContext(ctx, "executing agent conversation",
 		slog.String("model", h.modelID),
 		slog.Int("tools_available", len(tools)),
@@ -442,6 +749,7 @@ func (h *AWSAgentEventHandler) executeAgentConversation(
 
 	// Convert MCP tools to Bedrock tool specifications
 	bedrockTools := h.convertMCPToolsToBedrock(tools)
+	h.terminalTools = terminalToolNames(tools)
 
 	// Initialize conversation with system message and user prompt
 	messages := []types.Message{
@@ -458,18 +766,32 @@ func (h *AWSAgentEventHandler) executeAgentConversation(
 	// Track stop reasons for conversation log
 	stopReasons := make([]types.StopReason, 0)
 
+	// Throttling is waited out across the whole run rather than per call
+	backoff := newThrottleBackoff(throttleBaseDelay, throttleMaxDelay, h.throttleBudget)
+
+	// The schedule's limits are checked before each call, so a run stops before it goes over them
+	budget := newRunBudget(event.RunLimits, h.modelID)
+	if event.MaxCost > 0 && !budget.pricingKnown() {
+		h.logger.WarnContext(ctx, "max_cost is not enforced for a model without known pricing",
+			slog.String("model", h.modelID),
+		)
+	}
+
 	// Conversation loop - continue until no more tool calls
-	const maxIterations = 10 // Safety limit
-	var finalResponse string
+	maxIterations := budget.maxIterations()
 
 	for iteration := 0; iteration < maxIterations; iteration++ {
+		if reason := budget.exceeded(); reason != "" {
+			return h.stopRun(ctx, reason, messages), nil
+		}
+
 		h.logger.InfoContext(ctx, "bedrock conversation iteration",
 			slog.Int("iteration", iteration+1),
 			slog.Int("message_count", len(messages)),
 		)
 
 		// Call Bedrock Converse API
-		converseOutput, err := h.bedrockClient.Converse(ctx, &bedrockruntime.ConverseInput{
+		converseOutput, err := h.converse(ctx, backoff, &bedrockruntime.ConverseInput{
 			ModelId: aws.String(h.modelID),
 			System: []types.SystemContentBlock{
 				&types.SystemContentBlockMemberText{
@@ -484,11 +806,21 @@ func (h *AWSAgentEventHandler) executeAgentConversation(
 				MaxTokens:   maxTokens,
 				Temperature: temperature,
 			},
+			GuardrailConfig: h.bedrockGuardrail,
 		})
 
+		if errors.Is(err, errThrottleBudgetExhausted) {
+			// Rerunning the schedule would repeat tool calls already made, so report what was done
+			h.logger.WarnContext(ctx, "bedrock throttling stopped the run", slog.String("error", err.Error()))
+			return h.stopRun(ctx, "Bedrock kept throttling requests", messages), nil
+		}
 		if err != nil {
 			return "", fmt.Errorf("bedrock converse failed: %w", err)
 		}
+		if converseOutput.Usage != nil {
+			h.runSummary.AddUsage(converseOutput.Usage.InputTokens, converseOutput.Usage.OutputTokens)
+		}
+		budget.record(converseOutput.Usage)
 
 		// Add assistant response to conversation history
 		messages = append(messages, types.Message{
@@ -504,8 +836,17 @@ func (h *AWSAgentEventHandler) executeAgentConversation(
 			slog.String("stop_reason", string(stopReason)),
 		)
 
+		// The guardrail replaced the response with its blocked message, which ends the conversation
+		if stopReason == types.StopReasonGuardrailIntervened || stopReason == types.StopReasonContentFiltered {
+			h.logger.WarnContext(ctx, "bedrock guardrail intervened",
+				slog.String("stop_reason", string(stopReason)),
+				slog.Int("iteration", iteration+1),
+			)
+		}
+
 		// If no tool use, we're done
-		if stopReason == types.StopReasonEndTurn || stopReason == types.StopReasonMaxTokens {
+		if stopReason == types.StopReasonEndTurn || stopReason == types.StopReasonMaxTokens ||
+			stopReason == types.StopReasonGuardrailIntervened || stopReason == types.StopReasonContentFiltered {
 			// Extract final text response
 			finalResponse = h.extractTextFromMessage(converseOutput.Output.(*types.ConverseOutputMemberMessage).Value)
 			break
@@ -528,8 +869,8 @@ func (h *AWSAgentEventHandler) executeAgentConversation(
 				if toolUse, ok := block.(*types.ContentBlockMemberToolUse); ok {
 					toolName := *toolUse.Value.Name
 
-					// If the tool was send_notification, we can end here
-					if toolName == "send_push_notification" {
+					// A terminal tool (the result notification) completes the run
+					if h.terminalTools[toolName] {
 						finalResponse = h.extractTextFromMessage(converseOutput.Output.(*types.ConverseOutputMemberMessage).Value)
 
 						// Log conversation history to S3
@@ -569,7 +910,7 @@ func (h *AWSAgentEventHandler) executeAgentConversation(
 	}
 
 	if finalResponse == "" {
-		return "", fmt.Errorf("conversation ended without final response after %d iterations", maxIterations)
+		return h.stopRun(ctx, fmt.Sprintf("it reached its limit of %d model calls", maxIterations), messages), nil
 	}
 
 	h.logger.InfoContext(ctx, "agent conversation completed",
@@ -607,187 +948,281 @@ func (h *AWSAgentEventHandler) convertMCPToolsToBedrock(mcpTools []protocol.Tool
 	return bedrockTools
 }
 
-// processToolCalls executes tool calls requested by Bedrock
-func (h *AWSAgentEventHandler) processToolCalls(ctx context.Context, content []types.ContentBlock) ([]types.ContentBlock, error) {
-	results := make([]types.ContentBlock, 0)
+// terminalToolNames returns the names and aliases of the tools whose call completes the conversation
+func terminalToolNames(mcpTools []protocol.Tool) map[string]bool {
+	terminal := make(map[string]bool)
+	for _, tool := range mcpTools {
+		if !tool.IsTerminal() {
+			continue
+		}
+		for _, name := range tool.Names() {
+			terminal[name] = true
+		}
+	}
+	return terminal
+}
 
-	for _, block := range content {
-		if toolUse, ok := block.(*types.ContentBlockMemberToolUse); ok {
-			toolName := *toolUse.Value.Name
-			toolUseID := *toolUse.Value.ToolUseId
+// policyEnforcedTools are the MCP tools that receive the active booking policy
+var policyEnforcedTools = map[string]bool{
+	"golf_search_tee_times":       true,
+	"golf_search_tee_times_range": true,
+	"golf_book_tee_time":          true,
+	"check_constraints":           true,
+	"explain_decision":            true,
+}
 
-			h.logger.InfoContext(ctx, "executing MCP tool",
-				slog.String("tool_name", toolName),
-				slog.String("tool_use_id", toolUseID),
-			)
+// dryRunTools are the MCP tools that can book, and so receive the event's dry run flag
+var dryRunTools = map[string]bool{
+	"golf_search_tee_times": true,
+	"golf_book_tee_time":    true,
+}
 
-			// Parse input arguments - Bedrock uses document.Interface
-			var args map[string]interface{}
-			if toolUse.Value.Input != nil {
+// maxParallelToolCalls caps how many tool calls from one model turn run at once
+const maxParallelToolCalls = 4
 
-				bytes, err := toolUse.Value.Input.MarshalSmithyDocument()
-				if err != nil {
-					return nil, fmt.Errorf("failed to unmarshal tool input: %w", err)
-				}
-				err = json.Unmarshal(bytes, &args)
-				if err != nil {
-					return nil, fmt.Errorf("failed to unmarshal tool input JSON: %w", err)
-				}
-				// Add default tool arguments
-				for k, v := range h.defaultToolArguments {
-					if args[k] != nil {
-						args[k] = v
-					}
-				}
+// sequentialTools have side effects that must not overlap other calls, so they run one at a time
+// after the turn's independent calls finish
+var sequentialTools = map[string]bool{
+	"golf_book_tee_time": true,
+}
 
-			}
+// pendingToolCall is a tool call requested by the model, with its arguments prepared
+type pendingToolCall struct {
+	toolUseID string
+	request   protocol.ToolCallRequest
+	result    *protocol.ToolCallResult
+	err       error
+	duration  time.Duration
 
-			// Call MCP tool
-			mcpReq := protocol.ToolCallRequest{
-				Name:      toolName,
-				Arguments: args,
-			}
+	// committedSpend is the most a booking admitted by the guardrails can cost
+	committedSpend float64
+}
 
-			mcpResult, err := h.callMCPTool(ctx, mcpReq)
+// processToolCalls executes tool calls requested by Bedrock. Independent calls run concurrently;
+// calls with side effects run afterwards, one at a time. Results keep the order the model requested.
+func (h *AWSAgentEventHandler) processToolCalls(ctx context.Context, content []types.ContentBlock) ([]types.ContentBlock, error) {
+	calls := make([]*pendingToolCall, 0)
+	for _, block := range content {
+		if toolUse, ok := block.(*types.ContentBlockMemberToolUse); ok {
+			call, err := h.prepareToolCall(ctx, toolUse.Value)
 			if err != nil {
-				h.logger.ErrorContext(ctx, "MCP tool execution failed",
-					slog.String("tool_name", toolName),
-					slog.String("error", err.Error()),
-				)
-
-				// Return error as tool result
-				results = append(results, &types.ContentBlockMemberToolResult{
-					Value: types.ToolResultBlock{
-						ToolUseId: aws.String(toolUseID),
-						Content: []types.ToolResultContentBlock{
-							&types.ToolResultContentBlockMemberText{
-								Value: fmt.Sprintf("Error: %s", err.Error()),
-							},
-						},
-						Status: types.ToolResultStatusError,
-					},
-				})
-				continue
-			}
-
-			// Convert MCP result to Bedrock format
-			toolResultContent := make([]types.ToolResultContentBlock, 0, len(mcpResult.Content))
-			for _, content := range mcpResult.Content {
-				toolResultContent = append(toolResultContent, &types.ToolResultContentBlockMemberText{
-					Value: content.Text,
-				})
+				return nil, err
 			}
+			calls = append(calls, call)
+		}
+	}
 
-			results = append(results, &types.ContentBlockMemberToolResult{
-				Value: types.ToolResultBlock{
-					ToolUseId: aws.String(toolUseID),
-					Content:   toolResultContent,
-					Status:    types.ToolResultStatusSuccess,
-				},
-			})
-
-			h.logger.InfoContext(ctx, "MCP tool executed successfully",
-				slog.String("tool_name", toolName),
-			)
+	var sequential []*pendingToolCall
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxParallelToolCalls)
+	for _, call := range calls {
+		if sequentialTools[call.request.Name] || h.terminalTools[call.request.Name] {
+			sequential = append(sequential, call)
+			continue
 		}
+		g.Go(func() error {
+			h.executeToolCall(gctx, call)
+			return nil
+		})
+	}
+	// Tool failures are returned to the model as results, so the group itself never fails
+	_ = g.Wait()
+	for _, call := range sequential {
+		h.executeToolCall(ctx, call)
 	}
 
+	results := make([]types.ContentBlock, 0, len(calls))
+	for _, call := range calls {
+		results = append(results, h.toolResultBlock(call))
+	}
 	return results, nil
 }
 
-// extractTextFromMessage extracts text content from Bedrock message
-func (h *AWSAgentEventHandler) extractTextFromMessage(msg types.Message) string {
-	var texts []string
+// prepareToolCall parses the model's tool input and applies the event's defaults and the run's
+// hard rules
+func (h *AWSAgentEventHandler) prepareToolCall(ctx context.Context, toolUse types.ToolUseBlock) (*pendingToolCall, error) {
+	toolName := aws.ToString(toolUse.Name)
 
-	for _, block := range msg.Content {
-		if textBlock, ok := block.(*types.ContentBlockMemberText); ok {
-			texts = append(texts, textBlock.Value)
-		}
-	}
+	// Parse input arguments - Bedrock uses document.Interface
+	var args map[string]interface{}
+	if toolUse.Input != nil {
 
-	return strings.Join(texts, "\n")
-}
+		bytes, err := toolUse.Input.MarshalSmithyDocument()
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tool input: %w", err)
+		}
+		err = json.Unmarshal(bytes, &args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tool input JSON: %w", err)
+		}
+		// Add default tool arguments
+		for k, v := range h.defaultToolArguments {
+			if args[k] != nil {
+				args[k] = v
+			}
+		}
 
-// callMCPTool calls an MCP tool and returns the result
-func (h *AWSAgentEventHandler) callMCPTool(ctx context.Context, req protocol.ToolCallRequest) (*protocol.ToolCallResult, error) {
-	var result protocol.ToolCallResult
+	}
 
-	reqMap := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  "tools/call",
-		"params":  req,
+	// Always enforce the run's hard rules, whatever the model passed
+	rules := rulesFromContext(ctx)
+	if policyEnforcedTools[toolName] && !rules.policy.IsEmpty() {
+		if args == nil {
+			args = make(map[string]interface{})
+		}
+		args["policy"] = rules.policy
+	}
+	if toolName == "golf_book_tee_time" && rules.requireApproval {
+		if args == nil {
+			args = make(map[string]interface{})
+		}
+		args["require_approval"] = true
+	}
+	if dryRunTools[toolName] && rules.dryRun {
+		if args == nil {
+			args = make(map[string]interface{})
+		}
+		args["dry_run"] = true
 	}
 
-	if err := h.callMCPMethod(ctx, reqMap, &result); err != nil {
-		return nil, err
+	// Link the run summary from the final notification
+	if h.terminalTools[toolName] && h.runSummaryLink != "" {
+		if args == nil {
+			args = make(map[string]interface{})
+		}
+		message, _ := args["message"].(string)
+		args["message"] = fmt.Sprintf("%s\n\nRun summary: %s", message, h.runSummaryLink)
 	}
 
-	return &result, nil
+	return &pendingToolCall{
+		toolUseID: aws.ToString(toolUse.ToolUseId),
+		request: protocol.ToolCallRequest{
+			Name:      toolName,
+			Arguments: args,
+		},
+	}, nil
 }
 
-// callMCPMethod makes an HTTP call to the MCP server
-func (h *AWSAgentEventHandler) callMCPMethod(ctx context.Context, reqData map[string]interface{}, respData interface{}) error {
-	jsonData, err := json.Marshal(reqData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
-	}
+// executeToolCall calls the MCP tool, or delegates to a remote agent, and records how long it took.
+// It is safe to run concurrently: it only writes to its own call.
+func (h *AWSAgentEventHandler) executeToolCall(ctx context.Context, call *pendingToolCall) {
+	h.logger.InfoContext(ctx, "executing MCP tool",
+		slog.String("tool_name", call.request.Name),
+		slog.String("tool_use_id", call.toolUseID),
+	)
 
-	req, err := http.NewRequestWithContext(ctx, "POST", h.mcpServerURL, bytes.NewReader(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	if h.guardrails != nil {
+		if err := h.guardrails.admit(call); err != nil {
+			h.logger.WarnContext(ctx, "tool call blocked by guardrail",
+				slog.String("tool_name", call.request.Name),
+				slog.String("tool_use_id", call.toolUseID),
+				slog.String("error", err.Error()),
+			)
+			call.err = err
+			return
+		}
+		defer h.guardrails.settle(call)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	start := time.Now()
+	call.result, call.err = h.callTool(ctx, call.request)
+	call.duration = time.Since(start)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("MCP request failed: %w", err)
+	if call.err != nil {
+		h.logger.ErrorContext(ctx, "MCP tool execution failed",
+			slog.String("tool_name", call.request.Name),
+			slog.String("tool_use_id", call.toolUseID),
+			slog.Duration("duration", call.duration),
+			slog.String("error", call.err.Error()),
+		)
+		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("MCP server returned status %d", resp.StatusCode)
+	h.logger.InfoContext(ctx, "MCP tool executed successfully",
+		slog.String("tool_name", call.request.Name),
+		slog.String("tool_use_id", call.toolUseID),
+		slog.Duration("duration", call.duration),
+	)
+	if dryRunTools[call.request.Name] {
+		rulesFromContext(ctx).recordBookings(call.result)
 	}
+}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("error reading response body: %v", err)
+// toolResultBlock converts a finished call to a Bedrock tool result and records it in the run summary
+func (h *AWSAgentEventHandler) toolResultBlock(call *pendingToolCall) types.ContentBlock {
+	if call.err != nil {
+		if h.runSummary != nil {
+			h.runSummary.RecordToolCall(call.request.Name, call.request.Arguments, call.err.Error(), true, call.duration)
+		}
+
+		// Return error as tool result
+		return &types.ContentBlockMemberToolResult{
+			Value: types.ToolResultBlock{
+				ToolUseId: aws.String(call.toolUseID),
+				Content: []types.ToolResultContentBlock{
+					&types.ToolResultContentBlockMemberText{
+						Value: fmt.Sprintf("Error: %s", call.err.Error()),
+					},
+				},
+				Status: types.ToolResultStatusError,
+			},
+		}
 	}
 
-	var respMap map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &respMap); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+	// Convert MCP result to Bedrock format
+	toolResultContent := make([]types.ToolResultContentBlock, 0, len(call.result.Content))
+	resultTexts := make([]string, 0, len(call.result.Content))
+	for _, content := range call.result.Content {
+		toolResultContent = append(toolResultContent, &types.ToolResultContentBlockMemberText{
+			Value: content.Text,
+		})
+		resultTexts = append(resultTexts, content.Text)
+	}
+	if h.runSummary != nil {
+		h.runSummary.RecordToolCall(call.request.Name, call.request.Arguments, strings.Join(resultTexts, "\n"), false, call.duration)
 	}
 
-	if errObj, exists := respMap["error"]; exists {
-		errBytes, _ := json.Marshal(errObj)
-		return fmt.Errorf("MCP error: %s", string(errBytes))
+	return &types.ContentBlockMemberToolResult{
+		Value: types.ToolResultBlock{
+			ToolUseId: aws.String(call.toolUseID),
+			Content:   toolResultContent,
+			Status:    types.ToolResultStatusSuccess,
+		},
 	}
+}
 
-	// Convert the byte slice to a string
-	//bodyString := string(bodyBytes)
+// extractTextFromMessage extracts text content from Bedrock message
+func (h *AWSAgentEventHandler) extractTextFromMessage(msg types.Message) string {
+	var texts []string
 
-	// Print the string representation of the response body
-	//fmt.Println(bodyString)
+	for _, block := range msg.Content {
+		if textBlock, ok := block.(*types.ContentBlockMemberText); ok {
+			texts = append(texts, textBlock.Value)
+		}
+	}
 
-	if respData != nil {
+	return strings.Join(texts, "\n")
+}
 
-		var rpcResp protocol.JSONRPCResponse
-		err = json.Unmarshal(bodyBytes, &rpcResp)
-		if err != nil {
-			return fmt.Errorf("failed to unmarshal JSON-RPC response: %w", err)
-		}
+// callMCPTool calls an MCP tool and returns the result. The call is bounded by the tool's
+// timeout so a hung tool cannot consume the rest of the scheduler's Lambda timeout.
+func (h *AWSAgentEventHandler) callMCPTool(ctx context.Context, req protocol.ToolCallRequest) (*protocol.ToolCallResult, error) {
+	timeout, ok := mcpToolTimeouts[req.Name]
+	if !ok {
+		timeout = defaultMCPToolTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-		err = json.Unmarshal(rpcResp.Result, respData)
-		if err != nil {
-			return fmt.Errorf("failed to unmarshal result: %w", err)
+	result, err := h.mcpClient.CallTool(ctx, req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("MCP tool %s timed out after %s: %w", req.Name, timeout, err)
 		}
-		//if err := json.NewDecoder(resp.Body).Decode(respData); err != nil {
-		//	return fmt.Errorf("failed to decode response: %w", err)
-		//}
+		return nil, err
 	}
 
-	return nil
+	return result, nil
 }
 
 /*/ sendNotification sends a push notification with the booking result
@@ -842,27 +1277,3 @@ func (h *AWSAgentEventHandler) checkWeatherForReservations(ctx context.Context,
 	return nil
 }
 // */
-// isNonRetryableError checks if an error should not be retried
-func isNonRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errMsg := strings.ToLower(err.Error())
-
-	// Configuration errors
-	if strings.Contains(errMsg, "invalid") ||
-		strings.Contains(errMsg, "not found") ||
-		strings.Contains(errMsg, "required") {
-		return true
-	}
-
-	// Authentication errors
-	if strings.Contains(errMsg, "unauthorized") ||
-		strings.Contains(errMsg, "forbidden") ||
-		strings.Contains(errMsg, "authentication") {
-		return true
-	}
-
-	return false
-}
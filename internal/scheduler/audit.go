@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// AuditingScheduler records every schedule EventBridge creates or deletes in the audit log. It
+// wraps the scheduler itself, so schedules made by the API, the MCP tools and agents are all
+// covered.
+type AuditingScheduler struct {
+	next     EventBridgeScheduler
+	recorder *audit.Recorder
+}
+
+// NewAuditingScheduler wraps next so that its successful changes are recorded
+func NewAuditingScheduler(next EventBridgeScheduler, recorder *audit.Recorder) *AuditingScheduler {
+	return &AuditingScheduler{
+		next:     next,
+		recorder: recorder,
+	}
+}
+
+// CreateSchedule creates the schedule and records who it was created for
+func (s *AuditingScheduler) CreateSchedule(ctx context.Context, schedule *models.Schedule) (string, error) {
+	arn, err := s.next.CreateSchedule(ctx, schedule)
+	if err != nil {
+		return "", err
+	}
+
+	s.recorder.Record(ctx, audit.Event{
+		Action: models.AuditActionScheduleCreation,
+		UserID: schedule.CreatedBy,
+		Target: schedule.Name,
+		Details: map[string]string{
+			"schedule_id":         schedule.ID,
+			"schedule_arn":        arn,
+			"schedule_expression": schedule.ScheduleExpression,
+			"target_type":         string(schedule.TargetType),
+		},
+	})
+	return arn, nil
+}
+
+// DeleteSchedule deletes the named schedule and records the deletion
+func (s *AuditingScheduler) DeleteSchedule(ctx context.Context, name string) error {
+	if err := s.next.DeleteSchedule(ctx, name); err != nil {
+		return err
+	}
+
+	s.recorder.Record(ctx, audit.Event{
+		Action: models.AuditActionScheduleDeletion,
+		Target: name,
+	})
+	return nil
+}
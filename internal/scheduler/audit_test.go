@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+type fakeEventBridge struct {
+	err error
+}
+
+func (f *fakeEventBridge) CreateSchedule(ctx context.Context, schedule *models.Schedule) (string, error) {
+	return "arn:aws:scheduler:::schedule/default/" + schedule.Name, f.err
+}
+
+func (f *fakeEventBridge) DeleteSchedule(ctx context.Context, name string) error {
+	return f.err
+}
+
+func TestAuditingScheduler(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryAuditRepository()
+	recorder := audit.NewRecorder(repo, "scheduler", slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	eventBridge := &fakeEventBridge{}
+	scheduler := NewAuditingScheduler(eventBridge, recorder)
+	schedule := &models.Schedule{ID: "sched_1", Name: "weekly-search", ScheduleExpression: "cron(0 7 ? * MON *)", CreatedBy: "usr_1"}
+	if _, err := scheduler.CreateSchedule(ctx, schedule); err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+	if err := scheduler.DeleteSchedule(ctx, "weekly-search"); err != nil {
+		t.Fatalf("DeleteSchedule() error = %v", err)
+	}
+
+	// Failed changes leave no trace in the audit log
+	eventBridge.err = errors.New("throttled")
+	if _, err := scheduler.CreateSchedule(ctx, schedule); err == nil {
+		t.Error("CreateSchedule() error = nil, want EventBridge's error")
+	}
+
+	entries, _ := repo.ListEntries(ctx, time.Time{})
+	if len(entries) != 2 {
+		t.Fatalf("recorded %d entries, want 2", len(entries))
+	}
+	created := entries[0]
+	if entries[1].Action == models.AuditActionScheduleCreation {
+		created = entries[1]
+	}
+	if created.Action != models.AuditActionScheduleCreation || created.UserID != "usr_1" || created.Details["schedule_arn"] == "" {
+		t.Errorf("creation entry = %+v, want usr_1's schedule with its ARN", created)
+	}
+}
@@ -0,0 +1,234 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/jrzesz33/rez_agent/internal/prompts"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// chatPricing prices chat turns on a model bedrockPricing does not know, such as an inference
+// profile, at Claude 3.5 Sonnet rates so the agent's daily spending cap still applies
+var chatPricing = modelPricing{InputPer1K: 0.003, OutputPer1K: 0.015}
+
+// ChatRequest is one user turn of an interactive chat session
+type ChatRequest struct {
+	// History is the session's earlier messages as role/content pairs
+	History []map[string]interface{}
+	Message string
+
+	// ConsentTools need the user's approval before they run; ApprovedTools are the ones the user
+	// has approved for the session
+	ConsentTools  []string
+	ApprovedTools []string
+
+	// OnText, when set, streams the turn: it receives the model's text as it is generated,
+	// including any it writes before calling tools
+	OnText func(text string)
+}
+
+// ChatResponse is the agent's answer to a chat turn
+type ChatResponse struct {
+	Message string
+
+	// Messages are the turn's user and assistant messages, to append to the session
+	Messages []map[string]interface{}
+
+	// PendingConsent lists the tools the model asked to use that the user must approve first.
+	// None of the turn's tool calls ran.
+	PendingConsent []string
+
+	InputTokens  int64
+	OutputTokens int64
+	// Cost is the turn's estimated Bedrock cost in dollars
+	Cost float64
+}
+
+// Chat answers one turn of an interactive chat session. It runs the same Bedrock conversation
+// loop and MCP tools as scheduled runs, but with no booking policy, dry run or approval forced on
+// the tools: the user confirms bookings in the conversation, and a tool that needs consent stops
+// the turn until the user approves it.
+func (h *AWSAgentEventHandler) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	h.defaultToolArguments = nil
+	h.runSummary = nil
+	h.runSummaryLink = ""
+	h.terminalTools = nil
+	h.delegates = nil
+	h.guardrails = newRunGuardrails(h.defaultGuardrails, 1, false)
+
+	if h.chatTools == nil {
+		tools, err := h.getMCPTools(ctx)
+		if err != nil {
+			return nil, err
+		}
+		h.chatTools = tools
+	}
+
+	systemMsg, err := h.renderPrompt(ctx, prompts.InteractiveChat, prompts.ChatData{
+		CurrentTime: time.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
+		Courses:     courseSummary(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := chatMessages(req.History, req.Message)
+	bedrockTools := h.convertMCPToolsToBedrock(h.chatTools)
+	backoff := newThrottleBackoff(throttleBaseDelay, throttleMaxDelay, h.throttleBudget)
+	response := &ChatResponse{}
+
+	var toolConfig *types.ToolConfiguration
+	if len(bedrockTools) > 0 {
+		toolConfig = &types.ToolConfiguration{Tools: bedrockTools}
+	}
+
+	for iteration := 0; iteration < defaultMaxIterations; iteration++ {
+		input := &bedrockruntime.ConverseInput{
+			ModelId: aws.String(h.modelID),
+			System: []types.SystemContentBlock{
+				&types.SystemContentBlockMemberText{Value: systemMsg},
+			},
+			Messages:   messages,
+			ToolConfig: toolConfig,
+			InferenceConfig: &types.InferenceConfiguration{
+				MaxTokens:   aws.Int32(4096),
+				Temperature: aws.Float32(0.5),
+			},
+			GuardrailConfig: h.bedrockGuardrail,
+		}
+		var output *bedrockruntime.ConverseOutput
+		if req.OnText != nil && h.streamer != nil {
+			output, err = h.converseStream(ctx, backoff, input, req.OnText)
+		} else {
+			output, err = h.converse(ctx, backoff, input)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bedrock converse failed: %w", err)
+		}
+		response.addUsage(h.modelID, output.Usage)
+
+		reply := output.Output.(*types.ConverseOutputMemberMessage).Value
+		messages = append(messages, types.Message{Role: types.ConversationRoleAssistant, Content: reply.Content})
+
+		if output.StopReason != types.StopReasonToolUse {
+			return response.finish(req.Message, h.extractTextFromMessage(reply)), nil
+		}
+
+		if pending := toolsNeedingConsent(reply.Content, req.ConsentTools, req.ApprovedTools); len(pending) > 0 {
+			h.logger.InfoContext(ctx, "waiting for user consent to use tools", slog.Any("tools", pending))
+			response.PendingConsent = pending
+			return response.finish(req.Message, ConsentPrompt(pending)), nil
+		}
+
+		results, err := h.processToolCalls(ctx, reply.Content)
+		if err != nil {
+			return nil, fmt.Errorf("tool execution failed: %w", err)
+		}
+		messages = append(messages, types.Message{Role: types.ConversationRoleUser, Content: results})
+	}
+
+	h.logger.WarnContext(ctx, "chat turn reached its model call limit", slog.Int("limit", defaultMaxIterations))
+	return response.finish(req.Message, h.partialResponse(fmt.Sprintf("it reached its limit of %d model calls", defaultMaxIterations), messages)), nil
+}
+
+// addUsage records a model call's token usage and estimated cost
+func (r *ChatResponse) addUsage(modelID string, usage *types.TokenUsage) {
+	if usage == nil {
+		return
+	}
+	input := int64(aws.ToInt32(usage.InputTokens))
+	output := int64(aws.ToInt32(usage.OutputTokens))
+	r.InputTokens += input
+	r.OutputTokens += output
+
+	cost, ok := estimateCost(modelID, input, output)
+	if !ok {
+		cost = float64(input)/1000*chatPricing.InputPer1K + float64(output)/1000*chatPricing.OutputPer1K
+	}
+	r.Cost += cost
+}
+
+// finish sets the turn's answer and the messages it adds to the session
+func (r *ChatResponse) finish(userMessage, answer string) *ChatResponse {
+	r.Message = answer
+	r.Messages = []map[string]interface{}{{"role": "user", "content": userMessage}}
+	if answer != "" {
+		r.Messages = append(r.Messages, map[string]interface{}{"role": "assistant", "content": answer})
+	}
+	return r
+}
+
+// chatMessages converts the session history and the new user message to a Bedrock conversation.
+// Consecutive messages from the same role are merged, since Bedrock requires the roles to
+// alternate, and the conversation always opens with the user.
+func chatMessages(history []map[string]interface{}, userMessage string) []types.Message {
+	var messages []types.Message
+	add := func(role types.ConversationRole, text string) {
+		if text == "" || (len(messages) == 0 && role != types.ConversationRoleUser) {
+			return
+		}
+		block := &types.ContentBlockMemberText{Value: text}
+		if last := len(messages) - 1; last >= 0 && messages[last].Role == role {
+			messages[last].Content = append(messages[last].Content, block)
+			return
+		}
+		messages = append(messages, types.Message{Role: role, Content: []types.ContentBlock{block}})
+	}
+
+	for _, message := range history {
+		text, _ := message["content"].(string)
+		switch message["role"] {
+		case "user":
+			add(types.ConversationRoleUser, text)
+		case "assistant":
+			add(types.ConversationRoleAssistant, text)
+		}
+	}
+	add(types.ConversationRoleUser, userMessage)
+	return messages
+}
+
+// toolsNeedingConsent returns the tools the model asked to use that need consent and have not
+// been approved, in the order it asked for them
+func toolsNeedingConsent(content []types.ContentBlock, consentTools, approvedTools []string) []string {
+	var pending []string
+	for _, block := range content {
+		toolUse, ok := block.(*types.ContentBlockMemberToolUse)
+		if !ok {
+			continue
+		}
+		name := aws.ToString(toolUse.Value.Name)
+		if slices.Contains(consentTools, name) && !slices.Contains(approvedTools, name) && !slices.Contains(pending, name) {
+			pending = append(pending, name)
+		}
+	}
+	return pending
+}
+
+// ConsentPrompt asks the user to approve tools before the agent uses them
+func ConsentPrompt(tools []string) string {
+	return fmt.Sprintf("I need your permission before using %s, which can book tee times for you. "+
+		"Approve it for this session to continue, or tell me what to change.", strings.Join(tools, ", "))
+}
+
+// courseSummary describes the supported courses for the chat system prompt
+func courseSummary() string {
+	config, err := courses.LoadCourses()
+	if err != nil {
+		return "Course list unavailable - call golf_list_courses"
+	}
+	lines := make([]string, 0, len(config.Courses))
+	for _, course := range config.Courses {
+		lines = append(lines, fmt.Sprintf("- %s (course ID %d): %s; bookable %d days ahead; times are %s",
+			course.Name, course.CourseID, course.Address, course.GetBookingWindowDays(), course.GetTimezone()))
+	}
+	return strings.Join(lines, "\n")
+}
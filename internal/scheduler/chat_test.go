@@ -0,0 +1,230 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// bookingConverser asks to book a tee time, then ends the turn once it has the tool's result
+type bookingConverser struct {
+	inputs []*bedrockruntime.ConverseInput
+}
+
+func (f *bookingConverser) Converse(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error) {
+	f.inputs = append(f.inputs, params)
+	message := types.Message{Role: types.ConversationRoleAssistant, Content: []types.ContentBlock{toolUse("book", "golf_book_tee_time")}}
+	stopReason := types.StopReasonToolUse
+	if len(f.inputs) > 1 {
+		message.Content = []types.ContentBlock{&types.ContentBlockMemberText{Value: "Booked 8:10 AM"}}
+		stopReason = types.StopReasonEndTurn
+	}
+	return &bedrockruntime.ConverseOutput{
+		Output:     &types.ConverseOutputMemberMessage{Value: message},
+		StopReason: stopReason,
+		Usage:      &types.TokenUsage{InputTokens: aws.Int32(1000), OutputTokens: aws.Int32(100)},
+	}, nil
+}
+
+func TestAWSAgentEventHandler_Chat(t *testing.T) {
+	history := []map[string]interface{}{
+		{"role": "user", "content": "Find me a tee time at Birdsfoot on Saturday"},
+		{"role": "assistant", "content": "8:10 AM is open. Shall I book it?"},
+	}
+	newHandler := func(converser bedrockConverser, caller ToolCaller) *AWSAgentEventHandler {
+		return &AWSAgentEventHandler{
+			bedrockClient:  converser,
+			logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+			throttleBudget: time.Minute,
+			modelID:        "test-model",
+			toolCaller:     caller,
+			chatTools:      []protocol.Tool{},
+		}
+	}
+
+	t.Run("waits for consent", func(t *testing.T) {
+		caller := &recordingToolCaller{}
+		h := newHandler(&bookingConverser{}, caller)
+
+		response, err := h.Chat(context.Background(), &ChatRequest{
+			History:      history,
+			Message:      "Yes, book it",
+			ConsentTools: []string{"golf_book_tee_time"},
+		})
+		if err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+		if !slices.Equal(response.PendingConsent, []string{"golf_book_tee_time"}) {
+			t.Errorf("PendingConsent = %v, want [golf_book_tee_time]", response.PendingConsent)
+		}
+		if len(caller.requests) != 0 {
+			t.Errorf("tool calls = %v, want none before consent", caller.requests)
+		}
+		if response.Message != ConsentPrompt(response.PendingConsent) {
+			t.Errorf("Message = %q, want the consent prompt", response.Message)
+		}
+	})
+
+	t.Run("runs approved tools", func(t *testing.T) {
+		caller := &recordingToolCaller{}
+		converser := &bookingConverser{}
+		h := newHandler(converser, caller)
+
+		response, err := h.Chat(context.Background(), &ChatRequest{
+			History:       history,
+			Message:       "Yes, book it",
+			ConsentTools:  []string{"golf_book_tee_time"},
+			ApprovedTools: []string{"golf_book_tee_time"},
+		})
+		if err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+		if response.Message != "Booked 8:10 AM" || len(response.PendingConsent) != 0 {
+			t.Errorf("response = %q pending %v, want the booking confirmed", response.Message, response.PendingConsent)
+		}
+		if len(caller.requests) != 1 || caller.requests[0].Name != "golf_book_tee_time" {
+			t.Errorf("tool calls = %v, want one golf_book_tee_time call", caller.requests)
+		}
+		if len(response.Messages) != 2 || response.Messages[1]["content"] != "Booked 8:10 AM" {
+			t.Errorf("Messages = %v, want the user message and the answer", response.Messages)
+		}
+		if response.InputTokens != 2000 || response.OutputTokens != 200 || response.Cost <= 0 {
+			t.Errorf("usage = %d/%d tokens $%.4f, want both calls counted and priced", response.InputTokens, response.OutputTokens, response.Cost)
+		}
+
+		// The session history opens the conversation, before the new message
+		first := converser.inputs[0]
+		if len(first.Messages) != 3 || first.Messages[0].Role != types.ConversationRoleUser {
+			t.Fatalf("first call messages = %d, want the history and the new message", len(first.Messages))
+		}
+		if !strings.Contains(first.System[0].(*types.SystemContentBlockMemberText).Value, "Birdsfoot") {
+			t.Error("system prompt does not list the courses")
+		}
+	})
+}
+
+func TestChatMessages(t *testing.T) {
+	history := []map[string]interface{}{
+		{"role": "assistant", "content": "left over from a trimmed turn"},
+		{"role": "user", "content": "hi"},
+		{"role": "user", "content": "are you there?"},
+		{"role": "assistant", "content": "hello"},
+	}
+
+	messages := chatMessages(history, "book saturday")
+	if len(messages) != 3 {
+		t.Fatalf("messages = %d, want 3", len(messages))
+	}
+	if messages[0].Role != types.ConversationRoleUser || len(messages[0].Content) != 2 {
+		t.Errorf("first message = %s with %d blocks, want the two user messages merged", messages[0].Role, len(messages[0].Content))
+	}
+	if messages[2].Role != types.ConversationRoleUser {
+		t.Errorf("last message role = %s, want user", messages[2].Role)
+	}
+}
+
+// fakeStream replays stream events
+type fakeStream struct {
+	events chan types.ConverseStreamOutput
+}
+
+func newFakeStream(events ...types.ConverseStreamOutput) *fakeStream {
+	stream := &fakeStream{events: make(chan types.ConverseStreamOutput, len(events))}
+	for _, event := range events {
+		stream.events <- event
+	}
+	close(stream.events)
+	return stream
+}
+
+func (s *fakeStream) Events() <-chan types.ConverseStreamOutput { return s.events }
+func (s *fakeStream) Close() error                              { return nil }
+func (s *fakeStream) Err() error                                { return nil }
+
+// bookingStreamer streams a booking tool call, then the confirmation in two pieces
+type bookingStreamer struct {
+	calls int
+}
+
+func (s *bookingStreamer) ConverseStream(ctx context.Context, input *bedrockruntime.ConverseStreamInput) (bedrockruntime.ConverseStreamOutputReader, error) {
+	s.calls++
+	usage := &types.ConverseStreamOutputMemberMetadata{Value: types.ConverseStreamMetadataEvent{
+		Usage: &types.TokenUsage{InputTokens: aws.Int32(1000), OutputTokens: aws.Int32(100)},
+	}}
+	if s.calls == 1 {
+		return newFakeStream(
+			textDelta(0, "Booking it now."),
+			&types.ConverseStreamOutputMemberContentBlockStart{Value: types.ContentBlockStartEvent{
+				ContentBlockIndex: aws.Int32(1),
+				Start: &types.ContentBlockStartMemberToolUse{Value: types.ToolUseBlockStart{
+					ToolUseId: aws.String("book"), Name: aws.String("golf_book_tee_time"),
+				}},
+			}},
+			&types.ConverseStreamOutputMemberContentBlockDelta{Value: types.ContentBlockDeltaEvent{
+				ContentBlockIndex: aws.Int32(1),
+				Delta:             &types.ContentBlockDeltaMemberToolUse{Value: types.ToolUseBlockDelta{Input: aws.String(`{"course_name":`)}},
+			}},
+			&types.ConverseStreamOutputMemberContentBlockDelta{Value: types.ContentBlockDeltaEvent{
+				ContentBlockIndex: aws.Int32(1),
+				Delta:             &types.ContentBlockDeltaMemberToolUse{Value: types.ToolUseBlockDelta{Input: aws.String(`"Birdsfoot"}`)}},
+			}},
+			&types.ConverseStreamOutputMemberMessageStop{Value: types.MessageStopEvent{StopReason: types.StopReasonToolUse}},
+			usage,
+		), nil
+	}
+	return newFakeStream(
+		textDelta(0, "Booked "),
+		textDelta(0, "8:10 AM"),
+		&types.ConverseStreamOutputMemberMessageStop{Value: types.MessageStopEvent{StopReason: types.StopReasonEndTurn}},
+		usage,
+	), nil
+}
+
+func textDelta(index int32, text string) types.ConverseStreamOutput {
+	return &types.ConverseStreamOutputMemberContentBlockDelta{Value: types.ContentBlockDeltaEvent{
+		ContentBlockIndex: aws.Int32(index),
+		Delta:             &types.ContentBlockDeltaMemberText{Value: text},
+	}}
+}
+
+func TestAWSAgentEventHandler_ChatStreaming(t *testing.T) {
+	caller := &recordingToolCaller{}
+	h := &AWSAgentEventHandler{
+		streamer:       &bookingStreamer{},
+		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		throttleBudget: time.Minute,
+		modelID:        "test-model",
+		toolCaller:     caller,
+		chatTools:      []protocol.Tool{},
+	}
+
+	var streamed []string
+	response, err := h.Chat(context.Background(), &ChatRequest{
+		Message: "Book 8:10 at Birdsfoot",
+		OnText:  func(text string) { streamed = append(streamed, text) },
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if !slices.Equal(streamed, []string{"Booking it now.", "Booked ", "8:10 AM"}) {
+		t.Errorf("streamed = %q, want the text of both calls as it arrived", streamed)
+	}
+	if response.Message != "Booked 8:10 AM" {
+		t.Errorf("Message = %q, want the final answer", response.Message)
+	}
+	if len(caller.requests) != 1 || caller.requests[0].Arguments["course_name"] != "Birdsfoot" {
+		t.Errorf("tool calls = %v, want the streamed booking call with its input", caller.requests)
+	}
+	if response.InputTokens != 2000 {
+		t.Errorf("InputTokens = %d, want both calls counted", response.InputTokens)
+	}
+}
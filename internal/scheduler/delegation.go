@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jrzesz33/rez_agent/internal/a2a"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// remoteDelegate is a discovered remote agent offered to the model as a delegate_to_<name> tool
+type remoteDelegate struct {
+	agent models.RemoteAgent
+	card  *a2a.AgentCard
+}
+
+// SetRemoteAgents enables delegating subtasks to external A2A agents alongside the MCP tools
+func (h *AWSAgentEventHandler) SetRemoteAgents(client *a2a.Client, agents []models.RemoteAgent) {
+	h.a2aClient = client
+	h.remoteAgents = agents
+}
+
+// remoteAgentTools discovers the configured remote agents and returns a delegation tool for each.
+// Agents whose card cannot be fetched are left out of the run rather than failing it.
+func (h *AWSAgentEventHandler) remoteAgentTools(ctx context.Context) []protocol.Tool {
+	h.delegates = make(map[string]remoteDelegate, len(h.remoteAgents))
+	if h.a2aClient == nil {
+		return nil
+	}
+
+	tools := make([]protocol.Tool, 0, len(h.remoteAgents))
+	for _, agent := range h.remoteAgents {
+		card, err := h.a2aClient.Discover(ctx, agent)
+		if err != nil {
+			h.logger.WarnContext(ctx, "remote agent unavailable, continuing without it",
+				slog.String("agent", agent.Name),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		tool := a2a.Tool(agent, card)
+		h.delegates[tool.Name] = remoteDelegate{agent: agent, card: card}
+		tools = append(tools, tool)
+	}
+
+	h.logger.InfoContext(ctx, "remote agents discovered",
+		slog.Int("configured", len(h.remoteAgents)),
+		slog.Int("available", len(tools)),
+	)
+	return tools
+}
+
+// callTool runs a tool requested by the model: through the configured ToolCaller when there is one,
+// otherwise by delegating to a remote agent or calling the MCP server
+func (h *AWSAgentEventHandler) callTool(ctx context.Context, req protocol.ToolCallRequest) (*protocol.ToolCallResult, error) {
+	if h.toolCaller != nil {
+		return h.toolCaller.CallTool(ctx, req)
+	}
+
+	delegate, ok := h.delegates[req.Name]
+	if !ok {
+		return h.callMCPTool(ctx, req)
+	}
+
+	task, _ := req.Arguments["task"].(string)
+	if task == "" {
+		return nil, fmt.Errorf("%s requires a task", req.Name)
+	}
+
+	requestID := ""
+	if h.runSummary != nil {
+		requestID = h.runSummary.ExecutionID
+	}
+	reply, err := h.a2aClient.Delegate(ctx, delegate.agent, delegate.card, task, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &protocol.ToolCallResult{
+		Content: []protocol.Content{protocol.NewTextContent(reply)},
+	}, nil
+}
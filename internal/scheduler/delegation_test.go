@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/internal/a2a"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+func TestAWSAgentEventHandler_RemoteAgentToolsWithoutClient(t *testing.T) {
+	h := &AWSAgentEventHandler{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	h.SetRemoteAgents(nil, []models.RemoteAgent{{Name: "calendar", URL: "https://calendar.example.com"}})
+
+	if tools := h.remoteAgentTools(context.Background()); len(tools) != 0 {
+		t.Errorf("remoteAgentTools() = %d tools, want none without a client", len(tools))
+	}
+}
+
+func TestAWSAgentEventHandler_CallToolDelegateArguments(t *testing.T) {
+	agent := models.RemoteAgent{Name: "calendar", URL: "https://calendar.example.com"}
+	h := &AWSAgentEventHandler{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		delegates: map[string]remoteDelegate{
+			a2a.ToolName(agent): {agent: agent, card: &a2a.AgentCard{Name: "Calendar", URL: agent.URL}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"missing task", map[string]interface{}{}},
+		{"empty task", map[string]interface{}{"task": ""}},
+		{"non-string task", map[string]interface{}{"task": 42}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := h.callTool(context.Background(), protocol.ToolCallRequest{Name: "delegate_to_calendar", Arguments: tt.args})
+			if err == nil {
+				t.Error("callTool() should fail without a task")
+			}
+		})
+	}
+}
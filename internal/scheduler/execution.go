@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/logging"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// ExecutionHandler runs the agent events EventBridge Scheduler invokes the scheduler Lambda with:
+// the scheduled and standing_tee_time messages NewSchedule puts in a schedule's target input.
+// Schedule creation requests are handled by the schedule creation Lambda.
+type ExecutionHandler struct {
+	schedules repository.ScheduleRepository
+	agent     AgentEventHandler
+	registry  *models.MessageTypeRegistry
+	events    *messaging.EventBridgePublisher
+	logger    *slog.Logger
+}
+
+// NewExecutionHandler creates a handler for scheduled agent events
+func NewExecutionHandler(schedules repository.ScheduleRepository, agent AgentEventHandler, logger *slog.Logger) *ExecutionHandler {
+	return &ExecutionHandler{
+		schedules: schedules,
+		agent:     agent,
+		registry:  models.DefaultMessageTypeRegistry(),
+		logger:    logger,
+	}
+}
+
+// SetEventPublisher publishes a ScheduleTriggered domain event for every run a schedule triggers
+func (h *ExecutionHandler) SetEventPublisher(publisher *messaging.EventBridgePublisher) {
+	h.events = publisher
+}
+
+// HandleEvent runs the agent for a triggered schedule and records the execution against it.
+// EventBridge invokes the Lambda asynchronously, so returning an error would make Lambda run the
+// whole conversation again; the agent handler has already retried it, and a failed run is logged
+// instead.
+func (h *ExecutionHandler) HandleEvent(ctx context.Context, msg models.Message) error {
+	if msg.MessageType == "" {
+		// Payloads that are not messages, such as the daily cron's scheduler event, carry no run
+		h.logger.InfoContext(ctx, "ignoring scheduler event without a message type")
+		return nil
+	}
+	// Schedules invoke the Lambda directly, so a scheduled run starts its journey here
+	if msg.CorrelationID == "" {
+		msg.CorrelationID = logging.NewCorrelationID()
+	}
+	ctx = logging.WithCorrelationID(ctx, msg.CorrelationID)
+
+	if err := h.registry.ValidateConsume(&msg, models.ComponentScheduler); err != nil {
+		h.logger.ErrorContext(ctx, "rejected scheduled message",
+			slog.String("message_id", msg.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+
+	scheduleID := ScheduleIDFromArguments(msg.Arguments)
+	if scheduleID != "" {
+		h.events.Publish(ctx, messaging.ScheduleTriggered(scheduleID, &msg))
+	}
+
+	event, err := AgentEventFromMessage(&msg, time.Now().UTC())
+	if err != nil {
+		h.logger.ErrorContext(ctx, "invalid scheduled message",
+			slog.String("message_id", msg.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+
+	if err := h.agent.ExecuteScheduledEvent(ctx, event); err != nil {
+		h.logger.ErrorContext(ctx, "scheduled agent run failed",
+			slog.String("schedule_id", event.ScheduleID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	if err := RecordScheduleExecution(ctx, h.schedules, scheduleID, h.logger); err != nil {
+		h.logger.WarnContext(ctx, "failed to record schedule execution", slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// scheduleOwner returns the user a scheduled run acts for: whoever created the schedule, unless a
+// service created it, in which case the run uses the shared credentials
+func scheduleOwner(msg *models.Message) string {
+	if models.IsComponent(msg.CreatedBy) {
+		return ""
+	}
+	return msg.CreatedBy
+}
+
+// AgentEventFromMessage builds the agent event for a triggered scheduled or standing_tee_time
+// message. Scheduled runs are decoded from the payload; standing tee time runs from the arguments.
+func AgentEventFromMessage(msg *models.Message, triggeredAt time.Time) (*ScheduledAgentEvent, error) {
+	switch msg.MessageType {
+	case models.MessageTypeStandingTeeTime:
+		return NewStandingTeeTimeEvent(msg, triggeredAt)
+	case models.MessageTypeScheduled:
+		data, err := json.Marshal(msg.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal scheduled payload: %w", err)
+		}
+		var event ScheduledAgentEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("invalid scheduled payload: %w", err)
+		}
+		if event.ScheduleID == "" {
+			event.ScheduleID = ScheduleIDFromArguments(msg.Arguments)
+		}
+		if event.AuthConfig == nil {
+			event.AuthConfig = msg.AuthConfig
+		}
+		event.Owner = scheduleOwner(msg)
+		event.TriggeredAt = triggeredAt
+		return &event, nil
+	default:
+		return nil, fmt.Errorf("%s messages are not agent runs", msg.MessageType)
+	}
+}
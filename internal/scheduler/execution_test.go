@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// fakeAgentEventHandler records the events it is asked to run
+type fakeAgentEventHandler struct {
+	events []*ScheduledAgentEvent
+	err    error
+}
+
+func (f *fakeAgentEventHandler) ExecuteScheduledEvent(ctx context.Context, event *ScheduledAgentEvent) error {
+	f.events = append(f.events, event)
+	return f.err
+}
+
+func TestExecutionHandler_HandleEvent(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		msg        models.Message
+		runErr     error
+		wantPrompt string
+		wantRuns   int
+		wantCount  int64
+	}{
+		{
+			name: "scheduled run is executed and recorded",
+			msg: models.Message{
+				MessageType: models.MessageTypeScheduled,
+				Arguments:   map[string]interface{}{"schedule_id": "sched_1", "operation": "book"},
+				Payload:     map[string]interface{}{"user_prompt": "Book Saturday at 9:30", "course_name": "Birdsfoot", "max_iterations": float64(5)},
+			},
+			wantPrompt: "Book Saturday at 9:30",
+			wantRuns:   1,
+			wantCount:  1,
+		},
+		{
+			name: "standing tee time run is built from the arguments",
+			msg: models.Message{
+				MessageType: models.MessageTypeStandingTeeTime,
+				Arguments: map[string]interface{}{
+					"schedule_id":      "sched_1",
+					"course_name":      "Totteridge",
+					"standing_weekday": "Saturday",
+					"standing_time":    "08:00",
+				},
+			},
+			wantRuns:  1,
+			wantCount: 1,
+		},
+		{
+			name: "failed run is still recorded",
+			msg: models.Message{
+				MessageType: models.MessageTypeScheduled,
+				Arguments:   map[string]interface{}{"schedule_id": "sched_1"},
+				Payload:     map[string]interface{}{"user_prompt": "Book Saturday"},
+			},
+			runErr:     errors.New("bedrock unavailable"),
+			wantPrompt: "Book Saturday",
+			wantRuns:   1,
+			wantCount:  1,
+		},
+		{
+			name: "cron event without a message is ignored",
+			msg:  models.Message{},
+		},
+		{
+			name: "message for another consumer is rejected",
+			msg: models.Message{
+				MessageType: models.MessageTypeWebAction,
+				Arguments:   map[string]interface{}{"schedule_id": "sched_1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeScheduleRepository{schedules: map[string]*models.Schedule{
+				"sched_1": {ID: "sched_1", ScheduleExpression: "cron(0 12 * * ? *)", Timezone: "UTC", Status: models.ScheduleStatusActive},
+			}}
+			agent := &fakeAgentEventHandler{err: tt.runErr}
+			handler := NewExecutionHandler(repo, agent, logger)
+
+			if err := handler.HandleEvent(context.Background(), tt.msg); err != nil {
+				t.Fatalf("HandleEvent() error = %v", err)
+			}
+			if len(agent.events) != tt.wantRuns {
+				t.Fatalf("runs = %d, want %d", len(agent.events), tt.wantRuns)
+			}
+			if got := repo.schedules["sched_1"].ExecutionCount; got != tt.wantCount {
+				t.Errorf("ExecutionCount = %d, want %d", got, tt.wantCount)
+			}
+			if tt.wantRuns == 0 {
+				return
+			}
+			event := agent.events[0]
+			if event.ScheduleID != "sched_1" || event.TriggeredAt.IsZero() {
+				t.Errorf("event ScheduleID = %q, TriggeredAt = %v", event.ScheduleID, event.TriggeredAt)
+			}
+			if tt.wantPrompt != "" && event.UserPrompt != tt.wantPrompt {
+				t.Errorf("event UserPrompt = %q, want %q", event.UserPrompt, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestAgentEventFromMessage_RunLimits(t *testing.T) {
+	msg := &models.Message{
+		MessageType: models.MessageTypeScheduled,
+		Payload:     map[string]interface{}{"user_prompt": "Book Saturday", "max_iterations": float64(5), "max_cost": 0.25},
+	}
+
+	event, err := AgentEventFromMessage(msg, time.Date(2030, 6, 1, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("AgentEventFromMessage() error = %v", err)
+	}
+	if event.MaxIterations != 5 || event.MaxCost != 0.25 {
+		t.Errorf("RunLimits = %+v, want max_iterations 5 and max_cost 0.25", event.RunLimits)
+	}
+}
+
+func TestAgentEventFromMessage_Owner(t *testing.T) {
+	tests := []struct {
+		name      string
+		createdBy string
+		want      string
+	}{
+		{"member schedule", "alice", "alice"},
+		{"service schedule", "webapi", ""},
+		{"no creator", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &models.Message{
+				CreatedBy:   tt.createdBy,
+				MessageType: models.MessageTypeScheduled,
+				Payload:     map[string]interface{}{"user_prompt": "Book Saturday"},
+			}
+			event, err := AgentEventFromMessage(msg, time.Date(2030, 6, 1, 8, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("AgentEventFromMessage() error = %v", err)
+			}
+			if event.Owner != tt.want {
+				t.Errorf("Owner = %q, want %q", event.Owner, tt.want)
+			}
+		})
+	}
+}
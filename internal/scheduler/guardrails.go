@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jrzesz33/rez_agent/internal/policy"
+)
+
+// bookingTool is the tool whose calls count against the booking and spend limits
+const bookingTool = "golf_book_tee_time"
+
+// GuardrailViolation is returned to the model as the tool error when a guardrail blocks a call
+type GuardrailViolation struct {
+	Rule   string
+	Reason string
+}
+
+func (v *GuardrailViolation) Error() string {
+	return fmt.Sprintf("blocked by guardrail %s: %s", v.Rule, v.Reason)
+}
+
+// runGuardrails enforces policy.Guardrails across one scheduled run. Tool calls may run
+// concurrently, so the counters are guarded by a mutex.
+type runGuardrails struct {
+	mu        sync.Mutex
+	limits    *policy.Guardrails
+	players   int
+	approval  bool
+	toolCalls int
+	bookings  int
+	committed float64
+}
+
+// newRunGuardrails starts enforcing limits for a run booking for players golfers. approval is set
+// when the run already books through the golfer approval workflow.
+func newRunGuardrails(limits *policy.Guardrails, players int, approval bool) *runGuardrails {
+	if limits == nil {
+		limits = &policy.Guardrails{}
+	}
+	return &runGuardrails{
+		limits:   limits,
+		players:  max(players, 1),
+		approval: approval,
+	}
+}
+
+// admit checks a call before it runs and counts it against the limits. Where the tool can enforce
+// a limit itself, the call's arguments are tightened instead of blocking it: bookings get a price
+// cap that fits the remaining spend, and bookings that need confirmation go through approval.
+func (g *runGuardrails) admit(call *pendingToolCall) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	name := call.request.Name
+	if g.limits.MaxToolCallsPerRun > 0 && g.toolCalls >= g.limits.MaxToolCallsPerRun {
+		return &GuardrailViolation{
+			Rule:   "max_tool_calls_per_run",
+			Reason: fmt.Sprintf("this run already made %d tool calls; finish with what you have", g.toolCalls),
+		}
+	}
+
+	if g.limits.RequiresConfirmation(name) && name != bookingTool {
+		return &GuardrailViolation{
+			Rule:   "confirmation_required",
+			Reason: fmt.Sprintf("%s needs the golfer's confirmation and cannot run unattended; describe the action in your notification instead", name),
+		}
+	}
+
+	if name == bookingTool {
+		if g.limits.MaxBookingsPerRun > 0 && g.bookings >= g.limits.MaxBookingsPerRun {
+			return &GuardrailViolation{
+				Rule:   "max_bookings_per_run",
+				Reason: fmt.Sprintf("this run already booked %d tee time(s), the most allowed", g.bookings),
+			}
+		}
+
+		if call.request.Arguments == nil {
+			call.request.Arguments = make(map[string]interface{})
+		}
+		if g.limits.RequiresConfirmation(name) && !g.approval {
+			call.request.Arguments["require_approval"] = true
+		}
+
+		if g.limits.MaxSpendPerRun > 0 {
+			perPlayer := (g.limits.MaxSpendPerRun - g.committed) / float64(g.players)
+			if perPlayer < 1 {
+				return &GuardrailViolation{
+					Rule:   "max_spend_per_run",
+					Reason: fmt.Sprintf("this run's $%.2f spend cap is used up", g.limits.MaxSpendPerRun),
+				}
+			}
+			maxPrice, _ := call.request.Arguments["max_price"].(float64)
+			if maxPrice <= 0 || perPlayer < maxPrice {
+				maxPrice = perPlayer
+				call.request.Arguments["max_price"] = maxPrice
+			}
+			call.committedSpend = maxPrice * float64(g.players)
+		}
+	}
+
+	g.toolCalls++
+	return nil
+}
+
+// settle records a finished call. A successful booking uses up a booking and, because the tool
+// only reports that the price was under the cap, is counted at its cap against the spend limit.
+func (g *runGuardrails) settle(call *pendingToolCall) {
+	if call.err != nil || call.request.Name != bookingTool || (call.result != nil && call.result.IsError) {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.bookings++
+	g.committed += call.committedSpend
+}
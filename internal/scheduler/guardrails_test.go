@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/jrzesz33/rez_agent/internal/policy"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+func guardedCall(name string, args map[string]interface{}) *pendingToolCall {
+	return &pendingToolCall{toolUseID: name, request: protocol.ToolCallRequest{Name: name, Arguments: args}}
+}
+
+func TestRunGuardrails_Admit(t *testing.T) {
+	t.Run("booking limit", func(t *testing.T) {
+		g := newRunGuardrails(&policy.Guardrails{MaxBookingsPerRun: 1}, 2, false)
+
+		first := guardedCall(bookingTool, nil)
+		if err := g.admit(first); err != nil {
+			t.Fatalf("first booking admit() error = %v", err)
+		}
+		g.settle(first)
+
+		err := g.admit(guardedCall(bookingTool, nil))
+		var violation *GuardrailViolation
+		if !errors.As(err, &violation) || violation.Rule != "max_bookings_per_run" {
+			t.Errorf("second booking admit() error = %v, want max_bookings_per_run violation", err)
+		}
+	})
+
+	t.Run("failed booking does not count", func(t *testing.T) {
+		g := newRunGuardrails(&policy.Guardrails{MaxBookingsPerRun: 1}, 1, false)
+
+		failed := guardedCall(bookingTool, nil)
+		if err := g.admit(failed); err != nil {
+			t.Fatalf("admit() error = %v", err)
+		}
+		failed.result = &protocol.ToolCallResult{IsError: true}
+		g.settle(failed)
+
+		if err := g.admit(guardedCall(bookingTool, nil)); err != nil {
+			t.Errorf("admit() after a failed booking error = %v, want nil", err)
+		}
+	})
+
+	t.Run("spend cap lowers max_price", func(t *testing.T) {
+		g := newRunGuardrails(&policy.Guardrails{MaxSpendPerRun: 200}, 4, false)
+
+		call := guardedCall(bookingTool, map[string]interface{}{"max_price": 80.0})
+		if err := g.admit(call); err != nil {
+			t.Fatalf("admit() error = %v", err)
+		}
+		if got := call.request.Arguments["max_price"]; got != 50.0 {
+			t.Errorf("max_price = %v, want 50 (200 spread over 4 players)", got)
+		}
+
+		cheaper := guardedCall(bookingTool, map[string]interface{}{"max_price": 30.0})
+		if err := g.admit(cheaper); err != nil {
+			t.Fatalf("admit() error = %v", err)
+		}
+		if got := cheaper.request.Arguments["max_price"]; got != 30.0 {
+			t.Errorf("max_price = %v, want the lower requested cap of 30 kept", got)
+		}
+	})
+
+	t.Run("spend cap used up", func(t *testing.T) {
+		g := newRunGuardrails(&policy.Guardrails{MaxSpendPerRun: 100}, 2, false)
+
+		booked := guardedCall(bookingTool, nil)
+		if err := g.admit(booked); err != nil {
+			t.Fatalf("admit() error = %v", err)
+		}
+		g.settle(booked)
+
+		err := g.admit(guardedCall(bookingTool, nil))
+		var violation *GuardrailViolation
+		if !errors.As(err, &violation) || violation.Rule != "max_spend_per_run" {
+			t.Errorf("admit() error = %v, want max_spend_per_run violation", err)
+		}
+	})
+
+	t.Run("confirmation required", func(t *testing.T) {
+		g := newRunGuardrails(&policy.Guardrails{ConfirmationRequired: []string{bookingTool, "golf_cancel_reservation"}}, 1, false)
+
+		booking := guardedCall(bookingTool, nil)
+		if err := g.admit(booking); err != nil {
+			t.Fatalf("admit() error = %v", err)
+		}
+		if booking.request.Arguments["require_approval"] != true {
+			t.Error("a booking that needs confirmation should go through approval")
+		}
+
+		err := g.admit(guardedCall("golf_cancel_reservation", nil))
+		var violation *GuardrailViolation
+		if !errors.As(err, &violation) || violation.Rule != "confirmation_required" {
+			t.Errorf("admit() error = %v, want confirmation_required violation", err)
+		}
+	})
+
+	t.Run("tool call limit", func(t *testing.T) {
+		g := newRunGuardrails(&policy.Guardrails{MaxToolCallsPerRun: 2}, 1, false)
+
+		for i := 0; i < 2; i++ {
+			if err := g.admit(guardedCall("get_weather", nil)); err != nil {
+				t.Fatalf("admit() call %d error = %v", i+1, err)
+			}
+		}
+		err := g.admit(guardedCall("get_weather", nil))
+		var violation *GuardrailViolation
+		if !errors.As(err, &violation) || violation.Rule != "max_tool_calls_per_run" {
+			t.Errorf("admit() error = %v, want max_tool_calls_per_run violation", err)
+		}
+	})
+}
+
+func TestAWSAgentEventHandler_GuardrailViolationReturnedToModel(t *testing.T) {
+	server, started := slowMCPServer(t, 0)
+	defer server.Close()
+
+	h := &AWSAgentEventHandler{
+		mcpClient:  newTestMCPClient(server.URL),
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		runSummary: &RunSummary{},
+		guardrails: newRunGuardrails(&policy.Guardrails{MaxToolCallsPerRun: 1}, 1, false),
+	}
+
+	results, err := h.processToolCalls(context.Background(), []types.ContentBlock{
+		toolUse("1", "get_weather"),
+		toolUse("2", "golf_book_tee_time"),
+	})
+	if err != nil {
+		t.Fatalf("processToolCalls() error = %v", err)
+	}
+	if len(*started) != 1 {
+		t.Errorf("MCP server saw %v, want only the admitted call", *started)
+	}
+
+	blocked := results[1].(*types.ContentBlockMemberToolResult).Value
+	text := blocked.Content[0].(*types.ToolResultContentBlockMemberText).Value
+	if blocked.Status != types.ToolResultStatusError || !strings.Contains(text, "max_tool_calls_per_run") {
+		t.Errorf("blocked result = %s %q, want an error naming the guardrail", blocked.Status, text)
+	}
+	if !h.runSummary.ToolCalls[1].Failed {
+		t.Error("the blocked call should be recorded as failed in the run summary")
+	}
+}
+
+func TestAWSAgentEventHandler_SetBedrockGuardrail(t *testing.T) {
+	h := &AWSAgentEventHandler{}
+
+	h.SetBedrockGuardrail("abc123", "")
+	if h.bedrockGuardrail == nil || aws.ToString(h.bedrockGuardrail.GuardrailIdentifier) != "abc123" || aws.ToString(h.bedrockGuardrail.GuardrailVersion) != "DRAFT" {
+		t.Errorf("bedrockGuardrail = %+v, want abc123 at DRAFT", h.bedrockGuardrail)
+	}
+
+	h.SetBedrockGuardrail("", "")
+	if h.bedrockGuardrail != nil {
+		t.Error("an empty identifier should disable the Bedrock guardrail")
+	}
+}
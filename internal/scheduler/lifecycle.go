@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// RecordScheduleExecution records that a schedule fired. One-time (at()) schedules
+// are marked completed; EventBridge deletes the underlying schedule itself via
+// ActionAfterCompletion, so no DeleteSchedule call is needed here.
+func RecordScheduleExecution(ctx context.Context, repo repository.ScheduleRepository, scheduleID string, logger *slog.Logger) error {
+	if scheduleID == "" {
+		return nil
+	}
+
+	schedule, err := repo.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return fmt.Errorf("failed to load schedule %s: %w", scheduleID, err)
+	}
+
+	schedule.RecordExecution()
+
+	if err := repo.UpdateSchedule(ctx, schedule); err != nil {
+		return fmt.Errorf("failed to record execution for schedule %s: %w", scheduleID, err)
+	}
+
+	logger.InfoContext(ctx, "schedule execution recorded",
+		slog.String("schedule_id", scheduleID),
+		slog.Int64("execution_count", schedule.ExecutionCount),
+		slog.String("status", schedule.Status.String()),
+	)
+
+	return nil
+}
+
+// ScheduleIDFromArguments extracts the schedule_id that NewSchedule embeds in the target payload
+func ScheduleIDFromArguments(args map[string]interface{}) string {
+	if id, ok := args["schedule_id"].(string); ok {
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// fakeScheduleRepository is an in-memory ScheduleRepository for the scheduler tests
+type fakeScheduleRepository struct {
+	schedules map[string]*models.Schedule
+	updates   int
+}
+
+func (f *fakeScheduleRepository) SaveSchedule(ctx context.Context, s *models.Schedule) error {
+	f.schedules[s.ID] = s
+	return nil
+}
+
+func (f *fakeScheduleRepository) GetSchedule(ctx context.Context, id string) (*models.Schedule, error) {
+	s, ok := f.schedules[id]
+	if !ok {
+		return nil, fmt.Errorf("schedule not found: %s", id)
+	}
+	return s, nil
+}
+
+func (f *fakeScheduleRepository) UpdateSchedule(ctx context.Context, s *models.Schedule) error {
+	f.updates++
+	f.schedules[s.ID] = s
+	return nil
+}
+
+func (f *fakeScheduleRepository) UpdateScheduleStatus(ctx context.Context, id string, status models.ScheduleStatus, errorMessage string) error {
+	if s, ok := f.schedules[id]; ok {
+		s.Status = status
+		s.ErrorMessage = errorMessage
+	}
+	return nil
+}
+
+func (f *fakeScheduleRepository) ListSchedulesByStatus(ctx context.Context, status models.ScheduleStatus) ([]*models.Schedule, error) {
+	var schedules []*models.Schedule
+	for _, s := range f.schedules {
+		if s.Status == status {
+			schedules = append(schedules, s)
+		}
+	}
+	return schedules, nil
+}
+
+func (f *fakeScheduleRepository) ListSchedulesByCreator(ctx context.Context, createdBy string) ([]*models.Schedule, error) {
+	return nil, nil
+}
+
+func (f *fakeScheduleRepository) DeleteSchedule(ctx context.Context, id string) error {
+	return nil
+}
+
+func (f *fakeScheduleRepository) PurgeSchedule(ctx context.Context, id string) error {
+	return nil
+}
+
+func TestRecordScheduleExecution(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name        string
+		scheduleID  string
+		expr        string
+		wantErr     bool
+		wantStatus  models.ScheduleStatus
+		wantUpdates int
+	}{
+		{"one-time schedule is completed", "sched_1", "at(2030-06-01T08:30:00)", false, models.ScheduleStatusCompleted, 1},
+		{"recurring schedule stays active", "sched_1", "cron(0 12 * * ? *)", false, models.ScheduleStatusActive, 1},
+		{"missing schedule id is a no-op", "", "at(2030-06-01T08:30:00)", false, models.ScheduleStatusActive, 0},
+		{"unknown schedule errors", "sched_unknown", "at(2030-06-01T08:30:00)", true, models.ScheduleStatusActive, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeScheduleRepository{schedules: map[string]*models.Schedule{
+				"sched_1": {ID: "sched_1", ScheduleExpression: tt.expr, Timezone: "UTC", Status: models.ScheduleStatusActive},
+			}}
+
+			err := RecordScheduleExecution(context.Background(), repo, tt.scheduleID, logger)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RecordScheduleExecution() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := repo.schedules["sched_1"].Status; got != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", got, tt.wantStatus)
+			}
+			if repo.updates != tt.wantUpdates {
+				t.Errorf("updates = %d, want %d", repo.updates, tt.wantUpdates)
+			}
+		})
+	}
+}
+
+func TestScheduleIDFromArguments(t *testing.T) {
+	if got := ScheduleIDFromArguments(map[string]interface{}{"schedule_id": "sched_1"}); got != "sched_1" {
+		t.Errorf("ScheduleIDFromArguments() = %q, want sched_1", got)
+	}
+	if got := ScheduleIDFromArguments(map[string]interface{}{}); got != "" {
+		t.Errorf("ScheduleIDFromArguments() = %q, want empty", got)
+	}
+	if got := ScheduleIDFromArguments(nil); got != "" {
+		t.Errorf("ScheduleIDFromArguments(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/mcpclient"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// newTestMCPClient returns an MCP client for a test server that does not retry
+func newTestMCPClient(url string) *mcpclient.Client {
+	client := mcpclient.New(url, protocol.MCPClientInfo{Name: "test"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	client.SetRetries(0, 0)
+	return client
+}
+
+func TestAWSAgentEventHandler_CallMCPToolTimeout(t *testing.T) {
+	release := make(chan struct{})
+	mcpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer mcpServer.Close()
+	defer close(release)
+
+	mcpToolTimeouts["hung_tool"] = 50 * time.Millisecond
+	defer delete(mcpToolTimeouts, "hung_tool")
+
+	h := &AWSAgentEventHandler{
+		mcpClient: newTestMCPClient(mcpServer.URL),
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	start := time.Now()
+	_, err := h.callMCPTool(context.Background(), protocol.ToolCallRequest{Name: "hung_tool"})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("callMCPTool() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("callMCPTool() took %s, want it bounded by the tool timeout", elapsed)
+	}
+}
@@ -0,0 +1,393 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsscheduler "github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler/types"
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/metrics"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+// reconcileGrace is how long a schedule or EventBridge schedule is left alone after it is
+// created, so one whose other half is still being written is not reported
+const reconcileGrace = 10 * time.Minute
+
+// reconciledStatuses are the statuses of schedules whose EventBridge schedule should exist
+var reconciledStatuses = []models.ScheduleStatus{models.ScheduleStatusActive, models.ScheduleStatusPaused}
+
+// DiscrepancyKind is how the schedules table and EventBridge disagree about a schedule
+type DiscrepancyKind string
+
+const (
+	// DiscrepancyMissing is an active or paused schedule whose EventBridge schedule does not exist
+	DiscrepancyMissing DiscrepancyKind = "missing"
+	// DiscrepancyOrphaned is an EventBridge schedule with no active or paused schedule behind it
+	DiscrepancyOrphaned DiscrepancyKind = "orphaned"
+	// DiscrepancyDrifted is an EventBridge schedule whose expression, timezone, state or target
+	// differs from its schedule
+	DiscrepancyDrifted DiscrepancyKind = "drifted"
+)
+
+// discrepancyKinds are the kinds a reconciliation reports, in the order metrics are emitted
+var discrepancyKinds = []DiscrepancyKind{DiscrepancyMissing, DiscrepancyOrphaned, DiscrepancyDrifted}
+
+// Discrepancy is one schedule the table and EventBridge disagree about
+type Discrepancy struct {
+	Kind            DiscrepancyKind `json:"kind"`
+	ScheduleID      string          `json:"schedule_id,omitempty"`
+	EventBridgeName string          `json:"eventbridge_name,omitempty"`
+	Detail          string          `json:"detail"`
+	Repaired        bool            `json:"repaired"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// ReconcileReport is what a reconciliation compared, found and repaired
+type ReconcileReport struct {
+	Repair               bool          `json:"repair"`
+	Schedules            int           `json:"schedules"`
+	EventBridgeSchedules int           `json:"eventbridge_schedules"`
+	Missing              int           `json:"missing"`
+	Orphaned             int           `json:"orphaned"`
+	Drifted              int           `json:"drifted"`
+	Repaired             int           `json:"repaired"`
+	Discrepancies        []Discrepancy `json:"discrepancies"`
+}
+
+// add counts d under its kind
+func (r *ReconcileReport) add(d Discrepancy) {
+	switch d.Kind {
+	case DiscrepancyMissing:
+		r.Missing++
+	case DiscrepancyOrphaned:
+		r.Orphaned++
+	case DiscrepancyDrifted:
+		r.Drifted++
+	}
+	if d.Repaired {
+		r.Repaired++
+	}
+	r.Discrepancies = append(r.Discrepancies, d)
+}
+
+// ScheduleAPI is the part of the EventBridge Scheduler client the reconciler uses
+type ScheduleAPI interface {
+	awsscheduler.ListSchedulesAPIClient
+	GetSchedule(ctx context.Context, params *awsscheduler.GetScheduleInput, optFns ...func(*awsscheduler.Options)) (*awsscheduler.GetScheduleOutput, error)
+	UpdateSchedule(ctx context.Context, params *awsscheduler.UpdateScheduleInput, optFns ...func(*awsscheduler.Options)) (*awsscheduler.UpdateScheduleOutput, error)
+	DeleteSchedule(ctx context.Context, params *awsscheduler.DeleteScheduleInput, optFns ...func(*awsscheduler.Options)) (*awsscheduler.DeleteScheduleOutput, error)
+}
+
+// Reconciler compares the active and paused schedules in the schedules table with the EventBridge
+// schedules of the same stage, which NewSchedule names {name}-{stage}-{unix time}, and reports
+// or repairs where they disagree
+type Reconciler struct {
+	schedules   repository.ScheduleRepository
+	api         ScheduleAPI
+	stage       models.Stage
+	owned       *regexp.Regexp
+	functionArn string
+	recorder    *metrics.ReconcileRecorder
+	audit       *audit.Recorder
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+// NewReconciler creates a reconciler of the schedules of stage
+func NewReconciler(schedules repository.ScheduleRepository, api ScheduleAPI, stage models.Stage, logger *slog.Logger) *Reconciler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Reconciler{
+		schedules: schedules,
+		api:       api,
+		stage:     stage,
+		owned:     regexp.MustCompile(`-` + regexp.QuoteMeta(stage.String()) + `-\d+$`),
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetExecutionTarget also checks that agent run schedules invoke functionArn, the scheduler Lambda
+func (r *Reconciler) SetExecutionTarget(functionArn string) {
+	r.functionArn = functionArn
+}
+
+// SetRecorder emits metrics of what each reconciliation found
+func (r *Reconciler) SetRecorder(recorder *metrics.ReconcileRecorder) {
+	r.recorder = recorder
+}
+
+// SetAuditRecorder records every repair in the audit log
+func (r *Reconciler) SetAuditRecorder(recorder *audit.Recorder) {
+	r.audit = recorder
+}
+
+// Reconcile reports every schedule the table and EventBridge disagree about. With repair, it also
+// makes EventBridge or the table right: a drifted EventBridge schedule is updated to match its
+// schedule, an orphaned one is deleted, and a schedule whose EventBridge schedule is missing is
+// marked error, or completed when it was a one-time schedule that has fired, since the target it
+// was created with is not stored. A repair that fails is reported with its error and does not stop
+// the others; the error returned means the schedules could not be compared at all.
+func (r *Reconciler) Reconcile(ctx context.Context, repair bool) (ReconcileReport, error) {
+	report := ReconcileReport{Repair: repair, Discrepancies: []Discrepancy{}}
+	now := r.now()
+
+	var tracked []*models.Schedule
+	names := make(map[string]bool)
+	for _, status := range reconciledStatuses {
+		schedules, err := r.schedules.ListSchedulesByStatus(ctx, status)
+		if err != nil {
+			return report, fmt.Errorf("failed to list %s schedules: %w", status, err)
+		}
+		for _, schedule := range schedules {
+			if schedule.Stage != "" && schedule.Stage != r.stage {
+				continue
+			}
+			tracked = append(tracked, schedule)
+			names[schedule.EventBridgeName] = true
+		}
+	}
+	report.Schedules = len(tracked)
+
+	actual, err := r.listEventBridgeSchedules(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.EventBridgeSchedules = len(actual)
+
+	for _, schedule := range tracked {
+		if now.Sub(schedule.CreatedDate) < reconcileGrace {
+			continue
+		}
+		if _, ok := actual[schedule.EventBridgeName]; !ok {
+			r.resolve(ctx, &report, Discrepancy{
+				Kind:            DiscrepancyMissing,
+				ScheduleID:      schedule.ID,
+				EventBridgeName: schedule.EventBridgeName,
+				Detail:          fmt.Sprintf("%s schedule has no EventBridge schedule", schedule.Status),
+			}, repair, func(ctx context.Context) error {
+				return r.markMissing(ctx, schedule, now)
+			})
+			continue
+		}
+
+		out, err := r.api.GetSchedule(ctx, &awsscheduler.GetScheduleInput{
+			Name:      aws.String(schedule.EventBridgeName),
+			GroupName: actual[schedule.EventBridgeName].GroupName,
+		})
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			// Deleted since it was listed, such as a one-time schedule that just fired
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to get EventBridge schedule %s: %w", schedule.EventBridgeName, err)
+		}
+		if drift := r.drift(schedule, out); len(drift) > 0 {
+			r.resolve(ctx, &report, Discrepancy{
+				Kind:            DiscrepancyDrifted,
+				ScheduleID:      schedule.ID,
+				EventBridgeName: schedule.EventBridgeName,
+				Detail:          strings.Join(drift, "; "),
+			}, repair, func(ctx context.Context) error {
+				return r.update(ctx, schedule, out)
+			})
+		}
+	}
+
+	for name, summary := range actual {
+		if names[name] || (summary.CreationDate != nil && now.Sub(*summary.CreationDate) < reconcileGrace) {
+			continue
+		}
+		r.resolve(ctx, &report, Discrepancy{
+			Kind:            DiscrepancyOrphaned,
+			EventBridgeName: name,
+			Detail:          "no active or paused schedule uses this EventBridge schedule",
+		}, repair, func(ctx context.Context) error {
+			return r.delete(ctx, name, summary.GroupName)
+		})
+	}
+
+	r.record(ctx, report)
+	r.logger.InfoContext(ctx, "schedule reconciliation completed",
+		slog.Bool("repair", repair),
+		slog.Int("schedules", report.Schedules),
+		slog.Int("eventbridge_schedules", report.EventBridgeSchedules),
+		slog.Int("missing", report.Missing),
+		slog.Int("orphaned", report.Orphaned),
+		slog.Int("drifted", report.Drifted),
+		slog.Int("repaired", report.Repaired),
+	)
+	return report, nil
+}
+
+// listEventBridgeSchedules returns the EventBridge schedules of the reconciler's stage by name
+func (r *Reconciler) listEventBridgeSchedules(ctx context.Context) (map[string]types.ScheduleSummary, error) {
+	schedules := make(map[string]types.ScheduleSummary)
+	paginator := awsscheduler.NewListSchedulesPaginator(r.api, &awsscheduler.ListSchedulesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list EventBridge schedules: %w", err)
+		}
+		for _, summary := range page.Schedules {
+			if name := aws.ToString(summary.Name); r.owned.MatchString(name) {
+				schedules[name] = summary
+			}
+		}
+	}
+	return schedules, nil
+}
+
+// drift describes each way out differs from schedule
+func (r *Reconciler) drift(schedule *models.Schedule, out *awsscheduler.GetScheduleOutput) []string {
+	var drift []string
+	if got := aws.ToString(out.ScheduleExpression); got != schedule.ScheduleExpression {
+		drift = append(drift, fmt.Sprintf("expression is %s, want %s", got, schedule.ScheduleExpression))
+	}
+	if got, want := timezoneOrUTC(aws.ToString(out.ScheduleExpressionTimezone)), timezoneOrUTC(schedule.Timezone); got != want {
+		drift = append(drift, fmt.Sprintf("timezone is %s, want %s", got, want))
+	}
+	if want := scheduleState(schedule); out.State != want {
+		drift = append(drift, fmt.Sprintf("state is %s, want %s", out.State, want))
+	}
+	if r.functionArn != "" && IsAgentRunSchedule(schedule) && out.Target != nil {
+		if got := aws.ToString(out.Target.Arn); got != r.functionArn {
+			drift = append(drift, fmt.Sprintf("target is %s, want %s", got, r.functionArn))
+		}
+	}
+	return drift
+}
+
+// update rewrites the EventBridge schedule out to match schedule, keeping the rest of its
+// definition; UpdateSchedule replaces the whole schedule
+func (r *Reconciler) update(ctx context.Context, schedule *models.Schedule, out *awsscheduler.GetScheduleOutput) error {
+	if out.Target == nil {
+		return fmt.Errorf("EventBridge schedule %s has no target", schedule.EventBridgeName)
+	}
+	target := *out.Target
+	if r.functionArn != "" && IsAgentRunSchedule(schedule) {
+		target.Arn = aws.String(r.functionArn)
+	}
+
+	_, err := r.api.UpdateSchedule(ctx, &awsscheduler.UpdateScheduleInput{
+		Name:                       out.Name,
+		GroupName:                  out.GroupName,
+		ScheduleExpression:         aws.String(schedule.ScheduleExpression),
+		ScheduleExpressionTimezone: aws.String(timezoneOrUTC(schedule.Timezone)),
+		State:                      scheduleState(schedule),
+		FlexibleTimeWindow:         out.FlexibleTimeWindow,
+		Target:                     &target,
+		Description:                out.Description,
+		StartDate:                  out.StartDate,
+		EndDate:                    out.EndDate,
+		KmsKeyArn:                  out.KmsKeyArn,
+		ActionAfterCompletion:      out.ActionAfterCompletion,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update EventBridge schedule: %w", err)
+	}
+	return nil
+}
+
+// delete deletes an orphaned EventBridge schedule; one that is already gone is not an error
+func (r *Reconciler) delete(ctx context.Context, name string, group *string) error {
+	_, err := r.api.DeleteSchedule(ctx, &awsscheduler.DeleteScheduleInput{Name: aws.String(name), GroupName: group})
+	var notFound *types.ResourceNotFoundException
+	if err != nil && !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to delete EventBridge schedule: %w", err)
+	}
+	return nil
+}
+
+// markMissing records in the table that schedule no longer fires
+func (r *Reconciler) markMissing(ctx context.Context, schedule *models.Schedule, now time.Time) error {
+	if schedule.IsOneTime() {
+		// EventBridge deletes a one-time schedule once it fires, so it ran but was not recorded
+		if runAt, err := schedule.RunAt(); err == nil && runAt.Before(now) {
+			return r.schedules.UpdateScheduleStatus(ctx, schedule.ID, models.ScheduleStatusCompleted, "")
+		}
+	}
+	return r.schedules.UpdateScheduleStatus(ctx, schedule.ID, models.ScheduleStatusError,
+		fmt.Sprintf("EventBridge schedule %s no longer exists; create the schedule again", schedule.EventBridgeName))
+}
+
+// resolve repairs d when asked to, then logs it and adds it to the report
+func (r *Reconciler) resolve(ctx context.Context, report *ReconcileReport, d Discrepancy, repair bool, fix func(context.Context) error) {
+	if repair {
+		if err := fix(ctx); err != nil {
+			d.Error = err.Error()
+		} else {
+			d.Repaired = true
+			r.audit.Record(ctx, audit.Event{
+				Action: models.AuditActionScheduleReconciliation,
+				Target: d.EventBridgeName,
+				Details: map[string]string{
+					"kind":        string(d.Kind),
+					"schedule_id": d.ScheduleID,
+					"detail":      d.Detail,
+				},
+			})
+		}
+	}
+
+	attrs := []any{
+		slog.String("kind", string(d.Kind)),
+		slog.String("schedule_id", d.ScheduleID),
+		slog.String("eventbridge_name", d.EventBridgeName),
+		slog.String("detail", d.Detail),
+		slog.Bool("repaired", d.Repaired),
+	}
+	if d.Error != "" {
+		r.logger.ErrorContext(ctx, "failed to repair schedule discrepancy", append(attrs, slog.String("error", d.Error))...)
+	} else {
+		r.logger.WarnContext(ctx, "schedule discrepancy found", attrs...)
+	}
+	report.add(d)
+}
+
+// record emits the metrics of each kind of discrepancy
+func (r *Reconciler) record(ctx context.Context, report ReconcileReport) {
+	if r.recorder == nil {
+		return
+	}
+	for _, kind := range discrepancyKinds {
+		var found, repaired int
+		for _, d := range report.Discrepancies {
+			if d.Kind == kind {
+				found++
+				if d.Repaired {
+					repaired++
+				}
+			}
+		}
+		r.recorder.Record(ctx, string(kind), found, repaired)
+	}
+}
+
+// scheduleState is the EventBridge state of a schedule with the status of schedule
+func scheduleState(schedule *models.Schedule) types.ScheduleState {
+	if schedule.Status == models.ScheduleStatusPaused {
+		return types.ScheduleStateDisabled
+	}
+	return types.ScheduleStateEnabled
+}
+
+// timezoneOrUTC is the timezone EventBridge evaluates an expression in when none is set
+func timezoneOrUTC(timezone string) string {
+	if timezone == "" {
+		return "UTC"
+	}
+	return timezone
+}
@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsscheduler "github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// fakeScheduleAPI is an in-memory EventBridge Scheduler keyed by schedule name
+type fakeScheduleAPI struct {
+	schedules map[string]*awsscheduler.GetScheduleOutput
+	updated   []string
+	deleted   []string
+}
+
+func (f *fakeScheduleAPI) ListSchedules(ctx context.Context, params *awsscheduler.ListSchedulesInput, optFns ...func(*awsscheduler.Options)) (*awsscheduler.ListSchedulesOutput, error) {
+	out := &awsscheduler.ListSchedulesOutput{}
+	for _, s := range f.schedules {
+		out.Schedules = append(out.Schedules, types.ScheduleSummary{Name: s.Name, GroupName: s.GroupName, CreationDate: s.CreationDate})
+	}
+	return out, nil
+}
+
+func (f *fakeScheduleAPI) GetSchedule(ctx context.Context, params *awsscheduler.GetScheduleInput, optFns ...func(*awsscheduler.Options)) (*awsscheduler.GetScheduleOutput, error) {
+	s, ok := f.schedules[aws.ToString(params.Name)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{}
+	}
+	return s, nil
+}
+
+func (f *fakeScheduleAPI) UpdateSchedule(ctx context.Context, params *awsscheduler.UpdateScheduleInput, optFns ...func(*awsscheduler.Options)) (*awsscheduler.UpdateScheduleOutput, error) {
+	name := aws.ToString(params.Name)
+	f.updated = append(f.updated, name)
+	s := f.schedules[name]
+	s.ScheduleExpression = params.ScheduleExpression
+	s.ScheduleExpressionTimezone = params.ScheduleExpressionTimezone
+	s.State = params.State
+	s.Target = params.Target
+	return &awsscheduler.UpdateScheduleOutput{}, nil
+}
+
+func (f *fakeScheduleAPI) DeleteSchedule(ctx context.Context, params *awsscheduler.DeleteScheduleInput, optFns ...func(*awsscheduler.Options)) (*awsscheduler.DeleteScheduleOutput, error) {
+	name := aws.ToString(params.Name)
+	f.deleted = append(f.deleted, name)
+	delete(f.schedules, name)
+	return &awsscheduler.DeleteScheduleOutput{}, nil
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	const functionArn = "arn:aws:lambda:us-east-1:123456789012:function:rez-agent-scheduler-dev"
+	const topicArn = "arn:aws:sns:us-east-1:123456789012:rez-agent-web-actions-dev"
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-24 * time.Hour)
+
+	eventBridge := func(name, expr, timezone string, state types.ScheduleState, target string, createdAt time.Time) *awsscheduler.GetScheduleOutput {
+		return &awsscheduler.GetScheduleOutput{
+			Name:                       aws.String(name),
+			GroupName:                  aws.String("default"),
+			ScheduleExpression:         aws.String(expr),
+			ScheduleExpressionTimezone: aws.String(timezone),
+			State:                      state,
+			FlexibleTimeWindow:         &types.FlexibleTimeWindow{Mode: types.FlexibleTimeWindowModeOff},
+			Target:                     &types.Target{Arn: aws.String(target), Input: aws.String(`{"message_type":"web_action"}`)},
+			CreationDate:               aws.Time(createdAt),
+		}
+	}
+	newFixture := func() (*fakeScheduleRepository, *fakeScheduleAPI) {
+		repo := &fakeScheduleRepository{schedules: map[string]*models.Schedule{
+			"sched_ok":      {ID: "sched_ok", EventBridgeName: "weather-dev-1", ScheduleExpression: "cron(0 12 * * ? *)", Timezone: "UTC", TargetType: models.TargetTypeWebAction, Status: models.ScheduleStatusActive, Stage: models.StageDev, CreatedDate: created},
+			"sched_missing": {ID: "sched_missing", EventBridgeName: "digest-dev-2", ScheduleExpression: "cron(0 8 ? * MON *)", Timezone: "UTC", TargetType: models.TargetTypeWeeklyDigest, Status: models.ScheduleStatusActive, Stage: models.StageDev, CreatedDate: created},
+			"sched_fired":   {ID: "sched_fired", EventBridgeName: "once-dev-3", ScheduleExpression: "at(2030-05-31T08:00:00)", Timezone: "UTC", TargetType: models.TargetTypeWebAction, Status: models.ScheduleStatusActive, Stage: models.StageDev, CreatedDate: created},
+			"sched_drift":   {ID: "sched_drift", EventBridgeName: "saturday-dev-4", ScheduleExpression: "cron(0 7 ? * SAT *)", Timezone: "America/New_York", TargetType: models.TargetTypeScheduler, Status: models.ScheduleStatusPaused, Stage: models.StageDev, CreatedDate: created},
+			"sched_new":     {ID: "sched_new", EventBridgeName: "new-dev-5", ScheduleExpression: "cron(0 9 * * ? *)", Timezone: "UTC", Status: models.ScheduleStatusActive, Stage: models.StageDev, CreatedDate: now.Add(-time.Minute)},
+			"sched_deleted": {ID: "sched_deleted", EventBridgeName: "old-dev-6", ScheduleExpression: "cron(0 9 * * ? *)", Timezone: "UTC", Status: models.ScheduleStatusDeleted, Stage: models.StageDev, CreatedDate: created},
+			"sched_prod":    {ID: "sched_prod", EventBridgeName: "weather-prod-7", ScheduleExpression: "cron(0 12 * * ? *)", Timezone: "UTC", Status: models.ScheduleStatusActive, Stage: models.StageProd, CreatedDate: created},
+		}}
+		api := &fakeScheduleAPI{schedules: map[string]*awsscheduler.GetScheduleOutput{
+			"weather-dev-1":                 eventBridge("weather-dev-1", "cron(0 12 * * ? *)", "UTC", types.ScheduleStateEnabled, topicArn, created),
+			"saturday-dev-4":                eventBridge("saturday-dev-4", "cron(0 6 ? * SAT *)", "America/New_York", types.ScheduleStateEnabled, "arn:aws:sqs:us-east-1:123456789012:rez-agent-schedule-creation-dev", created),
+			"old-dev-6":                     eventBridge("old-dev-6", "cron(0 9 * * ? *)", "UTC", types.ScheduleStateEnabled, topicArn, created),
+			"pending-dev-8":                 eventBridge("pending-dev-8", "cron(0 9 * * ? *)", "UTC", types.ScheduleStateEnabled, topicArn, now.Add(-time.Minute)),
+			"weather-prod-7":                eventBridge("weather-prod-7", "cron(0 12 * * ? *)", "UTC", types.ScheduleStateEnabled, topicArn, created),
+			"rez-agent-daily-scheduler-dev": eventBridge("rez-agent-daily-scheduler-dev", "cron(0 12 * * ? *)", "UTC", types.ScheduleStateEnabled, functionArn, created),
+		}}
+		return repo, api
+	}
+	newReconciler := func(repo *fakeScheduleRepository, api *fakeScheduleAPI) *Reconciler {
+		r := NewReconciler(repo, api, models.StageDev, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		r.SetExecutionTarget(functionArn)
+		r.now = func() time.Time { return now }
+		return r
+	}
+
+	t.Run("report only", func(t *testing.T) {
+		repo, api := newFixture()
+		report, err := newReconciler(repo, api).Reconcile(context.Background(), false)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+
+		if report.Schedules != 5 || report.EventBridgeSchedules != 4 {
+			t.Errorf("compared %d schedules and %d EventBridge schedules, want 5 and 4", report.Schedules, report.EventBridgeSchedules)
+		}
+		if report.Missing != 2 || report.Orphaned != 1 || report.Drifted != 1 || report.Repaired != 0 {
+			t.Errorf("report = %+v, want 2 missing, 1 orphaned, 1 drifted and none repaired", report)
+		}
+		for _, d := range report.Discrepancies {
+			if d.Kind == DiscrepancyDrifted && d.Detail != "expression is cron(0 6 ? * SAT *), want cron(0 7 ? * SAT *); state is ENABLED, want DISABLED; target is arn:aws:sqs:us-east-1:123456789012:rez-agent-schedule-creation-dev, want "+functionArn {
+				t.Errorf("drift detail = %q", d.Detail)
+			}
+		}
+		if len(api.updated) != 0 || len(api.deleted) != 0 || repo.schedules["sched_missing"].Status != models.ScheduleStatusActive {
+			t.Errorf("report-only reconciliation changed something: updated %v, deleted %v", api.updated, api.deleted)
+		}
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		repo, api := newFixture()
+		report, err := newReconciler(repo, api).Reconcile(context.Background(), true)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+
+		if report.Repaired != 4 {
+			t.Errorf("repaired %d discrepancies, want 4: %+v", report.Repaired, report.Discrepancies)
+		}
+		if got := repo.schedules["sched_missing"]; got.Status != models.ScheduleStatusError || got.ErrorMessage == "" {
+			t.Errorf("missing recurring schedule status = %s (%q), want error", got.Status, got.ErrorMessage)
+		}
+		if got := repo.schedules["sched_fired"].Status; got != models.ScheduleStatusCompleted {
+			t.Errorf("missing one-time schedule status = %s, want completed", got)
+		}
+		if len(api.deleted) != 1 || api.deleted[0] != "old-dev-6" {
+			t.Errorf("deleted %v, want [old-dev-6]", api.deleted)
+		}
+		drifted := api.schedules["saturday-dev-4"]
+		if aws.ToString(drifted.ScheduleExpression) != "cron(0 7 ? * SAT *)" || drifted.State != types.ScheduleStateDisabled || aws.ToString(drifted.Target.Arn) != functionArn {
+			t.Errorf("drifted schedule after repair = %s, %s, %s", aws.ToString(drifted.ScheduleExpression), drifted.State, aws.ToString(drifted.Target.Arn))
+		}
+		if aws.ToString(drifted.Target.Input) == "" {
+			t.Error("repair dropped the target input")
+		}
+
+		// A second pass finds nothing left to repair
+		again, err := newReconciler(repo, api).Reconcile(context.Background(), true)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if len(again.Discrepancies) != 0 {
+			t.Errorf("second reconciliation found %+v, want nothing", again.Discrepancies)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// RunRecording is the machine-readable record of an agent run, stored next to its HTML summary
+// so the run can be replayed against a new prompt or model
+type RunRecording struct {
+	ScheduleID  string `json:"schedule_id"`
+	ExecutionID string `json:"execution_id"`
+	// CorrelationID ties the run to the schedule firing and the bookings and notifications it caused
+	CorrelationID string               `json:"correlation_id,omitempty"`
+	Stage         string               `json:"stage"`
+	ModelID       string               `json:"model_id"`
+	StartedAt     time.Time            `json:"started_at"`
+	Event         *ScheduledAgentEvent `json:"event"`
+
+	// PromptTemplate and PromptData re-render the system prompt; SystemMessage is used when the
+	// run was not rendered from a template
+	PromptTemplate string          `json:"prompt_template,omitempty"`
+	PromptData     json.RawMessage `json:"prompt_data,omitempty"`
+	SystemMessage  string          `json:"system_message"`
+
+	Tools         []protocol.Tool    `json:"tools"`
+	ToolCalls     []RecordedToolCall `json:"tool_calls"`
+	InputTokens   int64              `json:"input_tokens"`
+	OutputTokens  int64              `json:"output_tokens"`
+	FinalResponse string             `json:"final_response"`
+	Error         string             `json:"error,omitempty"`
+	// StoppedReason is set when a limit or Bedrock throttling stopped the run before it finished
+	StoppedReason string `json:"stopped_reason,omitempty"`
+}
+
+// RecordedToolCall is one tool call made during a recorded run and the result the tool returned
+type RecordedToolCall struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Result    string                 `json:"result"`
+	Failed    bool                   `json:"failed,omitempty"`
+}
+
+// Recording returns the run's machine-readable recording
+func (s *RunSummary) Recording() (*RunRecording, error) {
+	recording := &RunRecording{
+		ScheduleID:     s.ScheduleID,
+		ExecutionID:    s.ExecutionID,
+		CorrelationID:  s.CorrelationID,
+		Stage:          s.Stage,
+		ModelID:        s.ModelID,
+		StartedAt:      s.StartedAt,
+		Event:          s.Event,
+		PromptTemplate: s.PromptTemplate,
+		SystemMessage:  s.SystemMessage,
+		Tools:          s.Tools,
+		ToolCalls:      make([]RecordedToolCall, 0, len(s.ToolCalls)),
+		InputTokens:    s.InputTokens,
+		OutputTokens:   s.OutputTokens,
+		FinalResponse:  s.FinalResponse,
+		Error:          s.Error,
+		StoppedReason:  s.StoppedReason,
+	}
+
+	if s.PromptData != nil {
+		data, err := json.Marshal(s.PromptData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal prompt data: %w", err)
+		}
+		recording.PromptData = data
+	}
+
+	for _, call := range s.ToolCalls {
+		recording.ToolCalls = append(recording.ToolCalls, RecordedToolCall{
+			Name:      call.Name,
+			Arguments: call.Input,
+			Result:    call.Result,
+			Failed:    call.Failed,
+		})
+	}
+	return recording, nil
+}
+
+// ParseRunRecording decodes a recording and checks it can be replayed
+func ParseRunRecording(data []byte) (*RunRecording, error) {
+	var recording RunRecording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return nil, fmt.Errorf("failed to parse run recording: %w", err)
+	}
+	if recording.Event == nil {
+		return nil, fmt.Errorf("run recording %s has no event", recording.ExecutionID)
+	}
+	if recording.SystemMessage == "" && recording.PromptTemplate == "" {
+		return nil, fmt.Errorf("run recording %s has no system prompt", recording.ExecutionID)
+	}
+	return &recording, nil
+}
+
+// EstimatedCost returns the Bedrock cost of the recorded run in dollars, and false for models without known pricing
+func (r *RunRecording) EstimatedCost() (float64, bool) {
+	return estimateCost(r.ModelID, r.InputTokens, r.OutputTokens)
+}
+
+// RecordingKey returns the object key for a run's recording
+func (p *RunSummaryPublisher) RecordingKey(summary *RunSummary) string {
+	return fmt.Sprintf("%s%s/%s.json", p.recordingPrefix(summary.ScheduleID), summary.StartedAt.UTC().Format("2006/01/02"), summary.ExecutionID)
+}
+
+// recordingPrefix returns the key prefix under which a schedule's recordings are stored,
+// or the stage's recordings when scheduleID is empty
+func (p *RunSummaryPublisher) recordingPrefix(scheduleID string) string {
+	if scheduleID == "" {
+		return fmt.Sprintf("recordings/%s/", p.stage)
+	}
+	return fmt.Sprintf("recordings/%s/%s/", p.stage, scheduleID)
+}
+
+// publishRecording uploads the run's recording as JSON
+func (p *RunSummaryPublisher) publishRecording(ctx context.Context, summary *RunSummary) error {
+	recording, err := summary.Recording()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(recording)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run recording: %w", err)
+	}
+
+	_, err = p.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(p.bucket),
+		Key:         aws.String(p.RecordingKey(summary)),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload run recording: %w", err)
+	}
+	return nil
+}
+
+// ListRecordings returns the object keys of the stage's recordings, or one schedule's when scheduleID is set
+func (p *RunSummaryPublisher) ListRecordings(ctx context.Context, scheduleID string) ([]string, error) {
+	keys, err := p.listKeys(ctx, p.recordingPrefix(scheduleID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run recordings: %w", err)
+	}
+	return keys, nil
+}
+
+// ListRecordingsSince returns the object keys of the stage's recordings of runs started on or
+// after the day of since, read from the date in each key rather than by downloading the runs
+func (p *RunSummaryPublisher) ListRecordingsSince(ctx context.Context, since time.Time) ([]string, error) {
+	keys, err := p.ListRecordings(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	day := since.UTC().Truncate(24 * time.Hour)
+	recent := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if startedOn, ok := recordingDay(key); ok && !startedOn.Before(day) {
+			recent = append(recent, key)
+		}
+	}
+	return recent, nil
+}
+
+// recordingDay parses the YYYY/MM/DD a recording key is filed under
+func recordingDay(key string) (time.Time, bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) < 4 {
+		return time.Time{}, false
+	}
+	day, err := time.Parse("2006/01/02", strings.Join(parts[len(parts)-4:len(parts)-1], "/"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// GetRecording downloads and parses the recording stored at key
+func (p *RunSummaryPublisher) GetRecording(ctx context.Context, key string) (*RunRecording, error) {
+	out, err := p.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run recording %s: %w", key, err)
+	}
+	defer out.Body.Close()
+
+	body, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run recording %s: %w", key, err)
+	}
+	return ParseRunRecording(body)
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/prompts"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// ToolCaller answers the model's tool calls in place of the MCP server and remote agents,
+// e.g. from a recorded run
+type ToolCaller interface {
+	CallTool(ctx context.Context, req protocol.ToolCallRequest) (*protocol.ToolCallResult, error)
+}
+
+// SetToolCaller routes every tool call the model makes to caller instead of the real tools
+func (h *AWSAgentEventHandler) SetToolCaller(caller ToolCaller) {
+	h.toolCaller = caller
+}
+
+// SetModelID overrides the Bedrock model used for conversations
+func (h *AWSAgentEventHandler) SetModelID(modelID string) {
+	h.modelID = modelID
+}
+
+// Replay runs a recorded run's conversation again with the handler's current prompt templates
+// and model. Tools are answered by the configured ToolCaller, which is required so a replay can
+// never book or notify for real. Reservations and weather are not fetched again; they are part
+// of the recorded prompt data.
+func (h *AWSAgentEventHandler) Replay(ctx context.Context, recording *RunRecording) (*RunSummary, error) {
+	if h.toolCaller == nil {
+		return nil, fmt.Errorf("replay requires a tool caller")
+	}
+
+	event := *recording.Event
+	h.defaultToolArguments = map[string]interface{}{"course_name": event.CourseName}
+	ctx = withRunRules(ctx, newRunRules(&event))
+	h.guardrails = newRunGuardrails(h.defaultGuardrails.Merge(event.Guardrails), event.NumPlayers, event.RequireApproval)
+	h.delegates = nil
+
+	systemMessage, err := h.replaySystemMessage(ctx, recording)
+	if err != nil {
+		return nil, err
+	}
+
+	finalResponse, err := h.executeAgentConversation(ctx, &event, systemMessage, "", "", recording.Tools)
+
+	summary := h.runSummary
+	summary.Duration = time.Since(summary.StartedAt)
+	summary.FinalResponse = finalResponse
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	return summary, nil
+}
+
+// replaySystemMessage re-renders the recorded prompt data with the current templates, or returns
+// the recorded system message when the run was not rendered from a template
+func (h *AWSAgentEventHandler) replaySystemMessage(ctx context.Context, recording *RunRecording) (string, error) {
+	h.promptTemplate = ""
+	h.promptData = nil
+	if recording.PromptTemplate == "" {
+		return recording.SystemMessage, nil
+	}
+
+	data, err := prompts.DecodeData(recording.PromptTemplate, recording.PromptData)
+	if err != nil {
+		return "", err
+	}
+	return h.renderPrompt(ctx, recording.PromptTemplate, data)
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/policy"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// runRules are the hard rules a run forces on its tool calls, whatever the model passes, and the
+// tee times the run booked under them. They travel in the run's context rather than on the
+// handler, so a run never sees another run's rules.
+type runRules struct {
+	policy          *policy.Config
+	requireApproval bool
+	dryRun          bool
+
+	mu       sync.Mutex
+	bookings []models.BookedTeeTime
+}
+
+type runRulesKey struct{}
+
+// newRunRules takes the event's booking policy, capped at its max price, and its approval and
+// dry run flags
+func newRunRules(event *ScheduledAgentEvent) *runRules {
+	return &runRules{
+		policy:          event.Policy.WithMaxPrice(event.MaxPrice),
+		requireApproval: event.RequireApproval,
+		dryRun:          event.DryRun,
+	}
+}
+
+// withRunRules returns a context whose tool calls follow rules
+func withRunRules(ctx context.Context, rules *runRules) context.Context {
+	return context.WithValue(ctx, runRulesKey{}, rules)
+}
+
+// rulesFromContext returns the context's run rules; chat turns have none, so nothing is forced
+func rulesFromContext(ctx context.Context) *runRules {
+	if rules, ok := ctx.Value(runRulesKey{}).(*runRules); ok {
+		return rules
+	}
+	return &runRules{}
+}
+
+// recordBookings keeps the tee times a booking tool reports in its JSON content
+func (r *runRules) recordBookings(result *protocol.ToolCallResult) {
+	if result == nil || result.IsError {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, content := range result.Content {
+		if content.MimeType != protocol.JSONMimeType {
+			continue
+		}
+		var booked models.BookedTeeTime
+		if err := json.Unmarshal([]byte(content.Text), &booked); err != nil || booked.TeeTime == "" {
+			continue
+		}
+		r.bookings = append(r.bookings, booked)
+	}
+}
+
+// checkBookings evaluates every tee time the run booked against its booking policy once the
+// conversation is over, so a booking that slipped past the tools is reported, never trusted
+func (r *runRules) checkBookings() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.policy.IsEmpty() {
+		return nil
+	}
+
+	engine := policy.NewEngine(r.policy)
+	for _, booked := range r.bookings {
+		candidate, err := bookedCandidate(booked)
+		if err != nil {
+			return err
+		}
+		if err := engine.Evaluate(candidate).Err(); err != nil {
+			return fmt.Errorf("booked %s at %s (reservation %d): %w", booked.TeeTime, booked.CourseName, booked.ReservationID, err)
+		}
+	}
+	return nil
+}
+
+// bookedCandidate builds the policy candidate for a booked tee time at its course
+func bookedCandidate(booked models.BookedTeeTime) (policy.Candidate, error) {
+	teeTime, err := time.ParseInLocation("2006-01-02T15:04:05", booked.TeeTime, courseLocation(booked.CourseName))
+	if err != nil {
+		return policy.Candidate{}, fmt.Errorf("invalid booked tee time %q: %w", booked.TeeTime, err)
+	}
+	candidate := policy.Candidate{
+		TeeTime:      teeTime,
+		HasTime:      true,
+		GreenFee:     booked.GreenFee,
+		PrecipChance: booked.PrecipChance,
+	}
+	if course, err := courses.GetCourseByName(booked.CourseName); err == nil && (course.Latitude != 0 || course.Longitude != 0) {
+		candidate.Location = &policy.Coordinates{Latitude: course.Latitude, Longitude: course.Longitude}
+	}
+	return candidate, nil
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/policy"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+func TestRunRules_CheckBookings(t *testing.T) {
+	booked := func(teeTime string, fee float64, precip int) *protocol.ToolCallResult {
+		item, err := protocol.NewJSONContent(models.BookedTeeTime{
+			CourseName:    "Birdsfoot Golf Course",
+			TeeSheetID:    918274,
+			ReservationID: 55,
+			TeeTime:       teeTime,
+			GreenFee:      fee,
+			PrecipChance:  &precip,
+		})
+		if err != nil {
+			t.Fatalf("NewJSONContent() error = %v", err)
+		}
+		return &protocol.ToolCallResult{Content: []protocol.Content{protocol.NewTextContent("✅ Booked"), item}}
+	}
+
+	tests := []struct {
+		name    string
+		event   ScheduledAgentEvent
+		result  *protocol.ToolCallResult
+		wantErr bool
+	}{
+		{"no policy", ScheduledAgentEvent{}, booked("2030-06-01T07:30:00", 80, 90), false},
+		{"within the rules", ScheduledAgentEvent{MaxPrice: 60, Policy: &policy.Config{MaxPrecipChance: 50}}, booked("2030-06-01T07:30:00", 52, 20), false},
+		{"over the price cap", ScheduledAgentEvent{MaxPrice: 40}, booked("2030-06-01T07:30:00", 52, 20), true},
+		{"too wet", ScheduledAgentEvent{Policy: &policy.Config{MaxPrecipChance: 50}}, booked("2030-06-01T07:30:00", 52, 70), true},
+		{"blackout date", ScheduledAgentEvent{Policy: &policy.Config{BlackoutDates: []string{"2030-06-01"}}}, booked("2030-06-01T07:30:00", 52, 0), true},
+		{"failed booking", ScheduledAgentEvent{MaxPrice: 40}, &protocol.ToolCallResult{IsError: true, Content: booked("2030-06-01T07:30:00", 52, 0).Content}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := newRunRules(&tt.event)
+			rules.recordBookings(tt.result)
+			if err := rules.checkBookings(); (err != nil) != tt.wantErr {
+				t.Errorf("checkBookings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAWSAgentEventHandler_PrepareToolCallRunRules(t *testing.T) {
+	h := &AWSAgentEventHandler{}
+	use := types.ToolUseBlock{
+		ToolUseId: aws.String("tool-1"),
+		Name:      aws.String("golf_book_tee_time"),
+		Input:     document.NewLazyDocument(map[string]interface{}{"course_name": "Birdsfoot", "tee_sheet_id": 918274}),
+	}
+
+	ctx := withRunRules(context.Background(), newRunRules(&ScheduledAgentEvent{MaxPrice: 45, RequireApproval: true, DryRun: true}))
+	call, err := h.prepareToolCall(ctx, use)
+	if err != nil {
+		t.Fatalf("prepareToolCall() error = %v", err)
+	}
+	args := call.request.Arguments
+	if cfg, ok := args["policy"].(*policy.Config); !ok || cfg.MaxPrice != 45 {
+		t.Errorf("policy = %v, want the run's $45 cap", args["policy"])
+	}
+	if args["require_approval"] != true || args["dry_run"] != true {
+		t.Errorf("arguments = %v, want approval and dry run forced", args)
+	}
+
+	// A chat turn carries no run rules, so nothing is forced
+	call, err = h.prepareToolCall(context.Background(), use)
+	if err != nil {
+		t.Fatalf("prepareToolCall() error = %v", err)
+	}
+	for _, name := range []string{"policy", "require_approval", "dry_run"} {
+		if _, ok := call.request.Arguments[name]; ok {
+			t.Errorf("chat call has %s = %v, want it unset", name, call.request.Arguments[name])
+		}
+	}
+}
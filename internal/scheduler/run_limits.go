@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// defaultMaxIterations caps the model calls of a run whose schedule sets no limit
+const defaultMaxIterations = 10
+
+// stoppedNotificationTool notifies the golfer that a run stopped early
+const stoppedNotificationTool = "send_push_notification"
+
+// runBudget tracks a run's model calls and token usage against its limits
+type runBudget struct {
+	limits       models.RunLimits
+	modelID      string
+	inputTokens  int64
+	outputTokens int64
+	lastInput    int64
+	lastOutput   int64
+}
+
+// newRunBudget starts the budget of a run of the given model
+func newRunBudget(limits models.RunLimits, modelID string) *runBudget {
+	return &runBudget{limits: limits, modelID: modelID}
+}
+
+// maxIterations returns how many model calls the run may make
+func (b *runBudget) maxIterations() int {
+	if b.limits.MaxIterations > 0 {
+		return b.limits.MaxIterations
+	}
+	return defaultMaxIterations
+}
+
+// pricingKnown reports whether the run's cost can be estimated, which a cost limit needs
+func (b *runBudget) pricingKnown() bool {
+	_, ok := estimateCost(b.modelID, 0, 0)
+	return ok
+}
+
+// record adds a model call's token usage
+func (b *runBudget) record(usage *types.TokenUsage) {
+	if usage == nil {
+		return
+	}
+	b.lastInput = int64(aws.ToInt32(usage.InputTokens))
+	b.lastOutput = int64(aws.ToInt32(usage.OutputTokens))
+	b.inputTokens += b.lastInput
+	b.outputTokens += b.lastOutput
+}
+
+// exceeded returns why the next model call would go over the token or cost limit, or "" when it
+// fits. The next call's input repeats the whole conversation so far, so it is estimated as the
+// last call's input and output, with about as much output again.
+func (b *runBudget) exceeded() string {
+	if b.lastInput == 0 && b.lastOutput == 0 {
+		return ""
+	}
+	totalInput := b.inputTokens + b.lastInput + b.lastOutput
+	totalOutput := b.outputTokens + b.lastOutput
+
+	if b.limits.MaxTokens > 0 && totalInput+totalOutput > b.limits.MaxTokens {
+		return fmt.Sprintf("its next model call would go over its limit of %d tokens", b.limits.MaxTokens)
+	}
+	if b.limits.MaxCost > 0 {
+		if cost, ok := estimateCost(b.modelID, totalInput, totalOutput); ok && cost > b.limits.MaxCost {
+			return fmt.Sprintf("its next model call would go over its $%.2f cost limit", b.limits.MaxCost)
+		}
+	}
+	return ""
+}
+
+// stopRun ends a run early without failing its schedule. The run summary records why, the golfer
+// is notified, and what the agent got done becomes the run's final response.
+func (h *AWSAgentEventHandler) stopRun(ctx context.Context, reason string, messages []types.Message) string {
+	response := h.partialResponse(reason, messages)
+	if h.runSummary != nil {
+		h.runSummary.StoppedReason = reason
+	}
+	h.logger.WarnContext(ctx, "agent run stopped early", slog.String("reason", reason))
+
+	args := map[string]interface{}{
+		"title":    "Golf agent run stopped early",
+		"message":  response,
+		"priority": "high",
+		"tags":     []string{"warning"},
+	}
+	if h.runSummaryLink != "" {
+		args["click_url"] = h.runSummaryLink
+	}
+	// The agent never reached its own result notification, so this one stands in for it
+	if _, err := h.callTool(ctx, protocol.ToolCallRequest{Name: stoppedNotificationTool, Arguments: args}); err != nil {
+		h.logger.WarnContext(ctx, "failed to send run stopped notification", slog.String("error", err.Error()))
+	}
+	return response
+}
+
+// partialResponse is the final response of a run that stopped early: why, what the agent last
+// said, and the tools it had already called, so a booking it made is not reported as a failure
+func (h *AWSAgentEventHandler) partialResponse(reason string, messages []types.Message) string {
+	var response strings.Builder
+	fmt.Fprintf(&response, "This run stopped early because %s; it may not have finished.", reason)
+
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != types.ConversationRoleAssistant {
+			continue
+		}
+		if text := strings.TrimSpace(h.extractTextFromMessage(messages[i])); text != "" {
+			response.WriteString("\n\nLast response:\n")
+			response.WriteString(text)
+		}
+		break
+	}
+
+	if h.runSummary != nil && len(h.runSummary.ToolCalls) > 0 {
+		response.WriteString("\n\nTool calls completed:")
+		for _, call := range h.runSummary.ToolCalls {
+			status := "ok"
+			if call.Failed {
+				status = "failed"
+			}
+			fmt.Fprintf(&response, "\n- %s (%s)", call.Name, status)
+		}
+	}
+	return response.String()
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// recordingToolCaller answers every tool call with "ok" and records the requests
+type recordingToolCaller struct {
+	mu       sync.Mutex
+	requests []protocol.ToolCallRequest
+}
+
+func (c *recordingToolCaller) CallTool(ctx context.Context, req protocol.ToolCallRequest) (*protocol.ToolCallResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+	return &protocol.ToolCallResult{Content: []protocol.Content{{Type: "text", Text: "ok"}}}, nil
+}
+
+// toolLoopConverser asks for the weather on every call, so the run never finishes by itself
+type toolLoopConverser struct {
+	calls int
+}
+
+func (f *toolLoopConverser) Converse(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error) {
+	f.calls++
+	return &bedrockruntime.ConverseOutput{
+		Output: &types.ConverseOutputMemberMessage{Value: types.Message{
+			Role: types.ConversationRoleAssistant,
+			Content: []types.ContentBlock{
+				&types.ContentBlockMemberText{Value: "Checking the weather again"},
+				toolUse("weather", "get_weather"),
+			},
+		}},
+		StopReason: types.StopReasonToolUse,
+		Usage:      &types.TokenUsage{InputTokens: aws.Int32(1000), OutputTokens: aws.Int32(500)},
+	}, nil
+}
+
+func TestAWSAgentEventHandler_RunLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		modelID    string
+		limits     models.RunLimits
+		wantCalls  int
+		wantReason string
+	}{
+		{"default iterations", "amazon.nova-lite-v1:0", models.RunLimits{}, defaultMaxIterations, "limit of 10 model calls"},
+		{"max iterations", "amazon.nova-lite-v1:0", models.RunLimits{MaxIterations: 3}, 3, "limit of 3 model calls"},
+		// After three calls the fourth is estimated to bring the run to 4500 input and 2000 output tokens
+		{"max tokens", "amazon.nova-lite-v1:0", models.RunLimits{MaxTokens: 6000}, 3, "limit of 6000 tokens"},
+		// Each nova-pro call costs $0.0024, and after eight the ninth is estimated to bring the run to $0.0220
+		{"max cost", "amazon.nova-pro-v1:0", models.RunLimits{MaxCost: 0.02}, 8, "$0.02 cost limit"},
+		{"max cost without pricing", "unknown-model", models.RunLimits{MaxCost: 0.001, MaxIterations: 4}, 4, "limit of 4 model calls"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converser := &toolLoopConverser{}
+			tools := &recordingToolCaller{}
+			h := &AWSAgentEventHandler{
+				bedrockClient:  converser,
+				logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+				modelID:        tt.modelID,
+				throttleBudget: defaultThrottleBudget,
+				toolCaller:     tools,
+			}
+
+			event := &ScheduledAgentEvent{ScheduleID: "sched-1", UserPrompt: "Book a tee time", RunLimits: tt.limits}
+			response, err := h.executeAgentConversation(context.Background(), event, "system", "", "", nil)
+			if err != nil {
+				t.Fatalf("executeAgentConversation() error = %v, want the run stopped gracefully", err)
+			}
+			if converser.calls != tt.wantCalls {
+				t.Errorf("model calls = %d, want %d", converser.calls, tt.wantCalls)
+			}
+			if !strings.Contains(h.runSummary.StoppedReason, tt.wantReason) {
+				t.Errorf("StoppedReason = %q, want it to mention %q", h.runSummary.StoppedReason, tt.wantReason)
+			}
+			if !strings.Contains(response, "Checking the weather again") || !strings.Contains(response, "get_weather (ok)") {
+				t.Errorf("response = %q, want the last reply and the tool calls made", response)
+			}
+
+			last := tools.requests[len(tools.requests)-1]
+			if last.Name != stoppedNotificationTool || last.Arguments["message"] != response {
+				t.Errorf("last tool call = %s %v, want the stopped notification carrying the response", last.Name, last.Arguments)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// runSummaryLinkTTL is how long the presigned summary link is requested to stay valid. Links signed
+// with the Lambda role's session credentials stop working when that session expires, whichever is sooner.
+const runSummaryLinkTTL = 24 * time.Hour
+
+// modelPricing is the on-demand Bedrock price in dollars per 1K input and output tokens
+type modelPricing struct {
+	InputPer1K  float64
+	OutputPer1K float64
+}
+
+// bedrockPricing holds prices for the models the scheduler is configured with
+var bedrockPricing = map[string]modelPricing{
+	"amazon.nova-lite-v1:0":                     {InputPer1K: 0.00006, OutputPer1K: 0.00024},
+	"amazon.nova-pro-v1:0":                      {InputPer1K: 0.0008, OutputPer1K: 0.0032},
+	"anthropic.claude-3-5-sonnet-20241022-v2:0": {InputPer1K: 0.003, OutputPer1K: 0.015},
+	"anthropic.claude-3-5-haiku-20241022-v1:0":  {InputPer1K: 0.0008, OutputPer1K: 0.004},
+}
+
+// ToolCallSummary is one tool call made during an agent run
+type ToolCallSummary struct {
+	Name      string
+	Arguments string
+	Input     map[string]interface{}
+	Result    string
+	Failed    bool
+	Duration  time.Duration
+}
+
+// RunSummary captures what happened during one scheduled agent run for a human-readable report
+type RunSummary struct {
+	ScheduleID    string
+	ExecutionID   string
+	CorrelationID string
+	Stage         string
+	ModelID       string
+	CourseName    string
+	UserPrompt    string
+	StartedAt     time.Time
+	Duration      time.Duration
+	Iterations    int
+	InputTokens   int64
+	OutputTokens  int64
+	ToolCalls     []ToolCallSummary
+	FinalResponse string
+	Error         string
+	// StoppedReason says why the run stopped before it finished, when a limit or Bedrock
+	// throttling cut it short
+	StoppedReason string
+
+	// The run's inputs, kept so the run can be recorded and replayed
+	Event          *ScheduledAgentEvent
+	PromptTemplate string
+	PromptData     interface{}
+	SystemMessage  string
+	Tools          []protocol.Tool
+}
+
+// NewRunSummary starts a summary for a scheduled agent run
+func NewRunSummary(event *ScheduledAgentEvent, executionID, stage, modelID string, startedAt time.Time) *RunSummary {
+	return &RunSummary{
+		ScheduleID:  event.ScheduleID,
+		ExecutionID: executionID,
+		Stage:       stage,
+		ModelID:     modelID,
+		CourseName:  event.CourseName,
+		UserPrompt:  event.UserPrompt,
+		StartedAt:   startedAt,
+		Event:       event,
+	}
+}
+
+// AddUsage accumulates Bedrock token usage
+func (s *RunSummary) AddUsage(inputTokens, outputTokens *int32) {
+	s.Iterations++
+	s.InputTokens += int64(aws.ToInt32(inputTokens))
+	s.OutputTokens += int64(aws.ToInt32(outputTokens))
+}
+
+// RecordToolCall adds a tool call, its result, and how long it took
+func (s *RunSummary) RecordToolCall(name string, args map[string]interface{}, result string, failed bool, duration time.Duration) {
+	s.ToolCalls = append(s.ToolCalls, ToolCallSummary{
+		Name:      name,
+		Arguments: formatToolArguments(args),
+		Input:     args,
+		Result:    result,
+		Failed:    failed,
+		Duration:  duration.Round(time.Millisecond),
+	})
+}
+
+// EstimatedCost returns the Bedrock cost of the run in dollars, and false for models without known pricing
+func (s *RunSummary) EstimatedCost() (float64, bool) {
+	return estimateCost(s.ModelID, s.InputTokens, s.OutputTokens)
+}
+
+// estimateCost prices token usage for a model, returning false for models without known pricing
+func estimateCost(modelID string, inputTokens, outputTokens int64) (float64, bool) {
+	pricing, ok := bedrockPricing[modelID]
+	if !ok {
+		return 0, false
+	}
+	return float64(inputTokens)/1000*pricing.InputPer1K + float64(outputTokens)/1000*pricing.OutputPer1K, true
+}
+
+// lastResult returns the result of the last successful call to the named tool
+func (s *RunSummary) lastResult(name string) string {
+	for i := len(s.ToolCalls) - 1; i >= 0; i-- {
+		if s.ToolCalls[i].Name == name && !s.ToolCalls[i].Failed {
+			return s.ToolCalls[i].Result
+		}
+	}
+	return ""
+}
+
+// formatToolArguments renders tool arguments as sorted key=value pairs
+func formatToolArguments(args map[string]interface{}) string {
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, args[k]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+var runSummaryTemplate = template.Must(template.New("run_summary").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Agent run {{.ExecutionID}}</title>
+<style>
+body { font-family: -apple-system, sans-serif; margin: 1.5em; color: #222; }
+pre { white-space: pre-wrap; background: #f4f4f4; padding: 0.75em; border-radius: 4px; }
+.failed { color: #b00020; }
+td { padding: 0.2em 1em 0.2em 0; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>⛳ Agent run summary</h1>
+<table>
+<tr><td>Schedule</td><td>{{.ScheduleID}}</td></tr>
+<tr><td>Execution</td><td>{{.ExecutionID}}</td></tr>
+{{if .CorrelationID}}<tr><td>Correlation ID</td><td>{{.CorrelationID}}</td></tr>{{end}}
+<tr><td>Course</td><td>{{.CourseName}}</td></tr>
+<tr><td>Started</td><td>{{.StartedAt.Format "Mon Jan 2, 2006 3:04:05 PM MST"}}</td></tr>
+<tr><td>Duration</td><td>{{.Duration}}</td></tr>
+<tr><td>Model</td><td>{{.ModelID}} ({{.Stage}})</td></tr>
+<tr><td>Tokens</td><td>{{.InputTokens}} in / {{.OutputTokens}} out over {{.Iterations}} call(s)</td></tr>
+<tr><td>Estimated cost</td><td>{{.Cost}}</td></tr>
+</table>
+{{if .Error}}<h2 class="failed">Error</h2><pre>{{.Error}}</pre>{{end}}
+{{if .StoppedReason}}<p class="failed">This run stopped early because {{.StoppedReason}}; the final response is partial.</p>{{end}}
+<h2>Request</h2>
+<pre>{{.UserPrompt}}</pre>
+<h2>Decision</h2>
+{{if .Decision}}<pre>{{.Decision}}</pre>{{else}}<p>No decision was recorded.</p>{{end}}
+<h2>Booking</h2>
+{{if .Booking}}<pre>{{.Booking}}</pre>{{else}}<p>No booking was made.</p>{{end}}
+<h2>Tool calls ({{len .ToolCalls}})</h2>
+<ol>
+{{range .ToolCalls}}<li{{if .Failed}} class="failed"{{end}}><strong>{{.Name}}</strong>{{if .Arguments}} <code>{{.Arguments}}</code>{{end}}{{if .Duration}} ({{.Duration}}){{end}}
+<pre>{{.Result}}</pre></li>
+{{end}}</ol>
+<h2>Final response</h2>
+<pre>{{.FinalResponse}}</pre>
+</body>
+</html>
+`))
+
+// HTML renders the summary as a standalone HTML page
+func (s *RunSummary) HTML() ([]byte, error) {
+	cost := "unknown"
+	if c, ok := s.EstimatedCost(); ok {
+		cost = fmt.Sprintf("$%.4f", c)
+	}
+
+	data := struct {
+		*RunSummary
+		Cost     string
+		Decision string
+		Booking  string
+	}{
+		RunSummary: s,
+		Cost:       cost,
+		Decision:   s.lastResult("explain_decision"),
+		Booking:    s.lastResult("golf_book_tee_time"),
+	}
+
+	var buf bytes.Buffer
+	if err := runSummaryTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render run summary: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RunSummaryPublisher stores run summaries in S3 and produces shareable links to them
+type RunSummaryPublisher struct {
+	client  *s3.Client
+	presign *s3.PresignClient
+	bucket  string
+	stage   string
+}
+
+// NewRunSummaryPublisher creates a publisher that writes summaries to the given bucket
+func NewRunSummaryPublisher(client *s3.Client, bucket, stage string) *RunSummaryPublisher {
+	return &RunSummaryPublisher{
+		client:  client,
+		presign: s3.NewPresignClient(client),
+		bucket:  bucket,
+		stage:   stage,
+	}
+}
+
+// Key returns the object key for a run's summary, grouped by schedule so a schedule's summaries can be found and removed
+func (p *RunSummaryPublisher) Key(summary *RunSummary) string {
+	return fmt.Sprintf("%s%s/%s.html", p.schedulePrefix(summary.ScheduleID), summary.StartedAt.UTC().Format("2006/01/02"), summary.ExecutionID)
+}
+
+// schedulePrefix returns the key prefix under which a schedule's summaries are stored
+func (p *RunSummaryPublisher) schedulePrefix(scheduleID string) string {
+	if scheduleID == "" {
+		scheduleID = "unscheduled"
+	}
+	return fmt.Sprintf("summaries/%s/%s/", p.stage, scheduleID)
+}
+
+// ListScheduleSummaries returns the object keys of every summary and recording stored for a
+// schedule
+func (p *RunSummaryPublisher) ListScheduleSummaries(ctx context.Context, scheduleID string) ([]string, error) {
+	keys, err := p.listKeys(ctx, p.schedulePrefix(scheduleID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run summaries: %w", err)
+	}
+	recordings, err := p.listKeys(ctx, p.recordingPrefix(scheduleID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run recordings: %w", err)
+	}
+	return append(keys, recordings...), nil
+}
+
+// listKeys returns the object keys under prefix
+func (p *RunSummaryPublisher) listKeys(ctx context.Context, prefix string) ([]string, error) {
+	paginator := s3.NewListObjectsV2Paginator(p.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(p.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	keys := make([]string, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+	return keys, nil
+}
+
+// DeleteScheduleSummaries removes every summary stored for a schedule and returns how many were deleted
+func (p *RunSummaryPublisher) DeleteScheduleSummaries(ctx context.Context, scheduleID string) (int, error) {
+	keys, err := p.ListScheduleSummaries(ctx, scheduleID)
+	if err != nil {
+		return 0, err
+	}
+
+	// DeleteObjects accepts at most 1000 keys per request
+	for start := 0; start < len(keys); start += 1000 {
+		end := min(start+1000, len(keys))
+		objects := make([]s3types.ObjectIdentifier, 0, end-start)
+		for _, key := range keys[start:end] {
+			objects = append(objects, s3types.ObjectIdentifier{Key: aws.String(key)})
+		}
+
+		out, err := p.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(p.bucket),
+			Delete: &s3types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return start, fmt.Errorf("failed to delete run summaries: %w", err)
+		}
+		if len(out.Errors) > 0 {
+			return start, fmt.Errorf("failed to delete run summary %s: %s", aws.ToString(out.Errors[0].Key), aws.ToString(out.Errors[0].Message))
+		}
+	}
+	return len(keys), nil
+}
+
+// Link returns a presigned link to the run's summary; the object does not need to exist yet
+func (p *RunSummaryPublisher) Link(ctx context.Context, summary *RunSummary) (string, error) {
+	req, err := p.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(p.Key(summary)),
+	}, s3.WithPresignExpires(runSummaryLinkTTL))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign run summary link: %w", err)
+	}
+	return req.URL, nil
+}
+
+// Publish renders the summary and uploads it to S3 with the run's recording
+func (p *RunSummaryPublisher) Publish(ctx context.Context, summary *RunSummary) error {
+	body, err := summary.HTML()
+	if err != nil {
+		return err
+	}
+
+	_, err = p.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(p.bucket),
+		Key:         aws.String(p.Key(summary)),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("text/html; charset=utf-8"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload run summary: %w", err)
+	}
+
+	return p.publishRecording(ctx, summary)
+}
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/jrzesz33/rez_agent/internal/prompts"
+)
+
+func TestRunSummary_EstimatedCost(t *testing.T) {
+	summary := &RunSummary{ModelID: "anthropic.claude-3-5-sonnet-20241022-v2:0"}
+	summary.AddUsage(aws.Int32(2000), aws.Int32(500))
+	summary.AddUsage(aws.Int32(1000), nil)
+
+	cost, ok := summary.EstimatedCost()
+	if !ok {
+		t.Fatal("expected pricing for a known model")
+	}
+	if want := 3*0.003 + 0.5*0.015; math.Abs(cost-want) > 1e-9 {
+		t.Errorf("EstimatedCost() = %v, want %v", cost, want)
+	}
+	if summary.Iterations != 2 {
+		t.Errorf("Iterations = %d, want 2", summary.Iterations)
+	}
+
+	if _, ok := (&RunSummary{ModelID: "unknown"}).EstimatedCost(); ok {
+		t.Error("expected no pricing for an unknown model")
+	}
+}
+
+func TestRunSummary_HTML(t *testing.T) {
+	summary := NewRunSummary(&ScheduledAgentEvent{ScheduleID: "sched-1", CourseName: "Totteridge", UserPrompt: "Book <Saturday>"},
+		"123", "dev", "amazon.nova-lite-v1:0", time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC))
+	summary.RecordToolCall("golf_search_tee_times", map[string]interface{}{"course_name": "Totteridge", "date": "2030-06-08"}, "3 tee times", false, 1200*time.Millisecond)
+	summary.RecordToolCall("golf_book_tee_time", map[string]interface{}{"tee_sheet_id": 42}, "timeout", true, 20*time.Second)
+	summary.RecordToolCall("golf_book_tee_time", map[string]interface{}{"tee_sheet_id": 42}, "Confirmation ABC123", false, 3*time.Second)
+	summary.RecordToolCall("explain_decision", nil, "Verified: the booking satisfies every hard booking rule", false, 0)
+
+	body, err := summary.HTML()
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	html := string(body)
+
+	for _, want := range []string{
+		"sched-1",
+		"Book &lt;Saturday&gt;",
+		"course_name=Totteridge, date=2030-06-08",
+		"Confirmation ABC123",
+		"Verified: the booking satisfies every hard booking rule",
+		"Tool calls (4)",
+		"(1.2s)",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML() missing %q", want)
+		}
+	}
+}
+
+func TestRunSummaryPublisher_Key(t *testing.T) {
+	publisher := &RunSummaryPublisher{bucket: "rez-agent-logs-dev", stage: "dev"}
+	startedAt := time.Date(2026, 10, 3, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		scheduleID string
+		want       string
+	}{
+		{"scheduled run", "sched_1", "summaries/dev/sched_1/2026/10/03/42.html"},
+		{"run without a schedule", "", "summaries/dev/unscheduled/2026/10/03/42.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := publisher.Key(&RunSummary{ScheduleID: tt.scheduleID, ExecutionID: "42", StartedAt: startedAt})
+			if got != tt.want {
+				t.Errorf("Key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSummary_RecordingRoundTrip(t *testing.T) {
+	startedAt := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	summary := NewRunSummary(&ScheduledAgentEvent{ScheduleID: "sched-1", CourseName: "Totteridge", UserPrompt: "Book Saturday", NumPlayers: 2},
+		"123", "dev", "amazon.nova-lite-v1:0", startedAt)
+	summary.PromptTemplate = prompts.ScheduledBooking
+	summary.PromptData = prompts.BookingData{CurrentDate: "Saturday, June 1, 2030", NumPlayers: 2}
+	summary.SystemMessage = "You are a golf booking agent"
+	summary.RecordToolCall("golf_search_tee_times", map[string]interface{}{"date": "2030-06-08", "players": 2}, "3 tee times", false, time.Second)
+	summary.FinalResponse = "Booked"
+
+	recording, err := summary.Recording()
+	if err != nil {
+		t.Fatalf("Recording() error = %v", err)
+	}
+	body, err := json.Marshal(recording)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	parsed, err := ParseRunRecording(body)
+	if err != nil {
+		t.Fatalf("ParseRunRecording() error = %v", err)
+	}
+
+	if parsed.Event.NumPlayers != 2 || parsed.FinalResponse != "Booked" || len(parsed.ToolCalls) != 1 {
+		t.Errorf("ParseRunRecording() = %+v, want the recorded run", parsed)
+	}
+	if got := parsed.ToolCalls[0].Arguments["date"]; got != "2030-06-08" {
+		t.Errorf("tool call date = %v, want 2030-06-08", got)
+	}
+	data, err := prompts.DecodeData(parsed.PromptTemplate, parsed.PromptData)
+	if err != nil || data.(prompts.BookingData).NumPlayers != 2 {
+		t.Errorf("DecodeData() = %+v, %v; want the recorded prompt data", data, err)
+	}
+
+	publisher := &RunSummaryPublisher{bucket: "rez-agent-logs-dev", stage: "dev"}
+	if got, want := publisher.RecordingKey(summary), "recordings/dev/sched-1/2030/06/01/123.json"; got != want {
+		t.Errorf("RecordingKey() = %q, want %q", got, want)
+	}
+
+	if _, err := ParseRunRecording([]byte(`{"execution_id":"1","system_message":"hi"}`)); err == nil {
+		t.Error("ParseRunRecording() without an event error = nil, want error")
+	}
+}
+
+func TestRecordingDay(t *testing.T) {
+	day, ok := recordingDay("recordings/dev/sched-1/2030/06/01/123.json")
+	if !ok || !day.Equal(time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("recordingDay() = %v, %v; want 2030-06-01", day, ok)
+	}
+	if _, ok := recordingDay("recordings/dev/123.json"); ok {
+		t.Error("recordingDay() of a key without a date ok = true, want false")
+	}
+}
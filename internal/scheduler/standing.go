@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/prompts"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// courseBookingWindowDays is how many days in advance the named course takes bookings, or the
+// default window for a course that is not configured
+func courseBookingWindowDays(courseName string) int {
+	course, err := courses.GetCourseByName(courseName)
+	if err != nil {
+		course = &courses.Course{}
+	}
+	return course.GetBookingWindowDays()
+}
+
+// standingUserPrompt builds the default instruction for a standing tee time trigger
+func standingUserPrompt(standing *models.StandingTeeTime, triggeredAt time.Time) string {
+	if triggeredAt.IsZero() {
+		triggeredAt = time.Now()
+	}
+	target := standing.TargetDate(triggeredAt.In(courseLocation(standing.CourseName)), courseBookingWindowDays(standing.CourseName))
+
+	if standing.Mode == models.StandingModeRenew {
+		return fmt.Sprintf("Renew my standing tee time: book %s at %s for %d player(s) at %s.",
+			target.Format("Monday, January 2, 2006"), standing.TimeOfDay, standing.NumberOfPlayers, standing.CourseName)
+	}
+	return fmt.Sprintf("Remind me that the booking window is open for my standing tee time on %s at %s for %d player(s) at %s.",
+		target.Format("Monday, January 2, 2006"), standing.TimeOfDay, standing.NumberOfPlayers, standing.CourseName)
+}
+
+// constructStandingTeeTimeMessage builds the system prompt for standing tee time reminders and renewals
+func (h *AWSAgentEventHandler) constructStandingTeeTimeMessage(ctx context.Context, event *ScheduledAgentEvent, reservations, weather string) (string, error) {
+	standing := event.Standing
+
+	loc := courseLocation(standing.CourseName)
+	triggeredAt := event.TriggeredAt
+	if triggeredAt.IsZero() {
+		triggeredAt = time.Now()
+	}
+	triggeredAt = triggeredAt.In(loc)
+
+	return h.renderPrompt(ctx, prompts.StandingTeeTime, prompts.StandingData{
+		CurrentDate:  time.Now().In(loc).Format("Monday, January 2, 2006"),
+		CourseName:   standing.CourseName,
+		Weekday:      standing.Weekday.String(),
+		TimeOfDay:    standing.TimeOfDay,
+		NumPlayers:   standing.NumberOfPlayers,
+		TargetDate:   standing.TargetDate(triggeredAt, courseBookingWindowDays(standing.CourseName)).Format("Monday, January 2, 2006"),
+		Reservations: reservations,
+		Weather:      weather,
+		Renew:        standing.Mode == models.StandingModeRenew,
+	})
+}
+
+// NewStandingTeeTimeEvent builds the agent event for a triggered standing tee time schedule
+func NewStandingTeeTimeEvent(msg *models.Message, triggeredAt time.Time) (*ScheduledAgentEvent, error) {
+	standing, err := models.DecodePayload[*models.StandingTeeTime](msg)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := models.ParseRunLimitsArgs(msg.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid standing tee time message: %w", err)
+	}
+
+	return &ScheduledAgentEvent{
+		ScheduleID:  ScheduleIDFromArguments(msg.Arguments),
+		CourseName:  standing.CourseName,
+		NumPlayers:  standing.NumberOfPlayers,
+		TriggeredAt: triggeredAt,
+		AuthConfig:  msg.AuthConfig,
+		Owner:       scheduleOwner(msg),
+		Standing:    standing,
+		RunLimits:   limits,
+	}, nil
+}
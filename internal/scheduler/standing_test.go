@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+func TestNewStandingTeeTimeEvent(t *testing.T) {
+	triggeredAt := time.Date(2030, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		args       map[string]interface{}
+		wantErr    bool
+		wantPrompt string
+	}{
+		{
+			name: "renew standing tee time",
+			args: map[string]interface{}{
+				"schedule_id":      "sched_1",
+				"course_name":      "Birdsfoot Golf Course",
+				"standing_weekday": "Saturday",
+				"standing_time":    "08:00",
+				"num_players":      float64(2),
+				"standing_mode":    "renew",
+			},
+			wantPrompt: "Renew my standing tee time: book Saturday, June 15, 2030 at 08:00",
+		},
+		{
+			name: "remind standing tee time",
+			args: map[string]interface{}{
+				"course_name":      "Totteridge",
+				"standing_weekday": "Saturday",
+				"standing_time":    "08:00",
+			},
+			wantPrompt: "Remind me that the booking window is open",
+		},
+		{
+			name:    "missing standing arguments",
+			args:    map[string]interface{}{"course_name": "Totteridge"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &models.Message{MessageType: models.MessageTypeStandingTeeTime, Arguments: tt.args}
+			event, err := NewStandingTeeTimeEvent(msg, triggeredAt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStandingTeeTimeEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if event.ScheduleID != ScheduleIDFromArguments(tt.args) {
+				t.Errorf("ScheduleID = %q, want %q", event.ScheduleID, ScheduleIDFromArguments(tt.args))
+			}
+			prompt := standingUserPrompt(event.Standing, event.TriggeredAt)
+			if !strings.Contains(prompt, tt.wantPrompt) {
+				t.Errorf("standingUserPrompt() = %q, want it to contain %q", prompt, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestStandingUserPrompt_CourseLocalDate(t *testing.T) {
+	// 02:00 UTC on Saturday is still Friday evening at the course
+	triggeredAt := time.Date(2030, 6, 1, 2, 0, 0, 0, time.UTC)
+	standing := &models.StandingTeeTime{
+		CourseName:      "Birdsfoot Golf Course",
+		Weekday:         time.Friday,
+		TimeOfDay:       "08:00",
+		NumberOfPlayers: 2,
+		Mode:            models.StandingModeRenew,
+	}
+
+	prompt := standingUserPrompt(standing, triggeredAt)
+	if want := "book Friday, June 14, 2030 at 08:00"; !strings.Contains(prompt, want) {
+		t.Errorf("standingUserPrompt() = %q, want it to contain %q", prompt, want)
+	}
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// converseStreamer streams a model call's events as the model generates them
+type converseStreamer interface {
+	ConverseStream(ctx context.Context, input *bedrockruntime.ConverseStreamInput) (bedrockruntime.ConverseStreamOutputReader, error)
+}
+
+// bedrockStreamClient streams model calls with the Bedrock runtime client
+type bedrockStreamClient struct {
+	client *bedrockruntime.Client
+}
+
+func (c bedrockStreamClient) ConverseStream(ctx context.Context, input *bedrockruntime.ConverseStreamInput) (bedrockruntime.ConverseStreamOutputReader, error) {
+	output, err := c.client.ConverseStream(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return output.GetStream(), nil
+}
+
+// converseStream makes a model call with ConverseStream, passing text to onText as it arrives,
+// and returns the call's result as Converse would. Throttling is waited out like converse does
+// until the stream opens; an error after that ends the call.
+func (h *AWSAgentEventHandler) converseStream(ctx context.Context, backoff *throttleBackoff, input *bedrockruntime.ConverseInput, onText func(string)) (*bedrockruntime.ConverseOutput, error) {
+	var stream bedrockruntime.ConverseStreamOutputReader
+	err := h.retryThrottled(ctx, backoff, func() error {
+		var err error
+		stream, err = h.streamer.ConverseStream(ctx, &bedrockruntime.ConverseStreamInput{
+			ModelId:         input.ModelId,
+			System:          input.System,
+			Messages:        input.Messages,
+			ToolConfig:      input.ToolConfig,
+			InferenceConfig: input.InferenceConfig,
+			GuardrailConfig: streamGuardrail(input.GuardrailConfig),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	return collectStream(stream, onText)
+}
+
+// streamGuardrail applies a Converse guardrail to a streamed call
+func streamGuardrail(config *types.GuardrailConfiguration) *types.GuardrailStreamConfiguration {
+	if config == nil {
+		return nil
+	}
+	return &types.GuardrailStreamConfiguration{
+		GuardrailIdentifier: config.GuardrailIdentifier,
+		GuardrailVersion:    config.GuardrailVersion,
+		Trace:               config.Trace,
+	}
+}
+
+// streamedBlock is a content block being assembled from stream events
+type streamedBlock struct {
+	text      strings.Builder
+	toolUseID string
+	toolName  string
+	toolInput strings.Builder
+}
+
+// collectStream reads a model call's events into the message, stop reason and usage Converse
+// returns, passing each piece of text to onText as it arrives
+func collectStream(stream bedrockruntime.ConverseStreamOutputReader, onText func(string)) (*bedrockruntime.ConverseOutput, error) {
+	blocks := make(map[int32]*streamedBlock)
+	block := func(index *int32) *streamedBlock {
+		i := aws.ToInt32(index)
+		if blocks[i] == nil {
+			blocks[i] = &streamedBlock{}
+		}
+		return blocks[i]
+	}
+	output := &bedrockruntime.ConverseOutput{}
+
+	for event := range stream.Events() {
+		switch event := event.(type) {
+		case *types.ConverseStreamOutputMemberContentBlockStart:
+			if start, ok := event.Value.Start.(*types.ContentBlockStartMemberToolUse); ok {
+				b := block(event.Value.ContentBlockIndex)
+				b.toolUseID = aws.ToString(start.Value.ToolUseId)
+				b.toolName = aws.ToString(start.Value.Name)
+			}
+		case *types.ConverseStreamOutputMemberContentBlockDelta:
+			switch delta := event.Value.Delta.(type) {
+			case *types.ContentBlockDeltaMemberText:
+				block(event.Value.ContentBlockIndex).text.WriteString(delta.Value)
+				if onText != nil && delta.Value != "" {
+					onText(delta.Value)
+				}
+			case *types.ContentBlockDeltaMemberToolUse:
+				block(event.Value.ContentBlockIndex).toolInput.WriteString(aws.ToString(delta.Value.Input))
+			}
+		case *types.ConverseStreamOutputMemberMessageStop:
+			output.StopReason = event.Value.StopReason
+		case *types.ConverseStreamOutputMemberMetadata:
+			output.Usage = event.Value.Usage
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("bedrock stream failed: %w", err)
+	}
+
+	message := types.Message{Role: types.ConversationRoleAssistant}
+	for _, i := range slices.Sorted(maps.Keys(blocks)) {
+		b := blocks[i]
+		if b.toolUseID == "" {
+			message.Content = append(message.Content, &types.ContentBlockMemberText{Value: b.text.String()})
+			continue
+		}
+		input := map[string]interface{}{}
+		if raw := b.toolInput.String(); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &input); err != nil {
+				return nil, fmt.Errorf("failed to parse streamed input of tool %s: %w", b.toolName, err)
+			}
+		}
+		message.Content = append(message.Content, &types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{
+			ToolUseId: aws.String(b.toolUseID),
+			Name:      aws.String(b.toolName),
+			Input:     document.NewLazyDocument(input),
+		}})
+	}
+	output.Output = &types.ConverseOutputMemberMessage{Value: message}
+	return output, nil
+}
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// ExecutionTargetScheduler points the schedules of agent runs at the scheduler Lambda, which
+// executes them, now that schedules are created by a separate Lambda. Other schedules keep the
+// topic they publish to.
+type ExecutionTargetScheduler struct {
+	next        EventBridgeScheduler
+	functionArn string
+}
+
+// NewExecutionTargetScheduler wraps next so that agent run schedules invoke functionArn
+func NewExecutionTargetScheduler(next EventBridgeScheduler, functionArn string) *ExecutionTargetScheduler {
+	return &ExecutionTargetScheduler{
+		next:        next,
+		functionArn: functionArn,
+	}
+}
+
+// CreateSchedule retargets agent run schedules and creates the schedule
+func (s *ExecutionTargetScheduler) CreateSchedule(ctx context.Context, schedule *models.Schedule) (string, error) {
+	if IsAgentRunSchedule(schedule) && schedule.CreateRequest != nil && schedule.CreateRequest.Target != nil {
+		schedule.CreateRequest.Target.Arn = aws.String(s.functionArn)
+	}
+	return s.next.CreateSchedule(ctx, schedule)
+}
+
+// DeleteSchedule deletes the named schedule
+func (s *ExecutionTargetScheduler) DeleteSchedule(ctx context.Context, name string) error {
+	return s.next.DeleteSchedule(ctx, name)
+}
+
+// IsAgentRunSchedule reports whether the schedule triggers an agent run on the scheduler Lambda
+func IsAgentRunSchedule(schedule *models.Schedule) bool {
+	return schedule.TargetType == models.TargetTypeScheduler || schedule.TargetType == models.TargetTypeStandingTeeTime
+}
@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler/types"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// fakeEventBridgeScheduler keeps the schedules it is asked to create
+type fakeEventBridgeScheduler struct {
+	created []*models.Schedule
+}
+
+func (f *fakeEventBridgeScheduler) CreateSchedule(ctx context.Context, s *models.Schedule) (string, error) {
+	f.created = append(f.created, s)
+	return "arn:aws:scheduler:us-east-1:123456789012:schedule/default/" + s.Name, nil
+}
+
+func (f *fakeEventBridgeScheduler) DeleteSchedule(ctx context.Context, name string) error {
+	return nil
+}
+
+func TestExecutionTargetScheduler_CreateSchedule(t *testing.T) {
+	const functionArn = "arn:aws:lambda:us-east-1:123456789012:function:rez-agent-scheduler-dev"
+	const topicArn = "arn:aws:sns:us-east-1:123456789012:rez-agent-web-actions-dev"
+
+	tests := []struct {
+		targetType models.TargetType
+		wantArn    string
+	}{
+		{models.TargetTypeScheduler, functionArn},
+		{models.TargetTypeStandingTeeTime, functionArn},
+		{models.TargetTypeWebAction, topicArn},
+		{models.TargetTypeWeeklyDigest, topicArn},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.targetType), func(t *testing.T) {
+			next := &fakeEventBridgeScheduler{}
+			s := NewExecutionTargetScheduler(next, functionArn)
+
+			schedule := &models.Schedule{
+				Name:       "saturday",
+				TargetType: tt.targetType,
+				CreateRequest: &scheduler.CreateScheduleInput{
+					Target: &types.Target{Arn: aws.String(topicArn)},
+				},
+			}
+			if _, err := s.CreateSchedule(context.Background(), schedule); err != nil {
+				t.Fatalf("CreateSchedule() error = %v", err)
+			}
+			if len(next.created) != 1 {
+				t.Fatalf("created %d schedules, want 1", len(next.created))
+			}
+			if got := aws.ToString(next.created[0].CreateRequest.Target.Arn); got != tt.wantArn {
+				t.Errorf("target = %s, want %s", got, tt.wantArn)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+)
+
+const (
+	// defaultThrottleBudget is how long one run may spend waiting out Bedrock throttling, well
+	// inside the scheduler Lambda's 60 second timeout
+	defaultThrottleBudget = 20 * time.Second
+	// throttleBaseDelay is the first wait after a throttled call
+	throttleBaseDelay = 500 * time.Millisecond
+	// throttleMaxDelay caps the wait between throttled calls
+	throttleMaxDelay = 16 * time.Second
+)
+
+// errThrottleBudgetExhausted ends a conversation that spent its whole throttle budget waiting
+var errThrottleBudgetExhausted = errors.New("bedrock throttle budget exhausted")
+
+// bedrockConverser is the part of the Bedrock runtime client the agent conversation uses
+type bedrockConverser interface {
+	Converse(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error)
+}
+
+// throttleBackoff paces one run's Bedrock calls while they are being throttled. Each throttle
+// doubles the delay and each success halves it, so calls after a burst keep some spacing until
+// Bedrock catches up. Every wait is charged to the run's budget.
+type throttleBackoff struct {
+	base      time.Duration
+	max       time.Duration
+	budget    time.Duration
+	delay     time.Duration
+	spent     time.Duration
+	throttles int
+}
+
+// newThrottleBackoff starts a run's backoff with the given budget
+func newThrottleBackoff(base, maxDelay, budget time.Duration) *throttleBackoff {
+	return &throttleBackoff{base: base, max: maxDelay, budget: budget}
+}
+
+// pace returns how long to wait before the next call, zero when Bedrock has not throttled
+// recently or the budget cannot cover the wait
+func (b *throttleBackoff) pace() time.Duration {
+	if b.delay == 0 || b.spent+b.delay > b.budget {
+		return 0
+	}
+	b.spent += b.delay
+	return b.delay
+}
+
+// throttled records a throttled call and returns how long to wait before retrying it, and false
+// when the wait would go over the budget
+func (b *throttleBackoff) throttled() (time.Duration, bool) {
+	b.throttles++
+	b.delay = min(max(b.delay*2, b.base), b.max)
+
+	// Half fixed, half jitter, so concurrent runs throttled together don't retry together
+	wait := b.delay/2 + rand.N(b.delay/2+1)
+	if b.spent+wait > b.budget {
+		return 0, false
+	}
+	b.spent += wait
+	return wait, true
+}
+
+// succeeded eases the delay after a call that went through
+func (b *throttleBackoff) succeeded() {
+	b.delay /= 2
+	if b.delay < b.base {
+		b.delay = 0
+	}
+}
+
+// converse calls Bedrock, waiting out throttling until the run's budget is spent
+func (h *AWSAgentEventHandler) converse(ctx context.Context, backoff *throttleBackoff, input *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseOutput, error) {
+	var output *bedrockruntime.ConverseOutput
+	err := h.retryThrottled(ctx, backoff, func() error {
+		var err error
+		output, err = h.bedrockClient.Converse(ctx, input)
+		return err
+	})
+	return output, err
+}
+
+// retryThrottled makes a Bedrock call, retrying it while it is throttled until the run's budget
+// is spent
+func (h *AWSAgentEventHandler) retryThrottled(ctx context.Context, backoff *throttleBackoff, call func() error) error {
+	if wait := backoff.pace(); wait > 0 {
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+
+	for {
+		err := call()
+		if err == nil {
+			backoff.succeeded()
+			return nil
+		}
+		if !apperrors.Is(err, apperrors.ErrThrottled) {
+			return err
+		}
+
+		wait, ok := backoff.throttled()
+		if !ok {
+			h.logger.WarnContext(ctx, "bedrock throttle budget exhausted",
+				slog.Int("throttles", backoff.throttles),
+				slog.Duration("waited", backoff.spent),
+			)
+			return fmt.Errorf("%w after %d throttled calls: %w", errThrottleBudgetExhausted, backoff.throttles, err)
+		}
+		h.logger.WarnContext(ctx, "bedrock throttled, backing off",
+			slog.Int("throttles", backoff.throttles),
+			slog.Duration("delay", wait),
+		)
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
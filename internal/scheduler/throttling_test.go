@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// fakeConverser throttles the first throttles calls, then ends the turn with reply
+type fakeConverser struct {
+	throttles int
+	reply     string
+	calls     int
+}
+
+func (f *fakeConverser) Converse(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error) {
+	f.calls++
+	if f.calls <= f.throttles {
+		return nil, &types.ThrottlingException{Message: aws.String("Too many requests")}
+	}
+	return &bedrockruntime.ConverseOutput{
+		Output: &types.ConverseOutputMemberMessage{Value: types.Message{
+			Role:    types.ConversationRoleAssistant,
+			Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: f.reply}},
+		}},
+		StopReason: types.StopReasonEndTurn,
+		Usage:      &types.TokenUsage{InputTokens: aws.Int32(10), OutputTokens: aws.Int32(5)},
+	}, nil
+}
+
+func TestThrottleBackoff(t *testing.T) {
+	b := newThrottleBackoff(time.Second, 4*time.Second, time.Minute)
+
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		wait, ok := b.throttled()
+		if !ok || b.delay != want || wait < want/2 || wait > want {
+			t.Fatalf("throttle %d: wait %v, delay %v, ok %v; want a wait within [%v, %v]", i+1, wait, b.delay, ok, want/2, want)
+		}
+	}
+
+	b.succeeded()
+	if b.delay != 2*time.Second {
+		t.Errorf("delay after a success = %v, want it halved to 2s", b.delay)
+	}
+	if wait := b.pace(); wait != 2*time.Second {
+		t.Errorf("pace() = %v, want the remaining 2s delay", wait)
+	}
+	b.succeeded()
+	b.succeeded()
+	if wait := b.pace(); wait != 0 {
+		t.Errorf("pace() = %v, want no wait once the delay falls below the base", wait)
+	}
+
+	exhausted := newThrottleBackoff(time.Second, 4*time.Second, 100*time.Millisecond)
+	if _, ok := exhausted.throttled(); ok {
+		t.Error("throttled() should refuse a wait longer than the budget")
+	}
+}
+
+func TestAWSAgentEventHandler_ConversationThrottling(t *testing.T) {
+	tests := []struct {
+		name        string
+		throttles   int
+		budget      time.Duration
+		wantStopped bool
+		wantPrefix  string
+	}{
+		{"recovers within budget", 1, time.Minute, false, "Booked 8:10 AM"},
+		{"budget exhausted", 100, time.Millisecond, true, "This run stopped early because Bedrock kept throttling"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converser := &fakeConverser{throttles: tt.throttles, reply: "Booked 8:10 AM"}
+			h := &AWSAgentEventHandler{
+				bedrockClient:  converser,
+				logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+				throttleBudget: tt.budget,
+				toolCaller:     &recordingToolCaller{},
+			}
+
+			response, err := h.executeAgentConversation(context.Background(), &ScheduledAgentEvent{ScheduleID: "sched-1", UserPrompt: "Book a tee time"}, "system", "", "", nil)
+			if err != nil {
+				t.Fatalf("executeAgentConversation() error = %v, want a result", err)
+			}
+			if !strings.HasPrefix(response, tt.wantPrefix) {
+				t.Errorf("response = %q, want it to start with %q", response, tt.wantPrefix)
+			}
+			if stopped := h.runSummary.StoppedReason != ""; stopped != tt.wantStopped {
+				t.Errorf("runSummary.StoppedReason = %q, want stopped %v", h.runSummary.StoppedReason, tt.wantStopped)
+			}
+		})
+	}
+}
+
+func TestAWSAgentEventHandler_ConverseOtherErrors(t *testing.T) {
+	h := &AWSAgentEventHandler{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	h.bedrockClient = converserFunc(func() error { return &types.ValidationException{Message: aws.String("bad input")} })
+
+	_, err := h.converse(context.Background(), newThrottleBackoff(time.Millisecond, time.Millisecond, time.Minute), &bedrockruntime.ConverseInput{})
+	var validation *types.ValidationException
+	if !errors.As(err, &validation) {
+		t.Errorf("converse() error = %v, want the validation error returned without retrying", err)
+	}
+}
+
+// converserFunc fails every call with the error it returns
+type converserFunc func() error
+
+func (f converserFunc) Converse(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error) {
+	return nil, f()
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// slowMCPServer answers every tool call after a delay, echoing the tool name, and records
+// how many calls were running when each one started
+func slowMCPServer(t *testing.T, delay time.Duration) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	running := 0
+	var started []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     interface{} `json:"id"`
+			Params struct {
+				Name string `json:"name"`
+			} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		mu.Lock()
+		running++
+		started = append(started, fmt.Sprintf("%s:%d", req.Params.Name, running))
+		mu.Unlock()
+
+		time.Sleep(delay)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":null,"result":{"content":[{"type":"text","text":%q}]}}`, req.Params.Name)
+	}))
+	return server, &started
+}
+
+func toolUse(id, name string) types.ContentBlock {
+	return &types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{
+		ToolUseId: aws.String(id),
+		Name:      aws.String(name),
+		Input:     document.NewLazyDocument(map[string]interface{}{"course_name": "Birdsfoot"}),
+	}}
+}
+
+func TestAWSAgentEventHandler_ProcessToolCallsConcurrently(t *testing.T) {
+	const delay = 100 * time.Millisecond
+	server, started := slowMCPServer(t, delay)
+	defer server.Close()
+
+	h := &AWSAgentEventHandler{
+		mcpClient:  newTestMCPClient(server.URL),
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		runSummary: &RunSummary{},
+	}
+
+	begin := time.Now()
+	results, err := h.processToolCalls(context.Background(), []types.ContentBlock{
+		&types.ContentBlockMemberText{Value: "Checking the weather and your reservations"},
+		toolUse("1", "get_weather"),
+		toolUse("2", "golf_get_reservations"),
+		toolUse("3", "golf_book_tee_time"),
+		toolUse("4", "golf_search_tee_times"),
+	})
+	if err != nil {
+		t.Fatalf("processToolCalls() error = %v", err)
+	}
+	elapsed := time.Since(begin)
+
+	// Three independent calls overlap, then the booking runs on its own
+	if elapsed >= 4*delay {
+		t.Errorf("processToolCalls() took %s, want independent calls to overlap", elapsed)
+	}
+	if last := (*started)[len(*started)-1]; last != "golf_book_tee_time:1" {
+		t.Errorf("call order = %v, want golf_book_tee_time last and alone", *started)
+	}
+
+	wantOrder := []string{"get_weather", "golf_get_reservations", "golf_book_tee_time", "golf_search_tee_times"}
+	if len(results) != len(wantOrder) {
+		t.Fatalf("processToolCalls() returned %d results, want %d", len(results), len(wantOrder))
+	}
+	for i, want := range wantOrder {
+		result := results[i].(*types.ContentBlockMemberToolResult).Value
+		text := result.Content[0].(*types.ToolResultContentBlockMemberText).Value
+		if aws.ToString(result.ToolUseId) != fmt.Sprint(i+1) || text != want {
+			t.Errorf("result %d = %s %q, want tool use %d %q", i, aws.ToString(result.ToolUseId), text, i+1, want)
+		}
+		if h.runSummary.ToolCalls[i].Name != want || h.runSummary.ToolCalls[i].Duration < delay {
+			t.Errorf("run summary call %d = %+v, want %s taking at least %s", i, h.runSummary.ToolCalls[i], want, delay)
+		}
+	}
+}
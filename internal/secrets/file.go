@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// FileSource reads secrets from a local JSON file mapping each secret name to its value, for
+// running the Lambdas without Secrets Manager:
+//
+//	{"rez-agent/golf/credentials-dev": {"username": "...", "password": "..."}}
+//
+// The file is read on every cache miss, so edits apply without a restart once the cache expires.
+type FileSource struct {
+	path string
+}
+
+// NewFileSource creates a source that reads secrets from the JSON file at path
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+// NewFileManager creates a caching secrets manager that reads from the JSON file at path
+func NewFileManager(path string, logger *slog.Logger) *Manager {
+	return NewManagerWithSource(NewFileSource(path), logger)
+}
+
+// GetSecretString returns the named secret re-encoded as a JSON string
+func (s *FileSource) GetSecretString(_ context.Context, secretName string) (string, error) {
+	body, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	var secrets map[string]json.RawMessage
+	if err := json.Unmarshal(body, &secrets); err != nil {
+		return "", fmt.Errorf("failed to parse secrets file %s: %w", s.path, err)
+	}
+
+	value, ok := secrets[secretName]
+	if !ok {
+		return "", fmt.Errorf("secret not found in %s", s.path)
+	}
+	return string(value), nil
+}
@@ -29,19 +29,49 @@ type cachedSecret struct {
 	ExpiresAt  time.Time
 }
 
-// Manager handles AWS Secrets Manager operations with caching
+// Source fetches a secret's raw JSON string
+type Source interface {
+	GetSecretString(ctx context.Context, secretName string) (string, error)
+}
+
+// awsSource reads secrets from AWS Secrets Manager
+type awsSource struct {
+	client *secretsmanager.Client
+}
+
+// GetSecretString returns the secret's string value
+func (s *awsSource) GetSecretString(ctx context.Context, secretName string) (string, error) {
+	result, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretName),
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.SecretString == nil {
+		return "", fmt.Errorf("secret has no string value")
+	}
+	return *result.SecretString, nil
+}
+
+// Manager reads secrets from AWS Secrets Manager, or another Source, with caching
 type Manager struct {
-	client    *secretsmanager.Client
+	source    Source
 	logger    *slog.Logger
 	cache     map[string]*cachedSecret
 	cacheLock sync.RWMutex
 	cacheTTL  time.Duration
+	onAccess  func(ctx context.Context, secretName string)
 }
 
 // NewManager creates a new secrets manager with caching
 func NewManager(cfg aws.Config, logger *slog.Logger) *Manager {
+	return NewManagerWithSource(&awsSource{client: secretsmanager.NewFromConfig(cfg)}, logger)
+}
+
+// NewManagerWithSource creates a caching secrets manager that reads from source
+func NewManagerWithSource(source Source, logger *slog.Logger) *Manager {
 	return &Manager{
-		client:    secretsmanager.NewFromConfig(cfg),
+		source:    source,
 		logger:    logger,
 		cache:     make(map[string]*cachedSecret),
 		cacheLock: sync.RWMutex{},
@@ -49,7 +79,13 @@ func NewManager(cfg aws.Config, logger *slog.Logger) *Manager {
 	}
 }
 
-// GetSecret retrieves a secret from AWS Secrets Manager with caching
+// SetAccessRecorder calls record each time a secret is read from the source; reads served from
+// the cache are not reported
+func (m *Manager) SetAccessRecorder(record func(ctx context.Context, secretName string)) {
+	m.onAccess = record
+}
+
+// GetSecret retrieves a secret from the source with caching
 func (m *Manager) GetSecret(ctx context.Context, secretName string) (SecretValue, error) {
 	// Check cache first
 	if cached := m.getFromCache(secretName); cached != nil {
@@ -57,14 +93,9 @@ func (m *Manager) GetSecret(ctx context.Context, secretName string) (SecretValue
 		return cached.Value, nil
 	}
 
-	m.logger.Debug("secret cache miss, fetching from AWS", slog.String("secret_name", "[REDACTED]"))
+	m.logger.Debug("secret cache miss, fetching from source", slog.String("secret_name", "[REDACTED]"))
 
-	// Fetch from AWS Secrets Manager
-	input := &secretsmanager.GetSecretValueInput{
-		SecretId: aws.String(secretName),
-	}
-
-	result, err := m.client.GetSecretValue(ctx, input)
+	secretString, err := m.source.GetSecretString(ctx, secretName)
 	if err != nil {
 		m.logger.Error("failed to retrieve secret",
 			slog.String("error", err.Error()),
@@ -73,14 +104,13 @@ func (m *Manager) GetSecret(ctx context.Context, secretName string) (SecretValue
 		)
 		return nil, fmt.Errorf("failed to retrieve secret: %w", err)
 	}
-
-	if result.SecretString == nil {
-		return nil, fmt.Errorf("secret has no string value")
+	if m.onAccess != nil {
+		m.onAccess(ctx, secretName)
 	}
 
 	// Parse secret JSON
 	var secretValue SecretValue
-	if err := json.Unmarshal([]byte(*result.SecretString), &secretValue); err != nil {
+	if err := json.Unmarshal([]byte(secretString), &secretValue); err != nil {
 		return nil, fmt.Errorf("failed to parse secret JSON: %w", err)
 	}
 
@@ -97,7 +127,21 @@ func (m *Manager) GetOAuthCredentials(ctx context.Context, secretName string) (*
 		return nil, err
 	}
 
-	// Extract OAuth fields
+	creds, err := ParseOAuthCredentials(secretValue)
+	if err != nil {
+		return nil, err
+	}
+
+	// SECURITY: Never log credentials
+	m.logger.Debug("OAuth credentials retrieved",
+		slog.String("secret_name", "[REDACTED]"),
+	)
+
+	return creds, nil
+}
+
+// ParseOAuthCredentials extracts OAuth credentials from a secret's fields
+func ParseOAuthCredentials(secretValue SecretValue) (*OAuthCredentials, error) {
 	creds := &OAuthCredentials{
 		Username:     secretValue["username"],
 		Password:     secretValue["password"],
@@ -109,12 +153,6 @@ func (m *Manager) GetOAuthCredentials(ctx context.Context, secretName string) (*
 	if creds.Username == "" || creds.Password == "" {
 		return nil, fmt.Errorf("secret missing required OAuth fields (username, password)")
 	}
-
-	// SECURITY: Never log credentials
-	m.logger.Debug("OAuth credentials retrieved",
-		slog.String("secret_name", "[REDACTED]"),
-	)
-
 	return creds, nil
 }
 
@@ -147,6 +185,14 @@ func (m *Manager) putInCache(secretName string, value SecretValue) {
 	}
 }
 
+// Invalidate drops one cached secret, so the next read fetches its current value from the source
+func (m *Manager) Invalidate(secretName string) {
+	m.cacheLock.Lock()
+	defer m.cacheLock.Unlock()
+
+	delete(m.cache, secretName)
+}
+
 // ClearCache clears all cached secrets
 func (m *Manager) ClearCache() {
 	m.cacheLock.Lock()
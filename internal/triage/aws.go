@@ -0,0 +1,151 @@
+package triage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	// peekVisibilityTimeout hides sampled messages while the sample is taken so the same message
+	// is not returned twice; they are made visible again as soon as sampling finishes
+	peekVisibilityTimeout = 30
+
+	// maxLogEventsPerGroup caps the log lines collected per message and log group
+	maxLogEventsPerGroup = 25
+)
+
+// SQSQueueReader samples dead-letter queues through the SQS API
+type SQSQueueReader struct {
+	client *sqs.Client
+}
+
+// NewSQSQueueReader creates a queue reader
+func NewSQSQueueReader(client *sqs.Client) *SQSQueueReader {
+	return &SQSQueueReader{client: client}
+}
+
+// PeekMessages returns the approximate queue depth and up to max messages. Messages are received
+// and then released rather than deleted, so they stay in the queue for redrive; each sample does
+// increment their receive count.
+func (r *SQSQueueReader) PeekMessages(ctx context.Context, queueURL string, max int) (int, []DLQMessage, error) {
+	attrs, err := r.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNameApproximateNumberOfMessages},
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get queue attributes: %w", err)
+	}
+	depth, _ := strconv.Atoi(attrs.Attributes[string(sqstypes.QueueAttributeNameApproximateNumberOfMessages)])
+	if depth == 0 || max <= 0 {
+		return depth, nil, nil
+	}
+
+	var messages []DLQMessage
+	var received []sqstypes.Message
+	defer func() { r.release(queueURL, received) }()
+
+	for len(messages) < max {
+		out, err := r.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(queueURL),
+			MaxNumberOfMessages: int32(min(max-len(messages), 10)),
+			VisibilityTimeout:   peekVisibilityTimeout,
+			MessageSystemAttributeNames: []sqstypes.MessageSystemAttributeName{
+				sqstypes.MessageSystemAttributeNameSentTimestamp,
+				sqstypes.MessageSystemAttributeNameApproximateReceiveCount,
+			},
+		})
+		if err != nil {
+			return depth, messages, fmt.Errorf("failed to receive messages: %w", err)
+		}
+		if len(out.Messages) == 0 {
+			break
+		}
+
+		received = append(received, out.Messages...)
+		for _, m := range out.Messages {
+			messages = append(messages, toDLQMessage(m))
+		}
+	}
+
+	return depth, messages, nil
+}
+
+// release makes sampled messages visible again. It uses its own context so messages are released
+// even when sampling was cut short by the caller's deadline; any it misses reappear after
+// peekVisibilityTimeout.
+func (r *SQSQueueReader) release(queueURL string, received []sqstypes.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// ChangeMessageVisibilityBatch accepts at most 10 entries per request
+	for start := 0; start < len(received); start += 10 {
+		end := min(start+10, len(received))
+		entries := make([]sqstypes.ChangeMessageVisibilityBatchRequestEntry, 0, end-start)
+		for i, m := range received[start:end] {
+			entries = append(entries, sqstypes.ChangeMessageVisibilityBatchRequestEntry{
+				Id:                aws.String(strconv.Itoa(start + i)),
+				ReceiptHandle:     m.ReceiptHandle,
+				VisibilityTimeout: 0,
+			})
+		}
+		_, _ = r.client.ChangeMessageVisibilityBatch(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+			QueueUrl: aws.String(queueURL),
+			Entries:  entries,
+		})
+	}
+}
+
+// toDLQMessage converts an SQS message, reading its system attributes
+func toDLQMessage(m sqstypes.Message) DLQMessage {
+	msg := DLQMessage{
+		SQSMessageID: aws.ToString(m.MessageId),
+		Body:         aws.ToString(m.Body),
+	}
+	if sent, err := strconv.ParseInt(m.Attributes[string(sqstypes.MessageSystemAttributeNameSentTimestamp)], 10, 64); err == nil {
+		msg.SentAt = time.UnixMilli(sent).UTC()
+	}
+	msg.ReceiveCount, _ = strconv.Atoi(m.Attributes[string(sqstypes.MessageSystemAttributeNameApproximateReceiveCount)])
+	return msg
+}
+
+// CloudWatchLogSearcher searches Lambda log groups with CloudWatch Logs filter patterns
+type CloudWatchLogSearcher struct {
+	client *cloudwatchlogs.Client
+}
+
+// NewCloudWatchLogSearcher creates a log searcher
+func NewCloudWatchLogSearcher(client *cloudwatchlogs.Client) *CloudWatchLogSearcher {
+	return &CloudWatchLogSearcher{client: client}
+}
+
+// SearchLogs returns log lines containing the exact term, up to maxLogEventsPerGroup per group
+func (s *CloudWatchLogSearcher) SearchLogs(ctx context.Context, logGroups []string, term string, start, end time.Time) ([]LogEvent, error) {
+	var events []LogEvent
+	for _, group := range logGroups {
+		out, err := s.client.FilterLogEvents(ctx, &cloudwatchlogs.FilterLogEventsInput{
+			LogGroupName:  aws.String(group),
+			FilterPattern: aws.String(strconv.Quote(term)),
+			StartTime:     aws.Int64(start.UnixMilli()),
+			EndTime:       aws.Int64(end.UnixMilli()),
+			Limit:         aws.Int32(maxLogEventsPerGroup),
+		})
+		if err != nil {
+			return events, fmt.Errorf("failed to search %s: %w", group, err)
+		}
+		for _, e := range out.Events {
+			events = append(events, LogEvent{
+				LogGroup:  group,
+				Timestamp: time.UnixMilli(aws.ToInt64(e.Timestamp)).UTC(),
+				Message:   aws.ToString(e.Message),
+			})
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,228 @@
+// Package triage collects dead-letter queue samples and the logs and records related to them
+// into a single report for incident investigation.
+package triage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+const (
+	// DefaultMaxSamples is how many messages are sampled from each dead-letter queue
+	DefaultMaxSamples = 5
+
+	// DefaultLookback is how far before a message was sent its logs are searched
+	DefaultLookback = 24 * time.Hour
+)
+
+// Queue is a dead-letter queue to sample
+type Queue struct {
+	Name string
+	URL  string
+}
+
+// DLQMessage is a message sampled from a dead-letter queue
+type DLQMessage struct {
+	SQSMessageID string
+	Body         string
+	SentAt       time.Time
+	ReceiveCount int
+}
+
+// LogEvent is a log line that mentions a sampled message
+type LogEvent struct {
+	LogGroup  string
+	Timestamp time.Time
+	Message   string
+}
+
+// QueueReader samples a queue without consuming its messages
+type QueueReader interface {
+	PeekMessages(ctx context.Context, queueURL string, max int) (depth int, messages []DLQMessage, err error)
+}
+
+// LogSearcher finds log lines containing a term
+type LogSearcher interface {
+	SearchLogs(ctx context.Context, logGroups []string, term string, start, end time.Time) ([]LogEvent, error)
+}
+
+// Options controls what a triage run collects
+type Options struct {
+	MaxSamples    int
+	Lookback      time.Duration
+	LogGroups     []string
+	DeployVersion string
+}
+
+// Collector builds triage reports from the dead-letter queues, logs, and DynamoDB records
+type Collector struct {
+	queues   QueueReader
+	logs     LogSearcher
+	messages repository.MessageRepository
+	results  repository.WebActionResultRepository
+	stage    string
+	logger   *slog.Logger
+}
+
+// NewCollector creates a collector for the given stage
+func NewCollector(
+	queues QueueReader,
+	logs LogSearcher,
+	messages repository.MessageRepository,
+	results repository.WebActionResultRepository,
+	stage string,
+	logger *slog.Logger,
+) *Collector {
+	return &Collector{
+		queues:   queues,
+		logs:     logs,
+		messages: messages,
+		results:  results,
+		stage:    stage,
+		logger:   logger,
+	}
+}
+
+// Collect samples each queue and gathers what is known about every sampled message. Collection is
+// best effort: a lookup that fails is noted in the report rather than failing the whole run, since
+// a partial report is still useful during an incident.
+func (c *Collector) Collect(ctx context.Context, queues []Queue, opts Options) *Report {
+	if opts.MaxSamples <= 0 {
+		opts.MaxSamples = DefaultMaxSamples
+	}
+	if opts.Lookback <= 0 {
+		opts.Lookback = DefaultLookback
+	}
+
+	report := &Report{
+		ID:            uuid.New().String(),
+		Stage:         c.stage,
+		DeployVersion: opts.DeployVersion,
+		GeneratedAt:   time.Now().UTC(),
+		Lookback:      opts.Lookback,
+		LogGroups:     opts.LogGroups,
+	}
+
+	for _, queue := range queues {
+		report.Queues = append(report.Queues, c.collectQueue(ctx, queue, opts, report.GeneratedAt))
+	}
+	return report
+}
+
+// collectQueue samples one queue
+func (c *Collector) collectQueue(ctx context.Context, queue Queue, opts Options, now time.Time) QueueReport {
+	qr := QueueReport{Name: queue.Name, URL: queue.URL}
+
+	depth, messages, err := c.queues.PeekMessages(ctx, queue.URL, opts.MaxSamples)
+	if err != nil {
+		c.logger.WarnContext(ctx, "failed to sample dead-letter queue",
+			slog.String("queue", queue.Name),
+			slog.String("error", err.Error()),
+		)
+		qr.Error = err.Error()
+		return qr
+	}
+	qr.Depth = depth
+
+	for _, message := range messages {
+		qr.Samples = append(qr.Samples, c.collectSample(ctx, message, opts, now))
+	}
+	return qr
+}
+
+// collectSample looks up the stored record, results, and logs for a sampled message
+func (c *Collector) collectSample(ctx context.Context, dlqMessage DLQMessage, opts Options, now time.Time) Sample {
+	sample := Sample{DLQMessage: dlqMessage}
+
+	// Queue bodies are raw rez_agent messages; anything else is reported as-is
+	var body models.Message
+	if err := json.Unmarshal([]byte(dlqMessage.Body), &body); err != nil || body.ID == "" {
+		sample.Problems = append(sample.Problems, "body is not a rez_agent message")
+		return sample
+	}
+	sample.MessageID = body.ID
+	sample.MessageType = body.MessageType
+
+	record, err := c.messages.GetMessage(ctx, body.ID)
+	if err != nil {
+		sample.Problems = append(sample.Problems, fmt.Sprintf("message record lookup failed: %v", err))
+	} else {
+		sample.Record = record
+	}
+
+	if body.MessageType == models.MessageTypeWebAction && c.results != nil {
+		results, err := c.results.ListResultsByMessageID(ctx, body.ID)
+		if err != nil {
+			sample.Problems = append(sample.Problems, fmt.Sprintf("web action result lookup failed: %v", err))
+		} else {
+			sample.Results = results
+		}
+	}
+
+	if c.logs != nil && len(opts.LogGroups) > 0 {
+		start := now.Add(-opts.Lookback)
+		if !dlqMessage.SentAt.IsZero() {
+			start = dlqMessage.SentAt.Add(-opts.Lookback)
+		}
+		events, err := c.logs.SearchLogs(ctx, opts.LogGroups, body.ID, start, now)
+		if err != nil {
+			sample.Problems = append(sample.Problems, fmt.Sprintf("log search failed: %v", err))
+		} else {
+			sample.Logs = events
+		}
+	}
+
+	return sample
+}
+
+// ParseQueues reads the dead-letter queues from a JSON object of name to queue URL
+// (e.g. {"web-actions":"https://sqs..."}), returned in name order
+func ParseQueues(raw string) ([]Queue, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var urls map[string]string
+	if err := json.Unmarshal([]byte(raw), &urls); err != nil {
+		return nil, fmt.Errorf("invalid dead-letter queue list: %w", err)
+	}
+
+	queues := make([]Queue, 0, len(urls))
+	for name, url := range urls {
+		if url == "" {
+			return nil, fmt.Errorf("dead-letter queue %s has no URL", name)
+		}
+		queues = append(queues, Queue{Name: name, URL: url})
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].Name < queues[j].Name })
+	return queues, nil
+}
+
+// SelectQueues returns the named queues, or all of them when no names are given
+func SelectQueues(queues []Queue, names []string) ([]Queue, error) {
+	if len(names) == 0 {
+		return queues, nil
+	}
+
+	byName := make(map[string]Queue, len(queues))
+	for _, q := range queues {
+		byName[q.Name] = q
+	}
+	selected := make([]Queue, 0, len(names))
+	for _, name := range names {
+		q, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown dead-letter queue: %s", name)
+		}
+		selected = append(selected, q)
+	}
+	return selected, nil
+}
@@ -0,0 +1,194 @@
+package triage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+)
+
+type fakeQueues struct {
+	depth    map[string]int
+	messages map[string][]DLQMessage
+	err      map[string]error
+}
+
+func (f *fakeQueues) PeekMessages(ctx context.Context, queueURL string, max int) (int, []DLQMessage, error) {
+	if err := f.err[queueURL]; err != nil {
+		return 0, nil, err
+	}
+	messages := f.messages[queueURL]
+	return f.depth[queueURL], messages[:min(max, len(messages))], nil
+}
+
+type fakeLogs struct {
+	terms []string
+}
+
+func (f *fakeLogs) SearchLogs(ctx context.Context, logGroups []string, term string, start, end time.Time) ([]LogEvent, error) {
+	f.terms = append(f.terms, term)
+	return []LogEvent{{LogGroup: logGroups[0], Timestamp: end, Message: "failed to process message " + term}}, nil
+}
+
+type fakeMessages struct {
+	items map[string]*models.Message
+}
+
+func (f *fakeMessages) SaveMessage(ctx context.Context, m *models.Message) error { return nil }
+func (f *fakeMessages) GetMessage(ctx context.Context, id string) (*models.Message, error) {
+	if m, ok := f.items[id]; ok {
+		return m, nil
+	}
+	return nil, errors.New("message not found: " + id)
+}
+func (f *fakeMessages) ListMessages(ctx context.Context, stage *models.Stage, status *models.Status, limit int) ([]*models.Message, error) {
+	return nil, nil
+}
+func (f *fakeMessages) QueryMessages(ctx context.Context, query repository.MessageQuery, page repository.IndexPage) ([]*models.Message, map[string]string, error) {
+	return nil, nil, nil
+}
+func (f *fakeMessages) UpdateStatus(ctx context.Context, id string, status models.Status, errorMessage string) error {
+	return nil
+}
+func (f *fakeMessages) TransitionStatus(ctx context.Context, id string, from, to models.Status) error {
+	return nil
+}
+func (f *fakeMessages) ListMessagesByStatus(ctx context.Context, status models.Status, createdBefore time.Time, limit int) ([]*models.Message, error) {
+	return nil, nil
+}
+func (f *fakeMessages) RequeueMessage(ctx context.Context, id string, from models.Status) error {
+	return nil
+}
+func (f *fakeMessages) FailMessage(ctx context.Context, id string, from models.Status, errorMessage string) error {
+	return nil
+}
+func (f *fakeMessages) ListMessagesByCreator(ctx context.Context, createdBy string) ([]*models.Message, error) {
+	return nil, nil
+}
+func (f *fakeMessages) DeleteMessage(ctx context.Context, id string) error { return nil }
+
+type fakeResults struct{}
+
+func (f *fakeResults) SaveResult(ctx context.Context, r *models.WebActionResult) error { return nil }
+func (f *fakeResults) GetResult(ctx context.Context, id string) (*models.WebActionResult, error) {
+	return nil, nil
+}
+func (f *fakeResults) GetResultByMessageID(ctx context.Context, messageID string) (*models.WebActionResult, error) {
+	return nil, nil
+}
+func (f *fakeResults) ListResultsByMessageID(ctx context.Context, messageID string) ([]*models.WebActionResult, error) {
+	return []*models.WebActionResult{{ID: "res_1", MessageID: messageID, Status: models.StatusFailed, ErrorMessage: "golf API returned 503"}}, nil
+}
+func (f *fakeResults) QueryResults(ctx context.Context, query repository.ResultQuery, page repository.IndexPage) ([]*models.WebActionResult, map[string]string, error) {
+	return nil, nil, nil
+}
+func (f *fakeResults) DeleteResult(ctx context.Context, id string) error { return nil }
+
+func TestCollector_Collect(t *testing.T) {
+	queues := &fakeQueues{
+		depth: map[string]int{"web": 2, "notify": 0},
+		messages: map[string][]DLQMessage{
+			"web": {
+				{SQSMessageID: "sqs-1", Body: `{"id":"msg_1","message_type":"web_action"}`, ReceiveCount: 3},
+				{SQSMessageID: "sqs-2", Body: `not json`, ReceiveCount: 3},
+			},
+		},
+		err: map[string]error{"broken": errors.New("access denied")},
+	}
+	logs := &fakeLogs{}
+	messages := &fakeMessages{items: map[string]*models.Message{
+		"msg_1": {ID: "msg_1", Status: models.StatusFailed, ErrorMessage: "handler failed"},
+	}}
+	collector := NewCollector(queues, logs, messages, &fakeResults{}, "dev", slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	report := collector.Collect(context.Background(), []Queue{
+		{Name: "web-actions", URL: "web"},
+		{Name: "notifications", URL: "notify"},
+		{Name: "broken", URL: "broken"},
+	}, Options{LogGroups: []string{"/aws/lambda/rez-agent-webaction-dev"}, DeployVersion: "abc123"})
+
+	if len(report.Queues) != 3 || report.TotalDepth() != 2 {
+		t.Fatalf("report has %d queues and %d messages, want 3 and 2", len(report.Queues), report.TotalDepth())
+	}
+
+	web := report.Queues[0]
+	if len(web.Samples) != 2 {
+		t.Fatalf("web-actions samples = %d, want 2", len(web.Samples))
+	}
+	sample := web.Samples[0]
+	if sample.MessageID != "msg_1" || sample.Record == nil || len(sample.Results) != 1 || len(sample.Logs) != 1 {
+		t.Errorf("sample = %+v, want the record, result, and logs for msg_1", sample)
+	}
+	if len(logs.terms) != 1 || logs.terms[0] != "msg_1" {
+		t.Errorf("log searches = %v, want only msg_1", logs.terms)
+	}
+	if len(web.Samples[1].Problems) == 0 {
+		t.Error("a body that is not a message should be reported as a problem")
+	}
+	if report.Queues[2].Error == "" {
+		t.Error("a queue that cannot be sampled should record the error")
+	}
+
+	html, err := report.HTML()
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	for _, want := range []string{"abc123", "msg_1", "handler failed", "golf API returned 503", "access denied"} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("report HTML is missing %q", want)
+		}
+	}
+	if summary := report.Summary(); !strings.Contains(summary, "2 dead-letter message(s) across 1 of 3") {
+		t.Errorf("Summary() = %q", summary)
+	}
+}
+
+func TestParseQueues(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"sorted by name", `{"web-actions":"https://sqs/web","agent-responses":"https://sqs/agent"}`, []string{"agent-responses", "web-actions"}, false},
+		{"invalid JSON", `["https://sqs/web"]`, nil, true},
+		{"missing URL", `{"web-actions":""}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queues, err := ParseQueues(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQueues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(queues) != len(tt.want) {
+				t.Fatalf("ParseQueues() = %v, want %v", queues, tt.want)
+			}
+			for i, name := range tt.want {
+				if queues[i].Name != name {
+					t.Errorf("queue %d = %s, want %s", i, queues[i].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectQueues(t *testing.T) {
+	queues := []Queue{{Name: "notifications"}, {Name: "web-actions"}}
+
+	if got, _ := SelectQueues(queues, nil); len(got) != 2 {
+		t.Errorf("SelectQueues() without names = %v, want all queues", got)
+	}
+	if got, err := SelectQueues(queues, []string{"web-actions"}); err != nil || len(got) != 1 || got[0].Name != "web-actions" {
+		t.Errorf("SelectQueues() = %v, %v, want web-actions", got, err)
+	}
+	if _, err := SelectQueues(queues, []string{"missing"}); err == nil {
+		t.Error("SelectQueues() should reject an unknown queue")
+	}
+}
@@ -0,0 +1,183 @@
+package triage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// reportLinkTTL is how long the presigned report link is requested to stay valid. Links signed
+// with the Lambda role's session credentials stop working when that session expires, whichever is sooner.
+const reportLinkTTL = 24 * time.Hour
+
+// Sample is a dead-letter message with the record, results, and logs found for it
+type Sample struct {
+	DLQMessage
+	MessageID   string
+	MessageType models.MessageType
+	Record      *models.Message
+	Results     []*models.WebActionResult
+	Logs        []LogEvent
+	Problems    []string
+}
+
+// QueueReport is the state of one dead-letter queue
+type QueueReport struct {
+	Name    string
+	URL     string
+	Depth   int
+	Samples []Sample
+	Error   string
+}
+
+// Report is a triage report across the dead-letter queues
+type Report struct {
+	ID            string
+	Stage         string
+	DeployVersion string
+	GeneratedAt   time.Time
+	Lookback      time.Duration
+	LogGroups     []string
+	Queues        []QueueReport
+}
+
+// TotalDepth returns the number of messages waiting across all queues
+func (r *Report) TotalDepth() int {
+	total := 0
+	for _, q := range r.Queues {
+		total += q.Depth
+	}
+	return total
+}
+
+// Summary returns a one-line description of the report for notifications
+func (r *Report) Summary() string {
+	queues := 0
+	for _, q := range r.Queues {
+		if q.Depth > 0 {
+			queues++
+		}
+	}
+	version := r.DeployVersion
+	if version == "" {
+		version = "unknown"
+	}
+	return fmt.Sprintf("%d dead-letter message(s) across %d of %d queue(s) in %s (deploy %s)",
+		r.TotalDepth(), queues, len(r.Queues), r.Stage, version)
+}
+
+var reportTemplate = template.Must(template.New("triage_report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>DLQ triage {{.GeneratedAt.Format "2006-01-02 15:04"}}</title>
+<style>
+body { font-family: -apple-system, sans-serif; margin: 1.5em; color: #222; }
+pre { white-space: pre-wrap; background: #f4f4f4; padding: 0.75em; border-radius: 4px; }
+.failed { color: #b00020; }
+td { padding: 0.2em 1em 0.2em 0; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>🩺 DLQ triage report</h1>
+<table>
+<tr><td>Stage</td><td>{{.Stage}}</td></tr>
+<tr><td>Deploy version</td><td>{{if .DeployVersion}}{{.DeployVersion}}{{else}}unknown{{end}}</td></tr>
+<tr><td>Generated</td><td>{{.GeneratedAt.Format "Mon Jan 2, 2006 3:04:05 PM MST"}}</td></tr>
+<tr><td>Log lookback</td><td>{{.Lookback}}</td></tr>
+<tr><td>Log groups</td><td>{{range $i, $g := .LogGroups}}{{if $i}}, {{end}}{{$g}}{{else}}none{{end}}</td></tr>
+<tr><td>Messages waiting</td><td>{{.TotalDepth}}</td></tr>
+</table>
+{{range .Queues}}
+<h2>{{.Name}} ({{.Depth}} waiting)</h2>
+{{if .Error}}<p class="failed">Could not sample the queue: {{.Error}}</p>{{end}}
+{{if not .Samples}}{{if not .Error}}<p>No messages sampled.</p>{{end}}{{end}}
+{{range .Samples}}
+<h3>{{if .MessageID}}{{.MessageID}}{{else}}SQS {{.SQSMessageID}}{{end}}</h3>
+<table>
+<tr><td>Type</td><td>{{.MessageType}}</td></tr>
+<tr><td>Sent</td><td>{{if not .SentAt.IsZero}}{{.SentAt.Format "Mon Jan 2, 2006 3:04:05 PM MST"}}{{end}}</td></tr>
+<tr><td>Receive count</td><td>{{.ReceiveCount}}</td></tr>
+{{with .Record}}<tr><td>Status</td><td>{{.Status}}{{if .ErrorMessage}} <span class="failed">{{.ErrorMessage}}</span>{{end}}</td></tr>
+<tr><td>Retries</td><td>{{.RetryCount}}</td></tr>{{end}}
+</table>
+{{range .Problems}}<p class="failed">{{.}}</p>{{end}}
+<h4>Body</h4>
+<pre>{{.Body}}</pre>
+{{if .Results}}<h4>Web action results ({{len .Results}})</h4>
+<ol>
+{{range .Results}}<li{{if .ErrorMessage}} class="failed"{{end}}>{{.Action}} {{.Status}}{{if .ErrorMessage}}: {{.ErrorMessage}}{{end}}</li>
+{{end}}</ol>{{end}}
+<h4>Logs ({{len .Logs}})</h4>
+{{if .Logs}}<pre>{{range .Logs}}{{.Timestamp.Format "15:04:05.000"}} [{{.LogGroup}}] {{.Message}}
+{{end}}</pre>{{else}}<p>No matching log lines.</p>{{end}}
+{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// HTML renders the report as a standalone HTML page
+func (r *Report) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("failed to render triage report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ReportPublisher stores triage reports in S3 and produces shareable links to them
+type ReportPublisher struct {
+	client  *s3.Client
+	presign *s3.PresignClient
+	bucket  string
+}
+
+// NewReportPublisher creates a publisher that writes reports to the given bucket
+func NewReportPublisher(client *s3.Client, bucket string) *ReportPublisher {
+	return &ReportPublisher{
+		client:  client,
+		presign: s3.NewPresignClient(client),
+		bucket:  bucket,
+	}
+}
+
+// Key returns the object key for a report
+func (p *ReportPublisher) Key(report *Report) string {
+	return fmt.Sprintf("triage/%s/%s/%s.html", report.Stage, report.GeneratedAt.UTC().Format("2006/01/02"), report.ID)
+}
+
+// Publish renders the report, uploads it to S3, and returns a presigned link to it
+func (p *ReportPublisher) Publish(ctx context.Context, report *Report) (string, error) {
+	body, err := report.HTML()
+	if err != nil {
+		return "", err
+	}
+
+	key := p.Key(report)
+	_, err = p.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(p.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("text/html; charset=utf-8"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload triage report: %w", err)
+	}
+
+	req, err := p.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(reportLinkTTL))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign triage report link: %w", err)
+	}
+	return req.URL, nil
+}
@@ -0,0 +1,56 @@
+package webaction
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/messaging"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// FailureReporter publishes a structured failure event to the agent responses topic when a
+// web action fails for good, so the agent can retry differently or tell the golfer what to do
+type FailureReporter struct {
+	publisher messaging.SNSPublisher
+	stage     models.Stage
+	logger    *slog.Logger
+}
+
+// NewFailureReporter creates a reporter that publishes agent_response failure events
+func NewFailureReporter(publisher messaging.SNSPublisher, stage models.Stage, logger *slog.Logger) *FailureReporter {
+	return &FailureReporter{
+		publisher: publisher,
+		stage:     stage,
+		logger:    logger,
+	}
+}
+
+// Report publishes the failure; it is a messaging.PermanentFailureHandler
+func (r *FailureReporter) Report(ctx context.Context, message *models.Message, cause error, attempts int) {
+	failure := models.NewWebActionFailure(message, cause, attempts, time.Now())
+
+	event, err := failure.ToMessage(r.stage)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to build web action failure event",
+			slog.String("message_id", message.ID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	event.CorrelationID = message.CorrelationID
+
+	if err := r.publisher.PublishMessage(ctx, event); err != nil {
+		r.logger.ErrorContext(ctx, "failed to publish web action failure event",
+			slog.String("message_id", message.ID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	r.logger.InfoContext(ctx, "published web action failure event",
+		slog.String("message_id", message.ID),
+		slog.String("failure_event_id", event.ID),
+		slog.Int("attempts", attempts),
+	)
+}
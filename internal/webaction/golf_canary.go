@@ -0,0 +1,141 @@
+package webaction
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// Canary steps, in the order they run
+const (
+	CanaryStepSignIn = "sign_in"
+	CanaryStepSearch = "search"
+	CanaryStepLock   = "lock"
+	CanaryStepPrice  = "price"
+)
+
+// CanaryStep is the outcome of one step of a canary run
+type CanaryStep struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// CanaryResult is the outcome of a canary run against one course. Steps holds the steps that ran;
+// after a failure, or when the search finds nothing to lock, the rest are skipped.
+type CanaryResult struct {
+	CourseID   int
+	SearchDate string
+	TeeSheetID int
+	Steps      []CanaryStep
+	// Skipped explains why the run stopped early without a failure
+	Skipped string
+}
+
+// Failed returns the first failed step, or nil when every step that ran passed
+func (r *CanaryResult) Failed() *CanaryStep {
+	for i := range r.Steps {
+		if r.Steps[i].Err != nil {
+			return &r.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Summary describes the run for logs and notifications
+func (r *CanaryResult) Summary() string {
+	if failed := r.Failed(); failed != nil {
+		return fmt.Sprintf("course %d: %s failed: %v", r.CourseID, failed.Name, failed.Err)
+	}
+	if r.Skipped != "" {
+		return fmt.Sprintf("course %d: passed through %s, %s on %s", r.CourseID, r.Steps[len(r.Steps)-1].Name, r.Skipped, r.SearchDate)
+	}
+	return fmt.Sprintf("course %d: locked and priced tee sheet %d on %s", r.CourseID, r.TeeSheetID, r.SearchDate)
+}
+
+// RunCanary exercises the booking pipeline against course up to, but never including, the
+// reservation: it signs in with the credentials in secretName, searches for tee times on date,
+// locks the last tee time of the day (the one a golfer is least likely to want) and prices it.
+// The lock is released by not reserving it; the provider expires it after a few minutes, as it
+// does when a booking fails its policy check. A step that fails, or a response missing the fields
+// the booking run needs, means the provider's API contract has broken.
+func (h *GolfHandler) RunCanary(ctx context.Context, course *courses.Course, secretName string, date time.Time) *CanaryResult {
+	result := &CanaryResult{CourseID: course.CourseID, SearchDate: date.Format("Mon Jan 2 2006")}
+
+	var accessToken string
+	var claims *models.JWTClaims
+	if !h.canaryStep(result, CanaryStepSignIn, func() (err error) {
+		accessToken, claims, err = h.signIn(ctx, course, secretName)
+		return err
+	}) {
+		return result
+	}
+
+	h.runCanarySteps(ctx, course, accessToken, claims, result)
+	return result
+}
+
+// runCanarySteps runs the steps after sign in
+func (h *GolfHandler) runCanarySteps(ctx context.Context, course *courses.Course, accessToken string, claims *models.JWTClaims, result *CanaryResult) {
+	var slots []models.TeeTimeSlot
+	if !h.canaryStep(result, CanaryStepSearch, func() (err error) {
+		slots, err = h.searchTeeTimes(ctx, course, accessToken, &models.SearchTeeTimesParams{
+			SearchDate:     result.SearchDate,
+			NumberOfPlayer: 1,
+		})
+		return err
+	}) {
+		return
+	}
+	if len(slots) == 0 {
+		result.Skipped = "no tee times to lock"
+		return
+	}
+
+	params := &models.BookTeeTimeParams{TeeSheetID: slots[len(slots)-1].TeeSheetID, NumberOfPlayer: 1}
+	result.TeeSheetID = params.TeeSheetID
+	if !h.canaryStep(result, CanaryStepLock, func() error {
+		lock, err := h.lockTeeTime(ctx, course, params, accessToken, claims)
+		if err != nil {
+			return err
+		}
+		if lock.SessionID == "" {
+			return apperrors.Newf(apperrors.ErrValidation, "lock response has no sessionId")
+		}
+		return nil
+	}) {
+		return
+	}
+
+	h.canaryStep(result, CanaryStepPrice, func() error {
+		pricing, err := h.calculatePricing(ctx, course, params, accessToken, claims)
+		if err != nil {
+			return err
+		}
+		if pricing.TransactionID == "" {
+			return apperrors.Newf(apperrors.ErrValidation, "pricing response has no transactionId")
+		}
+		return nil
+	})
+}
+
+// canaryStep runs and times one step, adds it to result, and reports whether it passed
+func (h *GolfHandler) canaryStep(result *CanaryResult, name string, run func() error) bool {
+	start := h.clock.Now()
+	err := run()
+	result.Steps = append(result.Steps, CanaryStep{Name: name, Duration: h.clock.Now().Sub(start), Err: err})
+	if err != nil {
+		h.logger.Warn("booking canary step failed",
+			slog.Int("course_id", result.CourseID),
+			slog.String("step", name),
+			slog.String("error", err.Error()),
+		)
+		return false
+	}
+	return true
+}
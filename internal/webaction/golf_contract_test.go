@@ -0,0 +1,762 @@
+package webaction
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/policy"
+	"github.com/jrzesz33/rez_agent/internal/repository"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+// Contract tests replay recorded CPS Golf API responses from testdata/golf through GolfHandler,
+// so a change in the provider's response shapes fails here rather than in a live booking. When
+// the provider changes, re-record the affected fixture and update the expectations below.
+
+// CPS Golf API paths, as configured for the courses in courseInfo.yaml
+const (
+	searchPath       = "/onlineres/onlineapi/api/v1/onlinereservation/TeeTimes"
+	lockPath         = "/onlineres/onlineapi/api/v1/onlinereservation/LockTeeTimes"
+	releasePath      = "/onlineres/onlineapi/api/v1/onlinereservation/UnLockTeeTimes"
+	pricingPath      = "/onlineres/onlineapi/api/v1/onlinereservation/TeeTimePricesCalculation"
+	reservePath      = "/onlineres/onlineapi/api/v1/onlinereservation/ReserveTeeTimes"
+	reservationsPath = "/onlineres/onlineapi/api/v1/onlinereservation/UpcomingReservation"
+)
+
+// fixtureResponse is a recorded response served for one API path
+type fixtureResponse struct {
+	status  int
+	fixture string
+}
+
+// recordedRequest is a request the fixture server received
+type recordedRequest struct {
+	path    string
+	query   string
+	headers http.Header
+	body    map[string]interface{}
+}
+
+// golfFixtureServer serves recorded CPS Golf responses and records the requests it receives
+type golfFixtureServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []recordedRequest
+	queued   map[string][]fixtureResponse
+}
+
+func newGolfFixtureServer(t *testing.T, responses map[string]fixtureResponse) *golfFixtureServer {
+	t.Helper()
+	s := &golfFixtureServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded := recordedRequest{path: r.URL.Path, query: r.URL.RawQuery, headers: r.Header.Clone()}
+		if raw, _ := io.ReadAll(r.Body); len(raw) > 0 {
+			if err := json.Unmarshal(raw, &recorded.body); err != nil {
+				t.Errorf("%s request body is not a JSON object: %v", r.URL.Path, err)
+			}
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, recorded)
+		response, ok := responses[r.URL.Path]
+		if queued := s.queued[r.URL.Path]; len(queued) > 0 {
+			response, ok = queued[0], true
+			s.queued[r.URL.Path] = queued[1:]
+		}
+		s.mu.Unlock()
+
+		if !ok {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(response.status)
+		_, _ = w.Write(loadGolfFixture(t, response.fixture))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// queue serves responses, in order, to the next requests to path before its usual response
+func (s *golfFixtureServer) queue(path string, responses ...fixtureResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queued == nil {
+		s.queued = make(map[string][]fixtureResponse)
+	}
+	s.queued[path] = append(s.queued[path], responses...)
+}
+
+// request returns the first recorded request to path
+func (s *golfFixtureServer) request(t *testing.T, path string) recordedRequest {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.requests {
+		if r.path == path {
+			return r
+		}
+	}
+	t.Fatalf("no request was made to %s", path)
+	return recordedRequest{}
+}
+
+// called reports whether any request was made to path
+func (s *golfFixtureServer) called(path string) bool {
+	return s.count(path) > 0
+}
+
+// count returns how many requests were made to path
+func (s *golfFixtureServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.requests {
+		if r.path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func loadGolfFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "golf", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return body
+}
+
+// newContractGolfHandler returns a handler and a course whose API is the fixture server
+func newContractGolfHandler(t *testing.T, server *golfFixtureServer) (*GolfHandler, *courses.Course) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	course, err := courses.GetCourseByID(1)
+	if err != nil {
+		t.Fatalf("GetCourseByID() error = %v", err)
+	}
+	local := *course
+	local.Origin = server.URL
+
+	handler := NewGolfHandler(httpclient.NewClient(logger), nil, nil, logger)
+	handler.SetPricingWaiter(PricingPause{Clock: systemClock{}})
+	return handler, &local
+}
+
+var contractClaims = &models.JWTClaims{GolferID: "30417", Acct: "A-30417", Email: "golfer@example.com"}
+
+func TestGolfContract_SearchTeeTimes(t *testing.T) {
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{
+		searchPath: {http.StatusOK, "search_tee_times.json"},
+	})
+	handler, course := newContractGolfHandler(t, server)
+
+	slots, err := handler.searchTeeTimes(context.Background(), course, "token", &models.SearchTeeTimesParams{
+		SearchDate:     "Sat Jun 1 2030",
+		NumberOfPlayer: 2,
+	})
+	if err != nil {
+		t.Fatalf("searchTeeTimes() error = %v", err)
+	}
+	if len(slots) != 3 {
+		t.Fatalf("len(slots) = %d, want 3", len(slots))
+	}
+
+	first := slots[0]
+	if first.TeeSheetID != 918274 || first.StartTime != "2030-06-01T07:30:00" || first.Holes != 18 || first.MaxPlayer != 4 {
+		t.Errorf("first slot = %+v, want tee sheet 918274 at 07:30 for 18 holes and up to 4 players", first)
+	}
+	if fee, ok := first.GreenFee18(); !ok || fee != 52.0 {
+		t.Errorf("GreenFee18() = %v, %v, want 52, true", fee, ok)
+	}
+	if _, ok := slots[2].GreenFee18(); ok {
+		t.Error("GreenFee18() found a fee on the 9-hole slot")
+	}
+
+	request := server.request(t, searchPath)
+	for _, want := range []string{"searchDate=Sat%20Jun%201%202030", "numberOfPlayer=2", "courseIds=1"} {
+		if !strings.Contains(request.query, want) {
+			t.Errorf("search query %q does not contain %q", request.query, want)
+		}
+	}
+	if got := request.headers.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer token")
+	}
+}
+
+func TestGolfContract_SearchTimeWindow(t *testing.T) {
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{
+		searchPath: {http.StatusOK, "search_tee_times.json"},
+	})
+	handler, course := newContractGolfHandler(t, server)
+
+	out, err := handler.handleSearchTeeTimes(context.Background(), course, &models.WebActionPayload{
+		StartSearchTime: "2030-06-01T07:00:00",
+		EndSearchTime:   "2030-06-01T09:00:00",
+		NumberOfPlayers: 2,
+	}, "token", contractClaims)
+	if err != nil {
+		t.Fatalf("handleSearchTeeTimes() error = %v", err)
+	}
+
+	text := strings.Join(out, "\n")
+	for _, want := range []string{"Tee Sheet ID: 918274", "Tee Sheet ID: 918275", "$52.00 - 18 Holes Weekend", "Found 2 available time(s)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("search results do not contain %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "918301") {
+		t.Errorf("search results include the afternoon slot outside the window:\n%s", text)
+	}
+}
+
+func TestGolfContract_SearchPages(t *testing.T) {
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{
+		searchPath: {http.StatusOK, "search_tee_times.json"},
+	})
+	handler, course := newContractGolfHandler(t, server)
+
+	pages := []struct {
+		offset         int
+		wantSlot       string
+		wantOtherSlot  string
+		wantSummary    string
+		wantNextOffset int
+	}{
+		{0, "Tee Sheet ID: 918274", "918275", "Showing 1-1 of 2 available time(s)", 1},
+		{1, "Tee Sheet ID: 918275", "918274", "Showing 2-2 of 2 available time(s)", 0},
+	}
+	for _, page := range pages {
+		payload := &models.WebActionPayload{
+			StartSearchTime: "2030-06-01T07:00:00",
+			EndSearchTime:   "2030-06-01T09:00:00",
+			NumberOfPlayers: 2,
+			MaxResults:      1,
+			ResultOffset:    page.offset,
+		}
+		out, err := handler.handleSearchTeeTimes(context.Background(), course, payload, "token", contractClaims)
+		if err != nil {
+			t.Fatalf("handleSearchTeeTimes() error = %v", err)
+		}
+
+		text := strings.Join(out, "\n")
+		if !strings.Contains(text, page.wantSlot) || !strings.Contains(text, page.wantSummary) || strings.Contains(text, page.wantOtherSlot) {
+			t.Errorf("page at offset %d = %s, want only slot %s and %q", page.offset, text, page.wantSlot, page.wantSummary)
+		}
+		if payload.NextResultOffset != page.wantNextOffset {
+			t.Errorf("page at offset %d NextResultOffset = %d, want %d", page.offset, payload.NextResultOffset, page.wantNextOffset)
+		}
+	}
+}
+
+func TestGolfContract_SearchNoTeeTimes(t *testing.T) {
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{
+		searchPath: {http.StatusOK, "search_no_teetimes.json"},
+	})
+	handler, course := newContractGolfHandler(t, server)
+
+	out, err := handler.handleSearchTeeTimes(context.Background(), course, &models.WebActionPayload{
+		StartSearchTime: "2030-06-01T07:00:00",
+	}, "token", contractClaims)
+	if err != nil {
+		t.Fatalf("handleSearchTeeTimes() error = %v", err)
+	}
+	if !strings.Contains(strings.Join(out, "\n"), "No available tee times found") {
+		t.Errorf("handleSearchTeeTimes() = %v, want the no tee times message", out)
+	}
+}
+
+func TestGolfContract_SearchUnauthorized(t *testing.T) {
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{
+		searchPath: {http.StatusUnauthorized, "unauthorized.json"},
+	})
+	handler, course := newContractGolfHandler(t, server)
+
+	_, err := handler.searchTeeTimes(context.Background(), course, "expired", &models.SearchTeeTimesParams{
+		SearchDate:     "Sat Jun 1 2030",
+		NumberOfPlayer: 1,
+	})
+	if err == nil || !strings.Contains(err.Error(), "HTTP error 401") {
+		t.Errorf("searchTeeTimes() error = %v, want an HTTP 401 error", err)
+	}
+}
+
+func TestGolfContract_BookTeeTime(t *testing.T) {
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{
+		lockPath:    {http.StatusOK, "lock_tee_time.json"},
+		pricingPath: {http.StatusOK, "price_calculation.json"},
+		reservePath: {http.StatusOK, "reserve_tee_time.json"},
+	})
+	handler, course := newContractGolfHandler(t, server)
+	auditLog := repository.NewMemoryAuditRepository()
+	handler.SetAuditRecorder(audit.NewRecorder(auditLog, "webaction", slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	out, err := handler.handleBookTeeTime(context.Background(), course, &models.WebActionPayload{
+		TeeSheetID:      918274,
+		NumberOfPlayers: 2,
+	}, "token", contractClaims)
+	if err != nil {
+		t.Fatalf("handleBookTeeTime() error = %v", err)
+	}
+
+	entries, _ := auditLog.ListEntries(context.Background(), time.Time{})
+	if len(entries) != 1 || entries[0].Action != models.AuditActionBooking || entries[0].Target != "552190" || entries[0].Details["tee_sheet_id"] != "918274" {
+		t.Errorf("audit entries = %+v, want the booking of reservation 552190", entries)
+	}
+
+	text := strings.Join(out, "\n")
+	for _, want := range []string{"Confirmation: BGC-7Q4K2", "Reservation ID: 552190", "Sat, Jun 1 at 7:30 AM", "Total: $129.32", "Due at Course: $129.32"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("booking confirmation does not contain %q:\n%s", want, text)
+		}
+	}
+
+	lock := server.request(t, lockPath)
+	if ids, _ := lock.body["teeSheetIds"].([]interface{}); len(ids) != 1 || ids[0] != float64(918274) {
+		t.Errorf("lock teeSheetIds = %v, want [918274]", lock.body["teeSheetIds"])
+	}
+	if lock.body["golferId"] != float64(30417) || lock.body["email"] != "golfer@example.com" || lock.body["numberOfPlayer"] != float64(2) {
+		t.Errorf("lock request = %v, want the golfer from the claims and 2 players", lock.body)
+	}
+
+	pricing := server.request(t, pricingPath)
+	if pricing.body["selectedTeeSheetId"] != float64(918274) || pricing.body["numberOfPlayer"] != float64(2) {
+		t.Errorf("pricing request = %v, want tee sheet 918274 for 2 players", pricing.body)
+	}
+
+	// The reservation must reference the session the lock returned and the priced transaction
+	reserve := server.request(t, reservePath)
+	if reserve.body["lockedTeeTimesSessionId"] != "3f6a0c2e-8b1d-4e57-9c3a-6d2f1e0b7a55" {
+		t.Errorf("reserve lockedTeeTimesSessionId = %v, want the locked session", reserve.body["lockedTeeTimesSessionId"])
+	}
+	if reserve.body["transactionId"] != "b71e4d20-5c9a-4f3e-8a6b-0d1c2e3f4a66" {
+		t.Errorf("reserve transactionId = %v, want the pricing transaction", reserve.body["transactionId"])
+	}
+	if got := reserve.body["cancelReservationLink"]; got != server.URL+"/onlineresweb/auth/verify-email?returnUrl=cancel-booking" {
+		t.Errorf("reserve cancelReservationLink = %v, want the course cancel link", got)
+	}
+}
+
+// A dry run only prices the tee time: the fixture server fails the test on any lock or reserve
+func TestGolfContract_BookTeeTimeDryRun(t *testing.T) {
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{
+		pricingPath: {http.StatusOK, "price_calculation.json"},
+	})
+	handler, course := newContractGolfHandler(t, server)
+	auditLog := repository.NewMemoryAuditRepository()
+	handler.SetAuditRecorder(audit.NewRecorder(auditLog, "webaction", slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	out, err := handler.handleBookTeeTime(context.Background(), course, &models.WebActionPayload{
+		TeeSheetID:      918274,
+		NumberOfPlayers: 2,
+		RequireApproval: true,
+		DryRun:          true,
+	}, "token", contractClaims)
+	if err != nil {
+		t.Fatalf("handleBookTeeTime() error = %v", err)
+	}
+
+	text := strings.Join(out, "\n")
+	for _, want := range []string{"Dry run", "Saturday, June 1 at 7:30 AM", "Tee sheet 918274", "2 player(s)", "$129.32 total", "held for your approval", "Nothing was locked or reserved"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("dry run result does not contain %q:\n%s", want, text)
+		}
+	}
+	if server.called(lockPath) || server.called(reservePath) {
+		t.Error("dry run locked or reserved the tee time")
+	}
+	if entries, _ := auditLog.ListEntries(context.Background(), time.Time{}); len(entries) != 0 {
+		t.Errorf("audit entries = %+v, want none for a dry run", entries)
+	}
+
+	// The policy is still enforced, so a dry run shows whether the real booking would go through
+	_, err = handler.handleBookTeeTime(context.Background(), course, &models.WebActionPayload{
+		TeeSheetID:      918274,
+		NumberOfPlayers: 2,
+		Policy:          &policy.Config{MaxPrice: 40},
+		DryRun:          true,
+	}, "token", contractClaims)
+	if err == nil || !strings.Contains(err.Error(), "40") {
+		t.Errorf("handleBookTeeTime() over the price cap error = %v, want the policy rejection", err)
+	}
+}
+
+// An auto-book falls back to the next ranked tee time when the best one is taken before it is locked
+func TestGolfContract_AutoBookFallback(t *testing.T) {
+	search := func(t *testing.T, server *golfFixtureServer, lockAttempts int) ([]string, error) {
+		handler, course := newContractGolfHandler(t, server)
+		return handler.handleSearchTeeTimes(context.Background(), course, &models.WebActionPayload{
+			StartSearchTime: "2030-06-01T07:00:00",
+			EndSearchTime:   "2030-06-01T18:00:00",
+			NumberOfPlayers: 2,
+			AutoBook:        true,
+			LockAttempts:    lockAttempts,
+		}, "token", contractClaims)
+	}
+	lockedIDs := func(server *golfFixtureServer) []float64 {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		var ids []float64
+		for _, r := range server.requests {
+			if r.path == lockPath {
+				locked, _ := r.body["teeSheetIds"].([]interface{})
+				for _, id := range locked {
+					ids = append(ids, id.(float64))
+				}
+			}
+		}
+		return ids
+	}
+
+	t.Run("books the next ranked slot", func(t *testing.T) {
+		server := newGolfFixtureServer(t, map[string]fixtureResponse{
+			searchPath:  {http.StatusOK, "search_tee_times.json"},
+			lockPath:    {http.StatusOK, "lock_tee_time.json"},
+			pricingPath: {http.StatusOK, "price_calculation.json"},
+			reservePath: {http.StatusOK, "reserve_tee_time.json"},
+		})
+		server.queue(lockPath, fixtureResponse{http.StatusOK, "lock_unavailable.json"})
+
+		out, err := search(t, server, 0)
+		if err != nil {
+			t.Fatalf("handleSearchTeeTimes() error = %v", err)
+		}
+		if got := lockedIDs(server); len(got) != 2 || got[0] != 918274 || got[1] != 918275 {
+			t.Errorf("locked tee sheets = %v, want 918274 then 918275", got)
+		}
+		if len(out) < 2 || !strings.Contains(out[0], "7:30 AM was taken") || !strings.Contains(out[0], "choice 2 of 3") {
+			t.Errorf("result = %q, want a note that the first choice was taken and the second booked", out)
+		}
+		if !strings.Contains(strings.Join(out, "\n"), "Confirmation: BGC-7Q4K2") {
+			t.Errorf("result = %q, want the booking confirmation", out)
+		}
+	})
+
+	t.Run("gives up after the configured attempts", func(t *testing.T) {
+		server := newGolfFixtureServer(t, map[string]fixtureResponse{
+			searchPath: {http.StatusOK, "search_tee_times.json"},
+			lockPath:   {http.StatusOK, "lock_unavailable.json"},
+		})
+
+		_, err := search(t, server, 2)
+		if err == nil || !strings.Contains(err.Error(), "all 2 best-ranked tee times were taken") {
+			t.Errorf("handleSearchTeeTimes() error = %v, want both attempts taken", err)
+		}
+		if got := lockedIDs(server); len(got) != 2 {
+			t.Errorf("locked tee sheets = %v, want 2 attempts", got)
+		}
+	})
+
+	t.Run("other lock failures do not fall back", func(t *testing.T) {
+		server := newGolfFixtureServer(t, map[string]fixtureResponse{
+			searchPath: {http.StatusOK, "search_tee_times.json"},
+			lockPath:   {http.StatusOK, "lock_conflict.json"},
+		})
+
+		if _, err := search(t, server, 0); err == nil || !strings.Contains(err.Error(), "reservation conflict") {
+			t.Errorf("handleSearchTeeTimes() error = %v, want the reservation conflict", err)
+		}
+		if got := lockedIDs(server); len(got) != 1 {
+			t.Errorf("locked tee sheets = %v, want 1 attempt", got)
+		}
+	})
+}
+
+// The booking ledger allows one reservation per golfer, course and date, however the bookings overlap
+func TestGolfContract_BookingLedger(t *testing.T) {
+	newHandler := func(t *testing.T, reserveFixture string) (*GolfHandler, *courses.Course, *golfFixtureServer, *repository.MemoryBookingLedgerRepository) {
+		server := newGolfFixtureServer(t, map[string]fixtureResponse{
+			lockPath:    {http.StatusOK, "lock_tee_time.json"},
+			pricingPath: {http.StatusOK, "price_calculation.json"},
+			reservePath: {http.StatusOK, reserveFixture},
+			releasePath: {http.StatusOK, "release_tee_time.json"},
+		})
+		handler, course := newContractGolfHandler(t, server)
+		ledger := repository.NewMemoryBookingLedgerRepository()
+		handler.SetBookingLedger(ledger)
+		return handler, course, server, ledger
+	}
+	ledgerID := models.BookingLedgerID(contractClaims.GolferID, 1, "2030-06-01")
+
+	t.Run("second booking of the morning is refused", func(t *testing.T) {
+		handler, course, server, ledger := newHandler(t, "reserve_tee_time.json")
+		payload := &models.WebActionPayload{TeeSheetID: 918274, NumberOfPlayers: 2}
+		if _, err := handler.handleBookTeeTime(context.Background(), course, payload, "token", contractClaims); err != nil {
+			t.Fatalf("handleBookTeeTime() error = %v", err)
+		}
+		if entry, _ := ledger.GetEntry(ledgerID); entry == nil || entry.Status != models.BookingLedgerStatusBooked || entry.ReservationID != 552190 {
+			t.Fatalf("ledger entry = %+v, want booked with reservation 552190", entry)
+		}
+
+		if _, err := handler.handleBookTeeTime(context.Background(), course, payload, "token", contractClaims); err == nil || !strings.Contains(err.Error(), "already has a booking") {
+			t.Errorf("handleBookTeeTime() again error = %v, want a booking conflict", err)
+		}
+		if got := server.count(reservePath); got != 1 {
+			t.Errorf("reservations made = %d, want only the first", got)
+		}
+		if got := server.count(releasePath); got != 1 {
+			t.Errorf("locks released = %d, want the refused booking's lock released", got)
+		}
+	})
+
+	t.Run("rejected reservation releases the claim", func(t *testing.T) {
+		handler, course, _, ledger := newHandler(t, "reserve_rejected.json")
+		if _, err := handler.handleBookTeeTime(context.Background(), course, &models.WebActionPayload{TeeSheetID: 918274, NumberOfPlayers: 2}, "token", contractClaims); err == nil {
+			t.Fatal("handleBookTeeTime() error = nil, want the rejected reservation")
+		}
+		if entry, _ := ledger.GetEntry(ledgerID); entry != nil {
+			t.Errorf("ledger entry = %+v, want the claim released", entry)
+		}
+	})
+}
+
+func TestGolfContract_BookingErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		responses   map[string]fixtureResponse
+		policy      *policy.Config
+		wantErr     string
+		wantReserve bool
+		wantRelease bool
+	}{
+		{
+			name:      "existing reservation",
+			responses: map[string]fixtureResponse{lockPath: {http.StatusOK, "lock_conflict.json"}},
+			wantErr:   "reservation conflict",
+		},
+		{
+			name:      "tee time taken",
+			responses: map[string]fixtureResponse{lockPath: {http.StatusOK, "lock_unavailable.json"}},
+			wantErr:   "no longer available",
+		},
+		{
+			name: "price over policy cap",
+			responses: map[string]fixtureResponse{
+				lockPath:    {http.StatusOK, "lock_tee_time.json"},
+				pricingPath: {http.StatusOK, "price_calculation.json"},
+			},
+			policy:      &policy.Config{MaxPrice: 40},
+			wantErr:     "40",
+			wantRelease: true,
+		},
+		{
+			name: "pricing failed",
+			responses: map[string]fixtureResponse{
+				lockPath:    {http.StatusOK, "lock_tee_time.json"},
+				pricingPath: {http.StatusUnauthorized, "unauthorized.json"},
+			},
+			wantErr:     "pricing calculation failed",
+			wantRelease: true,
+		},
+		{
+			name: "reservation rejected",
+			responses: map[string]fixtureResponse{
+				lockPath:    {http.StatusOK, "lock_tee_time.json"},
+				pricingPath: {http.StatusOK, "price_calculation.json"},
+				reservePath: {http.StatusOK, "reserve_rejected.json"},
+			},
+			wantErr:     "reservation failed with result code: 3",
+			wantReserve: true,
+		},
+		{
+			name: "session expired",
+			responses: map[string]fixtureResponse{
+				lockPath: {http.StatusUnauthorized, "unauthorized.json"},
+			},
+			wantErr: "HTTP error 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantRelease {
+				tt.responses[releasePath] = fixtureResponse{http.StatusOK, "release_tee_time.json"}
+			}
+			server := newGolfFixtureServer(t, tt.responses)
+			handler, course := newContractGolfHandler(t, server)
+
+			_, err := handler.handleBookTeeTime(context.Background(), course, &models.WebActionPayload{
+				TeeSheetID:      918274,
+				NumberOfPlayers: 2,
+				Policy:          tt.policy,
+			}, "token", contractClaims)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("handleBookTeeTime() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if got := server.called(reservePath); got != tt.wantReserve {
+				t.Errorf("reserve called = %v, want %v", got, tt.wantReserve)
+			}
+			// A booking abandoned after the lock gives the tee time back rather than holding it
+			if got := server.called(releasePath); got != tt.wantRelease {
+				t.Errorf("release called = %v, want %v", got, tt.wantRelease)
+			}
+		})
+	}
+}
+
+func TestGolfContract_FetchReservations(t *testing.T) {
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{
+		reservationsPath: {http.StatusOK, "reservations.json"},
+	})
+	handler, course := newContractGolfHandler(t, server)
+
+	reservations, err := handler.fetchReservations(context.Background(), course, server.URL+reservationsPath+"?golferId=30417", "token")
+	if err != nil {
+		t.Fatalf("fetchReservations() error = %v", err)
+	}
+	if len(reservations) != 1 {
+		t.Fatalf("len(reservations) = %d, want 1", len(reservations))
+	}
+	got := reservations[0]
+	if got.ReservationID != 552190 || got.DateTime != "2030-06-01T07:30:00" || got.NumberOfPlayers != 2 || got.ConfirmationNum != "BGC-7Q4K2" {
+		t.Errorf("reservation = %+v, want reservation 552190 for 2 players at 07:30", got)
+	}
+}
+
+func TestGolfContract_DetectStandingTeeTimesPages(t *testing.T) {
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{})
+	server.queue(reservationsPath,
+		fixtureResponse{http.StatusOK, "reservations_page_1.json"},
+		fixtureResponse{http.StatusOK, "reservations_page_2.json"},
+	)
+	handler, course := newContractGolfHandler(t, server)
+
+	// The two Saturdays are on different pages, so the weekly repeat is only seen across both
+	messages, err := handler.handleDetectStandingTeeTimes(context.Background(), course, server.URL+reservationsPath+"?golferId=30417&pageSize=14&currentPage=1", "token")
+	if err != nil {
+		t.Fatalf("handleDetectStandingTeeTimes() error = %v", err)
+	}
+	if len(messages) != 1 || !strings.Contains(messages[0], "Every Saturday at 07:30 (2 player(s), 2 weeks)") {
+		t.Errorf("messages = %q, want the Saturday 07:30 standing tee time", messages)
+	}
+	if got := server.count(reservationsPath); got != 2 {
+		t.Errorf("reservation pages read = %d, want 2", got)
+	}
+	server.mu.Lock()
+	last := server.requests[len(server.requests)-1]
+	server.mu.Unlock()
+	if !strings.Contains(last.query, "currentPage=2") {
+		t.Errorf("second page query = %q, want currentPage=2", last.query)
+	}
+}
+
+func TestGolfContract_ReleaseTeeTime(t *testing.T) {
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{
+		releasePath: {http.StatusOK, "release_tee_time.json"},
+	})
+	handler, course := newContractGolfHandler(t, server)
+
+	if err := handler.releaseTeeTime(context.Background(), course, "token", 918274, "3f6a0c2e-8b1d-4e57-9c3a-6d2f1e0b7a55"); err != nil {
+		t.Fatalf("releaseTeeTime() error = %v", err)
+	}
+	request := server.request(t, releasePath)
+	if request.body["sessionId"] != "3f6a0c2e-8b1d-4e57-9c3a-6d2f1e0b7a55" {
+		t.Errorf("release sessionId = %v, want the lock's session", request.body["sessionId"])
+	}
+	if ids, _ := request.body["teeSheetIds"].([]interface{}); len(ids) != 1 || ids[0] != float64(918274) {
+		t.Errorf("release teeSheetIds = %v, want [918274]", request.body["teeSheetIds"])
+	}
+
+	server.queue(releasePath, fixtureResponse{http.StatusUnauthorized, "unauthorized.json"})
+	if err := handler.releaseTeeTime(context.Background(), course, "token", 918274, "3f6a0c2e-8b1d-4e57-9c3a-6d2f1e0b7a55"); err == nil || !strings.Contains(err.Error(), "HTTP error 401") {
+		t.Errorf("releaseTeeTime() error = %v, want the expired session", err)
+	}
+}
+
+func TestGolfContract_Canary(t *testing.T) {
+	tests := []struct {
+		name        string
+		responses   map[string]fixtureResponse
+		wantSteps   []string
+		wantFailed  string
+		wantSkipped bool
+	}{
+		{
+			name: "passes",
+			responses: map[string]fixtureResponse{
+				searchPath:  {http.StatusOK, "search_tee_times.json"},
+				lockPath:    {http.StatusOK, "lock_tee_time.json"},
+				pricingPath: {http.StatusOK, "price_calculation.json"},
+			},
+			wantSteps: []string{CanaryStepSearch, CanaryStepLock, CanaryStepPrice},
+		},
+		{
+			name:        "nothing to lock",
+			responses:   map[string]fixtureResponse{searchPath: {http.StatusOK, "search_no_teetimes.json"}},
+			wantSteps:   []string{CanaryStepSearch},
+			wantSkipped: true,
+		},
+		{
+			name: "lock rejected",
+			responses: map[string]fixtureResponse{
+				searchPath: {http.StatusOK, "search_tee_times.json"},
+				lockPath:   {http.StatusOK, "lock_unavailable.json"},
+			},
+			wantSteps:  []string{CanaryStepSearch, CanaryStepLock},
+			wantFailed: CanaryStepLock,
+		},
+		{
+			name:       "search unauthorized",
+			responses:  map[string]fixtureResponse{searchPath: {http.StatusUnauthorized, "unauthorized.json"}},
+			wantSteps:  []string{CanaryStepSearch},
+			wantFailed: CanaryStepSearch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newGolfFixtureServer(t, tt.responses)
+			handler, course := newContractGolfHandler(t, server)
+
+			result := &CanaryResult{CourseID: course.CourseID, SearchDate: "Sat Jun 1 2030"}
+			handler.runCanarySteps(context.Background(), course, "token", contractClaims, result)
+
+			var steps []string
+			for _, step := range result.Steps {
+				steps = append(steps, step.Name)
+			}
+			if strings.Join(steps, ",") != strings.Join(tt.wantSteps, ",") {
+				t.Errorf("steps = %v, want %v", steps, tt.wantSteps)
+			}
+			failed := ""
+			if step := result.Failed(); step != nil {
+				failed = step.Name
+			}
+			if failed != tt.wantFailed {
+				t.Errorf("Failed() = %q, want %q (%s)", failed, tt.wantFailed, result.Summary())
+			}
+			if (result.Skipped != "") != tt.wantSkipped {
+				t.Errorf("Skipped = %q, want skipped = %v", result.Skipped, tt.wantSkipped)
+			}
+			if server.called(reservePath) {
+				t.Error("the canary reserved a tee time")
+			}
+		})
+	}
+
+	// The canary locks the last tee time of the day, the one a golfer is least likely to want
+	server := newGolfFixtureServer(t, map[string]fixtureResponse{
+		searchPath:  {http.StatusOK, "search_tee_times.json"},
+		lockPath:    {http.StatusOK, "lock_tee_time.json"},
+		pricingPath: {http.StatusOK, "price_calculation.json"},
+	})
+	handler, course := newContractGolfHandler(t, server)
+	handler.runCanarySteps(context.Background(), course, "token", contractClaims, &CanaryResult{SearchDate: "Sat Jun 1 2030"})
+	if ids, _ := server.request(t, lockPath).body["teeSheetIds"].([]interface{}); len(ids) != 1 || ids[0] != float64(918301) {
+		t.Errorf("lock teeSheetIds = %v, want the last tee time [918301]", ids)
+	}
+}
@@ -3,38 +3,244 @@ package webaction
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jrzesz33/rez_agent/internal/httpclient"
+	"github.com/jrzesz33/rez_agent/internal/audit"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/messaging"
 	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/notification"
+	"github.com/jrzesz33/rez_agent/internal/oauth"
+	"github.com/jrzesz33/rez_agent/internal/policy"
+	"github.com/jrzesz33/rez_agent/internal/repository"
 	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/kit/redact"
 	"github.com/jrzesz33/rez_agent/pkg/courses"
 )
 
+// ApprovalNotifier sends booking approval requests with approve/decline buttons
+type ApprovalNotifier interface {
+	SendWithActions(ctx context.Context, title, message string, actions []notification.Action) error
+}
+
+// ProviderTransport sends requests to a golf course's booking API. *httpclient.Client implements
+// it; tests substitute a fake to assert requests without a server.
+type ProviderTransport interface {
+	Do(ctx context.Context, config httpclient.RequestConfig) (*httpclient.Response, error)
+}
+
+// Clock tells the time and waits, so tests can fast-forward the handler's pauses
+type Clock interface {
+	Now() time.Time
+	// Sleep waits for d, returning early with the context's error if it is cancelled
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// systemClock is the real Clock
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// PricingWaiter waits between pricing a tee time and reserving it, until the provider is ready to
+// complete the priced transaction
+type PricingWaiter interface {
+	WaitForPricing(ctx context.Context, pricing *models.PricingCalculationResponse) error
+}
+
+// PricingPause is a PricingWaiter that waits a fixed time on the clock
+type PricingPause struct {
+	Clock    Clock
+	Duration time.Duration
+}
+
+// WaitForPricing waits for the pause's duration
+func (p PricingPause) WaitForPricing(ctx context.Context, pricing *models.PricingCalculationResponse) error {
+	return p.Clock.Sleep(ctx, p.Duration)
+}
+
+// defaultPricingPause gives the provider a moment between pricing and reserving
+const defaultPricingPause = 3 * time.Second
+
 // GolfHandler handles golf reservation actions
 type GolfHandler struct {
-	httpClient     *httpclient.Client
-	oauthClient    *httpclient.OAuthClient
-	secretsManager *secrets.Manager
-	logger         *slog.Logger
+	transport        ProviderTransport
+	clock            Clock
+	pricingWaiter    PricingWaiter
+	oauthClient      *oauth.Client
+	secretsManager   *secrets.Manager
+	logger           *slog.Logger
+	approvals        repository.ApprovalRepository
+	approvalNotifier ApprovalNotifier
+	approvalBaseURL  string
+	groupNotifier    func(topic string) (ApprovalNotifier, error)
+	reservationCache repository.ReservationRepository
+	auditRecorder    *audit.Recorder
+	events           *messaging.EventBridgePublisher
+	bookingLedger    repository.BookingLedgerRepository
 }
 
 // NewGolfHandler creates a new golf handler
-func NewGolfHandler(httpClient *httpclient.Client, oauthClient *httpclient.OAuthClient, secretsManager *secrets.Manager, logger *slog.Logger) *GolfHandler {
+func NewGolfHandler(httpClient *httpclient.Client, oauthClient *oauth.Client, secretsManager *secrets.Manager, logger *slog.Logger) *GolfHandler {
 	return &GolfHandler{
-		httpClient:     httpClient,
+		transport:      httpClient,
+		clock:          systemClock{},
+		pricingWaiter:  PricingPause{Clock: systemClock{}, Duration: defaultPricingPause},
 		oauthClient:    oauthClient,
 		secretsManager: secretsManager,
 		logger:         logger,
 	}
 }
 
+// SetTransport replaces the HTTP client the handler calls the golf provider with
+func (h *GolfHandler) SetTransport(transport ProviderTransport) {
+	h.transport = transport
+}
+
+// SetClock replaces the clock the handler tells the time and waits with; the default pricing
+// pause waits on the new clock
+func (h *GolfHandler) SetClock(clock Clock) {
+	h.clock = clock
+	if pause, ok := h.pricingWaiter.(PricingPause); ok {
+		pause.Clock = clock
+		h.pricingWaiter = pause
+	}
+}
+
+// SetPricingWaiter replaces the fixed pause between pricing and reserving a tee time
+func (h *GolfHandler) SetPricingWaiter(waiter PricingWaiter) {
+	h.pricingWaiter = waiter
+}
+
+// SetApprovalWorkflow enables two-phase bookings: approval requests are stored in repo and sent
+// through notifier with buttons that call the approvals endpoint under apiBaseURL
+func (h *GolfHandler) SetApprovalWorkflow(repo repository.ApprovalRepository, notifier ApprovalNotifier, apiBaseURL string) {
+	h.approvals = repo
+	h.approvalNotifier = notifier
+	h.approvalBaseURL = strings.TrimRight(apiBaseURL, "/")
+}
+
+// SetGroupNotifier enables group bookings: newNotifier returns the notifier for a member's ntfy
+// topic, which receives that member's RSVP request
+func (h *GolfHandler) SetGroupNotifier(newNotifier func(topic string) (ApprovalNotifier, error)) {
+	h.groupNotifier = newNotifier
+}
+
+// SetReservationCache enables the sync_reservations operation, which stores each golfer's
+// upcoming reservations in repo
+func (h *GolfHandler) SetReservationCache(repo repository.ReservationRepository) {
+	h.reservationCache = repo
+}
+
+// SetAuditRecorder records every tee time the handler reserves in the audit log
+func (h *GolfHandler) SetAuditRecorder(recorder *audit.Recorder) {
+	h.auditRecorder = recorder
+}
+
+// SetEventPublisher publishes a BookingCompleted domain event for every tee time the handler reserves
+func (h *GolfHandler) SetEventPublisher(publisher *messaging.EventBridgePublisher) {
+	h.events = publisher
+}
+
+// SetBookingLedger claims every reservation in ledger before making it, so concurrent schedules
+// and retries cannot book a golfer at the same course twice on one date
+func (h *GolfHandler) SetBookingLedger(ledger repository.BookingLedgerRepository) {
+	h.bookingLedger = ledger
+}
+
+// claimBooking records the reservation about to be made in the booking ledger and returns the
+// ledger entry ID, or "" when there is no ledger. It fails when the golfer already has a booking,
+// or one in progress, at the course on the tee time's date.
+func (h *GolfHandler) claimBooking(ctx context.Context, course *courses.Course, claims *models.JWTClaims, teeSheetID int, teeTime string) (string, error) {
+	if h.bookingLedger == nil {
+		return "", nil
+	}
+	start, err := time.Parse("2006-01-02T15:04:05", teeTime)
+	if err != nil {
+		return "", apperrors.Newf(apperrors.ErrValidation, "cannot tell the date of tee time %q: %w", teeTime, err)
+	}
+
+	entry := models.NewBookingLedgerClaim(claims.GolferID, course.CourseID, teeSheetID, start)
+	if err := h.bookingLedger.ClaimBooking(ctx, entry); err != nil {
+		h.logger.Warn("booking ledger claim failed",
+			slog.String("ledger_id", entry.ID),
+			slog.Int("tee_sheet_id", teeSheetID),
+			slog.String("error", err.Error()))
+		return "", err
+	}
+	return entry.ID, nil
+}
+
+// settleBooking confirms a ledger claim with its reservation, or releases it when the provider
+// rejected the reservation. A reservation that failed any other way, such as a timeout, may still
+// have gone through, so its claim is kept until it expires.
+func (h *GolfHandler) settleBooking(ctx context.Context, ledgerID string, reservation *models.ReservationResponse, reserveErr error) {
+	if ledgerID == "" {
+		return
+	}
+
+	var err error
+	switch {
+	case reserveErr == nil:
+		err = h.bookingLedger.ConfirmBooking(ctx, ledgerID, reservation.ReservationID)
+	case reservationRejected(reserveErr):
+		err = h.bookingLedger.ReleaseBooking(ctx, ledgerID)
+	default:
+		h.logger.Warn("keeping booking ledger claim after an uncertain reservation failure", slog.String("ledger_id", ledgerID))
+	}
+	if err != nil {
+		h.logger.Warn("failed to settle booking ledger claim", slog.String("ledger_id", ledgerID), slog.String("error", err.Error()))
+	}
+}
+
+// recordBooking adds a reservation to the audit log, naming the golfer by their credentials secret
+func (h *GolfHandler) recordBooking(ctx context.Context, course *courses.Course, secretName string, teeSheetID, players int, reservation *models.ReservationResponse, approvalID string) {
+	details := map[string]string{
+		"course_id":      strconv.Itoa(course.CourseID),
+		"course_name":    course.Name,
+		"secret_name":    secretName,
+		"tee_sheet_id":   strconv.Itoa(teeSheetID),
+		"players":        strconv.Itoa(players),
+		"reservation_id": strconv.Itoa(reservation.ReservationID),
+	}
+	if approvalID != "" {
+		details["approval_id"] = approvalID
+	}
+	h.auditRecorder.Record(ctx, audit.Event{
+		Action:  models.AuditActionBooking,
+		Target:  strconv.Itoa(reservation.ReservationID),
+		Details: details,
+	})
+	h.events.Publish(ctx, messaging.BookingCompleted(messaging.BookingCompletedData{
+		ReservationID: reservation.ReservationID,
+		CourseID:      course.CourseID,
+		CourseName:    course.Name,
+		TeeSheetID:    teeSheetID,
+		Players:       players,
+		ApprovalID:    approvalID,
+	}))
+}
+
 // GetActionType returns the action type this handler supports
 func (h *GolfHandler) GetActionType() models.WebActionType {
 	return models.WebActionTypeGolf
@@ -48,12 +254,12 @@ func (h *GolfHandler) Execute(ctx context.Context, args map[string]interface{},
 
 	// Load course configuration
 	if payload.CourseID == 0 {
-		return nil, fmt.Errorf("courseID is required for golf actions")
+		return nil, apperrors.Newf(apperrors.ErrValidation, "courseID is required for golf actions")
 	}
 
 	course, err := courses.GetCourseByID(payload.CourseID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load course configuration: %w", err)
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("failed to load course configuration: %w", err))
 	}
 	// Route based on operation type
 	operation, _ := args["operation"].(string)
@@ -67,22 +273,60 @@ func (h *GolfHandler) Execute(ctx context.Context, args map[string]interface{},
 		slog.String("url", payload.URL),
 	)
 
+	// Use the requesting user's credentials, or the course's shared ones
+	secretName := golfSecretName(course, payload)
+	accessToken, claims, err := h.signIn(ctx, course, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation {
+	case "search_tee_times":
+		return h.handleSearchTeeTimes(ctx, course, payload, accessToken, claims)
+	case "search_tee_times_range":
+		return h.handleSearchTeeTimesRange(ctx, course, payload, accessToken)
+	case "book_tee_time":
+		if claims == nil {
+			return nil, apperrors.Newf(apperrors.ErrAuth, "JWT verification required for booking operations")
+		}
+		return h.handleBookTeeTime(ctx, course, payload, accessToken, claims)
+	case "complete_booking":
+		return h.handleCompleteBooking(ctx, course, payload, accessToken, claims)
+	case "release_lock":
+		return h.handleReleaseLock(ctx, course, payload, accessToken)
+	case "fetch_reservations":
+		payload.URL = fmt.Sprintf("%s?golferId=%s&pageSize=14&currentPage=1", payload.URL, claims.GolferID)
+		// Default to existing behavior
+		return h.handleFetchReservations(ctx, course, payload.URL, accessToken)
+	case "sync_reservations":
+		payload.URL = fmt.Sprintf("%s?golferId=%s&pageSize=14&currentPage=1", payload.URL, claims.GolferID)
+		return h.handleSyncReservations(ctx, course, payload.URL, accessToken, secretName)
+	case "detect_standing_tee_times":
+		payload.URL = fmt.Sprintf("%s?golferId=%s&pageSize=14&currentPage=1", payload.URL, claims.GolferID)
+		return h.handleDetectStandingTeeTimes(ctx, course, payload.URL, accessToken)
+	case "round_survey":
+		payload.URL = fmt.Sprintf("%s?golferId=%s&pageSize=14&currentPage=1", payload.URL, claims.GolferID)
+		return h.handleRoundSurvey(ctx, course, payload.URL, accessToken)
+	default:
+		return nil, apperrors.Newf(apperrors.ErrValidation, "unknown operation: %s", operation)
+	}
+}
+
+// signIn gets an access token for the course with the credentials in secretName and verifies its
+// claims
+func (h *GolfHandler) signIn(ctx context.Context, course *courses.Course, secretName string) (string, *models.JWTClaims, error) {
 	// Get token URL from course configuration
 	tokenURL, err := course.GetActionURL("token-url")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get token URL from course config: %w", err)
+		return "", nil, fmt.Errorf("failed to get token URL from course config: %w", err)
 	}
 
 	// Get JWKS URL from course configuration
 	jwksURL, err := course.GetActionURL("jwks-url")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get JWKS URL from course config: %w", err)
+		return "", nil, fmt.Errorf("failed to get JWKS URL from course config: %w", err)
 	}
 
-	// Get secret name from course configuration
-	// For now, all courses use the same credentials
-	secretName := course.GetSecretName("prod")
-
 	// Get scope from course configuration
 	scope := course.Scope
 
@@ -99,71 +343,285 @@ func (h *GolfHandler) Execute(ctx context.Context, args map[string]interface{},
 	// Get OAuth token
 	accessToken, err := h.oauthClient.OAuthPasswordGrant(ctx, tokenURL, secretName, scope, oauthHeaders)
 	if err != nil {
-		return nil, fmt.Errorf("OAuth authentication failed: %w", err)
+		return "", nil, fmt.Errorf("OAuth authentication failed: %w", err)
 	}
 
 	// Parse and verify JWT claims WITH signature verification (CRITICAL SECURITY FIX)
-	var claims *models.JWTClaims
-	claims, err = parseAndVerifyJWT(accessToken, jwksURL)
+	claims, err := parseAndVerifyJWT(accessToken, jwksURL)
 	if err != nil {
 		h.logger.Error("JWT verification failed", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("authentication failed: %w", err)
+		return "", nil, apperrors.Wrap(apperrors.ErrAuth, fmt.Errorf("authentication failed: %w", err))
 	}
 	h.logger.Debug("JWT verified successfully",
-		slog.String("golfer_id", claims.GolferID),
-		slog.String("acct", claims.Acct))
+		slog.String("golfer_id", redact.ID(claims.GolferID)),
+		slog.String("acct", redact.ID(claims.Acct)))
 
-	switch operation {
-	case "search_tee_times":
-		return h.handleSearchTeeTimes(ctx, course, payload, accessToken, claims)
-	case "book_tee_time":
-		if claims == nil {
-			return nil, fmt.Errorf("JWT verification required for booking operations")
-		}
-		return h.handleBookTeeTime(ctx, course, payload, accessToken, claims)
-	case "fetch_reservations":
-		payload.URL = fmt.Sprintf("%s?golferId=%s&pageSize=14&currentPage=1", payload.URL, claims.GolferID)
-		// Default to existing behavior
-		return h.handleFetchReservations(ctx, payload.URL, accessToken)
-	default:
-		return nil, fmt.Errorf("unknown operation: %s", operation)
+	return accessToken, claims, nil
+}
+
+// golfSecretName returns the credentials secret named by the payload's auth config, which the web
+// API sets to the requesting user's, falling back to the course's shared credentials
+func golfSecretName(course *courses.Course, payload *models.WebActionPayload) string {
+	if payload.AuthConfig != nil && payload.AuthConfig.SecretName != "" {
+		return payload.AuthConfig.SecretName
 	}
+	return course.GetSecretName("prod")
 }
 
 // handleFetchReservations handles fetching upcoming reservations
-func (h *GolfHandler) handleFetchReservations(ctx context.Context, reservationsURL string, accessToken string) ([]string, error) {
+func (h *GolfHandler) handleFetchReservations(ctx context.Context, course *courses.Course, reservationsURL string, accessToken string) ([]string, error) {
 	h.logger.Debug("fetching golf reservations")
 
 	// Fetch reservations
-	reservations, err := h.fetchReservations(ctx, reservationsURL, accessToken)
+	reservations, err := h.fetchReservations(ctx, course, reservationsURL, accessToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch reservations: %w", err)
 	}
 
 	// Format notification message
-	notification := h.formatReservationNotification(reservations)
+	message, err := h.formatReservationNotification(course, reservations)
+	if err != nil {
+		return nil, err
+	}
 
 	h.logger.Debug("golf action completed successfully",
 		slog.Int("reservations_found", len(reservations)),
 	)
 
-	return notification, nil
+	return message, nil
 }
 
+// handleDetectStandingTeeTimes looks for reservations that repeat weekly at the same time
+func (h *GolfHandler) handleDetectStandingTeeTimes(ctx context.Context, course *courses.Course, reservationsURL string, accessToken string) ([]string, error) {
+	reservations, err := h.fetchAllReservations(ctx, course, reservationsURL, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reservations: %w", err)
+	}
+
+	records := make([]models.ReservationRecord, 0, len(reservations))
+	for _, res := range reservations {
+		teeTime, err := time.Parse("2006-01-02T15:04:05", res.DateTime)
+		if err != nil {
+			if teeTime, err = time.Parse(time.RFC3339, res.DateTime); err != nil {
+				continue
+			}
+		}
+		records = append(records, models.ReservationRecord{
+			TeeTime:         teeTime,
+			CourseName:      course.Name,
+			NumberOfPlayers: res.NumberOfPlayers,
+		})
+	}
+
+	standing := models.DetectStandingTeeTimes(records, 2)
+
+	h.logger.Debug("standing tee time detection completed",
+		slog.Int("reservations", len(records)),
+		slog.Int("standing_found", len(standing)),
+	)
+
+	return h.formatStandingTeeTimes(course, standing), nil
+}
+
+// formatStandingTeeTimes formats detected standing tee times with the schedule to renew them
+// when the course's booking window opens
+func (h *GolfHandler) formatStandingTeeTimes(course *courses.Course, standing []models.StandingTeeTime) []string {
+	var sb strings.Builder
+	sb.WriteString("🔁 Standing Tee Times\n\n")
+
+	if len(standing) == 0 {
+		sb.WriteString("No weekly repeating reservations found.\n")
+		return []string{sb.String()}
+	}
+
+	for _, st := range standing {
+		sb.WriteString(fmt.Sprintf("- Every %s at %s (%d player(s), %d weeks)\n", st.Weekday, st.TimeOfDay, st.NumberOfPlayers, st.Occurrences))
+		if expr, err := st.RenewalScheduleExpression(course.GetBookingWindowDays(), ""); err == nil {
+			sb.WriteString(fmt.Sprintf("	Renewal schedule: %s\n", expr))
+		}
+	}
+
+	return []string{sb.String()}
+}
+
+// handleSyncReservations copies the golfer's reservations at the course into the reservation cache
+func (h *GolfHandler) handleSyncReservations(ctx context.Context, course *courses.Course, reservationsURL, accessToken, secretName string) ([]string, error) {
+	if h.reservationCache == nil {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "reservation cache is not configured")
+	}
+
+	reservations, err := h.fetchReservations(ctx, course, reservationsURL, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reservations: %w", err)
+	}
+
+	loc := course.Location()
+
+	snapshot := &models.ReservationSnapshot{
+		SecretName:   secretName,
+		CourseID:     course.CourseID,
+		CourseName:   course.Name,
+		Reservations: make([]models.CachedReservation, 0, len(reservations)),
+		SyncedAt:     h.clock.Now().UTC(),
+	}
+	for _, res := range reservations {
+		teeTime, err := time.ParseInLocation("2006-01-02T15:04:05", res.DateTime, loc)
+		if err != nil {
+			if teeTime, err = time.Parse(time.RFC3339, res.DateTime); err != nil {
+				h.logger.Warn("skipping reservation with unparseable tee time",
+					slog.Int("reservation_id", res.ReservationID),
+					slog.String("date_time", res.DateTime))
+				continue
+			}
+		}
+		snapshot.Reservations = append(snapshot.Reservations, models.CachedReservation{
+			ReservationID:   res.ReservationID,
+			TeeTime:         teeTime,
+			Players:         res.NumberOfPlayers,
+			ConfirmationKey: res.ConfirmationNum,
+		})
+	}
+
+	if err := h.reservationCache.SaveSnapshot(ctx, snapshot); err != nil {
+		return nil, err
+	}
+
+	h.logger.Info("reservations synced",
+		slog.Int("course_id", course.CourseID),
+		slog.Int("reservations", len(snapshot.Reservations)))
+
+	return []string{fmt.Sprintf("🔄 Synced %d reservation(s) at %s", len(snapshot.Reservations), course.Name)}, nil
+}
+
+// roundSurveyDelay is how long after the tee time a round is assumed to be finished
+const roundSurveyDelay = 4 * time.Hour
+
+// handleRoundSurvey sends a post-round survey for each of today's tee times that has been played
+func (h *GolfHandler) handleRoundSurvey(ctx context.Context, course *courses.Course, reservationsURL string, accessToken string) ([]string, error) {
+	reservations, err := h.fetchReservations(ctx, course, reservationsURL, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reservations: %w", err)
+	}
+
+	loc := course.Location()
+
+	played := playedToday(reservations, h.clock.Now().In(loc), loc)
+
+	h.logger.Debug("round survey check completed",
+		slog.Int("reservations", len(reservations)),
+		slog.Int("played_today", len(played)),
+	)
+
+	if len(played) == 0 {
+		return []string{}, nil
+	}
+	return h.formatRoundSurveys(course, played), nil
+}
+
+// playedToday returns reservations on now's date whose tee time was at least roundSurveyDelay ago
+func playedToday(reservations []GolfReservation, now time.Time, loc *time.Location) []GolfReservation {
+	var played []GolfReservation
+	for _, res := range reservations {
+		teeTime, err := time.ParseInLocation("2006-01-02T15:04:05", res.DateTime, loc)
+		if err != nil {
+			continue
+		}
+		if teeTime.YearDay() != now.YearDay() || teeTime.Year() != now.Year() {
+			continue
+		}
+		if now.Sub(teeTime) < roundSurveyDelay {
+			continue
+		}
+		res.TeeTimeDT = teeTime
+		played = append(played, res)
+	}
+	return played
+}
+
+// formatRoundSurveys formats one survey notification per played round
+func (h *GolfHandler) formatRoundSurveys(course *courses.Course, played []GolfReservation) []string {
+	out := make([]string, 0, len(played))
+	for _, res := range played {
+		var sb strings.Builder
+		sb.WriteString("📝 How was your round?\n\n")
+		sb.WriteString(fmt.Sprintf("%s - %s\n\n", course.Name, res.TeeTimeDT.Format("Mon, Jan 2 at 3:04 PM")))
+		sb.WriteString("1. Did you enjoy it? (1-5)\n")
+		sb.WriteString("2. Pace of play? (1-5)\n")
+		sb.WriteString("3. Was the price fair? (yes/no)\n\n")
+		sb.WriteString(fmt.Sprintf("Reply in chat or POST /api/surveys with course_name %q and tee_time %q.", course.Name, res.TeeTimeDT.Format("2006-01-02T15:04:05")))
+		out = append(out, sb.String())
+	}
+	return out
+}
+
+// maxReservationPages caps how many pages of reservations fetchAllReservations reads
+const maxReservationPages = 10
+
 // fetchReservations fetches golf reservations using the access token
-func (h *GolfHandler) fetchReservations(ctx context.Context, apiURL, accessToken string) ([]GolfReservation, error) {
+func (h *GolfHandler) fetchReservations(ctx context.Context, course *courses.Course, apiURL, accessToken string) ([]GolfReservation, error) {
+	apiResp, err := h.fetchReservationsPage(ctx, course, apiURL, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract reservations from response
+	if len(apiResp.Items) < 1 {
+		h.logger.Warn("no reservations found in response")
+		return []GolfReservation{}, nil
+	}
+
+	return apiResp.Items, nil
+}
+
+// fetchAllReservations fetches every page of the golfer's reservations, starting from the page
+// apiURL asks for, up to maxReservationPages
+func (h *GolfHandler) fetchAllReservations(ctx context.Context, course *courses.Course, apiURL, accessToken string) ([]GolfReservation, error) {
+	pageURL, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reservations URL: %w", err)
+	}
+	query := pageURL.Query()
+	page, err := strconv.Atoi(query.Get("currentPage"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	var reservations []GolfReservation
+	for read := 0; read < maxReservationPages; read++ {
+		query.Set("currentPage", strconv.Itoa(page))
+		pageURL.RawQuery = query.Encode()
+		apiResp, err := h.fetchReservationsPage(ctx, course, pageURL.String(), accessToken)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, apiResp.Items...)
+		if len(apiResp.Items) == 0 || page >= apiResp.TotalPages {
+			return reservations, nil
+		}
+		page++
+	}
+
+	h.logger.Warn("reservations truncated", slog.Int("pages", maxReservationPages))
+	return reservations, nil
+}
+
+// fetchReservationsPage fetches one page of golf reservations using the access token
+func (h *GolfHandler) fetchReservationsPage(ctx context.Context, course *courses.Course, apiURL, accessToken string) (*GolfAPIResponse, error) {
+	ph := course.GetProviderHeaders()
 	headers := map[string]string{
 		"accept":          "application/json, text/plain, */*",
 		"accept-language": "en-US,en;q=0.9",
 		"authorization":   fmt.Sprintf("Bearer %s", accessToken),
 		"cache-control":   "no-cache, no-store, must-revalidate",
-		"client-id":       "onlineresweb",
-		"referer":         "https://birdsfoot.cps.golf/onlineresweb/my-reservation",
-		"user-agent":      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
-		"x-componentid":   "1",
+		"client-id":       course.ClientID,
+		"user-agent":      ph.UserAgent,
+		"x-componentid":   ph.ComponentID,
+	}
+	if referer := course.GetActionReferer("fetch_reservations"); referer != "" {
+		headers["referer"] = referer
 	}
 
-	resp, err := h.httpClient.Do(ctx, httpclient.RequestConfig{
+	resp, err := h.transport.Do(ctx, httpclient.RequestConfig{
 		Method:  "GET",
 		URL:     apiURL,
 		Headers: headers,
@@ -178,14 +636,7 @@ func (h *GolfHandler) fetchReservations(ctx context.Context, apiURL, accessToken
 	if err := json.Unmarshal([]byte(resp.Body), &apiResp); err != nil {
 		return nil, fmt.Errorf("failed to parse reservations response: %w", err)
 	}
-
-	// Extract reservations from response
-	if len(apiResp.Items) < 1 {
-		h.logger.Warn("no reservations found in response")
-		return []GolfReservation{}, nil
-	}
-
-	return apiResp.Items, nil
+	return &apiResp, nil
 }
 
 // GolfAPIResponse represents the golf API response structure
@@ -207,22 +658,15 @@ type GolfReservation struct {
 }
 
 // formatReservationNotification formats reservations into a readable notification
-func (h *GolfHandler) formatReservationNotification(reservations []GolfReservation) []string {
-	var sb strings.Builder
-	var strOut []string
-	if len(reservations) == 0 {
-		sb.WriteString("⛳ Golf Reservations\n\n")
-		sb.WriteString("No tee-times found.\n")
-		strOut = append(strOut, sb.String())
-		return strOut
-	}
+func (h *GolfHandler) formatReservationNotification(course *courses.Course, reservations []GolfReservation) ([]string, error) {
+	loc := course.Location()
 
 	// Parse tee times and sort by date
 	for i := range reservations {
 		teeTime, err := time.Parse(time.RFC3339, reservations[i].DateTime)
 		if err != nil {
-			// Try alternative format
-			teeTime, err = time.Parse("2006-01-02T15:04:05", reservations[i].DateTime)
+			// Try alternative format, which is the course's local time
+			teeTime, err = time.ParseInLocation("2006-01-02T15:04:05", reservations[i].DateTime, loc)
 			if err != nil {
 				h.logger.Warn("failed to parse tee time",
 					slog.String("date_time", reservations[i].DateTime),
@@ -245,40 +689,20 @@ func (h *GolfHandler) formatReservationNotification(reservations []GolfReservati
 		reservations = reservations[:maxReservations]
 	}
 
-	strCourseNm := ""
-	if len(reservations) > 0 {
-		strCourseNm = fmt.Sprintf(" at %s", strCourseNm)
-	}
-
-	sb.WriteString(fmt.Sprintf("⛳ Current Reservations:%s\n\n", strCourseNm))
-
-	for i, res := range reservations {
-		// Format tee time
-		teeTimeStr := res.TeeTimeDT.Format("Mon, Jan 2 at 3:04 PM")
-
-		// Reservation header
-		sb.WriteString(fmt.Sprintf("- %s\n", teeTimeStr))
-
-		// Players
-		sb.WriteString(fmt.Sprintf("	%d player(s)\n", res.NumberOfPlayers))
-
-		// Confirmation number
-		if res.ConfirmationNum != "" {
-			sb.WriteString(fmt.Sprintf("	Confirmation: %s\n", res.ConfirmationNum))
-		}
-
-		// Separator
-		if i < len(reservations)-1 {
-			sb.WriteString("\n")
-		}
+	data := notification.ReservationSummaryData{CourseName: course.Name, Now: h.clock.Now().In(loc)}
+	for _, res := range reservations {
+		data.Reservations = append(data.Reservations, notification.ReservationSummaryItem{
+			TeeTime:      res.TeeTimeDT.In(loc),
+			Players:      res.NumberOfPlayers,
+			Confirmation: res.ConfirmationNum,
+		})
 	}
 
-	// Footer
-	if len(reservations) > 0 {
-		sb.WriteString(fmt.Sprintf("\n\n🏌️ Total: %d upcoming reservation(s)", len(reservations)))
+	message, err := notification.Render(notification.TemplateReservationSummary, data)
+	if err != nil {
+		return nil, err
 	}
-	strOut = append(strOut, sb.String())
-	return strOut
+	return []string{message}, nil
 }
 
 // handleSearchTeeTimes searches for available tee times
@@ -305,20 +729,80 @@ func (h *GolfHandler) handleSearchTeeTimes(ctx context.Context, course *courses.
 	h.logger.Debug("tee times found",
 		slog.Int("count", len(teeTimeSlots)))
 
-	// If auto-book and tee times found, book the first one
+	// Drop slots that break the booking policy so the agent never sees them
+	bookingPolicy := payload.Policy.WithMaxPrice(params.MaxPrice)
+	teeTimeSlots = h.filterByPolicy(course, teeTimeSlots, policy.NewEngine(bookingPolicy), h.precipForecast(ctx, course, bookingPolicy))
+
+	// Rank by preferred time/price/holes so auto-book picks the best match, not the first returned
+	opts, ok, err := params.RankOptions()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, err)
+	}
+	if ok {
+		models.RankTeeTimeSlotsBy(teeTimeSlots, opts)
+	}
+
+	// If auto-book and tee times found, book the best-ranked one
 	if params.AutoBook && len(teeTimeSlots) > 0 && claims != nil {
+		return h.autoBook(ctx, course, payload, teeTimeSlots, accessToken, claims)
+	}
 
-		h.logger.Info("auto-booking tee time for...", slog.Int("teeSheetId", teeTimeSlots[0].TeeSheetID))
+	// Format the requested page of search results as notification
+	total := len(teeTimeSlots)
+	teeTimeSlots = pageTeeTimeSlots(teeTimeSlots, payload)
+	return h.formatSearchResults(teeTimeSlots, params, payload.ResultOffset, total), nil
+}
 
-		// Create a new payload for booking
-		bookPayload := *payload
-		bookPayload.TeeSheetID = teeTimeSlots[0].TeeSheetID
+// defaultLockAttempts is how many ranked tee times an auto-book tries when LockAttempts is not set
+const defaultLockAttempts = 3
 
-		return h.handleBookTeeTime(ctx, course, &bookPayload, accessToken, claims)
+// autoBook books the best-ranked of slots. When a tee time is taken between the search and the
+// lock, it falls back to the next ranked slot, trying up to payload.LockAttempts in all, and the
+// result says which fallback was booked.
+func (h *GolfHandler) autoBook(ctx context.Context, course *courses.Course, payload *models.WebActionPayload, slots []models.TeeTimeSlot, accessToken string, claims *models.JWTClaims) ([]string, error) {
+	attempts := payload.LockAttempts
+	if attempts <= 0 {
+		attempts = defaultLockAttempts
 	}
+	attempts = min(attempts, len(slots))
+
+	var taken []string
+	var lastErr error
+	for i, slot := range slots[:attempts] {
+		h.logger.Info("auto-booking tee time", slog.Int("tee_sheet_id", slot.TeeSheetID), slog.Int("attempt", i+1))
 
-	// Format search results as notification
-	return h.formatSearchResults(teeTimeSlots, params), nil
+		bookPayload := *payload
+		bookPayload.TeeSheetID = slot.TeeSheetID
+		out, err := h.handleBookTeeTime(ctx, course, &bookPayload, accessToken, claims)
+		if err == nil {
+			if len(taken) > 0 {
+				note := fmt.Sprintf("🔁 %s was taken before it could be held, so the next-best tee time was used (choice %d of %d)",
+					strings.Join(taken, ", "), i+1, attempts)
+				out = append([]string{note}, out...)
+			}
+			return out, nil
+		}
+
+		var unavailable *lockUnavailableError
+		if !errors.As(err, &unavailable) {
+			return nil, err
+		}
+		h.logger.Warn("tee time taken before it could be locked",
+			slog.Int("tee_sheet_id", slot.TeeSheetID),
+			slog.Int("attempt", i+1),
+			slog.Int("max_attempts", attempts))
+		taken = append(taken, slotTimeLabel(slot))
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all %d best-ranked tee times were taken before they could be held: %w", attempts, lastErr)
+}
+
+// slotTimeLabel names a slot by its start time, such as "7:30 AM", or its tee sheet ID
+func slotTimeLabel(slot models.TeeTimeSlot) string {
+	if t, err := time.Parse("2006-01-02T15:04:05", slot.StartTime); err == nil {
+		return t.Format("3:04 PM")
+	}
+	return fmt.Sprintf("tee sheet %d", slot.TeeSheetID)
 }
 
 // parseSearchTeeTimesParams parses search parameters from arguments
@@ -338,12 +822,12 @@ func (h *GolfHandler) parseSearchTeeTimesParams(args models.WebActionPayload) (*
 		params.StartSearchTime = &args.StartSearchTime
 		_searchDate, err := time.Parse("2006-01-02T15:04:05", args.StartSearchTime)
 		if err != nil {
-			return nil, fmt.Errorf("invalid startSearchTime format: %w", err)
+			return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid startSearchTime format: %w", err))
 		}
 		params.SearchDate = _searchDate.Format("Mon Jan 2 2006")
 
 	} else {
-		return nil, fmt.Errorf("startSearchTime is required")
+		return nil, apperrors.Newf(apperrors.ErrValidation, "startSearchTime is required")
 	}
 
 	// Extract end time (optional)
@@ -353,9 +837,20 @@ func (h *GolfHandler) parseSearchTeeTimesParams(args models.WebActionPayload) (*
 
 	params.AutoBook = args.AutoBook
 
+	if args.MaxPrice < 0 {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "maxPrice must not be negative")
+	}
+	params.MaxPrice = args.MaxPrice
+
+	params.PreferredTime = args.PreferredTime
+	params.RankBy = args.RankBy
+	if _, _, err := params.RankOptions(); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, err)
+	}
+
 	// Validate number of players
 	if params.NumberOfPlayer < 1 || params.NumberOfPlayer > 4 {
-		return nil, fmt.Errorf("numberOfPlayer must be between 1 and 4")
+		return nil, apperrors.Newf(apperrors.ErrValidation, "numberOfPlayer must be between 1 and 4")
 	}
 
 	return params, nil
@@ -376,19 +871,20 @@ func (h *GolfHandler) searchTeeTimes(ctx context.Context, course *courses.Course
 		params.NumberOfPlayer,
 		course.CourseID)
 
+	ph := course.GetProviderHeaders()
 	headers := map[string]string{
 		"accept":            "application/json, text/plain, */*",
 		"accept-language":   "en-US,en;q=0.9",
 		"authorization":     fmt.Sprintf("Bearer %s", accessToken),
 		"cache-control":     "no-cache, no-store, must-revalidate",
 		"client-id":         course.ClientID,
-		"user-agent":        "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
-		"x-componentid":     "1",
-		"x-timezone-offset": "240",
-		"x-timezoneid":      "America/New_York",
+		"user-agent":        ph.UserAgent,
+		"x-componentid":     ph.ComponentID,
+		"x-timezone-offset": course.TimezoneOffset(h.clock.Now()),
+		"x-timezoneid":      course.GetTimezone(),
 	}
 
-	resp, err := h.httpClient.Do(ctx, httpclient.RequestConfig{
+	resp, err := h.transport.Do(ctx, httpclient.RequestConfig{
 		Method:  "GET",
 		URL:     searchURL,
 		Headers: headers,
@@ -401,9 +897,9 @@ func (h *GolfHandler) searchTeeTimes(ctx context.Context, course *courses.Course
 	// Parse response
 	var teeTimeSlots []models.TeeTimeSlot
 	if err := json.Unmarshal([]byte(resp.Body), &teeTimeSlots); err != nil {
-		h.logger.Warn("problem with response body", slog.String("resp", string(resp.Body)))
+		h.logger.Warn("problem with response body", slog.String("resp", redact.Text(resp.Body)))
 		if !strings.Contains(string(resp.Body), "NO_TEETIMES") {
-			return nil, fmt.Errorf("failed to parse tee times response: %s", string(resp.Body))
+			return nil, fmt.Errorf("failed to parse tee times response: %s", redact.Text(resp.Body))
 		}
 	}
 	//h.logger.Info("get tee times", slog.Int("unmarshalled slots", len(teeTimeSlots)))
@@ -429,14 +925,301 @@ func (h *GolfHandler) searchTeeTimes(ctx context.Context, course *courses.Course
 	return teeTimeSlots, nil
 }
 
-// formatSearchResults formats tee time search results as notification
-func (h *GolfHandler) formatSearchResults(slots []models.TeeTimeSlot, params *models.SearchTeeTimesParams) []string {
+// Limits for multi-day tee time searches
+const (
+	maxRangeSearchDays     = 14 // courses only allow booking 14 days in advance
+	rangeSearchConcurrency = 4
+)
+
+// defaultSearchResults is how many tee times a search returns when MaxResults is not set
+const defaultSearchResults = 5
+
+// pageTeeTimeSlots returns the page of ranked slots the payload asks for with ResultOffset and
+// MaxResults, and sets NextResultOffset when more slots remain after it
+func pageTeeTimeSlots(slots []models.TeeTimeSlot, payload *models.WebActionPayload) []models.TeeTimeSlot {
+	maxResults := payload.MaxResults
+	if maxResults <= 0 {
+		maxResults = defaultSearchResults
+	}
+	start := min(max(payload.ResultOffset, 0), len(slots))
+	end := min(start+maxResults, len(slots))
+
+	payload.NextResultOffset = 0
+	if end < len(slots) {
+		payload.NextResultOffset = end
+	}
+	return slots[start:end]
+}
+
+// handleSearchTeeTimesRange searches every date from startSearchTime through endSearchDate
+// concurrently and returns the best slots across the whole range
+func (h *GolfHandler) handleSearchTeeTimesRange(ctx context.Context, course *courses.Course, payload *models.WebActionPayload, accessToken string) ([]string, error) {
+	params, err := h.parseSearchTeeTimesParams(*payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search parameters: %w", err)
+	}
+
+	daily, err := dailySearchParams(params, payload.EndSearchDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search parameters: %w", err)
+	}
+
+	h.logger.Debug("searching tee time range",
+		slog.String("start_date", daily[0].SearchDate),
+		slog.String("end_date", daily[len(daily)-1].SearchDate),
+		slog.Int("days", len(daily)))
+
+	bookingPolicy := payload.Policy.WithMaxPrice(params.MaxPrice)
+	engine := policy.NewEngine(bookingPolicy)
+	precip := h.precipForecast(ctx, course, bookingPolicy)
+
+	// Fan out one search per day, bounded by rangeSearchConcurrency
+	results := make([][]models.TeeTimeSlot, len(daily))
+	errs := make([]error, len(daily))
+	sem := make(chan struct{}, rangeSearchConcurrency)
+	var wg sync.WaitGroup
+	for i, dayParams := range daily {
+		wg.Add(1)
+		go func(i int, dayParams *models.SearchTeeTimesParams) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			slots, err := h.searchTeeTimes(ctx, course, accessToken, dayParams)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = h.filterByPolicy(course, slots, engine, precip)
+		}(i, dayParams)
+	}
+	wg.Wait()
+
+	var merged []models.TeeTimeSlot
+	failed := 0
+	for i := range daily {
+		if errs[i] != nil {
+			failed++
+			h.logger.Warn("tee time search failed for date",
+				slog.String("search_date", daily[i].SearchDate),
+				slog.String("error", errs[i].Error()))
+			continue
+		}
+		merged = append(merged, results[i]...)
+	}
+	if failed == len(daily) {
+		return nil, fmt.Errorf("failed to search tee times: %w", errs[0])
+	}
+
+	opts, ok, err := params.RankOptions()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, err)
+	}
+	if ok {
+		models.RankTeeTimeSlotsBy(merged, opts)
+	} else {
+		models.RankTeeTimeSlots(merged)
+	}
+	total := len(merged)
+	merged = pageTeeTimeSlots(merged, payload)
+
+	return h.formatRangeSearchResults(merged, daily, failed, payload.ResultOffset, total), nil
+}
+
+// dailySearchParams expands a search into one set of parameters per date through endDate
+// (YYYY-MM-DD, inclusive), keeping the same time-of-day window on every date
+func dailySearchParams(params *models.SearchTeeTimesParams, endDate string) ([]*models.SearchTeeTimesParams, error) {
+	start, err := time.Parse("2006-01-02T15:04:05", *params.StartSearchTime)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid startSearchTime format: %w", err))
+	}
+	if endDate == "" {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "endSearchDate is required")
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid endSearchDate format: %w", err))
+	}
+
+	firstDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(end.Sub(firstDay).Hours()/24) + 1
+	if days < 1 {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "endSearchDate must not be before startSearchTime")
+	}
+	if days > maxRangeSearchDays {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "search range must not exceed %d days", maxRangeSearchDays)
+	}
+
+	var endClock *time.Time
+	if params.EndSearchTime != nil {
+		t, err := time.Parse("2006-01-02T15:04:05", *params.EndSearchTime)
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid endSearchTime format: %w", err))
+		}
+		endClock = &t
+	}
+
+	daily := make([]*models.SearchTeeTimesParams, 0, days)
+	for i := 0; i < days; i++ {
+		day := start.AddDate(0, 0, i)
+		dayParams := *params
+		dayParams.AutoBook = false
+		dayParams.SearchDate = day.Format("Mon Jan 2 2006")
+
+		startTime := day.Format("2006-01-02T15:04:05")
+		dayParams.StartSearchTime = &startTime
+		if endClock != nil {
+			endTime := time.Date(day.Year(), day.Month(), day.Day(), endClock.Hour(), endClock.Minute(), endClock.Second(), 0, time.UTC).
+				Format("2006-01-02T15:04:05")
+			dayParams.EndSearchTime = &endTime
+		}
+		daily = append(daily, &dayParams)
+	}
+	return daily, nil
+}
+
+// formatRangeSearchResults formats the best tee times across a date range as notification; slots
+// is the page starting at offset of total ranked tee times
+func (h *GolfHandler) formatRangeSearchResults(slots []models.TeeTimeSlot, daily []*models.SearchTeeTimesParams, failedDays, offset, total int) []string {
+	var sb strings.Builder
+	rangeText := fmt.Sprintf("%s - %s", daily[0].SearchDate, daily[len(daily)-1].SearchDate)
+
+	if len(slots) == 0 && total > 0 {
+		sb.WriteString("⛳ Tee Time Search Results\n\n")
+		sb.WriteString(fmt.Sprintf("No more tee times for %s: all %d were on earlier pages", rangeText, total))
+	} else if len(slots) == 0 {
+		sb.WriteString("⛳ Tee Time Search Results\n\n")
+		sb.WriteString(fmt.Sprintf("No available tee times found for %s", rangeText))
+		if daily[0].MaxPrice > 0 {
+			sb.WriteString(fmt.Sprintf(" at or under $%.2f", daily[0].MaxPrice))
+		}
+	} else {
+		sb.WriteString("⛳ Best Available Tee Times\n\n")
+		sb.WriteString(fmt.Sprintf("Dates: %s\n", rangeText))
+		sb.WriteString(fmt.Sprintf("Players: %d\n\n", daily[0].NumberOfPlayer))
+
+		for i, slot := range slots {
+			teeTime, err := slot.ParseStartTime()
+			if err != nil {
+				h.logger.Warn("failed to parse start time", slog.String("error", err.Error()))
+				continue
+			}
+
+			sb.WriteString(fmt.Sprintf("%d. %s\n", offset+i+1, teeTime.Format("Mon Jan 2 3:04 PM")))
+			sb.WriteString(fmt.Sprintf("   📍 %s\n", slot.CourseName))
+			sb.WriteString(fmt.Sprintf("   🎟️ Tee Sheet ID: %d\n", slot.TeeSheetID))
+			if fee, ok := slot.GreenFee18(); ok {
+				sb.WriteString(fmt.Sprintf("   💵 $%.2f\n", fee))
+			}
+		}
+		if len(slots) < total {
+			sb.WriteString(fmt.Sprintf("\nShowing %d-%d of %d tee times", offset+1, offset+len(slots), total))
+		}
+	}
+
+	if failedDays > 0 {
+		sb.WriteString(fmt.Sprintf("\n⚠️ %d date(s) could not be searched", failedDays))
+	}
+	return []string{sb.String()}
+}
+
+// precipForecast returns the course's hourly forecast precipitation chances for the weather rule,
+// keyed by UTC hour. It returns nil when cfg has no weather rule or the forecast cannot be read;
+// tee times without a forecast hour, such as those beyond the forecast's week, skip the rule.
+func (h *GolfHandler) precipForecast(ctx context.Context, course *courses.Course, cfg *policy.Config) map[time.Time]int {
+	if cfg == nil || cfg.MaxPrecipChance <= 0 {
+		return nil
+	}
+	forecastURL, err := course.GetActionURL("get-weather")
+	if err != nil {
+		h.logger.Warn("course has no forecast for the weather rule", slog.String("course_name", course.Name))
+		return nil
+	}
+
+	resp, err := h.transport.Do(ctx, httpclient.RequestConfig{
+		Method: "GET",
+		URL:    strings.TrimSuffix(forecastURL, "/") + "/hourly",
+		Headers: map[string]string{
+			"Accept":     "application/json",
+			"User-Agent": "rez-agent golf booking policy (contact@example.com)",
+		},
+		Timeout:  10 * time.Second,
+		CacheTTL: models.WeatherCacheTTL,
+	})
+	if err != nil {
+		h.logger.Warn("failed to fetch forecast for the weather rule", slog.String("error", err.Error()))
+		return nil
+	}
+	var forecast WeatherAPIResponse
+	if err := json.Unmarshal([]byte(resp.Body), &forecast); err != nil {
+		h.logger.Warn("failed to parse forecast for the weather rule", slog.String("error", err.Error()))
+		return nil
+	}
+	return hourlyPrecipChances(forecast)
+}
+
+// policyCandidate builds a policy candidate for a tee time at the course, with the precipitation
+// chance forecast for its hour when precip has one
+func policyCandidate(course *courses.Course, teeTime time.Time, greenFee float64, precip map[time.Time]int) policy.Candidate {
+	candidate := policy.Candidate{
+		TeeTime:  teeTime,
+		HasTime:  true,
+		GreenFee: greenFee,
+	}
+	if chance, ok := precip[teeTime.UTC().Truncate(time.Hour)]; ok {
+		candidate.PrecipChance = &chance
+	}
+	if course.Latitude != 0 || course.Longitude != 0 {
+		candidate.Location = &policy.Coordinates{Latitude: course.Latitude, Longitude: course.Longitude}
+	}
+	return candidate
+}
+
+// filterByPolicy removes tee time slots that violate the booking policy
+func (h *GolfHandler) filterByPolicy(course *courses.Course, slots []models.TeeTimeSlot, engine *policy.Engine, precip map[time.Time]int) []models.TeeTimeSlot {
+	loc := course.Location()
+
+	allowed := make([]models.TeeTimeSlot, 0, len(slots))
+	for _, slot := range slots {
+		teeTime, err := time.ParseInLocation("2006-01-02T15:04:05", slot.StartTime, loc)
+		if err != nil {
+			if teeTime, err = slot.ParseStartTime(); err != nil {
+				continue
+			}
+		}
+		fee, _ := slot.GreenFee18()
+
+		decision := engine.Evaluate(policyCandidate(course, teeTime, fee, precip))
+		if !decision.Allowed {
+			h.logger.Debug("tee time rejected by policy",
+				slog.Int("tee_sheet_id", slot.TeeSheetID),
+				slog.String("reason", decision.Err().Error()))
+			continue
+		}
+		allowed = append(allowed, slot)
+	}
+	return allowed
+}
+
+// formatSearchResults formats tee time search results as notification; slots is the page
+// starting at offset of total ranked tee times
+func (h *GolfHandler) formatSearchResults(slots []models.TeeTimeSlot, params *models.SearchTeeTimesParams, offset, total int) []string {
 	var sb strings.Builder
 	var strOut []string
 
+	if len(slots) == 0 && total > 0 {
+		sb.WriteString("⛳ Tee Time Search Results\n\n")
+		sb.WriteString(fmt.Sprintf("No more tee times for %s: all %d were on earlier pages", params.SearchDate, total))
+		strOut = append(strOut, sb.String())
+		return strOut
+	}
+
 	if len(slots) == 0 {
 		sb.WriteString("⛳ Tee Time Search Results\n\n")
 		sb.WriteString(fmt.Sprintf("No available tee times found for %s", params.SearchDate))
+		if params.MaxPrice > 0 {
+			sb.WriteString(fmt.Sprintf(" at or under $%.2f", params.MaxPrice))
+		}
 		if params.StartSearchTime != nil || params.EndSearchTime != nil {
 			sb.WriteString("\nTry adjusting your time range.")
 		}
@@ -444,12 +1227,6 @@ func (h *GolfHandler) formatSearchResults(slots []models.TeeTimeSlot, params *mo
 		return strOut
 	}
 
-	// Limit to 5 tee times
-	maxResults := 5
-	if len(slots) > maxResults {
-		slots = slots[:maxResults]
-	}
-
 	sb.WriteString("⛳ Available Tee Times\n\n")
 	sb.WriteString(fmt.Sprintf("Date: %s\n", params.SearchDate))
 	sb.WriteString(fmt.Sprintf("Players: %d\n\n", params.NumberOfPlayer))
@@ -463,7 +1240,7 @@ func (h *GolfHandler) formatSearchResults(slots []models.TeeTimeSlot, params *mo
 		}
 		teeTimeStr := teeTime.Format("3:04 PM")
 
-		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, teeTimeStr))
+		sb.WriteString(fmt.Sprintf("%d. %s\n", offset+i+1, teeTimeStr))
 		sb.WriteString(fmt.Sprintf("   📍 %s\n", slot.CourseName))
 		sb.WriteString(fmt.Sprintf("   ⛳ %d holes available\n", slot.Holes))
 		sb.WriteString(fmt.Sprintf("   🎟️ Tee Sheet ID: %d\n", slot.TeeSheetID))
@@ -481,7 +1258,11 @@ func (h *GolfHandler) formatSearchResults(slots []models.TeeTimeSlot, params *mo
 		}
 	}
 
-	sb.WriteString(fmt.Sprintf("\n\nFound %d available time(s)", len(slots)))
+	if len(slots) < total {
+		sb.WriteString(fmt.Sprintf("\n\nShowing %d-%d of %d available time(s)", offset+1, offset+len(slots), total))
+	} else {
+		sb.WriteString(fmt.Sprintf("\n\nFound %d available time(s)", len(slots)))
+	}
 	strOut = append(strOut, sb.String())
 	return strOut
 }
@@ -500,6 +1281,11 @@ func (h *GolfHandler) handleBookTeeTime(ctx context.Context, course *courses.Cou
 		slog.Int("tee_sheet_id", params.TeeSheetID),
 		slog.Int("num_players", params.NumberOfPlayer))
 
+	// A dry run prices the tee time and checks the policy but never holds or reserves it
+	if payload.DryRun {
+		return h.dryRunBooking(ctx, course, payload, params, accessToken, claims)
+	}
+
 	// Step 1: Lock tee time
 	lockResp, err := h.lockTeeTime(ctx, course, params, accessToken, claims)
 	if err != nil {
@@ -507,7 +1293,7 @@ func (h *GolfHandler) handleBookTeeTime(ctx context.Context, course *courses.Cou
 	}
 
 	if lockResp.Error != "" {
-		return nil, fmt.Errorf("lock error: %s", lockResp.Error)
+		return nil, apperrors.Newf(apperrors.ErrValidation, "lock error: %s", lockResp.Error)
 	}
 
 	h.logger.Debug("tee time locked",
@@ -516,7 +1302,7 @@ func (h *GolfHandler) handleBookTeeTime(ctx context.Context, course *courses.Cou
 	// Step 2: Calculate pricing
 	pricingResp, err := h.calculatePricing(ctx, course, params, accessToken, claims)
 	if err != nil {
-		// Lock will auto-expire server-side
+		h.abandonLock(ctx, course, accessToken, params.TeeSheetID, lockResp.SessionID)
 		return nil, fmt.Errorf("pricing calculation failed: %w", err)
 	}
 
@@ -524,11 +1310,39 @@ func (h *GolfHandler) handleBookTeeTime(ctx context.Context, course *courses.Cou
 		slog.String("transaction_id", pricingResp.TransactionID),
 		slog.Float64("total", pricingResp.SummaryDetail.Total))
 
-	// Pause execution for 3 seconds
-	time.Sleep(3 * time.Second)
+	// Enforce the booking policy before committing the reservation
+	precipChance, err := h.checkBookingPolicy(ctx, course, payload.Policy.WithMaxPrice(params.MaxPrice), pricingResp)
+	if err != nil {
+		h.abandonLock(ctx, course, accessToken, params.TeeSheetID, lockResp.SessionID)
+		return nil, err
+	}
+
+	// In approval mode, hold the lock and let the golfer decide before reserving; group bookings
+	// always wait for the members' RSVPs
+	if payload.RequireApproval || len(payload.Group) > 0 {
+		messages, err := h.requestApproval(ctx, course, params, lockResp, pricingResp, golfSecretName(course, payload), payload.Group)
+		if err != nil {
+			h.abandonLock(ctx, course, accessToken, params.TeeSheetID, lockResp.SessionID)
+		}
+		return messages, err
+	}
+
+	// Give the provider a moment between pricing and reserving
+	if err := h.pricingWaiter.WaitForPricing(ctx, pricingResp); err != nil {
+		h.abandonLock(ctx, course, accessToken, params.TeeSheetID, lockResp.SessionID)
+		return nil, fmt.Errorf("waiting to reserve: %w", err)
+	}
+
+	// Claim the golfer's morning at the course so a concurrent run cannot book it too
+	ledgerID, err := h.claimBooking(ctx, course, claims, params.TeeSheetID, pricingResp.StartTime)
+	if err != nil {
+		h.abandonLock(ctx, course, accessToken, params.TeeSheetID, lockResp.SessionID)
+		return nil, err
+	}
 
 	// Step 3: Reserve tee time
 	reserveResp, err := h.reserveTeeTime(ctx, course, accessToken, claims, lockResp.SessionID, pricingResp.TransactionID)
+	h.settleBooking(ctx, ledgerID, reserveResp, err)
 	if err != nil {
 		return nil, fmt.Errorf("reservation failed: %w", err)
 	}
@@ -536,9 +1350,344 @@ func (h *GolfHandler) handleBookTeeTime(ctx context.Context, course *courses.Cou
 	h.logger.Info("tee time reserved",
 		slog.Int("reservation_id", reserveResp.ReservationID),
 		slog.String("confirmation_key", reserveResp.ConfirmationKey))
+	h.recordBooking(ctx, course, golfSecretName(course, payload), params.TeeSheetID, params.NumberOfPlayer, reserveResp, "")
+	addToReceipt(ctx, course, params.TeeSheetID, reserveResp, pricingResp, precipChance)
 
 	// Format success notification
-	return h.formatBookingSuccess(course, reserveResp, pricingResp), nil
+	return h.formatBookingSuccess(course, reserveResp, pricingResp)
+}
+
+// abandonLock releases a tee time locked for a booking that will not go ahead, so the golfer and
+// other players are not kept from it until the lock expires. A failed release is only logged;
+// the lock still expires server-side.
+func (h *GolfHandler) abandonLock(ctx context.Context, course *courses.Course, accessToken string, teeSheetID int, sessionID string) {
+	if err := h.releaseTeeTime(context.WithoutCancel(ctx), course, accessToken, teeSheetID, sessionID); err != nil {
+		h.logger.Warn("failed to release abandoned tee time lock",
+			slog.Int("tee_sheet_id", teeSheetID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// dryRunBooking prices the tee time and checks it against the booking policy without locking or
+// reserving it, and reports what a real booking would have done. Nothing is recorded in the audit
+// log and no approval or RSVP requests are sent.
+func (h *GolfHandler) dryRunBooking(ctx context.Context, course *courses.Course, payload *models.WebActionPayload, params *models.BookTeeTimeParams, accessToken string, claims *models.JWTClaims) ([]string, error) {
+	pricingResp, err := h.calculatePricing(ctx, course, params, accessToken, claims)
+	if err != nil {
+		return nil, fmt.Errorf("pricing calculation failed: %w", err)
+	}
+	if _, err := h.checkBookingPolicy(ctx, course, payload.Policy.WithMaxPrice(params.MaxPrice), pricingResp); err != nil {
+		return nil, err
+	}
+
+	teeTime := pricingResp.StartTime
+	if t, err := time.Parse("2006-01-02T15:04:05", pricingResp.StartTime); err == nil {
+		teeTime = t.Format("Monday, January 2 at 3:04 PM")
+	}
+
+	h.logger.Info("dry run booking priced",
+		slog.Int("tee_sheet_id", params.TeeSheetID),
+		slog.Int("num_players", params.NumberOfPlayer),
+		slog.Float64("total", pricingResp.SummaryDetail.Total))
+
+	var sb strings.Builder
+	sb.WriteString("🧪 Dry run: would have booked\n\n")
+	sb.WriteString(fmt.Sprintf("📍 %s\n", course.Name))
+	sb.WriteString(fmt.Sprintf("📅 %s\n", teeTime))
+	sb.WriteString(fmt.Sprintf("🎫 Tee sheet %d\n", params.TeeSheetID))
+	sb.WriteString(fmt.Sprintf("👥 %d player(s)\n", params.NumberOfPlayer))
+	sb.WriteString(fmt.Sprintf("💵 $%.2f total\n\n", pricingResp.SummaryDetail.Total))
+	switch {
+	case len(payload.Group) > 0:
+		sb.WriteString(fmt.Sprintf("The tee time would have been held while %d group member(s) RSVP.", len(payload.Group)))
+	case payload.RequireApproval:
+		sb.WriteString("The tee time would have been held for your approval.")
+	default:
+		sb.WriteString("The tee time would have been reserved immediately.")
+	}
+	sb.WriteString(" Nothing was locked or reserved.")
+	return []string{sb.String()}, nil
+}
+
+// requestApproval stores the locked, priced tee time and asks the golfer to approve or decline it.
+// For a group booking, each member is also asked to RSVP through their own ntfy topic.
+func (h *GolfHandler) requestApproval(ctx context.Context, course *courses.Course, params *models.BookTeeTimeParams, lock *models.LockTeeTimeResponse, pricing *models.PricingCalculationResponse, secretName string, group []models.GroupMember) ([]string, error) {
+	if h.approvals == nil || h.approvalNotifier == nil || h.approvalBaseURL == "" {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "approval mode is not configured")
+	}
+	if len(group) > 0 && h.groupNotifier == nil {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "group bookings are not configured")
+	}
+
+	approval, err := models.NewBookingApproval(course.CourseID, course.Name, params.TeeSheetID, pricing.StartTime,
+		params.NumberOfPlayer, pricing.SummaryDetail.Total, lock.SessionID, pricing.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create booking approval: %w", err)
+	}
+	// The lock belongs to the golfer who took it, so completing it must use the same credentials
+	approval.SecretName = secretName
+	if err := approval.InviteGroup(group); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, err)
+	}
+	if err := h.approvals.SaveApproval(ctx, approval); err != nil {
+		return nil, fmt.Errorf("failed to save booking approval: %w", err)
+	}
+
+	teeTime := approval.TeeTime
+	if t, err := time.Parse("2006-01-02T15:04:05", approval.TeeTime); err == nil {
+		teeTime = t.Format("Monday, January 2 at 3:04 PM")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📍 %s\n", course.Name))
+	sb.WriteString(fmt.Sprintf("📅 %s\n", teeTime))
+	sb.WriteString(fmt.Sprintf("👥 %d player(s)\n", approval.NumberOfPlayers))
+	sb.WriteString(fmt.Sprintf("💵 $%.2f total\n\n", approval.Total))
+	details := sb.String()
+	if approval.IsGroup() {
+		names := make([]string, 0, len(group))
+		for _, m := range group {
+			names = append(names, m.Name)
+		}
+		sb.WriteString(fmt.Sprintf("Waiting on RSVPs from %s. Approving books for everyone who has not declined.\n\n", strings.Join(names, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf("This tee time is held for %d minutes.", int(models.ApprovalTimeout.Minutes())))
+
+	decisionURL := fmt.Sprintf("%s/api/approvals/%s?decision=", h.approvalBaseURL, approval.ID)
+	actions := []notification.Action{
+		{Label: "Approve", URL: decisionURL + "approve"},
+		{Label: "Decline", URL: decisionURL + "decline"},
+	}
+	if err := h.approvalNotifier.SendWithActions(ctx, "⛳ Approve tee time?", sb.String(), actions); err != nil {
+		return nil, fmt.Errorf("failed to send approval request: %w", err)
+	}
+	invited := h.sendGroupInvites(ctx, approval, group, details)
+
+	h.logger.Info("booking approval requested",
+		slog.String("approval_id", approval.ID),
+		slog.Int("tee_sheet_id", approval.TeeSheetID),
+		slog.Int("group_invites", invited),
+		slog.Time("expires_at", approval.ExpiresAt))
+
+	if approval.IsGroup() {
+		return []string{fmt.Sprintf("⏳ Approval requested for %s at %s; %d of %d group member(s) asked to RSVP by %s",
+			course.Name, teeTime, invited, len(group), approval.ExpiresAt.Format(time.RFC3339))}, nil
+	}
+	return []string{fmt.Sprintf("⏳ Approval requested for %s at %s; waiting until %s",
+		course.Name, teeTime, approval.ExpiresAt.Format(time.RFC3339))}, nil
+}
+
+// sendGroupInvites asks each group member to RSVP through their own ntfy topic and returns how
+// many were sent. A member who cannot be reached stays pending; the organizer can still approve.
+func (h *GolfHandler) sendGroupInvites(ctx context.Context, approval *models.BookingApproval, group []models.GroupMember, details string) int {
+	sent := 0
+	for i, member := range group {
+		notifier, err := h.groupNotifier(member.NtfyTopic)
+		if err == nil {
+			rsvpURL := fmt.Sprintf("%s/api/approvals/%s/rsvp?member=%s&decision=", h.approvalBaseURL, approval.ID, approval.RSVPs[i].Token)
+			actions := []notification.Action{
+				{Label: "I'm in", URL: rsvpURL + "accept"},
+				{Label: "Can't make it", URL: rsvpURL + "decline"},
+			}
+			message := fmt.Sprintf("%sReply within %d minutes.", details, int(models.ApprovalTimeout.Minutes()))
+			err = notifier.SendWithActions(ctx, fmt.Sprintf("⛳ %s, are you in?", member.Name), message, actions)
+		}
+		if err != nil {
+			h.logger.Warn("failed to send group rsvp request",
+				slog.String("approval_id", approval.ID),
+				slog.String("member", member.Name),
+				slog.String("error", err.Error()))
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// handleCompleteBooking reserves a tee time after the golfer approved it
+func (h *GolfHandler) handleCompleteBooking(ctx context.Context, course *courses.Course, payload *models.WebActionPayload, accessToken string, claims *models.JWTClaims) ([]string, error) {
+	if h.approvals == nil {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "approval mode is not configured")
+	}
+	if payload.ApprovalToken == "" {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "approvalToken is required")
+	}
+
+	approval, err := h.approvals.GetApproval(ctx, payload.ApprovalToken)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != models.ApprovalStatusApproved {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "booking approval is %s, not approved", approval.Status)
+	}
+	if approval.IsExpired(h.clock.Now()) {
+		if err := h.approvals.TransitionApproval(ctx, approval.ID, models.ApprovalStatusApproved, models.ApprovalStatusExpired); err != nil {
+			h.logger.Warn("failed to expire booking approval", slog.String("error", err.Error()))
+		}
+		return []string{fmt.Sprintf("⌛ The hold on %s expired before the booking could be completed", approval.CourseName)}, nil
+	}
+
+	if approval.ConfirmedPlayers() == 0 {
+		if err := h.approvals.TransitionApproval(ctx, approval.ID, models.ApprovalStatusApproved, models.ApprovalStatusDeclined); err != nil {
+			h.logger.Warn("failed to decline booking approval", slog.String("error", err.Error()))
+		}
+		if err := h.releaseTeeTime(ctx, course, accessToken, approval.TeeSheetID, approval.SessionID); err != nil {
+			h.logger.Warn("failed to release declined tee time", slog.String("error", err.Error()))
+		}
+		return []string{fmt.Sprintf("👋 Everyone declined the tee time at %s, so it was not booked", approval.CourseName)}, nil
+	}
+
+	// Claim the approval so a retried message cannot reserve twice
+	if err := h.approvals.TransitionApproval(ctx, approval.ID, models.ApprovalStatusApproved, models.ApprovalStatusCompleted); err != nil {
+		return nil, err
+	}
+
+	// A group books for everyone who did not decline, so re-price the held tee time when members dropped out
+	players := approval.ConfirmedPlayers()
+	transactionID, total := approval.TransactionID, approval.Total
+	if players != approval.NumberOfPlayers {
+		repriced, err := h.calculatePricing(ctx, course, &models.BookTeeTimeParams{TeeSheetID: approval.TeeSheetID, NumberOfPlayer: players}, accessToken, claims)
+		if err != nil {
+			if rerr := h.approvals.TransitionApproval(ctx, approval.ID, models.ApprovalStatusCompleted, models.ApprovalStatusApproved); rerr != nil {
+				h.logger.Warn("failed to release booking approval", slog.String("error", rerr.Error()))
+			}
+			return nil, fmt.Errorf("pricing calculation for %d player(s) failed: %w", players, err)
+		}
+		h.logger.Info("group booking re-priced",
+			slog.String("approval_id", approval.ID),
+			slog.Int("held_players", approval.NumberOfPlayers),
+			slog.Int("players", players))
+		transactionID, total = repriced.TransactionID, repriced.SummaryDetail.Total
+	}
+
+	ledgerID, err := h.claimBooking(ctx, course, claims, approval.TeeSheetID, approval.TeeTime)
+	if err != nil {
+		if rerr := h.approvals.TransitionApproval(ctx, approval.ID, models.ApprovalStatusCompleted, models.ApprovalStatusApproved); rerr != nil {
+			h.logger.Warn("failed to release booking approval", slog.String("error", rerr.Error()))
+		}
+		return nil, err
+	}
+
+	reserveResp, err := h.reserveTeeTime(ctx, course, accessToken, claims, approval.SessionID, transactionID)
+	h.settleBooking(ctx, ledgerID, reserveResp, err)
+	if err != nil {
+		// Release the claim so a retry can still complete the booking
+		if rerr := h.approvals.TransitionApproval(ctx, approval.ID, models.ApprovalStatusCompleted, models.ApprovalStatusApproved); rerr != nil {
+			h.logger.Warn("failed to release booking approval", slog.String("error", rerr.Error()))
+		}
+		return nil, fmt.Errorf("reservation failed: %w", err)
+	}
+
+	h.logger.Info("approved tee time reserved",
+		slog.String("approval_id", approval.ID),
+		slog.Int("reservation_id", reserveResp.ReservationID),
+		slog.String("confirmation_key", reserveResp.ConfirmationKey))
+	h.recordBooking(ctx, course, approval.SecretName, approval.TeeSheetID, players, reserveResp, approval.ID)
+
+	pricing := &models.PricingCalculationResponse{
+		TeeSheetID:    approval.TeeSheetID,
+		StartTime:     approval.TeeTime,
+		TransactionID: transactionID,
+	}
+	pricing.SummaryDetail.Total = total
+	return h.formatBookingSuccess(course, reserveResp, pricing)
+}
+
+// handleReleaseLock gives a declined tee time back to the course rather than holding it until
+// the provider's lock expires
+func (h *GolfHandler) handleReleaseLock(ctx context.Context, course *courses.Course, payload *models.WebActionPayload, accessToken string) ([]string, error) {
+	if h.approvals == nil {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "approval mode is not configured")
+	}
+	if payload.ApprovalToken == "" {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "approvalToken is required")
+	}
+
+	approval, err := h.approvals.GetApproval(ctx, payload.ApprovalToken)
+	if err != nil {
+		return nil, err
+	}
+	// Only a hold nobody will complete is released; an approved one is still being reserved
+	if approval.Status != models.ApprovalStatusDeclined && approval.Status != models.ApprovalStatusExpired {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "booking approval is %s, not declined", approval.Status)
+	}
+
+	if err := h.releaseTeeTime(ctx, course, accessToken, approval.TeeSheetID, approval.SessionID); err != nil {
+		return nil, fmt.Errorf("failed to release tee time: %w", err)
+	}
+
+	h.logger.Info("declined tee time released",
+		slog.String("approval_id", approval.ID),
+		slog.Int("tee_sheet_id", approval.TeeSheetID))
+	return []string{fmt.Sprintf("🔓 Released the hold on %s at %s", approval.CourseName, approval.TeeTime)}, nil
+}
+
+// checkBookingPolicy evaluates the priced tee time against the booking policy and returns the
+// forecast precipitation chance it was judged with, when the weather rule needed one
+func (h *GolfHandler) checkBookingPolicy(ctx context.Context, course *courses.Course, cfg *policy.Config, pricing *models.PricingCalculationResponse) (*int, error) {
+	if cfg.IsEmpty() {
+		return nil, nil
+	}
+
+	loc := course.Location()
+	var teeTime time.Time
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", pricing.StartTime, loc); err == nil {
+		teeTime = t
+	} else if t, err := time.Parse(time.RFC3339, pricing.StartTime); err == nil {
+		teeTime = t
+	}
+
+	fee, _ := pricing.GreenFee18()
+	candidate := policyCandidate(course, teeTime, fee, h.precipForecast(ctx, course, cfg))
+	candidate.HasTime = !teeTime.IsZero()
+
+	decision := policy.NewEngine(cfg).Evaluate(candidate)
+	if !decision.Allowed {
+		h.logger.Warn("booking rejected by policy",
+			slog.Int("tee_sheet_id", pricing.TeeSheetID),
+			slog.String("reason", decision.Err().Error()))
+	}
+	return candidate.PrecipChance, apperrors.Wrap(apperrors.ErrValidation, decision.Err())
+}
+
+// BookingReceipt collects the tee times a golf action reserves, for callers that act on them
+// rather than on the notification text
+type BookingReceipt struct {
+	mu       sync.Mutex
+	bookings []models.BookedTeeTime
+}
+
+type bookingReceiptKey struct{}
+
+// WithBookingReceipt returns a context whose reservations are added to receipt
+func WithBookingReceipt(ctx context.Context, receipt *BookingReceipt) context.Context {
+	return context.WithValue(ctx, bookingReceiptKey{}, receipt)
+}
+
+// Bookings returns the tee times reserved so far
+func (r *BookingReceipt) Bookings() []models.BookedTeeTime {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.BookedTeeTime(nil), r.bookings...)
+}
+
+// addToReceipt records a reserved tee time on the context's receipt, if it has one
+func addToReceipt(ctx context.Context, course *courses.Course, teeSheetID int, reservation *models.ReservationResponse, pricing *models.PricingCalculationResponse, precipChance *int) {
+	receipt, ok := ctx.Value(bookingReceiptKey{}).(*BookingReceipt)
+	if !ok {
+		return
+	}
+	fee, _ := pricing.GreenFee18()
+	receipt.mu.Lock()
+	defer receipt.mu.Unlock()
+	receipt.bookings = append(receipt.bookings, models.BookedTeeTime{
+		CourseName:    course.Name,
+		TeeSheetID:    teeSheetID,
+		ReservationID: reservation.ReservationID,
+		TeeTime:       pricing.StartTime,
+		GreenFee:      fee,
+		PrecipChance:  precipChance,
+	})
 }
 
 // parseBookTeeTimeParams parses booking parameters from arguments
@@ -552,7 +1701,7 @@ func (h *GolfHandler) parseBookTeeTimeParams(args models.WebActionPayload) (*mod
 	if args.TeeSheetID > 0 {
 		params.TeeSheetID = args.TeeSheetID
 	} else {
-		return nil, fmt.Errorf("teeSheetId is required")
+		return nil, apperrors.Newf(apperrors.ErrValidation, "teeSheetId is required")
 	}
 
 	// Extract number of players (optional, default 1)
@@ -560,25 +1709,34 @@ func (h *GolfHandler) parseBookTeeTimeParams(args models.WebActionPayload) (*mod
 	if args.NumberOfPlayers >= 1 && args.NumberOfPlayers <= 4 {
 		params.NumberOfPlayer = args.NumberOfPlayers
 	}
+	// A group books for all of its members
+	if len(args.Group) > 0 {
+		params.NumberOfPlayer = len(args.Group)
+	}
+
+	if args.MaxPrice < 0 {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "maxPrice must not be negative")
+	}
+	params.MaxPrice = args.MaxPrice
 
 	/*if startTime, ok := args["startSearchTime"].(string); ok && startTime != "" {
 		_searchDate, err := time.Parse("2006-01-02T15:04:05", startTime)
 		if err != nil {
-			return nil, fmt.Errorf("invalid startSearchTime format: %w", err)
+			return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid startSearchTime format: %w", err))
 		}
 		params.SearchDate = _searchDate.Format("Mon Jan 2 2006")
 
 	} else {
-		return nil, fmt.Errorf("startSearchTime is required")
+		return nil, apperrors.Newf(apperrors.ErrValidation, "startSearchTime is required")
 	}*/
 
 	// Validate
 	if params.TeeSheetID <= 0 {
-		return nil, fmt.Errorf("invalid teeSheetId")
+		return nil, apperrors.Newf(apperrors.ErrValidation, "invalid teeSheetId")
 	}
 
 	if params.NumberOfPlayer < 1 || params.NumberOfPlayer > 4 {
-		return nil, fmt.Errorf("numberOfPlayer must be between 1 and 4")
+		return nil, apperrors.Newf(apperrors.ErrValidation, "numberOfPlayer must be between 1 and 4")
 	}
 
 	return params, nil
@@ -590,7 +1748,7 @@ func (h *GolfHandler) lockTeeTime(ctx context.Context, course *courses.Course, p
 
 	_golferId, err := strconv.Atoi(claims.GolferID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid GolferID in claims: %w", err)
+		return nil, apperrors.Wrap(apperrors.ErrAuth, fmt.Errorf("invalid GolferID in claims: %w", err))
 	}
 
 	lockReq := models.LockTeeTimeRequest{
@@ -611,6 +1769,7 @@ func (h *GolfHandler) lockTeeTime(ctx context.Context, course *courses.Course, p
 		return nil, fmt.Errorf("failed to get lock URL from course config: %w", err)
 	}
 
+	ph := course.GetProviderHeaders()
 	headers := map[string]string{
 		"accept":          "application/json, text/plain, */*",
 		"accept-language": "en-US,en;q=0.9",
@@ -618,11 +1777,11 @@ func (h *GolfHandler) lockTeeTime(ctx context.Context, course *courses.Course, p
 		"cache-control":   "no-cache, no-store, must-revalidate",
 		"client-id":       course.ClientID,
 		"content-type":    "application/json",
-		"x-componentid":   "1",
+		"x-componentid":   ph.ComponentID,
 		"x-websiteid":     course.WebsiteID,
 	}
 
-	resp, err := h.httpClient.Do(ctx, httpclient.RequestConfig{
+	resp, err := h.transport.Do(ctx, httpclient.RequestConfig{
 		Method:  "POST",
 		URL:     lockURL,
 		Headers: headers,
@@ -632,27 +1791,80 @@ func (h *GolfHandler) lockTeeTime(ctx context.Context, course *courses.Course, p
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
-	h.logger.Debug("lock tee time response", slog.String("body", resp.Body))
+	h.logger.Debug("lock tee time response", slog.String("body", redact.Text(resp.Body)))
 	// Parse response
 	var lockResp models.LockTeeTimeResponse
 	if err := json.Unmarshal([]byte(resp.Body), &lockResp); err != nil {
 		return nil, fmt.Errorf("failed to parse lock response: %w", err)
 	}
 	if strings.Contains(lockResp.Warning, "already have a reservation") {
-		return nil, fmt.Errorf("reservation conflict: %s", lockResp.Warning)
+		return nil, apperrors.Newf(apperrors.ErrValidation, "reservation conflict: %s", lockResp.Warning)
 	}
 	if lockResp.Error != "" {
-		return nil, fmt.Errorf("issue with locking a tee time: %s", lockResp.Error)
+		if strings.Contains(strings.ToLower(lockResp.Error), "available") {
+			return nil, apperrors.Wrap(apperrors.ErrValidation, &lockUnavailableError{message: lockResp.Error})
+		}
+		return nil, apperrors.Newf(apperrors.ErrValidation, "issue with locking a tee time: %s", lockResp.Error)
 	}
 
 	return &lockResp, nil
 }
 
+// lockUnavailableError is a tee time someone else took between the search and the lock
+type lockUnavailableError struct {
+	message string
+}
+
+func (e *lockUnavailableError) Error() string {
+	return fmt.Sprintf("issue with locking a tee time: %s", e.message)
+}
+
+// releaseTeeTime unlocks the tee time held by sessionID so other golfers can book it
+func (h *GolfHandler) releaseTeeTime(ctx context.Context, course *courses.Course, accessToken string, teeSheetID int, sessionID string) error {
+	releaseURL, err := course.GetActionURL("release-tee-time")
+	if err != nil {
+		return fmt.Errorf("failed to get release URL from course config: %w", err)
+	}
+
+	ph := course.GetProviderHeaders()
+	headers := map[string]string{
+		"accept":          "application/json, text/plain, */*",
+		"accept-language": "en-US,en;q=0.9",
+		"authorization":   fmt.Sprintf("Bearer %s", accessToken),
+		"cache-control":   "no-cache, no-store, must-revalidate",
+		"client-id":       course.ClientID,
+		"content-type":    "application/json",
+		"x-componentid":   ph.ComponentID,
+		"x-websiteid":     course.WebsiteID,
+	}
+
+	resp, err := h.transport.Do(ctx, httpclient.RequestConfig{
+		Method:  "POST",
+		URL:     releaseURL,
+		Headers: headers,
+		Body:    models.ReleaseTeeTimeRequest{TeeSheetIDs: []int{teeSheetID}, SessionID: sessionID},
+		Timeout: 30 * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	h.logger.Debug("release tee time response", slog.String("body", redact.Text(resp.Body)))
+
+	var releaseResp models.LockTeeTimeResponse
+	if err := json.Unmarshal([]byte(resp.Body), &releaseResp); err != nil {
+		return fmt.Errorf("failed to parse release response: %w", err)
+	}
+	if releaseResp.Error != "" {
+		return fmt.Errorf("issue with releasing a tee time: %s", releaseResp.Error)
+	}
+	return nil
+}
+
 // calculatePricing performs step 2 of booking (pricing)
 func (h *GolfHandler) calculatePricing(ctx context.Context, course *courses.Course, params *models.BookTeeTimeParams, accessToken string, claims *models.JWTClaims) (*models.PricingCalculationResponse, error) {
 	_golferId, err := strconv.Atoi(claims.GolferID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid GolferID in claims: %w", err)
+		return nil, apperrors.Wrap(apperrors.ErrAuth, fmt.Errorf("invalid GolferID in claims: %w", err))
 	}
 	pricingReq := models.PricingCalculationRequest{
 		SelectedTeeSheetID: params.TeeSheetID,
@@ -693,6 +1905,7 @@ func (h *GolfHandler) calculatePricing(ctx context.Context, course *courses.Cour
 		return nil, fmt.Errorf("failed to get pricing URL from course config: %w", err)
 	}
 
+	ph := course.GetProviderHeaders()
 	headers := map[string]string{
 		"accept":            "application/json, text/plain, */*",
 		"accept-language":   "en-US,en;q=0.9",
@@ -700,22 +1913,22 @@ func (h *GolfHandler) calculatePricing(ctx context.Context, course *courses.Cour
 		"cache-control":     "no-cache, no-store, must-revalidate",
 		"client-id":         course.ClientID,
 		"content-type":      "application/json",
-		"x-componentid":     "1",
+		"x-componentid":     ph.ComponentID,
 		"x-websiteid":       course.WebsiteID,
 		"x-ismobile":        "true",
-		"x-moduleid":        "7",
-		"x-productid":       "1",
-		"x-siteid":          "3",
-		"x-terminalid":      "7",
-		"x-timezone-offset": "240",
-		"x-timezoneid":      "America/New_York",
+		"x-moduleid":        ph.ModuleID,
+		"x-productid":       ph.ProductID,
+		"x-siteid":          ph.SiteID,
+		"x-terminalid":      ph.TerminalID,
+		"x-timezone-offset": course.TimezoneOffset(h.clock.Now()),
+		"x-timezoneid":      course.GetTimezone(),
 		"if-modified-since": "0",
 		"origin":            course.Origin,
 		"pragma":            "no-cache",
 		"priority":          "u=1, i",
 	}
 
-	resp, err := h.httpClient.Do(ctx, httpclient.RequestConfig{
+	resp, err := h.transport.Do(ctx, httpclient.RequestConfig{
 		Method:  "POST",
 		URL:     pricingURL,
 		Headers: headers,
@@ -725,7 +1938,7 @@ func (h *GolfHandler) calculatePricing(ctx context.Context, course *courses.Cour
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
-	h.logger.Debug("pricing calculation response", slog.String("body", resp.Body))
+	h.logger.Debug("pricing calculation response", slog.String("body", redact.Text(resp.Body)))
 	// Parse response
 	var pricingResp models.PricingCalculationResponse
 	if err := json.Unmarshal([]byte(resp.Body), &pricingResp); err != nil {
@@ -782,6 +1995,7 @@ func (h *GolfHandler) reserveTeeTime(ctx context.Context, course *courses.Course
 		TransactionID:           transactionID,
 	}
 
+	ph := course.GetProviderHeaders()
 	headers := map[string]string{
 		"accept":             "application/json, text/plain, */*",
 		"accept-language":    "en-US,en;q=0.9",
@@ -789,7 +2003,7 @@ func (h *GolfHandler) reserveTeeTime(ctx context.Context, course *courses.Course
 		"cache-control":      "no-cache, no-store, must-revalidate",
 		"client-id":          course.ClientID,
 		"content-type":       "application/json",
-		"x-componentid":      "1",
+		"x-componentid":      ph.ComponentID,
 		"x-websiteid":        course.WebsiteID,
 		"if-modified-since":  "0",
 		"origin":             course.Origin,
@@ -800,18 +2014,18 @@ func (h *GolfHandler) reserveTeeTime(ctx context.Context, course *courses.Course
 		"sec-fetch-dest":     "empty",
 		"sec-fetch-mode":     "cors",
 		"sec-fetch-site":     "same-origin",
-		"user-agent":         "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+		"user-agent":         ph.UserAgent,
 		"x-ismobile":         "true",
-		"x-moduleid":         "7",
-		"x-productid":        "1",
-		"x-siteid":           "3",
-		"x-terminalid":       "7",
-		"x-timezone-offset":  "240",
-		"x-timezoneid":       "America/New_York",
+		"x-moduleid":         ph.ModuleID,
+		"x-productid":        ph.ProductID,
+		"x-siteid":           ph.SiteID,
+		"x-terminalid":       ph.TerminalID,
+		"x-timezone-offset":  course.TimezoneOffset(h.clock.Now()),
+		"x-timezoneid":       course.GetTimezone(),
 	}
-	h.logger.Warn("reserve request", slog.String("body", fmt.Sprint(reserveReq)), slog.String("header", fmt.Sprint(headers)))
+	h.logger.Debug("reserve request", slog.String("body", redact.Value(reserveReq)), slog.Any("headers", redact.Headers(headers)))
 
-	resp, err := h.httpClient.Do(ctx, httpclient.RequestConfig{
+	resp, err := h.transport.Do(ctx, httpclient.RequestConfig{
 		Method:  "POST",
 		URL:     bookURL,
 		Headers: headers,
@@ -830,36 +2044,50 @@ func (h *GolfHandler) reserveTeeTime(ctx context.Context, course *courses.Course
 
 	// Check if booking succeeded
 	if reserveResp.ReservationResult != 1 {
-		return nil, fmt.Errorf("reservation failed with result code: %d", reserveResp.ReservationResult)
+		return nil, &reservationRejectedError{resultCode: reserveResp.ReservationResult}
 	}
 
 	return &reserveResp, nil
 }
 
-// formatBookingSuccess formats successful booking as notification
-func (h *GolfHandler) formatBookingSuccess(course *courses.Course, reserve *models.ReservationResponse, pricing *models.PricingCalculationResponse) []string {
-	var sb strings.Builder
-	var strOut []string
-
-	sb.WriteString(fmt.Sprintf("⛳ Tee Time Booked Successfully at %s!\n\n", course.Name))
+// reservationRejectedError is a reservation the provider answered but did not make
+type reservationRejectedError struct {
+	resultCode int
+}
 
-	// Confirmation details
-	sb.WriteString(fmt.Sprintf("Confirmation: %s\n", reserve.ConfirmationKey))
-	sb.WriteString(fmt.Sprintf("Reservation ID: %d\n\n", reserve.ReservationID))
+func (e *reservationRejectedError) Error() string {
+	return fmt.Sprintf("reservation failed with result code: %d", e.resultCode)
+}
 
-	// Tee time details
-	teeTime, err := time.Parse("2006-01-02T15:04:05", pricing.StartTime)
-	if err == nil {
-		sb.WriteString(fmt.Sprintf("Date/Time: %s\n", teeTime.Format("Mon, Jan 2 at 3:04 PM")))
+// reservationRejected reports whether err means the provider certainly did not make the
+// reservation: it answered with a failed result or a client error status
+func reservationRejected(err error) bool {
+	var rejected *reservationRejectedError
+	if errors.As(err, &rejected) {
+		return true
 	}
-	sb.WriteString(fmt.Sprintf("Course: %s\n", pricing.CourseName))
-	sb.WriteString(fmt.Sprintf("Holes: %d\n\n", pricing.Holes))
+	var statusErr *httpclient.StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode >= 400 && statusErr.StatusCode < 500
+}
 
-	// Pricing
-	sb.WriteString(fmt.Sprintf("Total: $%.2f\n", pricing.SummaryDetail.Total))
-	sb.WriteString(fmt.Sprintf("Due at Course: $%.2f\n\n", pricing.SummaryDetail.TotalDueAtCourse))
+// formatBookingSuccess formats successful booking as notification
+func (h *GolfHandler) formatBookingSuccess(course *courses.Course, reserve *models.ReservationResponse, pricing *models.PricingCalculationResponse) ([]string, error) {
+	data := notification.BookingSuccessData{
+		CourseName:      course.Name,
+		ConfirmationKey: reserve.ConfirmationKey,
+		ReservationID:   reserve.ReservationID,
+		TeeSheetCourse:  pricing.CourseName,
+		Holes:           pricing.Holes,
+		Total:           pricing.SummaryDetail.Total,
+		DueAtCourse:     pricing.SummaryDetail.TotalDueAtCourse,
+	}
+	if teeTime, err := time.Parse("2006-01-02T15:04:05", pricing.StartTime); err == nil {
+		data.TeeTime = teeTime
+	}
 
-	sb.WriteString("See you on the course!")
-	strOut = append(strOut, sb.String())
-	return strOut
+	message, err := notification.Render(notification.TemplateBookingSuccess, data)
+	if err != nil {
+		return nil, err
+	}
+	return []string{message}, nil
 }
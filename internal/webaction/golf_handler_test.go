@@ -0,0 +1,256 @@
+package webaction
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/policy"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+	"github.com/jrzesz33/rez_agent/pkg/courses"
+)
+
+func TestDailySearchParams(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name      string
+		start     string
+		end       *string
+		endDate   string
+		wantDays  int
+		wantLast  string
+		wantLastE string
+		wantErr   bool
+	}{
+		{"three days with window", "2030-06-01T07:00:00", strPtr("2030-06-01T10:00:00"), "2030-06-03", 3, "2030-06-03T07:00:00", "2030-06-03T10:00:00", false},
+		{"single day", "2030-06-01T07:00:00", nil, "2030-06-01", 1, "2030-06-01T07:00:00", "", false},
+		{"crosses month", "2030-06-29T07:00:00", nil, "2030-07-02", 4, "2030-07-02T07:00:00", "", false},
+		{"missing end date", "2030-06-01T07:00:00", nil, "", 0, "", "", true},
+		{"end before start", "2030-06-05T07:00:00", nil, "2030-06-01", 0, "", "", true},
+		{"too many days", "2030-06-01T07:00:00", nil, "2030-06-30", 0, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &models.SearchTeeTimesParams{
+				NumberOfPlayer:  2,
+				StartSearchTime: strPtr(tt.start),
+				EndSearchTime:   tt.end,
+				AutoBook:        true,
+			}
+
+			daily, err := dailySearchParams(params, tt.endDate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dailySearchParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(daily) != tt.wantDays {
+				t.Fatalf("len(daily) = %d, want %d", len(daily), tt.wantDays)
+			}
+
+			last := daily[len(daily)-1]
+			if *last.StartSearchTime != tt.wantLast {
+				t.Errorf("last StartSearchTime = %s, want %s", *last.StartSearchTime, tt.wantLast)
+			}
+			if tt.wantLastE != "" && (last.EndSearchTime == nil || *last.EndSearchTime != tt.wantLastE) {
+				t.Errorf("last EndSearchTime = %v, want %s", last.EndSearchTime, tt.wantLastE)
+			}
+			if last.AutoBook || last.NumberOfPlayer != 2 {
+				t.Errorf("last params = %+v, want auto-book off and 2 players", last)
+			}
+			if *params.StartSearchTime != tt.start {
+				t.Errorf("input params were modified")
+			}
+		})
+	}
+}
+
+// fakeTransport answers provider requests with recorded fixtures by URL path suffix
+type fakeTransport struct {
+	t         *testing.T
+	responses map[string]string
+	requests  []httpclient.RequestConfig
+}
+
+func (f *fakeTransport) Do(ctx context.Context, config httpclient.RequestConfig) (*httpclient.Response, error) {
+	f.requests = append(f.requests, config)
+	fixture, ok := f.responses[path.Base(strings.SplitN(config.URL, "?", 2)[0])]
+	if !ok {
+		f.t.Errorf("unexpected request to %s", config.URL)
+		return nil, &httpclient.StatusError{StatusCode: 404}
+	}
+	return &httpclient.Response{StatusCode: 200, Body: string(loadGolfFixture(f.t, fixture))}, nil
+}
+
+// fakeClock records the pauses it is asked for instead of waiting
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+	err    error
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return c.err
+}
+
+func TestGolfHandler_BookTeeTimeSeams(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	course, err := courses.GetCourseByID(1)
+	if err != nil {
+		t.Fatalf("GetCourseByID() error = %v", err)
+	}
+	claims := &models.JWTClaims{GolferID: "30417", Acct: "A-30417", Email: "golfer@example.com"}
+	responses := map[string]string{
+		"LockTeeTimes":             "lock_tee_time.json",
+		"TeeTimePricesCalculation": "price_calculation.json",
+		"ReserveTeeTimes":          "reserve_tee_time.json",
+		"UnLockTeeTimes":           "release_tee_time.json",
+	}
+
+	t.Run("pauses on the clock between pricing and reserving", func(t *testing.T) {
+		transport := &fakeTransport{t: t, responses: responses}
+		clock := &fakeClock{now: time.Date(2030, 5, 25, 7, 0, 0, 0, time.UTC)}
+		handler := NewGolfHandler(nil, nil, nil, logger)
+		handler.SetTransport(transport)
+		handler.SetClock(clock)
+
+		start := time.Now()
+		if _, err := handler.handleBookTeeTime(context.Background(), course, &models.WebActionPayload{TeeSheetID: 918274, NumberOfPlayers: 2}, "token", claims); err != nil {
+			t.Fatalf("handleBookTeeTime() error = %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("handleBookTeeTime() took %v, want the pause fast-forwarded", elapsed)
+		}
+		if len(clock.sleeps) != 1 || clock.sleeps[0] != defaultPricingPause {
+			t.Errorf("sleeps = %v, want one %v pause", clock.sleeps, defaultPricingPause)
+		}
+
+		var paths []string
+		for _, r := range transport.requests {
+			paths = append(paths, path.Base(r.URL))
+			if r.Method != "POST" || r.Headers["authorization"] != "Bearer token" {
+				t.Errorf("%s request = %s with authorization %q, want an authorized POST", r.URL, r.Method, r.Headers["authorization"])
+			}
+		}
+		if got := strings.Join(paths, ","); got != "LockTeeTimes,TeeTimePricesCalculation,ReserveTeeTimes" {
+			t.Errorf("requests = %s, want lock, pricing then reserve", got)
+		}
+	})
+
+	t.Run("cancelled pause never reserves", func(t *testing.T) {
+		transport := &fakeTransport{t: t, responses: responses}
+		handler := NewGolfHandler(nil, nil, nil, logger)
+		handler.SetTransport(transport)
+		handler.SetClock(&fakeClock{err: context.Canceled})
+
+		if _, err := handler.handleBookTeeTime(context.Background(), course, &models.WebActionPayload{TeeSheetID: 918274, NumberOfPlayers: 2}, "token", claims); err == nil {
+			t.Fatal("handleBookTeeTime() error = nil, want the cancelled wait")
+		}
+		if last := transport.requests[len(transport.requests)-1]; !strings.HasSuffix(last.URL, "UnLockTeeTimes") {
+			t.Errorf("last request = %s, want the cancelled booking's lock released instead of reserved", last.URL)
+		}
+	})
+
+	t.Run("sends the course's provider headers", func(t *testing.T) {
+		transport := &fakeTransport{t: t, responses: responses}
+		handler := NewGolfHandler(nil, nil, nil, logger)
+		handler.SetTransport(transport)
+		handler.SetClock(&fakeClock{now: time.Date(2030, 7, 1, 12, 0, 0, 0, time.UTC)})
+
+		local := *course
+		local.Headers = courses.ProviderHeaders{SiteID: "11", TerminalID: "4"}
+		local.Timezone = "America/Chicago"
+		if _, err := handler.handleBookTeeTime(context.Background(), &local, &models.WebActionPayload{TeeSheetID: 918274, NumberOfPlayers: 2}, "token", claims); err != nil {
+			t.Fatalf("handleBookTeeTime() error = %v", err)
+		}
+
+		want := map[string]string{
+			"x-siteid":          "11",
+			"x-terminalid":      "4",
+			"x-moduleid":        courses.DefaultProviderHeaders.ModuleID,
+			"x-timezoneid":      "America/Chicago",
+			"x-timezone-offset": "300",
+		}
+		for _, r := range transport.requests[1:] {
+			for name, value := range want {
+				if got := r.Headers[name]; got != value {
+					t.Errorf("%s header %s = %q, want %q", path.Base(r.URL), name, got, value)
+				}
+			}
+		}
+	})
+}
+
+func TestGolfHandler_BookTeeTimeWeatherRule(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	course, err := courses.GetCourseByID(1)
+	if err != nil {
+		t.Fatalf("GetCourseByID() error = %v", err)
+	}
+	claims := &models.JWTClaims{GolferID: "30417", Acct: "A-30417", Email: "golfer@example.com"}
+	responses := map[string]string{
+		"hourly":                   "hourly_forecast.json",
+		"LockTeeTimes":             "lock_tee_time.json",
+		"TeeTimePricesCalculation": "price_calculation.json",
+		"ReserveTeeTimes":          "reserve_tee_time.json",
+		"UnLockTeeTimes":           "release_tee_time.json",
+	}
+
+	tests := []struct {
+		name            string
+		maxPrecipChance int
+		wantErr         bool
+	}{
+		// The 7:30 AM tee time falls in the 7 AM hour, forecast at 70%
+		{"wetter than allowed", 60, true},
+		{"within the limit", 80, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &fakeTransport{t: t, responses: responses}
+			handler := NewGolfHandler(nil, nil, nil, logger)
+			handler.SetTransport(transport)
+			handler.SetClock(&fakeClock{now: time.Date(2030, 5, 25, 7, 0, 0, 0, time.UTC)})
+
+			receipt := &BookingReceipt{}
+			payload := &models.WebActionPayload{TeeSheetID: 918274, NumberOfPlayers: 2, Policy: &policy.Config{MaxPrecipChance: tt.maxPrecipChance}}
+			_, err := handler.handleBookTeeTime(WithBookingReceipt(context.Background(), receipt), course, payload, "token", claims)
+			if tt.wantErr {
+				if !apperrors.Is(err, apperrors.ErrValidation) {
+					t.Fatalf("handleBookTeeTime() error = %v, want a policy violation", err)
+				}
+				if last := transport.requests[len(transport.requests)-1]; !strings.HasSuffix(last.URL, "UnLockTeeTimes") {
+					t.Errorf("last request = %s, want a tee time breaking the weather rule released instead of reserved", last.URL)
+				}
+				if len(receipt.Bookings()) != 0 {
+					t.Errorf("receipt = %+v, want no bookings", receipt.Bookings())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handleBookTeeTime() error = %v", err)
+			}
+
+			bookings := receipt.Bookings()
+			if len(bookings) != 1 {
+				t.Fatalf("receipt = %+v, want one booking", bookings)
+			}
+			if got := bookings[0]; got.TeeTime != "2030-06-01T07:30:00" || got.TeeSheetID != 918274 || got.PrecipChance == nil || *got.PrecipChance != 70 {
+				t.Errorf("booking = %+v, want the 7:30 tee time judged at 70%%", got)
+			}
+		})
+	}
+}
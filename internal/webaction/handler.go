@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
 	"github.com/jrzesz33/rez_agent/internal/models"
 )
 
@@ -20,6 +21,7 @@ type ActionHandler interface {
 // HandlerRegistry manages action handlers
 type HandlerRegistry struct {
 	handlers map[models.WebActionType]ActionHandler
+	disabled map[models.WebActionType]bool
 	logger   *slog.Logger
 }
 
@@ -27,6 +29,7 @@ type HandlerRegistry struct {
 func NewHandlerRegistry(logger *slog.Logger) *HandlerRegistry {
 	return &HandlerRegistry{
 		handlers: make(map[models.WebActionType]ActionHandler),
+		disabled: make(map[models.WebActionType]bool),
 		logger:   logger,
 	}
 }
@@ -50,8 +53,11 @@ func (r *HandlerRegistry) Register(handler ActionHandler) error {
 // GetHandler retrieves a handler for the given action type
 func (r *HandlerRegistry) GetHandler(actionType models.WebActionType) (ActionHandler, error) {
 	handler, exists := r.handlers[actionType]
+	if !exists && r.disabled[actionType] {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "web action %s is disabled in this stage", actionType)
+	}
 	if !exists {
-		return nil, fmt.Errorf("no handler registered for action type: %s", actionType)
+		return nil, apperrors.Newf(apperrors.ErrValidation, "no handler registered for action type: %s", actionType)
 	}
 
 	return handler, nil
@@ -0,0 +1,71 @@
+package webaction
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// HandlerFactory builds a handler from the options in its stage config
+type HandlerFactory func(options map[string]string) (ActionHandler, error)
+
+// RegisterFromConfig registers a handler for every factory whose config enables it. Action types
+// without a config are enabled with no options, so a missing row never turns a handler off.
+func (r *HandlerRegistry) RegisterFromConfig(factories map[models.WebActionType]HandlerFactory, configs []*models.WebActionHandlerConfig) error {
+	byAction := make(map[models.WebActionType]*models.WebActionHandlerConfig, len(configs))
+	for _, config := range configs {
+		if _, ok := factories[config.Action]; !ok {
+			r.logger.Warn("ignoring config for unknown web action handler",
+				slog.String("action_type", config.Action.String()),
+			)
+			continue
+		}
+		byAction[config.Action] = config
+	}
+
+	for actionType, factory := range factories {
+		config, ok := byAction[actionType]
+		if !ok {
+			config = &models.WebActionHandlerConfig{Action: actionType, Enabled: true}
+		}
+
+		if !config.Enabled {
+			r.disabled[actionType] = true
+			r.logger.Info("web action handler disabled by config",
+				slog.String("action_type", actionType.String()),
+			)
+			continue
+		}
+
+		handler, err := factory(config.Options)
+		if err != nil {
+			return fmt.Errorf("failed to build %s handler: %w", actionType, err)
+		}
+		if len(config.DisabledOperations) > 0 {
+			handler = &operationFilter{ActionHandler: handler, config: config}
+		}
+		if err := r.Register(handler); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// operationFilter rejects operations a stage has turned off before they reach the handler
+type operationFilter struct {
+	ActionHandler
+	config *models.WebActionHandlerConfig
+}
+
+// Execute runs the wrapped handler unless the operation is disabled
+func (f *operationFilter) Execute(ctx context.Context, args map[string]interface{}, payload *models.WebActionPayload) ([]string, error) {
+	operation, _ := args["operation"].(string)
+	if f.config.OperationDisabled(operation) {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "operation %s is disabled for %s web actions in this stage", operation, f.config.Action)
+	}
+	return f.ActionHandler.Execute(ctx, args, payload)
+}
@@ -0,0 +1,92 @@
+package webaction
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// fakeHandler records the options it was built with
+type fakeHandler struct {
+	actionType models.WebActionType
+	options    map[string]string
+}
+
+func (f *fakeHandler) Execute(ctx context.Context, args map[string]interface{}, payload *models.WebActionPayload) ([]string, error) {
+	return []string{"ok"}, nil
+}
+
+func (f *fakeHandler) GetActionType() models.WebActionType { return f.actionType }
+
+func fakeFactory(actionType models.WebActionType) HandlerFactory {
+	return func(options map[string]string) (ActionHandler, error) {
+		return &fakeHandler{actionType: actionType, options: options}, nil
+	}
+}
+
+func TestHandlerRegistry_RegisterFromConfig(t *testing.T) {
+	registry := NewHandlerRegistry(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	factories := map[models.WebActionType]HandlerFactory{
+		models.WebActionTypeWeather:     fakeFactory(models.WebActionTypeWeather),
+		models.WebActionTypeGolf:        fakeFactory(models.WebActionTypeGolf),
+		models.WebActionTypeHTTPRequest: fakeFactory(models.WebActionTypeHTTPRequest),
+	}
+	configs := []*models.WebActionHandlerConfig{
+		{Action: models.WebActionTypeGolf, Enabled: true, DisabledOperations: []string{"book_tee_time"}},
+		{Action: models.WebActionTypeHTTPRequest, Enabled: false},
+		{Action: "unknown", Enabled: true},
+	}
+
+	if err := registry.RegisterFromConfig(factories, configs); err != nil {
+		t.Fatalf("RegisterFromConfig() error = %v", err)
+	}
+
+	if _, err := registry.GetHandler(models.WebActionTypeWeather); err != nil {
+		t.Errorf("weather handler without config: GetHandler() error = %v, want enabled", err)
+	}
+
+	_, err := registry.GetHandler(models.WebActionTypeHTTPRequest)
+	if err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("disabled handler: GetHandler() error = %v, want disabled error", err)
+	}
+
+	golf, err := registry.GetHandler(models.WebActionTypeGolf)
+	if err != nil {
+		t.Fatalf("GetHandler(golf) error = %v", err)
+	}
+	tests := []struct {
+		operation string
+		wantErr   bool
+	}{
+		{"search_tee_times", false},
+		{"book_tee_time", true},
+	}
+	for _, tt := range tests {
+		_, err := golf.Execute(context.Background(), map[string]interface{}{"operation": tt.operation}, &models.WebActionPayload{})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Execute(%s) error = %v, wantErr %v", tt.operation, err, tt.wantErr)
+		}
+	}
+}
+
+func TestHandlerRegistry_RegisterFromConfig_Options(t *testing.T) {
+	registry := NewHandlerRegistry(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	factories := map[models.WebActionType]HandlerFactory{
+		models.WebActionTypeHTTPRequest: fakeFactory(models.WebActionTypeHTTPRequest),
+	}
+	configs := []*models.WebActionHandlerConfig{
+		{Action: models.WebActionTypeHTTPRequest, Enabled: true, Options: map[string]string{"allowed_hosts": "api.example.com"}},
+	}
+
+	if err := registry.RegisterFromConfig(factories, configs); err != nil {
+		t.Fatalf("RegisterFromConfig() error = %v", err)
+	}
+	handler, _ := registry.GetHandler(models.WebActionTypeHTTPRequest)
+	if got := handler.(*fakeHandler).options["allowed_hosts"]; got != "api.example.com" {
+		t.Errorf("factory options allowed_hosts = %q, want api.example.com", got)
+	}
+}
@@ -0,0 +1,235 @@
+package webaction
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
+	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/oauth"
+	"github.com/jrzesz33/rez_agent/internal/secrets"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
+)
+
+// httpRequestMethods are the methods an http_request action may use
+var httpRequestMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// maxHTTPRequestResultLength bounds how much of the response body goes into the notification
+const maxHTTPRequestResultLength = 1000
+
+// HTTPRequestHandler executes generic, allowlisted HTTP calls described by the web action payload,
+// so new integrations can be configured without writing a handler
+type HTTPRequestHandler struct {
+	httpClient     *httpclient.Client
+	oauthClient    *oauth.Client
+	secretsManager *secrets.Manager
+	allowedHosts   map[string]bool
+	logger         *slog.Logger
+}
+
+// NewHTTPRequestHandler creates a handler that may only call models.AllowedHosts and the extra hosts given
+func NewHTTPRequestHandler(httpClient *httpclient.Client, oauthClient *oauth.Client, secretsManager *secrets.Manager, extraHosts []string, logger *slog.Logger) *HTTPRequestHandler {
+	allowed := make(map[string]bool, len(models.AllowedHosts)+len(extraHosts))
+	for host := range models.AllowedHosts {
+		allowed[host] = true
+	}
+	for _, host := range extraHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			allowed[host] = true
+		}
+	}
+
+	return &HTTPRequestHandler{
+		httpClient:     httpClient,
+		oauthClient:    oauthClient,
+		secretsManager: secretsManager,
+		allowedHosts:   allowed,
+		logger:         logger,
+	}
+}
+
+// GetActionType returns the action type this handler supports
+func (h *HTTPRequestHandler) GetActionType() models.WebActionType {
+	return models.WebActionTypeHTTPRequest
+}
+
+// Execute renders the request templates with the message arguments, authenticates, and performs the call
+func (h *HTTPRequestHandler) Execute(ctx context.Context, args map[string]interface{}, payload *models.WebActionPayload) ([]string, error) {
+	req, err := h.buildRequest(args, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.authenticate(ctx, payload.AuthConfig, req.Headers); err != nil {
+		return nil, err
+	}
+
+	h.logger.Debug("executing http_request action",
+		slog.String("method", req.Method),
+		slog.String("url", redactedURL(req.URL)),
+	)
+
+	resp, err := h.httpClient.Do(ctx, *req)
+	if err != nil {
+		return nil, fmt.Errorf("http_request %s %s failed: %w", req.Method, redactedURL(req.URL), err)
+	}
+
+	body := resp.Body
+	if len(body) > maxHTTPRequestResultLength {
+		body = body[:maxHTTPRequestResultLength] + "..."
+	}
+	return []string{fmt.Sprintf("🌐 %s %s\nStatus: %d\n\n%s", req.Method, redactedURL(req.URL), resp.StatusCode, body)}, nil
+}
+
+// buildRequest renders the URL, headers, and body templates and enforces the host allowlist
+func (h *HTTPRequestHandler) buildRequest(args map[string]interface{}, payload *models.WebActionPayload) (*httpclient.RequestConfig, error) {
+	method := strings.ToUpper(payload.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !httpRequestMethods[method] {
+		return nil, apperrors.Newf(apperrors.ErrValidation, "http_request method %s is not allowed", payload.Method)
+	}
+
+	rawURL, err := renderTemplate("url", payload.URL, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.checkURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string, len(payload.Headers))
+	for name, value := range payload.Headers {
+		rendered, err := renderTemplate("header "+name, value, args)
+		if err != nil {
+			return nil, err
+		}
+		headers[name] = rendered
+	}
+
+	req := &httpclient.RequestConfig{
+		Method:  method,
+		URL:     rawURL,
+		Headers: headers,
+		Timeout: 30 * time.Second,
+	}
+
+	if payload.Body != "" {
+		rendered, err := renderTemplate("body", payload.Body, args)
+		if err != nil {
+			return nil, err
+		}
+		var body interface{}
+		if err := json.Unmarshal([]byte(rendered), &body); err != nil {
+			return nil, apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("http_request body is not valid JSON after rendering: %w", err))
+		}
+		req.Body = body
+	}
+
+	return req, nil
+}
+
+// checkURL allows only HTTPS URLs on allowlisted hosts
+func (h *HTTPRequestHandler) checkURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid http_request URL: %w", err))
+	}
+	if parsed.Scheme != "https" {
+		return apperrors.Newf(apperrors.ErrValidation, "http_request URL must use https")
+	}
+	if !h.allowedHosts[strings.ToLower(parsed.Hostname())] {
+		return apperrors.Newf(apperrors.ErrValidation, "http_request host %s is not allowlisted", parsed.Hostname())
+	}
+	return nil
+}
+
+// authenticate adds credentials from the auth config to the request headers
+func (h *HTTPRequestHandler) authenticate(ctx context.Context, auth *models.AuthConfig, headers map[string]string) error {
+	if auth == nil || auth.Type == models.AuthTypeNone {
+		return nil
+	}
+
+	switch auth.Type {
+	case models.AuthTypeOAuthPassword:
+		if err := h.checkURL(auth.TokenURL); err != nil {
+			return fmt.Errorf("invalid OAuth token URL: %w", err)
+		}
+		token, err := h.oauthClient.OAuthPasswordGrant(ctx, auth.TokenURL, auth.SecretName, auth.Scope, auth.Headers)
+		if err != nil {
+			return fmt.Errorf("OAuth authentication failed: %w", err)
+		}
+		httpclient.AddBearerToken(headers, token)
+		return nil
+
+	case models.AuthTypeBearer:
+		secret, err := h.secretsManager.GetSecret(ctx, auth.SecretName)
+		if err != nil {
+			return fmt.Errorf("failed to load bearer token: %w", err)
+		}
+		if secret["token"] == "" {
+			return apperrors.Newf(apperrors.ErrAuth, "secret %s has no token value", auth.SecretName)
+		}
+		httpclient.AddBearerToken(headers, secret["token"])
+
+	case models.AuthTypeAPIKey:
+		secret, err := h.secretsManager.GetSecret(ctx, auth.SecretName)
+		if err != nil {
+			return fmt.Errorf("failed to load API key: %w", err)
+		}
+		if secret["api_key"] == "" {
+			return apperrors.Newf(apperrors.ErrAuth, "secret %s has no api_key value", auth.SecretName)
+		}
+		httpclient.AddAPIKey(headers, secret["api_key"], secret["header_name"])
+
+	default:
+		return apperrors.Newf(apperrors.ErrValidation, "unsupported auth type for http_request: %s", auth.Type)
+	}
+
+	for name, value := range auth.Headers {
+		headers[name] = value
+	}
+	return nil
+}
+
+// renderTemplate renders a {{.arg}} template with the message arguments, failing on missing arguments
+func renderTemplate(name, text string, args map[string]interface{}) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("invalid http_request %s template: %w", name, err))
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, args); err != nil {
+		return "", apperrors.Wrap(apperrors.ErrValidation, fmt.Errorf("failed to render http_request %s template: %w", name, err))
+	}
+	return buf.String(), nil
+}
+
+// redactedURL drops the query string, which may carry credentials, for logs and notifications
+func redactedURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "[invalid url]"
+	}
+	return parsed.Scheme + "://" + parsed.Host + parsed.Path
+}
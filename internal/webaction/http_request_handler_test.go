@@ -0,0 +1,71 @@
+package webaction
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+func TestHTTPRequestHandler_BuildRequest(t *testing.T) {
+	h := NewHTTPRequestHandler(nil, nil, nil, []string{" Hooks.Example.com "}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	args := map[string]interface{}{"course": "Totteridge", "players": 2}
+
+	tests := []struct {
+		name    string
+		payload models.WebActionPayload
+		wantURL string
+		wantErr string
+	}{
+		{
+			name:    "templated POST",
+			payload: models.WebActionPayload{Method: "post", URL: "https://hooks.example.com/golf/{{.course}}", Body: `{"players": {{.players}}}`, Headers: map[string]string{"X-Course": "{{.course}}"}},
+			wantURL: "https://hooks.example.com/golf/Totteridge",
+		},
+		{
+			name:    "default allowlist",
+			payload: models.WebActionPayload{URL: "https://api.weather.gov/points/1,2"},
+			wantURL: "https://api.weather.gov/points/1,2",
+		},
+		{name: "host not allowlisted", payload: models.WebActionPayload{URL: "https://169.254.169.254/latest"}, wantErr: "not allowlisted"},
+		{name: "plain http", payload: models.WebActionPayload{URL: "http://hooks.example.com/x"}, wantErr: "https"},
+		{name: "templated host escape", payload: models.WebActionPayload{URL: "https://{{.course}}.evil.com/"}, wantErr: "not allowlisted"},
+		{name: "method not allowed", payload: models.WebActionPayload{Method: "TRACE", URL: "https://hooks.example.com/"}, wantErr: "not allowed"},
+		{name: "missing argument", payload: models.WebActionPayload{URL: "https://hooks.example.com/{{.missing}}"}, wantErr: "failed to render"},
+		{name: "body not JSON", payload: models.WebActionPayload{Method: "POST", URL: "https://hooks.example.com/", Body: "players={{.players}}"}, wantErr: "not valid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := h.buildRequest(args, &tt.payload)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("buildRequest() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildRequest() error = %v", err)
+			}
+			if req.URL != tt.wantURL {
+				t.Errorf("URL = %s, want %s", req.URL, tt.wantURL)
+			}
+		})
+	}
+
+	req, _ := h.buildRequest(args, &tests[0].payload)
+	if req.Method != "POST" || req.Headers["X-Course"] != "Totteridge" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if body, ok := req.Body.(map[string]interface{}); !ok || body["players"] != float64(2) {
+		t.Errorf("Body = %#v, want players=2", req.Body)
+	}
+}
+
+func TestRedactedURL(t *testing.T) {
+	if got := redactedURL("https://hooks.example.com/path?api_key=secret"); got != "https://hooks.example.com/path" {
+		t.Errorf("redactedURL() = %s", got)
+	}
+}
@@ -5,11 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"strings"
 	"time"
 
-	"github.com/jrzesz33/rez_agent/internal/httpclient"
+	apperrors "github.com/jrzesz33/rez_agent/internal/errors"
 	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/notification"
+	"github.com/jrzesz33/rez_agent/kit/httpclient"
 	"github.com/jrzesz33/rez_agent/pkg/courses"
 )
 
@@ -39,7 +40,7 @@ func (h *WeatherHandler) Execute(ctx context.Context, args map[string]interface{
 	)
 	course, err := courses.GetCourseByID(payload.CourseID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load course configuration: %w", err)
+		return nil, apperrors.Wrap(apperrors.ErrNotFound, fmt.Errorf("failed to load course configuration: %w", err))
 	}
 	// Route based on operation type
 	operation, _ := args["operation"].(string)
@@ -70,7 +71,8 @@ func (h *WeatherHandler) Execute(ctx context.Context, args map[string]interface{
 			"Accept":     "application/json",
 			"User-Agent": "rez-agent weather notifier (contact@example.com)",
 		},
-		Timeout: 30 * time.Second,
+		Timeout:  30 * time.Second,
+		CacheTTL: models.WeatherCacheTTL,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch weather data: %w", err)
@@ -83,14 +85,17 @@ func (h *WeatherHandler) Execute(ctx context.Context, args map[string]interface{
 	}
 
 	// Format notification message
-	notification := h.formatWeatherNotification(weatherData, numDays)
+	messages, err := h.formatWeatherNotification(weatherData, numDays)
+	if err != nil {
+		return nil, err
+	}
 
 	h.logger.Debug("weather action completed successfully",
 		slog.Int("num_days", numDays),
 		slog.Int("periods_found", len(weatherData.Properties.Periods)),
 	)
 
-	return notification, nil
+	return messages, nil
 }
 
 // WeatherAPIResponse represents the weather.gov API response structure
@@ -115,80 +120,61 @@ type WeatherPeriod struct {
 	WindDirection    string `json:"windDirection"`
 	ShortForecast    string `json:"shortForecast"`
 	DetailedForecast string `json:"detailedForecast"`
+
+	ProbabilityOfPrecipitation struct {
+		Value *int `json:"value"`
+	} `json:"probabilityOfPrecipitation"`
 }
 
-// formatWeatherNotification formats weather data into a readable notification
-func (h *WeatherHandler) formatWeatherNotification(data WeatherAPIResponse, numDays int) []string {
-	var sb strings.Builder
-	var strOut []string
+// hourlyPrecipChances reads an hourly forecast into the precipitation chance of each hour, keyed
+// by the UTC hour the period starts. A missing probability is zero, as weather.gov means it.
+func hourlyPrecipChances(data WeatherAPIResponse) map[time.Time]int {
+	chances := make(map[time.Time]int, len(data.Properties.Periods))
+	for _, period := range data.Properties.Periods {
+		start, err := time.Parse(time.RFC3339, period.StartTime)
+		if err != nil {
+			continue
+		}
+		chance := 0
+		if period.ProbabilityOfPrecipitation.Value != nil {
+			chance = *period.ProbabilityOfPrecipitation.Value
+		}
+		chances[start.UTC().Truncate(time.Hour)] = chance
+	}
+	return chances
+}
 
+// formatWeatherNotification formats weather data into one notification per day, each ending
+// with its night period
+func (h *WeatherHandler) formatWeatherNotification(data WeatherAPIResponse, numDays int) ([]string, error) {
 	// Calculate how many periods to include (2 periods per day: day and night)
 	maxPeriods := numDays * 2
 	if len(data.Properties.Periods) < maxPeriods {
 		maxPeriods = len(data.Properties.Periods)
 	}
 
-	// Include detailed forecast for each period
-	for i := 0; i < maxPeriods; i++ {
-
-		period := data.Properties.Periods[i]
-
-		if sb.Len() == 0 {
-			sb.WriteString("🌤️ Weather Forecast\n")
-		}
-
-		// Period header
-		sb.WriteString(fmt.Sprintf("📅 %s\n", period.Name))
-
-		// Temperature
-		tempEmoji := "🌡️"
-		if period.Temperature < 32 {
-			tempEmoji = "❄️"
-		} else if period.Temperature > 80 {
-			tempEmoji = "🔥"
-		}
-		sb.WriteString(fmt.Sprintf("%s %d°%s", tempEmoji, period.Temperature, period.TemperatureUnit))
-
-		// Temperature trend
-		if period.TemperatureTrend != "" {
-			trendEmoji := "↗️"
-			if period.TemperatureTrend == "falling" {
-				trendEmoji = "↘️"
-			}
-			sb.WriteString(fmt.Sprintf(" %s %s", trendEmoji, period.TemperatureTrend))
-		}
-		sb.WriteString("\n")
-
-		// Wind
-		sb.WriteString(fmt.Sprintf("💨 Wind: %s %s\n", period.WindSpeed, period.WindDirection))
-
-		// Short forecast
-		//sb.WriteString(fmt.Sprintf("☁️ %s\n", period.ShortForecast))
-
-		// Detailed forecast
-		sb.WriteString(fmt.Sprintf("☁️ %s\n", period.DetailedForecast))
-
-		// Separator between periods
-		//if i < maxPeriods-1 {
-		//	sb.WriteString("\n" + strings.Repeat("─", 40) + "\n\n")
-		//}
-
-		if !period.IsDaytime {
-			strOut = append(strOut, sb.String())
-			sb.Reset()
-		} else {
-			sb.WriteString("\n\n")
+	var strOut []string
+	var day notification.WeatherAlertData
+	for i, period := range data.Properties.Periods[:maxPeriods] {
+		day.Periods = append(day.Periods, notification.WeatherAlertPeriod{
+			Name:             period.Name,
+			Temperature:      period.Temperature,
+			TemperatureUnit:  period.TemperatureUnit,
+			TemperatureTrend: period.TemperatureTrend,
+			Wind:             fmt.Sprintf("%s %s", period.WindSpeed, period.WindDirection),
+			Forecast:         period.DetailedForecast,
+		})
+		if period.IsDaytime && i < maxPeriods-1 {
+			continue
 		}
-	}
 
-	/*/ Footer with update time
-	if data.Properties.Updated != "" {
-		updateTime, err := time.Parse(time.RFC3339, data.Properties.Updated)
-		if err == nil {
-			sb.WriteString(fmt.Sprintf("\n\nUpdated: %s", updateTime.Format("Mon Jan 2, 3:04 PM MST")))
+		message, err := notification.Render(notification.TemplateWeatherAlert, day)
+		if err != nil {
+			return nil, err
 		}
+		strOut = append(strOut, message)
+		day = notification.WeatherAlertData{}
 	}
-	//*/
 
-	return strOut
+	return strOut, nil
 }
@@ -0,0 +1,5 @@
+module github.com/jrzesz33/rez_agent/kit
+
+go 1.24
+
+require github.com/aws/aws-lambda-go v1.47.0
@@ -0,0 +1,170 @@
+package httpclient
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a request is short-circuited because its host is failing
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a per-host circuit
+type CircuitState string
+
+const (
+	// CircuitClosed lets requests through and counts failures
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects requests until the open timeout elapses
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a limited number of probe requests through
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreakerConfig holds the thresholds for a CircuitBreaker
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens a host's circuit
+	FailureThreshold int
+
+	// OpenTimeout is how long a circuit stays open before probe requests are allowed
+	OpenTimeout time.Duration
+
+	// HalfOpenMaxRequests is how many probe requests may be in flight while half-open
+	HalfOpenMaxRequests int
+}
+
+// DefaultCircuitBreakerConfig returns thresholds suited to the golf and weather APIs
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold:    5,
+		OpenTimeout:         30 * time.Second,
+		HalfOpenMaxRequests: 1,
+	}
+}
+
+// hostCircuit tracks the state of a single host
+type hostCircuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+// CircuitBreaker short-circuits requests to hosts that keep failing
+type CircuitBreaker struct {
+	mu     sync.Mutex
+	config CircuitBreakerConfig
+	hosts  map[string]*hostCircuit
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewCircuitBreaker creates a per-host circuit breaker; zero config values fall back to the defaults
+func NewCircuitBreaker(config CircuitBreakerConfig, logger *slog.Logger) *CircuitBreaker {
+	defaults := DefaultCircuitBreakerConfig()
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaults.FailureThreshold
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaults.OpenTimeout
+	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = defaults.HalfOpenMaxRequests
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &CircuitBreaker{
+		config: config,
+		hosts:  make(map[string]*hostCircuit),
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// circuit returns the circuit for a host, creating a closed one if needed. Callers must hold mu.
+func (cb *CircuitBreaker) circuit(host string) *hostCircuit {
+	c, ok := cb.hosts[host]
+	if !ok {
+		c = &hostCircuit{state: CircuitClosed}
+		cb.hosts[host] = c
+	}
+	return c
+}
+
+// Allow reports whether a request to host may proceed, returning ErrCircuitOpen if not
+func (cb *CircuitBreaker) Allow(host string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(host)
+	switch c.state {
+	case CircuitOpen:
+		if cb.now().Sub(c.openedAt) < cb.config.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		cb.transition(host, c, CircuitHalfOpen)
+		c.openedAt = cb.now()
+		c.probes = 1
+		return nil
+	case CircuitHalfOpen:
+		// Probes that never reported back free their slot after another open timeout
+		if c.probes >= cb.config.HalfOpenMaxRequests {
+			if cb.now().Sub(c.openedAt) < cb.config.OpenTimeout {
+				return ErrCircuitOpen
+			}
+			c.openedAt = cb.now()
+			c.probes = 0
+		}
+		c.probes++
+		return nil
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess closes the host's circuit and resets its failure count
+func (cb *CircuitBreaker) RecordSuccess(host string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(host)
+	c.failures = 0
+	if c.state != CircuitClosed {
+		cb.transition(host, c, CircuitClosed)
+	}
+}
+
+// RecordFailure counts a failure against the host, opening its circuit at the threshold
+func (cb *CircuitBreaker) RecordFailure(host string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(host)
+	c.failures++
+	if c.state == CircuitHalfOpen || (c.state == CircuitClosed && c.failures >= cb.config.FailureThreshold) {
+		c.openedAt = cb.now()
+		cb.transition(host, c, CircuitOpen)
+	}
+}
+
+// State returns the current state of a host's circuit
+func (cb *CircuitBreaker) State(host string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.circuit(host).state
+}
+
+// transition moves a circuit to a new state. Callers must hold mu.
+func (cb *CircuitBreaker) transition(host string, c *hostCircuit, to CircuitState) {
+	cb.logger.Warn("circuit breaker state change",
+		slog.String("host", host),
+		slog.String("from", string(c.state)),
+		slog.String("to", string(to)),
+		slog.Int("consecutive_failures", c.failures),
+	)
+	c.state = to
+	c.probes = 0
+}
@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, OpenTimeout: 30 * time.Second}, nil)
+	cb.now = func() time.Time { return now }
+	host := "api.example.com"
+
+	for i := 0; i < 2; i++ {
+		cb.RecordFailure(host)
+	}
+	if got := cb.State(host); got != CircuitClosed {
+		t.Fatalf("State() after 2 failures = %s, want closed", got)
+	}
+
+	cb.RecordFailure(host)
+	if got := cb.State(host); got != CircuitOpen {
+		t.Fatalf("State() after 3 failures = %s, want open", got)
+	}
+	if err := cb.Allow(host); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow() while open = %v, want ErrCircuitOpen", err)
+	}
+	if err := cb.Allow("other.example.com"); err != nil {
+		t.Fatalf("Allow() for another host = %v, want nil", err)
+	}
+
+	// After the open timeout a single probe is let through
+	now = now.Add(31 * time.Second)
+	if err := cb.Allow(host); err != nil {
+		t.Fatalf("Allow() probe = %v, want nil", err)
+	}
+	if got := cb.State(host); got != CircuitHalfOpen {
+		t.Fatalf("State() = %s, want half_open", got)
+	}
+	if err := cb.Allow(host); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second Allow() while half-open = %v, want ErrCircuitOpen", err)
+	}
+
+	// A failed probe reopens the circuit
+	cb.RecordFailure(host)
+	if got := cb.State(host); got != CircuitOpen {
+		t.Fatalf("State() after failed probe = %s, want open", got)
+	}
+
+	// A successful probe closes it
+	now = now.Add(31 * time.Second)
+	if err := cb.Allow(host); err != nil {
+		t.Fatalf("Allow() probe = %v, want nil", err)
+	}
+	cb.RecordSuccess(host)
+	if got := cb.State(host); got != CircuitClosed {
+		t.Fatalf("State() after successful probe = %s, want closed", got)
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2}, nil)
+	host := "api.example.com"
+
+	cb.RecordFailure(host)
+	cb.RecordSuccess(host)
+	cb.RecordFailure(host)
+	if got := cb.State(host); got != CircuitClosed {
+		t.Errorf("State() = %s, want closed since failures were not consecutive", got)
+	}
+}
+
+func TestClient_Do_ShortCircuits(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	client.SetCircuitBreaker(NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute}, nil))
+
+	_, err := client.Do(context.Background(), RequestConfig{Method: http.MethodGet, URL: server.URL})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Do() error = %v, want ErrCircuitOpen after the first failure", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server calls = %d, want 1 (retries should be short-circuited)", got)
+	}
+}
@@ -12,24 +12,17 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
-	"sync"
 	"time"
+
+	"github.com/jrzesz33/rez_agent/kit/redact"
 )
 
 // Client wraps http.Client with security features and retry logic
 type Client struct {
 	httpClient *http.Client
+	breaker    *CircuitBreaker
+	cache      *ResponseCache
 	logger     *slog.Logger
-
-	// OAuth token cache
-	oauthCache     map[string]*cachedToken
-	oauthCacheLock sync.RWMutex
-}
-
-// cachedToken represents a cached OAuth token
-type cachedToken struct {
-	AccessToken string
-	ExpiresAt   time.Time
 }
 
 // NewClient creates a new HTTP client with security configuration
@@ -65,11 +58,49 @@ func NewClient(logger *slog.Logger) *Client {
 	}
 
 	return &Client{
-		httpClient:     httpClient,
-		logger:         logger,
-		oauthCache:     make(map[string]*cachedToken),
-		oauthCacheLock: sync.RWMutex{},
+		httpClient: httpClient,
+		breaker:    NewCircuitBreaker(DefaultCircuitBreakerConfig(), logger),
+		cache:      NewResponseCache(defaultResponseCacheSize),
+		logger:     logger,
+	}
+}
+
+// SetCircuitBreaker replaces the per-host circuit breaker; nil disables it
+func (c *Client) SetCircuitBreaker(breaker *CircuitBreaker) {
+	c.breaker = breaker
+}
+
+// allowRequest checks the circuit breaker for the request's host
+func (c *Client) allowRequest(targetURL string) (string, error) {
+	if c.breaker == nil {
+		return "", nil
+	}
+	parsed, err := url.Parse(targetURL)
+	if err != nil || parsed.Host == "" {
+		return "", nil
+	}
+	if err := c.breaker.Allow(parsed.Host); err != nil {
+		return parsed.Host, fmt.Errorf("request to %s short-circuited: %w", parsed.Host, err)
+	}
+	return parsed.Host, nil
+}
+
+// recordResult reports a request outcome to the circuit breaker. Only failures that
+// point at an unhealthy host (network errors, 5xx, 429) count against it.
+func (c *Client) recordResult(host string, err error, resp *Response) {
+	if c.breaker == nil || host == "" {
+		return
 	}
+	if err != nil && isRetryableError(err, resp) {
+		c.breaker.RecordFailure(host)
+		return
+	}
+	c.breaker.RecordSuccess(host)
+}
+
+// SetResponseCache replaces the GET response cache; nil disables caching
+func (c *Client) SetResponseCache(cache *ResponseCache) {
+	c.cache = cache
 }
 
 // RequestConfig contains configuration for an HTTP request
@@ -79,6 +110,9 @@ type RequestConfig struct {
 	Headers map[string]string
 	Body    interface{}
 	Timeout time.Duration
+
+	// CacheTTL reuses a successful GET response for this long (zero disables caching)
+	CacheTTL time.Duration
 }
 
 // Response represents an HTTP response
@@ -88,8 +122,29 @@ type Response struct {
 	Headers    http.Header
 }
 
+// StatusError is returned for responses with a 4xx or 5xx status code
+type StatusError struct {
+	StatusCode int
+	// Body is the start of the response body, with credentials and personal data masked
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP error %d: %s", e.StatusCode, e.Body)
+}
+
 // Do executes an HTTP request with retry logic
 func (c *Client) Do(ctx context.Context, config RequestConfig) (*Response, error) {
+	cacheable := c.cache != nil && config.CacheTTL > 0 && config.Method == http.MethodGet
+	if cacheable {
+		if resp, ok := c.cache.Get(config.URL); ok {
+			c.logger.Debug("HTTP response served from cache",
+				slog.String("url", redact.URL(config.URL)),
+			)
+			return resp, nil
+		}
+	}
+
 	// Apply custom timeout if specified
 	if config.Timeout > 0 {
 		var cancel context.CancelFunc
@@ -102,6 +157,16 @@ func (c *Client) Do(ctx context.Context, config RequestConfig) (*Response, error
 	var lastErr error
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		// Check the breaker before backing off so an open circuit fails fast
+		host, err := c.allowRequest(config.URL)
+		if err != nil {
+			c.logger.Warn("HTTP request short-circuited",
+				slog.String("method", config.Method),
+				slog.String("host", host),
+			)
+			return nil, err
+		}
+
 		if attempt > 0 {
 			// Exponential backoff: 2^attempt seconds
 			backoff := time.Duration(1<<uint(attempt)) * time.Second
@@ -119,7 +184,11 @@ func (c *Client) Do(ctx context.Context, config RequestConfig) (*Response, error
 		}
 
 		resp, err := c.doRequest(ctx, config)
+		c.recordResult(host, err, resp)
 		if err == nil {
+			if cacheable {
+				c.cache.Put(config.URL, resp, config.CacheTTL)
+			}
 			return resp, nil
 		}
 
@@ -184,7 +253,7 @@ func (c *Client) doRequest(ctx context.Context, config RequestConfig) (*Response
 	if err != nil {
 		c.logger.Error("HTTP request failed",
 			slog.String("method", config.Method),
-			slog.String("url", config.URL),
+			slog.String("url", redact.URL(config.URL)),
 			slog.Duration("duration", duration),
 			slog.String("error", err.Error()),
 		)
@@ -207,7 +276,7 @@ func (c *Client) doRequest(ctx context.Context, config RequestConfig) (*Response
 	// Log response
 	c.logger.Debug("HTTP request completed",
 		slog.String("method", config.Method),
-		slog.String("url", config.URL),
+		slog.String("url", redact.URL(config.URL)),
 		slog.Int("status_code", resp.StatusCode),
 		slog.Duration("duration", duration),
 		slog.Int("response_size", len(bodyBytes)),
@@ -215,7 +284,7 @@ func (c *Client) doRequest(ctx context.Context, config RequestConfig) (*Response
 
 	// Check for HTTP errors
 	if resp.StatusCode >= 400 {
-		return response, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, truncateBody(string(bodyBytes), 200))
+		return response, &StatusError{StatusCode: resp.StatusCode, Body: TruncateBody(redact.Text(string(bodyBytes)), 200)}
 	}
 
 	return response, nil
@@ -223,25 +292,11 @@ func (c *Client) doRequest(ctx context.Context, config RequestConfig) (*Response
 
 // logRequest logs HTTP request without sensitive information
 func (c *Client) logRequest(req *http.Request) {
-	// SECURITY: Redact sensitive headers
-	redactedHeaders := make(map[string]string)
-	for key := range req.Header {
-		lowerKey := strings.ToLower(key)
-		if strings.Contains(lowerKey, "auth") ||
-		   strings.Contains(lowerKey, "token") ||
-		   strings.Contains(lowerKey, "key") ||
-		   strings.Contains(lowerKey, "secret") ||
-		   strings.Contains(lowerKey, "password") {
-			redactedHeaders[key] = "[REDACTED]"
-		} else {
-			redactedHeaders[key] = req.Header.Get(key)
-		}
-	}
-
+	// SECURITY: Redact sensitive headers and query parameters
 	c.logger.Debug("HTTP request",
 		slog.String("method", req.Method),
-		slog.String("url", req.URL.String()),
-		slog.Any("headers", redactedHeaders),
+		slog.String("url", redact.URL(req.URL.String())),
+		slog.Any("headers", redact.HTTPHeaders(req.Header)),
 	)
 }
 
@@ -274,66 +329,21 @@ func isRetryableError(err error, resp *Response) bool {
 	return false
 }
 
-// truncateBody truncates a response body for logging
-func truncateBody(body string, maxLen int) string {
+// TruncateBody truncates a response body for logs and error messages
+func TruncateBody(body string, maxLen int) string {
 	if len(body) <= maxLen {
 		return body
 	}
 	return body[:maxLen] + "..."
 }
 
-// GetCachedOAuthToken retrieves a cached OAuth token if not expired
-func (c *Client) GetCachedOAuthToken(cacheKey string) (string, bool) {
-	c.oauthCacheLock.RLock()
-	defer c.oauthCacheLock.RUnlock()
-
-	cached, exists := c.oauthCache[cacheKey]
-	if !exists {
-		return "", false
-	}
-
-	// Check if expired (with 10-minute buffer)
-	if time.Now().Add(10 * time.Minute).After(cached.ExpiresAt) {
-		return "", false
-	}
-
-	// SECURITY: Never log the actual token
-	c.logger.Debug("OAuth token cache hit",
-		slog.String("cache_key", cacheKey),
-	)
-
-	return cached.AccessToken, true
-}
-
-// CacheOAuthToken stores an OAuth token with expiration
-func (c *Client) CacheOAuthToken(cacheKey, accessToken string, expiresIn int) {
-	c.oauthCacheLock.Lock()
-	defer c.oauthCacheLock.Unlock()
-
-	expiresAt := time.Now().Add(time.Duration(expiresIn) * time.Second)
-	c.oauthCache[cacheKey] = &cachedToken{
-		AccessToken: accessToken,
-		ExpiresAt:   expiresAt,
-	}
-
-	// SECURITY: Never log the actual token
-	c.logger.Debug("OAuth token cached",
-		slog.String("cache_key", cacheKey),
-		slog.Time("expires_at", expiresAt),
-	)
-}
-
-// ClearOAuthCache clears all cached OAuth tokens
-func (c *Client) ClearOAuthCache() {
-	c.oauthCacheLock.Lock()
-	defer c.oauthCacheLock.Unlock()
-
-	c.oauthCache = make(map[string]*cachedToken)
-	c.logger.Debug("OAuth token cache cleared")
-}
-
 // DoFormPost performs a form-encoded POST request (for OAuth token requests)
 func (c *Client) DoFormPost(ctx context.Context, targetURL string, formData url.Values, headers map[string]string) (*Response, error) {
+	host, err := c.allowRequest(targetURL)
+	if err != nil {
+		return nil, err
+	}
+
 	// Apply timeout
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
@@ -362,8 +372,9 @@ func (c *Client) DoFormPost(ctx context.Context, targetURL string, formData url.
 	duration := time.Since(startTime)
 
 	if err != nil {
+		c.recordResult(host, err, nil)
 		c.logger.Error("form POST failed",
-			slog.String("url", targetURL),
+			slog.String("url", redact.URL(targetURL)),
 			slog.Duration("duration", duration),
 			slog.String("error", err.Error()),
 		)
@@ -383,14 +394,20 @@ func (c *Client) DoFormPost(ctx context.Context, targetURL string, formData url.
 		Headers:    resp.Header,
 	}
 
+	var statusErr error
+	if resp.StatusCode >= 400 {
+		statusErr = &StatusError{StatusCode: resp.StatusCode, Body: TruncateBody(redact.Text(string(bodyBytes)), 200)}
+	}
+	c.recordResult(host, statusErr, response)
+
 	c.logger.Debug("form POST completed",
-		slog.String("url", targetURL),
+		slog.String("url", redact.URL(targetURL)),
 		slog.Int("status_code", resp.StatusCode),
 		slog.Duration("duration", duration),
 	)
 
-	if resp.StatusCode >= 400 {
-		return response, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, truncateBody(string(bodyBytes), 200))
+	if statusErr != nil {
+		return response, statusErr
 	}
 
 	return response, nil
@@ -0,0 +1,34 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_Do_StatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":"no such course"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	_, err := client.Do(context.Background(), RequestConfig{Method: http.MethodGet, URL: server.URL})
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Do() error = %v, want a StatusError", err)
+	}
+	if statusErr.StatusCode != http.StatusNotFound || statusErr.Body != `{"error":"no such course"}` {
+		t.Errorf("StatusError = %+v, want 404 with the response body", statusErr)
+	}
+	if !strings.Contains(err.Error(), "HTTP error 404") {
+		t.Errorf("Error() = %q, want it to contain %q", err.Error(), "HTTP error 404")
+	}
+}
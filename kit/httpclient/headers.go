@@ -0,0 +1,24 @@
+package httpclient
+
+import "fmt"
+
+// AddBearerToken adds a Bearer token to request headers
+func AddBearerToken(headers map[string]string, token string) map[string]string {
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	headers["Authorization"] = fmt.Sprintf("Bearer %s", token)
+	return headers
+}
+
+// AddAPIKey adds an API key to request headers
+func AddAPIKey(headers map[string]string, apiKey, headerName string) map[string]string {
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	if headerName == "" {
+		headerName = "X-API-Key"
+	}
+	headers[headerName] = apiKey
+	return headers
+}
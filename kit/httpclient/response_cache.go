@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultResponseCacheSize bounds the number of cached responses held by a warm Lambda
+const defaultResponseCacheSize = 256
+
+// cachedResponse is a response and when it stops being reused
+type cachedResponse struct {
+	response  Response
+	expiresAt time.Time
+}
+
+// ResponseCache is an in-memory TTL cache of GET responses keyed by URL.
+// It lives as long as the Lambda execution environment, so warm invocations share it.
+type ResponseCache struct {
+	mu         sync.Mutex
+	entries    map[string]cachedResponse
+	maxEntries int
+	now        func() time.Time
+}
+
+// NewResponseCache creates a response cache holding at most maxEntries responses
+func NewResponseCache(maxEntries int) *ResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultResponseCacheSize
+	}
+	return &ResponseCache{
+		entries:    make(map[string]cachedResponse),
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// Get returns a copy of the unexpired response cached for url
+func (c *ResponseCache) Get(url string) (*Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[url]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, url)
+		return nil, false
+	}
+
+	resp := entry.response
+	resp.Headers = entry.response.Headers.Clone()
+	return &resp, true
+}
+
+// Put caches a response for url until ttl elapses
+func (c *ResponseCache) Put(url string, resp *Response, ttl time.Duration) {
+	if resp == nil || ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, exists := c.entries[url]; !exists && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+
+	stored := *resp
+	stored.Headers = resp.Headers.Clone()
+	c.entries[url] = cachedResponse{response: stored, expiresAt: now.Add(ttl)}
+}
+
+// evict drops expired entries, or the entry closest to expiry if none have expired. Callers must hold mu.
+func (c *ResponseCache) evict(now time.Time) {
+	var oldestURL string
+	var oldest time.Time
+	for url, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, url)
+			continue
+		}
+		if oldestURL == "" || entry.expiresAt.Before(oldest) {
+			oldestURL, oldest = url, entry.expiresAt
+		}
+	}
+	if len(c.entries) >= c.maxEntries && oldestURL != "" {
+		delete(c.entries, oldestURL)
+	}
+}
+
+// Len returns the number of cached responses, including expired ones not yet evicted
+func (c *ResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCache_Expiry(t *testing.T) {
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewResponseCache(0)
+	cache.now = func() time.Time { return now }
+
+	cache.Put("https://api.weather.gov/a", &Response{StatusCode: 200, Body: "forecast"}, 30*time.Minute)
+
+	now = now.Add(29 * time.Minute)
+	if resp, ok := cache.Get("https://api.weather.gov/a"); !ok || resp.Body != "forecast" {
+		t.Fatalf("Get() = %v, %v; want cached forecast", resp, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("https://api.weather.gov/a"); ok {
+		t.Error("Get() should miss once the TTL has elapsed")
+	}
+}
+
+func TestResponseCache_Eviction(t *testing.T) {
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewResponseCache(2)
+	cache.now = func() time.Time { return now }
+
+	cache.Put("a", &Response{Body: "a"}, time.Minute)
+	cache.Put("b", &Response{Body: "b"}, time.Hour)
+	cache.Put("c", &Response{Body: "c"}, time.Hour)
+
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("the entry closest to expiry should have been evicted")
+	}
+	for _, url := range []string{"b", "c"} {
+		if _, ok := cache.Get(url); !ok {
+			t.Errorf("Get(%q) should hit", url)
+		}
+	}
+}
+
+func TestClient_Do_CachesGET(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"properties":{}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Do(ctx, RequestConfig{Method: http.MethodGet, URL: server.URL, CacheTTL: 30 * time.Minute}); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server calls = %d, want 1 with caching", got)
+	}
+
+	// Requests without a TTL always reach the server
+	if _, err := client.Do(ctx, RequestConfig{Method: http.MethodGet, URL: server.URL}); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server calls = %d, want 2", got)
+	}
+}
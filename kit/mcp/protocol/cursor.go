@@ -0,0 +1,33 @@
+package protocol
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// offsetCursorPrefix marks a cursor that holds a result offset
+const offsetCursorPrefix = "offset:"
+
+// EncodeOffsetCursor returns an opaque cursor for the page of results starting at offset
+func EncodeOffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(offsetCursorPrefix + strconv.Itoa(offset)))
+}
+
+// DecodeOffsetCursor returns the result offset a cursor from EncodeOffsetCursor points to; an
+// empty cursor is the first page
+func DecodeOffsetCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor: %q", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), offsetCursorPrefix))
+	if err != nil || !strings.HasPrefix(string(raw), offsetCursorPrefix) || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor: %q", cursor)
+	}
+	return offset, nil
+}
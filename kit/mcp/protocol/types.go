@@ -127,9 +127,33 @@ type MCPSamplingCapability struct{}
 
 // Tool represents an MCP tool definition
 type Tool struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description,omitempty"`
-	InputSchema InputSchema `json:"inputSchema"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	InputSchema InputSchema      `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations describes how a tool behaves so clients need not special-case tool names
+type ToolAnnotations struct {
+	// Terminal marks a tool whose call completes an agent conversation (rez_agent extension)
+	Terminal bool `json:"terminal,omitempty"`
+
+	// Aliases are alternative names the tool can be called by (rez_agent extension)
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// IsTerminal reports whether calling the tool completes an agent conversation
+func (t Tool) IsTerminal() bool {
+	return t.Annotations != nil && t.Annotations.Terminal
+}
+
+// Names returns the tool's name followed by its aliases
+func (t Tool) Names() []string {
+	names := []string{t.Name}
+	if t.Annotations != nil {
+		names = append(names, t.Annotations.Aliases...)
+	}
+	return names
 }
 
 // InputSchema represents a JSON Schema for tool input validation
@@ -149,6 +173,11 @@ type Property struct {
 	Minimum     *int        `json:"minimum,omitempty"`
 	Maximum     *int        `json:"maximum,omitempty"`
 	Default     interface{} `json:"default,omitempty"`
+	// Items describes the elements of an array property
+	Items *Property `json:"items,omitempty"`
+	// Properties and Required describe the fields of an object property
+	Properties map[string]Property `json:"properties,omitempty"`
+	Required   []string            `json:"required,omitempty"`
 }
 
 // ToolsListRequest represents a request to list available tools
@@ -171,6 +200,8 @@ type ToolCallRequest struct {
 type ToolCallResult struct {
 	Content []Content `json:"content"`
 	IsError bool      `json:"isError,omitempty"`
+	// NextCursor is set when the tool has more results; pass it back as the cursor argument
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // Content represents content in a tool result
@@ -190,6 +221,23 @@ func NewTextContent(text string) Content {
 	}
 }
 
+// JSONMimeType marks a text content item whose text is a JSON document
+const JSONMimeType = "application/json"
+
+// NewJSONContent creates a text content item holding v as JSON, for clients that act on
+// structured data. It is still a text item, so clients that ignore mimeType can read it.
+func NewJSONContent(v interface{}) (Content, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return Content{}, fmt.Errorf("failed to marshal JSON content: %w", err)
+	}
+	return Content{
+		Type:     "text",
+		Text:     string(body),
+		MimeType: JSONMimeType,
+	}, nil
+}
+
 // NewErrorContent creates a new error content item
 func NewErrorContent(errorMsg string) Content {
 	return Content{
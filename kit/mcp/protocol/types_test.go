@@ -225,6 +225,23 @@ func TestNewTextContent(t *testing.T) {
 	}
 }
 
+func TestNewJSONContent(t *testing.T) {
+	content, err := NewJSONContent(map[string]int{"precip_chance": 40})
+	if err != nil {
+		t.Fatalf("NewJSONContent() error = %v", err)
+	}
+	if content.Type != "text" || content.MimeType != JSONMimeType {
+		t.Errorf("Type = %v, MimeType = %v, want text with %v", content.Type, content.MimeType, JSONMimeType)
+	}
+	if content.Text != `{"precip_chance":40}` {
+		t.Errorf("Text = %v", content.Text)
+	}
+
+	if _, err := NewJSONContent(make(chan int)); err == nil {
+		t.Error("expected an error for a value that cannot be marshaled")
+	}
+}
+
 func TestTool_Validation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -297,3 +314,21 @@ func TestErrorCodes(t *testing.T) {
 		})
 	}
 }
+
+func TestOffsetCursor(t *testing.T) {
+	for _, offset := range []int{0, 5, 120} {
+		got, err := DecodeOffsetCursor(EncodeOffsetCursor(offset))
+		if err != nil || got != offset {
+			t.Errorf("DecodeOffsetCursor(EncodeOffsetCursor(%d)) = %d, %v; want the offset back", offset, got, err)
+		}
+	}
+
+	if got, err := DecodeOffsetCursor(""); err != nil || got != 0 {
+		t.Errorf("DecodeOffsetCursor(\"\") = %d, %v; want the first page", got, err)
+	}
+	for _, cursor := range []string{"not base64!", "b2Zmc2V0Oi0x", "cGFnZToz"} {
+		if _, err := DecodeOffsetCursor(cursor); err == nil {
+			t.Errorf("DecodeOffsetCursor(%q) should fail", cursor)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+const (
+	// DefaultToolTimeout bounds a tool call that has no timeout of its own
+	DefaultToolTimeout = 20 * time.Second
+
+	// responseReserve is kept back from the request deadline so a timed-out tool still gets a response out
+	responseReserve = 2 * time.Second
+)
+
+// requestIDKey carries the JSON-RPC request ID through the handler context
+type requestIDKey struct{}
+
+// withRequestID returns a context carrying the JSON-RPC request ID
+func withRequestID(ctx context.Context, id interface{}) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the JSON-RPC ID of the request being handled, or nil for notifications
+func RequestIDFromContext(ctx context.Context) interface{} {
+	return ctx.Value(requestIDKey{})
+}
+
+// inflightCalls tracks running tool calls by request ID so they can be cancelled
+type inflightCalls struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelCauseFunc
+}
+
+func newInflightCalls() *inflightCalls {
+	return &inflightCalls{cancels: make(map[string]context.CancelCauseFunc)}
+}
+
+// requestKey normalizes a JSON-RPC ID, which may be a string or a number
+func requestKey(id interface{}) string {
+	return fmt.Sprintf("%v", id)
+}
+
+// start registers a call and returns its context; done must be called when the call finishes
+func (c *inflightCalls) start(ctx context.Context, id interface{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if id == nil {
+		return ctx, func() { cancel(nil) }
+	}
+
+	key := requestKey(id)
+	c.mu.Lock()
+	c.cancels[key] = cancel
+	c.mu.Unlock()
+
+	return ctx, func() {
+		c.mu.Lock()
+		delete(c.cancels, key)
+		c.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancel stops a running call, reporting whether one was found
+func (c *inflightCalls) cancel(id interface{}, reason string) bool {
+	c.mu.Lock()
+	cancel, ok := c.cancels[requestKey(id)]
+	c.mu.Unlock()
+	if ok {
+		cancel(fmt.Errorf("cancelled by client: %s", reason))
+	}
+	return ok
+}
+
+// cancelParams are the parameters of notifications/cancelled and $/cancelRequest
+type cancelParams struct {
+	RequestID interface{} `json:"requestId"`
+	ID        interface{} `json:"id"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// handleCancel stops an in-flight tool call. MCP clients send notifications/cancelled with a
+// requestId; $/cancelRequest with an id is accepted for clients that use the LSP-style name.
+// Cancelling a request that already finished is not an error.
+func (s *MCPServer) handleCancel(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var req cancelParams
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, protocol.NewJSONRPCError(protocol.ErrCodeInvalidParams,
+			"Invalid cancellation parameters", err.Error())
+	}
+
+	id := req.RequestID
+	if id == nil {
+		id = req.ID
+	}
+	if id == nil {
+		return nil, protocol.NewJSONRPCError(protocol.ErrCodeInvalidParams,
+			"Invalid cancellation parameters", "requestId is required")
+	}
+
+	cancelled := s.inflight.cancel(id, req.Reason)
+	s.logger.InfoContext(ctx, "cancellation requested",
+		slog.Any("request_id", id),
+		slog.String("reason", req.Reason),
+		slog.Bool("in_flight", cancelled),
+	)
+
+	return map[string]bool{"cancelled": cancelled}, nil
+}
+
+// SetDefaultToolTimeout changes the timeout for tools without their own
+func (s *MCPServer) SetDefaultToolTimeout(timeout time.Duration) {
+	s.defaultToolTimeout = timeout
+}
+
+// SetToolTimeout sets the timeout for one tool, e.g. a longer one for booking
+func (s *MCPServer) SetToolTimeout(name string, timeout time.Duration) {
+	s.toolTimeouts[name] = timeout
+}
+
+// toolTimeout returns how long a tool call may run, capped so a response can still be written
+// before the request's own deadline (the Lambda timeout)
+func (s *MCPServer) toolTimeout(ctx context.Context, name string) time.Duration {
+	timeout, ok := s.toolTimeouts[name]
+	if !ok {
+		timeout = s.defaultToolTimeout
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline) - responseReserve; remaining < timeout {
+			timeout = max(remaining, 0)
+		}
+	}
+	return timeout
+}
+
+// executeTool runs a tool under its timeout and cancellation. A tool that ignores its context is
+// abandoned when the deadline passes so the caller still gets a timely error result.
+func (s *MCPServer) executeTool(ctx context.Context, name string, tool Tool, args map[string]interface{}) ([]protocol.Content, error) {
+	ctx, done := s.inflight.start(ctx, RequestIDFromContext(ctx))
+	defer done()
+
+	timeout := s.toolTimeout(ctx, name)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		content []protocol.Content
+		err     error
+	}
+	result := make(chan outcome, 1)
+	go func() {
+		content, err := tool.Execute(ctx, args)
+		result <- outcome{content, err}
+	}()
+
+	select {
+	case out := <-result:
+		return out.content, out.err
+	case <-ctx.Done():
+		if cause := context.Cause(ctx); cause != nil && cause != context.DeadlineExceeded && cause != context.Canceled {
+			return nil, cause
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("tool %s timed out after %s", name, timeout.Round(time.Millisecond))
+		}
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// newInitializedServer returns a server with a blocking tool that only returns when its context ends
+func newInitializedServer(t *testing.T, ignoreContext bool) *MCPServer {
+	t.Helper()
+	server := NewMCPServer("test-server", "1.0.0", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := server.HandleRequest(context.Background(), []byte(`{"jsonrpc":"2.0","id":"0","method":"initialize","params":{}}`)); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	server.RegisterTool(&MockTool{
+		name: "slow_tool",
+		executeFunc: func(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+			if ignoreContext {
+				time.Sleep(time.Second)
+				return []protocol.Content{protocol.NewTextContent("too late")}, nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	})
+	return server
+}
+
+// callSlowTool calls slow_tool with the given request ID and returns the tool result
+func callSlowTool(t *testing.T, ctx context.Context, server *MCPServer, id string) protocol.ToolCallResult {
+	t.Helper()
+	request := `{"jsonrpc":"2.0","id":"` + id + `","method":"tools/call","params":{"name":"slow_tool","arguments":{"test_param":"x"}}}`
+	data, err := server.HandleRequest(ctx, []byte(request))
+	if err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+
+	var response protocol.JSONRPCResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	var result protocol.ToolCallResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+	return result
+}
+
+// isInflight reports whether a call with the request ID is registered without cancelling it
+func isInflight(server *MCPServer, id string) bool {
+	server.inflight.mu.Lock()
+	defer server.inflight.mu.Unlock()
+	_, ok := server.inflight.cancels[id]
+	return ok
+}
+
+func TestMCPServer_ToolTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		ignoreContext bool
+	}{
+		{"tool honors its context", false},
+		{"tool ignores its context", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newInitializedServer(t, tt.ignoreContext)
+			server.SetToolTimeout("slow_tool", 20*time.Millisecond)
+
+			start := time.Now()
+			result := callSlowTool(t, context.Background(), server, "1")
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("call took %s, want it bounded by the tool timeout", elapsed)
+			}
+			if !result.IsError || !strings.Contains(result.Content[0].Text, "timed out") {
+				t.Errorf("result = %+v, want a timed out error", result)
+			}
+		})
+	}
+}
+
+func TestMCPServer_ToolTimeoutCappedByDeadline(t *testing.T) {
+	server := newInitializedServer(t, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if got := server.toolTimeout(ctx, "slow_tool"); got > 3*time.Second {
+		t.Errorf("toolTimeout() = %s, want at most the deadline less the response reserve", got)
+	}
+	if got := server.toolTimeout(context.Background(), "slow_tool"); got != DefaultToolTimeout {
+		t.Errorf("toolTimeout() without deadline = %s, want %s", got, DefaultToolTimeout)
+	}
+}
+
+func TestMCPServer_CancelRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		notice string
+	}{
+		{"notifications/cancelled", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"7","reason":"user aborted"}}`},
+		{"$/cancelRequest", `{"jsonrpc":"2.0","method":"$/cancelRequest","params":{"id":"7"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newInitializedServer(t, false)
+
+			results := make(chan protocol.ToolCallResult, 1)
+			go func() {
+				results <- callSlowTool(t, context.Background(), server, "7")
+			}()
+
+			// Wait until the call is registered as in flight
+			deadline := time.Now().Add(time.Second)
+			for !isInflight(server, "7") {
+				if time.Now().After(deadline) {
+					t.Fatal("tool call never became cancellable")
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			// The notification itself gets no response
+			response, err := server.HandleRequest(context.Background(), []byte(tt.notice))
+			if err != nil || response != nil {
+				t.Errorf("notification response = %q, %v, want none", response, err)
+			}
+
+			select {
+			case result := <-results:
+				if !result.IsError || !strings.Contains(result.Content[0].Text, "cancelled") {
+					t.Errorf("result = %+v, want a cancelled error", result)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("cancelled call did not return")
+			}
+		})
+	}
+}
+
+func TestMCPServer_CancelUnknownRequest(t *testing.T) {
+	server := newInitializedServer(t, false)
+
+	// Sent as a request, the cancellation gets a response reporting nothing was in flight
+	data, err := server.HandleRequest(context.Background(), []byte(`{"jsonrpc":"2.0","id":"2","method":"$/cancelRequest","params":{"id":"missing"}}`))
+	if err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"cancelled":false`) {
+		t.Errorf("response = %s, want cancelled false", data)
+	}
+}
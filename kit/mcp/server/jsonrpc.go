@@ -5,8 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 
-	"github.com/jrzesz33/rez_agent/internal/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
 )
 
 // MethodHandler is a function that handles a JSON-RPC method call
@@ -39,7 +40,7 @@ func (s *JSONRPCServer) HandleRequest(ctx context.Context, requestData []byte) (
 	// Parse the request
 	var req protocol.JSONRPCRequest
 	if err := json.Unmarshal(requestData, &req); err != nil {
-		s.logger.Error("failed to parse JSON-RPC request",
+		s.logger.ErrorContext(ctx, "failed to parse JSON-RPC request",
 			slog.String("error", err.Error()),
 		)
 		return s.errorResponse(nil, protocol.ErrCodeParseError, "Parse error", nil)
@@ -59,7 +60,7 @@ func (s *JSONRPCServer) HandleRequest(ctx context.Context, requestData []byte) (
 	// Find handler
 	handler, exists := s.methods[req.Method]
 	if !exists {
-		s.logger.Warn("method not found",
+		s.logger.WarnContext(ctx, "method not found",
 			slog.String("method", req.Method),
 		)
 		return s.errorResponse(req.ID, protocol.ErrCodeMethodNotFound,
@@ -67,14 +68,26 @@ func (s *JSONRPCServer) HandleRequest(ctx context.Context, requestData []byte) (
 	}
 
 	// Execute handler
-	s.logger.Debug("executing JSON-RPC method",
+	s.logger.DebugContext(ctx, "executing JSON-RPC method",
 		slog.String("method", req.Method),
 		slog.Any("id", req.ID),
 	)
 
-	result, err := handler(ctx, req.Params)
+	result, err := handler(withRequestID(ctx, req.ID), req.Params)
+
+	// Notifications get no response, whatever the outcome
+	if isNotification(req) {
+		if err != nil {
+			s.logger.WarnContext(ctx, "notification handling failed",
+				slog.String("method", req.Method),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, nil
+	}
+
 	if err != nil {
-		s.logger.Error("method execution failed",
+		s.logger.ErrorContext(ctx, "method execution failed",
 			slog.String("method", req.Method),
 			slog.String("error", err.Error()),
 		)
@@ -140,18 +153,33 @@ func (s *JSONRPCServer) HandleBatch(ctx context.Context, requestData []byte) ([]
 	for _, reqData := range requests {
 		respData, err := s.HandleRequest(ctx, reqData)
 		if err != nil {
-			s.logger.Error("failed to process batch request",
+			s.logger.ErrorContext(ctx, "failed to process batch request",
 				slog.String("error", err.Error()),
 			)
 			continue
 		}
+		if respData == nil {
+			continue
+		}
 		responses = append(responses, respData)
 	}
 
+	// A batch of only notifications gets no response
+	if len(responses) == 0 {
+		return nil, nil
+	}
+
 	// Return batch response
 	return json.Marshal(responses)
 }
 
+// isNotification reports whether a request is a notification, which the client expects no response to.
+// Only the notifications/ and $/ methods are treated this way so older clients that omit IDs on
+// ordinary requests still get responses.
+func isNotification(req protocol.JSONRPCRequest) bool {
+	return req.ID == nil && (strings.HasPrefix(req.Method, "notifications/") || strings.HasPrefix(req.Method, "$/"))
+}
+
 // IsBatchRequest checks if the request data represents a batch request
 func IsBatchRequest(data []byte) bool {
 	// Quick check: does it start with '['?
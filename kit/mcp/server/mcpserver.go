@@ -3,27 +3,33 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
-	"github.com/jrzesz33/rez_agent/internal/mcp/protocol"
-	"github.com/jrzesz33/rez_agent/internal/mcp/tools"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
 )
 
 // MCPServer implements the Model Context Protocol server
 type MCPServer struct {
 	jsonrpcServer *JSONRPCServer
-	toolRegistry  *tools.Registry
+	toolRegistry  *Registry
 	serverInfo    protocol.MCPServerInfo
 	logger        *slog.Logger
 	initialized   bool
+
+	inflight           *inflightCalls
+	defaultToolTimeout time.Duration
+	toolTimeouts       map[string]time.Duration
+	middleware         []Middleware
 }
 
 // NewMCPServer creates a new MCP server
 func NewMCPServer(name, version string, logger *slog.Logger) *MCPServer {
 	server := &MCPServer{
 		jsonrpcServer: NewJSONRPCServer(logger),
-		toolRegistry:  tools.NewRegistry(logger),
+		toolRegistry:  NewRegistry(logger),
 		serverInfo: protocol.MCPServerInfo{
 			Name:            name,
 			Version:         version,
@@ -34,10 +40,13 @@ func NewMCPServer(name, version string, logger *slog.Logger) *MCPServer {
 				},
 				Logging: &protocol.MCPLoggingCapability{},
 			},
-			Instructions: "This is the rez_agent MCP server. It provides tools for push notifications, weather information, and golf course operations.",
 		},
-		logger:      logger,
-		initialized: false,
+		logger:             logger,
+		initialized:        false,
+		inflight:           newInflightCalls(),
+		defaultToolTimeout: DefaultToolTimeout,
+		toolTimeouts:       make(map[string]time.Duration),
+		middleware:         []Middleware{Logging(logger)},
 	}
 
 	// Register MCP protocol methods
@@ -52,10 +61,17 @@ func (s *MCPServer) registerMethods() {
 	s.jsonrpcServer.RegisterMethod("tools/list", s.handleToolsList)
 	s.jsonrpcServer.RegisterMethod("tools/call", s.handleToolsCall)
 	s.jsonrpcServer.RegisterMethod("ping", s.handlePing)
+	s.jsonrpcServer.RegisterMethod("notifications/cancelled", s.handleCancel)
+	s.jsonrpcServer.RegisterMethod("$/cancelRequest", s.handleCancel)
+}
+
+// SetInstructions sets the usage instructions returned to clients on initialize
+func (s *MCPServer) SetInstructions(instructions string) {
+	s.serverInfo.Instructions = instructions
 }
 
 // RegisterTool registers a tool with the server
-func (s *MCPServer) RegisterTool(tool tools.Tool) error {
+func (s *MCPServer) RegisterTool(tool Tool) error {
 	return s.toolRegistry.Register(tool)
 }
 
@@ -80,7 +96,7 @@ func (s *MCPServer) handleInitialize(ctx context.Context, params json.RawMessage
 		}
 	}
 
-	s.logger.Info("initialize request received",
+	s.logger.InfoContext(ctx, "initialize request received",
 		slog.String("client_name", req.ClientInfo.Name),
 		slog.String("client_version", req.ClientInfo.Version),
 		slog.String("protocol_version", req.ProtocolVersion),
@@ -88,7 +104,7 @@ func (s *MCPServer) handleInitialize(ctx context.Context, params json.RawMessage
 
 	// Validate protocol version compatibility
 	if req.ProtocolVersion != "" && req.ProtocolVersion != protocol.MCPVersion {
-		s.logger.Warn("protocol version mismatch",
+		s.logger.WarnContext(ctx, "protocol version mismatch",
 			slog.String("requested", req.ProtocolVersion),
 			slog.String("supported", protocol.MCPVersion),
 		)
@@ -122,7 +138,7 @@ func (s *MCPServer) handleToolsList(ctx context.Context, params json.RawMessage)
 		}
 	}
 
-	s.logger.Debug("tools/list request received")
+	s.logger.DebugContext(ctx, "tools/list request received")
 
 	toolsList := s.toolRegistry.ListTools()
 
@@ -130,7 +146,7 @@ func (s *MCPServer) handleToolsList(ctx context.Context, params json.RawMessage)
 		Tools: toolsList,
 	}
 
-	s.logger.Info("returning tools list",
+	s.logger.InfoContext(ctx, "returning tools list",
 		slog.Int("tool_count", len(toolsList)),
 	)
 
@@ -150,7 +166,7 @@ func (s *MCPServer) handleToolsCall(ctx context.Context, params json.RawMessage)
 			"Invalid tools/call parameters", err.Error())
 	}
 
-	s.logger.Info("tools/call request received",
+	s.logger.InfoContext(ctx, "tools/call request received",
 		slog.String("tool_name", req.Name),
 	)
 
@@ -161,19 +177,14 @@ func (s *MCPServer) handleToolsCall(ctx context.Context, params json.RawMessage)
 			fmt.Sprintf("Tool not found: %s", req.Name), nil)
 	}
 
-	// Validate input
-	if err := tool.ValidateInput(req.Arguments); err != nil {
-		return nil, protocol.NewJSONRPCError(protocol.ErrCodeInvalidParams,
-			fmt.Sprintf("Invalid tool input: %v", err), nil)
-	}
-
-	// Execute the tool
-	content, err := tool.Execute(ctx, req.Arguments)
+	// Validate input and execute the tool under its timeout, inside the middleware chain
+	call := &ToolCall{Name: req.Name, Arguments: req.Arguments, Tool: tool, InputSize: len(params)}
+	content, err := s.toolHandler()(withToolCall(ctx, call), call)
 	if err != nil {
-		s.logger.Error("tool execution failed",
-			slog.String("tool_name", req.Name),
-			slog.String("error", err.Error()),
-		)
+		var rpcErr *protocol.JSONRPCError
+		if errors.As(err, &rpcErr) {
+			return nil, rpcErr
+		}
 
 		// Return error as content
 		result := protocol.ToolCallResult{
@@ -185,14 +196,14 @@ func (s *MCPServer) handleToolsCall(ctx context.Context, params json.RawMessage)
 		return result, nil
 	}
 
-	s.logger.Info("tool executed successfully",
-		slog.String("tool_name", req.Name),
-	)
-
 	result := protocol.ToolCallResult{
 		Content: content,
 		IsError: false,
 	}
+	if cursor := call.NextCursor(); cursor != "" {
+		result.Content = withCursorHint(result.Content, req.Name, cursor)
+		result.NextCursor = cursor
+	}
 
 	return result, nil
 }
@@ -7,7 +7,7 @@ import (
 	"os"
 	"testing"
 
-	"github.com/jrzesz33/rez_agent/internal/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
 )
 
 // MockTool is a test implementation of the Tool interface
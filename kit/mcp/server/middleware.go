@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// ToolCall is a tools/call request on its way through the middleware chain to its tool
+type ToolCall struct {
+	// Name is the tool name the client asked for, which may be an alias
+	Name string
+
+	// Arguments are the call's input; middleware may inspect or adjust them
+	Arguments map[string]interface{}
+
+	// Tool is the registered tool that will run
+	Tool Tool
+
+	// InputSize is the size in bytes of the call's JSON-encoded parameters
+	InputSize int
+
+	// nextCursor is the cursor the tool set with SetNextCursor
+	nextCursor atomic.Pointer[string]
+}
+
+// ToolHandler runs a tool call and returns the tool's content
+type ToolHandler func(ctx context.Context, call *ToolCall) ([]protocol.Content, error)
+
+// Middleware wraps every tools/call with a cross-cutting concern such as logging or limits.
+// Returning a *protocol.JSONRPCError rejects the call with that error; any other error becomes
+// an error result the client's model can read, the same as a failed tool.
+type Middleware func(next ToolHandler) ToolHandler
+
+// Use adds middleware around every tools/call. Middleware runs in the order it was added, the
+// first outermost; the server's own logging middleware runs before all of it.
+func (s *MCPServer) Use(middleware ...Middleware) {
+	s.middleware = append(s.middleware, middleware...)
+}
+
+// toolHandler builds the middleware chain around runTool
+func (s *MCPServer) toolHandler() ToolHandler {
+	handler := s.runTool
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		handler = s.middleware[i](handler)
+	}
+	return handler
+}
+
+// runTool validates the call's input and runs the tool under its timeout; it is the end of the
+// middleware chain
+func (s *MCPServer) runTool(ctx context.Context, call *ToolCall) ([]protocol.Content, error) {
+	if err := call.Tool.ValidateInput(call.Arguments); err != nil {
+		return nil, protocol.NewJSONRPCError(protocol.ErrCodeInvalidParams,
+			fmt.Sprintf("Invalid tool input: %v", err), nil)
+	}
+	return s.executeTool(ctx, call.Name, call.Tool, call.Arguments)
+}
+
+// Logging logs the outcome and duration of every tool call
+func Logging(logger *slog.Logger) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) ([]protocol.Content, error) {
+			start := time.Now()
+			content, err := next(ctx, call)
+			duration := time.Since(start)
+
+			var rpcErr *protocol.JSONRPCError
+			switch {
+			case errors.As(err, &rpcErr):
+				logger.WarnContext(ctx, "tool call rejected",
+					slog.String("tool_name", call.Name),
+					slog.Int("code", rpcErr.Code),
+					slog.String("error", rpcErr.Message),
+				)
+			case err != nil:
+				logger.ErrorContext(ctx, "tool execution failed",
+					slog.String("tool_name", call.Name),
+					slog.Duration("duration", duration),
+					slog.String("error", err.Error()),
+				)
+			default:
+				logger.InfoContext(ctx, "tool executed successfully",
+					slog.String("tool_name", call.Name),
+					slog.Duration("duration", duration),
+				)
+			}
+			return content, err
+		}
+	}
+}
+
+// Authorize rejects tool calls that authorize refuses, e.g. tools a caller's credentials do not
+// cover. The refusal's message is returned to the client as the error's data.
+func Authorize(authorize func(ctx context.Context, call *ToolCall) error) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) ([]protocol.Content, error) {
+			if err := authorize(ctx, call); err != nil {
+				return nil, protocol.NewJSONRPCError(protocol.ErrCodeAuthFailure,
+					fmt.Sprintf("Not authorized to call tool: %s", call.Name), err.Error())
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+// MaxInputSize rejects tool calls whose parameters are larger than limit bytes before the tool
+// parses them
+func MaxInputSize(limit int) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) ([]protocol.Content, error) {
+			if call.InputSize > limit {
+				return nil, protocol.NewJSONRPCError(protocol.ErrCodeInvalidParams,
+					fmt.Sprintf("Tool input too large: %d bytes, limit is %d", call.InputSize, limit), nil)
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+// tokenBucket holds the calls one tool may still make
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimit allows each tool at most limit calls per period, counting a tool's aliases together.
+// Calls over the limit fail with an error result so the model can wait or move on. The limit is
+// kept in memory, so it applies per server instance.
+func RateLimit(limit int, per time.Duration) Middleware {
+	var mu sync.Mutex
+	buckets := make(map[string]*tokenBucket)
+	refill := float64(limit) / per.Seconds()
+
+	allow := func(name string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+		bucket, ok := buckets[name]
+		if !ok {
+			bucket = &tokenBucket{tokens: float64(limit), last: now}
+			buckets[name] = bucket
+		}
+		bucket.tokens = min(float64(limit), bucket.tokens+now.Sub(bucket.last).Seconds()*refill)
+		bucket.last = now
+		if bucket.tokens < 1 {
+			return false
+		}
+		bucket.tokens--
+		return true
+	}
+
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) ([]protocol.Content, error) {
+			if !allow(call.Tool.GetDefinition().Name) {
+				return nil, fmt.Errorf("rate limit exceeded for tool %s: at most %d calls per %s", call.Name, limit, per)
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+// ToolCallRecorder receives the duration and outcome of every tool call
+type ToolCallRecorder interface {
+	RecordToolCall(ctx context.Context, name string, duration time.Duration, failed bool)
+}
+
+// Metrics reports every tool call to recorder; calls rejected by the server, such as invalid
+// input, count as failed
+func Metrics(recorder ToolCallRecorder) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) ([]protocol.Content, error) {
+			start := time.Now()
+			content, err := next(ctx, call)
+			recorder.RecordToolCall(ctx, call.Tool.GetDefinition().Name, time.Since(start), err != nil)
+			return content, err
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// callEchoTool calls echo_tool with the argument and returns the JSON-RPC response
+func callEchoTool(t *testing.T, server *MCPServer, param string) protocol.JSONRPCResponse {
+	t.Helper()
+	request := `{"jsonrpc":"2.0","id":"1","method":"tools/call","params":{"name":"echo_tool","arguments":{"test_param":"` + param + `"}}}`
+	data, err := server.HandleRequest(context.Background(), []byte(request))
+	if err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+
+	var response protocol.JSONRPCResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	return response
+}
+
+// newEchoServer returns an initialized server with echo_tool registered
+func newEchoServer(t *testing.T) *MCPServer {
+	t.Helper()
+	server := NewMCPServer("test-server", "1.0.0", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := server.HandleRequest(context.Background(), []byte(`{"jsonrpc":"2.0","id":"0","method":"initialize","params":{}}`)); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	server.RegisterTool(&MockTool{
+		name: "echo_tool",
+		executeFunc: func(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+			return []protocol.Content{protocol.NewTextContent(args["test_param"].(string))}, nil
+		},
+	})
+	return server
+}
+
+// toolResult decodes a successful response's tool result
+func toolResult(t *testing.T, response protocol.JSONRPCResponse) protocol.ToolCallResult {
+	t.Helper()
+	if response.Error != nil {
+		t.Fatalf("response error = %+v, want a result", response.Error)
+	}
+	var result protocol.ToolCallResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+	return result
+}
+
+func TestMCPServer_UseOrder(t *testing.T) {
+	server := newEchoServer(t)
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next ToolHandler) ToolHandler {
+			return func(ctx context.Context, call *ToolCall) ([]protocol.Content, error) {
+				order = append(order, name)
+				call.Arguments["test_param"] = call.Arguments["test_param"].(string) + "+" + name
+				return next(ctx, call)
+			}
+		}
+	}
+	server.Use(trace("outer"), trace("inner"))
+
+	result := toolResult(t, callEchoTool(t, server, "x"))
+	if got := strings.Join(order, ","); got != "outer,inner" {
+		t.Errorf("middleware ran in order %s, want outer,inner", got)
+	}
+	if result.Content[0].Text != "x+outer+inner" {
+		t.Errorf("tool saw %q, want the arguments adjusted by both middleware", result.Content[0].Text)
+	}
+}
+
+func TestMiddleware_Rejections(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware Middleware
+		param      string
+		wantCode   int
+	}{
+		{
+			name: "authorize refuses",
+			middleware: Authorize(func(ctx context.Context, call *ToolCall) error {
+				return errors.New("key does not cover echo_tool")
+			}),
+			param:    "x",
+			wantCode: protocol.ErrCodeAuthFailure,
+		},
+		{
+			name:       "input too large",
+			middleware: MaxInputSize(64),
+			param:      strings.Repeat("x", 100),
+			wantCode:   protocol.ErrCodeInvalidParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEchoServer(t)
+			server.Use(tt.middleware)
+
+			response := callEchoTool(t, server, tt.param)
+			if response.Error == nil || response.Error.Code != tt.wantCode {
+				t.Errorf("response error = %+v, want code %d", response.Error, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("input within limit", func(t *testing.T) {
+		server := newEchoServer(t)
+		server.Use(MaxInputSize(1024))
+		if result := toolResult(t, callEchoTool(t, server, "x")); result.IsError {
+			t.Errorf("result = %+v, want the call to run", result)
+		}
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	server := newEchoServer(t)
+	server.Use(RateLimit(2, time.Hour))
+
+	for i := 0; i < 2; i++ {
+		if result := toolResult(t, callEchoTool(t, server, "x")); result.IsError {
+			t.Fatalf("call %d result = %+v, want it within the limit", i+1, result)
+		}
+	}
+
+	result := toolResult(t, callEchoTool(t, server, "x"))
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "rate limit exceeded") {
+		t.Errorf("third call result = %+v, want a rate limit error result", result)
+	}
+}
+
+type recordedToolCall struct {
+	name   string
+	failed bool
+}
+
+type fakeToolCallRecorder struct {
+	calls []recordedToolCall
+}
+
+func (r *fakeToolCallRecorder) RecordToolCall(ctx context.Context, name string, duration time.Duration, failed bool) {
+	r.calls = append(r.calls, recordedToolCall{name, failed})
+}
+
+func TestMetrics(t *testing.T) {
+	server := newEchoServer(t)
+	recorder := &fakeToolCallRecorder{}
+	server.Use(Metrics(recorder))
+
+	callEchoTool(t, server, "x")
+	if _, err := server.HandleRequest(context.Background(), []byte(`{"jsonrpc":"2.0","id":"2","method":"tools/call","params":{"name":"echo_tool","arguments":{}}}`)); err != nil {
+		t.Fatalf("HandleRequest() error = %v", err)
+	}
+
+	want := []recordedToolCall{{"echo_tool", false}, {"echo_tool", true}}
+	if len(recorder.calls) != len(want) || recorder.calls[0] != want[0] || recorder.calls[1] != want[1] {
+		t.Errorf("recorded calls = %+v, want %+v", recorder.calls, want)
+	}
+}
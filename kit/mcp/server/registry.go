@@ -1,11 +1,11 @@
-package tools
+package server
 
 import (
 	"context"
 	"fmt"
 	"log/slog"
 
-	"github.com/jrzesz33/rez_agent/internal/mcp/protocol"
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
 )
 
 // Tool represents an MCP tool that can be executed
@@ -22,15 +22,17 @@ type Tool interface {
 
 // Registry manages available MCP tools
 type Registry struct {
-	tools  map[string]Tool
-	logger *slog.Logger
+	tools   map[string]Tool
+	aliases map[string]string // alias -> tool name
+	logger  *slog.Logger
 }
 
 // NewRegistry creates a new tool registry
 func NewRegistry(logger *slog.Logger) *Registry {
 	return &Registry{
-		tools:  make(map[string]Tool),
-		logger: logger,
+		tools:   make(map[string]Tool),
+		aliases: make(map[string]string),
+		logger:  logger,
 	}
 }
 
@@ -38,11 +40,22 @@ func NewRegistry(logger *slog.Logger) *Registry {
 func (r *Registry) Register(tool Tool) error {
 	definition := tool.GetDefinition()
 
-	if _, exists := r.tools[definition.Name]; exists {
-		return fmt.Errorf("tool already registered: %s", definition.Name)
+	for _, name := range definition.Names() {
+		if _, exists := r.tools[name]; exists {
+			return fmt.Errorf("tool already registered: %s", name)
+		}
+		if _, exists := r.aliases[name]; exists {
+			return fmt.Errorf("tool already registered: %s", name)
+		}
 	}
 
 	r.tools[definition.Name] = tool
+	for _, alias := range definition.Names()[1:] {
+		if alias == definition.Name {
+			continue
+		}
+		r.aliases[alias] = definition.Name
+	}
 	r.logger.Info("registered MCP tool",
 		slog.String("tool_name", definition.Name),
 		slog.String("description", definition.Description),
@@ -51,8 +64,12 @@ func (r *Registry) Register(tool Tool) error {
 	return nil
 }
 
-// GetTool retrieves a tool by name
+// GetTool retrieves a tool by name or alias
 func (r *Registry) GetTool(name string) (Tool, error) {
+	if canonical, ok := r.aliases[name]; ok {
+		name = canonical
+	}
+
 	tool, exists := r.tools[name]
 	if !exists {
 		return nil, fmt.Errorf("tool not found: %s", name)
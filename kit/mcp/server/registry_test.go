@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// stubTool is a minimal tool with a configurable definition
+type stubTool struct {
+	definition protocol.Tool
+}
+
+func (s *stubTool) GetDefinition() protocol.Tool { return s.definition }
+
+func (s *stubTool) ValidateInput(args map[string]interface{}) error { return nil }
+
+func (s *stubTool) Execute(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+	return []protocol.Content{protocol.NewTextContent(s.definition.Name)}, nil
+}
+
+func TestRegistry_Aliases(t *testing.T) {
+	registry := NewRegistry(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	notify := &stubTool{definition: protocol.Tool{
+		Name:        "send_push_notification",
+		Annotations: &protocol.ToolAnnotations{Terminal: true, Aliases: []string{"send_notification"}},
+	}}
+	if err := registry.Register(notify); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	for _, name := range []string{"send_push_notification", "send_notification"} {
+		tool, err := registry.GetTool(name)
+		if err != nil {
+			t.Fatalf("GetTool(%q) error = %v", name, err)
+		}
+		if tool != notify {
+			t.Errorf("GetTool(%q) returned the wrong tool", name)
+		}
+	}
+
+	if got := len(registry.ListTools()); got != 1 {
+		t.Errorf("ListTools() returned %d tools, want 1 (aliases are not listed separately)", got)
+	}
+
+	tests := []struct {
+		name string
+		tool protocol.Tool
+	}{
+		{"name clashes with alias", protocol.Tool{Name: "send_notification"}},
+		{"alias clashes with name", protocol.Tool{Name: "notify", Annotations: &protocol.ToolAnnotations{Aliases: []string{"send_push_notification"}}}},
+		{"alias clashes with alias", protocol.Tool{Name: "notify", Annotations: &protocol.ToolAnnotations{Aliases: []string{"send_notification"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := registry.Register(&stubTool{definition: tt.tool}); err == nil {
+				t.Error("Register() should reject a duplicate name")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// toolCallKey carries the running ToolCall through the tool's context
+type toolCallKey struct{}
+
+// withToolCall returns a context carrying the tool call being handled
+func withToolCall(ctx context.Context, call *ToolCall) context.Context {
+	return context.WithValue(ctx, toolCallKey{}, call)
+}
+
+// SetNextCursor tells the client the tool has more results: the cursor is returned as the
+// result's nextCursor and mentioned in its text, for the client to pass back as the tool's cursor
+// argument. It does nothing outside a tools/call.
+func SetNextCursor(ctx context.Context, cursor string) {
+	if call, ok := ctx.Value(toolCallKey{}).(*ToolCall); ok {
+		call.nextCursor.Store(&cursor)
+	}
+}
+
+// NextCursor returns the cursor the tool set with SetNextCursor, if any
+func (c *ToolCall) NextCursor() string {
+	if cursor := c.nextCursor.Load(); cursor != nil {
+		return *cursor
+	}
+	return ""
+}
+
+// withCursorHint appends a note about the next page to the result's last text item, so models
+// that only read the text still learn how to continue
+func withCursorHint(content []protocol.Content, toolName, cursor string) []protocol.Content {
+	hint := fmt.Sprintf("More results are available: call %s again with cursor %q for the next page.", toolName, cursor)
+	for i := len(content) - 1; i >= 0; i-- {
+		if content[i].Type == "text" {
+			content[i].Text += "\n\n" + hint
+			return content
+		}
+	}
+	return append(content, protocol.NewTextContent(hint))
+}
+
+// MaxResultSize caps the text and data a tool call returns at limit bytes, so one large result
+// cannot crowd everything else out of the model's context. The item that crosses the limit is cut
+// short with a note saying so, and later items are dropped.
+func MaxResultSize(limit int) Middleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) ([]protocol.Content, error) {
+			content, err := next(ctx, call)
+			if err != nil {
+				return content, err
+			}
+			return truncateContent(content, limit), nil
+		}
+	}
+}
+
+// truncateContent keeps content within limit bytes of text and data
+func truncateContent(content []protocol.Content, limit int) []protocol.Content {
+	total := 0
+	for _, item := range content {
+		total += len(item.Text) + len(item.Data)
+	}
+	if total <= limit {
+		return content
+	}
+
+	note := fmt.Sprintf("\n\n[Result truncated to %d of %d bytes. Ask for fewer results or a narrower search.]", limit, total)
+	remaining := limit
+	var kept []protocol.Content
+	for _, item := range content {
+		size := len(item.Text) + len(item.Data)
+		if size <= remaining {
+			kept = append(kept, item)
+			remaining -= size
+			continue
+		}
+		// Binary data cannot be cut, so only a text item is shortened to fit
+		if item.Type == "text" {
+			item.Text = truncateUTF8(item.Text, remaining) + note
+			kept = append(kept, item)
+		} else {
+			kept = append(kept, protocol.NewTextContent(note[2:]))
+		}
+		break
+	}
+	return kept
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
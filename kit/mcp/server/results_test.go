@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+func TestMCPServer_NextCursor(t *testing.T) {
+	server := newEchoServer(t)
+	server.RegisterTool(&MockTool{
+		name: "paged_tool",
+		executeFunc: func(ctx context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+			if args["test_param"] == "more" {
+				SetNextCursor(ctx, "page-2")
+			}
+			return []protocol.Content{protocol.NewTextContent("page 1")}, nil
+		},
+	})
+
+	call := func(param string) protocol.ToolCallResult {
+		request := `{"jsonrpc":"2.0","id":"1","method":"tools/call","params":{"name":"paged_tool","arguments":{"test_param":"` + param + `"}}}`
+		data, err := server.HandleRequest(context.Background(), []byte(request))
+		if err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		var response protocol.JSONRPCResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		return toolResult(t, response)
+	}
+
+	result := call("more")
+	if result.NextCursor != "page-2" {
+		t.Errorf("NextCursor = %q, want page-2", result.NextCursor)
+	}
+	if len(result.Content) != 1 || !strings.HasPrefix(result.Content[0].Text, "page 1") || !strings.Contains(result.Content[0].Text, `cursor "page-2"`) {
+		t.Errorf("content = %+v, want the page followed by a note about the cursor", result.Content)
+	}
+
+	if result := call("last"); result.NextCursor != "" || result.Content[0].Text != "page 1" {
+		t.Errorf("last page result = %+v, want no cursor", result)
+	}
+}
+
+func TestMaxResultSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   []protocol.Content
+		wantTexts []string
+		wantCut   bool
+	}{
+		{
+			name:      "within limit",
+			content:   []protocol.Content{protocol.NewTextContent("short")},
+			wantTexts: []string{"short"},
+		},
+		{
+			name:      "long text cut",
+			content:   []protocol.Content{protocol.NewTextContent(strings.Repeat("a", 30))},
+			wantTexts: []string{strings.Repeat("a", 20)},
+			wantCut:   true,
+		},
+		{
+			name:      "later items dropped",
+			content:   []protocol.Content{protocol.NewTextContent(strings.Repeat("a", 15)), protocol.NewTextContent(strings.Repeat("b", 15))},
+			wantTexts: []string{strings.Repeat("a", 15), "bbbbb"},
+			wantCut:   true,
+		},
+		{
+			name:      "multi-byte characters kept whole",
+			content:   []protocol.Content{protocol.NewTextContent(strings.Repeat("a", 19) + "⛳⛳")},
+			wantTexts: []string{strings.Repeat("a", 19)},
+			wantCut:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := MaxResultSize(20)(func(ctx context.Context, call *ToolCall) ([]protocol.Content, error) {
+				return tt.content, nil
+			})
+			content, err := handler(context.Background(), &ToolCall{Name: "echo_tool"})
+			if err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if len(content) != len(tt.wantTexts) {
+				t.Fatalf("content = %+v, want %d items", content, len(tt.wantTexts))
+			}
+			for i, want := range tt.wantTexts {
+				text, _, cut := strings.Cut(content[i].Text, "\n\n[Result truncated")
+				if text != want {
+					t.Errorf("item %d text = %q, want %q", i, text, want)
+				}
+				if last := i == len(content)-1; last && cut != tt.wantCut {
+					t.Errorf("item %d truncation note present = %v, want %v", i, cut, tt.wantCut)
+				}
+			}
+		})
+	}
+}
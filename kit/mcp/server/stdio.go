@@ -0,0 +1,64 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+// ServeStdio serves the MCP stdio transport: each line read from in is a JSON-RPC message, and
+// each response is written to out as one line. Messages are handled one at a time in the order
+// they arrive. It returns nil when in reaches EOF, or ctx's error once ctx is done. Logs must not
+// be written to out, or the client will fail to parse them.
+func (s *MCPServer) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := reader.ReadBytes('\n')
+		if request := bytes.TrimSpace(line); len(request) > 0 {
+			if writeErr := s.serveStdioMessage(ctx, request, out); writeErr != nil {
+				return writeErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+	}
+}
+
+// serveStdioMessage handles one message and writes its response line, if it has one
+func (s *MCPServer) serveStdioMessage(ctx context.Context, request []byte, out io.Writer) error {
+	response, err := s.HandleRequest(ctx, request)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to handle MCP request", slog.String("error", err.Error()))
+		response, err = json.Marshal(protocol.JSONRPCResponse{
+			JSONRPC: protocol.JSONRPCVersion,
+			Error:   protocol.NewJSONRPCError(protocol.ErrCodeInternalError, "Internal error", err.Error()),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal error response: %w", err)
+		}
+	}
+
+	// Notifications, such as cancellations, get no response
+	if len(response) == 0 {
+		return nil
+	}
+	if _, err := out.Write(append(response, '\n')); err != nil {
+		return fmt.Errorf("failed to write stdout: %w", err)
+	}
+	return nil
+}
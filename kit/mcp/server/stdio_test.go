@@ -0,0 +1,54 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jrzesz33/rez_agent/kit/mcp/protocol"
+)
+
+func TestMCPServer_ServeStdio(t *testing.T) {
+	server := newEchoServer(t)
+
+	in := strings.NewReader(strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+		``,
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"9"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo_tool","arguments":{"test_param":"hello"}}}`,
+	}, "\n"))
+	var out bytes.Buffer
+
+	if err := server.ServeStdio(context.Background(), in, &out); err != nil {
+		t.Fatalf("ServeStdio() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("ServeStdio() wrote %d lines, want one per request and none for the notification:\n%s", len(lines), out.String())
+	}
+
+	var response protocol.JSONRPCResponse
+	if err := json.Unmarshal([]byte(lines[1]), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if result := toolResult(t, response); result.Content[0].Text != "hello" {
+		t.Errorf("tool result = %+v, want the echoed argument", result)
+	}
+}
+
+func TestMCPServer_ServeStdioCancelled(t *testing.T) {
+	server := newEchoServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out bytes.Buffer
+	if err := server.ServeStdio(ctx, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n"), &out); err != context.Canceled {
+		t.Errorf("ServeStdio() error = %v, want context.Canceled", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("ServeStdio() wrote %q after its context was cancelled", out.String())
+	}
+}
@@ -0,0 +1,162 @@
+// Package redact masks credentials and personal data before they reach logs or storage:
+// authorization headers, bearer tokens and JWTs, email addresses and account identifiers.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces a redacted value
+const Mask = "[REDACTED]"
+
+// sensitiveNameParts mark header, query parameter and field names whose values are credentials
+var sensitiveNameParts = []string{"auth", "token", "key", "secret", "password", "cookie", "session"}
+
+// personalFields are JSON fields and query parameters that identify a golfer, compared in
+// lowercase with underscores and dashes removed
+var personalFields = map[string]bool{
+	"email":      true,
+	"acct":       true,
+	"account":    true,
+	"accountid":  true,
+	"golferid":   true,
+	"playerid":   true,
+	"customerid": true,
+	"memberid":   true,
+}
+
+var (
+	// emailPattern matches email addresses
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+	// bearerPattern matches bearer credentials, such as in a logged Authorization header
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/\-]+=*`)
+
+	// jwtPattern matches JSON web tokens, whose payload carries the golfer's claims
+	jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]*\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+
+	// jsonFieldPattern matches a JSON string or number field, capturing its name
+	jsonFieldPattern = regexp.MustCompile(`"([A-Za-z_\-]+)"(\s*:\s*)("(?:[^"\\]|\\.)*"|-?\d+)`)
+
+	// fieldPattern matches Go's %v and %+v printing of a struct field, capturing its name
+	fieldPattern = regexp.MustCompile(`\b([A-Za-z_]+)(:)([^\s{}\[\]]+)`)
+)
+
+// normalize lowercases a field name and drops its separators, so acct_id, acctId and Acct-ID compare equal
+func normalize(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// IsSensitiveName reports whether a header, query parameter or field of this name holds a credential
+func IsSensitiveName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPersonalName reports whether a field of this name identifies a golfer
+func isPersonalName(name string) bool {
+	return personalFields[normalize(name)]
+}
+
+// Headers returns a copy of headers with the values of credential headers masked
+func Headers(headers map[string]string) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if IsSensitiveName(name) {
+			value = Mask
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// HTTPHeaders flattens request or response headers for logging, with credential headers masked
+func HTTPHeaders(headers http.Header) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for name := range headers {
+		if IsSensitiveName(name) {
+			redacted[name] = Mask
+			continue
+		}
+		redacted[name] = Text(headers.Get(name))
+	}
+	return redacted
+}
+
+// URL masks the values of credential and personal query parameters of rawURL; a URL that does not
+// parse is masked entirely
+func URL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return Mask
+	}
+	if parsed.User != nil {
+		parsed.User = url.User(Mask)
+	}
+	if parsed.RawQuery != "" {
+		query := parsed.Query()
+		for name, values := range query {
+			if IsSensitiveName(name) || isPersonalName(name) {
+				for i := range values {
+					values[i] = Mask
+				}
+			}
+		}
+		parsed.RawQuery = query.Encode()
+	}
+	return Text(parsed.String())
+}
+
+// Text masks credentials and personal data in free text, such as a response body or an error
+// message: bearer credentials, JWTs, email addresses, and the values of JSON fields that hold a
+// credential or identify a golfer
+func Text(s string) string {
+	if s == "" {
+		return s
+	}
+	s = jsonFieldPattern.ReplaceAllStringFunc(s, func(field string) string {
+		parts := jsonFieldPattern.FindStringSubmatch(field)
+		if !IsSensitiveName(parts[1]) && !isPersonalName(parts[1]) {
+			return field
+		}
+		return `"` + parts[1] + `"` + parts[2] + `"` + Mask + `"`
+	})
+	s = bearerPattern.ReplaceAllString(s, "$1 "+Mask)
+	s = jwtPattern.ReplaceAllString(s, Mask)
+	return emailPattern.ReplaceAllString(s, Mask)
+}
+
+// Value renders v for a log line with Text's masking applied: JSON for values that marshal, and
+// Go's %+v formatting, with sensitive fields masked, otherwise
+func Value(v any) string {
+	if body, err := json.Marshal(v); err == nil {
+		return Text(string(body))
+	}
+	printed := fmt.Sprintf("%+v", v)
+	printed = fieldPattern.ReplaceAllStringFunc(printed, func(field string) string {
+		parts := fieldPattern.FindStringSubmatch(field)
+		if !IsSensitiveName(parts[1]) && !isPersonalName(parts[1]) {
+			return field
+		}
+		return parts[1] + parts[2] + Mask
+	})
+	return Text(printed)
+}
+
+// ID masks an account or golfer identifier, keeping only whether it was set
+func ID(id string) string {
+	if id == "" {
+		return ""
+	}
+	return Mask
+}
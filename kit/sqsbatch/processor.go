@@ -0,0 +1,261 @@
+// Package sqsbatch processes SQS Lambda events record by record and reports partial batch
+// failures, so only the records that failed are redelivered.
+package sqsbatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Handler processes one decoded record
+type Handler[T any] func(ctx context.Context, item T) error
+
+// PermanentFailureHandler is called for a record whose final delivery attempt failed
+type PermanentFailureHandler[T any] func(ctx context.Context, item T, cause error, attempts int)
+
+// Processor decodes SQS records into T and runs a handler for each of them
+type Processor[T any] struct {
+	logger          *slog.Logger
+	decode          func(body string) (T, error)
+	validate        func(item T) error
+	onSuccess       func(ctx context.Context, item T)
+	onPermanentFail PermanentFailureHandler[T]
+	maxReceiveCount int
+	retryable       func(err error) bool
+	recordTimeout   time.Duration
+	concurrency     int
+	logAttrs        func(item T) []any
+}
+
+// NewProcessor creates a processor that decodes record bodies as JSON
+func NewProcessor[T any](logger *slog.Logger) *Processor[T] {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Processor[T]{
+		logger: logger,
+		decode: decodeJSON[T],
+	}
+}
+
+// SetDecoder replaces the JSON decoder for record bodies
+func (p *Processor[T]) SetDecoder(decode func(body string) (T, error)) {
+	p.decode = decode
+}
+
+// SetValidator rejects records before they reach the handler; rejected records fail without retry logic
+func (p *Processor[T]) SetValidator(validate func(item T) error) {
+	p.validate = validate
+}
+
+// SetSuccessHook is called after the handler succeeds, e.g. to record metrics
+func (p *Processor[T]) SetSuccessHook(hook func(ctx context.Context, item T)) {
+	p.onSuccess = hook
+}
+
+// SetPermanentFailureHandler reports records that fail on their last delivery before SQS moves them to the DLQ.
+// maxReceiveCount must match the queue's redrive policy.
+func (p *Processor[T]) SetPermanentFailureHandler(maxReceiveCount int, handler PermanentFailureHandler[T]) {
+	p.maxReceiveCount = maxReceiveCount
+	p.onPermanentFail = handler
+}
+
+// SetRetryPolicy decides which handler errors are worth a redelivery. A record that fails with an
+// error retryable rejects is reported to the permanent failure handler at once and not redelivered.
+func (p *Processor[T]) SetRetryPolicy(retryable func(err error) bool) {
+	p.retryable = retryable
+}
+
+// SetConcurrency handles up to n records of a batch at once. The default of 1 handles records one
+// at a time in queue order; use it for handlers that depend on that order. With n > 1 the handler,
+// validator and hooks must be safe for concurrent use.
+func (p *Processor[T]) SetConcurrency(n int) {
+	p.concurrency = n
+}
+
+// SetRecordTimeout bounds the handler's time on each record; a record that runs over fails and
+// is redelivered. Zero, the default, leaves records bounded only by the Lambda timeout.
+func (p *Processor[T]) SetRecordTimeout(timeout time.Duration) {
+	p.recordTimeout = timeout
+}
+
+// SetLogAttrs adds item attributes, such as an ID, to the processor's log lines
+func (p *Processor[T]) SetLogAttrs(attrs func(item T) []any) {
+	p.logAttrs = attrs
+}
+
+// Decode decodes every record in the event, failing on the first record that cannot be decoded
+func (p *Processor[T]) Decode(event events.SQSEvent) ([]T, error) {
+	items := make([]T, 0, len(event.Records))
+	for _, record := range event.Records {
+		item, err := p.decode(record.Body)
+		if err != nil {
+			p.logger.Error("failed to decode SQS record",
+				slog.String("error", err.Error()),
+				slog.String("sqs_message_id", record.MessageId),
+			)
+			return nil, fmt.Errorf("failed to decode SQS record %s: %w", record.MessageId, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ProcessBatch runs handler for each record and returns the records that should be redelivered.
+// A record that cannot be decoded fails on its own; the error is only set when no record in the
+// batch could be decoded.
+func (p *Processor[T]) ProcessBatch(ctx context.Context, event events.SQSEvent, handler Handler[T]) (events.SQSEventResponse, error) {
+	response := events.SQSEventResponse{
+		BatchItemFailures: []events.SQSBatchItemFailure{},
+	}
+
+	// Each record writes only its own slot, so concurrent records need no lock
+	failed := make([]bool, len(event.Records))
+	var decodeErrs []error
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, max(p.concurrency, 1))
+
+	for i, record := range event.Records {
+		item, err := p.decode(record.Body)
+		if err != nil {
+			p.logger.ErrorContext(ctx, "failed to decode SQS record",
+				slog.String("error", err.Error()),
+				slog.String("sqs_message_id", record.MessageId),
+			)
+			decodeErrs = append(decodeErrs, fmt.Errorf("failed to decode SQS record %s: %w", record.MessageId, err))
+			failed[i] = true
+			continue
+		}
+
+		if p.concurrency <= 1 {
+			failed[i] = !p.processRecord(ctx, handler, record, item)
+			continue
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			failed[i] = !p.processRecord(ctx, handler, record, item)
+		}()
+	}
+	wg.Wait()
+
+	for i, record := range event.Records {
+		if failed[i] {
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{
+				ItemIdentifier: record.MessageId,
+			})
+		}
+	}
+
+	if len(event.Records) > 0 && len(decodeErrs) == len(event.Records) {
+		return response, errors.Join(decodeErrs...)
+	}
+	return response, nil
+}
+
+// processRecord validates and handles one decoded record, returning false if it should be redelivered
+func (p *Processor[T]) processRecord(ctx context.Context, handler Handler[T], record events.SQSMessage, item T) bool {
+	attrs := p.attrs(item, record)
+
+	// Invalid records fail without reaching the handler so they land in the DLQ
+	if p.validate != nil {
+		if err := p.validate(item); err != nil {
+			p.logger.ErrorContext(ctx, "rejected message", append(attrs, slog.String("error", err.Error()))...)
+			return false
+		}
+	}
+
+	if err := p.handle(ctx, handler, item); err != nil {
+		p.logger.ErrorContext(ctx, "failed to process message", append(attrs, slog.String("error", err.Error()))...)
+		if p.retryable != nil && !p.retryable(err) {
+			p.logger.WarnContext(ctx, "message failed with a non-retryable error", attrs...)
+			p.reportPermanentFailure(ctx, record, item, err)
+			return true
+		}
+		p.reportIfFinalAttempt(ctx, record, item, err)
+		return false
+	}
+
+	p.logger.DebugContext(ctx, "successfully processed message", attrs...)
+	if p.onSuccess != nil {
+		p.onSuccess(ctx, item)
+	}
+	return true
+}
+
+// handle runs handler on one item under the record timeout. A handler that ignores its context is
+// abandoned when the timeout passes so it cannot hold up the rest of the batch.
+func (p *Processor[T]) handle(ctx context.Context, handler Handler[T], item T) error {
+	if p.recordTimeout <= 0 {
+		return handler(ctx, item)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.recordTimeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- handler(ctx, item)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("record timed out after %s: %w", p.recordTimeout, ctx.Err())
+	}
+}
+
+// reportIfFinalAttempt calls the permanent failure handler when this was the record's last delivery
+func (p *Processor[T]) reportIfFinalAttempt(ctx context.Context, record events.SQSMessage, item T, cause error) {
+	if p.onPermanentFail == nil {
+		return
+	}
+	attempts, err := strconv.Atoi(record.Attributes["ApproximateReceiveCount"])
+	if err != nil || attempts < p.maxReceiveCount {
+		return
+	}
+	p.logger.WarnContext(ctx, "message failed on its final attempt", append(p.attrs(item, record), slog.Int("attempts", attempts))...)
+	p.onPermanentFail(ctx, item, cause, attempts)
+}
+
+// reportPermanentFailure calls the permanent failure handler for a record that will not be retried
+func (p *Processor[T]) reportPermanentFailure(ctx context.Context, record events.SQSMessage, item T, cause error) {
+	if p.onPermanentFail == nil {
+		return
+	}
+	attempts, err := strconv.Atoi(record.Attributes["ApproximateReceiveCount"])
+	if err != nil || attempts < 1 {
+		attempts = 1
+	}
+	p.onPermanentFail(ctx, item, cause, attempts)
+}
+
+// attrs returns the log attributes for a record
+func (p *Processor[T]) attrs(item T, record events.SQSMessage) []any {
+	attrs := []any{slog.String("sqs_message_id", record.MessageId)}
+	if p.logAttrs != nil {
+		attrs = append(attrs, p.logAttrs(item)...)
+	}
+	return attrs
+}
+
+// decodeJSON is the default record decoder
+func decodeJSON[T any](body string) (T, error) {
+	var item T
+	err := json.Unmarshal([]byte(body), &item)
+	return item, err
+}
@@ -0,0 +1,271 @@
+package sqsbatch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type job struct {
+	ID   string `json:"id"`
+	Fail bool   `json:"fail"`
+}
+
+func newTestProcessor() *Processor[job] {
+	return NewProcessor[job](slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func record(id, body, receiveCount string) events.SQSMessage {
+	return events.SQSMessage{
+		MessageId:  id,
+		Body:       body,
+		Attributes: map[string]string{"ApproximateReceiveCount": receiveCount},
+	}
+}
+
+func TestProcessBatch_PartialFailures(t *testing.T) {
+	p := newTestProcessor()
+	p.SetValidator(func(j job) error {
+		if j.ID == "" {
+			return errors.New("missing id")
+		}
+		return nil
+	})
+	var succeeded []string
+	p.SetSuccessHook(func(ctx context.Context, j job) { succeeded = append(succeeded, j.ID) })
+
+	event := events.SQSEvent{Records: []events.SQSMessage{
+		record("sqs-1", `{"id":"a"}`, "1"),
+		record("sqs-2", `{"id":"b","fail":true}`, "1"),
+		record("sqs-3", `{}`, "1"),
+	}}
+
+	resp, err := p.ProcessBatch(context.Background(), event, func(ctx context.Context, j job) error {
+		if j.Fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ProcessBatch() error = %v", err)
+	}
+
+	var failed []string
+	for _, f := range resp.BatchItemFailures {
+		failed = append(failed, f.ItemIdentifier)
+	}
+	if len(failed) != 2 || failed[0] != "sqs-2" || failed[1] != "sqs-3" {
+		t.Errorf("BatchItemFailures = %v, want [sqs-2 sqs-3]", failed)
+	}
+	if len(succeeded) != 1 || succeeded[0] != "a" {
+		t.Errorf("success hook calls = %v, want [a]", succeeded)
+	}
+}
+
+func TestProcessBatch_DecodeErrorFailsRecord(t *testing.T) {
+	event := events.SQSEvent{Records: []events.SQSMessage{
+		record("sqs-1", `{"id":"a"}`, "1"),
+		record("sqs-2", `not json`, "1"),
+	}}
+
+	var handled []string
+	resp, err := newTestProcessor().ProcessBatch(context.Background(), event, func(ctx context.Context, j job) error {
+		handled = append(handled, j.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ProcessBatch() error = %v, want nil when some records decode", err)
+	}
+	if len(resp.BatchItemFailures) != 1 || resp.BatchItemFailures[0].ItemIdentifier != "sqs-2" {
+		t.Errorf("BatchItemFailures = %v, want only sqs-2", resp.BatchItemFailures)
+	}
+	if len(handled) != 1 || handled[0] != "a" {
+		t.Errorf("handled = %v, want [a]", handled)
+	}
+}
+
+func TestProcessBatch_NothingDecodes(t *testing.T) {
+	event := events.SQSEvent{Records: []events.SQSMessage{
+		record("sqs-1", `not json`, "1"),
+		record("sqs-2", `{`, "1"),
+	}}
+
+	resp, err := newTestProcessor().ProcessBatch(context.Background(), event, func(ctx context.Context, j job) error {
+		t.Error("handler called for an undecodable record")
+		return nil
+	})
+	if err == nil {
+		t.Fatal("ProcessBatch() error = nil, want decode error")
+	}
+	if len(resp.BatchItemFailures) != 2 {
+		t.Errorf("BatchItemFailures = %d, want every record", len(resp.BatchItemFailures))
+	}
+}
+
+func TestProcessBatch_RecordTimeout(t *testing.T) {
+	p := newTestProcessor()
+	p.SetRecordTimeout(20 * time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+	event := events.SQSEvent{Records: []events.SQSMessage{
+		record("sqs-1", `{"id":"slow"}`, "1"),
+		record("sqs-2", `{"id":"fast"}`, "1"),
+	}}
+
+	start := time.Now()
+	resp, err := p.ProcessBatch(context.Background(), event, func(ctx context.Context, j job) error {
+		if j.ID == "slow" {
+			// Ignores its context, so only the processor's timeout can stop it
+			<-release
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ProcessBatch() error = %v", err)
+	}
+	if len(resp.BatchItemFailures) != 1 || resp.BatchItemFailures[0].ItemIdentifier != "sqs-1" {
+		t.Errorf("BatchItemFailures = %v, want only the slow record", resp.BatchItemFailures)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("ProcessBatch() took %s, want it bounded by the record timeout", elapsed)
+	}
+}
+
+func TestProcessBatch_PermanentFailure(t *testing.T) {
+	tests := []struct {
+		name         string
+		receiveCount string
+		wantReported bool
+	}{
+		{"retries remain", "2", false},
+		{"final attempt", "3", true},
+		{"missing attribute", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProcessor()
+			var reported int
+			p.SetPermanentFailureHandler(3, func(ctx context.Context, j job, cause error, attempts int) {
+				reported = attempts
+			})
+
+			event := events.SQSEvent{Records: []events.SQSMessage{record("sqs-1", `{"id":"a"}`, tt.receiveCount)}}
+			_, _ = p.ProcessBatch(context.Background(), event, func(ctx context.Context, j job) error {
+				return errors.New("boom")
+			})
+
+			if got := reported != 0; got != tt.wantReported {
+				t.Errorf("reported = %v, want %v", got, tt.wantReported)
+			}
+		})
+	}
+}
+
+func TestProcessBatch_NonRetryableFailure(t *testing.T) {
+	errInvalid := errors.New("invalid job")
+
+	p := newTestProcessor()
+	p.SetRetryPolicy(func(err error) bool { return !errors.Is(err, errInvalid) })
+	var reported []string
+	p.SetPermanentFailureHandler(3, func(ctx context.Context, j job, cause error, attempts int) {
+		reported = append(reported, j.ID)
+		if attempts != 1 {
+			t.Errorf("attempts = %d, want 1", attempts)
+		}
+	})
+
+	event := events.SQSEvent{Records: []events.SQSMessage{
+		record("sqs-1", `{"id":"a"}`, "1"),
+		record("sqs-2", `{"id":"b","fail":true}`, "1"),
+	}}
+	response, err := p.ProcessBatch(context.Background(), event, func(ctx context.Context, j job) error {
+		if j.Fail {
+			return errors.New("upstream unavailable")
+		}
+		return errInvalid
+	})
+	if err != nil {
+		t.Fatalf("ProcessBatch() error = %v", err)
+	}
+
+	// The invalid record is reported and dropped; the transient failure is redelivered
+	if len(response.BatchItemFailures) != 1 || response.BatchItemFailures[0].ItemIdentifier != "sqs-2" {
+		t.Errorf("BatchItemFailures = %v, want only sqs-2", response.BatchItemFailures)
+	}
+	if len(reported) != 1 || reported[0] != "a" {
+		t.Errorf("reported = %v, want [a]", reported)
+	}
+}
+
+func TestProcessBatch_Concurrency(t *testing.T) {
+	p := newTestProcessor()
+	p.SetConcurrency(4)
+
+	var running, peak atomic.Int32
+	var records []events.SQSMessage
+	for i := range 8 {
+		id := strconv.Itoa(i)
+		records = append(records, record("sqs-"+id, `{"id":"`+id+`","fail":`+strconv.FormatBool(i%2 == 1)+`}`, "1"))
+	}
+
+	resp, err := p.ProcessBatch(context.Background(), events.SQSEvent{Records: records}, func(ctx context.Context, j job) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		if j.Fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ProcessBatch() error = %v", err)
+	}
+
+	if got := peak.Load(); got != 4 {
+		t.Errorf("peak concurrency = %d, want 4", got)
+	}
+	// Failures are reported in record order whatever order the records finished in
+	var failed []string
+	for _, f := range resp.BatchItemFailures {
+		failed = append(failed, f.ItemIdentifier)
+	}
+	if want := []string{"sqs-1", "sqs-3", "sqs-5", "sqs-7"}; !slices.Equal(failed, want) {
+		t.Errorf("BatchItemFailures = %v, want %v", failed, want)
+	}
+}
+
+func TestProcessBatch_OrderedByDefault(t *testing.T) {
+	var records []events.SQSMessage
+	for i := range 5 {
+		id := strconv.Itoa(i)
+		records = append(records, record("sqs-"+id, `{"id":"`+id+`"}`, "1"))
+	}
+
+	var handled []string
+	_, err := newTestProcessor().ProcessBatch(context.Background(), events.SQSEvent{Records: records}, func(ctx context.Context, j job) error {
+		handled = append(handled, j.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ProcessBatch() error = %v", err)
+	}
+	if want := []string{"0", "1", "2", "3", "4"}; !slices.Equal(handled, want) {
+		t.Errorf("handled = %v, want %v", handled, want)
+	}
+}
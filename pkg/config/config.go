@@ -1,10 +1,21 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
-
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/jrzesz33/rez_agent/internal/models"
+	"github.com/jrzesz33/rez_agent/internal/policy"
 )
 
 // Config holds all configuration for the application
@@ -19,30 +30,151 @@ type Config struct {
 	DynamoDBTableName         string
 	WebActionResultsTableName string
 	SchedulesTableName        string // Table for dynamic schedules
+	WeatherDecisionsTableName string // Table for forecast vs. observed weather decisions
+	PreferencesTableName      string // Table for post-round survey responses
+	ApprovalsTableName        string // Table for pending booking approvals
+	OAuthTokenTableName       string // Table for OAuth tokens shared across invocations (optional, memory-only when empty)
+	WebActionHandlerTableName string // Table of per-stage web action handler configs (optional, all handlers enabled when empty)
+	AuditTableName            string // Append-only audit log of data exports and deletions
+	AgentSessionTableName     string // Table of agent chat sessions written by the agent Lambda
+	UsersTableName            string // Table of users and their API keys (optional, single-user mode when empty)
+	ReservationsTableName     string // Synced reservations cache (optional, reservations are read live when empty)
+	BookingLedgerTableName    string // One booking per golfer, course and date (optional, duplicates are not prevented when empty)
 
 	// SNS Configuration
-	WebActionsSNSTopicArn      string // Topic for web action messages
-	NotificationsSNSTopicArn   string // Topic for notification messages
-	AgentResponseTopicArn      string // Topic for agent response messages
-	ScheduleCreationTopicArn   string // Topic for schedule creation requests
+	WebActionsSNSTopicArn    string                        // Topic for web action messages
+	NotificationsSNSTopicArn string                        // Topic for notification messages
+	AgentResponseTopicArn    string                        // Topic for agent response messages
+	ScheduleCreationTopicArn string                        // Topic for schedule creation requests
+	TopicRoutes              map[models.MessageType]string // Message type to topic routing table; unrouted types use NotificationsSNSTopicArn
 
 	// EventBridge Scheduler Configuration
 	EventBridgeExecutionRoleArn string // Role ARN for EventBridge Scheduler to invoke Lambda
+	SchedulerFunctionArn        string // Scheduler Lambda that agent run schedules invoke; empty keeps the target the schedule was built with
+	EventBusName                string // Custom EventBridge bus domain events are published to; empty disables them
+
+	// OutboxRelay leaves publishing new messages to the outbox relay Lambda, which publishes them
+	// from the messages table's stream; when false the web API and alarms Lambdas publish them
+	OutboxRelay bool
 
 	// SQS Configuration
-	NotificationSQSQueueURL    string
-	WebActionSQSQueueURL       string
-	ScheduleCreationQueueArn   string // ARN of SQS queue for EventBridge Scheduler targets
-	ScheduleCreationQueueURL   string // URL of SQS queue for schedule creation requests
+	NotificationSQSQueueURL  string
+	WebActionSQSQueueURL     string
+	ScheduleCreationQueueArn string // ARN of SQS queue for EventBridge Scheduler targets
+	ScheduleCreationQueueURL string // URL of SQS queue for schedule creation requests
+	DigestSQSQueueURL        string // URL of SQS queue for weekly digest triggers
+	AgentResponseQueueURL    string // URL of SQS queue of web action results for the agent
 
 	// Ntfy Configuration
 	NtfyURL string
 
+	// HTTPRequestAllowedHosts are extra hosts the generic http_request web action may call
+	HTTPRequestAllowedHosts []string
+
+	// ApprovalBaseURL is the public web API URL used in booking approve/decline buttons
+	ApprovalBaseURL string
+
+	// DataRequestAPIKey guards the data export and deletion endpoints, which are disabled when it is empty
+	DataRequestAPIKey string
+
+	// MetricsAPIKey guards the Prometheus metrics endpoint, which is disabled when it is empty
+	MetricsAPIKey string
+
+	// CalendarSigningKey signs calendar feed URLs; the feed is disabled when it is empty
+	CalendarSigningKey string
+
+	// EmailFromAddress is the SES-verified sender of email notifications
+	EmailFromAddress string
+
+	// DigestRecipients are the addresses the weekly digest is emailed to
+	DigestRecipients []string
+
+	// A2AAgents are external agents scheduled agent runs may delegate to, from the A2A_AGENTS JSON array
+	A2AAgents []models.RemoteAgent
+
+	// BedrockGuardrailID is the ID or ARN of the Bedrock guardrail applied to scheduled agent runs (optional)
+	BedrockGuardrailID string
+
+	// BedrockGuardrailVersion is the guardrail version to apply, defaulting to DRAFT
+	BedrockGuardrailVersion string
+
+	// BedrockThrottleBudget is how long one scheduled agent run may wait out Bedrock throttling
+	// before it stops with a partial result (zero keeps the scheduler's default)
+	BedrockThrottleBudget time.Duration
+
+	// PromptParameterPrefix is the SSM Parameter Store path holding prompt template overrides (optional)
+	PromptParameterPrefix string
+
+	// AgentGuardrails limit every scheduled agent run, from the AGENT_GUARDRAILS JSON object
+	AgentGuardrails *policy.Guardrails
+
 	// Secrets Manager Configuration
 	GolfSecretName string
 
+	// OAuthTokenRefreshBefore is how long before expiry a cached OAuth token is refreshed
+	OAuthTokenRefreshBefore time.Duration
+
+	// ReservationCacheMaxAge is how old synced reservations may be before reads go to the provider
+	ReservationCacheMaxAge time.Duration
+
+	// Outbound HTTP circuit breaker (zero values use the httpclient defaults)
+	CircuitBreakerFailureThreshold int           // Consecutive failures that open a host's circuit
+	CircuitBreakerOpenTimeout      time.Duration // How long a circuit stays open before probing
+
+	// SQSRecordTimeout bounds the handling of each SQS record in a batch (zero leaves it unbounded)
+	SQSRecordTimeout time.Duration
+
+	// SQSBatchConcurrency is how many records of an SQS batch are handled at once (1 keeps queue order)
+	SQSBatchConcurrency int
+
+	// StuckMessageAge is how long a message may sit in created, queued or processing unchanged
+	// before the sweeper re-drives or fails it
+	StuckMessageAge time.Duration
+
+	// StuckMessageMaxRetries is how many times a stuck message is re-driven before it is failed
+	StuckMessageMaxRetries int
+
+	// ScheduleReconcileRepair lets the schedule reconciler repair the discrepancies it finds
+	// between the schedules table and EventBridge, rather than only report them
+	ScheduleReconcileRepair bool
+
+	// MCPMaxResultBytes caps the text an MCP tool call returns; longer results are truncated
+	MCPMaxResultBytes int
+
+	// AgentSessionWindow bounds the chat history an agent session keeps and how long it lives idle
+	AgentSessionWindow models.AgentSessionWindow
+
+	// Chat agent
+	ConsentRequiredTools   []string // Tools a chat user must approve before the agent runs them
+	A2AAPIKey              string   // API key other agents send in X-API-Key; agent requests are refused when empty
+	AgentSessionSigningKey string   // Signs the chat UI's session cookie; the chat UI is disabled when empty
+	AgentCardURL           string   // Public agent URL in the agent card (optional, derived from the request)
+	AgentDailySpendingCap  float64  // Most the chat agent spends on Bedrock per UTC day, in dollars
+
+	// CORS of the web API, MCP server and agent
+	CORSAllowedOrigins   []string // Origins browsers may call the APIs from; "*" allows any, empty allows none
+	CORSAllowCredentials bool     // Whether browsers may send cookies and credentials cross-origin
+
+	// CompressionThreshold is the smallest web API and MCP response body gzipped for clients that
+	// accept it, in bytes; zero turns compression off
+	CompressionThreshold int
+
+	// WebActionBodyOffload keeps the full response bodies of web actions, beyond the 50KB stored
+	// in the results table, in the agent logs bucket (AGENT_LOGS_BUCKET)
+	WebActionBodyOffload bool
+
 	// Lambda Configuration
 	LambdaTimeout int
+
+	// Local runs a Lambda on a laptop (LOCAL=true): AWS clients use LocalStack, secrets come from
+	// a JSON file, and an in-process HTTP server replaces the Lambda runtime
+	Local              bool
+	LocalStackEndpoint string // AWS endpoint used in local mode
+	LocalSecretsFile   string // JSON object of secret name to secret value used in local mode
+	LocalHTTPAddr      string // Address of the in-process server (optional, each Lambda has a default port)
+
+	// explicit records the settings set in the environment rather than defaulted (see Validate)
+	explicit map[string]bool
 }
 
 // Load reads configuration from environment variables
@@ -77,17 +209,100 @@ func Load() (*Config, error) {
 		schedulesTableName = fmt.Sprintf("rez-agent-schedules-%s", stage)
 	}
 
+	preferencesTableName := os.Getenv("PREFERENCES_TABLE_NAME")
+	if preferencesTableName == "" {
+		preferencesTableName = fmt.Sprintf("rez-agent-preferences-%s", stage)
+	}
+
+	approvalsTableName := os.Getenv("APPROVALS_TABLE_NAME")
+	if approvalsTableName == "" {
+		approvalsTableName = fmt.Sprintf("rez-agent-approvals-%s", stage)
+	}
+
+	auditTableName := os.Getenv("AUDIT_TABLE_NAME")
+	if auditTableName == "" {
+		auditTableName = fmt.Sprintf("rez-agent-audit-%s", stage)
+	}
+
+	agentSessionTableName := os.Getenv("AGENT_SESSION_TABLE_NAME")
+	if agentSessionTableName == "" {
+		agentSessionTableName = fmt.Sprintf("rez-agent-sessions-%s", stage)
+	}
+
+	weatherDecisionsTableName := os.Getenv("WEATHER_DECISIONS_TABLE_NAME")
+	if weatherDecisionsTableName == "" {
+		weatherDecisionsTableName = fmt.Sprintf("rez-agent-weather-decisions-%s", stage)
+	}
+
 	// Topic-based routing (for webapi Lambda)
 	webActionsSNSTopicArn := os.Getenv("WEB_ACTIONS_TOPIC_ARN")
 	notificationsSNSTopicArn := os.Getenv("NOTIFICATIONS_TOPIC_ARN")
 	agentResponseTopicArn := os.Getenv("AGENT_RESPONSE_TOPIC_ARN")
 	scheduleCreationTopicArn := os.Getenv("SCHEDULE_CREATION_TOPIC_ARN")
 
+	// Routing table: the per-topic variables above, overridden or extended by TOPIC_ROUTES
+	topicRoutes := map[models.MessageType]string{
+		models.MessageTypeWebAction:        webActionsSNSTopicArn,
+		models.MessageTypeAgentResponse:    agentResponseTopicArn,
+		models.MessageTypeScheduleCreation: scheduleCreationTopicArn,
+	}
+	if err := mergeTopicRoutes(topicRoutes, os.Getenv("TOPIC_ROUTES")); err != nil {
+		return nil, err
+	}
+
+	a2aAgents, err := parseRemoteAgents(os.Getenv("A2A_AGENTS"))
+	if err != nil {
+		return nil, err
+	}
+
+	agentGuardrails, err := parseGuardrails(os.Getenv("AGENT_GUARDRAILS"))
+	if err != nil {
+		return nil, err
+	}
+
+	bedrockGuardrailID := os.Getenv("BEDROCK_GUARDRAIL_ID")
+	bedrockGuardrailVersion := os.Getenv("BEDROCK_GUARDRAIL_VERSION")
+	if bedrockGuardrailID == "" && bedrockGuardrailVersion != "" {
+		return nil, fmt.Errorf("BEDROCK_GUARDRAIL_VERSION is set without BEDROCK_GUARDRAIL_ID")
+	}
+	if bedrockGuardrailID != "" && bedrockGuardrailVersion == "" {
+		bedrockGuardrailVersion = "DRAFT"
+	}
+
+	var bedrockThrottleBudget time.Duration
+	if raw := os.Getenv("BEDROCK_THROTTLE_BUDGET_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 1 {
+			return nil, fmt.Errorf("invalid BEDROCK_THROTTLE_BUDGET_SECONDS value: %q", raw)
+		}
+		bedrockThrottleBudget = time.Duration(seconds) * time.Second
+	}
+
 	// EventBridge Scheduler execution role
 	eventBridgeExecutionRoleArn := os.Getenv("EVENTBRIDGE_EXECUTION_ROLE_ARN")
 
+	local, err := parseBool("LOCAL", os.Getenv("LOCAL"))
+	if err != nil {
+		return nil, err
+	}
+
+	outboxRelay, err := parseBool("OUTBOX_RELAY", os.Getenv("OUTBOX_RELAY"))
+	if err != nil {
+		return nil, err
+	}
+
+	localStackEndpoint := os.Getenv("LOCALSTACK_ENDPOINT")
+	if localStackEndpoint == "" {
+		localStackEndpoint = "http://localhost:4566"
+	}
+
+	localSecretsFile := os.Getenv("LOCAL_SECRETS_FILE")
+	if localSecretsFile == "" {
+		localSecretsFile = "local-secrets.json"
+	}
+
 	notificationSqsQueueURL := os.Getenv("NOTIFICATION_SQS_QUEUE_URL")
-	if notificationSqsQueueURL == "" {
+	if notificationSqsQueueURL == "" && !local {
 		return nil, fmt.Errorf("NOTIFICATION_SQS_QUEUE_URL environment variable is required")
 	}
 
@@ -99,61 +314,383 @@ func Load() (*Config, error) {
 		ntfyURL = "https://ntfy.sh/rzesz-alerts"
 	}
 
+	oauthTokenRefreshBefore := 10 * time.Minute
+	if raw := os.Getenv("OAUTH_TOKEN_REFRESH_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid OAUTH_TOKEN_REFRESH_SECONDS value: %q", raw)
+		}
+		oauthTokenRefreshBefore = time.Duration(seconds) * time.Second
+	}
+
+	reservationCacheMaxAge := 2 * time.Hour
+	if raw := os.Getenv("RESERVATION_CACHE_MAX_AGE_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid RESERVATION_CACHE_MAX_AGE_SECONDS value: %q", raw)
+		}
+		reservationCacheMaxAge = time.Duration(seconds) * time.Second
+	}
+
+	var circuitBreakerFailureThreshold int
+	if raw := os.Getenv("CIRCUIT_BREAKER_FAILURE_THRESHOLD"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid CIRCUIT_BREAKER_FAILURE_THRESHOLD value: %q", raw)
+		}
+		circuitBreakerFailureThreshold = n
+	}
+
+	var circuitBreakerOpenTimeout time.Duration
+	if raw := os.Getenv("CIRCUIT_BREAKER_OPEN_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 1 {
+			return nil, fmt.Errorf("invalid CIRCUIT_BREAKER_OPEN_SECONDS value: %q", raw)
+		}
+		circuitBreakerOpenTimeout = time.Duration(seconds) * time.Second
+	}
+
+	var sqsRecordTimeout time.Duration
+	if raw := os.Getenv("SQS_RECORD_TIMEOUT_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 1 {
+			return nil, fmt.Errorf("invalid SQS_RECORD_TIMEOUT_SECONDS value: %q", raw)
+		}
+		sqsRecordTimeout = time.Duration(seconds) * time.Second
+	}
+
+	sqsBatchConcurrency := 1
+	if raw := os.Getenv("SQS_BATCH_CONCURRENCY"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid SQS_BATCH_CONCURRENCY value: %q", raw)
+		}
+		sqsBatchConcurrency = n
+	}
+
+	stuckMessageAge := 30 * time.Minute
+	if raw := os.Getenv("STUCK_MESSAGE_AGE_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 1 {
+			return nil, fmt.Errorf("invalid STUCK_MESSAGE_AGE_SECONDS value: %q", raw)
+		}
+		stuckMessageAge = time.Duration(seconds) * time.Second
+	}
+
+	stuckMessageMaxRetries := 3
+	if raw := os.Getenv("STUCK_MESSAGE_MAX_RETRIES"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid STUCK_MESSAGE_MAX_RETRIES value: %q", raw)
+		}
+		stuckMessageMaxRetries = n
+	}
+
+	mcpMaxResultBytes := 16 * 1024
+	if raw := os.Getenv("MCP_MAX_RESULT_BYTES"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1024 {
+			return nil, fmt.Errorf("invalid MCP_MAX_RESULT_BYTES value: %q", raw)
+		}
+		mcpMaxResultBytes = n
+	}
+
+	agentSessionWindow := models.DefaultAgentSessionWindow
+	if raw := os.Getenv("AGENT_SESSION_MAX_MESSAGES"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 2 {
+			return nil, fmt.Errorf("invalid AGENT_SESSION_MAX_MESSAGES value: %q", raw)
+		}
+		agentSessionWindow.MaxMessages = n
+	}
+	if raw := os.Getenv("AGENT_SESSION_MAX_BYTES"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1024 || n > 350*1024 {
+			return nil, fmt.Errorf("invalid AGENT_SESSION_MAX_BYTES value: %q", raw)
+		}
+		agentSessionWindow.MaxBytes = n
+	}
+	if raw := os.Getenv("AGENT_SESSION_TTL_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 60 {
+			return nil, fmt.Errorf("invalid AGENT_SESSION_TTL_SECONDS value: %q", raw)
+		}
+		agentSessionWindow.TTL = time.Duration(seconds) * time.Second
+	}
+
+	// An empty CONSENT_REQUIRED_TOOLS turns consent off; unset keeps the default
+	consentRequiredTools := []string{"golf_book_tee_time"}
+	if raw, ok := os.LookupEnv("CONSENT_REQUIRED_TOOLS"); ok {
+		consentRequiredTools = splitList(raw)
+	}
+
+	agentDailySpendingCap := 5.0
+	if raw := os.Getenv("AGENT_DAILY_SPENDING_CAP"); raw != "" {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid AGENT_DAILY_SPENDING_CAP value: %q", raw)
+		}
+		agentDailySpendingCap = value
+	}
+
+	corsAllowedOrigins, err := parseCORSOrigins(stageEnum, os.Getenv("CORS_ALLOWED_ORIGINS"))
+	if err != nil {
+		return nil, err
+	}
+	corsAllowCredentials, err := parseBool("CORS_ALLOW_CREDENTIALS", os.Getenv("CORS_ALLOW_CREDENTIALS"))
+	if err != nil {
+		return nil, err
+	}
+	if corsAllowCredentials && slices.Contains(corsAllowedOrigins, "*") {
+		return nil, fmt.Errorf("CORS_ALLOW_CREDENTIALS requires CORS_ALLOWED_ORIGINS to list origins instead of *")
+	}
+
+	compressionThreshold := 1024
+	if raw := os.Getenv("COMPRESSION_THRESHOLD_BYTES"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid COMPRESSION_THRESHOLD_BYTES value: %q", raw)
+		}
+		compressionThreshold = n
+	}
+
+	webActionBodyOffload, err := parseBool("WEB_ACTION_BODY_OFFLOAD", os.Getenv("WEB_ACTION_BODY_OFFLOAD"))
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleReconcileRepair, err := parseBool("SCHEDULE_RECONCILE_REPAIR", os.Getenv("SCHEDULE_RECONCILE_REPAIR"))
+	if err != nil {
+		return nil, err
+	}
+
 	golfSecretName := os.Getenv("GOLF_SECRET_NAME")
 	if golfSecretName == "" {
 		golfSecretName = fmt.Sprintf("rez-agent/golf/credentials-%s", stage)
 	}
 
 	return &Config{
-		Stage:                       stageEnum,
-		AWSRegion:                   awsRegion,
-		DynamoDBTableName:           dynamoDBTableName,
-		WebActionResultsTableName:   webActionResultsTableName,
-		SchedulesTableName:          schedulesTableName,
-		WebActionsSNSTopicArn:       webActionsSNSTopicArn,
-		NotificationsSNSTopicArn:    notificationsSNSTopicArn,
-		AgentResponseTopicArn:       agentResponseTopicArn,
-		ScheduleCreationTopicArn:    scheduleCreationTopicArn,
-		EventBridgeExecutionRoleArn: eventBridgeExecutionRoleArn,
-		NotificationSQSQueueURL:     notificationSqsQueueURL,
-		WebActionSQSQueueURL:        webActionSQSQueueURL,
-		NtfyURL:                     ntfyURL,
-		GolfSecretName:              golfSecretName,
-		LambdaTimeout:               30,
+		Stage:                          stageEnum,
+		AWSRegion:                      awsRegion,
+		DynamoDBTableName:              dynamoDBTableName,
+		WebActionResultsTableName:      webActionResultsTableName,
+		SchedulesTableName:             schedulesTableName,
+		WeatherDecisionsTableName:      weatherDecisionsTableName,
+		PreferencesTableName:           preferencesTableName,
+		ApprovalsTableName:             approvalsTableName,
+		OAuthTokenTableName:            os.Getenv("OAUTH_TOKEN_TABLE_NAME"),
+		WebActionHandlerTableName:      os.Getenv("WEB_ACTION_HANDLER_TABLE_NAME"),
+		AuditTableName:                 auditTableName,
+		AgentSessionTableName:          agentSessionTableName,
+		UsersTableName:                 os.Getenv("USERS_TABLE_NAME"),
+		ReservationsTableName:          os.Getenv("RESERVATIONS_TABLE_NAME"),
+		BookingLedgerTableName:         os.Getenv("BOOKING_LEDGER_TABLE_NAME"),
+		WebActionsSNSTopicArn:          webActionsSNSTopicArn,
+		NotificationsSNSTopicArn:       notificationsSNSTopicArn,
+		AgentResponseTopicArn:          agentResponseTopicArn,
+		ScheduleCreationTopicArn:       scheduleCreationTopicArn,
+		TopicRoutes:                    topicRoutes,
+		EventBridgeExecutionRoleArn:    eventBridgeExecutionRoleArn,
+		SchedulerFunctionArn:           os.Getenv("SCHEDULER_FUNCTION_ARN"),
+		EventBusName:                   os.Getenv("EVENT_BUS_NAME"),
+		OutboxRelay:                    outboxRelay,
+		NotificationSQSQueueURL:        notificationSqsQueueURL,
+		WebActionSQSQueueURL:           webActionSQSQueueURL,
+		ScheduleCreationQueueURL:       os.Getenv("SCHEDULE_CREATION_QUEUE_URL"),
+		DigestSQSQueueURL:              os.Getenv("DIGEST_SQS_QUEUE_URL"),
+		AgentResponseQueueURL:          os.Getenv("AGENT_RESPONSE_QUEUE_URL"),
+		NtfyURL:                        ntfyURL,
+		HTTPRequestAllowedHosts:        splitList(os.Getenv("HTTP_REQUEST_ALLOWED_HOSTS")),
+		ApprovalBaseURL:                os.Getenv("APPROVAL_BASE_URL"),
+		DataRequestAPIKey:              os.Getenv("DATA_REQUEST_API_KEY"),
+		MetricsAPIKey:                  os.Getenv("METRICS_API_KEY"),
+		CalendarSigningKey:             os.Getenv("CALENDAR_SIGNING_KEY"),
+		EmailFromAddress:               os.Getenv("EMAIL_FROM_ADDRESS"),
+		DigestRecipients:               splitList(os.Getenv("DIGEST_RECIPIENTS")),
+		A2AAgents:                      a2aAgents,
+		AgentGuardrails:                agentGuardrails,
+		BedrockGuardrailID:             bedrockGuardrailID,
+		BedrockGuardrailVersion:        bedrockGuardrailVersion,
+		BedrockThrottleBudget:          bedrockThrottleBudget,
+		PromptParameterPrefix:          os.Getenv("PROMPT_PARAMETER_PREFIX"),
+		GolfSecretName:                 golfSecretName,
+		OAuthTokenRefreshBefore:        oauthTokenRefreshBefore,
+		ReservationCacheMaxAge:         reservationCacheMaxAge,
+		CircuitBreakerFailureThreshold: circuitBreakerFailureThreshold,
+		CircuitBreakerOpenTimeout:      circuitBreakerOpenTimeout,
+		SQSRecordTimeout:               sqsRecordTimeout,
+		SQSBatchConcurrency:            sqsBatchConcurrency,
+		StuckMessageAge:                stuckMessageAge,
+		StuckMessageMaxRetries:         stuckMessageMaxRetries,
+		ScheduleReconcileRepair:        scheduleReconcileRepair,
+		MCPMaxResultBytes:              mcpMaxResultBytes,
+		AgentSessionWindow:             agentSessionWindow,
+		ConsentRequiredTools:           consentRequiredTools,
+		A2AAPIKey:                      os.Getenv("A2A_API_KEY"),
+		AgentSessionSigningKey:         os.Getenv("AGENT_SESSION_SIGNING_KEY"),
+		AgentCardURL:                   os.Getenv("AGENT_CARD_URL"),
+		AgentDailySpendingCap:          agentDailySpendingCap,
+		CORSAllowedOrigins:             corsAllowedOrigins,
+		CORSAllowCredentials:           corsAllowCredentials,
+		CompressionThreshold:           compressionThreshold,
+		WebActionBodyOffload:           webActionBodyOffload,
+		LambdaTimeout:                  30,
+		Local:                          local,
+		LocalStackEndpoint:             localStackEndpoint,
+		LocalSecretsFile:               localSecretsFile,
+		LocalHTTPAddr:                  os.Getenv("LOCAL_HTTP_ADDR"),
+		explicit:                       explicitSettings(),
 	}, nil
 }
 
-// MustLoad loads configuration and panics if there's an error
-// This is useful for Lambda handlers where configuration errors should prevent startup
-func MustLoad() *Config {
-	cfg, err := Load()
+// parseBool reads a boolean environment variable; empty is false
+func parseBool(name, raw string) (bool, error) {
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
 	if err != nil {
-		panic(fmt.Sprintf("failed to load configuration: %v", err))
+		return false, fmt.Errorf("invalid %s value: %q", name, raw)
 	}
-	return cfg
+	return value, nil
 }
 
-// Validate checks that all required configuration is present
-func (c *Config) Validate() error {
-	if !c.Stage.IsValid() {
-		return fmt.Errorf("invalid stage: %s", c.Stage)
+// splitList parses a comma-separated list, dropping empty entries
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	if c.AWSRegion == "" {
-		return fmt.Errorf("AWS region is required")
+// parseCORSOrigins reads the allowed CORS origins: * or a comma-separated list of origins such as
+// https://app.example.com. Unset, dev allows any origin and the other stages none.
+func parseCORSOrigins(stage models.Stage, raw string) ([]string, error) {
+	if raw == "" {
+		if stage == models.StageDev {
+			return []string{"*"}, nil
+		}
+		return nil, nil
 	}
 
-	if c.DynamoDBTableName == "" {
-		return fmt.Errorf("DynamoDB table name is required")
+	entries := splitList(raw)
+	origins := make([]string, 0, len(entries))
+	for _, origin := range entries {
+		if origin == "*" {
+			if len(entries) > 1 {
+				return nil, fmt.Errorf("invalid CORS_ALLOWED_ORIGINS value: * cannot be combined with origins")
+			}
+			origins = append(origins, origin)
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || (parsed.Path != "" && parsed.Path != "/") || parsed.RawQuery != "" {
+			return nil, fmt.Errorf("invalid CORS_ALLOWED_ORIGINS origin: %q (want scheme://host[:port])", origin)
+		}
+		// Browsers send the Origin header without a trailing slash
+		origins = append(origins, strings.TrimSuffix(origin, "/"))
 	}
+	return origins, nil
+}
 
-	if c.NtfyURL == "" {
-		return fmt.Errorf("ntfy url is required")
+// mergeTopicRoutes adds routes from a JSON object of message type to topic ARN
+// (e.g. {"standing_tee_time":"arn:aws:sns:..."}), typically sourced from an SSM parameter
+func mergeTopicRoutes(routes map[models.MessageType]string, raw string) error {
+	if raw == "" {
+		return nil
 	}
 
+	var overrides map[string]string
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		return fmt.Errorf("invalid TOPIC_ROUTES value: %w", err)
+	}
+	for messageType, topicArn := range overrides {
+		routes[models.MessageType(messageType)] = topicArn
+	}
 	return nil
 }
 
+// parseRemoteAgents reads a JSON array of remote agents
+// (e.g. [{"name":"calendar","url":"https://...","api_key_secret":"..."}])
+func parseRemoteAgents(raw string) ([]models.RemoteAgent, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var agents []models.RemoteAgent
+	if err := json.Unmarshal([]byte(raw), &agents); err != nil {
+		return nil, fmt.Errorf("invalid A2A_AGENTS value: %w", err)
+	}
+	seen := make(map[string]bool, len(agents))
+	for i := range agents {
+		if err := agents[i].Validate(); err != nil {
+			return nil, fmt.Errorf("invalid A2A_AGENTS value: %w", err)
+		}
+		if seen[agents[i].Name] {
+			return nil, fmt.Errorf("invalid A2A_AGENTS value: duplicate agent %s", agents[i].Name)
+		}
+		seen[agents[i].Name] = true
+	}
+	return agents, nil
+}
+
+// parseGuardrails reads a JSON guardrails object
+// (e.g. {"max_bookings_per_run":1,"max_spend_per_run":200})
+func parseGuardrails(raw string) (*policy.Guardrails, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var guardrails policy.Guardrails
+	if err := json.Unmarshal([]byte(raw), &guardrails); err != nil {
+		return nil, fmt.Errorf("invalid AGENT_GUARDRAILS value: %w", err)
+	}
+	if err := guardrails.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid AGENT_GUARDRAILS value: %w", err)
+	}
+	return &guardrails, nil
+}
+
+// LoadAWSConfig loads the AWS SDK configuration for the configured region. In local mode every
+// client uses the LocalStack endpoint, with placeholder credentials when none are set.
+func (c *Config) LoadAWSConfig(ctx context.Context) (aws.Config, error) {
+	options := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(c.AWSRegion)}
+	if c.Local {
+		options = append(options, awsconfig.WithBaseEndpoint(c.LocalStackEndpoint))
+		if os.Getenv("AWS_ACCESS_KEY_ID") == "" {
+			options = append(options, awsconfig.WithCredentialsProvider(
+				credentials.NewStaticCredentialsProvider("test", "test", ""),
+			))
+		}
+	}
+	return awsconfig.LoadDefaultConfig(ctx, options...)
+}
+
+// MustLoad loads configuration, validates it for groups and panics if there's an error
+// This is useful for Lambda handlers where configuration errors should prevent startup
+func MustLoad(groups ...Group) *Config {
+	cfg, err := LoadFor(groups...)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load configuration: %v", err))
+	}
+	return cfg
+}
+
+// LoadFor loads configuration and validates the core settings and those of groups
+func LoadFor(groups ...Group) (*Config, error) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(groups...); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
 // IsDevelopment returns true if the stage is development
 func (c *Config) IsDevelopment() bool {
 	return c.Stage == models.StageDev
@@ -1,9 +1,15 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"log/slog"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/jrzesz33/rez_agent/internal/models"
 )
 
@@ -127,6 +133,199 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestLoad_TopicRoutes(t *testing.T) {
+	t.Setenv("NOTIFICATION_SQS_QUEUE_URL", "https://sqs.us-east-1.amazonaws.com/123456789012/notification-queue")
+	t.Setenv("WEB_ACTIONS_TOPIC_ARN", "arn:aws:sns:us-east-1:123456789012:web-actions")
+	t.Setenv("AGENT_RESPONSE_TOPIC_ARN", "arn:aws:sns:us-east-1:123456789012:agent-response")
+
+	t.Run("legacy topic variables", func(t *testing.T) {
+		t.Setenv("TOPIC_ROUTES", "")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if got := cfg.TopicRoutes[models.MessageTypeWebAction]; got != "arn:aws:sns:us-east-1:123456789012:web-actions" {
+			t.Errorf("TopicRoutes[web_action] = %q", got)
+		}
+	})
+
+	t.Run("TOPIC_ROUTES overrides and extends", func(t *testing.T) {
+		t.Setenv("TOPIC_ROUTES", `{"agent_response":"arn:aws:sns:us-east-1:123456789012:agent-v2","standing_tee_time":"arn:aws:sns:us-east-1:123456789012:standing"}`)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if got := cfg.TopicRoutes[models.MessageTypeAgentResponse]; got != "arn:aws:sns:us-east-1:123456789012:agent-v2" {
+			t.Errorf("TopicRoutes[agent_response] = %q", got)
+		}
+		if got := cfg.TopicRoutes[models.MessageTypeStandingTeeTime]; got != "arn:aws:sns:us-east-1:123456789012:standing" {
+			t.Errorf("TopicRoutes[standing_tee_time] = %q", got)
+		}
+	})
+
+	t.Run("invalid TOPIC_ROUTES", func(t *testing.T) {
+		t.Setenv("TOPIC_ROUTES", "web_action=arn")
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail on malformed TOPIC_ROUTES")
+		}
+	})
+}
+
+func TestLoad_A2AAgents(t *testing.T) {
+	t.Setenv("NOTIFICATION_SQS_QUEUE_URL", "https://sqs.us-east-1.amazonaws.com/123456789012/notification-queue")
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{"unset", "", 0, false},
+		{"valid", `[{"name":"calendar","url":"https://calendar.example.com/agent","api_key_secret":"rez-agent/a2a/calendar"}]`, 1, false},
+		{"plain http", `[{"name":"calendar","url":"http://calendar.example.com/agent"}]`, 0, true},
+		{"name not tool-safe", `[{"name":"Household Calendar","url":"https://calendar.example.com/agent"}]`, 0, true},
+		{"duplicate", `[{"name":"calendar","url":"https://a.example.com"},{"name":"calendar","url":"https://b.example.com"}]`, 0, true},
+		{"malformed", `calendar=https://calendar.example.com`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("A2A_AGENTS", tt.raw)
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(cfg.A2AAgents) != tt.want {
+				t.Errorf("len(A2AAgents) = %d, want %d", len(cfg.A2AAgents), tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_AgentGuardrails(t *testing.T) {
+	t.Setenv("NOTIFICATION_SQS_QUEUE_URL", "https://sqs.us-east-1.amazonaws.com/123456789012/notification-queue")
+
+	tests := []struct {
+		name         string
+		raw          string
+		wantBookings int
+		wantErr      bool
+	}{
+		{"unset", "", 0, false},
+		{"valid", `{"max_bookings_per_run":1,"max_spend_per_run":200,"confirmation_required":["golf_book_tee_time"]}`, 1, false},
+		{"negative limit", `{"max_bookings_per_run":-1}`, 0, true},
+		{"malformed", `max_bookings_per_run=1`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AGENT_GUARDRAILS", tt.raw)
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.AgentGuardrails.Merge(nil).MaxBookingsPerRun != tt.wantBookings {
+				t.Errorf("MaxBookingsPerRun = %d, want %d", cfg.AgentGuardrails.Merge(nil).MaxBookingsPerRun, tt.wantBookings)
+			}
+		})
+	}
+}
+
+func TestLoad_BedrockGuardrail(t *testing.T) {
+	t.Setenv("NOTIFICATION_SQS_QUEUE_URL", "https://sqs.us-east-1.amazonaws.com/123456789012/notification-queue")
+
+	tests := []struct {
+		name        string
+		id          string
+		version     string
+		wantVersion string
+		wantErr     bool
+	}{
+		{"unset", "", "", "", false},
+		{"defaults to draft", "arn:aws:bedrock:us-east-1:123456789012:guardrail/abc123", "", "DRAFT", false},
+		{"pinned version", "abc123", "3", "3", false},
+		{"version without ID", "", "3", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BEDROCK_GUARDRAIL_ID", tt.id)
+			t.Setenv("BEDROCK_GUARDRAIL_VERSION", tt.version)
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (cfg.BedrockGuardrailID != tt.id || cfg.BedrockGuardrailVersion != tt.wantVersion) {
+				t.Errorf("guardrail = %q version %q, want %q version %q", cfg.BedrockGuardrailID, cfg.BedrockGuardrailVersion, tt.id, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestLoad_CORS(t *testing.T) {
+	t.Setenv("NOTIFICATION_SQS_QUEUE_URL", "https://sqs.us-east-1.amazonaws.com/123456789012/notification-queue")
+
+	tests := []struct {
+		name        string
+		stage       string
+		origins     string
+		credentials string
+		want        []string
+		wantErr     bool
+	}{
+		{"dev allows any origin", "dev", "", "", []string{"*"}, false},
+		{"prod allows none", "prod", "", "", nil, false},
+		{"listed origins", "prod", "https://app.example.com/, http://localhost:5173", "true", []string{"https://app.example.com", "http://localhost:5173"}, false},
+		{"credentials with any origin", "dev", "*", "true", nil, true},
+		{"wildcard mixed with origins", "prod", "*,https://app.example.com", "", nil, true},
+		{"origin with a path", "prod", "https://app.example.com/chat", "", nil, true},
+		{"origin without a scheme", "prod", "app.example.com", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STAGE", tt.stage)
+			t.Setenv("CORS_ALLOWED_ORIGINS", tt.origins)
+			t.Setenv("CORS_ALLOW_CREDENTIALS", tt.credentials)
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(cfg.CORSAllowedOrigins, tt.want) {
+				t.Errorf("CORSAllowedOrigins = %q, want %q", cfg.CORSAllowedOrigins, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_LocalMode(t *testing.T) {
+	t.Setenv("NOTIFICATION_SQS_QUEUE_URL", "")
+	t.Setenv("LOCALSTACK_ENDPOINT", "")
+
+	t.Setenv("LOCAL", "")
+	if _, err := Load(); err == nil {
+		t.Error("Load() without LOCAL or NOTIFICATION_SQS_QUEUE_URL error = nil, want error")
+	}
+
+	t.Setenv("LOCAL", "true")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Local || cfg.LocalStackEndpoint != "http://localhost:4566" || cfg.LocalSecretsFile != "local-secrets.json" {
+		t.Errorf("Load() = local %v endpoint %q secrets %q, want local mode defaults", cfg.Local, cfg.LocalStackEndpoint, cfg.LocalSecretsFile)
+	}
+
+	awsCfg, err := cfg.LoadAWSConfig(context.Background())
+	if err != nil {
+		t.Fatalf("LoadAWSConfig() error = %v", err)
+	}
+	if aws.ToString(awsCfg.BaseEndpoint) != "http://localhost:4566" {
+		t.Errorf("BaseEndpoint = %q, want the LocalStack endpoint", aws.ToString(awsCfg.BaseEndpoint))
+	}
+
+	t.Setenv("LOCAL", "maybe")
+	if _, err := Load(); err == nil {
+		t.Error("Load() with LOCAL=maybe error = nil, want error")
+	}
+}
+
 func TestConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -180,6 +379,83 @@ func TestConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestConfig_ValidateGroups(t *testing.T) {
+	base := func() *Config {
+		return &Config{
+			Stage:             models.StageDev,
+			AWSRegion:         "us-east-1",
+			DynamoDBTableName: "test-table",
+			TopicRoutes:       map[models.MessageType]string{},
+		}
+	}
+
+	t.Run("missing settings are reported together", func(t *testing.T) {
+		err := base().Validate(GroupTopicRouting, GroupScheduling)
+		if err == nil {
+			t.Fatal("Validate() error = nil, want missing settings")
+		}
+		for _, want := range []string{"NOTIFICATIONS_TOPIC_ARN", "EVENTBRIDGE_EXECUTION_ROLE_ARN", "SCHEDULES_TABLE_NAME"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Validate() error = %v, want it to name %s", err, want)
+			}
+		}
+	})
+
+	t.Run("routed topic may come from TOPIC_ROUTES", func(t *testing.T) {
+		cfg := base()
+		cfg.TopicRoutes[models.MessageTypeWebAction] = "arn:aws:sns:us-east-1:123456789012:web-actions"
+		if err := cfg.Validate(GroupWebActions); err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+	})
+
+	t.Run("malformed route", func(t *testing.T) {
+		cfg := base()
+		cfg.TopicRoutes[models.MessageTypeStandingTeeTime] = "https://sns.example.com/standing"
+		if err := cfg.Validate(); err == nil {
+			t.Error("Validate() error = nil, want malformed route")
+		}
+	})
+
+	t.Run("prod rejects development defaults", func(t *testing.T) {
+		cfg := base()
+		cfg.Stage = models.StageProd
+		cfg.NtfyURL = "https://ntfy.sh/rzesz-alerts"
+		cfg.explicit = map[string]bool{"DYNAMODB_TABLE_NAME": true}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() without push notifications error = %v", err)
+		}
+		if err := cfg.Validate(GroupPushNotifications); err == nil || !strings.Contains(err.Error(), "NTFY_URL") {
+			t.Errorf("Validate(push-notifications) error = %v, want NTFY_URL to be explicit", err)
+		}
+	})
+}
+
+func TestConfig_LogValue(t *testing.T) {
+	cfg := &Config{
+		Stage:             models.StageDev,
+		NtfyURL:           "https://ntfy.sh/secret-topic",
+		DataRequestAPIKey: "key-123",
+		TopicRoutes:       map[models.MessageType]string{models.MessageTypeWebAction: "arn:aws:sns:us-east-1:123456789012:web-actions"},
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("config", slog.Any("config", cfg))
+	logged := buf.String()
+
+	for _, secret := range []string{"secret-topic", "key-123"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("logged config contains %q: %s", secret, logged)
+		}
+	}
+	if !strings.Contains(logged, `"WEB_ACTIONS_TOPIC_ARN":"arn:aws:sns:us-east-1:123456789012:web-actions"`) {
+		t.Errorf("logged config is missing the resolved web actions route: %s", logged)
+	}
+	if strings.Contains(logged, `"METRICS_API_KEY":"[REDACTED]"`) {
+		t.Errorf("unset secret should log empty: %s", logged)
+	}
+}
+
 func TestConfig_EnvironmentChecks(t *testing.T) {
 	tests := []struct {
 		name          string
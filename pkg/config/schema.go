@@ -0,0 +1,224 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jrzesz33/rez_agent/internal/models"
+)
+
+// Group is a set of settings a feature needs together. Every Lambda validates the core settings
+// plus the groups of the features it runs, so a deploy that leaves one out fails at startup
+// instead of on the first message that needs it.
+type Group string
+
+const (
+	// GroupTopicRouting publishes messages by type; unrouted types need the notifications topic
+	GroupTopicRouting Group = "topic-routing"
+	// GroupWebActions publishes web action requests
+	GroupWebActions Group = "web-actions"
+	// GroupAgentResponses publishes web action results back to the agent
+	GroupAgentResponses Group = "agent-responses"
+	// GroupScheduleRequests publishes schedule creation requests
+	GroupScheduleRequests Group = "schedule-requests"
+	// GroupScheduling creates EventBridge schedules
+	GroupScheduling Group = "scheduling"
+	// GroupPushNotifications sends push notifications through ntfy
+	GroupPushNotifications Group = "push-notifications"
+	// GroupReservationSync copies golf reservations into the reservations cache
+	GroupReservationSync Group = "reservation-sync"
+	// GroupDigest emails the weekly activity digest
+	GroupDigest Group = "digest"
+)
+
+// redacted replaces the value of a secret setting in the resolved config
+const redacted = "[REDACTED]"
+
+// setting is one environment variable of the schema
+type setting struct {
+	env string
+	// core settings are required by every Lambda; otherwise the setting is required by the
+	// listed groups and optional everywhere else
+	core   bool
+	groups []Group
+	// secret values are redacted when the config is logged
+	secret bool
+	// explicitInProd settings have a default meant for development, which a Lambda that
+	// requires the setting may not run with in prod
+	explicitInProd bool
+	// value is the resolved value, after defaults and TOPIC_ROUTES
+	value func(*Config) string
+}
+
+// requiredBy reports whether a Lambda validating groups must have this setting
+func (s setting) requiredBy(groups []Group) bool {
+	if s.core {
+		return true
+	}
+	for _, group := range s.groups {
+		if slices.Contains(groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// schema lists every setting Load reads, in the order the resolved config is printed
+var schema = []setting{
+	{env: "STAGE", core: true, value: func(c *Config) string { return c.Stage.String() }},
+	{env: "AWS_REGION", core: true, value: func(c *Config) string { return c.AWSRegion }},
+	{env: "DYNAMODB_TABLE_NAME", core: true, explicitInProd: true, value: func(c *Config) string { return c.DynamoDBTableName }},
+	{env: "WEB_ACTION_RESULTS_TABLE_NAME", value: func(c *Config) string { return c.WebActionResultsTableName }},
+	{env: "SCHEDULES_TABLE_NAME", groups: []Group{GroupScheduling}, value: func(c *Config) string { return c.SchedulesTableName }},
+	{env: "WEATHER_DECISIONS_TABLE_NAME", value: func(c *Config) string { return c.WeatherDecisionsTableName }},
+	{env: "PREFERENCES_TABLE_NAME", value: func(c *Config) string { return c.PreferencesTableName }},
+	{env: "APPROVALS_TABLE_NAME", value: func(c *Config) string { return c.ApprovalsTableName }},
+	{env: "OAUTH_TOKEN_TABLE_NAME", value: func(c *Config) string { return c.OAuthTokenTableName }},
+	{env: "WEB_ACTION_HANDLER_TABLE_NAME", value: func(c *Config) string { return c.WebActionHandlerTableName }},
+	{env: "AUDIT_TABLE_NAME", value: func(c *Config) string { return c.AuditTableName }},
+	{env: "AGENT_SESSION_TABLE_NAME", value: func(c *Config) string { return c.AgentSessionTableName }},
+	{env: "USERS_TABLE_NAME", value: func(c *Config) string { return c.UsersTableName }},
+	{env: "RESERVATIONS_TABLE_NAME", groups: []Group{GroupReservationSync}, value: func(c *Config) string { return c.ReservationsTableName }},
+	{env: "BOOKING_LEDGER_TABLE_NAME", value: func(c *Config) string { return c.BookingLedgerTableName }},
+	{env: "NOTIFICATIONS_TOPIC_ARN", groups: []Group{GroupTopicRouting}, value: func(c *Config) string { return c.NotificationsSNSTopicArn }},
+	// The routed topics may come from their own variable or from TOPIC_ROUTES
+	{env: "WEB_ACTIONS_TOPIC_ARN", groups: []Group{GroupWebActions}, value: func(c *Config) string { return c.TopicRoutes[models.MessageTypeWebAction] }},
+	{env: "AGENT_RESPONSE_TOPIC_ARN", groups: []Group{GroupAgentResponses}, value: func(c *Config) string { return c.TopicRoutes[models.MessageTypeAgentResponse] }},
+	{env: "SCHEDULE_CREATION_TOPIC_ARN", groups: []Group{GroupScheduleRequests}, value: func(c *Config) string { return c.TopicRoutes[models.MessageTypeScheduleCreation] }},
+	{env: "TOPIC_ROUTES", value: func(c *Config) string { return jsonValue(c.TopicRoutes) }},
+	{env: "EVENTBRIDGE_EXECUTION_ROLE_ARN", groups: []Group{GroupScheduling}, value: func(c *Config) string { return c.EventBridgeExecutionRoleArn }},
+	{env: "SCHEDULER_FUNCTION_ARN", value: func(c *Config) string { return c.SchedulerFunctionArn }},
+	{env: "EVENT_BUS_NAME", value: func(c *Config) string { return c.EventBusName }},
+	{env: "OUTBOX_RELAY", value: func(c *Config) string { return strconv.FormatBool(c.OutboxRelay) }},
+	{env: "NOTIFICATION_SQS_QUEUE_URL", value: func(c *Config) string { return c.NotificationSQSQueueURL }},
+	{env: "WEB_ACTION_SQS_QUEUE_URL", value: func(c *Config) string { return c.WebActionSQSQueueURL }},
+	{env: "SCHEDULE_CREATION_QUEUE_URL", value: func(c *Config) string { return c.ScheduleCreationQueueURL }},
+	{env: "DIGEST_SQS_QUEUE_URL", value: func(c *Config) string { return c.DigestSQSQueueURL }},
+	{env: "AGENT_RESPONSE_QUEUE_URL", value: func(c *Config) string { return c.AgentResponseQueueURL }},
+	// Anyone who knows the ntfy topic can read and post the household's notifications
+	{env: "NTFY_URL", groups: []Group{GroupPushNotifications}, secret: true, explicitInProd: true, value: func(c *Config) string { return c.NtfyURL }},
+	{env: "HTTP_REQUEST_ALLOWED_HOSTS", value: func(c *Config) string { return strings.Join(c.HTTPRequestAllowedHosts, ",") }},
+	{env: "APPROVAL_BASE_URL", value: func(c *Config) string { return c.ApprovalBaseURL }},
+	{env: "DATA_REQUEST_API_KEY", secret: true, value: func(c *Config) string { return c.DataRequestAPIKey }},
+	{env: "METRICS_API_KEY", secret: true, value: func(c *Config) string { return c.MetricsAPIKey }},
+	{env: "CALENDAR_SIGNING_KEY", secret: true, value: func(c *Config) string { return c.CalendarSigningKey }},
+	{env: "EMAIL_FROM_ADDRESS", groups: []Group{GroupDigest}, value: func(c *Config) string { return c.EmailFromAddress }},
+	{env: "DIGEST_RECIPIENTS", groups: []Group{GroupDigest}, value: func(c *Config) string { return strings.Join(c.DigestRecipients, ",") }},
+	{env: "A2A_AGENTS", value: func(c *Config) string { return jsonValue(c.A2AAgents) }},
+	{env: "AGENT_GUARDRAILS", value: func(c *Config) string { return jsonValue(c.AgentGuardrails) }},
+	{env: "BEDROCK_GUARDRAIL_ID", value: func(c *Config) string { return c.BedrockGuardrailID }},
+	{env: "BEDROCK_GUARDRAIL_VERSION", value: func(c *Config) string { return c.BedrockGuardrailVersion }},
+	{env: "BEDROCK_THROTTLE_BUDGET_SECONDS", value: func(c *Config) string { return durationSeconds(c.BedrockThrottleBudget.Seconds()) }},
+	{env: "PROMPT_PARAMETER_PREFIX", value: func(c *Config) string { return c.PromptParameterPrefix }},
+	{env: "GOLF_SECRET_NAME", value: func(c *Config) string { return c.GolfSecretName }},
+	{env: "OAUTH_TOKEN_REFRESH_SECONDS", value: func(c *Config) string { return durationSeconds(c.OAuthTokenRefreshBefore.Seconds()) }},
+	{env: "RESERVATION_CACHE_MAX_AGE_SECONDS", value: func(c *Config) string { return durationSeconds(c.ReservationCacheMaxAge.Seconds()) }},
+	{env: "CIRCUIT_BREAKER_FAILURE_THRESHOLD", value: func(c *Config) string { return intValue(c.CircuitBreakerFailureThreshold) }},
+	{env: "CIRCUIT_BREAKER_OPEN_SECONDS", value: func(c *Config) string { return durationSeconds(c.CircuitBreakerOpenTimeout.Seconds()) }},
+	{env: "SQS_RECORD_TIMEOUT_SECONDS", value: func(c *Config) string { return durationSeconds(c.SQSRecordTimeout.Seconds()) }},
+	{env: "SQS_BATCH_CONCURRENCY", value: func(c *Config) string { return intValue(c.SQSBatchConcurrency) }},
+	{env: "STUCK_MESSAGE_AGE_SECONDS", value: func(c *Config) string { return durationSeconds(c.StuckMessageAge.Seconds()) }},
+	{env: "STUCK_MESSAGE_MAX_RETRIES", value: func(c *Config) string { return intValue(c.StuckMessageMaxRetries) }},
+	{env: "SCHEDULE_RECONCILE_REPAIR", value: func(c *Config) string { return strconv.FormatBool(c.ScheduleReconcileRepair) }},
+	{env: "MCP_MAX_RESULT_BYTES", value: func(c *Config) string { return intValue(c.MCPMaxResultBytes) }},
+	{env: "AGENT_SESSION_MAX_MESSAGES", value: func(c *Config) string { return intValue(c.AgentSessionWindow.MaxMessages) }},
+	{env: "AGENT_SESSION_MAX_BYTES", value: func(c *Config) string { return intValue(c.AgentSessionWindow.MaxBytes) }},
+	{env: "AGENT_SESSION_TTL_SECONDS", value: func(c *Config) string { return durationSeconds(c.AgentSessionWindow.TTL.Seconds()) }},
+	{env: "CONSENT_REQUIRED_TOOLS", value: func(c *Config) string { return strings.Join(c.ConsentRequiredTools, ",") }},
+	{env: "A2A_API_KEY", secret: true, value: func(c *Config) string { return c.A2AAPIKey }},
+	{env: "AGENT_SESSION_SIGNING_KEY", secret: true, value: func(c *Config) string { return c.AgentSessionSigningKey }},
+	{env: "AGENT_CARD_URL", value: func(c *Config) string { return c.AgentCardURL }},
+	{env: "AGENT_DAILY_SPENDING_CAP", value: func(c *Config) string { return strconv.FormatFloat(c.AgentDailySpendingCap, 'f', -1, 64) }},
+	{env: "CORS_ALLOWED_ORIGINS", value: func(c *Config) string { return strings.Join(c.CORSAllowedOrigins, ",") }},
+	{env: "CORS_ALLOW_CREDENTIALS", value: func(c *Config) string { return strconv.FormatBool(c.CORSAllowCredentials) }},
+	{env: "COMPRESSION_THRESHOLD_BYTES", value: func(c *Config) string { return strconv.Itoa(c.CompressionThreshold) }},
+	{env: "WEB_ACTION_BODY_OFFLOAD", value: func(c *Config) string { return strconv.FormatBool(c.WebActionBodyOffload) }},
+	{env: "LOCAL", value: func(c *Config) string { return strconv.FormatBool(c.Local) }},
+	{env: "LOCALSTACK_ENDPOINT", value: func(c *Config) string { return c.LocalStackEndpoint }},
+	{env: "LOCAL_SECRETS_FILE", value: func(c *Config) string { return c.LocalSecretsFile }},
+	{env: "LOCAL_HTTP_ADDR", value: func(c *Config) string { return c.LocalHTTPAddr }},
+}
+
+// explicitSettings records which schema settings were set in the environment rather than defaulted
+func explicitSettings() map[string]bool {
+	explicit := make(map[string]bool, len(schema))
+	for _, s := range schema {
+		if os.Getenv(s.env) != "" {
+			explicit[s.env] = true
+		}
+	}
+	return explicit
+}
+
+// Validate checks the core settings and those of groups, reporting every problem at once.
+// In prod it also rejects development defaults for settings that must be set explicitly.
+func (c *Config) Validate(groups ...Group) error {
+	var errs []error
+	if !c.Stage.IsValid() {
+		errs = append(errs, fmt.Errorf("invalid stage: %s", c.Stage))
+	}
+
+	for _, s := range schema {
+		switch {
+		case s.requiredBy(groups) && s.value(c) == "":
+			errs = append(errs, fmt.Errorf("%s is required", s.env))
+		case s.explicitInProd && s.requiredBy(groups) && c.IsProduction() && c.explicit != nil && !c.explicit[s.env]:
+			errs = append(errs, fmt.Errorf("%s must be set explicitly in prod", s.env))
+		}
+	}
+
+	// A route to a malformed ARN would only fail when the first message of that type is published
+	for messageType, topicArn := range c.TopicRoutes {
+		if topicArn != "" && !strings.HasPrefix(topicArn, "arn:aws:sns:") {
+			errs = append(errs, fmt.Errorf("topic route for %s is not an SNS topic ARN: %q", messageType, topicArn))
+		}
+	}
+	if c.NotificationsSNSTopicArn != "" && !strings.HasPrefix(c.NotificationsSNSTopicArn, "arn:aws:sns:") {
+		errs = append(errs, fmt.Errorf("NOTIFICATIONS_TOPIC_ARN is not an SNS topic ARN: %q", c.NotificationsSNSTopicArn))
+	}
+
+	return errors.Join(errs...)
+}
+
+// LogValue prints the resolved configuration with secrets redacted, so a Lambda can log what it
+// actually runs with: slog.Any("config", cfg)
+func (c *Config) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(schema))
+	for _, s := range schema {
+		value := s.value(c)
+		if s.secret && value != "" {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(s.env, value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// jsonValue renders a structured setting the way it is written in the environment; empty stays empty
+func jsonValue(v any) string {
+	body, err := json.Marshal(v)
+	if err != nil || string(body) == "null" {
+		return ""
+	}
+	return string(body)
+}
+
+func intValue(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func durationSeconds(seconds float64) string {
+	if seconds == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(seconds, 'f', -1, 64)
+}
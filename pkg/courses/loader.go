@@ -3,7 +3,9 @@ package courses
 import (
 	_ "embed"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,20 +20,126 @@ type Action struct {
 		URL                   string `yaml:"url"`
 		CancelReservationLink string `yaml:"cancelReservationLink,omitempty"`
 		HomePageLink          string `yaml:"homePageLink,omitempty"`
+		// Referer is the page, relative to the course origin, the request appears to come from
+		Referer string `yaml:"referer,omitempty"`
 	} `yaml:"request"`
 }
 
+// ProviderHeaders identify the booking site, terminal and time zone to the course's booking
+// API. A course sets only the fields that differ from DefaultProviderHeaders.
+type ProviderHeaders struct {
+	SiteID      string `yaml:"siteId,omitempty"`
+	TerminalID  string `yaml:"terminalId,omitempty"`
+	ModuleID    string `yaml:"moduleId,omitempty"`
+	ProductID   string `yaml:"productId,omitempty"`
+	ComponentID string `yaml:"componentId,omitempty"`
+	UserAgent   string `yaml:"userAgent,omitempty"`
+}
+
+// DefaultProviderHeaders are the values the CPS Golf web app sends for the configured courses
+var DefaultProviderHeaders = ProviderHeaders{
+	SiteID:      "3",
+	TerminalID:  "7",
+	ModuleID:    "7",
+	ProductID:   "1",
+	ComponentID: "1",
+	UserAgent:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+}
+
 // Course represents a golf course configuration
 type Course struct {
 	CourseID    int      `yaml:"courseId"`
 	Name        string   `yaml:"name"`
 	Address     string   `yaml:"address"`
+	Latitude    float64  `yaml:"latitude"`
+	Longitude   float64  `yaml:"longitude"`
 	Description string   `yaml:"description"`
 	Origin      string   `yaml:"origin"`
 	ClientID    string   `yaml:"client-id"`
 	WebsiteID   string   `yaml:"websiteid"`
 	Scope       string   `yaml:"scope"`
 	Actions     []Action `yaml:"actions"`
+
+	// Timezone is the course's IANA time zone. Tee times are in course-local time.
+	Timezone string `yaml:"timezone,omitempty"`
+
+	// Headers override DefaultProviderHeaders for this course's booking API
+	Headers ProviderHeaders `yaml:"headers,omitempty"`
+
+	// BookingWindowDays is how many days in advance tee times can be booked
+	BookingWindowDays int `yaml:"bookingWindowDays,omitempty"`
+}
+
+// DefaultBookingWindowDays is the booking window of a course that does not set one
+const DefaultBookingWindowDays = 14
+
+// GetBookingWindowDays returns how many days in advance tee times can be booked at this course
+func (c *Course) GetBookingWindowDays() int {
+	if c.BookingWindowDays > 0 {
+		return c.BookingWindowDays
+	}
+	return DefaultBookingWindowDays
+}
+
+// DefaultTimezone is the time zone of a course that does not set one
+const DefaultTimezone = "America/New_York"
+
+// GetTimezone returns the course's IANA time zone name
+func (c *Course) GetTimezone() string {
+	if c.Timezone != "" {
+		return c.Timezone
+	}
+	return DefaultTimezone
+}
+
+// Location returns the course's time zone. LoadCourses rejects unknown time zones, so UTC is only
+// returned for a course built by hand with a bad one.
+func (c *Course) Location() *time.Location {
+	loc, err := time.LoadLocation(c.GetTimezone())
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// TimezoneOffset returns the course's UTC offset at now as the booking API expects it: minutes
+// behind UTC, as the browser's getTimezoneOffset reports it ("240" in Eastern daylight time and
+// "300" in Eastern standard time)
+func (c *Course) TimezoneOffset(now time.Time) string {
+	_, offset := now.In(c.Location()).Zone()
+	return strconv.Itoa(-offset / 60)
+}
+
+// GetProviderHeaders returns the course's booking API headers, with defaults for those it does not set
+func (c *Course) GetProviderHeaders() ProviderHeaders {
+	h := c.Headers
+	d := DefaultProviderHeaders
+	for _, f := range []struct {
+		value *string
+		def   string
+	}{
+		{&h.SiteID, d.SiteID},
+		{&h.TerminalID, d.TerminalID},
+		{&h.ModuleID, d.ModuleID},
+		{&h.ProductID, d.ProductID},
+		{&h.ComponentID, d.ComponentID},
+		{&h.UserAgent, d.UserAgent},
+	} {
+		if *f.value == "" {
+			*f.value = f.def
+		}
+	}
+	return h
+}
+
+// GetActionReferer returns the full referer URL for a named action, or "" when it sets none
+func (c *Course) GetActionReferer(actionName string) string {
+	for _, action := range c.Actions {
+		if action.Request.Name == actionName && action.Request.Referer != "" {
+			return c.Origin + action.Request.Referer
+		}
+	}
+	return ""
 }
 
 // CoursesConfig represents the root configuration
@@ -52,6 +160,20 @@ func (c *Course) GetActionURL(actionName string) (string, error) {
 	return "", fmt.Errorf("action not found: %s", actionName)
 }
 
+// GetCancelReservationURL returns the course's page for cancelling a reservation, or "" when the
+// course does not configure one
+func (c *Course) GetCancelReservationURL() string {
+	for _, action := range c.Actions {
+		if link := action.Request.CancelReservationLink; link != "" {
+			if strings.HasPrefix(link, "http") {
+				return link
+			}
+			return c.Origin + link
+		}
+	}
+	return ""
+}
+
 // GetSecretName returns the AWS Secrets Manager secret name for this course
 func (c *Course) GetSecretName(stage string) string {
 	// Convention: rez-agent/golf/credentials-{stage}
@@ -65,6 +187,11 @@ func LoadCourses() (*CoursesConfig, error) {
 	if err := yaml.Unmarshal(courseInfoYAML, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse courseInfo.yaml: %w", err)
 	}
+	for _, course := range config.Courses {
+		if _, err := time.LoadLocation(course.GetTimezone()); err != nil {
+			return nil, fmt.Errorf("course %d has an invalid timezone %q: %w", course.CourseID, course.Timezone, err)
+		}
+	}
 	return &config, nil
 }
 